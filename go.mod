@@ -24,6 +24,10 @@ require (
 	github.com/decred/dcrtime v0.0.0-20191018193024-8d8b4ef0458e
 	github.com/decred/dcrtime/api/v2 v2.0.0-20200912200806-b1e4dbc46be9
 	github.com/decred/go-socks v1.1.0
+	github.com/decred/politeia/politeiad/plugins v1.0.0
+	github.com/decred/politeia/politeiawww/api v1.0.0
+	github.com/decred/politeia/politeiawww/client v1.0.0
+	github.com/decred/politeia/unittest v1.0.0
 	github.com/decred/slog v1.1.0
 	github.com/go-sql-driver/mysql v1.5.0
 	github.com/go-test/deep v1.0.1
@@ -56,3 +60,10 @@ require (
 	google.golang.org/genproto v0.0.0-20200707001353-8e8330bf89df
 	google.golang.org/grpc v1.32.0
 )
+
+replace (
+	github.com/decred/politeia/politeiad/plugins => ./politeiad/plugins
+	github.com/decred/politeia/politeiawww/api => ./politeiawww/api
+	github.com/decred/politeia/politeiawww/client => ./politeiawww/client
+	github.com/decred/politeia/unittest => ./unittest
+)
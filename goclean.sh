@@ -12,20 +12,31 @@
 
 set -ex
 
-# run tests
-env GORACE="halt_on_error=1" go test -race ./...
+# The politeiawww client, the API types that it uses, and the unittest helpers
+# are separate modules so that importers of the client do not depend on the
+# server dependencies. Each module is checked from its own directory.
+MODULES=". unittest politeiad/plugins politeiawww/api politeiawww/client"
 
-# golangci-lint (github.com/golangci/golangci-lint) is used to run each each
-# static checker.
+for module in $MODULES; do
+  (
+    cd "$module"
 
-# check linters
-golangci-lint run --disable-all --deadline=10m \
-  --enable=gofmt \
-  --enable=vet \
-  --enable=gosimple \
-  --enable=unconvert \
-  --enable=ineffassign \
-  --enable=misspell \
-  --enable=bodyclose \
-  --enable=rowserrcheck \
-  --enable=sqlclosecheck \
+    # run tests
+    env GORACE="halt_on_error=1" go test -race ./...
+
+    # golangci-lint (github.com/golangci/golangci-lint) is used to run each each
+    # static checker.
+
+    # check linters
+    golangci-lint run --disable-all --deadline=10m \
+      --enable=gofmt \
+      --enable=vet \
+      --enable=gosimple \
+      --enable=unconvert \
+      --enable=ineffassign \
+      --enable=misspell \
+      --enable=bodyclose \
+      --enable=rowserrcheck \
+      --enable=sqlclosecheck
+  )
+done
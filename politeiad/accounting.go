@@ -0,0 +1,336 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	v2 "github.com/decred/politeia/politeiad/api/v2"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// slowRequestsMax is the number of slow requests that are kept in
+	// the slow request log.
+	slowRequestsMax = 100
+
+	// routeMetrics is the route that the resource usage metrics are
+	// served on.
+	routeMetrics = "/metrics"
+
+	// metricsContentType is the content type of the Prometheus text
+	// exposition format.
+	metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+	// routeUnmatched is the route of requests that did not match a
+	// route.
+	routeUnmatched = "unmatched"
+)
+
+// usageKey identifies the aggregate resource usage of a route or of a plugin
+// command that was executed by a route.
+type usageKey struct {
+	route    string
+	pluginID string
+	cmd      string
+}
+
+// usageTotals contains the aggregate resource usage of a usageKey.
+type usageTotals struct {
+	count    uint64
+	slow     uint64
+	duration time.Duration
+	backend  time.Duration
+}
+
+// usageOp is a backend operation that was performed by a request.
+type usageOp struct {
+	op       string
+	pluginID string
+	cmd      string
+	token    string
+	duration time.Duration
+}
+
+// usage contains the resource usage of a single request. The backend
+// operations of a request are executed sequentially by the request handler,
+// so it is not safe for concurrent use.
+type usage struct {
+	route   string
+	backend time.Duration
+	ops     []usageOp
+}
+
+// addOp records a backend operation.
+func (u *usage) addOp(op usageOp) {
+	u.backend += op.duration
+	u.ops = append(u.ops, op)
+}
+
+// usageCtxKey is the context key of the resource usage of a request.
+type usageCtxKey struct{}
+
+// usageFromContext returns the resource usage of the request. Nil is returned
+// if the request is not being accounted.
+func usageFromContext(ctx context.Context) *usage {
+	u, _ := ctx.Value(usageCtxKey{}).(*usage)
+	return u
+}
+
+// accounting tracks the resource usage of the politeiad requests and keeps a
+// log of the most recent slow requests.
+type accounting struct {
+	sync.Mutex
+	threshold time.Duration // Zero disables the slow request log
+	totals    map[usageKey]*usageTotals
+	slow      []v2.SlowRequest // Oldest first
+
+	// metrics contains the functions that write additional metrics to
+	// the metrics reply. They must be added before the server starts
+	// accepting requests.
+	metrics []func(io.Writer)
+}
+
+// newAccounting returns a new accounting. Requests whose duration is at least
+// the threshold are logged as slow requests.
+func newAccounting(threshold time.Duration) *accounting {
+	return &accounting{
+		threshold: threshold,
+		totals:    make(map[usageKey]*usageTotals, 64),
+		slow:      make([]v2.SlowRequest, 0, slowRequestsMax),
+	}
+}
+
+// addMetrics adds a function that writes additional metrics to the metrics
+// reply using the Prometheus text format.
+func (a *accounting) addMetrics(f func(io.Writer)) {
+	a.metrics = append(a.metrics, f)
+}
+
+// middleware records the resource usage of every request. The request is
+// labeled using the route template so that path variables do not create a
+// new label for every request.
+func (a *accounting) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeUnmatched
+		if cr := mux.CurrentRoute(r); cr != nil {
+			if t, err := cr.GetPathTemplate(); err == nil {
+				route = t
+			}
+		}
+		u := &usage{
+			route: route,
+		}
+		ctx := context.WithValue(r.Context(), usageCtxKey{}, u)
+
+		start := time.Now()
+		next.ServeHTTP(w, r.WithContext(ctx))
+
+		a.record(u, time.Since(start))
+	})
+}
+
+// add adds a request or a plugin command to the aggregate resource usage of
+// the provided key.
+//
+// This function must be called WITH the lock held.
+func (a *accounting) add(k usageKey, duration, backend time.Duration, slow bool) {
+	t, ok := a.totals[k]
+	if !ok {
+		t = &usageTotals{}
+		a.totals[k] = t
+	}
+	t.count++
+	t.duration += duration
+	t.backend += backend
+	if slow {
+		t.slow++
+	}
+}
+
+// record records the resource usage of a request. The request is added to
+// the slow request log if its duration exceeded the threshold.
+func (a *accounting) record(u *usage, duration time.Duration) {
+	slow := a.threshold > 0 && duration >= a.threshold
+
+	a.Lock()
+	defer a.Unlock()
+
+	a.add(usageKey{route: u.route}, duration, u.backend, slow)
+	for _, v := range u.ops {
+		if v.pluginID == "" {
+			continue
+		}
+		k := usageKey{
+			route:    u.route,
+			pluginID: v.pluginID,
+			cmd:      v.cmd,
+		}
+		a.add(k, v.duration, v.duration, slow)
+	}
+	if !slow {
+		return
+	}
+
+	ops := make([]v2.UsageOperation, 0, len(u.ops))
+	desc := make([]string, 0, len(u.ops))
+	for _, v := range u.ops {
+		ops = append(ops, v2.UsageOperation{
+			Operation: v.op,
+			PluginID:  v.pluginID,
+			Command:   v.cmd,
+			Token:     v.token,
+			Duration:  v.duration.Microseconds(),
+		})
+		d := v.op
+		if v.pluginID != "" {
+			d += " " + v.pluginID + " " + v.cmd
+		}
+		if v.token != "" {
+			d += " " + v.token
+		}
+		desc = append(desc, fmt.Sprintf("%v (%v)", d, v.duration))
+	}
+	log.Warnf("Slow request: %v %v (backend %v): %v", u.route,
+		duration, u.backend, strings.Join(desc, ", "))
+
+	if len(a.slow) == slowRequestsMax {
+		copy(a.slow, a.slow[1:])
+		a.slow = a.slow[:len(a.slow)-1]
+	}
+	a.slow = append(a.slow, v2.SlowRequest{
+		Timestamp:   time.Now().Unix(),
+		Route:       u.route,
+		Duration:    duration.Microseconds(),
+		BackendTime: u.backend.Microseconds(),
+		Operations:  ops,
+	})
+}
+
+// summaries returns the aggregate resource usage of all routes and plugin
+// commands, sorted by total duration from highest to lowest.
+func (a *accounting) summaries() []v2.UsageSummary {
+	a.Lock()
+	defer a.Unlock()
+
+	s := make([]v2.UsageSummary, 0, len(a.totals))
+	for k, t := range a.totals {
+		s = append(s, v2.UsageSummary{
+			Route:       k.route,
+			PluginID:    k.pluginID,
+			Command:     k.cmd,
+			Count:       t.count,
+			Slow:        t.slow,
+			Duration:    t.duration.Microseconds(),
+			BackendTime: t.backend.Microseconds(),
+		})
+	}
+	sort.Slice(s, func(i, j int) bool {
+		if s[i].Duration != s[j].Duration {
+			return s[i].Duration > s[j].Duration
+		}
+		if s[i].Route != s[j].Route {
+			return s[i].Route < s[j].Route
+		}
+		if s[i].PluginID != s[j].PluginID {
+			return s[i].PluginID < s[j].PluginID
+		}
+		return s[i].Command < s[j].Command
+	})
+	return s
+}
+
+// slowRequests returns the slow request log, sorted from newest to oldest.
+func (a *accounting) slowRequests() []v2.SlowRequest {
+	a.Lock()
+	defer a.Unlock()
+
+	s := make([]v2.SlowRequest, 0, len(a.slow))
+	for i := len(a.slow) - 1; i >= 0; i-- {
+		s = append(s, a.slow[i])
+	}
+	return s
+}
+
+// ServeHTTP serves the resource usage metrics using the Prometheus text
+// exposition format.
+func (a *accounting) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		b       strings.Builder
+		s       = a.summaries()
+		metrics = []struct {
+			name  string
+			help  string
+			value func(v2.UsageSummary) float64
+		}{
+			{
+				"politeiad_requests_total",
+				"Total number of requests by route and plugin command.",
+				func(u v2.UsageSummary) float64 { return float64(u.Count) },
+			},
+			{
+				"politeiad_slow_requests_total",
+				"Total number of slow requests by route and plugin command.",
+				func(u v2.UsageSummary) float64 { return float64(u.Slow) },
+			},
+			{
+				"politeiad_request_duration_seconds_total",
+				"Total request duration by route and plugin command.",
+				func(u v2.UsageSummary) float64 { return usToSeconds(u.Duration) },
+			},
+			{
+				"politeiad_request_backend_seconds_total",
+				"Total request backend time by route and plugin command.",
+				func(u v2.UsageSummary) float64 { return usToSeconds(u.BackendTime) },
+			},
+		}
+	)
+	sort.Slice(s, func(i, j int) bool {
+		if s[i].Route != s[j].Route {
+			return s[i].Route < s[j].Route
+		}
+		if s[i].PluginID != s[j].PluginID {
+			return s[i].PluginID < s[j].PluginID
+		}
+		return s[i].Command < s[j].Command
+	})
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "# HELP %v %v\n", m.name, m.help)
+		fmt.Fprintf(&b, "# TYPE %v counter\n", m.name)
+		for _, u := range s {
+			fmt.Fprintf(&b, "%v{route=%q,plugin=%q,command=%q} %v\n",
+				m.name, u.Route, u.PluginID, u.Command,
+				strconv.FormatFloat(m.value(u), 'g', -1, 64))
+		}
+	}
+	if cpu, ok := processCPUTime(); ok {
+		name := "politeiad_process_cpu_seconds_total"
+		fmt.Fprintf(&b, "# HELP %v Total user and system CPU time of "+
+			"the process.\n", name)
+		fmt.Fprintf(&b, "# TYPE %v counter\n", name)
+		fmt.Fprintf(&b, "%v %v\n", name,
+			strconv.FormatFloat(cpu.Seconds(), 'g', -1, 64))
+	}
+	for _, f := range a.metrics {
+		f(&b)
+	}
+
+	w.Header().Set("Content-Type", metricsContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(b.String()))
+}
+
+// usToSeconds converts a duration in microseconds to seconds.
+func usToSeconds(us int64) float64 {
+	return float64(us) / 1e6
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func TestAccounting(t *testing.T) {
+	a := newAccounting(time.Hour)
+
+	// The handler records a plugin command
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		u := usageFromContext(r.Context())
+		if u == nil {
+			t.Fatalf("request is not accounted")
+		}
+		u.addOp(usageOp{
+			op:       "PluginRead",
+			pluginID: "ticketvote",
+			cmd:      "summary",
+			token:    "0123456789abcdef",
+			duration: time.Millisecond,
+		})
+		w.WriteHeader(http.StatusOK)
+	}
+	router := mux.NewRouter()
+	router.Use(a.middleware)
+	router.HandleFunc("/v2/pluginreads", handler).Methods(http.MethodPost)
+
+	for i := 0; i < 2; i++ {
+		r := httptest.NewRequest(http.MethodPost, "/v2/pluginreads", nil)
+		router.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	s := a.summaries()
+	if len(s) != 2 {
+		t.Fatalf("got %v summaries, want 2", len(s))
+	}
+	for _, v := range s {
+		if v.Route != "/v2/pluginreads" || v.Count != 2 || v.Slow != 0 {
+			t.Fatalf("unexpected summary: %+v", v)
+		}
+		if v.BackendTime != 2000 {
+			t.Fatalf("got backend time %v, want 2000", v.BackendTime)
+		}
+	}
+	if len(a.slowRequests()) != 0 {
+		t.Fatalf("fast request logged as slow")
+	}
+
+	// Record slow requests until the slow request log is full
+	a.threshold = time.Millisecond
+	for i := 0; i <= slowRequestsMax; i++ {
+		u := &usage{
+			route: "/v2/records",
+		}
+		a.record(u, time.Duration(i+1)*time.Millisecond)
+	}
+	slow := a.slowRequests()
+	if len(slow) != slowRequestsMax {
+		t.Fatalf("got %v slow requests, want %v", len(slow),
+			slowRequestsMax)
+	}
+	if slow[0].Duration < slow[len(slow)-1].Duration {
+		t.Fatalf("slow requests are not sorted from newest to oldest")
+	}
+
+	// The metrics include the plugin command labels
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, httptest.NewRequest(http.MethodGet, routeMetrics, nil))
+	want := `politeiad_requests_total{route="/v2/pluginreads",` +
+		`plugin="ticketvote",command="summary"} 2`
+	if !strings.Contains(w.Body.String(), want) {
+		t.Fatalf("metrics do not contain %v:\n%v", want, w.Body.String())
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/decred/politeia/politeiad/backendv2"
+)
+
+// accountingBackend wraps a backendv2.Backend and records the duration of
+// every backend operation in the resource usage of a request.
+// Most backend methods do not take a context, so a new accountingBackend is
+// created for every request.
+type accountingBackend struct {
+	backendv2.Backend
+	usage *usage
+}
+
+// backend returns the backend that is used to serve the request. The backend
+// records its operations in the resource usage of the request when the
+// request is being accounted.
+func (p *politeia) backend(r *http.Request) backendv2.Backend {
+	u := usageFromContext(r.Context())
+	if u == nil {
+		return p.backendv2
+	}
+	return &accountingBackend{
+		Backend: p.backendv2,
+		usage:   u,
+	}
+}
+
+// start starts the accounting of a backend operation. The returned function
+// must be called once the operation has completed.
+func (b *accountingBackend) start(op, pluginID, cmd string, token []byte) func() {
+	var (
+		start    = time.Now()
+		tokenStr string
+	)
+	if len(token) > 0 {
+		tokenStr = hex.EncodeToString(token)
+	}
+	return func() {
+		b.usage.addOp(usageOp{
+			op:       op,
+			pluginID: pluginID,
+			cmd:      cmd,
+			token:    tokenStr,
+			duration: time.Since(start),
+		})
+	}
+}
+
+// RecordNew satisfies the backendv2.Backend interface.
+func (b *accountingBackend) RecordNew(ctx context.Context, metadata []backendv2.MetadataStream, files []backendv2.File) (*backendv2.Record, error) {
+	defer b.start("RecordNew", "", "", nil)()
+	return b.Backend.RecordNew(ctx, metadata, files)
+}
+
+// RecordEdit satisfies the backendv2.Backend interface.
+func (b *accountingBackend) RecordEdit(ctx context.Context, token []byte, mdAppend, mdOverwrite []backendv2.MetadataStream, filesAdd []backendv2.File, filesDel []string) (*backendv2.Record, error) {
+	defer b.start("RecordEdit", "", "", token)()
+	return b.Backend.RecordEdit(ctx, token, mdAppend, mdOverwrite,
+		filesAdd, filesDel)
+}
+
+// RecordEditMetadata satisfies the backendv2.Backend interface.
+func (b *accountingBackend) RecordEditMetadata(ctx context.Context, token []byte, mdAppend, mdOverwrite []backendv2.MetadataStream) (*backendv2.Record, error) {
+	defer b.start("RecordEditMetadata", "", "", token)()
+	return b.Backend.RecordEditMetadata(ctx, token, mdAppend, mdOverwrite)
+}
+
+// RecordSetStatus satisfies the backendv2.Backend interface.
+func (b *accountingBackend) RecordSetStatus(ctx context.Context, token []byte, s backendv2.StatusT, mdAppend, mdOverwrite []backendv2.MetadataStream) (*backendv2.Record, error) {
+	defer b.start("RecordSetStatus", "", "", token)()
+	return b.Backend.RecordSetStatus(ctx, token, s, mdAppend, mdOverwrite)
+}
+
+// RecordExists satisfies the backendv2.Backend interface.
+func (b *accountingBackend) RecordExists(token []byte) bool {
+	defer b.start("RecordExists", "", "", token)()
+	return b.Backend.RecordExists(token)
+}
+
+// RecordTimestamps satisfies the backendv2.Backend interface.
+func (b *accountingBackend) RecordTimestamps(token []byte, version uint32) (*backendv2.RecordTimestamps, error) {
+	defer b.start("RecordTimestamps", "", "", token)()
+	return b.Backend.RecordTimestamps(token, version)
+}
+
+// Records satisfies the backendv2.Backend interface. The token is only
+// recorded when a single record is requested.
+func (b *accountingBackend) Records(reqs []backendv2.RecordRequest) (map[string]backendv2.Record, error) {
+	var token []byte
+	if len(reqs) == 1 {
+		token = reqs[0].Token
+	}
+	defer b.start("Records", "", "", token)()
+	return b.Backend.Records(reqs)
+}
+
+// Inventory satisfies the backendv2.Backend interface.
+func (b *accountingBackend) Inventory(state backendv2.StateT, status backendv2.StatusT, pageSize, pageNumber uint32) (*backendv2.Inventory, error) {
+	defer b.start("Inventory", "", "", nil)()
+	return b.Backend.Inventory(state, status, pageSize, pageNumber)
+}
+
+// InventoryOrdered satisfies the backendv2.Backend interface.
+func (b *accountingBackend) InventoryOrdered(s backendv2.StateT, pageSize, pageNumber uint32) ([]string, error) {
+	defer b.start("InventoryOrdered", "", "", nil)()
+	return b.Backend.InventoryOrdered(s, pageSize, pageNumber)
+}
+
+// InventorySnapshot satisfies the backendv2.Backend interface.
+func (b *accountingBackend) InventorySnapshot() (*backendv2.InventorySnapshot, error) {
+	defer b.start("InventorySnapshot", "", "", nil)()
+	return b.Backend.InventorySnapshot()
+}
+
+// InventoryDelta satisfies the backendv2.Backend interface.
+func (b *accountingBackend) InventoryDelta(version uint64, pageSize uint32) (*backendv2.InventoryDelta, error) {
+	defer b.start("InventoryDelta", "", "", nil)()
+	return b.Backend.InventoryDelta(version, pageSize)
+}
+
+// PluginRead satisfies the backendv2.Backend interface.
+func (b *accountingBackend) PluginRead(token []byte, pluginID, pluginCmd, payload string) (string, error) {
+	defer b.start("PluginRead", pluginID, pluginCmd, token)()
+	return b.Backend.PluginRead(token, pluginID, pluginCmd, payload)
+}
+
+// PluginWrite satisfies the backendv2.Backend interface.
+func (b *accountingBackend) PluginWrite(ctx context.Context, token []byte, pluginID, pluginCmd, payload string) (string, error) {
+	defer b.start("PluginWrite", pluginID, pluginCmd, token)()
+	return b.Backend.PluginWrite(ctx, token, pluginID, pluginCmd, payload)
+}
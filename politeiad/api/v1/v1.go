@@ -10,6 +10,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"regexp"
+	"strconv"
 
 	"github.com/decred/dcrtime/merkle"
 	"github.com/decred/politeia/politeiad/api/v1/identity"
@@ -117,10 +118,11 @@ var (
 	RegexpSHA256 = regexp.MustCompile("[A-Fa-f0-9]{64}")
 
 	// Verification errors
-	ErrInvalidHex    = errors.New("corrupt hex string")
-	ErrInvalidBase64 = errors.New("corrupt base64")
-	ErrInvalidMerkle = errors.New("merkle roots do not match")
-	ErrCorrupt       = errors.New("signature verification failed")
+	ErrInvalidHex      = errors.New("corrupt hex string")
+	ErrInvalidBase64   = errors.New("corrupt base64")
+	ErrInvalidMerkle   = errors.New("merkle roots do not match")
+	ErrCorrupt         = errors.New("signature verification failed")
+	ErrInvalidRotation = errors.New("identity rotation verification failed")
 
 	// Length of prefix of token used for lookups. The length 7 was selected to
 	// match github's abbreviated hash length This is a var so that it can be
@@ -193,9 +195,45 @@ type Identity struct {
 }
 
 // IdentityReply contains the server public identity.
+//
+// PrevPublicKey, RotationEnd, and RotationSignature are only set while the
+// server identity is being rotated. PrevPublicKey is the previous server
+// public key and RotationEnd is the UNIX time at which the rotation ends.
+// RotationSignature is the signature of the RotationMessage made using the
+// previous identity. Clients that have pinned the previous public key can
+// verify it using VerifyRotation and replace their pinned key with PublicKey.
+// Data that was signed before the rotation remains verifiable using
+// PrevPublicKey.
 type IdentityReply struct {
 	Response  string `json:"response"`  // Signature of Challenge
 	PublicKey string `json:"publickey"` // Public key
+
+	PrevPublicKey     string `json:"prevpublickey,omitempty"`
+	RotationEnd       int64  `json:"rotationend,omitempty"`
+	RotationSignature string `json:"rotationsignature,omitempty"`
+}
+
+// RotationMessage returns the message that is signed by the previous server
+// identity during an identity rotation.
+func RotationMessage(publicKey string, rotationEnd int64) string {
+	return publicKey + strconv.FormatInt(rotationEnd, 10)
+}
+
+// VerifyRotation verifies that the identity rotation of an IdentityReply was
+// signed by the provided previous server identity.
+func VerifyRotation(prev identity.PublicIdentity, ir IdentityReply) error {
+	if ir.PrevPublicKey != prev.String() {
+		return ErrInvalidRotation
+	}
+	sig, err := identity.SignatureFromString(ir.RotationSignature)
+	if err != nil {
+		return ErrInvalidRotation
+	}
+	msg := RotationMessage(ir.PublicKey, ir.RotationEnd)
+	if !prev.VerifyMessage([]byte(msg), *sig) {
+		return ErrInvalidRotation
+	}
+	return nil
 }
 
 // File describes an individual file that is part of the record.  The
@@ -19,12 +19,25 @@ const (
 	RouteRecords            = "/records"
 	RouteInventory          = "/inventory"
 	RouteInventoryOrdered   = "/inventoryordered"
+	RouteInventorySnapshot  = "/inventorysnapshot"
+	RouteInventoryDelta     = "/inventorydelta"
 	RoutePluginWrite        = "/pluginwrite"
 	RoutePluginReads        = "/pluginreads"
 	RoutePluginInventory    = "/plugininventory"
+	RoutePluginSettings     = "/pluginsettings"
+	RouteUsage              = "/usage"
 
 	// ChallengeSize is the size of a request challenge token in bytes.
 	ChallengeSize = 32
+
+	// VersionHeader is the header that contains the politeiad write
+	// version. Every successful write increments the write version. The
+	// header of a write reply contains the version of the write. The
+	// header of a read reply contains the version at the start of the
+	// request, i.e. the reply reflects all writes up to that version.
+	// The version increases across politeiad restarts. It can be used by
+	// clients to determine whether cached data reflects a write.
+	VersionHeader = "X-Politeiad-Version"
 )
 
 // ErrorCodeT represents a user error code.
@@ -53,7 +66,8 @@ const (
 	ErrorCodePageSizeExceeded        ErrorCodeT = 19
 	ErrorCodeRecordStateInvalid      ErrorCodeT = 20
 	ErrorCodeRecordStatusInvalid     ErrorCodeT = 21
-	ErrorCodeLast                    ErrorCodeT = 22
+	ErrorCodeInventoryVersionInvalid ErrorCodeT = 22
+	ErrorCodeLast                    ErrorCodeT = 23
 )
 
 var (
@@ -81,6 +95,7 @@ var (
 		ErrorCodePageSizeExceeded:        "page size exceeded",
 		ErrorCodeRecordStateInvalid:      "record state invalid",
 		ErrorCodeRecordStatusInvalid:     "record status invalid",
+		ErrorCodeInventoryVersionInvalid: "inventory version invalid",
 	}
 )
 
@@ -216,7 +231,8 @@ const (
 	// token that has been shortened to improved UX. Short tokens can
 	// be used to retrieve record data but cannot be used on any routes
 	// that write record data. 7 characters was chosen to match the git
-	// abbreviated commitment hash size.
+	// abbreviated commitment hash size. This is the default length. A
+	// deployment can configure a longer length.
 	ShortTokenLength = 7
 )
 
@@ -458,6 +474,65 @@ type InventoryOrderedReply struct {
 	Tokens   []string `json:"tokens"`
 }
 
+const (
+	// InventoryDeltaPageSize is the maximum number of inventory changes
+	// that will be returned by the InventoryDelta command.
+	InventoryDeltaPageSize uint32 = 500
+)
+
+// InventorySnapshot requests the tokens of all records in the inventory,
+// categorized by record state and record status, along with the current
+// inventory version. Every change to the inventory increments the inventory
+// version. A consumer can use the InventoryDelta command to retrieve the
+// changes that were made since the snapshot was taken instead of retrieving
+// the full inventory again.
+type InventorySnapshot struct {
+	Challenge string `json:"challenge"` // Random challenge
+}
+
+// InventorySnapshotReply is the reply to the InventorySnapshot command. The
+// tokens are ordered by the timestamp of their most recent status change,
+// sorted from newest to oldest. The map keys are the human readable record
+// statuses defined by the RecordStatuses array.
+type InventorySnapshotReply struct {
+	Response string              `json:"response"` // Challenge response
+	Version  uint64              `json:"version"`  // Inventory version
+	Unvetted map[string][]string `json:"unvetted"` // [status][]token
+	Vetted   map[string][]string `json:"vetted"`   // [status][]token
+}
+
+// InventoryChange represents a change to the inventory. The change sets the
+// state and status of the record. A record that moves from unvetted to vetted
+// must be removed from the unvetted inventory.
+type InventoryChange struct {
+	Version   uint64        `json:"version"`   // Inventory version
+	Token     string        `json:"token"`     // Record token
+	State     RecordStateT  `json:"state"`     // New record state
+	Status    RecordStatusT `json:"status"`    // New record status
+	Timestamp int64         `json:"timestamp"` // Unix timestamp of change
+}
+
+// InventoryDelta requests the inventory changes that were made after the
+// provided inventory version. The changes are sorted from oldest to newest
+// and are limited to the InventoryDeltaPageSize. The version of the last
+// returned change can be used to request the next page of changes.
+//
+// Only the most recent changes are retained. An ErrorCodeInventoryVersionInvalid
+// is returned if the changes since the version are no longer retained. The
+// consumer must resync using the InventorySnapshot command when this happens.
+type InventoryDelta struct {
+	Challenge string `json:"challenge"` // Random challenge
+	Version   uint64 `json:"version"`
+}
+
+// InventoryDeltaReply is the reply to the InventoryDelta command. The version
+// is the current inventory version.
+type InventoryDeltaReply struct {
+	Response string            `json:"response"` // Challenge response
+	Version  uint64            `json:"version"`  // Inventory version
+	Changes  []InventoryChange `json:"changes"`
+}
+
 // PluginCmd represents plugin command and the command payload. A token is
 // required for all plugin writes, but is optional for reads.
 type PluginCmd struct {
@@ -531,3 +606,88 @@ type PluginInventoryReply struct {
 	Response string   `json:"response"` // Challenge response
 	Plugins  []Plugin `json:"plugins"`
 }
+
+// PluginSettingDetails describes a plugin setting. It contains the effective
+// value of the setting along with the schema that the setting was validated
+// against when the plugin was registered.
+//
+// Min and Max are inclusive. They bound the value of numeric settings, the
+// length of string settings, and the number of entries in string list
+// settings. A Max of zero indicates that there is no upper bound.
+type PluginSettingDetails struct {
+	Key     string `json:"key"`
+	Type    string `json:"type"`    // Human readable value type
+	Value   string `json:"value"`   // Effective value
+	Default string `json:"default"` // Default value
+	Min     int64  `json:"min"`
+	Max     int64  `json:"max"`
+}
+
+// PluginSettings retrieves the effective settings of all active plugins.
+type PluginSettings struct {
+	Challenge string `json:"challenge"` // Random challenge
+}
+
+// PluginSettingsReply is the reply to the PluginSettings command.
+type PluginSettingsReply struct {
+	Response string                            `json:"response"` // Challenge response
+	Settings map[string][]PluginSettingDetails `json:"settings"` // [pluginID]settings
+}
+
+// Usage retrieves the resource usage of the requests that politeiad has
+// served since it was started, along with the most recent slow requests.
+type Usage struct {
+	Challenge string `json:"challenge"` // Random challenge
+}
+
+// UsageSummary contains the aggregate resource usage of a route or of a
+// plugin command. The plugin ID and command are only set for the usage of a
+// plugin command. Durations are in microseconds.
+//
+// Duration is the wall clock time. BackendTime is the time that was spent in the backend, which includes the tstore,
+// trillian, and key-value store operations.
+type UsageSummary struct {
+	Route       string `json:"route"`
+	PluginID    string `json:"pluginid,omitempty"`
+	Command     string `json:"command,omitempty"`
+	Count       uint64 `json:"count"`
+	Slow        uint64 `json:"slow"`        // Number of slow requests
+	Duration    int64  `json:"duration"`    // Total, in microseconds
+	BackendTime int64  `json:"backendtime"` // Total, in microseconds
+}
+
+// UsageOperation is a backend operation that was performed by a request.
+// The plugin ID and command are only set for plugin commands. The token is
+// only set for operations on a single record.
+type UsageOperation struct {
+	Operation string `json:"operation"`
+	PluginID  string `json:"pluginid,omitempty"`
+	Command   string `json:"command,omitempty"`
+	Token     string `json:"token,omitempty"`
+	Duration  int64  `json:"duration"` // In microseconds
+}
+
+// SlowRequest is a request whose duration exceeded the slow request
+// threshold. Durations are in microseconds.
+type SlowRequest struct {
+	Timestamp   int64            `json:"timestamp"` // Unix time
+	Route       string           `json:"route"`
+	Duration    int64            `json:"duration"`
+	BackendTime int64            `json:"backendtime"`
+	Operations  []UsageOperation `json:"operations"`
+}
+
+// UsageReply is the reply to the Usage command. The usage summaries are
+// sorted by total duration from highest to lowest. The slow requests are
+// sorted from newest to oldest.
+//
+// CPUTime is the user and system CPU time of the politeiad process. CPU time
+// is not attributed to individual requests since a request is served by
+// multiple goroutines, e.g. the trillian and database clients. It is zero on
+// platforms that do not support process CPU accounting.
+type UsageReply struct {
+	Response string         `json:"response"` // Challenge response
+	CPUTime  int64          `json:"cputime"`  // Total, in microseconds
+	Usage    []UsageSummary `json:"usage"`
+	Slow     []SlowRequest  `json:"slow"`
+}
@@ -5,6 +5,7 @@
 package backendv2
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -35,6 +36,11 @@ var (
 	// ErrPluginCmdInvalid is returned when a invalid plugin command is
 	// used.
 	ErrPluginCmdInvalid = errors.New("plugin command invalid")
+
+	// ErrInventoryVersionInvalid is returned when the changes since an
+	// inventory version are requested for a version that does not
+	// exist or whose changes are no longer retained.
+	ErrInventoryVersionInvalid = errors.New("inventory version invalid")
 )
 
 // StateT represents the state of a record.
@@ -250,6 +256,34 @@ type Inventory struct {
 	Vetted   map[StatusT][]string
 }
 
+// InventorySnapshot contains the tokens of all records in the inventory
+// categorized by record state and record status. Tokens are sorted by the
+// timestamp of the status change from newest to oldest. The version is the
+// version of the most recent inventory change that is included in the
+// snapshot.
+type InventorySnapshot struct {
+	Version  uint64
+	Unvetted map[StatusT][]string
+	Vetted   map[StatusT][]string
+}
+
+// InventoryChange represents a change to the inventory. Each change sets the
+// state and status of a record and increments the inventory version.
+type InventoryChange struct {
+	Version   uint64
+	Token     string
+	State     StateT
+	Status    StatusT
+	Timestamp int64 // Unix timestamp of the change
+}
+
+// InventoryDelta contains the inventory changes since an inventory version.
+// The version is the current inventory version.
+type InventoryDelta struct {
+	Version uint64
+	Changes []InventoryChange
+}
+
 // PluginSetting represents a configurable plugin setting.
 //
 // The value can either contain a single value or multiple values. Multiple
@@ -264,6 +298,15 @@ type Plugin struct {
 	ID       string
 	Settings []PluginSetting
 
+	// Schema describes the plugin settings. It is set by the backend
+	// and is used to report the settings that the plugin supports.
+	Schema []PluginSettingSchema
+
+	// Dependencies contains the IDs of the plugins that this plugin
+	// depends on. It is set by the backend. The dependencies are set
+	// up before the plugin.
+	Dependencies []string
+
 	// Identity contains the full identity that the plugin uses to
 	// create receipts, i.e. signatures of user provided data that
 	// prove the backend received and processed a plugin command.
@@ -285,22 +328,27 @@ func (e PluginError) Error() string {
 }
 
 // Backend provides an API for interacting with records in the backend.
+//
+// The methods that write data take a context that is used to trace the
+// writes. The context does not cancel a write that is in progress.
 type Backend interface {
 	// RecordNew creates a new record.
-	RecordNew([]MetadataStream, []File) (*Record, error)
+	RecordNew(ctx context.Context, metadata []MetadataStream,
+		files []File) (*Record, error)
 
 	// RecordEdit edits an existing record.
-	RecordEdit(token []byte, mdAppend, mdOverwrite []MetadataStream,
-		filesAdd []File, filesDel []string) (*Record, error)
+	RecordEdit(ctx context.Context, token []byte, mdAppend,
+		mdOverwrite []MetadataStream, filesAdd []File,
+		filesDel []string) (*Record, error)
 
 	// RecordEditMetadata edits the metadata of a record without
 	// editing any record files.
-	RecordEditMetadata(token []byte, mdAppend,
+	RecordEditMetadata(ctx context.Context, token []byte, mdAppend,
 		mdOverwrite []MetadataStream) (*Record, error)
 
 	// RecordSetStatus sets the status of a record.
-	RecordSetStatus(token []byte, s StatusT, mdAppend,
-		mdOverwrite []MetadataStream) (*Record, error)
+	RecordSetStatus(ctx context.Context, token []byte, s StatusT,
+		mdAppend, mdOverwrite []MetadataStream) (*Record, error)
 
 	// RecordExists returns whether a record exists.
 	RecordExists(token []byte) bool
@@ -333,6 +381,16 @@ type Backend interface {
 	// oldest. The returned tokens will include all record statuses.
 	InventoryOrdered(s StateT, pageSize, pageNumber uint32) ([]string, error)
 
+	// InventorySnapshot returns the tokens of all records in the
+	// inventory along with the current inventory version.
+	InventorySnapshot() (*InventorySnapshot, error)
+
+	// InventoryDelta returns a page of the inventory changes that were
+	// made after the provided inventory version, sorted from oldest to
+	// newest. A ErrInventoryVersionInvalid is returned if the changes
+	// since the version are no longer retained.
+	InventoryDelta(version uint64, pageSize uint32) (*InventoryDelta, error)
+
 	// PluginRegister registers a plugin.
 	PluginRegister(Plugin) error
 
@@ -344,7 +402,7 @@ type Backend interface {
 		payload string) (string, error)
 
 	// PluginWrite executes a plugin command that writes data.
-	PluginWrite(token []byte, pluginID, pluginCmd,
+	PluginWrite(ctx context.Context, token []byte, pluginID, pluginCmd,
 		payload string) (string, error)
 
 	// PluginInventory returns all registered plugins.
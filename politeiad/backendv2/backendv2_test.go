@@ -19,4 +19,9 @@ func TestMaps(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Statuses: %v", err)
 	}
+	err = unittest.TestGenericConstMap(PluginSettingTypes,
+		uint64(PluginSettingTypeLast))
+	if err != nil {
+		t.Fatalf("PluginSettingTypes: %v", err)
+	}
 }
@@ -0,0 +1,185 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package backendv2
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PluginSettingT represents the value type of a plugin setting.
+type PluginSettingT uint32
+
+const (
+	// PluginSettingTypeInvalid is an invalid plugin setting type.
+	PluginSettingTypeInvalid PluginSettingT = 0
+
+	// PluginSettingTypeUint is an unsigned integer plugin setting.
+	PluginSettingTypeUint PluginSettingT = 1
+
+	// PluginSettingTypeInt is a signed integer plugin setting.
+	PluginSettingTypeInt PluginSettingT = 2
+
+	// PluginSettingTypeString is a string plugin setting.
+	PluginSettingTypeString PluginSettingT = 3
+
+	// PluginSettingTypeStringList is a plugin setting that contains
+	// multiple values formatted as a JSON encoded []string.
+	PluginSettingTypeStringList PluginSettingT = 4
+
+	// PluginSettingTypeURL is a plugin setting that contains an
+	// absolute URL.
+	PluginSettingTypeURL PluginSettingT = 5
+
+	// PluginSettingTypeLast is used for unit test only.
+	PluginSettingTypeLast PluginSettingT = 6
+)
+
+var (
+	// PluginSettingTypes contains the human readable plugin setting
+	// types.
+	PluginSettingTypes = map[PluginSettingT]string{
+		PluginSettingTypeInvalid:    "invalid",
+		PluginSettingTypeUint:       "uint",
+		PluginSettingTypeInt:        "int",
+		PluginSettingTypeString:     "string",
+		PluginSettingTypeStringList: "stringlist",
+		PluginSettingTypeURL:        "url",
+	}
+)
+
+// PluginSettingSchema describes a plugin setting. The schema is used to
+// validate the plugin settings that are provided by the operator prior to
+// the plugin being registered.
+//
+// Min and Max are inclusive. They bound the value of numeric settings, the
+// length of string settings, and the number of entries in string list
+// settings. A Max of zero indicates that there is no upper bound. Min and
+// Max are not used for URL settings.
+type PluginSettingSchema struct {
+	Key     string         // Name of setting
+	Type    PluginSettingT // Value type
+	Default string         // Value used when the setting is not provided
+	Min     int64          // Minimum value
+	Max     int64          // Maximum value
+}
+
+// verify verifies that the provided value satisfies the setting schema.
+func (s PluginSettingSchema) verify(value string) error {
+	var (
+		n    int64  // Numeric value or length of the value
+		desc string // Description of the bounded quantity
+	)
+	switch s.Type {
+	case PluginSettingTypeUint:
+		u, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("not a uint")
+		}
+		if u > math.MaxInt64 {
+			return fmt.Errorf("value out of range")
+		}
+		n, desc = int64(u), "value"
+
+	case PluginSettingTypeInt:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("not an int")
+		}
+		n, desc = i, "value"
+
+	case PluginSettingTypeString:
+		n, desc = int64(len(value)), "length"
+
+	case PluginSettingTypeStringList:
+		var sl []string
+		err := json.Unmarshal([]byte(value), &sl)
+		if err != nil {
+			return fmt.Errorf("not a json encoded []string")
+		}
+		n, desc = int64(len(sl)), "entry count"
+
+	case PluginSettingTypeURL:
+		u, err := url.Parse(value)
+		if err != nil {
+			return fmt.Errorf("not a url: %v", err)
+		}
+		if !u.IsAbs() || u.Host == "" {
+			return fmt.Errorf("not an absolute url")
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("invalid setting type %v", s.Type)
+	}
+
+	switch {
+	case n < s.Min:
+		return fmt.Errorf("%v is less than min %v", desc, s.Min)
+	case s.Max != 0 && n > s.Max:
+		return fmt.Errorf("%v exceeds max %v", desc, s.Max)
+	}
+
+	return nil
+}
+
+// PluginSettingsError is returned when the settings that are provided for a
+// plugin do not satisfy the plugin settings schema. It contains every invalid
+// setting so that all misconfigurations can be reported at once.
+type PluginSettingsError struct {
+	PluginID string
+	Errs     []string
+}
+
+// Error satisfies the error interface.
+func (e PluginSettingsError) Error() string {
+	return fmt.Sprintf("invalid %v plugin settings: %v",
+		e.PluginID, strings.Join(e.Errs, "; "))
+}
+
+// PluginSettingsVerify verifies the provided plugin settings against the
+// plugin settings schema. A PluginSettingsError is returned if any of the
+// settings are unknown, duplicated, or invalid.
+func PluginSettingsVerify(pluginID string, schema []PluginSettingSchema, settings []PluginSetting) error {
+	schemas := make(map[string]PluginSettingSchema, len(schema))
+	for _, v := range schema {
+		schemas[v.Key] = v
+	}
+
+	var (
+		errs = make([]string, 0, len(settings))
+		seen = make(map[string]struct{}, len(settings))
+	)
+	for _, v := range settings {
+		s, ok := schemas[v.Key]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("unknown setting '%v'", v.Key))
+			continue
+		}
+		if _, ok := seen[v.Key]; ok {
+			errs = append(errs, fmt.Sprintf("duplicate setting '%v'", v.Key))
+			continue
+		}
+		seen[v.Key] = struct{}{}
+
+		err := s.verify(v.Value)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%v '%v': %v",
+				v.Key, v.Value, err))
+		}
+	}
+	if len(errs) > 0 {
+		return PluginSettingsError{
+			PluginID: pluginID,
+			Errs:     errs,
+		}
+	}
+
+	return nil
+}
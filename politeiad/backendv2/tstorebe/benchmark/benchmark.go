@@ -0,0 +1,214 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package benchmark measures the write throughput of tstore. The benchmarks
+// are run against a local harness and produce a report that can be compared
+// against the report of a previous run in order to catch performance
+// regressions before a release.
+package benchmark
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"runtime"
+	"testing"
+	"time"
+
+	backend "github.com/decred/politeia/politeiad/backendv2"
+	"github.com/decred/politeia/politeiad/backendv2/tstorebe/plugins"
+	"github.com/decred/politeia/politeiad/backendv2/tstorebe/store"
+	"github.com/decred/politeia/politeiad/plugins/ticketvote"
+	"github.com/decred/politeia/politeiad/plugins/usermd"
+)
+
+const (
+	// ballotsPerRecord is the number of votes that are appended to a
+	// record before the ballot benchmark moves on to a new record. A
+	// blob save reads all leaves of the record tree, so the cost of an
+	// append grows with the number of votes. Capping the number of
+	// votes per record keeps the results comparable regardless of how
+	// many iterations the benchmark runs.
+	ballotsPerRecord = 500
+
+	// dataDescriptorCastVoteDetails is the data descriptor that the
+	// ticketvote plugin uses for cast votes.
+	dataDescriptorCastVoteDetails = ticketvote.PluginID + "-castvote-v1"
+)
+
+// Benchmark is a tstore write benchmark.
+type Benchmark struct {
+	Name        string
+	Description string
+	F           func(b *testing.B, h *Harness)
+}
+
+// Benchmarks contains all tstore write benchmarks.
+var Benchmarks = []Benchmark{
+	{
+		Name: "RecordSave",
+		Description: "Create an unvetted record and save its metadata " +
+			"and files",
+		F: benchmarkRecordSave,
+	},
+	{
+		Name:        "PluginHook",
+		Description: "Execute the new record pre plugin hook",
+		F:           benchmarkPluginHook,
+	},
+	{
+		Name:        "BallotAppend",
+		Description: "Append a cast vote to a vetted record",
+		F:           benchmarkBallotAppend,
+	},
+}
+
+// Run runs the benchmarks whose names match the provided regular expression
+// and returns the report. All benchmarks are run when the expression is
+// empty.
+func Run(filter string) (*Report, error) {
+	re, err := regexp.Compile(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	r := Report{
+		Timestamp: time.Now().Unix(),
+		GoVersion: runtime.Version(),
+		GOOS:      runtime.GOOS,
+		GOARCH:    runtime.GOARCH,
+		CPUs:      runtime.NumCPU(),
+		Results:   make([]Result, 0, len(Benchmarks)),
+	}
+	for _, v := range Benchmarks {
+		if !re.MatchString(v.Name) {
+			continue
+		}
+		br := testing.Benchmark(func(b *testing.B) {
+			h, cleanup := NewHarness(b)
+			defer cleanup()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			v.F(b, h)
+		})
+		if br.N == 0 {
+			return nil, fmt.Errorf("benchmark %v failed", v.Name)
+		}
+		r.Results = append(r.Results, newResult(v.Name, br))
+	}
+
+	return &r, nil
+}
+
+// benchmarkRecordSave measures the throughput of creating new records.
+func benchmarkRecordSave(b *testing.B, h *Harness) {
+	b.StopTimer()
+	for i := 0; i < b.N; i++ {
+		metadata, files, err := h.recordContent()
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+		_, err = h.recordSave(backend.StateUnvetted, metadata, files)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.StopTimer()
+	}
+	b.SetBytes(recordFileCount * recordFileSize)
+}
+
+// benchmarkPluginHook measures the throughput of the new record pre hook. The
+// usermd plugin is registered, which verifies the user signature of the
+// record.
+func benchmarkPluginHook(b *testing.B, h *Harness) {
+	err := h.Tstore.PluginRegister(nil, backend.Plugin{
+		ID: usermd.PluginID,
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	metadata, files, err := h.recordContent()
+	if err != nil {
+		b.Fatal(err)
+	}
+	payload, err := json.Marshal(plugins.HookNewRecordPre{
+		Metadata: metadata,
+		Files:    files,
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := h.Tstore.PluginHookPre(plugins.HookTypeNewRecordPre,
+			string(payload))
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkBallotAppend measures the throughput of saving cast votes to a
+// vetted record.
+func benchmarkBallotAppend(b *testing.B, h *Harness) {
+	b.StopTimer()
+	var token []byte
+	for i := 0; i < b.N; i++ {
+		if i%ballotsPerRecord == 0 {
+			metadata, files, err := h.recordContent()
+			if err != nil {
+				b.Fatal(err)
+			}
+			token, err = h.recordSave(backend.StateVetted, metadata, files)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+		be, err := castVoteBlob(token, i)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.StartTimer()
+		err = h.Tstore.BlobSave(context.Background(), token, *be)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.StopTimer()
+	}
+}
+
+// castVoteBlob returns the blob entry of a cast vote. The ticket is derived
+// from the vote number so that every cast vote is unique.
+func castVoteBlob(token []byte, n int) (*store.BlobEntry, error) {
+	ticket := make([]byte, 32)
+	copy(ticket, fmt.Sprintf("%x", n))
+	cv := ticketvote.CastVoteDetails{
+		Token:     hex.EncodeToString(token),
+		Ticket:    hex.EncodeToString(ticket),
+		VoteBit:   "1",
+		Signature: hex.EncodeToString(make([]byte, 65)),
+		Address:   "TsfDLrRkk9ciUuwfp2b8PawwnukYD7yAjGd",
+		Receipt:   hex.EncodeToString(make([]byte, 64)),
+		Timestamp: time.Now().Unix(),
+	}
+	data, err := json.Marshal(cv)
+	if err != nil {
+		return nil, err
+	}
+	hint, err := json.Marshal(store.DataDescriptor{
+		Type:       store.DataTypeStructure,
+		Descriptor: dataDescriptorCastVoteDetails,
+	})
+	if err != nil {
+		return nil, err
+	}
+	be := store.NewBlobEntry(hint, data)
+	return &be, nil
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package benchmark
+
+import (
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	base := Report{
+		Results: []Result{
+			{Name: "a", NsPerOp: 100},
+			{Name: "b", NsPerOp: 100},
+			{Name: "c", NsPerOp: 100},
+		},
+	}
+	current := Report{
+		Results: []Result{
+			{Name: "a", NsPerOp: 109}, // Within threshold
+			{Name: "b", NsPerOp: 150}, // Regression
+			{Name: "d", NsPerOp: 500}, // Not part of base
+		},
+	}
+
+	r := Compare(base, current, 0.1)
+	if len(r) != 1 {
+		t.Fatalf("got %v regressions, want 1", len(r))
+	}
+	if r[0].Name != "b" || r[0].Change != 0.5 {
+		t.Fatalf("got regression %v %v, want b 0.5", r[0].Name, r[0].Change)
+	}
+}
+
+// BenchmarkTstore runs the tstore write benchmarks using go test, e.g.
+// go test -run=XXX -bench=. ./politeiad/backendv2/tstorebe/benchmark
+func BenchmarkTstore(b *testing.B) {
+	for _, v := range Benchmarks {
+		v := v
+		b.Run(v.Name, func(b *testing.B) {
+			h, cleanup := NewHarness(b)
+			defer cleanup()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			v.F(b, h)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package benchmark
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	backend "github.com/decred/politeia/politeiad/backendv2"
+	"github.com/decred/politeia/politeiad/backendv2/tstorebe/tstore"
+	"github.com/decred/politeia/politeiad/plugins/usermd"
+	"github.com/decred/politeia/util"
+	"github.com/google/uuid"
+)
+
+const (
+	// The following settings define the record that is saved by the
+	// benchmarks. They roughly match a proposal with an index file and
+	// a couple of images.
+	recordFileCount = 3
+	recordFileSize  = 16 * 1024 // In bytes
+)
+
+// Harness is a local tstore instance that the benchmarks are run against. It
+// uses the tstore test tlog client and a leveldb key-value store in a
+// temporary directory, so the benchmarks measure the cost of the tstore code
+// and the key-value store without any network overhead.
+type Harness struct {
+	Tstore *tstore.Tstore
+	user   *identity.FullIdentity
+	userID string
+}
+
+// NewHarness returns a new Harness and a closure that removes all harness
+// data when invoked.
+func NewHarness(tb testing.TB) (*Harness, func()) {
+	tb.Helper()
+
+	dir, err := ioutil.TempDir("", "tstorebench")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	user, err := identity.New()
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	h := Harness{
+		Tstore: tstore.NewTestTstore(tb, dir),
+		user:   user,
+		userID: uuid.New().String(),
+	}
+
+	return &h, func() {
+		h.Tstore.Close()
+		err := os.RemoveAll(dir)
+		if err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+// recordContent returns the metadata streams and files of a new record. The
+// files contain random data and the metadata contains a valid user signature
+// of the files merkle root.
+func (h *Harness) recordContent() ([]backend.MetadataStream, []backend.File, error) {
+	files := make([]backend.File, 0, recordFileCount)
+	digests := make([]string, 0, recordFileCount)
+	for i := 0; i < recordFileCount; i++ {
+		b := make([]byte, recordFileSize)
+		_, err := rand.Read(b)
+		if err != nil {
+			return nil, nil, err
+		}
+		d := hex.EncodeToString(util.Digest(b))
+		files = append(files, backend.File{
+			Name:    fmt.Sprintf("file%v.bin", i),
+			MIME:    "application/octet-stream",
+			Digest:  d,
+			Payload: base64.StdEncoding.EncodeToString(b),
+		})
+		digests = append(digests, d)
+	}
+
+	mr, err := util.MerkleRoot(digests)
+	if err != nil {
+		return nil, nil, err
+	}
+	sig := h.user.SignMessage([]byte(hex.EncodeToString(mr[:])))
+	um, err := json.Marshal(usermd.UserMetadata{
+		UserID:    h.userID,
+		PublicKey: h.user.Public.String(),
+		Signature: hex.EncodeToString(sig[:]),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	metadata := []backend.MetadataStream{
+		{
+			PluginID: usermd.PluginID,
+			StreamID: usermd.StreamIDUserMetadata,
+			Payload:  string(um),
+		},
+	}
+
+	return metadata, files, nil
+}
+
+// recordSave creates a new record with the provided state and returns the
+// record token.
+func (h *Harness) recordSave(state backend.StateT, metadata []backend.MetadataStream, files []backend.File) ([]byte, error) {
+	token, err := h.Tstore.RecordNew(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	digests := make([]string, 0, len(files))
+	for _, v := range files {
+		digests = append(digests, v.Digest)
+	}
+	mr, err := util.MerkleRoot(digests)
+	if err != nil {
+		return nil, err
+	}
+	status := backend.StatusUnreviewed
+	if state == backend.StateVetted {
+		status = backend.StatusPublic
+	}
+	rm := backend.RecordMetadata{
+		Token:     hex.EncodeToString(token),
+		Version:   1,
+		Iteration: 1,
+		State:     state,
+		Status:    status,
+		Timestamp: time.Now().Unix(),
+		Merkle:    hex.EncodeToString(mr[:]),
+	}
+
+	err = h.Tstore.RecordSave(context.Background(), token, rm, metadata, files)
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
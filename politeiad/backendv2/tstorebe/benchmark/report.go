@@ -0,0 +1,88 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package benchmark
+
+import (
+	"testing"
+)
+
+// Result contains the result of a single benchmark.
+type Result struct {
+	Name        string  `json:"name"`
+	N           int     `json:"n"`       // Number of iterations
+	NsPerOp     int64   `json:"nsperop"` // Nanoseconds per operation
+	OpsPerSec   float64 `json:"opspersec"`
+	MBPerSec    float64 `json:"mbpersec,omitempty"`
+	AllocsPerOp int64   `json:"allocsperop"`
+	BytesPerOp  int64   `json:"bytesperop"` // Allocated bytes per operation
+}
+
+// newResult converts a testing.BenchmarkResult into a Result.
+func newResult(name string, br testing.BenchmarkResult) Result {
+	r := Result{
+		Name:        name,
+		N:           br.N,
+		NsPerOp:     br.NsPerOp(),
+		AllocsPerOp: br.AllocsPerOp(),
+		BytesPerOp:  br.AllocedBytesPerOp(),
+	}
+	if s := br.T.Seconds(); s > 0 {
+		r.OpsPerSec = float64(br.N) / s
+		r.MBPerSec = float64(br.Bytes) * float64(br.N) / 1e6 / s
+	}
+	return r
+}
+
+// Report contains the results of a benchmark run along with the details of
+// the environment that they were run in. Reports are only comparable when
+// they were created on the same hardware.
+type Report struct {
+	Timestamp int64    `json:"timestamp"` // Unix timestamp
+	GoVersion string   `json:"goversion"`
+	GOOS      string   `json:"goos"`
+	GOARCH    string   `json:"goarch"`
+	CPUs      int      `json:"cpus"`
+	Results   []Result `json:"results"`
+}
+
+// Regression describes a benchmark whose time per operation has increased by
+// more than the allowed threshold.
+type Regression struct {
+	Name    string  `json:"name"`
+	Base    int64   `json:"base"`    // Base nanoseconds per operation
+	Current int64   `json:"current"` // Current nanoseconds per operation
+	Change  float64 `json:"change"`  // Relative change, e.g. 0.25 is +25%
+}
+
+// Compare compares the current report against the base report and returns
+// the benchmarks whose time per operation has increased by more than the
+// threshold. The threshold is relative, e.g. 0.1 allows a 10% slowdown.
+// Benchmarks that are not part of both reports are ignored.
+func Compare(base, current Report, threshold float64) []Regression {
+	results := make(map[string]Result, len(base.Results))
+	for _, v := range base.Results {
+		results[v.Name] = v
+	}
+
+	regressions := make([]Regression, 0, len(current.Results))
+	for _, v := range current.Results {
+		b, ok := results[v.Name]
+		if !ok || b.NsPerOp <= 0 {
+			continue
+		}
+		change := float64(v.NsPerOp-b.NsPerOp) / float64(b.NsPerOp)
+		if change <= threshold {
+			continue
+		}
+		regressions = append(regressions, Regression{
+			Name:    v.Name,
+			Base:    b.NsPerOp,
+			Current: v.NsPerOp,
+			Change:  change,
+		})
+	}
+
+	return regressions
+}
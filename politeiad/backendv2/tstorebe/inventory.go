@@ -12,6 +12,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"time"
 
 	backend "github.com/decred/politeia/politeiad/backendv2"
 )
@@ -20,6 +21,14 @@ const (
 	// Filenames of the inventory caches.
 	filenameInvUnvetted = "inv-unvetted.json"
 	filenameInvVetted   = "inv-vetted.json"
+
+	// filenameInvChanges is the filename of the inventory change log.
+	filenameInvChanges = "inv-changes.json"
+
+	// invChangesMax is the maximum number of inventory changes that are
+	// retained in the change log. Consumers that fall further behind
+	// must resync using an inventory snapshot.
+	invChangesMax = 10000
 )
 
 // entry represents a record entry in the inventory.
@@ -33,6 +42,22 @@ type inventory struct {
 	Entries []entry `json:"entries"`
 }
 
+// invChange represents a change to the inventory.
+type invChange struct {
+	Version   uint64          `json:"version"`
+	Token     string          `json:"token"`
+	State     backend.StateT  `json:"state"`
+	Status    backend.StatusT `json:"status"`
+	Timestamp int64           `json:"timestamp"`
+}
+
+// invChanges contains the most recent inventory changes. Every change to the
+// inventory increments the inventory version.
+type invChanges struct {
+	Version uint64      `json:"version"` // Current inventory version
+	Changes []invChange `json:"changes"` // Sorted from oldest to newest
+}
+
 // invPathUnvetted returns the file path for the unvetted inventory.
 func (t *tstoreBackend) invPathUnvetted() string {
 	return filepath.Join(t.dataDir, filenameInvUnvetted)
@@ -43,6 +68,11 @@ func (t *tstoreBackend) invPathVetted() string {
 	return filepath.Join(t.dataDir, filenameInvVetted)
 }
 
+// invPathChanges returns the file path for the inventory change log.
+func (t *tstoreBackend) invPathChanges() string {
+	return filepath.Join(t.dataDir, filenameInvChanges)
+}
+
 // invGetLocked retrieves the inventory from disk. A new inventory is returned
 // if one does not exist yet.
 //
@@ -91,6 +121,60 @@ func (t *tstoreBackend) invSaveLocked(filePath string, inv inventory) error {
 	return ioutil.WriteFile(filePath, b, 0664)
 }
 
+// invChangesGetLocked retrieves the inventory change log from disk. A new
+// change log is returned if one does not exist yet.
+//
+// This function must be called WITH the read lock held.
+func (t *tstoreBackend) invChangesGetLocked() (*invChanges, error) {
+	b, err := ioutil.ReadFile(t.invPathChanges())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &invChanges{
+				Changes: make([]invChange, 0, 1024),
+			}, nil
+		}
+		return nil, err
+	}
+
+	var ic invChanges
+	err = json.Unmarshal(b, &ic)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ic, nil
+}
+
+// invChangeAddLocked adds a change to the inventory change log and increments
+// the inventory version. The oldest changes are dropped once the change log
+// exceeds the maximum number of changes.
+//
+// This function must be called WITH the read/write lock held.
+func (t *tstoreBackend) invChangeAddLocked(state backend.StateT, token []byte, s backend.StatusT) error {
+	ic, err := t.invChangesGetLocked()
+	if err != nil {
+		return err
+	}
+
+	ic.Version++
+	ic.Changes = append(ic.Changes, invChange{
+		Version:   ic.Version,
+		Token:     hex.EncodeToString(token),
+		State:     state,
+		Status:    s,
+		Timestamp: time.Now().Unix(),
+	})
+	if len(ic.Changes) > invChangesMax {
+		ic.Changes = ic.Changes[len(ic.Changes)-invChangesMax:]
+	}
+
+	b, err := json.Marshal(ic)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(t.invPathChanges(), b, 0664)
+}
+
 // invAdd adds a new record to the inventory.
 //
 // This function must be called WITHOUT the read/write lock held.
@@ -127,6 +211,10 @@ func (t *tstoreBackend) invAdd(state backend.StateT, token []byte, s backend.Sta
 	if err != nil {
 		return err
 	}
+	err = t.invChangeAddLocked(state, token, s)
+	if err != nil {
+		return err
+	}
 
 	log.Debugf("Inv add %v %x %v",
 		backend.States[state], token, backend.Statuses[s])
@@ -177,6 +265,10 @@ func (t *tstoreBackend) invUpdate(state backend.StateT, token []byte, s backend.
 	if err != nil {
 		return err
 	}
+	err = t.invChangeAddLocked(state, token, s)
+	if err != nil {
+		return err
+	}
 
 	log.Debugf("Inv update %v %x to %v",
 		backend.States[state], token, backend.Statuses[s])
@@ -234,6 +326,12 @@ func (t *tstoreBackend) invMoveToVetted(token []byte, s backend.StatusT) error {
 		return fmt.Errorf("vetted invSaveLocked: %v", err)
 	}
 
+	// Add inventory change
+	err = t.invChangeAddLocked(backend.StateVetted, token, s)
+	if err != nil {
+		return fmt.Errorf("invChangeAddLocked: %v", err)
+	}
+
 	log.Debugf("Inv move to vetted %x %v", token, backend.Statuses[s])
 
 	return nil
@@ -432,6 +530,86 @@ func (t *tstoreBackend) invOrdered(state backend.StateT, pageSize, pageNumber ui
 	return tokens, nil
 }
 
+// invSnapshot returns the tokens of all records in the inventory along with
+// the current inventory version.
+func (t *tstoreBackend) invSnapshot() (*backend.InventorySnapshot, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	u, err := t.invGetLocked(t.invPathUnvetted())
+	if err != nil {
+		return nil, fmt.Errorf("unvetted invGetLocked: %v", err)
+	}
+	v, err := t.invGetLocked(t.invPathVetted())
+	if err != nil {
+		return nil, fmt.Errorf("vetted invGetLocked: %v", err)
+	}
+	ic, err := t.invChangesGetLocked()
+	if err != nil {
+		return nil, fmt.Errorf("invChangesGetLocked: %v", err)
+	}
+
+	return &backend.InventorySnapshot{
+		Version:  ic.Version,
+		Unvetted: tokensByStatus(u.Entries),
+		Vetted:   tokensByStatus(v.Entries),
+	}, nil
+}
+
+// invDelta returns a page of the inventory changes that were made after the
+// provided inventory version, sorted from oldest to newest.
+func (t *tstoreBackend) invDelta(version uint64, pageSize uint32) (*backend.InventoryDelta, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	ic, err := t.invChangesGetLocked()
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify the version. The changes since the version must still be
+	// in the change log.
+	switch {
+	case version > ic.Version:
+		return nil, backend.ErrInventoryVersionInvalid
+	case len(ic.Changes) > 0 && version < ic.Changes[0].Version-1:
+		return nil, backend.ErrInventoryVersionInvalid
+	}
+
+	// Compile the page of changes
+	changes := make([]backend.InventoryChange, 0, pageSize)
+	for _, v := range ic.Changes {
+		if v.Version <= version {
+			continue
+		}
+		if len(changes) == int(pageSize) {
+			break
+		}
+		changes = append(changes, backend.InventoryChange{
+			Version:   v.Version,
+			Token:     v.Token,
+			State:     v.State,
+			Status:    v.Status,
+			Timestamp: v.Timestamp,
+		})
+	}
+
+	return &backend.InventoryDelta{
+		Version: ic.Version,
+		Changes: changes,
+	}, nil
+}
+
+// tokensByStatus returns the tokens of the provided entries categorized by
+// record status.
+func tokensByStatus(entries []entry) map[backend.StatusT][]string {
+	tokens := make(map[backend.StatusT][]string, 16)
+	for _, v := range entries {
+		tokens[v.Status] = append(tokens[v.Status], v.Token)
+	}
+	return tokens
+}
+
 // entryDel removes the entry for the token and returns the updated slice.
 func entryDel(entries []entry, token []byte) ([]entry, error) {
 	// Find token in entries
@@ -6,6 +6,7 @@ package comments
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
@@ -31,7 +32,7 @@ const (
 )
 
 // commentAddSave saves a CommentAdd to the backend.
-func (p *commentsPlugin) commentAddSave(token []byte, ca comments.CommentAdd) ([]byte, error) {
+func (p *commentsPlugin) commentAddSave(ctx context.Context, token []byte, ca comments.CommentAdd) ([]byte, error) {
 	be, err := convertBlobEntryFromCommentAdd(ca)
 	if err != nil {
 		return nil, err
@@ -40,7 +41,7 @@ func (p *commentsPlugin) commentAddSave(token []byte, ca comments.CommentAdd) ([
 	if err != nil {
 		return nil, err
 	}
-	err = p.tstore.BlobSave(token, *be)
+	err = p.tstore.BlobSave(ctx, token, *be)
 	if err != nil {
 		return nil, err
 	}
@@ -82,7 +83,7 @@ func (p *commentsPlugin) commentAdds(token []byte, digests [][]byte) ([]comments
 }
 
 // commentDelSave saves a CommentDel to the backend.
-func (p *commentsPlugin) commentDelSave(token []byte, cd comments.CommentDel) ([]byte, error) {
+func (p *commentsPlugin) commentDelSave(ctx context.Context, token []byte, cd comments.CommentDel) ([]byte, error) {
 	be, err := convertBlobEntryFromCommentDel(cd)
 	if err != nil {
 		return nil, err
@@ -91,7 +92,7 @@ func (p *commentsPlugin) commentDelSave(token []byte, cd comments.CommentDel) ([
 	if err != nil {
 		return nil, err
 	}
-	err = p.tstore.BlobSave(token, *be)
+	err = p.tstore.BlobSave(ctx, token, *be)
 	if err != nil {
 		return nil, err
 	}
@@ -133,7 +134,7 @@ func (p *commentsPlugin) commentDels(token []byte, digests [][]byte) ([]comments
 }
 
 // commentVoteSave saves a CommentVote to the backend.
-func (p *commentsPlugin) commentVoteSave(token []byte, cv comments.CommentVote) ([]byte, error) {
+func (p *commentsPlugin) commentVoteSave(ctx context.Context, token []byte, cv comments.CommentVote) ([]byte, error) {
 	be, err := convertBlobEntryFromCommentVote(cv)
 	if err != nil {
 		return nil, err
@@ -142,7 +143,7 @@ func (p *commentsPlugin) commentVoteSave(token []byte, cv comments.CommentVote)
 	if err != nil {
 		return nil, err
 	}
-	err = p.tstore.BlobSave(token, *be)
+	err = p.tstore.BlobSave(ctx, token, *be)
 	if err != nil {
 		return nil, err
 	}
@@ -268,6 +269,22 @@ func (p *commentsPlugin) comment(token []byte, ridx recordIndex, commentID uint3
 	return &c, nil
 }
 
+// commentDepth returns the nesting depth of a comment. A base level comment
+// has a depth of 1. The parent comments are walked until a base level comment
+// or the limit is reached, so the returned depth is capped at the limit.
+func (p *commentsPlugin) commentDepth(token []byte, ridx recordIndex, commentID, limit uint32) (uint32, error) {
+	var depth uint32
+	for commentID > 0 && depth < limit {
+		c, err := p.comment(token, ridx, commentID)
+		if err != nil {
+			return 0, err
+		}
+		depth++
+		commentID = c.ParentID
+	}
+	return depth, nil
+}
+
 // timestamp returns the timestamp for a blob entry digest.
 func (p *commentsPlugin) timestamp(token []byte, digest []byte) (*comments.Timestamp, error) {
 	// Get timestamp
@@ -427,7 +444,7 @@ func voteScore(cidx commentIndex) (uint64, uint64) {
 }
 
 // cmdNew creates a new comment.
-func (p *commentsPlugin) cmdNew(token []byte, payload string) (string, error) {
+func (p *commentsPlugin) cmdNew(ctx context.Context, token []byte, payload string) (string, error) {
 	// Decode payload
 	var n comments.New
 	err := json.Unmarshal([]byte(payload), &n)
@@ -444,6 +461,9 @@ func (p *commentsPlugin) cmdNew(token []byte, payload string) (string, error) {
 	// Verify signature
 	msg := strconv.FormatUint(uint64(n.State), 10) + n.Token +
 		strconv.FormatUint(uint64(n.ParentID), 10) + n.Comment
+	if n.Nonce != "" {
+		msg += n.Nonce + strconv.FormatInt(n.Expiry, 10)
+	}
 	err = util.VerifySignature(n.Signature, n.PublicKey, msg)
 	if err != nil {
 		return "", convertSignatureError(err)
@@ -488,26 +508,44 @@ func (p *commentsPlugin) cmdNew(token []byte, payload string) (string, error) {
 		}
 	}
 
+	// Verify the reply does not exceed the max comment depth
+	if n.ParentID > 0 && p.commentDepthMax > 0 {
+		depth, err := p.commentDepth(token, *ridx, n.ParentID,
+			p.commentDepthMax)
+		if err != nil {
+			return "", err
+		}
+		if depth >= p.commentDepthMax {
+			return "", backend.PluginError{
+				PluginID:  comments.PluginID,
+				ErrorCode: uint32(comments.ErrorCodeDepthMaxExceeded),
+				ErrorContext: fmt.Sprintf("max depth is %v",
+					p.commentDepthMax),
+			}
+		}
+	}
+
 	// Setup comment
 	receipt := p.identity.SignMessage([]byte(n.Signature))
 	ca := comments.CommentAdd{
-		UserID:        n.UserID,
-		State:         n.State,
-		Token:         n.Token,
-		ParentID:      n.ParentID,
-		Comment:       n.Comment,
-		PublicKey:     n.PublicKey,
-		Signature:     n.Signature,
-		CommentID:     commentIDLatest(*ridx) + 1,
-		Version:       1,
-		Timestamp:     time.Now().Unix(),
-		Receipt:       hex.EncodeToString(receipt[:]),
-		ExtraData:     n.ExtraData,
-		ExtraDataHint: n.ExtraDataHint,
+		UserID:           n.UserID,
+		State:            n.State,
+		Token:            n.Token,
+		ParentID:         n.ParentID,
+		Comment:          n.Comment,
+		PublicKey:        n.PublicKey,
+		Signature:        n.Signature,
+		CommentID:        commentIDLatest(*ridx) + 1,
+		Version:          1,
+		Timestamp:        time.Now().Unix(),
+		Receipt:          hex.EncodeToString(receipt[:]),
+		ExtraData:        n.ExtraData,
+		ExtraDataHint:    n.ExtraDataHint,
+		ReplayProtection: n.ReplayProtection,
 	}
 
 	// Save comment
-	digest, err := p.commentAddSave(token, ca)
+	digest, err := p.commentAddSave(ctx, token, ca)
 	if err != nil {
 		return "", fmt.Errorf("commentAddSave: %v", err)
 	}
@@ -546,7 +584,7 @@ func (p *commentsPlugin) cmdNew(token []byte, payload string) (string, error) {
 }
 
 // cmdEdit edits an existing comment.
-func (p *commentsPlugin) cmdEdit(token []byte, payload string) (string, error) {
+func (p *commentsPlugin) cmdEdit(ctx context.Context, token []byte, payload string) (string, error) {
 	// Decode payload
 	var e comments.Edit
 	err := json.Unmarshal([]byte(payload), &e)
@@ -655,7 +693,7 @@ func (p *commentsPlugin) cmdEdit(token []byte, payload string) (string, error) {
 	}
 
 	// Save comment
-	digest, err := p.commentAddSave(token, ca)
+	digest, err := p.commentAddSave(ctx, token, ca)
 	if err != nil {
 		return "", fmt.Errorf("commentAddSave: %v", err)
 	}
@@ -688,7 +726,7 @@ func (p *commentsPlugin) cmdEdit(token []byte, payload string) (string, error) {
 }
 
 // cmdDel deletes a comment.
-func (p *commentsPlugin) cmdDel(token []byte, payload string) (string, error) {
+func (p *commentsPlugin) cmdDel(ctx context.Context, token []byte, payload string) (string, error) {
 	// Decode payload
 	var d comments.Del
 	err := json.Unmarshal([]byte(payload), &d)
@@ -758,7 +796,7 @@ func (p *commentsPlugin) cmdDel(token []byte, payload string) (string, error) {
 	}
 
 	// Save comment del
-	digest, err := p.commentDelSave(token, cd)
+	digest, err := p.commentDelSave(ctx, token, cd)
 	if err != nil {
 		return "", fmt.Errorf("commentDelSave: %v", err)
 	}
@@ -784,7 +822,7 @@ func (p *commentsPlugin) cmdDel(token []byte, payload string) (string, error) {
 	for _, v := range cidx.Adds {
 		digests = append(digests, v)
 	}
-	err = p.tstore.BlobsDel(token, digests)
+	err = p.tstore.BlobsDel(ctx, token, digests)
 	if err != nil {
 		log.Errorf("comments cmdDel %x: BlobsDel %x: %v ",
 			token, digests, err)
@@ -809,7 +847,7 @@ func (p *commentsPlugin) cmdDel(token []byte, payload string) (string, error) {
 }
 
 // cmdVote casts a upvote/downvote for a comment.
-func (p *commentsPlugin) cmdVote(token []byte, payload string) (string, error) {
+func (p *commentsPlugin) cmdVote(ctx context.Context, token []byte, payload string) (string, error) {
 	// Decode payload
 	var v comments.Vote
 	err := json.Unmarshal([]byte(payload), &v)
@@ -838,6 +876,9 @@ func (p *commentsPlugin) cmdVote(token []byte, payload string) (string, error) {
 	msg := strconv.FormatUint(uint64(v.State), 10) + v.Token +
 		strconv.FormatUint(uint64(v.CommentID), 10) +
 		strconv.FormatInt(int64(v.Vote), 10)
+	if v.Nonce != "" {
+		msg += v.Nonce + strconv.FormatInt(v.Expiry, 10)
+	}
 	err = util.VerifySignature(v.Signature, v.PublicKey, msg)
 	if err != nil {
 		return "", convertSignatureError(err)
@@ -899,19 +940,20 @@ func (p *commentsPlugin) cmdVote(token []byte, payload string) (string, error) {
 	// Prepare comment vote
 	receipt := p.identity.SignMessage([]byte(v.Signature))
 	cv := comments.CommentVote{
-		UserID:    v.UserID,
-		State:     v.State,
-		Token:     v.Token,
-		CommentID: v.CommentID,
-		Vote:      v.Vote,
-		PublicKey: v.PublicKey,
-		Signature: v.Signature,
-		Timestamp: time.Now().Unix(),
-		Receipt:   hex.EncodeToString(receipt[:]),
+		UserID:           v.UserID,
+		State:            v.State,
+		Token:            v.Token,
+		CommentID:        v.CommentID,
+		Vote:             v.Vote,
+		PublicKey:        v.PublicKey,
+		Signature:        v.Signature,
+		Timestamp:        time.Now().Unix(),
+		Receipt:          hex.EncodeToString(receipt[:]),
+		ReplayProtection: v.ReplayProtection,
 	}
 
 	// Save comment vote
-	digest, err := p.commentVoteSave(token, cv)
+	digest, err := p.commentVoteSave(ctx, token, cv)
 	if err != nil {
 		return "", fmt.Errorf("commentVoteSave: %v", err)
 	}
@@ -1277,23 +1319,24 @@ func commentIDLatest(idx recordIndex) uint32 {
 
 func convertCommentFromCommentAdd(ca comments.CommentAdd) comments.Comment {
 	return comments.Comment{
-		UserID:        ca.UserID,
-		State:         ca.State,
-		Token:         ca.Token,
-		ParentID:      ca.ParentID,
-		Comment:       ca.Comment,
-		PublicKey:     ca.PublicKey,
-		Signature:     ca.Signature,
-		CommentID:     ca.CommentID,
-		Version:       ca.Version,
-		Timestamp:     ca.Timestamp,
-		Receipt:       ca.Receipt,
-		Downvotes:     0, // Not part of commentAdd data
-		Upvotes:       0, // Not part of commentAdd data
-		Deleted:       false,
-		Reason:        "",
-		ExtraData:     ca.ExtraData,
-		ExtraDataHint: ca.ExtraDataHint,
+		UserID:           ca.UserID,
+		State:            ca.State,
+		Token:            ca.Token,
+		ParentID:         ca.ParentID,
+		Comment:          ca.Comment,
+		PublicKey:        ca.PublicKey,
+		Signature:        ca.Signature,
+		CommentID:        ca.CommentID,
+		Version:          ca.Version,
+		Timestamp:        ca.Timestamp,
+		Receipt:          ca.Receipt,
+		Downvotes:        0, // Not part of commentAdd data
+		Upvotes:          0, // Not part of commentAdd data
+		Deleted:          false,
+		Reason:           "",
+		ExtraData:        ca.ExtraData,
+		ExtraDataHint:    ca.ExtraDataHint,
+		ReplayProtection: ca.ReplayProtection,
 	}
 }
 
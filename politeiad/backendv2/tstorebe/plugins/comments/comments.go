@@ -5,7 +5,9 @@
 package comments
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -42,6 +44,7 @@ type commentsPlugin struct {
 	// Plugin settings
 	commentLengthMax uint32
 	voteChangesMax   uint32
+	commentDepthMax  uint32
 }
 
 // Setup performs any plugin setup that is required.
@@ -56,18 +59,18 @@ func (p *commentsPlugin) Setup() error {
 // Cmd executes a plugin command.
 //
 // This function satisfies the plugins PluginClient interface.
-func (p *commentsPlugin) Cmd(token []byte, cmd, payload string) (string, error) {
+func (p *commentsPlugin) Cmd(ctx context.Context, token []byte, cmd, payload string) (string, error) {
 	log.Tracef("comments Cmd: %x %v %v", token, cmd, payload)
 
 	switch cmd {
 	case comments.CmdNew:
-		return p.cmdNew(token, payload)
+		return p.cmdNew(ctx, token, payload)
 	case comments.CmdEdit:
-		return p.cmdEdit(token, payload)
+		return p.cmdEdit(ctx, token, payload)
 	case comments.CmdDel:
-		return p.cmdDel(token, payload)
+		return p.cmdDel(ctx, token, payload)
 	case comments.CmdVote:
-		return p.cmdVote(token, payload)
+		return p.cmdVote(ctx, token, payload)
 	case comments.CmdGet:
 		return p.cmdGet(token, payload)
 	case comments.CmdGetAll:
@@ -121,14 +124,71 @@ func (p *commentsPlugin) Settings() []backend.PluginSetting {
 			Key:   comments.SettingKeyVoteChangesMax,
 			Value: strconv.FormatUint(uint64(p.voteChangesMax), 10),
 		},
+		{
+			Key:   comments.SettingKeyCommentDepthMax,
+			Value: strconv.FormatUint(uint64(p.commentDepthMax), 10),
+		},
+	}
+}
+
+// SettingsSchema returns the schema of the plugin settings.
+//
+// This function satisfies the plugins PluginClient interface.
+func (p *commentsPlugin) SettingsSchema() []backend.PluginSettingSchema {
+	log.Tracef("comments SettingsSchema")
+
+	return settingsSchema()
+}
+
+// Dependencies returns the IDs of the plugins that this plugin depends on. The
+// comments plugin does not depend on any other plugins.
+//
+// This function satisfies the plugins PluginClient interface.
+func (p *commentsPlugin) Dependencies() []string {
+	log.Tracef("comments Dependencies")
+
+	return nil
+}
+
+// settingsSchema returns the schema of the comments plugin settings.
+func settingsSchema() []backend.PluginSettingSchema {
+	return []backend.PluginSettingSchema{
+		{
+			Key:     comments.SettingKeyCommentLengthMax,
+			Type:    backend.PluginSettingTypeUint,
+			Default: strconv.FormatUint(uint64(comments.SettingCommentLengthMax), 10),
+			Min:     1,
+			Max:     math.MaxUint32,
+		},
+		{
+			Key:     comments.SettingKeyVoteChangesMax,
+			Type:    backend.PluginSettingTypeUint,
+			Default: strconv.FormatUint(uint64(comments.SettingVoteChangesMax), 10),
+			Min:     1,
+			Max:     math.MaxUint32,
+		},
+		{
+			Key:     comments.SettingKeyCommentDepthMax,
+			Type:    backend.PluginSettingTypeUint,
+			Default: strconv.FormatUint(uint64(comments.SettingCommentDepthMax), 10),
+			Min:     0,
+			Max:     math.MaxUint32,
+		},
 	}
 }
 
 // New returns a new comments plugin.
 func New(tstore plugins.TstoreClient, settings []backend.PluginSetting, dataDir string, id *identity.FullIdentity) (*commentsPlugin, error) {
+	// Verify the plugin settings
+	err := backend.PluginSettingsVerify(comments.PluginID,
+		settingsSchema(), settings)
+	if err != nil {
+		return nil, err
+	}
+
 	// Setup comments plugin data dir
 	dataDir = filepath.Join(dataDir, comments.PluginID)
-	err := os.MkdirAll(dataDir, 0700)
+	err = os.MkdirAll(dataDir, 0700)
 	if err != nil {
 		return nil, err
 	}
@@ -137,6 +197,7 @@ func New(tstore plugins.TstoreClient, settings []backend.PluginSetting, dataDir
 	var (
 		commentLengthMax = comments.SettingCommentLengthMax
 		voteChangesMax   = comments.SettingVoteChangesMax
+		commentDepthMax  = comments.SettingCommentDepthMax
 	)
 
 	// Override defaults with any passed in settings
@@ -156,6 +217,13 @@ func New(tstore plugins.TstoreClient, settings []backend.PluginSetting, dataDir
 					v.Key, v.Value, err)
 			}
 			voteChangesMax = uint32(u)
+		case comments.SettingKeyCommentDepthMax:
+			u, err := strconv.ParseUint(v.Value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid plugin setting %v '%v': %v",
+					v.Key, v.Value, err)
+			}
+			commentDepthMax = uint32(u)
 		default:
 			return nil, fmt.Errorf("invalid comments plugin setting '%v'", v.Key)
 		}
@@ -167,5 +235,6 @@ func New(tstore plugins.TstoreClient, settings []backend.PluginSetting, dataDir
 		dataDir:          dataDir,
 		commentLengthMax: commentLengthMax,
 		voteChangesMax:   voteChangesMax,
+		commentDepthMax:  commentDepthMax,
 	}, nil
 }
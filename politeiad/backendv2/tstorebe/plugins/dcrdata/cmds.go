@@ -19,52 +19,30 @@ import (
 	"github.com/decred/politeia/util"
 )
 
-// cmdBestBlock returns the best block. If the dcrdata websocket has been
-// disconnected the best block will be fetched from the dcrdata HTTP API. If
-// dcrdata cannot be reached then the most recent cached best block will be
-// returned along with a status of StatusDisconnected. It is the callers
-// responsibility to determine if the stale best block should be used.
+// cmdBestBlock returns the best block. The best block is requested from the
+// plugin's best block oracle, which fails over between the dcrdata websocket,
+// the dcrdata HTTP hosts, and the dcrd RPC host. If none of them can be
+// reached then the most recent best block will be returned along with a
+// status of StatusDisconnected. It is the callers responsibility to determine
+// if the stale best block should be used.
 func (p *dcrdataPlugin) cmdBestBlock(payload string) (string, error) {
 	// Payload is empty. Nothing to decode.
 
-	// Get the cached best block
-	bb := p.bestBlockGet()
-	var (
-		fetch  bool
-		stale  uint32
-		status = dcrdata.StatusConnected
-	)
-	switch {
-	case bb == 0:
-		// No cached best block means that the best block has not been
-		// populated by the websocket yet. Fetch is manually.
-		fetch = true
-	case p.bestBlockIsStale():
-		// The cached best block has been populated by the websocket, but
-		// the websocket is currently disconnected and the cached value
-		// is stale. Try to fetch the best block manually and only use
-		// the stale value if manually fetching it fails.
-		fetch = true
-		stale = bb
-	}
-
-	// Fetch the best block manually if required
-	if fetch {
-		block, err := p.bestBlockHTTP()
-		switch {
-		case err == nil:
-			// We got the best block. Use it.
-			bb = block.Height
-		case stale != 0:
-			// Unable to fetch the best block manually. Use the stale
-			// value and mark the connection status as disconnected.
-			bb = stale
-			status = dcrdata.StatusDisconnected
-		default:
-			// Unable to fetch the best block manually and there is no
-			// stale cached value to return.
-			return "", fmt.Errorf("bestBlockHTTP: %v", err)
+	status := dcrdata.StatusConnected
+	bb, err := p.oracle.bestBlock()
+	if err != nil {
+		// Unable to fetch the best block from any of the sources. Use
+		// the most recent best block if there is one and mark the
+		// connection status as disconnected. The stale websocket value
+		// is used if the oracle has not returned a best block yet.
+		bb = p.oracle.lastBestBlock()
+		if bb == 0 {
+			bb = p.bestBlockGet()
 		}
+		if bb == 0 {
+			return "", fmt.Errorf("bestBlock: %v", err)
+		}
+		status = dcrdata.StatusDisconnected
 	}
 
 	// Prepare reply
@@ -168,8 +146,14 @@ func (p *dcrdataPlugin) cmdTxsTrimmed(payload string) (string, error) {
 // slice of the response body. An error is returned if dcrdata responds with
 // anything other than a 200 http status code.
 func (p *dcrdataPlugin) makeReq(method string, route string, headers map[string]string, v interface{}) ([]byte, error) {
+	return makeReq(p.client, p.hostHTTP, method, route, headers, v)
+}
+
+// makeReq makes a http request to the provided dcrdata host using the
+// provided client. See the dcrdataPlugin makeReq method for details.
+func makeReq(client *http.Client, host, method, route string, headers map[string]string, v interface{}) ([]byte, error) {
 	var (
-		url     = p.hostHTTP + route
+		url     = host + route
 		reqBody []byte
 		err     error
 	)
@@ -192,7 +176,7 @@ func (p *dcrdataPlugin) makeReq(method string, route string, headers map[string]
 	}
 
 	// Send request
-	r, err := p.client.Do(req)
+	r, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -212,22 +196,6 @@ func (p *dcrdataPlugin) makeReq(method string, route string, headers map[string]
 	return util.RespBody(r), nil
 }
 
-// bestBlockHTTP fetches and returns the best block from the dcrdata http API.
-func (p *dcrdataPlugin) bestBlockHTTP() (*types.BlockDataBasic, error) {
-	resBody, err := p.makeReq(http.MethodGet, routeBestBlock, nil, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	var bdb types.BlockDataBasic
-	err = json.Unmarshal(resBody, &bdb)
-	if err != nil {
-		return nil, err
-	}
-
-	return &bdb, nil
-}
-
 // blockDetails returns the block details for the block at the specified block
 // height.
 func (p *dcrdataPlugin) blockDetails(height uint32) (*types.BlockDataBasic, error) {
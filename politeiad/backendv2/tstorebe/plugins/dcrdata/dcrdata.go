@@ -5,8 +5,11 @@
 package dcrdata
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"sync"
 
 	"github.com/decred/dcrd/chaincfg/v3"
@@ -49,8 +52,17 @@ type dcrdataPlugin struct {
 	ws              *wsdcrdata.Client
 
 	// Plugin settings
-	hostHTTP string // dcrdata HTTP host
-	hostWS   string // dcrdata websocket host
+	hostHTTP         string   // dcrdata HTTP host
+	hostWS           string   // dcrdata websocket host
+	hostHTTPFallback []string // Fallback dcrdata HTTP hosts
+	dcrdRPCHost      string   // dcrd RPC host
+	dcrdRPCUser      string   // dcrd RPC username
+	dcrdRPCPass      string   // dcrd RPC password
+	dcrdRPCCert      string   // dcrd RPC TLS certificate path
+
+	// schema is the schema of the plugin settings. The setting
+	// defaults depend on the active network.
+	schema []backend.PluginSettingSchema
 
 	// bestBlock is the cached best block height. This field is kept up
 	// to date by the websocket connection. If the websocket connection
@@ -59,6 +71,12 @@ type dcrdataPlugin struct {
 	// a new best block message is received.
 	bestBlock      uint32
 	bestBlockStale bool
+
+	// oracle provides the best block. It fails over between the
+	// websocket cached best block, the dcrdata HTTP hosts, and the
+	// dcrd RPC host so that the best block does not depend on a
+	// single dcrdata instance being reachable.
+	oracle *failoverOracle
 }
 
 // bestBlockGet returns the cached best block.
@@ -194,7 +212,7 @@ func (p *dcrdataPlugin) Setup() error {
 // Cmd executes a plugin command.
 //
 // This function satisfies the plugins PluginClient interface.
-func (p *dcrdataPlugin) Cmd(token []byte, cmd, payload string) (string, error) {
+func (p *dcrdataPlugin) Cmd(ctx context.Context, token []byte, cmd, payload string) (string, error) {
 	log.Tracef("dcrdata Cmd: %x %v %v", token, cmd, payload)
 
 	switch cmd {
@@ -229,20 +247,123 @@ func (p *dcrdataPlugin) Fsck() error {
 	return nil
 }
 
-// Settings returns the plugin's settings.
+// Settings returns the plugin's settings. The dcrd RPC password is not
+// included.
 //
 // This function satisfies the plugins PluginClient interface.
 func (p *dcrdataPlugin) Settings() []backend.PluginSetting {
 	log.Tracef("dcrdata Settings")
 
+	return []backend.PluginSetting{
+		{
+			Key:   dcrdata.SettingKeyHostHTTP,
+			Value: p.hostHTTP,
+		},
+		{
+			Key:   dcrdata.SettingKeyHostWS,
+			Value: p.hostWS,
+		},
+		{
+			Key:   dcrdata.SettingKeyHostHTTPFallback,
+			Value: encodeStringList(p.hostHTTPFallback),
+		},
+		{
+			Key:   dcrdata.SettingKeyDcrdRPCHost,
+			Value: p.dcrdRPCHost,
+		},
+		{
+			Key:   dcrdata.SettingKeyDcrdRPCUser,
+			Value: p.dcrdRPCUser,
+		},
+		{
+			Key:   dcrdata.SettingKeyDcrdRPCCert,
+			Value: p.dcrdRPCCert,
+		},
+	}
+}
+
+// SettingsSchema returns the schema of the plugin settings.
+//
+// This function satisfies the plugins PluginClient interface.
+func (p *dcrdataPlugin) SettingsSchema() []backend.PluginSettingSchema {
+	log.Tracef("dcrdata SettingsSchema")
+
+	return p.schema
+}
+
+// Dependencies returns the IDs of the plugins that this plugin depends on. The
+// dcrdata plugin does not depend on any other plugins.
+//
+// This function satisfies the plugins PluginClient interface.
+func (p *dcrdataPlugin) Dependencies() []string {
+	log.Tracef("dcrdata Dependencies")
+
 	return nil
 }
 
+// settingsSchema returns the schema of the dcrdata plugin settings using the
+// provided default values.
+func settingsSchema(hostHTTP, hostWS string) []backend.PluginSettingSchema {
+	return []backend.PluginSettingSchema{
+		{
+			Key:     dcrdata.SettingKeyHostHTTP,
+			Type:    backend.PluginSettingTypeURL,
+			Default: hostHTTP,
+		},
+		{
+			Key:     dcrdata.SettingKeyHostWS,
+			Type:    backend.PluginSettingTypeURL,
+			Default: hostWS,
+		},
+		{
+			Key:     dcrdata.SettingKeyHostHTTPFallback,
+			Type:    backend.PluginSettingTypeStringList,
+			Default: dcrdata.SettingHostHTTPFallback,
+		},
+		{
+			Key:     dcrdata.SettingKeyDcrdRPCHost,
+			Type:    backend.PluginSettingTypeString,
+			Default: dcrdata.SettingDcrdRPCHost,
+		},
+		{
+			Key:  dcrdata.SettingKeyDcrdRPCUser,
+			Type: backend.PluginSettingTypeString,
+		},
+		{
+			Key:  dcrdata.SettingKeyDcrdRPCPass,
+			Type: backend.PluginSettingTypeString,
+		},
+		{
+			Key:  dcrdata.SettingKeyDcrdRPCCert,
+			Type: backend.PluginSettingTypeString,
+		},
+	}
+}
+
+// encodeStringList returns the JSON encoded string list plugin setting value
+// of the provided list.
+func encodeStringList(l []string) string {
+	if l == nil {
+		l = []string{}
+	}
+	b, err := json.Marshal(l)
+	if err != nil {
+		// Encoding a []string can't fail
+		panic(err)
+	}
+	return string(b)
+}
+
 func New(settings []backend.PluginSetting, activeNetParams *chaincfg.Params) (*dcrdataPlugin, error) {
 	// Plugin setting
 	var (
-		hostHTTP string
-		hostWS   string
+		hostHTTP         string
+		hostWS           string
+		hostHTTPFallback []string
+		dcrdRPCHost      string
+		dcrdRPCUser      string
+		dcrdRPCPass      string
+		dcrdRPCCert      string
 	)
 
 	// Set plugin settings to defaults. These will be overwritten if
@@ -258,6 +379,13 @@ func New(settings []backend.PluginSetting, activeNetParams *chaincfg.Params) (*d
 		return nil, fmt.Errorf("unknown active net: %v", activeNetParams.Name)
 	}
 
+	// Verify the plugin settings
+	schema := settingsSchema(hostHTTP, hostWS)
+	err := backend.PluginSettingsVerify(dcrdata.PluginID, schema, settings)
+	if err != nil {
+		return nil, err
+	}
+
 	// Override defaults with any passed in settings
 	for _, v := range settings {
 		switch v.Key {
@@ -271,6 +399,42 @@ func New(settings []backend.PluginSetting, activeNetParams *chaincfg.Params) (*d
 			log.Infof("Plugin setting updated: dcrdata %v %v",
 				dcrdata.SettingKeyHostWS, hostWS)
 
+		case dcrdata.SettingKeyHostHTTPFallback:
+			err := json.Unmarshal([]byte(v.Value), &hostHTTPFallback)
+			if err != nil {
+				return nil, err
+			}
+			for _, h := range hostHTTPFallback {
+				u, err := url.Parse(h)
+				if err != nil || !u.IsAbs() || u.Host == "" {
+					return nil, fmt.Errorf("invalid plugin setting %v: "+
+						"'%v' is not an absolute url",
+						dcrdata.SettingKeyHostHTTPFallback, h)
+				}
+			}
+			log.Infof("Plugin setting updated: dcrdata %v %v",
+				dcrdata.SettingKeyHostHTTPFallback, hostHTTPFallback)
+
+		case dcrdata.SettingKeyDcrdRPCHost:
+			dcrdRPCHost = v.Value
+			log.Infof("Plugin setting updated: dcrdata %v %v",
+				dcrdata.SettingKeyDcrdRPCHost, dcrdRPCHost)
+
+		case dcrdata.SettingKeyDcrdRPCUser:
+			dcrdRPCUser = v.Value
+			log.Infof("Plugin setting updated: dcrdata %v %v",
+				dcrdata.SettingKeyDcrdRPCUser, dcrdRPCUser)
+
+		case dcrdata.SettingKeyDcrdRPCPass:
+			dcrdRPCPass = v.Value
+			log.Infof("Plugin setting updated: dcrdata %v",
+				dcrdata.SettingKeyDcrdRPCPass)
+
+		case dcrdata.SettingKeyDcrdRPCCert:
+			dcrdRPCCert = util.CleanAndExpandPath(v.Value)
+			log.Infof("Plugin setting updated: dcrdata %v %v",
+				dcrdata.SettingKeyDcrdRPCCert, dcrdRPCCert)
+
 		default:
 			return nil, fmt.Errorf("invalid plugin setting '%v'", v.Key)
 		}
@@ -291,11 +455,57 @@ func New(settings []backend.PluginSetting, activeNetParams *chaincfg.Params) (*d
 		log.Errorf("wsdcrdata New: %v", err)
 	}
 
-	return &dcrdataPlugin{
-		activeNetParams: activeNetParams,
-		client:          client,
-		ws:              ws,
-		hostHTTP:        hostHTTP,
-		hostWS:          hostWS,
-	}, nil
+	p := dcrdataPlugin{
+		activeNetParams:  activeNetParams,
+		client:           client,
+		ws:               ws,
+		hostHTTP:         hostHTTP,
+		hostWS:           hostWS,
+		hostHTTPFallback: hostHTTPFallback,
+		dcrdRPCHost:      dcrdRPCHost,
+		dcrdRPCUser:      dcrdRPCUser,
+		dcrdRPCPass:      dcrdRPCPass,
+		dcrdRPCCert:      dcrdRPCCert,
+		schema:           schema,
+	}
+
+	// Setup the best block oracle. The best block sources are tried in
+	// the following order: the dcrdata websocket, the dcrdata HTTP
+	// host, the fallback dcrdata HTTP hosts, and the dcrd RPC host.
+	oc, err := newOracleClient("")
+	if err != nil {
+		return nil, err
+	}
+	oracles := []bestBlockOracle{
+		&wsOracle{
+			plugin: &p,
+			host:   hostWS,
+		},
+		&httpOracle{
+			client: oc,
+			host:   hostHTTP,
+		},
+	}
+	for _, v := range hostHTTPFallback {
+		oracles = append(oracles, &httpOracle{
+			client: oc,
+			host:   v,
+		})
+	}
+	if dcrdRPCHost != "" {
+		dc, err := newOracleClient(dcrdRPCCert)
+		if err != nil {
+			return nil, err
+		}
+		oracles = append(oracles, &dcrdOracle{
+			client: dc,
+			host:   dcrdRPCHost,
+			user:   dcrdRPCUser,
+			pass:   dcrdRPCPass,
+		})
+	}
+	p.oracle = newFailoverOracle(oracles...)
+	log.Infof("Best block sources: %v", p.oracle.name())
+
+	return &p, nil
 }
@@ -0,0 +1,284 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package dcrdata
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	jsonrpc "github.com/decred/dcrd/rpc/jsonrpc/types/v2"
+	types "github.com/decred/dcrdata/v6/api/types"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// oracleTimeout is the timeout of a single best block request. It
+	// is kept short so that an unreachable source does not delay the
+	// failover to the next source.
+	oracleTimeout = 10 * time.Second
+)
+
+var (
+	// errBestBlockUnavailable is returned by the websocket oracle when
+	// the websocket has not received a best block yet or when the
+	// cached best block is stale.
+	errBestBlockUnavailable = errors.New("websocket best block unavailable")
+)
+
+// bestBlockOracle provides the best block height of the Decred blockchain.
+type bestBlockOracle interface {
+	// name returns the human readable name of the oracle.
+	name() string
+
+	// bestBlock returns the best block height.
+	bestBlock() (uint32, error)
+}
+
+var (
+	_ bestBlockOracle = (*wsOracle)(nil)
+	_ bestBlockOracle = (*httpOracle)(nil)
+	_ bestBlockOracle = (*dcrdOracle)(nil)
+	_ bestBlockOracle = (*failoverOracle)(nil)
+)
+
+// wsOracle returns the best block that is cached by the dcrdata websocket
+// connection.
+type wsOracle struct {
+	plugin *dcrdataPlugin
+	host   string
+}
+
+// name returns the human readable name of the oracle.
+//
+// This function satisfies the bestBlockOracle interface.
+func (o *wsOracle) name() string {
+	return "dcrdata websocket " + o.host
+}
+
+// bestBlock returns the best block height.
+//
+// This function satisfies the bestBlockOracle interface.
+func (o *wsOracle) bestBlock() (uint32, error) {
+	bb := o.plugin.bestBlockGet()
+	if bb == 0 || o.plugin.bestBlockIsStale() {
+		return 0, errBestBlockUnavailable
+	}
+	return bb, nil
+}
+
+// httpOracle requests the best block from the dcrdata http API.
+type httpOracle struct {
+	client *http.Client
+	host   string
+}
+
+// name returns the human readable name of the oracle.
+//
+// This function satisfies the bestBlockOracle interface.
+func (o *httpOracle) name() string {
+	return "dcrdata http " + o.host
+}
+
+// bestBlock returns the best block height.
+//
+// This function satisfies the bestBlockOracle interface.
+func (o *httpOracle) bestBlock() (uint32, error) {
+	resBody, err := makeReq(o.client, o.host, http.MethodGet,
+		routeBestBlock, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var bdb types.BlockDataBasic
+	err = json.Unmarshal(resBody, &bdb)
+	if err != nil {
+		return 0, err
+	}
+
+	return bdb.Height, nil
+}
+
+// dcrdOracle requests the best block from the dcrd JSON-RPC API.
+type dcrdOracle struct {
+	client *http.Client
+	host   string
+	user   string
+	pass   string
+}
+
+// dcrdRequest is a dcrd JSON-RPC request.
+type dcrdRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+	ID      uint64        `json:"id"`
+}
+
+// dcrdResponse is a dcrd JSON-RPC response.
+type dcrdResponse struct {
+	Result json.RawMessage   `json:"result"`
+	Error  *jsonrpc.RPCError `json:"error"`
+}
+
+// name returns the human readable name of the oracle.
+//
+// This function satisfies the bestBlockOracle interface.
+func (o *dcrdOracle) name() string {
+	return "dcrd rpc " + o.host
+}
+
+// bestBlock returns the best block height.
+//
+// This function satisfies the bestBlockOracle interface.
+func (o *dcrdOracle) bestBlock() (uint32, error) {
+	b, err := json.Marshal(dcrdRequest{
+		JSONRPC: "1.0",
+		Method:  "getbestblock",
+		Params:  []interface{}{},
+		ID:      1,
+	})
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequest(http.MethodPost, "https://"+o.host,
+		bytes.NewReader(b))
+	if err != nil {
+		return 0, err
+	}
+	req.SetBasicAuth(o.user, o.pass)
+	req.Header.Set(headerContentType, contentTypeJSON)
+
+	r, err := o.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Body.Close()
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return 0, err
+	}
+	if r.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("%v %s", r.StatusCode, body)
+	}
+
+	var resp dcrdResponse
+	err = json.Unmarshal(body, &resp)
+	if err != nil {
+		return 0, err
+	}
+	if resp.Error != nil {
+		return 0, resp.Error
+	}
+	var bbr jsonrpc.GetBestBlockResult
+	err = json.Unmarshal(resp.Result, &bbr)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(bbr.Height), nil
+}
+
+// failoverOracle requests the best block from a list of oracles. The oracles
+// are tried in order and the best block of the first oracle that succeeds is
+// returned.
+type failoverOracle struct {
+	sync.Mutex
+	oracles []bestBlockOracle
+
+	// current is the name of the oracle that provided the most recent
+	// best block. It is used to log failovers.
+	current string
+
+	// last is the most recent best block height that was returned by
+	// any of the oracles.
+	last uint32
+}
+
+// newFailoverOracle returns a new failoverOracle.
+func newFailoverOracle(oracles ...bestBlockOracle) *failoverOracle {
+	return &failoverOracle{
+		oracles: oracles,
+	}
+}
+
+// name returns the human readable name of the oracle.
+//
+// This function satisfies the bestBlockOracle interface.
+func (o *failoverOracle) name() string {
+	names := make([]string, 0, len(o.oracles))
+	for _, v := range o.oracles {
+		names = append(names, v.name())
+	}
+	return "failover(" + strings.Join(names, ", ") + ")"
+}
+
+// bestBlock returns the best block height of the first oracle that succeeds.
+// An error is returned if none of the oracles succeed.
+//
+// This function satisfies the bestBlockOracle interface.
+func (o *failoverOracle) bestBlock() (uint32, error) {
+	errs := make([]string, 0, len(o.oracles))
+	for _, v := range o.oracles {
+		bb, err := v.bestBlock()
+		if err == nil && bb == 0 {
+			err = fmt.Errorf("invalid best block height 0")
+		}
+		if err != nil {
+			if err != errBestBlockUnavailable {
+				log.Debugf("Best block %v: %v", v.name(), err)
+			}
+			errs = append(errs, fmt.Sprintf("%v: %v", v.name(), err))
+			continue
+		}
+
+		o.Lock()
+		if o.current != v.name() {
+			log.Infof("Best block source: %v", v.name())
+			o.current = v.name()
+		}
+		o.last = bb
+		o.Unlock()
+
+		return bb, nil
+	}
+
+	o.Lock()
+	if o.current != "" {
+		log.Errorf("Best block sources unreachable")
+		o.current = ""
+	}
+	o.Unlock()
+
+	return 0, fmt.Errorf("all best block sources failed: %v",
+		strings.Join(errs, "; "))
+}
+
+// lastBestBlock returns the most recent best block height that was returned
+// by any of the oracles. Zero is returned if no best block has been returned
+// yet.
+func (o *failoverOracle) lastBestBlock() uint32 {
+	o.Lock()
+	defer o.Unlock()
+
+	return o.last
+}
+
+// newOracleClient returns a new http client for best block requests.
+func newOracleClient(certPath string) (*http.Client, error) {
+	c, err := util.NewHTTPClient(false, certPath)
+	if err != nil {
+		return nil, err
+	}
+	c.Timeout = oracleTimeout
+	return c, nil
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package dcrdata
+
+import (
+	"errors"
+	"testing"
+)
+
+// testOracle is a bestBlockOracle that returns a preset best block.
+type testOracle struct {
+	height uint32
+	err    error
+}
+
+func (o *testOracle) name() string {
+	return "test"
+}
+
+func (o *testOracle) bestBlock() (uint32, error) {
+	return o.height, o.err
+}
+
+func TestFailoverOracle(t *testing.T) {
+	var (
+		down    = errors.New("down")
+		primary = &testOracle{height: 100}
+		backup  = &testOracle{height: 99}
+		o       = newFailoverOracle(primary, backup)
+	)
+
+	var tests = []struct {
+		name       string
+		primaryErr error
+		backupErr  error
+		want       uint32
+		wantErr    bool
+		wantLast   uint32
+	}{
+		{"primary", nil, nil, 100, false, 100},
+		{"failover", down, nil, 99, false, 99},
+		{"unavailable", errBestBlockUnavailable, nil, 99, false, 99},
+		{"all down", down, down, 0, true, 99},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			primary.err = v.primaryErr
+			backup.err = v.backupErr
+
+			bb, err := o.bestBlock()
+			switch {
+			case v.wantErr && err == nil:
+				t.Fatalf("got nil error, want error")
+			case !v.wantErr && err != nil:
+				t.Fatalf("got error %v, want nil", err)
+			}
+			if bb != v.want {
+				t.Fatalf("got best block %v, want %v", bb, v.want)
+			}
+			if o.lastBestBlock() != v.wantLast {
+				t.Fatalf("got last best block %v, want %v",
+					o.lastBestBlock(), v.wantLast)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pi
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	backend "github.com/decred/politeia/politeiad/backendv2"
+	"github.com/decred/politeia/politeiad/backendv2/tstorebe/store"
+	"github.com/decred/politeia/politeiad/plugins/pi"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// Blob entry data descriptors
+	dataDescriptorAuthorUpdate = pi.PluginID + "-authorupdate-v1"
+)
+
+// cmdSetAuthorUpdate sets the author update of a record. The new author
+// update is saved as a new version. Previous versions are not deleted.
+func (p *piPlugin) cmdSetAuthorUpdate(ctx context.Context, token []byte, payload string) (string, error) {
+	// Decode payload
+	var sau pi.SetAuthorUpdate
+	err := json.Unmarshal([]byte(payload), &sau)
+	if err != nil {
+		return "", err
+	}
+
+	// Verify token
+	err = tokenVerify(token, sau.Token)
+	if err != nil {
+		return "", err
+	}
+
+	// Verify signature
+	msg := sau.Token + sau.Update
+	err = util.VerifySignature(sau.Signature, sau.PublicKey, msg)
+	if err != nil {
+		return "", convertSignatureError(err)
+	}
+
+	// Verify update length
+	l := utf8.RuneCountInString(sau.Update)
+	if l == 0 || l > int(p.authorUpdateLengthMax) {
+		return "", backend.PluginError{
+			PluginID:  pi.PluginID,
+			ErrorCode: uint32(pi.ErrorCodeAuthorUpdateLengthInvalid),
+			ErrorContext: fmt.Sprintf("max length is %v characters",
+				p.authorUpdateLengthMax),
+		}
+	}
+
+	// Verify record state. Author updates can only be set on vetted
+	// records.
+	state, err := p.tstore.RecordState(token)
+	if err != nil {
+		return "", err
+	}
+	if state != backend.StateVetted {
+		return "", backend.PluginError{
+			PluginID:     pi.PluginID,
+			ErrorCode:    uint32(pi.ErrorCodeRecordStateInvalid),
+			ErrorContext: "record is not vetted",
+		}
+	}
+
+	// Verify the user is the record author
+	authorID, err := p.recordAuthor(token)
+	if err != nil {
+		return "", err
+	}
+	if sau.UserID != authorID {
+		return "", backend.PluginError{
+			PluginID:     pi.PluginID,
+			ErrorCode:    uint32(pi.ErrorCodeUserUnauthorized),
+			ErrorContext: "user is not the record author",
+		}
+	}
+
+	// Get the existing author updates so that the version can be
+	// determined.
+	aus, err := p.authorUpdates(token)
+	if err != nil {
+		return "", err
+	}
+
+	// Save the author update
+	receipt := p.identity.SignMessage([]byte(sau.Signature))
+	au := pi.AuthorUpdate{
+		UserID:    sau.UserID,
+		Token:     sau.Token,
+		Update:    sau.Update,
+		PublicKey: sau.PublicKey,
+		Signature: sau.Signature,
+		Version:   uint32(len(aus)) + 1,
+		Timestamp: time.Now().Unix(),
+		Receipt:   hex.EncodeToString(receipt[:]),
+	}
+	be, err := convertBlobEntryFromAuthorUpdate(au)
+	if err != nil {
+		return "", err
+	}
+	err = p.tstore.BlobSave(ctx, token, *be)
+	if err != nil {
+		return "", err
+	}
+
+	log.Debugf("Author update version %v saved to record %v",
+		au.Version, au.Token)
+
+	// Prepare reply
+	reply, err := json.Marshal(pi.SetAuthorUpdateReply{
+		AuthorUpdate: au,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(reply), nil
+}
+
+// cmdAuthorUpdates returns all versions of the author update of a record.
+func (p *piPlugin) cmdAuthorUpdates(token []byte) (string, error) {
+	aus, err := p.authorUpdates(token)
+	if err != nil {
+		return "", err
+	}
+
+	// Prepare reply
+	reply, err := json.Marshal(pi.AuthorUpdatesReply{
+		Updates: aus,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(reply), nil
+}
+
+// authorUpdates returns all versions of the author update of a record,
+// ordered from oldest to newest.
+func (p *piPlugin) authorUpdates(token []byte) ([]pi.AuthorUpdate, error) {
+	blobs, err := p.tstore.BlobsByDataDesc(token,
+		[]string{dataDescriptorAuthorUpdate})
+	if err != nil {
+		return nil, err
+	}
+	aus := make([]pi.AuthorUpdate, 0, len(blobs))
+	for _, v := range blobs {
+		au, err := convertAuthorUpdateFromBlobEntry(v)
+		if err != nil {
+			return nil, err
+		}
+		aus = append(aus, *au)
+	}
+	return aus, nil
+}
+
+// recordAuthor returns the user ID of the record author.
+func (p *piPlugin) recordAuthor(token []byte) (string, error) {
+	r, err := p.tstore.RecordPartial(token, 0, nil, true)
+	if err != nil {
+		return "", err
+	}
+	return userIDDecode(r.Metadata)
+}
+
+// tokenVerify verifies that a token that is part of a plugin command payload
+// is valid and matches the token that the plugin command is being executed
+// on.
+func tokenVerify(cmdToken []byte, payloadToken string) error {
+	pt, err := tokenDecode(payloadToken)
+	if err != nil {
+		return backend.PluginError{
+			PluginID:     pi.PluginID,
+			ErrorCode:    uint32(pi.ErrorCodeTokenInvalid),
+			ErrorContext: util.TokenRegexp(),
+		}
+	}
+	if !bytes.Equal(cmdToken, pt) {
+		return backend.PluginError{
+			PluginID:  pi.PluginID,
+			ErrorCode: uint32(pi.ErrorCodeTokenInvalid),
+			ErrorContext: fmt.Sprintf("payload token does not match "+
+				"command token: got %x, want %x", pt, cmdToken),
+		}
+	}
+	return nil
+}
+
+func convertSignatureError(err error) backend.PluginError {
+	var e util.SignatureError
+	var s pi.ErrorCodeT
+	if errors.As(err, &e) {
+		switch e.ErrorCode {
+		case util.ErrorStatusPublicKeyInvalid:
+			s = pi.ErrorCodePublicKeyInvalid
+		case util.ErrorStatusSignatureInvalid:
+			s = pi.ErrorCodeSignatureInvalid
+		}
+	}
+	return backend.PluginError{
+		PluginID:     pi.PluginID,
+		ErrorCode:    uint32(s),
+		ErrorContext: e.ErrorContext,
+	}
+}
+
+func convertBlobEntryFromAuthorUpdate(au pi.AuthorUpdate) (*store.BlobEntry, error) {
+	data, err := json.Marshal(au)
+	if err != nil {
+		return nil, err
+	}
+	hint, err := json.Marshal(
+		store.DataDescriptor{
+			Type:       store.DataTypeStructure,
+			Descriptor: dataDescriptorAuthorUpdate,
+		})
+	if err != nil {
+		return nil, err
+	}
+	be := store.NewBlobEntry(hint, data)
+	return &be, nil
+}
+
+func convertAuthorUpdateFromBlobEntry(be store.BlobEntry) (*pi.AuthorUpdate, error) {
+	// Decode and validate data hint
+	b, err := base64.StdEncoding.DecodeString(be.DataHint)
+	if err != nil {
+		return nil, fmt.Errorf("decode DataHint: %v", err)
+	}
+	var dd store.DataDescriptor
+	err = json.Unmarshal(b, &dd)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal DataHint: %v", err)
+	}
+	if dd.Descriptor != dataDescriptorAuthorUpdate {
+		return nil, fmt.Errorf("unexpected data descriptor: got %v, want %v",
+			dd.Descriptor, dataDescriptorAuthorUpdate)
+	}
+
+	// Decode data
+	b, err = base64.StdEncoding.DecodeString(be.Data)
+	if err != nil {
+		return nil, fmt.Errorf("decode Data: %v", err)
+	}
+	digest, err := hex.DecodeString(be.Digest)
+	if err != nil {
+		return nil, fmt.Errorf("decode digest: %v", err)
+	}
+	if !bytes.Equal(util.Digest(b), digest) {
+		return nil, fmt.Errorf("data is not coherent; got %x, want %x",
+			util.Digest(b), digest)
+	}
+	var au pi.AuthorUpdate
+	err = json.Unmarshal(b, &au)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal AuthorUpdate: %v", err)
+	}
+
+	return &au, nil
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/decred/politeia/politeiad/plugins/comments"
 	"github.com/decred/politeia/politeiad/plugins/pi"
 	"github.com/decred/politeia/politeiad/plugins/ticketvote"
+	"github.com/decred/politeia/politeiad/plugins/usermd"
 	"github.com/decred/politeia/util"
 	"github.com/pkg/errors"
 )
@@ -45,7 +46,25 @@ func (p *piPlugin) hookNewRecordPre(payload string) error {
 		return err
 	}
 
-	return p.proposalFilesVerify(nr.Files)
+	// Verify proposal files
+	err = p.proposalFilesVerify(nr.Files)
+	if err != nil {
+		return err
+	}
+
+	// Verify the resubmitted proposal
+	pm, err := proposalMetadataDecode(nr.Files)
+	if err != nil {
+		return err
+	}
+	if pm.Resubmits == "" {
+		return nil
+	}
+	authorID, err := userIDDecode(nr.Metadata)
+	if err != nil {
+		return err
+	}
+	return p.resubmitsVerify(pm.Resubmits, authorID)
 }
 
 // hookEditRecordPre adds plugin specific validation onto the tstore backend
@@ -63,6 +82,30 @@ func (p *piPlugin) hookEditRecordPre(payload string) error {
 		return err
 	}
 
+	// Verify the resubmitted proposal. The resubmitted proposal is only
+	// verified when it has changed. A resubmitted proposal that was
+	// valid when it was linked remains valid, e.g. when it has since
+	// been archived.
+	pm, err := proposalMetadataDecode(er.Files)
+	if err != nil {
+		return err
+	}
+	prevPM, err := proposalMetadataDecode(er.Record.Files)
+	if err != nil {
+		return err
+	}
+	if pm.Resubmits != "" &&
+		(prevPM == nil || pm.Resubmits != prevPM.Resubmits) {
+		authorID, err := userIDDecode(er.Record.Metadata)
+		if err != nil {
+			return err
+		}
+		err = p.resubmitsVerify(pm.Resubmits, authorID)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Verify vote status. Edits are not allowed to be made once a vote
 	// has been authorized. This only needs to be checked for vetted
 	// records since you cannot authorize or start a ticket vote on an
@@ -261,6 +304,69 @@ func (p *piPlugin) proposalFilesVerify(files []backend.File) error {
 	return nil
 }
 
+// resubmitsVerify verifies that the proposal that a new proposal is a
+// resubmission of exists, was rejected by the ticket vote, and was submitted
+// by the same user. The rejected proposal is not re-validated against the
+// current proposal policy since it was validated against the policy that was
+// in place when it was submitted.
+func (p *piPlugin) resubmitsVerify(resubmits, authorID string) error {
+	token, err := tokenDecode(resubmits)
+	if err != nil {
+		return backend.PluginError{
+			PluginID:     pi.PluginID,
+			ErrorCode:    uint32(pi.ErrorCodeResubmitsInvalid),
+			ErrorContext: util.TokenRegexp(),
+		}
+	}
+	r, err := p.tstore.RecordPartial(token, 0, nil, true)
+	if errors.Is(err, backend.ErrRecordNotFound) {
+		return backend.PluginError{
+			PluginID:     pi.PluginID,
+			ErrorCode:    uint32(pi.ErrorCodeResubmitsInvalid),
+			ErrorContext: "proposal not found",
+		}
+	} else if err != nil {
+		return err
+	}
+	if r.RecordMetadata.State != backend.StateVetted {
+		return backend.PluginError{
+			PluginID:     pi.PluginID,
+			ErrorCode:    uint32(pi.ErrorCodeResubmitsInvalid),
+			ErrorContext: "proposal is not vetted",
+		}
+	}
+
+	// Verify the vote status
+	s, err := p.voteSummary(token)
+	if err != nil {
+		return err
+	}
+	if s.Status != ticketvote.VoteStatusRejected {
+		return backend.PluginError{
+			PluginID:  pi.PluginID,
+			ErrorCode: uint32(pi.ErrorCodeResubmitsInvalid),
+			ErrorContext: fmt.Sprintf("only rejected proposals can be "+
+				"resubmitted; got vote status '%v'",
+				ticketvote.VoteStatuses[s.Status]),
+		}
+	}
+
+	// Verify the author
+	prevAuthorID, err := userIDDecode(r.Metadata)
+	if err != nil {
+		return err
+	}
+	if authorID != prevAuthorID {
+		return backend.PluginError{
+			PluginID:     pi.PluginID,
+			ErrorCode:    uint32(pi.ErrorCodeResubmitsInvalid),
+			ErrorContext: "user is not the author of the proposal",
+		}
+	}
+
+	return nil
+}
+
 // voteSummary requests the vote summary from the ticketvote plugin for a
 // record.
 func (p *piPlugin) voteSummary(token []byte) (*ticketvote.SummaryReply, error) {
@@ -329,3 +435,21 @@ func proposalMetadataDecode(files []backend.File) (*pi.ProposalMetadata, error)
 	}
 	return propMD, nil
 }
+
+// userIDDecode decodes and returns the user ID from the usermd plugin user
+// metadata stream of the provided metadata streams.
+func userIDDecode(metadata []backend.MetadataStream) (string, error) {
+	for _, v := range metadata {
+		if v.PluginID != usermd.PluginID ||
+			v.StreamID != usermd.StreamIDUserMetadata {
+			continue
+		}
+		var um usermd.UserMetadata
+		err := json.Unmarshal([]byte(v.Payload), &um)
+		if err != nil {
+			return "", err
+		}
+		return um.UserID, nil
+	}
+	return "", errors.Errorf("user metadata not found")
+}
@@ -20,6 +20,8 @@ import (
 	backend "github.com/decred/politeia/politeiad/backendv2"
 	"github.com/decred/politeia/politeiad/backendv2/tstorebe/plugins"
 	"github.com/decred/politeia/politeiad/plugins/pi"
+	"github.com/decred/politeia/politeiad/plugins/ticketvote"
+	"github.com/decred/politeia/politeiad/plugins/usermd"
 	"github.com/decred/politeia/util"
 )
 
@@ -463,3 +465,131 @@ func filesWithProposalName(t *testing.T, name string) []backend.File {
 		}),
 	}
 }
+
+// testTstore is a tstore client that returns the records of a map. Only the
+// methods that are used by the tests are implemented.
+type testTstore struct {
+	plugins.TstoreClient
+	records map[string]*backend.Record // [token]Record
+}
+
+// RecordPartial satisfies the plugins TstoreClient interface.
+func (t *testTstore) RecordPartial(token []byte, version uint32, filenames []string, omitAllFiles bool) (*backend.Record, error) {
+	r, ok := t.records[hex.EncodeToString(token)]
+	if !ok {
+		return nil, backend.ErrRecordNotFound
+	}
+	return r, nil
+}
+
+// testBackend is a backend that returns the vote statuses of a map. Only the
+// methods that are used by the tests are implemented.
+type testBackend struct {
+	backend.Backend
+	votes map[string]ticketvote.VoteStatusT // [token]VoteStatus
+}
+
+// PluginRead satisfies the backend Backend interface.
+func (b *testBackend) PluginRead(token []byte, pluginID, pluginCmd, payload string) (string, error) {
+	sr := ticketvote.SummaryReply{
+		Status: b.votes[hex.EncodeToString(token)],
+	}
+	reply, err := json.Marshal(sr)
+	if err != nil {
+		return "", err
+	}
+	return string(reply), nil
+}
+
+// recordWithAuthor returns a record with the provided state that contains
+// the user metadata of the provided author.
+func recordWithAuthor(t *testing.T, state backend.StateT, authorID string) *backend.Record {
+	t.Helper()
+
+	b, err := json.Marshal(usermd.UserMetadata{
+		UserID: authorID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &backend.Record{
+		RecordMetadata: backend.RecordMetadata{
+			State: state,
+		},
+		Metadata: []backend.MetadataStream{
+			{
+				PluginID: usermd.PluginID,
+				StreamID: usermd.StreamIDUserMetadata,
+				Payload:  string(b),
+			},
+		},
+	}
+}
+
+func TestResubmitsVerify(t *testing.T) {
+	// Setup pi plugin
+	p, cleanup := newTestPiPlugin(t)
+	defer cleanup()
+
+	var (
+		author = "author"
+
+		rejected   = "0000000000000001"
+		approved   = "0000000000000002"
+		unvetted   = "0000000000000003"
+		otherOwner = "0000000000000004"
+		notFound   = "0000000000000005"
+	)
+	p.tstore = &testTstore{
+		records: map[string]*backend.Record{
+			rejected:   recordWithAuthor(t, backend.StateVetted, author),
+			approved:   recordWithAuthor(t, backend.StateVetted, author),
+			unvetted:   recordWithAuthor(t, backend.StateUnvetted, author),
+			otherOwner: recordWithAuthor(t, backend.StateVetted, "other"),
+		},
+	}
+	p.backend = &testBackend{
+		votes: map[string]ticketvote.VoteStatusT{
+			rejected:   ticketvote.VoteStatusRejected,
+			approved:   ticketvote.VoteStatusApproved,
+			otherOwner: ticketvote.VoteStatusRejected,
+		},
+	}
+
+	var tests = []struct {
+		name      string
+		resubmits string
+		valid     bool
+	}{
+		{"rejected proposal", rejected, true},
+		{"invalid token", "invalid", false},
+		{"short token", rejected[:7], false},
+		{"proposal not found", notFound, false},
+		{"proposal not vetted", unvetted, false},
+		{"proposal not rejected", approved, false},
+		{"different author", otherOwner, false},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			err := p.resubmitsVerify(v.resubmits, author)
+			if v.valid {
+				if err != nil {
+					t.Fatalf("got error %v, want nil", err)
+				}
+				return
+			}
+
+			// All validation errors are ErrorCodeResubmitsInvalid
+			// plugin errors.
+			var pe backend.PluginError
+			if !errors.As(err, &pe) {
+				t.Fatalf("got error %v, want a plugin error", err)
+			}
+			if pi.ErrorCodeT(pe.ErrorCode) != pi.ErrorCodeResubmitsInvalid {
+				t.Fatalf("got error code %v, want %v",
+					pi.ErrorCodes[pi.ErrorCodeT(pe.ErrorCode)],
+					pi.ErrorCodes[pi.ErrorCodeResubmitsInvalid])
+			}
+		})
+	}
+}
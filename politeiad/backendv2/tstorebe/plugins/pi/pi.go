@@ -5,16 +5,20 @@
 package pi
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
 
+	"github.com/decred/politeia/politeiad/api/v1/identity"
 	backend "github.com/decred/politeia/politeiad/backendv2"
 	"github.com/decred/politeia/politeiad/backendv2/tstorebe/plugins"
 	"github.com/decred/politeia/politeiad/plugins/pi"
+	"github.com/decred/politeia/politeiad/plugins/ticketvote"
 	"github.com/decred/politeia/util"
 )
 
@@ -28,7 +32,9 @@ var (
 //
 // piPlugin satisfies the plugins PluginClient interface.
 type piPlugin struct {
-	backend backend.Backend
+	backend  backend.Backend
+	tstore   plugins.TstoreClient
+	identity *identity.FullIdentity
 
 	// dataDir is the pi plugin data directory. The only data that is
 	// stored here is cached data that can be re-created at any time
@@ -44,6 +50,7 @@ type piPlugin struct {
 	proposalNameLengthMin      uint32 // In characters
 	proposalNameLengthMax      uint32 // In characters
 	proposalNameRegexp         *regexp.Regexp
+	authorUpdateLengthMax      uint32 // In characters
 }
 
 // Setup performs any plugin setup that is required.
@@ -58,9 +65,16 @@ func (p *piPlugin) Setup() error {
 // Cmd executes a plugin command.
 //
 // This function satisfies the plugins PluginClient interface.
-func (p *piPlugin) Cmd(token []byte, cmd, payload string) (string, error) {
+func (p *piPlugin) Cmd(ctx context.Context, token []byte, cmd, payload string) (string, error) {
 	log.Tracef("pi Cmd: %x %v %v", token, cmd, payload)
 
+	switch cmd {
+	case pi.CmdSetAuthorUpdate:
+		return p.cmdSetAuthorUpdate(ctx, token, payload)
+	case pi.CmdAuthorUpdates:
+		return p.cmdAuthorUpdates(token)
+	}
+
 	return "", backend.ErrPluginCmdInvalid
 }
 
@@ -126,14 +140,110 @@ func (p *piPlugin) Settings() []backend.PluginSetting {
 			Key:   pi.SettingKeyProposalNameSupportedChars,
 			Value: p.proposalNameSupportedChars,
 		},
+		{
+			Key:   pi.SettingKeyAuthorUpdateLengthMax,
+			Value: strconv.FormatUint(uint64(p.authorUpdateLengthMax), 10),
+		},
+	}
+}
+
+// SettingsSchema returns the schema of the plugin settings.
+//
+// This function satisfies the plugins PluginClient interface.
+func (p *piPlugin) SettingsSchema() []backend.PluginSettingSchema {
+	log.Tracef("pi SettingsSchema")
+
+	return settingsSchema()
+}
+
+// Dependencies returns the IDs of the plugins that this plugin depends on. The
+// pi plugin uses the ticketvote plugin to retrieve the vote status of
+// proposals.
+//
+// This function satisfies the plugins PluginClient interface.
+func (p *piPlugin) Dependencies() []string {
+	log.Tracef("pi Dependencies")
+
+	return []string{
+		ticketvote.PluginID,
+	}
+}
+
+// settingsSchema returns the schema of the pi plugin settings.
+func settingsSchema() []backend.PluginSettingSchema {
+	// The default supported chars are a package level variable that
+	// is statically defined, so this will not fail.
+	b, _ := json.Marshal(pi.SettingProposalNameSupportedChars)
+
+	return []backend.PluginSettingSchema{
+		{
+			Key:     pi.SettingKeyTextFileSizeMax,
+			Type:    backend.PluginSettingTypeUint,
+			Default: strconv.FormatUint(uint64(pi.SettingTextFileSizeMax), 10),
+			Min:     1,
+			Max:     math.MaxUint32,
+		},
+		{
+			Key:     pi.SettingKeyImageFileCountMax,
+			Type:    backend.PluginSettingTypeUint,
+			Default: strconv.FormatUint(uint64(pi.SettingImageFileCountMax), 10),
+			Min:     0,
+			Max:     math.MaxUint32,
+		},
+		{
+			Key:     pi.SettingKeyImageFileSizeMax,
+			Type:    backend.PluginSettingTypeUint,
+			Default: strconv.FormatUint(uint64(pi.SettingImageFileSizeMax), 10),
+			Min:     1,
+			Max:     math.MaxUint32,
+		},
+		{
+			Key:     pi.SettingKeyProposalNameLengthMin,
+			Type:    backend.PluginSettingTypeUint,
+			Default: strconv.FormatUint(uint64(pi.SettingProposalNameLengthMin), 10),
+			Min:     1,
+			Max:     math.MaxUint32,
+		},
+		{
+			Key:     pi.SettingKeyProposalNameLengthMax,
+			Type:    backend.PluginSettingTypeUint,
+			Default: strconv.FormatUint(uint64(pi.SettingProposalNameLengthMax), 10),
+			Min:     1,
+			Max:     math.MaxUint32,
+		},
+		{
+			Key:     pi.SettingKeyProposalNameSupportedChars,
+			Type:    backend.PluginSettingTypeStringList,
+			Default: string(b),
+			Min:     1,
+		},
+		{
+			Key:     pi.SettingKeyAuthorUpdateLengthMax,
+			Type:    backend.PluginSettingTypeUint,
+			Default: strconv.FormatUint(uint64(pi.SettingAuthorUpdateLengthMax), 10),
+			Min:     1,
+			Max:     math.MaxUint32,
+		},
 	}
 }
 
+// settingsVerify verifies the provided settings against the pi plugin
+// settings schema.
+func settingsVerify(settings []backend.PluginSetting) error {
+	return backend.PluginSettingsVerify(pi.PluginID, settingsSchema(), settings)
+}
+
 // New returns a new piPlugin.
-func New(backend backend.Backend, settings []backend.PluginSetting, dataDir string) (*piPlugin, error) {
+func New(backend backend.Backend, tstore plugins.TstoreClient, settings []backend.PluginSetting, dataDir string, id *identity.FullIdentity) (*piPlugin, error) {
+	// Verify the plugin settings
+	err := settingsVerify(settings)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create plugin data directory
 	dataDir = filepath.Join(dataDir, pi.PluginID)
-	err := os.MkdirAll(dataDir, 0700)
+	err = os.MkdirAll(dataDir, 0700)
 	if err != nil {
 		return nil, err
 	}
@@ -146,6 +256,7 @@ func New(backend backend.Backend, settings []backend.PluginSetting, dataDir stri
 		nameLengthMin      = pi.SettingProposalNameLengthMin
 		nameLengthMax      = pi.SettingProposalNameLengthMax
 		nameSupportedChars = pi.SettingProposalNameSupportedChars
+		updateLengthMax    = pi.SettingAuthorUpdateLengthMax
 	)
 
 	// Override defaults with any passed in settings
@@ -194,6 +305,13 @@ func New(backend backend.Backend, settings []backend.PluginSetting, dataDir stri
 					v.Key, v.Value, err)
 			}
 			nameSupportedChars = sc
+		case pi.SettingKeyAuthorUpdateLengthMax:
+			u, err := strconv.ParseUint(v.Value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid plugin setting %v '%v': %v",
+					v.Key, v.Value, err)
+			}
+			updateLengthMax = uint32(u)
 		default:
 			return nil, fmt.Errorf("invalid plugin setting: %v", v.Key)
 		}
@@ -217,6 +335,8 @@ func New(backend backend.Backend, settings []backend.PluginSetting, dataDir stri
 	return &piPlugin{
 		dataDir:                    dataDir,
 		backend:                    backend,
+		tstore:                     tstore,
+		identity:                   id,
 		textFileSizeMax:            textFileSizeMax,
 		imageFileCountMax:          imageFileCountMax,
 		imageFileSizeMax:           imageFileSizeMax,
@@ -224,5 +344,6 @@ func New(backend backend.Backend, settings []backend.PluginSetting, dataDir stri
 		proposalNameLengthMax:      nameLengthMax,
 		proposalNameSupportedChars: nameSupportedCharsString,
 		proposalNameRegexp:         rexp,
+		authorUpdateLengthMax:      updateLengthMax,
 	}, nil
 }
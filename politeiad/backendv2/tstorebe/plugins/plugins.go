@@ -5,6 +5,7 @@
 package plugins
 
 import (
+	"context"
 	"errors"
 
 	backend "github.com/decred/politeia/politeiad/backendv2"
@@ -147,8 +148,9 @@ type PluginClient interface {
 	// Setup performs any required plugin setup.
 	Setup() error
 
-	// Cmd executes a plugin command.
-	Cmd(token []byte, cmd, payload string) (string, error)
+	// Cmd executes a plugin command. The context is used to trace the
+	// tstore writes of the command.
+	Cmd(ctx context.Context, token []byte, cmd, payload string) (string, error)
 
 	// Hook executes a plugin hook.
 	Hook(h HookT, payload string) error
@@ -158,6 +160,13 @@ type PluginClient interface {
 
 	// Settings returns the plugin settings.
 	Settings() []backend.PluginSetting
+
+	// SettingsSchema returns the schema of the plugin settings.
+	SettingsSchema() []backend.PluginSettingSchema
+
+	// Dependencies returns the IDs of the plugins that must be
+	// registered and set up before this plugin.
+	Dependencies() []string
 }
 
 // TstoreClient provides an API for plugins to interact with a tstore instance.
@@ -168,12 +177,14 @@ type TstoreClient interface {
 	// will be encrypted prior to being written to disk if the record
 	// is unvetted. The digest of the data, i.e. BlobEntry.Digest, can
 	// be thought of as the blob ID that can be used to get/del the
-	// blob from tstore.
-	BlobSave(token []byte, be store.BlobEntry) error
+	// blob from tstore. The save is traced when the context contains
+	// a trace span.
+	BlobSave(ctx context.Context, token []byte, be store.BlobEntry) error
 
 	// BlobsDel deletes the blobs that correspond to the provided
-	// digests.
-	BlobsDel(token []byte, digests [][]byte) error
+	// digests. The delete is traced when the context contains a trace
+	// span.
+	BlobsDel(ctx context.Context, token []byte, digests [][]byte) error
 
 	// Blobs returns the blobs that correspond to the provided digests.
 	// If a blob does not exist it will not be included in the returned
@@ -32,12 +32,14 @@ type activeVotes struct {
 // activeVote caches the data required to validate vote ballots for a record
 // with an active voting period.
 //
-// A active vote with 41k tickets will cache a maximum of 10.5 MB of data.
-// This includes a 3 MB vote details, 4.5 MB commitment addresses map, and a
-// potential 3 MB cast votes map if all 41k votes are cast.
+// A active vote with 41k tickets will cache a maximum of 13.5 MB of data.
+// This includes a 3 MB vote details, 3 MB eligible tickets map, 4.5 MB
+// commitment addresses map, and a potential 3 MB cast votes map if all 41k
+// votes are cast.
 type activeVote struct {
 	Details   *ticketvote.VoteDetails
-	CastVotes map[string]string // [ticket]voteBit
+	Eligible  map[string]struct{} // [ticket]struct{}
+	CastVotes map[string]string   // [ticket]voteBit
 
 	// Addrs contains the largest commitment address for each eligble
 	// ticket. The vote must be signed with the key from this address.
@@ -98,10 +100,29 @@ func (a *activeVotes) VoteDetails(token []byte) *ticketvote.VoteDetails {
 	}
 }
 
-// EligibleTickets returns the eligible tickets from the active votes cache for
-// the provided token. If the token does not correspond to an active vote then
-// nil is returned.
-func (a *activeVotes) EligibleTickets(token []byte) map[string]struct{} {
+// ticketStatus represents the status of a ticket in an active vote.
+type ticketStatus int
+
+const (
+	// ticketStatusNotEligible indicates that the ticket is not
+	// eligible to vote.
+	ticketStatusNotEligible ticketStatus = iota
+
+	// ticketStatusEligible indicates that the ticket is eligible to
+	// vote and has not voted yet.
+	ticketStatusEligible
+
+	// ticketStatusVoted indicates that the ticket has already voted.
+	ticketStatusVoted
+)
+
+// TicketStatuses returns the status of each of the provided tickets. The
+// returned map is a map[ticket]ticketStatus. The eligible tickets and the cast
+// votes are both indexed by ticket, so each lookup is O(1) and the lookups for
+// an entire ballot only require a single lock acquisition. Nil is returned if
+// the token does not correspond to an active vote. Its possible that the vote
+// ended while a ballot was being validated.
+func (a *activeVotes) TicketStatuses(token []byte, tickets []string) map[string]ticketStatus {
 	t := hex.EncodeToString(token)
 
 	a.RLock()
@@ -112,32 +133,20 @@ func (a *activeVotes) EligibleTickets(token []byte) map[string]struct{} {
 		return nil
 	}
 
-	// Return a map of the eligible tickets
-	eligible := make(map[string]struct{}, len(av.Details.EligibleTickets))
-	for _, v := range av.Details.EligibleTickets {
-		eligible[v] = struct{}{}
-	}
-
-	return eligible
-}
-
-// VoteIsDuplicate returns whether the vote has already been cast. The first
-// bool returned represents whether the record vote exists in the active votes
-// cache. The second bool returned represetns whether the ticket is a duplicate
-// vote.
-func (a *activeVotes) VoteIsDuplicate(token, ticket string) (bool, bool) {
-	a.RLock()
-	defer a.RUnlock()
-
-	av, ok := a.activeVotes[token]
-	if !ok {
-		// Vote does not exist. Its possible that the vote
-		// ended while a ballot was being validated.
-		return false, false
+	statuses := make(map[string]ticketStatus, len(tickets))
+	for _, v := range tickets {
+		if _, ok := av.CastVotes[v]; ok {
+			statuses[v] = ticketStatusVoted
+			continue
+		}
+		if _, ok := av.Eligible[v]; ok {
+			statuses[v] = ticketStatusEligible
+			continue
+		}
+		statuses[v] = ticketStatusNotEligible
 	}
 
-	_, isDup := av.CastVotes[ticket]
-	return true, isDup
+	return statuses
 }
 
 // CommitmentAddrs returns the largest comittment address for each of the
@@ -191,8 +200,13 @@ func (a *activeVotes) Tally(token string) map[string]uint32 {
 	return tally
 }
 
-// AddCastVote adds a cast ticket vote to the active votes cache.
-func (a *activeVotes) AddCastVote(token, ticket, votebit string) {
+// AddCastVotes adds a batch of cast ticket votes to the active votes cache.
+// The provided map is a map[ticket]voteBit.
+func (a *activeVotes) AddCastVotes(token string, votes map[string]string) {
+	if len(votes) == 0 {
+		return
+	}
+
 	a.Lock()
 	defer a.Unlock()
 
@@ -201,11 +215,13 @@ func (a *activeVotes) AddCastVote(token, ticket, votebit string) {
 		// Vote does not exist. Its possible that the vote ended after
 		// the cast votes passed validation but before this cache was
 		// able to be populated. Log a warning and exit gracefully.
-		log.Warnf("AddCastVote: vote not found %v", token)
+		log.Warnf("AddCastVotes: vote not found %v", token)
 		return
 	}
 
-	av.CastVotes[ticket] = votebit
+	for ticket, voteBit := range votes {
+		av.CastVotes[ticket] = voteBit
+	}
 }
 
 // AddCommitmentAddrs adds commitment addresses to the cache for a record.
@@ -248,9 +264,16 @@ func (a *activeVotes) Del(token string) {
 func (a *activeVotes) Add(vd ticketvote.VoteDetails) {
 	token := vd.Params.Token
 
+	// Index the eligible tickets
+	eligible := make(map[string]struct{}, len(vd.EligibleTickets))
+	for _, v := range vd.EligibleTickets {
+		eligible[v] = struct{}{}
+	}
+
 	a.Lock()
 	a.activeVotes[token] = activeVote{
 		Details:   &vd,
+		Eligible:  eligible,
 		CastVotes: make(map[string]string, 40960), // Ticket pool size
 		Addrs:     make(map[string]string, 40960), // Ticket pool size
 	}
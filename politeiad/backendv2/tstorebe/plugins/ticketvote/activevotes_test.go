@@ -0,0 +1,49 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ticketvote
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/decred/politeia/politeiad/plugins/ticketvote"
+)
+
+func TestTicketStatuses(t *testing.T) {
+	var (
+		a     = newActiveVotes()
+		token = "45154fb45664714b"
+	)
+	tokenb, err := hex.DecodeString(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Vote is not active
+	if s := a.TicketStatuses(tokenb, []string{"a"}); s != nil {
+		t.Fatalf("got statuses %v for inactive vote, want nil", s)
+	}
+
+	a.Add(ticketvote.VoteDetails{
+		Params: ticketvote.VoteParams{
+			Token: token,
+		},
+		EligibleTickets: []string{"a", "b", "c"},
+	})
+	a.AddCastVotes(token, map[string]string{"b": "1"})
+
+	want := map[string]ticketStatus{
+		"a": ticketStatusEligible,
+		"b": ticketStatusVoted,
+		"d": ticketStatusNotEligible,
+	}
+	s := a.TicketStatuses(tokenb, []string{"a", "b", "d"})
+	for ticket, status := range want {
+		if s[ticket] != status {
+			t.Errorf("ticket %v: got status %v, want %v",
+				ticket, s[ticket], status)
+		}
+	}
+}
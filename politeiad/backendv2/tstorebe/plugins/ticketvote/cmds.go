@@ -6,6 +6,7 @@ package ticketvote
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
@@ -38,7 +39,7 @@ const (
 )
 
 // cmdAuthorize authorizes a ticket vote or revokes a previous authorization.
-func (p *ticketVotePlugin) cmdAuthorize(token []byte, payload string) (string, error) {
+func (p *ticketVotePlugin) cmdAuthorize(ctx context.Context, token []byte, payload string) (string, error) {
 	// Decode payload
 	var a ticketvote.Authorize
 	err := json.Unmarshal([]byte(payload), &a)
@@ -149,7 +150,7 @@ func (p *ticketVotePlugin) cmdAuthorize(token []byte, payload string) (string, e
 	}
 
 	// Save authorize vote
-	err = p.authSave(token, auth)
+	err = p.authSave(ctx, token, auth)
 	if err != nil {
 		return "", err
 	}
@@ -429,7 +430,7 @@ func (p *ticketVotePlugin) voteChainParams(duration uint32) (*voteChainParams, e
 }
 
 // startStandard starts a standard vote.
-func (p *ticketVotePlugin) startStandard(token []byte, s ticketvote.Start) (*ticketvote.StartReply, error) {
+func (p *ticketVotePlugin) startStandard(ctx context.Context, token []byte, s ticketvote.Start) (*ticketvote.StartReply, error) {
 	// Verify there is only one start details
 	if len(s.Starts) != 1 {
 		return nil, backend.PluginError{
@@ -541,7 +542,7 @@ func (p *ticketVotePlugin) startStandard(token []byte, s ticketvote.Start) (*tic
 	}
 
 	// Save vote details
-	err = p.voteDetailsSave(token, vd)
+	err = p.voteDetailsSave(ctx, token, vd)
 	if err != nil {
 		return nil, fmt.Errorf("voteDetailsSave: %v", err)
 	}
@@ -563,12 +564,12 @@ func (p *ticketVotePlugin) startStandard(token []byte, s ticketvote.Start) (*tic
 }
 
 // startRunoffRecordSave saves a startRunoffRecord to the backend.
-func (p *ticketVotePlugin) startRunoffRecordSave(token []byte, srr startRunoffRecord) error {
+func (p *ticketVotePlugin) startRunoffRecordSave(ctx context.Context, token []byte, srr startRunoffRecord) error {
 	be, err := convertBlobEntryFromStartRunoff(srr)
 	if err != nil {
 		return err
 	}
-	err = p.tstore.BlobSave(token, *be)
+	err = p.tstore.BlobSave(ctx, token, *be)
 	if err != nil {
 		return err
 	}
@@ -605,7 +606,7 @@ func (p *ticketVotePlugin) startRunoffRecord(token []byte) (*startRunoffRecord,
 }
 
 // startRunoffForSub starts the voting period for a runoff vote submission.
-func (p *ticketVotePlugin) startRunoffForSub(token []byte, srs startRunoffSubmission) error {
+func (p *ticketVotePlugin) startRunoffForSub(ctx context.Context, token []byte, srs startRunoffSubmission) error {
 	// Sanity check
 	sd := srs.StartDetails
 	t, err := tokenDecode(sd.Params.Token)
@@ -688,7 +689,7 @@ func (p *ticketVotePlugin) startRunoffForSub(token []byte, srs startRunoffSubmis
 	}
 
 	// Save vote details
-	err = p.voteDetailsSave(token, vd)
+	err = p.voteDetailsSave(ctx, token, vd)
 	if err != nil {
 		return fmt.Errorf("voteDetailsSave %x: %v", token, err)
 	}
@@ -706,7 +707,7 @@ func (p *ticketVotePlugin) startRunoffForSub(token []byte, srs startRunoffSubmis
 // startRunoffForParent saves a startRunoffRecord to the parent record. Once
 // this has been saved the runoff vote is considered to be started and the
 // voting period on individual runoff vote submissions can be started.
-func (p *ticketVotePlugin) startRunoffForParent(token []byte, s ticketvote.Start) (*startRunoffRecord, error) {
+func (p *ticketVotePlugin) startRunoffForParent(ctx context.Context, token []byte, s ticketvote.Start) (*startRunoffRecord, error) {
 	// Check if the runoff vote data already exists on the parent tree.
 	srr, err := p.startRunoffRecord(token)
 	if err != nil {
@@ -852,7 +853,7 @@ func (p *ticketVotePlugin) startRunoffForParent(token []byte, s ticketvote.Start
 	}
 
 	// Save start runoff record
-	err = p.startRunoffRecordSave(token, *srr)
+	err = p.startRunoffRecordSave(ctx, token, *srr)
 	if err != nil {
 		return nil, fmt.Errorf("startRunoffRecordSave %x: %v",
 			token, err)
@@ -867,7 +868,7 @@ func (p *ticketVotePlugin) startRunoffForParent(token []byte, s ticketvote.Start
 // to have started. The voting period must now be started on all of the runoff
 // vote submissions individually. If any of these calls fail, they can be
 // retried.  This function will pick up where it left off.
-func (p *ticketVotePlugin) startRunoff(token []byte, s ticketvote.Start) (*ticketvote.StartReply, error) {
+func (p *ticketVotePlugin) startRunoff(ctx context.Context, token []byte, s ticketvote.Start) (*ticketvote.StartReply, error) {
 	// Sanity check
 	if len(s.Starts) == 0 {
 		return nil, fmt.Errorf("no start details found")
@@ -987,7 +988,7 @@ func (p *ticketVotePlugin) startRunoff(token []byte, s ticketvote.Start) (*ticke
 
 	// This function is being invoked on the runoff vote parent record.
 	// Create and save a start runoff record onto the parent record's tree.
-	srr, err := p.startRunoffForParent(token, s)
+	srr, err := p.startRunoffForParent(ctx, token, s)
 	if err != nil {
 		return nil, err
 	}
@@ -1007,7 +1008,7 @@ func (p *ticketVotePlugin) startRunoff(token []byte, s ticketvote.Start) (*ticke
 		if err != nil {
 			return nil, err
 		}
-		_, err = p.backend.PluginWrite(token, ticketvote.PluginID,
+		_, err = p.backend.PluginWrite(ctx, token, ticketvote.PluginID,
 			cmdStartRunoffSubmission, string(b))
 		if err != nil {
 			var ue backend.PluginError
@@ -1030,7 +1031,7 @@ func (p *ticketVotePlugin) startRunoff(token []byte, s ticketvote.Start) (*ticke
 
 // cmdStartRunoffSubmission is an internal plugin command that is used to start
 // the voting period on a runoff vote submission.
-func (p *ticketVotePlugin) cmdStartRunoffSubmission(token []byte, payload string) (string, error) {
+func (p *ticketVotePlugin) cmdStartRunoffSubmission(ctx context.Context, token []byte, payload string) (string, error) {
 	// Decode payload
 	var srs startRunoffSubmission
 	err := json.Unmarshal([]byte(payload), &srs)
@@ -1039,7 +1040,7 @@ func (p *ticketVotePlugin) cmdStartRunoffSubmission(token []byte, payload string
 	}
 
 	// Start voting period on runoff vote submission
-	err = p.startRunoffForSub(token, srs)
+	err = p.startRunoffForSub(ctx, token, srs)
 	if err != nil {
 		return "", err
 	}
@@ -1048,7 +1049,7 @@ func (p *ticketVotePlugin) cmdStartRunoffSubmission(token []byte, payload string
 }
 
 // cmdStart starts a ticket vote.
-func (p *ticketVotePlugin) cmdStart(token []byte, payload string) (string, error) {
+func (p *ticketVotePlugin) cmdStart(ctx context.Context, token []byte, payload string) (string, error) {
 	// Decode payload
 	var s ticketvote.Start
 	err := json.Unmarshal([]byte(payload), &s)
@@ -1070,12 +1071,12 @@ func (p *ticketVotePlugin) cmdStart(token []byte, payload string) (string, error
 	var sr *ticketvote.StartReply
 	switch vtype {
 	case ticketvote.VoteTypeStandard:
-		sr, err = p.startStandard(token, s)
+		sr, err = p.startStandard(ctx, token, s)
 		if err != nil {
 			return "", err
 		}
 	case ticketvote.VoteTypeRunoff:
-		sr, err = p.startRunoff(token, s)
+		sr, err = p.startRunoff(ctx, token, s)
 		if err != nil {
 			return "", err
 		}
@@ -1177,7 +1178,7 @@ type voteCollider struct {
 }
 
 // voteColliderSave saves a voteCollider to the backend.
-func (p *ticketVotePlugin) voteColliderSave(token []byte, vc voteCollider) error {
+func (p *ticketVotePlugin) voteColliderSave(ctx context.Context, token []byte, vc voteCollider) error {
 	// Prepare blob
 	be, err := convertBlobEntryFromVoteCollider(vc)
 	if err != nil {
@@ -1185,7 +1186,7 @@ func (p *ticketVotePlugin) voteColliderSave(token []byte, vc voteCollider) error
 	}
 
 	// Save blob
-	return p.tstore.BlobSave(token, *be)
+	return p.tstore.BlobSave(ctx, token, *be)
 }
 
 // ballotResults is used to aggregate data for votes that are cast
@@ -1194,6 +1195,7 @@ type ballotResults struct {
 	sync.RWMutex
 	addrs   map[string]string                   // [ticket]commitmentAddr
 	replies map[string]ticketvote.CastVoteReply // [ticket]CastVoteReply
+	votes   map[string]string                   // [ticket]voteBit
 }
 
 // newBallotResults returns a new ballotResults context.
@@ -1201,6 +1203,7 @@ func newBallotResults() ballotResults {
 	return ballotResults{
 		addrs:   make(map[string]string, 40960),
 		replies: make(map[string]ticketvote.CastVoteReply, 40960),
+		votes:   make(map[string]string, 40960),
 	}
 }
 
@@ -1238,6 +1241,14 @@ func (r *ballotResults) replyGet(ticket string) (ticketvote.CastVoteReply, bool)
 	return cvr, ok
 }
 
+// voteSet sets the vote bit of a ticket whose vote was successfully cast.
+func (r *ballotResults) voteSet(ticket, voteBit string) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.votes[ticket] = voteBit
+}
+
 // repliesLen returns the number of replies in the ballot results.
 func (r *ballotResults) repliesLen() int {
 	r.RLock()
@@ -1247,7 +1258,7 @@ func (r *ballotResults) repliesLen() int {
 }
 
 // castVoteDetailsSave saves a CastVoteDetails to the backend.
-func (p *ticketVotePlugin) castVoteDetailsSave(token []byte, cv ticketvote.CastVoteDetails) error {
+func (p *ticketVotePlugin) castVoteDetailsSave(ctx context.Context, token []byte, cv ticketvote.CastVoteDetails) error {
 	// Prepare blob
 	be, err := convertBlobEntryFromCastVoteDetails(cv)
 	if err != nil {
@@ -1255,7 +1266,7 @@ func (p *ticketVotePlugin) castVoteDetailsSave(token []byte, cv ticketvote.CastV
 	}
 
 	// Save blob
-	return p.tstore.BlobSave(token, *be)
+	return p.tstore.BlobSave(ctx, token, *be)
 }
 
 // castVoteVerifySignature verifies the signature of a CastVote. The signature
@@ -1263,6 +1274,9 @@ func (p *ticketVotePlugin) castVoteDetailsSave(token []byte, cv ticketvote.CastV
 // casting a vote.
 func castVoteVerifySignature(cv ticketvote.CastVote, addr string, net *chaincfg.Params) error {
 	msg := cv.Token + cv.Ticket + cv.VoteBit
+	if cv.Nonce != "" {
+		msg += cv.Nonce + strconv.FormatInt(cv.Expiry, 10)
+	}
 
 	// Convert hex signature to base64. This is what the verify
 	// message function expects.
@@ -1287,7 +1301,7 @@ func castVoteVerifySignature(cv ticketvote.CastVote, addr string, net *chaincfg.
 // ballot casts the provided votes concurrently. The vote results are passed
 // back through the results channel to the calling function. This function
 // waits until all provided votes have been cast before returning.
-func (p *ticketVotePlugin) ballot(token []byte, votes []ticketvote.CastVote, br *ballotResults) {
+func (p *ticketVotePlugin) ballot(ctx context.Context, token []byte, votes []ticketvote.CastVote, br *ballotResults) {
 	// Cast the votes concurrently
 	var wg sync.WaitGroup
 	for _, v := range votes {
@@ -1325,17 +1339,18 @@ func (p *ticketVotePlugin) ballot(token []byte, votes []ticketvote.CastVote, br
 
 			// Setup cast vote details
 			cvd = ticketvote.CastVoteDetails{
-				Token:     v.Token,
-				Ticket:    v.Ticket,
-				VoteBit:   v.VoteBit,
-				Signature: v.Signature,
-				Address:   addr,
-				Receipt:   hex.EncodeToString(receipt[:]),
-				Timestamp: time.Now().Unix(),
+				Token:            v.Token,
+				Ticket:           v.Ticket,
+				VoteBit:          v.VoteBit,
+				Signature:        v.Signature,
+				Address:          addr,
+				Receipt:          hex.EncodeToString(receipt[:]),
+				Timestamp:        time.Now().Unix(),
+				ReplayProtection: v.ReplayProtection,
 			}
 
 			// Save cast vote details
-			err = p.castVoteDetailsSave(token, cvd)
+			err = p.castVoteDetailsSave(ctx, token, cvd)
 			if err == plugins.ErrDuplicateBlob {
 				// This cast vote has already been saved. Its
 				// possible that a previous attempt to vote
@@ -1360,7 +1375,7 @@ func (p *ticketVotePlugin) ballot(token []byte, votes []ticketvote.CastVote, br
 				Token:  v.Token,
 				Ticket: v.Ticket,
 			}
-			err = p.voteColliderSave(token, vc)
+			err = p.voteColliderSave(ctx, token, vc)
 			if err != nil {
 				t := time.Now().Unix()
 				log.Errorf("cmdCastBallot: voteColliderSave %v: %v", t, err)
@@ -1376,8 +1391,10 @@ func (p *ticketVotePlugin) ballot(token []byte, votes []ticketvote.CastVote, br
 			cvr.Ticket = v.Ticket
 			cvr.Receipt = cvd.Receipt
 
-			// Update cast votes cache
-			p.activeVotes.AddCastVote(v.Token, v.Ticket, v.VoteBit)
+			// Stash the cast vote. The cast votes cache is
+			// updated in a single batch once the ballot has
+			// been cast.
+			br.voteSet(v.Ticket, v.VoteBit)
 
 		saveReply:
 			// Save the reply
@@ -1392,7 +1409,7 @@ func (p *ticketVotePlugin) ballot(token []byte, votes []ticketvote.CastVote, br
 // cmdCastBallot casts a ballot of votes. This function will not return a user
 // error if one occurs for an individual vote. It will instead return the
 // ballot reply with the error included in the individual cast vote reply.
-func (p *ticketVotePlugin) cmdCastBallot(token []byte, payload string) (string, error) {
+func (p *ticketVotePlugin) cmdCastBallot(ctx context.Context, token []byte, payload string) (string, error) {
 	// Decode payload
 	var cb ticketvote.CastBallot
 	err := json.Unmarshal([]byte(payload), &cb)
@@ -1415,7 +1432,11 @@ func (p *ticketVotePlugin) cmdCastBallot(token []byte, payload string) (string,
 	}
 
 	// Get the data that we need to validate the votes
-	eligible := p.activeVotes.EligibleTickets(token)
+	ballotTickets := make([]string, 0, len(votes))
+	for _, v := range votes {
+		ballotTickets = append(ballotTickets, v.Ticket)
+	}
+	statuses := p.activeVotes.TicketStatuses(token, ballotTickets)
 	voteDetails := p.activeVotes.VoteDetails(token)
 	bestBlock, err := p.bestBlock()
 	if err != nil {
@@ -1484,32 +1505,33 @@ func (p *ticketVotePlugin) cmdCastBallot(token []byte, payload string) (string,
 			continue
 		}
 
-		// Verify ticket is eligible to vote
-		_, ok := eligible[v.Ticket]
-		if !ok {
-			e := ticketvote.VoteErrorTicketNotEligible
-			receipts[k].Ticket = v.Ticket
-			receipts[k].ErrorCode = e
-			receipts[k].ErrorContext = ticketvote.VoteErrors[e]
-			continue
-		}
-
-		// Verify ticket has not already voted
-		isActive, isDup := p.activeVotes.VoteIsDuplicate(v.Token, v.Ticket)
-		if !isActive {
+		// Verify ticket is eligible to vote and has not already voted
+		if statuses == nil {
 			e := ticketvote.VoteErrorVoteStatusInvalid
 			receipts[k].Ticket = v.Ticket
 			receipts[k].ErrorCode = e
 			receipts[k].ErrorContext = fmt.Sprintf("%v: vote is "+
 				"not active", ticketvote.VoteErrors[e])
+			continue
 		}
-		if isDup {
+		switch statuses[v.Ticket] {
+		case ticketStatusNotEligible:
+			e := ticketvote.VoteErrorTicketNotEligible
+			receipts[k].Ticket = v.Ticket
+			receipts[k].ErrorCode = e
+			receipts[k].ErrorContext = ticketvote.VoteErrors[e]
+			continue
+		case ticketStatusVoted:
 			e := ticketvote.VoteErrorTicketAlreadyVoted
 			receipts[k].Ticket = v.Ticket
 			receipts[k].ErrorCode = e
 			receipts[k].ErrorContext = ticketvote.VoteErrors[e]
 			continue
 		}
+
+		// Mark the ticket as voted so that any additional votes for
+		// this ticket in the same ballot are treated as duplicates.
+		statuses[v.Ticket] = ticketStatusVoted
 	}
 
 	// Setup a ballotResults context. This is used to aggregate the
@@ -1672,8 +1694,11 @@ func (p *ticketVotePlugin) cmdCastBallot(token []byte, payload string) (string,
 		log.Debugf("Casting %v votes in batch %v/%v", len(batch), i+1,
 			len(queue))
 
-		p.ballot(token, batch, &br)
+		p.ballot(ctx, token, batch, &br)
 	}
+
+	// Update the cast votes cache
+	p.activeVotes.AddCastVotes(hex.EncodeToString(token), br.votes)
 	if br.repliesLen() != ballotCount {
 		log.Errorf("Missing results: got %v, want %v",
 			br.repliesLen(), ballotCount)
@@ -1974,8 +1999,94 @@ func (p *ticketVotePlugin) cmdSubmissions(token []byte) (string, error) {
 	return string(reply), nil
 }
 
+// cmdSubmissionsRepair re-verifies the submissions list of a runoff vote
+// parent record and updates it.
+func (p *ticketVotePlugin) cmdSubmissionsRepair(token []byte, payload string) (string, error) {
+	var sr ticketvote.SubmissionsRepair
+	err := json.Unmarshal([]byte(payload), &sr)
+	if err != nil {
+		return "", err
+	}
+
+	// Verify the record is a runoff vote parent
+	r, err := p.recordAbridged(token)
+	if err != nil {
+		return "", err
+	}
+	vm, err := voteMetadataDecode(r.Files)
+	if err != nil {
+		return "", err
+	}
+	if vm == nil || vm.LinkBy == 0 {
+		return "", backend.PluginError{
+			PluginID:     ticketvote.PluginID,
+			ErrorCode:    uint32(ticketvote.ErrorCodeVoteParentInvalid),
+			ErrorContext: "record is not a runoff vote parent",
+		}
+	}
+
+	// Verify the provided tokens
+	for _, v := range sr.Tokens {
+		_, err := tokenDecode(v)
+		if err != nil {
+			return "", backend.PluginError{
+				PluginID:     ticketvote.PluginID,
+				ErrorCode:    uint32(ticketvote.ErrorCodeTokenInvalid),
+				ErrorContext: fmt.Sprintf("%v: %v", v, util.TokenRegexp()),
+			}
+		}
+	}
+
+	added, removed, err := p.submissionsCacheRepair(token, sr.Tokens)
+	if err != nil {
+		return "", err
+	}
+
+	// Prepare reply
+	srr := ticketvote.SubmissionsRepairReply{
+		Added:   added,
+		Removed: removed,
+	}
+	reply, err := json.Marshal(srr)
+	if err != nil {
+		return "", err
+	}
+
+	return string(reply), nil
+}
+
+// submissionVerify returns whether the record belongs in the submissions list
+// of the provided parent record, i.e. whether it is a public record that links
+// to the parent record. Archived records remain in the list since they were
+// public when they were added.
+func (p *ticketVotePlugin) submissionVerify(parentToken, childToken string) (bool, error) {
+	token, err := tokenDecode(childToken)
+	if err != nil {
+		return false, err
+	}
+	r, err := p.recordAbridged(token)
+	if errors.Is(err, backend.ErrRecordNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if r.RecordMetadata.State != backend.StateVetted {
+		return false, nil
+	}
+	switch r.RecordMetadata.Status {
+	case backend.StatusPublic, backend.StatusArchived:
+	default:
+		return false, nil
+	}
+	vm, err := voteMetadataDecode(r.Files)
+	if err != nil {
+		return false, err
+	}
+	return vm != nil && vm.LinkTo == parentToken, nil
+}
+
 // authSave saves a AuthDetails to the backend.
-func (p *ticketVotePlugin) authSave(token []byte, ad ticketvote.AuthDetails) error {
+func (p *ticketVotePlugin) authSave(ctx context.Context, token []byte, ad ticketvote.AuthDetails) error {
 	// Prepare blob
 	be, err := convertBlobEntryFromAuthDetails(ad)
 	if err != nil {
@@ -1983,7 +2094,7 @@ func (p *ticketVotePlugin) authSave(token []byte, ad ticketvote.AuthDetails) err
 	}
 
 	// Save blob
-	return p.tstore.BlobSave(token, *be)
+	return p.tstore.BlobSave(ctx, token, *be)
 }
 
 // auths returns all AuthDetails for a record.
@@ -2015,7 +2126,7 @@ func (p *ticketVotePlugin) auths(token []byte) ([]ticketvote.AuthDetails, error)
 }
 
 // voteDetailsSave saves a VoteDetails to the backend.
-func (p *ticketVotePlugin) voteDetailsSave(token []byte, vd ticketvote.VoteDetails) error {
+func (p *ticketVotePlugin) voteDetailsSave(ctx context.Context, token []byte, vd ticketvote.VoteDetails) error {
 	// Prepare blob
 	be, err := convertBlobEntryFromVoteDetails(vd)
 	if err != nil {
@@ -2023,7 +2134,7 @@ func (p *ticketVotePlugin) voteDetailsSave(token []byte, vd ticketvote.VoteDetai
 	}
 
 	// Save blob
-	return p.tstore.BlobSave(token, *be)
+	return p.tstore.BlobSave(ctx, token, *be)
 }
 
 // voteDetails returns the VoteDetails for a record. Nil is returned if a vote
@@ -5,6 +5,7 @@
 package ticketvote
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"io/ioutil"
@@ -179,3 +180,69 @@ func (p *ticketVotePlugin) submissionsCacheDel(parentToken, childToken string) e
 
 	return nil
 }
+
+// submissionsCacheRepair re-verifies the tokens in the cached submissions
+// list for the parentToken, along with the provided candidate tokens, and
+// saves the records that belong in the list. It returns the tokens that were
+// added to and removed from the list.
+//
+// This function must be called WITHOUT the mtxSubs lock held.
+func (p *ticketVotePlugin) submissionsCacheRepair(parent []byte, candidates []string) ([]string, []string, error) {
+	p.mtxSubs.Lock()
+	defer p.mtxSubs.Unlock()
+
+	// Get existing submissions list
+	s, err := p.submissionsCacheWithLock(parent)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Verify the existing and the candidate submissions
+	var (
+		parentToken = hex.EncodeToString(parent)
+		repaired    = make(map[string]struct{}, len(s.Tokens))
+		added       = make([]string, 0, len(candidates))
+		removed     = make([]string, 0, len(s.Tokens))
+	)
+	for token := range s.Tokens {
+		ok, err := p.submissionVerify(parentToken, token)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			removed = append(removed, token)
+			continue
+		}
+		repaired[token] = struct{}{}
+	}
+	for _, token := range candidates {
+		if _, ok := s.Tokens[token]; ok {
+			continue
+		}
+		ok, err := p.submissionVerify(parentToken, token)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			continue
+		}
+		repaired[token] = struct{}{}
+		added = append(added, token)
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return added, removed, nil
+	}
+
+	// Save list
+	err = p.submissionsCacheSaveWithLock(parent, submissions{
+		Tokens: repaired,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	log.Infof("Submissions list repaired: parent %v added %v removed %v",
+		parentToken, added, removed)
+
+	return added, removed, nil
+}
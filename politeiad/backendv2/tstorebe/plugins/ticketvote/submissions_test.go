@@ -0,0 +1,152 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ticketvote
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"testing"
+
+	backend "github.com/decred/politeia/politeiad/backendv2"
+	"github.com/decred/politeia/politeiad/plugins/ticketvote"
+)
+
+// testBackend is a backend that returns the records of a map. Only the
+// methods that are used by the tests are implemented.
+type testBackend struct {
+	backend.Backend
+	records map[string]backend.Record // [token]Record
+}
+
+// Records satisfies the backend Backend interface.
+func (b *testBackend) Records(reqs []backend.RecordRequest) (map[string]backend.Record, error) {
+	records := make(map[string]backend.Record, len(reqs))
+	for _, v := range reqs {
+		token := hex.EncodeToString(v.Token)
+		if r, ok := b.records[token]; ok {
+			records[token] = r
+		}
+	}
+	return records, nil
+}
+
+// submissionRecord returns a record with the provided state and status that
+// links to the provided parent.
+func submissionRecord(t *testing.T, state backend.StateT, status backend.StatusT, linkTo string) backend.Record {
+	t.Helper()
+
+	b, err := json.Marshal(ticketvote.VoteMetadata{
+		LinkTo: linkTo,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return backend.Record{
+		RecordMetadata: backend.RecordMetadata{
+			State:  state,
+			Status: status,
+		},
+		Files: []backend.File{
+			{
+				Name:    ticketvote.FileNameVoteMetadata,
+				Payload: base64.StdEncoding.EncodeToString(b),
+			},
+		},
+	}
+}
+
+func TestSubmissionsCacheRepair(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", ticketvote.PluginID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	var (
+		parent = "0000000000000001"
+
+		listed    = "0000000000000002" // Listed and valid
+		censored  = "0000000000000003" // Listed but censored
+		unlinked  = "0000000000000004" // Listed but links elsewhere
+		missing   = "0000000000000005" // Listed but not found
+		candidate = "0000000000000006" // Not listed and valid
+		unvetted  = "0000000000000007" // Not listed and unvetted
+	)
+	p := &ticketVotePlugin{
+		dataDir: dataDir,
+		backend: &testBackend{
+			records: map[string]backend.Record{
+				listed: submissionRecord(t, backend.StateVetted,
+					backend.StatusPublic, parent),
+				censored: submissionRecord(t, backend.StateVetted,
+					backend.StatusCensored, parent),
+				unlinked: submissionRecord(t, backend.StateVetted,
+					backend.StatusPublic, "0000000000000008"),
+				candidate: submissionRecord(t, backend.StateVetted,
+					backend.StatusArchived, parent),
+				unvetted: submissionRecord(t, backend.StateUnvetted,
+					backend.StatusUnreviewed, parent),
+			},
+		},
+	}
+	for _, v := range []string{listed, censored, unlinked, missing} {
+		err := p.submissionsCacheAdd(parent, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	parentb, err := tokenDecode(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Repair the list
+	added, removed, err := p.submissionsCacheRepair(parentb,
+		[]string{listed, candidate, unvetted})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(removed)
+	if len(added) != 1 || added[0] != candidate {
+		t.Fatalf("got added %v, want %v", added, []string{candidate})
+	}
+	wantRemoved := []string{censored, unlinked, missing}
+	if len(removed) != len(wantRemoved) {
+		t.Fatalf("got removed %v, want %v", removed, wantRemoved)
+	}
+	for k, v := range wantRemoved {
+		if removed[k] != v {
+			t.Fatalf("got removed %v, want %v", removed, wantRemoved)
+		}
+	}
+
+	// The repaired list must have been saved
+	s, err := p.submissionsCache(parentb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Tokens) != 2 {
+		t.Fatalf("got submissions %v, want %v and %v",
+			s.Tokens, listed, candidate)
+	}
+	for _, v := range []string{listed, candidate} {
+		if _, ok := s.Tokens[v]; !ok {
+			t.Fatalf("submission %v missing from %v", v, s.Tokens)
+		}
+	}
+
+	// Repairing a valid list is a no-op
+	added, removed, err = p.submissionsCacheRepair(parentb, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(added) != 0 || len(removed) != 0 {
+		t.Fatalf("got added %v removed %v, want none", added, removed)
+	}
+}
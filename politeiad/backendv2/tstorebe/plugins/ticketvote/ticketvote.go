@@ -5,8 +5,10 @@
 package ticketvote
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -59,6 +61,10 @@ type ticketVotePlugin struct {
 	linkByPeriodMax int64  // In seconds
 	voteDurationMin uint32 // In blocks
 	voteDurationMax uint32 // In blocks
+
+	// schema is the schema of the plugin settings. The setting
+	// defaults depend on the active network.
+	schema []backend.PluginSettingSchema
 }
 
 // Setup performs any plugin setup that is required.
@@ -140,10 +146,12 @@ func (p *ticketVotePlugin) Setup() error {
 		if err != nil {
 			return err
 		}
+		// Add cast votes to the active votes cache
+		votes := make(map[string]string, len(rr.Votes))
 		for _, v := range rr.Votes {
-			// Add cast vote to the active votes cache
-			p.activeVotes.AddCastVote(v.Token, v.Ticket, v.VoteBit)
+			votes[v.Ticket] = v.VoteBit
 		}
+		p.activeVotes.AddCastVotes(dr.Vote.Params.Token, votes)
 	}
 
 	return nil
@@ -152,16 +160,16 @@ func (p *ticketVotePlugin) Setup() error {
 // Cmd executes a plugin command.
 //
 // This function satisfies the plugins PluginClient interface.
-func (p *ticketVotePlugin) Cmd(token []byte, cmd, payload string) (string, error) {
+func (p *ticketVotePlugin) Cmd(ctx context.Context, token []byte, cmd, payload string) (string, error) {
 	log.Tracef("ticketvote Cmd: %x %v %v", token, cmd, payload)
 
 	switch cmd {
 	case ticketvote.CmdAuthorize:
-		return p.cmdAuthorize(token, payload)
+		return p.cmdAuthorize(ctx, token, payload)
 	case ticketvote.CmdStart:
-		return p.cmdStart(token, payload)
+		return p.cmdStart(ctx, token, payload)
 	case ticketvote.CmdCastBallot:
-		return p.cmdCastBallot(token, payload)
+		return p.cmdCastBallot(ctx, token, payload)
 	case ticketvote.CmdDetails:
 		return p.cmdDetails(token)
 	case ticketvote.CmdResults:
@@ -174,10 +182,12 @@ func (p *ticketVotePlugin) Cmd(token []byte, cmd, payload string) (string, error
 		return p.cmdInventory(payload)
 	case ticketvote.CmdTimestamps:
 		return p.cmdTimestamps(token, payload)
+	case ticketvote.CmdSubmissionsRepair:
+		return p.cmdSubmissionsRepair(token, payload)
 
 		// Internal plugin commands
 	case cmdStartRunoffSubmission:
-		return p.cmdStartRunoffSubmission(token, payload)
+		return p.cmdStartRunoffSubmission(ctx, token, payload)
 	case cmdRunoffDetails:
 		return p.cmdRunoffDetails(token)
 	}
@@ -246,6 +256,69 @@ func (p *ticketVotePlugin) Settings() []backend.PluginSetting {
 	}
 }
 
+// SettingsSchema returns the schema of the plugin settings.
+//
+// This function satisfies the plugins PluginClient interface.
+func (p *ticketVotePlugin) SettingsSchema() []backend.PluginSettingSchema {
+	log.Tracef("ticketvote SettingsSchema")
+
+	return p.schema
+}
+
+// Dependencies returns the IDs of the plugins that this plugin depends on. The
+// ticketvote plugin uses the dcrdata plugin to retrieve the best block and the
+// ticket pool.
+//
+// This function satisfies the plugins PluginClient interface.
+func (p *ticketVotePlugin) Dependencies() []string {
+	log.Tracef("ticketvote Dependencies")
+
+	return []string{
+		dcrdata.PluginID,
+	}
+}
+
+// settingsSchema returns the schema of the ticketvote plugin settings using
+// the provided default values.
+func settingsSchema(linkByPeriodMin, linkByPeriodMax int64, voteDurationMin, voteDurationMax uint32) []backend.PluginSettingSchema {
+	return []backend.PluginSettingSchema{
+		{
+			Key:     ticketvote.SettingKeyLinkByPeriodMin,
+			Type:    backend.PluginSettingTypeInt,
+			Default: strconv.FormatInt(linkByPeriodMin, 10),
+			Min:     0,
+			Max:     math.MaxInt64,
+		},
+		{
+			Key:     ticketvote.SettingKeyLinkByPeriodMax,
+			Type:    backend.PluginSettingTypeInt,
+			Default: strconv.FormatInt(linkByPeriodMax, 10),
+			Min:     0,
+			Max:     math.MaxInt64,
+		},
+		{
+			Key:     ticketvote.SettingKeyVoteDurationMin,
+			Type:    backend.PluginSettingTypeUint,
+			Default: strconv.FormatUint(uint64(voteDurationMin), 10),
+			Min:     1,
+			Max:     math.MaxUint32,
+		},
+		{
+			Key:     ticketvote.SettingKeyVoteDurationMax,
+			Type:    backend.PluginSettingTypeUint,
+			Default: strconv.FormatUint(uint64(voteDurationMax), 10),
+			Min:     1,
+			Max:     math.MaxUint32,
+		},
+	}
+}
+
+// settingsVerify verifies the provided settings against the ticketvote
+// plugin settings schema.
+func settingsVerify(schema []backend.PluginSettingSchema, settings []backend.PluginSetting) error {
+	return backend.PluginSettingsVerify(ticketvote.PluginID, schema, settings)
+}
+
 func New(backend backend.Backend, tstore plugins.TstoreClient, settings []backend.PluginSetting, dataDir string, id *identity.FullIdentity, activeNetParams *chaincfg.Params) (*ticketVotePlugin, error) {
 	// Plugin settings
 	var (
@@ -278,6 +351,14 @@ func New(backend backend.Backend, tstore plugins.TstoreClient, settings []backen
 		return nil, fmt.Errorf("unknown active net: %v", activeNetParams.Name)
 	}
 
+	// Verify the plugin settings
+	schema := settingsSchema(linkByPeriodMin, linkByPeriodMax,
+		voteDurationMin, voteDurationMax)
+	err := settingsVerify(schema, settings)
+	if err != nil {
+		return nil, err
+	}
+
 	// Override defaults with any passed in settings
 	for _, v := range settings {
 		switch v.Key {
@@ -328,7 +409,7 @@ func New(backend backend.Backend, tstore plugins.TstoreClient, settings []backen
 
 	// Create the plugin data directory
 	dataDir = filepath.Join(dataDir, ticketvote.PluginID)
-	err := os.MkdirAll(dataDir, 0700)
+	err = os.MkdirAll(dataDir, 0700)
 	if err != nil {
 		return nil, err
 	}
@@ -344,5 +425,6 @@ func New(backend backend.Backend, tstore plugins.TstoreClient, settings []backen
 		linkByPeriodMax: linkByPeriodMax,
 		voteDurationMin: voteDurationMin,
 		voteDurationMax: voteDurationMax,
+		schema:          schema,
 	}, nil
 }
@@ -5,6 +5,7 @@
 package usermd
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"sync"
@@ -44,7 +45,7 @@ func (p *usermdPlugin) Setup() error {
 // Cmd executes a plugin command.
 //
 // This function satisfies the plugins PluginClient interface.
-func (p *usermdPlugin) Cmd(token []byte, cmd, payload string) (string, error) {
+func (p *usermdPlugin) Cmd(ctx context.Context, token []byte, cmd, payload string) (string, error) {
 	log.Tracef("usermd Cmd: %x %v %v", token, cmd, payload)
 
 	switch cmd {
@@ -99,11 +100,37 @@ func (p *usermdPlugin) Settings() []backend.PluginSetting {
 	return nil
 }
 
+// SettingsSchema returns the schema of the plugin settings. The usermd
+// plugin does not have any settings.
+//
+// This function satisfies the plugins PluginClient interface.
+func (p *usermdPlugin) SettingsSchema() []backend.PluginSettingSchema {
+	log.Tracef("usermd SettingsSchema")
+
+	return nil
+}
+
+// Dependencies returns the IDs of the plugins that this plugin depends on. The
+// usermd plugin does not depend on any other plugins.
+//
+// This function satisfies the plugins PluginClient interface.
+func (p *usermdPlugin) Dependencies() []string {
+	log.Tracef("usermd Dependencies")
+
+	return nil
+}
+
 // New returns a new usermdPlugin.
 func New(tstore plugins.TstoreClient, settings []backend.PluginSetting, dataDir string) (*usermdPlugin, error) {
+	// Verify the plugin settings
+	err := backend.PluginSettingsVerify(usermd.PluginID, nil, settings)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create plugin data directory
 	dataDir = filepath.Join(dataDir, usermd.PluginID)
-	err := os.MkdirAll(dataDir, 0700)
+	err = os.MkdirAll(dataDir, 0700)
 	if err != nil {
 		return nil, err
 	}
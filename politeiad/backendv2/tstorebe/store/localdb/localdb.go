@@ -165,6 +165,30 @@ func (l *localdb) Get(keys []string) (map[string][]byte, error) {
 	return blobs, nil
 }
 
+// Keys returns the keys of all entries in the store.
+//
+// This function satisfies the store BlobKV interface.
+func (l *localdb) Keys() ([]string, error) {
+	log.Tracef("Keys")
+
+	if l.isShutdown() {
+		return nil, store.ErrShutdown
+	}
+
+	keys := make([]string, 0, 1024)
+	iter := l.db.NewIterator(nil, nil)
+	for iter.Next() {
+		keys = append(keys, string(iter.Key()))
+	}
+	iter.Release()
+	err := iter.Error()
+	if err != nil {
+		return nil, fmt.Errorf("iterator: %v", err)
+	}
+
+	return keys, nil
+}
+
 // Closes closes the store connection.
 //
 // This function satisfies the store BlobKV interface.
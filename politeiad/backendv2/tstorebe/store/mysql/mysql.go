@@ -266,6 +266,42 @@ func (s *mysql) Get(keys []string) (map[string][]byte, error) {
 	return reply, nil
 }
 
+// Keys returns the keys of all entries in the store.
+//
+// This function satisfies the store BlobKV interface.
+func (s *mysql) Keys() ([]string, error) {
+	log.Tracef("Keys")
+
+	if s.isShutdown() {
+		return nil, store.ErrShutdown
+	}
+
+	ctx, cancel := ctxWithTimeout()
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT k FROM kv;")
+	if err != nil {
+		return nil, fmt.Errorf("query: %v", err)
+	}
+	defer rows.Close()
+
+	keys := make([]string, 0, 1024)
+	for rows.Next() {
+		var k string
+		err = rows.Scan(&k)
+		if err != nil {
+			return nil, fmt.Errorf("scan: %v", err)
+		}
+		keys = append(keys, k)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, fmt.Errorf("next: %v", err)
+	}
+
+	return keys, nil
+}
+
 // Closes closes the blob store connection.
 func (s *mysql) Close() {
 	log.Tracef("Close")
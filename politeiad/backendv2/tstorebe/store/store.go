@@ -98,6 +98,9 @@ type BlobKV interface {
 	// was returned for all provided keys.
 	Get(keys []string) (map[string][]byte, error)
 
+	// Keys returns the keys of all entries in the store.
+	Keys() ([]string, error)
+
 	// Closes closes the store connection.
 	Close()
 }
@@ -6,6 +6,7 @@ package tstore
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
@@ -23,13 +24,24 @@ import (
 // digest of the data, i.e. BlobEntry.Digest, can be thought of as the blob ID
 // and can be used to get/del the blob from tstore.
 //
+// The save is traced when the context contains a trace span.
+//
 // This function satisfies the plugins TstoreClient interface.
-func (t *Tstore) BlobSave(token []byte, be store.BlobEntry) error {
+func (t *Tstore) BlobSave(ctx context.Context, token []byte, be store.BlobEntry) (err error) {
 	log.Tracef("BlobSave: %x", token)
 
+	ctx, span := startSpan(ctx, "BlobSave", token)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+
 	// Verify tree is not frozen
 	treeID := treeIDFromToken(token)
+	tspan := t.startTlogSpan(ctx, "LeavesAll", treeID)
 	leaves, err := t.leavesAll(treeID)
+	tspan.SetError(err)
+	tspan.End()
 	if err != nil {
 		return err
 	}
@@ -96,7 +108,10 @@ func (t *Tstore) BlobSave(token []byte, be store.BlobEntry) error {
 	}
 
 	// Append log leaf to trillian tree
+	tspan = t.startTlogSpan(ctx, "LeavesAppend", treeID)
 	queued, _, err := t.tlog.LeavesAppend(treeID, leaves)
+	tspan.SetError(err)
+	tspan.End()
 	if err != nil {
 		return fmt.Errorf("LeavesAppend: %v", err)
 	}
@@ -120,13 +135,24 @@ func (t *Tstore) BlobSave(token []byte, be store.BlobEntry) error {
 // BlobsDel deletes the blobs that correspond to the provided digests. Blobs
 // can be deleted from both frozen and non-frozen records.
 //
+// The delete is traced when the context contains a trace span.
+//
 // This function satisfies the plugins TstoreClient interface.
-func (t *Tstore) BlobsDel(token []byte, digests [][]byte) error {
+func (t *Tstore) BlobsDel(ctx context.Context, token []byte, digests [][]byte) (err error) {
 	log.Tracef("BlobsDel: %x %x", token, digests)
 
+	ctx, span := startSpan(ctx, "BlobsDel", token)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+
 	// Get all tree leaves
 	treeID := treeIDFromToken(token)
+	tspan := t.startTlogSpan(ctx, "LeavesAll", treeID)
 	leaves, err := t.leavesAll(treeID)
+	tspan.SetError(err)
+	tspan.End()
 	if err != nil {
 		return err
 	}
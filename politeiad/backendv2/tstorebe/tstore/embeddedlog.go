@@ -0,0 +1,817 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package tstore
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/decred/politeia/politeiad/backendv2/tstorebe/store"
+	"github.com/google/trillian"
+	tcrypto "github.com/google/trillian/crypto"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/crypto/keyspb"
+	"github.com/google/trillian/crypto/sigpb"
+	"github.com/google/trillian/types"
+	rstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// embeddedTreesKey is the kv store key for the list of tree IDs
+	// of the embedded log.
+	embeddedTreesKey = "embeddedlog-trees"
+
+	// embeddedTreePrefix is the kv store key prefix for an embedded
+	// log tree. The key is the prefix followed by the tree ID.
+	embeddedTreePrefix = "embeddedlog-tree-"
+
+	// embeddedLeafPrefix is the kv store key prefix for an embedded
+	// log leaf. The key is the prefix followed by the tree ID and the
+	// leaf index, i.e. "embeddedlog-leaf-{treeID}-{leafIndex}".
+	embeddedLeafPrefix = "embeddedlog-leaf-"
+
+	// embeddedNodePrefix is the kv store key prefix for the hash of a
+	// merkle tree node, i.e. "embeddedlog-node-{treeID}-{level}-{index}".
+	embeddedNodePrefix = "embeddedlog-node-"
+
+	// embeddedHashPrefix is the kv store key prefix for the leaf index
+	// of a merkle leaf hash, i.e. "embeddedlog-hash-{treeID}-{hash}".
+	embeddedHashPrefix = "embeddedlog-hash-"
+)
+
+var (
+	_ tlogClient = (*embeddedLog)(nil)
+)
+
+// embeddedTree is the kv store representation of an embedded log tree.
+//
+// The tree only contains the compact range of the merkle tree, i.e. the roots
+// of the perfect subtrees that make up the tree ordered from left to right.
+// This is all that is needed to append a leaf and compute the new log root.
+// The hashes of the merkle tree nodes are saved separately as leaves are
+// appended so that inclusion proofs can be computed without retrieving all of
+// the leaves.
+type embeddedTree struct {
+	TreeID    int64              `json:"treeid"`
+	TreeState trillian.TreeState `json:"treestate"`
+	Revision  uint64             `json:"revision"`  // Log root revision
+	Timestamp int64              `json:"timestamp"` // Log root unix nano
+	TreeSize  int64              `json:"treesize"`  // Number of leaves
+	RootHash  []byte             `json:"roothash"`  // Log root hash
+	Range     [][]byte           `json:"range"`     // Compact range
+}
+
+// embeddedLeaf is the kv store representation of an embedded log leaf.
+type embeddedLeaf struct {
+	MerkleLeafHash []byte `json:"merkleleafhash"`
+	LeafValue      []byte `json:"leafvalue"`
+	ExtraData      []byte `json:"extradata"`
+}
+
+// merkleNode identifies a node of an embedded log merkle tree. The node at
+// level l with index i is the root of the perfect subtree that contains the
+// leaves [i*2^l, (i+1)*2^l). The nodes at level 0 are the merkle leaf hashes.
+type merkleNode struct {
+	level uint
+	index int64
+}
+
+// embeddedLog implements the tlogClient interface using an append-only
+// merkle log that is saved to the tstore kv store. It removes the need to run
+// a trillian log server and log signer alongside politeiad.
+//
+// Log roots and inclusion proofs are computed using the same RFC 6962 hashing
+// scheme that trillian uses and log roots are signed using the tlog signing
+// key, so they can be verified using the trillian client libraries.
+//
+// A tstore instance that was created using one tlogClient implementation
+// cannot be switched to a different implementation. The trees of the two
+// implementations are not compatible. The tlog type is saved to the kv store
+// and politeiad refuses to start when it does not match the configured type.
+type embeddedLog struct {
+	sync.Mutex // Protects tree writes
+	store      store.BlobKV
+	signer     *tcrypto.Signer
+}
+
+// embeddedTreeKey returns the kv store key for an embedded log tree.
+func embeddedTreeKey(treeID int64) string {
+	return embeddedTreePrefix + strconv.FormatInt(treeID, 10)
+}
+
+// embeddedLeafKey returns the kv store key for an embedded log leaf.
+func embeddedLeafKey(treeID int64, leafIndex int) string {
+	return embeddedLeafPrefix + strconv.FormatInt(treeID, 10) + "-" +
+		strconv.Itoa(leafIndex)
+}
+
+// embeddedNodeKey returns the kv store key for the hash of a merkle tree node.
+func embeddedNodeKey(treeID int64, n merkleNode) string {
+	return embeddedNodePrefix + strconv.FormatInt(treeID, 10) + "-" +
+		strconv.FormatUint(uint64(n.level), 10) + "-" +
+		strconv.FormatInt(n.index, 10)
+}
+
+// embeddedHashKey returns the kv store key for the leaf index of a merkle
+// leaf hash.
+func embeddedHashKey(treeID int64, merkleLeafHash []byte) string {
+	return embeddedHashPrefix + strconv.FormatInt(treeID, 10) + "-" +
+		hex.EncodeToString(merkleLeafHash)
+}
+
+// treeIDs returns the IDs of all trees in the embedded log.
+func (e *embeddedLog) treeIDs() ([]int64, error) {
+	blobs, err := e.store.Get([]string{embeddedTreesKey})
+	if err != nil {
+		return nil, err
+	}
+	b, ok := blobs[embeddedTreesKey]
+	if !ok {
+		return []int64{}, nil
+	}
+	var ids []int64
+	err = json.Unmarshal(b, &ids)
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// treeGet returns an embedded log tree. A grpc NotFound error is returned if
+// the tree does not exist so that the error is handled the same way that a
+// trillian error is handled.
+func (e *embeddedLog) treeGet(treeID int64) (*embeddedTree, error) {
+	key := embeddedTreeKey(treeID)
+	blobs, err := e.store.Get([]string{key})
+	if err != nil {
+		return nil, err
+	}
+	b, ok := blobs[key]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "tree %v not found", treeID)
+	}
+	var et embeddedTree
+	err = json.Unmarshal(b, &et)
+	if err != nil {
+		return nil, err
+	}
+	return &et, nil
+}
+
+// logRoot returns the current log root of an embedded log tree.
+func (e *embeddedLog) logRoot(et *embeddedTree) *types.LogRootV1 {
+	return &types.LogRootV1{
+		TreeSize:       uint64(et.TreeSize),
+		RootHash:       et.RootHash,
+		TimestampNanos: uint64(et.Timestamp),
+		Revision:       et.Revision,
+	}
+}
+
+// treeAppend appends a merkle leaf hash onto the compact range of an embedded
+// log tree. The hashes of the merkle tree nodes that are completed by the leaf
+// are added to the provided blobs so that they are saved along with the tree.
+func (e *embeddedLog) treeAppend(et *embeddedTree, merkleLeafHash []byte, blobs map[string][]byte) {
+	var (
+		index = et.TreeSize
+		hash  = merkleLeafHash
+		level uint
+	)
+	blobs[embeddedNodeKey(et.TreeID, merkleNode{0, index})] = hash
+
+	// Each set bit of the leaf index means that the leaf completes a
+	// perfect subtree at the next level. The left sibling of the
+	// subtree is the last entry of the compact range.
+	for ; index>>level&1 == 1; level++ {
+		left := et.Range[len(et.Range)-1]
+		et.Range = et.Range[:len(et.Range)-1]
+		hash = hasher.HashChildren(left, hash)
+		n := merkleNode{level + 1, index >> (level + 1)}
+		blobs[embeddedNodeKey(et.TreeID, n)] = hash
+	}
+	et.Range = append(et.Range, hash)
+	et.TreeSize++
+
+	// Update the root hash
+	root := et.Range[len(et.Range)-1]
+	for i := len(et.Range) - 2; i >= 0; i-- {
+		root = hasher.HashChildren(et.Range[i], root)
+	}
+	et.RootHash = root
+}
+
+// nodesGet returns the hashes of the merkle tree nodes that make up the
+// provided leaf ranges.
+func (e *embeddedLog) nodesGet(treeID int64, ranges [][2]int64) (map[merkleNode][]byte, error) {
+	var (
+		nodes = make(map[string]merkleNode, len(ranges))
+		keys  = make([]string, 0, len(ranges))
+	)
+	for _, r := range ranges {
+		for _, n := range merkleRangeNodes(r[0], r[1]) {
+			key := embeddedNodeKey(treeID, n)
+			if _, ok := nodes[key]; ok {
+				continue
+			}
+			nodes[key] = n
+			keys = append(keys, key)
+		}
+	}
+	blobs, err := e.store.Get(keys)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make(map[merkleNode][]byte, len(keys))
+	for _, v := range keys {
+		b, ok := blobs[v]
+		if !ok {
+			return nil, fmt.Errorf("node not found: %v", v)
+		}
+		hashes[nodes[v]] = b
+	}
+	return hashes, nil
+}
+
+// proofs returns the inclusion proofs for the leaves at the provided indexes
+// against the tree of the provided size, as well as the root hash of the tree
+// of that size. The tree size may be a previous size of the tree.
+func (e *embeddedLog) proofs(treeID int64, indexes []int64, treeSize int64) ([]*trillian.Proof, []byte, error) {
+	var (
+		ranges = [][2]int64{{0, treeSize}}
+		paths  = make([][][2]int64, 0, len(indexes))
+	)
+	for _, v := range indexes {
+		path := merklePathRanges(v, 0, treeSize)
+		paths = append(paths, path)
+		ranges = append(ranges, path...)
+	}
+	nodes, err := e.nodesGet(treeID, ranges)
+	if err != nil {
+		return nil, nil, err
+	}
+	proofs := make([]*trillian.Proof, 0, len(indexes))
+	for k, path := range paths {
+		hashes := make([][]byte, 0, len(path))
+		for _, r := range path {
+			hashes = append(hashes, merkleRangeHash(nodes, r[0], r[1]))
+		}
+		proofs = append(proofs, &trillian.Proof{
+			LeafIndex: indexes[k],
+			Hashes:    hashes,
+		})
+	}
+
+	return proofs, merkleRangeHash(nodes, 0, treeSize), nil
+}
+
+// TreeNew creates a new tree.
+//
+// This function satisfies the tlogClient interface.
+func (e *embeddedLog) TreeNew() (*trillian.Tree, *trillian.SignedLogRoot, error) {
+	log.Tracef("embeddedlog TreeNew")
+
+	e.Lock()
+	defer e.Unlock()
+
+	ids, err := e.treeIDs()
+	if err != nil {
+		return nil, nil, fmt.Errorf("treeIDs: %v", err)
+	}
+	exists := make(map[int64]struct{}, len(ids))
+	for _, v := range ids {
+		exists[v] = struct{}{}
+	}
+
+	// Create a random tree ID. Tree IDs must be positive.
+	var treeID int64
+	for {
+		b := make([]byte, 8)
+		_, err := rand.Read(b)
+		if err != nil {
+			return nil, nil, err
+		}
+		treeID = int64(binary.LittleEndian.Uint64(b) &^ (1 << 63))
+		if _, ok := exists[treeID]; treeID != 0 && !ok {
+			break
+		}
+	}
+
+	// Save the tree
+	et := embeddedTree{
+		TreeID:    treeID,
+		TreeState: trillian.TreeState_ACTIVE,
+		Timestamp: time.Now().UnixNano(),
+		RootHash:  hasher.EmptyRoot(),
+		Range:     [][]byte{},
+	}
+	bt, err := json.Marshal(et)
+	if err != nil {
+		return nil, nil, err
+	}
+	bids, err := json.Marshal(append(ids, treeID))
+	if err != nil {
+		return nil, nil, err
+	}
+	err = e.store.Put(map[string][]byte{
+		embeddedTreeKey(treeID): bt,
+		embeddedTreesKey:        bids,
+	}, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("put: %v", err)
+	}
+
+	slr, err := e.signer.SignLogRoot(e.logRoot(&et))
+	if err != nil {
+		return nil, nil, fmt.Errorf("SignLogRoot: %v", err)
+	}
+
+	log.Debugf("Created tree: %v", treeID)
+
+	return convertEmbeddedTree(et), slr, nil
+}
+
+// treeRestore creates a tree using the provided tree ID if it does not exist
+// yet. It is used to restore a tree from a replication stream, where the tree
+// ID must be preserved since it is the record token.
+func (e *embeddedLog) treeRestore(treeID int64) error {
+	e.Lock()
+	defer e.Unlock()
+
+	_, err := e.treeGet(treeID)
+	if err == nil {
+		return nil
+	}
+	if status.Code(err) != codes.NotFound {
+		return err
+	}
+	ids, err := e.treeIDs()
+	if err != nil {
+		return fmt.Errorf("treeIDs: %v", err)
+	}
+	et := embeddedTree{
+		TreeID:    treeID,
+		TreeState: trillian.TreeState_ACTIVE,
+		Timestamp: time.Now().UnixNano(),
+		RootHash:  hasher.EmptyRoot(),
+		Range:     [][]byte{},
+	}
+	bt, err := json.Marshal(et)
+	if err != nil {
+		return err
+	}
+	bids, err := json.Marshal(append(ids, treeID))
+	if err != nil {
+		return err
+	}
+	err = e.store.Put(map[string][]byte{
+		embeddedTreeKey(treeID): bt,
+		embeddedTreesKey:        bids,
+	}, false)
+	if err != nil {
+		return fmt.Errorf("put: %v", err)
+	}
+
+	log.Debugf("Restored tree: %v", treeID)
+
+	return nil
+}
+
+// TreeFreeze sets the status of a tree to frozen and returns the updated tree.
+//
+// This function satisfies the tlogClient interface.
+func (e *embeddedLog) TreeFreeze(treeID int64) (*trillian.Tree, error) {
+	log.Tracef("embeddedlog TreeFreeze: %v", treeID)
+
+	e.Lock()
+	defer e.Unlock()
+
+	et, err := e.treeGet(treeID)
+	if err != nil {
+		return nil, err
+	}
+	et.TreeState = trillian.TreeState_FROZEN
+	b, err := json.Marshal(et)
+	if err != nil {
+		return nil, err
+	}
+	err = e.store.Put(map[string][]byte{embeddedTreeKey(treeID): b}, false)
+	if err != nil {
+		return nil, fmt.Errorf("put: %v", err)
+	}
+
+	return convertEmbeddedTree(*et), nil
+}
+
+// Tree returns a tree.
+//
+// This function satisfies the tlogClient interface.
+func (e *embeddedLog) Tree(treeID int64) (*trillian.Tree, error) {
+	log.Tracef("embeddedlog Tree: %v", treeID)
+
+	et, err := e.treeGet(treeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertEmbeddedTree(*et), nil
+}
+
+// TreesAll returns all trees in the embedded log.
+//
+// This function satisfies the tlogClient interface.
+func (e *embeddedLog) TreesAll() ([]*trillian.Tree, error) {
+	log.Tracef("embeddedlog TreesAll")
+
+	ids, err := e.treeIDs()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(ids))
+	for _, v := range ids {
+		keys = append(keys, embeddedTreeKey(v))
+	}
+	blobs, err := e.store.Get(keys)
+	if err != nil {
+		return nil, err
+	}
+	trees := make([]*trillian.Tree, 0, len(ids))
+	for _, v := range keys {
+		b, ok := blobs[v]
+		if !ok {
+			return nil, fmt.Errorf("tree not found: %v", v)
+		}
+		var et embeddedTree
+		err = json.Unmarshal(b, &et)
+		if err != nil {
+			return nil, err
+		}
+		trees = append(trees, convertEmbeddedTree(et))
+	}
+
+	return trees, nil
+}
+
+// LeavesAppend appends leaves onto a tree. The leaves are appended in the
+// order in which they are provided. Leaves that are duplicates of an existing
+// leaf are not appended and are returned with an AlreadyExists status code,
+// the same as trillian.
+//
+// The leaves, the merkle tree nodes, and the updated tree are saved to the kv
+// store atomically.
+//
+// This function satisfies the tlogClient interface.
+func (e *embeddedLog) LeavesAppend(treeID int64, leaves []*trillian.LogLeaf) ([]queuedLeafProof, *types.LogRootV1, error) {
+	log.Tracef("embeddedlog LeavesAppend: %v %v", treeID, len(leaves))
+
+	e.Lock()
+	defer e.Unlock()
+
+	et, err := e.treeGet(treeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if et.TreeState == trillian.TreeState_FROZEN {
+		return nil, nil, fmt.Errorf("tree is frozen")
+	}
+
+	// Lookup the leaves that already exist in the tree
+	keys := make([]string, 0, len(leaves))
+	for _, v := range leaves {
+		v.MerkleLeafHash = merkleLeafHash(v.LeafValue)
+		keys = append(keys, embeddedHashKey(treeID, v.MerkleLeafHash))
+	}
+	exists, err := e.store.Get(keys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Append leaves
+	var (
+		blobs    = make(map[string][]byte, 4*len(leaves)+1)
+		appended = make([]int64, 0, len(leaves))
+		indexes  = make([]int64, len(leaves)) // Leaf index; -1 if not appended
+	)
+	for k, v := range leaves {
+		key := keys[k]
+		if _, ok := exists[key]; ok {
+			indexes[k] = -1
+			continue
+		}
+		if _, ok := blobs[key]; ok {
+			// Duplicate of a leaf in this batch
+			indexes[k] = -1
+			continue
+		}
+		b, err := json.Marshal(embeddedLeaf{
+			MerkleLeafHash: v.MerkleLeafHash,
+			LeafValue:      v.LeafValue,
+			ExtraData:      v.ExtraData,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		index := et.TreeSize
+		blobs[embeddedLeafKey(treeID, int(index))] = b
+		blobs[key] = []byte(strconv.FormatInt(index, 10))
+		e.treeAppend(et, v.MerkleLeafHash, blobs)
+		indexes[k] = index
+		appended = append(appended, index)
+	}
+
+	// Save the leaves and the updated tree
+	if len(appended) > 0 {
+		et.Revision++
+		et.Timestamp = time.Now().UnixNano()
+		b, err := json.Marshal(et)
+		if err != nil {
+			return nil, nil, err
+		}
+		blobs[embeddedTreeKey(treeID)] = b
+		err = e.store.Put(blobs, false)
+		if err != nil {
+			return nil, nil, fmt.Errorf("put: %v", err)
+		}
+	}
+
+	// Get inclusion proofs
+	var (
+		lr = e.logRoot(et)
+		p  []*trillian.Proof
+	)
+	if len(appended) > 0 {
+		p, _, err = e.proofs(treeID, appended, et.TreeSize)
+		if err != nil {
+			return nil, nil, fmt.Errorf("proofs: %v", err)
+		}
+	}
+	proofs := make([]queuedLeafProof, 0, len(leaves))
+	for k, v := range leaves {
+		index := indexes[k]
+		if index == -1 {
+			proofs = append(proofs, queuedLeafProof{
+				QueuedLeaf: &trillian.QueuedLogLeaf{
+					Leaf: v,
+					Status: &rstatus.Status{
+						Code:    int32(codes.AlreadyExists),
+						Message: "leaf already exists",
+					},
+				},
+			})
+			continue
+		}
+		v.LeafIndex = index
+		proofs = append(proofs, queuedLeafProof{
+			QueuedLeaf: &trillian.QueuedLogLeaf{
+				Leaf: v,
+				Status: &rstatus.Status{
+					Code: int32(codes.OK),
+				},
+			},
+			Proof: p[0],
+		})
+		p = p[1:]
+	}
+
+	log.Debugf("Appended leaves (%v/%v) to tree %v",
+		len(appended), len(leaves), treeID)
+
+	return proofs, lr, nil
+}
+
+// leaves returns the leaves of an embedded log tree from the start index up
+// to, but not including, the end index.
+func (e *embeddedLog) leaves(treeID int64, start, end int) ([]*trillian.LogLeaf, error) {
+	keys := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		keys = append(keys, embeddedLeafKey(treeID, i))
+	}
+	blobs, err := e.store.Get(keys)
+	if err != nil {
+		return nil, err
+	}
+	leaves := make([]*trillian.LogLeaf, 0, len(keys))
+	for k, v := range keys {
+		b, ok := blobs[v]
+		if !ok {
+			return nil, fmt.Errorf("leaf not found: %v", v)
+		}
+		var el embeddedLeaf
+		err = json.Unmarshal(b, &el)
+		if err != nil {
+			return nil, err
+		}
+		leaves = append(leaves, &trillian.LogLeaf{
+			MerkleLeafHash: el.MerkleLeafHash,
+			LeafValue:      el.LeafValue,
+			ExtraData:      el.ExtraData,
+			LeafIndex:      int64(start + k),
+		})
+	}
+
+	return leaves, nil
+}
+
+// LeavesAll returns all of the leaves for the provided treeID.
+//
+// This function satisfies the tlogClient interface.
+func (e *embeddedLog) LeavesAll(treeID int64) ([]*trillian.LogLeaf, error) {
+	log.Tracef("embeddedlog LeavesAll: %v", treeID)
+
+	et, err := e.treeGet(treeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.leaves(treeID, 0, int(et.TreeSize))
+}
+
+// LeavesByRange returns up to count leaves of a tree starting at the provided
+// leaf index. Fewer leaves are returned when the end of the tree is reached.
+//
+// This function satisfies the tlogClient interface.
+func (e *embeddedLog) LeavesByRange(treeID, startIndex, count int64) ([]*trillian.LogLeaf, error) {
+	log.Tracef("embeddedlog LeavesByRange: %v %v %v", treeID, startIndex,
+		count)
+
+	et, err := e.treeGet(treeID)
+	if err != nil {
+		return nil, err
+	}
+	if startIndex >= et.TreeSize {
+		return []*trillian.LogLeaf{}, nil
+	}
+	end := startIndex + count
+	if end > et.TreeSize {
+		end = et.TreeSize
+	}
+
+	return e.leaves(treeID, int(startIndex), int(end))
+}
+
+// SignedLogRoot returns the signed log root of a tree.
+//
+// This function satisfies the tlogClient interface.
+func (e *embeddedLog) SignedLogRoot(tree *trillian.Tree) (*trillian.SignedLogRoot, *types.LogRootV1, error) {
+	log.Tracef("embeddedlog SignedLogRoot: %v", tree.TreeId)
+
+	et, err := e.treeGet(tree.TreeId)
+	if err != nil {
+		return nil, nil, err
+	}
+	lr := e.logRoot(et)
+	slr, err := e.signer.SignLogRoot(lr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("SignLogRoot: %v", err)
+	}
+
+	return slr, lr, nil
+}
+
+// InclusionProof returns a proof for the inclusion of a merkle leaf hash in a
+// log root. The log root may be a log root of a previous tree size.
+//
+// This function satisfies the tlogClient interface.
+func (e *embeddedLog) InclusionProof(treeID int64, merkleLeafHash []byte, lr *types.LogRootV1) (*trillian.Proof, error) {
+	log.Tracef("embeddedlog InclusionProof: %v %x", treeID, merkleLeafHash)
+
+	et, err := e.treeGet(treeID)
+	if err != nil {
+		return nil, err
+	}
+	if lr.TreeSize > uint64(et.TreeSize) {
+		return nil, fmt.Errorf("invalid tree size: got %v, tree size %v",
+			lr.TreeSize, et.TreeSize)
+	}
+
+	// Lookup the leaf index
+	key := embeddedHashKey(treeID, merkleLeafHash)
+	blobs, err := e.store.Get([]string{key})
+	if err != nil {
+		return nil, err
+	}
+	b, ok := blobs[key]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "leaf %x not found",
+			merkleLeafHash)
+	}
+	index, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(index) >= lr.TreeSize {
+		return nil, status.Errorf(codes.NotFound, "leaf %x not found at "+
+			"tree size %v", merkleLeafHash, lr.TreeSize)
+	}
+
+	// Get the proof
+	proofs, root, err := e.proofs(treeID, []int64{index}, int64(lr.TreeSize))
+	if err != nil {
+		return nil, fmt.Errorf("proofs: %v", err)
+	}
+	if !bytes.Equal(root, lr.RootHash) {
+		return nil, fmt.Errorf("log root hash mismatch at tree size %v",
+			lr.TreeSize)
+	}
+
+	return proofs[0], nil
+}
+
+// Close closes the client connection. There is nothing to do for the embedded
+// log. The kv store is closed by tstore.
+//
+// This function satisfies the tlogClient interface.
+func (e *embeddedLog) Close() {}
+
+// convertEmbeddedTree converts an embedded log tree into a trillian tree.
+func convertEmbeddedTree(et embeddedTree) *trillian.Tree {
+	return &trillian.Tree{
+		TreeId:             et.TreeID,
+		TreeState:          et.TreeState,
+		TreeType:           trillian.TreeType_LOG,
+		HashStrategy:       trillian.HashStrategy_RFC6962_SHA256,
+		HashAlgorithm:      sigpb.DigitallySigned_SHA256,
+		SignatureAlgorithm: sigpb.DigitallySigned_ED25519,
+	}
+}
+
+// merkleSplit returns the largest power of two that is smaller than n. This
+// is the split point of a merkle tree of size n as defined by RFC 6962.
+func merkleSplit(n int64) int64 {
+	var k int64 = 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+// merkleRangeNodes returns the nodes of the perfect subtrees that make up the
+// leaf range [start, end), ordered from left to right. The start of the range
+// must be aligned to the size of the first subtree, which is the case for all
+// of the subtrees that are defined by RFC 6962.
+func merkleRangeNodes(start, end int64) []merkleNode {
+	nodes := make([]merkleNode, 0, 8)
+	for start < end {
+		var level uint
+		for int64(1)<<(level+1) <= end-start {
+			level++
+		}
+		nodes = append(nodes, merkleNode{level, start >> level})
+		start += int64(1) << level
+	}
+	return nodes
+}
+
+// merkleRangeHash returns the RFC 6962 merkle tree hash of the leaf range
+// [start, end) using the provided node hashes.
+func merkleRangeHash(hashes map[merkleNode][]byte, start, end int64) []byte {
+	nodes := merkleRangeNodes(start, end)
+	if len(nodes) == 0 {
+		return hasher.EmptyRoot()
+	}
+	hash := hashes[nodes[len(nodes)-1]]
+	for i := len(nodes) - 2; i >= 0; i-- {
+		hash = hasher.HashChildren(hashes[nodes[i]], hash)
+	}
+	return hash
+}
+
+// merklePathRanges returns the leaf ranges of the subtrees whose hashes make
+// up the RFC 6962 audit path of the leaf at the provided index in the leaf
+// range [start, end). The ranges are ordered from the leaf to the root, the
+// same as a trillian inclusion proof.
+func merklePathRanges(index, start, end int64) [][2]int64 {
+	if end-start <= 1 {
+		return [][2]int64{}
+	}
+	k := merkleSplit(end - start)
+	if index < start+k {
+		return append(merklePathRanges(index, start, start+k),
+			[2]int64{start + k, end})
+	}
+	return append(merklePathRanges(index, start+k, end),
+		[2]int64{start, start + k})
+}
+
+// newEmbeddedLog returns a new embeddedLog.
+func newEmbeddedLog(kvstore store.BlobKV, privateKey *keyspb.PrivateKey) (*embeddedLog, error) {
+	signer, err := der.UnmarshalPrivateKey(privateKey.Der)
+	if err != nil {
+		return nil, err
+	}
+	return &embeddedLog{
+		store:  kvstore,
+		signer: tcrypto.NewSigner(0, signer, crypto.SHA256),
+	}, nil
+}
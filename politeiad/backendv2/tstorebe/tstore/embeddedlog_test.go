@@ -0,0 +1,221 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package tstore
+
+import (
+	"crypto"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/decred/politeia/politeiad/backendv2/tstorebe/store/localdb"
+	"github.com/google/trillian"
+	"github.com/google/trillian/client"
+	tcrypto "github.com/google/trillian/crypto"
+	"github.com/google/trillian/crypto/keys/der"
+	"github.com/google/trillian/merkle/hashers/registry"
+	"github.com/google/trillian/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestEmbeddedLog(t *testing.T) {
+	// Setup a localdb kv store
+	appDir, err := ioutil.TempDir("", "tstore.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(appDir)
+	kvstore, err := localdb.New(appDir, filepath.Join(appDir, "store"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kvstore.Close()
+
+	// Setup the embedded log
+	key, err := deriveTlogKey(kvstore, "testpassphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := newEmbeddedLog(kvstore, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := der.UnmarshalPrivateKey(key.Der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lh, err := registry.NewLogHasher(trillian.HashStrategy_RFC6962_SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier := client.NewLogVerifier(lh, signer.Public(), crypto.SHA256)
+
+	// Missing trees must return a NotFound error
+	_, err = e.Tree(1)
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("got error %v, want NotFound", err)
+	}
+
+	tree, _, err := e.TreeNew()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Append leaves in multiple batches so that proofs are verified
+	// against trees of different sizes. The last leaf of each batch
+	// is a duplicate.
+	var leaves []*trillian.LogLeaf
+	for i := 0; i < 4; i++ {
+		batch := make([]*trillian.LogLeaf, 0, i+2)
+		for j := 0; j <= i; j++ {
+			v := []byte(strconv.Itoa(len(leaves) + j))
+			batch = append(batch, newLogLeaf(v, nil))
+		}
+		batch = append(batch, newLogLeaf([]byte("0"), nil))
+
+		queued, lr, err := e.LeavesAppend(tree.TreeId, batch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range queued {
+			c := codes.Code(v.QueuedLeaf.GetStatus().GetCode())
+			if k == len(queued)-1 {
+				if c != codes.AlreadyExists {
+					t.Fatalf("duplicate leaf: got code %v", c)
+				}
+				continue
+			}
+			if c != codes.OK {
+				t.Fatalf("got code %v, want OK", c)
+			}
+			err = verifier.VerifyInclusionByHash(lr,
+				v.QueuedLeaf.Leaf.MerkleLeafHash, v.Proof)
+			if err != nil {
+				t.Fatalf("VerifyInclusionByHash: %v", err)
+			}
+			leaves = append(leaves, v.QueuedLeaf.Leaf)
+		}
+
+		// Verify the signed log root
+		slr, lr2, err := e.SignedLogRoot(tree)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = tcrypto.VerifySignedLogRoot(signer.Public(),
+			crypto.SHA256, slr)
+		if err != nil {
+			t.Fatalf("VerifySignedLogRoot: %v", err)
+		}
+		if lr2.TreeSize != uint64(len(leaves)) {
+			t.Fatalf("got tree size %v, want %v", lr2.TreeSize, len(leaves))
+		}
+
+		// All leaves must be provable against the log root of the
+		// batch, including the leaves of previous batches.
+		for _, v := range leaves {
+			p, err := e.InclusionProof(tree.TreeId, v.MerkleLeafHash, lr)
+			if err != nil {
+				t.Fatalf("InclusionProof: %v", err)
+			}
+			err = verifier.VerifyInclusionByHash(lr, v.MerkleLeafHash, p)
+			if err != nil {
+				t.Fatalf("VerifyInclusionByHash %v: %v", v.LeafIndex, err)
+			}
+		}
+	}
+
+	// A leaf cannot be proven against a log root that precedes it
+	_, lr, err := e.SignedLogRoot(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = e.InclusionProof(tree.TreeId, leaves[0].MerkleLeafHash,
+		&types.LogRootV1{TreeSize: 0, RootHash: lr.RootHash})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("got error %v, want NotFound", err)
+	}
+
+	// Verify the leaves
+	all, err := e.LeavesAll(tree.TreeId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != len(leaves) {
+		t.Fatalf("got %v leaves, want %v", len(all), len(leaves))
+	}
+	for k, v := range all {
+		if string(v.LeafValue) != strconv.Itoa(k) || v.LeafIndex != int64(k) {
+			t.Fatalf("leaf %v: got %s at index %v", k, v.LeafValue, v.LeafIndex)
+		}
+	}
+
+	// Frozen trees cannot be appended to
+	_, err = e.TreeFreeze(tree.TreeId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = e.LeavesAppend(tree.TreeId, []*trillian.LogLeaf{
+		newLogLeaf([]byte("frozen"), nil),
+	})
+	if err == nil {
+		t.Fatalf("appended leaf to a frozen tree")
+	}
+	trees, err := e.TreesAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trees) != 1 || trees[0].TreeState != trillian.TreeState_FROZEN {
+		t.Fatalf("unexpected trees: %v", trees)
+	}
+}
+
+func TestVerifyTlogType(t *testing.T) {
+	appDir, err := ioutil.TempDir("", "tstore.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(appDir)
+	kvstore, err := localdb.New(appDir, filepath.Join(appDir, "store"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kvstore.Close()
+
+	// The tlog type is saved on the first startup
+	err = verifyTlogType(kvstore, TlogTypeEmbedded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = verifyTlogType(kvstore, TlogTypeEmbedded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = verifyTlogType(kvstore, TlogTypeTrillian)
+	if err == nil {
+		t.Fatalf("tlog type change was not rejected")
+	}
+
+	// A kv store that was created before the tlog type was saved used
+	// trillian.
+	err = kvstore.Del([]string{tlogTypeKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = deriveTlogKey(kvstore, "testpassphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = verifyTlogType(kvstore, TlogTypeEmbedded)
+	if err == nil {
+		t.Fatalf("tlog type change was not rejected")
+	}
+	err = verifyTlogType(kvstore, TlogTypeTrillian)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
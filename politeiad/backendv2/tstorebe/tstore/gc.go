@@ -0,0 +1,318 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package tstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	backend "github.com/decred/politeia/politeiad/backendv2"
+	"github.com/google/uuid"
+)
+
+const (
+	// gcSchedule determines how often the garbage collector is run.
+	// Seconds Minutes Hours Days Months DayOfWeek
+	gcSchedule = "0 30 3 * * *" // At 03:30 every day
+
+	// GCSafetyWindow is the default amount of time that a blob must be
+	// continuously found to be garbage before it is deleted. Blobs are
+	// saved to the kv store before their log leaf is appended onto the
+	// tlog tree, so a blob that is in the process of being saved will
+	// briefly look like an orphaned blob. The safety window prevents
+	// these blobs from being deleted.
+	GCSafetyWindow = 24 * time.Hour
+
+	// gcFilename is the filename of the file that is used to track
+	// when each garbage blob was first seen. It is saved to the tstore
+	// data dir.
+	gcFilename = "gc.json"
+)
+
+// GCReport contains the results of a garbage collection run.
+type GCReport struct {
+	// Orphans contains the kv store keys of the blobs that are not
+	// referenced by any tlog leaf. These are left behind when
+	// politeiad crashes after a blob was saved to the kv store, but
+	// before its leaf was appended onto the tlog tree.
+	Orphans []string
+
+	// Leftovers contains the kv store keys of the file blobs of
+	// censored records that still exist. These are left behind when
+	// politeiad crashes after a censored record was frozen, but before
+	// its files were deleted.
+	Leftovers []string
+
+	// Deleted contains the kv store keys of the blobs that were
+	// deleted. Only blobs that have been garbage for longer than the
+	// safety window are deleted.
+	Deleted []string
+}
+
+// gcPath returns the path of the file that tracks the garbage blobs.
+func (t *Tstore) gcPath() string {
+	return filepath.Join(t.dataDir, gcFilename)
+}
+
+// gcSeenLoad returns the garbage blobs that were found by previous garbage
+// collection runs and the unix timestamp of when they were first seen.
+func (t *Tstore) gcSeenLoad() (map[string]int64, error) {
+	b, err := ioutil.ReadFile(t.gcPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return make(map[string]int64), nil
+		}
+		return nil, err
+	}
+	var seen map[string]int64
+	err = json.Unmarshal(b, &seen)
+	if err != nil {
+		return nil, err
+	}
+	return seen, nil
+}
+
+// gcSeenSave saves the garbage blobs and the unix timestamp of when they were
+// first seen. The file is replaced atomically.
+func (t *Tstore) gcSeenSave(seen map[string]int64) error {
+	b, err := json.Marshal(seen)
+	if err != nil {
+		return err
+	}
+	tmp := t.gcPath() + ".tmp"
+	err = ioutil.WriteFile(tmp, b, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, t.gcPath())
+}
+
+// isBlobKey returns whether the provided kv store key is the key of a blob
+// that was saved by tstore. The kv store also contains entries that are not
+// tstore blobs, such as the key derivation params, which must never be garbage
+// collected.
+func isBlobKey(key string) bool {
+	_, err := uuid.Parse(strings.TrimPrefix(key, keyPrefixEncrypted))
+	return err == nil
+}
+
+// garbage walks all tlog trees and returns the kv store keys of the orphaned
+// blobs and of the censored record file blobs that still exist.
+func (t *Tstore) garbage() ([]string, []string, error) {
+	// The kv store keys must be retrieved before the trees are walked.
+	// A blob that is saved while the trees are being walked will then
+	// not be in the list of keys, instead of being reported as an
+	// orphan.
+	keys, err := t.store.Keys()
+	if err != nil {
+		return nil, nil, fmt.Errorf("store Keys: %v", err)
+	}
+	trees, err := t.tlog.TreesAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("TreesAll: %v", err)
+	}
+
+	var (
+		// referenced contains the keys of all blobs that have a tlog
+		// leaf.
+		referenced = make(map[string]struct{}, len(keys))
+
+		// deleted contains the keys of the blobs that should have been
+		// deleted, i.e. the file blobs of censored records.
+		deleted = make(map[string]struct{}, 256)
+	)
+	for _, tree := range trees {
+		leaves, err := t.leavesAll(tree.TreeId)
+		if err != nil {
+			return nil, nil, fmt.Errorf("leavesAll %v: %v", tree.TreeId, err)
+		}
+		for _, v := range leaves {
+			ed, err := extraDataDecode(v.ExtraData)
+			if err != nil {
+				return nil, nil, err
+			}
+			// An unvetted blob is re-saved as clear text using the key
+			// without the prefix when a record is made public. Both keys
+			// are referenced by the leaf.
+			referenced[ed.storeKey()] = struct{}{}
+			referenced[ed.storeKeyNoPrefix()] = struct{}{}
+		}
+
+		// The file blobs of censored records are deleted once the tree
+		// has been frozen.
+		idx, err := t.recordIndexLatest(leaves)
+		if errors.Is(err, backend.ErrRecordNotFound) {
+			// No record has been saved to this tree yet
+			continue
+		} else if err != nil {
+			return nil, nil, fmt.Errorf("recordIndexLatest %v: %v",
+				tree.TreeId, err)
+		}
+		if !idx.Frozen {
+			continue
+		}
+		r, err := t.record(tree.TreeId, 0, nil, true)
+		if err != nil {
+			return nil, nil, fmt.Errorf("record %v: %v", tree.TreeId, err)
+		}
+		if r.RecordMetadata.Status != backend.StatusCensored {
+			continue
+		}
+		indexes, err := t.recordIndexes(leaves)
+		if err != nil {
+			return nil, nil, fmt.Errorf("recordIndexes %v: %v",
+				tree.TreeId, err)
+		}
+		fk, err := fileKeys(leaves, indexes)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, v := range fk {
+			deleted[v] = struct{}{}
+		}
+	}
+
+	// Compile the garbage blobs
+	orphans := make([]string, 0, 256)
+	leftovers := make([]string, 0, 256)
+	for _, v := range keys {
+		if !isBlobKey(v) {
+			continue
+		}
+		if _, ok := deleted[v]; ok {
+			leftovers = append(leftovers, v)
+			continue
+		}
+		if _, ok := referenced[v]; !ok {
+			orphans = append(orphans, v)
+		}
+	}
+	sort.Strings(orphans)
+	sort.Strings(leftovers)
+
+	return orphans, leftovers, nil
+}
+
+// GarbageCollect walks all tlog trees and reports the blobs in the kv store
+// that are garbage. Garbage blobs are blobs that are not referenced by any
+// tlog leaf and file blobs of censored records that were not deleted.
+//
+// The time that a garbage blob is first seen is recorded. When del is true,
+// blobs that have been garbage for longer than the provided safety window
+// are deleted from the kv store.
+func (t *Tstore) GarbageCollect(window time.Duration, del bool) (*GCReport, error) {
+	log.Tracef("GarbageCollect: %v %v", window, del)
+
+	if !t.collectingGarbageTrySet() {
+		return nil, fmt.Errorf("garbage collection already in progress")
+	}
+	defer t.collectingGarbageSet(false)
+
+	orphans, leftovers, err := t.garbage()
+	if err != nil {
+		return nil, err
+	}
+
+	// Update the first seen timestamps. Blobs that are no longer
+	// garbage are removed.
+	prevSeen, err := t.gcSeenLoad()
+	if err != nil {
+		return nil, fmt.Errorf("gcSeenLoad: %v", err)
+	}
+	now := time.Now().Unix()
+	seen := make(map[string]int64, len(orphans)+len(leftovers))
+	for _, keys := range [][]string{orphans, leftovers} {
+		for _, v := range keys {
+			ts, ok := prevSeen[v]
+			if !ok {
+				ts = now
+			}
+			seen[v] = ts
+		}
+	}
+
+	// Delete the blobs that are past the safety window
+	deleted := make([]string, 0, len(seen))
+	if del {
+		for k, ts := range seen {
+			if now-ts >= int64(window.Seconds()) {
+				deleted = append(deleted, k)
+			}
+		}
+		sort.Strings(deleted)
+		if len(deleted) > 0 {
+			err = t.store.Del(deleted)
+			if err != nil {
+				return nil, fmt.Errorf("store Del: %v", err)
+			}
+			for _, v := range deleted {
+				delete(seen, v)
+			}
+		}
+	}
+
+	err = t.gcSeenSave(seen)
+	if err != nil {
+		return nil, fmt.Errorf("gcSeenSave: %v", err)
+	}
+
+	return &GCReport{
+		Orphans:   orphans,
+		Leftovers: leftovers,
+		Deleted:   deleted,
+	}, nil
+}
+
+// collectingGarbageTrySet sets the collecting garbage boolean if a garbage
+// collection run is not already in progress. It returns whether the boolean
+// was set. The check and the set are done under the same lock so that
+// concurrent garbage collection runs are not able to both start.
+func (t *Tstore) collectingGarbageTrySet() bool {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.collectingGarbage {
+		return false
+	}
+	t.collectingGarbage = true
+	return true
+}
+
+// collectingGarbageSet sets the collecting garbage boolean, which is used to
+// prevent concurrent garbage collection runs.
+func (t *Tstore) collectingGarbageSet(b bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.collectingGarbage = b
+}
+
+// gc runs the garbage collector and logs the results. It is run periodically
+// using cron.
+func (t *Tstore) gc() {
+	log.Infof("Collecting garbage")
+
+	r, err := t.GarbageCollect(GCSafetyWindow, true)
+	if err != nil {
+		log.Errorf("GarbageCollect: %v", err)
+		return
+	}
+	for _, v := range r.Orphans {
+		log.Debugf("Orphaned blob: %v", v)
+	}
+	for _, v := range r.Leftovers {
+		log.Debugf("Censored record blob: %v", v)
+	}
+
+	log.Infof("Garbage collection: %v orphaned blobs, %v censored record "+
+		"blobs, %v deleted", len(r.Orphans), len(r.Leftovers), len(r.Deleted))
+}
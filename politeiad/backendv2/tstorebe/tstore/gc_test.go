@@ -0,0 +1,80 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package tstore
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestGarbageCollect(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "tstore.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	ts := NewTestTstore(t, dataDir)
+	defer ts.Close()
+
+	// Save an orphaned blob and a non-blob entry to the kv store
+	orphan := storeKeyNew(true)
+	kv := map[string][]byte{
+		orphan:           []byte("orphan"),
+		tlogKeyParamsKey: []byte("params"),
+	}
+	err = ts.store.Put(kv, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The orphaned blob should be reported, but not deleted until it
+	// has been garbage for longer than the safety window.
+	r, err := ts.GarbageCollect(time.Hour, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Orphans) != 1 || r.Orphans[0] != orphan {
+		t.Fatalf("got orphans %v, want [%v]", r.Orphans, orphan)
+	}
+	if len(r.Deleted) != 0 {
+		t.Fatalf("got deleted %v, want none", r.Deleted)
+	}
+
+	// The orphaned blob should be deleted once it is past the safety
+	// window. The non-blob entry must never be deleted.
+	r, err = ts.GarbageCollect(0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Deleted) != 1 || r.Deleted[0] != orphan {
+		t.Fatalf("got deleted %v, want [%v]", r.Deleted, orphan)
+	}
+	blobs, err := ts.store.Get([]string{orphan, tlogKeyParamsKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := blobs[orphan]; ok {
+		t.Fatalf("orphaned blob was not deleted")
+	}
+	if _, ok := blobs[tlogKeyParamsKey]; !ok {
+		t.Fatalf("non-blob entry was deleted")
+	}
+
+	// A run is refused while another run is in progress
+	if !ts.collectingGarbageTrySet() {
+		t.Fatalf("collecting garbage was already set")
+	}
+	if ts.collectingGarbageTrySet() {
+		t.Fatalf("collecting garbage was set twice")
+	}
+	_, err = ts.GarbageCollect(0, true)
+	if err == nil {
+		t.Fatalf("concurrent garbage collection run was not refused")
+	}
+	ts.collectingGarbageSet(false)
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package tstore
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	backend "github.com/decred/politeia/politeiad/backendv2"
+	"github.com/decred/politeia/politeiad/backendv2/tstorebe/store"
+	"github.com/decred/politeia/util"
+	"github.com/google/trillian"
+)
+
+// LeafDetails contains the decoded contents of a single tlog leaf along with
+// the data blob that the leaf references.
+type LeafDetails struct {
+	TreeID         int64
+	LeafIndex      int64
+	MerkleLeafHash string
+	LeafValue      string         // Digest of the data blob
+	Key            string         // Key-value store key
+	Descriptor     string         // Blob entry data descriptor
+	State          backend.StateT // Record state, not set for anchors
+
+	// BlobEntry is the blob entry that the leaf references. It will
+	// not be populated if the blob has been deleted from the store,
+	// e.g. the record was censored.
+	BlobEntry  *store.BlobEntry
+	DataHint   *store.DataDescriptor
+	Data       []byte // Decoded blob entry data
+	DataDigest string // Digest of the decoded data
+
+	// Timestamp contains the digest chain from the data digest to the
+	// dcrtime merkle root that was anchored onto the decred blockchain.
+	// The proofs will not be populated if the leaf has not been
+	// anchored yet.
+	Timestamp *backend.Timestamp
+}
+
+// NewInspector returns a tstore instance that can be used to inspect the
+// tlog trees and data blobs of an existing tstore. Unlike New, the returned
+// instance does not connect to dcrtime and does not drop anchors.
+func NewInspector(appDir, dataDir string, anp *chaincfg.Params, tlogType, tlogHost, tlogPass, dbType, dbHost, dbPass string) (*Tstore, error) {
+	kvstore, tlogClient, err := connect(appDir, dataDir, anp, tlogType,
+		tlogHost, tlogPass, dbType, dbHost, dbPass)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tstore{
+		dataDir:         dataDir,
+		activeNetParams: anp,
+		tlog:            tlogClient,
+		store:           kvstore,
+		plugins:         make(map[string]plugin),
+		tokens:          make(map[string][]byte),
+	}, nil
+}
+
+// Leaf returns the details of the leaf with the provided merkle leaf hash
+// from the tlog tree of the provided record token. The data blob that the
+// leaf references is retrieved from the key-value store and decoded, and the
+// digest chain from the leaf to the anchored dcrtime merkle root is verified.
+func (t *Tstore) Leaf(token, merkleLeafHash []byte) (*LeafDetails, error) {
+	log.Tracef("Leaf: %x %x", token, merkleLeafHash)
+
+	treeID := treeIDFromToken(token)
+	leaves, err := t.leavesAll(treeID)
+	if err != nil {
+		return nil, err
+	}
+	var l *trillian.LogLeaf
+	for _, v := range leaves {
+		if bytes.Equal(v.MerkleLeafHash, merkleLeafHash) {
+			l = v
+			break
+		}
+	}
+	if l == nil {
+		return nil, fmt.Errorf("leaf not found")
+	}
+	ed, err := extraDataDecode(l.ExtraData)
+	if err != nil {
+		return nil, fmt.Errorf("extra data: %v", err)
+	}
+	ld := LeafDetails{
+		TreeID:         treeID,
+		LeafIndex:      l.LeafIndex,
+		MerkleLeafHash: hex.EncodeToString(l.MerkleLeafHash),
+		LeafValue:      hex.EncodeToString(l.LeafValue),
+		Key:            ed.storeKey(),
+		Descriptor:     ed.Desc,
+		State:          ed.State,
+	}
+
+	// Get the blob entry. The blob will not exist if it has been
+	// deleted.
+	blobs, err := t.store.Get([]string{ed.storeKey()})
+	if err != nil {
+		return nil, fmt.Errorf("store get: %v", err)
+	}
+	if b, ok := blobs[ed.storeKey()]; ok {
+		be, err := store.Deblob(b)
+		if err != nil {
+			return nil, fmt.Errorf("deblob: %v", err)
+		}
+		ld.BlobEntry = be
+
+		hint, err := base64.StdEncoding.DecodeString(be.DataHint)
+		if err != nil {
+			return nil, fmt.Errorf("decode data hint: %v", err)
+		}
+		var dd store.DataDescriptor
+		err = json.Unmarshal(hint, &dd)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal data hint: %v", err)
+		}
+		ld.DataHint = &dd
+
+		ld.Data, err = base64.StdEncoding.DecodeString(be.Data)
+		if err != nil {
+			return nil, fmt.Errorf("decode data: %v", err)
+		}
+		ld.DataDigest = hex.EncodeToString(util.Digest(ld.Data))
+	}
+
+	// Get the digest chain. The timestamp is verified when it is
+	// retrieved.
+	ld.Timestamp, err = t.timestamp(treeID, l.MerkleLeafHash, leaves)
+	if err != nil {
+		return &ld, fmt.Errorf("timestamp: %v", err)
+	}
+
+	return &ld, nil
+}
+
+// LeafReplay re-derives the blob entry of a leaf from its decoded data hint
+// and data and verifies that the result matches both the stored blob entry and
+// the digest that was appended to the tlog tree. Plugin command payloads are
+// not saved to tstore, so the plugin hook that produced a leaf can't be
+// executed a second time. Re-deriving the blob entry exercises the same
+// encoding path that was used when the leaf was appended.
+func LeafReplay(ld LeafDetails) error {
+	if ld.BlobEntry == nil {
+		return fmt.Errorf("blob entry not found")
+	}
+	dataHint, err := base64.StdEncoding.DecodeString(ld.BlobEntry.DataHint)
+	if err != nil {
+		return err
+	}
+	be := store.NewBlobEntry(dataHint, ld.Data)
+	if be != *ld.BlobEntry {
+		return fmt.Errorf("re-derived blob entry does not match the " +
+			"stored blob entry")
+	}
+	if be.Digest != ld.LeafValue {
+		return fmt.Errorf("blob entry digest %v does not match leaf "+
+			"value %v", be.Digest, ld.LeafValue)
+	}
+	_, err = store.Blobify(be)
+	if err != nil {
+		return fmt.Errorf("blobify: %v", err)
+	}
+	return nil
+}
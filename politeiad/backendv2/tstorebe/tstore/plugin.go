@@ -5,6 +5,7 @@
 package tstore
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"path/filepath"
@@ -88,7 +89,7 @@ func (t *Tstore) PluginRegister(b backend.Backend, p backend.Plugin) error {
 			return err
 		}
 	case piplugin.PluginID:
-		client, err = pi.New(b, p.Settings, dataDir)
+		client, err = pi.New(b, t, p.Settings, dataDir, p.Identity)
 		if err != nil {
 			return err
 		}
@@ -199,12 +200,14 @@ func (t *Tstore) PluginRead(token []byte, pluginID, cmd, payload string) (string
 		return "", backend.ErrPluginIDInvalid
 	}
 
-	// Execute plugin command
-	return p.client.Cmd(token, cmd, payload)
+	// Execute plugin command. Read commands do not write to tstore so
+	// they are not traced.
+	return p.client.Cmd(context.Background(), token, cmd, payload)
 }
 
-// PluginWrite executes a plugin command that writes data.
-func (t *Tstore) PluginWrite(token []byte, pluginID, cmd, payload string) (string, error) {
+// PluginWrite executes a plugin command that writes data. The tstore writes
+// of the command are traced when the context contains a trace span.
+func (t *Tstore) PluginWrite(ctx context.Context, token []byte, pluginID, cmd, payload string) (string, error) {
 	log.Tracef("PluginWrite: %x %v %v", token, pluginID, cmd)
 
 	// Get plugin
@@ -214,7 +217,7 @@ func (t *Tstore) PluginWrite(token []byte, pluginID, cmd, payload string) (strin
 	}
 
 	// Execute plugin command
-	return p.client.Cmd(token, cmd, payload)
+	return p.client.Cmd(ctx, token, cmd, payload)
 }
 
 // Plugins returns all registered plugins for the tstore instance.
@@ -227,8 +230,10 @@ func (t *Tstore) Plugins() []backend.Plugin {
 	plugins := make([]backend.Plugin, 0, len(t.plugins))
 	for _, v := range t.plugins {
 		plugins = append(plugins, backend.Plugin{
-			ID:       v.id,
-			Settings: v.client.Settings(),
+			ID:           v.id,
+			Settings:     v.client.Settings(),
+			Schema:       v.client.SettingsSchema(),
+			Dependencies: v.client.Dependencies(),
 		})
 	}
 
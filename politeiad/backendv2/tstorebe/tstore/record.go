@@ -6,6 +6,7 @@ package tstore
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
@@ -30,30 +31,43 @@ const (
 // RecordNew creates a new record in the tstore and returns the record token
 // that serves as the unique identifier for the record. Creating a new record
 // means creating a tlog tree for the record. Nothing is saved to the tree yet.
-func (t *Tstore) RecordNew() ([]byte, error) {
-	var token []byte
-	for retries := 0; retries < 10; retries++ {
+//
+// The short token of the new record must not collide with the short token of
+// an existing record. A collision is handled according to the token collision
+// policy.
+//
+// The tree creation is traced when the context contains a trace span.
+func (t *Tstore) RecordNew(ctx context.Context) ([]byte, error) {
+	for retries := 0; retries < tokenCollisionRetries; retries++ {
+		span := t.startTlogSpan(ctx, "TreeNew", 0)
 		tree, _, err := t.tlog.TreeNew()
+		span.SetError(err)
+		span.End()
 		if err != nil {
 			return nil, err
 		}
-		token = tokenFromTreeID(tree.TreeId)
+		token := tokenFromTreeID(tree.TreeId)
 
 		// Check for shortened token collisions
-		if t.tokenCollision(token) {
-			// This is a collision. We cannot use this tree. Try again.
-			log.Infof("Token collision %x, creating new token", token)
-			continue
+		if !t.tokenCollision(token) {
+			// We've found a valid token. Update the tokens cache. This
+			// must be done even if the record creation fails since the
+			// tree will still exist.
+			t.tokenAdd(token)
+			return token, nil
+		}
+
+		// This is a collision. We cannot use this tree.
+		log.Infof("Token collision %x", token)
+		if t.collisionPolicy == TokenCollisionReject {
+			return nil, errTokenCollision
 		}
 
-		// We've found a valid token. Update the tokens cache. This must
-		// be done even if the record creation fails since the tree will
-		// still exist.
-		t.tokenAdd(token)
-		break
+		// Try again
 	}
 
-	return token, nil
+	return nil, fmt.Errorf("%v: %v retries", errTokenCollision,
+		tokenCollisionRetries)
 }
 
 // recordSave saves the provided record content to the kv store, appends a leaf
@@ -65,9 +79,12 @@ func (t *Tstore) RecordNew() ([]byte, error) {
 // reset back to 1. This function detects when a record is being made public
 // and re-saves any encrypted content that is part of the public record as
 // clear text in the key-value store.
-func (t *Tstore) recordSave(treeID int64, recordMD backend.RecordMetadata, metadata []backend.MetadataStream, files []backend.File) (*recordIndex, error) {
+func (t *Tstore) recordSave(ctx context.Context, treeID int64, recordMD backend.RecordMetadata, metadata []backend.MetadataStream, files []backend.File) (*recordIndex, error) {
 	// Get tree leaves
+	span := t.startTlogSpan(ctx, "LeavesAll", treeID)
 	leavesAll, err := t.leavesAll(treeID)
+	span.SetError(err)
+	span.End()
 	if err != nil {
 		return nil, err
 	}
@@ -355,7 +372,10 @@ func (t *Tstore) recordSave(treeID int64, recordMD backend.RecordMetadata, metad
 	}
 
 	// Append leaves onto the trillian tree
+	span = t.startTlogSpan(ctx, "LeavesAppend", treeID)
 	queued, _, err := t.tlog.LeavesAppend(treeID, leaves)
+	span.SetError(err)
+	span.End()
 	if err != nil {
 		return nil, fmt.Errorf("LeavesAppend: %v", err)
 	}
@@ -440,9 +460,17 @@ func (t *Tstore) recordSave(treeID int64, recordMD backend.RecordMetadata, metad
 // considered to be valid until the record index has been successfully saved.
 // If the record content makes it in but the record index does not, the record
 // content blobs are orphaned and ignored.
-func (t *Tstore) RecordSave(token []byte, rm backend.RecordMetadata, metadata []backend.MetadataStream, files []backend.File) error {
+//
+// The save is traced when the context contains a trace span.
+func (t *Tstore) RecordSave(ctx context.Context, token []byte, rm backend.RecordMetadata, metadata []backend.MetadataStream, files []backend.File) (err error) {
 	log.Tracef("RecordSave: %x", token)
 
+	ctx, span := startSpan(ctx, "RecordSave", token)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+
 	// Verify token is valid. The full length token must be used when
 	// writing data.
 	if !tokenIsFullLength(token) {
@@ -451,13 +479,13 @@ func (t *Tstore) RecordSave(token []byte, rm backend.RecordMetadata, metadata []
 
 	// Save the record
 	treeID := treeIDFromToken(token)
-	idx, err := t.recordSave(treeID, rm, metadata, files)
+	idx, err := t.recordSave(ctx, treeID, rm, metadata, files)
 	if err != nil {
 		return err
 	}
 
 	// Save the record index
-	err = t.recordIndexSave(treeID, *idx)
+	err = t.recordIndexSave(ctx, treeID, *idx)
 	if err != nil {
 		return fmt.Errorf("recordIndexSave: %v", err)
 	}
@@ -500,22 +528,39 @@ func (t *Tstore) RecordDel(token []byte) error {
 		return err
 	}
 
-	// Aggregate the keys for all file blobs of all versions. The
-	// record index points to the log leaf merkle leaf hash. The log
-	// leaf contains the kv store key.
-	merkles := make(map[string]struct{}, len(leavesAll))
+	// Aggregate the keys for all file blobs of all versions
+	keys, err := fileKeys(leavesAll, indexes)
+	if err != nil {
+		return err
+	}
+
+	// Delete file blobs from the store
+	err = t.store.Del(keys)
+	if err != nil {
+		return fmt.Errorf("store Del: %v", err)
+	}
+
+	return nil
+}
+
+// fileKeys returns the kv store keys for the file blobs of all the provided
+// record indexes.
+func fileKeys(leaves []*trillian.LogLeaf, indexes []recordIndex) ([]string, error) {
+	// The record index points to the log leaf merkle leaf hash. The
+	// log leaf contains the kv store key.
+	merkles := make(map[string]struct{}, len(leaves))
 	for _, v := range indexes {
 		for _, merkle := range v.Files {
 			merkles[hex.EncodeToString(merkle)] = struct{}{}
 		}
 	}
 	keys := make([]string, 0, len(merkles))
-	for _, v := range leavesAll {
+	for _, v := range leaves {
 		_, ok := merkles[hex.EncodeToString(v.MerkleLeafHash)]
 		if ok {
 			ed, err := extraDataDecode(v.ExtraData)
 			if err != nil {
-				return err
+				return nil, err
 			}
 			keys = append(keys, ed.storeKey())
 
@@ -530,14 +575,7 @@ func (t *Tstore) RecordDel(token []byte) error {
 			}
 		}
 	}
-
-	// Delete file blobs from the store
-	err = t.store.Del(keys)
-	if err != nil {
-		return fmt.Errorf("store Del: %v", err)
-	}
-
-	return nil
+	return keys, nil
 }
 
 // RecordFreeze updates the status of a record then freezes the trillian tree
@@ -549,9 +587,17 @@ func (t *Tstore) RecordDel(token []byte) error {
 // anchored, the tstore fsck function will update the status of the tree to
 // frozen in trillian, at which point trillian will prevent any changes to the
 // tree.
-func (t *Tstore) RecordFreeze(token []byte, rm backend.RecordMetadata, metadata []backend.MetadataStream, files []backend.File) error {
+//
+// The freeze is traced when the context contains a trace span.
+func (t *Tstore) RecordFreeze(ctx context.Context, token []byte, rm backend.RecordMetadata, metadata []backend.MetadataStream, files []backend.File) (err error) {
 	log.Tracef("RecordFreeze: %x", token)
 
+	ctx, span := startSpan(ctx, "RecordFreeze", token)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+
 	// Verify token is valid. The full length token must be used when
 	// writing data.
 	if !tokenIsFullLength(token) {
@@ -560,7 +606,7 @@ func (t *Tstore) RecordFreeze(token []byte, rm backend.RecordMetadata, metadata
 
 	// Save updated record
 	treeID := treeIDFromToken(token)
-	idx, err := t.recordSave(treeID, rm, metadata, files)
+	idx, err := t.recordSave(ctx, treeID, rm, metadata, files)
 	if err != nil {
 		return err
 	}
@@ -569,7 +615,7 @@ func (t *Tstore) RecordFreeze(token []byte, rm backend.RecordMetadata, metadata
 	idx.Frozen = true
 
 	// Save the record index
-	return t.recordIndexSave(treeID, *idx)
+	return t.recordIndexSave(ctx, treeID, *idx)
 }
 
 // RecordExists returns whether a record exists.
@@ -6,6 +6,7 @@ package tstore
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
@@ -78,7 +79,7 @@ type recordIndex struct {
 }
 
 // recordIndexSave saves a record index to tstore.
-func (t *Tstore) recordIndexSave(treeID int64, idx recordIndex) error {
+func (t *Tstore) recordIndexSave(ctx context.Context, treeID int64, idx recordIndex) error {
 	// Only vetted data should be saved plain text
 	var encrypt bool
 	switch idx.State {
@@ -125,7 +126,10 @@ func (t *Tstore) recordIndexSave(treeID int64, idx recordIndex) error {
 	leaves := []*trillian.LogLeaf{
 		newLogLeaf(d, extraData),
 	}
+	span := t.startTlogSpan(ctx, "LeavesAppend", treeID)
 	queued, _, err := t.tlog.LeavesAppend(treeID, leaves)
+	span.SetError(err)
+	span.End()
 	if err != nil {
 		return fmt.Errorf("LeavesAppend: %v", err)
 	}
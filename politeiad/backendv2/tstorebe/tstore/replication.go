@@ -0,0 +1,699 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package tstore
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/decred/politeia/politeiad/backendv2/tstorebe/store"
+	"github.com/decred/politeia/util"
+	"github.com/google/trillian"
+	"github.com/google/trillian/types"
+	"github.com/marcopeereboom/sbox"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// replicationFilename is the filename of the file that is used to
+	// track the replication progress. It is saved to the tstore data
+	// dir.
+	replicationFilename = "replication.json"
+
+	// replicationLeavesMax is the maximum number of leaves that are
+	// sent in a single replication batch.
+	replicationLeavesMax = 100
+
+	// replicationRetryInterval is the amount of time that the
+	// replicator waits before retrying a failed batch. The pending
+	// trees are also rechecked at this interval.
+	replicationRetryInterval = 30 * time.Second
+
+	// replicationTimeout is the timeout of a replication batch request.
+	replicationTimeout = 60 * time.Second
+
+	// ReplicationBatchVersion is the version of the replication batch
+	// format.
+	ReplicationBatchVersion = 1
+)
+
+// ReplicationBatch is the envelope of a batch of replicated data that is
+// sent to the replication receiver. The payload is a JSON encoded
+// ReplicationPayload that is encrypted using a key that is derived from the
+// replication passphrase with the provided argon2id params. The receiver only
+// needs the passphrase to decrypt the batches during a restore.
+//
+// Batches are sent in sequence. The stream ID identifies the replication
+// stream of a tstore instance. A new stream is started when the replication
+// progress of the tstore instance is lost, in which case all data is sent
+// again. The receiver must never overwrite a batch of a stream. It replies
+// with a 409 when it already has a batch with the same sequence number, e.g.
+// when the reply to the original batch was lost, and the sequence number is
+// skipped by the sender.
+type ReplicationBatch struct {
+	Version  uint32            `json:"version"`
+	StreamID string            `json:"streamid"`
+	Sequence uint64            `json:"sequence"`
+	Params   util.Argon2Params `json:"params"`
+	Payload  []byte            `json:"payload"`
+}
+
+// ReplicatedLeaf is a tlog leaf that has been replicated.
+type ReplicatedLeaf struct {
+	LeafIndex int64  `json:"leafindex"`
+	LeafValue []byte `json:"leafvalue"`
+	ExtraData []byte `json:"extradata"`
+}
+
+// ReplicationPayload contains the replicated data of a batch. It either
+// contains new leaves of a tlog tree along with the kv store blobs that they
+// reference, the frozen status of a tlog tree, or the kv store keys of blobs
+// that have been deleted. Blobs that were deleted before their leaf was
+// replicated are not included.
+type ReplicationPayload struct {
+	TreeID  int64             `json:"treeid,omitempty"`
+	Leaves  []ReplicatedLeaf  `json:"leaves,omitempty"`
+	Frozen  bool              `json:"frozen,omitempty"` // Tree is frozen
+	Blobs   map[string][]byte `json:"blobs,omitempty"`  // [key]blob
+	Deleted []string          `json:"deleted,omitempty"`
+}
+
+// DecryptReplicationBatch decrypts the payload of a replication batch using
+// the replication passphrase.
+func DecryptReplicationBatch(b ReplicationBatch, passphrase string) (*ReplicationPayload, error) {
+	if b.Version != ReplicationBatchVersion {
+		return nil, fmt.Errorf("unsupported batch version %v", b.Version)
+	}
+	key := replicationKey(passphrase, b.Params)
+	defer util.Zero(key[:])
+	pt, _, err := sbox.Decrypt(key, b.Payload)
+	if err != nil {
+		return nil, err
+	}
+	var rp ReplicationPayload
+	err = json.Unmarshal(pt, &rp)
+	if err != nil {
+		return nil, err
+	}
+	return &rp, nil
+}
+
+// replicationKey derives the replication encryption key from the replication
+// passphrase using the argon2id key derivation function.
+func replicationKey(passphrase string, ap util.Argon2Params) *[32]byte {
+	var key [32]byte
+	k := argon2.IDKey([]byte(passphrase), ap.Salt, ap.Time, ap.Memory,
+		ap.Threads, ap.KeyLen)
+	copy(key[:], k)
+	util.Zero(k)
+	return &key
+}
+
+// ReplicationStatus contains the status of the replication stream.
+type ReplicationStatus struct {
+	// Pending is the number of tlog trees that have leaves that have
+	// not been replicated yet plus one if there are blob deletions
+	// that have not been replicated yet.
+	Pending int
+
+	// Lag is the amount of time that the oldest unreplicated change
+	// has been waiting to be replicated.
+	Lag time.Duration
+
+	// Sequence is the sequence number of the last batch that was
+	// accepted by the receiver.
+	Sequence uint64
+
+	// LastSuccess is the time the last batch was accepted by the
+	// receiver. It is zero if no batch has been accepted since
+	// startup.
+	LastSuccess time.Time
+
+	// Failures is the number of batches that have failed since
+	// startup.
+	Failures uint64
+}
+
+// replicationState is the replication progress that is saved to the tstore
+// data dir so that the replication resumes where it left off after a restart
+// or a receiver outage.
+type replicationState struct {
+	Params   util.Argon2Params `json:"params"`
+	StreamID string            `json:"streamid"`
+	Sequence uint64            `json:"sequence"`
+	Trees    map[int64]int64   `json:"trees"`   // [treeID]leavesReplicated
+	Frozen   map[int64]bool    `json:"frozen"`  // [treeID]frozenReplicated
+	Deleted  []string          `json:"deleted"` // Pending deletions
+}
+
+// replicator streams the new tlog leaves, the blobs that they reference, and
+// the blob deletions of a tstore instance to an off-site receiver. The tlog
+// trees are the source of truth, so the replicator tracks the number of
+// leaves of each tree that have been replicated and sends any leaves past
+// that point. This allows it to catch up after a restart or a receiver outage
+// without any changes being lost.
+type replicator struct {
+	sync.Mutex
+	url    string
+	tlog   tlogClient
+	store  store.BlobKV
+	client *http.Client
+	path   string
+	key    *[32]byte
+	state  replicationState
+
+	pending     map[int64]time.Time // [treeID]Time of the first change
+	deletedAt   time.Time           // Time of the first pending deletion
+	lastSuccess time.Time
+	failures    uint64
+
+	notify chan struct{}
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// newReplicator returns a new replicator. The replication progress is loaded
+// from the provided file if it exists.
+func newReplicator(receiverURL, passphrase, path string, tlog tlogClient, kv store.BlobKV) (*replicator, error) {
+	u, err := url.Parse(receiverURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return nil, fmt.Errorf("invalid replication url")
+	}
+	if passphrase == "" {
+		return nil, fmt.Errorf("replication passphrase not provided")
+	}
+
+	r := replicator{
+		url:   receiverURL,
+		tlog:  tlog,
+		store: kv,
+		client: &http.Client{
+			Timeout: replicationTimeout,
+		},
+		path:    path,
+		pending: make(map[int64]time.Time, 64),
+		notify:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	b, err := ioutil.ReadFile(path)
+	switch {
+	case err == nil:
+		err = json.Unmarshal(b, &r.state)
+		if err != nil {
+			return nil, fmt.Errorf("decode %v: %v", path, err)
+		}
+	case errors.Is(err, os.ErrNotExist):
+		r.state.Params = util.NewArgon2Params()
+	default:
+		return nil, err
+	}
+	if r.state.StreamID == "" {
+		b, err := util.Random(8)
+		if err != nil {
+			return nil, err
+		}
+		r.state.StreamID = hex.EncodeToString(b)
+	}
+	if r.state.Trees == nil {
+		r.state.Trees = make(map[int64]int64, 256)
+	}
+	if r.state.Frozen == nil {
+		r.state.Frozen = make(map[int64]bool, 256)
+	}
+	r.key = replicationKey(passphrase, r.state.Params)
+
+	// The trees may have changed while the replicator was not running.
+	// All trees are checked for unreplicated leaves on startup.
+	trees, err := tlog.TreesAll()
+	if err != nil {
+		return nil, fmt.Errorf("TreesAll: %v", err)
+	}
+	now := time.Now()
+	for _, v := range trees {
+		r.pending[v.TreeId] = now
+	}
+	if len(r.state.Deleted) > 0 {
+		r.deletedAt = now
+	}
+
+	return &r, nil
+}
+
+// stateSave saves the replication progress to disk.
+//
+// This function must be called WITH the lock held.
+func (r *replicator) stateSave() error {
+	b, err := json.Marshal(r.state)
+	if err != nil {
+		return err
+	}
+	tmp := r.path + ".tmp"
+	err = ioutil.WriteFile(tmp, b, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.path)
+}
+
+// wake wakes up the replication worker.
+func (r *replicator) wake() {
+	select {
+	case r.notify <- struct{}{}:
+	default:
+		// The worker has already been notified
+	}
+}
+
+// treeUpdated marks a tree as having unreplicated changes.
+func (r *replicator) treeUpdated(treeID int64) {
+	r.Lock()
+	if _, ok := r.pending[treeID]; !ok {
+		r.pending[treeID] = time.Now()
+	}
+	r.Unlock()
+
+	r.wake()
+}
+
+// blobsDeleted queues the deletion of the provided blobs. The pending
+// deletions are saved to disk so that they are not lost on a restart.
+func (r *replicator) blobsDeleted(keys []string) {
+	r.Lock()
+	if len(r.state.Deleted) == 0 {
+		r.deletedAt = time.Now()
+	}
+	r.state.Deleted = append(r.state.Deleted, keys...)
+	err := r.stateSave()
+	r.Unlock()
+	if err != nil {
+		log.Errorf("replication: save state: %v", err)
+	}
+
+	r.wake()
+}
+
+// status returns the replication status.
+func (r *replicator) status() ReplicationStatus {
+	r.Lock()
+	defer r.Unlock()
+
+	var oldest time.Time
+	for _, v := range r.pending {
+		if oldest.IsZero() || v.Before(oldest) {
+			oldest = v
+		}
+	}
+	pending := len(r.pending)
+	if len(r.state.Deleted) > 0 {
+		pending++
+		if oldest.IsZero() || r.deletedAt.Before(oldest) {
+			oldest = r.deletedAt
+		}
+	}
+	var lag time.Duration
+	if !oldest.IsZero() {
+		lag = time.Since(oldest)
+	}
+
+	return ReplicationStatus{
+		Pending:     pending,
+		Lag:         lag,
+		Sequence:    r.state.Sequence,
+		LastSuccess: r.lastSuccess,
+		Failures:    r.failures,
+	}
+}
+
+// send encrypts the provided payload and sends it to the receiver as the next
+// batch in the sequence. The sequence number is skipped when the receiver
+// already has a batch with that sequence number.
+func (r *replicator) send(rp ReplicationPayload) error {
+	b, err := json.Marshal(rp)
+	if err != nil {
+		return err
+	}
+	payload, err := sbox.Encrypt(0, r.key, b)
+	if err != nil {
+		return err
+	}
+
+	for {
+		r.Lock()
+		rb := ReplicationBatch{
+			Version:  ReplicationBatchVersion,
+			StreamID: r.state.StreamID,
+			Sequence: r.state.Sequence + 1,
+			Params:   r.state.Params,
+			Payload:  payload,
+		}
+		r.Unlock()
+
+		code, err := r.post(rb)
+		if err != nil {
+			return err
+		}
+		switch code {
+		case http.StatusOK:
+			r.Lock()
+			r.state.Sequence = rb.Sequence
+			r.lastSuccess = time.Now()
+			r.Unlock()
+			return nil
+
+		case http.StatusConflict:
+			// The receiver accepted a batch with this sequence number
+			// but the reply was lost. The batch remains valid since
+			// its contents are sent again in a later batch.
+			log.Warnf("replication: receiver already has batch %v; "+
+				"skipping it", rb.Sequence)
+			r.Lock()
+			r.state.Sequence = rb.Sequence
+			err = r.stateSave()
+			r.Unlock()
+			if err != nil {
+				return fmt.Errorf("save state: %v", err)
+			}
+
+		default:
+			return fmt.Errorf("receiver replied with %v %v", code,
+				http.StatusText(code))
+		}
+	}
+}
+
+// post sends a batch to the receiver and returns the status code of the
+// reply.
+func (r *replicator) post(rb ReplicationBatch) (int, error) {
+	b, err := json.Marshal(rb)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := r.client.Post(r.url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// replicateTree sends the unreplicated leaves of a tree to the receiver
+// along with the blobs that they reference. Only the leaves past the number
+// of leaves that have already been replicated are retrieved from the tlog.
+// The frozen status of the tree is replicated once all of its leaves have
+// been replicated.
+func (r *replicator) replicateTree(treeID int64) error {
+	r.Lock()
+	replicated := r.state.Trees[treeID]
+	frozen := r.state.Frozen[treeID]
+	r.Unlock()
+
+	for {
+		leaves, err := r.tlog.LeavesByRange(treeID, replicated,
+			replicationLeavesMax)
+		if err != nil {
+			return fmt.Errorf("LeavesByRange %v: %v", treeID, err)
+		}
+		if len(leaves) == 0 {
+			break
+		}
+
+		// Get the blobs that the leaves reference. The plain text copy
+		// of an unvetted blob is included when the record has since
+		// been made public.
+		rp := ReplicationPayload{
+			TreeID: treeID,
+			Leaves: make([]ReplicatedLeaf, 0, len(leaves)),
+		}
+		keys := make([]string, 0, 2*len(leaves))
+		for _, v := range leaves {
+			rp.Leaves = append(rp.Leaves, ReplicatedLeaf{
+				LeafIndex: v.LeafIndex,
+				LeafValue: v.LeafValue,
+				ExtraData: v.ExtraData,
+			})
+			ed, err := extraDataDecode(v.ExtraData)
+			if err != nil {
+				return err
+			}
+			keys = append(keys, ed.storeKey())
+			if ed.storeKey() != ed.storeKeyNoPrefix() {
+				keys = append(keys, ed.storeKeyNoPrefix())
+			}
+		}
+		rp.Blobs, err = r.store.Get(keys)
+		if err != nil {
+			return fmt.Errorf("store Get: %v", err)
+		}
+
+		err = r.send(rp)
+		if err != nil {
+			return err
+		}
+
+		end := replicated + int64(len(leaves))
+		r.Lock()
+		r.state.Trees[treeID] = end
+		err = r.stateSave()
+		r.Unlock()
+		if err != nil {
+			return fmt.Errorf("save state: %v", err)
+		}
+		replicated = end
+
+		log.Debugf("replication: tree %v leaves %v replicated", treeID, end)
+
+		if len(leaves) < replicationLeavesMax {
+			break
+		}
+	}
+
+	// Replicate the frozen status of the tree
+	if frozen {
+		return nil
+	}
+	tree, err := r.tlog.Tree(treeID)
+	if err != nil {
+		return fmt.Errorf("Tree %v: %v", treeID, err)
+	}
+	if tree.TreeState != trillian.TreeState_FROZEN {
+		return nil
+	}
+	err = r.send(ReplicationPayload{
+		TreeID: treeID,
+		Frozen: true,
+	})
+	if err != nil {
+		return err
+	}
+	r.Lock()
+	r.state.Frozen[treeID] = true
+	err = r.stateSave()
+	r.Unlock()
+	if err != nil {
+		return fmt.Errorf("save state: %v", err)
+	}
+
+	log.Debugf("replication: tree %v frozen", treeID)
+
+	return nil
+}
+
+// replicateDeletions sends the pending blob deletions to the receiver.
+func (r *replicator) replicateDeletions() error {
+	r.Lock()
+	deleted := r.state.Deleted
+	r.Unlock()
+	if len(deleted) == 0 {
+		return nil
+	}
+
+	err := r.send(ReplicationPayload{
+		Deleted: deleted,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Deletions may have been queued while the batch was being sent
+	r.Lock()
+	r.state.Deleted = r.state.Deleted[len(deleted):]
+	if len(r.state.Deleted) > 0 {
+		r.deletedAt = time.Now()
+	}
+	err = r.stateSave()
+	r.Unlock()
+	if err != nil {
+		return fmt.Errorf("save state: %v", err)
+	}
+
+	log.Debugf("replication: %v deletions replicated", len(deleted))
+
+	return nil
+}
+
+// replicate replicates all pending changes. The trees are replicated prior
+// to the deletions so that a blob is never resent after its deletion has
+// been replicated.
+func (r *replicator) replicate() error {
+	r.Lock()
+	trees := make([]int64, 0, len(r.pending))
+	for k := range r.pending {
+		trees = append(trees, k)
+	}
+	r.Unlock()
+	sort.Slice(trees, func(i, j int) bool { return trees[i] < trees[j] })
+
+	for _, treeID := range trees {
+		// The tree is removed from the pending trees before it is
+		// replicated so that leaves that are appended in the meantime
+		// mark it as pending again.
+		r.Lock()
+		since := r.pending[treeID]
+		delete(r.pending, treeID)
+		r.Unlock()
+
+		err := r.replicateTree(treeID)
+		if err != nil {
+			r.Lock()
+			if t, ok := r.pending[treeID]; !ok || since.Before(t) {
+				r.pending[treeID] = since
+			}
+			r.Unlock()
+			return err
+		}
+	}
+
+	return r.replicateDeletions()
+}
+
+// run replicates the pending changes until the replicator is stopped. Failed
+// batches are retried after the retry interval.
+func (r *replicator) run() {
+	defer r.wg.Done()
+
+	for {
+		err := r.replicate()
+		if err != nil {
+			r.Lock()
+			r.failures++
+			r.Unlock()
+			log.Errorf("replication: %v; replication lag %v", err,
+				r.status().Lag.Round(time.Second))
+		}
+
+		select {
+		case <-r.notify:
+		case <-time.After(replicationRetryInterval):
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// start launches the replication worker.
+func (r *replicator) start() {
+	r.wg.Add(1)
+	go r.run()
+}
+
+// stop stops the replication worker and waits for it to exit.
+func (r *replicator) stop() {
+	close(r.done)
+	r.wg.Wait()
+}
+
+// replicatedTlog is a tlogClient that notifies the replicator of the leaves
+// that have been appended onto a tree.
+type replicatedTlog struct {
+	tlogClient
+	r *replicator
+}
+
+// LeavesAppend appends leaves onto a tree.
+//
+// This function satisfies the tlogClient interface.
+func (t *replicatedTlog) LeavesAppend(treeID int64, leaves []*trillian.LogLeaf) ([]queuedLeafProof, *types.LogRootV1, error) {
+	queued, lr, err := t.tlogClient.LeavesAppend(treeID, leaves)
+	if err == nil {
+		t.r.treeUpdated(treeID)
+	}
+	return queued, lr, err
+}
+
+// TreeFreeze sets the status of a tree to frozen and returns the updated tree.
+//
+// This function satisfies the tlogClient interface.
+func (t *replicatedTlog) TreeFreeze(treeID int64) (*trillian.Tree, error) {
+	tree, err := t.tlogClient.TreeFreeze(treeID)
+	if err == nil {
+		t.r.treeUpdated(treeID)
+	}
+	return tree, err
+}
+
+// replicatedStore is a store.BlobKV that notifies the replicator of the blobs
+// that have been deleted.
+type replicatedStore struct {
+	store.BlobKV
+	r *replicator
+}
+
+// Del deletes the provided blobs from the store.
+//
+// This function satisfies the store.BlobKV interface.
+func (s *replicatedStore) Del(keys []string) error {
+	err := s.BlobKV.Del(keys)
+	if err == nil {
+		s.r.blobsDeleted(keys)
+	}
+	return err
+}
+
+// EnableReplication enables the streaming of the new tlog leaves, the blobs
+// that they reference, and the blob deletions to the provided receiver URL.
+// The data is encrypted using a key that is derived from the provided
+// passphrase. Basic auth credentials can be included in the URL. This must be
+// called prior to the tstore instance being used.
+func (t *Tstore) EnableReplication(receiverURL, passphrase string) error {
+	r, err := newReplicator(receiverURL, passphrase,
+		filepath.Join(t.dataDir, replicationFilename), t.tlog, t.store)
+	if err != nil {
+		return err
+	}
+
+	t.tlog = &replicatedTlog{
+		tlogClient: t.tlog,
+		r:          r,
+	}
+	t.store = &replicatedStore{
+		BlobKV: t.store,
+		r:      r,
+	}
+	t.replicator = r
+	r.start()
+
+	return nil
+}
+
+// ReplicationStatus returns the status of the replication stream. It returns
+// nil if replication has not been enabled.
+func (t *Tstore) ReplicationStatus() *ReplicationStatus {
+	if t.replicator == nil {
+		return nil
+	}
+	s := t.replicator.status()
+	return &s
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package tstore
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	backend "github.com/decred/politeia/politeiad/backendv2"
+	"github.com/google/trillian"
+)
+
+func TestReplication(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "tstore.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	// Setup a receiver that passes the batches to the test. The
+	// receiver already has the first batch, so the sender must skip
+	// its sequence number.
+	batches := make(chan ReplicationBatch, 4)
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var rb ReplicationBatch
+			err := json.NewDecoder(r.Body).Decode(&rb)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if rb.Sequence == 1 {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			batches <- rb
+		}))
+	defer srv.Close()
+
+	ts := NewTestTstore(t, dataDir)
+	defer ts.Close()
+	err = ts.EnableReplication(srv.URL, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	receive := func() *ReplicationPayload {
+		t.Helper()
+		select {
+		case rb := <-batches:
+			if rb.StreamID == "" {
+				t.Fatalf("stream ID not set")
+			}
+			rp, err := DecryptReplicationBatch(rb, "passphrase")
+			if err != nil {
+				t.Fatal(err)
+			}
+			return rp
+		case <-time.After(10 * time.Second):
+			t.Fatal("batch not received")
+		}
+		return nil
+	}
+
+	// Save a blob and append its leaf onto a new tree
+	tree, _, err := ts.tlog.TreeNew()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := storeKeyNew(false)
+	err = ts.store.Put(map[string][]byte{key: []byte("blob")}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ed, err := extraDataEncode(key, "desc", backend.StateVetted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaves := []*trillian.LogLeaf{newLogLeaf([]byte("digest"), ed)}
+	_, _, err = ts.tlog.LeavesAppend(tree.TreeId, leaves)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The leaf and its blob should be replicated
+	rp := receive()
+	if rp.TreeID != tree.TreeId || len(rp.Leaves) != 1 {
+		t.Fatalf("got tree %v leaves %v, want tree %v leaves 1",
+			rp.TreeID, len(rp.Leaves), tree.TreeId)
+	}
+	if string(rp.Blobs[key]) != "blob" {
+		t.Fatalf("got blob %q, want %q", rp.Blobs[key], "blob")
+	}
+
+	// The blob deletion should be replicated
+	err = ts.store.Del([]string{key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rp = receive()
+	if len(rp.Deleted) != 1 || rp.Deleted[0] != key {
+		t.Fatalf("got deleted %v, want [%v]", rp.Deleted, key)
+	}
+
+	// The sequence is updated once the receiver has replied
+	for i := 0; ; i++ {
+		s := ts.ReplicationStatus()
+		if s.Sequence == 3 && s.Pending == 0 {
+			break
+		}
+		if i == 100 {
+			t.Fatalf("got sequence %v pending %v, want 3 0",
+				s.Sequence, s.Pending)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func TestReplicationRestore(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "tstore.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	// Setup a tstore instance that uses the embedded log
+	ts := NewTestTstore(t, dataDir)
+	defer ts.Close()
+	key, err := deriveTlogKey(ts.store, "testpassphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := newEmbeddedLog(ts.store, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts.tlog = e
+
+	// Restore a tree with two leaves
+	var (
+		treeID int64 = 42
+		leaves       = make([]ReplicatedLeaf, 0, 3)
+		blobs        = make(map[string][]byte, 3)
+	)
+	for i := 0; i < 3; i++ {
+		key := storeKeyNew(i == 2)
+		ed, err := extraDataEncode(key, "desc", backend.StateVetted)
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaves = append(leaves, ReplicatedLeaf{
+			LeafIndex: int64(i),
+			LeafValue: []byte{byte(i)},
+			ExtraData: ed,
+		})
+		blobs[key] = []byte{byte(i)}
+	}
+	err = ts.ReplicationRestore(&ReplicationPayload{
+		TreeID: treeID,
+		Leaves: leaves[:2],
+		Blobs:  blobs,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A batch that does not continue the tree must fail
+	err = ts.ReplicationRestore(&ReplicationPayload{
+		TreeID: 7,
+		Leaves: leaves[1:2],
+	})
+	if err == nil {
+		t.Fatalf("restored a batch with a missing leaf")
+	}
+
+	// Restoring a batch again is a no-op, so a restore can be resumed
+	err = ts.ReplicationRestore(&ReplicationPayload{
+		TreeID: treeID,
+		Leaves: leaves,
+		Frozen: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := e.LeavesAll(treeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %v leaves, want 3", len(got))
+	}
+	for k, v := range got {
+		if v.LeafIndex != int64(k) || v.LeafValue[0] != byte(k) {
+			t.Fatalf("leaf %v: got index %v value %x", k, v.LeafIndex,
+				v.LeafValue)
+		}
+	}
+	tree, err := e.Tree(treeID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.TreeState != trillian.TreeState_FROZEN {
+		t.Fatalf("got tree state %v, want frozen", tree.TreeState)
+	}
+
+	// The blobs are restored and deleted
+	keys := make([]string, 0, len(blobs))
+	for k := range blobs {
+		keys = append(keys, k)
+	}
+	b, err := ts.store.Get(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != len(blobs) {
+		t.Fatalf("got %v blobs, want %v", len(b), len(blobs))
+	}
+	err = ts.ReplicationRestore(&ReplicationPayload{
+		Deleted: keys,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err = ts.store.Get(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 0 {
+		t.Fatalf("got %v blobs, want 0", len(b))
+	}
+}
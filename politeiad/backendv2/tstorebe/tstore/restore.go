@@ -0,0 +1,122 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package tstore
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/trillian"
+	"google.golang.org/grpc/codes"
+)
+
+// ReplicationRestore restores the data of a decrypted replication batch into
+// the tstore instance. The batches of a replication stream must be restored in
+// sequence. Leaves that have already been restored are skipped, so a restore
+// that was interrupted can be resumed from the start of the stream.
+//
+// The tlog tree IDs are the record tokens, so they must be preserved when the
+// trees are restored. Trillian assigns its own tree IDs, so the batches can
+// only be restored into a tstore instance that uses the embedded tlog. The
+// embedded tlog is saved to the kv store along with the blobs.
+func (t *Tstore) ReplicationRestore(rp *ReplicationPayload) error {
+	e, ok := t.tlog.(*embeddedLog)
+	if !ok {
+		return fmt.Errorf("replication batches can only be restored " +
+			"using the embedded tlog")
+	}
+
+	// Save the blobs. Unvetted blobs are encrypted by the kv store.
+	var (
+		plain     = make(map[string][]byte, len(rp.Blobs))
+		encrypted = make(map[string][]byte, len(rp.Blobs))
+	)
+	for k, v := range rp.Blobs {
+		if strings.HasPrefix(k, keyPrefixEncrypted) {
+			encrypted[k] = v
+			continue
+		}
+		plain[k] = v
+	}
+	if len(plain) > 0 {
+		err := t.store.Put(plain, false)
+		if err != nil {
+			return fmt.Errorf("store Put: %v", err)
+		}
+	}
+	if len(encrypted) > 0 {
+		err := t.store.Put(encrypted, true)
+		if err != nil {
+			return fmt.Errorf("store Put: %v", err)
+		}
+	}
+
+	// Append the leaves
+	if rp.TreeID != 0 {
+		err := e.treeRestore(rp.TreeID)
+		if err != nil {
+			return fmt.Errorf("treeRestore %v: %v", rp.TreeID, err)
+		}
+		err = restoreLeaves(e, rp.TreeID, rp.Leaves)
+		if err != nil {
+			return err
+		}
+		if rp.Frozen {
+			_, err = e.TreeFreeze(rp.TreeID)
+			if err != nil {
+				return fmt.Errorf("TreeFreeze %v: %v", rp.TreeID, err)
+			}
+		}
+	}
+
+	// Delete the blobs
+	if len(rp.Deleted) > 0 {
+		err := t.store.Del(rp.Deleted)
+		if err != nil {
+			return fmt.Errorf("store Del: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// restoreLeaves appends the replicated leaves onto an embedded log tree. The
+// leaves that the tree already contains are skipped. An error is returned if
+// the leaves do not continue the tree, i.e. a batch is missing.
+func restoreLeaves(e *embeddedLog, treeID int64, leaves []ReplicatedLeaf) error {
+	et, err := e.treeGet(treeID)
+	if err != nil {
+		return err
+	}
+	size := et.TreeSize
+	appends := make([]*trillian.LogLeaf, 0, len(leaves))
+	for _, v := range leaves {
+		switch {
+		case v.LeafIndex < size:
+			// Already restored
+			continue
+		case v.LeafIndex != size+int64(len(appends)):
+			return fmt.Errorf("tree %v: got leaf %v, want leaf %v", treeID,
+				v.LeafIndex, size+int64(len(appends)))
+		}
+		appends = append(appends, newLogLeaf(v.LeafValue, v.ExtraData))
+	}
+	if len(appends) == 0 {
+		return nil
+	}
+
+	queued, _, err := e.LeavesAppend(treeID, appends)
+	if err != nil {
+		return fmt.Errorf("LeavesAppend %v: %v", treeID, err)
+	}
+	for _, v := range queued {
+		c := codes.Code(v.QueuedLeaf.GetStatus().GetCode())
+		if c != codes.OK {
+			return fmt.Errorf("tree %v: leaf not appended: %v", treeID, c)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package tstore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// tokenCollisionRetries is the number of times that a new record
+	// token is created when using the retry token collision policy.
+	tokenCollisionRetries = 10
+
+	// shortTokenLengthKey is the key-value store key for the short
+	// token length that the tstore was last setup with.
+	shortTokenLengthKey = "shorttokenlength"
+)
+
+var (
+	// errTokenCollision is returned when the short token of a new
+	// record collides with the short token of an existing record.
+	errTokenCollision = errors.New("short token collision")
+)
+
+// shortTokenMigrate migrates the data that is keyed by short token when the
+// short token length has changed since the last time the tstore was setup.
+// The plugins save cache files to the plugin data dir using the short token
+// in the file name. These files are renamed to use the short token of the new
+// length.
+//
+// The short tokens of the new length must be unique. A short token length
+// can only be decreased if none of the existing records share a short token
+// of the new length.
+//
+// This function must be called after the tokens cache has been built.
+func (t *Tstore) shortTokenMigrate(tokens [][]byte) error {
+	// Get the short token length of the previous setup. The default
+	// short token length was used if one has not been saved.
+	blobs, err := t.store.Get([]string{shortTokenLengthKey})
+	if err != nil {
+		return err
+	}
+	prev := pdv2.ShortTokenLength
+	if b, ok := blobs[shortTokenLengthKey]; ok {
+		prev, err = strconv.Atoi(string(b))
+		if err != nil {
+			return err
+		}
+	}
+	curr := util.ShortTokenLength()
+	if prev == curr {
+		if _, ok := blobs[shortTokenLengthKey]; !ok {
+			return t.shortTokenLengthSave(curr)
+		}
+		// Nothing to migrate
+		return nil
+	}
+
+	log.Infof("Migrating short tokens from length %v to %v", prev, curr)
+
+	// Verify that the short tokens of the new length are unique. The
+	// tokens cache only contains a single entry for a short token.
+	t.RLock()
+	unique := len(t.tokens) == len(tokens)
+	t.RUnlock()
+	if !unique {
+		return fmt.Errorf("the short tokens of length %v are not unique; "+
+			"a longer short token length must be used", curr)
+	}
+
+	// Rename the plugin cache files
+	renames := make(map[string]string, len(tokens)) // [prevShort]currShort
+	for _, v := range tokens {
+		s := util.TokenEncode(v)
+		renames[s[:prev]] = s[:curr]
+	}
+	var count int
+	dir := filepath.Join(t.dataDir, pluginDataDirname)
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if path == dir && os.IsNotExist(err) {
+				// No plugin data has been saved yet
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		name := info.Name()
+		if strings.Index(name, "-") != prev {
+			return nil
+		}
+		short, ok := renames[name[:prev]]
+		if !ok {
+			return nil
+		}
+		newPath := filepath.Join(filepath.Dir(path), short+name[prev:])
+		log.Debugf("Rename %v to %v", path, newPath)
+		count++
+		return os.Rename(path, newPath)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("%v plugin cache files migrated", count)
+
+	return t.shortTokenLengthSave(curr)
+}
+
+// shortTokenLengthSave saves the short token length to the key-value store.
+func (t *Tstore) shortTokenLengthSave(length int) error {
+	return t.store.Put(map[string][]byte{
+		shortTokenLengthKey: []byte(strconv.Itoa(length)),
+	}, false)
+}
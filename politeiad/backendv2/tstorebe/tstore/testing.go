@@ -11,8 +11,10 @@ import (
 	"github.com/decred/politeia/politeiad/backendv2/tstorebe/store/localdb"
 )
 
-// NewTestTstore returns a tstore instance that is setup for testing.
-func NewTestTstore(t *testing.T, dataDir string) *Tstore {
+// NewTestTstore returns a tstore instance that is setup for testing. The
+// provided data directory must exist. The tstore instance uses an in memory
+// tlog client and a leveldb key-value store.
+func NewTestTstore(t testing.TB, dataDir string) *Tstore {
 	t.Helper()
 
 	// Setup datadir for this tstore instance
@@ -32,7 +34,11 @@ func NewTestTstore(t *testing.T, dataDir string) *Tstore {
 	}
 
 	return &Tstore{
-		tlog:  newTestTClient(t),
-		store: store,
+		dataDir:         dataDir,
+		tlog:            newTestTClient(t),
+		store:           store,
+		plugins:         make(map[string]plugin),
+		tokens:          make(map[string][]byte),
+		collisionPolicy: TokenCollisionRetry,
 	}
 }
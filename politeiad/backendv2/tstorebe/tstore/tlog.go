@@ -5,9 +5,12 @@
 package tstore
 
 import (
+	"context"
+	"encoding/hex"
 	"fmt"
 
 	backend "github.com/decred/politeia/politeiad/backendv2"
+	"github.com/decred/politeia/util/tracing"
 	"github.com/google/trillian"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -26,3 +29,32 @@ func (t *Tstore) leavesAll(treeID int64) ([]*trillian.LogLeaf, error) {
 	}
 	return leaves, nil
 }
+
+// startSpan starts a trace span for a tstore operation on a record. The
+// returned context contains the span. A nil span is returned when the context
+// is not being traced.
+func startSpan(ctx context.Context, op string, token []byte) (context.Context, *tracing.Span) {
+	ctx, s := tracing.Start(ctx, "tstore "+op, tracing.SpanKindInternal)
+	s.SetAttribute("token", hex.EncodeToString(token))
+	return ctx, s
+}
+
+// startTlogSpan starts a trace span for a call to the tlog client. Calls to a
+// trillian instance are traced as client spans. Calls to the embedded log are
+// traced as internal spans. A nil span is returned when the context is not
+// being traced.
+func (t *Tstore) startTlogSpan(ctx context.Context, method string, treeID int64) *tracing.Span {
+	var (
+		name = "tlog " + method
+		kind = tracing.SpanKindInternal
+	)
+	if t.tlogType == TlogTypeTrillian {
+		name = "trillian " + method
+		kind = tracing.SpanKindClient
+	}
+	_, s := tracing.Start(ctx, name, kind)
+	if treeID != 0 {
+		s.SetAttribute("treeid", treeID)
+	}
+	return s
+}
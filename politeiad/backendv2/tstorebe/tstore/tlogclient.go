@@ -62,7 +62,8 @@ type queuedLeafProof struct {
 // tlogClient provides an interface for interacting with a trillian log. It
 // creates an abstraction over the trillian provided TrillianLogClient and
 // TrillianAdminClient, creating a simplified API for the backend to use and
-// allowing us to create a implementation that can be used for testing.
+// allowing us to create alternate implementations, e.g. the embeddedLog and
+// an implementation that can be used for testing.
 type tlogClient interface {
 	// TreeNew creates a new tree.
 	TreeNew() (*trillian.Tree, *trillian.SignedLogRoot, error)
@@ -84,6 +85,11 @@ type tlogClient interface {
 	// LeavesAll returns all leaves of a tree.
 	LeavesAll(treeID int64) ([]*trillian.LogLeaf, error)
 
+	// LeavesByRange returns up to count leaves of a tree starting at
+	// the provided leaf index. Fewer leaves are returned when the end
+	// of the tree is reached.
+	LeavesByRange(treeID, startIndex, count int64) ([]*trillian.LogLeaf, error)
+
 	// SignedLogRoot returns the signed log root for a tree.
 	SignedLogRoot(tree *trillian.Tree) (*trillian.SignedLogRoot,
 		*types.LogRootV1, error)
@@ -492,6 +498,41 @@ func (t *tclient) LeavesAll(treeID int64) ([]*trillian.LogLeaf, error) {
 	return leaves, nil
 }
 
+// LeavesByRange returns up to count leaves of a tree starting at the provided
+// leaf index. Fewer leaves are returned when the end of the tree is reached.
+//
+// This function satisfies the tlogClient interface.
+func (t *tclient) LeavesByRange(treeID, startIndex, count int64) ([]*trillian.LogLeaf, error) {
+	log.Tracef("trillian LeavesByRange: %v %v %v", treeID, startIndex, count)
+
+	// Get tree
+	tree, err := t.Tree(treeID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get signed log root. Trillian returns an error when the range
+	// starts past the end of the tree.
+	_, lr, err := t.SignedLogRoot(tree)
+	if err != nil {
+		return nil, fmt.Errorf("SignedLogRoot: %v", err)
+	}
+	size := int64(lr.TreeSize)
+	if startIndex >= size {
+		return []*trillian.LogLeaf{}, nil
+	}
+	if startIndex+count > size {
+		count = size - startIndex
+	}
+
+	leaves, err := t.leavesByRange(treeID, startIndex, count)
+	if err != nil {
+		return nil, fmt.Errorf("leavesByRange: %v", err)
+	}
+
+	return leaves, nil
+}
+
 // Close closes the trillian grpc connection.
 //
 // This function satisfies the tlogClient interface.
@@ -612,6 +653,51 @@ func deriveTlogKey(kvstore store.BlobKV, passphrase string) (*keyspb.PrivateKey,
 	}, nil
 }
 
+const (
+	// tlogTypeKey is the kv store key for the type of the tlog that
+	// the tstore instance was created with.
+	tlogTypeKey = "tlogtype"
+)
+
+// verifyTlogType verifies that the provided tlog type is the tlog type that
+// the kv store was created with. The trees of the different tlog types are not
+// compatible, so the tlog type cannot be changed once records exist. The tlog
+// type is saved to the kv store on the first startup.
+//
+// Tstore instances that were created before the tlog type was saved can only
+// have used trillian. This function must be called before the tlog key is
+// derived, since the tlog key params are used to detect these instances.
+func verifyTlogType(kvstore store.BlobKV, tlogType string) error {
+	blobs, err := kvstore.Get([]string{tlogTypeKey, tlogKeyParamsKey})
+	if err != nil {
+		return fmt.Errorf("get: %v", err)
+	}
+	if b, ok := blobs[tlogTypeKey]; ok {
+		if string(b) != tlogType {
+			return fmt.Errorf("tlog type mismatch: the kv store was created "+
+				"using the %v tlog, got %v", b, tlogType)
+		}
+		return nil
+	}
+
+	prev := tlogType
+	if _, ok := blobs[tlogKeyParamsKey]; ok {
+		prev = TlogTypeTrillian
+	}
+	if prev != tlogType {
+		return fmt.Errorf("tlog type mismatch: the kv store was created "+
+			"using the %v tlog, got %v", prev, tlogType)
+	}
+	err = kvstore.Put(map[string][]byte{tlogTypeKey: []byte(tlogType)}, false)
+	if err != nil {
+		return fmt.Errorf("put: %v", err)
+	}
+
+	log.Infof("Tlog type saved to kv store")
+
+	return nil
+}
+
 // newTClient returns a new tclient.
 func newTClient(host string, privateKey *keyspb.PrivateKey) (*tclient, error) {
 	// Default gprc max message size is ~4MB (4194304 bytes). This is
@@ -730,7 +816,7 @@ func (t *testTClient) TreesAll() ([]*trillian.Tree, error) {
 	t.Lock()
 	defer t.Unlock()
 
-	trees := make([]*trillian.Tree, len(t.trees))
+	trees := make([]*trillian.Tree, 0, len(t.trees))
 	for _, v := range t.trees {
 		trees = append(trees, &trillian.Tree{
 			TreeId:             v.TreeId,
@@ -760,10 +846,7 @@ func (t *testTClient) LeavesAppend(treeID int64, leavesAppend []*trillian.LogLea
 	}
 
 	// Get last leaf index
-	var index int64
-	if len(leaves) > 0 {
-		index = int64(len(leaves)) - 1
-	}
+	index := int64(len(leaves)) - 1
 
 	// Append leaves
 	queued := make([]queuedLeafProof, 0, len(leavesAppend))
@@ -814,8 +897,8 @@ func (t *testTClient) LeavesAll(treeID int64) ([]*trillian.LogLeaf, error) {
 	leavesCopy := make([]*trillian.LogLeaf, 0, len(leaves))
 	for _, v := range leaves {
 		var (
-			leafValue []byte
-			extraData []byte
+			leafValue = make([]byte, len(v.LeafValue))
+			extraData = make([]byte, len(v.ExtraData))
 		)
 		copy(leafValue, v.LeafValue)
 		copy(extraData, v.ExtraData)
@@ -830,6 +913,25 @@ func (t *testTClient) LeavesAll(treeID int64) ([]*trillian.LogLeaf, error) {
 	return leavesCopy, nil
 }
 
+// LeavesByRange returns up to count leaves of a tree starting at the provided
+// leaf index.
+//
+// This function satisfies the tlogClient interface.
+func (t *testTClient) LeavesByRange(treeID, startIndex, count int64) ([]*trillian.LogLeaf, error) {
+	leaves, err := t.LeavesAll(treeID)
+	if err != nil {
+		return nil, err
+	}
+	if startIndex >= int64(len(leaves)) {
+		return []*trillian.LogLeaf{}, nil
+	}
+	end := startIndex + count
+	if end > int64(len(leaves)) {
+		end = int64(len(leaves))
+	}
+	return leaves[startIndex:end], nil
+}
+
 // SignedLogRoot has not been implemented yet.
 //
 // This function satisfies the tlogClient interface.
@@ -851,7 +953,7 @@ func (t *testTClient) InclusionProof(treeID int64, merkleLeafHash []byte, lr *ty
 func (t *testTClient) Close() {}
 
 // newTestTClient returns a new testTClient.
-func newTestTClient(t *testing.T) *testTClient {
+func newTestTClient(t testing.TB) *testTClient {
 	return &testTClient{
 		trees:  make(map[int64]*trillian.Tree),
 		leaves: make(map[int64][]*trillian.LogLeaf),
@@ -13,6 +13,7 @@ import (
 	"sync"
 
 	"github.com/decred/dcrd/chaincfg/v3"
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
 	backend "github.com/decred/politeia/politeiad/backendv2"
 	"github.com/decred/politeia/politeiad/backendv2/tstorebe/plugins"
 	"github.com/decred/politeia/politeiad/backendv2/tstorebe/store"
@@ -31,6 +32,25 @@ const (
 	// store to a MySQL instance.
 	DBTypeMySQL = "mysql"
 
+	// TlogTypeTrillian is a config option that sets the tlog
+	// implementation to a trillian log server.
+	TlogTypeTrillian = "trillian"
+
+	// TlogTypeEmbedded is a config option that sets the tlog
+	// implementation to an append-only log that is saved to the
+	// key-value store.
+	TlogTypeEmbedded = "embedded"
+
+	// TokenCollisionRetry is a config option that sets the token
+	// collision policy to discard a new record token whose short token
+	// collides with an existing record and to create a new token.
+	TokenCollisionRetry = "retry"
+
+	// TokenCollisionReject is a config option that sets the token
+	// collision policy to reject the creation of a record whose short
+	// token collides with an existing record.
+	TokenCollisionReject = "reject"
+
 	// LevelDB settings
 	storeDirname = "store"
 
@@ -60,12 +80,14 @@ var (
 // The tlog tree is append only and is treated as the source of truth. If any
 // blobs make it into the key-value store but do not make it into the tlog tree
 // they are considered to be orphaned and are simply ignored. We do not unwind
-// failed calls.
+// failed calls. Orphaned blobs are periodically removed from the key-value
+// store by the garbage collector.
 type Tstore struct {
 	sync.RWMutex
 	dataDir         string
 	activeNetParams *chaincfg.Params
 	tlog            tlogClient
+	tlogType        string
 	store           store.BlobKV
 	dcrtime         *dcrtimeClient
 	cron            *cron.Cron
@@ -76,6 +98,11 @@ type Tstore struct {
 	// using dcrtime. An anchor is dropped periodically using cron.
 	droppingAnchor bool
 
+	// collectingGarbage indicates whether tstore is in the process of
+	// garbage collecting orphaned blobs. The garbage collector is run
+	// periodically using cron.
+	collectingGarbage bool
+
 	// tokens contains the short token to full token mappings. The
 	// short token is the first n characters of the hex encoded record
 	// token, where n is defined by the short token length politeiad
@@ -84,6 +111,22 @@ type Tstore struct {
 	// and to facilitate lookups using only the short token. This cache
 	// is built on startup.
 	tokens map[string][]byte // [shortToken]fullToken
+
+	// legacyTokens contains the default length short token to full
+	// token mappings. It is only populated when the short token length
+	// has been changed from the default so that short tokens that were
+	// handed out prior to the change can still be looked up. A short
+	// token that is shared by multiple records maps to nil.
+	legacyTokens map[string][]byte // [shortToken]fullToken
+
+	// collisionPolicy is the token collision policy. It is applied
+	// when the short token of a new record collides with the short
+	// token of an existing record.
+	collisionPolicy string
+
+	// replicator streams the tstore changes to an off-site receiver.
+	// It is only set when replication has been enabled.
+	replicator *replicator
 }
 
 // tokenFromTreeID returns the record token for a tlog tree.
@@ -132,6 +175,16 @@ func (t *Tstore) tokenAdd(fullToken []byte) error {
 
 	t.Lock()
 	t.tokens[shortToken] = fullToken
+	if t.legacyTokens != nil {
+		legacy := util.TokenEncode(fullToken)[:pdv2.ShortTokenLength]
+		if _, ok := t.legacyTokens[legacy]; ok {
+			// Multiple records share this legacy short token. It can
+			// no longer be used for lookups.
+			t.legacyTokens[legacy] = nil
+		} else {
+			t.legacyTokens[legacy] = fullToken
+		}
+	}
 	t.Unlock()
 
 	log.Tracef("Token cache add: %v", shortToken)
@@ -159,12 +212,19 @@ func (t *Tstore) fullLengthToken(token []byte) ([]byte, error) {
 	defer t.RUnlock()
 
 	fullToken, ok := t.tokens[shortToken]
-	if !ok {
-		// Short token does not correspond to a record token
-		return nil, backend.ErrRecordNotFound
+	if ok {
+		return fullToken, nil
+	}
+
+	// Check if this is a short token that was handed out prior to
+	// a short token length change.
+	legacy, ok := util.LegacyShortToken(token)
+	if ok && t.legacyTokens[legacy] != nil {
+		return t.legacyTokens[legacy], nil
 	}
 
-	return fullToken, nil
+	// Short token does not correspond to a record token
+	return nil, backend.ErrRecordNotFound
 }
 
 // Fsck performs a filesystem check on the tstore.
@@ -178,6 +238,12 @@ func (t *Tstore) Fsck() {
 func (t *Tstore) Close() {
 	log.Tracef("Close")
 
+	// Stop the replication before the connections that it uses are
+	// closed.
+	if t.replicator != nil {
+		t.replicator.stop()
+	}
+
 	// Close connections
 	t.tlog.Close()
 	t.store.Close()
@@ -193,21 +259,33 @@ func (t *Tstore) Setup() error {
 	}
 
 	log.Infof("%v records in the tstore", len(tokens))
+	log.Infof("Short token length: %v", util.ShortTokenLength())
 
+	if util.ShortTokenLength() != pdv2.ShortTokenLength {
+		t.legacyTokens = make(map[string][]byte, len(tokens))
+	}
 	for _, v := range tokens {
 		t.tokenAdd(v)
 	}
 
+	// Migrate the data that is keyed by short token if the short
+	// token length has changed since the last startup.
+	err = t.shortTokenMigrate(tokens)
+	if err != nil {
+		return fmt.Errorf("short token migration: %v", err)
+	}
+
 	return nil
 }
 
-// New returns a new tstore instance.
-func New(appDir, dataDir string, anp *chaincfg.Params, tlogHost, tlogPass, dbType, dbHost, dbPass, dcrtimeHost, dcrtimeCert string) (*Tstore, error) {
+// connect returns the key-value store and the tlog client that are used by a
+// tstore instance.
+func connect(appDir, dataDir string, anp *chaincfg.Params, tlogType, tlogHost, tlogPass, dbType, dbHost, dbPass string) (store.BlobKV, tlogClient, error) {
 	// Setup datadir for this tstore instance
 	dataDir = filepath.Join(dataDir)
 	err := os.MkdirAll(dataDir, 0700)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Setup key-value store
@@ -218,30 +296,65 @@ func New(appDir, dataDir string, anp *chaincfg.Params, tlogHost, tlogPass, dbTyp
 		fp := filepath.Join(dataDir, storeDirname)
 		err = os.MkdirAll(fp, 0700)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		kvstore, err = localdb.New(appDir, fp)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	case DBTypeMySQL:
 		// Example db name: testnet3_unvetted_kv
 		dbName := fmt.Sprintf("%v_kv", anp.Name)
 		kvstore, err = mysql.New(dbHost, dbUser, dbPass, dbName)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	default:
-		return nil, fmt.Errorf("invalid db type: %v", dbType)
+		return nil, nil, fmt.Errorf("invalid db type: %v", dbType)
 	}
 
-	// Setup trillian client
-	log.Infof("Tlog host: %v", tlogHost)
+	// Setup tlog client
+	log.Infof("Tlog type: %v", tlogType)
+	err = verifyTlogType(kvstore, tlogType)
+	if err != nil {
+		return nil, nil, err
+	}
 	tlogKey, err := deriveTlogKey(kvstore, tlogPass)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	var tlogClient tlogClient
+	switch tlogType {
+	case TlogTypeTrillian:
+		log.Infof("Tlog host: %v", tlogHost)
+		tlogClient, err = newTClient(tlogHost, tlogKey)
+		if err != nil {
+			return nil, nil, err
+		}
+	case TlogTypeEmbedded:
+		tlogClient, err = newEmbeddedLog(kvstore, tlogKey)
+		if err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, fmt.Errorf("invalid tlog type: %v", tlogType)
+	}
+
+	return kvstore, tlogClient, nil
+}
+
+// New returns a new tstore instance.
+func New(appDir, dataDir string, anp *chaincfg.Params, tlogType, tlogHost, tlogPass, dbType, dbHost, dbPass, dcrtimeHost, dcrtimeCert, tokenCollision string) (*Tstore, error) {
+	switch tokenCollision {
+	case TokenCollisionRetry, TokenCollisionReject:
+		// Allowed; continue
+	default:
+		return nil, fmt.Errorf("invalid token collision policy '%v'",
+			tokenCollision)
 	}
-	tlogClient, err := newTClient(tlogHost, tlogKey)
+
+	kvstore, tlogClient, err := connect(appDir, dataDir, anp, tlogType,
+		tlogHost, tlogPass, dbType, dbHost, dbPass)
 	if err != nil {
 		return nil, err
 	}
@@ -264,11 +377,13 @@ func New(appDir, dataDir string, anp *chaincfg.Params, tlogHost, tlogPass, dbTyp
 		dataDir:         dataDir,
 		activeNetParams: anp,
 		tlog:            tlogClient,
+		tlogType:        tlogType,
 		store:           kvstore,
 		dcrtime:         dcrtimeClient,
 		cron:            cron.New(),
 		plugins:         make(map[string]plugin),
 		tokens:          make(map[string][]byte),
+		collisionPolicy: tokenCollision,
 	}
 
 	// Launch cron
@@ -282,6 +397,13 @@ func New(appDir, dataDir string, anp *chaincfg.Params, tlogHost, tlogPass, dbTyp
 	if err != nil {
 		return nil, err
 	}
+
+	log.Infof("Launch cron garbage collection job")
+	err = t.cron.AddFunc(gcSchedule, t.gc)
+	if err != nil {
+		return nil, err
+	}
+
 	t.cron.Start()
 
 	return &t, nil
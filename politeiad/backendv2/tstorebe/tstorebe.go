@@ -6,6 +6,7 @@ package tstorebe
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
@@ -334,7 +335,7 @@ func recordMetadataNew(token []byte, files []backend.File, state backend.StateT,
 // RecordNew creates a new record.
 //
 // This function satisfies the backendv2 Backend interface.
-func (t *tstoreBackend) RecordNew(metadata []backend.MetadataStream, files []backend.File) (*backend.Record, error) {
+func (t *tstoreBackend) RecordNew(ctx context.Context, metadata []backend.MetadataStream, files []backend.File) (*backend.Record, error) {
 	log.Tracef("RecordNew: %v metadata, %v files", len(metadata), len(files))
 
 	// Verify record content
@@ -362,7 +363,7 @@ func (t *tstoreBackend) RecordNew(metadata []backend.MetadataStream, files []bac
 	}
 
 	// Create a new token
-	token, err := t.tstore.RecordNew()
+	token, err := t.tstore.RecordNew(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -375,7 +376,7 @@ func (t *tstoreBackend) RecordNew(metadata []backend.MetadataStream, files []bac
 	}
 
 	// Save the record
-	err = t.tstore.RecordSave(token, *rm, metadata, files)
+	err = t.tstore.RecordSave(ctx, token, *rm, metadata, files)
 	if err != nil {
 		return nil, fmt.Errorf("RecordSave: %v", err)
 	}
@@ -408,7 +409,7 @@ func (t *tstoreBackend) RecordNew(metadata []backend.MetadataStream, files []bac
 // record.
 //
 // This function satisfies the backendv2 Backend interface.
-func (t *tstoreBackend) RecordEdit(token []byte, mdAppend, mdOverwrite []backend.MetadataStream, filesAdd []backend.File, filesDel []string) (*backend.Record, error) {
+func (t *tstoreBackend) RecordEdit(ctx context.Context, token []byte, mdAppend, mdOverwrite []backend.MetadataStream, filesAdd []backend.File, filesDel []string) (*backend.Record, error) {
 	log.Tracef("RecordEdit: %x", token)
 
 	// Verify record contents. Send in a single metadata array to
@@ -480,7 +481,7 @@ func (t *tstoreBackend) RecordEdit(token []byte, mdAppend, mdOverwrite []backend
 	}
 
 	// Save record
-	err = t.tstore.RecordSave(token, *recordMD, metadata, files)
+	err = t.tstore.RecordSave(ctx, token, *recordMD, metadata, files)
 	if err != nil {
 		switch err {
 		case backend.ErrRecordLocked:
@@ -507,7 +508,7 @@ func (t *tstoreBackend) RecordEdit(token []byte, mdAppend, mdOverwrite []backend
 // version of the record.
 //
 // This function satisfies the backendv2 Backend interface.
-func (t *tstoreBackend) RecordEditMetadata(token []byte, mdAppend, mdOverwrite []backend.MetadataStream) (*backend.Record, error) {
+func (t *tstoreBackend) RecordEditMetadata(ctx context.Context, token []byte, mdAppend, mdOverwrite []backend.MetadataStream) (*backend.Record, error) {
 	log.Tracef("RecordEditMetadata: %x", token)
 
 	// Verify metadata. Send in a single metadata array to verify there
@@ -568,7 +569,7 @@ func (t *tstoreBackend) RecordEditMetadata(token []byte, mdAppend, mdOverwrite [
 	}
 
 	// Update metadata
-	err = t.tstore.RecordSave(token, *recordMD, metadata, r.Files)
+	err = t.tstore.RecordSave(ctx, token, *recordMD, metadata, r.Files)
 	if err != nil {
 		switch err {
 		case backend.ErrRecordLocked, backend.ErrNoRecordChanges:
@@ -624,16 +625,16 @@ func statusChangeIsAllowed(from, to backend.StatusT) bool {
 // setStatusPublic updates the status of a record to public.
 //
 // This function must be called WITH the record lock held.
-func (t *tstoreBackend) setStatusPublic(token []byte, rm backend.RecordMetadata, metadata []backend.MetadataStream, files []backend.File) error {
-	return t.tstore.RecordSave(token, rm, metadata, files)
+func (t *tstoreBackend) setStatusPublic(ctx context.Context, token []byte, rm backend.RecordMetadata, metadata []backend.MetadataStream, files []backend.File) error {
+	return t.tstore.RecordSave(ctx, token, rm, metadata, files)
 }
 
 // setStatusArchived updates the status of a record to archived.
 //
 // This function must be called WITH the record lock held.
-func (t *tstoreBackend) setStatusArchived(token []byte, rm backend.RecordMetadata, metadata []backend.MetadataStream, files []backend.File) error {
+func (t *tstoreBackend) setStatusArchived(ctx context.Context, token []byte, rm backend.RecordMetadata, metadata []backend.MetadataStream, files []backend.File) error {
 	// Freeze record
-	err := t.tstore.RecordFreeze(token, rm, metadata, files)
+	err := t.tstore.RecordFreeze(ctx, token, rm, metadata, files)
 	if err != nil {
 		return fmt.Errorf("RecordFreeze: %v", err)
 	}
@@ -648,9 +649,9 @@ func (t *tstoreBackend) setStatusArchived(token []byte, rm backend.RecordMetadat
 // setStatusCensored updates the status of a record to censored.
 //
 // This function must be called WITH the record lock held.
-func (t *tstoreBackend) setStatusCensored(token []byte, rm backend.RecordMetadata, metadata []backend.MetadataStream, files []backend.File) error {
+func (t *tstoreBackend) setStatusCensored(ctx context.Context, token []byte, rm backend.RecordMetadata, metadata []backend.MetadataStream, files []backend.File) error {
 	// Freeze the tree
-	err := t.tstore.RecordFreeze(token, rm, metadata, files)
+	err := t.tstore.RecordFreeze(ctx, token, rm, metadata, files)
 	if err != nil {
 		return fmt.Errorf("RecordFreeze: %v", err)
 	}
@@ -671,7 +672,7 @@ func (t *tstoreBackend) setStatusCensored(token []byte, rm backend.RecordMetadat
 // RecordSetStatus sets the status of a record.
 //
 // This function satisfies the backendv2 Backend interface.
-func (t *tstoreBackend) RecordSetStatus(token []byte, status backend.StatusT, mdAppend, mdOverwrite []backend.MetadataStream) (*backend.Record, error) {
+func (t *tstoreBackend) RecordSetStatus(ctx context.Context, token []byte, status backend.StatusT, mdAppend, mdOverwrite []backend.MetadataStream) (*backend.Record, error) {
 	log.Tracef("RecordSetStatus: %x %v", token, status)
 
 	// Verify record exists
@@ -744,17 +745,17 @@ func (t *tstoreBackend) RecordSetStatus(token []byte, status backend.StatusT, md
 	// Update record status
 	switch status {
 	case backend.StatusPublic:
-		err := t.setStatusPublic(token, *recordMD, metadata, r.Files)
+		err := t.setStatusPublic(ctx, token, *recordMD, metadata, r.Files)
 		if err != nil {
 			return nil, err
 		}
 	case backend.StatusArchived:
-		err := t.setStatusArchived(token, *recordMD, metadata, r.Files)
+		err := t.setStatusArchived(ctx, token, *recordMD, metadata, r.Files)
 		if err != nil {
 			return nil, err
 		}
 	case backend.StatusCensored:
-		err := t.setStatusCensored(token, *recordMD, metadata, r.Files)
+		err := t.setStatusCensored(ctx, token, *recordMD, metadata, r.Files)
 		if err != nil {
 			return nil, err
 		}
@@ -901,6 +902,26 @@ func (t *tstoreBackend) InventoryOrdered(state backend.StateT, pageSize, pageNum
 	return tokens, nil
 }
 
+// InventorySnapshot returns the tokens of all records in the inventory along
+// with the current inventory version.
+//
+// This function satisfies the backendv2 Backend interface.
+func (t *tstoreBackend) InventorySnapshot() (*backend.InventorySnapshot, error) {
+	log.Tracef("InventorySnapshot")
+
+	return t.invSnapshot()
+}
+
+// InventoryDelta returns a page of the inventory changes that were made after
+// the provided inventory version, sorted from oldest to newest.
+//
+// This function satisfies the backendv2 Backend interface.
+func (t *tstoreBackend) InventoryDelta(version uint64, pageSize uint32) (*backend.InventoryDelta, error) {
+	log.Tracef("InventoryDelta: %v %v", version, pageSize)
+
+	return t.invDelta(version, pageSize)
+}
+
 // PluginRegister registers a plugin.
 //
 // This function satisfies the backendv2 Backend interface.
@@ -936,7 +957,7 @@ func (t *tstoreBackend) PluginRead(token []byte, pluginID, pluginCmd, payload st
 // PluginWrite executes a plugin command that writes data.
 //
 // This function satisfies the backendv2 Backend interface.
-func (t *tstoreBackend) PluginWrite(token []byte, pluginID, pluginCmd, payload string) (string, error) {
+func (t *tstoreBackend) PluginWrite(ctx context.Context, token []byte, pluginID, pluginCmd, payload string) (string, error) {
 	log.Tracef("PluginWrite: %x %v %v", token, pluginID, pluginCmd)
 
 	// Verify record exists
@@ -974,7 +995,7 @@ func (t *tstoreBackend) PluginWrite(token []byte, pluginID, pluginCmd, payload s
 	}
 
 	// Execute plugin command
-	reply, err := t.tstore.PluginWrite(token, pluginID, pluginCmd, payload)
+	reply, err := t.tstore.PluginWrite(ctx, token, pluginID, pluginCmd, payload)
 	if err != nil {
 		return "", err
 	}
@@ -1020,16 +1041,29 @@ func (t *tstoreBackend) Close() {
 	t.tstore.Close()
 }
 
+// EnableReplication enables the streaming of the tstore changes to an
+// off-site receiver. This must be called prior to the backend being used.
+func (t *tstoreBackend) EnableReplication(receiverURL, passphrase string) error {
+	return t.tstore.EnableReplication(receiverURL, passphrase)
+}
+
+// ReplicationStatus returns the status of the replication stream. It returns
+// nil if replication has not been enabled.
+func (t *tstoreBackend) ReplicationStatus() *tstore.ReplicationStatus {
+	return t.tstore.ReplicationStatus()
+}
+
 // setup performs any required work to setup the tstore instance.
 func (t *tstoreBackend) setup() error {
 	return t.tstore.Setup()
 }
 
 // New returns a new tstoreBackend.
-func New(appDir, dataDir string, anp *chaincfg.Params, tlogHost, tlogPass, dbType, dbHost, dbPass, dcrtimeHost, dcrtimeCert string) (*tstoreBackend, error) {
+func New(appDir, dataDir string, anp *chaincfg.Params, tlogType, tlogHost, tlogPass, dbType, dbHost, dbPass, dcrtimeHost, dcrtimeCert, tokenCollision string) (*tstoreBackend, error) {
 	// Setup tstore instances
-	ts, err := tstore.New(appDir, dataDir, anp, tlogHost,
-		tlogPass, dbType, dbHost, dbPass, dcrtimeHost, dcrtimeCert)
+	ts, err := tstore.New(appDir, dataDir, anp, tlogType, tlogHost,
+		tlogPass, dbType, dbHost, dbPass, dcrtimeHost, dcrtimeCert,
+		tokenCollision)
 	if err != nil {
 		return nil, fmt.Errorf("new tstore: %v", err)
 	}
@@ -5,20 +5,18 @@
 package backendv2
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/bits"
 
 	"github.com/decred/dcrtime/merkle"
 	dmerkle "github.com/decred/dcrtime/merkle"
 	"github.com/decred/politeia/util"
-	"github.com/google/trillian"
-	tmerkle "github.com/google/trillian/merkle"
-	"github.com/google/trillian/merkle/hashers/registry"
-	_ "github.com/google/trillian/merkle/rfc6962"
 )
 
 const (
@@ -37,6 +35,71 @@ type ExtraDataTrillianRFC6962 struct {
 	TreeSize  int64 `json:"treesize"`
 }
 
+// rfc6962HashLeaf returns the RFC 6962 leaf hash of the provided leaf value.
+func rfc6962HashLeaf(leaf []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x00})
+	h.Write(leaf)
+	return h.Sum(nil)
+}
+
+// rfc6962HashChildren returns the RFC 6962 hash of an interior merkle tree
+// node from the hashes of its left and right children.
+func rfc6962HashChildren(l, r []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x01})
+	h.Write(l)
+	h.Write(r)
+	return h.Sum(nil)
+}
+
+// verifyInclusionRFC6962 verifies that the leaf hash at the provided index is
+// included in the merkle root of an RFC 6962 merkle tree of the provided size.
+// This is the inclusion proof verification that is used by trillian. It is
+// implemented here so that clients can verify timestamps without depending on
+// trillian.
+func verifyInclusionRFC6962(leafIndex, treeSize int64, proof [][]byte, root, leafHash []byte) error {
+	switch {
+	case leafIndex < 0:
+		return fmt.Errorf("negative leaf index %v", leafIndex)
+	case treeSize < 0:
+		return fmt.Errorf("negative tree size %v", treeSize)
+	case leafIndex >= treeSize:
+		return fmt.Errorf("leaf index %v out of range for tree size %v",
+			leafIndex, treeSize)
+	case len(leafHash) != sha256.Size:
+		return fmt.Errorf("invalid leaf hash size %v", len(leafHash))
+	}
+
+	// The proof consists of the inner nodes on the path from the leaf
+	// to the point where the path diverges from the right border of
+	// the tree, followed by the border nodes.
+	inner := bits.Len64(uint64(leafIndex ^ (treeSize - 1)))
+	border := bits.OnesCount64(uint64(leafIndex) >> uint(inner))
+	if len(proof) != inner+border {
+		return fmt.Errorf("invalid proof size: got %v, want %v",
+			len(proof), inner+border)
+	}
+
+	h := leafHash
+	for i, v := range proof[:inner] {
+		if (leafIndex>>uint(i))&1 == 0 {
+			h = rfc6962HashChildren(h, v)
+		} else {
+			h = rfc6962HashChildren(v, h)
+		}
+	}
+	for _, v := range proof[inner:] {
+		h = rfc6962HashChildren(v, h)
+	}
+
+	if !bytes.Equal(h, root) {
+		return fmt.Errorf("root mismatch: got %x, want %x", h, root)
+	}
+
+	return nil
+}
+
 // verifyProofTrillian verifies a proof with the type ProofTypeTrillianRFC6962.
 func verifyProofTrillian(p Proof) error {
 	// Verify type
@@ -47,15 +110,11 @@ func verifyProofTrillian(p Proof) error {
 	// The digest of the data is stored in trillian as the leaf value.
 	// The digest of the leaf value is the digest that is included in
 	// the log merkle root.
-	h, err := registry.NewLogHasher(trillian.HashStrategy_RFC6962_SHA256)
-	if err != nil {
-		return err
-	}
 	leafValue, err := hex.DecodeString(p.Digest)
 	if err != nil {
 		return err
 	}
-	leafHash := h.HashLeaf(leafValue)
+	leafHash := rfc6962HashLeaf(leafValue)
 
 	merkleRoot, err := hex.DecodeString(p.MerkleRoot)
 	if err != nil {
@@ -77,8 +136,7 @@ func verifyProofTrillian(p Proof) error {
 		return err
 	}
 
-	verifier := tmerkle.NewLogVerifier(h)
-	return verifier.VerifyInclusionProof(ed.LeafIndex, ed.TreeSize,
+	return verifyInclusionRFC6962(ed.LeafIndex, ed.TreeSize,
 		merklePath, merkleRoot, leafHash)
 }
 
@@ -0,0 +1,67 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package backendv2
+
+import (
+	"fmt"
+	"testing"
+)
+
+// testTreeHash returns the RFC 6962 merkle tree hash of the leaf hashes.
+func testTreeHash(leaves [][]byte) []byte {
+	if len(leaves) == 1 {
+		return leaves[0]
+	}
+	k := 1
+	for k*2 < len(leaves) {
+		k *= 2
+	}
+	return rfc6962HashChildren(testTreeHash(leaves[:k]),
+		testTreeHash(leaves[k:]))
+}
+
+// testPath returns the RFC 6962 audit path of the leaf at index m using the
+// recursive definition of section 2.1.1 of the RFC.
+func testPath(m int, leaves [][]byte) [][]byte {
+	if len(leaves) == 1 {
+		return nil
+	}
+	k := 1
+	for k*2 < len(leaves) {
+		k *= 2
+	}
+	if m < k {
+		return append(testPath(m, leaves[:k]), testTreeHash(leaves[k:]))
+	}
+	return append(testPath(m-k, leaves[k:]), testTreeHash(leaves[:k]))
+}
+
+func TestVerifyInclusionRFC6962(t *testing.T) {
+	for size := 1; size <= 9; size++ {
+		leaves := make([][]byte, 0, size)
+		for i := 0; i < size; i++ {
+			leaves = append(leaves, rfc6962HashLeaf([]byte(fmt.Sprint(i))))
+		}
+		root := testTreeHash(leaves)
+		for i := 0; i < size; i++ {
+			proof := testPath(i, leaves)
+			err := verifyInclusionRFC6962(int64(i), int64(size), proof,
+				root, leaves[i])
+			if err != nil {
+				t.Fatalf("size %v leaf %v: %v", size, i, err)
+			}
+
+			// A proof for the wrong leaf must fail
+			other := leaves[(i+1)%size]
+			if size > 1 {
+				err = verifyInclusionRFC6962(int64(i), int64(size), proof,
+					root, other)
+				if err == nil {
+					t.Fatalf("size %v leaf %v: wrong leaf verified", size, i)
+				}
+			}
+		}
+	}
+}
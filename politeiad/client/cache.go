@@ -0,0 +1,266 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/decred/politeia/politeiad/plugins/ticketvote"
+)
+
+// cache caches the replies of the politeiad plugin commands that are
+// requested for the same tokens over and over again, e.g. by the proposal
+// listing pages. The cached replies expire after the cache TTL. The replies
+// of a record must be invalidated by the caller when the record has changed
+// in a way that changes the replies, e.g. when a new vote has been cast.
+//
+// The cached replies record the politeiad write version that was seen prior
+// to the reply being retrieved. A reply is not returned to a request that
+// requires a more recent version. See SetMinVersion.
+//
+// A retrieval that started before a record was invalidated may return the
+// replies from before the change. The cache generation is incremented on each
+// invalidation so that these replies are not put in the cache. See fetch.
+type cache struct {
+	sync.Mutex
+	ttl       time.Duration
+	summaries map[string]cachedSummary // [token]cachedSummary
+	counts    map[string]cachedCount   // [token]cachedCount
+
+	// generation is incremented on each invalidation. The invalidated
+	// map contains the generation and time of the most recent
+	// invalidation of a record. An invalidation only needs to be kept
+	// for the cache TTL since a retrieval that started before it would
+	// have expired by then.
+	generation  uint64
+	invalidated map[string]invalidation // [token]invalidation
+}
+
+// invalidation is the most recent invalidation of a record.
+type invalidation struct {
+	generation uint64
+	timestamp  time.Time
+}
+
+// fetch is a retrieval of replies that are put in the cache. It must be
+// started prior to retrieving the replies from politeiad.
+type fetch struct {
+	generation uint64    // Cache generation at the start
+	start      time.Time // Start time, used for the expiry
+	version    uint64    // Write version prior to the retrieval
+}
+
+// cachedSummary is a cached ticketvote plugin Summary reply.
+type cachedSummary struct {
+	reply   ticketvote.SummaryReply
+	expires time.Time
+	version uint64 // Write version prior to the retrieval
+}
+
+// cachedCount is a cached comments plugin Count reply.
+type cachedCount struct {
+	count   uint32
+	expires time.Time
+	version uint64 // Write version prior to the retrieval
+}
+
+// newCache returns a new cache.
+func newCache(ttl time.Duration) *cache {
+	return &cache{
+		ttl:         ttl,
+		summaries:   make(map[string]cachedSummary, 256),
+		counts:      make(map[string]cachedCount, 256),
+		invalidated: make(map[string]invalidation, 256),
+	}
+}
+
+// begin starts a retrieval of replies that will be put in the cache. The
+// version is the politeiad write version prior to the retrieval.
+func (c *cache) begin(version uint64) fetch {
+	c.Lock()
+	defer c.Unlock()
+
+	return fetch{
+		generation: c.generation,
+		start:      time.Now(),
+		version:    version,
+	}
+}
+
+// stale returns whether the record of the provided cached token has been
+// invalidated since the fetch started. The token may be a short token, so
+// all invalidated tokens that it is a prefix of are checked.
+//
+// This function must be called WITH the lock held.
+func (c *cache) stale(token string, f fetch) bool {
+	if c.generation == f.generation {
+		return false
+	}
+	if i, ok := c.invalidated[token]; ok && i.generation > f.generation {
+		return true
+	}
+	for k, v := range c.invalidated {
+		if v.generation > f.generation && strings.HasPrefix(k, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// getSummaries returns the cached vote summaries of the provided tokens and
+// the tokens that were not found in the cache. The summaries that were
+// retrieved prior to the min version are treated as not found.
+func (c *cache) getSummaries(tokens []string, minVersion uint64) (map[string]ticketvote.SummaryReply, []string) {
+	c.Lock()
+	defer c.Unlock()
+
+	now := time.Now()
+	found := make(map[string]ticketvote.SummaryReply, len(tokens))
+	missing := make([]string, 0, len(tokens))
+	for _, v := range tokens {
+		s, ok := c.summaries[v]
+		if !ok || now.After(s.expires) {
+			delete(c.summaries, v)
+			missing = append(missing, v)
+			continue
+		}
+		if s.version < minVersion {
+			missing = append(missing, v)
+			continue
+		}
+		found[v] = s.reply
+	}
+
+	return found, missing
+}
+
+// putSummaries adds the vote summaries that were retrieved by the provided
+// fetch to the cache. The summaries of the records that were invalidated
+// during the fetch are not added.
+func (c *cache) putSummaries(summaries map[string]ticketvote.SummaryReply, f fetch) {
+	c.Lock()
+	defer c.Unlock()
+
+	expires := f.start.Add(c.ttl)
+	for k, v := range summaries {
+		if c.stale(k, f) {
+			continue
+		}
+		c.summaries[k] = cachedSummary{
+			reply:   v,
+			expires: expires,
+			version: f.version,
+		}
+	}
+}
+
+// getCounts returns the cached comment counts of the provided tokens and the
+// tokens that were not found in the cache. The counts that were retrieved
+// prior to the min version are treated as not found.
+func (c *cache) getCounts(tokens []string, minVersion uint64) (map[string]uint32, []string) {
+	c.Lock()
+	defer c.Unlock()
+
+	now := time.Now()
+	found := make(map[string]uint32, len(tokens))
+	missing := make([]string, 0, len(tokens))
+	for _, v := range tokens {
+		cc, ok := c.counts[v]
+		if !ok || now.After(cc.expires) {
+			delete(c.counts, v)
+			missing = append(missing, v)
+			continue
+		}
+		if cc.version < minVersion {
+			missing = append(missing, v)
+			continue
+		}
+		found[v] = cc.count
+	}
+
+	return found, missing
+}
+
+// putCounts adds the comment counts that were retrieved by the provided fetch
+// to the cache. The counts of the records that were invalidated during the
+// fetch are not added.
+func (c *cache) putCounts(counts map[string]uint32, f fetch) {
+	c.Lock()
+	defer c.Unlock()
+
+	expires := f.start.Add(c.ttl)
+	for k, v := range counts {
+		if c.stale(k, f) {
+			continue
+		}
+		c.counts[k] = cachedCount{
+			count:   v,
+			expires: expires,
+			version: f.version,
+		}
+	}
+}
+
+// invalidate removes the cached replies of a record. The replies may have
+// been cached using a short token, so all cached tokens that are a prefix of
+// the provided token are removed.
+func (c *cache) invalidate(token string) {
+	c.Lock()
+	defer c.Unlock()
+
+	// Record the invalidation and prune the invalidations that can no
+	// longer affect a fetch.
+	now := time.Now()
+	c.generation++
+	c.invalidated[token] = invalidation{
+		generation: c.generation,
+		timestamp:  now,
+	}
+	for k, v := range c.invalidated {
+		if now.Sub(v.timestamp) > c.ttl {
+			delete(c.invalidated, k)
+		}
+	}
+
+	for k := range c.summaries {
+		if strings.HasPrefix(token, k) {
+			delete(c.summaries, k)
+		}
+	}
+	for k := range c.counts {
+		if strings.HasPrefix(token, k) {
+			delete(c.counts, k)
+		}
+	}
+}
+
+// EnableCache enables the caching of the ticketvote plugin Summary replies
+// and the comments plugin Count replies for the provided duration. This must
+// be set prior to the client being used.
+func (c *Client) EnableCache(ttl time.Duration) {
+	c.cache = newCache(ttl)
+}
+
+// InvalidateCache removes the cached replies of a record. It must be called
+// when a record has changed in a way that changes its cached replies. This is
+// a no-op when the cache has not been enabled.
+func (c *Client) InvalidateCache(token string) {
+	if c.cache == nil {
+		return
+	}
+	c.cache.invalidate(token)
+}
+
+// cacheMinVersion returns the politeiad write version that the cached replies
+// must reflect for the request of the provided context.
+func (c *Client) cacheMinVersion(ctx context.Context) uint64 {
+	if c.minVersion == nil {
+		return 0
+	}
+	return c.minVersion(ctx)
+}
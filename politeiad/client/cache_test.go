@@ -0,0 +1,138 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/politeiad/plugins/ticketvote"
+)
+
+const (
+	testToken      = "e8bca53eb9ca4bb4"
+	testShortToken = "e8bca53"
+)
+
+func TestCacheGetPut(t *testing.T) {
+	c := newCache(time.Minute)
+
+	// Put a count and a summary
+	f := c.begin(5)
+	c.putCounts(map[string]uint32{testToken: 7}, f)
+	c.putSummaries(map[string]ticketvote.SummaryReply{
+		testToken: {Status: ticketvote.VoteStatusStarted},
+	}, f)
+
+	counts, missing := c.getCounts([]string{testToken, "other"}, 0)
+	if counts[testToken] != 7 || len(missing) != 1 || missing[0] != "other" {
+		t.Fatalf("got counts %v, missing %v", counts, missing)
+	}
+	summaries, missing := c.getSummaries([]string{testToken}, 5)
+	if len(missing) != 0 ||
+		summaries[testToken].Status != ticketvote.VoteStatusStarted {
+		t.Fatalf("got summaries %v, missing %v", summaries, missing)
+	}
+
+	// A reply that was retrieved prior to the min version is not
+	// returned
+	_, missing = c.getCounts([]string{testToken}, 6)
+	if len(missing) != 1 {
+		t.Fatalf("got missing %v, want the token", missing)
+	}
+	_, missing = c.getSummaries([]string{testToken}, 6)
+	if len(missing) != 1 {
+		t.Fatalf("got missing %v, want the token", missing)
+	}
+
+	// An expired reply is not returned. The expiry is set from the
+	// start of the fetch.
+	f.start = time.Now().Add(-2 * time.Minute)
+	c.putCounts(map[string]uint32{testToken: 7}, f)
+	_, missing = c.getCounts([]string{testToken}, 0)
+	if len(missing) != 1 {
+		t.Fatalf("got missing %v, want the token", missing)
+	}
+	if _, ok := c.counts[testToken]; ok {
+		t.Fatalf("expired count was not removed")
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	c := newCache(time.Minute)
+
+	// The replies may have been cached using a short token
+	f := c.begin(0)
+	c.putCounts(map[string]uint32{
+		testShortToken: 1,
+		"other":        2,
+	}, f)
+	c.putSummaries(map[string]ticketvote.SummaryReply{
+		testToken: {},
+	}, f)
+	c.invalidate(testToken)
+
+	counts, missing := c.getCounts([]string{testShortToken, "other"}, 0)
+	if len(missing) != 1 || missing[0] != testShortToken ||
+		counts["other"] != 2 {
+		t.Fatalf("got counts %v, missing %v", counts, missing)
+	}
+	_, missing = c.getSummaries([]string{testToken}, 0)
+	if len(missing) != 1 {
+		t.Fatalf("got missing %v, want the token", missing)
+	}
+}
+
+func TestCacheStalePut(t *testing.T) {
+	c := newCache(time.Minute)
+
+	// Replies that were retrieved before an invalidation of their
+	// record are not added to the cache. The replies of the other
+	// records are added.
+	f := c.begin(0)
+	c.invalidate(testToken)
+	c.putCounts(map[string]uint32{
+		testToken:      1,
+		testShortToken: 1,
+		"other":        2,
+	}, f)
+	c.putSummaries(map[string]ticketvote.SummaryReply{
+		testToken: {},
+		"other":   {},
+	}, f)
+	for _, v := range []string{testToken, testShortToken} {
+		if _, ok := c.counts[v]; ok {
+			t.Fatalf("stale count of %v was added", v)
+		}
+	}
+	if _, ok := c.summaries[testToken]; ok {
+		t.Fatalf("stale summary was added")
+	}
+	if _, ok := c.counts["other"]; !ok {
+		t.Fatalf("count of other record was not added")
+	}
+	if _, ok := c.summaries["other"]; !ok {
+		t.Fatalf("summary of other record was not added")
+	}
+
+	// A fetch that started after the invalidation is added
+	f = c.begin(0)
+	c.putCounts(map[string]uint32{testToken: 1}, f)
+	if _, ok := c.counts[testToken]; !ok {
+		t.Fatalf("count was not added")
+	}
+
+	// The invalidations are pruned once they are older than the TTL
+	i := c.invalidated[testToken]
+	i.timestamp = time.Now().Add(-2 * time.Minute)
+	c.invalidated[testToken] = i
+	c.invalidate("other")
+	if _, ok := c.invalidated[testToken]; ok {
+		t.Fatalf("invalidation was not pruned")
+	}
+	if _, ok := c.invalidated["other"]; !ok {
+		t.Fatalf("invalidation was not recorded")
+	}
+}
@@ -10,19 +10,101 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/decred/politeia/politeiad/api/v1/identity"
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
 	"github.com/decred/politeia/util"
+	"github.com/decred/politeia/util/tracing"
 )
 
 // Client provides a client for interacting with the politeiad API.
 type Client struct {
+	version uint64 // Most recent politeiad write version; atomic
 	rpcHost string
 	rpcCert string
 	rpcUser string
 	rpcPass string
 	http    *http.Client
-	pid     *identity.PublicIdentity
+
+	// pid is the politeiad identity that the replies are verified
+	// against. It is replaced when politeiad rotates its identity.
+	pidMtx sync.RWMutex
+	pid    *identity.PublicIdentity
+
+	// rotated is called with the new politeiad identity when the
+	// identity has been rotated.
+	rotated RotationFunc
+
+	// observer is called with the latency of every politeiad request
+	// when it has been set.
+	observer ObserverFunc
+
+	// minVersion returns the politeiad write version that the cached
+	// replies must reflect when it has been set.
+	minVersion MinVersionFunc
+
+	// cache caches the replies of the plugin commands that are
+	// requested repeatedly when it has been enabled.
+	cache *cache
+}
+
+// ObserverFunc is called after every politeiad request with the request
+// context, the request route, the politeiad write version of the reply, the
+// request latency, and the error that was returned, if any. The version is
+// zero when the reply did not contain one.
+type ObserverFunc func(ctx context.Context, route string, version uint64, d time.Duration, err error)
+
+// SetObserver sets the function that is called after every politeiad request.
+// This must be set prior to the client being used.
+func (c *Client) SetObserver(fn ObserverFunc) {
+	c.observer = fn
+}
+
+// MinVersionFunc returns the politeiad write version that a cached reply must
+// reflect in order to be returned for the request of the provided context.
+type MinVersionFunc func(ctx context.Context) uint64
+
+// SetMinVersion sets the function that returns the politeiad write version
+// that the cached replies must reflect. The cached replies that were retrieved
+// before the version are not returned. This must be set prior to the client
+// being used.
+func (c *Client) SetMinVersion(fn MinVersionFunc) {
+	c.minVersion = fn
+}
+
+// Version returns the most recent politeiad write version that has been seen
+// by the client. A reply that is retrieved after this call is guaranteed to
+// reflect all writes up to the returned version.
+func (c *Client) Version() uint64 {
+	return atomic.LoadUint64(&c.version)
+}
+
+// observeVersion updates the most recent politeiad write version.
+func (c *Client) observeVersion(version uint64) {
+	for {
+		v := atomic.LoadUint64(&c.version)
+		if version <= v {
+			return
+		}
+		if atomic.CompareAndSwapUint64(&c.version, v, version) {
+			return
+		}
+	}
+}
+
+// VersionFromHeader returns the politeiad write version that is contained in
+// the provided reply header. Zero is returned if the header does not contain
+// a valid version. See the politeiad v2 VersionHeader for more details.
+func VersionFromHeader(h http.Header) uint64 {
+	v, err := strconv.ParseUint(h.Get(pdv2.VersionHeader), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
 }
 
 // ErrorReply represents the request body that is returned from politeaid when
@@ -56,15 +138,34 @@ func (e RespError) Error() string {
 // slice of the response body. A RespError is returned if politeiad responds
 // with anything other than a 200 http status code.
 func (c *Client) makeReq(ctx context.Context, method, api, route string, v interface{}) ([]byte, error) {
+	start := time.Now()
+	b, version, err := c.doReq(ctx, method, api, route, v)
+	c.observeVersion(version)
+	if c.observer != nil {
+		c.observer(ctx, api+route, version, time.Since(start), err)
+	}
+	return b, err
+}
+
+// doReq makes a politeiad http request and returns the reply body and the
+// politeiad write version of the reply. See makeReq for more details.
+//
+// The request is traced when the context contains a trace span. The span is
+// propagated to politeiad using the traceparent header.
+func (c *Client) doReq(ctx context.Context, method, api, route string, v interface{}) (b []byte, version uint64, err error) {
+	ctx, span := tracing.Start(ctx, "politeiad "+api+route,
+		tracing.SpanKindClient)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+
 	// Serialize body
-	var (
-		reqBody []byte
-		err     error
-	)
+	var reqBody []byte
 	if v != nil {
 		reqBody, err = json.Marshal(v)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 	}
 
@@ -73,29 +174,38 @@ func (c *Client) makeReq(ctx context.Context, method, api, route string, v inter
 	req, err := http.NewRequestWithContext(ctx, method,
 		fullRoute, bytes.NewReader(reqBody))
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	req.SetBasicAuth(c.rpcUser, c.rpcPass)
+	tracing.Inject(ctx, req.Header)
 	r, err := c.http.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer r.Body.Close()
+	version = VersionFromHeader(r.Header)
 
 	// Handle reply
 	if r.StatusCode != http.StatusOK {
 		var e ErrorReply
 		decoder := json.NewDecoder(r.Body)
 		if err := decoder.Decode(&e); err != nil {
-			return nil, fmt.Errorf("status code %v: %v", r.StatusCode, err)
+			return nil, version, fmt.Errorf("status code %v: %v",
+				r.StatusCode, err)
 		}
-		return nil, RespError{
+		return nil, version, RespError{
 			HTTPCode:   r.StatusCode,
 			ErrorReply: e,
 		}
 	}
 
-	return util.RespBody(r), nil
+	return util.RespBody(r), version, nil
+}
+
+// Close closes the idle connections to politeiad. The client can still be
+// used once it has been closed. New connections are made as needed.
+func (c *Client) Close() {
+	c.http.CloseIdleConnections()
 }
 
 // New returns a new politeiad client.
@@ -106,8 +106,41 @@ func (c *Client) CommentDel(ctx context.Context, d comments.Del) (*comments.DelR
 // CommentCount sends a batch of comment plugin Count commands to the
 // politeiad v2 API and returns a map[token]count with the results. If a
 // record is not found for a token or any other error occurs, that token
-// will not be included in the reply.
+// will not be included in the reply. The counts are served from the cache
+// when it has been enabled.
 func (c *Client) CommentCount(ctx context.Context, tokens []string) (map[string]uint32, error) {
+	if c.cache == nil {
+		return c.commentCount(ctx, tokens)
+	}
+
+	counts, missing := c.cache.getCounts(tokens, c.cacheMinVersion(ctx))
+	if len(missing) == 0 {
+		return counts, nil
+	}
+	f := c.cache.begin(c.Version())
+	cc, err := c.commentCount(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.putCounts(cc, f)
+	for k, v := range cc {
+		counts[k] = v
+	}
+
+	return counts, nil
+}
+
+// CommentCountNoCache sends a batch of comment plugin Count commands to the
+// politeiad v2 API and returns a map[token]count with the results. The cache
+// is bypassed. This is used by callers that maintain their own cache of the
+// comment counts and that must not be served stale counts.
+func (c *Client) CommentCountNoCache(ctx context.Context, tokens []string) (map[string]uint32, error) {
+	return c.commentCount(ctx, tokens)
+}
+
+// commentCount sends a batch of comment plugin Count commands to the
+// politeiad v2 API.
+func (c *Client) commentCount(ctx context.Context, tokens []string) (map[string]uint32, error) {
 	// Setup request
 	cmds := make([]pdv2.PluginCmd, 0, len(tokens))
 	for _, v := range tokens {
@@ -0,0 +1,103 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	"github.com/decred/politeia/politeiad/plugins/dcrdata"
+)
+
+// dcrdataRead sends a dcrdata plugin read command to the politeiad v2 API and
+// decodes the reply into the provided reply.
+func (c *Client) dcrdataRead(ctx context.Context, cmd string, payload, reply interface{}) error {
+	// Setup request
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	cmds := []pdv2.PluginCmd{
+		{
+			ID:      dcrdata.PluginID,
+			Command: cmd,
+			Token:   "",
+			Payload: string(b),
+		},
+	}
+
+	// Send request
+	replies, err := c.PluginReads(ctx, cmds)
+	if err != nil {
+		return err
+	}
+	if len(replies) == 0 {
+		return fmt.Errorf("no replies found")
+	}
+	pcr := replies[0]
+	err = extractPluginCmdError(pcr)
+	if err != nil {
+		return err
+	}
+
+	// Decode reply
+	return json.Unmarshal([]byte(pcr.Payload), reply)
+}
+
+// DcrdataBestBlock sends the dcrdata plugin BestBlock command to the
+// politeiad v2 API.
+func (c *Client) DcrdataBestBlock(ctx context.Context) (*dcrdata.BestBlockReply, error) {
+	var bbr dcrdata.BestBlockReply
+	err := c.dcrdataRead(ctx, dcrdata.CmdBestBlock,
+		dcrdata.BestBlock{}, &bbr)
+	if err != nil {
+		return nil, err
+	}
+	return &bbr, nil
+}
+
+// DcrdataBlockDetails sends the dcrdata plugin BlockDetails command to the
+// politeiad v2 API.
+func (c *Client) DcrdataBlockDetails(ctx context.Context, height uint32) (*dcrdata.BlockDetailsReply, error) {
+	var bdr dcrdata.BlockDetailsReply
+	err := c.dcrdataRead(ctx, dcrdata.CmdBlockDetails,
+		dcrdata.BlockDetails{
+			Height: height,
+		}, &bdr)
+	if err != nil {
+		return nil, err
+	}
+	return &bdr, nil
+}
+
+// DcrdataTicketPool sends the dcrdata plugin TicketPool command to the
+// politeiad v2 API.
+func (c *Client) DcrdataTicketPool(ctx context.Context, blockHash string) (*dcrdata.TicketPoolReply, error) {
+	var tpr dcrdata.TicketPoolReply
+	err := c.dcrdataRead(ctx, dcrdata.CmdTicketPool,
+		dcrdata.TicketPool{
+			BlockHash: blockHash,
+		}, &tpr)
+	if err != nil {
+		return nil, err
+	}
+	return &tpr, nil
+}
+
+// DcrdataTxsTrimmed sends the dcrdata plugin TxsTrimmed command to the
+// politeiad v2 API.
+func (c *Client) DcrdataTxsTrimmed(ctx context.Context, txIDs []string) (*dcrdata.TxsTrimmedReply, error) {
+	var ttr dcrdata.TxsTrimmedReply
+	err := c.dcrdataRead(ctx, dcrdata.CmdTxsTrimmed,
+		dcrdata.TxsTrimmed{
+			TxIDs: txIDs,
+		}, &ttr)
+	if err != nil {
+		return nil, err
+	}
+	return &ttr, nil
+}
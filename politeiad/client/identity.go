@@ -0,0 +1,91 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+
+	v1 "github.com/decred/politeia/politeiad/api/v1"
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	"github.com/decred/politeia/util"
+)
+
+// RotationFunc is called with the new politeiad identity when the client has
+// verified a politeiad identity rotation.
+type RotationFunc func(pid *identity.PublicIdentity)
+
+// SetRotationHandler sets the function that is called when the client has
+// verified a politeiad identity rotation. It can be used to persist the new
+// identity. This must be set prior to the client being used.
+func (c *Client) SetRotationHandler(fn RotationFunc) {
+	c.rotated = fn
+}
+
+// PublicIdentity returns the politeiad identity that the replies are verified
+// against.
+func (c *Client) PublicIdentity() *identity.PublicIdentity {
+	c.pidMtx.RLock()
+	defer c.pidMtx.RUnlock()
+
+	return c.pid
+}
+
+// verifyChallenge verifies that the challenge response was signed by the
+// politeiad identity. A response that does not verify may have been signed by
+// a new politeiad identity. The client replaces its identity with the new one
+// and verifies the response again if politeiad advertises an identity rotation
+// that was signed by the current identity.
+func (c *Client) verifyChallenge(ctx context.Context, challenge []byte, response string) error {
+	err := util.VerifyChallenge(c.PublicIdentity(), challenge, response)
+	if err == nil {
+		return nil
+	}
+	pid, rerr := c.rotate(ctx)
+	if rerr != nil {
+		return err
+	}
+	return util.VerifyChallenge(pid, challenge, response)
+}
+
+// rotate requests the politeiad identity and replaces the identity of the
+// client if politeiad has rotated its identity. See rotateTo for more details.
+func (c *Client) rotate(ctx context.Context) (*identity.PublicIdentity, error) {
+	ir, pid, err := c.identity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.rotateTo(*ir, pid)
+}
+
+// rotateTo replaces the identity of the client with the provided politeiad
+// identity once the identity rotation of the identity reply has been verified
+// against the current identity. The current identity is returned when it is
+// the same as the provided identity.
+func (c *Client) rotateTo(ir v1.IdentityReply, pid *identity.PublicIdentity) (*identity.PublicIdentity, error) {
+	prev := c.PublicIdentity()
+	if prev == nil || pid.String() == prev.String() {
+		return prev, nil
+	}
+	err := v1.VerifyRotation(*prev, ir)
+	if err != nil {
+		return nil, err
+	}
+
+	c.pidMtx.Lock()
+	if c.pid.String() != prev.String() {
+		// The identity was rotated by a concurrent request
+		pid = c.pid
+		c.pidMtx.Unlock()
+		return pid, nil
+	}
+	c.pid = pid
+	c.pidMtx.Unlock()
+
+	if c.rotated != nil {
+		c.rotated(pid)
+	}
+
+	return pid, nil
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/hex"
+	"testing"
+
+	v1 "github.com/decred/politeia/politeiad/api/v1"
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+)
+
+func TestRotateTo(t *testing.T) {
+	prev, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// rotationReply returns an identity reply for the next identity
+	// with a rotation that was signed by the provided identity.
+	rotationReply := func(signer *identity.FullIdentity) v1.IdentityReply {
+		ir := v1.IdentityReply{
+			PublicKey:     next.Public.String(),
+			PrevPublicKey: prev.Public.String(),
+			RotationEnd:   1700000000,
+		}
+		sig := signer.SignMessage([]byte(v1.RotationMessage(ir.PublicKey,
+			ir.RotationEnd)))
+		ir.RotationSignature = hex.EncodeToString(sig[:])
+		return ir
+	}
+
+	// A rotation that was not signed by the pinned identity is
+	// rejected
+	var rotated *identity.PublicIdentity
+	c := &Client{
+		pid: &prev.Public,
+		rotated: func(pid *identity.PublicIdentity) {
+			rotated = pid
+		},
+	}
+	_, err = c.rotateTo(rotationReply(other), &next.Public)
+	if err != v1.ErrInvalidRotation {
+		t.Fatalf("got err %v, want %v", err, v1.ErrInvalidRotation)
+	}
+	if c.PublicIdentity() != &prev.Public || rotated != nil {
+		t.Fatalf("identity was rotated")
+	}
+
+	// A rotation that was signed by the pinned identity replaces it
+	pid, err := c.rotateTo(rotationReply(prev), &next.Public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pid != &next.Public || c.PublicIdentity() != &next.Public {
+		t.Fatalf("got identity %v, want %v", c.PublicIdentity(), next.Public)
+	}
+	if rotated != &next.Public {
+		t.Fatalf("rotation handler got %v, want %v", rotated, next.Public)
+	}
+
+	// The same identity is not rotated again
+	rotated = nil
+	pid, err = c.rotateTo(rotationReply(prev), &next.Public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pid != &next.Public || rotated != nil {
+		t.Fatalf("identity was rotated again")
+	}
+}
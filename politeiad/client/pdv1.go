@@ -16,12 +16,28 @@ import (
 	"github.com/decred/politeia/util"
 )
 
-// Identity sends a Identity request to the politeiad v1 API.
+// Identity sends a Identity request to the politeiad v1 API. The identity of
+// the client is replaced when politeiad advertises an identity rotation that
+// was signed by it.
 func (c *Client) Identity(ctx context.Context) (*identity.PublicIdentity, error) {
+	ir, pid, err := c.identity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// An error only means that the client identity was not rotated
+	_, _ = c.rotateTo(*ir, pid)
+
+	return pid, nil
+}
+
+// identity sends a Identity request to the politeiad v1 API and returns the
+// reply along with the politeiad identity that signed it.
+func (c *Client) identity(ctx context.Context) (*v1.IdentityReply, *identity.PublicIdentity, error) {
 	// Setup request
 	challenge, err := util.Random(pdv1.ChallengeSize)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	i := v1.Identity{
 		Challenge: hex.EncodeToString(challenge),
@@ -31,25 +47,25 @@ func (c *Client) Identity(ctx context.Context) (*identity.PublicIdentity, error)
 	resBody, err := c.makeReq(ctx, http.MethodPost, "",
 		pdv1.IdentityRoute, i)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Decode reply
 	var ir v1.IdentityReply
 	err = json.Unmarshal(resBody, &ir)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	pid, err := util.IdentityFromString(ir.PublicKey)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	err = util.VerifyChallenge(pid, challenge, ir.Response)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return pid, nil
+	return &ir, pid, nil
 }
 
 // NewRecord sends a NewRecord request to the politeiad v1 API.
@@ -78,7 +94,7 @@ func (c *Client) NewRecord(ctx context.Context, metadata []pdv1.MetadataStream,
 	if err != nil {
 		return nil, err
 	}
-	err = util.VerifyChallenge(c.pid, challenge, nrr.Response)
+	err = c.verifyChallenge(ctx, challenge, nrr.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -112,7 +128,7 @@ func (c *Client) updateRecord(ctx context.Context, route, token string, mdAppend
 	if err != nil {
 		return err
 	}
-	err = util.VerifyChallenge(c.pid, challenge, urr.Response)
+	err = c.verifyChallenge(ctx, challenge, urr.Response)
 	if err != nil {
 		return err
 	}
@@ -161,7 +177,7 @@ func (c *Client) UpdateVettedMetadata(ctx context.Context, token string, mdAppen
 	if err != nil {
 		return err
 	}
-	err = util.VerifyChallenge(c.pid, challenge, uvmr.Response)
+	err = c.verifyChallenge(ctx, challenge, uvmr.Response)
 	if err != nil {
 		return err
 	}
@@ -198,7 +214,7 @@ func (c *Client) SetUnvettedStatus(ctx context.Context, token string, status pdv
 	if err != nil {
 		return err
 	}
-	err = util.VerifyChallenge(c.pid, challenge, susr.Response)
+	err = c.verifyChallenge(ctx, challenge, susr.Response)
 	if err != nil {
 		return err
 	}
@@ -234,7 +250,7 @@ func (c *Client) SetVettedStatus(ctx context.Context, token string, status pdv1.
 	if err != nil {
 		return err
 	}
-	err = util.VerifyChallenge(c.pid, challenge, svsr.Response)
+	err = c.verifyChallenge(ctx, challenge, svsr.Response)
 	if err != nil {
 		return err
 	}
@@ -267,7 +283,7 @@ func (c *Client) GetUnvetted(ctx context.Context, token string) (*pdv1.Record, e
 	if err != nil {
 		return nil, err
 	}
-	err = util.VerifyChallenge(c.pid, challenge, gur.Response)
+	err = c.verifyChallenge(ctx, challenge, gur.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -301,7 +317,7 @@ func (c *Client) GetVetted(ctx context.Context, token, version string) (*pdv1.Re
 	if err != nil {
 		return nil, err
 	}
-	err = util.VerifyChallenge(c.pid, challenge, gvr.Response)
+	err = c.verifyChallenge(ctx, challenge, gvr.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -337,7 +353,7 @@ func (c *Client) PluginCommand(ctx context.Context, pluginID, cmd, payload strin
 	if err != nil {
 		return "", err
 	}
-	err = util.VerifyChallenge(c.pid, challenge, pcr.Response)
+	err = c.verifyChallenge(ctx, challenge, pcr.Response)
 	if err != nil {
 		return "", err
 	}
@@ -44,7 +44,7 @@ func (c *Client) RecordNew(ctx context.Context, metadata []pdv2.MetadataStream,
 	if err != nil {
 		return nil, err
 	}
-	err = util.VerifyChallenge(c.pid, challenge, rnr.Response)
+	err = c.verifyChallenge(ctx, challenge, rnr.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -81,7 +81,7 @@ func (c *Client) RecordEdit(ctx context.Context, token string, mdAppend, mdOverw
 	if err != nil {
 		return nil, err
 	}
-	err = util.VerifyChallenge(c.pid, challenge, rer.Response)
+	err = c.verifyChallenge(ctx, challenge, rer.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -117,7 +117,7 @@ func (c *Client) RecordEditMetadata(ctx context.Context, token string, mdAppend,
 	if err != nil {
 		return nil, err
 	}
-	err = util.VerifyChallenge(c.pid, challenge, reply.Response)
+	err = c.verifyChallenge(ctx, challenge, reply.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -153,7 +153,7 @@ func (c *Client) RecordSetStatus(ctx context.Context, token string, status pdv2.
 	if err != nil {
 		return nil, err
 	}
-	err = util.VerifyChallenge(c.pid, challenge, reply.Response)
+	err = c.verifyChallenge(ctx, challenge, reply.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -187,7 +187,7 @@ func (c *Client) RecordTimestamps(ctx context.Context, token string, version uin
 	if err != nil {
 		return nil, err
 	}
-	err = util.VerifyChallenge(c.pid, challenge, reply.Response)
+	err = c.verifyChallenge(ctx, challenge, reply.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -220,7 +220,7 @@ func (c *Client) Records(ctx context.Context, reqs []pdv2.RecordRequest) (map[st
 	if err != nil {
 		return nil, err
 	}
-	err = util.VerifyChallenge(c.pid, challenge, reply.Response)
+	err = c.verifyChallenge(ctx, challenge, reply.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -255,7 +255,7 @@ func (c *Client) Inventory(ctx context.Context, state pdv2.RecordStateT, status
 	if err != nil {
 		return nil, err
 	}
-	err = util.VerifyChallenge(c.pid, challenge, ir.Response)
+	err = c.verifyChallenge(ctx, challenge, ir.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -289,7 +289,7 @@ func (c *Client) InventoryOrdered(ctx context.Context, state pdv2.RecordStateT,
 	if err != nil {
 		return nil, err
 	}
-	err = util.VerifyChallenge(c.pid, challenge, ir.Response)
+	err = c.verifyChallenge(ctx, challenge, ir.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -297,6 +297,72 @@ func (c *Client) InventoryOrdered(ctx context.Context, state pdv2.RecordStateT,
 	return ir.Tokens, nil
 }
 
+// InventorySnapshot sends a InventorySnapshot command to the politeiad v2
+// API.
+func (c *Client) InventorySnapshot(ctx context.Context) (*pdv2.InventorySnapshotReply, error) {
+	// Setup request
+	challenge, err := util.Random(pdv2.ChallengeSize)
+	if err != nil {
+		return nil, err
+	}
+	is := pdv2.InventorySnapshot{
+		Challenge: hex.EncodeToString(challenge),
+	}
+
+	// Send request
+	resBody, err := c.makeReq(ctx, http.MethodPost,
+		pdv2.APIRoute, pdv2.RouteInventorySnapshot, is)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode reply
+	var isr pdv2.InventorySnapshotReply
+	err = json.Unmarshal(resBody, &isr)
+	if err != nil {
+		return nil, err
+	}
+	err = c.verifyChallenge(ctx, challenge, isr.Response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &isr, nil
+}
+
+// InventoryDelta sends a InventoryDelta command to the politeiad v2 API.
+func (c *Client) InventoryDelta(ctx context.Context, version uint64) (*pdv2.InventoryDeltaReply, error) {
+	// Setup request
+	challenge, err := util.Random(pdv2.ChallengeSize)
+	if err != nil {
+		return nil, err
+	}
+	id := pdv2.InventoryDelta{
+		Challenge: hex.EncodeToString(challenge),
+		Version:   version,
+	}
+
+	// Send request
+	resBody, err := c.makeReq(ctx, http.MethodPost,
+		pdv2.APIRoute, pdv2.RouteInventoryDelta, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode reply
+	var idr pdv2.InventoryDeltaReply
+	err = json.Unmarshal(resBody, &idr)
+	if err != nil {
+		return nil, err
+	}
+	err = c.verifyChallenge(ctx, challenge, idr.Response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &idr, nil
+}
+
 // PluginWrite sends a PluginWrite command to the politeiad v2 API.
 func (c *Client) PluginWrite(ctx context.Context, cmd pdv2.PluginCmd) (string, error) {
 	// Setup request
@@ -322,7 +388,7 @@ func (c *Client) PluginWrite(ctx context.Context, cmd pdv2.PluginCmd) (string, e
 	if err != nil {
 		return "", err
 	}
-	err = util.VerifyChallenge(c.pid, challenge, pwr.Response)
+	err = c.verifyChallenge(ctx, challenge, pwr.Response)
 	if err != nil {
 		return "", err
 	}
@@ -355,7 +421,7 @@ func (c *Client) PluginReads(ctx context.Context, cmds []pdv2.PluginCmd) ([]pdv2
 	if err != nil {
 		return nil, err
 	}
-	err = util.VerifyChallenge(c.pid, challenge, prr.Response)
+	err = c.verifyChallenge(ctx, challenge, prr.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -387,7 +453,7 @@ func (c *Client) PluginInventory(ctx context.Context) ([]pdv2.Plugin, error) {
 	if err != nil {
 		return nil, err
 	}
-	err = util.VerifyChallenge(c.pid, challenge, pir.Response)
+	err = c.verifyChallenge(ctx, challenge, pir.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -395,6 +461,70 @@ func (c *Client) PluginInventory(ctx context.Context) ([]pdv2.Plugin, error) {
 	return pir.Plugins, nil
 }
 
+// PluginSettings sends a PluginSettings command to the politeiad v2 API.
+func (c *Client) PluginSettings(ctx context.Context) (map[string][]pdv2.PluginSettingDetails, error) {
+	// Setup request
+	challenge, err := util.Random(pdv2.ChallengeSize)
+	if err != nil {
+		return nil, err
+	}
+	ps := pdv2.PluginSettings{
+		Challenge: hex.EncodeToString(challenge),
+	}
+
+	// Send request
+	resBody, err := c.makeReq(ctx, http.MethodPost,
+		pdv2.APIRoute, pdv2.RoutePluginSettings, ps)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode reply
+	var psr pdv2.PluginSettingsReply
+	err = json.Unmarshal(resBody, &psr)
+	if err != nil {
+		return nil, err
+	}
+	err = c.verifyChallenge(ctx, challenge, psr.Response)
+	if err != nil {
+		return nil, err
+	}
+
+	return psr.Settings, nil
+}
+
+// Usage sends a Usage command to the politeiad v2 API.
+func (c *Client) Usage(ctx context.Context) (*pdv2.UsageReply, error) {
+	// Setup request
+	challenge, err := util.Random(pdv2.ChallengeSize)
+	if err != nil {
+		return nil, err
+	}
+	u := pdv2.Usage{
+		Challenge: hex.EncodeToString(challenge),
+	}
+
+	// Send request
+	resBody, err := c.makeReq(ctx, http.MethodPost,
+		pdv2.APIRoute, pdv2.RouteUsage, u)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode reply
+	var ur pdv2.UsageReply
+	err = json.Unmarshal(resBody, &ur)
+	if err != nil {
+		return nil, err
+	}
+	err = c.verifyChallenge(ctx, challenge, ur.Response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ur, nil
+}
+
 // RecordVerify verifies the censorship record of a v2 Record.
 func RecordVerify(r pdv2.Record, serverPubKey string) error {
 	// Verify censorship record merkle root
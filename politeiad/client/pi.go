@@ -0,0 +1,82 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	"github.com/decred/politeia/politeiad/plugins/pi"
+)
+
+// PiSetAuthorUpdate sends the pi plugin SetAuthorUpdate command to the
+// politeiad v2 API.
+func (c *Client) PiSetAuthorUpdate(ctx context.Context, sau pi.SetAuthorUpdate) (*pi.AuthorUpdate, error) {
+	// Setup request
+	b, err := json.Marshal(sau)
+	if err != nil {
+		return nil, err
+	}
+	cmd := pdv2.PluginCmd{
+		Token:   sau.Token,
+		ID:      pi.PluginID,
+		Command: pi.CmdSetAuthorUpdate,
+		Payload: string(b),
+	}
+
+	// Send request
+	reply, err := c.PluginWrite(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode reply
+	var saur pi.SetAuthorUpdateReply
+	err = json.Unmarshal([]byte(reply), &saur)
+	if err != nil {
+		return nil, err
+	}
+
+	return &saur.AuthorUpdate, nil
+}
+
+// PiAuthorUpdates sends the pi plugin AuthorUpdates command to the politeiad
+// v2 API.
+func (c *Client) PiAuthorUpdates(ctx context.Context, token string) ([]pi.AuthorUpdate, error) {
+	// Setup request
+	cmds := []pdv2.PluginCmd{
+		{
+			Token:   token,
+			ID:      pi.PluginID,
+			Command: pi.CmdAuthorUpdates,
+			Payload: "",
+		},
+	}
+
+	// Send request
+	replies, err := c.PluginReads(ctx, cmds)
+	if err != nil {
+		return nil, err
+	}
+	if len(replies) == 0 {
+		return nil, fmt.Errorf("no replies found")
+	}
+	pcr := replies[0]
+	err = extractPluginCmdError(pcr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode reply
+	var aur pi.AuthorUpdatesReply
+	err = json.Unmarshal([]byte(pcr.Payload), &aur)
+	if err != nil {
+		return nil, err
+	}
+
+	return aur.Updates, nil
+}
@@ -219,8 +219,33 @@ func (c *Client) TicketVoteSummary(ctx context.Context, token string) (*ticketvo
 
 // TicketVoteSummaries sends a batch of ticketvote plugin Summary commands to
 // the politeiad v2 API. Individual summary errors are not returned, the token
-// will simply be left out of the returned map.
+// will simply be left out of the returned map. The summaries are served from
+// the cache when it has been enabled.
 func (c *Client) TicketVoteSummaries(ctx context.Context, tokens []string) (map[string]ticketvote.SummaryReply, error) {
+	if c.cache == nil {
+		return c.ticketVoteSummaries(ctx, tokens)
+	}
+
+	summaries, missing := c.cache.getSummaries(tokens, c.cacheMinVersion(ctx))
+	if len(missing) == 0 {
+		return summaries, nil
+	}
+	f := c.cache.begin(c.Version())
+	ts, err := c.ticketVoteSummaries(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.putSummaries(ts, f)
+	for k, v := range ts {
+		summaries[k] = v
+	}
+
+	return summaries, nil
+}
+
+// ticketVoteSummaries sends a batch of ticketvote plugin Summary commands to
+// the politeiad v2 API.
+func (c *Client) ticketVoteSummaries(ctx context.Context, tokens []string) (map[string]ticketvote.SummaryReply, error) {
 	// Setup request
 	cmds := make([]pdv2.PluginCmd, 0, len(tokens))
 	for _, v := range tokens {
@@ -295,6 +320,37 @@ func (c *Client) TicketVoteSubmissions(ctx context.Context, token string) ([]str
 	return sr.Submissions, nil
 }
 
+// TicketVoteSubmissionsRepair sends the ticketvote plugin SubmissionsRepair
+// command to the politeiad v2 API.
+func (c *Client) TicketVoteSubmissionsRepair(ctx context.Context, token string, sr ticketvote.SubmissionsRepair) (*ticketvote.SubmissionsRepairReply, error) {
+	// Setup request
+	b, err := json.Marshal(sr)
+	if err != nil {
+		return nil, err
+	}
+	cmd := pdv2.PluginCmd{
+		Token:   token,
+		ID:      ticketvote.PluginID,
+		Command: ticketvote.CmdSubmissionsRepair,
+		Payload: string(b),
+	}
+
+	// Send request
+	reply, err := c.PluginWrite(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode reply
+	var srr ticketvote.SubmissionsRepairReply
+	err = json.Unmarshal([]byte(reply), &srr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &srr, nil
+}
+
 // TicketVoteInventory sends the ticketvote plugin Inventory command to the
 // politeiad v2 API.
 func (c *Client) TicketVoteInventory(ctx context.Context, i ticketvote.Inventory) (*ticketvote.InventoryReply, error) {
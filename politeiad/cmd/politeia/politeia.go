@@ -77,6 +77,8 @@ Available commands:
                    Args: <token>
   inventory        Get the record inventory 
                    Args (optional): <state> <status> <page>
+  inventorydelta   Get the inventory changes since an inventory version
+                   Args: <version>
 
 Metadata actions: appendmetadata, overwritemetadata
 File actions: add, del
@@ -814,6 +816,48 @@ func recordInventory() error {
 	return nil
 }
 
+// recordInventoryDelta retrieves the inventory changes that were made after
+// the provided inventory version. A version of 0 returns the changes since
+// the inventory was created, if they are still retained.
+func recordInventoryDelta() error {
+	flags := flag.Args()[1:] // Chop off action.
+	if len(flags) != 1 {
+		return fmt.Errorf("must provide an inventory version")
+	}
+	version, err := strconv.ParseUint(flags[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("unable to parse version '%v': %v", flags[0], err)
+	}
+
+	// Load server identity
+	pid, err := identity.LoadPublicIdentity(*identityFilename)
+	if err != nil {
+		return err
+	}
+
+	// Setup client
+	c, err := pdclient.New(*rpchost, *rpccert, *rpcuser, *rpcpass, pid)
+	if err != nil {
+		return err
+	}
+
+	// Get inventory changes
+	idr, err := c.InventoryDelta(context.Background(), version)
+	if err != nil {
+		return err
+	}
+
+	if *verbose {
+		fmt.Printf("Version: %v\n", idr.Version)
+		for _, v := range idr.Changes {
+			fmt.Printf("%v %v %v %v\n", v.Version, v.Token,
+				v2.RecordStates[v.State], v2.RecordStatuses[v.Status])
+		}
+	}
+
+	return nil
+}
+
 func _main() error {
 	flag.Usage = usage
 	flag.Parse()
@@ -867,6 +911,8 @@ func _main() error {
 				return record()
 			case "inventory":
 				return recordInventory()
+			case "inventorydelta":
+				return recordInventoryDelta()
 			default:
 				return fmt.Errorf("invalid action: %v", a)
 			}
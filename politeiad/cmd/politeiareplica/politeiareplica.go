@@ -0,0 +1,383 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/politeia/politeiad/backendv2/tstorebe/tstore"
+	"github.com/decred/politeia/politeiad/sharedconfig"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// batchSizeMax is the maximum size of a replication batch request.
+	batchSizeMax = 256 << 20 // 256 MiB
+
+	// envReplicationPass is the env variable that contains the
+	// replication password when verifying the batches.
+	envReplicationPass = "REPLICATIONPASS"
+)
+
+var (
+	defaultHomeDir = sharedconfig.DefaultHomeDir
+
+	// regexpStreamID matches a valid replication stream ID.
+	regexpStreamID = regexp.MustCompile("^[0-9a-f]{16}$")
+
+	// errBatchExists is returned when a different batch with the same
+	// sequence number has already been saved.
+	errBatchExists = errors.New("batch exists")
+)
+
+var (
+	// CLI flags
+	listen = flag.String("listen", ":49400", "Interface/port to listen on")
+	dir    = flag.String("dir", "replica", "Directory that the batches "+
+		"are saved to")
+	cert = flag.String("cert", "", "File containing the https certificate; "+
+		"plain http is used when not set")
+	key  = flag.String("key", "", "File containing the https certificate key")
+	user = flag.String("user", "", "Basic auth user name that politeiad "+
+		"must provide")
+	pass = flag.String("pass", "", "Basic auth password that politeiad "+
+		"must provide")
+	verify = flag.Bool("verify", false, "Decrypt the saved batches using "+
+		"the password in the env variable "+envReplicationPass+" and "+
+		"print a summary instead of receiving batches")
+	restore = flag.Bool("restore", false, "Restore the saved batches into "+
+		"a tstore instance that uses the embedded tlog instead of "+
+		"receiving batches")
+	stream = flag.String("stream", "", "Stream ID of the batches that are "+
+		"verified or restored; required when the dir contains more "+
+		"than one stream")
+
+	// Restore flags
+	homeDir = flag.String("homedir", defaultHomeDir, "politeiad home dir "+
+		"path that the batches are restored to")
+	testnet  = flag.Bool("testnet", false, "Restore testnet data")
+	dbType   = flag.String("dbtype", tstore.DBTypeLevelDB, "Database type")
+	dbHost   = flag.String("dbhost", "localhost:3306", "Database ip:port")
+	dbPass   = flag.String("dbpass", "", "Database password")
+	tlogPass = flag.String("tlogpass", "", "Tlog signing key password")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: politeiareplica [flags]\n")
+	fmt.Fprintf(os.Stderr, " flags:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\n")
+}
+
+// batchPath returns the path of the file that a batch is saved to. The
+// batches of a stream are saved to a directory that is named after the
+// stream ID. The filenames sort in sequence order.
+func batchPath(streamID string, sequence uint64) string {
+	return filepath.Join(*dir, streamID, fmt.Sprintf("%020d.json", sequence))
+}
+
+// authorized returns whether the request contains the basic auth
+// credentials.
+func authorized(r *http.Request) bool {
+	if *user == "" && *pass == "" {
+		return true
+	}
+	u, p, ok := r.BasicAuth()
+	return ok &&
+		subtle.ConstantTimeCompare([]byte(u), []byte(*user)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(p), []byte(*pass)) == 1
+}
+
+// saveBatch saves a batch to disk. A batch that already exists is never
+// overwritten. errBatchExists is returned if a different batch with the same
+// sequence number has already been saved.
+func saveBatch(rb tstore.ReplicationBatch, b []byte) error {
+	fp := batchPath(rb.StreamID, rb.Sequence)
+	err := os.MkdirAll(filepath.Dir(fp), 0700)
+	if err != nil {
+		return err
+	}
+
+	// The batch is linked into place so that an existing batch is
+	// never replaced, even by a concurrent request.
+	tmp, err := ioutil.TempFile(filepath.Dir(fp), "batch")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(b)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	err = os.Link(tmp.Name(), fp)
+	if !errors.Is(err, os.ErrExist) {
+		return err
+	}
+
+	// A resent batch that is identical to the saved batch is accepted
+	saved, err := ioutil.ReadFile(fp)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(saved, b) {
+		return errBatchExists
+	}
+	return nil
+}
+
+// handleBatch saves a replication batch to disk. The batch remains encrypted.
+// A saved batch is never overwritten. A batch whose sequence number has
+// already been used is rejected with a 409 and politeiad continues the stream
+// using the next sequence number.
+func handleBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !authorized(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	b, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, batchSizeMax))
+	if err != nil {
+		http.Error(w, "invalid body", http.StatusBadRequest)
+		return
+	}
+	var rb tstore.ReplicationBatch
+	err = json.Unmarshal(b, &rb)
+	if err != nil || rb.Sequence == 0 || len(rb.Payload) == 0 ||
+		!regexpStreamID.MatchString(rb.StreamID) {
+		http.Error(w, "invalid batch", http.StatusBadRequest)
+		return
+	}
+	if rb.Version != tstore.ReplicationBatchVersion {
+		http.Error(w, "unsupported batch version", http.StatusBadRequest)
+		return
+	}
+
+	err = saveBatch(rb, b)
+	switch {
+	case errors.Is(err, errBatchExists):
+		log.Printf("Batch %v %v rejected: batch exists", rb.StreamID,
+			rb.Sequence)
+		http.Error(w, "batch exists", http.StatusConflict)
+		return
+	case err != nil:
+		log.Printf("save batch %v %v: %v", rb.StreamID, rb.Sequence, err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Batch %v %v saved: %v bytes", rb.StreamID, rb.Sequence,
+		len(b))
+	w.WriteHeader(http.StatusOK)
+}
+
+// streamDir returns the directory of the stream whose batches are verified
+// or restored. The stream flag can be omitted when the dir only contains a
+// single stream.
+func streamDir() (string, error) {
+	if *stream != "" {
+		if !regexpStreamID.MatchString(*stream) {
+			return "", fmt.Errorf("invalid stream ID")
+		}
+		return filepath.Join(*dir, *stream), nil
+	}
+	files, err := ioutil.ReadDir(*dir)
+	if err != nil {
+		return "", err
+	}
+	streams := make([]string, 0, len(files))
+	for _, v := range files {
+		if v.IsDir() && regexpStreamID.MatchString(v.Name()) {
+			streams = append(streams, v.Name())
+		}
+	}
+	switch len(streams) {
+	case 0:
+		return "", fmt.Errorf("no streams found in %v", *dir)
+	case 1:
+		return filepath.Join(*dir, streams[0]), nil
+	}
+	return "", fmt.Errorf("multiple streams found, use the --stream flag "+
+		"to select one: %v", strings.Join(streams, ", "))
+}
+
+// walkBatches decrypts the batches of a stream in sequence order and passes
+// them to the provided function. An error is returned if a batch of the
+// stream is missing.
+func walkBatches(password string, f func(tstore.ReplicationBatch, *tstore.ReplicationPayload) error) (int, error) {
+	sd, err := streamDir()
+	if err != nil {
+		return 0, err
+	}
+	files, err := ioutil.ReadDir(sd)
+	if err != nil {
+		return 0, err
+	}
+	names := make([]string, 0, len(files))
+	for _, v := range files {
+		if strings.HasSuffix(v.Name(), ".json") {
+			names = append(names, v.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var prev uint64
+	for _, v := range names {
+		b, err := ioutil.ReadFile(filepath.Join(sd, v))
+		if err != nil {
+			return 0, err
+		}
+		var rb tstore.ReplicationBatch
+		err = json.Unmarshal(b, &rb)
+		if err != nil {
+			return 0, fmt.Errorf("%v: %v", v, err)
+		}
+		if rb.Sequence != prev+1 {
+			return 0, fmt.Errorf("batches %v to %v are missing", prev+1,
+				rb.Sequence-1)
+		}
+		prev = rb.Sequence
+
+		rp, err := tstore.DecryptReplicationBatch(rb, password)
+		if err != nil {
+			return 0, fmt.Errorf("batch %v: %v", rb.Sequence, err)
+		}
+		err = f(rb, rp)
+		if err != nil {
+			return 0, fmt.Errorf("batch %v: %v", rb.Sequence, err)
+		}
+	}
+
+	return len(names), nil
+}
+
+// verifyBatches decrypts the saved batches of a stream and prints a summary
+// of their contents. It verifies that the batches can be restored using the
+// replication password.
+func verifyBatches(password string) error {
+	var (
+		trees   = make(map[int64]struct{}, 256)
+		leaves  int
+		blobs   int
+		deleted int
+	)
+	n, err := walkBatches(password,
+		func(rb tstore.ReplicationBatch, rp *tstore.ReplicationPayload) error {
+			if rp.TreeID != 0 {
+				trees[rp.TreeID] = struct{}{}
+			}
+			leaves += len(rp.Leaves)
+			blobs += len(rp.Blobs)
+			deleted += len(rp.Deleted)
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%v batches, %v trees, %v leaves, %v blobs, %v deletions\n",
+		n, len(trees), leaves, blobs, deleted)
+
+	return nil
+}
+
+// restoreBatches restores the saved batches of a stream into the tstore
+// instance of the politeiad home dir. The tstore instance uses the embedded
+// tlog since trillian does not allow the tree IDs to be restored.
+func restoreBatches(password string) error {
+	anp := chaincfg.MainNetParams()
+	if *testnet {
+		anp = chaincfg.TestNet3Params()
+	}
+	appDir := util.CleanAndExpandPath(*homeDir)
+	dataDir := filepath.Join(appDir, sharedconfig.DefaultDataDirname,
+		anp.Name)
+	ts, err := tstore.NewInspector(appDir, dataDir, anp,
+		tstore.TlogTypeEmbedded, "", *tlogPass, *dbType, *dbHost, *dbPass)
+	if err != nil {
+		return err
+	}
+	defer ts.Close()
+
+	n, err := walkBatches(password,
+		func(rb tstore.ReplicationBatch, rp *tstore.ReplicationPayload) error {
+			return ts.ReplicationRestore(rp)
+		})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%v batches restored to %v\n", n, dataDir)
+
+	return nil
+}
+
+func _main() error {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() != 0 {
+		usage()
+		return fmt.Errorf("unexpected arguments")
+	}
+
+	*dir = util.CleanAndExpandPath(*dir)
+	if *verify || *restore {
+		password := os.Getenv(envReplicationPass)
+		if password == "" {
+			return fmt.Errorf("the replication password must be provided " +
+				"in the env variable " + envReplicationPass)
+		}
+		if *restore {
+			return restoreBatches(password)
+		}
+		return verifyBatches(password)
+	}
+
+	err := os.MkdirAll(*dir, 0700)
+	if err != nil {
+		return err
+	}
+
+	http.HandleFunc("/", handleBatch)
+	log.Printf("Saving batches to %v", *dir)
+	log.Printf("Listen: %v", *listen)
+	if *cert != "" {
+		return http.ListenAndServeTLS(*listen, *cert, *key, nil)
+	}
+	log.Printf("WARNING: serving plain http; use a tls terminating proxy " +
+		"or the --cert and --key flags when receiving over the internet")
+	return http.ListenAndServe(*listen, nil)
+}
+
+func main() {
+	err := _main()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,414 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/dcrec"
+	"github.com/decred/dcrd/dcrec/secp256k1/v3"
+	"github.com/decred/dcrd/dcrec/secp256k1/v3/ecdsa"
+	"github.com/decred/dcrd/dcrutil/v3"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	"github.com/decred/politeia/util"
+	"golang.org/x/crypto/ed25519"
+)
+
+const (
+	// The seeds are used to deterministically derive the keys that are
+	// used to create the test vectors. These keys are public and must
+	// never be used for anything other than test vectors.
+	seedServer = "politeia test vector server key"
+	seedUser   = "politeia test vector user key"
+	seedTicket = "politeia test vector ticket key"
+
+	// The token that is used for all test vectors.
+	vectorToken = "8ef6d4f6b5b6e7d4"
+
+	// The expiry that is used for the replay protected test vectors.
+	vectorExpiry int64 = 1700000000
+)
+
+var (
+	// CLI flags
+	outFile = flag.String("o", "", "Write the test vectors to this file "+
+		"instead of stdout")
+	testnet = flag.Bool("testnet", false, "Use testnet addresses for the "+
+		"cast vote test vectors")
+)
+
+// Keys contains the public keys that were used to create the test vectors.
+// The seeds are the hex encoded SHA256 digests of the seed strings that the
+// keys were derived from.
+type Keys struct {
+	ServerSeed      string `json:"serverseed"`
+	ServerPublicKey string `json:"serverpublickey"`
+	UserSeed        string `json:"userseed"`
+	UserPublicKey   string `json:"userpublickey"`
+	TicketSeed      string `json:"ticketseed"`
+	TicketAddress   string `json:"ticketaddress"`
+}
+
+// File is a record file test vector.
+type File struct {
+	Name    string `json:"name"`
+	MIME    string `json:"mime"`
+	Digest  string `json:"digest"`  // SHA256 digest of unencoded payload
+	Payload string `json:"payload"` // Base64 encoded
+}
+
+// Record is a record test vector. The merkle root is the merkle root of the
+// file digests. The user signature is the user signature of the merkle root.
+// The censorship record signature is the server signature of the
+// merkle root + token.
+type Record struct {
+	Files                     []File `json:"files"`
+	MerkleRoot                string `json:"merkleroot"`
+	Token                     string `json:"token"`
+	UserPublicKey             string `json:"userpublickey"`
+	UserSignature             string `json:"usersignature"`
+	CensorshipRecordMessage   string `json:"censorshiprecordmessage"`
+	CensorshipRecordSignature string `json:"censorshiprecordsignature"`
+}
+
+// Comment is a comment test vector. The signature is the user signature of
+// the message. The receipt is the server signature of the user signature. The
+// Nonce+Expiry are only appended to the message when a nonce is set.
+type Comment struct {
+	State     uint32 `json:"state"`
+	Token     string `json:"token"`
+	ParentID  uint32 `json:"parentid"`
+	Comment   string `json:"comment"`
+	Nonce     string `json:"nonce,omitempty"`
+	Expiry    int64  `json:"expiry,omitempty"`
+	Message   string `json:"message"` // State+Token+ParentID+Comment[+Nonce+Expiry]
+	Signature string `json:"signature"`
+	Receipt   string `json:"receipt"`
+}
+
+// CommentVote is a comment vote test vector. The signature is the user
+// signature of the message. The receipt is the server signature of the user
+// signature. The Nonce+Expiry are only appended to the message when a nonce is
+// set.
+type CommentVote struct {
+	State     uint32 `json:"state"`
+	Token     string `json:"token"`
+	CommentID uint32 `json:"commentid"`
+	Vote      int32  `json:"vote"`
+	Nonce     string `json:"nonce,omitempty"`
+	Expiry    int64  `json:"expiry,omitempty"`
+	Message   string `json:"message"` // State+Token+CommentID+Vote[+Nonce+Expiry]
+	Signature string `json:"signature"`
+	Receipt   string `json:"receipt"`
+}
+
+// CastVote is a cast vote test vector. The message is signed using the
+// decred signed message format with the ticket address key. The signature is
+// the hex encoded compact signature. The receipt is the server signature of
+// the hex encoded signature. The Nonce+Expiry are only appended to the message
+// when a nonce is set.
+type CastVote struct {
+	Token     string `json:"token"`
+	Ticket    string `json:"ticket"`
+	VoteBit   string `json:"votebit"`
+	Address   string `json:"address"`
+	Nonce     string `json:"nonce,omitempty"`
+	Expiry    int64  `json:"expiry,omitempty"`
+	Message   string `json:"message"` // Token+Ticket+VoteBit[+Nonce+Expiry]
+	Signature string `json:"signature"`
+	Receipt   string `json:"receipt"`
+}
+
+// Vectors contains all test vectors.
+type Vectors struct {
+	Keys         Keys          `json:"keys"`
+	Records      []Record      `json:"records"`
+	Comments     []Comment     `json:"comments"`
+	CommentVotes []CommentVote `json:"commentvotes"`
+	CastVotes    []CastVote    `json:"castvotes"`
+}
+
+// identityFromSeed returns the ed25519 identity that is derived from the
+// SHA256 digest of the provided seed string.
+func identityFromSeed(seed string) *identity.FullIdentity {
+	priv := ed25519.NewKeyFromSeed(util.Digest([]byte(seed)))
+	var fi identity.FullIdentity
+	copy(fi.PrivateKey[:], priv)
+	copy(fi.Public.Key[:], priv.Public().(ed25519.PublicKey))
+	return &fi
+}
+
+// nonceFromSeed returns the hex encoded 16 byte nonce that is derived from the
+// SHA256 digest of the provided seed string.
+func nonceFromSeed(seed string) string {
+	return hex.EncodeToString(util.Digest([]byte(seed))[:16])
+}
+
+// replayProtection returns the Nonce+Expiry that are appended to a signed
+// message. An empty string is returned if the nonce is not set.
+func replayProtection(nonce string, expiry int64) string {
+	if nonce == "" {
+		return ""
+	}
+	return nonce + strconv.FormatInt(expiry, 10)
+}
+
+// sign returns the hex encoded signature of the message.
+func sign(fi *identity.FullIdentity, msg string) string {
+	sig := fi.SignMessage([]byte(msg))
+	return hex.EncodeToString(sig[:])
+}
+
+func newRecord(server, user *identity.FullIdentity, files map[string]string) (*Record, error) {
+	fs := make([]File, 0, len(files))
+	digests := make([]string, 0, len(files))
+	for _, name := range []string{"index.md", "proposalmetadata.json"} {
+		payload, ok := files[name]
+		if !ok {
+			continue
+		}
+		d := hex.EncodeToString(util.Digest([]byte(payload)))
+		fs = append(fs, File{
+			Name:    name,
+			MIME:    "text/plain; charset=utf-8",
+			Digest:  d,
+			Payload: base64.StdEncoding.EncodeToString([]byte(payload)),
+		})
+		digests = append(digests, d)
+	}
+	mr, err := util.MerkleRoot(digests)
+	if err != nil {
+		return nil, err
+	}
+	merkle := hex.EncodeToString(mr[:])
+	crMsg := merkle + vectorToken
+
+	return &Record{
+		Files:                     fs,
+		MerkleRoot:                merkle,
+		Token:                     vectorToken,
+		UserPublicKey:             user.Public.String(),
+		UserSignature:             sign(user, merkle),
+		CensorshipRecordMessage:   crMsg,
+		CensorshipRecordSignature: sign(server, crMsg),
+	}, nil
+}
+
+func newComment(server, user *identity.FullIdentity, state, parentID uint32, comment, nonce string, expiry int64) Comment {
+	msg := strconv.FormatUint(uint64(state), 10) + vectorToken +
+		strconv.FormatUint(uint64(parentID), 10) + comment +
+		replayProtection(nonce, expiry)
+	sig := sign(user, msg)
+	return Comment{
+		State:     state,
+		Token:     vectorToken,
+		ParentID:  parentID,
+		Comment:   comment,
+		Nonce:     nonce,
+		Expiry:    expiry,
+		Message:   msg,
+		Signature: sig,
+		Receipt:   sign(server, sig),
+	}
+}
+
+func newCommentVote(server, user *identity.FullIdentity, state, commentID uint32, vote int32, nonce string, expiry int64) CommentVote {
+	msg := strconv.FormatUint(uint64(state), 10) + vectorToken +
+		strconv.FormatUint(uint64(commentID), 10) +
+		strconv.FormatInt(int64(vote), 10) +
+		replayProtection(nonce, expiry)
+	sig := sign(user, msg)
+	return CommentVote{
+		State:     state,
+		Token:     vectorToken,
+		CommentID: commentID,
+		Vote:      vote,
+		Nonce:     nonce,
+		Expiry:    expiry,
+		Message:   msg,
+		Signature: sig,
+		Receipt:   sign(server, sig),
+	}
+}
+
+func newCastVote(server *identity.FullIdentity, key *secp256k1.PrivateKey, address, ticket, voteBit, nonce string, expiry int64) (*CastVote, error) {
+	msg := vectorToken + ticket + voteBit + replayProtection(nonce, expiry)
+
+	// Sign the message using the decred signed message format
+	var buf bytes.Buffer
+	err := wire.WriteVarString(&buf, 0, "Decred Signed Message:\n")
+	if err != nil {
+		return nil, err
+	}
+	err = wire.WriteVarString(&buf, 0, msg)
+	if err != nil {
+		return nil, err
+	}
+	hash := chainhash.HashB(buf.Bytes())
+	sig := hex.EncodeToString(ecdsa.SignCompact(key, hash, true))
+
+	// Sanity check the signature using the same code path that is
+	// used to verify cast votes.
+	b, _ := hex.DecodeString(sig)
+	net := chaincfg.MainNetParams()
+	if *testnet {
+		net = chaincfg.TestNet3Params()
+	}
+	ok, err := util.VerifyMessage(address, msg,
+		base64.StdEncoding.EncodeToString(b), net)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("cast vote signature did not verify")
+	}
+
+	return &CastVote{
+		Token:     vectorToken,
+		Ticket:    ticket,
+		VoteBit:   voteBit,
+		Address:   address,
+		Nonce:     nonce,
+		Expiry:    expiry,
+		Message:   msg,
+		Signature: sig,
+		Receipt:   sign(server, sig),
+	}, nil
+}
+
+func _main() error {
+	flag.Parse()
+
+	var (
+		server = identityFromSeed(seedServer)
+		user   = identityFromSeed(seedUser)
+	)
+
+	// Derive the ticket key and address
+	ticketKey := secp256k1.PrivKeyFromBytes(util.Digest([]byte(seedTicket)))
+	net := chaincfg.MainNetParams()
+	if *testnet {
+		net = chaincfg.TestNet3Params()
+	}
+	pkh := dcrutil.Hash160(ticketKey.PubKey().SerializeCompressed())
+	addr, err := dcrutil.NewAddressPubKeyHash(pkh, net,
+		dcrec.STEcdsaSecp256k1)
+	if err != nil {
+		return err
+	}
+
+	v := Vectors{
+		Keys: Keys{
+			ServerSeed:      hex.EncodeToString(util.Digest([]byte(seedServer))),
+			ServerPublicKey: server.Public.String(),
+			UserSeed:        hex.EncodeToString(util.Digest([]byte(seedUser))),
+			UserPublicKey:   user.Public.String(),
+			TicketSeed:      hex.EncodeToString(util.Digest([]byte(seedTicket))),
+			TicketAddress:   addr.Address(),
+		},
+	}
+
+	// Records
+	recordFiles := []map[string]string{
+		{
+			"index.md": "# Test vector proposal\n\nThis is a test.\n",
+		},
+		{
+			"index.md":              "# Test vector proposal\n\nThis is a test.\n",
+			"proposalmetadata.json": `{"name":"Test vector proposal"}`,
+		},
+	}
+	for _, files := range recordFiles {
+		r, err := newRecord(server, user, files)
+		if err != nil {
+			return err
+		}
+		v.Records = append(v.Records, *r)
+	}
+
+	// Comments and comment votes. The last vectors of each type are
+	// replay protected.
+	v.Comments = []Comment{
+		newComment(server, user, 2, 0, "This is a comment.", "", 0),
+		newComment(server, user, 2, 1, "This is a reply with unicode: ✓",
+			"", 0),
+		newComment(server, user, 1, 0, "This is an unvetted comment.",
+			"", 0),
+		newComment(server, user, 2, 0, "This is a replay protected comment.",
+			nonceFromSeed("comment nonce"), vectorExpiry),
+	}
+	v.CommentVotes = []CommentVote{
+		newCommentVote(server, user, 2, 1, 1, "", 0),
+		newCommentVote(server, user, 2, 2, -1, "", 0),
+		newCommentVote(server, user, 2, 1, -1,
+			nonceFromSeed("comment vote nonce"), vectorExpiry),
+	}
+
+	// Cast votes
+	tickets := []struct {
+		ticket  string
+		voteBit string
+		nonce   string
+		expiry  int64
+	}{
+		{
+			hex.EncodeToString(util.Digest([]byte("ticket 1"))),
+			"1",
+			"",
+			0,
+		},
+		{
+			hex.EncodeToString(util.Digest([]byte("ticket 2"))),
+			"2",
+			"",
+			0,
+		},
+		{
+			hex.EncodeToString(util.Digest([]byte("ticket 3"))),
+			"1",
+			nonceFromSeed("cast vote nonce"),
+			vectorExpiry,
+		},
+	}
+	for _, t := range tickets {
+		cv, err := newCastVote(server, ticketKey, addr.Address(),
+			t.ticket, t.voteBit, t.nonce, t.expiry)
+		if err != nil {
+			return err
+		}
+		v.CastVotes = append(v.CastVotes, *cv)
+	}
+
+	// Output vectors
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	if *outFile == "" {
+		_, err = os.Stdout.Write(b)
+		return err
+	}
+	return ioutil.WriteFile(util.CleanAndExpandPath(*outFile), b, 0644)
+}
+
+func main() {
+	err := _main()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
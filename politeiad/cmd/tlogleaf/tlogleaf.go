@@ -0,0 +1,149 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	backend "github.com/decred/politeia/politeiad/backendv2"
+	"github.com/decred/politeia/politeiad/backendv2/tstorebe/tstore"
+	"github.com/decred/politeia/politeiad/sharedconfig"
+	"github.com/decred/politeia/util"
+)
+
+var (
+	defaultHomeDir = sharedconfig.DefaultHomeDir
+
+	// CLI flags
+	homeDir  = flag.String("homedir", defaultHomeDir, "politeiad home dir path")
+	testnet  = flag.Bool("testnet", false, "Use testnet data")
+	dbType   = flag.String("dbtype", tstore.DBTypeLevelDB, "Database type")
+	dbHost   = flag.String("dbhost", "localhost:3306", "Database ip:port")
+	dbPass   = flag.String("dbpass", "", "Database password")
+	tlogType = flag.String("tlogtype", tstore.TlogTypeTrillian, "Tlog "+
+		"implementation (trillian or embedded)")
+	tlogHost = flag.String("tloghost", "localhost:8090", "Trillian log ip:port")
+	tlogPass = flag.String("tlogpass", "", "Trillian log signing key password")
+	replay   = flag.Bool("replay", false, "Re-derive the leaf blob entry and "+
+		"verify it against the stored blob entry and leaf value")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: tlogleaf [flags] <token> <merkleleafhash>\n")
+	fmt.Fprintf(os.Stderr, " flags:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\n")
+}
+
+// printLeaf prints the leaf details to stdout.
+func printLeaf(ld tstore.LeafDetails) {
+	fmt.Printf("Tree ID         : %v\n", ld.TreeID)
+	fmt.Printf("Leaf index      : %v\n", ld.LeafIndex)
+	fmt.Printf("Merkle leaf hash: %v\n", ld.MerkleLeafHash)
+	fmt.Printf("Leaf value      : %v\n", ld.LeafValue)
+	fmt.Printf("Store key       : %v\n", ld.Key)
+	fmt.Printf("Descriptor      : %v\n", ld.Descriptor)
+	fmt.Printf("State           : %v\n", backend.States[ld.State])
+
+	if ld.BlobEntry == nil {
+		fmt.Printf("Blob entry      : not found\n")
+	} else {
+		fmt.Printf("Blob entry\n")
+		fmt.Printf("  Digest        : %v\n", ld.BlobEntry.Digest)
+		if ld.DataHint != nil {
+			fmt.Printf("  Data type     : %v\n", ld.DataHint.Type)
+			fmt.Printf("  Descriptor    : %v\n", ld.DataHint.Descriptor)
+			if ld.DataHint.ExtraData != "" {
+				fmt.Printf("  Extra data    : %v\n", ld.DataHint.ExtraData)
+			}
+		}
+		fmt.Printf("  Data digest   : %v\n", ld.DataDigest)
+		fmt.Printf("  Data size     : %v\n", len(ld.Data))
+		if json.Valid(ld.Data) {
+			fmt.Printf("  Data          : %s\n", ld.Data)
+		}
+	}
+
+	ts := ld.Timestamp
+	if ts == nil || len(ts.Proofs) == 0 {
+		fmt.Printf("Digest chain    : not anchored\n")
+		return
+	}
+	fmt.Printf("Digest chain\n")
+	for _, p := range ts.Proofs {
+		fmt.Printf("  %v\n", p.Type)
+		fmt.Printf("    Digest      : %v\n", p.Digest)
+		for _, v := range p.MerklePath {
+			fmt.Printf("    Merkle path : %v\n", v)
+		}
+		fmt.Printf("    Merkle root : %v\n", p.MerkleRoot)
+	}
+	fmt.Printf("Anchor tx       : %v\n", ts.TxID)
+	fmt.Printf("Anchor root     : %v\n", ts.MerkleRoot)
+}
+
+func _main() error {
+	flag.Usage = usage
+	flag.Parse()
+	if len(flag.Args()) != 2 {
+		usage()
+		return fmt.Errorf("must provide a token and a merkle leaf hash")
+	}
+	token, err := util.TokenDecode(util.TokenTypeTstore, flag.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid token: %v", err)
+	}
+	merkleLeafHash, err := hex.DecodeString(flag.Arg(1))
+	if err != nil {
+		return fmt.Errorf("invalid merkle leaf hash: %v", err)
+	}
+
+	// Setup tstore
+	anp := chaincfg.MainNetParams()
+	if *testnet {
+		anp = chaincfg.TestNet3Params()
+	}
+	appDir := util.CleanAndExpandPath(*homeDir)
+	dataDir := filepath.Join(appDir, sharedconfig.DefaultDataDirname,
+		anp.Name)
+	ts, err := tstore.NewInspector(appDir, dataDir, anp, *tlogType,
+		*tlogHost, *tlogPass, *dbType, *dbHost, *dbPass)
+	if err != nil {
+		return err
+	}
+	defer ts.Close()
+
+	// Inspect leaf. The leaf details are still printed and replayed
+	// when the digest chain can't be verified.
+	ld, err := ts.Leaf(token, merkleLeafHash)
+	if ld == nil {
+		return err
+	}
+	printLeaf(*ld)
+	if *replay {
+		rerr := tstore.LeafReplay(*ld)
+		if rerr != nil {
+			fmt.Printf("Replay          : %v\n", rerr)
+		} else {
+			fmt.Printf("Replay          : ok\n")
+		}
+	}
+
+	return err
+}
+
+func main() {
+	err := _main()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/decred/politeia/politeiad/backendv2/tstorebe/benchmark"
+)
+
+var (
+	// CLI flags
+	run       = flag.String("run", "", "Only run the benchmarks that match this regular expression")
+	benchTime = flag.String("benchtime", "1s", "Run each benchmark for this duration or, using the Nx format, this number of iterations")
+	outFile   = flag.String("o", "", "Write the JSON report to this file")
+	baseline  = flag.String("baseline", "", "Compare the results against this JSON report and fail on regressions")
+	threshold = flag.Float64("threshold", 0.1, "Relative slowdown that is allowed before a benchmark is considered a regression")
+
+	errRegression = errors.New("performance regression")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: tstorebench [flags]\n")
+	fmt.Fprintf(os.Stderr, " flags:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\n")
+}
+
+// loadReport loads a JSON report from disk.
+func loadReport(fp string) (*benchmark.Report, error) {
+	b, err := ioutil.ReadFile(fp)
+	if err != nil {
+		return nil, err
+	}
+	var r benchmark.Report
+	err = json.Unmarshal(b, &r)
+	if err != nil {
+		return nil, fmt.Errorf("decode %v: %v", fp, err)
+	}
+	return &r, nil
+}
+
+// printReport prints the benchmark results to stdout.
+func printReport(r benchmark.Report) {
+	fmt.Printf("%v %v/%v %v CPUs\n", r.GoVersion, r.GOOS, r.GOARCH, r.CPUs)
+	for _, v := range r.Results {
+		fmt.Printf("%-14v %8v %12v ns/op %10.1f ops/s %8.2f MB/s "+
+			"%8v B/op %6v allocs/op\n", v.Name, v.N, v.NsPerOp,
+			v.OpsPerSec, v.MBPerSec, v.BytesPerOp, v.AllocsPerOp)
+	}
+}
+
+func _main() error {
+	// The testing flags must be registered in order to set the
+	// benchmark time.
+	testing.Init()
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() != 0 {
+		usage()
+		return fmt.Errorf("unexpected arguments")
+	}
+	err := flag.Set("test.benchtime", *benchTime)
+	if err != nil {
+		return fmt.Errorf("invalid benchtime: %v", err)
+	}
+
+	// Load the baseline before running the benchmarks so that an
+	// invalid baseline is reported right away.
+	var base *benchmark.Report
+	if *baseline != "" {
+		base, err = loadReport(*baseline)
+		if err != nil {
+			return err
+		}
+	}
+
+	r, err := benchmark.Run(*run)
+	if err != nil {
+		return err
+	}
+	printReport(*r)
+
+	if *outFile != "" {
+		b, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(*outFile, b, 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	if base == nil {
+		return nil
+	}
+	regressions := benchmark.Compare(*base, *r, *threshold)
+	for _, v := range regressions {
+		fmt.Printf("Regression %v: %v ns/op -> %v ns/op (%+.1f%%)\n",
+			v.Name, v.Base, v.Current, v.Change*100)
+	}
+	if len(regressions) > 0 {
+		return errRegression
+	}
+	fmt.Printf("No regressions found\n")
+
+	return nil
+}
+
+func main() {
+	err := _main()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
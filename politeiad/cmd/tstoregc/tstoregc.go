@@ -0,0 +1,98 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/politeia/politeiad/backendv2/tstorebe/tstore"
+	"github.com/decred/politeia/politeiad/sharedconfig"
+	"github.com/decred/politeia/util"
+)
+
+var (
+	defaultHomeDir = sharedconfig.DefaultHomeDir
+
+	// CLI flags
+	homeDir  = flag.String("homedir", defaultHomeDir, "politeiad home dir path")
+	testnet  = flag.Bool("testnet", false, "Use testnet data")
+	dbType   = flag.String("dbtype", tstore.DBTypeLevelDB, "Database type")
+	dbHost   = flag.String("dbhost", "localhost:3306", "Database ip:port")
+	dbPass   = flag.String("dbpass", "", "Database password")
+	tlogType = flag.String("tlogtype", tstore.TlogTypeTrillian, "Tlog "+
+		"implementation (trillian or embedded)")
+	tlogHost = flag.String("tloghost", "localhost:8090", "Trillian log ip:port")
+	tlogPass = flag.String("tlogpass", "", "Trillian log signing key password")
+	del      = flag.Bool("delete", false, "Delete the garbage blobs that "+
+		"are past the safety window")
+	window = flag.Duration("window", tstore.GCSafetyWindow, "Amount of "+
+		"time a blob must be garbage before it is deleted")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: tstoregc [flags]\n")
+	fmt.Fprintf(os.Stderr, " flags:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\n")
+}
+
+// printReport prints the garbage collection results to stdout.
+func printReport(r tstore.GCReport) {
+	for _, v := range r.Orphans {
+		fmt.Printf("Orphaned blob       : %v\n", v)
+	}
+	for _, v := range r.Leftovers {
+		fmt.Printf("Censored record blob: %v\n", v)
+	}
+	for _, v := range r.Deleted {
+		fmt.Printf("Deleted blob        : %v\n", v)
+	}
+	fmt.Printf("%v orphaned blobs, %v censored record blobs, %v deleted\n",
+		len(r.Orphans), len(r.Leftovers), len(r.Deleted))
+}
+
+func _main() error {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() != 0 {
+		usage()
+		return fmt.Errorf("unexpected arguments")
+	}
+
+	// Setup tstore
+	anp := chaincfg.MainNetParams()
+	if *testnet {
+		anp = chaincfg.TestNet3Params()
+	}
+	appDir := util.CleanAndExpandPath(*homeDir)
+	dataDir := filepath.Join(appDir, sharedconfig.DefaultDataDirname,
+		anp.Name)
+	ts, err := tstore.NewInspector(appDir, dataDir, anp, *tlogType,
+		*tlogHost, *tlogPass, *dbType, *dbHost, *dbPass)
+	if err != nil {
+		return err
+	}
+	defer ts.Close()
+
+	r, err := ts.GarbageCollect(*window, *del)
+	if err != nil {
+		return err
+	}
+	printReport(*r)
+
+	return nil
+}
+
+func main() {
+	err := _main()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
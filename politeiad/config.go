@@ -17,8 +17,10 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	v1 "github.com/decred/dcrtime/api/v1"
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
 	"github.com/decred/politeia/politeiad/backendv2/tstorebe/tstore"
 	"github.com/decred/politeia/politeiad/sharedconfig"
 	"github.com/decred/politeia/util"
@@ -48,11 +50,20 @@ const (
 	// Tstore default settings
 	defaultDBType   = tstore.DBTypeLevelDB
 	defaultDBHost   = "localhost:3306" // MySQL default host
+	defaultTlogType = tstore.TlogTypeTrillian
 	defaultTlogHost = "localhost:8090"
 
+	// Token default settings
+	defaultTokenPrefixLength = pdv2.ShortTokenLength
+	defaultTokenCollision    = tstore.TokenCollisionRetry
+
+	// Resource accounting default settings
+	defaultSlowRequest = 1000 // In milliseconds
+
 	// Environment variables
-	envDBPass   = "DBPASS"
-	envTlogPass = "TLOGPASS"
+	envDBPass          = "DBPASS"
+	envTlogPass        = "TLOGPASS"
+	envReplicationPass = "REPLICATIONPASS"
 )
 
 var (
@@ -95,6 +106,17 @@ type config struct {
 	Identity    string `long:"identity" description:"File containing the politeiad identity file"`
 	Backend     string `long:"backend" description:"Backend type"`
 
+	// Identity rotation options
+	PrevIdentity string `long:"previdentity" description:"File containing the previous politeiad identity during an identity rotation"`
+	RotationEnd  string `long:"rotationend" description:"RFC 3339 time at which the identity rotation ends and the previous identity is no longer advertised"`
+
+	// Tracing options
+	TracingEndpoint string `long:"tracingendpoint" description:"OTLP/HTTP endpoint of an OpenTelemetry collector that request traces are exported to"`
+
+	// Resource accounting options
+	SlowRequest uint32 `long:"slowrequest" description:"Number of milliseconds after which a request is logged as a slow request; slow requests are not logged when set to 0"`
+	Metrics     bool   `long:"metrics" description:"Serve the request resource usage metrics on /metrics using the Prometheus text format"`
+
 	// Git backend options
 	GitTrace    bool   `long:"gittrace" description:"Enable git tracing in logs"`
 	DcrdataHost string `long:"dcrdatahost" description:"Dcrdata ip:port"`
@@ -103,9 +125,18 @@ type config struct {
 	DBType   string `long:"dbtype" description:"Database type"`
 	DBHost   string `long:"dbhost" description:"Database ip:port"`
 	DBPass   string // Provided in env variable "DBPASS"
+	TlogType string `long:"tlogtype" description:"Tlog implementation (trillian or embedded)"`
 	TlogHost string `long:"tloghost" description:"Trillian log ip:port"`
 	TlogPass string // Provided in env variable "TLOGPASS"
 
+	// Replication options
+	ReplicationURL  string `long:"replicationurl" description:"URL of the off-site receiver that the new tlog leaves and blobs are streamed to; replication is disabled when not set"`
+	ReplicationPass string // Provided in env variable "REPLICATIONPASS"
+
+	// Token options
+	TokenPrefixLength int    `long:"tokenprefixlength" description:"Length of the short token prefix of a record token"`
+	TokenCollision    string `long:"tokencollision" description:"Short token collision policy (retry or reject)"`
+
 	// Plugin options
 	Plugins        []string `long:"plugin" description:"Plugins"`
 	PluginSettings []string `long:"pluginsetting" description:"Plugin settings"`
@@ -260,7 +291,12 @@ func loadConfig() (*config, []string, error) {
 		Backend:    defaultBackend,
 		DBType:     defaultDBType,
 		DBHost:     defaultDBHost,
+		TlogType:   defaultTlogType,
 		TlogHost:   defaultTlogHost,
+
+		TokenPrefixLength: defaultTokenPrefixLength,
+		TokenCollision:    defaultTokenCollision,
+		SlowRequest:       defaultSlowRequest,
 	}
 
 	// Service options which are only added on Windows.
@@ -519,6 +555,20 @@ func loadConfig() (*config, []string, error) {
 	}
 	cfg.Identity = util.CleanAndExpandPath(cfg.Identity)
 
+	// Verify the identity rotation options
+	switch {
+	case cfg.PrevIdentity == "" && cfg.RotationEnd != "":
+		return nil, nil, fmt.Errorf("rotationend requires previdentity")
+	case cfg.PrevIdentity != "" && cfg.RotationEnd == "":
+		return nil, nil, fmt.Errorf("previdentity requires rotationend")
+	case cfg.PrevIdentity != "":
+		_, err := time.Parse(time.RFC3339, cfg.RotationEnd)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid rotationend: %v", err)
+		}
+		cfg.PrevIdentity = util.CleanAndExpandPath(cfg.PrevIdentity)
+	}
+
 	// Set random username and password when not specified
 	if cfg.RPCUser == "" {
 		name, err := util.Random(32)
@@ -537,6 +587,16 @@ func loadConfig() (*config, []string, error) {
 		log.Warnf("RPC password not set, using random value")
 	}
 
+	// Verify the tracing endpoint
+	if cfg.TracingEndpoint != "" {
+		u, err := url.Parse(cfg.TracingEndpoint)
+		if err != nil || u.Host == "" ||
+			(u.Scheme != "http" && u.Scheme != "https") {
+			return nil, nil, fmt.Errorf("invalid tracingendpoint: %v",
+				cfg.TracingEndpoint)
+		}
+	}
+
 	// Verify backend specific settings
 	switch cfg.Backend {
 	case backendGit:
@@ -578,9 +638,16 @@ func verifyTstoreSettings(cfg *config) error {
 	}
 
 	// Verify tlog options
-	_, err := url.Parse(cfg.TlogHost)
-	if err != nil {
-		return fmt.Errorf("invalid tlog host '%v': %v", cfg.TlogHost, err)
+	switch cfg.TlogType {
+	case tstore.TlogTypeTrillian:
+		_, err := url.Parse(cfg.TlogHost)
+		if err != nil {
+			return fmt.Errorf("invalid tlog host '%v': %v", cfg.TlogHost, err)
+		}
+	case tstore.TlogTypeEmbedded:
+		// Allowed; continue
+	default:
+		return fmt.Errorf("invalid tlog type '%v'", cfg.TlogType)
 	}
 	cfg.TlogPass = os.Getenv(envTlogPass)
 	if cfg.TlogPass == "" {
@@ -589,5 +656,35 @@ func verifyTstoreSettings(cfg *config) error {
 			"the env variable %v", envTlogPass)
 	}
 
+	// Verify replication options. The replicated data is encrypted
+	// using a key that is derived from the replication password.
+	if cfg.ReplicationURL != "" {
+		u, err := url.Parse(cfg.ReplicationURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+			return fmt.Errorf("invalid replication url")
+		}
+		cfg.ReplicationPass = os.Getenv(envReplicationPass)
+		if cfg.ReplicationPass == "" {
+			return fmt.Errorf("replicationpass not found: a replication "+
+				"password that is used to derive the replication encryption "+
+				"key must be provided in the env variable %v",
+				envReplicationPass)
+		}
+	}
+
+	// Verify token options. The short token length is set globally
+	// since it's used by both the tstore and the plugins.
+	err := util.SetShortTokenLength(cfg.TokenPrefixLength)
+	if err != nil {
+		return fmt.Errorf("invalid token prefix length: %v", err)
+	}
+	switch cfg.TokenCollision {
+	case tstore.TokenCollisionRetry, tstore.TokenCollisionReject:
+		// Allowed; continue
+	default:
+		return fmt.Errorf("invalid token collision policy '%v'",
+			cfg.TokenCollision)
+	}
+
 	return nil
 }
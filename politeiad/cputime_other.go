@@ -0,0 +1,16 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+//
+// +build windows plan9 js
+
+package main
+
+import "time"
+
+// processCPUTime returns the CPU time that has been used by the politeiad
+// process. Process CPU accounting is not supported on this platform, so false
+// is always returned.
+func processCPUTime() (time.Duration, bool) {
+	return 0, false
+}
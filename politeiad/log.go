@@ -18,6 +18,7 @@ import (
 	"github.com/decred/politeia/politeiad/backendv2/tstorebe/store/localdb"
 	"github.com/decred/politeia/politeiad/backendv2/tstorebe/store/mysql"
 	"github.com/decred/politeia/politeiad/backendv2/tstorebe/tstore"
+	"github.com/decred/politeia/util/tracing"
 	"github.com/decred/politeia/wsdcrdata"
 	"github.com/decred/slog"
 	"github.com/jrick/logrotate/rotator"
@@ -78,6 +79,7 @@ func init() {
 
 	// Other loggers
 	wsdcrdata.UseLogger(wsdcrdataLog)
+	tracing.UseLogger(log)
 }
 
 // subsystemLoggers maps each subsystem identifier to its associated logger.
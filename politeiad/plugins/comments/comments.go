@@ -34,6 +34,10 @@ const (
 	// SettingKeyVoteChangesMax is the plugin setting key for the
 	// SettingVoteChangesMax plugin setting.
 	SettingKeyVoteChangesMax = "votechangesmax"
+
+	// SettingKeyCommentDepthMax is the plugin setting key for the
+	// SettingCommentDepthMax plugin setting.
+	SettingKeyCommentDepthMax = "commentdepthmax"
 )
 
 // Plugin setting default values. These can be overridden by providing a plugin
@@ -47,6 +51,13 @@ const (
 	// user can change their vote on a comment. This prevents a
 	// malicious user from being able to spam comment votes.
 	SettingVoteChangesMax uint32 = 5
+
+	// SettingCommentDepthMax is the default maximum nesting depth of
+	// a comment. A base level comment has a depth of 1 and a reply
+	// has a depth of one more than its parent. Replies that would
+	// exceed the maximum depth are rejected. The nesting depth is not
+	// limited when this is set to 0.
+	SettingCommentDepthMax uint32 = 0
 )
 
 // ErrorCodeT represents a error that was caused by the user.
@@ -99,8 +110,12 @@ const (
 	// does not match the record state.
 	ErrorCodeRecordStateInvalid ErrorCodeT = 11
 
+	// ErrorCodeDepthMaxExceeded is returned when a reply would exceed
+	// the max comment depth plugin setting.
+	ErrorCodeDepthMaxExceeded ErrorCodeT = 12
+
 	// ErrorCodeLast unit test only.
-	ErrorCodeLast ErrorCodeT = 12
+	ErrorCodeLast ErrorCodeT = 13
 )
 
 var (
@@ -118,6 +133,7 @@ var (
 		ErrorCodeVoteInvalid:            "vote invalid",
 		ErrorCodeVoteChangesMaxExceeded: "vote changes max exceeded",
 		ErrorCodeRecordStateInvalid:     "record state invalid",
+		ErrorCodeDepthMaxExceeded:       "max depth exceeded",
 	}
 )
 
@@ -135,6 +151,15 @@ const (
 	RecordStateVetted RecordStateT = 2
 )
 
+// ReplayProtection protects a signed request from being replayed. The fields
+// are optional. When a nonce is provided the Nonce+Expiry are appended to the
+// message that is signed by the client. The nonce is verified by politeiawww
+// and is saved along with the signature so that the signature can be verified.
+type ReplayProtection struct {
+	Nonce  string `json:"nonce,omitempty"`  // Hex encoded random nonce
+	Expiry int64  `json:"expiry,omitempty"` // UNIX timestamp
+}
+
 // Comment represent a record comment.
 //
 // A parent ID of 0 indicates that the comment is a base level comment and not
@@ -170,6 +195,8 @@ type Comment struct {
 	Deleted bool   `json:"deleted,omitempty"` // Comment has been deleted
 	Reason  string `json:"reason,omitempty"`  // Reason for deletion
 
+	ReplayProtection
+
 	// Optional fields to be used freely
 	ExtraData     string `json:"extradata,omitempty"`
 	ExtraDataHint string `json:"extradatahint,omitempty"`
@@ -198,6 +225,8 @@ type CommentAdd struct {
 	// Optional fields to be used freely
 	ExtraData     string `json:"extradata,omitempty"`
 	ExtraDataHint string `json:"extradatahint,omitempty"`
+
+	ReplayProtection
 }
 
 // CommentDel is the structure that is saved to disk when a comment is deleted.
@@ -254,6 +283,8 @@ type CommentVote struct {
 	// Metadata generated by server
 	Timestamp int64  `json:"timestamp"` // Received UNIX timestamp
 	Receipt   string `json:"receipt"`   // Server signature of client signature
+
+	ReplayProtection
 }
 
 // New creates a new comment.
@@ -274,6 +305,8 @@ type New struct {
 	// Optional fields to be used freely
 	ExtraData     string `json:"extradata,omitempty"`
 	ExtraDataHint string `json:"extradatahint,omitempty"`
+
+	ReplayProtection
 }
 
 // NewReply is the reply to the New command.
@@ -338,6 +371,8 @@ type Vote struct {
 	Vote      VoteT        `json:"vote"`      // Upvote or downvote
 	PublicKey string       `json:"publickey"` // Public key used for signature
 	Signature string       `json:"signature"` // Client signature
+
+	ReplayProtection
 }
 
 // VoteReply is the reply to the Vote command.
@@ -27,6 +27,27 @@ const (
 	// SettingKeyHostWS is the plugin setting key for the plugin
 	// setting SettingHostWS.
 	SettingKeyHostWS = "hostws"
+
+	// SettingKeyHostHTTPFallback is the plugin setting key for the
+	// plugin setting SettingHostHTTPFallback.
+	SettingKeyHostHTTPFallback = "hosthttpfallback"
+
+	// SettingKeyDcrdRPCHost is the plugin setting key for the plugin
+	// setting SettingDcrdRPCHost.
+	SettingKeyDcrdRPCHost = "dcrdrpchost"
+
+	// SettingKeyDcrdRPCUser is the plugin setting key for the dcrd RPC
+	// username.
+	SettingKeyDcrdRPCUser = "dcrdrpcuser"
+
+	// SettingKeyDcrdRPCPass is the plugin setting key for the dcrd RPC
+	// password. The password is not returned in the plugin settings.
+	SettingKeyDcrdRPCPass = "dcrdrpcpass"
+
+	// SettingKeyDcrdRPCCert is the plugin setting key for the path of
+	// the dcrd RPC TLS certificate. The system certificates are used
+	// when no certificate is provided.
+	SettingKeyDcrdRPCCert = "dcrdrpccert"
 )
 
 // Plugin setting default values. These can be overridden by providing a plugin
@@ -45,6 +66,17 @@ const (
 	// SettingHostWSTestNet is the default dcrdata testnet websocket
 	// host.
 	SettingHostWSTestNet = "wss://testnet.decred.org/ps"
+
+	// SettingHostHTTPFallback is the default list of dcrdata http hosts
+	// that the best block is requested from when it can't be retrieved
+	// from the dcrdata websocket or the dcrdata http host. The hosts
+	// are tried in order. The setting must be a JSON encoded []string.
+	SettingHostHTTPFallback = "[]"
+
+	// SettingDcrdRPCHost is the default dcrd RPC host, e.g.
+	// 127.0.0.1:9109. The dcrd RPC host is the last source that the
+	// best block is requested from. It is not used by default.
+	SettingDcrdRPCHost = ""
 )
 
 // StatusT represents a dcrdata connection status. Some commands will returned
@@ -64,10 +96,12 @@ const (
 	StatusDisconnected StatusT = 2
 )
 
-// BestBlock requests best block data. If dcrdata cannot be reached then the
-// data from the most recent cached best block will be returned along with a
-// status of StatusDisconnected. It is the callers responsibility to determine
-// if the stale best block height should be used.
+// BestBlock requests best block data. The best block is requested from the
+// dcrdata websocket, the dcrdata http host, the fallback dcrdata http hosts,
+// and the dcrd RPC host, in that order, until one of them succeeds. If none of
+// them can be reached then the most recent best block will be returned along
+// with a status of StatusDisconnected. It is the callers responsibility to
+// determine if the stale best block height should be used.
 type BestBlock struct{}
 
 // BestBlockReply is the reply to the BestBlock command.
@@ -0,0 +1,7 @@
+module github.com/decred/politeia/politeiad/plugins
+
+go 1.15
+
+require github.com/decred/politeia/unittest v1.0.0
+
+replace github.com/decred/politeia/unittest => ../../unittest
@@ -9,6 +9,10 @@ package pi
 const (
 	// PluginID is the unique identifier for this plugin.
 	PluginID = "pi"
+
+	// Plugin commands
+	CmdSetAuthorUpdate = "setauthorupdate" // Set the author update
+	CmdAuthorUpdates   = "authorupdates"   // Get all author updates
 )
 
 // Plugin setting keys can be used to specify custom plugin settings. Default
@@ -38,6 +42,10 @@ const (
 	// SettingKeyProposalNameSupportedChars is the plugin setting key
 	// for the SettingProposalNameSupportedChars plugin setting.
 	SettingKeyProposalNameSupportedChars = "proposalnamesupportedchars"
+
+	// SettingKeyAuthorUpdateLengthMax is the plugin setting key for
+	// the SettingAuthorUpdateLengthMax plugin setting.
+	SettingKeyAuthorUpdateLengthMax = "authorupdatelengthmax"
 )
 
 // Plugin setting default values. These can be overridden by providing a plugin
@@ -62,6 +70,10 @@ const (
 	// SettingProposalNameLengthMax is the default maximum number of
 	// characters that a proposal name can be.
 	SettingProposalNameLengthMax uint32 = 80
+
+	// SettingAuthorUpdateLengthMax is the default maximum number of
+	// characters that an author update can be.
+	SettingAuthorUpdateLengthMax uint32 = 8000
 )
 
 var (
@@ -108,21 +120,55 @@ const (
 	// status does not allow changes to be made to the proposal.
 	ErrorCodeVoteStatusInvalid ErrorCodeT = 7
 
+	// ErrorCodeTokenInvalid is returned when a token is invalid.
+	ErrorCodeTokenInvalid ErrorCodeT = 8
+
+	// ErrorCodePublicKeyInvalid is returned when a public key is
+	// invalid.
+	ErrorCodePublicKeyInvalid ErrorCodeT = 9
+
+	// ErrorCodeSignatureInvalid is returned when a signature is
+	// invalid.
+	ErrorCodeSignatureInvalid ErrorCodeT = 10
+
+	// ErrorCodeAuthorUpdateLengthInvalid is returned when an author
+	// update is empty or exceedes the AuthorUpdateLengthMax setting.
+	ErrorCodeAuthorUpdateLengthInvalid ErrorCodeT = 11
+
+	// ErrorCodeRecordStateInvalid is returned when an author update is
+	// set on a record that is not vetted.
+	ErrorCodeRecordStateInvalid ErrorCodeT = 12
+
+	// ErrorCodeUserUnauthorized is returned when the user setting the
+	// author update is not the record author.
+	ErrorCodeUserUnauthorized ErrorCodeT = 13
+
+	// ErrorCodeResubmitsInvalid is returned when the proposal that a
+	// proposal is a resubmission of is invalid.
+	ErrorCodeResubmitsInvalid ErrorCodeT = 14
+
 	// ErrorCodeLast unit test only.
-	ErrorCodeLast ErrorCodeT = 8
+	ErrorCodeLast ErrorCodeT = 15
 )
 
 var (
 	// ErrorCodes contains the human readable errors.
 	ErrorCodes = map[ErrorCodeT]string{
-		ErrorCodeInvalid:               "error code invalid",
-		ErrorCodeTextFileNameInvalid:   "text file name invalid",
-		ErrorCodeTextFileSizeInvalid:   "text file size invalid",
-		ErrorCodeTextFileMissing:       "text file is misisng",
-		ErrorCodeImageFileCountInvalid: "image file count invalid",
-		ErrorCodeImageFileSizeInvalid:  "image file size invalid",
-		ErrorCodeProposalNameInvalid:   "proposal name invalid",
-		ErrorCodeVoteStatusInvalid:     "vote status invalid",
+		ErrorCodeInvalid:                   "error code invalid",
+		ErrorCodeTextFileNameInvalid:       "text file name invalid",
+		ErrorCodeTextFileSizeInvalid:       "text file size invalid",
+		ErrorCodeTextFileMissing:           "text file is misisng",
+		ErrorCodeImageFileCountInvalid:     "image file count invalid",
+		ErrorCodeImageFileSizeInvalid:      "image file size invalid",
+		ErrorCodeProposalNameInvalid:       "proposal name invalid",
+		ErrorCodeVoteStatusInvalid:         "vote status invalid",
+		ErrorCodeTokenInvalid:              "token invalid",
+		ErrorCodePublicKeyInvalid:          "public key invalid",
+		ErrorCodeSignatureInvalid:          "signature invalid",
+		ErrorCodeAuthorUpdateLengthInvalid: "author update length invalid",
+		ErrorCodeRecordStateInvalid:        "record state invalid",
+		ErrorCodeUserUnauthorized:          "user is unauthorized",
+		ErrorCodeResubmitsInvalid:          "resubmitted proposal invalid",
 	}
 )
 
@@ -145,6 +191,59 @@ const (
 // proposal signature since it is user specified data. The ProposalMetadata
 // object is saved to politeiad as a file, not as a metadata stream, since it
 // needs to be included in the merkle root that politeiad signs.
+//
+// Resubmits is the token of a proposal that was rejected by the ticket vote
+// and that this proposal is a resubmission of. The rejected proposal must
+// have been submitted by the same user.
 type ProposalMetadata struct {
-	Name string `json:"name"`
+	Name      string `json:"name"`
+	Resubmits string `json:"resubmits,omitempty"`
+}
+
+// SetAuthorUpdate sets the author update of a record. A record has a single
+// author update that is displayed above the comments. Setting the author
+// update creates a new version of it. All previous versions are kept.
+//
+// Signature is the client signature of the Token+Update.
+type SetAuthorUpdate struct {
+	UserID    string `json:"userid"`    // Unique user ID
+	Token     string `json:"token"`     // Record token
+	Update    string `json:"update"`    // Author update text
+	PublicKey string `json:"publickey"` // Pubkey used for Signature
+	Signature string `json:"signature"` // Client signature
+}
+
+// SetAuthorUpdateReply is the reply to the SetAuthorUpdate command.
+type SetAuthorUpdateReply struct {
+	AuthorUpdate AuthorUpdate `json:"authorupdate"`
+}
+
+// AuthorUpdate is the structure that is saved to disk when an author update
+// is set.
+//
+// Signature is the client signature of the Token+Update. Receipt is the
+// server signature of the client signature.
+type AuthorUpdate struct {
+	// Data generated by client
+	UserID    string `json:"userid"`    // Unique user ID
+	Token     string `json:"token"`     // Record token
+	Update    string `json:"update"`    // Author update text
+	PublicKey string `json:"publickey"` // Pubkey used for Signature
+	Signature string `json:"signature"` // Client signature
+
+	// Metadata generated by server
+	Version   uint32 `json:"version"`   // Author update version
+	Timestamp int64  `json:"timestamp"` // Received UNIX timestamp
+	Receipt   string `json:"receipt"`   // Server signature of client signature
+}
+
+// AuthorUpdates requests all versions of the author update of a record. This
+// command does not have a payload.
+type AuthorUpdates struct{}
+
+// AuthorUpdatesReply is the reply to the AuthorUpdates command. The updates
+// are ordered from oldest version to newest version. An empty slice is
+// returned if the author update has never been set.
+type AuthorUpdatesReply struct {
+	Updates []AuthorUpdate `json:"updates"`
 }
@@ -20,6 +20,10 @@ const (
 	CmdSubmissions = "submissions" // Get runoff vote submissions
 	CmdInventory   = "inventory"   // Get inventory by vote status
 	CmdTimestamps  = "timestamps"  // Get vote timestamps
+
+	// CmdSubmissionsRepair repairs the submissions list of a runoff vote
+	// parent record.
+	CmdSubmissionsRepair = "submissionsrepair"
 )
 
 // Plugin setting keys can be used to specify custom plugin settings. Default
@@ -344,9 +348,10 @@ type VoteDetails struct {
 
 // CastVoteDetails contains the details of a cast vote.
 //
-// Signature is the client signature of the Token+Ticket+VoteBit. The client
-// uses the ticket's largest commitment address to create the signature. The
-// receipt is the server signature of the client signature.
+// Signature is the client signature of the Token+Ticket+VoteBit, followed by
+// the Nonce+Expiry when a nonce is set. The client uses the ticket's largest
+// commitment address to create the signature. The receipt is the server
+// signature of the client signature.
 type CastVoteDetails struct {
 	// Data generated by client
 	Token     string `json:"token"`     // Record token
@@ -358,6 +363,8 @@ type CastVoteDetails struct {
 	Address   string `json:"address"`   // Largest commitment address
 	Receipt   string `json:"receipt"`   // Server signature
 	Timestamp int64  `json:"timestamp"` // Unix timestamp
+
+	ReplayProtection
 }
 
 // AuthActionT represents the ticket vote authorization actions.
@@ -481,13 +488,25 @@ var (
 	}
 )
 
+// ReplayProtection protects a signed cast vote from being replayed. The
+// fields are optional. When a nonce is provided the Nonce+Expiry are appended
+// to the message that is signed by the ticket. The nonce is verified by
+// politeiawww and is saved along with the signature so that the signature can
+// be verified.
+type ReplayProtection struct {
+	Nonce  string `json:"nonce,omitempty"`  // Hex encoded random nonce
+	Expiry int64  `json:"expiry,omitempty"` // UNIX timestamp
+}
+
 // CastVote is a signed ticket vote. This structure gets saved to disk when
 // a vote is cast.
 type CastVote struct {
 	Token     string `json:"token"`     // Record token
 	Ticket    string `json:"ticket"`    // Ticket ID
 	VoteBit   string `json:"votebit"`   // Selected vote bit, hex encoded
-	Signature string `json:"signature"` // Signature of Token+Ticket+VoteBit
+	Signature string `json:"signature"` // Signature of Token+Ticket+VoteBit[+Nonce+Expiry]
+
+	ReplayProtection
 }
 
 // CastVoteReply contains the receipt for the cast vote.
@@ -629,6 +648,27 @@ type SubmissionsReply struct {
 	Submissions []string `json:"submissions"`
 }
 
+// SubmissionsRepair repairs the submissions list of a runoff vote parent
+// record. The submissions list is updated when a record that links to the
+// parent record changes status, so a list can fall out of sync if the update
+// fails.
+//
+// Every record that is currently in the submissions list and every record in
+// Tokens is re-verified. A record belongs in the list if it is a public record
+// whose VoteMetadata LinkTo field is set to the parent record token. The full
+// length tokens must be used.
+type SubmissionsRepair struct {
+	Tokens []string `json:"tokens,omitempty"` // Submissions to add
+}
+
+// SubmissionsRepairReply is the reply to the SubmissionsRepair command. It
+// contains the records that were added to and removed from the submissions
+// list.
+type SubmissionsRepairReply struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
 const (
 	// InventoryPageSize is the maximum number of tokens that will be
 	// returned for any single status in an InventoryReply.
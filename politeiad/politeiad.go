@@ -12,12 +12,15 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httputil"
+	"net/url"
 	"os"
 	"os/signal"
 	"regexp"
 	"runtime/debug"
+	"sort"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/decred/dcrd/chaincfg/v3"
 	v1 "github.com/decred/politeia/politeiad/api/v1"
@@ -27,7 +30,9 @@ import (
 	"github.com/decred/politeia/politeiad/backend/gitbe"
 	"github.com/decred/politeia/politeiad/backendv2"
 	"github.com/decred/politeia/politeiad/backendv2/tstorebe"
+	"github.com/decred/politeia/politeiad/backendv2/tstorebe/tstore"
 	"github.com/decred/politeia/util"
+	"github.com/decred/politeia/util/tracing"
 	"github.com/decred/politeia/util/version"
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
@@ -47,6 +52,68 @@ type politeia struct {
 	cfg       *config
 	router    *mux.Router
 	identity  *identity.FullIdentity
+
+	// prevIdentity is the previous politeiad identity. It is only set
+	// during an identity rotation. The identity route advertises the
+	// previous public key until rotationEnd.
+	prevIdentity *identity.FullIdentity
+	rotationEnd  int64 // UNIX time
+
+	// tracer exports the request trace spans. It is nil when tracing
+	// is disabled.
+	tracer *tracing.Tracer
+
+	// accounting tracks the resource usage of the requests.
+	accounting *accounting
+
+	// writeVersion tracks the write version that is returned in the
+	// reply headers.
+	writeVersion *writeVersion
+
+	// replicationStatus returns the status of the tstore replication
+	// stream. It is nil when replication is disabled.
+	replicationStatus func() *tstore.ReplicationStatus
+}
+
+// loadPrevIdentity loads the previous politeiad identity of an identity
+// rotation.
+func (p *politeia) loadPrevIdentity() error {
+	end, err := time.Parse(time.RFC3339, p.cfg.RotationEnd)
+	if err != nil {
+		return fmt.Errorf("invalid rotationend: %v", err)
+	}
+	id, err := identity.LoadFullIdentity(p.cfg.PrevIdentity)
+	if err != nil {
+		return fmt.Errorf("load previdentity: %v", err)
+	}
+	if id.Public.String() == p.identity.Public.String() {
+		return fmt.Errorf("previdentity is the same as the identity")
+	}
+	p.prevIdentity = id
+	p.rotationEnd = end.Unix()
+
+	log.Infof("Previous public key: %x", id.Public.Key)
+	if time.Now().Unix() >= p.rotationEnd {
+		log.Warnf("Identity rotation ended at %v; the previous public "+
+			"key is no longer advertised", end.UTC())
+	} else {
+		log.Infof("Identity rotation ends at %v", end.UTC())
+	}
+
+	return nil
+}
+
+// rotationIdentity returns the previous politeiad identity if an identity
+// rotation is in progress. nil is returned if there is no rotation or if the
+// rotation has ended.
+func (p *politeia) rotationIdentity() *identity.FullIdentity {
+	if p.prevIdentity == nil {
+		return nil
+	}
+	if time.Now().Unix() >= p.rotationEnd {
+		return nil
+	}
+	return p.prevIdentity
 }
 
 func remoteAddr(r *http.Request) string {
@@ -296,15 +363,107 @@ func parsePluginSetting(setting string) (string, *backendv2.PluginSetting, error
 	}, nil
 }
 
+// pluginSetupOrder returns the IDs of the provided plugins in the order that
+// the plugins must be set up in. A plugin is always set up after the plugins
+// that it depends on. The plugins are visited in order of plugin ID so that
+// the returned order is deterministic. An error is returned if a dependency is
+// not one of the provided plugins or if the dependencies contain a cycle.
+func pluginSetupOrder(plugins []backendv2.Plugin) ([]string, error) {
+	deps := make(map[string][]string, len(plugins))
+	ids := make([]string, 0, len(plugins))
+	for _, v := range plugins {
+		deps[v.ID] = v.Dependencies
+		ids = append(ids, v.ID)
+	}
+	sort.Strings(ids)
+
+	// Verify that all dependencies are present. All missing
+	// dependencies are reported at once.
+	missing := make([]string, 0, len(plugins))
+	for _, id := range ids {
+		for _, v := range deps[id] {
+			if _, ok := deps[v]; !ok {
+				missing = append(missing,
+					fmt.Sprintf("%v requires %v", id, v))
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("plugin dependencies not enabled: %v",
+			strings.Join(missing, ", "))
+	}
+
+	// Depth first traversal of the dependencies. The path contains
+	// the plugins that are currently being visited and is used to
+	// detect and report cycles.
+	var (
+		order   = make([]string, 0, len(plugins))
+		visited = make(map[string]bool, len(plugins)) // [id]done
+		path    = make([]string, 0, len(plugins))
+		visit   func(id string) error
+	)
+	visit = func(id string) error {
+		done, ok := visited[id]
+		switch {
+		case ok && done:
+			return nil
+		case ok:
+			// The plugin is already on the current path
+			cycle := append(path, id)
+			for k, v := range cycle {
+				if v == id {
+					cycle = cycle[k:]
+					break
+				}
+			}
+			return fmt.Errorf("plugin dependency cycle: %v",
+				strings.Join(cycle, " -> "))
+		}
+		visited[id] = false
+		path = append(path, id)
+		for _, v := range deps[id] {
+			err := visit(v)
+			if err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		visited[id] = true
+		order = append(order, id)
+		return nil
+	}
+	for _, id := range ids {
+		err := visit(id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
 func (p *politeia) setupBackendTstore(anp *chaincfg.Params) error {
 	b, err := tstorebe.New(p.cfg.HomeDir, p.cfg.DataDir, anp,
-		p.cfg.TlogHost, p.cfg.TlogPass, p.cfg.DBType, p.cfg.DBHost,
-		p.cfg.DBPass, p.cfg.DcrtimeHost, p.cfg.DcrtimeCert)
+		p.cfg.TlogType, p.cfg.TlogHost, p.cfg.TlogPass, p.cfg.DBType,
+		p.cfg.DBHost, p.cfg.DBPass, p.cfg.DcrtimeHost, p.cfg.DcrtimeCert,
+		p.cfg.TokenCollision)
 	if err != nil {
 		return fmt.Errorf("new tstorebe: %v", err)
 	}
 	p.backendv2 = b
 
+	// Setup replication
+	if p.cfg.ReplicationURL != "" {
+		// The URL has been validated by the config setup
+		u, _ := url.Parse(p.cfg.ReplicationURL)
+		log.Infof("Replication: %v", u.Redacted())
+		err = b.EnableReplication(p.cfg.ReplicationURL, p.cfg.ReplicationPass)
+		if err != nil {
+			return fmt.Errorf("enable replication: %v", err)
+		}
+		p.replicationStatus = b.ReplicationStatus
+	}
+
 	// Setup mux
 	p.router = mux.NewRouter()
 
@@ -332,15 +491,20 @@ func (p *politeia) setupBackendTstore(anp *chaincfg.Params) error {
 		p.handleInventory, permissionPublic)
 	p.addRouteV2(http.MethodPost, v2.RouteInventoryOrdered,
 		p.handleInventoryOrdered, permissionPublic)
+	p.addRouteV2(http.MethodPost, v2.RouteInventorySnapshot,
+		p.handleInventorySnapshot, permissionPublic)
+	p.addRouteV2(http.MethodPost, v2.RouteInventoryDelta,
+		p.handleInventoryDelta, permissionPublic)
 	p.addRouteV2(http.MethodPost, v2.RoutePluginWrite,
 		p.handlePluginWrite, permissionPublic)
 	p.addRouteV2(http.MethodPost, v2.RoutePluginReads,
 		p.handlePluginReads, permissionPublic)
 	p.addRouteV2(http.MethodPost, v2.RoutePluginInventory,
 		p.handlePluginInventory, permissionPublic)
-
-	p.addRouteV2(http.MethodPost, v2.RoutePluginInventory,
-		p.handlePluginInventory, permissionPublic)
+	p.addRouteV2(http.MethodPost, v2.RoutePluginSettings,
+		p.handlePluginSettings, permissionPublic)
+	p.addRouteV2(http.MethodPost, v2.RouteUsage,
+		p.handleUsage, permissionAuth)
 
 	// Setup plugins
 	if len(p.cfg.Plugins) > 0 {
@@ -364,7 +528,24 @@ func (p *politeia) setupBackendTstore(anp *chaincfg.Params) error {
 			settings[pluginID] = pss
 		}
 
-		// Register plugins
+		// Verify that settings were only provided for plugins that
+		// are being registered.
+		errs := make([]string, 0, len(settings))
+		registered := make(map[string]struct{}, len(p.cfg.Plugins))
+		for _, v := range p.cfg.Plugins {
+			registered[v] = struct{}{}
+		}
+		for pluginID := range settings {
+			if _, ok := registered[pluginID]; !ok {
+				errs = append(errs, fmt.Sprintf("settings provided for "+
+					"plugin %v but the plugin is not enabled", pluginID))
+			}
+		}
+
+		// Register plugins. The plugin settings are verified against
+		// the plugin settings schema during registration. All errors
+		// are aggregated so that every misconfiguration is reported
+		// at once.
 		for _, v := range p.cfg.Plugins {
 			// Setup plugin
 			ps, ok := settings[v]
@@ -381,16 +562,29 @@ func (p *politeia) setupBackendTstore(anp *chaincfg.Params) error {
 			log.Infof("Register plugin: %v", v)
 			err = p.backendv2.PluginRegister(plugin)
 			if err != nil {
-				return fmt.Errorf("PluginRegister %v: %v", v, err)
+				errs = append(errs, fmt.Sprintf("PluginRegister %v: %v", v, err))
+			}
+		}
+		if len(errs) > 0 {
+			for _, v := range errs {
+				log.Errorf("%v", v)
 			}
+			return fmt.Errorf("plugin configuration invalid: %v errors",
+				len(errs))
 		}
 
-		// Setup plugins
-		for _, v := range p.backendv2.PluginInventory() {
-			log.Infof("Setup plugin: %v", v.ID)
-			err = p.backendv2.PluginSetup(v.ID)
+		// Setup plugins. The plugins declare the plugins that they
+		// depend on and are set up after their dependencies, regardless
+		// of the order that they were provided in.
+		order, err := pluginSetupOrder(p.backendv2.PluginInventory())
+		if err != nil {
+			return err
+		}
+		for _, v := range order {
+			log.Infof("Setup plugin: %v", v)
+			err = p.backendv2.PluginSetup(v)
 			if err != nil {
-				return fmt.Errorf("plugin setup %v: %v", v.ID, err)
+				return fmt.Errorf("plugin setup %v: %v", v, err)
 			}
 		}
 	}
@@ -464,6 +658,15 @@ func _main() error {
 	}
 	log.Infof("Public key: %x", p.identity.Public.Key)
 
+	// Load the previous identity if an identity rotation is in
+	// progress.
+	if cfg.PrevIdentity != "" {
+		err := p.loadPrevIdentity()
+		if err != nil {
+			return err
+		}
+	}
+
 	// Load certs, if there.  If they aren't there assume OS is used to
 	// resolve cert validity.
 	if len(cfg.DcrtimeCert) != 0 {
@@ -500,6 +703,33 @@ func _main() error {
 		return fmt.Errorf("invalid backend selected: %v", cfg.Backend)
 	}
 
+	// Setup request tracing
+	if cfg.TracingEndpoint != "" {
+		log.Infof("Tracing: %v", cfg.TracingEndpoint)
+		p.tracer = tracing.New("politeiad", cfg.TracingEndpoint)
+		p.router.Use(p.tracer.Middleware)
+	}
+
+	// Setup resource accounting. The accounting middleware is
+	// registered after the tracing middleware so that the request
+	// spans include the accounting overhead.
+	slowRequest := time.Duration(cfg.SlowRequest) * time.Millisecond
+	p.accounting = newAccounting(slowRequest)
+	p.router.Use(p.accounting.middleware)
+
+	// Setup the write version. The write version middleware runs after
+	// the routes have been matched.
+	p.writeVersion = newWriteVersion()
+	p.router.Use(p.writeVersion.middleware)
+	if cfg.Metrics {
+		log.Infof("Metrics: enabled")
+		p.addRoute(http.MethodGet, routeMetrics,
+			p.accounting.ServeHTTP, permissionAuth)
+		if p.replicationStatus != nil {
+			p.accounting.addMetrics(p.replicationMetrics)
+		}
+	}
+
 	// Bind to a port and pass our router in
 	listenC := make(chan error)
 	for _, listener := range cfg.Listeners {
@@ -535,6 +765,9 @@ done:
 	case backendTstore:
 		p.backendv2.Close()
 	}
+	if p.tracer != nil {
+		p.tracer.Close()
+	}
 
 	log.Infof("Exiting")
 
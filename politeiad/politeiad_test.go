@@ -4,7 +4,12 @@
 
 package main
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+
+	backendv2 "github.com/decred/politeia/politeiad/backendv2"
+)
 
 func TestParsePluginSetting(t *testing.T) {
 	var tests = []struct {
@@ -145,3 +150,88 @@ func TestParsePluginSetting(t *testing.T) {
 		})
 	}
 }
+
+func TestPluginSetupOrder(t *testing.T) {
+	plugin := func(id string, deps ...string) backendv2.Plugin {
+		return backendv2.Plugin{
+			ID:           id,
+			Dependencies: deps,
+		}
+	}
+	var tests = []struct {
+		name    string
+		plugins []backendv2.Plugin
+		order   []string // Nil if an error is expected
+	}{
+		{
+			"no dependencies",
+			[]backendv2.Plugin{
+				plugin("usermd"),
+				plugin("comments"),
+			},
+			[]string{"comments", "usermd"},
+		},
+		{
+			"dependency chain",
+			[]backendv2.Plugin{
+				plugin("pi", "ticketvote"),
+				plugin("usermd"),
+				plugin("ticketvote", "dcrdata"),
+				plugin("comments"),
+				plugin("dcrdata"),
+			},
+			[]string{"comments", "dcrdata", "ticketvote", "pi", "usermd"},
+		},
+		{
+			"shared dependency",
+			[]backendv2.Plugin{
+				plugin("c", "a"),
+				plugin("b", "a"),
+				plugin("a"),
+			},
+			[]string{"a", "b", "c"},
+		},
+		{
+			"missing dependency",
+			[]backendv2.Plugin{
+				plugin("ticketvote", "dcrdata"),
+			},
+			nil,
+		},
+		{
+			"self dependency",
+			[]backendv2.Plugin{
+				plugin("a", "a"),
+			},
+			nil,
+		},
+		{
+			"dependency cycle",
+			[]backendv2.Plugin{
+				plugin("a", "b"),
+				plugin("b", "c"),
+				plugin("c", "a"),
+			},
+			nil,
+		},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			order, err := pluginSetupOrder(v.plugins)
+			switch {
+			case v.order == nil && err == nil:
+				t.Errorf("got nil error, want failure")
+
+			case v.order == nil && err != nil:
+				// Received the expected error output. Continue.
+
+			case err != nil:
+				t.Errorf("got error '%v', want nil error", err)
+
+			case !reflect.DeepEqual(order, v.order):
+				t.Errorf("invalid order: got %v, want %v",
+					order, v.order)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// replicationMetrics writes the status of the tstore replication stream using
+// the Prometheus text format. The lag is the amount of time that the oldest
+// change that has not been accepted by the receiver has been waiting.
+func (p *politeia) replicationMetrics(w io.Writer) {
+	s := p.replicationStatus()
+	if s == nil {
+		return
+	}
+
+	var lastSuccess int64
+	if !s.LastSuccess.IsZero() {
+		lastSuccess = s.LastSuccess.Unix()
+	}
+	metrics := []struct {
+		name  string
+		help  string
+		typ   string
+		value interface{}
+	}{
+		{
+			"politeiad_replication_lag_seconds",
+			"Age of the oldest change that has not been replicated.",
+			"gauge", s.Lag.Seconds(),
+		},
+		{
+			"politeiad_replication_pending",
+			"Number of trees and deletion queues with unreplicated changes.",
+			"gauge", s.Pending,
+		},
+		{
+			"politeiad_replication_sequence",
+			"Sequence number of the last batch accepted by the receiver.",
+			"counter", s.Sequence,
+		},
+		{
+			"politeiad_replication_last_success_timestamp_seconds",
+			"Unix time of the last batch accepted by the receiver.",
+			"gauge", lastSuccess,
+		},
+		{
+			"politeiad_replication_failures_total",
+			"Total number of failed replication batches.",
+			"counter", s.Failures,
+		},
+	}
+	for _, m := range metrics {
+		fmt.Fprintf(w, "# HELP %v %v\n", m.name, m.help)
+		fmt.Fprintf(w, "# TYPE %v %v\n", m.name, m.typ)
+		fmt.Fprintf(w, "%v %v\n", m.name, m.value)
+	}
+}
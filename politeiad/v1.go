@@ -35,6 +35,17 @@ func (p *politeia) getIdentity(w http.ResponseWriter, r *http.Request) {
 		Response:  hex.EncodeToString(response[:]),
 	}
 
+	// Advertise the identity rotation. The previous identity signs the
+	// new public key so that clients that have pinned the previous
+	// public key are able to verify the new one.
+	if prevID := p.rotationIdentity(); prevID != nil {
+		msg := v1.RotationMessage(reply.PublicKey, p.rotationEnd)
+		sig := prevID.SignMessage([]byte(msg))
+		reply.PrevPublicKey = prevID.Public.String()
+		reply.RotationEnd = p.rotationEnd
+		reply.RotationSignature = hex.EncodeToString(sig[:])
+	}
+
 	util.RespondWithJSON(w, http.StatusOK, reply)
 }
 
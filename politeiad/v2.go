@@ -4,6 +4,7 @@
 package main
 
 import (
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -15,6 +16,7 @@ import (
 	v2 "github.com/decred/politeia/politeiad/api/v2"
 	"github.com/decred/politeia/politeiad/backendv2"
 	"github.com/decred/politeia/util"
+	"github.com/decred/politeia/util/tracing"
 )
 
 func (p *politeia) handleRecordNew(w http.ResponseWriter, r *http.Request) {
@@ -44,7 +46,7 @@ func (p *politeia) handleRecordNew(w http.ResponseWriter, r *http.Request) {
 		metadata = convertMetadataStreamsToBackend(rn.Metadata)
 		files    = convertFilesToBackend(rn.Files)
 	)
-	rc, err := p.backendv2.RecordNew(metadata, files)
+	rc, err := p.backend(r).RecordNew(r.Context(), metadata, files)
 	if err != nil {
 		respondWithErrorV2(w, r,
 			"handleRecordNew: RecordNew: %v", err)
@@ -101,7 +103,7 @@ func (p *politeia) handleRecordEdit(w http.ResponseWriter, r *http.Request) {
 		mdOverwrite = convertMetadataStreamsToBackend(re.MDOverwrite)
 		filesAdd    = convertFilesToBackend(re.FilesAdd)
 	)
-	rc, err := p.backendv2.RecordEdit(token, mdAppend,
+	rc, err := p.backend(r).RecordEdit(r.Context(), token, mdAppend,
 		mdOverwrite, filesAdd, re.FilesDel)
 	if err != nil {
 		respondWithErrorV2(w, r,
@@ -158,7 +160,8 @@ func (p *politeia) handleRecordEditMetadata(w http.ResponseWriter, r *http.Reque
 		mdAppend    = convertMetadataStreamsToBackend(re.MDAppend)
 		mdOverwrite = convertMetadataStreamsToBackend(re.MDOverwrite)
 	)
-	rc, err := p.backendv2.RecordEditMetadata(token, mdAppend, mdOverwrite)
+	rc, err := p.backend(r).RecordEditMetadata(r.Context(), token,
+		mdAppend, mdOverwrite)
 	if err != nil {
 		respondWithErrorV2(w, r,
 			"handleRecordEditMetadata: RecordEditMetadata: %v", err)
@@ -212,7 +215,7 @@ func (p *politeia) handleRecordSetStatus(w http.ResponseWriter, r *http.Request)
 		mdOverwrite = convertMetadataStreamsToBackend(rss.MDOverwrite)
 		status      = backendv2.StatusT(rss.Status)
 	)
-	rc, err := p.backendv2.RecordSetStatus(token, status,
+	rc, err := p.backend(r).RecordSetStatus(r.Context(), token, status,
 		mdAppend, mdOverwrite)
 	if err != nil {
 		respondWithErrorV2(w, r,
@@ -266,7 +269,7 @@ func (p *politeia) handleRecords(w http.ResponseWriter, r *http.Request) {
 
 	// Get record batch
 	reqs := convertRecordRequestsToBackend(rgb.Requests)
-	brecords, err := p.backendv2.Records(reqs)
+	brecords, err := p.backend(r).Records(reqs)
 	if err != nil {
 		respondWithErrorV2(w, r,
 			"handleRecordGet: Records: %v", err)
@@ -319,7 +322,7 @@ func (p *politeia) handleRecordTimestamps(w http.ResponseWriter, r *http.Request
 	}
 
 	// Get record timestamps
-	rt, err := p.backendv2.RecordTimestamps(token, rgt.Version)
+	rt, err := p.backend(r).RecordTimestamps(token, rgt.Version)
 	if err != nil {
 		respondWithErrorV2(w, r,
 			"handleRecordTimestamps: RecordTimestamps: %v", err)
@@ -390,7 +393,7 @@ func (p *politeia) handleInventory(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get inventory
-	inv, err := p.backendv2.Inventory(state, status, pageSize, pageNumber)
+	inv, err := p.backend(r).Inventory(state, status, pageSize, pageNumber)
 	if err != nil {
 		respondWithErrorV2(w, r,
 			"handleInventory: Inventory: %v", err)
@@ -454,7 +457,7 @@ func (p *politeia) handleInventoryOrdered(w http.ResponseWriter, r *http.Request
 	}
 
 	// Get inventory
-	tokens, err := p.backendv2.InventoryOrdered(state,
+	tokens, err := p.backend(r).InventoryOrdered(state,
 		v2.InventoryPageSize, i.Page)
 	if err != nil {
 		respondWithErrorV2(w, r,
@@ -471,6 +474,118 @@ func (p *politeia) handleInventoryOrdered(w http.ResponseWriter, r *http.Request
 	util.RespondWithJSON(w, http.StatusOK, ir)
 }
 
+func (p *politeia) handleInventorySnapshot(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleInventorySnapshot")
+
+	// Decode request
+	var is v2.InventorySnapshot
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&is); err != nil {
+		respondWithErrorV2(w, r, "handleInventorySnapshot: unmarshal",
+			v2.UserErrorReply{
+				ErrorCode: v2.ErrorCodeRequestPayloadInvalid,
+			})
+		return
+	}
+	challenge, err := hex.DecodeString(is.Challenge)
+	if err != nil || len(challenge) != v2.ChallengeSize {
+		respondWithErrorV2(w, r, "handleInventorySnapshot: decode challenge",
+			v2.UserErrorReply{
+				ErrorCode: v2.ErrorCodeChallengeInvalid,
+			})
+		return
+	}
+
+	// Get inventory snapshot
+	snapshot, err := p.backend(r).InventorySnapshot()
+	if err != nil {
+		respondWithErrorV2(w, r,
+			"handleInventorySnapshot: InventorySnapshot: %v", err)
+		return
+	}
+
+	// Prepare reply
+	unvetted := make(map[string][]string, len(snapshot.Unvetted))
+	for k, v := range snapshot.Unvetted {
+		key := backendv2.Statuses[k]
+		unvetted[key] = v
+	}
+	vetted := make(map[string][]string, len(snapshot.Vetted))
+	for k, v := range snapshot.Vetted {
+		key := backendv2.Statuses[k]
+		vetted[key] = v
+	}
+	response := p.identity.SignMessage(challenge)
+	isr := v2.InventorySnapshotReply{
+		Response: hex.EncodeToString(response[:]),
+		Version:  snapshot.Version,
+		Unvetted: unvetted,
+		Vetted:   vetted,
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, isr)
+}
+
+func (p *politeia) handleInventoryDelta(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleInventoryDelta")
+
+	// Decode request
+	var id v2.InventoryDelta
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&id); err != nil {
+		respondWithErrorV2(w, r, "handleInventoryDelta: unmarshal",
+			v2.UserErrorReply{
+				ErrorCode: v2.ErrorCodeRequestPayloadInvalid,
+			})
+		return
+	}
+	challenge, err := hex.DecodeString(id.Challenge)
+	if err != nil || len(challenge) != v2.ChallengeSize {
+		respondWithErrorV2(w, r, "handleInventoryDelta: decode challenge",
+			v2.UserErrorReply{
+				ErrorCode: v2.ErrorCodeChallengeInvalid,
+			})
+		return
+	}
+
+	// Get inventory changes
+	delta, err := p.backend(r).InventoryDelta(id.Version,
+		v2.InventoryDeltaPageSize)
+	if err != nil {
+		respondWithErrorV2(w, r,
+			"handleInventoryDelta: InventoryDelta: %v", err)
+		return
+	}
+
+	// Prepare reply
+	changes := make([]v2.InventoryChange, 0, len(delta.Changes))
+	for _, v := range delta.Changes {
+		changes = append(changes, v2.InventoryChange{
+			Version:   v.Version,
+			Token:     v.Token,
+			State:     v2.RecordStateT(v.State),
+			Status:    v2.RecordStatusT(v.Status),
+			Timestamp: v.Timestamp,
+		})
+	}
+	response := p.identity.SignMessage(challenge)
+	idr := v2.InventoryDeltaReply{
+		Response: hex.EncodeToString(response[:]),
+		Version:  delta.Version,
+		Changes:  changes,
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, idr)
+}
+
+// startPluginSpan starts a trace span for the execution of a plugin command.
+// The returned context contains the span. A nil span is returned when the
+// request is not being traced.
+func startPluginSpan(r *http.Request, pluginID, cmd string) (context.Context, *tracing.Span) {
+	return tracing.Start(r.Context(), "plugin "+pluginID+" "+cmd,
+		tracing.SpanKindInternal)
+}
+
 func (p *politeia) handlePluginWrite(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("handlePluginWrite")
 
@@ -503,8 +618,11 @@ func (p *politeia) handlePluginWrite(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Execute plugin cmd
-	payload, err := p.backendv2.PluginWrite(token, pw.Cmd.ID,
+	ctx, span := startPluginSpan(r, pw.Cmd.ID, pw.Cmd.Command)
+	payload, err := p.backend(r).PluginWrite(ctx, token, pw.Cmd.ID,
 		pw.Cmd.Command, pw.Cmd.Payload)
+	span.SetError(err)
+	span.End()
 	if err != nil {
 		respondWithErrorV2(w, r,
 			"handlePluginWrite: PluginWrite: %v", err)
@@ -565,8 +683,11 @@ func (p *politeia) handlePluginReads(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Execute plugin cmd
-		replyPayload, err := p.backendv2.PluginRead(token, v.ID,
+		_, span := startPluginSpan(r, v.ID, v.Command)
+		replyPayload, err := p.backend(r).PluginRead(token, v.ID,
 			v.Command, v.Payload)
+		span.SetError(err)
+		span.End()
 		if err != nil {
 			var (
 				errCode = convertErrorToV2(err)
@@ -666,6 +787,79 @@ func (p *politeia) handlePluginInventory(w http.ResponseWriter, r *http.Request)
 
 }
 
+func (p *politeia) handlePluginSettings(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handlePluginSettings")
+
+	// Decode request
+	var ps v2.PluginSettings
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&ps); err != nil {
+		respondWithErrorV2(w, r, "handlePluginSettings: unmarshal",
+			v2.UserErrorReply{
+				ErrorCode: v2.ErrorCodeRequestPayloadInvalid,
+			})
+		return
+	}
+	challenge, err := hex.DecodeString(ps.Challenge)
+	if err != nil || len(challenge) != v2.ChallengeSize {
+		respondWithErrorV2(w, r, "handlePluginSettings: decode challenge",
+			v2.UserErrorReply{
+				ErrorCode: v2.ErrorCodeChallengeInvalid,
+			})
+		return
+	}
+
+	// Get the effective plugin settings
+	settings := make(map[string][]v2.PluginSettingDetails)
+	for _, v := range p.backendv2.PluginInventory() {
+		settings[v.ID] = convertPluginSettingDetailsToV2(v)
+	}
+
+	// Prepare reply
+	response := p.identity.SignMessage(challenge)
+	psr := v2.PluginSettingsReply{
+		Response: hex.EncodeToString(response[:]),
+		Settings: settings,
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, psr)
+}
+
+func (p *politeia) handleUsage(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleUsage")
+
+	// Decode request
+	var u v2.Usage
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&u); err != nil {
+		respondWithErrorV2(w, r, "handleUsage: unmarshal",
+			v2.UserErrorReply{
+				ErrorCode: v2.ErrorCodeRequestPayloadInvalid,
+			})
+		return
+	}
+	challenge, err := hex.DecodeString(u.Challenge)
+	if err != nil || len(challenge) != v2.ChallengeSize {
+		respondWithErrorV2(w, r, "handleUsage: decode challenge",
+			v2.UserErrorReply{
+				ErrorCode: v2.ErrorCodeChallengeInvalid,
+			})
+		return
+	}
+
+	// Prepare reply
+	response := p.identity.SignMessage(challenge)
+	cpu, _ := processCPUTime()
+	ur := v2.UsageReply{
+		Response: hex.EncodeToString(response[:]),
+		CPUTime:  cpu.Microseconds(),
+		Usage:    p.accounting.summaries(),
+		Slow:     p.accounting.slowRequests(),
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, ur)
+}
+
 // decodeToken decodes a v2 token and errors if the token is not the full
 // length token.
 func decodeToken(token string) ([]byte, error) {
@@ -847,6 +1041,32 @@ func convertPluginSettingToV2(p backendv2.PluginSetting) v2.PluginSetting {
 	}
 }
 
+// convertPluginSettingDetailsToV2 combines the plugin settings schema with
+// the effective plugin settings. Settings that are not part of the schema
+// are not included.
+func convertPluginSettingDetailsToV2(p backendv2.Plugin) []v2.PluginSettingDetails {
+	values := make(map[string]string, len(p.Settings))
+	for _, v := range p.Settings {
+		values[v.Key] = v.Value
+	}
+	details := make([]v2.PluginSettingDetails, 0, len(p.Schema))
+	for _, v := range p.Schema {
+		value, ok := values[v.Key]
+		if !ok {
+			value = v.Default
+		}
+		details = append(details, v2.PluginSettingDetails{
+			Key:     v.Key,
+			Type:    backendv2.PluginSettingTypes[v.Type],
+			Value:   value,
+			Default: v.Default,
+			Min:     v.Min,
+			Max:     v.Max,
+		})
+	}
+	return details
+}
+
 func convertPluginsToV2(bplugins []backendv2.Plugin) []v2.Plugin {
 	plugins := make([]v2.Plugin, 0, len(bplugins))
 	for _, v := range bplugins {
@@ -962,6 +1182,8 @@ func convertErrorToV2(e error) v2.ErrorCodeT {
 		return v2.ErrorCodePluginIDInvalid
 	case backendv2.ErrPluginCmdInvalid:
 		return v2.ErrorCodePluginCmdInvalid
+	case backendv2.ErrInventoryVersionInvalid:
+		return v2.ErrorCodeInventoryVersionInvalid
 	}
 	return v2.ErrorCodeInvalid
 }
@@ -0,0 +1,114 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	v1 "github.com/decred/politeia/politeiad/api/v1"
+	v2 "github.com/decred/politeia/politeiad/api/v2"
+	"github.com/gorilla/mux"
+)
+
+var (
+	// writeRoutes contains the routes that write data. A successful
+	// request to any of these routes increments the write version. The
+	// v1 plugin command route is used for both reads and writes so it is
+	// treated as a write.
+	writeRoutes = map[string]struct{}{
+		v1.NewRecordRoute:                        {},
+		v1.UpdateUnvettedRoute:                   {},
+		v1.UpdateVettedRoute:                     {},
+		v1.UpdateVettedMetadataRoute:             {},
+		v1.SetUnvettedStatusRoute:                {},
+		v1.SetVettedStatusRoute:                  {},
+		v1.PluginCommandRoute:                    {},
+		v1.UpdateReadmeRoute:                     {},
+		v2.APIRoute + v2.RouteRecordNew:          {},
+		v2.APIRoute + v2.RouteRecordEdit:         {},
+		v2.APIRoute + v2.RouteRecordEditMetadata: {},
+		v2.APIRoute + v2.RouteRecordSetStatus:    {},
+		v2.APIRoute + v2.RoutePluginWrite:        {},
+	}
+)
+
+// writeVersion tracks the politeiad write version. Every successful write
+// increments the version. The version is returned in the v2.VersionHeader of
+// every reply. See the v2.VersionHeader documentation for more details.
+//
+// The version starts at the startup time in nanoseconds so that it increases
+// across restarts without having to be persisted. Writes take far longer than
+// a nanosecond, so the versions of a run can never exceed the startup time of
+// the next run.
+type writeVersion struct {
+	version uint64 // Atomic
+}
+
+// newWriteVersion returns a new writeVersion.
+func newWriteVersion() *writeVersion {
+	return &writeVersion{
+		version: uint64(time.Now().UnixNano()),
+	}
+}
+
+// middleware sets the write version header of the reply. The header of a read
+// contains the version at the start of the request. The header of a write
+// contains the version of the write.
+func (v *writeVersion) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var route string
+		if cr := mux.CurrentRoute(r); cr != nil {
+			route, _ = cr.GetPathTemplate()
+		}
+		if _, ok := writeRoutes[route]; ok {
+			w = &versionWriter{
+				ResponseWriter: w,
+				version:        v,
+			}
+		} else {
+			setVersion(w, atomic.LoadUint64(&v.version))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// setVersion sets the write version header.
+func setVersion(w http.ResponseWriter, version uint64) {
+	w.Header().Set(v2.VersionHeader, strconv.FormatUint(version, 10))
+}
+
+// versionWriter increments the write version when a write succeeds and sets
+// the version header before the reply is written.
+type versionWriter struct {
+	http.ResponseWriter
+	version     *writeVersion
+	wroteHeader bool
+}
+
+// WriteHeader satisfies the http.ResponseWriter interface.
+func (w *versionWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		var version uint64
+		if code == http.StatusOK {
+			version = atomic.AddUint64(&w.version.version, 1)
+		} else {
+			version = atomic.LoadUint64(&w.version.version)
+		}
+		setVersion(w.ResponseWriter, version)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write satisfies the http.ResponseWriter interface.
+func (w *versionWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
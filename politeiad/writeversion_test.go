@@ -0,0 +1,70 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	v2 "github.com/decred/politeia/politeiad/api/v2"
+	"github.com/gorilla/mux"
+)
+
+func TestWriteVersion(t *testing.T) {
+	v := newWriteVersion()
+
+	// The handler fails when the fail query param is set
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte("{}"))
+	}
+	router := mux.NewRouter()
+	router.Use(v.middleware)
+	router.HandleFunc(v2.APIRoute+v2.RoutePluginWrite, handler).
+		Methods(http.MethodPost)
+	router.HandleFunc(v2.APIRoute+v2.RoutePluginReads, handler).
+		Methods(http.MethodPost)
+
+	version := func(route string) uint64 {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodPost, route, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		h := w.Header().Get(v2.VersionHeader)
+		u, err := strconv.ParseUint(h, 10, 64)
+		if err != nil {
+			t.Fatalf("invalid version header '%v': %v", h, err)
+		}
+		return u
+	}
+
+	var (
+		write = v2.APIRoute + v2.RoutePluginWrite
+		read  = v2.APIRoute + v2.RoutePluginReads
+	)
+	start := version(read)
+	if got := version(write); got != start+1 {
+		t.Fatalf("write: got %v, want %v", got, start+1)
+	}
+	if got := version(read); got != start+1 {
+		t.Fatalf("read: got %v, want %v", got, start+1)
+	}
+
+	// A failed write does not increment the version
+	if got := version(write + "?fail=1"); got != start+1 {
+		t.Fatalf("failed write: got %v, want %v", got, start+1)
+	}
+
+	// The versions of a new instance exceed the versions of the previous
+	// instance.
+	if got := version(read); got >= newWriteVersion().version {
+		t.Fatalf("version %v exceeds the version of a new instance", got)
+	}
+}
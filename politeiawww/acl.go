@@ -0,0 +1,429 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/config"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// aclPruneInterval is the interval at which the expired access
+	// control list entries and auth failures are removed.
+	aclPruneInterval = time.Minute
+)
+
+// aclEntry is a network that is part of an access control list. A zero
+// expiry means that the entry does not expire.
+type aclEntry struct {
+	network *net.IPNet
+	expiry  time.Time
+	reason  string
+}
+
+// expired returns whether the entry has expired.
+func (e aclEntry) expired(now time.Time) bool {
+	return !e.expiry.IsZero() && now.After(e.expiry)
+}
+
+// authFailures tracks the failed login attempts of a client address.
+type authFailures struct {
+	count int
+	first time.Time // Start of the auth failure window
+}
+
+// acl contains the network access control lists of politeiawww. The deny list
+// applies to all routes and the admin allow list applies to the admin routes.
+// An empty admin allow list allows all networks. Clients that exceed the
+// maximum number of failed login attempts are temporarily added to the deny
+// list.
+type acl struct {
+	sync.Mutex
+	adminAllow []aclEntry
+	deny       []aclEntry
+	trusted    []*net.IPNet // Trusted reverse proxies
+	failures   map[string]*authFailures
+
+	authFailMax    int // Zero disables automatic bans
+	authFailWindow time.Duration
+	banDuration    time.Duration
+}
+
+// parseCIDR parses a CIDR or a single IP address. A single IP address is
+// converted to a network that only contains the address.
+func parseCIDR(s string) (*net.IPNet, error) {
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid address %v", s)
+		}
+		bits := 8 * net.IPv6len
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+			bits = 8 * net.IPv4len
+		}
+		return &net.IPNet{
+			IP:   ip,
+			Mask: net.CIDRMask(bits, bits),
+		}, nil
+	}
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// newACL returns a new acl that is populated using the provided config.
+func newACL(cfg *config.Config) (*acl, error) {
+	a := acl{
+		adminAllow:     make([]aclEntry, 0, len(cfg.AdminAllow)),
+		deny:           make([]aclEntry, 0, len(cfg.Deny)),
+		trusted:        make([]*net.IPNet, 0, len(cfg.TrustedProxies)),
+		failures:       make(map[string]*authFailures),
+		authFailMax:    int(cfg.AuthFailMax),
+		authFailWindow: time.Duration(cfg.AuthFailWindow) * time.Minute,
+		banDuration:    time.Duration(cfg.BanDuration) * time.Minute,
+	}
+	for _, v := range cfg.AdminAllow {
+		n, err := parseCIDR(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid adminallow: %v", err)
+		}
+		a.adminAllow = append(a.adminAllow, aclEntry{network: n})
+	}
+	for _, v := range cfg.Deny {
+		n, err := parseCIDR(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deny: %v", err)
+		}
+		a.deny = append(a.deny, aclEntry{network: n})
+	}
+	for _, v := range cfg.TrustedProxies {
+		n, err := parseCIDR(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trustedproxy: %v", err)
+		}
+		a.trusted = append(a.trusted, n)
+	}
+	return &a, nil
+}
+
+// isTrusted returns whether the address belongs to a trusted reverse proxy.
+func (a *acl) isTrusted(ip net.IP) bool {
+	for _, v := range a.trusted {
+		if v.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the address of the client. The X-Forwarded-For header is
+// only used when the request was sent by a trusted reverse proxy, in which
+// case the right most address that is not a trusted proxy is returned.
+func (a *acl) clientIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !a.isTrusted(ip) {
+		return ip
+	}
+	xff := strings.Split(r.Header.Get(www.Forward), ",")
+	for i := len(xff) - 1; i >= 0; i-- {
+		fip := net.ParseIP(strings.TrimSpace(xff[i]))
+		if fip == nil {
+			break
+		}
+		ip = fip
+		if !a.isTrusted(fip) {
+			break
+		}
+	}
+	return ip
+}
+
+// contains returns whether the address is part of the provided list. Expired
+// entries are ignored.
+func contains(list []aclEntry, ip net.IP, now time.Time) bool {
+	for _, v := range list {
+		if !v.expired(now) && v.network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// denied returns whether the address is part of the deny list.
+func (a *acl) denied(ip net.IP) bool {
+	a.Lock()
+	defer a.Unlock()
+
+	return ip != nil && contains(a.deny, ip, time.Now())
+}
+
+// adminAllowed returns whether the address is allowed to access the admin
+// routes.
+func (a *acl) adminAllowed(ip net.IP) bool {
+	a.Lock()
+	defer a.Unlock()
+
+	if len(a.adminAllow) == 0 {
+		return true
+	}
+	return ip != nil && contains(a.adminAllow, ip, time.Now())
+}
+
+// authFailure records a failed login attempt of the address. The address is
+// temporarily added to the deny list once the maximum number of failed login
+// attempts within the auth failure window has been exceeded.
+func (a *acl) authFailure(ip net.IP) {
+	if a.authFailMax == 0 || ip == nil {
+		return
+	}
+
+	a.Lock()
+	defer a.Unlock()
+
+	var (
+		now = time.Now()
+		key = ip.String()
+	)
+	f, ok := a.failures[key]
+	if !ok || now.Sub(f.first) > a.authFailWindow {
+		f = &authFailures{first: now}
+		a.failures[key] = f
+	}
+	f.count++
+	if f.count < a.authFailMax {
+		return
+	}
+
+	delete(a.failures, key)
+	n, _ := parseCIDR(key)
+	a.deny = append(a.deny, aclEntry{
+		network: n,
+		expiry:  now.Add(a.banDuration),
+		reason:  "too many failed login attempts",
+	})
+	log.Infof("Temporarily banned %v for %v: too many failed login "+
+		"attempts", key, a.banDuration)
+}
+
+// loginCredentialsInvalid returns whether a login error was caused by invalid
+// credentials. Only these errors are recorded as auth failures so that server
+// errors and the errors of accounts that are not in good standing do not
+// result in a ban.
+func loginCredentialsInvalid(err error) bool {
+	var ue www.UserError
+	if !errors.As(err, &ue) {
+		return false
+	}
+	switch ue.ErrorCode {
+	case www.ErrorStatusInvalidLogin, www.ErrorStatusTOTPFailedValidation:
+		return true
+	}
+	return false
+}
+
+// prune removes the expired entries and the expired auth failures.
+//
+// This function must be called WITH the lock held.
+func (a *acl) prune(now time.Time) {
+	list := func(l []aclEntry) []aclEntry {
+		pruned := l[:0]
+		for _, v := range l {
+			if !v.expired(now) {
+				pruned = append(pruned, v)
+			}
+		}
+		return pruned
+	}
+	a.adminAllow = list(a.adminAllow)
+	a.deny = list(a.deny)
+	for k, v := range a.failures {
+		if now.Sub(v.first) > a.authFailWindow {
+			delete(a.failures, k)
+		}
+	}
+}
+
+// pruneLoop periodically removes the expired entries and the expired auth
+// failures. It runs for the lifetime of the process.
+func (a *acl) pruneLoop() {
+	ticker := time.NewTicker(aclPruneInterval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		a.Lock()
+		a.prune(now)
+		a.Unlock()
+	}
+}
+
+// reply returns the access control lists.
+func (a *acl) reply() www.ACLReply {
+	a.Lock()
+	defer a.Unlock()
+
+	a.prune(time.Now())
+
+	convert := func(l []aclEntry) []www.ACLEntry {
+		entries := make([]www.ACLEntry, 0, len(l))
+		for _, v := range l {
+			var expiry int64
+			if !v.expiry.IsZero() {
+				expiry = v.expiry.Unix()
+			}
+			entries = append(entries, www.ACLEntry{
+				CIDR:   v.network.String(),
+				Expiry: expiry,
+				Reason: v.reason,
+			})
+		}
+		return entries
+	}
+	return www.ACLReply{
+		AdminAllow: convert(a.adminAllow),
+		Deny:       convert(a.deny),
+	}
+}
+
+// set adds an entry to or removes an entry from one of the access control
+// lists.
+func (a *acl) set(sa www.SetACL) error {
+	n, err := parseCIDR(sa.CIDR)
+	if err != nil {
+		return www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{err.Error()},
+		}
+	}
+	if sa.TTL < 0 {
+		return www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"invalid ttl"},
+		}
+	}
+
+	a.Lock()
+	defer a.Unlock()
+
+	var list *[]aclEntry
+	switch sa.List {
+	case www.ACLListAdminAllow:
+		list = &a.adminAllow
+	case www.ACLListDeny:
+		list = &a.deny
+	default:
+		return www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"invalid list"},
+		}
+	}
+
+	// Remove any existing entry for the network
+	entries := (*list)[:0]
+	for _, v := range *list {
+		if v.network.String() != n.String() {
+			entries = append(entries, v)
+		}
+	}
+	*list = entries
+	if sa.Remove {
+		return nil
+	}
+
+	e := aclEntry{
+		network: n,
+		reason:  sa.Reason,
+	}
+	if sa.TTL > 0 {
+		e.expiry = time.Now().Add(time.Duration(sa.TTL) * time.Second)
+	}
+	*list = append(*list, e)
+
+	return nil
+}
+
+// middleware rejects the requests of the clients that are part of the deny
+// list.
+func (a *acl) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.denied(a.clientIP(r)) {
+			log.Debugf("%v denied: %v %v", util.RemoteAddr(r),
+				r.Method, r.URL)
+			util.RespondWithJSON(w, http.StatusForbidden, www.UserError{
+				ErrorCode: www.ErrorStatusAccessDenied,
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isAdminNetwork ensures that the client is part of the admin allow list
+// before calling the next function.
+func (p *politeiawww) isAdminNetwork(f http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !p.acl.adminAllowed(p.acl.clientIP(r)) {
+			log.Infof("%v admin route denied: %v %v",
+				util.RemoteAddr(r), r.Method, r.URL)
+			util.RespondWithJSON(w, http.StatusForbidden, www.UserError{
+				ErrorCode: www.ErrorStatusAccessDenied,
+			})
+			return
+		}
+		f(w, r)
+	}
+}
+
+// handleACL returns the network access control lists.
+func (p *politeiawww) handleACL(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleACL")
+
+	util.RespondWithJSON(w, http.StatusOK, p.acl.reply())
+}
+
+// handleSetACL modifies the network access control lists. The changes are not
+// persisted and are lost on restart.
+func (p *politeiawww) handleSetACL(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleSetACL")
+
+	var sa www.SetACL
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&sa); err != nil {
+		RespondWithError(w, r, 0, "handleSetACL: unmarshal",
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	err := p.acl.set(sa)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleSetACL: set %v", err)
+		return
+	}
+
+	log.Infof("%v ACL changed: list %v cidr %v ttl %v remove %v",
+		util.RemoteAddr(r), sa.List, sa.CIDR, sa.TTL, sa.Remove)
+
+	util.RespondWithJSON(w, http.StatusOK, www.SetACLReply{
+		ACL: p.acl.reply(),
+	})
+}
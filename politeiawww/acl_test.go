@@ -0,0 +1,209 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/config"
+)
+
+func TestACL(t *testing.T) {
+	a, err := newACL(&config.Config{
+		AdminAllow:     []string{"10.0.0.0/8"},
+		Deny:           []string{"192.0.2.0/24"},
+		TrustedProxies: []string{"127.0.0.1"},
+		AuthFailMax:    3,
+		AuthFailWindow: 15,
+		BanDuration:    60,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls int
+	h := a.middleware(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusOK)
+		}))
+	send := func(remoteAddr, xff string) int {
+		r := httptest.NewRequest(http.MethodGet, "/v1/route", nil)
+		r.RemoteAddr = remoteAddr
+		if xff != "" {
+			r.Header.Set(www.Forward, xff)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// Denied networks are rejected
+	if code := send("192.0.2.1:1234", ""); code != http.StatusForbidden {
+		t.Fatalf("got code %v, want 403", code)
+	}
+	if code := send("198.51.100.1:1234", ""); code != http.StatusOK {
+		t.Fatalf("got code %v, want 200", code)
+	}
+
+	// The forwarded address is only used for trusted proxies
+	if code := send("127.0.0.1:1234", "192.0.2.1"); code != http.StatusForbidden {
+		t.Fatalf("trusted proxy: got code %v, want 403", code)
+	}
+	if code := send("198.51.100.1:1234", "192.0.2.1"); code != http.StatusOK {
+		t.Fatalf("untrusted proxy: got code %v, want 200", code)
+	}
+	if calls != 2 {
+		t.Fatalf("got calls %v, want 2", calls)
+	}
+
+	// Admin allow list
+	if !a.adminAllowed(net.ParseIP("10.1.2.3")) {
+		t.Fatalf("admin network not allowed")
+	}
+	if a.adminAllowed(net.ParseIP("198.51.100.1")) {
+		t.Fatalf("non admin network allowed")
+	}
+
+	// Repeated auth failures result in a temporary ban
+	ip := net.ParseIP("198.51.100.7")
+	for i := 0; i < 3; i++ {
+		if a.denied(ip) {
+			t.Fatalf("banned after %v failures", i)
+		}
+		a.authFailure(ip)
+	}
+	if !a.denied(ip) {
+		t.Fatalf("not banned after 3 failures")
+	}
+
+	// Entries can be removed and added with a TTL
+	err = a.set(www.SetACL{
+		List:   www.ACLListDeny,
+		CIDR:   ip.String(),
+		Remove: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.denied(ip) {
+		t.Fatalf("denied after removal")
+	}
+	err = a.set(www.SetACL{
+		List: www.ACLListDeny,
+		CIDR: "198.51.100.0/24",
+		TTL:  3600,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !a.denied(ip) {
+		t.Fatalf("not denied after set")
+	}
+	r := a.reply()
+	if len(r.Deny) != 2 || r.Deny[1].Expiry == 0 {
+		t.Fatalf("unexpected deny list %v", r.Deny)
+	}
+
+	// Invalid requests are rejected
+	err = a.set(www.SetACL{
+		List: "invalid",
+		CIDR: "198.51.100.0/24",
+	})
+	if err == nil {
+		t.Fatalf("invalid list accepted")
+	}
+	err = a.set(www.SetACL{
+		List: www.ACLListDeny,
+		CIDR: "invalid",
+	})
+	if err == nil {
+		t.Fatalf("invalid cidr accepted")
+	}
+}
+
+func TestACLPrune(t *testing.T) {
+	a, err := newACL(&config.Config{
+		AuthFailMax:    3,
+		AuthFailWindow: 1,
+		BanDuration:    1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Record a ban and an auth failure
+	banned := net.ParseIP("198.51.100.7")
+	for i := 0; i < 3; i++ {
+		a.authFailure(banned)
+	}
+	a.authFailure(net.ParseIP("198.51.100.8"))
+	if len(a.deny) != 1 || len(a.failures) != 1 {
+		t.Fatalf("got %v deny entries %v failures, want 1 1",
+			len(a.deny), len(a.failures))
+	}
+
+	// Nothing is pruned before the ban and the window expire
+	a.prune(time.Now())
+	if len(a.deny) != 1 || len(a.failures) != 1 {
+		t.Fatalf("got %v deny entries %v failures, want 1 1",
+			len(a.deny), len(a.failures))
+	}
+
+	// The expired ban and failures are removed
+	a.prune(time.Now().Add(2 * time.Minute))
+	if len(a.deny) != 0 || len(a.failures) != 0 {
+		t.Fatalf("got %v deny entries %v failures, want 0 0",
+			len(a.deny), len(a.failures))
+	}
+}
+
+func TestLoginCredentialsInvalid(t *testing.T) {
+	var tests = []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			"invalid login",
+			www.UserError{ErrorCode: www.ErrorStatusInvalidLogin},
+			true,
+		},
+		{
+			"invalid totp code",
+			www.UserError{ErrorCode: www.ErrorStatusTOTPFailedValidation},
+			true,
+		},
+		{
+			"totp code required",
+			www.UserError{ErrorCode: www.ErrorStatusRequiresTOTPCode},
+			false,
+		},
+		{
+			"user locked",
+			www.UserError{ErrorCode: www.ErrorStatusUserLocked},
+			false,
+		},
+		{
+			"server error",
+			errors.New("database unavailable"),
+			false,
+		},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			got := loginCredentialsInvalid(v.err)
+			if got != v.want {
+				t.Fatalf("got %v, want %v", got, v.want)
+			}
+		})
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/decred/dcrd/dcrutil/v3"
-	"github.com/decred/politeia/cmsplugin"
 	www "github.com/decred/politeia/politeiawww/api/www/v1"
 )
 
@@ -55,6 +54,11 @@ const (
 	RouteProposalBillingSummary = "/proposals/spendingsummary"
 	RouteProposalBillingDetails = "/proposals/spendingdetails"
 	RouteUserCodeStats          = "/user/codestats"
+	RouteDomains                = "/domains"
+	RouteRateSchedules          = "/rateschedules"
+	RouteSetDomain              = "/admin/setdomain"
+	RouteSetRateSchedule        = "/admin/setrateschedule"
+	RouteSetSupervisors         = "/admin/setsupervisors"
 
 	// Invoice status codes
 	InvoiceStatusInvalid  InvoiceStatusT = 0 // Invalid status
@@ -243,6 +247,10 @@ const (
 	ErrorStatusDCCDuplicateVote               www.ErrorStatusT = 1056
 	ErrorStatusMissingCodeStatsUsername       www.ErrorStatusT = 1057
 	ErrorStatusTrackerNotStarted              www.ErrorStatusT = 1058
+	ErrorStatusInvalidDomain                  www.ErrorStatusT = 1059
+	ErrorStatusInvalidRateSchedule            www.ErrorStatusT = 1060
+	ErrorStatusInvalidLineItemDomain          www.ErrorStatusT = 1061
+	ErrorStatusInvalidLineItemSubdomain       www.ErrorStatusT = 1062
 
 	ProposalsMainnet = "https://proposals.decred.org"
 	ProposalsTestnet = "https://test-proposals.decred.org"
@@ -380,6 +388,10 @@ var (
 		ErrorStatusDCCDuplicateVote:               "user has already submitted a vote for the given dcc",
 		ErrorStatusMissingCodeStatsUsername:       "codestats site username is required to receive code stats",
 		ErrorStatusTrackerNotStarted:              "code tracker required for attempted request, check token setting in config",
+		ErrorStatusInvalidDomain:                  "invalid domain",
+		ErrorStatusInvalidRateSchedule:            "invalid rate schedule",
+		ErrorStatusInvalidLineItemDomain:          "line item domain is not a supported domain",
+		ErrorStatusInvalidLineItemSubdomain:       "line item subdomain is not a supported subdomain of the domain",
 	}
 )
 
@@ -389,6 +401,17 @@ type AvailableDomain struct {
 	Type        DomainTypeT `json:"type"`
 }
 
+// Domain contains a contractor domain and the subdomains that line items of
+// the domain may be billed against. Line items of a domain without any
+// subdomains may use any subdomain. Disabled domains are kept for the
+// existing invoices but can no longer be billed against.
+type Domain struct {
+	Type        DomainTypeT `json:"type"`
+	Description string      `json:"description"`
+	Subdomains  []string    `json:"subdomains"`
+	Disabled    bool        `json:"disabled"`
+}
+
 // AvailableLineItemType contains a line item type and it's description
 type AvailableLineItemType struct {
 	Description string        `json:"description"`
@@ -723,6 +746,65 @@ type CMSManageUser struct {
 // CMSManageUserReply is the reply for the CMSManageUserReply command.
 type CMSManageUserReply struct{}
 
+// Domains requests all contractor domains.
+type Domains struct{}
+
+// DomainsReply returns all contractor domains, including the disabled ones.
+type DomainsReply struct {
+	Domains []Domain `json:"domains"`
+}
+
+// SetDomain creates a new contractor domain or updates the existing domain of
+// the same type.
+type SetDomain struct {
+	Domain Domain `json:"domain"`
+}
+
+// SetDomainReply is the reply for the SetDomain command.
+type SetDomainReply struct{}
+
+// RateSchedule contains the minimum and maximum contractor rates of a domain
+// in USD cents. A rate schedule is effective for the invoices of its
+// month/year and of all following months until a newer rate schedule of the
+// domain becomes effective.
+type RateSchedule struct {
+	Domain  DomainTypeT `json:"domain"`
+	Month   uint        `json:"month"`
+	Year    uint        `json:"year"`
+	MinRate uint        `json:"minrate"` // in USD cents
+	MaxRate uint        `json:"maxrate"` // in USD cents
+}
+
+// RateSchedules requests the rate schedules of a domain.
+type RateSchedules struct {
+	Domain DomainTypeT `json:"domain"`
+}
+
+// RateSchedulesReply returns the rate schedules of the requested domain
+// ordered by the month/year that they become effective.
+type RateSchedulesReply struct {
+	RateSchedules []RateSchedule `json:"rateschedules"`
+}
+
+// SetRateSchedule creates a new rate schedule or updates the existing rate
+// schedule of the same domain and month/year.
+type SetRateSchedule struct {
+	RateSchedule RateSchedule `json:"rateschedule"`
+}
+
+// SetRateScheduleReply is the reply for the SetRateSchedule command.
+type SetRateScheduleReply struct{}
+
+// SetSupervisors replaces the supervisors of a user. Supervisors must be
+// supervisor contractors.
+type SetSupervisors struct {
+	UserID            string   `json:"userid"`
+	SupervisorUserIDs []string `json:"supervisoruserids"`
+}
+
+// SetSupervisorsReply is the reply for the SetSupervisors command.
+type SetSupervisorsReply struct{}
+
 // DCCInput contains all of the information concerning a DCC object that
 // will be submitted as a Record to the politeiad backend.
 type DCCInput struct {
@@ -1004,14 +1086,29 @@ type CastVote struct {
 	Signature string `json:"signature"` // Signature of the Token+VoteBit+UserID by the submitting user.
 }
 
+// CastVoteErrorT represents an error that occurred while casting a DCC vote.
+// The error codes are the cmsplugin error codes. They are defined here so that
+// the API types do not depend on the cmsplugin.
+type CastVoteErrorT int
+
+const (
+	CastVoteErrorInvalid          CastVoteErrorT = 0
+	CastVoteErrorInternalError    CastVoteErrorT = 1
+	CastVoteErrorDCCNotFound      CastVoteErrorT = 2
+	CastVoteErrorInvalidVoteBit   CastVoteErrorT = 3
+	CastVoteErrorVoteHasEnded     CastVoteErrorT = 4
+	CastVoteErrorDuplicateVote    CastVoteErrorT = 5
+	CastVoteErrorIneligibleUserID CastVoteErrorT = 6
+)
+
 // CastVoteReply is the answer to the CastVote command. The Error and
 // ErrorStatus fields will only be populated if something went wrong while
 // attempting to cast the vote.
 type CastVoteReply struct {
-	ClientSignature string                 `json:"clientsignature"`       // Signature that was sent in
-	Signature       string                 `json:"signature"`             // Signature of the ClientSignature
-	Error           string                 `json:"error"`                 // Error status message
-	ErrorStatus     cmsplugin.ErrorStatusT `json:"errorstatus,omitempty"` // Error status code
+	ClientSignature string         `json:"clientsignature"`       // Signature that was sent in
+	Signature       string         `json:"signature"`             // Signature of the ClientSignature
+	Error           string         `json:"error"`                 // Error status message
+	ErrorStatus     CastVoteErrorT `json:"errorstatus,omitempty"` // Error status code
 }
 
 // ProposalBillingSummary allows for all proposal spending to be returned for
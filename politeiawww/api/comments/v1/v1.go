@@ -19,6 +19,7 @@ const (
 	RouteComments   = "/comments"
 	RouteVotes      = "/votes"
 	RouteTimestamps = "/timestamps"
+	RouteExport     = "/export"
 )
 
 // ErrorCodeT represents a user error code.
@@ -35,7 +36,12 @@ const (
 	ErrorCodeRecordNotFound     ErrorCodeT = 7
 	ErrorCodeRecordLocked       ErrorCodeT = 8
 	ErrorCodePageSizeExceeded   ErrorCodeT = 9
-	ErrorCodeLast               ErrorCodeT = 10
+	ErrorCodeUserNotEligible    ErrorCodeT = 10
+	ErrorCodeNonceInvalid       ErrorCodeT = 11
+	ErrorCodeNonceExpired       ErrorCodeT = 12
+	ErrorCodeNonceUsed          ErrorCodeT = 13
+	ErrorCodeRateLimitExceeded  ErrorCodeT = 14
+	ErrorCodeLast               ErrorCodeT = 15
 )
 
 var (
@@ -51,6 +57,11 @@ var (
 		ErrorCodeRecordNotFound:     "record not found",
 		ErrorCodeRecordLocked:       "record is locked",
 		ErrorCodePageSizeExceeded:   "page size exceeded",
+		ErrorCodeUserNotEligible:    "user not eligible",
+		ErrorCodeNonceInvalid:       "nonce invalid",
+		ErrorCodeNonceExpired:       "nonce expired",
+		ErrorCodeNonceUsed:          "nonce already used",
+		ErrorCodeRateLimitExceeded:  "rate limit exceeded",
 	}
 )
 
@@ -97,9 +108,58 @@ func (e ServerErrorReply) Error() string {
 type Policy struct{}
 
 // PolicyReply is the reply to the policy command.
+//
+// The AccountAgeMin and Stake fields describe the users that are eligible to
+// submit comments (New) and to vote on comments (Vote). The account age is in
+// days. When Stake is set, users that have verified stake are eligible as
+// well. Admins are always eligible.
+//
+// NonceRequired indicates that the New and Vote commands must include a nonce
+// and an expiry. See ReplayProtection.
+//
+// DepthMax is the maximum nesting depth of a comment. A base level comment has
+// a depth of 1 and a reply has a depth of one more than its parent. Replies
+// that would exceed the maximum depth are rejected. The nesting depth is not
+// limited when DepthMax is 0.
+//
+// BotCommentsPerHour is the maximum number of comments that a bot account can
+// submit per hour. Bot accounts are exempt from the New eligibility
+// requirements. The comments of bot accounts are not limited when
+// BotCommentsPerHour is 0.
 type PolicyReply struct {
-	LengthMax      uint32 `json:"lengthmax"` // In characters
-	VoteChangesMax uint32 `json:"votechangesmax"`
+	LengthMax          uint32 `json:"lengthmax"` // In characters
+	VoteChangesMax     uint32 `json:"votechangesmax"`
+	DepthMax           uint32 `json:"depthmax"`
+	NewAccountAgeMin   uint32 `json:"newaccountagemin,omitempty"`
+	NewStake           bool   `json:"newstake,omitempty"`
+	VoteAccountAgeMin  uint32 `json:"voteaccountagemin,omitempty"`
+	VoteStake          bool   `json:"votestake,omitempty"`
+	NonceRequired      bool   `json:"noncerequired,omitempty"`
+	NonceExpiryMax     int64  `json:"nonceexpirymax"` // In seconds
+	BotCommentsPerHour uint32 `json:"botcommentsperhour,omitempty"`
+}
+
+const (
+	// NonceSize is the size in bytes of a nonce. Nonces are hex encoded.
+	NonceSize = 16
+
+	// NonceExpiryMax is the maximum number of seconds in the future that
+	// the expiry of a nonce can be.
+	NonceExpiryMax int64 = 600
+)
+
+// ReplayProtection protects a signed request from being replayed. The Nonce is
+// a random value of NonceSize bytes that must not be reused and the Expiry is
+// the UNIX timestamp after which the request is rejected. The expiry can be at
+// most NonceExpiryMax seconds in the future. The fields are optional unless
+// the policy requires them.
+//
+// When a nonce is provided the Nonce+Expiry are appended to the message that
+// is signed by the client. The fields are returned along with the comments and
+// the comment votes so that the signatures can be verified.
+type ReplayProtection struct {
+	Nonce  string `json:"nonce,omitempty"`  // Hex encoded random nonce
+	Expiry int64  `json:"expiry,omitempty"` // UNIX timestamp
 }
 
 // RecordStateT represents the state of a record.
@@ -154,6 +214,15 @@ type Comment struct {
 	// Optional fields to be used freely
 	ExtraData     string `json:"extradata,omitempty"`
 	ExtraDataHint string `json:"extradatahint,omitempty"`
+
+	ReplayProtection
+
+	// Thread fields. These are only set when the comments are
+	// requested with the Flatten option. ThreadID is the comment ID
+	// of the base level comment of the thread that the comment is
+	// part of and Depth is the nesting depth of the comment.
+	ThreadID uint32 `json:"threadid,omitempty"`
+	Depth    uint32 `json:"depth,omitempty"`
 }
 
 // CommentVote represents a comment vote (upvote/downvote).
@@ -170,6 +239,8 @@ type CommentVote struct {
 	Signature string       `json:"signature"` // Client signature
 	Timestamp int64        `json:"timestamp"` // Received UNIX timestamp
 	Receipt   string       `json:"receipt"`   // Server sig of client sig
+
+	ReplayProtection
 }
 
 // New creates a new comment.
@@ -186,6 +257,8 @@ type New struct {
 	PublicKey string       `json:"publickey"`
 	Signature string       `json:"signature"`
 
+	ReplayProtection
+
 	// Optional fields to be used freely
 	ExtraData     string `json:"extradata,omitempty"`
 	ExtraDataHint string `json:"extradatahint,omitempty"`
@@ -226,6 +299,8 @@ type Vote struct {
 	Vote      VoteT        `json:"vote"`
 	PublicKey string       `json:"publickey"`
 	Signature string       `json:"signature"`
+
+	ReplayProtection
 }
 
 // VoteReply is the reply to the Vote command.
@@ -273,8 +348,17 @@ type CountReply struct {
 }
 
 // Comments requests a record's comments.
+//
+// The comments are returned as a flat list that clients build the threads
+// from using the ParentID of each comment. When Flatten is set, the comments
+// are returned in thread order instead: each base level comment is followed by
+// all of its replies, at any depth, in the order that they were made. The
+// ThreadID and Depth fields of the comments are set so that clients can render
+// deeply nested threads without building them. The ParentID remains the
+// reference to the comment that was replied to.
 type Comments struct {
-	Token string `json:"token"`
+	Token   string `json:"token"`
+	Flatten bool   `json:"flatten,omitempty"`
 }
 
 // CommentsReply is the reply to the comments command.
@@ -349,9 +433,13 @@ type CommentTimestamp struct {
 }
 
 // Timestamps requests the timestamps for the comments of a record.
+//
+// This route can also be requested using a GET request with the fields as
+// query params. The reply to a GET request includes an ETag header and a 304
+// Not Modified is returned when the ETag matches the If-None-Match header.
 type Timestamps struct {
-	Token      string   `json:"token"`
-	CommentIDs []uint32 `json:"commentids"`
+	Token      string   `json:"token" schema:"token"`
+	CommentIDs []uint32 `json:"commentids" schema:"commentids"`
 }
 
 // TimestampsReply is the reply to the Timestamps command.
@@ -359,3 +447,43 @@ type TimestampsReply struct {
 	// map[commentID]CommentTimestamp
 	Comments map[uint32]CommentTimestamp `json:"comments"`
 }
+
+// Export requests a signed archive of the full comment thread of a public
+// record. The archive contains all comments and comment timestamps and can
+// be verified offline.
+type Export struct {
+	Token string `json:"token"`
+}
+
+// ExportManifest describes the contents of a comments export. The digests are
+// the hex encoded SHA256 digests of the JSON encoded ExportReply fields.
+//
+// PoliteiadPubKey is the politeiad public key that was used to sign the
+// comment receipts. ServerPubKey is the politeiawww public key that was used
+// to sign the manifest. PrevServerPubKey is only set while the politeiawww
+// signing identity is being rotated and is the previous politeiawww public
+// key.
+type ExportManifest struct {
+	Token            string `json:"token"`
+	Timestamp        int64  `json:"timestamp"` // UNIX time of export
+	CommentsCount    uint32 `json:"commentscount"`
+	CommentsDigest   string `json:"commentsdigest"`
+	TimestampsDigest string `json:"timestampsdigest"`
+	PoliteiadPubKey  string `json:"politeiadpubkey"`
+	ServerPubKey     string `json:"serverpubkey"`
+	PrevServerPubKey string `json:"prevserverpubkey,omitempty"`
+}
+
+// ExportReply is the reply to the Export command.
+//
+// Signature is the politeiawww signature of the hex encoded SHA256 digest of
+// the JSON encoded manifest. PrevSignature is the signature of the same
+// digest using the previous politeiawww signing identity. It is only set while
+// the signing identity is being rotated.
+type ExportReply struct {
+	Manifest      ExportManifest              `json:"manifest"`
+	Signature     string                      `json:"signature"`
+	PrevSignature string                      `json:"prevsignature,omitempty"`
+	Comments      []Comment                   `json:"comments"`
+	Timestamps    map[uint32]CommentTimestamp `json:"timestamps"`
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package v1
+
+import "fmt"
+
+const (
+	// APIRoute is prefixed onto all routes defined in this package.
+	APIRoute = "/eventlog/v1"
+
+	// RouteEvents returns the recorded events for a time range. This
+	// route is admin only.
+	RouteEvents = "/events"
+
+	// RouteReplay replays the recorded events of a time range into the
+	// notification pipeline. This route is admin only.
+	RouteReplay = "/replay"
+)
+
+// ErrorCodeT represents a user error code.
+type ErrorCodeT uint32
+
+const (
+	// Error codes
+	ErrorCodeInvalid          ErrorCodeT = 0
+	ErrorCodeInputInvalid     ErrorCodeT = 1
+	ErrorCodeTimeRangeInvalid ErrorCodeT = 2
+	ErrorCodeEventTypeInvalid ErrorCodeT = 3
+	ErrorCodeLast             ErrorCodeT = 4
+)
+
+var (
+	// ErrorCodes contains the human readable errors.
+	ErrorCodes = map[ErrorCodeT]string{
+		ErrorCodeInvalid:          "error invalid",
+		ErrorCodeInputInvalid:     "input invalid",
+		ErrorCodeTimeRangeInvalid: "time range invalid",
+		ErrorCodeEventTypeInvalid: "event type invalid",
+	}
+)
+
+// UserErrorReply is the reply that the server returns when it encounters an
+// error that is caused by something that the user did (malformed input, bad
+// timing, etc). The HTTP status code will be 400.
+type UserErrorReply struct {
+	ErrorCode    ErrorCodeT `json:"errorcode"`
+	ErrorContext string     `json:"errorcontext,omitempty"`
+}
+
+// Error satisfies the error interface.
+func (e UserErrorReply) Error() string {
+	return fmt.Sprintf("user error code: %v", e.ErrorCode)
+}
+
+// ServerErrorReply is the reply that the server returns when it encounters an
+// unrecoverable error while executing a command. The HTTP status code will be
+// 500 and the ErrorCode field will contain a UNIX timestamp that the user can
+// provide to the server admin to track down the error details in the logs.
+type ServerErrorReply struct {
+	ErrorCode int64 `json:"errorcode"`
+}
+
+// Error satisfies the error interface.
+func (e ServerErrorReply) Error() string {
+	return fmt.Sprintf("server error: %v", e.ErrorCode)
+}
+
+// Event is an event that was emitted by politeiawww and recorded in the
+// append-only event log.
+//
+// ID is the sequence number of the event in the log. Token is the token of
+// the record that the event applies to and Actor is the ID of the user that
+// triggered the event. Both are empty when they do not apply to the event.
+// Digest is the hex encoded SHA256 digest of the recorded event payload. The
+// payload itself is not returned since it can contain user data.
+type Event struct {
+	ID        uint64 `json:"id"`
+	Type      string `json:"type"`
+	Token     string `json:"token,omitempty"`
+	Actor     string `json:"actor,omitempty"`
+	Timestamp int64  `json:"timestamp"` // UNIX timestamp
+	Digest    string `json:"digest"`
+}
+
+const (
+	// EventsPageSize is the maximum number of events that are returned
+	// by the Events command.
+	EventsPageSize uint32 = 100
+)
+
+// Events requests the events that were recorded in the provided time range.
+// The From and To UNIX timestamps are inclusive. To defaults to the current
+// time when it is not set. The results can be filtered by event type and by
+// record token.
+//
+// The events are returned in the order that they were recorded and are
+// paginated using EventsPageSize. The next page is requested by setting After
+// to the ID of the last event of the previous page.
+type Events struct {
+	From  int64  `json:"from"`
+	To    int64  `json:"to,omitempty"`
+	Type  string `json:"type,omitempty"`
+	Token string `json:"token,omitempty"`
+	After uint64 `json:"after,omitempty"`
+}
+
+// EventsReply is the reply to the Events command.
+type EventsReply struct {
+	Events []Event `json:"events"`
+}
+
+// Replay replays the events that were recorded in the provided time range
+// into the notification pipeline. The From and To UNIX timestamps are
+// inclusive. To defaults to the current time when it is not set. Only the
+// event types that are included in Types are replayed. All event types are
+// replayed when Types is empty.
+//
+// Replayed events are processed again by all event listeners, i.e. the
+// notifications of the events are sent again. Replayed events are not
+// recorded in the event log a second time.
+type Replay struct {
+	From  int64    `json:"from"`
+	To    int64    `json:"to,omitempty"`
+	Types []string `json:"types,omitempty"`
+}
+
+// ReplayReply is the reply to the Replay command. Replayed contains the IDs
+// of the events that were replayed.
+type ReplayReply struct {
+	Replayed []uint64 `json:"replayed"`
+}
@@ -0,0 +1,11 @@
+module github.com/decred/politeia/politeiawww/api
+
+go 1.15
+
+require (
+	github.com/decred/dcrd/crypto/blake256 v1.0.1-0.20200921185235-6d75c7ec1199 // indirect
+	github.com/decred/dcrd/dcrutil/v3 v3.0.0
+	github.com/decred/politeia/unittest v1.0.0
+)
+
+replace github.com/decred/politeia/unittest => ../../unittest
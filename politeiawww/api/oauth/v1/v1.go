@@ -0,0 +1,431 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package v1
+
+import "fmt"
+
+const (
+	// APIRoute is prefixed onto all routes defined in this package.
+	APIRoute = "/oauth/v1"
+
+	// RouteClient returns the details of a third-party application that
+	// the GUI displays on the consent screen.
+	RouteClient = "/client"
+
+	// RouteAuthorize records the consent of the logged in user and
+	// returns an authorization code. This route requires a login.
+	RouteAuthorize = "/authorize"
+
+	// RouteToken is the OAuth2 token endpoint. It exchanges an
+	// authorization code or a refresh token for an access token. The
+	// request is form encoded as described in RFC 6749.
+	RouteToken = "/token"
+
+	// RouteMe returns the public profile of the user that authorized
+	// the access token. It requires the ScopeProfile scope.
+	RouteMe = "/me"
+
+	// RouteProposals returns the tokens of the proposals of the user
+	// that authorized the access token. It requires the ScopeProposals
+	// scope.
+	RouteProposals = "/proposals"
+
+	// RouteGrants returns the applications that the logged in user has
+	// authorized. This route requires a login.
+	RouteGrants = "/grants"
+
+	// RouteRevoke revokes the authorization of an application. This
+	// route requires a login.
+	RouteRevoke = "/revoke"
+
+	// RouteIntrospect is the OAuth2 token introspection endpoint. It
+	// returns whether an access token or a refresh token is active. The
+	// request is form encoded and the application must authenticate as
+	// described in RFC 7662.
+	RouteIntrospect = "/introspect"
+
+	// RouteUserInfo is the OpenID Connect userinfo endpoint. It returns
+	// the claims of the user that authorized the access token. It
+	// requires the ScopeOpenID scope.
+	RouteUserInfo = "/userinfo"
+
+	// RouteJWKS returns the public keys that are used to sign the
+	// OpenID Connect ID tokens.
+	RouteJWKS = "/jwks"
+
+	// RouteClients returns all applications. This route requires an
+	// admin login.
+	RouteClients = "/clients"
+
+	// RouteRegisterClient registers a new application. This route
+	// requires an admin login.
+	RouteRegisterClient = "/clients/register"
+
+	// RouteDeleteClient deletes an application that was registered
+	// using the RegisterClient route. This route requires an admin
+	// login.
+	RouteDeleteClient = "/clients/delete"
+)
+
+const (
+	// RouteDiscovery is the OpenID Connect discovery document. It is
+	// not prefixed with the APIRoute. It is served relative to the
+	// issuer as described in the OpenID Connect Discovery spec.
+	RouteDiscovery = "/.well-known/openid-configuration"
+
+	// ConsentPath is the path of the GUI consent screen, relative to
+	// the issuer. It is advertised as the authorization endpoint in the
+	// discovery document. The GUI reads the OAuth2 query parameters,
+	// asks the user for consent, and sends the Authorize command.
+	ConsentPath = "/oauth/authorize"
+)
+
+const (
+	// ScopeProfile grants access to the public profile of the user, i.e.
+	// the user ID and username.
+	ScopeProfile = "profile"
+
+	// ScopeProposals grants access to the tokens of the proposals of
+	// the user, including the proposals that have not been made public
+	// yet.
+	ScopeProposals = "proposals"
+
+	// ScopeOpenID allows the application to sign the user in. An ID
+	// token is issued along with the access token. This scope is only
+	// available when the server is configured as an OpenID Connect
+	// identity provider.
+	ScopeOpenID = "openid"
+
+	// ScopeEmail adds the email address of the user to the ID token and
+	// to the userinfo reply. This scope is only available when the
+	// server is configured as an OpenID Connect identity provider.
+	ScopeEmail = "email"
+)
+
+var (
+	// Scopes contains the human readable descriptions of the scopes that
+	// an application can request.
+	Scopes = map[string]string{
+		ScopeProfile:   "View your user ID and username",
+		ScopeProposals: "View the list of proposals that you have submitted",
+		ScopeOpenID:    "Sign in using your politeia account",
+		ScopeEmail:     "View your email address",
+	}
+)
+
+// ErrorCodeT represents a user error code.
+type ErrorCodeT uint32
+
+const (
+	// Error codes
+	ErrorCodeInvalid            ErrorCodeT = 0
+	ErrorCodeInputInvalid       ErrorCodeT = 1
+	ErrorCodeClientInvalid      ErrorCodeT = 2
+	ErrorCodeRedirectURIInvalid ErrorCodeT = 3
+	ErrorCodeScopeInvalid       ErrorCodeT = 4
+	ErrorCodeGrantNotFound      ErrorCodeT = 5
+	ErrorCodeLast               ErrorCodeT = 6
+)
+
+var (
+	// ErrorCodes contains the human readable errors.
+	ErrorCodes = map[ErrorCodeT]string{
+		ErrorCodeInvalid:            "error invalid",
+		ErrorCodeInputInvalid:       "input invalid",
+		ErrorCodeClientInvalid:      "client invalid",
+		ErrorCodeRedirectURIInvalid: "redirect uri invalid",
+		ErrorCodeScopeInvalid:       "scope invalid",
+		ErrorCodeGrantNotFound:      "grant not found",
+	}
+)
+
+// UserErrorReply is the reply that the server returns when it encounters an
+// error that is caused by something that the user did (malformed input, bad
+// timing, etc). The HTTP status code will be 400.
+type UserErrorReply struct {
+	ErrorCode    ErrorCodeT `json:"errorcode"`
+	ErrorContext string     `json:"errorcontext,omitempty"`
+}
+
+// Error satisfies the error interface.
+func (e UserErrorReply) Error() string {
+	return fmt.Sprintf("user error code: %v", e.ErrorCode)
+}
+
+// ServerErrorReply is the reply that the server returns when it encounters an
+// unrecoverable error while executing a command. The HTTP status code will be
+// 500 and the ErrorCode field will contain a UNIX timestamp that the user can
+// provide to the server admin to track down the error details in the logs.
+type ServerErrorReply struct {
+	ErrorCode int64 `json:"errorcode"`
+}
+
+// Error satisfies the error interface.
+func (e ServerErrorReply) Error() string {
+	return fmt.Sprintf("server error: %v", e.ErrorCode)
+}
+
+// The following are the OAuth2 error codes that are returned by the token
+// endpoint and by the routes that require an access token. See RFC 6749
+// section 5.2 and RFC 6750 section 3.1.
+const (
+	ErrorInvalidRequest       = "invalid_request"
+	ErrorInvalidClient        = "invalid_client"
+	ErrorInvalidGrant         = "invalid_grant"
+	ErrorUnsupportedGrantType = "unsupported_grant_type"
+	ErrorInvalidToken         = "invalid_token"
+	ErrorInsufficientScope    = "insufficient_scope"
+)
+
+// ErrorReply is the reply that the token endpoint and the routes that require
+// an access token return on error. It uses the OAuth2 error format so that
+// standard OAuth2 client libraries can be used.
+type ErrorReply struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// The following are the OAuth2 grant types that the token endpoint supports.
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeRefreshToken      = "refresh_token"
+)
+
+// CodeChallengeMethodS256 is the only supported PKCE code challenge method.
+// See RFC 7636.
+const CodeChallengeMethodS256 = "S256"
+
+// The following are the token type hints that can be provided to the
+// introspection endpoint. See RFC 7009 section 2.1.
+const (
+	TokenTypeHintAccessToken  = "access_token"
+	TokenTypeHintRefreshToken = "refresh_token"
+)
+
+// SigningAlgRS256 is the algorithm that is used to sign the ID tokens.
+const SigningAlgRS256 = "RS256"
+
+// Client requests the details of a third-party application. The redirect URI
+// must match the redirect URI that the application has been registered with.
+type Client struct {
+	ClientID    string `json:"clientid"`
+	RedirectURI string `json:"redirecturi"`
+}
+
+// ScopeDetails describes a scope.
+type ScopeDetails struct {
+	Scope       string `json:"scope"`
+	Description string `json:"description"`
+}
+
+// ClientReply is the reply to the Client command. Granted contains the scopes
+// that the user has already authorized the application for, if a user is
+// logged in.
+type ClientReply struct {
+	ClientID string         `json:"clientid"`
+	Name     string         `json:"name"`
+	Scopes   []ScopeDetails `json:"scopes"` // All available scopes
+	Granted  []string       `json:"granted,omitempty"`
+}
+
+// Authorize records the consent of the logged in user to give an application
+// access to the requested scopes. It is sent by the GUI once the user has
+// approved the consent screen.
+//
+// Scope is a space separated list of scopes. The State is returned unchanged
+// in the redirect URI. Applications that do not have a client secret must use
+// PKCE by providing a code challenge. The Nonce is returned unchanged in the
+// ID token when the ScopeOpenID scope is requested.
+type Authorize struct {
+	ClientID            string `json:"clientid"`
+	RedirectURI         string `json:"redirecturi"`
+	Scope               string `json:"scope"`
+	State               string `json:"state,omitempty"`
+	Nonce               string `json:"nonce,omitempty"`
+	CodeChallenge       string `json:"codechallenge,omitempty"`
+	CodeChallengeMethod string `json:"codechallengemethod,omitempty"`
+}
+
+// AuthorizeReply is the reply to the Authorize command. RedirectURI is the
+// registered redirect URI of the application with the code and state query
+// parameters added. The GUI redirects the user to it. The code expires after
+// one minute and can only be used once.
+type AuthorizeReply struct {
+	Code        string `json:"code"`
+	RedirectURI string `json:"redirecturi"`
+}
+
+// TokenReply is the reply of the token endpoint. See RFC 6749 section 5.1.
+// The refresh token is rotated on every use. The ID token is only returned
+// when the grant includes the ScopeOpenID scope.
+type TokenReply struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"` // Always "Bearer"
+	ExpiresIn    int64  `json:"expires_in"` // In seconds
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"` // Space separated
+	IDToken      string `json:"id_token,omitempty"`
+}
+
+// IDTokenClaims contains the claims of an ID token. The ID token is a JWT
+// that is signed using RS256. The signature can be verified using the keys
+// that are returned by the JWKS route. The subject is the user ID. The email
+// claims are only included when the grant includes the ScopeEmail scope. See
+// OpenID Connect Core section 2.
+type IDTokenClaims struct {
+	Issuer            string `json:"iss"`
+	Subject           string `json:"sub"`
+	Audience          string `json:"aud"` // Client ID
+	Expires           int64  `json:"exp"` // UNIX time
+	IssuedAt          int64  `json:"iat"` // UNIX time
+	Nonce             string `json:"nonce,omitempty"`
+	PreferredUsername string `json:"preferred_username"`
+	Email             string `json:"email,omitempty"`
+	EmailVerified     bool   `json:"email_verified,omitempty"`
+}
+
+// IntrospectReply is the reply of the introspection endpoint. See RFC 7662
+// section 2.2. Only the Active field is set when the token is not active. A
+// token is reported as not active when it was issued to another application.
+// The expiry is not set for refresh tokens, which do not expire.
+type IntrospectReply struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"` // Space separated
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"` // Token type hint
+	Expires   int64  `json:"exp,omitempty"`        // UNIX time
+	IssuedAt  int64  `json:"iat,omitempty"`        // UNIX time
+	Subject   string `json:"sub,omitempty"`        // User ID
+}
+
+// UserInfoReply is the reply to the UserInfo command. See OpenID Connect
+// Core section 5.3. The email claims are only included when the access token
+// has been granted the ScopeEmail scope.
+type UserInfoReply struct {
+	Subject           string `json:"sub"`
+	PreferredUsername string `json:"preferred_username"`
+	Email             string `json:"email,omitempty"`
+	EmailVerified     bool   `json:"email_verified,omitempty"`
+}
+
+// JWK is an RSA public key in the JSON Web Key format. See RFC 7517.
+type JWK struct {
+	KeyType   string `json:"kty"` // Always "RSA"
+	Use       string `json:"use"` // Always "sig"
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n"` // Base64url encoded modulus
+	E         string `json:"e"` // Base64url encoded exponent
+}
+
+// JWKSReply is the reply to the JWKS command.
+type JWKSReply struct {
+	Keys []JWK `json:"keys"`
+}
+
+// DiscoveryReply is the OpenID Connect discovery document. See OpenID
+// Connect Discovery section 3.
+type DiscoveryReply struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+// MeReply is the reply to the Me command.
+type MeReply struct {
+	UserID   string `json:"userid"`
+	Username string `json:"username"`
+}
+
+// ProposalsReply is the reply to the Proposals command.
+type ProposalsReply struct {
+	Unvetted []string `json:"unvetted"`
+	Vetted   []string `json:"vetted"`
+}
+
+// Grant describes an application that a user has authorized.
+type Grant struct {
+	ClientID  string   `json:"clientid"`
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	Timestamp int64    `json:"timestamp"` // UNIX time of the last consent
+}
+
+// Grants requests the applications that the logged in user has authorized.
+type Grants struct{}
+
+// GrantsReply is the reply to the Grants command.
+type GrantsReply struct {
+	Grants []Grant `json:"grants"`
+}
+
+// Revoke revokes the authorization of an application. All access tokens and
+// refresh tokens that were issued to the application for the logged in user
+// are invalidated immediately.
+type Revoke struct {
+	ClientID string `json:"clientid"`
+}
+
+// RevokeReply is the reply to the Revoke command.
+type RevokeReply struct{}
+
+// ClientDetails describes a third-party application. Configured is set for
+// the applications that are configured in the politeiawww config file. These
+// applications can not be deleted using the API. Applications that have a
+// client secret are confidential.
+type ClientDetails struct {
+	ClientID     string `json:"clientid"`
+	Name         string `json:"name"`
+	RedirectURI  string `json:"redirecturi"`
+	Confidential bool   `json:"confidential"`
+	Configured   bool   `json:"configured"`
+	Timestamp    int64  `json:"timestamp,omitempty"` // UNIX time of registration
+}
+
+// Clients requests all applications.
+type Clients struct{}
+
+// ClientsReply is the reply to the Clients command.
+type ClientsReply struct {
+	Clients []ClientDetails `json:"clients"`
+}
+
+// RegisterClient registers a new third-party application. A client secret
+// is created for confidential applications, i.e. applications that run on a
+// server. Public applications, such as mobile and browser apps, must use
+// PKCE instead.
+type RegisterClient struct {
+	Name         string `json:"name"`
+	RedirectURI  string `json:"redirecturi"`
+	Confidential bool   `json:"confidential"`
+}
+
+// RegisterClientReply is the reply to the RegisterClient command. The client
+// secret is only returned once. Only its hash is stored by the server.
+type RegisterClientReply struct {
+	ClientID     string `json:"clientid"`
+	ClientSecret string `json:"clientsecret,omitempty"`
+}
+
+// DeleteClient deletes a registered application. All grants that users have
+// given to the application are revoked.
+type DeleteClient struct {
+	ClientID string `json:"clientid"`
+}
+
+// DeleteClientReply is the reply to the DeleteClient command.
+type DeleteClientReply struct{}
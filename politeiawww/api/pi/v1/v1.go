@@ -4,14 +4,166 @@
 
 package v1
 
+import "fmt"
+
 const (
 	// APIRoute is prefixed onto all routes defined in this package.
 	APIRoute = "/pi/v1"
 
 	// RoutePolicy returns the policy for the pi API.
 	RoutePolicy = "/policy"
+
+	// RouteSimilar returns the existing proposals that are similar to
+	// a submitted proposal. This route is admin only.
+	RouteSimilar = "/similar"
+
+	// RoutePreflight returns the existing proposals that are similar
+	// to a proposal that has not been submitted yet.
+	RoutePreflight = "/preflight"
+
+	// RouteSetAuthorUpdate sets the author update of a proposal.
+	RouteSetAuthorUpdate = "/setauthorupdate"
+
+	// RouteAuthorUpdates returns all versions of the author update of a
+	// proposal.
+	RouteAuthorUpdates = "/authorupdates"
+
+	// RouteWalletSummaries returns the proposal details that are
+	// required by wallet integrations in a single call.
+	RouteWalletSummaries = "/walletsummaries"
+
+	// RouteVettingQueue returns the proposals that are awaiting vetting.
+	// This route is admin only.
+	RouteVettingQueue = "/vettingqueue"
+
+	// RouteVettingAssign assigns a reviewer to a proposal that is
+	// awaiting vetting. This route is admin only.
+	RouteVettingAssign = "/vettingassign"
+
+	// RouteReportNew submits a legal or abuse report about a public
+	// proposal or comment.
+	RouteReportNew = "/reportnew"
+
+	// RouteReports returns the submitted reports. This route is admin
+	// only.
+	RouteReports = "/reports"
+
+	// RouteReportResolve resolves or dismisses a report. This route is
+	// admin only.
+	RouteReportResolve = "/reportresolve"
+
+	// RouteFeedProposals returns an RSS or Atom feed of the proposals
+	// that have been made public.
+	RouteFeedProposals = "/feeds/proposals"
+
+	// RouteFeedVotes returns an RSS or Atom feed of the proposals whose
+	// vote has started.
+	RouteFeedVotes = "/feeds/votes"
+
+	// RouteFeedComments returns an RSS or Atom feed of the comments of
+	// a public proposal.
+	RouteFeedComments = "/feeds/comments/{token:[A-Fa-f0-9]{7,64}}"
+
+	// RouteSearch searches the public proposals and their comments.
+	RouteSearch = "/search"
+
+	// RouteRFP returns an RFP proposal and its submissions.
+	RouteRFP = "/rfp"
+
+	// RouteRFPParent returns the RFP proposal that a submission links
+	// to.
+	RouteRFPParent = "/rfpparent"
+
+	// RouteRFPRepair repairs the submissions list of an RFP proposal.
+	// This route is admin only.
+	RouteRFPRepair = "/rfprepair"
+
+	// RouteLineage returns the proposals that a proposal is a
+	// resubmission of along with their vote results.
+	RouteLineage = "/lineage"
+)
+
+// ErrorCodeT represents a user error code.
+type ErrorCodeT uint32
+
+const (
+	// Error codes
+	ErrorCodeInvalid             ErrorCodeT = 0
+	ErrorCodeInputInvalid        ErrorCodeT = 1
+	ErrorCodeTokenInvalid        ErrorCodeT = 2
+	ErrorCodeRecordNotFound      ErrorCodeT = 3
+	ErrorCodePublicKeyInvalid    ErrorCodeT = 4
+	ErrorCodeRecordLocked        ErrorCodeT = 5
+	ErrorCodePageSizeExceeded    ErrorCodeT = 6
+	ErrorCodeRecordStatusInvalid ErrorCodeT = 7
+	ErrorCodeUserNotFound        ErrorCodeT = 8
+	ErrorCodeCommentNotFound     ErrorCodeT = 9
+	ErrorCodeReportNotFound      ErrorCodeT = 10
+	ErrorCodeReportStatusInvalid ErrorCodeT = 11
+	ErrorCodeNotRFP              ErrorCodeT = 12
+	ErrorCodeNotRFPSubmission    ErrorCodeT = 13
+	ErrorCodeLast                ErrorCodeT = 14
+)
+
+var (
+	// ErrorCodes contains the human readable errors.
+	ErrorCodes = map[ErrorCodeT]string{
+		ErrorCodeInvalid:             "error invalid",
+		ErrorCodeInputInvalid:        "input invalid",
+		ErrorCodeTokenInvalid:        "token invalid",
+		ErrorCodeRecordNotFound:      "record not found",
+		ErrorCodePublicKeyInvalid:    "public key invalid",
+		ErrorCodeRecordLocked:        "record is locked",
+		ErrorCodePageSizeExceeded:    "page size exceeded",
+		ErrorCodeRecordStatusInvalid: "record status invalid",
+		ErrorCodeUserNotFound:        "user not found",
+		ErrorCodeCommentNotFound:     "comment not found",
+		ErrorCodeReportNotFound:      "report not found",
+		ErrorCodeReportStatusInvalid: "report status invalid",
+		ErrorCodeNotRFP:              "proposal is not an rfp",
+		ErrorCodeNotRFPSubmission:    "proposal is not an rfp submission",
+	}
 )
 
+// UserErrorReply is the reply that the server returns when it encounters an
+// error that is caused by something that the user did (malformed input, bad
+// timing, etc). The HTTP status code will be 400.
+type UserErrorReply struct {
+	ErrorCode    ErrorCodeT `json:"errorcode"`
+	ErrorContext string     `json:"errorcontext,omitempty"`
+}
+
+// Error satisfies the error interface.
+func (e UserErrorReply) Error() string {
+	return fmt.Sprintf("user error code: %v", e.ErrorCode)
+}
+
+// PluginErrorReply is the reply that the server returns when it encounters
+// a plugin error.
+type PluginErrorReply struct {
+	PluginID     string `json:"pluginid"`
+	ErrorCode    uint32 `json:"errorcode"`
+	ErrorContext string `json:"errorcontext,omitempty"`
+}
+
+// Error satisfies the error interface.
+func (e PluginErrorReply) Error() string {
+	return fmt.Sprintf("plugin %v error code: %v", e.PluginID, e.ErrorCode)
+}
+
+// ServerErrorReply is the reply that the server returns when it encounters an
+// unrecoverable error while executing a command. The HTTP status code will be
+// 500 and the ErrorCode field will contain a UNIX timestamp that the user can
+// provide to the server admin to track down the error details in the logs.
+type ServerErrorReply struct {
+	ErrorCode int64 `json:"errorcode"`
+}
+
+// Error satisfies the error interface.
+func (e ServerErrorReply) Error() string {
+	return fmt.Sprintf("server error: %v", e.ErrorCode)
+}
+
 // Policy requests the policy settings for the pi API. It includes the policy
 // guidlines for the contents of a proposal record.
 type Policy struct{}
@@ -24,6 +176,23 @@ type PolicyReply struct {
 	NameLengthMin      uint32   `json:"namelengthmin"`    // In characters
 	NameLengthMax      uint32   `json:"namelengthmax"`    // In characters
 	NameSupportedChars []string `json:"namesupportedchars"`
+
+	// SimilarityThreshold is the minimum similarity score, from 0 to
+	// 1, that a proposal must have with another proposal in order for
+	// it to be considered a likely duplicate.
+	SimilarityThreshold float64 `json:"similaritythreshold"`
+
+	// AuthorUpdateLengthMax is the maximum number of characters that
+	// an author update can be.
+	AuthorUpdateLengthMax uint32 `json:"authorupdatelengthmax"`
+
+	// VettingSLA is the number of seconds that a proposal can await
+	// vetting before it is flagged as an SLA breach.
+	VettingSLA int64 `json:"vettingsla"`
+
+	// ReportReasonLengthMax is the maximum number of characters that
+	// the reason and the resolution of a report can be.
+	ReportReasonLengthMax uint32 `json:"reportreasonlengthmax"`
 }
 
 const (
@@ -45,8 +214,13 @@ const (
 
 // ProposalMetadata contains metadata that is specified by the user on proposal
 // submission.
+//
+// Resubmits is the token of a proposal that was rejected by the ticket vote
+// and that the proposal is a resubmission of. The rejected proposal must have
+// been submitted by the same user. The reference is validated by the server.
 type ProposalMetadata struct {
-	Name string `json:"name"` // Proposal name
+	Name      string `json:"name"`                // Proposal name
+	Resubmits string `json:"resubmits,omitempty"` // Resubmitted proposal
 }
 
 // VoteMetadata is metadata that is specified by the user on proposal
@@ -63,3 +237,541 @@ type VoteMetadata struct {
 	// in the runoff vote.
 	LinkTo string `json:"linkto,omitempty"`
 }
+
+// SimilarProposal describes an existing proposal that is similar to the
+// proposal being checked. Score is a value between 0 and 1 where 1 means that
+// the proposal names and index files are identical.
+type SimilarProposal struct {
+	Token string  `json:"token"`
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+// Similar requests the existing proposals that are similar to the proposal
+// with the provided token. Only proposals with a similarity score that meets
+// the policy SimilarityThreshold are returned.
+type Similar struct {
+	Token string `json:"token"`
+}
+
+// SimilarReply is the reply to the Similar command. The proposals are sorted
+// by score from highest to lowest.
+type SimilarReply struct {
+	Proposals []SimilarProposal `json:"proposals"`
+}
+
+// Preflight requests the existing proposals that are similar to a proposal
+// that has not been submitted yet. This allows an author to check for
+// duplicates before submitting a proposal. Index is the contents of the
+// proposal index file. Users that are not admins are only returned vetted
+// public proposals.
+type Preflight struct {
+	Name  string `json:"name"`
+	Index string `json:"index"`
+}
+
+// PreflightReply is the reply to the Preflight command. The proposals are
+// sorted by score from highest to lowest.
+type PreflightReply struct {
+	Proposals []SimilarProposal `json:"proposals"`
+}
+
+// AuthorUpdate is a pinned section of a proposal that is maintained by the
+// proposal author. Clients display the most recent version above the proposal
+// comments. Setting the author update creates a new version of it. All
+// previous versions are kept.
+//
+// Signature is the client signature of the Token+Update. Receipt is the
+// server signature of the client signature.
+type AuthorUpdate struct {
+	UserID    string `json:"userid"`    // Unique user ID
+	Username  string `json:"username"`  // Username
+	Token     string `json:"token"`     // Proposal token
+	Update    string `json:"update"`    // Author update text
+	PublicKey string `json:"publickey"` // Pubkey used for Signature
+	Signature string `json:"signature"` // Client signature
+	Version   uint32 `json:"version"`   // Author update version
+	Timestamp int64  `json:"timestamp"` // Received UNIX timestamp
+	Receipt   string `json:"receipt"`   // Server signature of client signature
+}
+
+// SetAuthorUpdate sets the author update of a proposal. Only the proposal
+// author is allowed to set the author update and only once the proposal has
+// been made public.
+//
+// Signature is the client signature of the Token+Update.
+type SetAuthorUpdate struct {
+	Token     string `json:"token"`
+	Update    string `json:"update"`
+	PublicKey string `json:"publickey"`
+	Signature string `json:"signature"`
+}
+
+// SetAuthorUpdateReply is the reply to the SetAuthorUpdate command.
+type SetAuthorUpdateReply struct {
+	AuthorUpdate AuthorUpdate `json:"authorupdate"`
+}
+
+// AuthorUpdates requests all versions of the author update of a proposal.
+type AuthorUpdates struct {
+	Token string `json:"token"`
+}
+
+// AuthorUpdatesReply is the reply to the AuthorUpdates command. The updates
+// are ordered from oldest version to newest version. An empty slice is
+// returned if the author update has never been set.
+type AuthorUpdatesReply struct {
+	Updates []AuthorUpdate `json:"updates"`
+}
+
+const (
+	// WalletSummariesPageSize is the maximum number of wallet summaries
+	// that can be requested at any one time.
+	WalletSummariesPageSize uint32 = 20
+
+	// WalletDescriptionLengthMax is the maximum number of characters of
+	// the proposal index file that are included in a wallet summary
+	// description.
+	WalletDescriptionLengthMax = 300
+
+	// WalletSummariesCacheTTL is the number of seconds that a wallet
+	// summary is cached by the server.
+	WalletSummariesCacheTTL = 60
+)
+
+// WalletVoteResult describes a vote option and the total number of votes that
+// have been cast for the option.
+type WalletVoteResult struct {
+	ID          string `json:"id"`          // Single unique word (e.g. yes)
+	Description string `json:"description"` // Longer description of the vote
+	VoteBit     uint64 `json:"votebit"`     // Bits used for this option
+	Votes       uint64 `json:"votes"`       // Votes cast for this option
+}
+
+// WalletVote contains the vote parameters and results of a proposal. Status
+// and Type are the ticketvote v1 VoteStatusT and VoteT. The remaining fields
+// are only populated once the vote has been started.
+type WalletVote struct {
+	Status           uint32             `json:"status"`
+	Type             uint32             `json:"type,omitempty"`
+	Duration         uint32             `json:"duration,omitempty"`
+	StartBlockHeight uint32             `json:"startblockheight,omitempty"`
+	StartBlockHash   string             `json:"startblockhash,omitempty"`
+	EndBlockHeight   uint32             `json:"endblockheight,omitempty"`
+	EligibleTickets  uint32             `json:"eligibletickets,omitempty"`
+	QuorumPercentage uint32             `json:"quorumpercentage,omitempty"`
+	PassPercentage   uint32             `json:"passpercentage,omitempty"`
+	Results          []WalletVoteResult `json:"results,omitempty"`
+	BestBlock        uint32             `json:"bestblock"`
+}
+
+// WalletSummary contains the details of a proposal that are displayed by
+// wallet integrations. Description contains the start of the proposal index
+// file, truncated to WalletDescriptionLengthMax characters.
+type WalletSummary struct {
+	Token        string     `json:"token"`
+	Name         string     `json:"name"`
+	Username     string     `json:"username"`
+	Timestamp    int64      `json:"timestamp"` // Last update
+	Description  string     `json:"description"`
+	CommentCount uint32     `json:"commentcount"`
+	Vote         WalletVote `json:"vote"`
+}
+
+// WalletSummaries requests the wallet summaries of the provided public
+// proposals. This allows a wallet to retrieve everything that it displays for
+// a proposal in a single call. Tokens that do not correspond to a public
+// proposal are not included in the reply.
+//
+// The replies are cached by the server for a short period of time and the
+// summaries may be up to WalletSummariesCacheTTL seconds old.
+type WalletSummaries struct {
+	Tokens []string `json:"tokens"`
+}
+
+// WalletSummariesReply is the reply to the WalletSummaries command.
+type WalletSummariesReply struct {
+	Summaries map[string]WalletSummary `json:"summaries"` // [token]summary
+}
+
+// VettingEntry describes a proposal that is awaiting vetting. Timestamp is
+// the time that the proposal was submitted or last edited by the author and
+// Age is the number of seconds that have elapsed since then. A proposal that
+// has been awaiting vetting for longer than the policy VettingSLA is flagged
+// as an SLA breach.
+type VettingEntry struct {
+	Token       string `json:"token"`
+	Name        string `json:"name"`
+	UserID      string `json:"userid"`
+	Username    string `json:"username"`
+	Timestamp   int64  `json:"timestamp"`
+	Age         int64  `json:"age"` // In seconds
+	SLABreached bool   `json:"slabreached"`
+
+	// Similar contains the existing proposals that are likely duplicates
+	// of the proposal, sorted by score from highest to lowest.
+	Similar []SimilarProposal `json:"similar,omitempty"`
+
+	// The following fields are only populated when a reviewer has been
+	// assigned to the proposal.
+	Reviewer         string `json:"reviewer,omitempty"`         // User ID
+	ReviewerUsername string `json:"reviewerusername,omitempty"` // Username
+	AssignedAt       int64  `json:"assignedat,omitempty"`       // UNIX timestamp
+}
+
+// VettingQueue requests the proposals that are awaiting vetting, i.e. the
+// unvetted proposals that have not been made public or censored yet.
+type VettingQueue struct{}
+
+// VettingQueueReply is the reply to the VettingQueue command. The entries are
+// sorted by age from oldest to newest.
+type VettingQueueReply struct {
+	Entries []VettingEntry `json:"entries"`
+}
+
+// VettingAssign assigns an admin to review a proposal that is awaiting
+// vetting. The reviewer is the user ID of the admin. An empty reviewer
+// removes the current assignment.
+type VettingAssign struct {
+	Token    string `json:"token"`
+	Reviewer string `json:"reviewer"`
+}
+
+// VettingAssignReply is the reply to the VettingAssign command.
+type VettingAssignReply struct {
+	Entry VettingEntry `json:"entry"`
+}
+
+// ReportCategoryT represents the category of a report.
+type ReportCategoryT uint32
+
+const (
+	// ReportCategoryInvalid is an invalid report category.
+	ReportCategoryInvalid ReportCategoryT = 0
+
+	// ReportCategoryLegal is a legal report, e.g. a copyright or a
+	// defamation claim.
+	ReportCategoryLegal ReportCategoryT = 1
+
+	// ReportCategoryAbuse is a report of abusive content, e.g.
+	// harassment or doxxing.
+	ReportCategoryAbuse ReportCategoryT = 2
+
+	// ReportCategorySpam is a report of spam.
+	ReportCategorySpam ReportCategoryT = 3
+
+	// ReportCategoryOther is a report that does not fit any of the other
+	// categories.
+	ReportCategoryOther ReportCategoryT = 4
+
+	// ReportCategoryLast is used for testing that the ReportCategories
+	// map contains all report categories.
+	ReportCategoryLast ReportCategoryT = 5
+)
+
+var (
+	// ReportCategories contains the human readable report categories.
+	ReportCategories = map[ReportCategoryT]string{
+		ReportCategoryInvalid: "invalid",
+		ReportCategoryLegal:   "legal",
+		ReportCategoryAbuse:   "abuse",
+		ReportCategorySpam:    "spam",
+		ReportCategoryOther:   "other",
+	}
+)
+
+// ReportStatusT represents the status of a report.
+type ReportStatusT uint32
+
+const (
+	// ReportStatusInvalid is an invalid report status.
+	ReportStatusInvalid ReportStatusT = 0
+
+	// ReportStatusOpen is the status of a report that has not been
+	// handled by an admin yet.
+	ReportStatusOpen ReportStatusT = 1
+
+	// ReportStatusResolved is the status of a report that an admin has
+	// acted on, e.g. by censoring the reported content.
+	ReportStatusResolved ReportStatusT = 2
+
+	// ReportStatusDismissed is the status of a report that an admin
+	// has determined does not require any action.
+	ReportStatusDismissed ReportStatusT = 3
+
+	// ReportStatusLast is used for testing that the ReportStatuses map
+	// contains all report statuses.
+	ReportStatusLast ReportStatusT = 4
+)
+
+var (
+	// ReportStatuses contains the human readable report statuses.
+	ReportStatuses = map[ReportStatusT]string{
+		ReportStatusInvalid:   "invalid",
+		ReportStatusOpen:      "open",
+		ReportStatusResolved:  "resolved",
+		ReportStatusDismissed: "dismissed",
+	}
+)
+
+// Report is a legal or abuse report about a public proposal or about one of
+// its comments. A CommentID of 0 indicates that the report is about the
+// proposal itself.
+//
+// The contact is an optional way for the admins to reach the reporter, e.g.
+// an email address. Reports are only visible to admins.
+type Report struct {
+	ID        string          `json:"id"`
+	Token     string          `json:"token"`
+	CommentID uint32          `json:"commentid,omitempty"`
+	Category  ReportCategoryT `json:"category"`
+	Reason    string          `json:"reason"`
+	Contact   string          `json:"contact,omitempty"`
+	UserID    string          `json:"userid,omitempty"` // Reporter if logged in
+	Status    ReportStatusT   `json:"status"`
+	Timestamp int64           `json:"timestamp"` // UNIX timestamp
+
+	// The following fields are only populated once the report has been
+	// resolved or dismissed.
+	Resolution string `json:"resolution,omitempty"`
+	ResolvedBy string `json:"resolvedby,omitempty"` // Admin user ID
+	ResolvedAt int64  `json:"resolvedat,omitempty"` // UNIX timestamp
+}
+
+// ReportNew submits a legal or abuse report about a public proposal or about
+// one of its comments. The report is added to the admin moderation queue and
+// the admins are notified. Reports can be submitted without being logged in.
+// The route is rate limited per client address and per user, and the admin
+// notifications of reports that are submitted in quick succession are
+// batched.
+type ReportNew struct {
+	Token     string          `json:"token"`
+	CommentID uint32          `json:"commentid,omitempty"`
+	Category  ReportCategoryT `json:"category"`
+	Reason    string          `json:"reason"`
+	Contact   string          `json:"contact,omitempty"`
+}
+
+// ReportNewReply is the reply to the ReportNew command. The ID can be
+// provided to the admins when following up on the report.
+type ReportNewReply struct {
+	ID        string `json:"id"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// Reports requests the submitted reports. The reports can optionally be
+// filtered by status and by the token of the reported proposal.
+type Reports struct {
+	Status ReportStatusT `json:"status,omitempty"`
+	Token  string        `json:"token,omitempty"`
+}
+
+// ReportsReply is the reply to the Reports command. The reports are sorted by
+// timestamp from oldest to newest.
+type ReportsReply struct {
+	Reports []Report `json:"reports"`
+}
+
+// ReportResolve sets the status of an open report to resolved or dismissed.
+// The resolution describes the action that was taken.
+type ReportResolve struct {
+	ID         string        `json:"id"`
+	Status     ReportStatusT `json:"status"`
+	Resolution string        `json:"resolution"`
+}
+
+// ReportResolveReply is the reply to the ReportResolve command.
+type ReportResolveReply struct {
+	Report Report `json:"report"`
+}
+
+const (
+	// FeedFormatAtom and FeedFormatRSS are the supported feed formats.
+	// The format is selected using the format query parameter of the
+	// feed routes, e.g. /pi/v1/feeds/proposals?format=rss. Atom is
+	// returned when no format is provided.
+	FeedFormatAtom = "atom"
+	FeedFormatRSS  = "rss"
+
+	// FeedEntriesMax is the maximum number of entries in a feed. The
+	// feeds contain the most recent entries.
+	FeedEntriesMax = 20
+
+	// FeedCacheTTL is the number of seconds that a feed is cached by the
+	// server. Clients are allowed to cache the feeds for the same
+	// duration.
+	FeedCacheTTL = 300
+)
+
+// SearchTypeT represents the type of the search results that are requested.
+type SearchTypeT uint32
+
+const (
+	// SearchTypeAny requests both proposal and comment search results.
+	SearchTypeAny SearchTypeT = 0
+
+	// SearchTypeProposal requests proposal search results only.
+	SearchTypeProposal SearchTypeT = 1
+
+	// SearchTypeComment requests comment search results only.
+	SearchTypeComment SearchTypeT = 2
+
+	// SearchTypeLast is used for testing that the SearchTypes map
+	// contains all search types.
+	SearchTypeLast SearchTypeT = 3
+)
+
+var (
+	// SearchTypes contains the human readable search types.
+	SearchTypes = map[SearchTypeT]string{
+		SearchTypeAny:      "any",
+		SearchTypeProposal: "proposal",
+		SearchTypeComment:  "comment",
+	}
+)
+
+const (
+	// SearchPageSize is the maximum number of search results that are
+	// returned for any single request.
+	SearchPageSize uint32 = 20
+
+	// SearchQueryLengthMax is the maximum number of characters that a
+	// search query can be.
+	SearchQueryLengthMax = 200
+
+	// SearchSnippetLengthMax is the maximum number of characters of the
+	// matched text that are included in a search result.
+	SearchSnippetLengthMax = 200
+)
+
+// Search searches the public proposals and their comments. The query is
+// split into keywords and only results that contain all of the keywords are
+// returned. Author is a username. Status is a records v1 RecordStatusT and
+// matches the status of the proposal that a comment was made on for comment
+// results. After and Before are UNIX timestamps. A query or an author must
+// be provided. All other fields are optional.
+//
+// Pages start at 1. The first page is returned when no page is provided.
+type Search struct {
+	Query  string      `json:"query,omitempty"`
+	Author string      `json:"author,omitempty"`
+	Type   SearchTypeT `json:"type,omitempty"`
+	Status uint32      `json:"status,omitempty"`
+	After  int64       `json:"after,omitempty"`
+	Before int64       `json:"before,omitempty"`
+	Page   uint32      `json:"page,omitempty"`
+}
+
+// SearchResult is a proposal or a comment that matched a search. CommentID is
+// only populated for comment results. Name is the name of the proposal that
+// the result belongs to. Snippet is an excerpt of the matched text.
+type SearchResult struct {
+	Type      SearchTypeT `json:"type"`
+	Token     string      `json:"token"`
+	CommentID uint32      `json:"commentid,omitempty"`
+	Name      string      `json:"name"`
+	UserID    string      `json:"userid"`
+	Username  string      `json:"username"`
+	Status    uint32      `json:"status"`
+	Timestamp int64       `json:"timestamp"` // UNIX timestamp
+	Score     float64     `json:"score"`
+	Snippet   string      `json:"snippet"`
+}
+
+// SearchReply is the reply to the Search command. The results are sorted by
+// score from highest to lowest. Results with equal scores are sorted from
+// newest to oldest. Results are sorted from newest to oldest when no query is
+// provided. Total is the number of results across all pages.
+type SearchReply struct {
+	Results []SearchResult `json:"results"`
+	Total   uint32         `json:"total"`
+}
+
+// RFPProposal contains the details of an RFP proposal or of an RFP submission.
+// An RFP is a proposal that has set a VoteMetadata LinkBy deadline. A
+// submission is a proposal that links to an RFP using the VoteMetadata LinkTo
+// field. Status is the records v1 RecordStatusT. The name is only returned
+// for public and archived proposals.
+//
+// LinkErrors contains the reasons that the link between a submission and its
+// RFP is broken. It is only populated for submissions.
+type RFPProposal struct {
+	Token      string     `json:"token"`
+	Name       string     `json:"name,omitempty"`
+	Status     uint32     `json:"status"`
+	LinkBy     int64      `json:"linkby,omitempty"`
+	LinkTo     string     `json:"linkto,omitempty"`
+	Vote       WalletVote `json:"vote"`
+	LinkErrors []string   `json:"linkerrors,omitempty"`
+}
+
+// RFP requests an RFP proposal and the submissions in its submissions list,
+// along with their vote outcomes. The RFP must be a public or archived
+// proposal.
+type RFP struct {
+	Token string `json:"token"`
+}
+
+// RFPReply is the reply to the RFP command.
+type RFPReply struct {
+	RFP         RFPProposal   `json:"rfp"`
+	Submissions []RFPProposal `json:"submissions"`
+}
+
+// RFPParent requests the RFP proposal that a submission links to. The link is
+// validated and any problems are returned in the submission LinkErrors. The
+// submission must be a public or archived proposal. Only the token of the RFP
+// is returned when the RFP is not a public or archived proposal.
+type RFPParent struct {
+	Token string `json:"token"` // Submission token
+}
+
+// RFPParentReply is the reply to the RFPParent command.
+type RFPParentReply struct {
+	RFP        RFPProposal `json:"rfp"`
+	Submission RFPProposal `json:"submission"`
+}
+
+// RFPRepair repairs the submissions list of an RFP proposal. The submissions
+// list is used to verify the runoff vote of the submissions and is updated
+// when a submission is made public or is censored, so it can fall out of sync
+// if an update fails.
+//
+// The proposals that are currently in the list and the provided submissions
+// are re-verified. All public proposals are searched for submissions that
+// link to the RFP when no submissions are provided.
+type RFPRepair struct {
+	Token       string   `json:"token"`
+	Submissions []string `json:"submissions,omitempty"`
+}
+
+// RFPRepairReply is the reply to the RFPRepair command. It contains the
+// submissions that were added to and removed from the submissions list.
+type RFPRepairReply struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// LineageProposal contains a proposal of a resubmission lineage along with
+// its vote results. Status is the records v1 RecordStatusT. The name is only
+// returned for public and archived proposals.
+type LineageProposal struct {
+	Token     string     `json:"token"`
+	Name      string     `json:"name,omitempty"`
+	Status    uint32     `json:"status"`
+	Resubmits string     `json:"resubmits,omitempty"`
+	Vote      WalletVote `json:"vote"`
+}
+
+// Lineage requests the resubmission lineage of a proposal. The proposal must
+// be a public or archived proposal.
+type Lineage struct {
+	Token string `json:"token"`
+}
+
+// LineageReply is the reply to the Lineage command. It contains the proposal
+// followed by the proposals that it is a resubmission of, ordered from newest
+// to oldest, so that voters can review the results of the prior votes.
+type LineageReply struct {
+	Proposals []LineageProposal `json:"proposals"`
+}
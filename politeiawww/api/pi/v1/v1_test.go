@@ -0,0 +1,32 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/decred/politeia/unittest"
+)
+
+func TestMaps(t *testing.T) {
+	err := unittest.TestGenericConstMap(ErrorCodes, uint64(ErrorCodeLast))
+	if err != nil {
+		t.Fatalf("ErrorCodes: %v", err)
+	}
+	err = unittest.TestGenericConstMap(ReportCategories,
+		uint64(ReportCategoryLast))
+	if err != nil {
+		t.Fatalf("ReportCategories: %v", err)
+	}
+	err = unittest.TestGenericConstMap(ReportStatuses,
+		uint64(ReportStatusLast))
+	if err != nil {
+		t.Fatalf("ReportStatuses: %v", err)
+	}
+	err = unittest.TestGenericConstMap(SearchTypes, uint64(SearchTypeLast))
+	if err != nil {
+		t.Fatalf("SearchTypes: %v", err)
+	}
+}
@@ -15,6 +15,7 @@ const (
 	RouteEdit             = "/edit"
 	RouteSetStatus        = "/setstatus"
 	RouteDetails          = "/details"
+	RouteBatchDetails     = "/batchdetails"
 	RouteTimestamps       = "/timestamps"
 	RouteRecords          = "/records"
 	RouteInventory        = "/inventory"
@@ -22,6 +23,9 @@ const (
 
 	// Metadata routes
 	RouteUserRecords = "/userrecords"
+
+	// Legacy routes
+	RouteLegacyTokens = "/legacytokens"
 )
 
 // ErrorCodeT represents a user error code.
@@ -50,7 +54,9 @@ const (
 	ErrorCodeStatusChangeInvalid     ErrorCodeT = 18
 	ErrorCodeStatusReasonNotFound    ErrorCodeT = 19
 	ErrorCodePageSizeExceeded        ErrorCodeT = 20
-	ErrorCodeLast                    ErrorCodeT = 21
+	ErrorCodeFileRejected            ErrorCodeT = 21
+	ErrorCodeBotNotAllowed           ErrorCodeT = 22
+	ErrorCodeLast                    ErrorCodeT = 23
 )
 
 var (
@@ -77,6 +83,8 @@ var (
 		ErrorCodeStatusChangeInvalid:     "status change invalid",
 		ErrorCodeStatusReasonNotFound:    "status reason not found",
 		ErrorCodePageSizeExceeded:        "page size exceeded",
+		ErrorCodeFileRejected:            "file rejected by content scan",
+		ErrorCodeBotNotAllowed:           "bot accounts can not submit records",
 	}
 )
 
@@ -305,14 +313,75 @@ type SetStatusReply struct {
 
 // Details requests the details of a record. The full record will be returned.
 // If no version is specified then the most recent version will be returned.
+//
+// This route can also be requested using a GET request with the fields as
+// query params. The reply to a GET request includes an ETag header and a 304
+// Not Modified is returned when the ETag matches the If-None-Match header.
 type Details struct {
-	Token   string `json:"token"`
-	Version uint32 `json:"version,omitempty"`
+	Token   string `json:"token" schema:"token"`
+	Version uint32 `json:"version,omitempty" schema:"version"`
 }
 
 // DetailsReply is the reply to the Details command.
+//
+// Lineage contains the records that the record is a resubmission of, ordered
+// from newest to oldest, so that voters can review the results of the prior
+// votes. It is only populated for public and archived records that are
+// resubmissions.
 type DetailsReply struct {
-	Record Record `json:"record"`
+	Record  Record          `json:"record"`
+	Lineage []LineageRecord `json:"lineage,omitempty"`
+}
+
+// LineageRecord contains a record that another record is a resubmission of
+// along with the results of its vote. The name is only returned for public and
+// archived records. Only the token is returned for a record that could not be
+// retrieved. VoteStatus is a ticketvote v1 VoteStatusT.
+type LineageRecord struct {
+	Token            string              `json:"token"`
+	Name             string              `json:"name,omitempty"`
+	Status           RecordStatusT       `json:"status"`
+	Resubmits        string              `json:"resubmits,omitempty"`
+	VoteStatus       uint32              `json:"votestatus"`
+	EligibleTickets  uint32              `json:"eligibletickets,omitempty"`
+	QuorumPercentage uint32              `json:"quorumpercentage,omitempty"`
+	PassPercentage   uint32              `json:"passpercentage,omitempty"`
+	VoteResults      []LineageVoteResult `json:"voteresults,omitempty"`
+}
+
+// LineageVoteResult contains the number of votes that were cast for a vote
+// option of a lineage record.
+type LineageVoteResult struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	VoteBit     uint64 `json:"votebit"`
+	Votes       uint64 `json:"votes"`
+}
+
+const (
+	// BatchDetailsPageSize is the maximum number of records that can be
+	// requested in a BatchDetails request.
+	BatchDetailsPageSize = 5
+)
+
+// BatchDetails requests the full details of a batch of records. It allows
+// clients to retrieve multiple full records in a single round trip. Unvetted
+// record files are only returned to admins and the author.
+type BatchDetails struct {
+	Requests []Details `json:"requests"`
+}
+
+// BatchDetailsReply is the reply to the BatchDetails command. A request that
+// could not be fulfilled, e.g. because the token does not correspond to a
+// record, is included in the errors instead of the records.
+//
+// CommentCounts contains the number of comments of each returned record. It
+// is keyed by the same tokens as the records and is not populated when the
+// comment counts are not available.
+type BatchDetailsReply struct {
+	Records       map[string]Record         `json:"records"`                 // [token]Record
+	Errors        map[string]UserErrorReply `json:"errors,omitempty"`        // [token]Error
+	CommentCounts map[string]uint32         `json:"commentcounts,omitempty"` // [token]Count
 }
 
 // Proof contains an inclusion proof for the digest in the merkle root. All
@@ -348,9 +417,13 @@ type Timestamp struct {
 // Timestamps requests the timestamps for a specific record version. If the
 // version is omitted, the timestamps for the most recent version will be
 // returned.
+//
+// This route can also be requested using a GET request with the fields as
+// query params. The reply to a GET request includes an ETag header and a 304
+// Not Modified is returned when the ETag matches the If-None-Match header.
 type Timestamps struct {
-	Token   string `json:"token"`
-	Version uint32 `json:"version,omitempty"`
+	Token   string `json:"token" schema:"token"`
+	Version uint32 `json:"version,omitempty" schema:"version"`
 }
 
 // TimestampsReply is the reply to the Timestamps command.
@@ -451,3 +524,24 @@ type UserRecordsReply struct {
 	Unvetted []string `json:"unvetted"`
 	Vetted   []string `json:"vetted"`
 }
+
+const (
+	// LegacyTokensPageSize is the maximum number of tokens that can be
+	// requested in a LegacyTokens request.
+	LegacyTokensPageSize = 20
+)
+
+// LegacyTokens requests the tstore tokens of proposals that were submitted to
+// the legacy git backend and were later migrated to tstore. This allows
+// clients to resolve external links that still reference the legacy
+// censorship tokens. Both full length legacy tokens and legacy token prefixes
+// are accepted.
+type LegacyTokens struct {
+	Tokens []string `json:"tokens"`
+}
+
+// LegacyTokensReply is the reply to the LegacyTokens command. Tokens that do
+// not correspond to a migrated proposal are not included in the reply.
+type LegacyTokensReply struct {
+	Tokens map[string]string `json:"tokens"` // [legacyToken]token
+}
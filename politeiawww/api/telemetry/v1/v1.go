@@ -0,0 +1,158 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package v1
+
+import "fmt"
+
+const (
+	// APIRoute is prefixed onto all routes defined in this package.
+	APIRoute = "/telemetry/v1"
+
+	// RoutePolicy returns the policy for the telemetry API.
+	RoutePolicy = "/policy"
+
+	// RouteReport submits a client telemetry report.
+	RouteReport = "/report"
+
+	// RouteStats returns the aggregated telemetry stats. This route is
+	// admin only.
+	RouteStats = "/stats"
+)
+
+// ErrorCodeT represents a user error code.
+type ErrorCodeT uint32
+
+const (
+	// Error codes
+	ErrorCodeInvalid        ErrorCodeT = 0
+	ErrorCodeInputInvalid   ErrorCodeT = 1
+	ErrorCodeClientInvalid  ErrorCodeT = 2
+	ErrorCodeEventInvalid   ErrorCodeT = 3
+	ErrorCodeEventsExceeded ErrorCodeT = 4
+	ErrorCodeLast           ErrorCodeT = 5
+)
+
+var (
+	// ErrorCodes contains the human readable errors.
+	ErrorCodes = map[ErrorCodeT]string{
+		ErrorCodeInvalid:        "error invalid",
+		ErrorCodeInputInvalid:   "input invalid",
+		ErrorCodeClientInvalid:  "client invalid",
+		ErrorCodeEventInvalid:   "event invalid",
+		ErrorCodeEventsExceeded: "events exceeded",
+	}
+)
+
+// UserErrorReply is the reply that the server returns when it encounters an
+// error that is caused by something that the user did (malformed input, bad
+// timing, etc). The HTTP status code will be 400.
+type UserErrorReply struct {
+	ErrorCode    ErrorCodeT `json:"errorcode"`
+	ErrorContext string     `json:"errorcontext,omitempty"`
+}
+
+// Error satisfies the error interface.
+func (e UserErrorReply) Error() string {
+	return fmt.Sprintf("user error code: %v", e.ErrorCode)
+}
+
+// ServerErrorReply is the reply that the server returns when it encounters an
+// unrecoverable error while executing a command. The HTTP status code will be
+// 500 and the ErrorCode field will contain a UNIX timestamp that the user can
+// provide to the server admin to track down the error details in the logs.
+type ServerErrorReply struct {
+	ErrorCode int64 `json:"errorcode"`
+}
+
+// Error satisfies the error interface.
+func (e ServerErrorReply) Error() string {
+	return fmt.Sprintf("server error: %v", e.ErrorCode)
+}
+
+// Policy requests the telemetry policy.
+type Policy struct{}
+
+// PolicyReply is the reply to the Policy command.
+//
+// Clients contains the client names that the server accepts reports from.
+// Reports from any other client are rejected.
+type PolicyReply struct {
+	Clients       []string `json:"clients"`
+	EventsMax     uint32   `json:"eventsmax"`     // Max events per report
+	NameLengthMax uint32   `json:"namelengthmax"` // In characters
+}
+
+// EventT represents a telemetry event type.
+type EventT uint32
+
+const (
+	// EventTypeInvalid is an invalid event type.
+	EventTypeInvalid EventT = 0
+
+	// EventTypeUsage indicates that a client feature or API flow was
+	// used.
+	EventTypeUsage EventT = 1
+
+	// EventTypeError indicates that a client encountered an error.
+	EventTypeError EventT = 2
+
+	// EventTypeLast unit test only.
+	EventTypeLast EventT = 3
+)
+
+var (
+	// EventTypes contains the human readable event types.
+	EventTypes = map[EventT]string{
+		EventTypeInvalid: "invalid",
+		EventTypeUsage:   "usage",
+		EventTypeError:   "error",
+	}
+)
+
+// Event represents a single client telemetry event.
+//
+// Name is a short identifier chosen by the client, e.g. "proposal.new" or
+// "comment.vote". It must only contain lowercase letters, digits, periods,
+// dashes and underscores. Count allows clients to batch repeated events into
+// a single entry.
+//
+// Events must not contain any user identifying information. The server never
+// stores anything other than the aggregated counters.
+type Event struct {
+	Type  EventT `json:"type"`
+	Name  string `json:"name"`
+	Count uint32 `json:"count"`
+}
+
+// Report submits a batch of client telemetry events. Reporting is opt-in and
+// is not tied to a user session.
+type Report struct {
+	Client  string  `json:"client"`  // Client name, e.g. politeiagui
+	Version string  `json:"version"` // Client version
+	Events  []Event `json:"events"`
+}
+
+// ReportReply is the reply to the Report command.
+type ReportReply struct{}
+
+// Stats requests the aggregated telemetry stats.
+type Stats struct{}
+
+// ClientStats contains the aggregated telemetry stats for a single client.
+// The event maps are keyed by event name.
+type ClientStats struct {
+	Reports  uint64            `json:"reports"`
+	Versions map[string]uint64 `json:"versions"`
+	Usage    map[string]uint64 `json:"usage"`
+	Errors   map[string]uint64 `json:"errors"`
+}
+
+// StatsReply is the reply to the Stats command. The stats are aggregated in
+// memory and are reset when the server is restarted. Since is the UNIX
+// timestamp of when the aggregation started.
+type StatsReply struct {
+	Since   int64                  `json:"since"`
+	Clients map[string]ClientStats `json:"clients"` // [client]ClientStats
+}
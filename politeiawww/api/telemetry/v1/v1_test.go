@@ -0,0 +1,22 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/decred/politeia/unittest"
+)
+
+func TestMaps(t *testing.T) {
+	err := unittest.TestGenericConstMap(ErrorCodes, uint64(ErrorCodeLast))
+	if err != nil {
+		t.Fatalf("ErrorCodes: %v", err)
+	}
+	err = unittest.TestGenericConstMap(EventTypes, uint64(EventTypeLast))
+	if err != nil {
+		t.Fatalf("EventTypes: %v", err)
+	}
+}
@@ -20,6 +20,8 @@ const (
 	RouteSubmissions = "/submissions"
 	RouteInventory   = "/inventory"
 	RouteTimestamps  = "/timestamps"
+	RouteCertificate = "/certificate"
+	RouteTallies     = "/tallies"
 )
 
 // ErrorCodeT represents a user error code.
@@ -27,28 +29,30 @@ type ErrorCodeT uint32
 
 const (
 	// Error codes
-	ErrorCodeInvalid          ErrorCodeT = 0
-	ErrorCodeInputInvalid     ErrorCodeT = 1
-	ErrorCodePublicKeyInvalid ErrorCodeT = 2
-	ErrorCodeUnauthorized     ErrorCodeT = 3
-	ErrorCodeRecordNotFound   ErrorCodeT = 4
-	ErrorCodeRecordLocked     ErrorCodeT = 5
-	ErrorCodeTokenInvalid     ErrorCodeT = 6
-	ErrorCodePageSizeExceeded ErrorCodeT = 7
-	ErrorCodeLast             ErrorCodeT = 8
+	ErrorCodeInvalid           ErrorCodeT = 0
+	ErrorCodeInputInvalid      ErrorCodeT = 1
+	ErrorCodePublicKeyInvalid  ErrorCodeT = 2
+	ErrorCodeUnauthorized      ErrorCodeT = 3
+	ErrorCodeRecordNotFound    ErrorCodeT = 4
+	ErrorCodeRecordLocked      ErrorCodeT = 5
+	ErrorCodeTokenInvalid      ErrorCodeT = 6
+	ErrorCodePageSizeExceeded  ErrorCodeT = 7
+	ErrorCodeVoteStatusInvalid ErrorCodeT = 8
+	ErrorCodeLast              ErrorCodeT = 9
 )
 
 var (
 	// ErrorCodes contains the human readable errors.
 	ErrorCodes = map[ErrorCodeT]string{
-		ErrorCodeInvalid:          "error invalid",
-		ErrorCodeInputInvalid:     "input invalid",
-		ErrorCodePublicKeyInvalid: "public key invalid",
-		ErrorCodeUnauthorized:     "unauthorized",
-		ErrorCodeRecordNotFound:   "record not found",
-		ErrorCodeRecordLocked:     "record locked",
-		ErrorCodeTokenInvalid:     "token is invalid",
-		ErrorCodePageSizeExceeded: "page size exceeded",
+		ErrorCodeInvalid:           "error invalid",
+		ErrorCodeInputInvalid:      "input invalid",
+		ErrorCodePublicKeyInvalid:  "public key invalid",
+		ErrorCodeUnauthorized:      "unauthorized",
+		ErrorCodeRecordNotFound:    "record not found",
+		ErrorCodeRecordLocked:      "record locked",
+		ErrorCodeTokenInvalid:      "token is invalid",
+		ErrorCodePageSizeExceeded:  "page size exceeded",
+		ErrorCodeVoteStatusInvalid: "vote status invalid",
 	}
 )
 
@@ -95,11 +99,16 @@ func (e ServerErrorReply) Error() string {
 type Policy struct{}
 
 // PolicyReply is the reply to the Policy command.
+//
+// NonceRequired indicates that the cast votes must include a nonce and an
+// expiry. See ReplayProtection.
 type PolicyReply struct {
 	LinkByPeriodMin int64  `json:"linkbyperiodmin"` // In seconds
 	LinkByPeriodMax int64  `json:"linkbyperiodmax"` // In seconds
 	VoteDurationMin uint32 `json:"votedurationmin"` // In blocks
 	VoteDurationMax uint32 `json:"votedurationmax"` // In blocks
+	NonceRequired   bool   `json:"noncerequired,omitempty"`
+	NonceExpiryMax  int64  `json:"nonceexpirymax"` // In seconds
 }
 
 // AuthActionT represents an Authorize action.
@@ -376,14 +385,53 @@ const (
 	// VoteErrorTicketAlreadyVoted is returned when attempting to cast
 	// a vote using a dcr ticket that has already voted.
 	VoteErrorTicketAlreadyVoted VoteErrorT = 9
+
+	// VoteErrorNonceInvalid is returned when a cast vote nonce or
+	// expiry is invalid, or when a required nonce is missing.
+	VoteErrorNonceInvalid VoteErrorT = 10
+
+	// VoteErrorNonceExpired is returned when the expiry of a cast vote
+	// has passed.
+	VoteErrorNonceExpired VoteErrorT = 11
+
+	// VoteErrorNonceUsed is returned when a cast vote reuses a nonce.
+	VoteErrorNonceUsed VoteErrorT = 12
 )
 
+const (
+	// NonceSize is the size in bytes of a nonce. Nonces are hex encoded.
+	NonceSize = 16
+
+	// NonceExpiryMax is the maximum number of seconds in the future that
+	// the expiry of a nonce can be. It exceeds the maximum duration of a
+	// vote so that votes can be signed up front and trickled in over the
+	// duration of the vote.
+	NonceExpiryMax int64 = 60 * 60 * 24 * 30
+)
+
+// ReplayProtection protects a signed cast vote from being replayed. The Nonce
+// is a random value of NonceSize bytes that must not be reused by the ticket
+// and the Expiry is the UNIX timestamp after which the vote is rejected. The
+// expiry can be at most NonceExpiryMax seconds in the future. The fields are
+// optional unless the policy requires them.
+//
+// When a nonce is provided the Nonce+Expiry are appended to the message that
+// is signed by the ticket. The fields are returned along with the cast vote
+// details so that the signatures can be verified. The nonce of a vote that
+// was not cast can be used again.
+type ReplayProtection struct {
+	Nonce  string `json:"nonce,omitempty"`  // Hex encoded random nonce
+	Expiry int64  `json:"expiry,omitempty"` // UNIX timestamp
+}
+
 // CastVote is a signed ticket vote.
 type CastVote struct {
 	Token     string `json:"token"`     // Record token
 	Ticket    string `json:"ticket"`    // Ticket ID
 	VoteBit   string `json:"votebit"`   // Selected vote bit, hex encoded
-	Signature string `json:"signature"` // Signature of Token+Ticket+VoteBit
+	Signature string `json:"signature"` // Signature of Token+Ticket+VoteBit[+Nonce+Expiry]
+
+	ReplayProtection
 }
 
 // CastVoteReply contains the receipt for the cast vote.
@@ -452,9 +500,10 @@ type DetailsReply struct {
 
 // CastVoteDetails contains the details of a cast vote.
 //
-// Signature is the client signature of the Token+Ticket+VoteBit. The client
-// uses the ticket's largest commitment address to create the signature. The
-// receipt is the server signature of the client signature.
+// Signature is the client signature of the Token+Ticket+VoteBit, followed by
+// the Nonce+Expiry when a nonce is set. The client uses the ticket's largest
+// commitment address to create the signature. The receipt is the server
+// signature of the client signature.
 type CastVoteDetails struct {
 	Token     string `json:"token"`     // Record token
 	Ticket    string `json:"ticket"`    // Ticket hash
@@ -463,11 +512,17 @@ type CastVoteDetails struct {
 	Signature string `json:"signature"` // Client signature
 	Receipt   string `json:"receipt"`   // Server sig of client sig
 	Timestamp int64  `json:"timestamp"` // Unix timestamp
+
+	ReplayProtection
 }
 
 // Results returns the cast votes for a record.
+//
+// This route can also be requested using a GET request with the fields as
+// query params. The reply to a GET request includes an ETag header and a 304
+// Not Modified is returned when the ETag matches the If-None-Match header.
 type Results struct {
-	Token string `json:"token"`
+	Token string `json:"token" schema:"token"`
 }
 
 // ResultsReply is the reply to the Results command.
@@ -626,9 +681,13 @@ const (
 // If no votes page number is provided then the vote authorization and vote
 // details timestamps will be returned. If a votes page number is provided then
 // the specified page of cast vote timestamps will be returned.
+//
+// This route can also be requested using a GET request with the fields as
+// query params. The reply to a GET request includes an ETag header and a 304
+// Not Modified is returned when the ETag matches the If-None-Match header.
 type Timestamps struct {
-	Token     string `json:"token"`
-	VotesPage uint32 `json:"votespage,omitempty"`
+	Token     string `json:"token" schema:"token"`
+	VotesPage uint32 `json:"votespage,omitempty" schema:"votespage"`
 }
 
 // TimestampsReply is the reply to the Timestamps command.
@@ -645,3 +704,105 @@ type TimestampsReply struct {
 	// payloads will contain CastVoteDetails strucutures.
 	Votes []Timestamp `json:"votes,omitempty"`
 }
+
+// VoteCertificate certifies the outcome of a finished record vote. It
+// contains the vote parameters, the vote totals, the quorum and pass
+// thresholds that the totals were checked against, and references to the
+// dcr transaction that anchored the vote details.
+//
+// EligibleTicketsDigest is the hex encoded SHA256 digest of the JSON encoded
+// list of eligible ticket hashes that is returned by the Details route. It
+// commits the certificate to the ticket snapshot that the vote was run
+// against without including the full snapshot.
+//
+// Quorum is the number of votes required to meet the quorum requirement and
+// is calculated as QuorumPercentage percent of the eligible tickets. Pass is
+// the number of approve votes required to meet the pass requirement and is
+// calculated as PassPercentage percent of the total votes. The thresholds are
+// rounded down.
+//
+// Status is the final vote status as determined by politeiad. A runoff vote
+// submission can meet both the quorum and pass requirements and still be
+// rejected if it did not have the most net approve votes.
+type VoteCertificate struct {
+	Token                 string       `json:"token"`
+	Version               uint32       `json:"version"` // Record version
+	Type                  VoteT        `json:"type"`
+	Status                VoteStatusT  `json:"status"`
+	Parent                string       `json:"parent,omitempty"` // Runoff only
+	StartBlockHeight      uint32       `json:"startblockheight"`
+	StartBlockHash        string       `json:"startblockhash"`
+	EndBlockHeight        uint32       `json:"endblockheight"`
+	EligibleTickets       uint32       `json:"eligibletickets"`
+	EligibleTicketsDigest string       `json:"eligibleticketsdigest"`
+	QuorumPercentage      uint32       `json:"quorumpercentage"`
+	PassPercentage        uint32       `json:"passpercentage"`
+	Results               []VoteResult `json:"results"`
+	TotalVotes            uint64       `json:"totalvotes"`
+	ApproveVotes          uint64       `json:"approvevotes"`
+	Quorum                uint64       `json:"quorum"`
+	QuorumMet             bool         `json:"quorummet"`
+	Pass                  uint64       `json:"pass"`
+	PassMet               bool         `json:"passmet"`
+
+	// AnchorDigest is the digest of the vote details that was anchored.
+	// AnchorTxID and AnchorMerkleRoot reference the dcr transaction
+	// that anchored the digest. They will be empty if the vote
+	// details have not been anchored yet. The full inclusion proof can
+	// be retrieved using the Timestamps route.
+	AnchorDigest     string `json:"anchordigest,omitempty"`
+	AnchorTxID       string `json:"anchortxid,omitempty"`
+	AnchorMerkleRoot string `json:"anchormerkleroot,omitempty"`
+
+	Timestamp       int64  `json:"timestamp"`       // Generation time
+	PoliteiadPubKey string `json:"politeiadpubkey"` // politeiad identity
+	ServerPubKey    string `json:"serverpubkey"`    // politeiawww identity
+
+	// PrevServerPubKey is the previous politeiawww identity. It is only
+	// set while the politeiawww signing identity is being rotated.
+	PrevServerPubKey string `json:"prevserverpubkey,omitempty"`
+}
+
+// Certificate requests the certificate of a finished record vote.
+type Certificate struct {
+	Token string `json:"token"`
+}
+
+// CertificateReply is the reply to the Certificate command.
+//
+// Signature is the politeiawww signature of the hex encoded SHA256 digest of
+// the JSON encoded VoteCertificate. PrevSignature is the signature of the same
+// digest using the previous politeiawww signing identity. It is only set while
+// the signing identity is being rotated. Text contains a human readable
+// version of the certificate.
+type CertificateReply struct {
+	Certificate   VoteCertificate `json:"certificate"`
+	Signature     string          `json:"signature"`
+	PrevSignature string          `json:"prevsignature,omitempty"`
+	Text          string          `json:"text"`
+}
+
+// Tallies requests the vote tally time series of a record vote.
+type Tallies struct {
+	Token string `json:"token"`
+}
+
+// Tally is a snapshot of the vote results of a record vote at a block height.
+// Timestamp is the UNIX timestamp of when the snapshot was taken.
+type Tally struct {
+	BlockHeight uint32       `json:"blockheight"`
+	Timestamp   int64        `json:"timestamp"`
+	Results     []VoteResult `json:"results"`
+}
+
+// TalliesReply is the reply to the Tallies command.
+//
+// Tallies contains the vote tally snapshots of the record vote, ordered by
+// block height. A snapshot is taken every Interval blocks while the vote is
+// active and a final snapshot containing the final vote results is added
+// once the vote has finished. The tallies will be empty if the vote has not
+// started yet or if the vote finished before the snapshots were enabled.
+type TalliesReply struct {
+	Interval uint32  `json:"interval"` // In blocks
+	Tallies  []Tally `json:"tallies"`
+}
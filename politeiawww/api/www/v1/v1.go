@@ -7,7 +7,9 @@ package v1
 import (
 	"fmt"
 
-	"github.com/decred/politeia/decredplugin"
+	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
 )
 
 type ErrorStatusT int
@@ -16,17 +18,29 @@ type PropStatusT int
 type PropVoteStatusT int
 type UserManageActionT int
 type EmailNotificationT int
+type EmailDigestT int
 type VoteT int
 type TOTPMethodT int
 
 const (
 	PoliteiaWWWAPIVersion = 1 // API version this backend understands
 
-	CsrfToken = "X-CSRF-Token"    // CSRF token for replies
-	Forward   = "X-Forwarded-For" // Proxy header
+	CsrfToken        = "X-CSRF-Token"         // CSRF token for replies
+	CsrfSessionToken = "X-CSRF-Session-Token" // CSRF session token
+	Forward          = "X-Forwarded-For"      // Proxy header
+	IdempotencyKey   = "X-Idempotency-Key"    // Write request idempotency key
+	APIKey           = "X-API-Key"            // Bot account API key
+	IdempotentReplay = "X-Idempotent-Replay"  // Set on replayed replies
+	ConsistencyToken = "X-Consistency-Token"  // Read-after-write token
+
+	// MailFeedbackToken is the header that contains the shared secret
+	// that is required by the MailFeedback route.
+	MailFeedbackToken = "X-Mail-Feedback-Token"
 
 	RouteVersion                  = "/version"
+	RouteCSRFToken                = "/csrftoken"
 	RoutePolicy                   = "/policy"
+	RoutePolicies                 = "/policies"
 	RouteSecret                   = "/secret"
 	RouteLogin                    = "/login"
 	RouteLogout                   = "/logout"
@@ -47,12 +61,32 @@ const (
 	RouteUserProposalCredits      = "/user/payments/credits"
 	RouteUserPaymentsRescan       = "/user/payments/rescan"
 	RouteManageUser               = "/user/manage"
+	RouteNewBot                   = "/user/bot/new"
+	RouteBotAPIKey                = "/user/bot/apikey"
 	RouteSetTOTP                  = "/user/totp"
 	RouteVerifyTOTP               = "/user/verifytotp"
+	RouteVerifyStake              = "/user/stake/verify"
+	RouteVerifications            = "/user/verifications"
 	RouteUserDetails              = "/user/{userid:[0-9a-zA-Z-]{36}}"
 	RouteUsers                    = "/users"
 	RouteUnauthenticatedWebSocket = "/ws"
 	RouteAuthenticatedWebSocket   = "/aws"
+	RouteMailFeedback             = "/mail/feedback"
+	RouteNotificationLog          = "/notification/log"
+	RouteNotificationRetry        = "/notification/retry"
+	RouteUnsubscribe              = "/user/unsubscribe"
+	RouteACL                      = "/acl"
+	RouteSetACL                   = "/acl/set"
+	RouteMaintenance              = "/maintenance"
+	RouteSetMaintenance           = "/maintenance/set"
+	RouteSiteInfo                 = "/siteinfo"
+	RouteOpenAPI                  = "/openapi"
+
+	// The following routes are served from the root of the server
+	// instead of the API route so that load balancers and container
+	// orchestrators can probe them without knowledge of the API.
+	RouteHealth = "/healthz"
+	RouteReady  = "/readyz"
 
 	// The following routes have been DEPRECATED.
 	RouteTokenInventory   = "/proposals/tokeninventory"
@@ -82,10 +116,10 @@ const (
 	// VerificationTokenSize is the size of verification token in bytes
 	VerificationTokenSize = 32
 
-	// TokenPrefixLength is the length of the token prefix that can
-	// be used in RouteProposalDetails. This should match what is defined
-	// in politeiad, and once a Policy route is created in politeiad,
-	// this should be removed.
+	// TokenPrefixLength is the default length of the token prefix that
+	// can be used in place of a full token. The length is configurable
+	// per deployment. The Policy reply contains the length that is used
+	// by the server.
 	TokenPrefixLength = 7
 
 	// VerificationExpiryHours is the number of hours before the
@@ -114,7 +148,9 @@ const (
 	PolicyMaxMDSize = 512 * 1024
 
 	// PolicyMinPasswordLength is the minimum number of characters
-	// accepted for user passwords
+	// accepted for user passwords. Servers can be configured to
+	// require longer passwords. The PolicyReply contains the length
+	// that is enforced by the server.
 	PolicyMinPasswordLength = 8
 
 	// PolicyMaxUsernameLength is the max length of a username
@@ -223,7 +259,14 @@ const (
 	ErrorStatusTOTPInvalidType             ErrorStatusT = 78
 	ErrorStatusRequiresTOTPCode            ErrorStatusT = 79
 	ErrorStatusTOTPWaitForNewCode          ErrorStatusT = 80
-	ErrorStatusLast                        ErrorStatusT = 81
+	ErrorStatusAccessDenied                ErrorStatusT = 81
+	ErrorStatusRequestTooLarge             ErrorStatusT = 82
+	ErrorStatusStakeInvalid                ErrorStatusT = 83
+	ErrorStatusVerificationResendThrottled ErrorStatusT = 84
+	ErrorStatusMaintenance                 ErrorStatusT = 85
+	ErrorStatusBotNotAllowed               ErrorStatusT = 86
+	ErrorStatusRateLimited                 ErrorStatusT = 87
+	ErrorStatusLast                        ErrorStatusT = 88
 
 	// Proposal state codes
 	//
@@ -289,9 +332,12 @@ const (
 	UserManageUnlock                          UserManageActionT = 5
 	UserManageDeactivate                      UserManageActionT = 6
 	UserManageReactivate                      UserManageActionT = 7
-	UserManageLast                            UserManageActionT = 8
+	UserManageClearEmailSuppression           UserManageActionT = 8
+	UserManageLast                            UserManageActionT = 9
 
-	// Email notification types
+	// Email notification types. The values are saved in the user
+	// database, so the bit of a notification must never change.
+	// 1 << 10 is unused.
 	NotificationEmailMyProposalStatusChange      EmailNotificationT = 1 << 0
 	NotificationEmailMyProposalVoteStarted       EmailNotificationT = 1 << 1
 	NotificationEmailRegularProposalVetted       EmailNotificationT = 1 << 2
@@ -301,6 +347,15 @@ const (
 	NotificationEmailAdminProposalVoteAuthorized EmailNotificationT = 1 << 6
 	NotificationEmailCommentOnMyProposal         EmailNotificationT = 1 << 7
 	NotificationEmailCommentOnMyComment          EmailNotificationT = 1 << 8
+	NotificationEmailAdminReportNew              EmailNotificationT = 1 << 9
+	NotificationEmailRegularProposalAuthorUpdate EmailNotificationT = 1 << 11
+	NotificationEmailAdminFileQuarantined        EmailNotificationT = 1 << 12
+
+	// Email digest types
+	EmailDigestNone   EmailDigestT = 0 // Notifications are sent immediately
+	EmailDigestDaily  EmailDigestT = 1
+	EmailDigestWeekly EmailDigestT = 2
+	EmailDigestLast   EmailDigestT = 3
 
 	// Time-base one time password types
 	TOTPTypeInvalid TOTPMethodT = 0 // Invalid TOTP type
@@ -409,6 +464,13 @@ var (
 		ErrorStatusTOTPInvalidType:             "invalid totp type",
 		ErrorStatusRequiresTOTPCode:            "login requires totp code",
 		ErrorStatusTOTPWaitForNewCode:          "must wait until next totp code window",
+		ErrorStatusAccessDenied:                "access denied for client network",
+		ErrorStatusRequestTooLarge:             "request body too large",
+		ErrorStatusStakeInvalid:                "stake verification invalid",
+		ErrorStatusVerificationResendThrottled: "verification email was sent recently",
+		ErrorStatusMaintenance:                 "server is in read-only maintenance mode",
+		ErrorStatusBotNotAllowed:               "action is not allowed for bot accounts",
+		ErrorStatusRateLimited:                 "too many requests",
 	}
 
 	// PropStatus converts propsal status codes to human readable text
@@ -442,6 +504,14 @@ var (
 		UserManageUnlock:                          "unlock user",
 		UserManageDeactivate:                      "deactivate user",
 		UserManageReactivate:                      "reactivate user",
+		UserManageClearEmailSuppression:           "clear email suppression",
+	}
+
+	// EmailDigests contains the human readable email digest types.
+	EmailDigests = map[EmailDigestT]string{
+		EmailDigestNone:   "none",
+		EmailDigestDaily:  "daily",
+		EmailDigestWeekly: "weekly",
 	}
 )
 
@@ -587,14 +657,51 @@ type Version struct{}
 // VersionReply returns information that indicates the lowest version that
 // this backend supports and additionally the route to the API and the public
 // signing key of the server.
+//
+// APIs contains the versions of the plugin APIs that are supported by the
+// server. The key is the API name, e.g. "ticketvote", which is the first
+// element of the API route. Clients can use this to select the highest API
+// version that both the client and the server support. Servers that do not
+// return this field only support v1 of the plugin APIs.
+//
+// SigningPubKey is the politeiawww signing key that is used to sign data that
+// originates from politeiawww, such as vote certificates and comment export
+// manifests. PrevSigningPubKey and RotationEnd are only set while the signing
+// identity is being rotated. Data is signed using both keys until the UNIX
+// time RotationEnd so that clients that only know the previous key are able
+// to verify it. Clients should replace the previous key with SigningPubKey
+// before the rotation ends.
 type VersionReply struct {
-	Version           uint   `json:"version"`           // Lowest supported WWW API version
-	Route             string `json:"route"`             // Prefix to API calls
-	BuildVersion      string `json:"buildversion"`      // Build version from hosted pi module
-	PubKey            string `json:"pubkey"`            // Server public key
-	TestNet           bool   `json:"testnet"`           // Network indicator
-	Mode              string `json:"mode"`              // current politeiawww mode running (piwww or cmswww)
-	ActiveUserSession bool   `json:"activeusersession"` // indicates if there is an active user session
+	Version           uint                `json:"version"`           // Lowest supported WWW API version
+	Route             string              `json:"route"`             // Prefix to API calls
+	BuildVersion      string              `json:"buildversion"`      // Build version from hosted pi module
+	PubKey            string              `json:"pubkey"`            // Server public key
+	TestNet           bool                `json:"testnet"`           // Network indicator
+	Mode              string              `json:"mode"`              // current politeiawww mode running (piwww or cmswww)
+	ActiveUserSession bool                `json:"activeusersession"` // indicates if there is an active user session
+	APIs              map[string][]uint32 `json:"apis,omitempty"`    // Supported plugin API versions
+	SigningPubKey     string              `json:"signingpubkey"`     // politeiawww signing key
+	PrevSigningPubKey string              `json:"prevsigningpubkey,omitempty"`
+	RotationEnd       int64               `json:"rotationend,omitempty"`
+}
+
+// CSRFToken requests a new CSRF session token for the current user session.
+//
+// The cookie based CSRF token that is returned in the Version reply header
+// has been DEPRECATED. API clients should instead request a CSRF session
+// token and include it in the CsrfSessionToken header of all authenticated
+// requests. A CSRF session token is bound to the user session that it was
+// issued for and does not require the client to manage a CSRF cookie. A new
+// token is issued on every request. Previously issued tokens remain valid
+// until they expire, allowing clients to rotate tokens without coordinating
+// in-flight requests.
+type CSRFToken struct{}
+
+// CSRFTokenReply is the reply to the CSRFToken command. Expiry is a UNIX
+// timestamp of when the token expires.
+type CSRFTokenReply struct {
+	Token  string `json:"token"`
+	Expiry int64  `json:"expiry"`
 }
 
 // NewUser is used to request that a new user be created within the db.
@@ -759,19 +866,23 @@ type Login struct {
 
 // LoginReply is used to reply to the Login command.
 type LoginReply struct {
-	IsAdmin            bool   `json:"isadmin"`            // Set if user is an admin
-	UserID             string `json:"userid"`             // User id
-	Email              string `json:"email"`              // User email
-	Username           string `json:"username"`           // Username
-	PublicKey          string `json:"publickey"`          // Active public key
-	PaywallAddress     string `json:"paywalladdress"`     // Registration paywall address
-	PaywallAmount      uint64 `json:"paywallamount"`      // Registration paywall amount in atoms
-	PaywallTxNotBefore int64  `json:"paywalltxnotbefore"` // Minimum timestamp for paywall tx
-	PaywallTxID        string `json:"paywalltxid"`        // Paywall payment tx ID
-	ProposalCredits    uint64 `json:"proposalcredits"`    // Number of the proposal credits the user has available to spend
-	LastLoginTime      int64  `json:"lastlogintime"`      // Unix timestamp of last login date
-	SessionMaxAge      int64  `json:"sessionmaxage"`      // Unix timestamp of session max age
-	TOTPVerified       bool   `json:"totpverified"`       // Whether current totp secret has been verified with
+	IsAdmin            bool         `json:"isadmin"`                 // Set if user is an admin
+	UserID             string       `json:"userid"`                  // User id
+	Email              string       `json:"email"`                   // User email
+	Username           string       `json:"username"`                // Username
+	PublicKey          string       `json:"publickey"`               // Active public key
+	PaywallAddress     string       `json:"paywalladdress"`          // Registration paywall address
+	PaywallAmount      uint64       `json:"paywallamount"`           // Registration paywall amount in atoms
+	PaywallTxNotBefore int64        `json:"paywalltxnotbefore"`      // Minimum timestamp for paywall tx
+	PaywallTxID        string       `json:"paywalltxid"`             // Paywall payment tx ID
+	ProposalCredits    uint64       `json:"proposalcredits"`         // Number of the proposal credits the user has available to spend
+	LastLoginTime      int64        `json:"lastlogintime"`           // Unix timestamp of last login date
+	SessionMaxAge      int64        `json:"sessionmaxage"`           // Unix timestamp of session max age
+	TOTPVerified       bool         `json:"totpverified"`            // Whether current totp secret has been verified with
+	TimeZone           string       `json:"timezone,omitempty"`      // Email time zone
+	Locale             string       `json:"locale,omitempty"`        // Email locale
+	EmailDigest        EmailDigestT `json:"emaildigest,omitempty"`   // Email digest
+	StakeVerified      int64        `json:"stakeverified,omitempty"` // Unix timestamp of stake verification
 }
 
 //Logout attempts to log the user out.
@@ -987,6 +1098,8 @@ type Policy struct{}
 // the file upload restrictions set for Politeia.
 type PolicyReply struct {
 	MinPasswordLength          uint     `json:"minpasswordlength"`
+	MinPasswordScore           uint     `json:"minpasswordscore"`    // 0 to 4
+	PasswordBreachCheck        bool     `json:"passwordbreachcheck"` // Checked against breaches
 	MinUsernameLength          uint     `json:"minusernamelength"`
 	MaxUsernameLength          uint     `json:"maxusernamelength"`
 	UsernameSupportedChars     []string `json:"usernamesupportedchars"`
@@ -1011,6 +1124,93 @@ type PolicyReply struct {
 	MinVoteDuration            uint32   `json:"minvoteduration"`
 	MaxVoteDuration            uint32   `json:"maxvoteduration"`
 	PaywallConfirmations       uint64   `json:"paywallconfirmations"`
+	EmailLocales               []string `json:"emaillocales"` // Supported email locales
+}
+
+// Policies retrieves the policies of all APIs that are served by politeiawww.
+// This allows clients to retrieve the full server policy with a single
+// request.
+type Policies struct{}
+
+// PoliciesReply is the reply to the Policies command. The plugin API policies
+// are only returned when politeiawww is running in piwww mode. APIs contains
+// the plugin API versions that are supported by the server.
+type PoliciesReply struct {
+	WWW        PolicyReply         `json:"www"`
+	Comments   *cmv1.PolicyReply   `json:"comments,omitempty"`
+	TicketVote *tkv1.PolicyReply   `json:"ticketvote,omitempty"`
+	Pi         *piv1.PolicyReply   `json:"pi,omitempty"`
+	APIs       map[string][]uint32 `json:"apis,omitempty"`
+}
+
+// The following are the optional features that a deployment can have
+// enabled. They are returned in the SiteInfo reply.
+const (
+	FeaturePaywall   = "paywall"   // User registration and proposal paywalls
+	FeatureEmail     = "email"     // Email verification and notifications
+	FeatureTelemetry = "telemetry" // Opt-in client telemetry API
+	FeatureOAuth     = "oauth"     // Third-party application access
+	FeatureReports   = "reports"   // Legal and abuse reports
+)
+
+// SiteInfo retrieves the branding and the capabilities of the deployment so
+// that generic clients are able to adapt to any politeia instance without
+// hardcoding its details. This is a GET request.
+type SiteInfo struct{}
+
+// SiteInfoReply is the reply to the SiteInfo command. LogoURL and Contact are
+// only set if they have been configured by the operator. Network is the name
+// of the Decred network that the deployment runs on, e.g. mainnet or
+// testnet3. Features contains the enabled optional features.
+type SiteInfoReply struct {
+	Name     string   `json:"name"`
+	LogoURL  string   `json:"logourl,omitempty"`
+	Contact  string   `json:"contact,omitempty"`
+	Network  string   `json:"network"`
+	Mode     string   `json:"mode"` // piwww or cmswww
+	Features []string `json:"features"`
+}
+
+// The following are the statuses of the health and readiness checks.
+const (
+	HealthStatusOK   = "ok"
+	HealthStatusFail = "fail"
+)
+
+// The following are the names of the readiness checks.
+const (
+	ReadyCheckPoliteiad = "politeiad" // Politeiad is reachable
+	ReadyCheckUserDB    = "userdb"    // User database is reachable
+	ReadyCheckSessions  = "sessions"  // Session store is reachable
+	ReadyCheckMail      = "mail"      // SMTP server is configured
+	ReadyCheckShutdown  = "shutdown"  // Server is not shutting down
+)
+
+// Health reports whether the politeiawww process is alive. This is a GET
+// request. A 200 is returned as long as the server is handling requests.
+type Health struct{}
+
+// HealthReply is the reply to the Health command.
+type HealthReply struct {
+	Status string `json:"status"`
+}
+
+// Ready reports whether politeiawww is ready to serve requests, i.e. whether
+// the services that it depends on are reachable. This is a GET request. A 503
+// is returned when any of the checks failed.
+type Ready struct{}
+
+// ReadyCheck contains the result of a single readiness check.
+type ReadyCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+// ReadyReply is the reply to the Ready command. Status is only ok when all of
+// the checks succeeded.
+type ReadyReply struct {
+	Status string       `json:"status"`
+	Checks []ReadyCheck `json:"checks"`
 }
 
 // VoteOption describes a single vote option.
@@ -1100,14 +1300,31 @@ type CastVote struct {
 	Signature string `json:"signature"` // Signature of Token+Ticket+VoteBit
 }
 
+// CastVoteErrorT represents an error that occurred while casting a vote. The
+// error codes are the decredplugin error codes. They are defined here so that
+// the API types do not depend on the decredplugin.
+//
+// These must stay in until the deprecated cast votes route is removed.
+type CastVoteErrorT int
+
+const (
+	CastVoteErrorInvalid          CastVoteErrorT = 0
+	CastVoteErrorInternalError    CastVoteErrorT = 1
+	CastVoteErrorProposalNotFound CastVoteErrorT = 2
+	CastVoteErrorInvalidVoteBit   CastVoteErrorT = 3
+	CastVoteErrorVoteHasEnded     CastVoteErrorT = 4
+	CastVoteErrorDuplicateVote    CastVoteErrorT = 5
+	CastVoteErrorIneligibleTicket CastVoteErrorT = 6
+)
+
 // CastVoteReply is the answer to the CastVote command. The Error and
 // ErrorStatus fields will only be populated if something went wrong while
 // attempting to cast the vote.
 type CastVoteReply struct {
-	ClientSignature string                    `json:"clientsignature"`       // Signature that was sent in
-	Signature       string                    `json:"signature"`             // Signature of the ClientSignature
-	Error           string                    `json:"error"`                 // Error status message
-	ErrorStatus     decredplugin.ErrorStatusT `json:"errorstatus,omitempty"` // Error status code
+	ClientSignature string         `json:"clientsignature"`       // Signature that was sent in
+	Signature       string         `json:"signature"`             // Signature of the ClientSignature
+	Error           string         `json:"error"`                 // Error status message
+	ErrorStatus     CastVoteErrorT `json:"errorstatus,omitempty"` // Error status code
 }
 
 // Ballot is a batch of votes that are sent to the server.
@@ -1335,9 +1552,51 @@ type ManageUser struct {
 // ManageUserReply is the reply for the ManageUserReply command.
 type ManageUserReply struct{}
 
+// NewBot creates a bot account. Bot accounts are used by community bots, e.g.
+// vote tally posters and reminder bots. A bot account does not have a
+// password and can only authenticate using the API key that is returned in
+// the reply. The API key is sent in the APIKey header of every request. Bot
+// accounts are exempt from the registration paywall, can not submit
+// proposals, and have their comments rate limited.
+//
+// PublicKey is the identity that the bot uses to sign its comments.
+type NewBot struct {
+	Username  string `json:"username"`
+	PublicKey string `json:"publickey"`
+}
+
+// NewBotReply is the reply to the NewBot command. The API key is only
+// returned once and can not be retrieved afterwards.
+type NewBotReply struct {
+	UserID string `json:"userid"`
+	APIKey string `json:"apikey"`
+}
+
+// BotAPIKey issues a new API key for a bot account. The previous API key is
+// revoked.
+type BotAPIKey struct {
+	UserID string `json:"userid"`
+}
+
+// BotAPIKeyReply is the reply to the BotAPIKey command.
+type BotAPIKeyReply struct {
+	APIKey string `json:"apikey"`
+}
+
 // EditUser edits a user's preferences.
+//
+// TimeZone is an IANA time zone name, e.g. "America/Chicago", and Locale is
+// one of the locales that are returned in the PolicyReply. They are used to
+// format the dates in notification emails. An empty string resets the
+// preference to the default, which is UTC and en-US respectively.
+//
+// EmailDigest sets whether the notification emails are sent immediately or
+// are batched into a daily or weekly digest email.
 type EditUser struct {
-	EmailNotifications *uint64 `json:"emailnotifications"` // Notify the user via emails
+	EmailNotifications *uint64       `json:"emailnotifications"`    // Notify the user via emails
+	TimeZone           *string       `json:"timezone,omitempty"`    // Email time zone
+	Locale             *string       `json:"locale,omitempty"`      // Email locale
+	EmailDigest        *EmailDigestT `json:"emaildigest,omitempty"` // Email digest
 }
 
 // EditUserReply is the reply for the EditUser command.
@@ -1349,6 +1608,7 @@ type User struct {
 	Email                           string         `json:"email"`
 	Username                        string         `json:"username"`
 	Admin                           bool           `json:"isadmin"`
+	Bot                             bool           `json:"isbot,omitempty"`
 	NewUserPaywallAddress           string         `json:"newuserpaywalladdress"`
 	NewUserPaywallAmount            uint64         `json:"newuserpaywallamount"`
 	NewUserPaywallTx                string         `json:"newuserpaywalltx"`
@@ -1367,6 +1627,254 @@ type User struct {
 	Identities                      []UserIdentity `json:"identities"`
 	ProposalCredits                 uint64         `json:"proposalcredits"`
 	EmailNotifications              uint64         `json:"emailnotifications"` // Notify the user via emails
+	EmailSuppressed                 bool           `json:"emailsuppressed"`    // Emails are not sent to the user
+	EmailSuppressedReason           string         `json:"emailsuppressedreason,omitempty"`
+	TimeZone                        string         `json:"timezone,omitempty"`      // Email time zone
+	Locale                          string         `json:"locale,omitempty"`        // Email locale
+	EmailDigest                     EmailDigestT   `json:"emaildigest,omitempty"`   // Email digest
+	StakeVerified                   int64          `json:"stakeverified,omitempty"` // Unix timestamp of stake verification
+}
+
+const (
+	// MailFeedbackTypeBounce indicates that an email bounced.
+	MailFeedbackTypeBounce = "bounce"
+
+	// MailFeedbackTypeComplaint indicates that the recipient marked an
+	// email as spam.
+	MailFeedbackTypeComplaint = "complaint"
+)
+
+// MailFeedback is sent by a mail provider webhook or a mailbox feedback loop
+// processor to report an email bounce or complaint. The request must include
+// the MailFeedbackToken header.
+//
+// Complaints and permanent bounces suppress the email address immediately.
+// Temporary bounces suppress the email address once the soft bounce limit has
+// been reached.
+type MailFeedback struct {
+	Type      string `json:"type"`      // Bounce or complaint
+	Email     string `json:"email"`     // Recipient email address
+	Permanent bool   `json:"permanent"` // Bounce was permanent
+	Reason    string `json:"reason"`    // Provider diagnostic
+}
+
+// MailFeedbackReply is the reply to the MailFeedback command.
+type MailFeedbackReply struct {
+	Suppressed bool `json:"suppressed"`
+}
+
+const (
+	// NotificationLogPageSize is the maximum number of entries that are
+	// returned by the NotificationLog command.
+	NotificationLogPageSize = 100
+)
+
+// The following are the statuses of a notification send attempt.
+const (
+	NotificationStatusSent       = "sent"       // Accepted by the receiver
+	NotificationStatusRetrying   = "retrying"   // Send failed and will be retried
+	NotificationStatusFailed     = "failed"     // Send failed permanently
+	NotificationStatusSuppressed = "suppressed" // Recipient address is suppressed
+)
+
+// The following are the channels that notifications are delivered through.
+const (
+	NotificationChannelEmail   = "email"   // Notification email
+	NotificationChannelWebhook = "webhook" // Webhook POST
+)
+
+// NotificationLog returns the most recent notification send attempts, newest
+// first. The send attempts of both the notification emails and the webhook
+// deliveries are returned. The entries can be filtered by recipient, user,
+// message ID, event type, and channel. This is an admin only GET request. The
+// parameters are sent as URL query parameters.
+//
+// The message ID is the ID that the notification email was queued with or the
+// webhook delivery ID. All send attempts of a notification share the same
+// message ID. The recipient of a webhook delivery is the webhook URL without
+// its query string. The UserID filter matches the email address of the user.
+// When Failures is set, only the failed send attempts are returned.
+type NotificationLog struct {
+	Recipient string `json:"recipient,omitempty"` // Recipient address
+	UserID    string `json:"userid,omitempty"`    // Recipient user ID
+	MessageID string `json:"messageid,omitempty"` // Notification ID
+	Event     string `json:"event,omitempty"`     // Notification event type
+	Channel   string `json:"channel,omitempty"`   // Delivery channel
+	Failures  bool   `json:"failures,omitempty"`  // Only failed attempts
+}
+
+// NotificationLogEntry is a notification send attempt for a single recipient.
+// Error is only set when the send failed. Channel is empty for the emails that
+// were recorded before webhook deliveries were recorded.
+type NotificationLogEntry struct {
+	MessageID string `json:"messageid"`
+	Event     string `json:"event"`
+	Channel   string `json:"channel,omitempty"`
+	Recipient string `json:"recipient"`
+	Status    string `json:"status"`
+	Attempt   uint32 `json:"attempt"`
+	Error     string `json:"error,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// NotificationLogReply is the reply to the NotificationLog command.
+type NotificationLogReply struct {
+	Entries []NotificationLogEntry `json:"entries"`
+}
+
+// NotificationRetry retriggers the delivery of a notification that failed
+// permanently. Notification emails are requeued and are sent again with a
+// fresh set of attempts. Webhook deliveries are resent to the URLs that they
+// failed for. Only the most recent failed webhook deliveries are kept by the
+// server. The failed webhook deliveries are persisted so that they can be
+// retried after a restart. This is an admin only request.
+type NotificationRetry struct {
+	MessageID string `json:"messageid"`
+}
+
+// NotificationRetryReply is the reply to the NotificationRetry command. It
+// contains the channel that the notification is retried through.
+type NotificationRetryReply struct {
+	Channel string `json:"channel"`
+}
+
+// The following are the types of the verification tokens that are sent to
+// users by email.
+const (
+	VerificationTypeNewUser       = "newuser"
+	VerificationTypeUpdateKey     = "updatekey"
+	VerificationTypeResetPassword = "resetpassword"
+)
+
+// Verifications returns the verification tokens that have been sent to users
+// and that have not been used. The verifications can be filtered by type and
+// expired verifications can be excluded. This is an admin only GET request.
+// The parameters are sent as URL query parameters.
+//
+// Expired verification tokens are cleared periodically by the server.
+// Accounts that were never verified are deactivated once their new user
+// verification token has been expired for the configured retention period.
+// Deactivated accounts are not returned.
+type Verifications struct {
+	Type           string `json:"type,omitempty"`           // Verification type
+	ExcludeExpired bool   `json:"excludeexpired,omitempty"` // Exclude expired tokens
+}
+
+// Verification is a verification token that has been sent to a user. The
+// token itself is not returned.
+type Verification struct {
+	UserID   string `json:"userid"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Type     string `json:"type"`
+	Expiry   int64  `json:"expiry"`  // Token expiration UNIX timestamp
+	Expired  bool   `json:"expired"` // Token has expired
+}
+
+// VerificationsReply is the reply to the Verifications command. The
+// verifications are sorted by expiration, soonest first.
+type VerificationsReply struct {
+	Verifications []Verification `json:"verifications"`
+}
+
+// Unsubscribe disables the email notifications of a user without requiring
+// the user to be logged in. The unsubscribe links that are included in
+// notification emails point to this route. The parameters are sent as URL
+// query parameters so that the route supports one-click unsubscribe (RFC
+// 8058). Only POST requests disable the notifications. A GET request returns a
+// page that asks the user to confirm the unsubscribe.
+//
+// Notifications is the bit field of the notifications to disable. Token is
+// the hex encoded server signature of the email address and notifications
+// that is created when the notification email is sent.
+type Unsubscribe struct {
+	Email         string `schema:"email"` // User email address
+	Notifications uint64 `schema:"ntfn"`  // Notifications to disable
+	Token         string `schema:"token"` // Server unsubscribe token
+}
+
+// UnsubscribeReply is the reply to the Unsubscribe command. It contains the
+// updated email notification setting of the user.
+type UnsubscribeReply struct {
+	EmailNotifications uint64 `json:"emailnotifications"`
+}
+
+const (
+	// ACLListAdminAllow is the network access control list that
+	// contains the networks that are allowed to access the admin
+	// routes. An empty list allows all networks.
+	ACLListAdminAllow = "adminallow"
+
+	// ACLListDeny is the network access control list that contains
+	// the networks that are denied access to all routes.
+	ACLListDeny = "deny"
+)
+
+// ACLEntry is a network that is part of a network access control list. An
+// expiry of zero means that the entry does not expire.
+type ACLEntry struct {
+	CIDR   string `json:"cidr"`
+	Expiry int64  `json:"expiry"` // Unix timestamp
+	Reason string `json:"reason,omitempty"`
+}
+
+// ACL requests the network access control lists. This is an admin only
+// route.
+type ACL struct{}
+
+// ACLReply is the reply to the ACL command.
+type ACLReply struct {
+	AdminAllow []ACLEntry `json:"adminallow"`
+	Deny       []ACLEntry `json:"deny"`
+}
+
+// SetACL adds a network to or removes a network from one of the network
+// access control lists. The CIDR may also be a single IP address. A TTL of
+// zero adds an entry that does not expire. Runtime changes are not persisted
+// and are lost when politeiawww is restarted. This is an admin only route.
+type SetACL struct {
+	List   string `json:"list"`   // ACL list
+	CIDR   string `json:"cidr"`   // Network or IP address
+	TTL    int64  `json:"ttl"`    // In seconds
+	Reason string `json:"reason"` // Optional
+	Remove bool   `json:"remove"` // Remove the entry instead
+}
+
+// SetACLReply is the reply to the SetACL command. It contains the updated
+// network access control lists.
+type SetACLReply struct {
+	ACL ACLReply `json:"acl"`
+}
+
+// Maintenance requests the maintenance mode status of the server. While the
+// server is in maintenance mode, all read routes keep working and all write
+// routes return a 503 with the ErrorStatusMaintenance error code. The reply
+// contains a Retry-After header with the number of seconds after which the
+// request can be retried.
+type Maintenance struct{}
+
+// MaintenanceReply is the reply to the Maintenance command. Since and
+// RetryAfter are only set when maintenance mode is enabled.
+type MaintenanceReply struct {
+	Enabled    bool   `json:"enabled"`
+	Message    string `json:"message,omitempty"`
+	Since      int64  `json:"since,omitempty"`      // Unix timestamp
+	RetryAfter int64  `json:"retryafter,omitempty"` // In seconds
+}
+
+// SetMaintenance enables or disables maintenance mode. The message is
+// returned to clients in the context of the ErrorStatusMaintenance errors.
+// Runtime changes are not persisted and are lost when politeiawww is
+// restarted. This is an admin only route.
+type SetMaintenance struct {
+	Enabled bool   `json:"enabled"`
+	Message string `json:"message"` // Optional
+}
+
+// SetMaintenanceReply is the reply to the SetMaintenance command. It contains
+// the updated maintenance mode status.
+type SetMaintenanceReply struct {
+	Maintenance MaintenanceReply `json:"maintenance"`
 }
 
 // UserIdentity represents a user's unique identity.
@@ -1436,6 +1944,22 @@ const (
 	WSCError     = "error"
 	WSCPing      = "ping"
 	WSCSubscribe = "subscribe"
+	WSCEvent     = "event"
+)
+
+// Websocket events. The events are subscriptions that require an
+// authenticated websocket. A subscribed client receives a WSEvent when the
+// event occurs.
+const (
+	WSEventProposalNew    = "proposal-new"    // Proposal made public
+	WSEventProposalStatus = "proposal-status" // Public proposal status change
+	WSEventVoteStarted    = "vote-started"
+	WSEventVoteFinished   = "vote-finished"
+	WSEventCommentNew     = "comment-new" // New comment on a public proposal
+
+	// WSSubscribeTokensMax is the maximum number of record tokens that
+	// the events of a subscription can be filtered on.
+	WSSubscribeTokensMax = 100
 )
 
 // WSHeader is required to be sent before any other command. The point is to
@@ -1456,8 +1980,13 @@ type WSError struct {
 
 // WSSubscribe is a client side push to tell the server what RPCs it wishes to
 // subscribe to.
+//
+// The events can be limited to the records that the client is watching by
+// providing the full record tokens. The events of all records are sent when
+// no tokens are provided.
 type WSSubscribe struct {
-	RPCS []string `json:"rpcs"` // Commands that the client wants to subscribe to
+	RPCS   []string `json:"rpcs"`             // Commands that the client wants to subscribe to
+	Tokens []string `json:"tokens,omitempty"` // Record tokens to filter the events on
 }
 
 // WSPing is a server side push to the client to see if it is still alive.
@@ -1465,6 +1994,19 @@ type WSPing struct {
 	Timestamp int64 `json:"timestamp"` // Server side timestamp
 }
 
+// WSEvent is a server side push that notifies the client of an event that it
+// has subscribed to. The event only contains the record token and the data
+// that changed. The client is expected to fetch the updated record data that
+// it requires. Status contains the human readable record status of the
+// proposal events and the human readable vote status of the vote events.
+type WSEvent struct {
+	Event     string `json:"event"`               // Event type
+	Token     string `json:"token"`               // Record token
+	Status    string `json:"status,omitempty"`    // Record or vote status
+	CommentID uint32 `json:"commentid,omitempty"` // New comment ID
+	Timestamp int64  `json:"timestamp"`           // Server side timestamp
+}
+
 // SetTOTP attempts to set a TOTP key for the chosen TOTP type (Basic/UFI2 etc).
 // When the user issues this request, the server generates a new key pair for
 // them and returns the key/image that will allow them to save it to their
@@ -1495,3 +2037,21 @@ type VerifyTOTP struct {
 // with no errors.
 type VerifyTOTPReply struct {
 }
+
+// StakeVerificationMsg is the message that must be signed to verify stake.
+// The %v is replaced with the user ID.
+const StakeVerificationMsg = "politeia stake verification %v"
+
+// VerifyStake verifies that the user controls a live ticket. The signature is
+// the hex encoded signature of the StakeVerificationMsg of the user, created
+// using the largest commitment address of the ticket, e.g. using the wallet
+// signmessage command. A ticket can only be used to verify a single user.
+type VerifyStake struct {
+	Ticket    string `json:"ticket"`    // Ticket hash
+	Signature string `json:"signature"` // Signature of StakeVerificationMsg
+}
+
+// VerifyStakeReply is the reply to the VerifyStake command.
+type VerifyStakeReply struct {
+	StakeVerified int64 `json:"stakeverified"` // Unix timestamp of verification
+}
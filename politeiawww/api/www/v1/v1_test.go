@@ -27,4 +27,8 @@ func TestMaps(t *testing.T) {
 	if err != nil {
 		t.Fatalf("UserManageAction: %v", err)
 	}
+	err = unittest.TestGenericConstMap(EmailDigests, uint64(EmailDigestLast))
+	if err != nil {
+		t.Fatalf("EmailDigests: %v", err)
+	}
 }
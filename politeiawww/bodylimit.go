@@ -0,0 +1,202 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/util"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// bodySizeMaxDefault is the maximum request body size of the routes
+	// that do not have a route specific limit.
+	bodySizeMaxDefault int64 = 1 << 20 // 1 MiB
+
+	// bodySizeOverhead is added to the limits that are derived from
+	// the file size policies in order to account for the JSON encoding,
+	// the metadata, and the signatures of a request.
+	bodySizeOverhead int64 = 64 << 10 // 64 KiB
+
+	// recordTextFilesMax is the number of text files that a proposal
+	// can contain, i.e. the index file, the proposal metadata, and the
+	// vote metadata.
+	recordTextFilesMax = 3
+
+	// ballotSizeMax is the maximum request body size of a cast ballot.
+	// It allows a single ballot to contain the votes of every ticket
+	// that is eligible to vote on mainnet.
+	ballotSizeMax int64 = 32 << 20 // 32 MiB
+)
+
+var (
+	// errBodyTooLarge is returned by a request body that has exceeded
+	// the maximum body size of the route.
+	errBodyTooLarge = errors.New("request body too large")
+)
+
+// filesSizeMax returns the maximum request body size of a route that accepts
+// files of the provided total size. File payloads are base64 encoded.
+func filesSizeMax(size int64) int64 {
+	return size*4/3 + bodySizeOverhead
+}
+
+// bodyLimits contains the maximum request body sizes of the routes. The
+// limits must be set before the server starts accepting requests.
+type bodyLimits struct {
+	def    int64
+	limits map[string]int64 // [route]maxBytes
+}
+
+// newBodyLimits returns a new bodyLimits that uses the provided default for
+// the routes that do not have a route specific limit.
+func newBodyLimits(def int64) *bodyLimits {
+	return &bodyLimits{
+		def:    def,
+		limits: make(map[string]int64, 16),
+	}
+}
+
+// set sets the maximum request body size of a route. The route must include
+// the API version prefix.
+func (l *bodyLimits) set(route string, max int64) {
+	log.Debugf("Body size limit %v: %v bytes", route, max)
+
+	l.limits[route] = max
+}
+
+// max returns the maximum request body size of the route that matched the
+// request.
+func (l *bodyLimits) max(r *http.Request) int64 {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return l.def
+	}
+	tmpl, err := route.GetPathTemplate()
+	if err != nil {
+		return l.def
+	}
+	max, ok := l.limits[tmpl]
+	if !ok {
+		return l.def
+	}
+	return max
+}
+
+// limitedBody is a request body that returns errBodyTooLarge once more than
+// the maximum number of bytes have been read. The body is read as a stream
+// by the request handlers, so a request that exceeds the limit is rejected
+// without the remainder of the request being read into memory.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	exceeded  bool
+}
+
+// Read reads from the underlying request body.
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.exceeded {
+		return 0, errBodyTooLarge
+	}
+	// Read one byte past the limit so that a body that is exactly the
+	// limit is not rejected.
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.remaining = 0
+		b.exceeded = true
+		return n, errBodyTooLarge
+	}
+	b.remaining -= int64(n)
+	return n, err
+}
+
+// bodyLimitWriter replaces the reply to a request whose body has exceeded
+// the maximum body size with a 413. This allows the request handlers to
+// treat the read error like any other decoding error.
+type bodyLimitWriter struct {
+	http.ResponseWriter
+	r        *http.Request
+	body     *limitedBody
+	max      int64
+	rejected bool
+}
+
+// WriteHeader writes the reply header.
+func (w *bodyLimitWriter) WriteHeader(code int) {
+	if !w.body.exceeded {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.rejected {
+		return
+	}
+	w.rejected = true
+	respondBodyTooLarge(w.ResponseWriter, w.r, w.max)
+}
+
+// Write writes the reply body. The reply body of the handler is discarded
+// when the request has been rejected.
+func (w *bodyLimitWriter) Write(b []byte) (int, error) {
+	if !w.rejected && w.body.exceeded {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.rejected {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// respondBodyTooLarge replies with a 413 and the maximum body size of the
+// route.
+func respondBodyTooLarge(w http.ResponseWriter, r *http.Request, max int64) {
+	log.Debugf("%v request body too large: %v %v max %v bytes",
+		util.RemoteAddr(r), r.Method, r.URL, max)
+
+	util.RespondWithJSON(w, http.StatusRequestEntityTooLarge, www.UserError{
+		ErrorCode: www.ErrorStatusRequestTooLarge,
+		ErrorContext: []string{
+			fmt.Sprintf("max request body size is %v bytes", max),
+		},
+	})
+}
+
+// middleware enforces the maximum request body size of the route. Requests
+// whose content length exceeds the limit are rejected before the handler is
+// called. The body of all other requests is limited while it is being read.
+func (l *bodyLimits) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost && r.Method != http.MethodPut {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		max := l.max(r)
+		if r.ContentLength > max {
+			respondBodyTooLarge(w, r, max)
+			return
+		}
+
+		body := &limitedBody{
+			ReadCloser: r.Body,
+			remaining:  max,
+		}
+		r.Body = body
+		next.ServeHTTP(&bodyLimitWriter{
+			ResponseWriter: w,
+			r:              r,
+			body:           body,
+			max:            max,
+		}, r)
+	})
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/gorilla/mux"
+)
+
+func TestBodyLimitsMiddleware(t *testing.T) {
+	l := newBodyLimits(16)
+	l.set("/v1/large", 64)
+
+	// The handler decodes the body as a stream and replies with a 400
+	// on decoding errors, like the request handlers do.
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var v interface{}
+		err := json.NewDecoder(r.Body).Decode(&v)
+		if err != nil {
+			RespondWithError(w, r, 0, "decode", www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			})
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	router := mux.NewRouter()
+	router.Use(l.middleware)
+	router.HandleFunc("/v1/small", handler).Methods(http.MethodPost)
+	router.HandleFunc("/v1/large", handler).Methods(http.MethodPost)
+
+	body := `"` + strings.Repeat("a", 30) + `"`
+	var tests = []struct {
+		name          string
+		route         string
+		contentLength bool
+		want          int
+	}{
+		{"content length exceeded", "/v1/small", true, http.StatusRequestEntityTooLarge},
+		{"stream exceeded", "/v1/small", false, http.StatusRequestEntityTooLarge},
+		{"route limit", "/v1/large", false, http.StatusOK},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, v.route,
+				strings.NewReader(body))
+			if !v.contentLength {
+				r.ContentLength = -1
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+			if w.Code != v.want {
+				t.Fatalf("got code %v, want %v", w.Code, v.want)
+			}
+			if v.want != http.StatusRequestEntityTooLarge {
+				return
+			}
+			var ue www.UserError
+			err := json.Unmarshal(w.Body.Bytes(), &ue)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ue.ErrorCode != www.ErrorStatusRequestTooLarge {
+				t.Fatalf("got error %v, want %v", ue.ErrorCode,
+					www.ErrorStatusRequestTooLarge)
+			}
+		})
+	}
+}
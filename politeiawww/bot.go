@@ -0,0 +1,235 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/sessions"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// botEmailDomain is the domain of the placeholder email addresses of
+	// bot accounts. The user database requires a unique email address
+	// for every user. The .invalid TLD is reserved by RFC 2606 so the
+	// addresses can never be delivered to.
+	botEmailDomain = "bot.invalid"
+
+	// botPaywallTx is the registration paywall tx of bot accounts. Bot
+	// accounts are exempt from the registration paywall.
+	botPaywallTx = "bot_account"
+
+	// botEmailSuppressedReason is the email suppression reason of bot
+	// accounts. Emails are never sent to bot accounts.
+	botEmailSuppressedReason = "bot account"
+)
+
+// handleNewBot handles the creation of a bot account by an admin.
+func (p *politeiawww) handleNewBot(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleNewBot")
+
+	var nb www.NewBot
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&nb); err != nil {
+		RespondWithError(w, r, 0, "handleNewBot: unmarshal",
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	adminUser, err := p.sessions.GetSessionUser(w, r)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleNewBot: getSessionUser %v", err)
+		return
+	}
+
+	nbr, err := p.processNewBot(nb, adminUser)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleNewBot: processNewBot %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, nbr)
+}
+
+// handleBotAPIKey handles the issuance of a new API key for a bot account by
+// an admin.
+func (p *politeiawww) handleBotAPIKey(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleBotAPIKey")
+
+	var bk www.BotAPIKey
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&bk); err != nil {
+		RespondWithError(w, r, 0, "handleBotAPIKey: unmarshal",
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	adminUser, err := p.sessions.GetSessionUser(w, r)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleBotAPIKey: getSessionUser %v", err)
+		return
+	}
+
+	bkr, err := p.processBotAPIKey(bk, adminUser)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleBotAPIKey: processBotAPIKey %v",
+			err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, bkr)
+}
+
+// processNewBot creates a new bot account and returns its API key. Bot
+// accounts are verified on creation, are exempt from the registration
+// paywall, and never receive emails.
+func (p *politeiawww) processNewBot(nb www.NewBot, adminUser *user.User) (*www.NewBotReply, error) {
+	log.Tracef("processNewBot: %v", nb.Username)
+
+	// Validate the bot credentials
+	nb.Username = formatUsername(nb.Username)
+	err := validateUsername(nb.Username)
+	if err != nil {
+		return nil, err
+	}
+	err = validatePubKey(nb.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure username is unique
+	_, err = p.db.UserGetByUsername(nb.Username)
+	switch {
+	case err == nil:
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusDuplicateUsername,
+		}
+	case !errors.Is(err, user.ErrUserNotFound):
+		return nil, err
+	}
+
+	// Ensure public key is unique
+	_, err = p.db.UserGetByPubKey(nb.PublicKey)
+	switch {
+	case err == nil:
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusDuplicatePublicKey,
+		}
+	case !errors.Is(err, user.ErrUserNotFound):
+		return nil, err
+	}
+
+	// Create the bot user. The identity is activated right away since
+	// there is no email address to verify.
+	bot := user.User{
+		Email:                 fmt.Sprintf("%v@%v", nb.Username, botEmailDomain),
+		Username:              nb.Username,
+		Bot:                   true,
+		NewUserPaywallTx:      botPaywallTx,
+		EmailSuppressed:       true,
+		EmailSuppressedReason: botEmailSuppressedReason,
+	}
+	id, err := user.NewIdentity(nb.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	err = bot.AddIdentity(*id)
+	if err != nil {
+		return nil, err
+	}
+	err = bot.ActivateIdentity(id.Key[:])
+	if err != nil {
+		return nil, err
+	}
+	err = p.db.UserNew(bot)
+	if err != nil {
+		return nil, err
+	}
+
+	// The API key contains the user ID, which is set by the database.
+	// Lookup the user to get it.
+	u, err := p.db.UserGetByUsername(bot.Username)
+	if err != nil {
+		return nil, err
+	}
+	key, err := p.newBotAPIKey(u)
+	if err != nil {
+		return nil, err
+	}
+	p.setUserEmailsCache(u.Email, u.ID)
+
+	err = p.logAdminAction(adminUser, fmt.Sprintf("NewBot,%v,%v",
+		u.ID, u.Username))
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Bot account created: %v", u.Username)
+
+	return &www.NewBotReply{
+		UserID: u.ID.String(),
+		APIKey: key,
+	}, nil
+}
+
+// processBotAPIKey issues a new API key for a bot account. The previous API
+// key is revoked.
+func (p *politeiawww) processBotAPIKey(bk www.BotAPIKey, adminUser *user.User) (*www.BotAPIKeyReply, error) {
+	log.Tracef("processBotAPIKey: %v", bk.UserID)
+
+	u, err := p.userByIDStr(bk.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !u.Bot {
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"user is not a bot"},
+		}
+	}
+	key, err := p.newBotAPIKey(u)
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.logAdminAction(adminUser, fmt.Sprintf("BotAPIKey,%v,%v",
+		u.ID, u.Username))
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Bot API key rotated: %v", u.Username)
+
+	return &www.BotAPIKeyReply{
+		APIKey: key,
+	}, nil
+}
+
+// newBotAPIKey creates a new API key for a bot account and saves its hash to
+// the user database. Any previous API key of the bot stops working.
+func (p *politeiawww) newBotAPIKey(u *user.User) (string, error) {
+	key, hash, err := sessions.NewAPIKey(u.ID)
+	if err != nil {
+		return "", err
+	}
+	u.APIKeyHash = hash
+	u.APIKeyCreated = time.Now().Unix()
+	err = p.db.UserUpdate(*u)
+	if err != nil {
+		return "", err
+	}
+	return key, nil
+}
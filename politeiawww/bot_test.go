@@ -0,0 +1,80 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+)
+
+func TestProcessNewBot(t *testing.T) {
+	p, cleanup := newTestPoliteiawww(t)
+	defer cleanup()
+
+	admin, _ := newUser(t, p, true, true)
+	id, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nb := www.NewBot{
+		Username:  "tallybot",
+		PublicKey: id.Public.String(),
+	}
+
+	// authenticate returns the username of the user that the API key
+	// authenticates as.
+	authenticate := func(key string) string {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set(www.APIKey, key)
+		u, err := p.sessions.GetSessionUser(httptest.NewRecorder(), r)
+		if err != nil {
+			return ""
+		}
+		return u.Username
+	}
+
+	nbr, err := p.processNewBot(nb, admin)
+	if err != nil {
+		t.Fatalf("processNewBot: %v", err)
+	}
+	if got := authenticate(nbr.APIKey); got != nb.Username {
+		t.Fatalf("got user '%v', want '%v'", got, nb.Username)
+	}
+	if got := authenticate(nbr.UserID + ".00"); got != "" {
+		t.Fatalf("invalid API key authenticated as '%v'", got)
+	}
+
+	// Duplicate username
+	_, err = p.processNewBot(nb, admin)
+	got := errToStr(err)
+	want := www.ErrorStatus[www.ErrorStatusDuplicateUsername]
+	if got != want {
+		t.Fatalf("got error %v, want %v", got, want)
+	}
+
+	// The previous API key is revoked when a new one is issued
+	bkr, err := p.processBotAPIKey(www.BotAPIKey{UserID: nbr.UserID}, admin)
+	if err != nil {
+		t.Fatalf("processBotAPIKey: %v", err)
+	}
+	if got := authenticate(nbr.APIKey); got != "" {
+		t.Fatalf("revoked API key authenticated as '%v'", got)
+	}
+	if got := authenticate(bkr.APIKey); got != nb.Username {
+		t.Fatalf("got user '%v', want '%v'", got, nb.Username)
+	}
+
+	// Regular users can not be given an API key
+	_, err = p.processBotAPIKey(www.BotAPIKey{UserID: admin.ID.String()},
+		admin)
+	got = errToStr(err)
+	want = www.ErrorStatus[www.ErrorStatusInvalidInput]
+	if got != want {
+		t.Fatalf("got error %v, want %v", got, want)
+	}
+}
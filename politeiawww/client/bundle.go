@@ -0,0 +1,335 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	"github.com/decred/politeia/politeiawww/client/internal/util"
+)
+
+// ProposalBundle contains all of the public data for a proposal along with
+// the signatures, receipts, and timestamps that are required to verify it.
+// A bundle can be verified fully offline using ProposalBundleVerify.
+//
+// Comment votes are only returned by the politeiawww API on a per user basis.
+// The bundle contains the comment votes of all users that have commented on
+// the proposal.
+type ProposalBundle struct {
+	Token        string `json:"token"`
+	ServerPubKey string `json:"serverpubkey"`
+
+	// Records contains every version of the record, ordered from the
+	// first version to the most recent version. The timestamps are
+	// ordered the same way.
+	Records          []rcv1.Record          `json:"records"`
+	RecordTimestamps []rcv1.TimestampsReply `json:"recordtimestamps"`
+
+	Comments          []cmv1.Comment       `json:"comments"`
+	CommentVotes      []cmv1.CommentVote   `json:"commentvotes"`
+	CommentTimestamps cmv1.TimestampsReply `json:"commenttimestamps"`
+
+	// CommentsNotTimestamped contains the IDs of the comments that had
+	// not been anchored onto the decred blockchain yet when the bundle
+	// was created.
+	CommentsNotTimestamped []uint32 `json:"commentsnottimestamped,omitempty"`
+
+	VoteDetails    tkv1.DetailsReply      `json:"votedetails"`
+	VoteResults    []tkv1.CastVoteDetails `json:"voteresults"`
+	VoteTimestamps tkv1.TimestampsReply   `json:"votetimestamps"`
+}
+
+// ProposalBundle fetches all public data for a proposal, verifies it, and
+// returns it as a single ProposalBundle.
+func (c *Client) ProposalBundle(token, serverPubKey string) (*ProposalBundle, error) {
+	b := ProposalBundle{
+		Token:        token,
+		ServerPubKey: serverPubKey,
+	}
+
+	// Get the most recent record version so that we know how many
+	// versions need to be fetched.
+	r, err := c.RecordDetails(rcv1.Details{
+		Token: token,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Get all record versions and their timestamps
+	b.Records = make([]rcv1.Record, 0, r.Version)
+	b.RecordTimestamps = make([]rcv1.TimestampsReply, 0, r.Version)
+	for i := uint32(1); i <= r.Version; i++ {
+		rv := *r
+		if i != r.Version {
+			v, err := c.RecordDetails(rcv1.Details{
+				Token:   token,
+				Version: i,
+			})
+			if err != nil {
+				return nil, err
+			}
+			rv = *v
+		}
+		tr, err := c.RecordTimestamps(rcv1.Timestamps{
+			Token:   token,
+			Version: i,
+		})
+		if err != nil {
+			return nil, err
+		}
+		b.Records = append(b.Records, rv)
+		b.RecordTimestamps = append(b.RecordTimestamps, *tr)
+	}
+
+	// Get comments
+	cr, err := c.Comments(cmv1.Comments{
+		Token: token,
+	})
+	if err != nil {
+		return nil, err
+	}
+	b.Comments = cr.Comments
+
+	// Get the comment votes of all users that have commented
+	users := make(map[string]struct{}, len(b.Comments))
+	for _, v := range b.Comments {
+		users[v.UserID] = struct{}{}
+	}
+	b.CommentVotes = make([]cmv1.CommentVote, 0, len(users))
+	for userID := range users {
+		vr, err := c.CommentVotes(cmv1.Votes{
+			Token:  token,
+			UserID: userID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		b.CommentVotes = append(b.CommentVotes, vr.Votes...)
+	}
+	sort.SliceStable(b.CommentVotes, func(i, j int) bool {
+		return b.CommentVotes[i].Timestamp < b.CommentVotes[j].Timestamp
+	})
+
+	// Get comment timestamps. The comment IDs must be requested in
+	// pages.
+	commentIDs := make([]uint32, 0, len(b.Comments))
+	for _, v := range b.Comments {
+		commentIDs = append(commentIDs, v.CommentID)
+	}
+	b.CommentTimestamps = cmv1.TimestampsReply{
+		Comments: make(map[uint32]cmv1.CommentTimestamp, len(commentIDs)),
+	}
+	for len(commentIDs) > 0 {
+		pageSize := int(cmv1.TimestampsPageSize)
+		if len(commentIDs) < pageSize {
+			pageSize = len(commentIDs)
+		}
+		tr, err := c.CommentTimestamps(cmv1.Timestamps{
+			Token:      token,
+			CommentIDs: commentIDs[:pageSize],
+		})
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range tr.Comments {
+			b.CommentTimestamps.Comments[k] = v
+		}
+		commentIDs = commentIDs[pageSize:]
+	}
+
+	// Get vote details and results
+	dr, err := c.TicketVoteDetails(tkv1.Details{
+		Token: token,
+	})
+	if err != nil {
+		return nil, err
+	}
+	b.VoteDetails = *dr
+	rr, err := c.TicketVoteResults(tkv1.Results{
+		Token: token,
+	})
+	if err != nil {
+		return nil, err
+	}
+	b.VoteResults = rr.Votes
+
+	// Get vote timestamps. The auth and vote details timestamps are
+	// returned when no votes page is provided. The cast vote
+	// timestamps are requested in pages until an empty page is
+	// returned.
+	tr, err := c.TicketVoteTimestamps(tkv1.Timestamps{
+		Token: token,
+	})
+	if err != nil {
+		return nil, err
+	}
+	b.VoteTimestamps = *tr
+	for page := uint32(1); len(b.VoteResults) > 0; page++ {
+		tr, err := c.TicketVoteTimestamps(tkv1.Timestamps{
+			Token:     token,
+			VotesPage: page,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(tr.Votes) == 0 {
+			break
+		}
+		b.VoteTimestamps.Votes = append(b.VoteTimestamps.Votes, tr.Votes...)
+	}
+
+	// Verify the bundle
+	err = ProposalBundleVerify(&b)
+	if err != nil {
+		return nil, err
+	}
+
+	return &b, nil
+}
+
+// ProposalBundleWrite writes the JSON encoded proposal bundle to the provided
+// writer.
+func ProposalBundleWrite(w io.Writer, b ProposalBundle) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(b)
+}
+
+// ProposalBundleRead reads a JSON encoded proposal bundle from the provided
+// reader. The bundle is not verified.
+func ProposalBundleRead(r io.Reader) (*ProposalBundle, error) {
+	var b ProposalBundle
+	err := json.NewDecoder(r).Decode(&b)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// ProposalBundleVerify verifies all of the signatures, receipts, and
+// timestamps contained in the proposal bundle. This does not require any
+// network access. The IDs of any comments that have not been anchored yet are
+// recorded in the bundle.
+func ProposalBundleVerify(b *ProposalBundle) error {
+	// Verify records
+	if len(b.Records) != len(b.RecordTimestamps) {
+		return fmt.Errorf("record and record timestamps count mismatch")
+	}
+	for i, r := range b.Records {
+		if r.CensorshipRecord.Token != b.Token {
+			return fmt.Errorf("record version %v: invalid token %v",
+				r.Version, r.CensorshipRecord.Token)
+		}
+		if r.Version != uint32(i+1) {
+			return fmt.Errorf("record version %v: expected version %v",
+				r.Version, i+1)
+		}
+		err := RecordVerify(r, b.ServerPubKey)
+		if err != nil {
+			return fmt.Errorf("record version %v: %v", r.Version, err)
+		}
+		err = RecordTimestampsVerify(b.RecordTimestamps[i])
+		if err != nil {
+			return fmt.Errorf("record version %v: %v", r.Version, err)
+		}
+	}
+
+	// Verify comments
+	for _, v := range b.Comments {
+		if v.Token != b.Token {
+			return fmt.Errorf("comment %v: invalid token %v",
+				v.CommentID, v.Token)
+		}
+		err := CommentVerify(v, b.ServerPubKey)
+		if err != nil {
+			return err
+		}
+	}
+	for _, v := range b.CommentVotes {
+		if v.Token != b.Token {
+			return fmt.Errorf("comment %v vote: invalid token %v",
+				v.CommentID, v.Token)
+		}
+		err := CommentVoteVerify(v, b.ServerPubKey)
+		if err != nil {
+			return err
+		}
+	}
+	notTimestamped, err := CommentTimestampsVerify(b.CommentTimestamps)
+	if err != nil {
+		return err
+	}
+	sort.Slice(notTimestamped, func(i, j int) bool {
+		return notTimestamped[i] < notTimestamped[j]
+	})
+	b.CommentsNotTimestamped = notTimestamped
+
+	// Verify vote data
+	for i, v := range b.VoteDetails.Auths {
+		err := AuthDetailsVerify(v, b.ServerPubKey)
+		if err != nil {
+			return fmt.Errorf("vote authorization %v: %v", i, err)
+		}
+	}
+	if b.VoteDetails.Vote != nil {
+		err := VoteDetailsVerify(*b.VoteDetails.Vote, b.ServerPubKey)
+		if err != nil {
+			return fmt.Errorf("vote details: %v", err)
+		}
+	}
+	for _, v := range b.VoteResults {
+		if v.Token != b.Token {
+			return fmt.Errorf("cast vote %v: invalid token %v",
+				v.Ticket, v.Token)
+		}
+		err := CastVoteDetailsVerify(v, b.ServerPubKey)
+		if err != nil {
+			return fmt.Errorf("cast vote %v: %v", v.Ticket, err)
+		}
+	}
+	err = TicketVoteTimestampsVerify(b.VoteTimestamps)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CommentVoteVerify verifies the signature and receipt of the provided
+// comments v1 CommentVote.
+func CommentVoteVerify(v cmv1.CommentVote, serverPublicKey string) error {
+	// Verify signature. The signature is the client signature of the
+	// State+Token+CommentID+Vote, followed by the Nonce+Expiry when a
+	// nonce was provided.
+	msg := strconv.FormatUint(uint64(v.State), 10) + v.Token +
+		strconv.FormatUint(uint64(v.CommentID), 10) +
+		strconv.FormatInt(int64(v.Vote), 10)
+	if v.Nonce != "" {
+		msg += v.Nonce + strconv.FormatInt(v.Expiry, 10)
+	}
+	err := util.VerifySignature(v.Signature, v.PublicKey, msg)
+	if err != nil {
+		return fmt.Errorf("unable to verify comment %v vote signature: %v",
+			v.CommentID, err)
+	}
+
+	// Verify receipt. The receipt is the server signature of the
+	// client signature.
+	err = util.VerifySignature(v.Receipt, serverPublicKey, v.Signature)
+	if err != nil {
+		return fmt.Errorf("unable to verify comment %v vote receipt: %v",
+			v.CommentID, err)
+	}
+
+	return nil
+}
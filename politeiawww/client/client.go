@@ -6,30 +6,53 @@ package client
 
 import (
 	"bytes"
+	"crypto/tls"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
 	"reflect"
+	"sync"
+	"time"
 
-	"github.com/decred/politeia/util"
+	"github.com/decred/politeia/politeiawww/client/internal/util"
 	"github.com/gorilla/schema"
 	"golang.org/x/net/publicsuffix"
 )
 
 var (
 	// HTTP headers
-	headerCSRF = "X-CSRF-Token"
+	headerCSRF           = "X-CSRF-Token"
+	headerCSRFSession    = "X-CSRF-Session-Token"
+	headerIdempotencyKey = "X-Idempotency-Key"
+	headerConsistency    = "X-Consistency-Token"
+	headerAPIKey         = "X-API-Key"
 )
 
 // Client provides a client for interacting with the politeiawww API.
 type Client struct {
-	host       string
-	headerCSRF string // Header csrf token
-	verbose    bool
-	rawJSON    bool
-	http       *http.Client
+	sync.RWMutex
+	host              string
+	headerCSRF        string // Header csrf token
+	headerCSRFSession string // Header csrf session token
+	apiKey            string // Bot account API key
+	verbose           bool
+	rawJSON           bool
+	http              *http.Client
+	metrics           Metrics
+	idempotencyKeys   bool
+	writeRetries      int
+	readYourWrites    bool
+
+	// consistencyToken is the consistency token of the most recent
+	// write. It is only used when read your writes is enabled.
+	consistencyToken string
+
+	// The following fields are set by Negotiate.
+	serverPubKey string
+	apiVersions  map[string]uint32 // [api]version
 }
 
 // makeReq makes a politeiawww http request to the method and route provided,
@@ -37,6 +60,29 @@ type Client struct {
 // slice of the response body. An ReqError is returned if politeiawww responds
 // with anything other than a 200 http status code.
 func (c *Client) makeReq(method string, api, route string, v interface{}) ([]byte, error) {
+	r, err := c.doReq(method, api, route, v)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	// Decode response body
+	respBody := util.RespBody(r)
+
+	// Print response body
+	if c.verbose || c.rawJSON {
+		fmt.Printf("%s\n", respBody)
+	}
+
+	return respBody, nil
+}
+
+// doReq makes a politeiawww http request to the method and route provided,
+// serializing the provided object as the request body. The response is
+// returned with the body unread so that it can be decoded as a stream. The
+// caller must close the response body. An ReqError is returned if
+// politeiawww responds with anything other than a 200 http status code.
+func (c *Client) doReq(method string, api, route string, v interface{}) (*http.Response, error) {
 	// Serialize body
 	var (
 		reqBody     []byte
@@ -72,7 +118,7 @@ func (c *Client) makeReq(method string, api, route string, v interface{}) ([]byt
 	}
 
 	// Setup route
-	fullRoute := c.host + api + route + queryParams
+	fullRoute := c.host + c.apiRoute(api) + route + queryParams
 
 	// Print request details
 	switch {
@@ -92,18 +138,21 @@ func (c *Client) makeReq(method string, api, route string, v interface{}) ([]byt
 	}
 
 	// Send request
-	req, err := http.NewRequest(method, fullRoute, bytes.NewReader(reqBody))
+	start := time.Now()
+	r, err := c.send(method, fullRoute, reqBody)
 	if err != nil {
+		c.observe(api, route, 0, start)
 		return nil, err
 	}
-	if c.headerCSRF != "" {
-		req.Header.Add(headerCSRF, c.headerCSRF)
-	}
-	r, err := c.http.Do(req)
-	if err != nil {
-		return nil, err
+	c.observe(api, route, r.StatusCode, start)
+
+	// Save the consistency token of a write
+	if token := r.Header.Get(headerConsistency); token != "" &&
+		c.readYourWrites {
+		c.Lock()
+		c.consistencyToken = token
+		c.Unlock()
 	}
-	defer r.Body.Close()
 
 	// Print response code
 	if c.verbose {
@@ -112,36 +161,110 @@ func (c *Client) makeReq(method string, api, route string, v interface{}) ([]byt
 
 	// Handle reply
 	if r.StatusCode != http.StatusOK {
+		defer r.Body.Close()
 		switch r.StatusCode {
 		case http.StatusNotFound:
 			return nil, fmt.Errorf("404 not found")
 		case http.StatusForbidden:
 			return nil, fmt.Errorf("403 %s", util.RespBody(r))
+		case http.StatusRequestEntityTooLarge:
+			return nil, fmt.Errorf("413 %s", util.RespBody(r))
+		case http.StatusTooManyRequests:
+			return nil, fmt.Errorf("429 retry after %vs: %s",
+				r.Header.Get("Retry-After"), util.RespBody(r))
 		default:
 			// All other http status codes should have a request body that
 			// decodes into a ErrorReply.
-			var e ErrorReply
-			decoder := json.NewDecoder(r.Body)
-			if err := decoder.Decode(&e); err != nil {
+			e, err := decodeErrorReply(api, r.Body)
+			if err != nil {
 				return nil, fmt.Errorf("status code %v: %v", r.StatusCode, err)
 			}
 			return nil, RespErr{
 				HTTPCode:   r.StatusCode,
 				API:        api,
-				ErrorReply: e,
+				ErrorReply: *e,
 			}
 		}
 	}
 
-	// Decode response body
-	respBody := util.RespBody(r)
+	return r, nil
+}
 
-	// Print response body
-	if c.verbose || c.rawJSON {
-		fmt.Printf("%s\n", respBody)
+// send sends an http request to politeiawww. Write requests are sent with an
+// idempotency key when idempotency keys are enabled. A write request that
+// fails with a transport error, e.g. a timeout, is retried using the same
+// idempotency key so that politeiawww does not execute it more than once.
+func (c *Client) send(method, fullRoute string, reqBody []byte) (*http.Response, error) {
+	var (
+		idempotencyKey string
+		retries        int
+		err            error
+	)
+	if c.idempotencyKeys && method != http.MethodGet {
+		idempotencyKey, err = newIdempotencyKey()
+		if err != nil {
+			return nil, err
+		}
+		retries = c.writeRetries
 	}
 
-	return respBody, nil
+	for i := 0; ; i++ {
+		req, err := http.NewRequest(method, fullRoute,
+			bytes.NewReader(reqBody))
+		if err != nil {
+			return nil, err
+		}
+		if c.headerCSRF != "" {
+			req.Header.Add(headerCSRF, c.headerCSRF)
+		}
+		if c.headerCSRFSession != "" {
+			req.Header.Add(headerCSRFSession, c.headerCSRFSession)
+		}
+		if c.apiKey != "" {
+			req.Header.Add(headerAPIKey, c.apiKey)
+		}
+		if idempotencyKey != "" {
+			req.Header.Add(headerIdempotencyKey, idempotencyKey)
+		}
+		if token := c.ConsistencyToken(); token != "" {
+			req.Header.Add(headerConsistency, token)
+		}
+		r, err := c.http.Do(req)
+		if err != nil && i < retries {
+			if c.verbose {
+				fmt.Printf("Request failed, retrying: %v\n", err)
+			}
+			continue
+		}
+		return r, err
+	}
+}
+
+// ConsistencyToken returns the consistency token of the most recent write
+// that was made by the client. An empty string is returned if read your
+// writes is not enabled or if no writes have been made.
+func (c *Client) ConsistencyToken() string {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.consistencyToken
+}
+
+// newIdempotencyKey returns a new random idempotency key.
+func newIdempotencyKey() (string, error) {
+	b, err := util.Random(16)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// observe records the request metrics if a metrics hook has been provided.
+func (c *Client) observe(api, route string, code int, start time.Time) {
+	if c.metrics == nil {
+		return
+	}
+	c.metrics.Observe(api, routeTemplate(route), code, time.Since(start))
 }
 
 // Opts contains the politeiawww client options. All values are optional.
@@ -150,14 +273,91 @@ func (c *Client) makeReq(method string, api, route string, v interface{}) ([]byt
 // allowing you to interact with a politeiawww instance that uses a self signed
 // cert.
 //
-// Authenticated routes require a CSRF cookie as well as the corresponding CSRF
-// header.
+// TLSConfig is used as the base TLS configuration of the http client, e.g. to
+// provide the root CAs of a private CA, a minimum TLS version, or client
+// certificates. The config is cloned. The HTTPSCert can only be used with a
+// TLSConfig that does not contain any root CAs. ClientCert and ClientKey are
+// the paths of a PEM encoded client certificate and key that are presented to
+// the server for mutual TLS, e.g. when politeiawww is behind a reverse proxy
+// that requires client certificates. Both must be provided together.
+//
+// Authenticated routes require either a CSRF session token header or a CSRF
+// cookie as well as the corresponding CSRF header. The cookie based CSRF token
+// has been DEPRECATED. A CSRF session token can be obtained using the
+// CSRFToken method.
+//
+// Proxy is the URL of an http, https, or socks5 proxy that all requests are
+// routed through, e.g. socks5://127.0.0.1:9050. Proxy credentials can be
+// included in the URL. ProxyIsolation uses random proxy credentials for each
+// request, which makes tor use a separate circuit for each request. It is only
+// supported for socks5 proxies.
+//
+// The transport options can be used by high volume consumers to tune
+// connection reuse. MaxIdleConns, MaxIdleConnsPerHost, MaxConnsPerHost, and
+// IdleConnTimeout map directly onto the fields of the same name in the
+// http.Transport. Timeout is the time limit for a single request, including
+// reading the response body. HTTP2 forces an attempt to use HTTP/2 and
+// DisableHTTP2 prevents it from being negotiated. Zero values use the
+// defaults.
+//
+// UnixSocket is the path of a unix domain socket that politeiawww is listening
+// on. When set, all requests are sent over the socket instead of a TCP
+// connection, which avoids the loopback overhead for colocated services. The
+// host is still used to build the request URLs, e.g. http://localhost. It can
+// not be used with a proxy.
+//
+// IdempotencyKeys sends a random idempotency key with every write request.
+// politeiawww replays the recorded reply to a request that is sent with an
+// idempotency key that it has already seen instead of executing the request
+// again. WriteRetries is the number of times that a write request that fails
+// with a transport error, e.g. a timeout, is retried using the same
+// idempotency key. Write requests are only retried when idempotency keys are
+// enabled.
+//
+// ReadYourWrites saves the consistency token that politeiawww returns in the
+// reply to a write request and sends it with every subsequent request. This
+// guarantees that the replies reflect the writes that were made by the client,
+// i.e. that they are not served from a cache that was populated before the
+// writes were made.
+//
+// APIKey is the API key of a bot account. It is sent with every request and
+// authenticates the client as the bot, so no cookies or CSRF tokens are
+// required.
+//
+// Metrics is an optional hook that is called for every request. It allows
+// services that embed the client to record request counters and latency
+// histograms without wrapping every call site.
 type Opts struct {
-	HTTPSCert  string
-	Cookies    []*http.Cookie
-	HeaderCSRF string
-	Verbose    bool // Print verbose output
-	RawJSON    bool // Print raw json
+	HTTPSCert         string
+	TLSConfig         *tls.Config
+	ClientCert        string
+	ClientKey         string
+	Cookies           []*http.Cookie
+	HeaderCSRF        string // Deprecated; use HeaderCSRFSession
+	HeaderCSRFSession string
+	APIKey            string
+	Proxy             string
+	ProxyIsolation    bool
+	UnixSocket        string
+
+	// Transport options
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+	IdleConnTimeout     time.Duration
+	Timeout             time.Duration
+	HTTP2               bool
+	DisableHTTP2        bool
+
+	// Write request options
+	IdempotencyKeys bool
+	WriteRetries    int
+	ReadYourWrites  bool
+
+	Metrics Metrics // Request metrics hook
+
+	Verbose bool // Print verbose output
+	RawJSON bool // Print raw json
 }
 
 // New returns a new politeiawww client.
@@ -168,6 +368,46 @@ func New(host string, opts Opts) (*Client, error) {
 		return nil, err
 	}
 
+	// Setup transport
+	tr, ok := h.Transport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("invalid http transport %T", h.Transport)
+	}
+	err = transportSetup(h, tr, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Setup TLS
+	err = tlsSetup(tr, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Setup unix socket
+	if opts.UnixSocket != "" {
+		if opts.Proxy != "" {
+			return nil, fmt.Errorf("unix socket can not be used with a proxy")
+		}
+		err = unixSocketSetup(tr, opts.UnixSocket)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Setup proxy. This must be done after the transport has been
+	// setup since proxy isolation overrides the connection settings.
+	if opts.Proxy != "" {
+		err = proxySetup(tr, opts.Proxy, opts.ProxyIsolation)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.WriteRetries < 0 {
+		return nil, fmt.Errorf("invalid write retries %v", opts.WriteRetries)
+	}
+
 	// Setup cookies
 	if opts.Cookies != nil {
 		copt := cookiejar.Options{
@@ -186,10 +426,16 @@ func New(host string, opts Opts) (*Client, error) {
 	}
 
 	return &Client{
-		host:       host,
-		headerCSRF: opts.HeaderCSRF,
-		verbose:    opts.Verbose,
-		rawJSON:    opts.RawJSON,
-		http:       h,
+		host:              host,
+		headerCSRF:        opts.HeaderCSRF,
+		headerCSRFSession: opts.HeaderCSRFSession,
+		apiKey:            opts.APIKey,
+		verbose:           opts.Verbose,
+		rawJSON:           opts.RawJSON,
+		http:              h,
+		metrics:           opts.Metrics,
+		idempotencyKeys:   opts.IdempotencyKeys,
+		writeRetries:      opts.WriteRetries,
+		readYourWrites:    opts.ReadYourWrites,
 	}, nil
 }
@@ -0,0 +1,274 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+
+	cms "github.com/decred/politeia/politeiawww/api/cms/v1"
+)
+
+// InvoiceNew sends a cms v1 NewInvoice request to politeiawww.
+func (c *Client) InvoiceNew(ni cms.NewInvoice) (*cms.NewInvoiceReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		cms.APIRoute, cms.RouteNewInvoice, ni)
+	if err != nil {
+		return nil, err
+	}
+
+	var nir cms.NewInvoiceReply
+	err = json.Unmarshal(resBody, &nir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nir, nil
+}
+
+// InvoiceEdit sends a cms v1 EditInvoice request to politeiawww.
+func (c *Client) InvoiceEdit(ei cms.EditInvoice) (*cms.EditInvoiceReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		cms.APIRoute, cms.RouteEditInvoice, ei)
+	if err != nil {
+		return nil, err
+	}
+
+	var eir cms.EditInvoiceReply
+	err = json.Unmarshal(resBody, &eir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &eir, nil
+}
+
+// InvoiceDetails sends a cms v1 InvoiceDetails request to politeiawww.
+func (c *Client) InvoiceDetails(id cms.InvoiceDetails) (*cms.InvoiceDetailsReply, error) {
+	route := "/invoices/" + id.Token
+	resBody, err := c.makeReq(http.MethodGet,
+		cms.APIRoute, route, &id)
+	if err != nil {
+		return nil, err
+	}
+
+	var idr cms.InvoiceDetailsReply
+	err = json.Unmarshal(resBody, &idr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &idr, nil
+}
+
+// InvoiceSetStatus sends a cms v1 SetInvoiceStatus request to politeiawww.
+func (c *Client) InvoiceSetStatus(sis cms.SetInvoiceStatus) (*cms.SetInvoiceStatusReply, error) {
+	route := "/invoices/" + sis.Token + "/status"
+	resBody, err := c.makeReq(http.MethodPost,
+		cms.APIRoute, route, sis)
+	if err != nil {
+		return nil, err
+	}
+
+	var sisr cms.SetInvoiceStatusReply
+	err = json.Unmarshal(resBody, &sisr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sisr, nil
+}
+
+// UserInvoices sends a cms v1 UserInvoices request to politeiawww.
+func (c *Client) UserInvoices() (*cms.UserInvoicesReply, error) {
+	resBody, err := c.makeReq(http.MethodGet,
+		cms.APIRoute, cms.RouteUserInvoices, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var uir cms.UserInvoicesReply
+	err = json.Unmarshal(resBody, &uir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &uir, nil
+}
+
+// Invoices sends a cms v1 Invoices request to politeiawww.
+func (c *Client) Invoices(i cms.Invoices) (*cms.InvoicesReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		cms.APIRoute, cms.RouteInvoices, i)
+	if err != nil {
+		return nil, err
+	}
+
+	var ir cms.InvoicesReply
+	err = json.Unmarshal(resBody, &ir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ir, nil
+}
+
+// InvoiceExchangeRate sends a cms v1 InvoiceExchangeRate request to
+// politeiawww.
+func (c *Client) InvoiceExchangeRate(ier cms.InvoiceExchangeRate) (*cms.InvoiceExchangeRateReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		cms.APIRoute, cms.RouteInvoiceExchangeRate, ier)
+	if err != nil {
+		return nil, err
+	}
+
+	var ierr cms.InvoiceExchangeRateReply
+	err = json.Unmarshal(resBody, &ierr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ierr, nil
+}
+
+// InvoicePayouts sends a cms v1 InvoicePayouts request to politeiawww. The
+// reply contains the line item payouts of all invoices that were paid within
+// the provided time range.
+func (c *Client) InvoicePayouts(ip cms.InvoicePayouts) (*cms.InvoicePayoutsReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		cms.APIRoute, cms.RouteInvoicePayouts, ip)
+	if err != nil {
+		return nil, err
+	}
+
+	var ipr cms.InvoicePayoutsReply
+	err = json.Unmarshal(resBody, &ipr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ipr, nil
+}
+
+// GeneratePayouts sends a cms v1 GeneratePayouts request to politeiawww.
+func (c *Client) GeneratePayouts() (*cms.GeneratePayoutsReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		cms.APIRoute, cms.RouteGeneratePayouts, cms.GeneratePayouts{})
+	if err != nil {
+		return nil, err
+	}
+
+	var gpr cms.GeneratePayoutsReply
+	err = json.Unmarshal(resBody, &gpr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gpr, nil
+}
+
+// PayInvoices sends a cms v1 PayInvoices request to politeiawww.
+func (c *Client) PayInvoices() (*cms.PayInvoicesReply, error) {
+	resBody, err := c.makeReq(http.MethodGet,
+		cms.APIRoute, cms.RoutePayInvoices, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var pir cms.PayInvoicesReply
+	err = json.Unmarshal(resBody, &pir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pir, nil
+}
+
+// DCCNew sends a cms v1 NewDCC request to politeiawww.
+func (c *Client) DCCNew(nd cms.NewDCC) (*cms.NewDCCReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		cms.APIRoute, cms.RouteNewDCC, nd)
+	if err != nil {
+		return nil, err
+	}
+
+	var ndr cms.NewDCCReply
+	err = json.Unmarshal(resBody, &ndr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ndr, nil
+}
+
+// DCCDetails sends a cms v1 DCCDetails request to politeiawww.
+func (c *Client) DCCDetails(dd cms.DCCDetails) (*cms.DCCDetailsReply, error) {
+	route := "/dcc/" + dd.Token
+	resBody, err := c.makeReq(http.MethodGet,
+		cms.APIRoute, route, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var ddr cms.DCCDetailsReply
+	err = json.Unmarshal(resBody, &ddr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ddr, nil
+}
+
+// DCCs sends a cms v1 GetDCCs request to politeiawww.
+func (c *Client) DCCs(gd cms.GetDCCs) (*cms.GetDCCsReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		cms.APIRoute, cms.RouteGetDCCs, gd)
+	if err != nil {
+		return nil, err
+	}
+
+	var gdr cms.GetDCCsReply
+	err = json.Unmarshal(resBody, &gdr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gdr, nil
+}
+
+// DCCSupportOppose sends a cms v1 SupportOpposeDCC request to politeiawww.
+func (c *Client) DCCSupportOppose(sd cms.SupportOpposeDCC) (*cms.SupportOpposeDCCReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		cms.APIRoute, cms.RouteSupportOpposeDCC, sd)
+	if err != nil {
+		return nil, err
+	}
+
+	var sdr cms.SupportOpposeDCCReply
+	err = json.Unmarshal(resBody, &sdr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sdr, nil
+}
+
+// DCCSetStatus sends a cms v1 SetDCCStatus request to politeiawww.
+func (c *Client) DCCSetStatus(sd cms.SetDCCStatus) (*cms.SetDCCStatusReply, error) {
+	route := "/dcc/" + sd.Token + "/status"
+	resBody, err := c.makeReq(http.MethodPost,
+		cms.APIRoute, route, sd)
+	if err != nil {
+		return nil, err
+	}
+
+	var sdr cms.SetDCCStatusReply
+	err = json.Unmarshal(resBody, &sdr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sdr, nil
+}
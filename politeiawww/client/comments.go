@@ -5,14 +5,16 @@
 package client
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
+	"sync"
 
-	backend "github.com/decred/politeia/politeiad/backendv2"
 	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
-	"github.com/decred/politeia/util"
+	"github.com/decred/politeia/politeiawww/client/internal/util"
 )
 
 // CommentPolicy sends a comments v1 Policy request to politeiawww.
@@ -151,6 +153,193 @@ func (c *Client) CommentTimestamps(t cmv1.Timestamps) (*cmv1.TimestampsReply, er
 	return &tr, nil
 }
 
+// CommentTimestampsStream retrieves the comment timestamps of a record in
+// chunks of TimestampsPageSize comments and invokes the provided callback
+// with each chunk. Only a single chunk is held in memory at any one time,
+// which allows the timestamps of records with a large number of comments to
+// be processed. The timestamps of all comments on the record are retrieved
+// when no comment IDs are provided. Retrieval stops at the first error that
+// is returned by the callback.
+func (c *Client) CommentTimestampsStream(t cmv1.Timestamps, fn func(*cmv1.TimestampsReply) error) error {
+	commentIDs := t.CommentIDs
+	if len(commentIDs) == 0 {
+		cr, err := c.Comments(cmv1.Comments{
+			Token: t.Token,
+		})
+		if err != nil {
+			return err
+		}
+		commentIDs = make([]uint32, 0, len(cr.Comments))
+		for _, v := range cr.Comments {
+			commentIDs = append(commentIDs, v.CommentID)
+		}
+	}
+
+	pageSize := int(cmv1.TimestampsPageSize)
+	for start := 0; start < len(commentIDs); start += pageSize {
+		end := start + pageSize
+		if end > len(commentIDs) {
+			end = len(commentIDs)
+		}
+		tr, err := c.CommentTimestamps(cmv1.Timestamps{
+			Token:      t.Token,
+			CommentIDs: commentIDs[start:end],
+		})
+		if err != nil {
+			return err
+		}
+		err = fn(tr)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CommentNode is a comment in a comment section along with its replies.
+type CommentNode struct {
+	Comment  cmv1.Comment
+	Score    int64      // Upvotes minus downvotes
+	UserVote cmv1.VoteT // Vote of the user, VoteInvalid if none
+	Replies  []*CommentNode
+}
+
+// CommentSection is the comment section of a record.
+type CommentSection struct {
+	Count    uint32         // Total number of comments
+	Comments []*CommentNode // Top level comments
+}
+
+// CommentSection retrieves the comments of a record, the comment votes of
+// the provided user, and the comment count in parallel and assembles them
+// into a comment tree that is ready to be rendered. The comments at each
+// level of the tree are sorted oldest first. The user vote of each comment is
+// only populated when a user ID is provided.
+func (c *Client) CommentSection(token, userID string) (*CommentSection, error) {
+	var (
+		wg       sync.WaitGroup
+		cr       *cmv1.CommentsReply
+		vr       *cmv1.VotesReply
+		cc       *cmv1.CountReply
+		errCm    error
+		errVotes error
+		errCount error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		cr, errCm = c.Comments(cmv1.Comments{
+			Token: token,
+		})
+	}()
+	go func() {
+		defer wg.Done()
+		cc, errCount = c.CommentCount(cmv1.Count{
+			Tokens: []string{token},
+		})
+	}()
+	if userID != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			vr, errVotes = c.CommentVotes(cmv1.Votes{
+				Token:  token,
+				UserID: userID,
+			})
+		}()
+	}
+	wg.Wait()
+	switch {
+	case errCm != nil:
+		return nil, errCm
+	case errCount != nil:
+		return nil, errCount
+	case errVotes != nil:
+		return nil, errVotes
+	}
+
+	var votes []cmv1.CommentVote
+	if vr != nil {
+		votes = vr.Votes
+	}
+	return &CommentSection{
+		Count:    cc.Counts[token],
+		Comments: commentTree(cr.Comments, userVotes(votes)),
+	}, nil
+}
+
+// userVotes returns the vote of a user on each comment by replaying the
+// comment votes of the user. Voting the same way twice removes the vote.
+func userVotes(votes []cmv1.CommentVote) map[uint32]cmv1.VoteT {
+	sort.SliceStable(votes, func(i, j int) bool {
+		return votes[i].Timestamp < votes[j].Timestamp
+	})
+	uv := make(map[uint32]cmv1.VoteT, len(votes))
+	for _, v := range votes {
+		if uv[v.CommentID] == v.Vote {
+			uv[v.CommentID] = cmv1.VoteInvalid
+			continue
+		}
+		uv[v.CommentID] = v.Vote
+	}
+	return uv
+}
+
+// commentTree assembles the provided comments into a comment tree and
+// returns the top level comments. A reply whose parent comment is not found
+// is treated as a top level comment.
+func commentTree(comments []cmv1.Comment, votes map[uint32]cmv1.VoteT) []*CommentNode {
+	nodes := make(map[uint32]*CommentNode, len(comments))
+	for _, v := range comments {
+		nodes[v.CommentID] = &CommentNode{
+			Comment:  v,
+			Score:    int64(v.Upvotes) - int64(v.Downvotes),
+			UserVote: votes[v.CommentID],
+		}
+	}
+
+	var (
+		top  = make([]*CommentNode, 0, len(comments))
+		byID = func(n []*CommentNode) {
+			sort.Slice(n, func(i, j int) bool {
+				return n[i].Comment.CommentID < n[j].Comment.CommentID
+			})
+		}
+	)
+	for _, n := range nodes {
+		parent, ok := nodes[n.Comment.ParentID]
+		if n.Comment.ParentID == 0 || !ok {
+			top = append(top, n)
+			continue
+		}
+		parent.Replies = append(parent.Replies, n)
+	}
+	for _, n := range nodes {
+		byID(n.Replies)
+	}
+	byID(top)
+
+	return top
+}
+
+// CommentExport sends a comments v1 Export request to politeiawww.
+func (c *Client) CommentExport(e cmv1.Export) (*cmv1.ExportReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		cmv1.APIRoute, cmv1.RouteExport, e)
+	if err != nil {
+		return nil, err
+	}
+
+	var er cmv1.ExportReply
+	err = json.Unmarshal(resBody, &er)
+	if err != nil {
+		return nil, err
+	}
+
+	return &er, nil
+}
+
 // commentDelVerify verifies the signature of a comment that has been deleted.
 // The signature will be from the deletion event, not the original comment
 // submission.
@@ -188,9 +377,13 @@ func CommentVerify(c cmv1.Comment, serverPublicKey string) error {
 	}
 
 	// Verify comment. The signature is the client signature of the
-	// State+Token+ParentID+Comment.
+	// State+Token+ParentID+Comment, followed by the Nonce+Expiry when
+	// a nonce was provided.
 	msg := strconv.FormatUint(uint64(c.State), 10) + c.Token +
 		strconv.FormatUint(uint64(c.ParentID), 10) + c.Comment
+	if c.Nonce != "" {
+		msg += c.Nonce + strconv.FormatInt(c.Expiry, 10)
+	}
 	err := util.VerifySignature(c.Signature, c.PublicKey, msg)
 	if err != nil {
 		return fmt.Errorf("unable to verify comment %v signature: %v",
@@ -213,9 +406,9 @@ func CommentVerify(c cmv1.Comment, serverPublicKey string) error {
 func CommentTimestampVerify(ct cmv1.CommentTimestamp) error {
 	// Verify comment adds
 	for i, ts := range ct.Adds {
-		err := backend.VerifyTimestamp(convertCommentTimestamp(ts))
+		err := util.VerifyTimestamp(convertCommentTimestamp(ts))
 		if err != nil {
-			if err == backend.ErrNotTimestamped {
+			if err == ErrNotTimestamped {
 				return err
 			}
 			return fmt.Errorf("verify comment add timestamp %v: %v", i, err)
@@ -226,9 +419,9 @@ func CommentTimestampVerify(ct cmv1.CommentTimestamp) error {
 	if ct.Del == nil {
 		return nil
 	}
-	err := backend.VerifyTimestamp(convertCommentTimestamp(*ct.Del))
+	err := util.VerifyTimestamp(convertCommentTimestamp(*ct.Del))
 	if err != nil {
-		if err == backend.ErrNotTimestamped {
+		if err == ErrNotTimestamped {
 			return err
 		}
 		return fmt.Errorf("verify comment del timestamp: %v", err)
@@ -245,7 +438,7 @@ func CommentTimestampsVerify(tr cmv1.TimestampsReply) ([]uint32, error) {
 	for cid, v := range tr.Comments {
 		err := CommentTimestampVerify(v)
 		if err != nil {
-			if err == backend.ErrNotTimestamped {
+			if err == ErrNotTimestamped {
 				notTimestamped = append(notTimestamped, cid)
 				continue
 			}
@@ -256,8 +449,66 @@ func CommentTimestampsVerify(tr cmv1.TimestampsReply) ([]uint32, error) {
 	return notTimestamped, nil
 }
 
-func convertCommentProof(p cmv1.Proof) backend.Proof {
-	return backend.Proof{
+// CommentExportVerify verifies the manifest signature, the manifest digests,
+// and all comment signatures, receipts, and timestamps contained in a comments
+// v1 ExportReply. The serverPubKey is the politeiawww signing key that is
+// expected to have signed the manifest. The previous signing key is accepted
+// during a signing identity rotation. The IDs of comments that have not been
+// anchored yet are returned.
+func CommentExportVerify(er cmv1.ExportReply, serverPubKey string) ([]uint32, error) {
+	m := er.Manifest
+
+	// Verify manifest signature
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	err = serverSignatureVerify(hex.EncodeToString(util.Digest(b)),
+		serverPubKey, m.ServerPubKey, er.Signature, m.PrevServerPubKey,
+		er.PrevSignature)
+	if err != nil {
+		return nil, fmt.Errorf("unable to verify manifest signature: %v", err)
+	}
+
+	// Verify manifest digests
+	b, err = json.Marshal(er.Comments)
+	if err != nil {
+		return nil, err
+	}
+	if hex.EncodeToString(util.Digest(b)) != m.CommentsDigest {
+		return nil, fmt.Errorf("comments digest mismatch")
+	}
+	b, err = json.Marshal(er.Timestamps)
+	if err != nil {
+		return nil, err
+	}
+	if hex.EncodeToString(util.Digest(b)) != m.TimestampsDigest {
+		return nil, fmt.Errorf("timestamps digest mismatch")
+	}
+	if int(m.CommentsCount) != len(er.Comments) {
+		return nil, fmt.Errorf("comments count mismatch")
+	}
+
+	// Verify comments
+	for _, v := range er.Comments {
+		if v.Token != m.Token {
+			return nil, fmt.Errorf("comment %v: invalid token %v",
+				v.CommentID, v.Token)
+		}
+		err := CommentVerify(v, m.PoliteiadPubKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Verify timestamps
+	return CommentTimestampsVerify(cmv1.TimestampsReply{
+		Comments: er.Timestamps,
+	})
+}
+
+func convertCommentProof(p cmv1.Proof) util.Proof {
+	return util.Proof{
 		Type:       p.Type,
 		Digest:     p.Digest,
 		MerkleRoot: p.MerkleRoot,
@@ -266,12 +517,12 @@ func convertCommentProof(p cmv1.Proof) backend.Proof {
 	}
 }
 
-func convertCommentTimestamp(t cmv1.Timestamp) backend.Timestamp {
-	proofs := make([]backend.Proof, 0, len(t.Proofs))
+func convertCommentTimestamp(t cmv1.Timestamp) util.Timestamp {
+	proofs := make([]util.Proof, 0, len(t.Proofs))
 	for _, v := range t.Proofs {
 		proofs = append(proofs, convertCommentProof(v))
 	}
-	return backend.Timestamp{
+	return util.Timestamp{
 		Data:       t.Data,
 		Digest:     t.Digest,
 		TxID:       t.TxID,
@@ -0,0 +1,33 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+)
+
+// CSRFToken sends a www v1 CSRFToken request to politeiawww. The returned
+// CSRF session token is used in the header of all subsequent requests made
+// by the client. This method should not be called concurrently with other
+// client requests.
+func (c *Client) CSRFToken() (*www.CSRFTokenReply, error) {
+	resBody, err := c.makeReq(http.MethodGet,
+		www.PoliteiaWWWAPIRoute, www.RouteCSRFToken, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var tr www.CSRFTokenReply
+	err = json.Unmarshal(resBody, &tr)
+	if err != nil {
+		return nil, err
+	}
+	c.headerCSRFSession = tr.Token
+
+	return &tr, nil
+}
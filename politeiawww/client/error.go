@@ -5,7 +5,9 @@
 package client
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 
@@ -13,9 +15,14 @@ import (
 	piplugin "github.com/decred/politeia/politeiad/plugins/pi"
 	tkplugin "github.com/decred/politeia/politeiad/plugins/ticketvote"
 	umplugin "github.com/decred/politeia/politeiad/plugins/usermd"
+	cms "github.com/decred/politeia/politeiawww/api/cms/v1"
 	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	oav1 "github.com/decred/politeia/politeiawww/api/oauth/v1"
+	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
 	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	tmv1 "github.com/decred/politeia/politeiawww/api/telemetry/v1"
 	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
 )
 
 // ErrorReply represents the request body that is returned from politeiawww
@@ -27,6 +34,30 @@ type ErrorReply struct {
 	ErrorContext string
 }
 
+// decodeErrorReply decodes the error reply body that was returned by the
+// provided API. The legacy www API, which the cms API shares a route prefix
+// with, returns the error context as a list of strings. The list is joined
+// into a single error context string.
+func decodeErrorReply(api string, r io.Reader) (*ErrorReply, error) {
+	decoder := json.NewDecoder(r)
+	if api != www.PoliteiaWWWAPIRoute {
+		var e ErrorReply
+		if err := decoder.Decode(&e); err != nil {
+			return nil, err
+		}
+		return &e, nil
+	}
+
+	var e www.ErrorReply
+	if err := decoder.Decode(&e); err != nil {
+		return nil, err
+	}
+	return &ErrorReply{
+		ErrorCode:    int(e.ErrorCode),
+		ErrorContext: strings.Join(e.ErrorContext, ", "),
+	}, nil
+}
+
 // RespErr represents a politeiawww response error. A RespErr is returned
 // anytime the politeiawww response is not a 200.
 //
@@ -65,10 +96,23 @@ func apiUserErr(api string, e ErrorReply) string {
 	switch api {
 	case cmv1.APIRoute:
 		errMsg = cmv1.ErrorCodes[cmv1.ErrorCodeT(e.ErrorCode)]
+	case piv1.APIRoute:
+		errMsg = piv1.ErrorCodes[piv1.ErrorCodeT(e.ErrorCode)]
 	case rcv1.APIRoute:
 		errMsg = rcv1.ErrorCodes[rcv1.ErrorCodeT(e.ErrorCode)]
 	case tkv1.APIRoute:
 		errMsg = tkv1.ErrorCodes[tkv1.ErrorCodeT(e.ErrorCode)]
+	case tmv1.APIRoute:
+		errMsg = tmv1.ErrorCodes[tmv1.ErrorCodeT(e.ErrorCode)]
+	case oav1.APIRoute:
+		errMsg = oav1.ErrorCodes[oav1.ErrorCodeT(e.ErrorCode)]
+	case www.PoliteiaWWWAPIRoute:
+		// The cms API shares the www API route prefix and defines its
+		// own error codes on top of the www error codes.
+		errMsg = www.ErrorStatus[www.ErrorStatusT(e.ErrorCode)]
+		if errMsg == "" {
+			errMsg = cms.ErrorStatus[www.ErrorStatusT(e.ErrorCode)]
+		}
 	}
 
 	// Remove "/" from api string. "/records/v1" to "records v1".
@@ -0,0 +1,28 @@
+module github.com/decred/politeia/politeiawww/client
+
+go 1.15
+
+require (
+	github.com/decred/dcrd/chaincfg/chainhash v1.0.3-0.20200921185235-6d75c7ec1199
+	github.com/decred/dcrd/chaincfg/v3 v3.0.0
+	github.com/decred/dcrd/crypto/blake256 v1.0.1-0.20200921185235-6d75c7ec1199 // indirect
+	github.com/decred/dcrd/dcrec v1.0.1-0.20200921185235-6d75c7ec1199
+	github.com/decred/dcrd/dcrec/secp256k1/v3 v3.0.0
+	github.com/decred/dcrd/dcrutil/v3 v3.0.0
+	github.com/decred/dcrd/wire v1.4.0
+	github.com/decred/dcrtime v0.0.0-20191018193024-8d8b4ef0458e
+	github.com/decred/go-socks v1.1.0
+	github.com/decred/politeia/politeiad/plugins v1.0.0
+	github.com/decred/politeia/politeiawww/api v1.0.0
+	github.com/google/uuid v1.1.1
+	github.com/gorilla/schema v1.1.0
+	golang.org/x/crypto v0.0.0-20210220033148-5ea612d1eb83
+	golang.org/x/net v0.0.0-20201110031124-69a78807bb2b
+	golang.org/x/sys v0.0.0-20210309074719-68d13333faf2 // indirect
+)
+
+replace (
+	github.com/decred/politeia/politeiad/plugins => ../../politeiad/plugins
+	github.com/decred/politeia/politeiawww/api => ../api
+	github.com/decred/politeia/unittest => ../../unittest
+)
@@ -0,0 +1,309 @@
+// Copyright (c) 2020-2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/decred/dcrtime/merkle"
+	dmerkle "github.com/decred/dcrtime/merkle"
+)
+
+const (
+	// ProofTypeTrillianRFC6962 represents a trillian proof that uses
+	// the trillian hashing strategy HashStrategy_RFC6962_SHA256.
+	ProofTypeTrillianRFC6962 = "trillian-rfc6962"
+
+	// ProofTypeDcrtime represents a dcrtime proof.
+	ProofTypeDcrtime = "dcrtime"
+)
+
+// Proof contains an inclusion proof for the digest in the merkle root. All
+// digests are hex encoded SHA256 digests.
+//
+// The ExtraData field is used by certain types of proofs to include additional
+// data that is required to validate the proof.
+type Proof struct {
+	Type       string
+	Digest     string
+	MerkleRoot string
+	MerklePath []string
+	ExtraData  string // JSON encoded
+}
+
+// Timestamp contains all of the data required to verify that a piece of record
+// content was timestamped onto the decred blockchain.
+//
+// All digests are hex encoded SHA256 digests. The merkle root can be found in
+// the OP_RETURN of the specified DCR transaction.
+//
+// TxID, MerkleRoot, and Proofs will only be populated once the merkle root has
+// been included in a DCR tx and the tx has 6 confirmations. The Data field
+// will not be populated if the data has been censored.
+type Timestamp struct {
+	Data       string // JSON encoded
+	Digest     string
+	TxID       string
+	MerkleRoot string
+	Proofs     []Proof
+}
+
+// ExtraDataTrillianRFC6962 contains the extra data required to verify a
+// trillian inclusion proof.
+type ExtraDataTrillianRFC6962 struct {
+	LeafIndex int64 `json:"leafindex"`
+	TreeSize  int64 `json:"treesize"`
+}
+
+// rfc6962HashLeaf returns the RFC 6962 leaf hash of the provided leaf value.
+func rfc6962HashLeaf(leaf []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x00})
+	h.Write(leaf)
+	return h.Sum(nil)
+}
+
+// rfc6962HashChildren returns the RFC 6962 hash of an interior merkle tree
+// node from the hashes of its left and right children.
+func rfc6962HashChildren(l, r []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x01})
+	h.Write(l)
+	h.Write(r)
+	return h.Sum(nil)
+}
+
+// verifyInclusionRFC6962 verifies that the leaf hash at the provided index is
+// included in the merkle root of an RFC 6962 merkle tree of the provided size.
+// This is the inclusion proof verification that is used by trillian. It is
+// implemented here so that clients can verify timestamps without depending on
+// trillian.
+func verifyInclusionRFC6962(leafIndex, treeSize int64, proof [][]byte, root, leafHash []byte) error {
+	switch {
+	case leafIndex < 0:
+		return fmt.Errorf("negative leaf index %v", leafIndex)
+	case treeSize < 0:
+		return fmt.Errorf("negative tree size %v", treeSize)
+	case leafIndex >= treeSize:
+		return fmt.Errorf("leaf index %v out of range for tree size %v",
+			leafIndex, treeSize)
+	case len(leafHash) != sha256.Size:
+		return fmt.Errorf("invalid leaf hash size %v", len(leafHash))
+	}
+
+	// The proof consists of the inner nodes on the path from the leaf
+	// to the point where the path diverges from the right border of
+	// the tree, followed by the border nodes.
+	inner := bits.Len64(uint64(leafIndex ^ (treeSize - 1)))
+	border := bits.OnesCount64(uint64(leafIndex) >> uint(inner))
+	if len(proof) != inner+border {
+		return fmt.Errorf("invalid proof size: got %v, want %v",
+			len(proof), inner+border)
+	}
+
+	h := leafHash
+	for i, v := range proof[:inner] {
+		if (leafIndex>>uint(i))&1 == 0 {
+			h = rfc6962HashChildren(h, v)
+		} else {
+			h = rfc6962HashChildren(v, h)
+		}
+	}
+	for _, v := range proof[inner:] {
+		h = rfc6962HashChildren(v, h)
+	}
+
+	if !bytes.Equal(h, root) {
+		return fmt.Errorf("root mismatch: got %x, want %x", h, root)
+	}
+
+	return nil
+}
+
+// verifyProofTrillian verifies a proof with the type ProofTypeTrillianRFC6962.
+func verifyProofTrillian(p Proof) error {
+	// Verify type
+	if p.Type != ProofTypeTrillianRFC6962 {
+		return fmt.Errorf("invalid proof type")
+	}
+
+	// The digest of the data is stored in trillian as the leaf value.
+	// The digest of the leaf value is the digest that is included in
+	// the log merkle root.
+	leafValue, err := hex.DecodeString(p.Digest)
+	if err != nil {
+		return err
+	}
+	leafHash := rfc6962HashLeaf(leafValue)
+
+	merkleRoot, err := hex.DecodeString(p.MerkleRoot)
+	if err != nil {
+		return err
+	}
+
+	merklePath := make([][]byte, 0, len(p.MerklePath))
+	for _, v := range p.MerklePath {
+		b, err := hex.DecodeString(v)
+		if err != nil {
+			return err
+		}
+		merklePath = append(merklePath, b)
+	}
+
+	var ed ExtraDataTrillianRFC6962
+	err = json.Unmarshal([]byte(p.ExtraData), &ed)
+	if err != nil {
+		return err
+	}
+
+	return verifyInclusionRFC6962(ed.LeafIndex, ed.TreeSize,
+		merklePath, merkleRoot, leafHash)
+}
+
+// ExtraDataDcrtime contains the extra data required to verify a dcrtime
+// inclusion proof.
+type ExtraDataDcrtime struct {
+	NumLeaves uint32 // Nuber of leaves
+	Flags     string // Bitmap of merkle tree, base64 encoded
+}
+
+// verifyProofDcrtime verifies a proof with the type ProofTypeDcrtime.
+func verifyProofDcrtime(p Proof) error {
+	if p.Type != ProofTypeDcrtime {
+		return fmt.Errorf("invalid proof type")
+	}
+
+	// Verify digest is part of merkle path
+	var found bool
+	for _, v := range p.MerklePath {
+		if v == p.Digest {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("digest %v not found in merkle path %v",
+			p.Digest, p.MerklePath)
+	}
+
+	// Decode extra data
+	var ed ExtraDataDcrtime
+	err := json.Unmarshal([]byte(p.ExtraData), &ed)
+	if err != nil {
+		return err
+	}
+	flags, err := base64.StdEncoding.DecodeString(ed.Flags)
+	if err != nil {
+		return err
+	}
+
+	// Calculate merkle root
+	digests := make([][sha256.Size]byte, 0, len(p.MerklePath))
+	for _, v := range p.MerklePath {
+		b, err := hex.DecodeString(v)
+		if err != nil {
+			return err
+		}
+		var d [sha256.Size]byte
+		copy(d[:], b)
+		digests = append(digests, d)
+	}
+	mb := merkle.Branch{
+		NumLeaves: ed.NumLeaves,
+		Hashes:    digests,
+		Flags:     flags,
+	}
+	mr, err := dmerkle.VerifyAuthPath(&mb)
+	if err != nil {
+		return err
+	}
+	merkleRoot := hex.EncodeToString(mr[:])
+
+	// Verify merkle root matches
+	if merkleRoot != p.MerkleRoot {
+		return fmt.Errorf("invalid merkle root: got %v, want %v",
+			merkleRoot, p.MerkleRoot)
+	}
+
+	return nil
+}
+
+// verifyProof verifies a backend proof.
+func verifyProof(p Proof) error {
+	switch p.Type {
+	case ProofTypeTrillianRFC6962:
+		return verifyProofTrillian(p)
+	case ProofTypeDcrtime:
+		return verifyProofDcrtime(p)
+	}
+	return fmt.Errorf("invalid proof type")
+}
+
+var (
+	// ErrNotTimestamped is returned when a timestamp does not contain
+	// a TxID. This indicates that the data has yet to be included in
+	// a DCR transaction.
+	ErrNotTimestamped = errors.New("data has not be included in a dcr tx yet")
+)
+
+// VerifyTimestamp verifies the inclusion of the data in the merkle root that
+// was timestamped onto the dcr blockchain.
+func VerifyTimestamp(t Timestamp) error {
+	if t.TxID == "" {
+		return ErrNotTimestamped
+	}
+
+	// Verify digest. The data blob may not be included in certain
+	// scenerios such as if it has been censored.
+	if t.Data != "" {
+		d := hex.EncodeToString(Digest([]byte(t.Data)))
+		if d != t.Digest {
+			return fmt.Errorf("invalid digest: got %v, want %v", d, t.Digest)
+		}
+	}
+
+	// Verify proof ordering. The digest of the first proof should be
+	// the data digest. The digest of every subsequent proof should be
+	// the merkle root of the previous proof.
+	if len(t.Proofs) == 0 {
+		return fmt.Errorf("no proofs found")
+	}
+	if t.Digest != t.Proofs[0].Digest {
+		return fmt.Errorf("invalid proofs: digest %v not found", t.Digest)
+	}
+	nextDigest := t.Proofs[0].MerkleRoot
+	for i := 1; i < len(t.Proofs); i++ {
+		p := t.Proofs[i]
+		if p.Digest != nextDigest {
+			return fmt.Errorf("invalid proof %v digest: got %v, want %v",
+				i, p.Digest, nextDigest)
+		}
+		nextDigest = t.MerkleRoot
+	}
+
+	// Verify the merkle root of the last proof is the merkle root
+	// that was included in the dcr transaction.
+	if nextDigest != t.MerkleRoot {
+		return fmt.Errorf("merkle root of last proof does not match timestamped "+
+			"merkle root: got %v, want %v", nextDigest, t.MerkleRoot)
+	}
+
+	// Verify proofs
+	for _, v := range t.Proofs {
+		err := verifyProof(v)
+		if err != nil {
+			return fmt.Errorf("invalid %v proof: %v", v.Type, err)
+		}
+	}
+
+	return nil
+}
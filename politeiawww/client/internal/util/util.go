@@ -0,0 +1,157 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package util contains the utilities of the politeia util package and the
+// politeiad backend that the politeiawww client uses. They are copied so that
+// the client module does not depend on the politeia module.
+package util
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	dcrtime "github.com/decred/dcrtime/api/v1"
+	"github.com/decred/dcrtime/merkle"
+	"golang.org/x/crypto/ed25519"
+)
+
+const (
+	// SignatureSize is the size of an ed25519 signature.
+	SignatureSize = ed25519.SignatureSize
+
+	// PublicKeySize is the size of an ed25519 public key.
+	PublicKeySize = ed25519.PublicKeySize
+)
+
+// PublicIdentity is an ed25519 public key.
+type PublicIdentity struct {
+	Key [PublicKeySize]byte
+}
+
+// VerifyMessage verifies the signature of a message.
+func (p PublicIdentity) VerifyMessage(msg []byte, sig [SignatureSize]byte) bool {
+	return ed25519.Verify(p.Key[:], msg, sig[:])
+}
+
+// IdentityFromString converts a hex encoded public key into a public identity
+// structure.
+func IdentityFromString(id string) (*PublicIdentity, error) {
+	pk, err := hex.DecodeString(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(pk) != PublicKeySize {
+		return nil, fmt.Errorf("invalid public key length")
+	}
+	var p PublicIdentity
+	copy(p.Key[:], pk)
+	return &p, nil
+}
+
+// ConvertSignature converts a hex encoded signature to a proper sized byte
+// slice.
+func ConvertSignature(s string) ([SignatureSize]byte, error) {
+	sb, err := hex.DecodeString(s)
+	if err != nil {
+		return [SignatureSize]byte{}, err
+	}
+	if len(sb) != SignatureSize {
+		return [SignatureSize]byte{}, fmt.Errorf("invalid signature length")
+	}
+	var sig [SignatureSize]byte
+	copy(sig[:], sb)
+	return sig, nil
+}
+
+// Digest returns the SHA256 of a byte slice.
+func Digest(b []byte) []byte {
+	h := sha256.New()
+	h.Write(b)
+	return h.Sum(nil)
+}
+
+// ConvertDigest converts a string into a digest.
+func ConvertDigest(d string) ([sha256.Size]byte, bool) {
+	var digest [sha256.Size]byte
+	if !dcrtime.RegexpSHA256.MatchString(d) {
+		return digest, false
+	}
+	dd, err := hex.DecodeString(d)
+	if err != nil {
+		return digest, false
+	}
+	copy(digest[:], dd)
+	return digest, true
+}
+
+// MerkleRoot computes and returns the merkle root of the provided digests.
+// The digests should be hex encoded SHA256 digests.
+func MerkleRoot(digests []string) (*[sha256.Size]byte, error) {
+	sha := make([]*[sha256.Size]byte, 0, len(digests))
+	for _, v := range digests {
+		d, err := hex.DecodeString(v)
+		if err != nil {
+			return nil, err
+		}
+		var s [sha256.Size]byte
+		copy(s[:], d)
+		sha = append(sha, &s)
+	}
+	return merkle.Root(sha), nil
+}
+
+// Random returns a variable number of bytes of random data.
+func Random(n int) ([]byte, error) {
+	k := make([]byte, n)
+	_, err := io.ReadFull(rand.Reader, k)
+	if err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// NewHTTPClient returns a new http Client.
+func NewHTTPClient(skipVerify bool, certPath string) (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: skipVerify,
+	}
+
+	if !skipVerify && certPath != "" {
+		cert, err := ioutil.ReadFile(certPath)
+		if err != nil {
+			return nil, err
+		}
+		certPool, err := x509.SystemCertPool()
+		if err != nil {
+			fmt.Printf("WARN: unable to get system cert pool: %v\n", err)
+			certPool = x509.NewCertPool()
+		}
+		certPool.AppendCertsFromPEM(cert)
+		tlsConfig.RootCAs = certPool
+	}
+
+	return &http.Client{
+		Timeout: 2 * time.Minute,
+		Transport: &http.Transport{
+			IdleConnTimeout:       2 * time.Minute,
+			ResponseHeaderTimeout: 2 * time.Minute,
+			TLSClientConfig:       tlsConfig,
+		}}, nil
+}
+
+// RespBody returns the response body as a byte slice.
+func RespBody(r *http.Response) []byte {
+	var body bytes.Buffer
+	io.Copy(&body, r.Body)
+	return body.Bytes()
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Metrics is the hook that is used to record politeiawww client request
+// metrics, e.g. request counters and latency histograms. Implementations must
+// be safe for concurrent use.
+//
+// The client does not depend on a metrics library. A Prometheus implementation
+// only needs to register a counter and a histogram that are labeled by API,
+// route, and status code:
+//
+//	type promMetrics struct {
+//		requests *prometheus.CounterVec
+//		latency  *prometheus.HistogramVec
+//	}
+//
+//	func (m *promMetrics) Observe(api, route string, code int, d time.Duration) {
+//		c := strconv.Itoa(code)
+//		m.requests.WithLabelValues(api, route, c).Inc()
+//		m.latency.WithLabelValues(api, route, c).Observe(d.Seconds())
+//	}
+type Metrics interface {
+	// Observe is called once for every request that is sent. The route
+	// is the route template, meaning that record tokens and user IDs
+	// in the route path are replaced with placeholders in order to
+	// keep the label cardinality bounded. The code is the HTTP status
+	// code of the response or 0 if a response was not received.
+	Observe(api, route string, code int, d time.Duration)
+}
+
+const (
+	// Route template placeholders
+	routeParamToken  = "{token}"
+	routeParamUserID = "{userid}"
+)
+
+// routeTemplate returns the provided route with all record tokens and user IDs
+// replaced by placeholders.
+func routeTemplate(route string) string {
+	s := strings.Split(route, "/")
+	for i, v := range s {
+		switch {
+		case isUUID(v):
+			s[i] = routeParamUserID
+		case isToken(v):
+			s[i] = routeParamToken
+		}
+	}
+	return strings.Join(s, "/")
+}
+
+// isUUID returns whether the provided route segment is a UUID.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	_, err := uuid.Parse(s)
+	return err == nil
+}
+
+// isToken returns whether the provided route segment is a full length or
+// short record token. Tokens are hex encoded.
+func isToken(s string) bool {
+	if len(s) < 7 || len(s) > 64 {
+		return false
+	}
+	if len(s)%2 == 1 {
+		// Short tokens can have an odd length
+		s += "0"
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+
+	oav1 "github.com/decred/politeia/politeiawww/api/oauth/v1"
+)
+
+// OAuthClient sends a oauth v1 Client request to politeiawww.
+func (c *Client) OAuthClient(cl oav1.Client) (*oav1.ClientReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		oav1.APIRoute, oav1.RouteClient, cl)
+	if err != nil {
+		return nil, err
+	}
+
+	var cr oav1.ClientReply
+	err = json.Unmarshal(resBody, &cr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cr, nil
+}
+
+// OAuthAuthorize sends a oauth v1 Authorize request to politeiawww.
+func (c *Client) OAuthAuthorize(a oav1.Authorize) (*oav1.AuthorizeReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		oav1.APIRoute, oav1.RouteAuthorize, a)
+	if err != nil {
+		return nil, err
+	}
+
+	var ar oav1.AuthorizeReply
+	err = json.Unmarshal(resBody, &ar)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ar, nil
+}
+
+// OAuthGrants sends a oauth v1 Grants request to politeiawww.
+func (c *Client) OAuthGrants() (*oav1.GrantsReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		oav1.APIRoute, oav1.RouteGrants, oav1.Grants{})
+	if err != nil {
+		return nil, err
+	}
+
+	var gr oav1.GrantsReply
+	err = json.Unmarshal(resBody, &gr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gr, nil
+}
+
+// OAuthRevoke sends a oauth v1 Revoke request to politeiawww.
+func (c *Client) OAuthRevoke(r oav1.Revoke) (*oav1.RevokeReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		oav1.APIRoute, oav1.RouteRevoke, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var rr oav1.RevokeReply
+	err = json.Unmarshal(resBody, &rr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rr, nil
+}
+
+// OAuthClients sends a oauth v1 Clients request to politeiawww.
+func (c *Client) OAuthClients() (*oav1.ClientsReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		oav1.APIRoute, oav1.RouteClients, oav1.Clients{})
+	if err != nil {
+		return nil, err
+	}
+
+	var cr oav1.ClientsReply
+	err = json.Unmarshal(resBody, &cr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cr, nil
+}
+
+// OAuthRegisterClient sends a oauth v1 RegisterClient request to
+// politeiawww.
+func (c *Client) OAuthRegisterClient(rc oav1.RegisterClient) (*oav1.RegisterClientReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		oav1.APIRoute, oav1.RouteRegisterClient, rc)
+	if err != nil {
+		return nil, err
+	}
+
+	var rcr oav1.RegisterClientReply
+	err = json.Unmarshal(resBody, &rcr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rcr, nil
+}
+
+// OAuthDeleteClient sends a oauth v1 DeleteClient request to politeiawww.
+func (c *Client) OAuthDeleteClient(dc oav1.DeleteClient) (*oav1.DeleteClientReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		oav1.APIRoute, oav1.RouteDeleteClient, dc)
+	if err != nil {
+		return nil, err
+	}
+
+	var dcr oav1.DeleteClientReply
+	err = json.Unmarshal(resBody, &dcr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dcr, nil
+}
@@ -12,6 +12,7 @@ import (
 
 	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
 	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	"github.com/decred/politeia/politeiawww/client/internal/util"
 )
 
 // PiPolicy sends a pi v1 Policy request to politeiawww.
@@ -31,6 +32,266 @@ func (c *Client) PiPolicy() (*piv1.PolicyReply, error) {
 	return &pr, nil
 }
 
+// PiSimilar sends a pi v1 Similar request to politeiawww.
+func (c *Client) PiSimilar(s piv1.Similar) (*piv1.SimilarReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		piv1.APIRoute, piv1.RouteSimilar, s)
+	if err != nil {
+		return nil, err
+	}
+
+	var sr piv1.SimilarReply
+	err = json.Unmarshal(resBody, &sr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sr, nil
+}
+
+// PiPreflight sends a pi v1 Preflight request to politeiawww.
+func (c *Client) PiPreflight(p piv1.Preflight) (*piv1.PreflightReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		piv1.APIRoute, piv1.RoutePreflight, p)
+	if err != nil {
+		return nil, err
+	}
+
+	var pr piv1.PreflightReply
+	err = json.Unmarshal(resBody, &pr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pr, nil
+}
+
+// PiSetAuthorUpdate sends a pi v1 SetAuthorUpdate request to politeiawww.
+func (c *Client) PiSetAuthorUpdate(sau piv1.SetAuthorUpdate) (*piv1.SetAuthorUpdateReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		piv1.APIRoute, piv1.RouteSetAuthorUpdate, sau)
+	if err != nil {
+		return nil, err
+	}
+
+	var saur piv1.SetAuthorUpdateReply
+	err = json.Unmarshal(resBody, &saur)
+	if err != nil {
+		return nil, err
+	}
+
+	return &saur, nil
+}
+
+// PiAuthorUpdates sends a pi v1 AuthorUpdates request to politeiawww.
+func (c *Client) PiAuthorUpdates(au piv1.AuthorUpdates) (*piv1.AuthorUpdatesReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		piv1.APIRoute, piv1.RouteAuthorUpdates, au)
+	if err != nil {
+		return nil, err
+	}
+
+	var aur piv1.AuthorUpdatesReply
+	err = json.Unmarshal(resBody, &aur)
+	if err != nil {
+		return nil, err
+	}
+
+	return &aur, nil
+}
+
+// PiWalletSummaries sends a pi v1 WalletSummaries request to politeiawww.
+func (c *Client) PiWalletSummaries(ws piv1.WalletSummaries) (*piv1.WalletSummariesReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		piv1.APIRoute, piv1.RouteWalletSummaries, ws)
+	if err != nil {
+		return nil, err
+	}
+
+	var wsr piv1.WalletSummariesReply
+	err = json.Unmarshal(resBody, &wsr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &wsr, nil
+}
+
+// PiVettingQueue sends a pi v1 VettingQueue request to politeiawww.
+func (c *Client) PiVettingQueue(vq piv1.VettingQueue) (*piv1.VettingQueueReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		piv1.APIRoute, piv1.RouteVettingQueue, vq)
+	if err != nil {
+		return nil, err
+	}
+
+	var vqr piv1.VettingQueueReply
+	err = json.Unmarshal(resBody, &vqr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vqr, nil
+}
+
+// PiVettingAssign sends a pi v1 VettingAssign request to politeiawww.
+func (c *Client) PiVettingAssign(va piv1.VettingAssign) (*piv1.VettingAssignReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		piv1.APIRoute, piv1.RouteVettingAssign, va)
+	if err != nil {
+		return nil, err
+	}
+
+	var vr piv1.VettingAssignReply
+	err = json.Unmarshal(resBody, &vr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vr, nil
+}
+
+// PiReportNew sends a pi v1 ReportNew request to politeiawww.
+func (c *Client) PiReportNew(rn piv1.ReportNew) (*piv1.ReportNewReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		piv1.APIRoute, piv1.RouteReportNew, rn)
+	if err != nil {
+		return nil, err
+	}
+
+	var rnr piv1.ReportNewReply
+	err = json.Unmarshal(resBody, &rnr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rnr, nil
+}
+
+// PiReports sends a pi v1 Reports request to politeiawww.
+func (c *Client) PiReports(rs piv1.Reports) (*piv1.ReportsReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		piv1.APIRoute, piv1.RouteReports, rs)
+	if err != nil {
+		return nil, err
+	}
+
+	var rsr piv1.ReportsReply
+	err = json.Unmarshal(resBody, &rsr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rsr, nil
+}
+
+// PiReportResolve sends a pi v1 ReportResolve request to politeiawww.
+func (c *Client) PiReportResolve(rr piv1.ReportResolve) (*piv1.ReportResolveReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		piv1.APIRoute, piv1.RouteReportResolve, rr)
+	if err != nil {
+		return nil, err
+	}
+
+	var rrr piv1.ReportResolveReply
+	err = json.Unmarshal(resBody, &rrr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rrr, nil
+}
+
+// PiRFP sends a pi v1 RFP request to politeiawww.
+func (c *Client) PiRFP(rfp piv1.RFP) (*piv1.RFPReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		piv1.APIRoute, piv1.RouteRFP, rfp)
+	if err != nil {
+		return nil, err
+	}
+
+	var rfpr piv1.RFPReply
+	err = json.Unmarshal(resBody, &rfpr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rfpr, nil
+}
+
+// PiRFPParent sends a pi v1 RFPParent request to politeiawww.
+func (c *Client) PiRFPParent(rp piv1.RFPParent) (*piv1.RFPParentReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		piv1.APIRoute, piv1.RouteRFPParent, rp)
+	if err != nil {
+		return nil, err
+	}
+
+	var rpr piv1.RFPParentReply
+	err = json.Unmarshal(resBody, &rpr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rpr, nil
+}
+
+// PiRFPRepair sends a pi v1 RFPRepair request to politeiawww.
+func (c *Client) PiRFPRepair(rr piv1.RFPRepair) (*piv1.RFPRepairReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		piv1.APIRoute, piv1.RouteRFPRepair, rr)
+	if err != nil {
+		return nil, err
+	}
+
+	var rrr piv1.RFPRepairReply
+	err = json.Unmarshal(resBody, &rrr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rrr, nil
+}
+
+// PiLineage sends a pi v1 Lineage request to politeiawww.
+func (c *Client) PiLineage(l piv1.Lineage) (*piv1.LineageReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		piv1.APIRoute, piv1.RouteLineage, l)
+	if err != nil {
+		return nil, err
+	}
+
+	var lr piv1.LineageReply
+	err = json.Unmarshal(resBody, &lr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lr, nil
+}
+
+// AuthorUpdateVerify verifies the author update signature and receipt.
+func AuthorUpdateVerify(au piv1.AuthorUpdate, serverPublicKey string) error {
+	// Verify signature. The signature is the client signature of the
+	// Token+Update.
+	msg := au.Token + au.Update
+	err := util.VerifySignature(au.Signature, au.PublicKey, msg)
+	if err != nil {
+		return fmt.Errorf("unable to verify author update %v signature: %v",
+			au.Version, err)
+	}
+
+	// Verify receipt. The receipt is the server signature of the
+	// client signature.
+	err = util.VerifySignature(au.Receipt, serverPublicKey, au.Signature)
+	if err != nil {
+		return fmt.Errorf("unable to verify author update %v receipt: %v",
+			au.Version, err)
+	}
+
+	return nil
+}
+
 // ProposalMetadataDecode decodes and returns the ProposalMetadata from the
 // Provided record files. An error returned if a ProposalMetadata is not found.
 func ProposalMetadataDecode(files []rcv1.File) (*piv1.ProposalMetadata, error) {
@@ -0,0 +1,78 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/decred/go-socks/socks"
+)
+
+const (
+	// Supported proxy URL schemes
+	proxySchemeHTTP   = "http"
+	proxySchemeHTTPS  = "https"
+	proxySchemeSOCKS5 = "socks5"
+)
+
+// proxySetup configures the provided transport to route all requests through
+// the provided proxy URL. Proxy credentials can be included in the URL user
+// info.
+//
+// Isolation is only supported for SOCKS5 proxies. When enabled, random proxy
+// credentials are used for each connection and connection reuse is disabled.
+// Tor uses the credentials to isolate the circuits that are used for each
+// request so that the requests can't be linked to each other by an observer.
+func proxySetup(tr *http.Transport, proxy string, isolation bool) error {
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return fmt.Errorf("invalid proxy url: %v", err)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid proxy url: host not found")
+	}
+
+	switch u.Scheme {
+	case proxySchemeHTTP, proxySchemeHTTPS:
+		if isolation {
+			return fmt.Errorf("proxy isolation is only supported for %v "+
+				"proxies", proxySchemeSOCKS5)
+		}
+		tr.Proxy = http.ProxyURL(u)
+
+	case proxySchemeSOCKS5:
+		_, _, err := net.SplitHostPort(u.Host)
+		if err != nil {
+			return fmt.Errorf("invalid proxy address '%v': %v", u.Host, err)
+		}
+		p := &socks.Proxy{
+			Addr:         u.Host,
+			TorIsolation: isolation,
+		}
+		if u.User != nil {
+			p.Username = u.User.Username()
+			p.Password, _ = u.User.Password()
+		}
+		tr.Proxy = nil
+		tr.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return p.Dial(network, addr)
+		}
+		if isolation {
+			// Each request must use a new connection in order for
+			// it to use a new set of proxy credentials.
+			tr.MaxConnsPerHost = 1
+			tr.DisableKeepAlives = true
+		}
+
+	default:
+		return fmt.Errorf("unsupported proxy scheme '%v'", u.Scheme)
+	}
+
+	return nil
+}
@@ -16,11 +16,10 @@ import (
 	"strconv"
 	"strings"
 
-	backend "github.com/decred/politeia/politeiad/backendv2"
 	"github.com/decred/politeia/politeiad/plugins/usermd"
 	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
 	v1 "github.com/decred/politeia/politeiawww/api/records/v1"
-	"github.com/decred/politeia/util"
+	"github.com/decred/politeia/politeiawww/client/internal/util"
 	"github.com/google/uuid"
 )
 
@@ -92,6 +91,64 @@ func (c *Client) RecordDetails(d rcv1.Details) (*rcv1.Record, error) {
 	return &dr.Record, nil
 }
 
+// RecordBatchDetails sends a records v1 BatchDetails request to politeiawww.
+func (c *Client) RecordBatchDetails(bd rcv1.BatchDetails) (*rcv1.BatchDetailsReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		rcv1.APIRoute, rcv1.RouteBatchDetails, bd)
+	if err != nil {
+		return nil, err
+	}
+
+	var bdr rcv1.BatchDetailsReply
+	err = json.Unmarshal(resBody, &bdr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bdr, nil
+}
+
+// RecordDetailsBatch retrieves the most recent version of the full records
+// for the provided tokens. The tokens are split into BatchDetails requests of
+// the maximum page size. The records that could not be retrieved are returned
+// in a map of the token to the error. An error is only returned if a request
+// fails as a whole.
+func (c *Client) RecordDetailsBatch(tokens []string) (map[string]rcv1.Record, map[string]error, error) {
+	var (
+		records = make(map[string]rcv1.Record, len(tokens))
+		errs    = make(map[string]error)
+	)
+	for len(tokens) > 0 {
+		n := rcv1.BatchDetailsPageSize
+		if len(tokens) < n {
+			n = len(tokens)
+		}
+		page := tokens[:n]
+		tokens = tokens[n:]
+
+		reqs := make([]rcv1.Details, 0, len(page))
+		for _, v := range page {
+			reqs = append(reqs, rcv1.Details{
+				Token: v,
+			})
+		}
+		bdr, err := c.RecordBatchDetails(rcv1.BatchDetails{
+			Requests: reqs,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		for k, v := range bdr.Records {
+			records[k] = v
+		}
+		for k, v := range bdr.Errors {
+			errs[k] = v
+		}
+	}
+
+	return records, errs, nil
+}
+
 // RecordTimestamps sends a records v1 Timestamps request to politeiawww.
 func (c *Client) RecordTimestamps(t rcv1.Timestamps) (*rcv1.TimestampsReply, error) {
 	resBody, err := c.makeReq(http.MethodPost,
@@ -281,11 +338,15 @@ func RecordVerify(r rcv1.Record, serverPubKey string) error {
 	return nil
 }
 
+// ErrNotTimestamped is returned by the timestamp verification functions when
+// the data has not been included in a dcr transaction yet.
+var ErrNotTimestamped = util.ErrNotTimestamped
+
 // RecordTimestampVerify verifies a records v1 API timestamp. This proves
 // inclusion of the data in the merkle root that was timestamped onto the dcr
 // blockchain.
 func RecordTimestampVerify(t rcv1.Timestamp) error {
-	return backend.VerifyTimestamp(convertRecordTimestamp(t))
+	return util.VerifyTimestamp(convertRecordTimestamp(t))
 }
 
 // RecordTimestampsVerify verifies all timestamps in a records v1 API
@@ -403,8 +464,8 @@ func StatusChangesVerify(sc []v1.StatusChange) error {
 	return nil
 }
 
-func convertRecordProof(p rcv1.Proof) backend.Proof {
-	return backend.Proof{
+func convertRecordProof(p rcv1.Proof) util.Proof {
+	return util.Proof{
 		Type:       p.Type,
 		Digest:     p.Digest,
 		MerkleRoot: p.MerkleRoot,
@@ -413,12 +474,12 @@ func convertRecordProof(p rcv1.Proof) backend.Proof {
 	}
 }
 
-func convertRecordTimestamp(t rcv1.Timestamp) backend.Timestamp {
-	proofs := make([]backend.Proof, 0, len(t.Proofs))
+func convertRecordTimestamp(t rcv1.Timestamp) util.Timestamp {
+	proofs := make([]util.Proof, 0, len(t.Proofs))
 	for _, v := range t.Proofs {
 		proofs = append(proofs, convertRecordProof(v))
 	}
-	return backend.Timestamp{
+	return util.Timestamp{
 		Data:       t.Data,
 		Digest:     t.Digest,
 		TxID:       t.TxID,
@@ -0,0 +1,36 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+)
+
+// unixSocketSetup configures the provided transport to send all requests over
+// the unix domain socket at the provided path. The host that the client was
+// created with is still used to construct the request URLs and, when https is
+// used, to verify the server certificate, but it is not used to dial the
+// server.
+func unixSocketSetup(tr *http.Transport, path string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("unix socket: %v", err)
+	}
+	if fi.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("unix socket: %v is not a socket", path)
+	}
+
+	var d net.Dialer
+	tr.Proxy = nil
+	tr.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return d.DialContext(ctx, "unix", path)
+	}
+
+	return nil
+}
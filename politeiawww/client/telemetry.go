@@ -0,0 +1,63 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+
+	tmv1 "github.com/decred/politeia/politeiawww/api/telemetry/v1"
+)
+
+// TelemetryPolicy sends a telemetry v1 Policy request to politeiawww.
+func (c *Client) TelemetryPolicy() (*tmv1.PolicyReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		tmv1.APIRoute, tmv1.RoutePolicy, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var pr tmv1.PolicyReply
+	err = json.Unmarshal(resBody, &pr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pr, nil
+}
+
+// TelemetryReport sends a telemetry v1 Report request to politeiawww.
+func (c *Client) TelemetryReport(r tmv1.Report) (*tmv1.ReportReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		tmv1.APIRoute, tmv1.RouteReport, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var rr tmv1.ReportReply
+	err = json.Unmarshal(resBody, &rr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rr, nil
+}
+
+// TelemetryStats sends a telemetry v1 Stats request to politeiawww.
+func (c *Client) TelemetryStats() (*tmv1.StatsReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		tmv1.APIRoute, tmv1.RouteStats, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var sr tmv1.StatsReply
+	err = json.Unmarshal(resBody, &sr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sr, nil
+}
@@ -5,17 +5,18 @@
 package client
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
 	"github.com/decred/dcrd/chaincfg/v3"
-	backend "github.com/decred/politeia/politeiad/backendv2"
 	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
-	"github.com/decred/politeia/util"
+	"github.com/decred/politeia/politeiawww/client/internal/util"
 )
 
 // TicketVotePolicy sends a ticketvote v1 Policy request to politeiawww.
@@ -121,6 +122,141 @@ func (c *Client) TicketVoteResults(r tkv1.Results) (*tkv1.ResultsReply, error) {
 	return &rr, nil
 }
 
+// VoteResultsIter iterates over the cast votes of a ticketvote v1 Results
+// reply. The reply is decoded as a stream so that only a single cast vote is
+// held in memory at any one time. Proposals with tens of thousands of votes
+// can be tallied without buffering the full reply.
+type VoteResultsIter struct {
+	body         io.ReadCloser
+	dec          *json.Decoder
+	serverPubKey string
+	vote         tkv1.CastVoteDetails
+	started      bool
+	done         bool
+	err          error
+}
+
+// TicketVoteResultsIter sends a ticketvote v1 Results request to politeiawww
+// and returns an iterator over the cast votes of the reply. Each cast vote is
+// verified as it is decoded when a server public key is provided. The caller
+// must close the iterator.
+func (c *Client) TicketVoteResultsIter(r tkv1.Results, serverPubKey string) (*VoteResultsIter, error) {
+	resp, err := c.doReq(http.MethodPost,
+		tkv1.APIRoute, tkv1.RouteResults, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VoteResultsIter{
+		body:         resp.Body,
+		dec:          json.NewDecoder(resp.Body),
+		serverPubKey: serverPubKey,
+	}, nil
+}
+
+// Next decodes the next cast vote. It returns false when there are no more
+// votes or when an error was encountered. Err must be checked once Next
+// returns false.
+func (i *VoteResultsIter) Next() bool {
+	if i.done || i.err != nil {
+		return false
+	}
+	if !i.started {
+		i.started = true
+		if err := i.seekVotes(); err != nil {
+			i.err = err
+			return false
+		}
+		if i.done {
+			return false
+		}
+	}
+	if !i.dec.More() {
+		// Consume the closing bracket of the votes array
+		if _, err := i.dec.Token(); err != nil {
+			i.err = err
+		}
+		i.done = true
+		return false
+	}
+
+	var cvd tkv1.CastVoteDetails
+	if err := i.dec.Decode(&cvd); err != nil {
+		i.err = fmt.Errorf("decode vote: %v", err)
+		return false
+	}
+	if i.serverPubKey != "" {
+		err := CastVoteDetailsVerify(cvd, i.serverPubKey)
+		if err != nil {
+			i.err = fmt.Errorf("vote %v: %v", cvd.Ticket, err)
+			return false
+		}
+	}
+	i.vote = cvd
+
+	return true
+}
+
+// Vote returns the cast vote that was decoded by the most recent call to
+// Next.
+func (i *VoteResultsIter) Vote() tkv1.CastVoteDetails {
+	return i.vote
+}
+
+// Err returns the first error that was encountered by the iterator.
+func (i *VoteResultsIter) Err() error {
+	return i.err
+}
+
+// Close closes the reply body.
+func (i *VoteResultsIter) Close() error {
+	i.done = true
+	return i.body.Close()
+}
+
+// seekVotes advances the decoder to the first element of the votes array.
+// All other fields of the reply are skipped. The iterator is marked as done
+// if the reply does not contain any votes.
+func (i *VoteResultsIter) seekVotes() error {
+	t, err := i.dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := t.(json.Delim); !ok || d != '{' {
+		return fmt.Errorf("unexpected token %v; want {", t)
+	}
+	for i.dec.More() {
+		t, err := i.dec.Token()
+		if err != nil {
+			return err
+		}
+		if key, ok := t.(string); !ok || key != "votes" {
+			// Skip the value
+			var raw json.RawMessage
+			if err := i.dec.Decode(&raw); err != nil {
+				return err
+			}
+			continue
+		}
+		t, err = i.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t {
+		case json.Delim('['):
+			return nil
+		case nil:
+			// Votes is null
+			i.done = true
+			return nil
+		default:
+			return fmt.Errorf("unexpected token %v; want [", t)
+		}
+	}
+	i.done = true
+	return nil
+}
+
 // TicketVoteSummaries sends a ticketvote v1 Summaries request to politeiawww.
 func (c *Client) TicketVoteSummaries(s tkv1.Summaries) (*tkv1.SummariesReply, error) {
 	resBody, err := c.makeReq(http.MethodPost,
@@ -191,10 +327,87 @@ func (c *Client) TicketVoteTimestamps(t tkv1.Timestamps) (*tkv1.TimestampsReply,
 	return &tr, nil
 }
 
+// TicketVoteTimestampsStream retrieves the ticket vote timestamps of a record
+// in chunks and invokes the provided callback with each chunk. The first
+// chunk contains the vote authorization and vote details timestamps. Each
+// subsequent chunk contains a page of cast vote timestamps. Only a single
+// chunk is held in memory at any one time, which allows the timestamps of
+// votes with thousands of cast votes to be processed. Retrieval stops at the
+// first error that is returned by the callback.
+func (c *Client) TicketVoteTimestampsStream(token string, fn func(*tkv1.TimestampsReply) error) error {
+	tr, err := c.TicketVoteTimestamps(tkv1.Timestamps{
+		Token: token,
+	})
+	if err != nil {
+		return err
+	}
+	err = fn(tr)
+	if err != nil {
+		return err
+	}
+
+	for page := uint32(1); ; page++ {
+		tr, err := c.TicketVoteTimestamps(tkv1.Timestamps{
+			Token:     token,
+			VotesPage: page,
+		})
+		if err != nil {
+			return err
+		}
+		if len(tr.Votes) == 0 {
+			break
+		}
+		err = fn(tr)
+		if err != nil {
+			return err
+		}
+		if uint32(len(tr.Votes)) < tkv1.VoteTimestampsPageSize {
+			break
+		}
+	}
+
+	return nil
+}
+
+// TicketVoteCertificate sends a ticketvote v1 Certificate request to
+// politeiawww.
+func (c *Client) TicketVoteCertificate(cr tkv1.Certificate) (*tkv1.CertificateReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		tkv1.APIRoute, tkv1.RouteCertificate, cr)
+	if err != nil {
+		return nil, err
+	}
+
+	var crr tkv1.CertificateReply
+	err = json.Unmarshal(resBody, &crr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &crr, nil
+}
+
+// TicketVoteTallies sends a ticketvote v1 Tallies request to politeiawww.
+func (c *Client) TicketVoteTallies(t tkv1.Tallies) (*tkv1.TalliesReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		tkv1.APIRoute, tkv1.RouteTallies, t)
+	if err != nil {
+		return nil, err
+	}
+
+	var tr tkv1.TalliesReply
+	err = json.Unmarshal(resBody, &tr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tr, nil
+}
+
 // TicketVoteTimestampVerify verifies that the provided ticketvote v1 Timestamp
 // is valid.
 func TicketVoteTimestampVerify(t tkv1.Timestamp) error {
-	return backend.VerifyTimestamp(convertVoteTimestamp(t))
+	return util.VerifyTimestamp(convertVoteTimestamp(t))
 }
 
 // TicketVoteTimestampsVerify verifies that all timestamps in the ticketvote
@@ -311,6 +524,9 @@ func CastVoteDetailsVerify(cvd tkv1.CastVoteDetails, serverPublicKey string) err
 	// Verify signature. The signature must be converted from hex to
 	// base64. This is what the verify message function expects.
 	msg := cvd.Token + cvd.Ticket + cvd.VoteBit
+	if cvd.Nonce != "" {
+		msg += cvd.Nonce + strconv.FormatInt(cvd.Expiry, 10)
+	}
 	b, err := hex.DecodeString(cvd.Signature)
 	if err != nil {
 		return fmt.Errorf("signature invalid hex")
@@ -333,8 +549,181 @@ func CastVoteDetailsVerify(cvd tkv1.CastVoteDetails, serverPublicKey string) err
 	return nil
 }
 
-func convertVoteProof(p tkv1.Proof) backend.Proof {
-	return backend.Proof{
+// CertificateVerify verifies the politeiawww signature of a ticketvote v1
+// CertificateReply. The serverPubKey is the politeiawww signing key that is
+// expected to have signed the certificate. The previous signing key is
+// accepted during a signing identity rotation.
+func CertificateVerify(cr tkv1.CertificateReply, serverPubKey string) error {
+	vc := cr.Certificate
+	b, err := json.Marshal(vc)
+	if err != nil {
+		return err
+	}
+	err = serverSignatureVerify(hex.EncodeToString(util.Digest(b)),
+		serverPubKey, vc.ServerPubKey, cr.Signature, vc.PrevServerPubKey,
+		cr.PrevSignature)
+	if err != nil {
+		return fmt.Errorf("unable to verify certificate signature: %v", err)
+	}
+	return nil
+}
+
+// CertificateResultsVerify verifies the outcome of a ticketvote v1
+// VoteCertificate against the vote details and the cast votes of the record
+// vote. The eligible tickets are checked against the snapshot digest of the
+// certificate, the cast votes are tallied and checked against the vote
+// totals, and the quorum and pass thresholds are recalculated.
+//
+// The signatures of the vote details and the cast votes are not verified by
+// this function. See VoteDetailsVerify and CastVoteDetailsVerify.
+func CertificateResultsVerify(vc tkv1.VoteCertificate, vd tkv1.VoteDetails, votes []tkv1.CastVoteDetails) error {
+	// Verify the vote details
+	switch {
+	case vd.Params.Token != vc.Token:
+		return fmt.Errorf("vote details token mismatch: got %v, want %v",
+			vd.Params.Token, vc.Token)
+	case vd.Params.Version != vc.Version:
+		return fmt.Errorf("record version mismatch: got %v, want %v",
+			vd.Params.Version, vc.Version)
+	case vd.StartBlockHash != vc.StartBlockHash,
+		vd.StartBlockHeight != vc.StartBlockHeight,
+		vd.EndBlockHeight != vc.EndBlockHeight:
+		return fmt.Errorf("voting period does not match the certificate")
+	case vd.Params.QuorumPercentage != vc.QuorumPercentage,
+		vd.Params.PassPercentage != vc.PassPercentage:
+		return fmt.Errorf("vote thresholds do not match the certificate")
+	case uint32(len(vd.EligibleTickets)) != vc.EligibleTickets:
+		return fmt.Errorf("eligible tickets mismatch: got %v, want %v",
+			len(vd.EligibleTickets), vc.EligibleTickets)
+	}
+	b, err := json.Marshal(vd.EligibleTickets)
+	if err != nil {
+		return err
+	}
+	digest := hex.EncodeToString(util.Digest(b))
+	if digest != vc.EligibleTicketsDigest {
+		return fmt.Errorf("eligible tickets digest mismatch: got %v, want %v",
+			digest, vc.EligibleTicketsDigest)
+	}
+
+	// Tally the cast votes. Every vote must be cast by a unique
+	// eligible ticket.
+	var (
+		eligible = make(map[string]struct{}, len(vd.EligibleTickets))
+		voted    = make(map[string]struct{}, len(votes))
+		tally    = make(map[uint64]uint64, len(vc.Results)) // [voteBit]votes
+	)
+	for _, v := range vd.EligibleTickets {
+		eligible[v] = struct{}{}
+	}
+	for _, v := range votes {
+		if v.Token != vc.Token {
+			return fmt.Errorf("vote %v token mismatch: got %v, want %v",
+				v.Ticket, v.Token, vc.Token)
+		}
+		if _, ok := eligible[v.Ticket]; !ok {
+			return fmt.Errorf("ticket %v is not eligible", v.Ticket)
+		}
+		if _, ok := voted[v.Ticket]; ok {
+			return fmt.Errorf("duplicate vote for ticket %v", v.Ticket)
+		}
+		voted[v.Ticket] = struct{}{}
+		bit, err := strconv.ParseUint(v.VoteBit, 16, 64)
+		if err != nil {
+			return fmt.Errorf("vote %v invalid vote bit %v", v.Ticket, v.VoteBit)
+		}
+		tally[bit]++
+	}
+
+	// Verify the vote totals
+	var total, approve uint64
+	for _, v := range vc.Results {
+		if tally[v.VoteBit] != v.Votes {
+			return fmt.Errorf("vote option %v: got %v votes, want %v",
+				v.ID, tally[v.VoteBit], v.Votes)
+		}
+		delete(tally, v.VoteBit)
+		total += v.Votes
+		if v.ID == tkv1.VoteOptionIDApprove {
+			approve = v.Votes
+		}
+	}
+	if len(tally) > 0 {
+		return fmt.Errorf("votes were cast for bits that are not vote options")
+	}
+	if total != vc.TotalVotes || approve != vc.ApproveVotes {
+		return fmt.Errorf("vote totals mismatch: got %v total and %v approve, "+
+			"want %v total and %v approve", total, approve, vc.TotalVotes,
+			vc.ApproveVotes)
+	}
+
+	// Verify the thresholds. This mirrors the politeiawww calculation.
+	var (
+		quorumPerc = float64(vc.QuorumPercentage)
+		passPerc   = float64(vc.PassPercentage)
+		quorum     = uint64(quorumPerc / 100 * float64(vc.EligibleTickets))
+		pass       = uint64(passPerc / 100 * float64(total))
+	)
+	switch {
+	case quorum != vc.Quorum || (total >= quorum) != vc.QuorumMet:
+		return fmt.Errorf("quorum mismatch: got %v, want %v", quorum, vc.Quorum)
+	case pass != vc.Pass || (approve >= pass) != vc.PassMet:
+		return fmt.Errorf("pass mismatch: got %v, want %v", pass, vc.Pass)
+	}
+
+	return nil
+}
+
+// CertificateAnchorVerify verifies that the anchor references of a ticketvote
+// v1 VoteCertificate match the provided vote details timestamp, that the
+// timestamp is valid, and that the timestamped data is the provided vote
+// details.
+func CertificateAnchorVerify(vc tkv1.VoteCertificate, vd tkv1.VoteDetails, t tkv1.Timestamp) error {
+	if vc.AnchorTxID == "" {
+		return fmt.Errorf("certificate does not contain anchor references")
+	}
+	switch {
+	case t.Digest != vc.AnchorDigest:
+		return fmt.Errorf("anchor digest mismatch: got %v, want %v",
+			t.Digest, vc.AnchorDigest)
+	case t.TxID != vc.AnchorTxID:
+		return fmt.Errorf("anchor tx mismatch: got %v, want %v",
+			t.TxID, vc.AnchorTxID)
+	case t.MerkleRoot != vc.AnchorMerkleRoot:
+		return fmt.Errorf("anchor merkle root mismatch: got %v, want %v",
+			t.MerkleRoot, vc.AnchorMerkleRoot)
+	case t.Data == "":
+		return fmt.Errorf("vote details timestamp does not contain data")
+	}
+
+	// Verify that the timestamp is for the provided vote details. The
+	// timestamp data is decoded and encoded again so that the two are
+	// compared using the same encoding. The timestamp digest is
+	// verified against the timestamp data below.
+	var tvd tkv1.VoteDetails
+	err := json.Unmarshal([]byte(t.Data), &tvd)
+	if err != nil {
+		return fmt.Errorf("could not unmarshal vote details timestamp "+
+			"data: %v", err)
+	}
+	tb, err := json.Marshal(tvd)
+	if err != nil {
+		return err
+	}
+	vb, err := json.Marshal(vd)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(tb, vb) {
+		return fmt.Errorf("vote details timestamp is not for the provided " +
+			"vote details")
+	}
+
+	return TicketVoteTimestampVerify(t)
+}
+
+func convertVoteProof(p tkv1.Proof) util.Proof {
+	return util.Proof{
 		Type:       p.Type,
 		Digest:     p.Digest,
 		MerkleRoot: p.MerkleRoot,
@@ -343,12 +732,12 @@ func convertVoteProof(p tkv1.Proof) backend.Proof {
 	}
 }
 
-func convertVoteTimestamp(t tkv1.Timestamp) backend.Timestamp {
-	proofs := make([]backend.Proof, 0, len(t.Proofs))
+func convertVoteTimestamp(t tkv1.Timestamp) util.Timestamp {
+	proofs := make([]util.Proof, 0, len(t.Proofs))
 	for _, v := range t.Proofs {
 		proofs = append(proofs, convertVoteProof(v))
 	}
-	return backend.Timestamp{
+	return util.Timestamp{
 		Data:       t.Data,
 		Digest:     t.Digest,
 		TxID:       t.TxID,
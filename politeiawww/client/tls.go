@@ -0,0 +1,54 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+)
+
+// tlsSetup applies the TLS options to the provided transport. The provided
+// TLS config is cloned so that the caller is free to reuse it. The root CAs
+// that were created from the HTTPSCert are used when the TLS config does not
+// specify any root CAs.
+func tlsSetup(tr *http.Transport, opts Opts) error {
+	if (opts.ClientCert == "") != (opts.ClientKey == "") {
+		return fmt.Errorf("client cert and client key must be provided " +
+			"together")
+	}
+	if opts.TLSConfig == nil && opts.ClientCert == "" {
+		return nil
+	}
+
+	cfg := tr.TLSClientConfig
+	if cfg == nil {
+		cfg = &tls.Config{}
+	}
+	if opts.TLSConfig != nil {
+		c := opts.TLSConfig.Clone()
+		if c.RootCAs != nil && opts.HTTPSCert != "" {
+			return fmt.Errorf("https cert can not be used with a tls " +
+				"config that contains root CAs")
+		}
+		if c.RootCAs == nil {
+			c.RootCAs = cfg.RootCAs
+		}
+		cfg = c
+	}
+
+	// Load the client certificate for mutual TLS
+	if opts.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(opts.ClientCert, opts.ClientKey)
+		if err != nil {
+			return fmt.Errorf("load client cert: %v", err)
+		}
+		cfg.Certificates = append(cfg.Certificates, cert)
+	}
+
+	tr.TLSClientConfig = cfg
+
+	return nil
+}
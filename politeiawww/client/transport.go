@@ -0,0 +1,66 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+)
+
+// transportSetup applies the transport tuning options to the provided http
+// client. Options that are not set leave the util.NewHTTPClient defaults in
+// place.
+func transportSetup(h *http.Client, tr *http.Transport, opts Opts) error {
+	switch {
+	case opts.MaxIdleConns < 0:
+		return fmt.Errorf("invalid max idle conns %v", opts.MaxIdleConns)
+	case opts.MaxIdleConnsPerHost < 0:
+		return fmt.Errorf("invalid max idle conns per host %v",
+			opts.MaxIdleConnsPerHost)
+	case opts.MaxConnsPerHost < 0:
+		return fmt.Errorf("invalid max conns per host %v",
+			opts.MaxConnsPerHost)
+	case opts.IdleConnTimeout < 0:
+		return fmt.Errorf("invalid idle conn timeout %v", opts.IdleConnTimeout)
+	case opts.Timeout < 0:
+		return fmt.Errorf("invalid timeout %v", opts.Timeout)
+	case opts.HTTP2 && opts.DisableHTTP2:
+		return fmt.Errorf("http2 can not be both enabled and disabled")
+	case opts.HTTP2 && opts.ProxyIsolation:
+		// HTTP/2 multiplexes requests over a single connection, which
+		// would defeat the per request proxy credentials.
+		return fmt.Errorf("http2 can not be used with proxy isolation")
+	}
+
+	if opts.MaxIdleConns != 0 {
+		tr.MaxIdleConns = opts.MaxIdleConns
+	}
+	if opts.MaxIdleConnsPerHost != 0 {
+		tr.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	}
+	if opts.MaxConnsPerHost != 0 {
+		tr.MaxConnsPerHost = opts.MaxConnsPerHost
+	}
+	if opts.IdleConnTimeout != 0 {
+		tr.IdleConnTimeout = opts.IdleConnTimeout
+	}
+	if opts.Timeout != 0 {
+		h.Timeout = opts.Timeout
+	}
+
+	// The transport uses a custom TLS config, which means the standard
+	// library will not attempt HTTP/2 unless it is explicitly forced.
+	switch {
+	case opts.HTTP2:
+		tr.ForceAttemptHTTP2 = true
+	case opts.DisableHTTP2:
+		tr.ForceAttemptHTTP2 = false
+		tr.TLSNextProto = make(map[string]func(string,
+			*tls.Conn) http.RoundTripper)
+	}
+
+	return nil
+}
@@ -0,0 +1,252 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/client/internal/util"
+)
+
+// UserDetails sends a www v1 UserDetails request to politeiawww. The reply is
+// verified before being returned.
+func (c *Client) UserDetails(ud www.UserDetails) (*www.UserDetailsReply, error) {
+	route := "/user/" + ud.UserID
+	resBody, err := c.makeReq(http.MethodGet,
+		www.PoliteiaWWWAPIRoute, route, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var udr www.UserDetailsReply
+	err = json.Unmarshal(resBody, &udr)
+	if err != nil {
+		return nil, err
+	}
+	err = UserDetailsVerify(udr.User, ud.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &udr, nil
+}
+
+// UserMe sends a www v1 Me request to politeiawww.
+func (c *Client) UserMe() (*www.LoginReply, error) {
+	resBody, err := c.makeReq(http.MethodGet,
+		www.PoliteiaWWWAPIRoute, www.RouteUserMe, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var lr www.LoginReply
+	err = json.Unmarshal(resBody, &lr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lr, nil
+}
+
+// UserKeyUpdate sends a www v1 UpdateUserKey request to politeiawww.
+func (c *Client) UserKeyUpdate(uuk www.UpdateUserKey) (*www.UpdateUserKeyReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		www.PoliteiaWWWAPIRoute, www.RouteUpdateUserKey, uuk)
+	if err != nil {
+		return nil, err
+	}
+
+	var uukr www.UpdateUserKeyReply
+	err = json.Unmarshal(resBody, &uukr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &uukr, nil
+}
+
+// UserKeyVerify sends a www v1 VerifyUpdateUserKey request to politeiawww.
+func (c *Client) UserKeyVerify(vuuk www.VerifyUpdateUserKey) (*www.VerifyUpdateUserKeyReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		www.PoliteiaWWWAPIRoute, www.RouteVerifyUpdateUserKey, vuuk)
+	if err != nil {
+		return nil, err
+	}
+
+	var vuukr www.VerifyUpdateUserKeyReply
+	err = json.Unmarshal(resBody, &vuukr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vuukr, nil
+}
+
+// UserTOTPSet sends a www v1 SetTOTP request to politeiawww. The reply is
+// verified before being returned.
+func (c *Client) UserTOTPSet(st www.SetTOTP) (*www.SetTOTPReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		www.PoliteiaWWWAPIRoute, www.RouteSetTOTP, st)
+	if err != nil {
+		return nil, err
+	}
+
+	var str www.SetTOTPReply
+	err = json.Unmarshal(resBody, &str)
+	if err != nil {
+		return nil, err
+	}
+	err = SetTOTPReplyVerify(str)
+	if err != nil {
+		return nil, err
+	}
+
+	return &str, nil
+}
+
+// UserTOTPVerify sends a www v1 VerifyTOTP request to politeiawww.
+func (c *Client) UserTOTPVerify(vt www.VerifyTOTP) (*www.VerifyTOTPReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		www.PoliteiaWWWAPIRoute, www.RouteVerifyTOTP, vt)
+	if err != nil {
+		return nil, err
+	}
+
+	var vtr www.VerifyTOTPReply
+	err = json.Unmarshal(resBody, &vtr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtr, nil
+}
+
+// UserStakeVerify sends a www v1 VerifyStake request to politeiawww.
+func (c *Client) UserStakeVerify(vs www.VerifyStake) (*www.VerifyStakeReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		www.PoliteiaWWWAPIRoute, www.RouteVerifyStake, vs)
+	if err != nil {
+		return nil, err
+	}
+
+	var vsr www.VerifyStakeReply
+	err = json.Unmarshal(resBody, &vsr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vsr, nil
+}
+
+// UserPasswordChange sends a www v1 ChangePassword request to politeiawww.
+func (c *Client) UserPasswordChange(cp www.ChangePassword) (*www.ChangePasswordReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		www.PoliteiaWWWAPIRoute, www.RouteChangePassword, cp)
+	if err != nil {
+		return nil, err
+	}
+
+	var cpr www.ChangePasswordReply
+	err = json.Unmarshal(resBody, &cpr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cpr, nil
+}
+
+// UserEmailVerify sends a www v1 VerifyNewUser request to politeiawww.
+func (c *Client) UserEmailVerify(vnu www.VerifyNewUser) (*www.VerifyNewUserReply, error) {
+	resBody, err := c.makeReq(http.MethodGet,
+		www.PoliteiaWWWAPIRoute, www.RouteVerifyNewUser, &vnu)
+	if err != nil {
+		return nil, err
+	}
+
+	var vnur www.VerifyNewUserReply
+	err = json.Unmarshal(resBody, &vnur)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vnur, nil
+}
+
+// UserBotNew sends a www v1 NewBot request to politeiawww.
+func (c *Client) UserBotNew(nb www.NewBot) (*www.NewBotReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		www.PoliteiaWWWAPIRoute, www.RouteNewBot, nb)
+	if err != nil {
+		return nil, err
+	}
+
+	var nbr www.NewBotReply
+	err = json.Unmarshal(resBody, &nbr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nbr, nil
+}
+
+// UserBotAPIKey sends a www v1 BotAPIKey request to politeiawww.
+func (c *Client) UserBotAPIKey(bk www.BotAPIKey) (*www.BotAPIKeyReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		www.PoliteiaWWWAPIRoute, www.RouteBotAPIKey, bk)
+	if err != nil {
+		return nil, err
+	}
+
+	var bkr www.BotAPIKeyReply
+	err = json.Unmarshal(resBody, &bkr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bkr, nil
+}
+
+// UserDetailsVerify verifies that the provided www v1 User is the user that
+// was requested and that it contains no more than one active identity. The
+// identity public keys must be valid ed25519 public keys.
+func UserDetailsVerify(u www.User, userID string) error {
+	if u.ID != userID {
+		return fmt.Errorf("user id mismatch: got %v, want %v",
+			u.ID, userID)
+	}
+	var active int
+	for _, v := range u.Identities {
+		_, err := util.IdentityFromString(v.Pubkey)
+		if err != nil {
+			return fmt.Errorf("invalid user identity %v: %v",
+				v.Pubkey, err)
+		}
+		if v.Active {
+			active++
+		}
+	}
+	if active > 1 {
+		return fmt.Errorf("user %v has %v active identities",
+			u.ID, active)
+	}
+	return nil
+}
+
+// SetTOTPReplyVerify verifies that the provided www v1 SetTOTPReply contains
+// a TOTP key and a valid base64 encoded QR code image.
+func SetTOTPReplyVerify(str www.SetTOTPReply) error {
+	if str.Key == "" {
+		return fmt.Errorf("totp key not found")
+	}
+	_, err := base64.StdEncoding.DecodeString(str.Image)
+	if err != nil {
+		return fmt.Errorf("invalid totp image: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/client/internal/util"
+)
+
+var (
+	// apiVersions contains the plugin API versions that are supported
+	// by this client.
+	apiVersions = map[string][]uint32{
+		"records":    {1},
+		"comments":   {1},
+		"ticketvote": {1},
+		"pi":         {1},
+	}
+)
+
+// Version sends a Version request to politeiawww.
+func (c *Client) Version() (*www.VersionReply, error) {
+	resBody, err := c.makeReq(http.MethodGet,
+		www.PoliteiaWWWAPIRoute, www.RouteVersion, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var vr www.VersionReply
+	err = json.Unmarshal(resBody, &vr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vr, nil
+}
+
+// Policies sends a Policies request to politeiawww.
+func (c *Client) Policies() (*www.PoliciesReply, error) {
+	resBody, err := c.makeReq(http.MethodGet,
+		www.PoliteiaWWWAPIRoute, www.RoutePolicies, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var pr www.PoliciesReply
+	err = json.Unmarshal(resBody, &pr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pr, nil
+}
+
+// SiteInfo sends a SiteInfo request to politeiawww.
+func (c *Client) SiteInfo() (*www.SiteInfoReply, error) {
+	resBody, err := c.makeReq(http.MethodGet,
+		www.PoliteiaWWWAPIRoute, www.RouteSiteInfo, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var sr www.SiteInfoReply
+	err = json.Unmarshal(resBody, &sr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sr, nil
+}
+
+// Negotiate requests the politeiawww version and records the server public
+// key and the highest plugin API versions that are supported by both the
+// client and the server. Subsequent plugin API requests are routed to the
+// negotiated API versions. A server that does not return its supported plugin
+// API versions is assumed to only support v1 of the plugin APIs.
+//
+// An error is returned if the client and the server do not have a plugin API
+// version in common.
+func (c *Client) Negotiate() (*www.VersionReply, error) {
+	vr, err := c.Version()
+	if err != nil {
+		return nil, err
+	}
+
+	negotiated := make(map[string]uint32, len(apiVersions))
+	for api, versions := range apiVersions {
+		server, ok := vr.APIs[api]
+		if !ok {
+			server = []uint32{1}
+		}
+		v, ok := highestCommon(versions, server)
+		if !ok {
+			return nil, fmt.Errorf("no mutually supported %v api version; "+
+				"client %v, server %v", api, versions, server)
+		}
+		negotiated[api] = v
+	}
+
+	c.Lock()
+	c.serverPubKey = vr.PubKey
+	c.apiVersions = negotiated
+	c.Unlock()
+
+	return vr, nil
+}
+
+// ServerPubKey returns the politeiawww public key that was recorded during
+// version negotiation. An empty string is returned if the client has not
+// negotiated with the server.
+func (c *Client) ServerPubKey() string {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.serverPubKey
+}
+
+// APIVersion returns the negotiated version of the provided plugin API, e.g.
+// "ticketvote". Zero is returned if the API version has not been negotiated.
+func (c *Client) APIVersion(api string) uint32 {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.apiVersions[api]
+}
+
+// apiRoute returns the API route prefix that a request should be sent to. A
+// plugin API route, e.g. "/ticketvote/v1", is rewritten to use the negotiated
+// API version. All other API routes are returned unchanged.
+func (c *Client) apiRoute(api string) string {
+	s := strings.Split(strings.TrimPrefix(api, "/"), "/")
+	if len(s) != 2 || !strings.HasPrefix(s[1], "v") {
+		return api
+	}
+	if _, err := strconv.ParseUint(s[1][1:], 10, 32); err != nil {
+		return api
+	}
+
+	v := c.APIVersion(s[0])
+	if v == 0 {
+		return api
+	}
+
+	return fmt.Sprintf("/%v/v%v", s[0], v)
+}
+
+// highestCommon returns the highest version that is in both of the provided
+// version lists.
+func highestCommon(a, b []uint32) (uint32, bool) {
+	var (
+		highest uint32
+		found   bool
+	)
+	for _, v := range a {
+		for _, w := range b {
+			if v == w && v >= highest {
+				highest = v
+				found = true
+			}
+		}
+	}
+	return highest, found
+}
+
+// serverSignatureVerify verifies a politeiawww signature of the provided
+// message. The serverPubKey is the politeiawww signing key that the caller
+// expects to have signed the message. pubKey and sig are the signing key and
+// the signature that were returned by politeiawww. prevPubKey and prevSig are
+// only returned during a signing identity rotation and contain the previous
+// signing key and signature. Either key is accepted so that data remains
+// verifiable by clients that have not learned about the rotation yet.
+func serverSignatureVerify(msg, serverPubKey, pubKey, sig, prevPubKey, prevSig string) error {
+	switch {
+	case serverPubKey == pubKey:
+		return util.VerifySignature(sig, pubKey, msg)
+	case prevPubKey != "" && serverPubKey == prevPubKey:
+		return util.VerifySignature(prevSig, prevPubKey, msg)
+	}
+	return fmt.Errorf("server key mismatch: got %v, want %v",
+		pubKey, serverPubKey)
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package voter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// The following are the journal filenames. The journal files are
+	// prefixed by the journal filename and suffixed by the unix time
+	// of the run that created them.
+	JournalFailed  = "failed.json"  // Votes that failed to be cast
+	JournalSuccess = "success.json" // Receipts of the cast votes
+	JournalWork    = "work.json"    // Vote schedule
+)
+
+// JournalTime is the timestamp entry that precedes every journal entry.
+type JournalTime struct {
+	Time string `json:"time"`
+}
+
+// Journal writes JSON journal entries of the voting work that is performed
+// during a run. The journal is written to a directory per record token and
+// allows the votes of a run to be verified after the vote has ended.
+type Journal struct {
+	dir string    // Journal root directory
+	run time.Time // Start of the run
+}
+
+// NewJournal returns a new Journal that writes to the provided directory.
+// The run time is used to keep the journal files of different runs apart.
+func NewJournal(dir string, run time.Time) *Journal {
+	return &Journal{
+		dir: dir,
+		run: run,
+	}
+}
+
+// Dir returns the journal directory of the provided record token.
+func (j *Journal) Dir(token string) string {
+	return filepath.Join(j.dir, token)
+}
+
+// Log appends a timestamp entry followed by the provided entries to a journal
+// file of the provided record token.
+func (j *Journal) Log(filename, token string, entries ...interface{}) error {
+	dir := j.Dir(token)
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return err
+	}
+
+	f := filepath.Join(dir, fmt.Sprintf("%v.%v", filename, j.run.Unix()))
+	fh, err := os.OpenFile(f, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	e := json.NewEncoder(fh)
+	e.SetIndent("", "  ")
+	err = e.Encode(JournalTime{
+		Time: time.Now().Format(time.StampNano),
+	})
+	if err != nil {
+		return err
+	}
+	for _, v := range entries {
+		err = e.Encode(v)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package voter
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	"github.com/decred/politeia/politeiawww/client"
+	"github.com/decred/politeia/politeiawww/client/voter/uniformprng"
+)
+
+const (
+	// defaultRetries is the default number of times that a vote that
+	// failed with a retryable error is retried.
+	defaultRetries = 10
+
+	// defaultRetryDelay is the default maximum delay before a vote that
+	// failed with a retryable error is retried.
+	defaultRetryDelay = 5 * time.Minute
+)
+
+// Interval is a vote along with the delay that must elapse after the previous
+// vote before the vote is cast. This is a JSON structure so that the vote
+// schedule can be journaled.
+type Interval struct {
+	Vote tkv1.CastVote `json:"vote"` // Signed vote
+	At   time.Duration `json:"at"`   // Delay to cast the vote
+}
+
+// Schedule spreads the provided votes out over the provided duration. The
+// vote times are drawn from a uniform distribution using a cryptographically
+// secure random number generator so that the vote times can't be used to
+// link the tickets of a wallet together. The votes are scheduled in the order
+// that they are provided, so they should be shuffled by the caller.
+func Schedule(votes []tkv1.CastVote, duration time.Duration) ([]Interval, error) {
+	if duration <= 0 {
+		return nil, fmt.Errorf("invalid duration %v", duration)
+	}
+	prng, err := uniformprng.RandSource(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	ts := make([]time.Duration, 0, len(votes))
+	for range votes {
+		ts = append(ts, time.Duration(prng.Int63n(int64(duration))))
+	}
+	sort.Slice(ts, func(i, j int) bool { return ts[i] < ts[j] })
+
+	var previous time.Duration
+	intervals := make([]Interval, 0, len(votes))
+	for k, v := range votes {
+		intervals = append(intervals, Interval{
+			Vote: v,
+			At:   ts[k] - previous, // Delta to previous timestamp
+		})
+		previous = ts[k]
+	}
+
+	return intervals, nil
+}
+
+// Caster casts a ballot of votes. It is satisfied by a client.Client.
+type Caster interface {
+	TicketVoteCastBallot(tkv1.CastBallot) (*tkv1.CastBallotReply, error)
+}
+
+// Retryable returns whether a cast ballot error is temporary, such as a
+// network error or a politeiawww internal server error, and the vote can be
+// cast again.
+func Retryable(err error) bool {
+	var re client.RespErr
+	if errors.As(err, &re) {
+		return re.HTTPCode >= http.StatusInternalServerError
+	}
+	return !errors.Is(err, context.Canceled)
+}
+
+// TrickleOpts contains the options for trickling votes. All fields are
+// optional.
+type TrickleOpts struct {
+	// Journal journals the cast votes and the failed votes.
+	Journal *Journal
+
+	// Retries is the number of times that a vote that failed with a
+	// retryable error is retried. Defaults to 10.
+	Retries int
+
+	// RetryDelay is the maximum random delay before a failed vote is
+	// retried. Defaults to 5 minutes.
+	RetryDelay time.Duration
+
+	// Cast is called after every attempt to cast a vote. The receipt
+	// is nil when the attempt failed.
+	Cast func(v tkv1.CastVote, r *tkv1.CastVoteReply, err error)
+}
+
+// retry is a vote that failed with a retryable error.
+type retry struct {
+	vote     tkv1.CastVote
+	due      time.Time
+	attempts int
+}
+
+// Trickle casts the votes one at a time according to the provided schedule.
+// Each vote is cast in its own ballot so that the server can't link the
+// tickets together. The first vote is cast without a delay. Votes that fail
+// with a retryable error are retried after a random delay in between the
+// scheduled votes. Trickling stops when the vote has ended.
+//
+// The receipts of the votes that were cast are returned. The receipts are
+// returned along with the error if trickling was aborted, e.g. when the
+// context was canceled.
+func Trickle(ctx context.Context, c Caster, token string, intervals []Interval, opts *TrickleOpts) ([]tkv1.CastVoteReply, error) {
+	if opts == nil {
+		opts = &TrickleOpts{}
+	}
+	retries := opts.Retries
+	if retries == 0 {
+		retries = defaultRetries
+	}
+	retryDelay := opts.RetryDelay
+	if retryDelay == 0 {
+		retryDelay = defaultRetryDelay
+	}
+	prng, err := uniformprng.RandSource(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	journal := func(filename string, entries ...interface{}) error {
+		if opts.Journal == nil {
+			return nil
+		}
+		return opts.Journal.Log(filename, token, entries...)
+	}
+	if len(intervals) > 0 {
+		err = journal(JournalWork, intervals)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		receipts = make([]tkv1.CastVoteReply, 0, len(intervals))
+		queue    []retry // Sorted by due time
+		next     = time.Now()
+		i        int
+	)
+	for i < len(intervals) || len(queue) > 0 {
+		// Cast the next scheduled vote or the next retry, whichever
+		// is due first.
+		var (
+			r      retry
+			due    time.Time
+			isMain = i < len(intervals) &&
+				(len(queue) == 0 || !queue[0].due.Before(next))
+		)
+		if isMain {
+			if i > 0 {
+				next = next.Add(intervals[i].At)
+			}
+			r = retry{vote: intervals[i].Vote}
+			due = next
+			i++
+		} else {
+			r = queue[0]
+			queue = queue[1:]
+			due = r.due
+		}
+
+		t := time.NewTimer(time.Until(due))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return receipts, ctx.Err()
+		case <-t.C:
+		}
+
+		b := tkv1.CastBallot{Votes: []tkv1.CastVote{r.vote}}
+		br, err := c.TicketVoteCastBallot(b)
+		if err == nil && len(br.Receipts) != 1 {
+			err = fmt.Errorf("unexpected receipt count: got %v, want 1",
+				len(br.Receipts))
+		}
+		if err != nil {
+			if opts.Cast != nil {
+				opts.Cast(r.vote, nil, err)
+			}
+			if !Retryable(err) {
+				return receipts, err
+			}
+			jerr := journal(JournalFailed, b, err.Error())
+			if jerr != nil {
+				return receipts, jerr
+			}
+			r.attempts++
+			if r.attempts > retries {
+				return receipts, fmt.Errorf("vote %v failed after %v "+
+					"attempts: %v", r.vote.Ticket, r.attempts, err)
+			}
+			r.due = time.Now().Add(time.Duration(
+				prng.Int63n(int64(retryDelay))))
+			queue = append(queue, r)
+			sort.SliceStable(queue, func(i, j int) bool {
+				return queue[i].due.Before(queue[j].due)
+			})
+			continue
+		}
+
+		vr := br.Receipts[0]
+		receipts = append(receipts, vr)
+		if opts.Cast != nil {
+			opts.Cast(r.vote, &vr, nil)
+		}
+		if vr.ErrorCode == tkv1.VoteErrorVoteStatusInvalid {
+			// The vote has ended
+			err = journal(JournalFailed, vr)
+			if err != nil {
+				return receipts, err
+			}
+			return receipts, fmt.Errorf("vote has ended")
+		}
+		err = journal(JournalSuccess, vr)
+		if err != nil {
+			return receipts, err
+		}
+	}
+
+	return receipts, nil
+}
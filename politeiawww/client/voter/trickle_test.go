@@ -0,0 +1,255 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package voter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	"github.com/decred/politeia/politeiawww/client"
+)
+
+// testCaster is a Caster that returns the queued errors of a ticket before
+// the vote of the ticket is cast successfully.
+type testCaster struct {
+	errs  map[string][]error // [ticket]Errors
+	reply map[string]tkv1.CastVoteReply
+	cast  []string // Tickets in the order that they were cast
+}
+
+// TicketVoteCastBallot satisfies the Caster interface.
+func (c *testCaster) TicketVoteCastBallot(b tkv1.CastBallot) (*tkv1.CastBallotReply, error) {
+	ticket := b.Votes[0].Ticket
+	if errs := c.errs[ticket]; len(errs) > 0 {
+		c.errs[ticket] = errs[1:]
+		return nil, errs[0]
+	}
+	c.cast = append(c.cast, ticket)
+	r, ok := c.reply[ticket]
+	if !ok {
+		r = tkv1.CastVoteReply{
+			Ticket:  ticket,
+			Receipt: "receipt",
+		}
+	}
+	return &tkv1.CastBallotReply{
+		Receipts: []tkv1.CastVoteReply{r},
+	}, nil
+}
+
+// testIntervals returns intervals without a delay for the provided tickets.
+func testIntervals(tickets ...string) []Interval {
+	intervals := make([]Interval, 0, len(tickets))
+	for _, v := range tickets {
+		intervals = append(intervals, Interval{
+			Vote: tkv1.CastVote{
+				Token:  testToken,
+				Ticket: v,
+			},
+		})
+	}
+	return intervals
+}
+
+func TestSchedule(t *testing.T) {
+	votes := make([]tkv1.CastVote, 0, 100)
+	for i := 0; i < cap(votes); i++ {
+		votes = append(votes, tkv1.CastVote{
+			Ticket: fmt.Sprintf("%v", i),
+		})
+	}
+	duration := time.Hour
+
+	intervals, err := Schedule(votes, duration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intervals) != len(votes) {
+		t.Fatalf("got %v intervals, want %v", len(intervals), len(votes))
+	}
+	var total time.Duration
+	for k, v := range intervals {
+		if v.Vote.Ticket != votes[k].Ticket {
+			t.Fatalf("got vote %v at %v, want %v",
+				v.Vote.Ticket, k, votes[k].Ticket)
+		}
+		if v.At < 0 {
+			t.Fatalf("got negative delay %v", v.At)
+		}
+		total += v.At
+	}
+	if total >= duration {
+		t.Fatalf("got schedule of %v, want less than %v", total, duration)
+	}
+
+	// The duration must be positive
+	_, err = Schedule(votes, 0)
+	if err == nil {
+		t.Fatalf("got nil error, want error")
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	var tests = []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			"internal server error",
+			client.RespErr{HTTPCode: http.StatusInternalServerError},
+			true,
+		},
+		{
+			"user error",
+			client.RespErr{HTTPCode: http.StatusBadRequest},
+			false,
+		},
+		{
+			"wrapped user error",
+			fmt.Errorf("cast: %w",
+				client.RespErr{HTTPCode: http.StatusBadRequest}),
+			false,
+		},
+		{
+			"network error",
+			errors.New("connection refused"),
+			true,
+		},
+		{
+			"context canceled",
+			context.Canceled,
+			false,
+		},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			got := Retryable(v.err)
+			if got != v.want {
+				t.Fatalf("got %v, want %v", got, v.want)
+			}
+		})
+	}
+}
+
+func TestTrickle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "voter.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// A vote that fails with a retryable error is retried
+	c := &testCaster{
+		errs: map[string][]error{
+			"ticket1": {errors.New("connection refused")},
+		},
+	}
+	j := NewJournal(dir, time.Now())
+	opts := &TrickleOpts{
+		Journal:    j,
+		RetryDelay: time.Millisecond,
+	}
+	receipts, err := Trickle(context.Background(), c, testToken,
+		testIntervals("ticket1", "ticket2"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(receipts) != 2 {
+		t.Fatalf("got %v receipts, want 2", len(receipts))
+	}
+	if len(c.cast) != 2 || c.cast[0] != "ticket2" || c.cast[1] != "ticket1" {
+		t.Fatalf("got cast order %v, want ticket2 then ticket1", c.cast)
+	}
+	for _, v := range []string{JournalWork, JournalFailed, JournalSuccess} {
+		f := filepath.Join(j.Dir(testToken),
+			fmt.Sprintf("%v.%v", v, j.run.Unix()))
+		if _, err := os.Stat(f); err != nil {
+			t.Fatalf("journal %v: %v", v, err)
+		}
+	}
+
+	// A vote that fails with a non retryable error aborts the run
+	c = &testCaster{
+		errs: map[string][]error{
+			"ticket1": {client.RespErr{HTTPCode: http.StatusBadRequest}},
+		},
+	}
+	_, err = Trickle(context.Background(), c, testToken,
+		testIntervals("ticket1", "ticket2"), nil)
+	var re client.RespErr
+	if !errors.As(err, &re) {
+		t.Fatalf("got error %v, want a client.RespErr", err)
+	}
+	if len(c.cast) != 0 {
+		t.Fatalf("got cast %v, want none", c.cast)
+	}
+
+	// A vote that keeps failing is retried a limited number of times
+	errs := make([]error, 3)
+	for k := range errs {
+		errs[k] = errors.New("connection refused")
+	}
+	c = &testCaster{
+		errs: map[string][]error{
+			"ticket1": errs,
+		},
+	}
+	_, err = Trickle(context.Background(), c, testToken,
+		testIntervals("ticket1"), &TrickleOpts{
+			Retries:    2,
+			RetryDelay: time.Millisecond,
+		})
+	if err == nil {
+		t.Fatalf("got nil error, want error")
+	}
+
+	// Trickling stops once the vote has ended
+	c = &testCaster{
+		reply: map[string]tkv1.CastVoteReply{
+			"ticket1": {
+				Ticket:       "ticket1",
+				ErrorCode:    tkv1.VoteErrorVoteStatusInvalid,
+				ErrorContext: "vote has ended",
+			},
+		},
+	}
+	receipts, err = Trickle(context.Background(), c, testToken,
+		testIntervals("ticket1", "ticket2"), nil)
+	if err == nil {
+		t.Fatalf("got nil error, want error")
+	}
+	if len(receipts) != 1 || len(c.cast) != 1 {
+		t.Fatalf("got receipts %v and cast %v, want ticket1 only",
+			receipts, c.cast)
+	}
+
+	// Trickling stops when the context is canceled while waiting for
+	// the next vote.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c = &testCaster{}
+	intervals := testIntervals("ticket1", "ticket2")
+	intervals[1].At = time.Hour
+	receipts, err = Trickle(ctx, c, testToken, intervals, &TrickleOpts{
+		Cast: func(v tkv1.CastVote, r *tkv1.CastVoteReply, err error) {
+			cancel()
+		},
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+	if len(receipts) != 1 {
+		t.Fatalf("got %v receipts, want 1", len(receipts))
+	}
+}
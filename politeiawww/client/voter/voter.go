@@ -0,0 +1,230 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package voter contains the ticket vote casting logic that is used by
+// politeiavoter. It allows wallets and other applications to sign, trickle,
+// journal, and verify ticket votes without shelling out to politeiavoter.
+//
+// The wallet is abstracted behind the Wallet interface and the politeiawww
+// server behind the Caster interface, which is satisfied by a client.Client.
+// A typical vote consists of the following steps:
+//
+//  1. Eligible returns the wallet tickets that have not voted yet.
+//  2. Sign signs a vote for each eligible ticket.
+//  3. Schedule spreads the votes out over the remaining vote duration.
+//  4. Trickle casts the votes according to the schedule.
+//  5. VerifyReceipts verifies the server receipts of the cast votes.
+package voter
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	"github.com/decred/politeia/politeiawww/client/internal/util"
+)
+
+// Ticket is a wallet ticket along with the commitment address that is used to
+// sign the ticket vote.
+type Ticket struct {
+	Ticket  string // Ticket hash
+	Address string // Commitment address
+}
+
+// Message is a message that must be signed by the private key of the
+// provided address.
+type Message struct {
+	Address string
+	Message string
+}
+
+// Wallet is the interface that a wallet must satisfy in order to vote.
+type Wallet interface {
+	// CommittedTickets returns the tickets from the provided list of
+	// ticket hashes that the wallet is able to sign votes for, along
+	// with their commitment addresses. Tickets that the wallet can't
+	// sign for, such as the tickets tracked by imported xpub accounts,
+	// must not be returned.
+	CommittedTickets(ctx context.Context, tickets []string) ([]Ticket, error)
+
+	// SignMessages signs the provided messages. The signatures must be
+	// returned in the same order as the messages.
+	SignMessages(ctx context.Context, msgs []Message) ([][]byte, error)
+}
+
+// VoteBit returns the vote bit that the ticket should vote. The voteBits map
+// contains per ticket overrides of the default vote bit and may be nil.
+func VoteBit(ticket, voteBit string, voteBits map[string]string) string {
+	if bit, ok := voteBits[ticket]; ok {
+		return bit
+	}
+	return voteBit
+}
+
+// VoteMessage returns the message that is signed by the commitment address of
+// a ticket to cast a vote. The replay protection is only included in the
+// message when it contains a nonce.
+func VoteMessage(token, ticket, voteBit string, rp tkv1.ReplayProtection) string {
+	msg := token + ticket + voteBit
+	if rp.Nonce != "" {
+		msg += rp.Nonce + strconv.FormatInt(rp.Expiry, 10)
+	}
+	return msg
+}
+
+// NewReplayProtection returns the replay protection for a new vote. The
+// expiry is set to half of the max expiry so that a trickled vote remains
+// valid for the duration of the vote.
+func NewReplayProtection() (*tkv1.ReplayProtection, error) {
+	nonce, err := util.Random(tkv1.NonceSize)
+	if err != nil {
+		return nil, err
+	}
+	return &tkv1.ReplayProtection{
+		Nonce:  hex.EncodeToString(nonce),
+		Expiry: time.Now().Unix() + tkv1.NonceExpiryMax/2,
+	}, nil
+}
+
+// VerifyMessage verifies the signature of a message that was signed by a
+// wallet. The signature is the raw compact signature that is returned by the
+// wallet.
+func VerifyMessage(params *chaincfg.Params, address, message string, signature []byte) (bool, error) {
+	sig := base64.StdEncoding.EncodeToString(signature)
+	return util.VerifyMessage(address, message, sig, params)
+}
+
+// Eligible returns the wallet tickets that are eligible to vote and that have
+// not voted yet. The eligible tickets are taken from the vote details and the
+// cast votes are taken from the vote results.
+func Eligible(ctx context.Context, w Wallet, dr tkv1.DetailsReply, rr tkv1.ResultsReply, serverPubKey string) ([]Ticket, error) {
+	if dr.Vote == nil {
+		return nil, fmt.Errorf("vote has not been started")
+	}
+	tickets, err := w.CommittedTickets(ctx, dr.Vote.EligibleTickets)
+	if err != nil {
+		return nil, fmt.Errorf("committed tickets: %v", err)
+	}
+	return Unvoted(tickets, rr, serverPubKey), nil
+}
+
+// Unvoted returns the tickets that have not voted yet. The cast votes are
+// taken from the vote results. A ticket that has voted but whose vote fails
+// verification is returned so that the vote can be resubmitted. This can be
+// caused by bad data on the server or by the server lying to the client.
+func Unvoted(tickets []Ticket, rr tkv1.ResultsReply, serverPubKey string) []Ticket {
+	castVotes := make(map[string]struct{}, len(rr.Votes))
+	for _, v := range rr.Votes {
+		if util.VerifySignature(v.Receipt, serverPubKey, v.Signature) != nil {
+			continue
+		}
+		castVotes[v.Ticket] = struct{}{}
+	}
+	unvoted := make([]Ticket, 0, len(tickets))
+	for _, v := range tickets {
+		if _, ok := castVotes[v.Ticket]; ok {
+			continue
+		}
+		unvoted = append(unvoted, v)
+	}
+	return unvoted
+}
+
+// Ballot contains the signed votes of a ballot. The tickets whose signature
+// failed local verification are not included in the votes.
+type Ballot struct {
+	Votes   []tkv1.CastVote
+	Invalid []string // Tickets with an invalid signature
+}
+
+// Sign signs a vote for each of the provided tickets. The voteBit is the hex
+// encoded vote bit of the vote option and the voteBits map contains per ticket
+// overrides of the vote bit. Each signature is verified locally so that a bad
+// signature is caught before the vote is cast. An error is returned if none of
+// the signatures are valid.
+func Sign(ctx context.Context, w Wallet, params *chaincfg.Params, token, voteBit string, voteBits map[string]string, tickets []Ticket) (*Ballot, error) {
+	if len(tickets) == 0 {
+		return nil, fmt.Errorf("no tickets to sign")
+	}
+
+	var (
+		msgs = make([]Message, 0, len(tickets))
+		rps  = make([]tkv1.ReplayProtection, 0, len(tickets))
+	)
+	for _, v := range tickets {
+		rp, err := NewReplayProtection()
+		if err != nil {
+			return nil, err
+		}
+		rps = append(rps, *rp)
+		msgs = append(msgs, Message{
+			Address: v.Address,
+			Message: VoteMessage(token, v.Ticket,
+				VoteBit(v.Ticket, voteBit, voteBits), *rp),
+		})
+	}
+	sigs, err := w.SignMessages(ctx, msgs)
+	if err != nil {
+		return nil, fmt.Errorf("sign messages: %v", err)
+	}
+	if len(sigs) != len(msgs) {
+		return nil, fmt.Errorf("unexpected signature count: got %v, "+
+			"want %v", len(sigs), len(msgs))
+	}
+
+	b := Ballot{
+		Votes: make([]tkv1.CastVote, 0, len(tickets)),
+	}
+	for k, v := range tickets {
+		ok, err := VerifyMessage(params, v.Address, msgs[k].Message, sigs[k])
+		if err != nil || !ok {
+			b.Invalid = append(b.Invalid, v.Ticket)
+			continue
+		}
+		b.Votes = append(b.Votes, tkv1.CastVote{
+			Token:            token,
+			Ticket:           v.Ticket,
+			VoteBit:          VoteBit(v.Ticket, voteBit, voteBits),
+			Signature:        hex.EncodeToString(sigs[k]),
+			ReplayProtection: rps[k],
+		})
+	}
+	if len(b.Votes) == 0 {
+		return nil, fmt.Errorf("no valid signatures")
+	}
+
+	return &b, nil
+}
+
+// VerifyReceipts verifies the server receipts of the provided cast votes. The
+// receipt is the server signature of the vote signature. A map of the tickets
+// whose receipt failed verification to the verification error is returned.
+// Receipts that contain a vote error are not verified.
+func VerifyReceipts(votes []tkv1.CastVote, receipts []tkv1.CastVoteReply, serverPubKey string) map[string]error {
+	sigs := make(map[string]string, len(votes))
+	for _, v := range votes {
+		sigs[v.Ticket] = v.Signature
+	}
+	failed := make(map[string]error)
+	for _, v := range receipts {
+		if v.ErrorContext != "" {
+			continue
+		}
+		sig, ok := sigs[v.Ticket]
+		if !ok {
+			failed[v.Ticket] = fmt.Errorf("receipt for unknown ticket")
+			continue
+		}
+		err := util.VerifySignature(v.Receipt, serverPubKey, sig)
+		if err != nil {
+			failed[v.Ticket] = err
+		}
+	}
+	return failed
+}
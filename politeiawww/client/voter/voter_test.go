@@ -0,0 +1,339 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package voter
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"strconv"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/dcrec"
+	"github.com/decred/dcrd/dcrec/secp256k1/v3"
+	"github.com/decred/dcrd/dcrec/secp256k1/v3/ecdsa"
+	"github.com/decred/dcrd/dcrutil/v3"
+	"github.com/decred/dcrd/wire"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	"golang.org/x/crypto/ed25519"
+)
+
+const (
+	testToken   = "e8bca53eb9ca4bb4"
+	testVoteBit = "1"
+)
+
+// testWallet is a Wallet that signs messages using in memory keys.
+type testWallet struct {
+	t       *testing.T
+	params  *chaincfg.Params
+	tickets []Ticket
+	keys    map[string]*secp256k1.PrivateKey // [address]Key
+
+	// corrupt contains the addresses whose signatures are corrupted.
+	corrupt map[string]struct{}
+}
+
+func newTestWallet(t *testing.T) *testWallet {
+	return &testWallet{
+		t:       t,
+		params:  chaincfg.TestNet3Params(),
+		keys:    make(map[string]*secp256k1.PrivateKey),
+		corrupt: make(map[string]struct{}),
+	}
+}
+
+// addTicket adds a ticket with a new commitment address to the wallet.
+func (w *testWallet) addTicket(ticket string) Ticket {
+	w.t.Helper()
+
+	key, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		w.t.Fatal(err)
+	}
+	pkh := dcrutil.Hash160(key.PubKey().SerializeCompressed())
+	addr, err := dcrutil.NewAddressPubKeyHash(pkh, w.params,
+		dcrec.STEcdsaSecp256k1)
+	if err != nil {
+		w.t.Fatal(err)
+	}
+	t := Ticket{
+		Ticket:  ticket,
+		Address: addr.Address(),
+	}
+	w.tickets = append(w.tickets, t)
+	w.keys[t.Address] = key
+	return t
+}
+
+// CommittedTickets satisfies the Wallet interface.
+func (w *testWallet) CommittedTickets(ctx context.Context, tickets []string) ([]Ticket, error) {
+	requested := make(map[string]struct{}, len(tickets))
+	for _, v := range tickets {
+		requested[v] = struct{}{}
+	}
+	committed := make([]Ticket, 0, len(w.tickets))
+	for _, v := range w.tickets {
+		if _, ok := requested[v.Ticket]; ok {
+			committed = append(committed, v)
+		}
+	}
+	return committed, nil
+}
+
+// SignMessages satisfies the Wallet interface.
+func (w *testWallet) SignMessages(ctx context.Context, msgs []Message) ([][]byte, error) {
+	sigs := make([][]byte, 0, len(msgs))
+	for _, v := range msgs {
+		var buf bytes.Buffer
+		err := wire.WriteVarString(&buf, 0, "Decred Signed Message:\n")
+		if err != nil {
+			return nil, err
+		}
+		err = wire.WriteVarString(&buf, 0, v.Message)
+		if err != nil {
+			return nil, err
+		}
+		sig := ecdsa.SignCompact(w.keys[v.Address],
+			chainhash.HashB(buf.Bytes()), true)
+		if _, ok := w.corrupt[v.Address]; ok {
+			sig[len(sig)-1] ^= 0xff
+		}
+		sigs = append(sigs, sig)
+	}
+	return sigs, nil
+}
+
+// testServer is the politeiawww identity that signs the vote receipts.
+type testServer struct {
+	publicKey  string // Hex encoded
+	privateKey ed25519.PrivateKey
+}
+
+// newTestServer returns a new testServer with a random identity.
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	pk, sk, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testServer{
+		publicKey:  hex.EncodeToString(pk),
+		privateKey: sk,
+	}
+}
+
+// testReceipt returns the server receipt of a vote signature.
+func testReceipt(server *testServer, signature string) string {
+	r := ed25519.Sign(server.privateKey, []byte(signature))
+	return hex.EncodeToString(r)
+}
+
+func TestVoteBit(t *testing.T) {
+	voteBits := map[string]string{
+		"ticket1": "2",
+	}
+	var tests = []struct {
+		name     string
+		ticket   string
+		voteBits map[string]string
+		want     string
+	}{
+		{"no overrides", "ticket1", nil, testVoteBit},
+		{"override", "ticket1", voteBits, "2"},
+		{"not overridden", "ticket2", voteBits, testVoteBit},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			got := VoteBit(v.ticket, testVoteBit, v.voteBits)
+			if got != v.want {
+				t.Fatalf("got %v, want %v", got, v.want)
+			}
+		})
+	}
+}
+
+func TestVoteMessage(t *testing.T) {
+	// The replay protection is not part of the message when there is
+	// no nonce.
+	got := VoteMessage(testToken, "ticket", testVoteBit,
+		tkv1.ReplayProtection{})
+	want := testToken + "ticket" + testVoteBit
+	if got != want {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	rp, err := NewReplayProtection()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rp.Nonce) != hex.EncodedLen(tkv1.NonceSize) {
+		t.Fatalf("got nonce %v, want %v bytes", rp.Nonce, tkv1.NonceSize)
+	}
+	got = VoteMessage(testToken, "ticket", testVoteBit, *rp)
+	want += rp.Nonce + strconv.FormatInt(rp.Expiry, 10)
+	if got != want {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestEligible(t *testing.T) {
+	server := newTestServer(t)
+	w := newTestWallet(t)
+	var (
+		voted   = w.addTicket("voted")   // Voted with a valid receipt
+		badVote = w.addTicket("badvote") // Voted with an invalid receipt
+		unvoted = w.addTicket("unvoted") // Not voted
+	)
+	dr := tkv1.DetailsReply{
+		Vote: &tkv1.VoteDetails{
+			EligibleTickets: []string{
+				voted.Ticket, badVote.Ticket, unvoted.Ticket,
+				"notinwallet",
+			},
+		},
+	}
+	rr := tkv1.ResultsReply{
+		Votes: []tkv1.CastVoteDetails{
+			{
+				Ticket:    voted.Ticket,
+				Signature: "sig1",
+				Receipt:   testReceipt(server, "sig1"),
+			},
+			{
+				Ticket:    badVote.Ticket,
+				Signature: "sig2",
+				Receipt:   testReceipt(server, "sig1"),
+			},
+		},
+	}
+
+	eligible, err := Eligible(context.Background(), w, dr, rr,
+		server.publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(eligible) != 2 || eligible[0] != badVote ||
+		eligible[1] != unvoted {
+		t.Fatalf("got eligible %v, want %v and %v",
+			eligible, badVote, unvoted)
+	}
+
+	// The vote must have been started
+	_, err = Eligible(context.Background(), w, tkv1.DetailsReply{}, rr,
+		server.publicKey)
+	if err == nil {
+		t.Fatalf("got nil error, want error")
+	}
+}
+
+func TestSign(t *testing.T) {
+	w := newTestWallet(t)
+	var (
+		valid      = w.addTicket("valid")
+		overridden = w.addTicket("overridden")
+		corrupt    = w.addTicket("corrupt")
+	)
+	w.corrupt[corrupt.Address] = struct{}{}
+	voteBits := map[string]string{
+		overridden.Ticket: "2",
+	}
+
+	b, err := Sign(context.Background(), w, w.params, testToken,
+		testVoteBit, voteBits, w.tickets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Invalid) != 1 || b.Invalid[0] != corrupt.Ticket {
+		t.Fatalf("got invalid %v, want %v", b.Invalid, corrupt.Ticket)
+	}
+	if len(b.Votes) != 2 {
+		t.Fatalf("got %v votes, want 2", len(b.Votes))
+	}
+	for k, v := range []Ticket{valid, overridden} {
+		cv := b.Votes[k]
+		if cv.Token != testToken || cv.Ticket != v.Ticket {
+			t.Fatalf("got vote %+v, want ticket %v", cv, v.Ticket)
+		}
+		wantBit := VoteBit(v.Ticket, testVoteBit, voteBits)
+		if cv.VoteBit != wantBit {
+			t.Fatalf("got vote bit %v, want %v", cv.VoteBit, wantBit)
+		}
+		if cv.ReplayProtection.Nonce == "" {
+			t.Fatalf("vote of %v has no replay protection", v.Ticket)
+		}
+
+		// The signature must cover the vote and its replay protection
+		sig, err := hex.DecodeString(cv.Signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := VoteMessage(testToken, v.Ticket, wantBit,
+			cv.ReplayProtection)
+		ok, err := VerifyMessage(w.params, v.Address, msg, sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Fatalf("signature of %v did not verify", v.Ticket)
+		}
+	}
+
+	// An error is returned when there are no valid signatures
+	_, err = Sign(context.Background(), w, w.params, testToken,
+		testVoteBit, nil, []Ticket{corrupt})
+	if err == nil {
+		t.Fatalf("got nil error, want error")
+	}
+
+	// An error is returned when there are no tickets
+	_, err = Sign(context.Background(), w, w.params, testToken,
+		testVoteBit, nil, nil)
+	if err == nil {
+		t.Fatalf("got nil error, want error")
+	}
+}
+
+func TestVerifyReceipts(t *testing.T) {
+	server := newTestServer(t)
+	votes := []tkv1.CastVote{
+		{Ticket: "valid", Signature: "sig1"},
+		{Ticket: "invalid", Signature: "sig2"},
+		{Ticket: "failed", Signature: "sig3"},
+	}
+	receipts := []tkv1.CastVoteReply{
+		{
+			Ticket:  "valid",
+			Receipt: testReceipt(server, "sig1"),
+		},
+		{
+			Ticket:  "invalid",
+			Receipt: testReceipt(server, "sig1"),
+		},
+		{
+			// Receipts of votes that failed are not verified
+			Ticket:       "failed",
+			ErrorCode:    tkv1.VoteErrorTicketAlreadyVoted,
+			ErrorContext: "already voted",
+		},
+		{
+			Ticket:  "unknown",
+			Receipt: testReceipt(server, "sig4"),
+		},
+	}
+
+	failed := VerifyReceipts(votes, receipts, server.publicKey)
+	if len(failed) != 2 {
+		t.Fatalf("got failed %v, want invalid and unknown", failed)
+	}
+	for _, v := range []string{"invalid", "unknown"} {
+		if _, ok := failed[v]; !ok {
+			t.Fatalf("receipt of %v did not fail", v)
+		}
+	}
+}
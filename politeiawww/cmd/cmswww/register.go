@@ -34,9 +34,10 @@ func (cmd *RegisterUserCmd) Execute(args []string) error {
 	}
 
 	// Validate password
-	if uint(len(cmd.Args.Password)) < pr.MinPasswordLength {
-		return fmt.Errorf("password must be %v characters long",
-			pr.MinPasswordLength)
+	err = shared.ValidatePassword(pr, cmd.Args.Password,
+		cmd.Args.Username, cmd.Args.Email)
+	if err != nil {
+		return err
 	}
 
 	// Create user identity and save it to disk
@@ -15,6 +15,7 @@ import (
 	"github.com/decred/politeia/politeiad/api/v1/identity"
 	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
 	pclient "github.com/decred/politeia/politeiawww/client"
+	"github.com/decred/politeia/politeiawww/client/voter"
 	"github.com/decred/politeia/util"
 )
 
@@ -39,9 +40,11 @@ func (c *cmdCastBallot) Execute(args []string) error {
 
 	// Setup politeiawww client
 	opts := pclient.Opts{
-		HTTPSCert: cfg.HTTPSCert,
-		Verbose:   cfg.Verbose,
-		RawJSON:   cfg.RawJSON,
+		HTTPSCert:      cfg.HTTPSCert,
+		Proxy:          cfg.Proxy,
+		ProxyIsolation: cfg.ProxyIsolation,
+		Verbose:        cfg.Verbose,
+		RawJSON:        cfg.RawJSON,
 	}
 	pc, err := pclient.New(cfg.Host, opts)
 	if err != nil {
@@ -129,9 +132,15 @@ func (c *cmdCastBallot) Execute(args []string) error {
 	// Sign eligible tickets with vote preference
 	messages := make([]*walletrpc.SignMessagesRequest_Message, 0,
 		len(eligibleTickets))
+	rps := make([]tkv1.ReplayProtection, 0, len(eligibleTickets))
 	for i, v := range ctr.TicketAddresses {
 		// ctr.TicketAddresses and eligibleTickets share the same ordering
-		msg := token + eligibleTickets[i] + voteBit
+		rp, err := voter.NewReplayProtection()
+		if err != nil {
+			return err
+		}
+		rps = append(rps, *rp)
+		msg := voter.VoteMessage(token, eligibleTickets[i], voteBit, *rp)
 		messages = append(messages, &walletrpc.SignMessagesRequest_Message{
 			Address: v.Address,
 			Message: msg,
@@ -155,12 +164,13 @@ func (c *cmdCastBallot) Execute(args []string) error {
 	// Setup ballot request
 	votes := make([]tkv1.CastVote, 0, len(eligibleTickets))
 	for i, ticket := range eligibleTickets {
-		// eligibleTickets and sigs use the same index
+		// eligibleTickets, sigs and rps use the same index
 		votes = append(votes, tkv1.CastVote{
-			Token:     token,
-			Ticket:    ticket,
-			VoteBit:   voteBit,
-			Signature: hex.EncodeToString(sigs.Replies[i].Signature),
+			Token:            token,
+			Ticket:           ticket,
+			VoteBit:          voteBit,
+			Signature:        hex.EncodeToString(sigs.Replies[i].Signature),
+			ReplayProtection: rps[i],
 		})
 	}
 	cb := tkv1.CastBallot{
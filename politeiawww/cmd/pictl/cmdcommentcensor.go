@@ -54,11 +54,13 @@ func (c *cmdCommentCensor) Execute(args []string) error {
 
 	// Setup client
 	opts := pclient.Opts{
-		HTTPSCert:  cfg.HTTPSCert,
-		Cookies:    cfg.Cookies,
-		HeaderCSRF: cfg.CSRF,
-		Verbose:    cfg.Verbose,
-		RawJSON:    cfg.RawJSON,
+		HTTPSCert:      cfg.HTTPSCert,
+		Proxy:          cfg.Proxy,
+		ProxyIsolation: cfg.ProxyIsolation,
+		Cookies:        cfg.Cookies,
+		HeaderCSRF:     cfg.CSRF,
+		Verbose:        cfg.Verbose,
+		RawJSON:        cfg.RawJSON,
 	}
 	pc, err := pclient.New(cfg.Host, opts)
 	if err != nil {
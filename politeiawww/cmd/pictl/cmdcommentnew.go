@@ -45,11 +45,13 @@ func (c *cmdCommentNew) Execute(args []string) error {
 
 	// Setup client
 	opts := pclient.Opts{
-		HTTPSCert:  cfg.HTTPSCert,
-		Cookies:    cfg.Cookies,
-		HeaderCSRF: cfg.CSRF,
-		Verbose:    cfg.Verbose,
-		RawJSON:    cfg.RawJSON,
+		HTTPSCert:      cfg.HTTPSCert,
+		Proxy:          cfg.Proxy,
+		ProxyIsolation: cfg.ProxyIsolation,
+		Cookies:        cfg.Cookies,
+		HeaderCSRF:     cfg.CSRF,
+		Verbose:        cfg.Verbose,
+		RawJSON:        cfg.RawJSON,
 	}
 	pc, err := pclient.New(cfg.Host, opts)
 	if err != nil {
@@ -66,8 +68,13 @@ func (c *cmdCommentNew) Execute(args []string) error {
 	}
 
 	// Setup request
+	nonce, expiry, err := commentNonce()
+	if err != nil {
+		return err
+	}
 	msg := strconv.FormatUint(uint64(state), 10) + token +
-		strconv.FormatUint(uint64(parentID), 10) + comment
+		strconv.FormatUint(uint64(parentID), 10) + comment +
+		commentNonceMsg(nonce, expiry)
 	sig := cfg.Identity.SignMessage([]byte(msg))
 	n := cmv1.New{
 		State:     state,
@@ -76,6 +83,10 @@ func (c *cmdCommentNew) Execute(args []string) error {
 		Comment:   comment,
 		Signature: hex.EncodeToString(sig[:]),
 		PublicKey: cfg.Identity.Public.String(),
+		ReplayProtection: cmv1.ReplayProtection{
+			Nonce:  nonce,
+			Expiry: expiry,
+		},
 	}
 
 	// Send request
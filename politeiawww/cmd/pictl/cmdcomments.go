@@ -16,6 +16,9 @@ type cmdComments struct {
 	Args struct {
 		Token string `positional-arg-name:"token"` // Censorship token
 	} `positional-args:"true" required:"true"`
+
+	// Flatten returns the comments in thread order.
+	Flatten bool `long:"flatten" optional:"true"`
 }
 
 // Execute executes the cmdComments command.
@@ -24,11 +27,13 @@ type cmdComments struct {
 func (c *cmdComments) Execute(args []string) error {
 	// Setup client
 	opts := pclient.Opts{
-		HTTPSCert:  cfg.HTTPSCert,
-		Cookies:    cfg.Cookies,
-		HeaderCSRF: cfg.CSRF,
-		Verbose:    cfg.Verbose,
-		RawJSON:    cfg.RawJSON,
+		HTTPSCert:      cfg.HTTPSCert,
+		Proxy:          cfg.Proxy,
+		ProxyIsolation: cfg.ProxyIsolation,
+		Cookies:        cfg.Cookies,
+		HeaderCSRF:     cfg.CSRF,
+		Verbose:        cfg.Verbose,
+		RawJSON:        cfg.RawJSON,
 	}
 	pc, err := pclient.New(cfg.Host, opts)
 	if err != nil {
@@ -37,7 +42,8 @@ func (c *cmdComments) Execute(args []string) error {
 
 	// Get comments
 	cm := cmv1.Comments{
-		Token: c.Args.Token,
+		Token:   c.Args.Token,
+		Flatten: c.Flatten,
 	}
 	cr, err := pc.Comments(cm)
 	if err != nil {
@@ -47,6 +53,10 @@ func (c *cmdComments) Execute(args []string) error {
 	// Print comments
 	for _, v := range cr.Comments {
 		printComment(v)
+		if c.Flatten {
+			printf("  Thread ID: %v\n", v.ThreadID)
+			printf("  Depth    : %v\n", v.Depth)
+		}
 		fmt.Printf("\n")
 	}
 
@@ -64,4 +74,8 @@ record author.
 
 Arguments:
 1. token  (string, required)  Proposal censorship token
+
+Flags:
+  --flatten    (bool, optional)  Return the comments in thread order along
+                                 with the thread ID and depth of each comment.
 `
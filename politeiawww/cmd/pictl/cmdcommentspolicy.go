@@ -17,9 +17,11 @@ type cmdCommentPolicy struct{}
 func (c *cmdCommentPolicy) Execute(args []string) error {
 	// Setup client
 	opts := pclient.Opts{
-		HTTPSCert: cfg.HTTPSCert,
-		Verbose:   cfg.Verbose,
-		RawJSON:   cfg.RawJSON,
+		HTTPSCert:      cfg.HTTPSCert,
+		Proxy:          cfg.Proxy,
+		ProxyIsolation: cfg.ProxyIsolation,
+		Verbose:        cfg.Verbose,
+		RawJSON:        cfg.RawJSON,
 	}
 	pc, err := pclient.New(cfg.Host, opts)
 	if err != nil {
@@ -37,11 +37,13 @@ func (c *cmdCommentVote) Execute(args []string) error {
 
 	// Setup client
 	opts := pclient.Opts{
-		HTTPSCert:  cfg.HTTPSCert,
-		Cookies:    cfg.Cookies,
-		HeaderCSRF: cfg.CSRF,
-		Verbose:    cfg.Verbose,
-		RawJSON:    cfg.RawJSON,
+		HTTPSCert:      cfg.HTTPSCert,
+		Proxy:          cfg.Proxy,
+		ProxyIsolation: cfg.ProxyIsolation,
+		Cookies:        cfg.Cookies,
+		HeaderCSRF:     cfg.CSRF,
+		Verbose:        cfg.Verbose,
+		RawJSON:        cfg.RawJSON,
 	}
 	pc, err := pclient.New(cfg.Host, opts)
 	if err != nil {
@@ -63,9 +65,14 @@ func (c *cmdCommentVote) Execute(args []string) error {
 
 	// Setup request
 	state := cmv1.RecordStateVetted
+	nonce, expiry, err := commentNonce()
+	if err != nil {
+		return err
+	}
 	msg := strconv.FormatUint(uint64(state), 10) + c.Args.Token +
 		strconv.FormatUint(uint64(c.Args.CommentID), 10) +
-		strconv.FormatInt(int64(vote), 10)
+		strconv.FormatInt(int64(vote), 10) +
+		commentNonceMsg(nonce, expiry)
 	sig := cfg.Identity.SignMessage([]byte(msg))
 	v := cmv1.Vote{
 		State:     state,
@@ -74,6 +81,10 @@ func (c *cmdCommentVote) Execute(args []string) error {
 		Vote:      vote,
 		Signature: hex.EncodeToString(sig[:]),
 		PublicKey: cfg.Identity.Public.String(),
+		ReplayProtection: cmv1.ReplayProtection{
+			Nonce:  nonce,
+			Expiry: expiry,
+		},
 	}
 
 	// Send request
@@ -89,6 +89,14 @@ func (c *cmdHelp) Execute(args []string) error {
 		fmt.Printf("%s\n", proposalInvOrderedHelpMsg)
 	case "userproposals":
 		fmt.Printf("%s\n", userProposalsHelpMsg)
+	case "rfp":
+		fmt.Printf("%s\n", rfpHelpMsg)
+	case "rfpparent":
+		fmt.Printf("%s\n", rfpParentHelpMsg)
+	case "rfprepair":
+		fmt.Printf("%s\n", rfpRepairHelpMsg)
+	case "lineage":
+		fmt.Printf("%s\n", lineageHelpMsg)
 
 		// Comment commands
 	case "commentpolicy":
@@ -129,6 +137,8 @@ func (c *cmdHelp) Execute(args []string) error {
 		fmt.Printf("%s\n", voteInvHelpMsg)
 	case "votetimestamps":
 		fmt.Printf("%s\n", voteTimestampsHelpMsg)
+	case "votecertificate":
+		fmt.Printf("%s\n", voteCertificateHelpMsg)
 
 	// Websocket commands
 	case "subscribe":
@@ -0,0 +1,58 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	pclient "github.com/decred/politeia/politeiawww/client"
+)
+
+// cmdLineage retrieves the resubmission lineage of a proposal.
+type cmdLineage struct {
+	Args struct {
+		Token string `positional-arg-name:"token"`
+	} `positional-args:"true" required:"true"`
+}
+
+// Execute executes the cmdLineage command.
+//
+// This function satisfies the go-flags Commander interface.
+func (c *cmdLineage) Execute(args []string) error {
+	// Setup client
+	opts := pclient.Opts{
+		HTTPSCert:      cfg.HTTPSCert,
+		Proxy:          cfg.Proxy,
+		ProxyIsolation: cfg.ProxyIsolation,
+		Verbose:        cfg.Verbose,
+		RawJSON:        cfg.RawJSON,
+	}
+	pc, err := pclient.New(cfg.Host, opts)
+	if err != nil {
+		return err
+	}
+
+	// Get the lineage
+	lr, err := pc.PiLineage(piv1.Lineage{
+		Token: c.Args.Token,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Print the lineage
+	printJSON(lr)
+
+	return nil
+}
+
+// lineageHelpMsg is printed to stdout by the help command.
+const lineageHelpMsg = `lineage "token"
+
+Get the resubmission lineage of a proposal. The proposal is returned followed
+by the rejected proposals that it is a resubmission of, ordered from newest to
+oldest, along with their vote results.
+
+Arguments:
+1. token  (string, required)  Proposal token.`
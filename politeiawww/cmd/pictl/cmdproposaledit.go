@@ -37,6 +37,10 @@ type cmdProposalEdit struct {
 	LinkTo string `long:"linkto" optional:"true"`
 	LinkBy string `long:"linkby" optional:"true"`
 
+	// Resubmits is the token of a rejected proposal that this proposal
+	// is a resubmission of.
+	Resubmits string `long:"resubmits" optional:"true"`
+
 	// RFP is a flag that is intended to make submitting an RFP easier
 	// by calculating and inserting a linkby timestamp automatically
 	// instead of having to pass in a timestamp using the --linkby
@@ -94,11 +98,13 @@ func proposalEdit(c *cmdProposalEdit) (*rcv1.Record, error) {
 
 	// Setup client
 	opts := pclient.Opts{
-		HTTPSCert:  cfg.HTTPSCert,
-		Cookies:    cfg.Cookies,
-		HeaderCSRF: cfg.CSRF,
-		Verbose:    cfg.Verbose,
-		RawJSON:    cfg.RawJSON,
+		HTTPSCert:      cfg.HTTPSCert,
+		Proxy:          cfg.Proxy,
+		ProxyIsolation: cfg.ProxyIsolation,
+		Cookies:        cfg.Cookies,
+		HeaderCSRF:     cfg.CSRF,
+		Verbose:        cfg.Verbose,
+		RawJSON:        cfg.RawJSON,
 	}
 	pc, err := pclient.New(cfg.Host, opts)
 	if err != nil {
@@ -157,6 +163,7 @@ func proposalEdit(c *cmdProposalEdit) (*rcv1.Record, error) {
 			return nil, err
 		}
 		c.Name = pm.Name
+		c.Resubmits = pm.Resubmits
 	case c.Random && c.Name == "":
 		// Create a random proposal name
 		r, err := util.Random(int(pr.NameLengthMin))
@@ -166,7 +173,8 @@ func proposalEdit(c *cmdProposalEdit) (*rcv1.Record, error) {
 		c.Name = hex.EncodeToString(r)
 	}
 	pm := piv1.ProposalMetadata{
-		Name: c.Name,
+		Name:      c.Name,
+		Resubmits: c.Resubmits,
 	}
 	pmb, err := json.Marshal(pm)
 	if err != nil {
@@ -266,6 +274,10 @@ flag. Only one of these flags can be used at a time.
 A proposal can be submitted as an RFP submission by using the --linkto flag
 to link to and an existing RFP proposal.
 
+A proposal that was rejected by the ticket vote can be resubmitted by using
+the --resubmits flag to reference the rejected proposal. The rejected proposal
+must have been submitted by the same user.
+
 Arguments:
 1. token       (string, required) Proposal censorship token.
 2. indexfile   (string, optional) Index file.
@@ -278,6 +290,9 @@ Flags:
 
  --linkto       (string) Token of an existing public proposal to link to.
 
+ --resubmits    (string) Token of a rejected proposal that this proposal is
+                         a resubmission of.
+
  --linkby       (string) Make the proposal and RFP by setting the linkby
                          deadline. Other proposals must be entered as RFP
                          submissions by this linkby deadline. The provided
@@ -31,6 +31,10 @@ type cmdProposalNew struct {
 	LinkTo string `long:"linkto" optional:"true"`
 	LinkBy string `long:"linkby" optional:"true"`
 
+	// Resubmits is the token of a rejected proposal that this proposal
+	// is a resubmission of.
+	Resubmits string `long:"resubmits" optional:"true"`
+
 	// RFP is a flag that is intended to make submitting an RFP easier
 	// by calculating and inserting a linkby timestamp automatically
 	// instead of having to pass in a timestamp using the --linkby
@@ -87,11 +91,13 @@ func proposalNew(c *cmdProposalNew) (*rcv1.Record, error) {
 
 	// Setup client
 	opts := pclient.Opts{
-		HTTPSCert:  cfg.HTTPSCert,
-		Cookies:    cfg.Cookies,
-		HeaderCSRF: cfg.CSRF,
-		Verbose:    cfg.Verbose,
-		RawJSON:    cfg.RawJSON,
+		HTTPSCert:      cfg.HTTPSCert,
+		Proxy:          cfg.Proxy,
+		ProxyIsolation: cfg.ProxyIsolation,
+		Cookies:        cfg.Cookies,
+		HeaderCSRF:     cfg.CSRF,
+		Verbose:        cfg.Verbose,
+		RawJSON:        cfg.RawJSON,
 	}
 	pc, err := pclient.New(cfg.Host, opts)
 	if err != nil {
@@ -138,7 +144,8 @@ func proposalNew(c *cmdProposalNew) (*rcv1.Record, error) {
 		c.Name = fmt.Sprintf("A Proposal Name %x", r)
 	}
 	pm := piv1.ProposalMetadata{
-		Name: c.Name,
+		Name:      c.Name,
+		Resubmits: c.Resubmits,
 	}
 	pmb, err := json.Marshal(pm)
 	if err != nil {
@@ -234,6 +241,10 @@ flag. Only one of these flags can be used at a time.
 A proposal can be submitted as an RFP submission by using the --linkto flag
 to link to and an existing RFP proposal.
 
+A proposal that was rejected by the ticket vote can be resubmitted by using
+the --resubmits flag to reference the rejected proposal. The rejected proposal
+must have been submitted by the same user.
+
 Arguments:
 1. indexfile   (string, optional) Index file.
 2. attachments (string, optional) Attachment files.
@@ -243,6 +254,9 @@ Flags:
 
  --linkto       (string) Token of an existing public proposal to link to.
 
+ --resubmits    (string) Token of a rejected proposal that this proposal is
+                         a resubmission of.
+
  --linkby       (string) Make the proposal and RFP by setting the linkby
                          deadline. Other proposals must be entered as RFP
                          submissions by this linkby deadline. The provided
@@ -0,0 +1,160 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	pclient "github.com/decred/politeia/politeiawww/client"
+)
+
+// cmdRFP retrieves an RFP proposal and its submissions.
+type cmdRFP struct {
+	Args struct {
+		Token string `positional-arg-name:"token"`
+	} `positional-args:"true" required:"true"`
+}
+
+// Execute executes the cmdRFP command.
+//
+// This function satisfies the go-flags Commander interface.
+func (c *cmdRFP) Execute(args []string) error {
+	// Setup client
+	opts := pclient.Opts{
+		HTTPSCert:      cfg.HTTPSCert,
+		Proxy:          cfg.Proxy,
+		ProxyIsolation: cfg.ProxyIsolation,
+		Verbose:        cfg.Verbose,
+		RawJSON:        cfg.RawJSON,
+	}
+	pc, err := pclient.New(cfg.Host, opts)
+	if err != nil {
+		return err
+	}
+
+	// Get the RFP
+	rr, err := pc.PiRFP(piv1.RFP{
+		Token: c.Args.Token,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Print the RFP and its submissions
+	printJSON(rr)
+
+	return nil
+}
+
+// rfpHelpMsg is printed to stdout by the help command.
+const rfpHelpMsg = `rfp "token"
+
+Get an RFP proposal and its submissions along with their vote outcomes. Any
+problems with the link between a submission and the RFP are returned in the
+submission linkerrors.
+
+Arguments:
+1. token  (string, required)  RFP token.`
+
+// cmdRFPParent retrieves the RFP proposal that a submission links to.
+type cmdRFPParent struct {
+	Args struct {
+		Token string `positional-arg-name:"token"`
+	} `positional-args:"true" required:"true"`
+}
+
+// Execute executes the cmdRFPParent command.
+//
+// This function satisfies the go-flags Commander interface.
+func (c *cmdRFPParent) Execute(args []string) error {
+	// Setup client
+	opts := pclient.Opts{
+		HTTPSCert:      cfg.HTTPSCert,
+		Proxy:          cfg.Proxy,
+		ProxyIsolation: cfg.ProxyIsolation,
+		Verbose:        cfg.Verbose,
+		RawJSON:        cfg.RawJSON,
+	}
+	pc, err := pclient.New(cfg.Host, opts)
+	if err != nil {
+		return err
+	}
+
+	// Get the RFP of the submission
+	rpr, err := pc.PiRFPParent(piv1.RFPParent{
+		Token: c.Args.Token,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Print the RFP and the submission
+	printJSON(rpr)
+
+	return nil
+}
+
+// rfpParentHelpMsg is printed to stdout by the help command.
+const rfpParentHelpMsg = `rfpparent "token"
+
+Get the RFP proposal that a submission links to. Any problems with the link
+are returned in the submission linkerrors.
+
+Arguments:
+1. token  (string, required)  Submission token.`
+
+// cmdRFPRepair repairs the submissions list of an RFP proposal.
+type cmdRFPRepair struct {
+	Args struct {
+		Token       string   `positional-arg-name:"token" required:"true"`
+		Submissions []string `positional-arg-name:"submissions"`
+	} `positional-args:"true"`
+}
+
+// Execute executes the cmdRFPRepair command.
+//
+// This function satisfies the go-flags Commander interface.
+func (c *cmdRFPRepair) Execute(args []string) error {
+	// Setup client
+	opts := pclient.Opts{
+		HTTPSCert:      cfg.HTTPSCert,
+		Proxy:          cfg.Proxy,
+		ProxyIsolation: cfg.ProxyIsolation,
+		Cookies:        cfg.Cookies,
+		HeaderCSRF:     cfg.CSRF,
+		Verbose:        cfg.Verbose,
+		RawJSON:        cfg.RawJSON,
+	}
+	pc, err := pclient.New(cfg.Host, opts)
+	if err != nil {
+		return err
+	}
+
+	// Repair the submissions list
+	rrr, err := pc.PiRFPRepair(piv1.RFPRepair{
+		Token:       c.Args.Token,
+		Submissions: c.Args.Submissions,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Print the added and removed submissions
+	printJSON(rrr)
+
+	return nil
+}
+
+// rfpRepairHelpMsg is printed to stdout by the help command.
+const rfpRepairHelpMsg = `rfprepair "token" "submissions..."
+
+Repair the submissions list of an RFP proposal. The proposals that are in the
+list and the provided submissions are re-verified. A proposal belongs in the
+list if it is public and links to the RFP. All public proposals are searched
+for submissions that link to the RFP when no submissions are provided. Requires
+admin privileges.
+
+Arguments:
+1. token        (string, required)  RFP token.
+2. submissions  (string, optional)  Submission tokens.`
@@ -0,0 +1,134 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	pclient "github.com/decred/politeia/politeiawww/client"
+)
+
+// cmdVoteCertificate retrieves the vote certificate of a finished record vote
+// and verifies it against the vote data of the record.
+type cmdVoteCertificate struct {
+	Args struct {
+		Token string `positional-arg-name:"token" required:"true"`
+	} `positional-args:"true"`
+}
+
+// Execute executes the cmdVoteCertificate command.
+//
+// This function satisfies the go-flags Commander interface.
+func (c *cmdVoteCertificate) Execute(args []string) error {
+	// Setup client
+	opts := pclient.Opts{
+		HTTPSCert:      cfg.HTTPSCert,
+		Proxy:          cfg.Proxy,
+		ProxyIsolation: cfg.ProxyIsolation,
+		Verbose:        cfg.Verbose,
+		RawJSON:        cfg.RawJSON,
+	}
+	pc, err := pclient.New(cfg.Host, opts)
+	if err != nil {
+		return err
+	}
+	vr, err := client.Version()
+	if err != nil {
+		return err
+	}
+
+	// Get the certificate and verify the server signature
+	cr, err := pc.TicketVoteCertificate(tkv1.Certificate{
+		Token: c.Args.Token,
+	})
+	if err != nil {
+		return err
+	}
+	err = pclient.CertificateVerify(*cr, vr.PubKey)
+	if err != nil {
+		return err
+	}
+	vc := cr.Certificate
+
+	// Get the vote details and the cast votes. The signatures are
+	// verified before the vote data is used to verify the certificate.
+	dr, err := pc.TicketVoteDetails(tkv1.Details{
+		Token: c.Args.Token,
+	})
+	if err != nil {
+		return err
+	}
+	if dr.Vote == nil {
+		return fmt.Errorf("vote details not found")
+	}
+	err = pclient.VoteDetailsVerify(*dr.Vote, vr.PubKey)
+	if err != nil {
+		return fmt.Errorf("unable to verify vote details: %v", err)
+	}
+	rr, err := pc.TicketVoteResults(tkv1.Results{
+		Token: c.Args.Token,
+	})
+	if err != nil {
+		return err
+	}
+	for _, v := range rr.Votes {
+		err = pclient.CastVoteDetailsVerify(v, vr.PubKey)
+		if err != nil {
+			return fmt.Errorf("unable to verify vote %v: %v", v.Ticket, err)
+		}
+	}
+
+	// Recompute the tally
+	err = pclient.CertificateResultsVerify(vc, *dr.Vote, rr.Votes)
+	if err != nil {
+		return err
+	}
+
+	// Verify the anchor references
+	tr, err := pc.TicketVoteTimestamps(tkv1.Timestamps{
+		Token: c.Args.Token,
+	})
+	if err != nil {
+		return err
+	}
+	if tr.Details == nil {
+		return fmt.Errorf("vote details timestamp not found")
+	}
+	err = pclient.CertificateAnchorVerify(vc, *dr.Vote, *tr.Details)
+	if err != nil {
+		return err
+	}
+
+	// Print the certificate
+	printf("Token            : %v\n", vc.Token)
+	printf("Outcome          : %v\n", tkv1.VoteStatuses[vc.Status])
+	printf("Eligible tickets : %v\n", vc.EligibleTickets)
+	printf("Tickets digest   : %v\n", vc.EligibleTicketsDigest)
+	printf("Votes cast       : %v\n", vc.TotalVotes)
+	for _, v := range vc.Results {
+		printf("  %-16v %v\n", v.ID, v.Votes)
+	}
+	printf("Anchor digest    : %v\n", vc.AnchorDigest)
+	printf("Merkle root      : %v\n", vc.AnchorMerkleRoot)
+	printf("DCR tx           : %v\n", vc.AnchorTxID)
+	printf("Certificate verified!\n")
+
+	return nil
+}
+
+// voteCertificateHelpMsg is printed to stdout by the help command.
+const voteCertificateHelpMsg = `votecertificate "token"
+
+Fetch and verify the vote certificate of a finished record vote.
+
+The server signature of the certificate is verified, the vote totals and
+thresholds are recalculated from the cast votes of the record, and the anchor
+references of the certificate are verified against the vote details timestamp.
+The merkle root can be found in the OP_RETURN of the DCR tx.
+
+Arguments:
+1. token  (string, required)  Record token.
+`
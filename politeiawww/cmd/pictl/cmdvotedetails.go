@@ -24,9 +24,11 @@ type cmdVoteDetails struct {
 func (c *cmdVoteDetails) Execute(args []string) error {
 	// Setup client
 	opts := pclient.Opts{
-		HTTPSCert: cfg.HTTPSCert,
-		Verbose:   cfg.Verbose,
-		RawJSON:   cfg.RawJSON,
+		HTTPSCert:      cfg.HTTPSCert,
+		Proxy:          cfg.Proxy,
+		ProxyIsolation: cfg.ProxyIsolation,
+		Verbose:        cfg.Verbose,
+		RawJSON:        cfg.RawJSON,
 	}
 	pc, err := pclient.New(cfg.Host, opts)
 	if err != nil {
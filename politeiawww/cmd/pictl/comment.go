@@ -5,12 +5,34 @@
 package main
 
 import (
+	"encoding/hex"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	"github.com/decred/politeia/util"
 )
 
+// commentNonce returns a random nonce and an expiry that prevent a signed
+// comment request from being replayed. The expiry leaves some margin for
+// clock differences between the client and the server.
+func commentNonce() (string, int64, error) {
+	b, err := util.Random(cmv1.NonceSize)
+	if err != nil {
+		return "", 0, err
+	}
+	expiry := time.Now().Unix() + cmv1.NonceExpiryMax/2
+	return hex.EncodeToString(b), expiry, nil
+}
+
+// commentNonceMsg returns the nonce and expiry formatted as they are appended
+// to the signed message.
+func commentNonceMsg(nonce string, expiry int64) string {
+	return nonce + strconv.FormatInt(expiry, 10)
+}
+
 func printComment(c cmv1.Comment) {
 	downvotes := int64(c.Downvotes) * -1
 
@@ -74,6 +74,10 @@ type pictl struct {
 	ProposalInv        cmdProposalInv        `command:"proposalinv"`
 	ProposalInvOrdered cmdProposalInvOrdered `command:"proposalinvordered"`
 	UserProposals      cmdUserProposals      `command:"userproposals"`
+	RFP                cmdRFP                `command:"rfp"`
+	RFPParent          cmdRFPParent          `command:"rfpparent"`
+	RFPRepair          cmdRFPRepair          `command:"rfprepair"`
+	Lineage            cmdLineage            `command:"lineage"`
 
 	// Comments commands
 	CommentsPolicy    cmdCommentPolicy     `command:"commentpolicy"`
@@ -96,6 +100,7 @@ type pictl struct {
 	VoteSubmissions cmdVoteSubmissions `command:"votesubmissions"`
 	VoteInv         cmdVoteInv         `command:"voteinv"`
 	VoteTimestamps  cmdVoteTimestamps  `command:"votetimestamps"`
+	VoteCertificate cmdVoteCertificate `command:"votecertificate"`
 
 	// Websocket commands
 	Subscribe subscribeCmd `command:"subscribe"`
@@ -162,6 +167,10 @@ Proposal commands
   proposalinv             (public) Get inventory by proposal status
   proposalinvordered      (public) Get inventory ordered chronologically
   userproposals           (public) Get proposals submitted by a user
+  rfp                     (public) Get an RFP and its submissions
+  rfpparent               (public) Get the RFP of a submission
+  rfprepair               (admin)  Repair the submissions list of an RFP
+  lineage                 (public) Get the resubmission lineage of a proposal
 
 Comment commands
   commentpolicy           (public) Get the comments api policy
@@ -184,6 +193,7 @@ Vote commands
   votesubmissions         (public) Get runoff vote submissions
   voteinv                 (public) Get proposal inventory by vote status
   votetimestamps          (public) Get vote timestamps
+  votecertificate         (public) Verify a vote certificate
 
 Websocket commands
   subscribe               (public) Subscribe/unsubscribe to websocket event
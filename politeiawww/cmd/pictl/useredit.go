@@ -18,6 +18,15 @@ type userEditCmd struct {
 	Args struct {
 		NotifType string `long:"emailnotifications"` // Email notification bit field
 	} `positional-args:"true" required:"true"`
+
+	// TimeZone and Locale are used to format the dates in the
+	// notification emails of the user.
+	TimeZone string `long:"timezone" optional:"true"`
+	Locale   string `long:"locale" optional:"true"`
+
+	// Digest batches the notification emails into a daily or weekly
+	// digest email.
+	Digest string `long:"digest" optional:"true"`
 }
 
 // Execute executes the userEditCmd command.
@@ -34,6 +43,9 @@ func (cmd *userEditCmd) Execute(args []string) error {
 		"userauthorizedvote":        v1.NotificationEmailAdminProposalVoteAuthorized,
 		"commentonproposal":         v1.NotificationEmailCommentOnMyProposal,
 		"commentoncomment":          v1.NotificationEmailCommentOnMyComment,
+		"newreport":                 v1.NotificationEmailAdminReportNew,
+		"authorupdate":              v1.NotificationEmailRegularProposalAuthorUpdate,
+		"filequarantined":           v1.NotificationEmailAdminFileQuarantined,
 	}
 
 	var notif v1.EmailNotificationT
@@ -68,6 +80,27 @@ func (cmd *userEditCmd) Execute(args []string) error {
 	eu := &v1.EditUser{
 		EmailNotifications: &helper,
 	}
+	if cmd.TimeZone != "" {
+		eu.TimeZone = &cmd.TimeZone
+	}
+	if cmd.Locale != "" {
+		eu.Locale = &cmd.Locale
+	}
+	if cmd.Digest != "" {
+		var found bool
+		for k, v := range v1.EmailDigests {
+			if v == cmd.Digest {
+				digest := k
+				eu.EmailDigest = &digest
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("invalid digest %v; must be none, daily, "+
+				"or weekly", cmd.Digest)
+		}
+	}
 
 	// Print request details
 	err = shared.PrintJSON(eu)
@@ -94,14 +127,28 @@ Edit user settings for the logged in user.
 Arguments:
 1. emailnotifications       (string, required)   Email notification bit field
 
+Flags:
+ --timezone  (string, optional)  IANA time zone that is used to format the
+                                 dates in notification emails, e.g.
+                                 America/Chicago
+ --locale    (string, optional)  Locale that is used to format the dates in
+                                 notification emails, e.g. en-GB. The
+                                 supported locales are returned by the
+                                 policy command.
+ --digest    (string, optional)  Batch the notification emails into a digest
+                                 email: none, daily, or weekly.
+
 Valid options are:
 
-1.   userproposalchange         Notify when status of my proposal changes
-2.   userproposalvotingstarted  Notify when my proposal vote has started
-4.   proposalvetted             Notify when any proposal is vetted
-8.   proposaledited             Notify when any proposal is edited
-16.  votingstarted              Notify when voting on any proposal has started
-32.  newproposal                Notify when proposal is submitted (admin only)
-64.  userauthorizedvote         Notify when user authorizes vote (admin only)
-128. commentonproposal          Notify when comment is made on my proposal
-256. commentoncomment           Notify when comment is made on my comment`
+1.    userproposalchange          Notify when status of my proposal changes
+2.    userproposalvotingstarted   Notify when my proposal vote has started
+4.    proposalvetted              Notify when any proposal is vetted
+8.    proposaledited              Notify when any proposal is edited
+16.   votingstarted               Notify when voting on any proposal has started
+32.   newproposal                 Notify when proposal is submitted (admin only)
+64.   userauthorizedvote          Notify when user authorizes vote (admin only)
+128.  commentonproposal           Notify when comment is made on my proposal
+256.  commentoncomment            Notify when comment is made on my comment
+512.  newreport                   Notify when report is submitted (admin only)
+2048. authorupdate                Notify when an author update is posted
+4096. filequarantined             Notify when an infected upload is quarantined (admin only)`
@@ -64,9 +64,9 @@ func (cmd *userNewCmd) Execute(args []string) error {
 	}
 
 	// Validate password
-	if uint(len(password)) < pr.MinPasswordLength {
-		return fmt.Errorf("password must be %v characters long",
-			pr.MinPasswordLength)
+	err = shared.ValidatePassword(pr, password, username, email)
+	if err != nil {
+		return err
 	}
 
 	// Create user identity and save it to disk
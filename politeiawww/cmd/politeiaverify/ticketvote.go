@@ -9,7 +9,6 @@ import (
 	"fmt"
 	"io/ioutil"
 
-	backend "github.com/decred/politeia/politeiad/backendv2"
 	tkplugin "github.com/decred/politeia/politeiad/plugins/ticketvote"
 	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
 	"github.com/decred/politeia/politeiawww/client"
@@ -176,7 +175,7 @@ func verifyVoteTimestamps(fp string) error {
 		case nil:
 			// Timestamp verified. Check the next one.
 			continue
-		case backend.ErrNotTimestamped:
+		case client.ErrNotTimestamped:
 			// This ticket has not been timestamped yet. Continue to the
 			// code below so that the ticket hash gets printed.
 		default:
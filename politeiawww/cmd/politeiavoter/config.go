@@ -69,19 +69,34 @@ type config struct {
 	WalletCert       string `long:"walletgrpccert" description:"Wallet GRPC certificate"`
 	WalletPassphrase string `long:"walletpassphrase" description:"Wallet decryption passphrase"`
 	BypassProxyCheck bool   `long:"bypassproxycheck" description:"Don't use this unless you know what you're doing."`
-	Proxy            string `long:"proxy" description:"Connect via SOCKS5 proxy (eg. 127.0.0.1:9050)"`
+	Proxy            string `long:"proxy" description:"Connect via SOCKS5 proxy; host names are resolved by the proxy unless a socks5:// URL is used (eg. 127.0.0.1:9050, socks5h://[::1]:9050)"`
 	ProxyUser        string `long:"proxyuser" description:"Username for proxy server"`
 	ProxyPass        string `long:"proxypass" default-mask:"-" description:"Password for proxy server"`
+	IPv4             bool   `long:"ipv4" description:"Only connect to politeiawww using IPv4"`
+	IPv6             bool   `long:"ipv6" description:"Only connect to politeiawww using IPv6"`
+	ServerPubKey     string `long:"serverpubkey" description:"Expected politeiawww identity public key; required when politeiawww is an onion service"`
 	VoteDuration     string `long:"voteduration" description:"Duration to cast all votes in hours and minutes e.g. 5h10m (default 0s means autodetect duration)"`
 	Trickle          bool   `long:"trickle" description:"Enable vote trickling, requires --proxy."`
+	BallotPadding    int    `long:"ballotpadding" description:"Pad cast ballot requests to a multiple of this many bytes so that the request size does not reveal the ballot contents (default 0 disables padding)"`
+	BallotJitter     string `long:"ballotjitter" description:"Maximum random delay that is added before each trickled ballot is sent e.g. 30s, requires --trickle"`
+	ProgressSocket   string `long:"progresssocket" description:"Path of a unix socket that returns the trickle vote progress as JSON"`
+	TicketBuyerPause string `long:"ticketbuyerpause" description:"Path of a file that exists for the duration of a trickle vote run to signal a ticket buyer to pause purchases; requires --trickle"`
+	VoteMap          string `long:"votemap" description:"Path to a file that maps ticket hashes to vote options; mapped tickets vote the mapped option instead of the option provided to the vote command"`
+	UpdateManifest   string `long:"updatemanifest" description:"URL of a signed release manifest that is used to warn when politeiavoter is outdated or incompatible with the server; requires --updatepubkey"`
+	UpdatePubKey     string `long:"updatepubkey" description:"Release signing public key that the release manifest is verified against"`
 	SkipVerify       bool   `long:"skipverify" description:"Skip verifying the server's certifcate chain and host name."`
+	StrictPerms      bool   `long:"strictperms" description:"Refuse to run when the application directories or client key are accessible by other users"`
 
 	ClientCert string `long:"clientcert" description:"Path to TLS certificate for client authentication (default: client.pem)"`
 	ClientKey  string `long:"clientkey" description:"Path to TLS client authentication key (default: client-key.pem)"`
 
 	voteDir       string
+	onion         bool // PoliteiaWWW is an onion service
+	remoteDNS     bool // Host names are resolved by the proxy
 	dial          func(string, string) (net.Conn, error)
-	voteDuration  time.Duration // Parsed VoteDuration
+	voteDuration  time.Duration     // Parsed VoteDuration
+	ballotJitter  time.Duration     // Parsed BallotJitter
+	voteMap       map[string]string // Parsed VoteMap, [ticket]voteID
 	blocksPerHour uint64
 }
 
@@ -292,10 +307,16 @@ func loadConfig() (*config, []string, error) {
 		configFileError = err
 	}
 
-	// See if appdata was overridden
+	// See if appdata was overridden. Only the directories that still
+	// point into the previous home directory are moved; explicitly
+	// configured locations are left alone.
 	if hd != cfg.HomeDir {
-		cfg.LogDir = filepath.Join(cfg.HomeDir, defaultLogDirname)
-		cfg.voteDir = filepath.Join(cfg.HomeDir, defaultVoteDirname)
+		if cfg.LogDir == filepath.Join(hd, defaultLogDirname) {
+			cfg.LogDir = filepath.Join(cfg.HomeDir, defaultLogDirname)
+		}
+		if cfg.voteDir == filepath.Join(hd, defaultVoteDirname) {
+			cfg.voteDir = filepath.Join(cfg.HomeDir, defaultVoteDirname)
+		}
 	}
 
 	// Parse command line options again to ensure they take precedence.
@@ -428,9 +449,13 @@ func loadConfig() (*config, []string, error) {
 	}
 
 	// Socks proxy
-	cfg.dial = net.Dial
+	if cfg.IPv4 && cfg.IPv6 {
+		return nil, nil, fmt.Errorf("--ipv4 and --ipv6 can't both be set")
+	}
+	network := dialNetwork(cfg.IPv4, cfg.IPv6)
+	var proxy *socks.Proxy
 	if cfg.Proxy != "" {
-		_, _, err := net.SplitHostPort(cfg.Proxy)
+		addr, remoteDNS, err := parseProxy(cfg.Proxy)
 		if err != nil {
 			str := "%s: proxy address '%s' is invalid: %v"
 			err := fmt.Errorf(str, funcName, cfg.Proxy, err)
@@ -438,14 +463,24 @@ func loadConfig() (*config, []string, error) {
 			fmt.Fprintln(os.Stderr, usageMessage)
 			return nil, nil, fmt.Errorf("invalid --proxy %v", err)
 		}
-		proxy := &socks.Proxy{
-			Addr:         cfg.Proxy,
+		if !remoteDNS && !cfg.BypassProxyCheck {
+			return nil, nil, fmt.Errorf("socks5 proxies leak the DNS " +
+				"lookups outside of the proxy; use a socks5h proxy")
+		}
+		if remoteDNS && network != "tcp" {
+			return nil, nil, fmt.Errorf("--ipv4 and --ipv6 can't be " +
+				"used with a socks5h proxy since the proxy resolves " +
+				"the politeiawww host")
+		}
+		proxy = &socks.Proxy{
+			Addr:         addr,
 			Username:     cfg.ProxyUser,
 			Password:     cfg.ProxyPass,
 			TorIsolation: true,
 		}
-		cfg.dial = proxy.Dial
+		cfg.remoteDNS = remoteDNS
 	}
+	cfg.dial = newDial(proxy, cfg.remoteDNS, network)
 
 	// VoteDuration can only be set with trickle enable.
 	if cfg.VoteDuration != "" && !cfg.Trickle {
@@ -462,6 +497,23 @@ func loadConfig() (*config, []string, error) {
 		}
 	}
 
+	// Ballot padding and jitter
+	if cfg.BallotPadding < 0 {
+		return nil, nil, fmt.Errorf("invalid --ballotpadding %v",
+			cfg.BallotPadding)
+	}
+	if cfg.BallotJitter != "" {
+		if !cfg.Trickle {
+			return nil, nil, fmt.Errorf("must use --trickle when " +
+				"--ballotjitter is set")
+		}
+		cfg.ballotJitter, err = time.ParseDuration(cfg.BallotJitter)
+		if err != nil || cfg.ballotJitter < 0 {
+			return nil, nil, fmt.Errorf("invalid --ballotjitter %v",
+				cfg.BallotJitter)
+		}
+	}
+
 	if !cfg.BypassProxyCheck {
 		if cfg.Trickle && cfg.Proxy == "" {
 			return nil, nil, fmt.Errorf("cannot use --trickle " +
@@ -469,15 +521,134 @@ func loadConfig() (*config, []string, error) {
 		}
 	}
 
-	// Set path for the client key/cert depending on if they are set in options
+	// Onion service. The proxy is always required since the onion
+	// service can only be reached through Tor. The server identity must
+	// be provided out-of-band since the identity returned by the server
+	// can't be authenticated using the TLS certificate chain.
+	cfg.onion, err = isOnion(cfg.PoliteiaWWW)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid --politeiawww %v", err)
+	}
+	if cfg.onion {
+		if cfg.Proxy == "" || !cfg.remoteDNS {
+			return nil, nil, fmt.Errorf("cannot connect to onion " +
+				"service without a socks5h --proxy")
+		}
+		if cfg.ServerPubKey == "" {
+			return nil, nil, fmt.Errorf("must use --serverpubkey " +
+				"when connecting to an onion service")
+		}
+	}
+
+	// Host names must only be resolved by the proxy when the proxy
+	// resolves them. Clearnet DNS is disabled so that a lookup that is
+	// accidentally performed locally fails instead of leaking.
+	if cfg.remoteDNS {
+		disableClearnetDNS()
+	}
+	if cfg.ServerPubKey != "" {
+		_, err = util.IdentityFromString(cfg.ServerPubKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid --serverpubkey %v",
+				err)
+		}
+	}
+
+	// Update check
+	if cfg.UpdateManifest != "" {
+		if cfg.UpdatePubKey == "" {
+			return nil, nil, fmt.Errorf("must use --updatepubkey when " +
+				"--updatemanifest is set")
+		}
+		_, err = util.IdentityFromString(cfg.UpdatePubKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid --updatepubkey %v", err)
+		}
+	}
+
+	// Progress socket
+	if cfg.ProgressSocket != "" {
+		if !cfg.Trickle {
+			return nil, nil, fmt.Errorf("must use --trickle when " +
+				"--progresssocket is set")
+		}
+		cfg.ProgressSocket = util.CleanAndExpandPath(cfg.ProgressSocket)
+	}
+
+	// Ticket buyer pause file
+	if cfg.TicketBuyerPause != "" {
+		if !cfg.Trickle {
+			return nil, nil, fmt.Errorf("must use --trickle when " +
+				"--ticketbuyerpause is set")
+		}
+		cfg.TicketBuyerPause = util.CleanAndExpandPath(cfg.TicketBuyerPause)
+	}
+
+	// Vote map
+	if cfg.VoteMap != "" {
+		cfg.VoteMap = util.CleanAndExpandPath(cfg.VoteMap)
+		cfg.voteMap, err = loadVoteMap(cfg.VoteMap)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid --votemap %v: %v",
+				cfg.VoteMap, err)
+		}
+	}
+
+	// Set path for the client key/cert depending on if they are set in
+	// options. Relative paths are relative to the application home
+	// directory so that instances using different appdata directories
+	// never pick up each others keypair.
 	cfg.ClientCert = util.CleanAndExpandPath(cfg.ClientCert)
 	cfg.ClientKey = util.CleanAndExpandPath(cfg.ClientKey)
 	if cfg.ClientCert == "" {
-		cfg.ClientCert = filepath.Join(cfg.HomeDir, clientCertFile)
+		cfg.ClientCert = clientCertFile
 	}
 	if cfg.ClientKey == "" {
-		cfg.ClientKey = filepath.Join(cfg.HomeDir, clientKeyFile)
+		cfg.ClientKey = clientKeyFile
+	}
+	if !filepath.IsAbs(cfg.ClientCert) {
+		cfg.ClientCert = filepath.Join(cfg.HomeDir, cfg.ClientCert)
+	}
+	if !filepath.IsAbs(cfg.ClientKey) {
+		cfg.ClientKey = filepath.Join(cfg.HomeDir, cfg.ClientKey)
+	}
+
+	// Verify that the application data is not accessible by other
+	// users. This matters on shared machines where multiple users run
+	// their own politeiavoter instance.
+	err = verifyPermissions(&cfg)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	return &cfg, remainingArgs, nil
 }
+
+// verifyPermissions checks the permissions of the application directories
+// and the client key. A warning is logged for every path that is accessible
+// by other users. An error is returned instead when --strictperms is set.
+func verifyPermissions(cfg *config) error {
+	paths := []string{
+		cfg.HomeDir,
+		cfg.voteDir,
+		cfg.LogDir,
+		cfg.ClientKey,
+	}
+	for _, v := range paths {
+		w, err := permissionsWarning(v)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		if w == "" {
+			continue
+		}
+		if cfg.StrictPerms {
+			return fmt.Errorf("unsafe permissions: %v", w)
+		}
+		log.Warnf("Unsafe permissions: %v", w)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// onionTLD is the top level domain of Tor onion services.
+	onionTLD = ".onion"
+
+	// The following timeouts are used when connecting to an onion
+	// service. Tor circuits to onion services take considerably longer
+	// to build than clearnet circuits and the latency of each round
+	// trip is higher.
+	onionTLSHandshakeTimeout   = time.Minute
+	onionResponseHeaderTimeout = 3 * time.Minute
+	onionRequestTimeout        = 5 * time.Minute
+)
+
+var (
+	// errClearnetDNS is returned when a DNS lookup is attempted while
+	// clearnet DNS is disabled.
+	errClearnetDNS = errors.New("clearnet dns is disabled when host " +
+		"names are resolved by the proxy")
+)
+
+// isOnion returns whether the host of the provided URL is a Tor onion
+// service.
+func isOnion(rawURL string) (bool, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false, err
+	}
+	return strings.HasSuffix(strings.ToLower(u.Hostname()), onionTLD), nil
+}
+
+// disableClearnetDNS replaces the default resolver with one that refuses to
+// perform DNS lookups. The hosts file is still consulted so that the wallet
+// host can be provided as localhost. All other names must be resolved by the
+// proxy so that lookups do not leak outside of Tor. This is used when
+// connecting to an onion service and whenever a socks5h proxy is used.
+func disableClearnetDNS() {
+	net.DefaultResolver = &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return nil, errClearnetDNS
+		},
+	}
+}
+
+// onionTimeouts adjusts the timeouts of the provided http client and
+// transport for the latency of onion services.
+func onionTimeouts(c *http.Client, tr *http.Transport) {
+	tr.TLSHandshakeTimeout = onionTLSHandshakeTimeout
+	tr.ResponseHeaderTimeout = onionResponseHeaderTimeout
+	c.Timeout = onionRequestTimeout
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	crand "crypto/rand"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/client/voter/uniformprng"
+)
+
+// padBallot pads a JSON encoded ballot with trailing whitespace up to the
+// next multiple of size bytes. Trailing whitespace is ignored by the JSON
+// decoder, so the padded ballot decodes to the same ballot. Padding all
+// ballots to the same size prevents a network observer from inferring the
+// contents of a ballot, e.g. the vote option or the number of votes, from the
+// size of the request. A size of zero disables padding.
+func padBallot(b []byte, size int) []byte {
+	if size <= 0 {
+		return b
+	}
+	r := len(b) % size
+	if r == 0 && len(b) > 0 {
+		return b
+	}
+	return append(b, bytes.Repeat([]byte(" "), size-r)...)
+}
+
+// ballotJitter waits for a random duration between zero and the configured
+// ballot jitter before a ballot is sent. The jitter prevents the send time of
+// a ballot from exactly matching its scheduled time, which makes it harder to
+// correlate ballots with other traffic, such as retries. An ErrRetry is
+// returned if the wait is interrupted so that the ballot is cast again.
+func (c *ctx) ballotJitter() error {
+	if c.cfg.ballotJitter <= 0 {
+		return nil
+	}
+	prng, err := uniformprng.RandSource(crand.Reader)
+	if err != nil {
+		return err
+	}
+	d := time.Duration(prng.Int63n(int64(c.cfg.ballotJitter)))
+	log.Debugf("ballotJitter: %v", d)
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-c.wctx.Done():
+		return ErrRetry{
+			At:  "ballotJitter",
+			Err: c.wctx.Err(),
+		}
+	case <-t.C:
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+)
+
+func TestPadBallot(t *testing.T) {
+	cb := tkv1.CastBallot{
+		Votes: []tkv1.CastVote{{
+			Token:     "b1b3bd4a7b9e8a0d",
+			Ticket:    "e4e11cbc6d5e6e7c8a6a7b8ac1ec1e7b",
+			VoteBit:   "1",
+			Signature: "1f9ab1b5d0a8c0d5",
+		}},
+	}
+	b, err := json.Marshal(cb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name string
+		size int
+		want int
+	}{
+		{"disabled", 0, len(b)},
+		{"smaller than ballot", 16, (len(b) + 15) / 16 * 16},
+		{"larger than ballot", 1024, 1024},
+		{"exact", len(b), len(b)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := padBallot(append([]byte{}, b...), test.size)
+			if len(p) != test.want {
+				t.Fatalf("got length %v, want %v", len(p), test.want)
+			}
+			var got tkv1.CastBallot
+			err := json.Unmarshal(p, &got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, cb) {
+				t.Fatalf("got %v, want %v", got, cb)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+//
+// +build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// permissionsWarning returns a human readable warning when the provided path
+// is owned by another user or is accessible by group or world. An empty
+// string is returned when the permissions are safe.
+func permissionsWarning(path string) (string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok {
+		if int(st.Uid) != os.Getuid() {
+			return fmt.Sprintf("%v is owned by uid %v", path, st.Uid), nil
+		}
+	}
+	if perm := fi.Mode().Perm(); perm&0077 != 0 {
+		return fmt.Sprintf("%v is accessible by other users (%v)",
+			path, perm), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,13 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+//
+// +build windows
+
+package main
+
+// permissionsWarning is a noop on Windows. Directories created inside of
+// %LOCALAPPDATA% are only accessible by the owning user by default.
+func permissionsWarning(path string) (string, error) {
+	return "", nil
+}
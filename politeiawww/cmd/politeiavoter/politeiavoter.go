@@ -11,7 +11,6 @@ import (
 	crand "crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
-	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -33,16 +32,12 @@ import (
 
 	pb "decred.org/dcrwallet/rpc/walletrpc"
 	"github.com/davecgh/go-spew/spew"
-	"github.com/decred/dcrd/blockchain/stake/v3"
 	"github.com/decred/dcrd/chaincfg/chainhash"
-	"github.com/decred/dcrd/chaincfg/v3"
-	"github.com/decred/dcrd/dcrec/secp256k1/v3/ecdsa"
-	"github.com/decred/dcrd/dcrutil/v3"
-	"github.com/decred/dcrd/wire"
 	"github.com/decred/politeia/politeiad/api/v1/identity"
 	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
 	v1 "github.com/decred/politeia/politeiawww/api/www/v1"
 	"github.com/decred/politeia/politeiawww/client"
+	"github.com/decred/politeia/politeiawww/client/voter"
 	"github.com/decred/politeia/util"
 	"github.com/gorilla/schema"
 	"golang.org/x/crypto/ssh/terminal"
@@ -52,9 +47,9 @@ import (
 )
 
 const (
-	failedJournal  = "failed.json"
-	successJournal = "success.json"
-	workJournal    = "work.json"
+	failedJournal  = voter.JournalFailed
+	successJournal = voter.JournalSuccess
+	workJournal    = voter.JournalWork
 )
 
 func generateSeed() (int64, error) {
@@ -76,6 +71,7 @@ func usage() {
 	fmt.Fprintf(os.Stderr, "  vote      - Vote on a proposal\n")
 	fmt.Fprintf(os.Stderr, "  tally     - Tally votes on a proposal\n")
 	fmt.Fprintf(os.Stderr, "  verify    - Verify votes on a proposal\n")
+	fmt.Fprintf(os.Stderr, "  tickets   - List the live wallet tickets\n")
 	//fmt.Fprintf(os.Stderr, "  startvote          - Instruct vote to start "+
 	//	"(admin only)\n")
 	fmt.Fprintf(os.Stderr, "\n")
@@ -106,61 +102,6 @@ func (c *ctx) walletPassphrase() ([]byte, error) {
 	}
 }
 
-// verifyMessage verifies a message is properly signed.
-// Copied from https://github.com/decred/dcrd/blob/0fc55252f912756c23e641839b1001c21442c38a/rpcserver.go#L5605
-func verifyMessage(params *chaincfg.Params, address, message, signature string) (bool, error) {
-	// Decode the provided address.
-	addr, err := dcrutil.DecodeAddress(address, params)
-	if err != nil {
-		return false, fmt.Errorf("Could not decode address: %v",
-			err)
-	}
-
-	// Only P2PKH addresses are valid for signing.
-	if _, ok := addr.(*dcrutil.AddressPubKeyHash); !ok {
-		return false, fmt.Errorf("Address is not a pay-to-pubkey-hash "+
-			"address: %v", address)
-	}
-
-	// Decode base64 signature.
-	sig, err := base64.StdEncoding.DecodeString(signature)
-	if err != nil {
-		return false, fmt.Errorf("Malformed base64 encoding: %v", err)
-	}
-
-	// Validate the signature - this just shows that it was valid at all.
-	// we will compare it with the key next.
-	var buf bytes.Buffer
-	wire.WriteVarString(&buf, 0, "Decred Signed Message:\n")
-	wire.WriteVarString(&buf, 0, message)
-	expectedMessageHash := chainhash.HashB(buf.Bytes())
-	pk, wasCompressed, err := ecdsa.RecoverCompact(sig,
-		expectedMessageHash)
-	if err != nil {
-		// Mirror Bitcoin Core behavior, which treats error in
-		// RecoverCompact as invalid signature.
-		return false, nil
-	}
-
-	// Reconstruct the pubkey hash.
-	dcrPK := pk
-	var serializedPK []byte
-	if wasCompressed {
-		serializedPK = dcrPK.SerializeCompressed()
-	} else {
-		serializedPK = dcrPK.SerializeUncompressed()
-	}
-	a, err := dcrutil.NewAddressSecpPubKey(serializedPK, activeNetParams.Params)
-	if err != nil {
-		// Again mirror Bitcoin Core behavior, which treats error in
-		// public key reconstruction as invalid signature.
-		return false, nil
-	}
-
-	// Return boolean if addresses match.
-	return a.Address() == address, nil
-}
-
 // ctx is the client context.
 type ctx struct {
 	sync.RWMutex                            // retryQ lock
@@ -172,7 +113,17 @@ type ctx struct {
 	ballotResults      []tkv1.CastVoteReply // results of voting
 	voteIntervalQ      *list.List           // work that has to be completed
 
-	run time.Time // when this run started
+	run        time.Time     // when this run started
+	voteWindow time.Duration // time left in the vote when trickling
+
+	// Vote progress stats. The samples are only accessed by the stats
+	// handler. The most recent stats are protected by the mutex.
+	statsSamples []statsSample
+	statsLast    voteStats
+
+	// Cast ballot requests of this run. Used to generate the post-vote
+	// privacy report. Protected by the mutex.
+	voteRequests []voteRequest
 
 	cfg *config // application config
 
@@ -241,6 +192,15 @@ func newClient(shutdownCtx context.Context, cfg *config) (*ctx, error) {
 	}
 	wallet := pb.NewWalletServiceClient(conn)
 
+	// Tor latency is much higher when connecting to an onion service
+	hc := &http.Client{
+		Transport: tr,
+		Jar:       jar,
+	}
+	if cfg.onion {
+		onionTimeouts(hc, tr)
+	}
+
 	// return context
 	return &ctx{
 		run:                time.Now(),
@@ -254,45 +214,13 @@ func newClient(shutdownCtx context.Context, cfg *config) (*ctx, error) {
 		conn:               conn,
 		wallet:             wallet,
 		cfg:                cfg,
-		client: &http.Client{
-			Transport: tr,
-			Jar:       jar,
-		},
-		userAgent: fmt.Sprintf("politeiavoter/%s", cfg.Version),
+		client:             hc,
+		userAgent:          fmt.Sprintf("politeiavoter/%s", cfg.Version),
 	}, nil
 }
 
-type JSONTime struct {
-	Time string `json:"time"`
-}
-
 func (c *ctx) jsonLog(filename, token string, work ...interface{}) error {
-	dir := filepath.Join(c.cfg.voteDir, token)
-	os.MkdirAll(dir, 0700)
-
-	f := filepath.Join(dir, fmt.Sprintf("%v.%v", filename, c.run.Unix()))
-	fh, err := os.OpenFile(f, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
-	if err != nil {
-		return err
-	}
-	defer fh.Close()
-
-	e := json.NewEncoder(fh)
-	e.SetIndent("", "  ")
-	err = e.Encode(JSONTime{
-		Time: time.Now().Format(time.StampNano),
-	})
-	if err != nil {
-		return err
-	}
-	for _, v := range work {
-		err = e.Encode(v)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return voter.NewJournal(c.cfg.voteDir, c.run).Log(filename, token, work...)
 }
 
 func convertTicketHashes(h []string) ([][]byte, error) {
@@ -327,6 +255,10 @@ func (c *ctx) makeRequest(method, api, route string, b interface{}) ([]byte, err
 			if err != nil {
 				return nil, err
 			}
+			if route == tkv1.RouteCastBallot {
+				requestBody = padBallot(requestBody,
+					c.cfg.BallotPadding)
+			}
 		}
 	}
 
@@ -415,6 +347,12 @@ func firstContact(shutdownCtx context.Context, cfg *config) (*ctx, error) {
 	log.Debugf("Route  : %v", version.Route)
 	log.Debugf("Pubkey : %v", version.PubKey)
 
+	// Verify the server identity if it was provided out-of-band
+	if cfg.ServerPubKey != "" && cfg.ServerPubKey != version.PubKey {
+		return nil, fmt.Errorf("server identity mismatch: got %v, "+
+			"want %v", version.PubKey, cfg.ServerPubKey)
+	}
+
 	c.id, err = util.IdentityFromString(version.PubKey)
 	if err != nil {
 		return nil, err
@@ -423,74 +361,6 @@ func firstContact(shutdownCtx context.Context, cfg *config) (*ctx, error) {
 	return c, nil
 }
 
-// eligibleVotes takes a vote result reply that contains the full list of the
-// votes already cast along with a committed tickets response from wallet which
-// consists of a list of tickets the wallet is aware of and returns a list of
-// tickets that the wallet is actually able to sign and vote with.
-//
-// When a ticket has already voted, the signature is also checked to ensure it
-// is valid.  In the case it is invalid, and the wallet can sign it, the ticket
-// is included so it may be resubmitted.  This could be caused by bad data on
-// the server or if the server is lying to the client.
-func (c *ctx) eligibleVotes(rr *tkv1.ResultsReply, ctres *pb.CommittedTicketsResponse) ([]*pb.CommittedTicketsResponse_TicketAddress, error) {
-	// Put cast votes into a map to filter in linear time
-	castVotes := make(map[string]tkv1.CastVoteDetails)
-	for _, v := range rr.Votes {
-		castVotes[v.Ticket] = v
-	}
-
-	// Filter out tickets that have already voted. If a ticket has
-	// voted but the signature is invalid, resubmit the vote. This
-	// could be caused by bad data on the server or if the server is
-	// lying to the client.
-	eligible := make([]*pb.CommittedTicketsResponse_TicketAddress, 0,
-		len(ctres.TicketAddresses))
-	for _, t := range ctres.TicketAddresses {
-		h, err := chainhash.NewHash(t.Ticket)
-		if err != nil {
-			return nil, err
-		}
-
-		// Filter out tickets tracked by imported xpub accounts.
-		r, err := c.wallet.GetTransaction(context.TODO(), &pb.GetTransactionRequest{
-			TransactionHash: h[:],
-		})
-		if err != nil {
-			log.Error(err)
-			continue
-		}
-		tx := new(wire.MsgTx)
-		err = tx.Deserialize(bytes.NewReader(r.Transaction.Transaction))
-		if err != nil {
-			log.Error(err)
-			continue
-		}
-		addr, err := stake.AddrFromSStxPkScrCommitment(tx.TxOut[1].PkScript, activeNetParams.Params)
-		if err != nil {
-			log.Error(err)
-			continue
-		}
-		vr, err := c.wallet.ValidateAddress(context.TODO(), &pb.ValidateAddressRequest{
-			Address: addr.String(),
-		})
-		if err != nil {
-			log.Error(err)
-			continue
-		}
-		if vr.AccountNumber >= 1<<31-1 { // imported xpub account
-			// do not append to filtered.
-			continue
-		}
-
-		_, ok := castVotes[h.String()]
-		if !ok {
-			eligible = append(eligible, t)
-		}
-	}
-
-	return eligible, nil
-}
-
 func (c *ctx) _inventory(i tkv1.Inventory) (*tkv1.InventoryReply, error) {
 	responseBody, err := c.makeRequest(http.MethodPost,
 		tkv1.APIRoute, tkv1.RouteInventory, i)
@@ -563,13 +433,8 @@ func (c *ctx) voteResults(token, serverPubKey string) (*tkv1.ResultsReply, error
 	return &rr, nil
 }
 
-func (c *ctx) inventory() error {
-	// Get server public key to verify replies.
-	version, err := c.getVersion()
-	if err != nil {
-		return err
-	}
-	serverPubKey := version.PubKey
+// startedVotes returns the tokens of all records with an active vote.
+func (c *ctx) startedVotes() ([]string, error) {
 	// Inventory route is paginated, therefore we keep fetching
 	// until we receive a patch with number of records smaller than the
 	// ticketvote's declared page size.
@@ -581,7 +446,7 @@ func (c *ctx) inventory() error {
 			Status: tkv1.VoteStatusStarted,
 		})
 		if err != nil {
-			return err
+			return nil, err
 		}
 		pageTokens := ir.Vetted[tkv1.VoteStatuses[tkv1.VoteStatusStarted]]
 		tokens = append(tokens, pageTokens...)
@@ -590,6 +455,20 @@ func (c *ctx) inventory() error {
 		}
 		page++
 	}
+	return tokens, nil
+}
+
+func (c *ctx) inventory() error {
+	// Get server public key to verify replies.
+	version, err := c.getVersion()
+	if err != nil {
+		return err
+	}
+	serverPubKey := version.PubKey
+	tokens, err := c.startedVotes()
+	if err != nil {
+		return err
+	}
 
 	// Print empty message in case no active votes found.
 	if len(tokens) == 0 {
@@ -605,16 +484,8 @@ func (c *ctx) inventory() error {
 		}
 
 		// Ensure eligibility
-		tix, err := convertTicketHashes(dr.Vote.EligibleTickets)
-		if err != nil {
-			fmt.Printf("Ticket pool corrupt: %v %v\n",
-				dr.Vote.Params.Token, err)
-			continue
-		}
-		ctres, err := c.wallet.CommittedTickets(c.wctx,
-			&pb.CommittedTicketsRequest{
-				Tickets: tix,
-			})
+		w := &voteWallet{c: c}
+		tickets, err := w.CommittedTickets(c.wctx, dr.Vote.EligibleTickets)
 		if err != nil {
 			fmt.Printf("Ticket pool verification: %v %v\n",
 				dr.Vote.Params.Token, err)
@@ -622,7 +493,7 @@ func (c *ctx) inventory() error {
 		}
 
 		// Bail if there are no eligible tickets
-		if len(ctres.TicketAddresses) == 0 {
+		if len(tickets) == 0 {
 			fmt.Printf("No eligible tickets: %v\n", dr.Vote.Params.Token)
 		}
 
@@ -635,23 +506,17 @@ func (c *ctx) inventory() error {
 			continue
 		}
 
-		// Filter out tickets that have already voted or are otherwise
-		// ineligible for the wallet to sign.  Note that tickets that have
-		// already voted, but have an invalid signature are included so they
-		// may be resubmitted.
-		eligible, err := c.eligibleVotes(rr, ctres)
-		if err != nil {
-			fmt.Printf("Eligible vote filtering error: %v %v\n",
-				dr.Vote.Params, err)
-			continue
-		}
+		// Filter out tickets that have already voted. Note that tickets
+		// that have already voted, but have an invalid signature are
+		// included so they may be resubmitted.
+		eligible := voter.Unvoted(tickets, *rr, serverPubKey)
 
 		// Display vote bits
 		fmt.Printf("Vote: %v\n", dr.Vote.Params.Token)
 		fmt.Printf("  Start block     : %v\n", dr.Vote.StartBlockHeight)
 		fmt.Printf("  End block       : %v\n", dr.Vote.EndBlockHeight)
 		fmt.Printf("  Mask            : %v\n", dr.Vote.Params.Mask)
-		fmt.Printf("  Eligible tickets: %v\n", len(ctres.TicketAddresses))
+		fmt.Printf("  Eligible tickets: %v\n", len(tickets))
 		fmt.Printf("  Eligible votes  : %v\n", len(eligible))
 		for _, vo := range dr.Vote.Params.Options {
 			fmt.Printf("  Vote Option:\n")
@@ -691,9 +556,15 @@ func (c *ctx) sendVote(ballot *tkv1.CastBallot) (*tkv1.CastVoteReply, error) {
 	if len(ballot.Votes) != 1 {
 		return nil, fmt.Errorf("sendVote: only one vote allowed")
 	}
+	err := c.ballotJitter()
+	if err != nil {
+		return nil, err
+	}
 
+	at := time.Now()
 	responseBody, err := c.makeRequest(http.MethodPost,
 		tkv1.APIRoute, tkv1.RouteCastBallot, ballot)
+	c.recordRequest(at, ballot, err)
 	if err != nil {
 		return nil, err
 	}
@@ -712,6 +583,58 @@ func (c *ctx) sendVote(ballot *tkv1.CastBallot) (*tkv1.CastVoteReply, error) {
 	return &vr.Receipts[0], nil
 }
 
+// alreadyVoted returns whether a cast vote reply indicates that the ticket has
+// already voted. This happens when a ticket is voted again after a previous
+// run was interrupted, or when a vote is retried after the server recorded it
+// but the reply was lost. The vote of the ticket has been recorded so the
+// reply is equivalent to a successful vote.
+func alreadyVoted(vr tkv1.CastVoteReply) bool {
+	return vr.ErrorCode == tkv1.VoteErrorTicketAlreadyVoted
+}
+
+// classifyReceipts returns the cast vote replies of the votes that failed and
+// the number of votes that had already been cast. Votes that had already been
+// cast are not failures.
+func classifyReceipts(replies []tkv1.CastVoteReply) ([]tkv1.CastVoteReply, int) {
+	var (
+		failed      = make([]tkv1.CastVoteReply, 0, len(replies))
+		alreadyCast int
+	)
+	for _, v := range replies {
+		switch {
+		case alreadyVoted(v):
+			alreadyCast++
+		case v.ErrorContext != "":
+			failed = append(failed, v)
+		}
+	}
+	return failed, alreadyCast
+}
+
+// recordRequest records a cast ballot request for the post-vote privacy
+// report.
+func (c *ctx) recordRequest(at time.Time, ballot *tkv1.CastBallot, err error) {
+	tickets := make([]string, 0, len(ballot.Votes))
+	for _, v := range ballot.Votes {
+		tickets = append(tickets, v.Ticket)
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	c.voteRequests = append(c.voteRequests, voteRequest{
+		At:      at,
+		Tickets: tickets,
+		Failed:  err != nil,
+	})
+}
+
+// runReport returns the post-vote privacy report of this run.
+func (c *ctx) runReport() runReport {
+	c.RLock()
+	defer c.RUnlock()
+	return analyzeRun(c.voteRequests, c.voteWindow, c.cfg.Proxy != "")
+}
+
 // dumpComplete dumps the completed votes in this run.
 func (c *ctx) dumpComplete() {
 	c.RLock()
@@ -837,6 +760,12 @@ func (c *ctx) _voteTrickler(token string) error {
 				goto exit
 			}
 
+			// A ticket that has already voted, e.g. during a
+			// previous run that was interrupted, is reconciled into
+			// the success journal since its vote has been recorded.
+			if alreadyVoted(*vr) {
+				fmt.Printf("Vote already cast: %v\n", vr.Ticket)
+			}
 			err = c.jsonLog(successJournal, token, vr)
 			if err != nil {
 				return err
@@ -908,22 +837,11 @@ func (c *ctx) _vote(token, voteID string) error {
 		return fmt.Errorf("vote id not found: %v", voteID)
 	}
 
-	// Find eligble tickets
-	tix, err := convertTicketHashes(dr.Vote.EligibleTickets)
-	if err != nil {
-		return fmt.Errorf("ticket pool corrupt: %v %v",
-			token, err)
-	}
-	ctres, err := c.wallet.CommittedTickets(c.wctx,
-		&pb.CommittedTicketsRequest{
-			Tickets: tix,
-		})
+	// Convert the vote map option IDs to vote bits. Tickets that are
+	// in the vote map override the default vote bit.
+	voteBits, err := c.voteMapBits(dr.Vote.Params.Options)
 	if err != nil {
-		return fmt.Errorf("ticket pool verification: %v %v",
-			token, err)
-	}
-	if len(ctres.TicketAddresses) == 0 {
-		return fmt.Errorf("no eligible tickets found")
+		return err
 	}
 
 	// voteResults a list of the votes that have already been cast. We use these
@@ -933,12 +851,15 @@ func (c *ctx) _vote(token, voteID string) error {
 		return err
 	}
 
-	// Filter out tickets that have already voted or are otherwise ineligible
-	// for the wallet to sign.  Note that tickets that have already voted, but
-	// have an invalid signature are included so they may be resubmitted.
-	eligible, err := c.eligibleVotes(rr, ctres)
+	// Find the eligible tickets. Tickets that have already voted or are
+	// otherwise ineligible for the wallet to sign are filtered out. Note
+	// that tickets that have already voted, but have an invalid signature
+	// are included so they may be resubmitted.
+	w := &voteWallet{c: c}
+	eligible, err := voter.Eligible(c.wctx, w, *dr, *rr, v.PubKey)
 	if err != nil {
-		return err
+		return fmt.Errorf("ticket pool verification: %v %v",
+			token, err)
 	}
 
 	eligibleLen := len(eligible)
@@ -946,55 +867,77 @@ func (c *ctx) _vote(token, voteID string) error {
 		return fmt.Errorf("no eligible tickets found")
 	}
 	r := rand.New(rand.NewSource(seed))
-	// Fisher-Yates shuffle the ticket addresses.
+	// Fisher-Yates shuffle the tickets.
 	for i := 0; i < eligibleLen; i++ {
 		// Pick a number between current index and the end.
 		j := r.Intn(eligibleLen-i) + i
 		eligible[i], eligible[j] = eligible[j], eligible[i]
 	}
-	ctres.TicketAddresses = eligible
 
-	passphrase, err := c.walletPassphrase()
-	if err != nil {
-		return err
+	// Report the vote map tickets that can't be voted by this wallet
+	if len(voteBits) > 0 {
+		tickets := make(map[string]struct{}, eligibleLen)
+		for _, v := range eligible {
+			tickets[v.Ticket] = struct{}{}
+		}
+		var mapped int
+		for ticket := range voteBits {
+			if _, ok := tickets[ticket]; !ok {
+				fmt.Printf("Vote map ticket not eligible: %v\n", ticket)
+				continue
+			}
+			mapped++
+		}
+		fmt.Printf("Vote map tickets     : %v\n", mapped)
 	}
 
-	// Sign all tickets
-	sm := &pb.SignMessagesRequest{
-		Passphrase: passphrase,
-		Messages: make([]*pb.SignMessagesRequest_Message, 0,
-			len(ctres.TicketAddresses)),
-	}
-	for _, v := range ctres.TicketAddresses {
-		h, err := chainhash.NewHash(v.Ticket)
+	// Pause the ticket buyer for the duration of a trickle run. The
+	// pause starts before the tickets are signed since purchases can
+	// lock the accounts that the signatures require.
+	if c.cfg.Trickle && c.cfg.TicketBuyerPause != "" {
+		paused, err := pauseTicketBuyer(c.cfg.TicketBuyerPause, token)
 		if err != nil {
-			return err
+			return fmt.Errorf("pause ticket buyer: %v", err)
+		}
+		if paused {
+			defer func() {
+				err := resumeTicketBuyer(c.cfg.TicketBuyerPause)
+				if err != nil {
+					log.Errorf("resume ticket buyer: %v", err)
+				}
+			}()
 		}
-		msg := token + h.String() + voteBit
-		sm.Messages = append(sm.Messages, &pb.SignMessagesRequest_Message{
-			Address: v.Address,
-			Message: msg,
-		})
 	}
-	smr, err := c.wallet.SignMessages(c.wctx, sm)
+
+	w.passphrase, err = c.walletPassphrase()
 	if err != nil {
 		return err
 	}
 
-	// Make sure all signatures worked
-	for k, v := range smr.Replies {
-		if v.Error == "" {
-			continue
-		}
-		return fmt.Errorf("signature failed index %v: %v", k, v.Error)
+	// Sign all tickets. Each vote gets its own replay protection, which
+	// is part of the signed message. The signatures are verified locally
+	// so that bad signatures are flagged before the votes are submitted.
+	// Tickets with an invalid signature are not voted.
+	ballot, err := voter.Sign(c.wctx, w, activeNetParams.Params, token,
+		voteBit, voteBits, eligible)
+	if err != nil {
+		return err
+	}
+	for _, v := range ballot.Invalid {
+		fmt.Printf("Signature invalid: %v\n", v)
+	}
+	if len(ballot.Invalid) > 0 {
+		fmt.Printf("Invalid signatures   : %v\n", len(ballot.Invalid))
 	}
 
 	if c.cfg.Trickle {
 		go c.statsHandler()
 
 		// Calculate vote duration if not set
+		blocksLeft := vs.EndBlockHeight - bestBlock
+		c.voteWindow = activeNetParams.TargetTimePerBlock *
+			time.Duration(blocksLeft)
 		if c.cfg.voteDuration.Seconds() == 0 {
-			blocksLeft := vs.EndBlockHeight - bestBlock
 			if blocksLeft < uint32(c.cfg.blocksPerHour) {
 				return fmt.Errorf("less than one hour left to" +
 					" vote, please set --voteduration " +
@@ -1006,7 +949,7 @@ func (c *ctx) _vote(token, voteID string) error {
 		}
 
 		// Generate work
-		err := c.calculateTrickle(token, voteBit, ctres, smr)
+		err := c.calculateTrickle(token, ballot.Votes)
 		if err != nil {
 			return err
 		}
@@ -1015,29 +958,15 @@ func (c *ctx) _vote(token, voteID string) error {
 	}
 
 	// Vote everything at once.
-
-	// Note that ctres, sm and smr use the same index.
 	cv := tkv1.CastBallot{
-		Votes: make([]tkv1.CastVote, 0, len(ctres.TicketAddresses)),
-	}
-	c.ballotResults = make([]tkv1.CastVoteReply, 0, len(ctres.TicketAddresses))
-	for k, v := range ctres.TicketAddresses {
-		h, err := chainhash.NewHash(v.Ticket)
-		if err != nil {
-			return err
-		}
-		signature := hex.EncodeToString(smr.Replies[k].Signature)
-		cv.Votes = append(cv.Votes, tkv1.CastVote{
-			Token:     token,
-			Ticket:    h.String(),
-			VoteBit:   voteBit,
-			Signature: signature,
-		})
+		Votes: ballot.Votes,
 	}
 
 	// Vote on the supplied proposal
+	at := time.Now()
 	responseBody, err := c.makeRequest(http.MethodPost,
 		tkv1.APIRoute, tkv1.RouteCastBallot, &cv)
+	c.recordRequest(at, &cv, err)
 	if err != nil {
 		return err
 	}
@@ -1064,16 +993,12 @@ func (c *ctx) vote(args []string) error {
 	}
 
 	// Verify vote replies
-	failedReceipts := make([]tkv1.CastVoteReply, 0,
-		len(c.ballotResults))
-	for _, v := range c.ballotResults {
-		if v.ErrorContext != "" {
-			failedReceipts = append(failedReceipts, v)
-			continue
-		}
-	}
+	failedReceipts, alreadyCast := classifyReceipts(c.ballotResults)
 	fmt.Printf("Votes succeeded: %v\n", len(c.ballotResults)-
 		len(failedReceipts))
+	if alreadyCast > 0 {
+		fmt.Printf("Already cast   : %v\n", alreadyCast)
+	}
 	fmt.Printf("Votes failed   : %v\n", len(failedReceipts))
 	notCast := c.voteIntervalLen() + uint64(c.retryLen())
 	if notCast > 0 {
@@ -1084,6 +1009,9 @@ func (c *ctx) vote(args []string) error {
 			v.Ticket, v.ErrorContext)
 	}
 
+	// Summarize what an observer could infer from this run
+	fmt.Printf("\n%v", c.runReport())
+
 	return nil
 }
 
@@ -1163,7 +1091,7 @@ func (c *ctx) tally(args []string) error {
 }
 
 type failedTuple struct {
-	Time  JSONTime
+	Time  voter.JournalTime
 	Votes tkv1.CastBallot `json:"votes"`
 	Error ErrRetry
 }
@@ -1239,7 +1167,7 @@ exit:
 }
 
 type successTuple struct {
-	Time   JSONTime
+	Time   voter.JournalTime
 	Result tkv1.CastVoteReply
 }
 
@@ -1295,7 +1223,7 @@ exit:
 }
 
 type workTuple struct {
-	Time  JSONTime
+	Time  voter.JournalTime
 	Votes []voteInterval
 }
 
@@ -1514,9 +1442,20 @@ func (c *ctx) verifyVote(vote string) error {
 				}
 				if s, ok := success[ticket]; ok {
 					vs.success = len(s)
-					if len(s) != 1 {
+
+					// Replies that report the ticket has
+					// already voted are expected when a
+					// run is repeated and are not counted
+					// as multiple successes.
+					var cast int
+					for _, v := range s {
+						if !alreadyVoted(v.Result) {
+							cast++
+						}
+					}
+					if cast > 1 {
 						fmt.Printf("multiple success:"+
-							" %v %v\n", len(s),
+							" %v %v\n", cast,
 							ticket)
 					}
 				} else {
@@ -1693,6 +1632,12 @@ func _main() error {
 	}
 	log.Debugf("Current wallet height: %v", ar.CurrentBlockHeight)
 
+	// Warn when politeiavoter is outdated or incompatible with the
+	// server.
+	if cfg.UpdateManifest != "" {
+		c.updateCheck()
+	}
+
 	// Scan through command line arguments.
 
 	switch action {
@@ -1704,6 +1649,8 @@ func _main() error {
 		err = c.vote(args[1:])
 	case "verify":
 		err = c.verify(args[1:])
+	case "tickets":
+		err = c.tickets()
 	default:
 		err = fmt.Errorf("invalid action: %v", action)
 	}
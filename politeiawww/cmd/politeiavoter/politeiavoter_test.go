@@ -4,7 +4,37 @@
 
 package main
 
-import "testing"
+import (
+	"testing"
+
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+)
 
 func TestRetryQueue(t *testing.T) {
 }
+
+func TestClassifyReceipts(t *testing.T) {
+	replies := []tkv1.CastVoteReply{
+		{
+			Ticket:  "success",
+			Receipt: "receipt",
+		},
+		{
+			Ticket:       "alreadyvoted",
+			ErrorCode:    tkv1.VoteErrorTicketAlreadyVoted,
+			ErrorContext: "ticket already voted",
+		},
+		{
+			Ticket:       "failed",
+			ErrorCode:    tkv1.VoteErrorSignatureInvalid,
+			ErrorContext: "signature invalid",
+		},
+	}
+	failed, alreadyCast := classifyReceipts(replies)
+	if alreadyCast != 1 {
+		t.Fatalf("got %v already cast, want 1", alreadyCast)
+	}
+	if len(failed) != 1 || failed[0].Ticket != "failed" {
+		t.Fatalf("got failed %v, want the failed ticket", failed)
+	}
+}
@@ -0,0 +1,340 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// privacyIntervalTarget is the mean time between votes at which the
+	// votes are considered to be well spread out. Votes that are cast
+	// closer together are easier to link to each other by timing.
+	privacyIntervalTarget = 30 * time.Minute
+
+	// privacyBurstWindow is the window that is used to detect clusters
+	// of votes.
+	privacyBurstWindow = time.Minute
+
+	// privacyCoverageTarget is the duration that a schedule must cover
+	// to receive the full coverage score when the vote window is not
+	// known.
+	privacyCoverageTarget = 24 * time.Hour
+)
+
+// privacyReport contains the heuristic privacy analysis of a trickle
+// schedule.
+type privacyReport struct {
+	Votes        int           // Number of votes in the schedule
+	Duration     time.Duration // Time between the first and the last vote
+	Window       time.Duration // Remaining vote window, zero if unknown
+	MeanInterval time.Duration // Mean time between votes
+	MinInterval  time.Duration // Shortest time between votes
+	IntervalCV   float64       // Coefficient of variation of the intervals
+	MaxBurst     int           // Most votes within privacyBurstWindow
+	Proxy        bool          // Votes are sent through a proxy
+
+	Score       int      // Unlinkability score, 0-100
+	Suggestions []string // Suggestions to improve the score
+}
+
+// String returns a human readable representation of the privacy report.
+func (r privacyReport) String() string {
+	window := "unknown"
+	if r.Window > 0 {
+		window = r.Window.Round(time.Second).String()
+	}
+	s := fmt.Sprintf("Privacy analysis:\n"+
+		"  Votes                : %v\n"+
+		"  Schedule duration    : %v\n"+
+		"  Vote window          : %v\n"+
+		"  Mean interval        : %v\n"+
+		"  Min interval         : %v\n"+
+		"  Interval variation   : %.2f\n"+
+		"  Max votes per minute : %v\n"+
+		"  Proxy                : %v\n"+
+		"  Unlinkability score  : %v/100 (%v)\n",
+		r.Votes, r.Duration.Round(time.Second), window,
+		r.MeanInterval.Round(time.Second), r.MinInterval.Round(time.Second),
+		r.IntervalCV, r.MaxBurst, r.Proxy, r.Score,
+		privacyRating(r.Score))
+	for _, v := range r.Suggestions {
+		s += fmt.Sprintf("  Suggestion: %v\n", v)
+	}
+	return s
+}
+
+// privacyRating returns a human readable rating of a privacy score.
+func privacyRating(score int) string {
+	switch {
+	case score >= 80:
+		return "good"
+	case score >= 50:
+		return "fair"
+	default:
+		return "poor"
+	}
+}
+
+// analyzeSchedule returns a heuristic privacy analysis of a trickle schedule.
+// The schedule contains the sorted offsets from the start of the trickle at
+// which each vote is cast. The window is the time that is left in the vote
+// and may be zero if it is not known.
+//
+// The score is made up of the following components:
+//
+//	40 - how much of the vote window the schedule covers
+//	20 - how far apart the votes are on average
+//	10 - the absence of clusters of votes
+//	30 - the votes are sent through a proxy
+func analyzeSchedule(schedule []time.Duration, window time.Duration, proxy bool) privacyReport {
+	r := privacyReport{
+		Votes:  len(schedule),
+		Window: window,
+		Proxy:  proxy,
+	}
+	if len(schedule) == 0 {
+		return r
+	}
+	r.Duration = schedule[len(schedule)-1] - schedule[0]
+
+	// Interval distribution
+	if len(schedule) > 1 {
+		var (
+			intervals = make([]float64, 0, len(schedule)-1)
+			sum       float64
+		)
+		r.MinInterval = time.Duration(math.MaxInt64)
+		for i := 1; i < len(schedule); i++ {
+			d := schedule[i] - schedule[i-1]
+			if d < r.MinInterval {
+				r.MinInterval = d
+			}
+			intervals = append(intervals, float64(d))
+			sum += float64(d)
+		}
+		mean := sum / float64(len(intervals))
+		var variance float64
+		for _, v := range intervals {
+			variance += (v - mean) * (v - mean)
+		}
+		variance /= float64(len(intervals))
+		r.MeanInterval = time.Duration(mean)
+		if mean > 0 {
+			r.IntervalCV = math.Sqrt(variance) / mean
+		}
+	}
+
+	// Clustering. Find the most votes that are cast within any burst
+	// window using a sliding window over the sorted schedule.
+	var start int
+	for end := range schedule {
+		for schedule[end]-schedule[start] >= privacyBurstWindow {
+			start++
+		}
+		if n := end - start + 1; n > r.MaxBurst {
+			r.MaxBurst = n
+		}
+	}
+
+	// Coverage of the vote window
+	coverage := float64(r.Duration) / float64(privacyCoverageTarget)
+	if window > 0 {
+		coverage = float64(r.Duration) / float64(window)
+	}
+	coverage = math.Min(coverage, 1)
+	score := 40 * coverage
+	if coverage < 0.5 {
+		r.Suggestions = append(r.Suggestions, "use a longer "+
+			"--voteduration so that the votes are spread over more of "+
+			"the vote window")
+	}
+
+	// Vote spacing. A single vote can't be linked to other votes by
+	// timing.
+	spacing := 1.0
+	if len(schedule) > 1 {
+		spacing = math.Min(float64(r.MeanInterval)/
+			float64(privacyIntervalTarget), 1)
+	}
+	score += 20 * spacing
+	if spacing < 1 && coverage >= 0.5 && coverage < 1 {
+		r.Suggestions = append(r.Suggestions, "use a longer "+
+			"--voteduration to increase the time between votes")
+	}
+
+	// Clusters
+	burst := 1.0
+	if r.MaxBurst > 1 {
+		burst = 1 / float64(r.MaxBurst)
+	}
+	score += 10 * burst
+	if r.MaxBurst > 2 {
+		r.Suggestions = append(r.Suggestions, fmt.Sprintf("%v votes "+
+			"are cast within a minute of each other; a longer "+
+			"--voteduration reduces clustering", r.MaxBurst))
+	}
+
+	// Network
+	if proxy {
+		score += 30
+	} else {
+		r.Suggestions = append(r.Suggestions, "use --proxy with Tor so "+
+			"that the votes are not linked by IP address; each vote uses "+
+			"a separate Tor circuit")
+	}
+
+	r.Score = int(math.Round(score))
+	return r
+}
+
+// voteRequest is a cast ballot request that was sent during a run.
+type voteRequest struct {
+	At      time.Time // When the request was sent
+	Tickets []string  // Tickets in the ballot
+	Failed  bool      // Request failed and was not answered
+}
+
+// runReport summarizes what an external observer, including the server,
+// could plausibly infer from the cast ballot requests of a run.
+type runReport struct {
+	Requests     int           // Number of cast ballot requests
+	Failed       int           // Requests that failed
+	Tickets      int           // Number of unique tickets
+	Retries      int           // Requests for tickets that were already sent
+	MaxBatch     int           // Most tickets in a single request
+	Duration     time.Duration // Time between the first and last request
+	MeanInterval time.Duration // Mean time between requests
+	MinInterval  time.Duration // Shortest time between requests
+	MaxBurst     int           // Most requests within privacyBurstWindow
+	Proxy        bool          // Requests were sent through a proxy
+
+	Score      int      // Unlinkability score, 0-100
+	Inferences []string // What an observer could infer
+}
+
+// String returns a human readable representation of the run report.
+func (r runReport) String() string {
+	s := fmt.Sprintf("Post-vote privacy report:\n"+
+		"  Requests                : %v (%v failed)\n"+
+		"  Tickets                 : %v\n"+
+		"  Retried requests        : %v\n"+
+		"  Max tickets per request : %v\n"+
+		"  Time span               : %v\n"+
+		"  Mean interval           : %v\n"+
+		"  Min interval            : %v\n"+
+		"  Max requests per minute : %v\n"+
+		"  Proxy                   : %v\n"+
+		"  Unlinkability score     : %v/100 (%v)\n",
+		r.Requests, r.Failed, r.Tickets, r.Retries, r.MaxBatch,
+		r.Duration.Round(time.Second), r.MeanInterval.Round(time.Second),
+		r.MinInterval.Round(time.Second), r.MaxBurst, r.Proxy, r.Score,
+		privacyRating(r.Score))
+	for _, v := range r.Inferences {
+		s += fmt.Sprintf("  Observer: %v\n", v)
+	}
+	return s
+}
+
+// analyzeRun returns the post-vote privacy report of the provided cast ballot
+// requests, which must be in the order they were sent. The window is the time
+// that was left in the vote when the run started and may be zero if it is not
+// known.
+//
+// The score is the analyzeSchedule score of the request times. Tickets that
+// are sent in the same request are trivially linked to each other, so the
+// score is zero when any request contains more than one ticket.
+func analyzeRun(requests []voteRequest, window time.Duration, proxy bool) runReport {
+	r := runReport{
+		Requests: len(requests),
+		Proxy:    proxy,
+	}
+	if len(requests) == 0 {
+		return r
+	}
+
+	var (
+		tickets  = make(map[string]struct{}, len(requests))
+		schedule = make([]time.Duration, 0, len(requests))
+		start    = requests[0].At
+	)
+	for _, v := range requests {
+		if v.Failed {
+			r.Failed++
+		}
+		if len(v.Tickets) > r.MaxBatch {
+			r.MaxBatch = len(v.Tickets)
+		}
+		retry := len(v.Tickets) > 0
+		for _, t := range v.Tickets {
+			if _, ok := tickets[t]; !ok {
+				tickets[t] = struct{}{}
+				retry = false
+			}
+		}
+		if retry {
+			r.Retries++
+		}
+		schedule = append(schedule, v.At.Sub(start))
+	}
+	r.Tickets = len(tickets)
+
+	// Timing. The requests are sent in order, but the retry loop runs
+	// concurrently with the main loop so the times are sorted before
+	// they are analyzed.
+	sort.Slice(schedule, func(i, j int) bool {
+		return schedule[i] < schedule[j]
+	})
+	sr := analyzeSchedule(schedule, window, proxy)
+	r.Duration = sr.Duration
+	r.MeanInterval = sr.MeanInterval
+	r.MinInterval = sr.MinInterval
+	r.MaxBurst = sr.MaxBurst
+	r.Score = sr.Score
+
+	r.Inferences = append(r.Inferences, fmt.Sprintf("votes were cast "+
+		"between %v and %v; your voting activity is bounded to this "+
+		"time span", requests[0].At.Format(time.Stamp),
+		start.Add(r.Duration).Format(time.Stamp)))
+	if r.MaxBatch > 1 {
+		r.Score = 0
+		r.Inferences = append(r.Inferences, fmt.Sprintf("up to %v "+
+			"tickets were sent in a single request; the server can "+
+			"link all tickets in a request to the same voter, use "+
+			"--trickle to send each ticket separately", r.MaxBatch))
+	}
+	if proxy {
+		r.Inferences = append(r.Inferences, "each request was sent over "+
+			"a separate Tor circuit; the tickets can't be linked by IP "+
+			"address")
+	} else {
+		r.Inferences = append(r.Inferences, fmt.Sprintf("all %v "+
+			"requests were sent from your IP address; the server can "+
+			"link all tickets to it, use --proxy with Tor to prevent "+
+			"this", r.Requests))
+	}
+	if r.MaxBurst > 1 {
+		r.Inferences = append(r.Inferences, fmt.Sprintf("%v requests "+
+			"were sent within a minute of each other; requests that "+
+			"are close together may be linked by timing", r.MaxBurst))
+	}
+	if r.Requests > 1 && r.MeanInterval < privacyIntervalTarget {
+		r.Inferences = append(r.Inferences, fmt.Sprintf("the mean time "+
+			"between requests was %v; requests less than %v apart are "+
+			"easier to link, use a longer --voteduration",
+			r.MeanInterval.Round(time.Second), privacyIntervalTarget))
+	}
+	if r.Retries > 0 {
+		r.Inferences = append(r.Inferences, fmt.Sprintf("%v requests "+
+			"were retries of tickets that were already sent; the "+
+			"timing of retries may reveal that the requests came from "+
+			"the same client", r.Retries))
+	}
+
+	return r
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeSchedule(t *testing.T) {
+	// Evenly spread votes over the entire vote window
+	spread := make([]time.Duration, 0, 24)
+	for i := 0; i < 24; i++ {
+		spread = append(spread, time.Duration(i)*time.Hour)
+	}
+
+	// All votes cast within the same minute
+	burst := make([]time.Duration, 0, 24)
+	for i := 0; i < 24; i++ {
+		burst = append(burst, time.Duration(i)*time.Second)
+	}
+
+	var tests = []struct {
+		name     string
+		schedule []time.Duration
+		window   time.Duration
+		proxy    bool
+		score    int
+		burst    int
+	}{
+		{"spread proxy", spread, 23 * time.Hour, true, 100, 1},
+		{"spread no proxy", spread, 23 * time.Hour, false, 70, 1},
+		{"burst proxy", burst, 23 * time.Hour, true, 30, 24},
+		{"single vote", spread[:1], 0, true, 60, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := analyzeSchedule(test.schedule, test.window, test.proxy)
+			if r.Score != test.score {
+				t.Errorf("got score %v, want %v", r.Score, test.score)
+			}
+			if r.MaxBurst != test.burst {
+				t.Errorf("got max burst %v, want %v", r.MaxBurst, test.burst)
+			}
+			if r.Score < 100 && len(r.Suggestions) == 0 {
+				t.Errorf("no suggestions for score %v", r.Score)
+			}
+		})
+	}
+}
+
+func TestAnalyzeRun(t *testing.T) {
+	start := time.Unix(1614623400, 0)
+
+	// Trickled votes that are spread over the entire vote window, with
+	// one failed request that was retried.
+	trickle := make([]voteRequest, 0, 25)
+	for i := 0; i < 24; i++ {
+		trickle = append(trickle, voteRequest{
+			At:      start.Add(time.Duration(i) * time.Hour),
+			Tickets: []string{fmt.Sprintf("ticket%v", i)},
+			Failed:  i == 0,
+		})
+	}
+	trickle = append(trickle, voteRequest{
+		At:      start.Add(24 * time.Hour),
+		Tickets: []string{"ticket0"},
+	})
+
+	// All votes sent in a single request
+	batch := []voteRequest{{
+		At:      start,
+		Tickets: []string{"ticket0", "ticket1", "ticket2"},
+	}}
+
+	var tests = []struct {
+		name    string
+		reqs    []voteRequest
+		proxy   bool
+		score   int
+		tickets int
+		retries int
+		batch   int
+	}{
+		{"trickle", trickle, true, 100, 24, 1, 1},
+		{"batch", batch, false, 0, 3, 0, 3},
+		{"no requests", nil, true, 0, 0, 0, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := analyzeRun(test.reqs, 24*time.Hour, test.proxy)
+			if r.Score != test.score {
+				t.Errorf("got score %v, want %v", r.Score, test.score)
+			}
+			if r.Tickets != test.tickets {
+				t.Errorf("got tickets %v, want %v", r.Tickets, test.tickets)
+			}
+			if r.Retries != test.retries {
+				t.Errorf("got retries %v, want %v", r.Retries, test.retries)
+			}
+			if r.MaxBatch != test.batch {
+				t.Errorf("got max batch %v, want %v", r.MaxBatch,
+					test.batch)
+			}
+		})
+	}
+}
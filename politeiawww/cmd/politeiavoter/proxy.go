@@ -0,0 +1,110 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/decred/go-socks/socks"
+)
+
+const (
+	// The following are the supported proxy URL schemes. A proxy that
+	// is provided without a scheme is a socks5h proxy.
+	//
+	// socks5h proxies resolve the politeiawww host name. socks5 proxies
+	// are sent the address that the host name was resolved to locally,
+	// which leaks the DNS lookup outside of the proxy.
+	proxySchemeSOCKS5H = "socks5h"
+	proxySchemeSOCKS5  = "socks5"
+)
+
+// parseProxy parses the provided proxy address and returns the host:port of
+// the proxy and whether the proxy resolves the host names. The proxy can be
+// provided as host:port or as a socks5h or socks5 URL. IPv6 proxy addresses
+// must be enclosed in square brackets, e.g. socks5h://[::1]:9050.
+func parseProxy(proxy string) (string, bool, error) {
+	scheme := proxySchemeSOCKS5H
+	addr := proxy
+	if strings.Contains(proxy, "://") {
+		u, err := url.Parse(proxy)
+		if err != nil {
+			return "", false, err
+		}
+		if u.Path != "" || u.RawQuery != "" || u.User != nil {
+			return "", false, fmt.Errorf("proxy url may only contain " +
+				"a scheme, host, and port")
+		}
+		scheme = strings.ToLower(u.Scheme)
+		addr = u.Host
+	}
+	switch scheme {
+	case proxySchemeSOCKS5H, proxySchemeSOCKS5:
+	default:
+		return "", false, fmt.Errorf("unsupported proxy scheme %v", scheme)
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", false, err
+	}
+	if host == "" || port == "" {
+		return "", false, fmt.Errorf("proxy host and port are required")
+	}
+
+	return addr, scheme == proxySchemeSOCKS5H, nil
+}
+
+// dialNetwork returns the network that politeiawww is connected to over,
+// which restricts the connections to IPv4 or IPv6 when requested.
+func dialNetwork(ipv4, ipv6 bool) string {
+	switch {
+	case ipv4:
+		return "tcp4"
+	case ipv6:
+		return "tcp6"
+	}
+	return "tcp"
+}
+
+// newDial returns the function that is used to connect to politeiawww.
+//
+// Connections are made directly when no proxy is provided. When the proxy
+// resolves the host names, the host name is sent to the proxy as is. The
+// address family can't be enforced in that case since the proxy decides which
+// address to connect to. Otherwise, the host name is resolved locally using
+// the requested address family and the resolved address is sent to the proxy.
+func newDial(p *socks.Proxy, remoteDNS bool, network string) func(string, string) (net.Conn, error) {
+	switch {
+	case p == nil:
+		d := &net.Dialer{}
+		return func(_, addr string) (net.Conn, error) {
+			return d.Dial(network, addr)
+		}
+	case remoteDNS:
+		return p.Dial
+	}
+
+	ipNetwork := strings.Replace(network, "tcp", "ip", 1)
+	return func(_, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		ips, err := net.DefaultResolver.LookupIP(context.Background(),
+			ipNetwork, host)
+		if err != nil {
+			return nil, err
+		}
+		if len(ips) == 0 {
+			return nil, fmt.Errorf("no %v addresses found for %v",
+				ipNetwork, host)
+		}
+		return p.Dial("tcp", net.JoinHostPort(ips[0].String(), port))
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import "testing"
+
+func TestParseProxy(t *testing.T) {
+	var tests = []struct {
+		name      string
+		proxy     string
+		addr      string
+		remoteDNS bool
+		wantErr   bool
+	}{
+		{"host port", "127.0.0.1:9050", "127.0.0.1:9050", true, false},
+		{"ipv6 host port", "[::1]:9050", "[::1]:9050", true, false},
+		{"socks5h", "socks5h://localhost:9050", "localhost:9050", true, false},
+		{"socks5h ipv6", "socks5h://[::1]:9050", "[::1]:9050", true, false},
+		{"socks5", "SOCKS5://127.0.0.1:9050", "127.0.0.1:9050", false, false},
+		{"http", "http://127.0.0.1:8080", "", false, true},
+		{"no port", "socks5h://127.0.0.1", "", false, true},
+		{"ipv6 no brackets", "::1:9050", "", false, true},
+		{"path", "socks5h://127.0.0.1:9050/tor", "", false, true},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			addr, remoteDNS, err := parseProxy(v.proxy)
+			switch {
+			case v.wantErr && err == nil:
+				t.Fatalf("got nil error, want error")
+			case !v.wantErr && err != nil:
+				t.Fatalf("got error %v, want nil", err)
+			}
+			if addr != v.addr || remoteDNS != v.remoteDNS {
+				t.Fatalf("got %v %v, want %v %v", addr, remoteDNS,
+					v.addr, v.remoteDNS)
+			}
+		})
+	}
+}
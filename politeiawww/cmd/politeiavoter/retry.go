@@ -147,6 +147,11 @@ func (c *ctx) retryLoop() {
 			return
 		}
 
+		// A ticket that has already voted is reconciled into the
+		// success journal since its vote has been recorded.
+		if alreadyVoted(*vr) {
+			fmt.Printf("Retry vote already cast: %v\n", vr.Ticket)
+		}
 		err = c.jsonLog("success.json", e.vote.Token, vr)
 		if err != nil {
 			log.Errorf("retryLoop: c.jsonLog 3: %v", err)
@@ -21,6 +21,10 @@ const FileContents = `[Application Options]
 ; appdata=$LOCALAPPDATA/Politeiavoter                 ; Windows
 ; appdata=~/Library/Application Support/Politeiavoter ; macOS
 
+; Refuse to run when the application directories or the client key are
+; accessible by other users. By default only a warning is logged.
+; strictperms=1
+
 ; ------------------------------------------------------------------------------
 ; Network settings
 ; ------------------------------------------------------------------------------
@@ -30,11 +34,63 @@ const FileContents = `[Application Options]
 
 ; Connect via a SOCKS5 proxy. This is required when trickling votes.
 ; The SOCKS5 proxy is assumed to be Tor (https://www.torproject.org).
+; The proxy can be provided as host:port or as a socks5h:// URL, in which case
+; the politeiawww host name is resolved by the proxy and all local DNS lookups
+; are disabled. Only the hosts file is consulted locally, so the wallethost
+; must be an IP address or a name in the hosts file. A socks5:// URL resolves
+; the host name locally, which leaks the lookup outside of the proxy, and
+; requires bypassproxycheck. IPv6 proxy addresses must be enclosed in square
+; brackets.
 ; trickle=1
 ; proxy=127.0.0.1:9050
+; proxy=socks5h://[::1]:9050
 ; proxyuser=
 ; proxypass=
 
+; Only connect to politeiawww using IPv4 or IPv6. These can't be used with a
+; socks5h proxy since the proxy decides which address to connect to.
+; ipv4=1
+; ipv6=1
+
+; The expected politeiawww identity public key. When set, politeiavoter refuses
+; to vote if the server returns a different identity. This is required when
+; politeiawww is a Tor onion service, in which case the proxy is also required
+; and all DNS lookups are performed by the proxy.
+; politeiawww=http://xxxxxxxx.onion/api
+; serverpubkey=
+
+; Path of a unix socket that returns the trickle vote progress as JSON. The
+; progress includes the moving average cast rate and the estimated completion
+; time.
+; progresssocket=~/.politeiavoter/progress.sock
+
+; Path of a file that exists for the duration of a trickle vote run. Ticket
+; purchases during a run can lock the wallet accounts and spend the outputs
+; that the votes rely on, which makes the signing of the votes fail. A ticket
+; buyer, or the script that runs it, must not purchase tickets while the file
+; exists. The file contains the politeiavoter process ID so that a stale file
+; can be detected.
+; ticketbuyerpause=~/.politeiavoter/ticketbuyer.pause
+
+; Pad cast ballot requests to a multiple of this many bytes so that network
+; observers can't infer the ballot contents from the request size. The ballot
+; jitter adds a random delay of up to the provided duration before each
+; trickled ballot is sent.
+; ballotpadding=1024
+; ballotjitter=30s
+
+; Path to a vote map file. Each line contains a ticket hash and the vote option
+; ID that the ticket should vote. Tickets that are not in the vote map vote the
+; option that is provided to the vote command.
+; votemap=~/.politeiavoter/votemap.txt
+
+; URL of a signed release manifest. When set, politeiavoter warns at startup if
+; it is outdated or is not compatible with the server API version. The manifest
+; signature is verified using the release signing public key. Nothing is ever
+; installed automatically.
+; updatemanifest=
+; updatepubkey=
+
 ; ------------------------------------------------------------------------------
 ; Wallet
 ; ------------------------------------------------------------------------------
@@ -0,0 +1,166 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+const (
+	// statsInterval is the interval at which the vote progress is
+	// sampled and printed.
+	statsInterval = time.Minute
+
+	// statsWindow is the number of samples that are used to calculate
+	// the moving average cast rate and the retry backlog trend.
+	statsWindow = 10
+)
+
+// statsSample is a sample of the vote progress at a point in time.
+type statsSample struct {
+	t     time.Time
+	cast  int
+	retry int
+}
+
+// voteStats contains the vote progress. This is a JSON structure so that it
+// can be written to the progress socket.
+type voteStats struct {
+	Timestamp  int64   `json:"timestamp"`  // UNIX time of the sample
+	Total      int     `json:"total"`      // Total votes being cast
+	Cast       int     `json:"cast"`       // Votes that have been cast
+	Queued     int     `json:"queued"`     // Votes that have not been cast yet
+	Retry      int     `json:"retry"`      // Votes waiting to be retried
+	RetryTrend int     `json:"retrytrend"` // Retry backlog change in window
+	Rate       float64 `json:"rate"`       // Votes per minute, moving average
+	ETA        int64   `json:"eta"`        // UNIX time of estimated completion
+}
+
+// String returns a human readable representation of the vote progress.
+func (s voteStats) String() string {
+	eta := "unknown"
+	if s.ETA != 0 {
+		eta = time.Unix(s.ETA, 0).Format(time.Stamp)
+	}
+	return fmt.Sprintf("Progress: %v/%v cast, %v queued, %v retry (%+d), "+
+		"%.2f votes/min, eta %v", s.Cast, s.Total, s.Queued, s.Retry,
+		s.RetryTrend, s.Rate, eta)
+}
+
+// statsUpdate samples the current vote progress, updates the moving averages,
+// and returns the new vote stats. It must only be called by the stats handler.
+func (c *ctx) statsUpdate() voteStats {
+	now := time.Now()
+
+	// Sample the queues. The remaining schedule is the sum of the
+	// trickle delays of the votes that have not been cast yet.
+	c.Lock()
+	var (
+		cast     = len(c.ballotResults)
+		queued   = c.voteIntervalQ.Len()
+		retry    = c.retryQ.Len()
+		schedule time.Duration
+	)
+	for e := c.voteIntervalQ.Front(); e != nil; e = e.Next() {
+		schedule += e.Value.(*voteInterval).At
+	}
+	c.Unlock()
+
+	c.statsSamples = append(c.statsSamples, statsSample{
+		t:     now,
+		cast:  cast,
+		retry: retry,
+	})
+	if len(c.statsSamples) > statsWindow+1 {
+		c.statsSamples = c.statsSamples[1:]
+	}
+
+	// Calculate the moving average cast rate and the retry trend
+	// using the oldest sample in the window.
+	var (
+		oldest  = c.statsSamples[0]
+		elapsed = now.Sub(oldest.t).Minutes()
+		rate    float64
+	)
+	if elapsed > 0 {
+		rate = float64(cast-oldest.cast) / elapsed
+	}
+
+	// The estimated completion time is the remaining trickle schedule
+	// plus the time it takes to clear the retry backlog at the current
+	// cast rate.
+	var eta int64
+	switch {
+	case queued == 0 && retry == 0:
+		eta = now.Unix()
+	case retry == 0:
+		eta = now.Add(schedule).Unix()
+	case rate > 0:
+		backlog := time.Duration(float64(retry) / rate * float64(time.Minute))
+		eta = now.Add(schedule + backlog).Unix()
+	}
+
+	s := voteStats{
+		Timestamp:  now.Unix(),
+		Total:      cast + queued + retry,
+		Cast:       cast,
+		Queued:     queued,
+		Retry:      retry,
+		RetryTrend: retry - oldest.retry,
+		Rate:       rate,
+		ETA:        eta,
+	}
+
+	c.Lock()
+	c.statsLast = s
+	c.Unlock()
+
+	return s
+}
+
+// progressListen writes the most recent vote stats as JSON to every
+// connection that is made to the progress socket. The socket is removed when
+// the wallet context is canceled.
+func (c *ctx) progressListen(path string) {
+	// Remove a stale socket from a previous run
+	_ = os.Remove(path)
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		log.Errorf("progressListen: %v", err)
+		return
+	}
+	go func() {
+		<-c.wctx.Done()
+		l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			select {
+			case <-c.wctx.Done():
+				return
+			default:
+			}
+			log.Errorf("progressListen: accept: %v", err)
+			continue
+		}
+
+		c.RLock()
+		s := c.statsLast
+		c.RUnlock()
+
+		err = json.NewEncoder(conn).Encode(s)
+		if err != nil {
+			log.Debugf("progressListen: encode: %v", err)
+		}
+		conn.Close()
+	}
+}
@@ -1,4 +1,4 @@
-// Copyright (c) 2020 The Decred developers
+// Copyright (c) 2020-2021 The Decred developers
 // Use of this source code is governed by an ISC
 // license that can be found in the LICENSE file.
 //
@@ -11,6 +11,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 func (c *ctx) statsHandler() {
@@ -19,6 +20,15 @@ func (c *ctx) statsHandler() {
 	signalsDone := make(chan struct{}, 1)
 	signal.Notify(signalsChan, []os.Signal{syscall.SIGUSR1}...)
 
+	// Launch progress socket
+	if c.cfg.ProgressSocket != "" {
+		go c.progressListen(c.cfg.ProgressSocket)
+	}
+
+	ticker := time.NewTicker(statsInterval)
+	defer ticker.Stop()
+	c.statsUpdate()
+
 	for {
 		select {
 		case <-c.wctx.Done():
@@ -26,8 +36,11 @@ func (c *ctx) statsHandler() {
 			signal.Stop(signalsChan)
 			close(signalsDone)
 			return
+		case <-ticker.C:
+			fmt.Printf("%v\n", c.statsUpdate())
 		case <-signalsChan:
 			fmt.Printf("----- politeiavoter status -----\n")
+			fmt.Printf("%v\n", c.statsUpdate())
 			c.dumpTogo()
 			c.dumpComplete()
 			c.dumpQueue()
@@ -1,4 +1,4 @@
-// Copyright (c) 2020 The Decred developers
+// Copyright (c) 2020-2021 The Decred developers
 // Use of this source code is governed by an ISC
 // license that can be found in the LICENSE file.
 //
@@ -6,5 +6,27 @@
 
 package main
 
+import (
+	"fmt"
+	"time"
+)
+
 func (c *ctx) statsHandler() {
+	// Launch progress socket
+	if c.cfg.ProgressSocket != "" {
+		go c.progressListen(c.cfg.ProgressSocket)
+	}
+
+	ticker := time.NewTicker(statsInterval)
+	defer ticker.Stop()
+	c.statsUpdate()
+
+	for {
+		select {
+		case <-c.wctx.Done():
+			return
+		case <-ticker.C:
+			fmt.Printf("%v\n", c.statsUpdate())
+		}
+	}
 }
@@ -0,0 +1,106 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// ticketBuyerPause is the content of the ticket buyer pause file.
+//
+// dcrwallet does not provide a RPC that pauses its ticket buyer, so the pause
+// is coordinated using a file. A ticket buyer, or the script that runs it,
+// must not purchase tickets while the file exists and the process that created
+// it is still running. Purchases unlock wallet accounts and spend outputs that
+// the trickled votes rely on, which has caused SignMessages failures in the
+// middle of a run. See the README for the contract with the ticket buyer.
+type ticketBuyerPause struct {
+	PID     int    `json:"pid"`     // politeiavoter process ID
+	Token   string `json:"token"`   // Proposal token
+	Started int64  `json:"started"` // Unix timestamp of the run start
+}
+
+// pauseTicketBuyer creates the ticket buyer pause file. It returns whether
+// this run created the file. The file is left alone when it already exists
+// and belongs to a run that is in progress, and is then not removed by this
+// run. A file that was left behind by a run that is no longer running, e.g.
+// because it was killed, is replaced.
+func pauseTicketBuyer(path, token string) (bool, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, os.ErrExist) {
+		var stale bool
+		stale, err = ticketBuyerPauseStale(path)
+		if err != nil {
+			return false, err
+		}
+		if !stale {
+			log.Infof("Ticket buyer pause file already exists: %v", path)
+			return false, nil
+		}
+		log.Infof("Removing stale ticket buyer pause file: %v", path)
+		err = os.Remove(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return false, err
+		}
+		f, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, os.ErrExist) {
+			// Another run created the file in the meantime
+			log.Infof("Ticket buyer pause file already exists: %v", path)
+			return false, nil
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	err = json.NewEncoder(f).Encode(ticketBuyerPause{
+		PID:     os.Getpid(),
+		Token:   token,
+		Started: time.Now().Unix(),
+	})
+	if err != nil {
+		os.Remove(path)
+		return false, err
+	}
+
+	fmt.Printf("Ticket buyer paused  : %v\n", path)
+	return true, nil
+}
+
+// ticketBuyerPauseStale returns whether the ticket buyer pause file was left
+// behind by a politeiavoter process that is no longer running. A file that
+// can not be decoded is not considered stale since it may be in the process
+// of being written or may have been created by a different tool.
+func ticketBuyerPauseStale(path string) (bool, error) {
+	b, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		// The file was removed by the run that created it
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	var tbp ticketBuyerPause
+	err = json.Unmarshal(b, &tbp)
+	if err != nil || tbp.PID <= 0 {
+		return false, nil
+	}
+	return !processIsRunning(tbp.PID), nil
+}
+
+// resumeTicketBuyer removes the ticket buyer pause file.
+func resumeTicketBuyer(path string) error {
+	err := os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	fmt.Printf("Ticket buyer resumed\n")
+	return nil
+}
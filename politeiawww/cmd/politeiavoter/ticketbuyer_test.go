@@ -0,0 +1,116 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestPauseTicketBuyer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ticketbuyer.pause")
+
+	paused, err := pauseTicketBuyer(path, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !paused {
+		t.Fatalf("ticket buyer not paused")
+	}
+
+	// A run must not take over a pause file that already exists
+	paused, err = pauseTicketBuyer(path, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paused {
+		t.Fatalf("existing pause file was taken over")
+	}
+
+	err = resumeTicketBuyer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("pause file not removed: %v", err)
+	}
+}
+
+func TestPauseTicketBuyerStale(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ticketbuyer.pause")
+
+	// writePause writes a pause file that was created by the provided
+	// process.
+	writePause := func(pid int) {
+		t.Helper()
+		b, err := json.Marshal(ticketBuyerPause{
+			PID:   pid,
+			Token: "other",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(path, b, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The pause file of a run that is still running is left alone
+	writePause(os.Getpid())
+	paused, err := pauseTicketBuyer(path, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paused {
+		t.Fatalf("pause file of a running process was taken over")
+	}
+
+	// The pause file of a run that is no longer running is replaced.
+	// The test binary is run without any tests to get the ID of a
+	// process that has exited.
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	err = cmd.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePause(cmd.Process.Pid)
+	paused, err = pauseTicketBuyer(path, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !paused {
+		t.Fatalf("stale pause file was not replaced")
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tbp ticketBuyerPause
+	err = json.Unmarshal(b, &tbp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tbp.PID != os.Getpid() || tbp.Token != "token" {
+		t.Fatalf("got pause file %+v", tbp)
+	}
+
+	// A pause file that can not be decoded is left alone
+	err = ioutil.WriteFile(path, nil, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paused, err = pauseTicketBuyer(path, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paused {
+		t.Fatalf("undecodable pause file was taken over")
+	}
+}
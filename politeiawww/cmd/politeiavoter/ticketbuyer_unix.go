@@ -0,0 +1,20 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+//
+// +build !windows
+
+package main
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processIsRunning returns whether a process with the provided ID is running.
+// A process that is owned by another user can not be signaled but is still
+// running.
+func processIsRunning(pid int) bool {
+	err := syscall.Kill(pid, syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
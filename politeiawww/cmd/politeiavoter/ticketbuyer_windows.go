@@ -0,0 +1,21 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+//
+// +build windows
+
+package main
+
+import "os"
+
+// processIsRunning returns whether a process with the provided ID is running.
+// FindProcess opens a handle to the process on Windows, which fails when the
+// process does not exist.
+func processIsRunning(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
@@ -0,0 +1,260 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+
+	pb "decred.org/dcrwallet/rpc/walletrpc"
+	"github.com/decred/dcrd/blockchain/stake/v3"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+)
+
+// vspFeeStatuses contains the human readable VSP fee statuses that are
+// reported for a ticket.
+var vspFeeStatuses = map[pb.GetVSPTicketsByFeeStatusRequest_FeeStatus]string{
+	pb.GetVSPTicketsByFeeStatusRequest_VSP_FEE_PROCESS_STARTED:   "fee started",
+	pb.GetVSPTicketsByFeeStatusRequest_VSP_FEE_PROCESS_PAID:      "fee paid",
+	pb.GetVSPTicketsByFeeStatusRequest_VSP_FEE_PROCESS_ERRORED:   "fee errored",
+	pb.GetVSPTicketsByFeeStatusRequest_VSP_FEE_PROCESS_CONFIRMED: "fee confirmed",
+}
+
+// liveTicket contains the details of a live wallet ticket.
+type liveTicket struct {
+	hash       string
+	address    string // Commitment address
+	account    uint32
+	vspStatus  string
+	addressErr error    // Commitment address lookup error
+	eligible   []string // Tokens of the active votes the ticket can vote in
+	votedIn    []string // Tokens of the active votes the ticket has voted in
+}
+
+// liveTickets returns the hashes of all live tickets in the wallet.
+func (c *ctx) liveTickets() ([]*chainhash.Hash, error) {
+	ar, err := c.wallet.Accounts(c.wctx, &pb.AccountsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	stream, err := c.wallet.GetTickets(c.wctx, &pb.GetTicketsRequest{
+		StartingBlockHeight: 0,
+		EndingBlockHeight:   ar.CurrentBlockHeight,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tickets := make([]*chainhash.Hash, 0, 256)
+	for {
+		r, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if r.Ticket == nil || r.Ticket.Ticket == nil ||
+			r.Ticket.TicketStatus != pb.GetTicketsResponse_TicketDetails_LIVE {
+			continue
+		}
+		h, err := chainhash.NewHash(r.Ticket.Ticket.Hash)
+		if err != nil {
+			return nil, err
+		}
+		tickets = append(tickets, h)
+	}
+
+	return tickets, nil
+}
+
+// commitmentAddress returns the commitment address of a wallet ticket and
+// the wallet account that the address belongs to.
+func (c *ctx) commitmentAddress(h *chainhash.Hash) (string, uint32, error) {
+	r, err := c.wallet.GetTransaction(c.wctx, &pb.GetTransactionRequest{
+		TransactionHash: h[:],
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	tx := new(wire.MsgTx)
+	err = tx.Deserialize(bytes.NewReader(r.Transaction.Transaction))
+	if err != nil {
+		return "", 0, err
+	}
+	if len(tx.TxOut) < 2 {
+		return "", 0, fmt.Errorf("not a ticket")
+	}
+	addr, err := stake.AddrFromSStxPkScrCommitment(tx.TxOut[1].PkScript,
+		activeNetParams.Params)
+	if err != nil {
+		return "", 0, err
+	}
+	vr, err := c.wallet.ValidateAddress(c.wctx, &pb.ValidateAddressRequest{
+		Address: addr.String(),
+	})
+	if err != nil {
+		return "", 0, err
+	}
+
+	return addr.String(), vr.AccountNumber, nil
+}
+
+// vspStatuses returns the VSP fee status of all wallet tickets that are
+// managed by a VSP. An error is returned if the wallet does not support VSP
+// ticket queries.
+func (c *ctx) vspStatuses() (map[string]string, error) {
+	statuses := make(map[string]string, 256)
+	for status, desc := range vspFeeStatuses {
+		r, err := c.wallet.GetVSPTicketsByFeeStatus(c.wctx,
+			&pb.GetVSPTicketsByFeeStatusRequest{
+				FeeStatus: status,
+			})
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range r.TicketsHashes {
+			h, err := chainhash.NewHash(v)
+			if err != nil {
+				return nil, err
+			}
+			statuses[h.String()] = desc
+		}
+	}
+	return statuses, nil
+}
+
+// tickets lists the live tickets of the wallet by account along with their
+// commitment addresses, their VSP fee status, and their eligibility in the
+// active votes. This allows a user to verify the tickets that politeiavoter
+// is able to vote with prior to voting.
+func (c *ctx) tickets() error {
+	hashes, err := c.liveTickets()
+	if err != nil {
+		return fmt.Errorf("live tickets: %v", err)
+	}
+	if len(hashes) == 0 {
+		fmt.Printf("No live tickets found.\n")
+		return nil
+	}
+
+	// Lookup the VSP status of the tickets. Older wallets do not
+	// support VSP ticket queries.
+	vsp, err := c.vspStatuses()
+	if err != nil {
+		log.Debugf("vspStatuses: %v", err)
+		vsp = nil
+	}
+
+	tickets := make(map[string]*liveTicket, len(hashes))
+	for _, h := range hashes {
+		t := liveTicket{
+			hash:      h.String(),
+			vspStatus: "unknown",
+		}
+		if vsp != nil {
+			t.vspStatus = "none"
+			if s, ok := vsp[t.hash]; ok {
+				t.vspStatus = s
+			}
+		}
+		t.address, t.account, t.addressErr = c.commitmentAddress(h)
+		tickets[t.hash] = &t
+	}
+
+	// Check the ticket eligibility against the active votes
+	version, err := c.getVersion()
+	if err != nil {
+		return err
+	}
+	tokens, err := c.startedVotes()
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		dr, err := c.voteDetails(token, version.PubKey)
+		if err != nil {
+			return err
+		}
+		rr, err := c.voteResults(token, version.PubKey)
+		if err != nil {
+			return err
+		}
+		voted := make(map[string]struct{}, len(rr.Votes))
+		for _, v := range rr.Votes {
+			voted[v.Ticket] = struct{}{}
+		}
+		for _, v := range dr.Vote.EligibleTickets {
+			t, ok := tickets[v]
+			if !ok {
+				continue
+			}
+			if _, ok := voted[v]; ok {
+				t.votedIn = append(t.votedIn, token)
+				continue
+			}
+			t.eligible = append(t.eligible, token)
+		}
+	}
+
+	// Group the tickets by account
+	ar, err := c.wallet.Accounts(c.wctx, &pb.AccountsRequest{})
+	if err != nil {
+		return err
+	}
+	names := make(map[uint32]string, len(ar.Accounts))
+	for _, v := range ar.Accounts {
+		names[v.AccountNumber] = v.AccountName
+	}
+	accounts := make(map[uint32][]*liveTicket, len(ar.Accounts))
+	for _, t := range tickets {
+		accounts[t.account] = append(accounts[t.account], t)
+	}
+	numbers := make([]uint32, 0, len(accounts))
+	for k := range accounts {
+		numbers = append(numbers, k)
+	}
+	sort.Slice(numbers, func(i, j int) bool {
+		return numbers[i] < numbers[j]
+	})
+
+	// Print tickets
+	for _, n := range numbers {
+		ts := accounts[n]
+		sort.Slice(ts, func(i, j int) bool {
+			return ts[i].hash < ts[j].hash
+		})
+		name, ok := names[n]
+		if !ok {
+			name = "unknown"
+		}
+		fmt.Printf("Account: %v (%v)\n", name, n)
+		for _, t := range ts {
+			fmt.Printf("  Ticket: %v\n", t.hash)
+			if t.addressErr != nil {
+				fmt.Printf("    Commitment address: %v\n", t.addressErr)
+			} else {
+				fmt.Printf("    Commitment address: %v\n", t.address)
+			}
+			fmt.Printf("    VSP status        : %v\n", t.vspStatus)
+			for _, v := range t.eligible {
+				fmt.Printf("    Eligible          : %v\n", v)
+			}
+			for _, v := range t.votedIn {
+				fmt.Printf("    Voted             : %v\n", v)
+			}
+			if len(t.eligible) == 0 && len(t.votedIn) == 0 {
+				fmt.Printf("    Eligible          : none\n")
+			}
+		}
+	}
+	fmt.Printf("Live tickets: %v\n", len(tickets))
+	fmt.Printf("Active votes: %v\n", len(tokens))
+
+	return nil
+}
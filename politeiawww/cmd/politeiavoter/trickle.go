@@ -1,21 +1,15 @@
 package main
 
 import (
-	"encoding/hex"
 	"fmt"
-	"sort"
 	"time"
 
-	"crypto/rand"
-
-	pb "decred.org/dcrwallet/rpc/walletrpc"
-	"github.com/decred/dcrd/chaincfg/chainhash"
 	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
-	"github.com/decred/politeia/politeiawww/cmd/politeiavoter/uniformprng"
+	"github.com/decred/politeia/politeiawww/client/voter"
 )
 
-func (c *ctx) calculateTrickle(token, voteBit string, ctres *pb.CommittedTicketsResponse, smr *pb.SignMessagesResponse) error {
-	votes := len(ctres.TicketAddresses)
+func (c *ctx) calculateTrickle(token string, cv []tkv1.CastVote) error {
+	votes := len(cv)
 	duration := c.cfg.voteDuration
 	voteDuration := duration - time.Hour
 	if voteDuration < time.Hour {
@@ -25,38 +19,26 @@ func (c *ctx) calculateTrickle(token, voteBit string, ctres *pb.CommittedTickets
 	fmt.Printf("Total vote duration  : %v\n", duration)
 	fmt.Printf("Duration calculated  : %v\n", voteDuration)
 
-	prng, err := uniformprng.RandSource(rand.Reader)
+	intervals, err := voter.Schedule(cv, voteDuration)
 	if err != nil {
 		return err
 	}
 
-	ts := make([]time.Duration, 0, votes)
-	for i := 0; i < votes; i++ {
-		ts = append(ts, time.Duration(prng.Int63n(int64(voteDuration))))
+	// Print the privacy analysis of the schedule
+	ts := make([]time.Duration, 0, len(intervals))
+	var t time.Duration
+	for _, v := range intervals {
+		t += v.At
+		ts = append(ts, t)
 	}
-	sort.Slice(ts, func(i, j int) bool { return ts[i] < ts[j] })
-	var previous, t time.Duration
-
-	buckets := make([]*voteInterval, votes)
-	for k := range ts {
-		// Assemble missing vote bits
-		h, err := chainhash.NewHash(ctres.TicketAddresses[k].Ticket)
-		if err != nil {
-			return err
-		}
-		signature := hex.EncodeToString(smr.Replies[k].Signature)
+	fmt.Print(analyzeSchedule(ts, c.voteWindow, c.cfg.Proxy != ""))
 
-		buckets[k] = &voteInterval{
-			Vote: tkv1.CastVote{
-				Token:     token,
-				Ticket:    h.String(),
-				VoteBit:   voteBit,
-				Signature: signature,
-			},
-			At: ts[k] - previous, // Delta to previous timestamp
-		}
-		t += ts[k] - previous
-		previous = ts[k]
+	buckets := make([]*voteInterval, 0, len(intervals))
+	for _, v := range intervals {
+		buckets = append(buckets, &voteInterval{
+			Vote: v.Vote,
+			At:   v.At,
+		})
 	}
 
 	// Should not happen
@@ -66,10 +48,9 @@ func (c *ctx) calculateTrickle(token, voteBit string, ctres *pb.CommittedTickets
 	}
 
 	// Sanity
-	if len(buckets) != len(ctres.TicketAddresses) {
+	if len(buckets) != votes {
 		return fmt.Errorf("unexpected time bucket count got "+
-			"%v, wanted %v", len(ctres.TicketAddresses),
-			len(buckets))
+			"%v, wanted %v", len(buckets), votes)
 	}
 
 	// Convert buckets to a list
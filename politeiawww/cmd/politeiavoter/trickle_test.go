@@ -2,34 +2,26 @@ package main
 
 import (
 	"container/list"
+	"encoding/hex"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
-	pb "decred.org/dcrwallet/rpc/walletrpc"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
 )
 
-func fakeTickets(x int) (*pb.CommittedTicketsResponse, *pb.SignMessagesResponse) {
-	ctres := pb.CommittedTicketsResponse{
-		TicketAddresses: make([]*pb.CommittedTicketsResponse_TicketAddress, x),
-	}
-	for k := range ctres.TicketAddresses {
-		ctres.TicketAddresses[k] = &pb.CommittedTicketsResponse_TicketAddress{
-			Ticket: make([]byte, 32),
-		}
-	}
-	smr := pb.SignMessagesResponse{
-		Replies: make([]*pb.SignMessagesResponse_SignReply, x),
-	}
-	for k := range smr.Replies {
-		smr.Replies[k] = &pb.SignMessagesResponse_SignReply{
-			Signature: make([]byte, 64),
+func fakeVotes(x int) []tkv1.CastVote {
+	votes := make([]tkv1.CastVote, x)
+	for k := range votes {
+		votes[k] = tkv1.CastVote{
+			Ticket:    hex.EncodeToString(make([]byte, 32)),
+			Signature: hex.EncodeToString(make([]byte, 64)),
 		}
 	}
 
-	return &ctres, &smr
+	return votes
 }
 
 func fakeCtx(t *testing.T, d time.Duration, x int) (*ctx, func()) {
@@ -60,8 +52,7 @@ func TestTrickleNotEnoughTime(t *testing.T) {
 	c, cleanup := fakeCtx(t, time.Hour, x)
 	defer cleanup()
 
-	ctres, smr := fakeTickets(x)
-	err := c.calculateTrickle("", "", ctres, smr)
+	err := c.calculateTrickle("", fakeVotes(x))
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -72,8 +63,7 @@ func TestTrickle2(t *testing.T) {
 	c, cleanup := fakeCtx(t, 24*time.Hour, x)
 	defer cleanup()
 
-	ctres, smr := fakeTickets(x)
-	err := c.calculateTrickle("", "", ctres, smr)
+	err := c.calculateTrickle("", fakeVotes(x))
 	if err != nil {
 		t.Fatal(err)
 	}
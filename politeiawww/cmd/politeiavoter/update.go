@@ -0,0 +1,187 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	v1 "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/util"
+	"github.com/decred/politeia/util/version"
+)
+
+const (
+	// manifestMaxSize is the maximum size of a release manifest.
+	manifestMaxSize = 1 << 16
+)
+
+// releaseManifest describes the latest politeiavoter release.
+type releaseManifest struct {
+	Version     string `json:"version"`     // Latest release
+	MinVersion  string `json:"minversion"`  // Oldest supported release
+	APIVersions []uint `json:"apiversions"` // Supported www API versions
+}
+
+// signedManifest is the format of a release manifest file. The signature is
+// the hex encoded ed25519 signature of the raw bytes of the manifest field.
+type signedManifest struct {
+	Manifest  json.RawMessage `json:"manifest"`
+	Signature string          `json:"signature"`
+}
+
+// decodeManifest verifies the signature of a signed release manifest using
+// the provided release public key and returns the decoded manifest.
+func decodeManifest(b []byte, pubKey *identity.PublicIdentity) (*releaseManifest, error) {
+	var sm signedManifest
+	err := json.Unmarshal(b, &sm)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest: %v", err)
+	}
+	sig, err := util.ConvertSignature(sm.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest signature: %v", err)
+	}
+	if !pubKey.VerifyMessage(sm.Manifest, sig) {
+		return nil, fmt.Errorf("manifest signature verification failed")
+	}
+
+	var m releaseManifest
+	err = json.Unmarshal(sm.Manifest, &m)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest: %v", err)
+	}
+	if _, err := parseVersion(m.Version); err != nil {
+		return nil, fmt.Errorf("invalid manifest version: %v", err)
+	}
+	if _, err := parseVersion(m.MinVersion); err != nil {
+		return nil, fmt.Errorf("invalid manifest min version: %v", err)
+	}
+
+	return &m, nil
+}
+
+// parseVersion parses a major.minor.patch version string. Any pre-release or
+// build metadata is ignored.
+func parseVersion(s string) ([3]uint, error) {
+	var v [3]uint
+	s = strings.TrimPrefix(s, "v")
+	if i := strings.IndexAny(s, "-+"); i != -1 {
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return v, fmt.Errorf("invalid version '%v'", s)
+	}
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return v, fmt.Errorf("invalid version '%v'", s)
+		}
+		v[i] = uint(n)
+	}
+	return v, nil
+}
+
+// versionLess returns whether version a precedes version b.
+func versionLess(a, b [3]uint) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// updateWarnings returns the warnings for a local politeiavoter version and
+// server www API version when compared against the provided release manifest.
+func updateWarnings(m releaseManifest, local [3]uint, serverAPI uint) []string {
+	var (
+		warnings []string
+		latest   = m.Version
+	)
+	latestV, _ := parseVersion(m.Version)
+	minV, _ := parseVersion(m.MinVersion)
+	switch {
+	case versionLess(local, minV):
+		warnings = append(warnings, fmt.Sprintf("politeiavoter is no "+
+			"longer supported; upgrade to %v", latest))
+	case versionLess(local, latestV):
+		warnings = append(warnings, fmt.Sprintf("politeiavoter %v is "+
+			"available", latest))
+	}
+
+	if serverAPI != v1.PoliteiaWWWAPIVersion {
+		w := fmt.Sprintf("server API version %v is not supported by "+
+			"this politeiavoter", serverAPI)
+		for _, v := range m.APIVersions {
+			if v == serverAPI {
+				w += fmt.Sprintf("; upgrade to %v", latest)
+				break
+			}
+		}
+		warnings = append(warnings, w)
+	}
+
+	return warnings
+}
+
+// updateCheck fetches the signed release manifest and warns when the local
+// politeiavoter is outdated or is not compatible with the server API version.
+// Nothing is ever installed. The check is advisory, so failures are reported
+// as warnings.
+func (c *ctx) updateCheck() {
+	err := c._updateCheck()
+	if err != nil {
+		fmt.Printf("Warning: update check failed: %v\n", err)
+	}
+}
+
+func (c *ctx) _updateCheck() error {
+	pubKey, err := util.IdentityFromString(c.cfg.UpdatePubKey)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(c.wctx, http.MethodGet,
+		c.cfg.UpdateManifest, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	r, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return fmt.Errorf("manifest request: %v", r.Status)
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(r.Body, manifestMaxSize))
+	if err != nil {
+		return err
+	}
+	m, err := decodeManifest(b, pubKey)
+	if err != nil {
+		return err
+	}
+
+	vr, err := c.getVersion()
+	if err != nil {
+		return err
+	}
+	local := [3]uint{version.Major, version.Minor, version.Patch}
+	for _, w := range updateWarnings(*m, local, vr.Version) {
+		fmt.Printf("Warning: %v\n", w)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+)
+
+func TestDecodeManifest(t *testing.T) {
+	id, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := []byte(`{"version":"1.4.0","minversion":"1.3.0","apiversions":[1]}`)
+	sig := id.SignMessage(m)
+	b, err := json.Marshal(signedManifest{
+		Manifest:  m,
+		Signature: hex.EncodeToString(sig[:]),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Valid signature
+	rm, err := decodeManifest(b, &id.Public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rm.Version != "1.4.0" || rm.MinVersion != "1.3.0" {
+		t.Fatalf("unexpected manifest %+v", rm)
+	}
+
+	// Wrong public key
+	other, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = decodeManifest(b, &other.Public)
+	if err == nil {
+		t.Fatal("expected signature verification failure")
+	}
+}
+
+func TestUpdateWarnings(t *testing.T) {
+	m := releaseManifest{
+		Version:     "1.4.0",
+		MinVersion:  "1.3.0",
+		APIVersions: []uint{1, 2},
+	}
+	var tests = []struct {
+		name      string
+		local     [3]uint
+		serverAPI uint
+		warnings  int
+	}{
+		{"current", [3]uint{1, 4, 0}, 1, 0},
+		{"newer", [3]uint{1, 5, 0}, 1, 0},
+		{"outdated", [3]uint{1, 3, 2}, 1, 1},
+		{"unsupported", [3]uint{1, 2, 9}, 1, 1},
+		{"incompatible", [3]uint{1, 4, 0}, 2, 1},
+		{"outdated incompatible", [3]uint{1, 3, 0}, 2, 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := updateWarnings(m, test.local, test.serverAPI)
+			if len(w) != test.warnings {
+				t.Fatalf("got %v warnings %v, want %v", len(w), w,
+					test.warnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+)
+
+// loadVoteMap reads a vote map file and returns the vote option ID for each
+// ticket hash in the file.
+//
+// The vote map file contains one ticket per line. Each line contains a
+// ticket hash followed by the vote option ID that the ticket should vote,
+// separated by whitespace. Blank lines and lines starting with a '#' are
+// ignored.
+//
+//	# Client A
+//	<ticket hash> yes
+//	# Client B
+//	<ticket hash> no
+func loadVoteMap(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		voteMap = make(map[string]string, 256)
+		scanner = bufio.NewScanner(f)
+		line    int
+	)
+	for scanner.Scan() {
+		line++
+		l := strings.TrimSpace(scanner.Text())
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		fields := strings.Fields(l)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %v: expected '<ticket> "+
+				"<vote option>', got '%v'", line, l)
+		}
+		ticket, voteID := fields[0], fields[1]
+		_, err := chainhash.NewHashFromStr(ticket)
+		if err != nil || len(ticket) != chainhash.MaxHashStringSize {
+			return nil, fmt.Errorf("line %v: invalid ticket hash '%v'",
+				line, ticket)
+		}
+		if _, ok := voteMap[ticket]; ok {
+			return nil, fmt.Errorf("line %v: duplicate ticket %v",
+				line, ticket)
+		}
+		voteMap[ticket] = voteID
+	}
+	err = scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+	if len(voteMap) == 0 {
+		return nil, fmt.Errorf("no tickets found")
+	}
+
+	return voteMap, nil
+}
+
+// voteMapBits converts the vote option IDs in the configured vote map to the
+// vote bits of the provided vote options. An error is returned if the vote
+// map contains a vote option ID that is not part of the vote.
+func (c *ctx) voteMapBits(options []tkv1.VoteOption) (map[string]string, error) {
+	if len(c.cfg.voteMap) == 0 {
+		return nil, nil
+	}
+
+	bits := make(map[string]string, len(options))
+	for _, v := range options {
+		bits[v.ID] = strconv.FormatUint(v.Bit, 16)
+	}
+	voteBits := make(map[string]string, len(c.cfg.voteMap))
+	for ticket, voteID := range c.cfg.voteMap {
+		bit, ok := bits[voteID]
+		if !ok {
+			return nil, fmt.Errorf("vote map: vote id not found for "+
+				"ticket %v: %v", ticket, voteID)
+		}
+		voteBits[ticket] = bit
+	}
+
+	return voteBits, nil
+}
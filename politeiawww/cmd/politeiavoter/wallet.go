@@ -0,0 +1,118 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	pb "decred.org/dcrwallet/rpc/walletrpc"
+	"github.com/decred/dcrd/blockchain/stake/v3"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+	"github.com/decred/politeia/politeiawww/client/voter"
+)
+
+// voteWallet satisfies the voter Wallet interface using the dcrwallet gRPC
+// connection of the client context.
+type voteWallet struct {
+	c          *ctx
+	passphrase []byte // Wallet passphrase used to sign messages
+}
+
+// CommittedTickets satisfies the voter Wallet interface.
+//
+// Tickets that are tracked by imported xpub accounts are filtered out since
+// the wallet is not able to sign votes for them.
+func (w *voteWallet) CommittedTickets(ctx context.Context, tickets []string) ([]voter.Ticket, error) {
+	tix, err := convertTicketHashes(tickets)
+	if err != nil {
+		return nil, fmt.Errorf("ticket pool corrupt: %v", err)
+	}
+	ctres, err := w.c.wallet.CommittedTickets(ctx,
+		&pb.CommittedTicketsRequest{
+			Tickets: tix,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	committed := make([]voter.Ticket, 0, len(ctres.TicketAddresses))
+	for _, t := range ctres.TicketAddresses {
+		h, err := chainhash.NewHash(t.Ticket)
+		if err != nil {
+			return nil, err
+		}
+
+		// Filter out tickets tracked by imported xpub accounts.
+		r, err := w.c.wallet.GetTransaction(ctx, &pb.GetTransactionRequest{
+			TransactionHash: h[:],
+		})
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		tx := new(wire.MsgTx)
+		err = tx.Deserialize(bytes.NewReader(r.Transaction.Transaction))
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		addr, err := stake.AddrFromSStxPkScrCommitment(tx.TxOut[1].PkScript,
+			activeNetParams.Params)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		vr, err := w.c.wallet.ValidateAddress(ctx, &pb.ValidateAddressRequest{
+			Address: addr.String(),
+		})
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		if vr.AccountNumber >= 1<<31-1 { // imported xpub account
+			continue
+		}
+
+		committed = append(committed, voter.Ticket{
+			Ticket:  h.String(),
+			Address: t.Address,
+		})
+	}
+
+	return committed, nil
+}
+
+// SignMessages satisfies the voter Wallet interface.
+func (w *voteWallet) SignMessages(ctx context.Context, msgs []voter.Message) ([][]byte, error) {
+	sm := &pb.SignMessagesRequest{
+		Passphrase: w.passphrase,
+		Messages:   make([]*pb.SignMessagesRequest_Message, 0, len(msgs)),
+	}
+	for _, v := range msgs {
+		sm.Messages = append(sm.Messages, &pb.SignMessagesRequest_Message{
+			Address: v.Address,
+			Message: v.Message,
+		})
+	}
+	smr, err := w.c.wallet.SignMessages(ctx, sm)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure all signatures worked
+	sigs := make([][]byte, 0, len(smr.Replies))
+	for k, v := range smr.Replies {
+		if v.Error != "" {
+			return nil, fmt.Errorf("signature failed index %v: %v",
+				k, v.Error)
+		}
+		sigs = append(sigs, v.Signature)
+	}
+
+	return sigs, nil
+}
@@ -58,6 +58,9 @@ type Config struct {
 	ClientCert string `long:"clientcert" description:"Path to TLS certificate for client authentication"`
 	ClientKey  string `long:"clientkey" description:"Path to TLS client authentication key"`
 
+	Proxy          string `long:"proxy" description:"Connect via an http or socks5 proxy URL (eg. socks5://127.0.0.1:9050)"`
+	ProxyIsolation bool   `long:"proxyisolation" description:"Use random socks5 proxy credentials for each request (tor stream isolation)"`
+
 	DataDir    string // Application data dir
 	Version    string // CLI version
 	WalletHost string // Wallet host
@@ -13,6 +13,8 @@ import (
 	"os"
 
 	"github.com/decred/politeia/politeiad/api/v1/identity"
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/passwords"
 	"github.com/decred/politeia/util"
 	"golang.org/x/crypto/ed25519"
 	"golang.org/x/crypto/sha3"
@@ -66,6 +68,24 @@ func DigestSHA3(s string) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
+// ValidatePassword verifies that the plain text password satisfies the
+// password policy of the server. politeiawww only receives the digest of the
+// password, so the policy must be enforced before the password is hashed.
+// The user inputs, e.g. the username and email address, are treated as
+// guessable when the strength of the password is estimated.
+func ValidatePassword(pr *www.PolicyReply, password string, userInputs ...string) error {
+	var breachURL string
+	if pr.PasswordBreachCheck {
+		breachURL = passwords.DefaultBreachURL
+	}
+	c := passwords.New(pr.MinPasswordLength, pr.MinPasswordScore, breachURL)
+	err := c.Check(password, userInputs...)
+	if err != nil {
+		return fmt.Errorf("invalid password: %v", err)
+	}
+	return nil
+}
+
 // NewIdentity generates a new FullIdentity using randomly generated data to
 // create the public/private key pair.
 func NewIdentity() (*identity.FullIdentity, error) {
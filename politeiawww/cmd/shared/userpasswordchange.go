@@ -4,11 +4,7 @@
 
 package shared
 
-import (
-	"fmt"
-
-	v1 "github.com/decred/politeia/politeiawww/api/www/v1"
-)
+import v1 "github.com/decred/politeia/politeiawww/api/www/v1"
 
 // UserPasswordChangeCmd changes the password for the logged in user.
 type UserPasswordChangeCmd struct {
@@ -27,9 +23,9 @@ func (cmd *UserPasswordChangeCmd) Execute(args []string) error {
 	}
 
 	// Validate new password
-	if uint(len(cmd.Args.NewPassword)) < pr.MinPasswordLength {
-		return fmt.Errorf("password must be %v characters long",
-			pr.MinPasswordLength)
+	err = ValidatePassword(pr, cmd.Args.NewPassword)
+	if err != nil {
+		return err
 	}
 
 	// Setup change password request
@@ -4,11 +4,7 @@
 
 package shared
 
-import (
-	"fmt"
-
-	www "github.com/decred/politeia/politeiawww/api/www/v1"
-)
+import www "github.com/decred/politeia/politeiawww/api/www/v1"
 
 // UserPasswordResetCmd resets the password of the specified user.
 type UserPasswordResetCmd struct {
@@ -32,9 +28,9 @@ func (cmd *UserPasswordResetCmd) Execute(args []string) error {
 	}
 
 	// Validate new password
-	if uint(len(newPassword)) < pr.MinPasswordLength {
-		return fmt.Errorf("password must be %v characters long",
-			pr.MinPasswordLength)
+	err = ValidatePassword(pr, newPassword, username, email)
+	if err != nil {
+		return err
 	}
 
 	// Reset password
@@ -30,6 +30,8 @@ const (
 	tableNameExchangeRate  = "exchange_rates"
 	tableNamePayments      = "payments"
 	tableNameDCC           = "dcc"
+	tableNameDomain        = "domains"
+	tableNameRateSchedule  = "rate_schedules"
 
 	userPoliteiawww = "politeiawww" // cmsdb user (read/write access)
 )
@@ -736,6 +738,18 @@ func createCmsTables(tx *gorm.DB) error {
 			return err
 		}
 	}
+	if !tx.HasTable(tableNameDomain) {
+		err := tx.CreateTable(&Domain{}).Error
+		if err != nil {
+			return err
+		}
+	}
+	if !tx.HasTable(tableNameRateSchedule) {
+		err := tx.CreateTable(&RateSchedule{}).Error
+		if err != nil {
+			return err
+		}
+	}
 	if !tx.HasTable(tableNameVersions) {
 		err := tx.CreateTable(&Version{}).Error
 		if err != nil {
@@ -1078,3 +1092,98 @@ func (c *cockroachdb) DCCsAll() ([]*database.DCC, error) {
 	}
 	return dbDCCs, nil
 }
+
+// SetDomain creates a new domain or updates the existing domain of the same
+// type.
+//
+// SetDomain satisfies the database interface.
+func (c *cockroachdb) SetDomain(dbDomain *database.Domain) error {
+	domain := encodeDomain(dbDomain)
+
+	log.Debugf("SetDomain: %v %v", domain.Type, domain.Description)
+	return c.recordsdb.Save(&domain).Error
+}
+
+// Domains returns all domains ordered by type.
+//
+// Domains satisfies the database interface.
+func (c *cockroachdb) Domains() ([]database.Domain, error) {
+	log.Tracef("Domains")
+
+	domains := make([]Domain, 0, 16)
+	err := c.recordsdb.
+		Order("type").
+		Find(&domains).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	dbDomains := make([]database.Domain, 0, len(domains))
+	for _, v := range domains {
+		dbDomains = append(dbDomains, decodeDomain(v))
+	}
+	return dbDomains, nil
+}
+
+// SetRateSchedule creates a new rate schedule or updates the existing rate
+// schedule of the same domain and month/year.
+//
+// SetRateSchedule satisfies the database interface.
+func (c *cockroachdb) SetRateSchedule(dbRateSchedule *database.RateSchedule) error {
+	rs := encodeRateSchedule(dbRateSchedule)
+
+	log.Debugf("SetRateSchedule: %v %v %v", rs.Domain, rs.Month, rs.Year)
+	return c.recordsdb.Save(&rs).Error
+}
+
+// RateSchedules returns all rate schedules of a domain ordered by the date
+// that they become effective.
+//
+// RateSchedules satisfies the database interface.
+func (c *cockroachdb) RateSchedules(domain int) ([]database.RateSchedule, error) {
+	log.Tracef("RateSchedules: %v", domain)
+
+	schedules := make([]RateSchedule, 0, 16)
+	err := c.recordsdb.
+		Where("domain = ?", domain).
+		Order("year, month").
+		Find(&schedules).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	dbSchedules := make([]database.RateSchedule, 0, len(schedules))
+	for _, v := range schedules {
+		dbSchedules = append(dbSchedules, decodeRateSchedule(v))
+	}
+	return dbSchedules, nil
+}
+
+// RateScheduleEffective returns the rate schedule of a domain that is
+// effective at the given month/year. This is the most recent rate schedule
+// that became effective on or before the month/year.
+//
+// RateScheduleEffective satisfies the database interface.
+func (c *cockroachdb) RateScheduleEffective(domain int, month, year uint) (*database.RateSchedule, error) {
+	log.Tracef("RateScheduleEffective: %v %v %v", domain, month, year)
+
+	rs := RateSchedule{}
+	err := c.recordsdb.
+		Where("domain = ? AND (year < ? OR (year = ? AND month <= ?))",
+			domain, year, year, month).
+		Order("year desc, month desc").
+		Limit(1).
+		Find(&rs).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = database.ErrRateScheduleNotFound
+		}
+		return nil, err
+	}
+
+	dbRateSchedule := decodeRateSchedule(rs)
+	return &dbRateSchedule, nil
+}
@@ -6,6 +6,7 @@ package cockroachdb
 
 import (
 	"strconv"
+	"strings"
 	"time"
 
 	cms "github.com/decred/politeia/politeiawww/api/cms/v1"
@@ -259,6 +260,52 @@ func decodeDCC(dcc *DCC) *database.DCC {
 	return &dbDCC
 }
 
+// subdomainsSeparator separates the subdomains of a domain when they are
+// stored in the database. Newlines are not allowed in subdomains.
+const subdomainsSeparator = "\n"
+
+func encodeDomain(dbDomain *database.Domain) Domain {
+	return Domain{
+		Type:        int(dbDomain.Type),
+		Description: dbDomain.Description,
+		Subdomains:  strings.Join(dbDomain.Subdomains, subdomainsSeparator),
+		Disabled:    dbDomain.Disabled,
+	}
+}
+
+func decodeDomain(domain Domain) database.Domain {
+	var subdomains []string
+	if domain.Subdomains != "" {
+		subdomains = strings.Split(domain.Subdomains, subdomainsSeparator)
+	}
+	return database.Domain{
+		Type:        cms.DomainTypeT(domain.Type),
+		Description: domain.Description,
+		Subdomains:  subdomains,
+		Disabled:    domain.Disabled,
+	}
+}
+
+func encodeRateSchedule(dbRateSchedule *database.RateSchedule) RateSchedule {
+	return RateSchedule{
+		Domain:  int(dbRateSchedule.Domain),
+		Month:   dbRateSchedule.Month,
+		Year:    dbRateSchedule.Year,
+		MinRate: dbRateSchedule.MinRate,
+		MaxRate: dbRateSchedule.MaxRate,
+	}
+}
+
+func decodeRateSchedule(rs RateSchedule) database.RateSchedule {
+	return database.RateSchedule{
+		Domain:  cms.DomainTypeT(rs.Domain),
+		Month:   rs.Month,
+		Year:    rs.Year,
+		MinRate: rs.MinRate,
+		MaxRate: rs.MaxRate,
+	}
+}
+
 func convertMatchingLineItemToInvoices(matching []MatchingLineItems) []database.Invoice {
 	// Each invoice added will include just 1 line item.
 	dbInvoices := make([]database.Invoice, 0, len(matching))
@@ -147,3 +147,32 @@ type DCC struct {
 func (DCC) TableName() string {
 	return tableNameDCC
 }
+
+// Domain contains a contractor domain and the newline separated list of the
+// subdomains that may be billed against it.
+type Domain struct {
+	Type        int    `gorm:"primary_key"`
+	Description string `gorm:"not null;unique"`
+	Subdomains  string `gorm:"not null"`
+	Disabled    bool   `gorm:"not null"`
+}
+
+// TableName returns the table name of the domains table.
+func (Domain) TableName() string {
+	return tableNameDomain
+}
+
+// RateSchedule contains the minimum and maximum contractor rates of a domain
+// that are effective starting at the given month/year.
+type RateSchedule struct {
+	Domain  int  `gorm:"primary_key"`
+	Year    uint `gorm:"primary_key"`
+	Month   uint `gorm:"primary_key"`
+	MinRate uint `gorm:"not null"`
+	MaxRate uint `gorm:"not null"`
+}
+
+// TableName returns the table name of the rate schedules table.
+func (RateSchedule) TableName() string {
+	return tableNameRateSchedule
+}
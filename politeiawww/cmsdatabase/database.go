@@ -35,6 +35,10 @@ var (
 
 	// ErrDCCNotFound indicates that a DCC was not found from a given token
 	ErrDCCNotFound = errors.New("dcc not found")
+
+	// ErrRateScheduleNotFound indicates that no rate schedule is effective
+	// for a given domain and month/year
+	ErrRateScheduleNotFound = errors.New("rate schedule not found")
 )
 
 // Database interface that is required by the web server.
@@ -76,6 +80,15 @@ type Database interface {
 	DCCsByStatus(int) ([]*DCC, error)
 	DCCsAll() ([]*DCC, error)
 
+	// Domain functions
+	SetDomain(*Domain) error    // Create or update a domain
+	Domains() ([]Domain, error) // Return all domains
+
+	// RateSchedule functions
+	SetRateSchedule(*RateSchedule) error                          // Create or update a rate schedule
+	RateSchedules(int) ([]RateSchedule, error)                    // Return all rate schedules of a domain
+	RateScheduleEffective(int, uint, uint) (*RateSchedule, error) // Return the rate schedule of a domain effective at month and year
+
 	// Setup the invoice tables
 	Setup() error
 
@@ -180,3 +193,22 @@ type DCC struct {
 	SupportUserIDs    string
 	OppositionUserIDs string
 }
+
+// Domain contains a contractor domain and the subdomains that may be billed
+// against it.
+type Domain struct {
+	Type        cms.DomainTypeT
+	Description string
+	Subdomains  []string
+	Disabled    bool
+}
+
+// RateSchedule contains the minimum and maximum contractor rates of a domain
+// that are effective starting at the given month/year.
+type RateSchedule struct {
+	Domain  cms.DomainTypeT
+	Month   uint
+	Year    uint
+	MinRate uint
+	MaxRate uint
+}
@@ -0,0 +1,287 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	cms "github.com/decred/politeia/politeiawww/api/cms/v1"
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	database "github.com/decred/politeia/politeiawww/cmsdatabase"
+)
+
+// setupCMSDomains adds the default contractor domains to the cmsdb when no
+// domains have been added yet.
+func (p *politeiawww) setupCMSDomains() error {
+	domains, err := p.cmsDB.Domains()
+	if err != nil {
+		return err
+	}
+	if len(domains) > 0 {
+		return nil
+	}
+
+	log.Infof("Adding default cms domains")
+
+	for _, v := range cms.PolicySupportedCMSDomains {
+		err := p.cmsDB.SetDomain(&database.Domain{
+			Type:        v.Type,
+			Description: v.Description,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cmsSupportedDomains returns the contractor domains that can currently be
+// billed against.
+func (p *politeiawww) cmsSupportedDomains() ([]cms.AvailableDomain, error) {
+	domains, err := p.cmsDB.Domains()
+	if err != nil {
+		return nil, err
+	}
+	available := make([]cms.AvailableDomain, 0, len(domains))
+	for _, v := range domains {
+		if v.Disabled {
+			continue
+		}
+		available = append(available, cms.AvailableDomain{
+			Description: v.Description,
+			Type:        v.Type,
+		})
+	}
+	return available, nil
+}
+
+// cmsDomainDescription returns the description of a contractor domain. An
+// empty string is returned if the domain does not exist.
+func (p *politeiawww) cmsDomainDescription(domain int) (string, error) {
+	domains, err := p.cmsDB.Domains()
+	if err != nil {
+		return "", err
+	}
+	for _, v := range domains {
+		if int(v.Type) == domain {
+			return v.Description, nil
+		}
+	}
+	return "", nil
+}
+
+// contractorRates returns the minimum and maximum contractor rates of a
+// domain that are effective at the given month/year. The default rates are
+// returned for domains without a rate schedule.
+func (p *politeiawww) contractorRates(domain int, month, year uint) (uint, uint, error) {
+	rs, err := p.cmsDB.RateScheduleEffective(domain, month, year)
+	if errors.Is(err, database.ErrRateScheduleNotFound) {
+		return minRate, maxRate, nil
+	} else if err != nil {
+		return 0, 0, err
+	}
+	return rs.MinRate, rs.MaxRate, nil
+}
+
+// validateLineItemDomain verifies that the domain of a line item is one of
+// the enabled domains and that the subdomain is one of the subdomains of the
+// domain. Any subdomain is allowed for domains without subdomains.
+func validateLineItemDomain(domains []database.Domain, domain, subdomain string) error {
+	for _, v := range domains {
+		if v.Disabled || !strings.EqualFold(v.Description, domain) {
+			continue
+		}
+		if len(v.Subdomains) == 0 {
+			return nil
+		}
+		for _, s := range v.Subdomains {
+			if strings.EqualFold(s, subdomain) {
+				return nil
+			}
+		}
+		return www.UserError{
+			ErrorCode:    cms.ErrorStatusInvalidLineItemSubdomain,
+			ErrorContext: []string{subdomain},
+		}
+	}
+	return www.UserError{
+		ErrorCode:    cms.ErrorStatusInvalidLineItemDomain,
+		ErrorContext: []string{domain},
+	}
+}
+
+func convertDomainFromDatabase(d database.Domain) cms.Domain {
+	subdomains := d.Subdomains
+	if subdomains == nil {
+		subdomains = []string{}
+	}
+	return cms.Domain{
+		Type:        d.Type,
+		Description: d.Description,
+		Subdomains:  subdomains,
+		Disabled:    d.Disabled,
+	}
+}
+
+func convertRateScheduleFromDatabase(rs database.RateSchedule) cms.RateSchedule {
+	return cms.RateSchedule{
+		Domain:  rs.Domain,
+		Month:   rs.Month,
+		Year:    rs.Year,
+		MinRate: rs.MinRate,
+		MaxRate: rs.MaxRate,
+	}
+}
+
+// processDomains returns all contractor domains.
+func (p *politeiawww) processDomains() (*cms.DomainsReply, error) {
+	log.Tracef("processDomains")
+
+	domains, err := p.cmsDB.Domains()
+	if err != nil {
+		return nil, err
+	}
+	reply := cms.DomainsReply{
+		Domains: make([]cms.Domain, 0, len(domains)),
+	}
+	for _, v := range domains {
+		reply.Domains = append(reply.Domains, convertDomainFromDatabase(v))
+	}
+	return &reply, nil
+}
+
+// processSetDomain creates a new contractor domain or updates an existing
+// one. Domain descriptions are stored in lower case since they are matched
+// against the domains of the invoice line items.
+func (p *politeiawww) processSetDomain(sd cms.SetDomain) (*cms.SetDomainReply, error) {
+	log.Tracef("processSetDomain: %v", sd.Domain.Type)
+
+	if sd.Domain.Type <= cms.DomainTypeInvalid {
+		return nil, www.UserError{
+			ErrorCode:    cms.ErrorStatusInvalidDomain,
+			ErrorContext: []string{"invalid domain type"},
+		}
+	}
+	description := strings.ToLower(formatInvoiceField(sd.Domain.Description))
+	if !validateInvoiceField(description) {
+		return nil, www.UserError{
+			ErrorCode:    cms.ErrorStatusInvalidDomain,
+			ErrorContext: []string{"invalid description"},
+		}
+	}
+
+	// Verify that the description is not used by another domain
+	domains, err := p.cmsDB.Domains()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range domains {
+		if v.Type != sd.Domain.Type && v.Description == description {
+			e := fmt.Sprintf("description used by domain %v", v.Type)
+			return nil, www.UserError{
+				ErrorCode:    cms.ErrorStatusInvalidDomain,
+				ErrorContext: []string{e},
+			}
+		}
+	}
+
+	// Validate subdomains
+	subdomains := make([]string, 0, len(sd.Domain.Subdomains))
+	seen := make(map[string]struct{}, len(sd.Domain.Subdomains))
+	for _, v := range sd.Domain.Subdomains {
+		subdomain := formatInvoiceField(v)
+		if !validateInvoiceField(subdomain) {
+			return nil, www.UserError{
+				ErrorCode:    cms.ErrorStatusMalformedSubdomain,
+				ErrorContext: []string{v},
+			}
+		}
+		key := strings.ToLower(subdomain)
+		if _, ok := seen[key]; ok {
+			e := fmt.Sprintf("duplicate subdomain: %v", subdomain)
+			return nil, www.UserError{
+				ErrorCode:    cms.ErrorStatusInvalidDomain,
+				ErrorContext: []string{e},
+			}
+		}
+		seen[key] = struct{}{}
+		subdomains = append(subdomains, subdomain)
+	}
+
+	err = p.cmsDB.SetDomain(&database.Domain{
+		Type:        sd.Domain.Type,
+		Description: description,
+		Subdomains:  subdomains,
+		Disabled:    sd.Domain.Disabled,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &cms.SetDomainReply{}, nil
+}
+
+// processRateSchedules returns the rate schedules of a contractor domain.
+func (p *politeiawww) processRateSchedules(rs cms.RateSchedules) (*cms.RateSchedulesReply, error) {
+	log.Tracef("processRateSchedules: %v", rs.Domain)
+
+	schedules, err := p.cmsDB.RateSchedules(int(rs.Domain))
+	if err != nil {
+		return nil, err
+	}
+	reply := cms.RateSchedulesReply{
+		RateSchedules: make([]cms.RateSchedule, 0, len(schedules)),
+	}
+	for _, v := range schedules {
+		reply.RateSchedules = append(reply.RateSchedules,
+			convertRateScheduleFromDatabase(v))
+	}
+	return &reply, nil
+}
+
+// processSetRateSchedule creates a new rate schedule for a contractor domain
+// or updates the existing one of the same month/year.
+func (p *politeiawww) processSetRateSchedule(srs cms.SetRateSchedule) (*cms.SetRateScheduleReply, error) {
+	rs := srs.RateSchedule
+
+	log.Tracef("processSetRateSchedule: %v %v %v", rs.Domain, rs.Month, rs.Year)
+
+	description, err := p.cmsDomainDescription(int(rs.Domain))
+	if err != nil {
+		return nil, err
+	}
+	if description == "" {
+		return nil, www.UserError{
+			ErrorCode: cms.ErrorStatusInvalidDomain,
+		}
+	}
+	if rs.Month < 1 || rs.Month > 12 || rs.Year == 0 {
+		return nil, www.UserError{
+			ErrorCode:    cms.ErrorStatusInvalidRateSchedule,
+			ErrorContext: []string{"invalid month/year"},
+		}
+	}
+	if rs.MinRate == 0 || rs.MinRate > rs.MaxRate {
+		return nil, www.UserError{
+			ErrorCode:    cms.ErrorStatusInvalidRateSchedule,
+			ErrorContext: []string{"invalid rates"},
+		}
+	}
+
+	err = p.cmsDB.SetRateSchedule(&database.RateSchedule{
+		Domain:  rs.Domain,
+		Month:   rs.Month,
+		Year:    rs.Year,
+		MinRate: rs.MinRate,
+		MaxRate: rs.MaxRate,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &cms.SetRateScheduleReply{}, nil
+}
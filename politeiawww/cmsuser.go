@@ -218,7 +218,7 @@ func (p *politeiawww) processRegisterUser(u cms.RegisterUser) (*cms.RegisterUser
 	}
 
 	// Validate the password.
-	err = validatePassword(u.Password)
+	err = p.validatePassword(u.Password, u.Username, u.Email)
 	if err != nil {
 		return nil, err
 	}
@@ -369,33 +369,10 @@ func (p *politeiawww) processManageCMSUser(mu cms.CMSManageUser) (*cms.CMSManage
 		uu.ContractorType = int(mu.ContractorType)
 	}
 	if len(mu.SupervisorUserIDs) > 0 {
-		// Validate SupervisorUserID input
-		parseSuperUserIds := make([]uuid.UUID, 0, len(mu.SupervisorUserIDs))
-		for _, super := range mu.SupervisorUserIDs {
-			parseUUID, err := uuid.Parse(super)
-			if err != nil {
-				e := fmt.Sprintf("invalid uuid: %v", super)
-				return nil, www.UserError{
-					ErrorCode:    cms.ErrorStatusInvalidSupervisorUser,
-					ErrorContext: []string{e},
-				}
-			}
-			u, err := p.getCMSUserByID(super)
-			if err != nil {
-				e := fmt.Sprintf("user not found: %v", super)
-				return nil, www.UserError{
-					ErrorCode:    cms.ErrorStatusInvalidSupervisorUser,
-					ErrorContext: []string{e},
-				}
-			}
-			if u.ContractorType != cms.ContractorTypeSupervisor {
-				e := fmt.Sprintf("user not a supervisor: %v", super)
-				return nil, www.UserError{
-					ErrorCode:    cms.ErrorStatusInvalidSupervisorUser,
-					ErrorContext: []string{e},
-				}
-			}
-			parseSuperUserIds = append(parseSuperUserIds, parseUUID)
+		parseSuperUserIds, err := p.parseSupervisorUserIDs(editUser.ID,
+			mu.SupervisorUserIDs)
+		if err != nil {
+			return nil, err
 		}
 		uu.SupervisorUserIDs = parseSuperUserIds
 	}
@@ -420,6 +397,100 @@ func (p *politeiawww) processManageCMSUser(mu cms.CMSManageUser) (*cms.CMSManage
 	return &cms.CMSManageUserReply{}, nil
 }
 
+// parseSupervisorUserIDs validates the supervisor user IDs of a user. The
+// supervisors must be supervisor contractors and a user cannot supervise
+// themselves.
+func (p *politeiawww) parseSupervisorUserIDs(userID uuid.UUID, supervisorUserIDs []string) ([]uuid.UUID, error) {
+	parseSuperUserIds := make([]uuid.UUID, 0, len(supervisorUserIDs))
+	seen := make(map[uuid.UUID]struct{}, len(supervisorUserIDs))
+	for _, super := range supervisorUserIDs {
+		parseUUID, err := uuid.Parse(super)
+		if err != nil {
+			e := fmt.Sprintf("invalid uuid: %v", super)
+			return nil, www.UserError{
+				ErrorCode:    cms.ErrorStatusInvalidSupervisorUser,
+				ErrorContext: []string{e},
+			}
+		}
+		if parseUUID == userID {
+			e := fmt.Sprintf("user cannot supervise themselves: %v", super)
+			return nil, www.UserError{
+				ErrorCode:    cms.ErrorStatusInvalidSupervisorUser,
+				ErrorContext: []string{e},
+			}
+		}
+		if _, ok := seen[parseUUID]; ok {
+			e := fmt.Sprintf("duplicate supervisor: %v", super)
+			return nil, www.UserError{
+				ErrorCode:    cms.ErrorStatusInvalidSupervisorUser,
+				ErrorContext: []string{e},
+			}
+		}
+		seen[parseUUID] = struct{}{}
+		u, err := p.getCMSUserByID(super)
+		if err != nil {
+			e := fmt.Sprintf("user not found: %v", super)
+			return nil, www.UserError{
+				ErrorCode:    cms.ErrorStatusInvalidSupervisorUser,
+				ErrorContext: []string{e},
+			}
+		}
+		if u.ContractorType != cms.ContractorTypeSupervisor {
+			e := fmt.Sprintf("user not a supervisor: %v", super)
+			return nil, www.UserError{
+				ErrorCode:    cms.ErrorStatusInvalidSupervisorUser,
+				ErrorContext: []string{e},
+			}
+		}
+		parseSuperUserIds = append(parseSuperUserIds, parseUUID)
+	}
+	return parseSuperUserIds, nil
+}
+
+// processSetSupervisors replaces the supervisors of a user.
+func (p *politeiawww) processSetSupervisors(ss cms.SetSupervisors) (*cms.SetSupervisorsReply, error) {
+	log.Tracef("processSetSupervisors: %v", ss.UserID)
+
+	editUser, err := p.userByIDStr(ss.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The cms user database keeps the existing supervisors when no
+	// supervisors are provided, so at least one is required.
+	if len(ss.SupervisorUserIDs) == 0 {
+		return nil, www.UserError{
+			ErrorCode:    cms.ErrorStatusInvalidSupervisorUser,
+			ErrorContext: []string{"no supervisors provided"},
+		}
+	}
+	supervisorUserIDs, err := p.parseSupervisorUserIDs(editUser.ID,
+		ss.SupervisorUserIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	uu := user.UpdateCMSUser{
+		ID:                editUser.ID,
+		SupervisorUserIDs: supervisorUserIDs,
+	}
+	payload, err := user.EncodeUpdateCMSUser(uu)
+	if err != nil {
+		return nil, err
+	}
+	pc := user.PluginCommand{
+		ID:      user.CMSPluginID,
+		Command: user.CmdUpdateCMSUser,
+		Payload: string(payload),
+	}
+	_, err = p.db.PluginExec(pc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cms.SetSupervisorsReply{}, nil
+}
+
 // filterCMSUserPublicFields creates a filtered copy of a cms User that only
 // contains public information.
 func filterCMSUserPublicFields(user cms.User) cms.User {
@@ -382,8 +382,16 @@ func (p *politeiawww) handleInvoiceExchangeRate(w http.ResponseWriter, r *http.R
 func (p *politeiawww) handleCMSPolicy(w http.ResponseWriter, r *http.Request) {
 	// Get the policy command.
 	log.Tracef("handlePolicy")
+
+	domains, err := p.cmsSupportedDomains()
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleCMSPolicy: cmsSupportedDomains: %v", err)
+		return
+	}
+
 	reply := &cms.PolicyReply{
-		MinPasswordLength:             www.PolicyMinPasswordLength,
+		MinPasswordLength:             p.passwords.Policy().MinLength,
 		MinUsernameLength:             www.PolicyMinUsernameLength,
 		MaxUsernameLength:             www.PolicyMaxUsernameLength,
 		MaxImages:                     cms.PolicyMaxImages,
@@ -404,7 +412,7 @@ func (p *politeiawww) handleCMSPolicy(w http.ResponseWriter, r *http.Request) {
 		CMSNameLocationSupportedChars: cms.PolicyCMSNameLocationSupportedChars,
 		CMSContactSupportedChars:      cms.PolicyCMSContactSupportedChars,
 		CMSStatementSupportedChars:    cms.PolicySponsorStatementSupportedChars,
-		CMSSupportedDomains:           cms.PolicySupportedCMSDomains,
+		CMSSupportedDomains:           domains,
 		CMSSupportedLineItemTypes:     cms.PolicyCMSSupportedLineItemTypes,
 	}
 
@@ -490,6 +498,118 @@ func (p *politeiawww) handleManageCMSUser(w http.ResponseWriter, r *http.Request
 	util.RespondWithJSON(w, http.StatusOK, reply)
 }
 
+// handleDomains handles the request to get all contractor domains.
+func (p *politeiawww) handleDomains(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleDomains")
+
+	reply, err := p.processDomains()
+	if err != nil {
+		RespondWithError(w, r, 0, "handleDomains: processDomains %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
+// handleSetDomain handles the request to create or update a contractor
+// domain.
+func (p *politeiawww) handleSetDomain(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleSetDomain")
+
+	var sd cms.SetDomain
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&sd); err != nil {
+		RespondWithError(w, r, 0, "handleSetDomain: unmarshal",
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	reply, err := p.processSetDomain(sd)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleSetDomain: processSetDomain %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
+// handleRateSchedules handles the request to get the rate schedules of a
+// contractor domain.
+func (p *politeiawww) handleRateSchedules(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleRateSchedules")
+
+	var rs cms.RateSchedules
+	err := util.ParseGetParams(r, &rs)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleRateSchedules: ParseGetParams",
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	reply, err := p.processRateSchedules(rs)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleRateSchedules: processRateSchedules %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
+// handleSetRateSchedule handles the request to create or update the rate
+// schedule of a contractor domain.
+func (p *politeiawww) handleSetRateSchedule(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleSetRateSchedule")
+
+	var srs cms.SetRateSchedule
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&srs); err != nil {
+		RespondWithError(w, r, 0, "handleSetRateSchedule: unmarshal",
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	reply, err := p.processSetRateSchedule(srs)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleSetRateSchedule: processSetRateSchedule %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
+// handleSetSupervisors handles the request to replace the supervisors of a
+// user.
+func (p *politeiawww) handleSetSupervisors(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleSetSupervisors")
+
+	var ss cms.SetSupervisors
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&ss); err != nil {
+		RespondWithError(w, r, 0, "handleSetSupervisors: unmarshal",
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	reply, err := p.processSetSupervisors(ss)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleSetSupervisors: processSetSupervisors %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
 func (p *politeiawww) handleCMSUserDetails(w http.ResponseWriter, r *http.Request) {
 	// Add the path param to the struct.
 	log.Tracef("handleCMSUserDetails")
@@ -1090,8 +1210,11 @@ func (p *politeiawww) setCMSWWWRoutes() {
 	p.router.NotFoundHandler = http.HandlerFunc(p.handleNotFound)
 
 	// The version routes set the CSRF token and thus need to be part
-	// of the CSRF protected auth router.
-	p.auth.HandleFunc("/", p.handleVersion).Methods(http.MethodGet)
+	// of the CSRF protected auth router. The root route serves the web
+	// frontend instead when it has been enabled.
+	if p.cfg.WebRoot == "" {
+		p.auth.HandleFunc("/", p.handleVersion).Methods(http.MethodGet)
+	}
 	p.auth.StrictSlash(true).
 		HandleFunc(www.PoliteiaWWWAPIRoute+www.RouteVersion, p.handleVersion).
 		Methods(http.MethodGet)
@@ -1177,6 +1300,12 @@ func (p *politeiawww) setCMSWWWRoutes() {
 	p.addRoute(http.MethodPost, cms.APIRoute,
 		cms.RouteUserCodeStats, p.handleUserCodeStats,
 		permissionLogin)
+	p.addRoute(http.MethodGet, cms.APIRoute,
+		cms.RouteDomains, p.handleDomains,
+		permissionLogin)
+	p.addRoute(http.MethodGet, cms.APIRoute,
+		cms.RouteRateSchedules, p.handleRateSchedules,
+		permissionLogin)
 
 	// Unauthenticated websocket
 	p.addRoute("", www.PoliteiaWWWAPIRoute,
@@ -1218,4 +1347,13 @@ func (p *politeiawww) setCMSWWWRoutes() {
 	p.addRoute(http.MethodPost, cms.APIRoute,
 		cms.RouteProposalBillingDetails, p.handleProposalBillingDetails,
 		permissionAdmin)
+	p.addRoute(http.MethodPost, cms.APIRoute,
+		cms.RouteSetDomain, p.handleSetDomain,
+		permissionAdmin)
+	p.addRoute(http.MethodPost, cms.APIRoute,
+		cms.RouteSetRateSchedule, p.handleSetRateSchedule,
+		permissionAdmin)
+	p.addRoute(http.MethodPost, cms.APIRoute,
+		cms.RouteSetSupervisors, p.handleSetSupervisors,
+		permissionAdmin)
 }
@@ -0,0 +1,68 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package comments
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+)
+
+// botLimitWindow is the window that the comments of a bot account are
+// counted in.
+const botLimitWindow = time.Hour
+
+// botLimiter limits the number of comments that a bot account can submit per
+// hour.
+//
+// The comment times are only kept in memory. A restart resets the limits,
+// which is fine since bot accounts are created by admins and can be
+// deactivated if they misbehave.
+type botLimiter struct {
+	sync.Mutex
+	max      uint32
+	comments map[string][]time.Time // [userID]commentTimes
+}
+
+// newBotLimiter returns a new botLimiter. The comments are not limited when
+// max is 0.
+func newBotLimiter(max uint32) *botLimiter {
+	return &botLimiter{
+		max:      max,
+		comments: make(map[string][]time.Time),
+	}
+}
+
+// allow verifies that the bot account has not reached its comment limit and
+// counts a new comment.
+func (l *botLimiter) allow(userID string, now time.Time) error {
+	if l.max == 0 {
+		return nil
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	// Drop the comments that are outside of the window
+	times := l.comments[userID]
+	start := now.Add(-botLimitWindow)
+	for len(times) > 0 && !times[0].After(start) {
+		times = times[1:]
+	}
+
+	if uint32(len(times)) >= l.max {
+		l.comments[userID] = times
+		return v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeRateLimitExceeded,
+			ErrorContext: fmt.Sprintf("bot accounts can submit %v "+
+				"comments per hour", l.max),
+		}
+	}
+
+	l.comments[userID] = append(times, now)
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	pdv2 "github.com/decred/politeia/politeiad/api/v2"
 	pdclient "github.com/decred/politeia/politeiad/client"
@@ -16,6 +17,7 @@ import (
 	v1 "github.com/decred/politeia/politeiawww/api/comments/v1"
 	"github.com/decred/politeia/politeiawww/config"
 	"github.com/decred/politeia/politeiawww/events"
+	"github.com/decred/politeia/politeiawww/nonces"
 	"github.com/decred/politeia/politeiawww/sessions"
 	"github.com/decred/politeia/politeiawww/user"
 	"github.com/decred/politeia/util"
@@ -28,7 +30,18 @@ type Comments struct {
 	userdb    user.Database
 	sessions  *sessions.Sessions
 	events    *events.Manager
+	nonces    *nonces.Nonces
+	bots      *botLimiter
 	policy    *v1.PolicyReply
+
+	// counts caches the comment counts of records. It is nil when the
+	// comment count cache is disabled.
+	counts *countCache
+}
+
+// Policy returns the comments v1 policy.
+func (c *Comments) Policy() v1.PolicyReply {
+	return *c.policy
 }
 
 // HandlePolicy is the request handler for the comments v1 Policy route.
@@ -218,8 +231,7 @@ func (c *Comments) HandleTimestamps(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("HandleTimestamps")
 
 	var t v1.Timestamps
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&t); err != nil {
+	if err := util.ParseRequest(r, &t); err != nil {
 		respondWithError(w, r, "HandleTimestamps: unmarshal",
 			v1.UserErrorReply{
 				ErrorCode: v1.ErrorCodeInputInvalid,
@@ -244,7 +256,33 @@ func (c *Comments) HandleTimestamps(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	util.RespondWithJSON(w, http.StatusOK, tr)
+	util.RespondWithETag(w, r, tr)
+}
+
+// HandleExport is the request handler for the comments v1 Export route.
+func (c *Comments) HandleExport(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleExport")
+
+	var e v1.Export
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&e); err != nil {
+		respondWithError(w, r, "HandleExport: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	er, err := c.processExport(r.Context(), e)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleExport: processExport: %v", err)
+		return
+	}
+
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=%v-comments.json", e.Token))
+	util.RespondWithJSON(w, http.StatusOK, er)
 }
 
 // New returns a new Comments context.
@@ -253,6 +291,7 @@ func New(cfg *config.Config, pdc *pdclient.Client, udb user.Database, s *session
 	var (
 		lengthMax      uint32
 		voteChangesMax uint32
+		depthMax       uint32
 	)
 	for _, p := range plugins {
 		if p.ID != comments.PluginID {
@@ -273,6 +312,12 @@ func New(cfg *config.Config, pdc *pdclient.Client, udb user.Database, s *session
 					return nil, err
 				}
 				voteChangesMax = uint32(u)
+			case comments.SettingKeyCommentDepthMax:
+				u, err := strconv.ParseUint(v.Value, 10, 64)
+				if err != nil {
+					return nil, err
+				}
+				depthMax = uint32(u)
 			default:
 				// Skip unknown settings
 				log.Warnf("Unknown plugin setting %v; Skipping...", v.Key)
@@ -290,15 +335,44 @@ func New(cfg *config.Config, pdc *pdclient.Client, udb user.Database, s *session
 			comments.SettingKeyVoteChangesMax)
 	}
 
+	// Setup the comment count cache
+	var counts *countCache
+	if cfg.CommentCountReconcile > 0 {
+		interval := time.Duration(cfg.CommentCountReconcile) * time.Minute
+		log.Infof("Comment count cache: reconcile every %v", interval)
+		counts = newCountCache(pdc, 2*interval)
+		counts.setupEventListeners(e)
+		go counts.run(interval)
+	}
+
 	return &Comments{
 		cfg:       cfg,
 		politeiad: pdc,
 		userdb:    udb,
 		sessions:  s,
 		events:    e,
+		nonces:    nonces.New(v1.NonceSize, v1.NonceExpiryMax),
+		bots:      newBotLimiter(cfg.BotCommentsPerHour),
 		policy: &v1.PolicyReply{
-			LengthMax:      lengthMax,
-			VoteChangesMax: voteChangesMax,
+			LengthMax:          lengthMax,
+			VoteChangesMax:     voteChangesMax,
+			DepthMax:           depthMax,
+			NewAccountAgeMin:   cfg.CommentAccountAge,
+			NewStake:           cfg.CommentStake,
+			VoteAccountAgeMin:  cfg.CommentVoteAccountAge,
+			VoteStake:          cfg.CommentVoteStake,
+			NonceRequired:      cfg.CommentNonces,
+			NonceExpiryMax:     v1.NonceExpiryMax,
+			BotCommentsPerHour: cfg.BotCommentsPerHour,
 		},
+		counts: counts,
 	}, nil
 }
+
+// SetNonceStore sets the store that records the nonces that have been used by
+// signed requests. A store that is shared by all politeiawww instances must be
+// used when multiple instances serve requests. This must be set prior to the
+// Comments context being used.
+func (c *Comments) SetNonceStore(s nonces.Store) {
+	c.nonces.SetStore(s)
+}
@@ -0,0 +1,227 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package comments
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pdclient "github.com/decred/politeia/politeiad/client"
+	"github.com/decred/politeia/politeiawww/consistency"
+	"github.com/decred/politeia/politeiawww/events"
+)
+
+// countsReconcileBatch is the number of records whose comment counts are
+// requested from politeiad in a single request during a reconciliation.
+const countsReconcileBatch = 50
+
+// countFetcher retrieves the comment counts of the provided tokens from
+// politeiad.
+type countFetcher func(ctx context.Context, tokens []string) (map[string]uint32, error)
+
+// countCache caches the comment counts of records. A count is added to the
+// cache the first time it is requested and is updated when a new comment is
+// made on the record. The counts are periodically reconciled with politeiad.
+//
+// Comments are never removed from a record, they are only marked as deleted,
+// and comment IDs are assigned sequentially starting at 1. The comment count
+// of a record is therefore the ID of its latest comment. A new comment event
+// sets the count to the comment ID, which makes it safe for an event to be
+// applied to a count that was fetched after the comment was made.
+//
+// The new comment events are only received by the politeiawww instance that
+// the comment was made on. The counts of the comments that are made on other
+// instances are updated by the reconciliation. A count that has not been
+// reconciled within the max age is fetched again when it is requested, so a
+// failed reconciliation does not leave the cache stale. A count that was
+// fetched before the write of the request consistency token is also fetched
+// again.
+//
+// The counts are keyed by full length token.
+type countCache struct {
+	sync.Mutex
+	fetch   countFetcher
+	version func() uint64 // Returns the politeiad write version
+	maxAge  time.Duration
+	counts  map[string]cachedCount // [token]cachedCount
+}
+
+// cachedCount is a cached comment count.
+type cachedCount struct {
+	count   uint32
+	fetched time.Time // Time of the last politeiad fetch
+	version uint64    // Write version prior to the last fetch
+}
+
+// newCountCache returns a new countCache. The politeiad client cache is
+// bypassed so that the counts that are fetched are never stale.
+func newCountCache(pdc *pdclient.Client, maxAge time.Duration) *countCache {
+	return &countCache{
+		fetch:   pdc.CommentCountNoCache,
+		version: pdc.Version,
+		maxAge:  maxAge,
+		counts:  make(map[string]cachedCount, 256),
+	}
+}
+
+// get returns the comment counts of the provided tokens. The counts that are
+// not cached, that have exceeded the max age, or that do not satisfy the
+// request consistency token are retrieved from politeiad and added to the
+// cache.
+func (c *countCache) get(ctx context.Context, tokens []string) (map[string]uint32, error) {
+	var (
+		counts  = make(map[string]uint32, len(tokens))
+		missing = make([]string, 0, len(tokens))
+		now     = time.Now()
+	)
+	c.Lock()
+	for _, v := range tokens {
+		cc, ok := c.counts[v]
+		if !ok || now.Sub(cc.fetched) > c.maxAge ||
+			!consistency.Satisfied(ctx, cc.version) {
+			missing = append(missing, v)
+			continue
+		}
+		counts[v] = cc.count
+	}
+	c.Unlock()
+
+	if len(missing) == 0 {
+		return counts, nil
+	}
+	version := c.version()
+	fetched, err := c.fetch(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	for k, v := range fetched {
+		counts[k] = c.put(k, v, now, version)
+	}
+
+	return counts, nil
+}
+
+// put adds a fetched comment count to the cache and returns the resulting
+// count. A new comment event that was applied while the count was being
+// fetched is not overwritten, since the fetched count may not include the
+// comment. Comment counts never decrease. The version is the politeiad write
+// version prior to the fetch.
+//
+// This function must be called WITH the lock held.
+func (c *countCache) put(token string, count uint32, fetched time.Time, version uint64) uint32 {
+	if cc, ok := c.counts[token]; ok && cc.count > count {
+		count = cc.count
+	}
+	c.counts[token] = cachedCount{
+		count:   count,
+		fetched: fetched,
+		version: version,
+	}
+	return count
+}
+
+// update updates the comment count of a record using the ID of a new comment.
+// Records that are not cached are skipped. Their count is retrieved when it
+// is first requested.
+func (c *countCache) update(token string, commentID uint32) {
+	c.Lock()
+	defer c.Unlock()
+
+	cc, ok := c.counts[token]
+	if ok && commentID > cc.count {
+		cc.count = commentID
+		c.counts[token] = cc
+	}
+}
+
+// reconcile updates the cached comment counts with the counts that are
+// returned by politeiad.
+func (c *countCache) reconcile(ctx context.Context) error {
+	c.Lock()
+	tokens := make([]string, 0, len(c.counts))
+	for k := range c.counts {
+		tokens = append(tokens, k)
+	}
+	c.Unlock()
+
+	var drift int
+	for len(tokens) > 0 {
+		n := countsReconcileBatch
+		if n > len(tokens) {
+			n = len(tokens)
+		}
+		var (
+			now     = time.Now()
+			version = c.version()
+		)
+		fetched, err := c.fetch(ctx, tokens[:n])
+		if err != nil {
+			return err
+		}
+		tokens = tokens[n:]
+
+		c.Lock()
+		for k, v := range fetched {
+			if c.counts[k].count != v {
+				drift++
+			}
+			c.put(k, v, now, version)
+		}
+		c.Unlock()
+	}
+
+	if drift > 0 {
+		log.Infof("Comment count cache reconciled: %v counts updated", drift)
+	}
+
+	return nil
+}
+
+// run reconciles the comment counts at the provided interval.
+func (c *countCache) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		err := c.reconcile(context.Background())
+		if err != nil {
+			log.Errorf("comment count cache reconcile: %v", err)
+		}
+	}
+}
+
+// setupEventListeners registers the comment event listeners that keep the
+// cached counts up to date.
+func (c *countCache) setupEventListeners(e *events.Manager) {
+	ch := make(chan interface{})
+	e.Register(EventTypeNew, ch)
+	go c.handleEventNew(ch)
+}
+
+func (c *countCache) handleEventNew(ch chan interface{}) {
+	for msg := range ch {
+		e, ok := msg.(EventNew)
+		if !ok {
+			log.Errorf("handleEventNew invalid msg: %v", msg)
+			continue
+		}
+		c.update(e.Comment.Token, e.Comment.CommentID)
+	}
+}
+
+// CommentCounts returns the comment counts of the provided records. The
+// tokens must be full length tokens. The counts are served from the comment
+// count cache when it is enabled.
+func (c *Comments) CommentCounts(ctx context.Context, tokens []string) (map[string]uint32, error) {
+	if c.counts == nil {
+		return c.politeiad.CommentCount(ctx, tokens)
+	}
+	return c.counts.get(ctx, tokens)
+}
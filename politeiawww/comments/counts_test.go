@@ -0,0 +1,240 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package comments
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/consistency"
+)
+
+// testCounts is a fake politeiad that returns the comment counts of records.
+type testCounts struct {
+	sync.Mutex
+	counts  map[string]uint32
+	version uint64 // Write version
+	fetches int
+
+	// onFetch is called after the counts have been read and before they
+	// are returned. It is used to simulate events that arrive while a
+	// fetch is in flight.
+	onFetch func()
+}
+
+func (t *testCounts) fetch(ctx context.Context, tokens []string) (map[string]uint32, error) {
+	t.Lock()
+	t.fetches++
+	counts := make(map[string]uint32, len(tokens))
+	for _, v := range tokens {
+		c, ok := t.counts[v]
+		if ok {
+			counts[v] = c
+		}
+	}
+	onFetch := t.onFetch
+	t.Unlock()
+
+	if onFetch != nil {
+		onFetch()
+	}
+
+	return counts, nil
+}
+
+// set sets the count of a record. This is a write, so the write version is
+// incremented.
+func (t *testCounts) set(token string, count uint32) {
+	t.Lock()
+	defer t.Unlock()
+	t.counts[token] = count
+	t.version++
+}
+
+func (t *testCounts) writeVersion() uint64 {
+	t.Lock()
+	defer t.Unlock()
+	return t.version
+}
+
+func newTestCountCache(maxAge time.Duration) (*countCache, *testCounts) {
+	tc := &testCounts{
+		counts: map[string]uint32{
+			"a": 1,
+			"b": 2,
+		},
+	}
+	return &countCache{
+		fetch:   tc.fetch,
+		version: tc.writeVersion,
+		maxAge:  maxAge,
+		counts:  make(map[string]cachedCount),
+	}, tc
+}
+
+func TestCountCacheGet(t *testing.T) {
+	c, tc := newTestCountCache(time.Hour)
+
+	counts, err := c.get(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["a"] != 1 || counts["b"] != 2 {
+		t.Fatalf("got %v", counts)
+	}
+
+	// The cached counts are returned without a fetch
+	tc.set("a", 5)
+	counts, err = c.get(context.Background(), []string{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["a"] != 1 {
+		t.Fatalf("got %v, want cached count 1", counts["a"])
+	}
+	if tc.fetches != 1 {
+		t.Fatalf("got %v fetches, want 1", tc.fetches)
+	}
+
+	// Counts that exceed the max age are fetched again
+	c.maxAge = 0
+	counts, err = c.get(context.Background(), []string{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["a"] != 5 {
+		t.Fatalf("got %v, want fetched count 5", counts["a"])
+	}
+}
+
+func TestCountCacheUpdate(t *testing.T) {
+	c, tc := newTestCountCache(time.Hour)
+
+	// Records that are not cached are skipped
+	c.update("a", 2)
+	if _, ok := c.counts["a"]; ok {
+		t.Fatalf("uncached record was added")
+	}
+
+	_, err := c.get(context.Background(), []string{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The event of a comment that was already included in the fetched
+	// count must not be counted again.
+	c.update("a", 1)
+	if c.counts["a"].count != 1 {
+		t.Fatalf("got %v, want 1", c.counts["a"].count)
+	}
+	c.update("a", 2)
+	if c.counts["a"].count != 2 {
+		t.Fatalf("got %v, want 2", c.counts["a"].count)
+	}
+
+	// An event that arrives while a fetch is in flight is not
+	// overwritten by the fetched count.
+	c.maxAge = 0
+	tc.onFetch = func() {
+		c.update("a", 3)
+	}
+	counts, err := c.get(context.Background(), []string{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["a"] != 3 || c.counts["a"].count != 3 {
+		t.Fatalf("got %v %v, want 3", counts["a"], c.counts["a"].count)
+	}
+}
+
+func TestCountCacheReconcile(t *testing.T) {
+	c, tc := newTestCountCache(time.Hour)
+
+	_, err := c.get(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Comments made on other politeiawww instances do not generate
+	// events on this instance. They are picked up by the reconcile.
+	tc.set("a", 4)
+	err = c.reconcile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.counts["a"].count != 4 || c.counts["b"].count != 2 {
+		t.Fatalf("got %v", c.counts)
+	}
+
+	// The reconcile does not roll back a newer count
+	c.update("b", 3)
+	err = c.reconcile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.counts["b"].count != 3 {
+		t.Fatalf("got %v, want 3", c.counts["b"].count)
+	}
+}
+
+func TestCountCacheConsistency(t *testing.T) {
+	c, tc := newTestCountCache(time.Hour)
+
+	_, err := c.get(context.Background(), []string{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A comment is made on another politeiawww instance
+	tc.set("a", 2)
+
+	// requestCtx returns the context of a request that contains the
+	// provided consistency token.
+	requestCtx := func(token string) context.Context {
+		var ctx context.Context
+		h := consistency.New().Middleware(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				ctx = r.Context()
+			}))
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set(www.ConsistencyToken, token)
+		h.ServeHTTP(httptest.NewRecorder(), r)
+		return ctx
+	}
+
+	// A request without a consistency token is served from the cache
+	counts, err := c.get(context.Background(), []string{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["a"] != 1 {
+		t.Fatalf("got %v, want cached count 1", counts["a"])
+	}
+
+	// A request with the consistency token of the comment is not
+	token := strconv.FormatUint(tc.writeVersion(), 10)
+	counts, err = c.get(requestCtx(token), []string{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["a"] != 2 {
+		t.Fatalf("got %v, want fetched count 2", counts["a"])
+	}
+
+	// The fetched count satisfies the token
+	counts, err = c.get(requestCtx(token), []string{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["a"] != 2 || tc.fetches != 2 {
+		t.Fatalf("got count %v fetches %v, want 2 2", counts["a"], tc.fetches)
+	}
+}
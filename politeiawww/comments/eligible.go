@@ -0,0 +1,70 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package comments
+
+import (
+	"fmt"
+	"time"
+
+	v1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	"github.com/decred/politeia/politeiawww/user"
+)
+
+// accountVerified returns the UNIX time at which the account of a user was
+// verified, i.e. the time at which the first identity of the user was
+// activated. 0 is returned if the account has not been verified.
+func accountVerified(u user.User) int64 {
+	var verified int64
+	for _, v := range u.Identities {
+		if v.Activated == 0 {
+			continue
+		}
+		if verified == 0 || v.Activated < verified {
+			verified = v.Activated
+		}
+	}
+	return verified
+}
+
+// verifyEligible verifies that a user is eligible to perform a comment action
+// given the minimum account age in days and whether users that have verified
+// stake are eligible. A user that meets either requirement is eligible, so
+// that only verified stake is required when the account age is not set.
+// Admins are always eligible.
+func verifyEligible(u user.User, accountAgeMin uint32, stake bool, now time.Time) error {
+	switch {
+	case u.Admin:
+		return nil
+	case accountAgeMin == 0 && !stake:
+		// No requirements
+		return nil
+	case stake && u.StakeVerified != 0:
+		return nil
+	}
+
+	if accountAgeMin > 0 {
+		verified := accountVerified(u)
+		minAge := time.Duration(accountAgeMin) * 24 * time.Hour
+		if verified != 0 && now.Sub(time.Unix(verified, 0)) >= minAge {
+			return nil
+		}
+	}
+
+	var requirement string
+	switch {
+	case accountAgeMin > 0 && stake:
+		requirement = fmt.Sprintf("account must be at least %v days old "+
+			"or have verified stake", accountAgeMin)
+	case accountAgeMin > 0:
+		requirement = fmt.Sprintf("account must be at least %v days old",
+			accountAgeMin)
+	default:
+		requirement = "account must have verified stake"
+	}
+	return v1.UserErrorReply{
+		ErrorCode:    v1.ErrorCodeUserNotEligible,
+		ErrorContext: requirement,
+	}
+}
@@ -11,6 +11,9 @@ import (
 const (
 	// EventTypeNew is emitted when a new comment is made.
 	EventTypeNew = "comments-new"
+
+	// EventTypeDel is emitted when a comment is deleted.
+	EventTypeDel = "comments-del"
 )
 
 // EventNew is the event data for the EventTypeNew.
@@ -18,3 +21,10 @@ type EventNew struct {
 	State   v1.RecordStateT
 	Comment v1.Comment
 }
+
+// EventDel is the event data for the EventTypeDel. The comment is the deleted
+// comment.
+type EventDel struct {
+	State   v1.RecordStateT
+	Comment v1.Comment
+}
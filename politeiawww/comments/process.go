@@ -6,15 +6,20 @@ package comments
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"sort"
+	"time"
 
 	pdv2 "github.com/decred/politeia/politeiad/api/v2"
 	"github.com/decred/politeia/politeiad/plugins/comments"
 	v1 "github.com/decred/politeia/politeiawww/api/comments/v1"
 	"github.com/decred/politeia/politeiawww/config"
+	"github.com/decred/politeia/politeiawww/nonces"
 	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
 	"github.com/google/uuid"
 )
 
@@ -37,6 +42,19 @@ func (c *Comments) processNew(ctx context.Context, n v1.New, u user.User) (*v1.N
 		}
 	}
 
+	// Verify user is eligible to comment. Bot accounts are created by
+	// admins so they are rate limited instead.
+	var err error
+	if u.Bot {
+		err = c.bots.allow(u.ID.String(), time.Now())
+	} else {
+		err = verifyEligible(u, c.cfg.CommentAccountAge,
+			c.cfg.CommentStake, time.Now())
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	// Execute pre plugin hooks. Checking the mode is a temporary
 	// measure until user plugins have been properly implemented.
 	switch c.cfg.Mode {
@@ -63,6 +81,12 @@ func (c *Comments) processNew(ctx context.Context, n v1.New, u user.User) (*v1.N
 		}
 	}
 
+	// Verify the request is not a replay
+	err = c.verifyNonce(n.PublicKey, n.ReplayProtection)
+	if err != nil {
+		return nil, err
+	}
+
 	// Send plugin command
 	cn := comments.New{
 		UserID:    u.ID.String(),
@@ -72,6 +96,8 @@ func (c *Comments) processNew(ctx context.Context, n v1.New, u user.User) (*v1.N
 		Comment:   n.Comment,
 		PublicKey: n.PublicKey,
 		Signature: n.Signature,
+		ReplayProtection: convertReplayProtectionToPlugin(
+			n.ReplayProtection),
 	}
 	pdc, err := c.politeiad.CommentNew(ctx, cn)
 	if err != nil {
@@ -113,6 +139,13 @@ func (c *Comments) processVote(ctx context.Context, v v1.Vote, u user.User) (*v1
 		}
 	}
 
+	// Verify user is eligible to vote on comments
+	err := verifyEligible(u, c.cfg.CommentVoteAccountAge,
+		c.cfg.CommentVoteStake, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
 	// Execute pre plugin hooks. Checking the mode is a temporary
 	// measure until user plugins have been properly implemented.
 	switch c.cfg.Mode {
@@ -131,6 +164,12 @@ func (c *Comments) processVote(ctx context.Context, v v1.Vote, u user.User) (*v1
 		}
 	}
 
+	// Verify the request is not a replay
+	err = c.verifyNonce(v.PublicKey, v.ReplayProtection)
+	if err != nil {
+		return nil, err
+	}
+
 	// Send plugin command
 	cv := comments.Vote{
 		UserID:    u.ID.String(),
@@ -140,6 +179,8 @@ func (c *Comments) processVote(ctx context.Context, v v1.Vote, u user.User) (*v1
 		Vote:      comments.VoteT(v.Vote),
 		PublicKey: v.PublicKey,
 		Signature: v.Signature,
+		ReplayProtection: convertReplayProtectionToPlugin(
+			v.ReplayProtection),
 	}
 	vr, err := c.politeiad.CommentVote(ctx, cv)
 	if err != nil {
@@ -191,6 +232,13 @@ func (c *Comments) processDel(ctx context.Context, d v1.Del, u user.User) (*v1.D
 	cm := convertComment(cdr.Comment)
 	commentPopulateUserData(&cm, u)
 
+	// Emit event
+	c.events.Emit(EventTypeDel,
+		EventDel{
+			State:   d.State,
+			Comment: cm,
+		})
+
 	return &v1.DelReply{
 		Comment: cm,
 	}, nil
@@ -226,7 +274,7 @@ func (c *Comments) processCount(ctx context.Context, ct v1.Count) (*v1.CountRepl
 }
 
 func (c *Comments) processComments(ctx context.Context, cs v1.Comments, u *user.User) (*v1.CommentsReply, error) {
-	log.Tracef("processComments: %v", cs.Token)
+	log.Tracef("processComments: %v %v", cs.Token, cs.Flatten)
 
 	// Send plugin command
 	pcomments, err := c.politeiad.CommentsGetAll(ctx, cs.Token)
@@ -291,6 +339,10 @@ func (c *Comments) processComments(ctx context.Context, cs v1.Comments, u *user.
 		comments = append(comments, cm)
 	}
 
+	if cs.Flatten {
+		flattenComments(comments)
+	}
+
 	return &v1.CommentsReply{
 		Comments: comments,
 	}, nil
@@ -408,6 +460,103 @@ func (c *Comments) processTimestamps(ctx context.Context, t v1.Timestamps, isAdm
 	}, nil
 }
 
+func (c *Comments) processExport(ctx context.Context, e v1.Export) (*v1.ExportReply, error) {
+	log.Tracef("processExport: %v", e.Token)
+
+	// Only public records can be exported
+	r, err := c.recordNoFiles(ctx, e.Token)
+	if err != nil {
+		if err == errRecordNotFound {
+			return nil, v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeRecordNotFound,
+			}
+		}
+		return nil, err
+	}
+	if r.State != pdv2.RecordStateVetted {
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeRecordStateInvalid,
+			ErrorContext: "record is not public",
+		}
+	}
+
+	// Get comments
+	cr, err := c.processComments(ctx, v1.Comments{Token: e.Token}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get comment timestamps. The timestamps are requested in pages.
+	commentIDs := make([]uint32, 0, len(cr.Comments))
+	for _, v := range cr.Comments {
+		commentIDs = append(commentIDs, v.CommentID)
+	}
+	timestamps := make(map[uint32]v1.CommentTimestamp, len(commentIDs))
+	for len(commentIDs) > 0 {
+		pageSize := int(v1.TimestampsPageSize)
+		if len(commentIDs) < pageSize {
+			pageSize = len(commentIDs)
+		}
+		t := v1.Timestamps{
+			Token:      e.Token,
+			CommentIDs: commentIDs[:pageSize],
+		}
+		tr, err := c.processTimestamps(ctx, t, false)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range tr.Comments {
+			timestamps[k] = v
+		}
+		commentIDs = commentIDs[pageSize:]
+	}
+
+	// Prepare the manifest
+	cb, err := json.Marshal(cr.Comments)
+	if err != nil {
+		return nil, err
+	}
+	tb, err := json.Marshal(timestamps)
+	if err != nil {
+		return nil, err
+	}
+	m := v1.ExportManifest{
+		Token:            e.Token,
+		Timestamp:        time.Now().Unix(),
+		CommentsCount:    uint32(len(cr.Comments)),
+		CommentsDigest:   hex.EncodeToString(util.Digest(cb)),
+		TimestampsDigest: hex.EncodeToString(util.Digest(tb)),
+		PoliteiadPubKey:  c.politeiad.PublicIdentity().String(),
+		ServerPubKey:     c.cfg.ServerIdentity.Public.String(),
+	}
+	prevID := c.cfg.RotationIdentity()
+	if prevID != nil {
+		m.PrevServerPubKey = prevID.Public.String()
+	}
+
+	// Sign the manifest. The manifest is also signed using the
+	// previous signing identity during an identity rotation.
+	mb, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	msg := hex.EncodeToString(util.Digest(mb))
+	sig := c.cfg.ServerIdentity.SignMessage([]byte(msg))
+	var prevSig string
+	if prevID != nil {
+		s := prevID.SignMessage([]byte(msg))
+		prevSig = hex.EncodeToString(s[:])
+	}
+
+	return &v1.ExportReply{
+		Manifest:      m,
+		Signature:     hex.EncodeToString(sig[:]),
+		PrevSignature: prevSig,
+		Comments:      cr.Comments,
+		Timestamps:    timestamps,
+	}, nil
+}
+
 var (
 	errRecordNotFound = errors.New("record not found")
 )
@@ -434,6 +583,34 @@ func (c *Comments) recordNoFiles(ctx context.Context, token string) (*pdv2.Recor
 	return &r, nil
 }
 
+// flattenComments sorts the comments into thread order and sets the thread ID
+// and the depth of each comment. The threads are ordered by the comment ID of
+// their base level comment and the comments of a thread are ordered by
+// comment ID.
+func flattenComments(cs []v1.Comment) {
+	// A reply always has a larger comment ID than its parent, so the
+	// parent of a comment has been processed before the comment when
+	// the comments are processed in comment ID order.
+	sort.Slice(cs, func(i, j int) bool {
+		return cs[i].CommentID < cs[j].CommentID
+	})
+	idx := make(map[uint32]int, len(cs)) // [commentID]index
+	for i, v := range cs {
+		p, ok := idx[v.ParentID]
+		if v.ParentID == 0 || !ok {
+			cs[i].ThreadID = v.CommentID
+			cs[i].Depth = 1
+		} else {
+			cs[i].ThreadID = cs[p].ThreadID
+			cs[i].Depth = cs[p].Depth + 1
+		}
+		idx[v.CommentID] = i
+	}
+	sort.SliceStable(cs, func(i, j int) bool {
+		return cs[i].ThreadID < cs[j].ThreadID
+	})
+}
+
 // commentPopulateUserData populates the comment with user data that is not
 // stored in politeiad.
 func commentPopulateUserData(c *v1.Comment, u user.User) {
@@ -448,6 +625,45 @@ func commentVotePopulateUserData(votes []v1.CommentVote, u user.User) {
 	}
 }
 
+// verifyNonce verifies the replay protection of a signed request. The nonce
+// is scoped to the public key that signed the request.
+func (c *Comments) verifyNonce(publicKey string, rp v1.ReplayProtection) error {
+	err := c.nonces.Verify(publicKey, rp.Nonce, rp.Expiry,
+		c.policy.NonceRequired, time.Now())
+	var ve nonces.VerifyError
+	if errors.As(err, &ve) {
+		return v1.UserErrorReply{
+			ErrorCode:    convertNonceErrorCode(ve.ErrorCode),
+			ErrorContext: ve.ErrorContext,
+		}
+	}
+	return err
+}
+
+func convertNonceErrorCode(e nonces.ErrorCodeT) v1.ErrorCodeT {
+	switch e {
+	case nonces.ErrorCodeExpired:
+		return v1.ErrorCodeNonceExpired
+	case nonces.ErrorCodeUsed:
+		return v1.ErrorCodeNonceUsed
+	}
+	return v1.ErrorCodeNonceInvalid
+}
+
+func convertReplayProtectionToPlugin(rp v1.ReplayProtection) comments.ReplayProtection {
+	return comments.ReplayProtection{
+		Nonce:  rp.Nonce,
+		Expiry: rp.Expiry,
+	}
+}
+
+func convertReplayProtectionToV1(rp comments.ReplayProtection) v1.ReplayProtection {
+	return v1.ReplayProtection{
+		Nonce:  rp.Nonce,
+		Expiry: rp.Expiry,
+	}
+}
+
 func convertStateToPlugin(s v1.RecordStateT) comments.RecordStateT {
 	switch s {
 	case v1.RecordStateUnvetted:
@@ -489,6 +705,8 @@ func convertComment(c comments.Comment) v1.Comment {
 		Reason:        c.Reason,
 		ExtraData:     c.ExtraData,
 		ExtraDataHint: c.ExtraDataHint,
+		ReplayProtection: convertReplayProtectionToV1(
+			c.ReplayProtection),
 	}
 }
 
@@ -505,6 +723,8 @@ func convertCommentVotes(cv []comments.CommentVote) []v1.CommentVote {
 			Signature: v.Signature,
 			Timestamp: v.Timestamp,
 			Receipt:   v.Receipt,
+			ReplayProtection: convertReplayProtectionToV1(
+				v.ReplayProtection),
 		})
 	}
 	return c
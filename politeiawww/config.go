@@ -25,7 +25,12 @@ import (
 
 	"github.com/decred/dcrd/hdkeychain/v3"
 	"github.com/decred/politeia/politeiad/api/v1/identity"
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
 	"github.com/decred/politeia/politeiawww/config"
+	wwwmail "github.com/decred/politeia/politeiawww/mail"
+	"github.com/decred/politeia/politeiawww/passwords"
+	"github.com/decred/politeia/politeiawww/scanner"
+	"github.com/decred/politeia/politeiawww/sessions"
 	"github.com/decred/politeia/util/version"
 
 	v1 "github.com/decred/politeia/politeiad/api/v1"
@@ -40,6 +45,10 @@ const (
 	adminLogFilename        = "admin.log"
 	defaultIdentityFilename = "identity.json"
 
+	// defaultSigningIdentityFilename is the filename of the politeiawww
+	// signing identity. This is not the politeiad identity.
+	defaultSigningIdentityFilename = "signingidentity.json"
+
 	defaultMainnetPort = "4443"
 	defaultTestnetPort = "4443"
 
@@ -54,6 +63,9 @@ const (
 	defaultMailAddressPi  = "Politeia <noreply@example.org>"
 	defaultMailAddressCMS = "Contractor Management System <noreply@example.org>"
 
+	defaultSiteNamePi  = "Politeia"
+	defaultSiteNameCMS = "Contractor Management System"
+
 	defaultDcrdataMainnet = "dcrdata.decred.org:443"
 	defaultDcrdataTestnet = "testnet.decred.org:443"
 
@@ -76,8 +88,114 @@ const (
 	defaultMySQLDBHost     = "localhost:3306"  // MySQL default host
 	defaultCockroachDBHost = "localhost:26257" // CockroachDB default host
 
+	// Session store options
+	defaultSessionStore = sessions.StoreUserDB
+	defaultRedisHost    = "localhost:6379"
+	defaultRedisPort    = "6379"
+
 	// Environment variables.
 	envDBPass = "DBPASS"
+
+	// defaultSimilarityThreshold is the default minimum similarity
+	// score for a proposal to be reported as a likely duplicate.
+	defaultSimilarityThreshold = 0.6
+
+	// defaultVettingSLA is the default number of hours that a proposal
+	// can await vetting before it is flagged as an SLA breach.
+	defaultVettingSLA = 72
+
+	// defaultVoteTallyInterval is the default number of blocks between
+	// the vote tally snapshots of the active votes.
+	defaultVoteTallyInterval = 12
+
+	// defaultSummaryCacheTTL is the default number of seconds that the
+	// vote summaries and comment counts are cached for.
+	defaultSummaryCacheTTL = 30
+
+	// defaultCommentCountReconcile is the default number of minutes
+	// between the reconciliations of the comment count cache. The
+	// comments that are made on other politeiawww instances are only
+	// reflected in the cache once it has been reconciled.
+	defaultCommentCountReconcile = 2
+
+	// The following are the default automatic temporary ban settings.
+	// A client address is banned for defaultBanDuration minutes after
+	// defaultAuthFailMax failed login attempts within
+	// defaultAuthFailWindow minutes.
+	defaultAuthFailMax    = 10
+	defaultAuthFailWindow = 15
+	defaultBanDuration    = 60
+
+	// defaultMailLogRetention is the default number of days that the
+	// notification email send attempts are kept in the send log.
+	defaultMailLogRetention = 30
+
+	// defaultVerificationResendInterval is the default minimum number
+	// of minutes between new user verification emails that are sent to
+	// the same email address.
+	defaultVerificationResendInterval = 10
+
+	// defaultUnverifiedRetention is the default number of days after
+	// the new user verification token expires that an unverified
+	// account is deactivated.
+	defaultUnverifiedRetention = 30
+
+	// defaultMaintenanceRetryAfter is the default number of seconds
+	// that clients are told to wait before retrying a write request
+	// that was rejected by maintenance mode.
+	defaultMaintenanceRetryAfter = 300
+
+	// defaultShutdownTimeout is the default maximum number of seconds
+	// that politeiawww waits for in-flight requests and queued
+	// notifications to finish on shutdown.
+	defaultShutdownTimeout = 30
+
+	// defaultBotCommentsPerHour is the default maximum number of
+	// comments that a bot account is allowed to submit per hour.
+	defaultBotCommentsPerHour = 10
+
+	// defaultScannerTimeout is the default maximum number of seconds
+	// that the content scan of a single file may take.
+	defaultScannerTimeout = 30
+
+	// defaultPasswordMinScore is the default minimum strength score of
+	// a user password.
+	defaultPasswordMinScore = 2
+
+	// defaultWebhookRetries is the default number of times that a
+	// failed webhook delivery is retried.
+	defaultWebhookRetries = 5
+
+	// defaultTokenPrefixLength is the default length of the short token
+	// prefix of a record token. It must match the politeiad setting.
+	defaultTokenPrefixLength = www.TokenPrefixLength
+
+	// defaultWebCSP is the default Content-Security-Policy header of the
+	// web frontend responses. The frontend may only load resources from
+	// politeiawww itself and can't be embedded into other sites.
+	defaultWebCSP = "default-src 'self'; img-src 'self' data:; " +
+		"style-src 'self' 'unsafe-inline'; object-src 'none'; " +
+		"base-uri 'self'; frame-ancestors 'none'"
+)
+
+var (
+	// defaultTelemetryClients contains the official clients that are
+	// allowed to submit telemetry reports by default.
+	defaultTelemetryClients = []string{
+		"politeiagui",
+		"pictl",
+		"politeiavoter",
+	}
+
+	// defaultRateLimits contains the default rate limits of the rate
+	// limit buckets. They are overridden by the ratelimit settings of
+	// the config file.
+	defaultRateLimits = []string{
+		"login:10:0",
+		"signup:5:0",
+		"comment:30:10",
+		"report:2:2",
+	}
 )
 
 var (
@@ -267,6 +385,77 @@ func loadIdentity(cfg *config.Config) error {
 	return nil
 }
 
+// loadSigningIdentity loads the politeiawww signing identity. A new identity
+// is created if one does not exist yet.
+func loadSigningIdentity(cfg *config.Config) error {
+	if cfg.SigningIdentity == "" {
+		cfg.SigningIdentity = filepath.Join(cfg.HomeDir,
+			defaultSigningIdentityFilename)
+	} else {
+		cfg.SigningIdentity = util.CleanAndExpandPath(cfg.SigningIdentity)
+	}
+
+	if !util.FileExists(cfg.SigningIdentity) {
+		log.Infof("Generating signing identity...")
+		id, err := identity.New()
+		if err != nil {
+			return err
+		}
+		err = id.Save(cfg.SigningIdentity)
+		if err != nil {
+			return err
+		}
+		log.Infof("Signing identity created...")
+	}
+
+	var err error
+	cfg.ServerIdentity, err = identity.LoadFullIdentity(cfg.SigningIdentity)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Signing identity loaded from: %v", cfg.SigningIdentity)
+	log.Infof("Signing public key: %x", cfg.ServerIdentity.Public.Key)
+
+	// Load the previous signing identity if an identity rotation is
+	// in progress.
+	if cfg.PrevSigningID == "" {
+		if cfg.RotationEnd != "" {
+			return fmt.Errorf("rotationend requires prevsigningidentity")
+		}
+		return nil
+	}
+	if cfg.RotationEnd == "" {
+		return fmt.Errorf("prevsigningidentity requires rotationend")
+	}
+	end, err := time.Parse(time.RFC3339, cfg.RotationEnd)
+	if err != nil {
+		return fmt.Errorf("invalid rotationend: %v", err)
+	}
+	cfg.PrevSigningID = util.CleanAndExpandPath(cfg.PrevSigningID)
+	cfg.PrevServerIdentity, err = identity.LoadFullIdentity(cfg.PrevSigningID)
+	if err != nil {
+		return fmt.Errorf("load prevsigningidentity: %v", err)
+	}
+	if cfg.PrevServerIdentity.Public.String() ==
+		cfg.ServerIdentity.Public.String() {
+		return fmt.Errorf("prevsigningidentity is the same as the " +
+			"signing identity")
+	}
+	cfg.RotationEndTime = end.Unix()
+
+	log.Infof("Previous signing public key: %x",
+		cfg.PrevServerIdentity.Public.Key)
+	if time.Now().Unix() >= cfg.RotationEndTime {
+		log.Warnf("Signing identity rotation ended at %v; the previous "+
+			"signing identity is no longer used", end.UTC())
+	} else {
+		log.Infof("Signing identity rotation ends at %v", end.UTC())
+	}
+
+	return nil
+}
+
 // validateEncryptionKeys validates the encryption keys config and returns
 // the keys' cleaned paths.
 func validateEncryptionKeys(encKey, oldEncKey string) error {
@@ -321,22 +510,44 @@ func validateDBHost(host string) error {
 func loadConfig() (*config.Config, []string, error) {
 	// Default config.
 	cfg := config.Config{
-		HomeDir:                  config.DefaultHomeDir,
-		ConfigFile:               config.DefaultConfigFile,
-		DebugLevel:               defaultLogLevel,
-		DataDir:                  config.DefaultDataDir,
-		LogDir:                   defaultLogDir,
-		HTTPSKey:                 defaultHTTPSKeyFile,
-		HTTPSCert:                config.DefaultHTTPSCertFile,
-		RPCCert:                  defaultRPCCertFile,
-		CookieKeyFile:            defaultCookieKeyFile,
-		Version:                  version.String(),
-		Mode:                     defaultWWWMode,
-		UserDB:                   defaultUserDB,
-		PaywallAmount:            defaultPaywallAmount,
-		MinConfirmationsRequired: defaultPaywallMinConfirmations,
-		VoteDurationMin:          defaultVoteDurationMin,
-		VoteDurationMax:          defaultVoteDurationMax,
+		HomeDir:                    config.DefaultHomeDir,
+		ConfigFile:                 config.DefaultConfigFile,
+		DebugLevel:                 defaultLogLevel,
+		DataDir:                    config.DefaultDataDir,
+		LogDir:                     defaultLogDir,
+		HTTPSKey:                   defaultHTTPSKeyFile,
+		HTTPSCert:                  config.DefaultHTTPSCertFile,
+		RPCCert:                    defaultRPCCertFile,
+		CookieKeyFile:              defaultCookieKeyFile,
+		Version:                    version.String(),
+		Mode:                       defaultWWWMode,
+		UserDB:                     defaultUserDB,
+		SessionStore:               defaultSessionStore,
+		RedisHost:                  defaultRedisHost,
+		PaywallAmount:              defaultPaywallAmount,
+		MinConfirmationsRequired:   defaultPaywallMinConfirmations,
+		VoteDurationMin:            defaultVoteDurationMin,
+		VoteDurationMax:            defaultVoteDurationMax,
+		SimilarityThreshold:        defaultSimilarityThreshold,
+		VettingSLA:                 defaultVettingSLA,
+		VoteTallyInterval:          defaultVoteTallyInterval,
+		SummaryCacheTTL:            defaultSummaryCacheTTL,
+		CommentCountReconcile:      defaultCommentCountReconcile,
+		AuthFailMax:                defaultAuthFailMax,
+		AuthFailWindow:             defaultAuthFailWindow,
+		BanDuration:                defaultBanDuration,
+		WebhookRetries:             defaultWebhookRetries,
+		MailProvider:               wwwmail.ProviderSMTP,
+		MailLogRetention:           defaultMailLogRetention,
+		VerificationResendInterval: defaultVerificationResendInterval,
+		UnverifiedRetention:        defaultUnverifiedRetention,
+		MaintenanceRetryAfter:      defaultMaintenanceRetryAfter,
+		ShutdownTimeout:            defaultShutdownTimeout,
+		ScannerTimeout:             defaultScannerTimeout,
+		BotCommentsPerHour:         defaultBotCommentsPerHour,
+		PasswordMinLength:          www.PolicyMinPasswordLength,
+		PasswordMinScore:           defaultPasswordMinScore,
+		TokenPrefixLength:          defaultTokenPrefixLength,
 	}
 
 	// Service options which are only added on Windows.
@@ -455,10 +666,16 @@ func loadConfig() (*config.Config, []string, error) {
 		if cfg.MailAddress == "" {
 			cfg.MailAddress = defaultMailAddressCMS
 		}
+		if cfg.SiteName == "" {
+			cfg.SiteName = defaultSiteNameCMS
+		}
 	case config.PoliteiaWWWMode:
 		if cfg.MailAddress == "" {
 			cfg.MailAddress = defaultMailAddressPi
 		}
+		if cfg.SiteName == "" {
+			cfg.SiteName = defaultSiteNamePi
+		}
 	default:
 		err := fmt.Errorf("invalid mode: %v", cfg.Mode)
 		fmt.Fprintln(os.Stderr, err)
@@ -539,6 +756,79 @@ func loadConfig() (*config.Config, []string, error) {
 	cfg.HTTPSCert = util.CleanAndExpandPath(cfg.HTTPSCert)
 	cfg.RPCCert = util.CleanAndExpandPath(cfg.RPCCert)
 
+	// Verify the token prefix length. The short token length is set
+	// globally since it's used for token validation throughout.
+	err = util.SetShortTokenLength(cfg.TokenPrefixLength)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid tokenprefixlength: %v", err)
+	}
+
+	// Verify the legacy proposal settings
+	if cfg.LegacyTokens != "" {
+		cfg.LegacyTokens = util.CleanAndExpandPath(cfg.LegacyTokens)
+	}
+	if cfg.LegacyRedirectURL != "" {
+		if cfg.LegacyTokens == "" {
+			return nil, nil, fmt.Errorf("legacyredirecturl requires " +
+				"legacytokens to be set")
+		}
+		u, err := url.Parse(cfg.LegacyRedirectURL)
+		if err != nil || !u.IsAbs() || u.Host == "" {
+			return nil, nil, fmt.Errorf("invalid legacyredirecturl: %v",
+				cfg.LegacyRedirectURL)
+		}
+		cfg.LegacyRedirectURL = strings.TrimSuffix(cfg.LegacyRedirectURL, "/")
+	}
+
+	// Verify the content scanning settings
+	if cfg.Scanner != "" {
+		if cfg.ScannerTimeout == 0 {
+			return nil, nil, fmt.Errorf("scannertimeout must be positive")
+		}
+		_, err := scanner.New(cfg.Scanner,
+			time.Duration(cfg.ScannerTimeout)*time.Second)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid scanner: %v", err)
+		}
+	}
+
+	// Verify the password policy settings
+	if cfg.PasswordMinLength < www.PolicyMinPasswordLength {
+		return nil, nil, fmt.Errorf("passwordminlength must be at least %v",
+			www.PolicyMinPasswordLength)
+	}
+	if cfg.PasswordMinScore > passwords.ScoreMax {
+		return nil, nil, fmt.Errorf("passwordminscore must be between 0 "+
+			"and %v", passwords.ScoreMax)
+	}
+	if cfg.PasswordBreachURL != "" {
+		u, err := url.Parse(cfg.PasswordBreachURL)
+		if err != nil || !u.IsAbs() || u.Host == "" {
+			return nil, nil, fmt.Errorf("invalid passwordbreachurl: %v",
+				cfg.PasswordBreachURL)
+		}
+	}
+
+	if cfg.MetricsListen != "" {
+		if !cfg.Metrics {
+			return nil, nil, fmt.Errorf("metricslisten requires metrics " +
+				"to be set")
+		}
+		_, _, err := net.SplitHostPort(cfg.MetricsListen)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid metricslisten: %v", err)
+		}
+	}
+
+	if cfg.TracingEndpoint != "" {
+		u, err := url.Parse(cfg.TracingEndpoint)
+		if err != nil || u.Host == "" ||
+			(u.Scheme != "http" && u.Scheme != "https") {
+			return nil, nil, fmt.Errorf("invalid tracingendpoint: %v",
+				cfg.TracingEndpoint)
+		}
+	}
+
 	if cfg.CodeStatStart > 0 &&
 		(time.Unix(cfg.CodeStatStart, 0).Before(codeStatCheck) ||
 			time.Unix(cfg.CodeStatStart, 0).After(time.Now())) {
@@ -633,18 +923,33 @@ func loadConfig() (*config.Config, []string, error) {
 			"with --rpcpass")
 	}
 
-	// Verify mail settings
-	switch {
-	case cfg.MailHost == "" && cfg.MailUser == "" &&
-		cfg.MailPass == "" && cfg.WebServerAddress == "":
-		// Email is disabled; this is ok
-	case cfg.MailHost != "" && cfg.MailUser != "" &&
-		cfg.MailPass != "" && cfg.WebServerAddress != "":
-		// All mail settings have been set; this is ok
-	default:
-		return nil, nil, fmt.Errorf("either all or none of the " +
-			"following config options should be supplied: " +
-			"mailhost, mailuser, mailpass, webserveraddress")
+	// Verify mail settings. Email is enabled when the settings of the
+	// mail provider have been set.
+	mailEnabled, err := verifyMailProvider(&cfg, cfg.MailProvider)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.MailFallback != "" {
+		if !mailEnabled {
+			return nil, nil, fmt.Errorf("mailfallback requires the " +
+				"mailprovider settings to be supplied")
+		}
+		if cfg.MailFallback == cfg.MailProvider {
+			return nil, nil, fmt.Errorf("mailfallback must be a different " +
+				"provider than mailprovider")
+		}
+		ok, err := verifyMailProvider(&cfg, cfg.MailFallback)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			return nil, nil, fmt.Errorf("the settings of the mailfallback "+
+				"provider %v must be supplied", cfg.MailFallback)
+		}
+	}
+	if mailEnabled != (cfg.WebServerAddress != "") {
+		return nil, nil, fmt.Errorf("webserveraddress must be supplied " +
+			"if and only if email is enabled")
 	}
 
 	u, err = url.Parse(cfg.MailHost)
@@ -811,6 +1116,31 @@ func loadConfig() (*config.Config, []string, error) {
 			"be leveldb, cockroachdb or mysql", cfg.UserDB)
 	}
 
+	// Verify the session store settings
+	switch cfg.SessionStore {
+	case sessions.StoreUserDB:
+		// Nothing to verify
+	case sessions.StoreRedis:
+		cfg.RedisHost = util.NormalizeAddress(cfg.RedisHost, defaultRedisPort)
+		if cfg.RedisDB < 0 {
+			return nil, nil, fmt.Errorf("redisdb must not be negative")
+		}
+		if cfg.RedisCert != "" {
+			if !cfg.RedisTLS {
+				return nil, nil, fmt.Errorf("rediscert requires redistls")
+			}
+			cfg.RedisCert = util.CleanAndExpandPath(cfg.RedisCert)
+		}
+		if cfg.RedisPassword != "" && !cfg.RedisTLS {
+			log.Warnf("The redis password is sent in clear text; " +
+				"enable redistls")
+		}
+	default:
+		return nil, nil, fmt.Errorf("invalid sessionstore '%v'; must "+
+			"be %v or %v", cfg.SessionStore, sessions.StoreUserDB,
+			sessions.StoreRedis)
+	}
+
 	// Verify paywall settings
 	paywallIsEnabled := cfg.PaywallAmount != 0 || cfg.PaywallXpub != ""
 	if paywallIsEnabled {
@@ -844,11 +1174,86 @@ func loadConfig() (*config.Config, []string, error) {
 		}
 	}
 
+	// Verify the proposal similarity threshold
+	if cfg.SimilarityThreshold <= 0 || cfg.SimilarityThreshold > 1 {
+		return nil, nil, fmt.Errorf("invalid similarity threshold %v; "+
+			"must be greater than 0 and less than or equal to 1",
+			cfg.SimilarityThreshold)
+	}
+
+	// Verify the site branding settings
+	if cfg.SiteLogoURL != "" {
+		u, err := url.Parse(cfg.SiteLogoURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") ||
+			u.Host == "" {
+			return nil, nil, fmt.Errorf("invalid sitelogourl %v",
+				cfg.SiteLogoURL)
+		}
+	}
+
+	// Setup the rate limits. The config file settings are added after
+	// the defaults so that they take precedence.
+	rateLimits := make([]string, 0, len(defaultRateLimits)+len(cfg.RateLimits))
+	rateLimits = append(rateLimits, defaultRateLimits...)
+	cfg.RateLimits = append(rateLimits, cfg.RateLimits...)
+
+	// Setup telemetry clients
+	if cfg.Telemetry && len(cfg.TelemetryClients) == 0 {
+		cfg.TelemetryClients = defaultTelemetryClients
+	}
+
+	// Verify the webhook settings
+	for _, v := range cfg.WebhookURLs {
+		u, err := url.Parse(v)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+			return nil, nil, fmt.Errorf("invalid webhookurl %v", v)
+		}
+	}
+	if len(cfg.WebhookURLs) > 0 && cfg.WebhookSecret == "" {
+		return nil, nil, fmt.Errorf("webhooksecret must be set when " +
+			"webhookurl is set")
+	}
+
+	// Verify the treasury handoff settings
+	if cfg.TreasuryURL != "" {
+		u, err := url.Parse(cfg.TreasuryURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+			return nil, nil, fmt.Errorf("invalid treasuryurl %v",
+				cfg.TreasuryURL)
+		}
+		if cfg.TreasurySecret == "" {
+			return nil, nil, fmt.Errorf("treasurysecret must be set " +
+				"when treasuryurl is set")
+		}
+	}
+
+	// Setup the web frontend settings
+	if cfg.WebRoot != "" {
+		cfg.WebRoot = util.CleanAndExpandPath(cfg.WebRoot)
+		if cfg.WebCSP == "" {
+			cfg.WebCSP = defaultWebCSP
+		}
+	}
+
+	// Verify the notifier settings. The events and notifier names are
+	// verified when the routes are setup.
+	for _, v := range cfg.Notifiers {
+		_, _, err := parseNotifier(v)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// Load identity
 	if err := loadIdentity(&cfg); err != nil {
 		return nil, nil, err
 	}
 
+	// Load signing identity
+	if err := loadSigningIdentity(&cfg); err != nil {
+		return nil, nil, err
+	}
+
 	// Warn about missing config file only after all other configuration is
 	// done.  This prevents the warning on help messages and invalid
 	// options.  Note this should go directly before the return.
@@ -859,6 +1264,45 @@ func loadConfig() (*config.Config, []string, error) {
 	return &cfg, remainingArgs, nil
 }
 
+// verifyMailProvider verifies the settings of the provided mail provider. It
+// returns true when all of the provider settings have been supplied and false
+// when none of them have been supplied.
+func verifyMailProvider(cfg *config.Config, provider string) (bool, error) {
+	var (
+		names    string
+		settings []string
+	)
+	switch provider {
+	case wwwmail.ProviderSMTP:
+		names = "mailhost, mailuser, mailpass"
+		settings = []string{cfg.MailHost, cfg.MailUser, cfg.MailPass}
+	case wwwmail.ProviderSendGrid:
+		names = "sendgridkey"
+		settings = []string{cfg.SendGridKey}
+	case wwwmail.ProviderSES:
+		names = "sesregion, sesaccesskey, sessecretkey"
+		settings = []string{cfg.SESRegion, cfg.SESAccessKey, cfg.SESSecretKey}
+	default:
+		return false, fmt.Errorf("invalid mail provider '%v'", provider)
+	}
+
+	var set int
+	for _, v := range settings {
+		if v != "" {
+			set++
+		}
+	}
+	switch set {
+	case 0:
+		return false, nil
+	case len(settings):
+		return true, nil
+	}
+
+	return false, fmt.Errorf("either all or none of the following config "+
+		"options should be supplied: %v", names)
+}
+
 func (p *politeiawww) dcrdataHostHTTP() string {
 	return fmt.Sprintf("https://%v/api", p.cfg.DcrdataHost)
 }
@@ -866,3 +1310,21 @@ func (p *politeiawww) dcrdataHostHTTP() string {
 func (p *politeiawww) dcrdataHostWS() string {
 	return fmt.Sprintf("wss://%v/ps", p.cfg.DcrdataHost)
 }
+
+// parseNotifier parses a notifier config option in the format
+// event:notifier[,notifier] and returns the event and notifier names.
+func parseNotifier(s string) (string, []string, error) {
+	i := strings.Index(s, ":")
+	if i <= 0 || i == len(s)-1 {
+		return "", nil, fmt.Errorf("invalid notifier %v: must be in the "+
+			"format event:notifier[,notifier]", s)
+	}
+	names := strings.Split(s[i+1:], ",")
+	for _, v := range names {
+		if v == "" {
+			return "", nil, fmt.Errorf("invalid notifier %v: empty "+
+				"notifier name", s)
+		}
+	}
+	return s[:i], names, nil
+}
@@ -8,6 +8,7 @@ package config
 import (
 	"crypto/x509"
 	"path/filepath"
+	"time"
 
 	"github.com/decred/dcrd/dcrutil/v3"
 	"github.com/decred/politeia/politeiad/api/v1/identity"
@@ -65,6 +66,9 @@ type Config struct {
 	RPCUser         string   `long:"rpcuser" description:"RPC user name for privileged politeaid commands"`
 	RPCPass         string   `long:"rpcpass" description:"RPC password for privileged politeiad commands"`
 	FetchIdentity   bool     `long:"fetchidentity" description:"Whether or not politeiawww fetches the identity from politeiad."`
+	SigningIdentity string   `long:"signingidentity" description:"Path to file containing the politeiawww signing identity (created if it does not exist)"`
+	PrevSigningID   string   `long:"prevsigningidentity" description:"Path to file containing the previous politeiawww signing identity during an identity rotation"`
+	RotationEnd     string   `long:"rotationend" description:"RFC 3339 time at which the identity rotation ends and the previous signing identity is no longer used"`
 	Interactive     string   `long:"interactive" description:"Set to i-know-this-is-a-bad-idea to turn off interactive mode during --fetchidentity."`
 	AdminLogFile    string   `long:"adminlogfile" description:"admin log filename (Default: admin.log)"`
 	Mode            string   `long:"mode" description:"Mode www runs as. Supported values: piwww, cmswww"`
@@ -79,6 +83,14 @@ type Config struct {
 	EncryptionKey    string `long:"encryptionkey" description:"File containing encryption key used for encrypting user data at rest"`
 	OldEncryptionKey string `long:"oldencryptionkey" description:"File containing old encryption key (only set when rotating keys)"`
 
+	// Session store settings
+	SessionStore  string `long:"sessionstore" description:"Storage backend of the user sessions; supported values: userdb, redis"`
+	RedisHost     string `long:"redishost" description:"Redis server address in this format: <host>:<port>"`
+	RedisPassword string `long:"redispass" description:"Redis server password"`
+	RedisDB       int    `long:"redisdb" description:"Redis database number"`
+	RedisTLS      bool   `long:"redistls" description:"Use TLS for the connections to the Redis server"`
+	RedisCert     string `long:"rediscert" description:"File containing the CA certificate of the Redis server; the system CAs are used when not set"`
+
 	// SMTP settings
 	MailHost         string `long:"mailhost" description:"Email server address in this format: <host>:<port>"`
 	MailUser         string `long:"mailuser" description:"Email server username"`
@@ -88,6 +100,57 @@ type Config struct {
 	MailSkipVerify   bool   `long:"mailskipverify" description:"Skip TLS verification when connecting to the mail server"`
 	WebServerAddress string `long:"webserveraddress" description:"Web server address used to create email links (format: <scheme>://<host>[:<port>])"`
 
+	// Mail provider settings
+	MailProvider string `long:"mailprovider" description:"Mail provider that sends the emails; supported values: smtp, sendgrid, ses"`
+	MailFallback string `long:"mailfallback" description:"Mail provider that sends the emails that the mailprovider fails to send; supported values: smtp, sendgrid, ses"`
+	SendGridKey  string `long:"sendgridkey" description:"SendGrid API key"`
+	SESRegion    string `long:"sesregion" description:"Amazon SES region, e.g. us-east-1"`
+	SESAccessKey string `long:"sesaccesskey" description:"Amazon SES access key ID"`
+	SESSecretKey string `long:"sessecretkey" description:"Amazon SES secret access key"`
+
+	// Site branding settings
+	SiteName    string `long:"sitename" description:"Name of the deployment that is displayed by clients (default: Politeia or Contractor Management System)"`
+	SiteLogoURL string `long:"sitelogourl" description:"URL of the logo of the deployment"`
+	SiteContact string `long:"sitecontact" description:"Contact of the deployment operator, e.g. an email address or a URL"`
+
+	// Mail bounce and complaint settings
+	MailFeedbackToken string `long:"mailfeedbacktoken" description:"Shared secret required by the mail bounce and complaint webhook; the webhook is disabled when not set"`
+
+	// Mail send log settings
+	MailLogRetention uint32 `long:"maillogretention" description:"Number of days that the notification email send attempts are logged for; the send log is disabled when set to 0"`
+
+	// Verification token settings
+	VerificationResendInterval uint32 `long:"verificationresendinterval" description:"Minimum number of minutes between new user verification emails sent to the same email address"`
+	UnverifiedRetention        uint32 `long:"unverifiedretention" description:"Number of days after the new user verification token expires that an unverified account is deactivated; unverified accounts are never deactivated when set to 0"`
+
+	// Maintenance mode settings
+	Maintenance           bool   `long:"maintenance" description:"Start in read-only maintenance mode; write routes return a 503 until maintenance mode is disabled by an admin"`
+	MaintenanceRetryAfter uint32 `long:"maintenanceretryafter" description:"Number of seconds that clients are told to wait before retrying a write request that was rejected by maintenance mode"`
+
+	// Shutdown settings
+	ShutdownTimeout uint32 `long:"shutdowntimeout" description:"Maximum number of seconds to wait for in-flight requests and queued notifications to finish on shutdown"`
+
+	// Password policy settings
+	PasswordMinLength uint   `long:"passwordminlength" description:"Minimum number of characters of a user password"`
+	PasswordMinScore  uint   `long:"passwordminscore" description:"Minimum strength score of a user password, from 0 (disabled) to 4"`
+	PasswordBreachURL string `long:"passwordbreachurl" description:"URL of a Pwned Passwords compatible range API that passwords are checked against; the breach check is disabled when not set"`
+
+	// Webhook notification settings
+	WebhookURLs    []string `long:"webhookurl" description:"URL that event notifications are POSTed to; webhook notifications are disabled when not set"`
+	WebhookSecret  string   `long:"webhooksecret" description:"Shared secret that is used to sign the webhook payloads using HMAC-SHA256"`
+	WebhookEvents  []string `long:"webhookevent" description:"Event that webhook notifications are sent for (default: all events)"`
+	WebhookRetries uint32   `long:"webhookretries" description:"Number of times a failed webhook delivery is retried"`
+
+	// Treasury handoff settings
+	TreasuryURL    string `long:"treasuryurl" description:"Treasury operations endpoint that approved proposals are POSTed to; the handoff is disabled when not set"`
+	TreasurySecret string `long:"treasurysecret" description:"Shared secret that is used to sign the treasury payloads using HMAC-SHA256"`
+
+	// Notification routing settings
+	Notifiers []string `long:"notifier" description:"Notifiers that a notification event is sent to in the format event:notifier[,notifier]; valid notifiers: smtp, webhook, noop"`
+
+	// Event log settings
+	EventLog bool `long:"eventlog" description:"Record all emitted events to an append-only event log that admins can query and replay"`
+
 	// XXX These should all be plugin settings
 	DcrdataHost              string   `long:"dcrdatahost" description:"Dcrdata ip:port"`
 	PaywallAmount            uint64   `long:"paywallamount" description:"Amount of DCR (in atoms) required for a user to register or submit a proposal."`
@@ -103,7 +166,103 @@ type Config struct {
 	VoteDurationMin          uint32   `long:"votedurationmin" description:"Minimum duration of a dcc vote in blocks"`
 	VoteDurationMax          uint32   `long:"votedurationmax" description:"Maximum duration of a dcc vote in blocks"`
 
+	// Proposal similarity settings
+	SimilarityThreshold float64 `long:"similaritythreshold" description:"Minimum similarity score (0-1) for a proposal to be reported as a likely duplicate of another proposal"`
+
+	// Proposal vetting settings
+	VettingSLA uint32 `long:"vettingsla" description:"Number of hours that a proposal can await vetting before it is flagged as an SLA breach"`
+
+	// Vote tally settings
+	VoteTallyInterval uint32 `long:"votetallyinterval" description:"Number of blocks between the vote tally snapshots of the active votes"`
+
+	// Politeiad cache settings
+	SummaryCacheTTL       uint32 `long:"summarycachettl" description:"Number of seconds that the vote summaries and comment counts are cached for; 0 disables the cache"`
+	CommentCountReconcile uint32 `long:"commentcountreconcile" description:"Number of minutes between the reconciliations of the comment count cache with politeiad; the comments made on other instances are counted once the cache is reconciled; 0 disables the cache"`
+
+	// Comment eligibility settings
+	CommentAccountAge     uint32 `long:"commentaccountage" description:"Minimum age in days of the accounts that are allowed to submit comments"`
+	CommentStake          bool   `long:"commentstake" description:"Allow users that have verified stake to submit comments"`
+	CommentVoteAccountAge uint32 `long:"commentvoteaccountage" description:"Minimum age in days of the accounts that are allowed to vote on comments"`
+	CommentVoteStake      bool   `long:"commentvotestake" description:"Allow users that have verified stake to vote on comments"`
+
+	// Bot account settings
+	BotCommentsPerHour uint32 `long:"botcommentsperhour" description:"Maximum number of comments that a bot account is allowed to submit per hour; 0 disables the limit"`
+
+	// Replay protection settings
+	CommentNonces bool `long:"commentnonces" description:"Require nonces on the signed comment and comment vote requests to prevent replays"`
+	VoteNonces    bool `long:"votenonces" description:"Require nonces on the signed cast votes to prevent replays"`
+
+	// Network access control settings
+	AdminAllow     []string `long:"adminallow" description:"CIDR or IP address that is allowed to access the admin routes; all networks are allowed when not set"`
+	Deny           []string `long:"deny" description:"CIDR or IP address that is denied access to all routes"`
+	TrustedProxies []string `long:"trustedproxy" description:"CIDR or IP address of a reverse proxy whose X-Forwarded-For header is trusted"`
+	AuthFailMax    uint32   `long:"authfailmax" description:"Number of failed login attempts after which a client address is temporarily banned; 0 disables the bans"`
+	AuthFailWindow uint32   `long:"authfailwindow" description:"Number of minutes in which the failed login attempts are counted"`
+	BanDuration    uint32   `long:"banduration" description:"Number of minutes that a client address is banned for"`
+
+	// Rate limit settings
+	RateLimits []string `long:"ratelimit" description:"Maximum number of requests per minute per client address and per user of a rate limit bucket, using the format bucket:perip:peruser; buckets: default, login, signup, comment, ballot, report; 0 disables a limit"`
+
+	// Metrics settings
+	Metrics       bool   `long:"metrics" description:"Serve Prometheus metrics on the /metrics route"`
+	MetricsListen string `long:"metricslisten" description:"Interface/port to serve the metrics on instead of the API listeners, e.g. 127.0.0.1:9090"`
+
+	// Tracing settings
+	TracingEndpoint string `long:"tracingendpoint" description:"OTLP/HTTP endpoint of an OpenTelemetry collector that request traces are exported to, e.g. http://127.0.0.1:4318; tracing is disabled when not set"`
+
+	// Telemetry settings
+	Telemetry        bool     `long:"telemetry" description:"Enable the opt-in client telemetry API"`
+	TelemetryClients []string `long:"telemetryclient" description:"Client name that is allowed to submit telemetry reports (default: politeiagui, pictl, politeiavoter)"`
+
+	// OAuth settings
+	OAuthClients []string `long:"oauthclient" description:"Third-party application that users can authorize; format: clientid,redirecturi,name[,secret]"`
+	OAuthIssuer  string   `long:"oauthissuer" description:"Public URL of politeiawww, e.g. https://proposals.decred.org; enables the OpenID Connect identity provider"`
+
+	// Web frontend settings
+	WebRoot string `long:"webroot" description:"Directory of a built web frontend that is served along with the API; the frontend is not served when not set"`
+	WebCSP  string `long:"webcsp" description:"Content-Security-Policy header of the web frontend responses"`
+
+	// Content scanning settings
+	Scanner        string `long:"scanner" description:"URL of a clamd or ICAP server that uploaded record files are scanned with, e.g. clamd://127.0.0.1:3310 or icap://127.0.0.1:1344/avscan; files are not scanned when not set"`
+	ScannerTimeout uint32 `long:"scannertimeout" description:"Maximum number of seconds that the scan of a single file may take"`
+
+	// Token settings
+	TokenPrefixLength int `long:"tokenprefixlength" description:"Length of the short token prefix of a record token; must match the politeiad setting"`
+
+	// Legacy proposal settings
+	LegacyTokens      string `long:"legacytokens" description:"Path to a file that maps legacy git backend proposal tokens to their tstore tokens"`
+	LegacyRedirectURL string `long:"legacyredirecturl" description:"Base URL that legacy proposal permalinks are redirected to, e.g. https://proposals.decred.org"`
+
 	Version     string
 	Identity    *identity.PublicIdentity
 	SystemCerts *x509.CertPool
+
+	// ServerIdentity is the politeiawww signing identity. It is used to
+	// sign data that originates from politeiawww, such as export
+	// manifests. This is not the politeiad identity.
+	ServerIdentity *identity.FullIdentity
+
+	// PrevServerIdentity is the previous politeiawww signing identity.
+	// It is only set during an identity rotation. Data is signed using
+	// both the current and the previous identity until the rotation
+	// ends so that clients that have not learned the new key yet can
+	// still verify it.
+	PrevServerIdentity *identity.FullIdentity
+
+	// RotationEndTime is the UNIX time at which the identity rotation
+	// ends.
+	RotationEndTime int64
+}
+
+// RotationIdentity returns the previous politeiawww signing identity if an
+// identity rotation is in progress. nil is returned if there is no rotation
+// or if the rotation has ended.
+func (c *Config) RotationIdentity() *identity.FullIdentity {
+	if c.PrevServerIdentity == nil {
+		return nil
+	}
+	if time.Now().Unix() >= c.RotationEndTime {
+		return nil
+	}
+	return c.PrevServerIdentity
 }
@@ -0,0 +1,210 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package consistency provides read-after-write consistency tokens.
+//
+// Every successful write to politeiad increments the politeiad write version,
+// which politeiad returns in the header of every reply. The sequence that is
+// used by this package is the politeiad write version. The reply to a request
+// that performed a write contains a consistency token that encodes the
+// sequence of the write. A client that includes the token in a later request
+// is guaranteed that the reply reflects the write, i.e. that the reply is not
+// served from a cache entry that was populated before the write was made.
+//
+// The write version is shared by all politeiawww instances that use the same
+// politeiad instance, so a token can be used with any of them. An instance
+// that has not yet seen the sequence of a token treats all of its cache
+// entries as not satisfying the token.
+//
+// Tokens are opaque to clients.
+package consistency
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/util"
+)
+
+// contextKey is the type of the context keys that are set by this package.
+type contextKey int
+
+const (
+	// contextKeyScope is the context key of the request scope.
+	contextKeyScope contextKey = iota
+)
+
+// scope contains the consistency state of a single request.
+type scope struct {
+	seq     uint64 // Sequence of the most recent write; atomic
+	minSeq  uint64 // Sequence that the reply must reflect
+	tracker *Tracker
+}
+
+// Tracker tracks the politeiad write sequence.
+type Tracker struct {
+	seq uint64 // Most recent sequence seen in a politeiad reply; atomic
+}
+
+// New returns a new Tracker.
+func New() *Tracker {
+	return &Tracker{}
+}
+
+// Seq returns the most recent sequence that has been seen in a politeiad
+// reply. Data that is retrieved from politeiad after this call is guaranteed
+// to reflect all writes up to the returned sequence.
+func (t *Tracker) Seq() uint64 {
+	return atomic.LoadUint64(&t.seq)
+}
+
+// Observe records the sequence of a politeiad reply. The write argument
+// indicates whether the reply is the reply to a successful write. The
+// consistency token of a write is returned in the reply to the request of the
+// provided context.
+func (t *Tracker) Observe(ctx context.Context, seq uint64, write bool) {
+	storeMax(&t.seq, seq)
+	if !write {
+		return
+	}
+	s, ok := ctx.Value(contextKeyScope).(*scope)
+	if !ok {
+		// The write was not made by a request
+		return
+	}
+	storeMax(&s.seq, seq)
+}
+
+// storeMax atomically stores the provided value if it is greater than the
+// current value.
+func storeMax(addr *uint64, val uint64) {
+	for {
+		v := atomic.LoadUint64(addr)
+		if val <= v || atomic.CompareAndSwapUint64(addr, v, val) {
+			return
+		}
+	}
+}
+
+// token returns the consistency token of the provided sequence.
+func token(seq uint64) string {
+	return strconv.FormatUint(seq, 10)
+}
+
+// parse parses a consistency token and returns the sequence that it requires.
+// The sequence may not have been seen by this instance yet if the write was
+// made on a different instance.
+func parse(token string) (uint64, error) {
+	seq, err := strconv.ParseUint(token, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("malformed token")
+	}
+	return seq, nil
+}
+
+// writer sets the consistency token header before the reply is written.
+type writer struct {
+	http.ResponseWriter
+	scope       *scope
+	wroteHeader bool
+}
+
+// WriteHeader satisfies the http.ResponseWriter interface.
+func (w *writer) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		seq := atomic.LoadUint64(&w.scope.seq)
+		if seq != 0 {
+			w.Header().Set(www.ConsistencyToken, token(seq))
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write satisfies the http.ResponseWriter interface.
+func (w *writer) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Hijack satisfies the http.Hijacker interface. The websocket routes require
+// the underlying connection to be hijacked.
+func (w *writer) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer is not a hijacker")
+	}
+	w.wroteHeader = true
+	return h.Hijack()
+}
+
+// Middleware adds the consistency scope to the request context. The scope
+// contains the sequence that is required by the consistency token of the
+// request, if one was provided, and records the sequence of any writes that
+// are made by the request so that the consistency token can be returned.
+func (t *Tracker) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := &scope{
+			tracker: t,
+		}
+		if v := r.Header.Get(www.ConsistencyToken); v != "" {
+			seq, err := parse(v)
+			if err != nil {
+				util.RespondWithJSON(w, http.StatusBadRequest, www.UserError{
+					ErrorCode: www.ErrorStatusInvalidInput,
+					ErrorContext: []string{fmt.Sprintf("consistency "+
+						"token: %v", err)},
+				})
+				return
+			}
+			s.minSeq = seq
+		}
+
+		ctx := context.WithValue(r.Context(), contextKeyScope, s)
+		next.ServeHTTP(&writer{ResponseWriter: w, scope: s},
+			r.WithContext(ctx))
+	})
+}
+
+// Seq returns the sequence of the most recent write. Caches use it to record
+// the sequence at which an entry was populated. It must be called before the
+// entry data is retrieved. Zero is returned if the context does not contain a
+// consistency scope.
+func Seq(ctx context.Context) uint64 {
+	s, ok := ctx.Value(contextKeyScope).(*scope)
+	if !ok {
+		return 0
+	}
+	return s.tracker.Seq()
+}
+
+// MinSeq returns the sequence that is required by the consistency token of
+// the request. Zero is returned if the request did not contain a consistency
+// token or if the context does not contain a consistency scope.
+func MinSeq(ctx context.Context) uint64 {
+	s, ok := ctx.Value(contextKeyScope).(*scope)
+	if !ok {
+		return 0
+	}
+	return s.minSeq
+}
+
+// Satisfied returns whether a cache entry that was populated at the provided
+// sequence satisfies the consistency token of the request. Entries always
+// satisfy requests that do not contain a consistency token.
+func Satisfied(ctx context.Context, seq uint64) bool {
+	s, ok := ctx.Value(contextKeyScope).(*scope)
+	if !ok {
+		return true
+	}
+	return seq >= s.minSeq
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package consistency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+)
+
+func TestConsistency(t *testing.T) {
+	var (
+		tr = New()
+
+		// writeSeq is the sequence that is returned by politeiad for
+		// the write.
+		writeSeq uint64 = 10
+
+		// cacheSeq is the sequence of a cache entry that was populated
+		// prior to the write.
+		cacheSeq = writeSeq - 1
+
+		// satisfied is set by the handler to whether the cache entry
+		// satisfies the request.
+		satisfied bool
+	)
+	h := tr.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			tr.Observe(r.Context(), writeSeq, true)
+		}
+		satisfied = Satisfied(r.Context(), cacheSeq)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// Make a write and verify that the token is returned
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+	writeToken := w.Header().Get(www.ConsistencyToken)
+	if writeToken == "" {
+		t.Fatalf("write reply does not contain a consistency token")
+	}
+	if tr.Seq() != writeSeq {
+		t.Fatalf("got seq %v, want %v", tr.Seq(), writeSeq)
+	}
+
+	var tests = []struct {
+		name      string
+		token     string
+		wantCode  int
+		satisfied bool
+	}{
+		{"no token", "", http.StatusOK, true},
+		{"token", writeToken, http.StatusOK, false},
+		{"earlier write", token(cacheSeq), http.StatusOK, true},
+
+		// A token that was issued by another instance may contain a
+		// sequence that has not been seen by this instance yet.
+		{"other instance", token(writeSeq + 5), http.StatusOK, false},
+		{"malformed", "abc", http.StatusBadRequest, false},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			satisfied = false
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if v.token != "" {
+				r.Header.Set(www.ConsistencyToken, v.token)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != v.wantCode {
+				t.Fatalf("got code %v, want %v", w.Code, v.wantCode)
+			}
+			if satisfied != v.satisfied {
+				t.Fatalf("got satisfied %v, want %v", satisfied, v.satisfied)
+			}
+			if w.Header().Get(www.ConsistencyToken) != "" {
+				t.Fatalf("read reply contains a consistency token")
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/util"
+	"github.com/gorilla/csrf"
+)
+
+const (
+	// csrfSessionTokenMaxAge is the max age of a CSRF session token.
+	csrfSessionTokenMaxAge = time.Hour
+
+	// csrfSessionNonceLength is the length of the random nonce that is
+	// included in each CSRF session token. The nonce ensures that a new
+	// token is issued on every request.
+	csrfSessionNonceLength = 16
+
+	// csrfSessionTokenLength is the byte length of a decoded CSRF
+	// session token. A token is the expiry, nonce, and the HMAC-SHA256
+	// of the session ID, expiry, and nonce.
+	csrfSessionTokenLength = 8 + csrfSessionNonceLength + sha256.Size
+)
+
+var (
+	errCSRFSessionTokenInvalid = errors.New("csrf session token invalid")
+	errCSRFSessionTokenExpired = errors.New("csrf session token expired")
+)
+
+// csrfSessionKey derives the HMAC key that is used for CSRF session tokens
+// from the CSRF key. A separate key is derived so that the key used by the
+// cookie based CSRF protection is never used for anything else.
+func csrfSessionKey(csrfKey []byte) []byte {
+	h := hmac.New(sha256.New, csrfKey)
+	h.Write([]byte("politeiawww csrf session token"))
+	return h.Sum(nil)
+}
+
+// csrfSessionMAC returns the HMAC-SHA256 of the session ID, expiry, and
+// nonce.
+func csrfSessionMAC(key []byte, sessionID string, expiryNonce []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(sessionID))
+	h.Write(expiryNonce)
+	return h.Sum(nil)
+}
+
+// newCSRFSessionToken returns a new CSRF session token for the provided
+// session ID along with the UNIX timestamp of when the token expires.
+func newCSRFSessionToken(key []byte, sessionID string) (string, int64, error) {
+	nonce, err := util.Random(csrfSessionNonceLength)
+	if err != nil {
+		return "", 0, err
+	}
+	expiry := time.Now().Add(csrfSessionTokenMaxAge).Unix()
+
+	b := make([]byte, 8, csrfSessionTokenLength)
+	binary.BigEndian.PutUint64(b, uint64(expiry))
+	b = append(b, nonce...)
+	b = append(b, csrfSessionMAC(key, sessionID, b)...)
+
+	return hex.EncodeToString(b), expiry, nil
+}
+
+// csrfSessionTokenVerify verifies that the provided CSRF session token was
+// issued for the provided session ID and has not expired.
+func csrfSessionTokenVerify(key []byte, sessionID, token string) error {
+	b, err := hex.DecodeString(token)
+	if err != nil || len(b) != csrfSessionTokenLength {
+		return errCSRFSessionTokenInvalid
+	}
+	var (
+		expiryNonce = b[:8+csrfSessionNonceLength]
+		mac         = b[8+csrfSessionNonceLength:]
+	)
+	if !hmac.Equal(mac, csrfSessionMAC(key, sessionID, expiryNonce)) {
+		return errCSRFSessionTokenInvalid
+	}
+	expiry := int64(binary.BigEndian.Uint64(expiryNonce[:8]))
+	if time.Now().Unix() > expiry {
+		return errCSRFSessionTokenExpired
+	}
+	return nil
+}
+
+// csrfSessionMiddleware validates the CSRF session token of requests that
+// include one. A request that contains a valid CSRF session token is exempt
+// from the cookie based CSRF check. Requests that do not include a CSRF
+// session token fall through to the cookie based CSRF check.
+//
+// Requests that contain a bot API key are exempt from the CSRF checks. A
+// browser can not be made to send the API key header cross-site, and the
+// session cookie of a request that contains an API key is ignored.
+//
+// This middleware must be registered before the cookie based CSRF middleware.
+func (p *politeiawww) csrfSessionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(www.APIKey) != "" {
+			next.ServeHTTP(w, csrf.UnsafeSkipCheck(r))
+			return
+		}
+
+		token := r.Header.Get(www.CsrfSessionToken)
+		if token == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		session, err := p.sessions.GetSession(r)
+		if err != nil || session.IsNew {
+			log.Debugf("%v csrfSessionMiddleware: session not found",
+				util.RemoteAddr(r))
+			http.Error(w, "Forbidden - CSRF session not found",
+				http.StatusForbidden)
+			return
+		}
+		err = csrfSessionTokenVerify(p.csrfSessionKey, session.ID, token)
+		if err != nil {
+			log.Debugf("%v csrfSessionMiddleware: %v",
+				util.RemoteAddr(r), err)
+			http.Error(w, "Forbidden - "+err.Error(), http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, csrf.UnsafeSkipCheck(r))
+	})
+}
+
+// handleCSRFToken issues a new CSRF session token for the user session.
+func (p *politeiawww) handleCSRFToken(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleCSRFToken")
+
+	session, err := p.sessions.GetSession(r)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleCSRFToken: GetSession: %v", err)
+		return
+	}
+	token, expiry, err := newCSRFSessionToken(p.csrfSessionKey, session.ID)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleCSRFToken: newCSRFSessionToken: %v", err)
+		return
+	}
+
+	w.Header().Set(www.CsrfSessionToken, token)
+	util.RespondWithJSON(w, http.StatusOK, www.CSRFTokenReply{
+		Token:  token,
+		Expiry: expiry,
+	})
+}
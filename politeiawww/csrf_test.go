@@ -0,0 +1,63 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestCSRFSessionToken(t *testing.T) {
+	key := csrfSessionKey([]byte("csrfkey"))
+	token, _, err := newCSRFSessionToken(key, "session")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name      string
+		key       []byte
+		sessionID string
+		token     string
+		wantErr   error
+	}{
+		{
+			"invalid hex",
+			key,
+			"session",
+			"zz",
+			errCSRFSessionTokenInvalid,
+		},
+		{
+			"wrong session",
+			key,
+			"othersession",
+			token,
+			errCSRFSessionTokenInvalid,
+		},
+		{
+			"wrong key",
+			csrfSessionKey([]byte("otherkey")),
+			"session",
+			token,
+			errCSRFSessionTokenInvalid,
+		},
+		{
+			"success",
+			key,
+			"session",
+			token,
+			nil,
+		},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			err := csrfSessionTokenVerify(v.key, v.sessionID, v.token)
+			if err != v.wantErr {
+				t.Errorf("got error %v, want %v", err, v.wantErr)
+			}
+		})
+	}
+}
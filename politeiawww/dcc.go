@@ -286,7 +286,7 @@ func convertCastVoteReplyToCMS(cv *cmsplugin.CastVoteReply) *cms.CastVoteReply {
 		ClientSignature: cv.ClientSignature,
 		Signature:       cv.Signature,
 		Error:           cv.Error,
-		ErrorStatus:     cv.ErrorStatus,
+		ErrorStatus:     cms.CastVoteErrorT(cv.ErrorStatus),
 	}
 }
 
@@ -487,7 +487,7 @@ func (p *politeiawww) processNewDCC(ctx context.Context, nd cms.NewDCC, u *user.
 	}
 
 	// Verify NewRecord challenge
-	err = util.VerifyChallenge(p.cfg.Identity, challenge, pdReply.Response)
+	err = util.VerifyChallenge(p.politeiadIdentity(), challenge, pdReply.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -538,7 +538,7 @@ func (p *politeiawww) processNewDCC(ctx context.Context, nd cms.NewDCC, u *user.
 	}
 
 	// Verify the SetUnvettedStatus challenge.
-	err = util.VerifyChallenge(p.cfg.Identity, challenge,
+	err = util.VerifyChallenge(p.politeiadIdentity(), challenge,
 		pdSetUnvettedStatusReply.Response)
 	if err != nil {
 		return nil, err
@@ -992,7 +992,7 @@ func (p *politeiawww) processSupportOpposeDCC(ctx context.Context, sd cms.Suppor
 	}
 
 	// Verify the UpdateVettedMetadata challenge.
-	err = util.VerifyChallenge(p.cfg.Identity, challenge, pdReply.Response)
+	err = util.VerifyChallenge(p.politeiadIdentity(), challenge, pdReply.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -1137,7 +1137,7 @@ func (p *politeiawww) processNewCommentDCC(ctx context.Context, nc www.NewCommen
 			"PluginCommandReply: %v", err)
 	}
 
-	err = util.VerifyChallenge(p.cfg.Identity, challenge, reply.Response)
+	err = util.VerifyChallenge(p.politeiadIdentity(), challenge, reply.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -1327,7 +1327,7 @@ func (p *politeiawww) processSetDCCStatus(ctx context.Context, sds cms.SetDCCSta
 	}
 
 	// Verify the UpdateVettedMetadata challenge.
-	err = util.VerifyChallenge(p.cfg.Identity, challenge, pdReply.Response)
+	err = util.VerifyChallenge(p.politeiadIdentity(), challenge, pdReply.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -1471,7 +1471,7 @@ func (p *politeiawww) processCastVoteDCC(ctx context.Context, cv cms.CastVote, u
 	}
 
 	// Verify the challenge.
-	err = util.VerifyChallenge(p.cfg.Identity, challenge, reply.Response)
+	err = util.VerifyChallenge(p.politeiadIdentity(), challenge, reply.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -1547,7 +1547,7 @@ func (p *politeiawww) cmsVoteDetails(ctx context.Context, token string) (*cmsplu
 		return nil, fmt.Errorf("could not unmarshal "+
 			"PluginCommandReply: %v", err)
 	}
-	err = util.VerifyChallenge(p.cfg.Identity, challenge, reply.Response)
+	err = util.VerifyChallenge(p.politeiadIdentity(), challenge, reply.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -1593,7 +1593,7 @@ func (p *politeiawww) cmsVoteSummary(ctx context.Context, token string) (*cmsplu
 		return nil, fmt.Errorf("could not unmarshal "+
 			"PluginCommandReply: %v", err)
 	}
-	err = util.VerifyChallenge(p.cfg.Identity, challenge, reply.Response)
+	err = util.VerifyChallenge(p.politeiadIdentity(), challenge, reply.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -1634,7 +1634,7 @@ func (p *politeiawww) processActiveVoteDCC(ctx context.Context) (*cms.ActiveVote
 	}
 
 	// Verify the UpdateVettedMetadata challenge.
-	err = util.VerifyChallenge(p.cfg.Identity, challenge, pdReply.Response)
+	err = util.VerifyChallenge(p.politeiadIdentity(), challenge, pdReply.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -1913,7 +1913,7 @@ func (p *politeiawww) processStartVoteDCC(ctx context.Context, sv cms.StartVote,
 		return nil, fmt.Errorf("could not unmarshal "+
 			"PluginCommandReply: %v", err)
 	}
-	err = util.VerifyChallenge(p.cfg.Identity, challenge, reply.Response)
+	err = util.VerifyChallenge(p.politeiadIdentity(), challenge, reply.Response)
 	if err != nil {
 		return nil, err
 	}
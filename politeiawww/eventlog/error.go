@@ -0,0 +1,71 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package eventlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	v1 "github.com/decred/politeia/politeiawww/api/eventlog/v1"
+	"github.com/decred/politeia/util"
+)
+
+func respondWithError(w http.ResponseWriter, r *http.Request, format string, err error) {
+	// Check if the client dropped the connection
+	if err := r.Context().Err(); err == context.Canceled {
+		log.Infof("%v %v %v %v client aborted connection",
+			util.RemoteAddr(r), r.Method, r.URL, r.Proto)
+
+		// Client dropped the connection. There is no need to
+		// respond further.
+		return
+	}
+
+	// Check for expected error types
+	var ue v1.UserErrorReply
+	switch {
+	case errors.As(err, &ue):
+		// Event log user error
+		m := fmt.Sprintf("%v Event log user error: %v %v",
+			util.RemoteAddr(r), ue.ErrorCode, v1.ErrorCodes[ue.ErrorCode])
+		if ue.ErrorContext != "" {
+			m += fmt.Sprintf(": %v", ue.ErrorContext)
+		}
+		log.Infof(m)
+		util.RespondWithJSON(w, http.StatusBadRequest,
+			v1.UserErrorReply{
+				ErrorCode:    ue.ErrorCode,
+				ErrorContext: ue.ErrorContext,
+			})
+		return
+
+	default:
+		// Internal server error. Log it and return a 500.
+		t := time.Now().Unix()
+		e := fmt.Sprintf(format, err)
+		log.Errorf("%v %v %v %v Internal error %v: %v",
+			util.RemoteAddr(r), r.Method, r.URL, r.Proto, t, e)
+
+		// If this is a pkg/errors error then we can pull the
+		// stack trace out of the error, otherwise, we use the
+		// stack trace for this function.
+		stack, ok := util.StackTrace(err)
+		if !ok {
+			stack = string(debug.Stack())
+		}
+
+		log.Errorf("Stacktrace (NOT A REAL CRASH): %v", stack)
+
+		util.RespondWithJSON(w, http.StatusInternalServerError,
+			v1.ServerErrorReply{
+				ErrorCode: t,
+			})
+		return
+	}
+}
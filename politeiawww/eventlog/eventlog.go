@@ -0,0 +1,136 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package eventlog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	v1 "github.com/decred/politeia/politeiawww/api/eventlog/v1"
+	"github.com/decred/politeia/politeiawww/config"
+	"github.com/decred/politeia/politeiawww/events"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// eventLogFilename is the filename of the event log. It is saved
+	// to the politeiawww data directory.
+	eventLogFilename = "eventlog.json"
+)
+
+// EventLog is the context for the event log API. It records every event that
+// is emitted by the event manager to an append-only store so that the events
+// can be audited and replayed into the notification pipeline, e.g. after a
+// mail server outage.
+type EventLog struct {
+	cfg    *config.Config
+	events *events.Manager
+	store  *store
+}
+
+// Record records an emitted event. Errors are logged and are not returned
+// since recording an event must not prevent the event from being emitted.
+//
+// This function satisfies the events.Recorder interface.
+func (l *EventLog) Record(event string, data interface{}) {
+	if _, ok := eventTypes[event]; !ok {
+		log.Debugf("Event log: event type not recorded %v", event)
+		return
+	}
+	d, token, actor, err := describe(data)
+	if err != nil {
+		log.Errorf("Event log %v: %v", event, err)
+		return
+	}
+	payload, err := json.Marshal(d)
+	if err != nil {
+		log.Errorf("Event log %v: %v", event, err)
+		return
+	}
+	digest := sha256.Sum256(payload)
+	id, err := l.store.append(entry{
+		Type:      event,
+		Token:     token,
+		Actor:     actor,
+		Timestamp: time.Now().Unix(),
+		Digest:    hex.EncodeToString(digest[:]),
+		Payload:   payload,
+	})
+	if err != nil {
+		log.Errorf("Event log %v: %v", event, err)
+		return
+	}
+
+	log.Debugf("Event log: recorded %v %v %v", id, event, token)
+}
+
+// HandleEvents is the request handler for the eventlog v1 Events route.
+func (l *EventLog) HandleEvents(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleEvents")
+
+	var e v1.Events
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&e); err != nil {
+		respondWithError(w, r, "HandleEvents: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	er, err := l.processEvents(e)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleEvents: processEvents: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, er)
+}
+
+// HandleReplay is the request handler for the eventlog v1 Replay route.
+func (l *EventLog) HandleReplay(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleReplay")
+
+	var rp v1.Replay
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&rp); err != nil {
+		respondWithError(w, r, "HandleReplay: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	rr, err := l.processReplay(rp)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleReplay: processReplay: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, rr)
+}
+
+// New returns a new EventLog context. The event log is set as the recorder of
+// the provided event manager.
+func New(cfg *config.Config, e *events.Manager) (*EventLog, error) {
+	s, err := newStore(filepath.Join(cfg.DataDir, eventLogFilename))
+	if err != nil {
+		return nil, err
+	}
+	l := EventLog{
+		cfg:    cfg,
+		events: e,
+		store:  s,
+	}
+	e.SetRecorder(&l)
+
+	return &l, nil
+}
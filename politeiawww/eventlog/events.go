@@ -0,0 +1,140 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package eventlog
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/decred/politeia/politeiawww/comments"
+	"github.com/decred/politeia/politeiawww/pi"
+	"github.com/decred/politeia/politeiawww/records"
+	"github.com/decred/politeia/politeiawww/ticketvote"
+	"github.com/decred/politeia/politeiawww/user"
+)
+
+// eventTypes contains the event types that are recorded. Events of any other
+// type are not recorded since they can not be decoded when they are
+// replayed.
+var eventTypes = map[string]struct{}{
+	records.EventTypeNew:          {},
+	records.EventTypeEdit:         {},
+	records.EventTypeSetStatus:    {},
+	comments.EventTypeNew:         {},
+	ticketvote.EventTypeAuthorize: {},
+	ticketvote.EventTypeStart:     {},
+	ticketvote.EventTypeFinished:  {},
+	pi.EventTypeReportNew:         {},
+	pi.EventTypeAuthorUpdate:      {},
+	pi.EventTypeProposalApproved:  {},
+}
+
+// eventUser returns a copy of the user that only contains the user fields
+// that the event listeners use. The user credentials and verification tokens
+// are never written to the event log.
+func eventUser(u user.User) user.User {
+	return user.User{
+		ID:       u.ID,
+		Email:    u.Email,
+		Username: u.Username,
+		Admin:    u.Admin,
+	}
+}
+
+// describe returns the event data that is recorded for the provided event
+// data, along with the record token and the ID of the user that triggered the
+// event. The user data of the event is stripped down using eventUser. A vote
+// start event can contain the starts of multiple runoff submissions. The
+// token of the first submission is returned for it.
+func describe(data interface{}) (interface{}, string, string, error) {
+	switch e := data.(type) {
+	case records.EventNew:
+		e.User = eventUser(e.User)
+		return e, e.Record.CensorshipRecord.Token, e.User.ID.String(), nil
+	case records.EventEdit:
+		e.User = eventUser(e.User)
+		return e, e.Record.CensorshipRecord.Token, e.User.ID.String(), nil
+	case records.EventSetStatus:
+		return e, e.Record.CensorshipRecord.Token, "", nil
+	case comments.EventNew:
+		return e, e.Comment.Token, e.Comment.UserID, nil
+	case ticketvote.EventAuthorize:
+		e.User = eventUser(e.User)
+		return e, e.Auth.Token, e.User.ID.String(), nil
+	case ticketvote.EventStart:
+		e.User = eventUser(e.User)
+		var token string
+		if len(e.Starts) > 0 {
+			token = e.Starts[0].Params.Token
+		}
+		return e, token, e.User.ID.String(), nil
+	case ticketvote.EventFinished:
+		return e, e.Certificate.Certificate.Token, "", nil
+	case pi.EventReportNew:
+		return e, e.Report.Token, e.Report.UserID, nil
+	case pi.EventAuthorUpdate:
+		return e, e.AuthorUpdate.Token, e.AuthorUpdate.UserID, nil
+	case pi.EventProposalApproved:
+		return e, e.Certificate.Certificate.Token, "", nil
+	}
+	return nil, "", "", fmt.Errorf("invalid event data %T", data)
+}
+
+// decode decodes the recorded payload of an event into the event data type
+// that the event listeners expect.
+func decode(event string, payload []byte) (interface{}, error) {
+	var (
+		data interface{}
+		err  error
+	)
+	switch event {
+	case records.EventTypeNew:
+		var e records.EventNew
+		err = json.Unmarshal(payload, &e)
+		data = e
+	case records.EventTypeEdit:
+		var e records.EventEdit
+		err = json.Unmarshal(payload, &e)
+		data = e
+	case records.EventTypeSetStatus:
+		var e records.EventSetStatus
+		err = json.Unmarshal(payload, &e)
+		data = e
+	case comments.EventTypeNew:
+		var e comments.EventNew
+		err = json.Unmarshal(payload, &e)
+		data = e
+	case ticketvote.EventTypeAuthorize:
+		var e ticketvote.EventAuthorize
+		err = json.Unmarshal(payload, &e)
+		data = e
+	case ticketvote.EventTypeStart:
+		var e ticketvote.EventStart
+		err = json.Unmarshal(payload, &e)
+		data = e
+	case ticketvote.EventTypeFinished:
+		var e ticketvote.EventFinished
+		err = json.Unmarshal(payload, &e)
+		data = e
+	case pi.EventTypeReportNew:
+		var e pi.EventReportNew
+		err = json.Unmarshal(payload, &e)
+		data = e
+	case pi.EventTypeAuthorUpdate:
+		var e pi.EventAuthorUpdate
+		err = json.Unmarshal(payload, &e)
+		data = e
+	case pi.EventTypeProposalApproved:
+		var e pi.EventProposalApproved
+		err = json.Unmarshal(payload, &e)
+		data = e
+	default:
+		return nil, fmt.Errorf("invalid event type %v", event)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package eventlog
+
+import (
+	"fmt"
+	"time"
+
+	v1 "github.com/decred/politeia/politeiawww/api/eventlog/v1"
+)
+
+// timeRange verifies the provided time range and returns it. To defaults to
+// the current time when it is not set.
+func timeRange(from, to int64) (int64, int64, error) {
+	if to == 0 {
+		to = time.Now().Unix()
+	}
+	if from < 0 || from > to {
+		return 0, 0, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeTimeRangeInvalid,
+			ErrorContext: fmt.Sprintf("from %v to %v", from, to),
+		}
+	}
+	return from, to, nil
+}
+
+func convertEventToV1(e entry) v1.Event {
+	return v1.Event{
+		ID:        e.ID,
+		Type:      e.Type,
+		Token:     e.Token,
+		Actor:     e.Actor,
+		Timestamp: e.Timestamp,
+		Digest:    e.Digest,
+	}
+}
+
+func (l *EventLog) processEvents(e v1.Events) (*v1.EventsReply, error) {
+	log.Tracef("processEvents: %v %v %v %v", e.From, e.To, e.Type, e.Token)
+
+	from, to, err := timeRange(e.From, e.To)
+	if err != nil {
+		return nil, err
+	}
+	if e.Type != "" {
+		if _, ok := eventTypes[e.Type]; !ok {
+			return nil, v1.UserErrorReply{
+				ErrorCode:    v1.ErrorCodeEventTypeInvalid,
+				ErrorContext: e.Type,
+			}
+		}
+	}
+
+	events := make([]v1.Event, 0, v1.EventsPageSize)
+	err = l.store.iterate(func(v entry) bool {
+		switch {
+		case v.ID <= e.After:
+			return true
+		case v.Timestamp < from || v.Timestamp > to:
+			return true
+		case e.Type != "" && v.Type != e.Type:
+			return true
+		case e.Token != "" && v.Token != e.Token:
+			return true
+		}
+		events = append(events, convertEventToV1(v))
+		return len(events) < int(v1.EventsPageSize)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.EventsReply{
+		Events: events,
+	}, nil
+}
+
+func (l *EventLog) processReplay(rp v1.Replay) (*v1.ReplayReply, error) {
+	log.Tracef("processReplay: %v %v %v", rp.From, rp.To, rp.Types)
+
+	from, to, err := timeRange(rp.From, rp.To)
+	if err != nil {
+		return nil, err
+	}
+	types := make(map[string]struct{}, len(rp.Types))
+	for _, v := range rp.Types {
+		if _, ok := eventTypes[v]; !ok {
+			return nil, v1.UserErrorReply{
+				ErrorCode:    v1.ErrorCodeEventTypeInvalid,
+				ErrorContext: v,
+			}
+		}
+		types[v] = struct{}{}
+	}
+
+	// Collect the entries before replaying them so that the store is
+	// not being read while the event listeners are running.
+	entries := make([]entry, 0, 256)
+	err = l.store.iterate(func(v entry) bool {
+		if v.Timestamp < from || v.Timestamp > to {
+			return true
+		}
+		if _, ok := types[v.Type]; len(types) > 0 && !ok {
+			return true
+		}
+		entries = append(entries, v)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Replay the events
+	replayed := make([]uint64, 0, len(entries))
+	for _, v := range entries {
+		data, err := decode(v.Type, v.Payload)
+		if err != nil {
+			// Log the error and continue. A single bad entry should
+			// not prevent the other events from being replayed.
+			log.Errorf("Event log replay %v %v: %v", v.ID, v.Type, err)
+			continue
+		}
+		l.events.Replay(v.Type, data)
+		replayed = append(replayed, v.ID)
+
+		log.Infof("Event log: replayed %v %v %v", v.ID, v.Type, v.Token)
+	}
+
+	return &v1.ReplayReply{
+		Replayed: replayed,
+	}, nil
+}
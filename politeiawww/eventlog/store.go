@@ -0,0 +1,145 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package eventlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// entry is an event log entry. The entries are stored as one JSON object per
+// line.
+type entry struct {
+	ID        uint64          `json:"id"`
+	Type      string          `json:"type"`
+	Token     string          `json:"token,omitempty"`
+	Actor     string          `json:"actor,omitempty"`
+	Timestamp int64           `json:"timestamp"`
+	Digest    string          `json:"digest"`  // SHA256 of the payload
+	Payload   json.RawMessage `json:"payload"` // JSON encoded event data
+}
+
+// store is an append-only file store for event log entries. Entries are never
+// modified or deleted once they have been appended.
+type store struct {
+	sync.Mutex
+	path   string
+	file   *os.File
+	lastID uint64
+}
+
+// iterate calls the provided function for every entry in the store in the
+// order that the entries were appended. Iteration stops when the function
+// returns false.
+func (s *store) iterate(fn func(e entry) bool) error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// The entries can be large since the event payloads include the
+	// record files. A reader is used instead of a scanner so that the
+	// line length is not limited.
+	r := bufio.NewReader(f)
+	for {
+		b, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			// A partially written last entry is ignored
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var e entry
+		err = json.Unmarshal(b, &e)
+		if err != nil {
+			return fmt.Errorf("decode entry: %v", err)
+		}
+		if !fn(e) {
+			return nil
+		}
+	}
+}
+
+// append assigns the next sequence number to the provided entry and appends
+// it to the store.
+func (s *store) append(e entry) (uint64, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	e.ID = s.lastID + 1
+	b, err := json.Marshal(e)
+	if err != nil {
+		return 0, err
+	}
+	_, err = s.file.Write(append(b, '\n'))
+	if err != nil {
+		return 0, err
+	}
+	s.lastID = e.ID
+
+	return e.ID, nil
+}
+
+// load sets the last sequence number of the store. A partially written last
+// entry, which is left behind when politeiawww is stopped in the middle of an
+// append, is truncated so that the next entry is appended on a new line.
+func (s *store) load() error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var (
+		r      = bufio.NewReader(f)
+		offset int64
+	)
+	for {
+		b, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			if len(b) > 0 {
+				log.Warnf("Truncating partial event log entry at %v", offset)
+				return os.Truncate(s.path, offset)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var e entry
+		err = json.Unmarshal(b, &e)
+		if err != nil {
+			return fmt.Errorf("decode entry at %v: %v", offset, err)
+		}
+		s.lastID = e.ID
+		offset += int64(len(b))
+	}
+}
+
+// newStore opens the append-only store at the provided path. The file is
+// created if it does not exist.
+func newStore(path string) (*store, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	s := store{
+		path: path,
+		file: f,
+	}
+	err = s.load()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("load %v: %v", path, err)
+	}
+
+	return &s, nil
+}
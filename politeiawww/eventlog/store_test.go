@@ -0,0 +1,79 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package eventlog
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eventlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, eventLogFilename)
+
+	// Append entries
+	s, err := newStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{"a", "b"} {
+		_, err = s.append(entry{
+			Type:    v,
+			Payload: json.RawMessage(`{}`),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.file.Close()
+
+	// Simulate a partially written entry
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.Write([]byte(`{"id":3,"type":"c"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	// Reopen the store. The partial entry must be truncated and the
+	// sequence must continue from the last complete entry.
+	s, err = newStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.file.Close()
+	id, err := s.append(entry{
+		Type:    "d",
+		Payload: json.RawMessage(`{}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 3 {
+		t.Fatalf("got id %v, want 3", id)
+	}
+
+	var types string
+	err = s.iterate(func(e entry) bool {
+		types += e.Type
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if types != "abd" {
+		t.Fatalf("got entries %v, want abd", types)
+	}
+}
@@ -5,6 +5,15 @@
 package main
 
 import (
+	"time"
+
+	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/comments"
+	"github.com/decred/politeia/politeiawww/records"
+	"github.com/decred/politeia/politeiawww/ticketvote"
 	"github.com/decred/politeia/politeiawww/user"
 )
 
@@ -159,3 +168,187 @@ func (p *politeiawww) handleEventDCCSupportOppose(ch chan interface{}) {
 		log.Debugf("Sent DCC support/oppose notification %v", d.token)
 	}
 }
+
+// setupEventListenersWS sets up the event listeners that push the pi events
+// to the subscribed websockets.
+func (p *politeiawww) setupEventListenersWS() {
+	// Setup record set status event
+	ch := make(chan interface{})
+	p.events.Register(records.EventTypeSetStatus, ch)
+	go p.handleEventWSRecordSetStatus(ch)
+
+	// Setup new comment event
+	ch = make(chan interface{})
+	p.events.Register(comments.EventTypeNew, ch)
+	go p.handleEventWSCommentNew(ch)
+
+	// Setup vote started event
+	ch = make(chan interface{})
+	p.events.Register(ticketvote.EventTypeStart, ch)
+	go p.handleEventWSVoteStart(ch)
+
+	// Setup vote finished event
+	ch = make(chan interface{})
+	p.events.Register(ticketvote.EventTypeFinished, ch)
+	go p.handleEventWSVoteFinished(ch)
+}
+
+// handleEventWSRecordSetStatus pushes the status changes of public proposals.
+// Unvetted proposals are not public so their status changes are not pushed.
+func (p *politeiawww) handleEventWSRecordSetStatus(ch chan interface{}) {
+	for msg := range ch {
+		d, ok := msg.(records.EventSetStatus)
+		if !ok {
+			log.Errorf("handleEventWSRecordSetStatus invalid msg: %v", msg)
+			continue
+		}
+		if d.Record.State != rcv1.RecordStateVetted {
+			continue
+		}
+
+		event := www.WSEventProposalStatus
+		if d.Record.Status == rcv1.RecordStatusPublic {
+			event = www.WSEventProposalNew
+		}
+		p.websocketEvent(www.WSEvent{
+			Event:     event,
+			Token:     d.Record.CensorshipRecord.Token,
+			Status:    rcv1.RecordStatuses[d.Record.Status],
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// handleEventWSCommentNew pushes the new comments of public proposals.
+func (p *politeiawww) handleEventWSCommentNew(ch chan interface{}) {
+	for msg := range ch {
+		d, ok := msg.(comments.EventNew)
+		if !ok {
+			log.Errorf("handleEventWSCommentNew invalid msg: %v", msg)
+			continue
+		}
+		if d.State != cmv1.RecordStateVetted {
+			continue
+		}
+
+		p.websocketEvent(www.WSEvent{
+			Event:     www.WSEventCommentNew,
+			Token:     d.Comment.Token,
+			CommentID: d.Comment.CommentID,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// handleEventWSVoteStart pushes the started votes. A runoff vote starts the
+// votes of all of its submissions at once.
+func (p *politeiawww) handleEventWSVoteStart(ch chan interface{}) {
+	for msg := range ch {
+		d, ok := msg.(ticketvote.EventStart)
+		if !ok {
+			log.Errorf("handleEventWSVoteStart invalid msg: %v", msg)
+			continue
+		}
+
+		for _, v := range d.Starts {
+			p.websocketEvent(www.WSEvent{
+				Event:     www.WSEventVoteStarted,
+				Token:     v.Params.Token,
+				Status:    tkv1.VoteStatuses[tkv1.VoteStatusStarted],
+				Timestamp: time.Now().Unix(),
+			})
+		}
+	}
+}
+
+// handleEventWSVoteFinished pushes the finished votes along with their
+// outcome.
+func (p *politeiawww) handleEventWSVoteFinished(ch chan interface{}) {
+	for msg := range ch {
+		d, ok := msg.(ticketvote.EventFinished)
+		if !ok {
+			log.Errorf("handleEventWSVoteFinished invalid msg: %v", msg)
+			continue
+		}
+
+		c := d.Certificate.Certificate
+		p.websocketEvent(www.WSEvent{
+			Event:     www.WSEventVoteFinished,
+			Token:     c.Token,
+			Status:    tkv1.VoteStatuses[c.Status],
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
+
+// setupEventListenersCache sets up the event listeners that drop the cached
+// politeiad vote summaries and comment counts of the records that change.
+func (p *politeiawww) setupEventListenersCache() {
+	// Setup record set status event
+	ch := make(chan interface{})
+	p.events.Register(records.EventTypeSetStatus, ch)
+	go p.handleEventCache(ch)
+
+	// Setup new comment event
+	ch = make(chan interface{})
+	p.events.Register(comments.EventTypeNew, ch)
+	go p.handleEventCache(ch)
+
+	// Setup comment deleted event
+	ch = make(chan interface{})
+	p.events.Register(comments.EventTypeDel, ch)
+	go p.handleEventCache(ch)
+
+	// Setup vote authorized event
+	ch = make(chan interface{})
+	p.events.Register(ticketvote.EventTypeAuthorize, ch)
+	go p.handleEventCache(ch)
+
+	// Setup vote started event
+	ch = make(chan interface{})
+	p.events.Register(ticketvote.EventTypeStart, ch)
+	go p.handleEventCache(ch)
+
+	// Setup vote finished event
+	ch = make(chan interface{})
+	p.events.Register(ticketvote.EventTypeFinished, ch)
+	go p.handleEventCache(ch)
+
+	// Setup cast ballot event
+	ch = make(chan interface{})
+	p.events.Register(ticketvote.EventTypeCastBallot, ch)
+	go p.handleEventCache(ch)
+}
+
+// handleEventCache drops the cached politeiad replies of the records that
+// the events are for.
+func (p *politeiawww) handleEventCache(ch chan interface{}) {
+	for msg := range ch {
+		var tokens []string
+		switch d := msg.(type) {
+		case records.EventSetStatus:
+			tokens = []string{d.Record.CensorshipRecord.Token}
+		case comments.EventNew:
+			tokens = []string{d.Comment.Token}
+		case comments.EventDel:
+			tokens = []string{d.Comment.Token}
+		case ticketvote.EventAuthorize:
+			tokens = []string{d.Auth.Token}
+		case ticketvote.EventStart:
+			for _, v := range d.Starts {
+				tokens = append(tokens, v.Params.Token)
+			}
+		case ticketvote.EventFinished:
+			tokens = []string{d.Certificate.Certificate.Token}
+		case ticketvote.EventCastBallot:
+			tokens = []string{d.Token}
+		default:
+			log.Errorf("handleEventCache invalid msg: %v", msg)
+			continue
+		}
+
+		for _, v := range tokens {
+			p.politeiad.InvalidateCache(v)
+		}
+	}
+}
@@ -5,13 +5,36 @@
 package events
 
 import (
+	"context"
 	"sync"
+	"time"
 )
 
-// Manager manages event listeners.
+const (
+	// closePollInterval is the interval at which the queue depths are
+	// checked while the manager is waiting for the queued events to be
+	// handled.
+	closePollInterval = 100 * time.Millisecond
+)
+
+// Manager manages event listeners and the notifiers that notification events
+// are routed to.
 type Manager struct {
 	sync.Mutex
 	listeners map[string][]chan interface{}
+	recorder  Recorder // Optional
+	closed    bool     // Events are dropped once closed
+
+	// depthMtx protects the listeners when reading the queue depths.
+	// The queue depths cannot be read using the manager mutex since
+	// it is held while an event is blocked on a full listener.
+	depthMtx sync.RWMutex
+
+	// Notification routing. The notifiers are protected by a separate
+	// mutex since notifications are sent by the event handlers.
+	ntfnMtx   sync.RWMutex
+	notifiers map[string]Notifier // [name]Notifier
+	routes    map[string][]string // [event][]notifier name
 }
 
 // Register registers an event listener (channel) to listen for the provided
@@ -19,6 +42,8 @@ type Manager struct {
 func (e *Manager) Register(event string, listener chan interface{}) {
 	e.Lock()
 	defer e.Unlock()
+	e.depthMtx.Lock()
+	defer e.depthMtx.Unlock()
 
 	l, ok := e.listeners[event]
 	if !ok {
@@ -31,12 +56,105 @@ func (e *Manager) Register(event string, listener chan interface{}) {
 	log.Debugf("Register event %v", event)
 }
 
+// QueueDepth returns the number of events that are waiting to be handled by
+// the listeners of each event type.
+func (e *Manager) QueueDepth() map[string]int {
+	e.depthMtx.RLock()
+	defer e.depthMtx.RUnlock()
+
+	depth := make(map[string]int, len(e.listeners))
+	for event, listeners := range e.listeners {
+		var n int
+		for _, ch := range listeners {
+			n += len(ch)
+		}
+		depth[event] = n
+	}
+	return depth
+}
+
+// Recorder records the events that are emitted by the Manager so that they
+// can be audited and replayed.
+type Recorder interface {
+	// Record records an emitted event.
+	Record(event string, data interface{})
+}
+
+// SetRecorder sets the recorder that all emitted events are passed to.
+func (e *Manager) SetRecorder(r Recorder) {
+	e.Lock()
+	defer e.Unlock()
+
+	e.recorder = r
+}
+
 // Emit emits an event by passing it to all channels that have been registered
-// to listen for the event.
+// to listen for the event. The event is recorded if a recorder has been set.
 func (e *Manager) Emit(event string, data interface{}) {
 	e.Lock()
 	defer e.Unlock()
 
+	if e.closed {
+		log.Warnf("Event %v dropped: manager is closed", event)
+		return
+	}
+	if e.recorder != nil {
+		e.recorder.Record(event, data)
+	}
+
+	e.emit(event, data)
+}
+
+// Replay emits a previously recorded event. The event is passed to the
+// listeners of the event but is not recorded again.
+func (e *Manager) Replay(event string, data interface{}) {
+	e.Lock()
+	defer e.Unlock()
+
+	if e.closed {
+		log.Warnf("Event %v dropped: manager is closed", event)
+		return
+	}
+	e.emit(event, data)
+}
+
+// Close stops the manager from accepting new events and waits until the
+// queued events have been received by their listeners. Events that are
+// emitted once the manager has been closed are dropped. An error is returned
+// if the context is canceled before the queues have been drained.
+func (e *Manager) Close(ctx context.Context) error {
+	// The lock is held while an event is passed to its listeners, so
+	// all events that are being emitted have been passed to the
+	// listeners once it has been acquired.
+	e.Lock()
+	e.closed = true
+	e.Unlock()
+
+	ticker := time.NewTicker(closePollInterval)
+	defer ticker.Stop()
+	for {
+		var queued int
+		for _, v := range e.QueueDepth() {
+			queued += v
+		}
+		if queued == 0 {
+			return nil
+		}
+		log.Debugf("Waiting for %v queued events", queued)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// emit passes an event to all channels that have been registered to listen
+// for the event.
+//
+// This function must be called WITH the lock held.
+func (e *Manager) emit(event string, data interface{}) {
 	listeners, ok := e.listeners[event]
 	if !ok {
 		return
@@ -53,5 +171,9 @@ func (e *Manager) Emit(event string, data interface{}) {
 func NewManager() *Manager {
 	return &Manager{
 		listeners: make(map[string][]chan interface{}),
+		notifiers: map[string]Notifier{
+			NotifierNoop: noop{},
+		},
+		routes: make(map[string][]string),
 	}
 }
@@ -0,0 +1,42 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestClose(t *testing.T) {
+	m := NewManager()
+	ch := make(chan interface{}, 2)
+	m.Register("proposal-new", ch)
+	m.Emit("proposal-new", 1)
+
+	// The queued event has not been received by the listener
+	ctx, cancel := context.WithTimeout(context.Background(),
+		10*time.Millisecond)
+	defer cancel()
+	err := m.Close(ctx)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("Close: got %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// Events are dropped once the manager has been closed
+	m.Emit("proposal-new", 2)
+	if len(ch) != 1 {
+		t.Fatalf("got %v queued events, want 1", len(ch))
+	}
+
+	// Close returns once the queued event has been received
+	go func() {
+		<-ch
+	}()
+	err = m.Close(context.Background())
+	if err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
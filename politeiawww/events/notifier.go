@@ -0,0 +1,155 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package events
+
+import (
+	"fmt"
+)
+
+const (
+	// NotifierNoop is the name of the notifier that discards all
+	// notifications. Routing a notification event to it disables the
+	// notifications of the event.
+	NotifierNoop = "noop"
+)
+
+// Recipient is a user that a notification is sent to.
+type Recipient struct {
+	Email    string
+	TimeZone string // IANA time zone, empty for UTC
+	Locale   string // Locale used to format dates
+}
+
+// Notification contains the contents of a notification. Notifiers use the
+// fields that apply to their channel and ignore notifications that do not
+// contain what they need, e.g. a notification without recipients is not
+// emailed.
+type Notification struct {
+	Subject   string
+	Timestamp int64  // UNIX time of the event
+	NtfnBit   uint64 // User notification setting of the notification
+
+	// Body returns the human readable body of the notification given
+	// the event timestamp formatted for the recipient.
+	Body func(date string) (string, error)
+
+	// Data is the machine readable event data.
+	Data interface{}
+}
+
+// Notifier sends notifications over a notification channel, e.g. email.
+type Notifier interface {
+	// Send sends the notification of the event to the recipients.
+	Send(event string, recipients []Recipient, n Notification) error
+}
+
+// noop is a Notifier that discards all notifications.
+type noop struct{}
+
+// Send satisfies the Notifier interface.
+func (noop) Send(event string, recipients []Recipient, n Notification) error {
+	return nil
+}
+
+// RegisterNotifier registers a notifier under the provided name.
+func (e *Manager) RegisterNotifier(name string, n Notifier) {
+	e.ntfnMtx.Lock()
+	defer e.ntfnMtx.Unlock()
+
+	e.notifiers[name] = n
+
+	log.Debugf("Register notifier %v", name)
+}
+
+// Route adds notifiers to the notifiers that a notification event is sent
+// to. It is used by the event producers to setup the default routes.
+func (e *Manager) Route(event string, notifiers ...string) {
+	e.ntfnMtx.Lock()
+	defer e.ntfnMtx.Unlock()
+
+	r := e.routes[event]
+	for _, v := range notifiers {
+		var found bool
+		for _, name := range r {
+			if v == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			r = append(r, v)
+		}
+	}
+	e.routes[event] = r
+}
+
+// SetRoute replaces the notifiers that a notification event is sent to. An
+// error is returned if the event does not have a route or if a notifier has
+// not been registered.
+func (e *Manager) SetRoute(event string, notifiers ...string) error {
+	e.ntfnMtx.Lock()
+	defer e.ntfnMtx.Unlock()
+
+	if _, ok := e.routes[event]; !ok {
+		return fmt.Errorf("unknown notification event %v", event)
+	}
+	for _, v := range notifiers {
+		if _, ok := e.notifiers[v]; !ok {
+			return fmt.Errorf("notifier %v is not registered", v)
+		}
+	}
+	e.routes[event] = notifiers
+
+	log.Debugf("Route notification event %v to %v", event, notifiers)
+
+	return nil
+}
+
+// IsRouted returns whether a notification event is routed to the provided
+// notifier.
+func (e *Manager) IsRouted(event, notifier string) bool {
+	e.ntfnMtx.RLock()
+	defer e.ntfnMtx.RUnlock()
+
+	for _, v := range e.routes[event] {
+		if v == notifier {
+			return true
+		}
+	}
+	return false
+}
+
+// Notify sends a notification to every notifier that the notification event
+// is routed to. All notifiers are tried and the first error is returned.
+//
+// Notify uses a separate lock from the event listeners so that it can be
+// called by an event handler while an event is being emitted.
+func (e *Manager) Notify(event string, recipients []Recipient, n Notification) error {
+	e.ntfnMtx.RLock()
+	var (
+		route     = e.routes[event]
+		names     = make([]string, 0, len(route))
+		notifiers = make([]Notifier, 0, len(route))
+	)
+	for _, v := range route {
+		if notifier, ok := e.notifiers[v]; ok {
+			names = append(names, v)
+			notifiers = append(notifiers, notifier)
+		}
+	}
+	e.ntfnMtx.RUnlock()
+
+	var firstErr error
+	for k, v := range notifiers {
+		err := v.Send(event, recipients, n)
+		if err != nil {
+			log.Errorf("Notify %v %v: %v", event, names[k], err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
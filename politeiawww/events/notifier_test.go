@@ -0,0 +1,64 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package events
+
+import (
+	"testing"
+)
+
+// testNotifier records the events that it was sent.
+type testNotifier struct {
+	events []string
+}
+
+// Send satisfies the Notifier interface.
+func (n *testNotifier) Send(event string, recipients []Recipient, ntfn Notification) error {
+	n.events = append(n.events, event)
+	return nil
+}
+
+func TestNotify(t *testing.T) {
+	var (
+		m    = NewManager()
+		smtp = &testNotifier{}
+		hook = &testNotifier{}
+	)
+	m.RegisterNotifier("smtp", smtp)
+	m.RegisterNotifier("webhook", hook)
+
+	// Setup the default routes
+	m.Route("comment-reply", "smtp")
+	m.Route("comment-reply", "webhook", "smtp")
+	m.Route("proposal-edit", "smtp")
+
+	// Invalid routes
+	err := m.SetRoute("invalid-event", "smtp")
+	if err == nil {
+		t.Errorf("SetRoute: unknown event: got nil error")
+	}
+	err = m.SetRoute("proposal-edit", "invalid-notifier")
+	if err == nil {
+		t.Errorf("SetRoute: unknown notifier: got nil error")
+	}
+
+	// Disable the proposal edit notifications
+	err = m.SetRoute("proposal-edit", NotifierNoop)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range []string{"comment-reply", "proposal-edit"} {
+		err = m.Notify(v, nil, Notification{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(smtp.events) != 1 || smtp.events[0] != "comment-reply" {
+		t.Errorf("smtp: got %v, want [comment-reply]", smtp.events)
+	}
+	if len(hook.events) != 1 || hook.events[0] != "comment-reply" {
+		t.Errorf("webhook: got %v, want [comment-reply]", hook.events)
+	}
+}
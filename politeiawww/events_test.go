@@ -0,0 +1,188 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	pdclient "github.com/decred/politeia/politeiad/client"
+	pdcomments "github.com/decred/politeia/politeiad/plugins/comments"
+	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	"github.com/decred/politeia/politeiawww/comments"
+	"github.com/decred/politeia/politeiawww/events"
+	"github.com/decred/politeia/politeiawww/records"
+	"github.com/decred/politeia/politeiawww/ticketvote"
+)
+
+// newTestCountServer returns a fake politeiad that replies to the comments
+// plugin Count commands. The count that is returned is the number of plugin
+// reads that the server has handled, so a count that is served from the
+// cache can be told apart from a count that was retrieved.
+func newTestCountServer(t *testing.T, id *identity.FullIdentity) *httptest.Server {
+	t.Helper()
+
+	var (
+		mtx   sync.Mutex
+		reads uint32
+	)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var pr pdv2.PluginReads
+		err := json.NewDecoder(r.Body).Decode(&pr)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		challenge, err := hex.DecodeString(pr.Challenge)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		mtx.Lock()
+		reads++
+		count := reads
+		mtx.Unlock()
+
+		payload, err := json.Marshal(pdcomments.CountReply{Count: count})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		replies := make([]pdv2.PluginCmdReply, 0, len(pr.Cmds))
+		for _, v := range pr.Cmds {
+			replies = append(replies, pdv2.PluginCmdReply{
+				Token:   v.Token,
+				ID:      v.ID,
+				Command: v.Command,
+				Payload: string(payload),
+			})
+		}
+		sig := id.SignMessage(challenge)
+		json.NewEncoder(w).Encode(pdv2.PluginReadsReply{
+			Response: hex.EncodeToString(sig[:]),
+			Replies:  replies,
+		})
+	}
+
+	return httptest.NewServer(http.HandlerFunc(handler))
+}
+
+func TestEventListenersCache(t *testing.T) {
+	id, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newTestCountServer(t, id)
+	defer srv.Close()
+
+	pdc, err := pdclient.New(srv.URL, "", "", "", &id.Public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pdc.EnableCache(time.Minute)
+	p := &politeiawww{
+		politeiad: pdc,
+		events:    events.NewManager(),
+	}
+	p.setupEventListenersCache()
+
+	const token = "e8bca53eb9ca4bb4"
+	count := func(t *testing.T) uint32 {
+		t.Helper()
+		c, err := pdc.CommentCount(context.Background(), []string{token})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c[token]
+	}
+
+	// The count is cached
+	prev := count(t)
+	if c := count(t); c != prev {
+		t.Fatalf("got count %v, want cached count %v", c, prev)
+	}
+
+	// Each event must invalidate the cached replies of its record
+	var tests = []struct {
+		event string
+		data  interface{}
+	}{
+		{
+			records.EventTypeSetStatus,
+			records.EventSetStatus{
+				Record: rcv1.Record{
+					CensorshipRecord: rcv1.CensorshipRecord{
+						Token: token,
+					},
+				},
+			},
+		},
+		{
+			comments.EventTypeNew,
+			comments.EventNew{Comment: cmv1.Comment{Token: token}},
+		},
+		{
+			comments.EventTypeDel,
+			comments.EventDel{Comment: cmv1.Comment{Token: token}},
+		},
+		{
+			ticketvote.EventTypeAuthorize,
+			ticketvote.EventAuthorize{
+				Auth: tkv1.Authorize{Token: token},
+			},
+		},
+		{
+			ticketvote.EventTypeStart,
+			ticketvote.EventStart{
+				Starts: []tkv1.StartDetails{{
+					Params: tkv1.VoteParams{Token: token},
+				}},
+			},
+		},
+		{
+			ticketvote.EventTypeFinished,
+			ticketvote.EventFinished{
+				Certificate: tkv1.CertificateReply{
+					Certificate: tkv1.VoteCertificate{Token: token},
+				},
+			},
+		},
+		{
+			ticketvote.EventTypeCastBallot,
+			ticketvote.EventCastBallot{Token: token},
+		},
+	}
+	for _, v := range tests {
+		t.Run(v.event, func(t *testing.T) {
+			p.events.Emit(v.event, v.data)
+
+			// The cache is invalidated asynchronously by the
+			// listener.
+			deadline := time.Now().Add(5 * time.Second)
+			for {
+				c := count(t)
+				if c != prev {
+					prev = c
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("cache was not invalidated")
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		})
+	}
+}
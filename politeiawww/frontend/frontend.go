@@ -0,0 +1,184 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/config"
+)
+
+const (
+	// indexFile is the file that is served for the routes of the single
+	// page application.
+	indexFile = "/index.html"
+
+	// cacheImmutable is the Cache-Control header of the files whose name
+	// contains a content hash. These files never change so they can be
+	// cached indefinitely.
+	cacheImmutable = "public, max-age=31536000, immutable"
+
+	// cacheRevalidate is the Cache-Control header of all other files.
+	// Clients must revalidate these files using the ETag before using a
+	// cached copy.
+	cacheRevalidate = "no-cache"
+)
+
+var (
+	// regexpHashedName matches the file names that contain a content
+	// hash, e.g. main.3f2a1b9c.chunk.js. The build tools of the web
+	// frontend add the hash to the names of the files whose content
+	// changes between builds.
+	regexpHashedName = regexp.MustCompile(`[.-][0-9a-f]{8,}\.`)
+)
+
+// file is a web frontend file that is held in memory.
+type file struct {
+	content   []byte
+	modTime   time.Time
+	etag      string // Quoted SHA256 digest of the content
+	immutable bool   // Name contains a content hash
+}
+
+// Frontend serves a built single page application web frontend from the
+// configured web root. The files are read into memory on startup, so
+// politeiawww must be restarted when the frontend is updated.
+type Frontend struct {
+	csp       string
+	files     map[string]*file // [path]file
+	apiRoutes []string
+	notFound  http.Handler
+}
+
+// isAPIRoute returns whether the provided path is part of one of the API
+// routes.
+func (f *Frontend) isAPIRoute(p string) bool {
+	for _, v := range f.apiRoutes {
+		if p == v || strings.HasPrefix(p, v+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup returns the file that is served for the provided path. Paths that
+// do not match a file and that do not have a file extension are routes of
+// the single page application and are served the index file. Nil is returned
+// if the path is not part of the web frontend.
+func (f *Frontend) lookup(p string) (string, *file) {
+	if fl, ok := f.files[p]; ok {
+		return p, fl
+	}
+	index := strings.TrimSuffix(p, "/") + indexFile
+	if fl, ok := f.files[index]; ok {
+		return index, fl
+	}
+	if f.isAPIRoute(p) || path.Ext(p) != "" {
+		return "", nil
+	}
+	return indexFile, f.files[indexFile]
+}
+
+// HandleFrontend serves the web frontend. Requests that are not part of the
+// web frontend are passed to the not found handler.
+func (f *Frontend) HandleFrontend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		f.notFound.ServeHTTP(w, r)
+		return
+	}
+	name, fl := f.lookup(path.Clean("/" + r.URL.Path))
+	if fl == nil {
+		f.notFound.ServeHTTP(w, r)
+		return
+	}
+
+	log.Tracef("HandleFrontend: %v %v", r.URL.Path, name)
+
+	h := w.Header()
+	h.Set("Content-Security-Policy", f.csp)
+	h.Set("X-Content-Type-Options", "nosniff")
+	h.Set("Referrer-Policy", "strict-origin-when-cross-origin")
+	h.Set("ETag", fl.etag)
+	if fl.immutable {
+		h.Set("Cache-Control", cacheImmutable)
+	} else {
+		h.Set("Cache-Control", cacheRevalidate)
+	}
+
+	// ServeContent sets the content type using the file extension and
+	// handles the conditional and range requests.
+	http.ServeContent(w, r, name, fl.modTime, bytes.NewReader(fl.content))
+}
+
+// loadFiles reads all files of the provided web root into memory. Hidden
+// files and directories are skipped.
+func loadFiles(root string) (map[string]*file, error) {
+	files := make(map[string]*file)
+	err := filepath.Walk(root, func(fp string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fp != root && strings.HasPrefix(fi.Name(), ".") {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, fp)
+		if err != nil {
+			return err
+		}
+		b, err := ioutil.ReadFile(fp)
+		if err != nil {
+			return err
+		}
+		files["/"+filepath.ToSlash(rel)] = &file{
+			content:   b,
+			modTime:   fi.ModTime(),
+			etag:      fmt.Sprintf(`"%x"`, sha256.Sum256(b)),
+			immutable: regexpHashedName.MatchString(fi.Name()),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := files[indexFile]; !ok {
+		return nil, fmt.Errorf("%v not found in %v", indexFile, root)
+	}
+	return files, nil
+}
+
+// New returns a new Frontend that serves the files of the configured web
+// root. Requests for the provided API routes are never served the index file
+// and are passed to the not found handler when no API route matched them.
+func New(cfg *config.Config, apiRoutes []string, notFound http.Handler) (*Frontend, error) {
+	files, err := loadFiles(cfg.WebRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Web frontend: %v files loaded from %v", len(files), cfg.WebRoot)
+
+	return &Frontend{
+		csp:       cfg.WebCSP,
+		files:     files,
+		apiRoutes: apiRoutes,
+		notFound:  notFound,
+	}, nil
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package frontend
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHandleFrontend(t *testing.T) {
+	// Setup web root
+	dir, err := ioutil.TempDir("", "frontend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"index.html":                 "index",
+		"robots.txt":                 "robots",
+		"static/main.3f2a1b9c.js":    "main",
+		"docs/index.html":            "docs",
+		".git/config":                "hidden",
+		"static/.DS_Store":           "hidden",
+		"static/roboto-regular.woff": "font",
+	}
+	for k, v := range files {
+		fp := filepath.Join(dir, filepath.FromSlash(k))
+		err := os.MkdirAll(filepath.Dir(fp), 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(fp, []byte(v), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	fl, err := loadFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &Frontend{
+		csp:       "default-src 'self'",
+		files:     fl,
+		apiRoutes: []string{"/v1", "/records/v1"},
+		notFound: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}),
+	}
+
+	// Setup tests
+	var tests = []struct {
+		name      string
+		method    string
+		path      string
+		wantCode  int
+		wantBody  string
+		immutable bool
+	}{
+		{"root", http.MethodGet, "/", http.StatusOK, "index", false},
+		{"file", http.MethodGet, "/robots.txt", http.StatusOK, "robots", false},
+		{"hashed file", http.MethodGet, "/static/main.3f2a1b9c.js",
+			http.StatusOK, "main", true},
+		{"unhashed file", http.MethodGet, "/static/roboto-regular.woff",
+			http.StatusOK, "font", false},
+		{"directory index", http.MethodGet, "/docs/", http.StatusOK,
+			"docs", false},
+		{"spa route", http.MethodGet, "/record/abcdef1", http.StatusOK,
+			"index", false},
+		{"missing file", http.MethodGet, "/static/missing.js",
+			http.StatusNotFound, "", false},
+		{"hidden file", http.MethodGet, "/static/.DS_Store",
+			http.StatusNotFound, "", false},
+		{"hidden directory", http.MethodGet, "/.git/config",
+			http.StatusOK, "index", false},
+		{"api route", http.MethodGet, "/v1/missing",
+			http.StatusNotFound, "", false},
+		{"api route root", http.MethodGet, "/records/v1",
+			http.StatusNotFound, "", false},
+		{"post", http.MethodPost, "/", http.StatusNotFound, "", false},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest(v.method, v.path, nil)
+			w := httptest.NewRecorder()
+			f.HandleFrontend(w, r)
+
+			if w.Code != v.wantCode {
+				t.Fatalf("got code %v, want %v", w.Code, v.wantCode)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			if w.Body.String() != v.wantBody {
+				t.Errorf("got body %q, want %q", w.Body.String(), v.wantBody)
+			}
+			if w.Header().Get("Content-Security-Policy") != f.csp {
+				t.Errorf("csp header not set")
+			}
+			cc := w.Header().Get("Cache-Control")
+			if v.immutable && cc != cacheImmutable {
+				t.Errorf("got cache control %q, want %q", cc, cacheImmutable)
+			}
+			if !v.immutable && cc != cacheRevalidate {
+				t.Errorf("got cache control %q, want %q", cc, cacheRevalidate)
+			}
+
+			// Verify the ETag is used for conditional requests
+			r = httptest.NewRequest(v.method, v.path, nil)
+			r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+			w = httptest.NewRecorder()
+			f.HandleFrontend(w, r)
+			if w.Code != http.StatusNotModified {
+				t.Errorf("got conditional code %v, want %v",
+					w.Code, http.StatusNotModified)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// readyTimeout is the maximum amount of time that the readiness
+	// checks are allowed to take.
+	readyTimeout = 5 * time.Second
+
+	// readySessionID is the session ID that is looked up to verify
+	// that the user database is reachable. It is not a valid session
+	// ID so the lookup never finds a session.
+	readySessionID = "readyz"
+)
+
+// handleHealth returns whether the politeiawww process is alive.
+func (p *politeiawww) handleHealth(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleHealth")
+
+	util.RespondWithJSON(w, http.StatusOK, www.HealthReply{
+		Status: www.HealthStatusOK,
+	})
+}
+
+// handleReady returns whether politeiawww is ready to serve requests. A 503 is
+// returned when any of the readiness checks failed so that load balancers take
+// the instance out of rotation.
+func (p *politeiawww) handleReady(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleReady")
+
+	ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+	defer cancel()
+
+	rr := p.ready(ctx)
+	code := http.StatusOK
+	if rr.Status != www.HealthStatusOK {
+		code = http.StatusServiceUnavailable
+	}
+
+	util.RespondWithJSON(w, code, rr)
+}
+
+// ready runs the readiness checks. The check errors are only logged so that
+// internal details are not leaked to the client.
+func (p *politeiawww) ready(ctx context.Context) www.ReadyReply {
+	checks := []struct {
+		name  string
+		check func() error
+	}{
+		{
+			name: www.ReadyCheckPoliteiad,
+			check: func() error {
+				_, err := p.politeiad.Identity(ctx)
+				return err
+			},
+		},
+		{
+			name: www.ReadyCheckUserDB,
+			check: func() error {
+				_, err := p.db.SessionGetByID(readySessionID)
+				if errors.Is(err, user.ErrSessionNotFound) {
+					return nil
+				}
+				return err
+			},
+		},
+		{
+			name:  www.ReadyCheckSessions,
+			check: p.sessions.Ping,
+		},
+		{
+			name: www.ReadyCheckMail,
+			check: func() error {
+				if !p.mail.IsEnabled() {
+					return errors.New("not configured")
+				}
+				return nil
+			},
+		},
+		{
+			name: www.ReadyCheckShutdown,
+			check: func() error {
+				if atomic.LoadUint32(&p.shuttingDown) == 1 {
+					return errors.New("shutting down")
+				}
+				return nil
+			},
+		},
+	}
+
+	rr := www.ReadyReply{
+		Status: www.HealthStatusOK,
+		Checks: make([]www.ReadyCheck, 0, len(checks)),
+	}
+	for _, v := range checks {
+		rc := www.ReadyCheck{
+			Name:   v.name,
+			Status: www.HealthStatusOK,
+		}
+		err := v.check()
+		if err != nil {
+			log.Warnf("Readiness check %v failed: %v", v.name, err)
+			rc.Status = www.HealthStatusFail
+			rr.Status = www.HealthStatusFail
+		}
+		rr.Checks = append(rr.Checks, rc)
+	}
+
+	return rr
+}
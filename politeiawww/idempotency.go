@@ -0,0 +1,289 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// idempotencyKeyMaxLength is the maximum length of an idempotency
+	// key.
+	idempotencyKeyMaxLength = 64
+
+	// idempotencyTTL is the amount of time that the reply to a request
+	// that contained an idempotency key is kept.
+	idempotencyTTL = 24 * time.Hour
+
+	// idempotencyMaxEntries is the maximum number of replies that are
+	// kept. Requests are processed without idempotency protection when
+	// the cache is full.
+	idempotencyMaxEntries = 10000
+
+	// idempotencyMaxBodySize is the maximum size of a reply body that
+	// is recorded. Larger replies are not recorded. A retry of such a
+	// request is executed again. The size allows the reply to the
+	// largest cast ballot to be recorded.
+	idempotencyMaxBodySize = 16 * 1024 * 1024
+
+	// idempotencyMaxSize is the maximum combined size of the recorded
+	// reply bodies. Replies are not recorded once the cache is full so
+	// that the cache memory is bounded.
+	idempotencyMaxSize = 128 * 1024 * 1024
+)
+
+// idempotencyEntry contains the reply to a request that contained an
+// idempotency key.
+type idempotencyEntry struct {
+	digest [sha256.Size]byte // Digest of the request body
+	expiry time.Time
+	done   bool          // Reply has been recorded
+	ready  chan struct{} // Closed once the request has been executed
+	code   int
+	header http.Header
+	body   []byte
+}
+
+// idempotencyCache caches the replies to write requests that contain an
+// idempotency key. A retried request that contains the same idempotency key
+// is sent the recorded reply instead of being executed a second time. This
+// allows a client to safely retry a write request that timed out without
+// knowing whether the original request was executed.
+//
+// The replies to requests that have a session are keyed by the user ID of
+// the session, the request method and route, and the idempotency key, so a
+// reply is only ever replayed to the user that made the original request.
+// The replies to requests without a session, e.g. cast ballots, are keyed by
+// the request method and route, the idempotency key, and the digest of the
+// request body, so a reply is only ever replayed to a client that sent the
+// exact same request.
+type idempotencyCache struct {
+	sync.Mutex
+	entries map[string]*idempotencyEntry // [scope+route+key]entry
+	size    int                          // Combined size of reply bodies
+
+	// userID returns the user ID of the session of the request or an
+	// empty string if the request does not have a session.
+	userID func(w http.ResponseWriter, r *http.Request) string
+}
+
+// newIdempotencyCache returns a new idempotencyCache. The provided function
+// returns the user ID of the session of a request.
+func newIdempotencyCache(userID func(http.ResponseWriter, *http.Request) string) *idempotencyCache {
+	return &idempotencyCache{
+		entries: make(map[string]*idempotencyEntry, 256),
+		userID:  userID,
+	}
+}
+
+// prune removes all expired entries from the cache.
+//
+// This function must be called WITH the lock held.
+func (c *idempotencyCache) prune(now time.Time) {
+	for k, v := range c.entries {
+		if v.done && now.After(v.expiry) {
+			c.del(k)
+		}
+	}
+}
+
+// del removes an entry from the cache.
+//
+// This function must be called WITH the lock held.
+func (c *idempotencyCache) del(key string) {
+	e, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	c.size -= len(e.body)
+	delete(c.entries, key)
+}
+
+// idempotencyRecorder records the reply to a request while writing it to
+// the underlying response writer. Recording stops once the body exceeds the
+// maximum body size.
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	code      int
+	body      bytes.Buffer
+	truncated bool
+}
+
+// WriteHeader satisfies the http.ResponseWriter interface.
+func (r *idempotencyRecorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Write satisfies the http.ResponseWriter interface.
+func (r *idempotencyRecorder) Write(b []byte) (int, error) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	if !r.truncated {
+		if r.body.Len()+len(b) > idempotencyMaxBodySize {
+			r.truncated = true
+			r.body.Reset()
+		} else {
+			r.body.Write(b)
+		}
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// middleware replays the recorded reply of write requests that contain a
+// previously seen idempotency key. A request that reuses an idempotency key
+// with a different body, or that is sent while the original request is still
+// being executed, waits for the original request to finish and is then sent
+// its reply. A request that reuses an idempotency key with a different body
+// is rejected with a 409. Replies with a 5xx status code are not recorded so
+// that the request can be retried.
+//
+// The middleware must wrap the route handler after the session has been
+// authenticated so that the requests of logged in users are scoped to the
+// user. It is added to the handlers of all routes, so a client can safely
+// retry any write request. Replies to requests without a session that set a
+// cookie, e.g. a login, are not recorded since the cookie can't be replayed.
+func (c *idempotencyCache) middleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(www.IdempotencyKey)
+		if key == "" || r.Method != http.MethodPost {
+			next(w, r)
+			return
+		}
+		if len(key) > idempotencyKeyMaxLength {
+			util.RespondWithJSON(w, http.StatusBadRequest, www.UserError{
+				ErrorCode:    www.ErrorStatusInvalidInput,
+				ErrorContext: []string{"idempotency key too long"},
+			})
+			return
+		}
+
+		// Read the request body so that it can be included in the
+		// request digest. The body is replaced so that the handler
+		// is still able to read it. The size of the body is bounded
+		// by the request body size limit of the route.
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			util.RespondWithJSON(w, http.StatusBadRequest, www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			})
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(b))
+		digest := sha256.Sum256(b)
+
+		// Scope the idempotency key to the route and to either the user
+		// or, for requests without a session, the request body.
+		userID := c.userID(w, r)
+		scope := userID
+		if scope == "" {
+			scope = "public " + hex.EncodeToString(digest[:])
+		}
+		key = scope + " " + r.Method + " " + r.URL.Path + " " + key
+
+		// Lookup the idempotency key. A retry of a request that is still
+		// being executed waits for the original request to finish.
+		var (
+			now = time.Now()
+			e   *idempotencyEntry
+			ok  bool
+		)
+		for {
+			c.Lock()
+			e, ok = c.entries[key]
+			if ok && e.done && now.After(e.expiry) {
+				c.del(key)
+				ok = false
+			}
+			if !ok || e.done || e.digest != digest {
+				break
+			}
+			c.Unlock()
+			select {
+			case <-e.ready:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		switch {
+		case ok && e.digest != digest:
+			c.Unlock()
+			log.Debugf("%v idempotency key reused: %v",
+				util.RemoteAddr(r), key)
+			util.RespondWithJSON(w, http.StatusConflict, www.UserError{
+				ErrorCode:    www.ErrorStatusInvalidInput,
+				ErrorContext: []string{"idempotency key reused"},
+			})
+			return
+
+		case ok:
+			c.Unlock()
+			log.Debugf("%v idempotent replay: %v", util.RemoteAddr(r), key)
+			for k, v := range e.header {
+				w.Header()[k] = v
+			}
+			w.Header().Set(www.IdempotentReplay, "true")
+			w.WriteHeader(e.code)
+			w.Write(e.body)
+			return
+		}
+		if len(c.entries) >= idempotencyMaxEntries {
+			c.prune(now)
+		}
+		if len(c.entries) >= idempotencyMaxEntries {
+			c.Unlock()
+			log.Warnf("idempotency cache full")
+			next(w, r)
+			return
+		}
+		e = &idempotencyEntry{
+			digest: digest,
+			ready:  make(chan struct{}),
+		}
+		c.entries[key] = e
+		c.Unlock()
+
+		// Execute the request and record the reply
+		rec := &idempotencyRecorder{ResponseWriter: w}
+		defer func() {
+			c.Lock()
+			defer c.Unlock()
+			defer close(e.ready)
+
+			setCookie := w.Header().Get("Set-Cookie") != ""
+			switch {
+			case rec.code == 0, rec.code >= http.StatusInternalServerError,
+				rec.truncated, userID == "" && setCookie:
+				delete(c.entries, key)
+				return
+			case c.size+rec.body.Len() > idempotencyMaxSize:
+				c.prune(time.Now())
+				if c.size+rec.body.Len() > idempotencyMaxSize {
+					log.Warnf("idempotency cache full")
+					delete(c.entries, key)
+					return
+				}
+			}
+			e.done = true
+			e.expiry = time.Now().Add(idempotencyTTL)
+			e.code = rec.code
+			e.header = w.Header().Clone()
+			e.header.Del("Set-Cookie")
+			e.body = rec.body.Bytes()
+			c.size += len(e.body)
+		}()
+		next(rec, r)
+	}
+}
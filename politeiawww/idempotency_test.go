@@ -0,0 +1,156 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	var calls int
+	userID := func(w http.ResponseWriter, r *http.Request) string {
+		return r.Header.Get("user")
+	}
+	h := newIdempotencyCache(userID).middleware(
+		func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("reply"))
+		})
+
+	sendAs := func(user, key, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/v1/route",
+			strings.NewReader(body))
+		if key != "" {
+			r.Header.Set(www.IdempotencyKey, key)
+		}
+		r.Header.Set("user", user)
+		w := httptest.NewRecorder()
+		h(w, r)
+		return w
+	}
+	send := func(key, body string) *httptest.ResponseRecorder {
+		return sendAs("user1", key, body)
+	}
+
+	// The first request is executed
+	w := send("key", "body")
+	if w.Code != http.StatusOK || calls != 1 {
+		t.Fatalf("got code %v calls %v, want 200 1", w.Code, calls)
+	}
+
+	// A retry is replayed without being executed
+	w = send("key", "body")
+	if w.Code != http.StatusOK || calls != 1 {
+		t.Fatalf("got code %v calls %v, want 200 1", w.Code, calls)
+	}
+	if w.Body.String() != "reply" ||
+		w.Header().Get(www.IdempotentReplay) != "true" {
+		t.Fatalf("reply was not replayed")
+	}
+
+	// Reusing the key for a different request is rejected
+	w = send("key", "other")
+	if w.Code != http.StatusConflict || calls != 1 {
+		t.Fatalf("got code %v calls %v, want 409 1", w.Code, calls)
+	}
+
+	// The reply is not replayed to a different user
+	w = sendAs("user2", "key", "body")
+	if w.Header().Get(www.IdempotentReplay) != "" || calls != 2 {
+		t.Fatalf("reply was replayed to a different user")
+	}
+
+	// Requests without a key are always executed
+	send("", "body")
+	send("", "body")
+	if calls != 4 {
+		t.Fatalf("got calls %v, want 4", calls)
+	}
+
+	// Requests without a session are only replayed to a request with
+	// the same body
+	sendAs("", "key", "body")
+	w = sendAs("", "key", "body")
+	if w.Header().Get(www.IdempotentReplay) != "true" || calls != 5 {
+		t.Fatalf("reply was not replayed")
+	}
+	w = sendAs("", "key", "other")
+	if w.Code != http.StatusOK || calls != 6 {
+		t.Fatalf("got code %v calls %v, want 200 6", w.Code, calls)
+	}
+}
+
+func TestIdempotencyCastBallotRetry(t *testing.T) {
+	// The handler casts the ballot on the first call and rejects the
+	// votes as already cast on subsequent calls. The first call blocks
+	// until it is released so that a retry can be sent while the
+	// original request is still being executed.
+	var (
+		calls   int
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+	userID := func(w http.ResponseWriter, r *http.Request) string {
+		return ""
+	}
+	h := newIdempotencyCache(userID).middleware(
+		func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls > 1 {
+				w.Write([]byte("already voted"))
+				return
+			}
+			close(started)
+			<-release
+			w.Write([]byte("ballot cast"))
+		})
+
+	route := tkv1.APIRoute + tkv1.RouteCastBallot
+	send := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, route,
+			strings.NewReader(`{"votes":[]}`))
+		r.Header.Set(www.IdempotencyKey, "ballot")
+		w := httptest.NewRecorder()
+		h(w, r)
+		return w
+	}
+
+	// Send the ballot and retry it while it is still being cast, as a
+	// client does when the original request times out. The retry waits
+	// for the original request and is sent its reply.
+	replies := make(chan *httptest.ResponseRecorder, 2)
+	go func() {
+		replies <- send()
+	}()
+	<-started
+	go func() {
+		replies <- send()
+	}()
+	close(release)
+	for i := 0; i < 2; i++ {
+		w := <-replies
+		if w.Code != http.StatusOK || w.Body.String() != "ballot cast" {
+			t.Fatalf("got code %v reply %q, want 200 ballot cast",
+				w.Code, w.Body.String())
+		}
+	}
+
+	// A retry after the ballot has been cast is replayed
+	w := send()
+	if w.Body.String() != "ballot cast" ||
+		w.Header().Get(www.IdempotentReplay) != "true" {
+		t.Fatalf("got reply %q, want ballot cast replay", w.Body.String())
+	}
+	if calls != 1 {
+		t.Fatalf("got calls %v, want 1", calls)
+	}
+}
@@ -38,7 +38,8 @@ const (
 	// invoiceFile contains the file name of the invoice file
 	invoiceFile = "invoice.json"
 
-	// Sanity check for Contractor Rates
+	// Sanity check for Contractor Rates. These are the rates of the
+	// domains without a rate schedule.
 	minRate = 500   // 5 USD (in cents)
 	maxRate = 50000 // 500 USD (in cents)
 
@@ -717,7 +718,7 @@ func (p *politeiawww) processNewInvoice(ctx context.Context, ni cms.NewInvoice,
 	}
 
 	// Verify NewRecord challenge
-	err = util.VerifyChallenge(p.cfg.Identity, challenge, pdReply.Response)
+	err = util.VerifyChallenge(p.politeiadIdentity(), challenge, pdReply.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -768,7 +769,7 @@ func (p *politeiawww) processNewInvoice(ctx context.Context, ni cms.NewInvoice,
 	}
 
 	// Verify the SetUnvettedStatus challenge.
-	err = util.VerifyChallenge(p.cfg.Identity, challenge,
+	err = util.VerifyChallenge(p.politeiadIdentity(), challenge,
 		pdSetUnvettedStatusReply.Response)
 	if err != nil {
 		return nil, err
@@ -977,7 +978,12 @@ func (p *politeiawww) validateInvoice(ni cms.NewInvoice, u *user.CMSUser) error
 					ErrorCode: cms.ErrorStatusInvoiceMissingRate,
 				}
 			}
-			if invInput.ContractorRate < uint(minRate) || invInput.ContractorRate > uint(maxRate) {
+			rateMin, rateMax, err := p.contractorRates(u.Domain,
+				invInput.Month, invInput.Year)
+			if err != nil {
+				return err
+			}
+			if invInput.ContractorRate < rateMin || invInput.ContractorRate > rateMax {
 				return www.UserError{
 					ErrorCode: cms.ErrorStatusInvoiceInvalidRate,
 				}
@@ -989,6 +995,10 @@ func (p *politeiawww) validateInvoice(ni cms.NewInvoice, u *user.CMSUser) error
 					ErrorCode: cms.ErrorStatusInvoiceRequireLineItems,
 				}
 			}
+			domains, err := p.cmsDB.Domains()
+			if err != nil {
+				return err
+			}
 			for _, lineInput := range invInput.LineItems {
 				domain := formatInvoiceField(lineInput.Domain)
 				if !validateInvoiceField(domain) {
@@ -1002,6 +1012,10 @@ func (p *politeiawww) validateInvoice(ni cms.NewInvoice, u *user.CMSUser) error
 						ErrorCode: cms.ErrorStatusMalformedSubdomain,
 					}
 				}
+				err := validateLineItemDomain(domains, domain, subdomain)
+				if err != nil {
+					return err
+				}
 
 				description := formatInvoiceField(lineInput.Description)
 				if !validateInvoiceField(description) {
@@ -1074,7 +1088,12 @@ func (p *politeiawww) validateInvoice(ni cms.NewInvoice, u *user.CMSUser) error
 							ErrorCode: cms.ErrorStatusInvalidLaborExpense,
 						}
 					}
-					if lineInput.SubRate < uint(minRate) || lineInput.SubRate > uint(maxRate) {
+					subMin, subMax, err := p.contractorRates(subUser.Domain,
+						invInput.Month, invInput.Year)
+					if err != nil {
+						return err
+					}
+					if lineInput.SubRate < subMin || lineInput.SubRate > subMax {
 						return www.UserError{
 							ErrorCode: cms.ErrorStatusInvoiceInvalidRate,
 						}
@@ -1150,7 +1169,9 @@ func (p *politeiawww) validateInvoice(ni cms.NewInvoice, u *user.CMSUser) error
 	return nil
 }
 
-func filterDomainInvoice(inv *cms.InvoiceRecord, requestedDomain int) cms.InvoiceRecord {
+// filterDomainInvoice filters out the private information of an invoice and
+// the line items that do not belong to the provided domain.
+func filterDomainInvoice(inv *cms.InvoiceRecord, domain string) cms.InvoiceRecord {
 	inv.Files = nil
 	inv.Input.ContractorContact = ""
 	inv.Input.ContractorLocation = ""
@@ -1160,16 +1181,8 @@ func filterDomainInvoice(inv *cms.InvoiceRecord, requestedDomain int) cms.Invoic
 
 	filteredLineItems := make([]cms.LineItemsInput, 0, len(inv.Input.LineItems))
 	for _, li := range inv.Input.LineItems {
-		// Get the Supported CMS Domain from API
-		var cmsDomain cms.AvailableDomain
-		for _, domain := range cms.PolicySupportedCMSDomains {
-			if int(domain.Type) == requestedDomain {
-				cmsDomain = domain
-			}
-		}
-
 		// Filter out any line item that doesn't match the requested Domain
-		if strings.ToLower(li.Domain) == cmsDomain.Description {
+		if domain != "" && strings.ToLower(li.Domain) == domain {
 			li.Expenses = 0
 			li.SubRate = 0
 			filteredLineItems = append(filteredLineItems, li)
@@ -1218,7 +1231,11 @@ func (p *politeiawww) processInvoiceDetails(invDetails cms.InvoiceDetails, u *us
 		reply.Invoice = *invRec
 		reply.Payout = payout
 	} else {
-		reply.Invoice = filterDomainInvoice(invRec, requestingUser.Domain)
+		domain, err := p.cmsDomainDescription(requestingUser.Domain)
+		if err != nil {
+			return nil, err
+		}
+		reply.Invoice = filterDomainInvoice(invRec, domain)
 	}
 	return &reply, nil
 }
@@ -1303,7 +1320,7 @@ func (p *politeiawww) processSetInvoiceStatus(ctx context.Context, sis cms.SetIn
 	}
 
 	// Verify the UpdateVettedMetadata challenge.
-	err = util.VerifyChallenge(p.cfg.Identity, challenge, pdReply.Response)
+	err = util.VerifyChallenge(p.politeiadIdentity(), challenge, pdReply.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -1556,7 +1573,7 @@ func (p *politeiawww) processEditInvoice(ctx context.Context, ei cms.EditInvoice
 		return nil, fmt.Errorf("Unmarshal UpdateUnvettedReply: %v", err)
 	}
 
-	err = util.VerifyChallenge(p.cfg.Identity, challenge, pdReply.Response)
+	err = util.VerifyChallenge(p.politeiadIdentity(), challenge, pdReply.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -1603,7 +1620,7 @@ func (p *politeiawww) processEditInvoice(ctx context.Context, ei cms.EditInvoice
 	}
 
 	// Verify the UpdateVettedMetadata challenge.
-	err = util.VerifyChallenge(p.cfg.Identity, challenge, updateMetaReply.Response)
+	err = util.VerifyChallenge(p.politeiadIdentity(), challenge, updateMetaReply.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -1867,6 +1884,11 @@ func (p *politeiawww) processInvoices(ai cms.Invoices, u *user.User) (*cms.UserI
 		return dbInvs[a].Timestamp < dbInvs[b].Timestamp
 	})
 
+	domain, err := p.cmsDomainDescription(requestingUser.Domain)
+	if err != nil {
+		return nil, err
+	}
+
 	invRecs := make([]cms.InvoiceRecord, 0, len(dbInvs))
 	for _, v := range dbInvs {
 		// Only return up to max page size if start time and end time are
@@ -1901,7 +1923,7 @@ func (p *politeiawww) processInvoices(ai cms.Invoices, u *user.User) (*cms.UserI
 				continue
 			}
 
-			inv = filterDomainInvoice(&inv, requestingUser.Domain)
+			inv = filterDomainInvoice(&inv, domain)
 		}
 		// Only return invoices that have non-zero line items after filtering.
 		if len(inv.Input.LineItems) > 0 {
@@ -2001,7 +2023,7 @@ func (p *politeiawww) processNewCommentInvoice(ctx context.Context, nc www.NewCo
 			"PluginCommandReply: %v", err)
 	}
 
-	err = util.VerifyChallenge(p.cfg.Identity, challenge, reply.Response)
+	err = util.VerifyChallenge(p.politeiadIdentity(), challenge, reply.Response)
 	if err != nil {
 		return nil, err
 	}
@@ -2176,7 +2198,7 @@ func (p *politeiawww) processPayInvoices(ctx context.Context, u *user.User) (*cm
 		}
 
 		// Verify the UpdateVettedMetadata challenge.
-		err = util.VerifyChallenge(p.cfg.Identity, challenge, pdReply.Response)
+		err = util.VerifyChallenge(p.politeiadIdentity(), challenge, pdReply.Response)
 		if err != nil {
 			return nil, err
 		}
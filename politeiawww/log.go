@@ -13,15 +13,22 @@ import (
 	"github.com/decred/politeia/politeiawww/codetracker/github"
 	ghdb "github.com/decred/politeia/politeiawww/codetracker/github/database/cockroachdb"
 	"github.com/decred/politeia/politeiawww/comments"
+	"github.com/decred/politeia/politeiawww/eventlog"
 	"github.com/decred/politeia/politeiawww/events"
+	"github.com/decred/politeia/politeiawww/frontend"
 	"github.com/decred/politeia/politeiawww/mail"
+	"github.com/decred/politeia/politeiawww/oauth"
+	"github.com/decred/politeia/politeiawww/passwords"
 	"github.com/decred/politeia/politeiawww/pi"
 	"github.com/decred/politeia/politeiawww/records"
 	"github.com/decred/politeia/politeiawww/sessions"
+	"github.com/decred/politeia/politeiawww/telemetry"
 	"github.com/decred/politeia/politeiawww/ticketvote"
 	"github.com/decred/politeia/politeiawww/user/cockroachdb"
 	"github.com/decred/politeia/politeiawww/user/localdb"
 	"github.com/decred/politeia/politeiawww/user/mysql"
+	"github.com/decred/politeia/politeiawww/webhook"
+	"github.com/decred/politeia/util/tracing"
 	"github.com/decred/politeia/wsdcrdata"
 	"github.com/decred/slog"
 	"github.com/jrick/logrotate/rotator"
@@ -70,8 +77,11 @@ var (
 // Initialize package-global logger variables.
 func init() {
 	mail.UseLogger(log)
+	tracing.UseLogger(log)
 	sessions.UseLogger(sessionsLog)
 	events.UseLogger(eventsLog)
+	webhook.UseLogger(eventsLog)
+	eventlog.UseLogger(eventsLog)
 
 	// UserDB loggers
 	localdb.UseLogger(userdbLog)
@@ -83,6 +93,10 @@ func init() {
 	comments.UseLogger(apiLog)
 	ticketvote.UseLogger(apiLog)
 	pi.UseLogger(apiLog)
+	telemetry.UseLogger(apiLog)
+	oauth.UseLogger(apiLog)
+	passwords.UseLogger(apiLog)
+	frontend.UseLogger(apiLog)
 
 	// CMS loggers
 	cmsdb.UseLogger(cmsdbLog)
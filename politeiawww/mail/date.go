@@ -0,0 +1,85 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mail
+
+import (
+	"sort"
+	"time"
+
+	// Embed the time zone database so that the user time zones can be
+	// loaded on systems that do not have it installed.
+	_ "time/tzdata"
+)
+
+const (
+	// DefaultLocale is the locale that is used to format the dates of
+	// users that have not set a locale.
+	DefaultLocale = "en-US"
+)
+
+var (
+	// dateLayouts contains the date layouts of the supported locales.
+	// Numeric layouts are used so that month and weekday names do not
+	// need to be translated.
+	dateLayouts = map[string]string{
+		"de-DE": "02.01.2006 15:04 MST",
+		"en-GB": "02/01/2006 15:04 MST",
+		"en-US": "01/02/2006 3:04 PM MST",
+		"es-ES": "02/01/2006 15:04 MST",
+		"fr-FR": "02/01/2006 15:04 MST",
+		"it-IT": "02/01/2006 15:04 MST",
+		"ja-JP": "2006/01/02 15:04 MST",
+		"ko-KR": "2006. 01. 02. 15:04 MST",
+		"nl-NL": "02-01-2006 15:04 MST",
+		"pt-BR": "02/01/2006 15:04 MST",
+		"ru-RU": "02.01.2006 15:04 MST",
+		"zh-CN": "2006-01-02 15:04 MST",
+	}
+)
+
+// Locales returns the locales that are supported for formatting dates.
+func Locales() []string {
+	l := make([]string, 0, len(dateLayouts))
+	for k := range dateLayouts {
+		l = append(l, k)
+	}
+	sort.Strings(l)
+	return l
+}
+
+// IsValidLocale returns whether the locale is supported. An empty locale is
+// valid and uses the DefaultLocale.
+func IsValidLocale(locale string) bool {
+	if locale == "" {
+		return true
+	}
+	_, ok := dateLayouts[locale]
+	return ok
+}
+
+// IsValidTimeZone returns whether the time zone is a valid IANA time zone
+// name, e.g. "America/Chicago". An empty time zone is valid and uses UTC.
+func IsValidTimeZone(timeZone string) bool {
+	if timeZone == "" {
+		return true
+	}
+	_, err := time.LoadLocation(timeZone)
+	return err == nil
+}
+
+// FormatTime formats a UNIX timestamp using the provided time zone and locale.
+// UTC and the DefaultLocale are used when the time zone or the locale are
+// empty or invalid.
+func FormatTime(timestamp int64, timeZone, locale string) string {
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	layout, ok := dateLayouts[locale]
+	if !ok {
+		layout = dateLayouts[DefaultLocale]
+	}
+	return time.Unix(timestamp, 0).In(loc).Format(layout)
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mail
+
+import "testing"
+
+func TestFormatTime(t *testing.T) {
+	// 2021-03-01 18:30:00 UTC
+	var ts int64 = 1614623400
+
+	var tests = []struct {
+		name     string
+		timeZone string
+		locale   string
+		want     string
+	}{
+		{"defaults", "", "", "03/01/2021 6:30 PM UTC"},
+		{"time zone", "America/Chicago", "en-US", "03/01/2021 12:30 PM CST"},
+		{"locale", "Europe/Berlin", "de-DE", "01.03.2021 19:30 CET"},
+		{"invalid", "Mars/Olympus", "xx-XX", "03/01/2021 6:30 PM UTC"},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			got := FormatTime(ts, v.timeZone, v.locale)
+			if got != v.want {
+				t.Fatalf("got %v, want %v", got, v.want)
+			}
+		})
+	}
+}
@@ -5,23 +5,143 @@
 package mail
 
 import (
-	"crypto/tls"
-	"crypto/x509"
+	"errors"
 	"fmt"
-	"io/ioutil"
 	"net/mail"
-	"net/url"
-
-	"github.com/dajohi/goemail"
+	"strings"
+	"sync"
+	"sync/atomic"
 )
 
-// Client provides an SMTP client for sending emails from a preset email
-// address.
+// Client sends emails from a preset email address using the configured mail
+// providers. An email that cannot be sent by a provider is sent using the
+// next provider.
 type Client struct {
-	smtp        *goemail.SMTP // SMTP server
-	mailName    string        // From name
-	mailAddress string        // From email address
-	disabled    bool          // Has email been disabled
+	sync.RWMutex
+	providers   []*provider // Mail providers in order of priority
+	mailName    string      // From name
+	mailAddress string      // From email address
+	disabled    bool        // Has email been disabled
+
+	// suppressed contains the email addresses that have hard bounced
+	// or that have filed a complaint. Emails are never sent to these
+	// addresses.
+	suppressed map[string]struct{} // [email]struct{}
+
+	// unsubscribe returns the unsubscribe links that are included in
+	// notification emails.
+	unsubscribe UnsubscribeFunc
+
+	// failures is the number of emails that could not be sent by any
+	// of the providers. It must be accessed atomically.
+	failures uint64
+}
+
+// SendFailures returns the number of emails that could not be sent by any of
+// the mail providers since the client was created.
+func (c *Client) SendFailures() uint64 {
+	return atomic.LoadUint64(&c.failures)
+}
+
+// ProviderStats returns the send statistics of the mail providers since the
+// client was created.
+func (c *Client) ProviderStats() map[string]ProviderStats {
+	stats := make(map[string]ProviderStats, len(c.providers))
+	for _, v := range c.providers {
+		stats[v.Name()] = v.stats()
+	}
+	return stats
+}
+
+// send sends an email using the mail providers in order of priority. The
+// email is sent using the next provider when a provider fails to send it.
+// When a provider was able to send the email to some of the recipients, the
+// next provider only sends it to the remaining recipients so that no one
+// receives the email twice. An error is returned when none of the providers
+// were able to send the email.
+func (c *Client) send(msg *Message) error {
+	var errs []string
+	for _, v := range c.providers {
+		err := v.send(msg)
+		if err == nil {
+			return nil
+		}
+		log.Errorf("Mail provider %v send failed: %v", v.Name(), err)
+		errs = append(errs, fmt.Sprintf("%v: %v", v.Name(), err))
+
+		var perr *PartialSendError
+		if errors.As(err, &perr) {
+			m := *msg
+			m.To = perr.To
+			m.BCC = perr.BCC
+			msg = &m
+		}
+	}
+	atomic.AddUint64(&c.failures, 1)
+	return fmt.Errorf("%v", strings.Join(errs, ", "))
+}
+
+// newMessage returns a new email from the client's email address.
+func (c *Client) newMessage(subject, body string) *Message {
+	return &Message{
+		FromName:    c.mailName,
+		FromAddress: c.mailAddress,
+		Subject:     subject,
+		Body:        body,
+	}
+}
+
+// Unsubscribe contains the one-click unsubscribe links of a notification
+// email recipient.
+type Unsubscribe struct {
+	Category string // Unsubscribe from the notification category
+	All      string // Unsubscribe from all notifications
+}
+
+// UnsubscribeFunc returns the unsubscribe links of the provided recipient for
+// the provided notification category.
+type UnsubscribeFunc func(email string, ntfn uint64) (*Unsubscribe, error)
+
+// unsubscribeText is appended to the body of notification emails.
+const unsubscribeText = `
+--
+Unsubscribe from these notifications: %v
+Unsubscribe from all notifications: %v
+`
+
+// SetUnsubscribe sets the function that returns the unsubscribe links that
+// are included in notification emails.
+func (c *Client) SetUnsubscribe(fn UnsubscribeFunc) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.unsubscribe = fn
+}
+
+// Suppress adds an email address to the suppression list. No emails will be
+// sent to a suppressed address.
+func (c *Client) Suppress(email string) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.suppressed[strings.ToLower(email)] = struct{}{}
+}
+
+// Unsuppress removes an email address from the suppression list.
+func (c *Client) Unsuppress(email string) {
+	c.Lock()
+	defer c.Unlock()
+
+	delete(c.suppressed, strings.ToLower(email))
+}
+
+// IsSuppressed returns whether the email address is on the suppression list.
+func (c *Client) IsSuppressed(email string) bool {
+	c.RLock()
+	defer c.RUnlock()
+
+	_, ok := c.suppressed[strings.ToLower(email)]
+	return ok
 }
 
 // IsEnabled returns whether the mail server is enabled.
@@ -30,44 +150,94 @@ func (c *Client) IsEnabled() bool {
 }
 
 // SendTo sends an email with the given subject and body to the provided list
-// of email addresses.
+// of email addresses. Suppressed email addresses are skipped.
 func (c *Client) SendTo(subject, body string, recipients []string) error {
-	if c.disabled || len(recipients) == 0 {
+	if c.disabled {
 		return nil
 	}
 
-	// Setup email
-	msg := goemail.NewMessage(c.mailAddress, subject, body)
-	msg.SetName(c.mailName)
+	// Remove suppressed recipients
+	r := make([]string, 0, len(recipients))
+	for _, v := range recipients {
+		if c.IsSuppressed(v) {
+			log.Debugf("Email suppressed: %v", v)
+			continue
+		}
+		r = append(r, v)
+	}
+	recipients = r
+	if len(recipients) == 0 {
+		return nil
+	}
 
-	// Add all recipients to BCC
+	// Setup email and add all recipients to BCC
+	msg := c.newMessage(subject, body)
+	msg.BCC = recipients
+
+	return c.send(msg)
+}
+
+// SendToNtfn sends a notification email with the given subject and body to
+// the provided list of email addresses. The ntfn argument is the notification
+// category of the email. When unsubscribe links have been setup, a separate
+// email is sent to each recipient that contains the recipient's unsubscribe
+// links in the body and in the List-Unsubscribe headers. Suppressed email
+// addresses are skipped.
+func (c *Client) SendToNtfn(subject, body string, ntfn uint64, recipients []string) error {
+	if c.disabled {
+		return nil
+	}
+	c.RLock()
+	unsubscribe := c.unsubscribe
+	c.RUnlock()
+	if unsubscribe == nil {
+		return c.SendTo(subject, body, recipients)
+	}
+
+	var errs []string
 	for _, v := range recipients {
-		msg.AddBCC(v)
+		if c.IsSuppressed(v) {
+			log.Debugf("Email suppressed: %v", v)
+			continue
+		}
+		u, err := unsubscribe(v, ntfn)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%v: %v", v, err))
+			continue
+		}
+
+		msg := c.newMessage(subject,
+			body+fmt.Sprintf(unsubscribeText, u.Category, u.All))
+		msg.To = []string{v}
+		msg.Headers = map[string]string{
+			"List-Unsubscribe":      "<" + u.Category + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		}
+
+		err = c.send(msg)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%v: %v", v, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("send failed: %v", strings.Join(errs, ", "))
 	}
 
-	return c.smtp.Send(msg)
+	return nil
 }
 
-// New returns a new mail Client.
-func New(host, user, password, emailAddress, certPath string, skipVerify bool) (*Client, error) {
-	// Email is considered disabled if any of the required user
-	// credentials are mising.
-	if host == "" || user == "" || password == "" {
+// New returns a new mail Client that sends emails from the provided email
+// address. The providers are used in the order that they are provided. Email
+// is disabled when no providers are provided.
+func New(emailAddress string, providers ...Provider) (*Client, error) {
+	if len(providers) == 0 {
 		log.Infof("Email: DISABLED")
 		return &Client{
-			disabled: true,
+			disabled:   true,
+			suppressed: make(map[string]struct{}),
 		}, nil
 	}
 
-	// Parse mail host
-	h := fmt.Sprintf("smtps://%v:%v@%v", user, password, host)
-	u, err := url.Parse(h)
-	if err != nil {
-		return nil, err
-	}
-
-	log.Infof("Mail host: smtps://%v:[password]@%v", user, host)
-
 	// Parse email address
 	a, err := mail.ParseAddress(emailAddress)
 	if err != nil {
@@ -76,33 +246,21 @@ func New(host, user, password, emailAddress, certPath string, skipVerify bool) (
 
 	log.Infof("Mail address: %v", a.String())
 
-	// Setup tls config
-	tlsConfig := &tls.Config{
-		InsecureSkipVerify: skipVerify,
-	}
-	if !skipVerify && certPath != "" {
-		cert, err := ioutil.ReadFile(certPath)
-		if err != nil {
-			return nil, err
-		}
-		certPool, err := x509.SystemCertPool()
-		if err != nil {
-			certPool = x509.NewCertPool()
+	ps := make([]*provider, 0, len(providers))
+	for i, v := range providers {
+		if i == 0 {
+			log.Infof("Mail provider: %v", v.Name())
+		} else {
+			log.Infof("Mail fallback provider: %v", v.Name())
 		}
-		certPool.AppendCertsFromPEM(cert)
-		tlsConfig.RootCAs = certPool
-	}
-
-	// Setup smtp context
-	smtp, err := goemail.NewSMTP(u.String(), tlsConfig)
-	if err != nil {
-		return nil, err
+		ps = append(ps, &provider{Provider: v})
 	}
 
 	return &Client{
-		smtp:        smtp,
+		providers:   ps,
 		mailName:    a.Name,
 		mailAddress: a.Address,
 		disabled:    false,
+		suppressed:  make(map[string]struct{}),
 	}, nil
 }
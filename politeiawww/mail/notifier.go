@@ -0,0 +1,69 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mail
+
+import (
+	"github.com/decred/politeia/politeiawww/events"
+)
+
+const (
+	// NotifierSMTP is the name of the notifier that sends notifications
+	// as emails.
+	NotifierSMTP = "smtp"
+)
+
+// Notifier is an events.Notifier that emails notifications to their
+// recipients. The emails are added to a durable queue that sends them.
+type Notifier struct {
+	queue *Queue
+}
+
+// datePref contains the date preferences of a recipient.
+type datePref struct {
+	timeZone string
+	locale   string
+}
+
+// Send satisfies the events.Notifier interface. The recipients are grouped by
+// their date preferences and the notification body is rendered once for each
+// group so that the recipients see the dates in their own time zone and
+// locale. Notifications without a body or recipients are ignored. An error
+// is only returned if the emails could not be queued, delivery failures are
+// retried by the queue.
+func (n *Notifier) Send(event string, recipients []events.Recipient, ntfn events.Notification) error {
+	if ntfn.Body == nil || len(recipients) == 0 {
+		return nil
+	}
+
+	groups := make(map[datePref][]string, len(recipients))
+	for _, v := range recipients {
+		dp := datePref{
+			timeZone: v.TimeZone,
+			locale:   v.Locale,
+		}
+		groups[dp] = append(groups[dp], v.Email)
+	}
+	for dp, emails := range groups {
+		date := FormatTime(ntfn.Timestamp, dp.timeZone, dp.locale)
+		body, err := ntfn.Body(date)
+		if err != nil {
+			return err
+		}
+		err = n.queue.Add(event, ntfn.Subject, body, ntfn.NtfnBit, emails)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NewNotifier returns a new Notifier that sends emails using the provided
+// queue.
+func NewNotifier(q *Queue) *Notifier {
+	return &Notifier{
+		queue: q,
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mail
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// ProviderSMTP sends emails using an SMTP server.
+	ProviderSMTP = "smtp"
+
+	// ProviderSendGrid sends emails using the SendGrid web API.
+	ProviderSendGrid = "sendgrid"
+
+	// ProviderSES sends emails using the Amazon SES web API.
+	ProviderSES = "ses"
+)
+
+// providerTimeout is the timeout of the requests that are sent to the web
+// API of a mail provider.
+const providerTimeout = 30 * time.Second
+
+// Providers contains the supported mail providers.
+var Providers = map[string]struct{}{
+	ProviderSMTP:     {},
+	ProviderSendGrid: {},
+	ProviderSES:      {},
+}
+
+// Message is an email that is sent by a mail provider.
+type Message struct {
+	FromName    string
+	FromAddress string
+	Subject     string
+	Body        string
+	To          []string
+	BCC         []string
+	Headers     map[string]string
+}
+
+// recipients returns all recipients of the message.
+func (m *Message) recipients() []string {
+	r := make([]string, 0, len(m.To)+len(m.BCC))
+	r = append(r, m.To...)
+	return append(r, m.BCC...)
+}
+
+// Provider sends emails using a mail service.
+type Provider interface {
+	// Name returns the name of the mail provider.
+	Name() string
+
+	// Send sends the email. A PartialSendError is returned when the
+	// email was sent to some, but not all, of its recipients.
+	Send(m *Message) error
+}
+
+// PartialSendError is returned by a Provider that splits an email into
+// multiple requests when some of the requests succeeded. The email was sent
+// to all recipients except the unsent recipients, so it must only be resent
+// to the unsent recipients.
+type PartialSendError struct {
+	To  []string // Unsent To recipients
+	BCC []string // Unsent BCC recipients
+	Err error
+}
+
+// Error satisfies the error interface.
+func (e *PartialSendError) Error() string {
+	return fmt.Sprintf("%v (%v recipients unsent)", e.Err,
+		len(e.To)+len(e.BCC))
+}
+
+// Unwrap returns the underlying error.
+func (e *PartialSendError) Unwrap() error {
+	return e.Err
+}
+
+// ProviderStats contains the send statistics of a mail provider.
+type ProviderStats struct {
+	Sent     uint64 // Emails sent
+	Failures uint64 // Emails that could not be sent
+}
+
+// provider wraps a Provider and counts its sent emails and failures. The
+// counters must be accessed atomically.
+type provider struct {
+	Provider
+	sent     uint64
+	failures uint64
+}
+
+// send sends the email using the provider and updates the counters.
+func (p *provider) send(m *Message) error {
+	err := p.Send(m)
+	if err != nil {
+		atomic.AddUint64(&p.failures, 1)
+		return err
+	}
+	atomic.AddUint64(&p.sent, 1)
+	return nil
+}
+
+// stats returns the send statistics of the provider.
+func (p *provider) stats() ProviderStats {
+	return ProviderStats{
+		Sent:     atomic.LoadUint64(&p.sent),
+		Failures: atomic.LoadUint64(&p.failures),
+	}
+}
+
+// responseError returns an error that contains the status code and the start
+// of the body of a web API response that was not successful.
+func responseError(r *http.Response) error {
+	body, _ := ioutil.ReadAll(io.LimitReader(r.Body, 1024))
+	return fmt.Errorf("%v: %v", r.Status, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mail
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testProvider is a Provider that records the recipients of the emails that
+// it sends. A provider with a partial count only sends the email to that many
+// recipients and returns a PartialSendError.
+type testProvider struct {
+	name    string
+	fail    bool
+	partial int
+	sent    []string
+}
+
+func (p *testProvider) Name() string {
+	return p.name
+}
+
+func (p *testProvider) Send(m *Message) error {
+	if p.fail {
+		return errors.New("unavailable")
+	}
+	if p.partial > 0 {
+		r := m.recipients()
+		p.sent = append(p.sent, r[:p.partial]...)
+		return &PartialSendError{
+			BCC: r[p.partial:],
+			Err: errors.New("unavailable"),
+		}
+	}
+	p.sent = append(p.sent, m.recipients()...)
+	return nil
+}
+
+func TestClientFailover(t *testing.T) {
+	primary := &testProvider{name: ProviderSMTP, fail: true}
+	fallback := &testProvider{name: ProviderSendGrid}
+	c, err := New("Politeia <noreply@example.org>", primary, fallback)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The email is sent using the fallback when the primary fails
+	err = c.SendTo("subject", "body", []string{"a@example.org"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fallback.sent) != 1 {
+		t.Fatalf("fallback sent %v emails, want 1", len(fallback.sent))
+	}
+
+	// The send fails when all providers fail
+	fallback.fail = true
+	err = c.SendTo("subject", "body", []string{"a@example.org"})
+	if err == nil {
+		t.Fatal("got nil error")
+	}
+
+	stats := c.ProviderStats()
+	if s := stats[ProviderSMTP]; s.Sent != 0 || s.Failures != 2 {
+		t.Fatalf("got smtp stats %+v, want 0 sent 2 failures", s)
+	}
+	if s := stats[ProviderSendGrid]; s.Sent != 1 || s.Failures != 1 {
+		t.Fatalf("got sendgrid stats %+v, want 1 sent 1 failure", s)
+	}
+	if c.SendFailures() != 1 {
+		t.Fatalf("got %v send failures, want 1", c.SendFailures())
+	}
+}
+
+func TestClientFailoverPartial(t *testing.T) {
+	primary := &testProvider{name: ProviderSendGrid, partial: 2}
+	fallback := &testProvider{name: ProviderSMTP}
+	c, err := New("Politeia <noreply@example.org>", primary, fallback)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The fallback only sends the email to the recipients that the
+	// primary was not able to send it to.
+	bcc := []string{"a@example.org", "b@example.org", "c@example.org"}
+	err = c.SendTo("subject", "body", bcc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(primary.sent, bcc[:2]) {
+		t.Fatalf("primary sent to %v, want %v", primary.sent, bcc[:2])
+	}
+	if !reflect.DeepEqual(fallback.sent, bcc[2:]) {
+		t.Fatalf("fallback sent to %v, want %v", fallback.sent, bcc[2:])
+	}
+}
+
+func TestSendGridPartial(t *testing.T) {
+	// Setup a SendGrid server that fails the second request
+	var (
+		requests int
+		sent     []string
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var sm sendGridMail
+		err := json.NewDecoder(r.Body).Decode(&sm)
+		if err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		for _, v := range sm.Personalizations {
+			for _, a := range v.To {
+				sent = append(sent, a.Email)
+			}
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer s.Close()
+	p := &sendGridProvider{
+		url:  s.URL,
+		http: s.Client(),
+	}
+
+	// Send an email that requires three requests
+	m := &Message{
+		To: []string{"to@example.org"},
+	}
+	for i := 0; i < 2*sendGridPersonalizationsMax; i++ {
+		m.BCC = append(m.BCC, fmt.Sprintf("%v@example.org", i))
+	}
+	err := p.Send(m)
+	var perr *PartialSendError
+	if !errors.As(err, &perr) {
+		t.Fatalf("got error %v, want a PartialSendError", err)
+	}
+
+	// The first request contains the To recipient and the first BCC
+	// recipients. All other recipients must be returned as unsent.
+	want := append([]string{"to@example.org"},
+		m.BCC[:sendGridPersonalizationsMax-1]...)
+	if !reflect.DeepEqual(sent, want) {
+		t.Fatalf("sent to %v recipients, want %v", len(sent), len(want))
+	}
+	if len(perr.To) != 0 ||
+		!reflect.DeepEqual(perr.BCC, m.BCC[sendGridPersonalizationsMax-1:]) {
+		t.Fatalf("got %v unsent to and %v unsent bcc", len(perr.To),
+			len(perr.BCC))
+	}
+}
+
+func TestSESSign(t *testing.T) {
+	// Example request from the AWS signature version 4 documentation
+	r, err := http.NewRequest(http.MethodGet,
+		"https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type",
+		"application/x-www-form-urlencoded; charset=utf-8")
+	sesSign(r, nil, "us-east-1", "iam", "AKIDEXAMPLE",
+		"wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 " +
+		"Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-date, " +
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if got := r.Header.Get("Authorization"); got != want {
+		t.Fatalf("got authorization %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mail
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/google/uuid"
+)
+
+const (
+	// queueCheckInterval is the interval at which the queue is checked
+	// for emails whose retry delay has elapsed.
+	queueCheckInterval = 30 * time.Second
+
+	// queueRetryDelay is the delay before the first retry of a failed
+	// email. The delay is doubled on every retry.
+	queueRetryDelay = time.Minute
+
+	// queueAttemptsMax is the number of delivery attempts after which
+	// an email is marked as failed. The last attempt is made roughly
+	// eight and a half hours after the email was queued.
+	queueAttemptsMax = 10
+)
+
+var (
+	// ErrNotFound is returned by Retry when the failed email does not
+	// exist.
+	ErrNotFound = errors.New("failed email not found")
+)
+
+// Queue is a durable queue of outbound notification emails. Emails are saved
+// to the user database before they are sent so that they are not lost when
+// the mail server is unavailable or politeiawww is restarted. A single worker
+// sends the queued emails and retries failed deliveries with an exponential
+// backoff. Emails that could not be delivered after the maximum number of
+// attempts are marked as failed and are kept in the database. Every send
+// attempt is recorded in the send log when one has been provided.
+type Queue struct {
+	client  *Client
+	db      user.Database
+	sendLog *SendLog // Optional
+	wake    chan struct{}
+	quit    chan struct{} // Closed by Stop
+	done    chan struct{} // Closed when Run returns
+}
+
+// Add adds a notification email of the provided event type to the queue and
+// wakes up the worker. The
+// email is queued separately for every recipient when unsubscribe links have
+// been setup, since the recipients are sent separate emails in that case.
+// This ensures that a failed delivery is only retried for the recipients that
+// it failed for.
+func (q *Queue) Add(event, subject, body string, ntfn uint64, recipients []string) error {
+	if !q.client.IsEnabled() || len(recipients) == 0 {
+		return nil
+	}
+
+	q.client.RLock()
+	perRecipient := q.client.unsubscribe != nil
+	q.client.RUnlock()
+
+	groups := [][]string{recipients}
+	if perRecipient {
+		groups = make([][]string, 0, len(recipients))
+		for _, v := range recipients {
+			groups = append(groups, []string{v})
+		}
+	}
+
+	now := time.Now().Unix()
+	for _, v := range groups {
+		e := user.QueuedEmail{
+			ID:          uuid.New().String(),
+			Event:       event,
+			Subject:     subject,
+			Body:        body,
+			NtfnBit:     ntfn,
+			Recipients:  v,
+			CreatedAt:   now,
+			NextAttempt: now,
+		}
+		err := q.db.QueuedEmailSave(e)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Wake up the worker. The worker drains the whole queue so a
+	// wake up only needs to be sent if one is not already pending.
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Retry requeues a queued email that failed permanently. The email is sent
+// again with a fresh set of attempts. ErrNotFound is returned if there is no
+// failed email with the provided ID.
+func (q *Queue) Retry(id string) error {
+	emails, err := q.db.QueuedEmailsGet(true)
+	if err != nil {
+		return err
+	}
+	var e *user.QueuedEmail
+	for i, v := range emails {
+		if v.ID == id {
+			e = &emails[i]
+			break
+		}
+	}
+	if e == nil {
+		return ErrNotFound
+	}
+
+	e.Attempts = 0
+	e.Failed = false
+	e.Error = ""
+	e.NextAttempt = time.Now().Unix()
+	err = q.db.QueuedEmailSave(*e)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Queued email %v requeued", e.ID)
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// send attempts to deliver a queued email. The email is deleted from the
+// queue once it has been delivered. A failed delivery is retried after the
+// retry delay or, once the maximum number of attempts has been reached, the
+// email is marked as failed.
+func (q *Queue) send(e user.QueuedEmail) error {
+	err := q.client.SendToNtfn(e.Subject, e.Body, e.NtfnBit, e.Recipients)
+	q.record(e, err)
+	if err == nil {
+		log.Debugf("Queued email sent %v", e.ID)
+		return q.db.QueuedEmailDeleteByID(e.ID)
+	}
+
+	e.Attempts++
+	e.Error = err.Error()
+	if e.Attempts >= queueAttemptsMax {
+		e.Failed = true
+		log.Errorf("Queued email %v failed permanently after %v attempts: %v",
+			e.ID, e.Attempts, err)
+	} else {
+		delay := queueRetryDelay << (e.Attempts - 1)
+		e.NextAttempt = time.Now().Add(delay).Unix()
+		log.Infof("Queued email %v failed, retrying in %v: %v",
+			e.ID, delay, err)
+	}
+
+	return q.db.QueuedEmailSave(e)
+}
+
+// record records the send attempt of a queued email in the send log. An entry
+// is recorded for every recipient. The recipients of an email that is sent
+// to multiple recipients share the status of the email, except for the
+// suppressed recipients that the email was not sent to.
+func (q *Queue) record(e user.QueuedEmail, err error) {
+	if q.sendLog == nil {
+		return
+	}
+
+	attempt := e.Attempts + 1
+	status := www.NotificationStatusSent
+	var errStr string
+	if err != nil {
+		status = www.NotificationStatusRetrying
+		if attempt >= queueAttemptsMax {
+			status = www.NotificationStatusFailed
+		}
+		errStr = err.Error()
+	}
+	now := time.Now().Unix()
+	entries := make([]www.NotificationLogEntry, 0, len(e.Recipients))
+	for _, v := range e.Recipients {
+		le := www.NotificationLogEntry{
+			MessageID: e.ID,
+			Event:     e.Event,
+			Channel:   www.NotificationChannelEmail,
+			Recipient: v,
+			Status:    status,
+			Attempt:   attempt,
+			Error:     errStr,
+			Timestamp: now,
+		}
+		if q.client.IsSuppressed(v) {
+			le.Status = www.NotificationStatusSuppressed
+			le.Error = ""
+		}
+		entries = append(entries, le)
+	}
+	err = q.sendLog.Record(entries)
+	if err != nil {
+		log.Errorf("Send log %v: %v", e.ID, err)
+	}
+}
+
+// drain sends the queued emails whose retry delay has elapsed. The remaining
+// emails are left in the queue when the context is canceled.
+func (q *Queue) drain(ctx context.Context) {
+	emails, err := q.db.QueuedEmailsGet(false)
+	if err != nil {
+		log.Errorf("QueuedEmailsGet: %v", err)
+		return
+	}
+	now := time.Now().Unix()
+	for _, v := range emails {
+		if ctx.Err() != nil {
+			return
+		}
+		if v.NextAttempt > now {
+			continue
+		}
+		err := q.send(v)
+		if err != nil {
+			log.Errorf("Queued email %v: %v", v.ID, err)
+		}
+	}
+}
+
+// Run sends the queued emails. Emails that were queued before a restart are
+// sent immediately. It runs until the queue is stopped.
+func (q *Queue) Run() {
+	defer close(q.done)
+
+	ticker := time.NewTicker(queueCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		q.drain(context.Background())
+
+		select {
+		case <-ticker.C:
+		case <-q.wake:
+		case <-q.quit:
+			return
+		}
+	}
+}
+
+// Stop stops the queue worker once the emails that have been queued have
+// been sent. The emails are sent until the context is canceled. The emails
+// that have not been sent remain in the user database and are sent once the
+// queue is run again. Stop must only be called once and only after Run has
+// been started.
+func (q *Queue) Stop(ctx context.Context) error {
+	close(q.quit)
+
+	// Wait for the worker to finish the pending send, then send the
+	// emails that were queued in the meantime.
+	select {
+	case <-q.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	q.drain(ctx)
+
+	return ctx.Err()
+}
+
+// NewQueue returns a new Queue that sends emails using the provided client and
+// that persists the emails to the provided user database. The send log is
+// optional and may be nil.
+func NewQueue(c *Client, db user.Database, sl *SendLog) *Queue {
+	return &Queue{
+		client:  c,
+		db:      db,
+		sendLog: sl,
+		wake:    make(chan struct{}, 1),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
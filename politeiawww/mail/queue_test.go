@@ -0,0 +1,117 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mail
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/decred/politeia/politeiawww/user"
+)
+
+// testQueueDB is a user database that only implements the queued email
+// methods.
+type testQueueDB struct {
+	user.Database
+	emails map[string]user.QueuedEmail
+}
+
+func (db *testQueueDB) QueuedEmailSave(e user.QueuedEmail) error {
+	db.emails[e.ID] = e
+	return nil
+}
+
+func (db *testQueueDB) QueuedEmailsGet(failed bool) ([]user.QueuedEmail, error) {
+	emails := make([]user.QueuedEmail, 0, len(db.emails))
+	for _, v := range db.emails {
+		if v.Failed == failed {
+			emails = append(emails, v)
+		}
+	}
+	sort.SliceStable(emails, func(i, j int) bool {
+		return emails[i].CreatedAt < emails[j].CreatedAt
+	})
+	return emails, nil
+}
+
+func (db *testQueueDB) QueuedEmailDeleteByID(id string) error {
+	delete(db.emails, id)
+	return nil
+}
+
+func TestQueueRetry(t *testing.T) {
+	p := &testProvider{name: ProviderSMTP, fail: true}
+	c, err := New("Politeia <noreply@example.org>", p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := &testQueueDB{
+		emails: make(map[string]user.QueuedEmail),
+	}
+	q := NewQueue(c, db, nil)
+
+	err = q.Add("event", "subject", "body", 0, []string{"a@example.org"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-q.wake
+
+	// Fail the email permanently
+	for i := 0; i < queueAttemptsMax; i++ {
+		emails, err := db.QueuedEmailsGet(false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(emails) != 1 {
+			t.Fatalf("got %v pending emails, want 1", len(emails))
+		}
+		err = q.send(emails[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	failed, err := db.QueuedEmailsGet(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 1 {
+		t.Fatalf("got %v failed emails, want 1", len(failed))
+	}
+	id := failed[0].ID
+
+	// An email that did not fail can't be retried
+	err = q.Retry("unknown")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("got err %v, want %v", err, ErrNotFound)
+	}
+
+	// A failed email is requeued with a fresh set of attempts and the
+	// worker is woken up
+	err = q.Retry(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := db.emails[id]
+	if e.Failed || e.Attempts != 0 || e.Error != "" {
+		t.Fatalf("got email %+v, want a pending email", e)
+	}
+	select {
+	case <-q.wake:
+	default:
+		t.Fatalf("worker was not woken up")
+	}
+
+	// The requeued email is sent and removed from the queue
+	p.fail = false
+	q.drain(context.Background())
+	if len(db.emails) != 0 {
+		t.Fatalf("got %v queued emails, want 0", len(db.emails))
+	}
+	if len(p.sent) != 1 {
+		t.Fatalf("got %v sent emails, want 1", len(p.sent))
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mail
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	// sendGridURL is the URL of the SendGrid mail send API.
+	sendGridURL = "https://api.sendgrid.com/v3/mail/send"
+
+	// sendGridPersonalizationsMax is the maximum number of
+	// personalizations that are allowed in a single SendGrid request.
+	sendGridPersonalizationsMax = 1000
+)
+
+var (
+	_ Provider = (*sendGridProvider)(nil)
+)
+
+// sendGridAddress is an email address in a SendGrid request.
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+// sendGridPersonalization contains the recipients of a single email in a
+// SendGrid request.
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+// sendGridContent is the content of an email in a SendGrid request.
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// sendGridMail is the body of a SendGrid mail send request.
+type sendGridMail struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Headers          map[string]string         `json:"headers,omitempty"`
+}
+
+// sendGridProvider sends emails using the SendGrid web API.
+type sendGridProvider struct {
+	url    string
+	apiKey string
+	http   *http.Client
+}
+
+// Name returns the name of the mail provider.
+//
+// This function satisfies the Provider interface.
+func (p *sendGridProvider) Name() string {
+	return ProviderSendGrid
+}
+
+// Send sends the email using the SendGrid web API. The BCC recipients are
+// each sent a separate email so that the recipients are not disclosed to one
+// another. Emails with more recipients than SendGrid allows in a single
+// request are split into multiple requests. A PartialSendError is returned
+// when a request fails after a previous request succeeded.
+//
+// This function satisfies the Provider interface.
+func (p *sendGridProvider) Send(m *Message) error {
+	ps := make([]sendGridPersonalization, 0, len(m.BCC)+1)
+	if len(m.To) > 0 {
+		to := make([]sendGridAddress, 0, len(m.To))
+		for _, v := range m.To {
+			to = append(to, sendGridAddress{Email: v})
+		}
+		ps = append(ps, sendGridPersonalization{To: to})
+	}
+	for _, v := range m.BCC {
+		ps = append(ps, sendGridPersonalization{
+			To: []sendGridAddress{{Email: v}},
+		})
+	}
+
+	// The To recipients are always part of the first request
+	var toCount int
+	if len(m.To) > 0 {
+		toCount = 1
+	}
+
+	var sent int // Number of personalizations sent
+	for sent < len(ps) {
+		n := sendGridPersonalizationsMax
+		if n > len(ps)-sent {
+			n = len(ps) - sent
+		}
+		err := p.send(sendGridMail{
+			Personalizations: ps[sent : sent+n],
+			From: sendGridAddress{
+				Email: m.FromAddress,
+				Name:  m.FromName,
+			},
+			Subject: m.Subject,
+			Content: []sendGridContent{{
+				Type:  "text/plain",
+				Value: m.Body,
+			}},
+			Headers: m.Headers,
+		})
+		if err != nil {
+			if sent == 0 {
+				return err
+			}
+			return &PartialSendError{
+				BCC: m.BCC[sent-toCount:],
+				Err: err,
+			}
+		}
+		sent += n
+	}
+
+	return nil
+}
+
+// send sends a mail send request to the SendGrid web API.
+func (p *sendGridProvider) send(sm sendGridMail) error {
+	b, err := json.Marshal(sm)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	r, err := p.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode/100 != 2 {
+		return responseError(r)
+	}
+
+	return nil
+}
+
+// NewSendGrid returns a new Provider that sends emails using the SendGrid web
+// API.
+func NewSendGrid(apiKey string) Provider {
+	return &sendGridProvider{
+		url:    sendGridURL,
+		apiKey: apiKey,
+		http: &http.Client{
+			Timeout: providerTimeout,
+		},
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mail
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+)
+
+const (
+	// sendLogEntriesMax is the maximum number of entries that are kept
+	// in the send log, regardless of the retention period.
+	sendLogEntriesMax = 100000
+
+	// sendLogCompactMin is the minimum number of expired entries that
+	// must be in the send log file before the file is compacted.
+	sendLogCompactMin = 1000
+)
+
+// SendLog records the send attempts of the notification emails and webhook
+// deliveries so that it can be determined whether a notification was
+// delivered. Entries are
+// appended to a file, one JSON object per line, and are kept in memory for
+// querying. Entries that are older than the retention period are dropped and
+// the file is periodically compacted to remove them.
+type SendLog struct {
+	sync.RWMutex
+	path      string
+	file      *os.File
+	retention time.Duration
+	entries   []www.NotificationLogEntry // Oldest first
+	expired   int                        // Expired entries still in the file
+}
+
+// Record appends the provided entries to the send log.
+func (l *SendLog) Record(entries []www.NotificationLogEntry) error {
+	l.Lock()
+	defer l.Unlock()
+
+	var b []byte
+	for _, v := range entries {
+		e, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		b = append(b, e...)
+		b = append(b, '\n')
+	}
+	_, err := l.file.Write(b)
+	if err != nil {
+		return err
+	}
+	l.entries = append(l.entries, entries...)
+
+	return l.pruneLocked(time.Now())
+}
+
+// QueryFilter contains the filters of a send log query. Empty filters match
+// all entries. The recipient is matched case insensitively. Entries without a
+// channel are email entries.
+type QueryFilter struct {
+	Recipient string
+	MessageID string
+	Event     string
+	Channel   string
+	Failures  bool // Only match failed send attempts
+}
+
+// match returns whether the entry matches the filter.
+func (f QueryFilter) match(e www.NotificationLogEntry) bool {
+	channel := e.Channel
+	if channel == "" {
+		channel = www.NotificationChannelEmail
+	}
+	switch {
+	case f.Recipient != "" && !strings.EqualFold(e.Recipient, f.Recipient):
+		return false
+	case f.MessageID != "" && e.MessageID != f.MessageID:
+		return false
+	case f.Event != "" && e.Event != f.Event:
+		return false
+	case f.Channel != "" && channel != f.Channel:
+		return false
+	case f.Failures && e.Status != www.NotificationStatusRetrying &&
+		e.Status != www.NotificationStatusFailed:
+		return false
+	}
+	return true
+}
+
+// Query returns the entries that match the provided filter, newest first. At
+// most limit entries are returned.
+func (l *SendLog) Query(f QueryFilter, limit int) []www.NotificationLogEntry {
+	l.RLock()
+	defer l.RUnlock()
+
+	entries := make([]www.NotificationLogEntry, 0, limit)
+	for i := len(l.entries) - 1; i >= 0 && len(entries) < limit; i-- {
+		e := l.entries[i]
+		if !f.match(e) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	return entries
+}
+
+// pruneLocked drops the entries that are past the retention period or that
+// exceed the maximum number of entries. The file is compacted once enough
+// expired entries have accumulated.
+//
+// This function must be called WITH the lock held.
+func (l *SendLog) pruneLocked(now time.Time) error {
+	cutoff := now.Add(-l.retention).Unix()
+	var i int
+	for i < len(l.entries) && l.entries[i].Timestamp < cutoff {
+		i++
+	}
+	if n := len(l.entries) - sendLogEntriesMax; n > i {
+		i = n
+	}
+	if i > 0 {
+		l.entries = append(l.entries[:0:0], l.entries[i:]...)
+		l.expired += i
+	}
+	if l.expired < sendLogCompactMin {
+		return nil
+	}
+
+	return l.compactLocked()
+}
+
+// compactLocked rewrites the send log file with only the entries that are
+// still being retained.
+//
+// This function must be called WITH the lock held.
+func (l *SendLog) compactLocked() error {
+	log.Debugf("Compacting send log: %v expired entries", l.expired)
+
+	tmp := l.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, v := range l.entries {
+		b, err := json.Marshal(v)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		w.Write(append(b, '\n'))
+	}
+	err = w.Flush()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmp, l.path)
+	if err != nil {
+		return err
+	}
+
+	// Reopen the file since the previous file handle points to the
+	// file that was replaced.
+	l.file.Close()
+	l.file, err = os.OpenFile(l.path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	l.expired = 0
+
+	return nil
+}
+
+// load loads the send log entries from the file. A partially written last
+// entry, which is left behind when politeiawww is stopped in the middle of a
+// write, is ignored.
+func (l *SendLog) load() error {
+	f, err := os.Open(l.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for {
+		b, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			if len(b) > 0 {
+				// Force a compaction so that the partial entry is
+				// removed before new entries are appended.
+				l.expired = sendLogCompactMin
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var e www.NotificationLogEntry
+		err = json.Unmarshal(b, &e)
+		if err != nil {
+			return fmt.Errorf("decode entry: %v", err)
+		}
+		l.entries = append(l.entries, e)
+	}
+}
+
+// NewSendLog returns a new SendLog that is saved to the provided path and
+// that keeps the entries for the provided retention period.
+func NewSendLog(path string, retention time.Duration) (*SendLog, error) {
+	l := SendLog{
+		path:      path,
+		retention: retention,
+		entries:   make([]www.NotificationLogEntry, 0, 1024),
+	}
+	err := l.load()
+	if err != nil {
+		return nil, fmt.Errorf("load %v: %v", path, err)
+	}
+	l.file, err = os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY,
+		0600)
+	if err != nil {
+		return nil, err
+	}
+	err = l.pruneLocked(time.Now())
+	if err != nil {
+		l.file.Close()
+		return nil, err
+	}
+
+	log.Infof("Mail send log: %v entries loaded", len(l.entries))
+
+	return &l, nil
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mail
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+)
+
+func TestSendLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sendlog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "maillog.json")
+	retention := 24 * time.Hour
+	l, err := NewSendLog(path, retention)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Record an expired entry and two recent entries
+	now := time.Now().Unix()
+	err = l.Record([]www.NotificationLogEntry{
+		{
+			MessageID: "1",
+			Recipient: "a@example.com",
+			Status:    www.NotificationStatusSent,
+			Timestamp: now - 2*24*60*60,
+		},
+		{
+			MessageID: "2",
+			Recipient: "a@example.com",
+			Status:    www.NotificationStatusRetrying,
+			Timestamp: now,
+		},
+		{
+			MessageID: "2",
+			Recipient: "b@example.com",
+			Status:    www.NotificationStatusSuppressed,
+			Timestamp: now,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name   string
+		filter QueryFilter
+		want   int
+	}{
+		{"all", QueryFilter{}, 2},
+		{"recipient", QueryFilter{Recipient: "A@example.com"}, 1},
+		{"message id", QueryFilter{MessageID: "2"}, 2},
+		{"expired", QueryFilter{MessageID: "1"}, 0},
+		{"failures", QueryFilter{Failures: true}, 1},
+		{"email", QueryFilter{Channel: www.NotificationChannelEmail}, 2},
+		{"webhook", QueryFilter{Channel: www.NotificationChannelWebhook}, 0},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			got := l.Query(v.filter, www.NotificationLogPageSize)
+			if len(got) != v.want {
+				t.Fatalf("got %v entries, want %v", len(got), v.want)
+			}
+		})
+	}
+
+	// Verify the entries are loaded when the send log is reopened
+	l.file.Close()
+	l, err = NewSendLog(path, retention)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := l.Query(QueryFilter{}, www.NotificationLogPageSize)
+	if len(got) != 2 {
+		t.Fatalf("got %v entries after reopen, want 2", len(got))
+	}
+	if got[0].Recipient != "b@example.com" {
+		t.Fatalf("got newest recipient %v, want b@example.com",
+			got[0].Recipient)
+	}
+}
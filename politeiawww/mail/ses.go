@@ -0,0 +1,271 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mail
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/http"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// sesRecipientsMax is the maximum number of recipients that are
+	// allowed in a single SES email.
+	sesRecipientsMax = 50
+
+	// sesService is the service name that is used to sign SES requests.
+	sesService = "ses"
+
+	// sesTimeFormat is the format of the SES request signature time.
+	sesTimeFormat = "20060102T150405Z"
+)
+
+var (
+	_ Provider = (*sesProvider)(nil)
+)
+
+// sesDestination contains the recipients of an SES email.
+type sesDestination struct {
+	ToAddresses  []string `json:"ToAddresses,omitempty"`
+	BccAddresses []string `json:"BccAddresses,omitempty"`
+}
+
+// sesRaw contains a raw MIME email.
+type sesRaw struct {
+	Data []byte `json:"Data"` // Base64 encoded by json.Marshal
+}
+
+// sesContent is the content of an SES email.
+type sesContent struct {
+	Raw sesRaw `json:"Raw"`
+}
+
+// sesEmail is the body of an SES v2 SendEmail request.
+type sesEmail struct {
+	FromEmailAddress string         `json:"FromEmailAddress"`
+	Destination      sesDestination `json:"Destination"`
+	Content          sesContent     `json:"Content"`
+}
+
+// sesProvider sends emails using the Amazon SES v2 web API.
+type sesProvider struct {
+	url       string
+	region    string
+	accessKey string
+	secretKey string
+	http      *http.Client
+}
+
+// Name returns the name of the mail provider.
+//
+// This function satisfies the Provider interface.
+func (p *sesProvider) Name() string {
+	return ProviderSES
+}
+
+// Send sends the email using the SES web API. Emails that have more
+// recipients than SES allows are split into multiple emails. The To recipients
+// are only included in the first email. A PartialSendError is returned when an
+// email fails to send after a previous email succeeded.
+//
+// This function satisfies the Provider interface.
+func (p *sesProvider) Send(m *Message) error {
+	raw, err := sesMessage(m, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var (
+		to    = m.To
+		bcc   = m.BCC
+		first = true
+	)
+	for first || len(bcc) > 0 {
+		n := sesRecipientsMax - len(to)
+		if n < 1 {
+			n = 1
+		}
+		if n > len(bcc) {
+			n = len(bcc)
+		}
+		err := p.send(sesEmail{
+			FromEmailAddress: m.FromAddress,
+			Destination: sesDestination{
+				ToAddresses:  to,
+				BccAddresses: bcc[:n],
+			},
+			Content: sesContent{
+				Raw: sesRaw{
+					Data: raw,
+				},
+			},
+		})
+		if err != nil {
+			if first {
+				return err
+			}
+			return &PartialSendError{
+				BCC: bcc,
+				Err: err,
+			}
+		}
+		to = nil
+		bcc = bcc[n:]
+		first = false
+	}
+
+	return nil
+}
+
+// send sends a SendEmail request to the SES web API.
+func (p *sesProvider) send(e sesEmail) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	sesSign(req, b, p.region, sesService, p.accessKey, p.secretKey,
+		time.Now())
+
+	r, err := p.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode/100 != 2 {
+		return responseError(r)
+	}
+
+	return nil
+}
+
+// sesMessage returns the raw MIME encoding of the email. The BCC recipients
+// are not included in the headers.
+func sesMessage(m *Message, date time.Time) ([]byte, error) {
+	var b bytes.Buffer
+	from := mail.Address{
+		Name:    m.FromName,
+		Address: m.FromAddress,
+	}
+	fmt.Fprintf(&b, "From: %v\r\n", from.String())
+	if len(m.To) > 0 {
+		fmt.Fprintf(&b, "To: %v\r\n", strings.Join(m.To, ", "))
+	}
+	fmt.Fprintf(&b, "Subject: %v\r\n",
+		mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&b, "Date: %v\r\n", date.Format(time.RFC1123Z))
+	keys := make([]string, 0, len(m.Headers))
+	for k := range m.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%v: %v\r\n", k, m.Headers[k])
+	}
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	w := quotedprintable.NewWriter(&b)
+	_, err := w.Write([]byte(m.Body))
+	if err != nil {
+		return nil, err
+	}
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// sesSign signs the request using the AWS signature version 4 signing process.
+// The query of the request URL must already be in canonical form.
+func sesSign(r *http.Request, payload []byte, region, service, accessKey, secretKey string, t time.Time) {
+	amzDate := t.UTC().Format(sesTimeFormat)
+	day := amzDate[:8]
+	r.Header.Set("X-Amz-Date", amzDate)
+
+	// Create the canonical request
+	payloadHash := sha256.Sum256(payload)
+	signedHeaders := "content-type;host;x-amz-date"
+	canonicalHeaders := "content-type:" + r.Header.Get("Content-Type") + "\n" +
+		"host:" + r.URL.Host + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	path := r.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		path,
+		r.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	// Create the string to sign
+	scope := day + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	// Derive the signing key and sign
+	key := []byte("AWS4" + secretKey)
+	for _, v := range []string{day, region, service, "aws4_request"} {
+		key = hmacSHA256(key, v)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	r.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 "+
+		"Credential=%v/%v, SignedHeaders=%v, Signature=%v",
+		accessKey, scope, signedHeaders, signature))
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of the data using the provided key.
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// NewSES returns a new Provider that sends emails using the Amazon SES web API
+// of the provided region.
+func NewSES(region, accessKey, secretKey string) Provider {
+	u := fmt.Sprintf("https://email.%v.amazonaws.com/v2/email/outbound-emails",
+		region)
+
+	log.Infof("SES endpoint: %v", u)
+
+	return &sesProvider{
+		url:       u,
+		region:    region,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		http: &http.Client{
+			Timeout: providerTimeout,
+		},
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mail
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+
+	"github.com/dajohi/goemail"
+)
+
+var (
+	_ Provider = (*smtpProvider)(nil)
+)
+
+// smtpProvider sends emails using an SMTP server.
+type smtpProvider struct {
+	smtp *goemail.SMTP
+}
+
+// Name returns the name of the mail provider.
+//
+// This function satisfies the Provider interface.
+func (p *smtpProvider) Name() string {
+	return ProviderSMTP
+}
+
+// Send sends the email using the SMTP server.
+//
+// This function satisfies the Provider interface.
+func (p *smtpProvider) Send(m *Message) error {
+	msg := goemail.NewMessage(m.FromAddress, m.Subject, m.Body)
+	msg.SetName(m.FromName)
+	for _, v := range m.To {
+		msg.AddTo(v)
+	}
+	for _, v := range m.BCC {
+		msg.AddBCC(v)
+	}
+	for k, v := range m.Headers {
+		msg.AddHeader(k, v)
+	}
+	return p.smtp.Send(msg)
+}
+
+// NewSMTP returns a new Provider that sends emails using the provided SMTP
+// server.
+func NewSMTP(host, user, password, certPath string, skipVerify bool) (Provider, error) {
+	// Parse mail host
+	h := fmt.Sprintf("smtps://%v:%v@%v", user, password, host)
+	u, err := url.Parse(h)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Mail host: smtps://%v:[password]@%v", user, host)
+
+	// Setup tls config
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: skipVerify,
+	}
+	if !skipVerify && certPath != "" {
+		cert, err := ioutil.ReadFile(certPath)
+		if err != nil {
+			return nil, err
+		}
+		certPool, err := x509.SystemCertPool()
+		if err != nil {
+			certPool = x509.NewCertPool()
+		}
+		certPool.AppendCertsFromPEM(cert)
+		tlsConfig.RootCAs = certPool
+	}
+
+	// Setup smtp context
+	smtp, err := goemail.NewSMTP(u.String(), tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &smtpProvider{
+		smtp: smtp,
+	}, nil
+}
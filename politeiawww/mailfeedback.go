@@ -0,0 +1,160 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// mailSoftBounceMax is the number of temporary bounces after which
+	// an email address is suppressed.
+	mailSoftBounceMax = 3
+)
+
+// initMailSuppression adds the email addresses of all suppressed users to the
+// mail client suppression list.
+func (p *politeiawww) initMailSuppression() error {
+	var count int
+	err := p.db.AllUsers(func(u *user.User) {
+		if u.EmailSuppressed {
+			p.mail.Suppress(u.Email)
+			count++
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Suppressed email addresses: %v", count)
+
+	return nil
+}
+
+// handleMailFeedback handles the incoming bounce and complaint notifications
+// from the mail provider.
+func (p *politeiawww) handleMailFeedback(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleMailFeedback")
+
+	// Verify the shared secret
+	token := r.Header.Get(www.MailFeedbackToken)
+	if subtle.ConstantTimeCompare([]byte(token),
+		[]byte(p.cfg.MailFeedbackToken)) != 1 {
+		RespondWithError(w, r, http.StatusForbidden,
+			"handleMailFeedback: invalid token", www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	var mf www.MailFeedback
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&mf); err != nil {
+		RespondWithError(w, r, 0, "handleMailFeedback: unmarshal",
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	reply, err := p.processMailFeedback(mf)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleMailFeedback: processMailFeedback %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
+// processMailFeedback processes a bounce or complaint notification. The
+// email address is suppressed on complaints and permanent bounces. Temporary
+// bounces are counted and the address is suppressed once mailSoftBounceMax
+// has been reached.
+func (p *politeiawww) processMailFeedback(mf www.MailFeedback) (*www.MailFeedbackReply, error) {
+	log.Tracef("processMailFeedback: %v %v", mf.Type, mf.Email)
+
+	email := strings.ToLower(strings.TrimSpace(mf.Email))
+	switch {
+	case email == "":
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"email not found"},
+		}
+	case mf.Type != www.MailFeedbackTypeBounce &&
+		mf.Type != www.MailFeedbackTypeComplaint:
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"invalid feedback type"},
+		}
+	}
+
+	// Emails may be sent to addresses that are not tied to a user,
+	// e.g. the email of a new user that has not been verified yet.
+	// These are only added to the in-memory suppression list.
+	u, err := p.userByEmail(email)
+	if err != nil {
+		if !errors.Is(err, user.ErrUserNotFound) {
+			return nil, err
+		}
+		if mf.Type == www.MailFeedbackTypeComplaint || mf.Permanent {
+			p.mail.Suppress(email)
+			log.Infof("Email suppressed: %v %v", email, mf.Type)
+			return &www.MailFeedbackReply{
+				Suppressed: true,
+			}, nil
+		}
+		return &www.MailFeedbackReply{}, nil
+	}
+	if u.EmailSuppressed {
+		// Already suppressed; nothing to do
+		return &www.MailFeedbackReply{
+			Suppressed: true,
+		}, nil
+	}
+
+	// Update the user suppression state
+	switch {
+	case mf.Type == www.MailFeedbackTypeComplaint:
+		u.EmailSuppressed = true
+		u.EmailSuppressedReason = "complaint"
+	case mf.Permanent:
+		u.EmailSuppressed = true
+		u.EmailSuppressedReason = "bounce"
+	default:
+		u.EmailSoftBounces++
+		if u.EmailSoftBounces >= mailSoftBounceMax {
+			u.EmailSuppressed = true
+			u.EmailSuppressedReason = fmt.Sprintf("%v temporary bounces",
+				u.EmailSoftBounces)
+		}
+	}
+	if u.EmailSuppressed && mf.Reason != "" {
+		u.EmailSuppressedReason += ": " + mf.Reason
+	}
+	err = p.db.UserUpdate(*u)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.EmailSuppressed {
+		p.mail.Suppress(u.Email)
+		log.Infof("Email suppressed for user %v: %v", u.ID,
+			u.EmailSuppressedReason)
+	}
+
+	return &www.MailFeedbackReply{
+		Suppressed: u.EmailSuppressed,
+	}, nil
+}
@@ -0,0 +1,310 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	cms "github.com/decred/politeia/politeiawww/api/cms/v1"
+	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	elv1 "github.com/decred/politeia/politeiawww/api/eventlog/v1"
+	oav1 "github.com/decred/politeia/politeiawww/api/oauth/v1"
+	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	tmv1 "github.com/decred/politeia/politeiawww/api/telemetry/v1"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/metrics"
+	"github.com/decred/politeia/util"
+	"github.com/gorilla/mux"
+)
+
+// maintenanceRoute is a route and the request method that is used for it.
+type maintenanceRoute struct {
+	method string
+	route  string
+}
+
+// maintenanceReadRoutes contains the routes that only read data. These routes
+// keep working while the server is in maintenance mode. All other routes are
+// disabled so that a newly added write route can not be missed. The login,
+// logout and set maintenance routes are included so that admins are able to
+// disable maintenance mode.
+//
+// The routes are listed together with the request method since the same
+// route can be a read for one method and a write for another, e.g. the GET
+// unsubscribe route only shows a confirmation page.
+var maintenanceReadRoutes = []maintenanceRoute{
+	// Version, frontend, health and metrics routes
+	{http.MethodGet, "/"},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteVersion},
+	{http.MethodGet, www.RouteHealth},
+	{http.MethodGet, www.RouteReady},
+	{http.MethodGet, metrics.Route},
+	{http.MethodGet, legacyProposalRoute},
+	{http.MethodGet, legacyCommentRoute},
+
+	// www routes
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RoutePolicies},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RoutePolicy},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteTokenInventory},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteAllVetted},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteProposalDetails},
+	{http.MethodPost, www.PoliteiaWWWAPIRoute + www.RouteBatchProposals},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteVoteStatus},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteAllVoteStatus},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteActiveVote},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteVoteResults},
+	{http.MethodPost, www.PoliteiaWWWAPIRoute + www.RouteBatchVoteSummary},
+	{http.MethodPost, www.PoliteiaWWWAPIRoute + www.RouteLogin},
+	{http.MethodPost, www.PoliteiaWWWAPIRoute + www.RouteLogout},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteUserDetails},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteUsers},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteCSRFToken},
+	{http.MethodPost, www.PoliteiaWWWAPIRoute + www.RouteSecret},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteUserMe},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteUserProposalPaywall},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteUserProposalPaywallTx},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteUserProposalCredits},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteNotificationLog},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteUnsubscribe},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteVerifications},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteACL},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteMaintenance},
+	{http.MethodPost, www.PoliteiaWWWAPIRoute + www.RouteSetMaintenance},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteSiteInfo},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteOpenAPI},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteUnauthenticatedWebSocket},
+	{http.MethodGet, www.PoliteiaWWWAPIRoute + www.RouteAuthenticatedWebSocket},
+
+	// Records routes
+	{http.MethodPost, rcv1.APIRoute + rcv1.RouteDetails},
+	{http.MethodPost, rcv1.APIRoute + rcv1.RouteBatchDetails},
+	{http.MethodPost, rcv1.APIRoute + rcv1.RouteTimestamps},
+	{http.MethodPost, rcv1.APIRoute + rcv1.RouteRecords},
+	{http.MethodPost, rcv1.APIRoute + rcv1.RouteInventory},
+	{http.MethodPost, rcv1.APIRoute + rcv1.RouteInventoryOrdered},
+	{http.MethodPost, rcv1.APIRoute + rcv1.RouteUserRecords},
+	{http.MethodPost, rcv1.APIRoute + rcv1.RouteLegacyTokens},
+	{http.MethodGet, rcv1.APIRoute + rcv1.RouteDetails},
+	{http.MethodGet, rcv1.APIRoute + rcv1.RouteTimestamps},
+
+	// Comments routes
+	{http.MethodPost, cmv1.APIRoute + cmv1.RoutePolicy},
+	{http.MethodPost, cmv1.APIRoute + cmv1.RouteCount},
+	{http.MethodPost, cmv1.APIRoute + cmv1.RouteComments},
+	{http.MethodPost, cmv1.APIRoute + cmv1.RouteVotes},
+	{http.MethodPost, cmv1.APIRoute + cmv1.RouteTimestamps},
+	{http.MethodPost, cmv1.APIRoute + cmv1.RouteExport},
+	{http.MethodGet, cmv1.APIRoute + cmv1.RouteTimestamps},
+
+	// Ticketvote routes
+	{http.MethodPost, tkv1.APIRoute + tkv1.RoutePolicy},
+	{http.MethodPost, tkv1.APIRoute + tkv1.RouteDetails},
+	{http.MethodPost, tkv1.APIRoute + tkv1.RouteResults},
+	{http.MethodPost, tkv1.APIRoute + tkv1.RouteSummaries},
+	{http.MethodPost, tkv1.APIRoute + tkv1.RouteSubmissions},
+	{http.MethodPost, tkv1.APIRoute + tkv1.RouteInventory},
+	{http.MethodPost, tkv1.APIRoute + tkv1.RouteTimestamps},
+	{http.MethodPost, tkv1.APIRoute + tkv1.RouteCertificate},
+	{http.MethodPost, tkv1.APIRoute + tkv1.RouteTallies},
+	{http.MethodGet, tkv1.APIRoute + tkv1.RouteResults},
+	{http.MethodGet, tkv1.APIRoute + tkv1.RouteTimestamps},
+
+	// Pi routes
+	{http.MethodPost, piv1.APIRoute + piv1.RoutePolicy},
+	{http.MethodPost, piv1.APIRoute + piv1.RoutePreflight},
+	{http.MethodPost, piv1.APIRoute + piv1.RouteSimilar},
+	{http.MethodPost, piv1.APIRoute + piv1.RouteSearch},
+	{http.MethodPost, piv1.APIRoute + piv1.RouteAuthorUpdates},
+	{http.MethodPost, piv1.APIRoute + piv1.RouteWalletSummaries},
+	{http.MethodPost, piv1.APIRoute + piv1.RouteVettingQueue},
+	{http.MethodPost, piv1.APIRoute + piv1.RouteReports},
+	{http.MethodPost, piv1.APIRoute + piv1.RouteRFP},
+	{http.MethodPost, piv1.APIRoute + piv1.RouteRFPParent},
+	{http.MethodPost, piv1.APIRoute + piv1.RouteLineage},
+	{http.MethodGet, piv1.APIRoute + piv1.RouteFeedProposals},
+	{http.MethodGet, piv1.APIRoute + piv1.RouteFeedVotes},
+	{http.MethodGet, piv1.APIRoute + piv1.RouteFeedComments},
+
+	// Telemetry routes
+	{http.MethodPost, tmv1.APIRoute + tmv1.RoutePolicy},
+	{http.MethodPost, tmv1.APIRoute + tmv1.RouteStats},
+
+	// OAuth routes
+	{http.MethodPost, oav1.APIRoute + oav1.RouteClient},
+	{http.MethodGet, oav1.APIRoute + oav1.RouteMe},
+	{http.MethodGet, oav1.APIRoute + oav1.RouteProposals},
+	{http.MethodPost, oav1.APIRoute + oav1.RouteGrants},
+	{http.MethodPost, oav1.APIRoute + oav1.RouteIntrospect},
+	{http.MethodPost, oav1.APIRoute + oav1.RouteClients},
+	{http.MethodGet, oav1.RouteDiscovery},
+	{http.MethodGet, oav1.APIRoute + oav1.RouteJWKS},
+	{http.MethodGet, oav1.APIRoute + oav1.RouteUserInfo},
+
+	// Event log routes
+	{http.MethodPost, elv1.APIRoute + elv1.RouteEvents},
+
+	// CMS routes
+	{http.MethodGet, cms.APIRoute + www.RoutePolicy},
+	{http.MethodGet, cms.APIRoute + cms.RouteInvoiceDetails},
+	{http.MethodGet, cms.APIRoute + cms.RouteUserInvoices},
+	{http.MethodPost, cms.APIRoute + cms.RouteInvoices},
+	{http.MethodGet, cms.APIRoute + cms.RouteInvoiceComments},
+	{http.MethodGet, cms.APIRoute + cms.RouteDCCDetails},
+	{http.MethodPost, cms.APIRoute + cms.RouteGetDCCs},
+	{http.MethodGet, cms.APIRoute + cms.RouteDCCComments},
+	{http.MethodGet, cms.APIRoute + cms.RouteUserSubContractors},
+	{http.MethodGet, cms.APIRoute + cms.RouteProposalOwner},
+	{http.MethodPost, cms.APIRoute + cms.RouteProposalBilling},
+	{http.MethodPost, cms.APIRoute + cms.RouteVoteDetailsDCC},
+	{http.MethodGet, cms.APIRoute + cms.RouteActiveVotesDCC},
+	{http.MethodGet, cms.APIRoute + www.RouteTokenInventory},
+	{http.MethodPost, cms.APIRoute + cms.RouteUserCodeStats},
+	{http.MethodGet, cms.APIRoute + cms.RouteDomains},
+	{http.MethodGet, cms.APIRoute + cms.RouteRateSchedules},
+	{http.MethodPost, cms.APIRoute + cms.RouteInvoicePayouts},
+	{http.MethodGet, cms.APIRoute + cms.RouteAdminUserInvoices},
+	{http.MethodGet, cms.APIRoute + cms.RouteProposalBillingSummary},
+	{http.MethodPost, cms.APIRoute + cms.RouteProposalBillingDetails},
+	{http.MethodGet, cms.APIRoute + www.RouteUserDetails},
+	{http.MethodGet, cms.APIRoute + cms.RouteCMSUsers},
+}
+
+// maintenance contains the read-only maintenance mode status of the server.
+// Maintenance mode is used during politeiad and database migrations.
+type maintenance struct {
+	sync.RWMutex
+	enabled    bool
+	message    string
+	since      time.Time
+	retryAfter time.Duration
+	reads      map[maintenanceRoute]struct{}
+}
+
+// newMaintenance returns a new maintenance. The retry after duration is
+// returned to the clients whose requests are rejected.
+func newMaintenance(enabled bool, retryAfter time.Duration) *maintenance {
+	m := maintenance{
+		enabled:    enabled,
+		retryAfter: retryAfter,
+		reads:      make(map[maintenanceRoute]struct{}, len(maintenanceReadRoutes)),
+	}
+	if enabled {
+		m.since = time.Now()
+	}
+	for _, v := range maintenanceReadRoutes {
+		m.reads[v] = struct{}{}
+	}
+	return &m
+}
+
+// set enables or disables maintenance mode.
+func (m *maintenance) set(enabled bool, message string) {
+	m.Lock()
+	defer m.Unlock()
+
+	switch {
+	case enabled && !m.enabled:
+		m.since = time.Now()
+	case !enabled:
+		m.since = time.Time{}
+		message = ""
+	}
+	m.enabled = enabled
+	m.message = message
+}
+
+// reply returns the maintenance mode status.
+func (m *maintenance) reply() www.MaintenanceReply {
+	m.RLock()
+	defer m.RUnlock()
+
+	if !m.enabled {
+		return www.MaintenanceReply{}
+	}
+	return www.MaintenanceReply{
+		Enabled:    true,
+		Message:    m.message,
+		Since:      m.since.Unix(),
+		RetryAfter: int64(m.retryAfter.Seconds()),
+	}
+}
+
+// isRead returns whether the request is sent to a read route. Requests that
+// did not match a route are treated as reads since they are answered with a
+// 404.
+func (m *maintenance) isRead(r *http.Request) bool {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return true
+	}
+	tmpl, err := route.GetPathTemplate()
+	if err != nil {
+		return false
+	}
+	_, ok := m.reads[maintenanceRoute{r.Method, tmpl}]
+	return ok
+}
+
+// middleware rejects the requests to all routes that are not read routes with
+// a 503 while the server is in maintenance mode. The reply contains a Retry-After header.
+func (m *maintenance) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mr := m.reply()
+		if !mr.Enabled || m.isRead(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		log.Debugf("%v maintenance mode: %v %v",
+			util.RemoteAddr(r), r.Method, r.URL)
+
+		var errContext []string
+		if mr.Message != "" {
+			errContext = []string{mr.Message}
+		}
+		w.Header().Set("Retry-After", strconv.FormatInt(mr.RetryAfter, 10))
+		util.RespondWithJSON(w, http.StatusServiceUnavailable, www.UserError{
+			ErrorCode:    www.ErrorStatusMaintenance,
+			ErrorContext: errContext,
+		})
+	})
+}
+
+// handleMaintenance returns the maintenance mode status.
+func (p *politeiawww) handleMaintenance(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleMaintenance")
+
+	util.RespondWithJSON(w, http.StatusOK, p.maintenance.reply())
+}
+
+// handleSetMaintenance enables or disables maintenance mode.
+func (p *politeiawww) handleSetMaintenance(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleSetMaintenance")
+
+	var sm www.SetMaintenance
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&sm); err != nil {
+		RespondWithError(w, r, 0, "handleSetMaintenance: unmarshal",
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	p.maintenance.set(sm.Enabled, sm.Message)
+
+	log.Infof("%v maintenance mode changed: enabled %v message %q",
+		util.RemoteAddr(r), sm.Enabled, sm.Message)
+
+	util.RespondWithJSON(w, http.StatusOK, www.SetMaintenanceReply{
+		Maintenance: p.maintenance.reply(),
+	})
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	elv1 "github.com/decred/politeia/politeiawww/api/eventlog/v1"
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/gorilla/mux"
+)
+
+func TestMaintenanceMiddleware(t *testing.T) {
+	m := newMaintenance(false, time.Minute)
+
+	var (
+		write       = rcv1.APIRoute + rcv1.RouteNew
+		read        = rcv1.APIRoute + rcv1.RouteRecords
+		replay      = elv1.APIRoute + elv1.RouteReplay
+		unsubscribe = www.PoliteiaWWWAPIRoute + www.RouteUnsubscribe
+	)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	router := mux.NewRouter()
+	router.Use(m.middleware)
+	router.HandleFunc(write, handler).Methods(http.MethodPost)
+	router.HandleFunc(read, handler).Methods(http.MethodPost)
+	router.HandleFunc(replay, handler).Methods(http.MethodPost)
+	router.HandleFunc(unsubscribe, handler).
+		Methods(http.MethodGet, http.MethodPost)
+
+	var tests = []struct {
+		name    string
+		enabled bool
+		method  string
+		route   string
+		want    int
+	}{
+		{"disabled write", false, http.MethodPost, write, http.StatusOK},
+		{"enabled read", true, http.MethodPost, read, http.StatusOK},
+		{"enabled write", true, http.MethodPost, write,
+			http.StatusServiceUnavailable},
+
+		// Routes that are not listed as reads are rejected
+		{"enabled unlisted", true, http.MethodPost, replay,
+			http.StatusServiceUnavailable},
+
+		// The same route can be a read for one method and a write for
+		// another.
+		{"enabled read method", true, http.MethodGet, unsubscribe,
+			http.StatusOK},
+		{"enabled write method", true, http.MethodPost, unsubscribe,
+			http.StatusServiceUnavailable},
+
+		// Requests that do not match a route are not rejected
+		{"enabled not found", true, http.MethodPost, "/notfound",
+			http.StatusNotFound},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			m.set(v.enabled, "database migration")
+
+			r := httptest.NewRequest(v.method, v.route, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+			if w.Code != v.want {
+				t.Fatalf("got code %v, want %v", w.Code, v.want)
+			}
+			if v.want != http.StatusServiceUnavailable {
+				return
+			}
+			if got := w.Header().Get("Retry-After"); got != "60" {
+				t.Fatalf("got Retry-After %v, want 60", got)
+			}
+			var ue www.UserError
+			err := json.Unmarshal(w.Body.Bytes(), &ue)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ue.ErrorCode != www.ErrorStatusMaintenance {
+				t.Fatalf("got error %v, want %v", ue.ErrorCode,
+					www.ErrorStatusMaintenance)
+			}
+		})
+	}
+
+	// Disabling maintenance mode clears the status
+	m.set(false, "database migration")
+	if mr := m.reply(); mr.Enabled || mr.Message != "" || mr.Since != 0 {
+		t.Fatalf("maintenance status was not cleared: %+v", mr)
+	}
+}
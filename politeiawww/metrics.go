@@ -0,0 +1,105 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+
+	"github.com/decred/politeia/politeiawww/metrics"
+)
+
+// setupMetrics registers the metrics that are retrieved from the politeiawww
+// components when the metrics are served. The metrics route is added to the
+// API router when the metrics are not served on a separate listener. Access
+// to the route is then restricted to the admin networks.
+func (p *politeiawww) setupMetrics() {
+	m := p.metrics
+
+	// Session store stats
+	m.CounterFunc("politeiawww_sessions_created_total",
+		"Total number of sessions created.",
+		func() float64 {
+			return float64(p.sessions.Stats().Created)
+		})
+	m.CounterFunc("politeiawww_sessions_deleted_total",
+		"Total number of sessions deleted.",
+		func() float64 {
+			return float64(p.sessions.Stats().Deleted)
+		})
+	m.CounterFunc("politeiawww_sessions_expired_total",
+		"Total number of expired sessions deleted.",
+		func() float64 {
+			return float64(p.sessions.Stats().Expired)
+		})
+	m.CounterFunc("politeiawww_session_store_errors_total",
+		"Total number of session store errors.",
+		func() float64 {
+			return float64(p.sessions.Stats().Errors)
+		})
+
+	// Event queues
+	m.GaugeVecFunc("politeiawww_event_queue_depth",
+		"Number of events waiting to be handled by event type.", "event",
+		func() map[string]float64 {
+			depth := p.events.QueueDepth()
+			values := make(map[string]float64, len(depth))
+			for k, v := range depth {
+				values[k] = float64(v)
+			}
+			return values
+		})
+
+	// Email
+	m.CounterFunc("politeiawww_email_send_failures_total",
+		"Total number of emails that none of the mail providers could send.",
+		func() float64 {
+			return float64(p.mail.SendFailures())
+		})
+	m.CounterVecFunc("politeiawww_email_provider_sent_total",
+		"Total number of emails sent by mail provider.", "provider",
+		func() map[string]float64 {
+			stats := p.mail.ProviderStats()
+			values := make(map[string]float64, len(stats))
+			for k, v := range stats {
+				values[k] = float64(v.Sent)
+			}
+			return values
+		})
+	m.CounterVecFunc("politeiawww_email_provider_failures_total",
+		"Total number of emails that a mail provider could not send.",
+		"provider",
+		func() map[string]float64 {
+			stats := p.mail.ProviderStats()
+			values := make(map[string]float64, len(stats))
+			for k, v := range stats {
+				values[k] = float64(v.Failures)
+			}
+			return values
+		})
+
+	if p.cfg.MetricsListen != "" {
+		return
+	}
+	p.router.StrictSlash(true).
+		HandleFunc(metrics.Route, p.isAdminNetwork(m.ServeHTTP)).
+		Methods(http.MethodGet)
+}
+
+// listenMetrics serves the metrics over plain HTTP on the provided metrics
+// server. The returned error is sent to the provided channel.
+func (p *politeiawww) listenMetrics(srv *http.Server, listenC chan error) {
+	log.Infof("Metrics listen: %v", srv.Addr)
+	listenC <- srv.ListenAndServe()
+}
+
+// newMetricsServer returns the HTTP server of the metrics listener.
+func (p *politeiawww) newMetricsServer() *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle(metrics.Route, p.metrics)
+	return &http.Server{
+		Handler: mux,
+		Addr:    p.cfg.MetricsListen,
+	}
+}
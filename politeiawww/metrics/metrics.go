@@ -0,0 +1,404 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// Route is the route that the metrics are served on.
+	Route = "/metrics"
+
+	// contentType is the content type of the Prometheus text exposition
+	// format.
+	contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+	// routeUnmatched is the route label of requests that did not match
+	// a route.
+	routeUnmatched = "unmatched"
+
+	// Metric types
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+var (
+	// buckets contains the upper bounds, in seconds, of the latency
+	// histogram buckets.
+	buckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+)
+
+// histogram tracks the distribution of observed latencies.
+type histogram struct {
+	counts []uint64 // Count per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+func newHistogram() *histogram {
+	return &histogram{
+		counts: make([]uint64, len(buckets)),
+	}
+}
+
+// observe adds a latency to the histogram.
+func (h *histogram) observe(d time.Duration) {
+	s := d.Seconds()
+	for i, v := range buckets {
+		if s <= v {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += s
+}
+
+// requestKey identifies the http requests of a route.
+type requestKey struct {
+	method string
+	route  string
+}
+
+// responseKey identifies the http responses of a route.
+type responseKey struct {
+	method string
+	route  string
+	code   int
+}
+
+// metricFunc is a metric whose values are retrieved when the metrics are
+// served. The map key is the value of the metric label. A metric without a
+// label uses an empty map key.
+type metricFunc struct {
+	name  string
+	help  string
+	typ   string
+	label string
+	fn    func() map[string]float64
+}
+
+// Metrics collects the politeiawww metrics and serves them using the
+// Prometheus text exposition format.
+type Metrics struct {
+	sync.Mutex
+	requests        map[requestKey]*histogram // HTTP request latencies
+	responses       map[responseKey]uint64    // HTTP response counts
+	politeiad       map[string]*histogram     // [route]politeiad latencies
+	politeiadErrors map[string]uint64         // [route]politeiad errors
+	funcs           []metricFunc
+}
+
+// New returns a new Metrics context.
+func New() *Metrics {
+	return &Metrics{
+		requests:        make(map[requestKey]*histogram),
+		responses:       make(map[responseKey]uint64),
+		politeiad:       make(map[string]*histogram),
+		politeiadErrors: make(map[string]uint64),
+	}
+}
+
+// CounterFunc registers a counter whose value is retrieved using the provided
+// function when the metrics are served.
+func (m *Metrics) CounterFunc(name, help string, fn func() float64) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.funcs = append(m.funcs, metricFunc{
+		name: name,
+		help: help,
+		typ:  typeCounter,
+		fn: func() map[string]float64 {
+			return map[string]float64{"": fn()}
+		},
+	})
+}
+
+// CounterVecFunc registers a counter with a single label whose values are
+// retrieved using the provided function when the metrics are served. The map
+// key of the returned values is the value of the label.
+func (m *Metrics) CounterVecFunc(name, help, label string, fn func() map[string]float64) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.funcs = append(m.funcs, metricFunc{
+		name:  name,
+		help:  help,
+		typ:   typeCounter,
+		label: label,
+		fn:    fn,
+	})
+}
+
+// GaugeVecFunc registers a gauge with a single label whose values are
+// retrieved using the provided function when the metrics are served. The map
+// key of the returned values is the value of the label.
+func (m *Metrics) GaugeVecFunc(name, help, label string, fn func() map[string]float64) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.funcs = append(m.funcs, metricFunc{
+		name:  name,
+		help:  help,
+		typ:   typeGauge,
+		label: label,
+		fn:    fn,
+	})
+}
+
+// ObservePoliteiad records the latency of a politeiad request. Requests that
+// returned an error are also counted as errors.
+func (m *Metrics) ObservePoliteiad(route string, d time.Duration, err error) {
+	m.Lock()
+	defer m.Unlock()
+
+	h, ok := m.politeiad[route]
+	if !ok {
+		h = newHistogram()
+		m.politeiad[route] = h
+	}
+	h.observe(d)
+	if err != nil {
+		m.politeiadErrors[route]++
+	}
+}
+
+// observeRequest records the latency and the response status code of a http
+// request.
+func (m *Metrics) observeRequest(method, route string, code int, d time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+
+	rk := requestKey{
+		method: method,
+		route:  route,
+	}
+	h, ok := m.requests[rk]
+	if !ok {
+		h = newHistogram()
+		m.requests[rk] = h
+	}
+	h.observe(d)
+	m.responses[responseKey{
+		method: method,
+		route:  route,
+		code:   code,
+	}]++
+}
+
+// statusWriter records the status code of the reply to a request.
+type statusWriter struct {
+	http.ResponseWriter
+	code int
+}
+
+// WriteHeader satisfies the http.ResponseWriter interface.
+func (w *statusWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write satisfies the http.ResponseWriter interface.
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Hijack satisfies the http.Hijacker interface. The websocket routes require
+// the underlying connection to be hijacked.
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer is not a hijacker")
+	}
+	w.code = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
+
+// Middleware records the request count and the latency of every request. The
+// requests are labeled using the route template instead of the request path
+// so that path variables do not create a new label for every request.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeUnmatched
+		if cr := mux.CurrentRoute(r); cr != nil {
+			if t, err := cr.GetPathTemplate(); err == nil {
+				route = t
+			}
+		}
+
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r)
+		if sw.code == 0 {
+			sw.code = http.StatusOK
+		}
+
+		m.observeRequest(r.Method, route, sw.code, time.Since(start))
+	})
+}
+
+// ServeHTTP serves the metrics using the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var b strings.Builder
+	m.write(&b)
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(b.String()))
+}
+
+// write writes all metrics to the provided builder.
+func (m *Metrics) write(b *strings.Builder) {
+	m.Lock()
+	defer m.Unlock()
+
+	// HTTP requests
+	rks := make([]responseKey, 0, len(m.responses))
+	for k := range m.responses {
+		rks = append(rks, k)
+	}
+	sort.Slice(rks, func(i, j int) bool {
+		if rks[i].route != rks[j].route {
+			return rks[i].route < rks[j].route
+		}
+		if rks[i].method != rks[j].method {
+			return rks[i].method < rks[j].method
+		}
+		return rks[i].code < rks[j].code
+	})
+	writeHeader(b, "politeiawww_http_requests_total",
+		"Total number of HTTP requests by route and status code.",
+		typeCounter)
+	for _, k := range rks {
+		writeSample(b, "politeiawww_http_requests_total",
+			labels("method", k.method, "route", k.route,
+				"code", strconv.Itoa(k.code)),
+			float64(m.responses[k]))
+	}
+
+	qks := make([]requestKey, 0, len(m.requests))
+	for k := range m.requests {
+		qks = append(qks, k)
+	}
+	sort.Slice(qks, func(i, j int) bool {
+		if qks[i].route != qks[j].route {
+			return qks[i].route < qks[j].route
+		}
+		return qks[i].method < qks[j].method
+	})
+	writeHeader(b, "politeiawww_http_request_duration_seconds",
+		"HTTP request latencies by route.", typeHistogram)
+	for _, k := range qks {
+		writeHistogram(b, "politeiawww_http_request_duration_seconds",
+			labels("method", k.method, "route", k.route), m.requests[k])
+	}
+
+	// Politeiad requests
+	routes := make([]string, 0, len(m.politeiad))
+	for k := range m.politeiad {
+		routes = append(routes, k)
+	}
+	sort.Strings(routes)
+	writeHeader(b, "politeiawww_politeiad_request_duration_seconds",
+		"Politeiad request latencies by route.", typeHistogram)
+	for _, v := range routes {
+		writeHistogram(b, "politeiawww_politeiad_request_duration_seconds",
+			labels("route", v), m.politeiad[v])
+	}
+	writeHeader(b, "politeiawww_politeiad_request_errors_total",
+		"Total number of failed politeiad requests by route.", typeCounter)
+	for _, v := range routes {
+		writeSample(b, "politeiawww_politeiad_request_errors_total",
+			labels("route", v), float64(m.politeiadErrors[v]))
+	}
+
+	// Registered metrics
+	for _, f := range m.funcs {
+		values := f.fn()
+		keys := make([]string, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		writeHeader(b, f.name, f.help, f.typ)
+		for _, k := range keys {
+			var l string
+			if f.label != "" {
+				l = labels(f.label, k)
+			}
+			writeSample(b, f.name, l, values[k])
+		}
+	}
+}
+
+// writeHeader writes the help and type lines of a metric.
+func writeHeader(b *strings.Builder, name, help, typ string) {
+	fmt.Fprintf(b, "# HELP %v %v\n", name, help)
+	fmt.Fprintf(b, "# TYPE %v %v\n", name, typ)
+}
+
+// writeSample writes a single sample. The labels must already be formatted.
+func writeSample(b *strings.Builder, name, labels string, v float64) {
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+	fmt.Fprintf(b, "%v%v %v\n", name, labels,
+		strconv.FormatFloat(v, 'g', -1, 64))
+}
+
+// writeHistogram writes the cumulative buckets, the sum, and the count of a
+// histogram.
+func writeHistogram(b *strings.Builder, name, l string, h *histogram) {
+	bucket := func(le string) string {
+		if l == "" {
+			return labels("le", le)
+		}
+		return l + "," + labels("le", le)
+	}
+	var cumulative uint64
+	for i, v := range buckets {
+		cumulative += h.counts[i]
+		le := strconv.FormatFloat(v, 'g', -1, 64)
+		writeSample(b, name+"_bucket", bucket(le), float64(cumulative))
+	}
+	writeSample(b, name+"_bucket", bucket("+Inf"), float64(h.count))
+	writeSample(b, name+"_sum", l, h.sum)
+	writeSample(b, name+"_count", l, float64(h.count))
+}
+
+// labels formats the provided label name and value pairs.
+func labels(pairs ...string) string {
+	s := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		s = append(s, fmt.Sprintf("%v=\"%v\"", pairs[i],
+			escapeLabel(pairs[i+1])))
+	}
+	return strings.Join(s, ",")
+}
+
+// escapeLabel escapes a label value.
+func escapeLabel(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return strings.ReplaceAll(s, "\n", `\n`)
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	m := New()
+	m.CounterFunc("test_total", "Test counter.", func() float64 {
+		return 3
+	})
+	m.GaugeVecFunc("test_depth", "Test gauge.", "event",
+		func() map[string]float64 {
+			return map[string]float64{
+				"b": 2,
+				"a": 1,
+			}
+		})
+	m.CounterVecFunc("test_sent_total", "Test counter vec.", "provider",
+		func() map[string]float64 {
+			return map[string]float64{"smtp": 4}
+		})
+	m.ObservePoliteiad("/v2/read", 20*time.Millisecond, nil)
+	m.ObservePoliteiad("/v2/read", 3*time.Second, errors.New("error"))
+
+	// Send a request through the middleware
+	h := m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(),
+		httptest.NewRequest(http.MethodGet, "/foo", nil))
+
+	// Serve the metrics
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest(http.MethodGet, Route, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %v, want %v", w.Code, http.StatusOK)
+	}
+	b, err := ioutil.ReadAll(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := string(b)
+
+	// Verify the expected samples are present
+	samples := []string{
+		`politeiawww_http_requests_total{method="GET",route="unmatched",code="404"} 1`,
+		`politeiawww_http_request_duration_seconds_count{method="GET",route="unmatched"} 1`,
+		`politeiawww_politeiad_request_duration_seconds_bucket{route="/v2/read",le="0.025"} 1`,
+		`politeiawww_politeiad_request_duration_seconds_bucket{route="/v2/read",le="2.5"} 1`,
+		`politeiawww_politeiad_request_duration_seconds_bucket{route="/v2/read",le="+Inf"} 2`,
+		`politeiawww_politeiad_request_duration_seconds_count{route="/v2/read"} 2`,
+		`politeiawww_politeiad_request_errors_total{route="/v2/read"} 1`,
+		"# TYPE test_total counter\ntest_total 3\n",
+		"test_depth{event=\"a\"} 1\ntest_depth{event=\"b\"} 2\n",
+		"# TYPE test_sent_total counter\ntest_sent_total{provider=\"smtp\"} 4\n",
+	}
+	for _, v := range samples {
+		if !strings.Contains(body, v) {
+			t.Errorf("sample not found: %v\n%v", v, body)
+		}
+	}
+}
@@ -42,6 +42,16 @@ func (p *politeiawww) isLoggedIn(f http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// sessionUserID returns the user ID of the session of the request. An empty
+// string is returned when the request does not have a valid session.
+func (p *politeiawww) sessionUserID(w http.ResponseWriter, r *http.Request) string {
+	userID, err := p.sessions.GetSessionUserID(w, r)
+	if err != nil {
+		return ""
+	}
+	return userID
+}
+
 // isAdmin returns true if the current session has admin privileges.
 func (p *politeiawww) isAdmin(w http.ResponseWriter, r *http.Request) (bool, error) {
 	user, err := p.sessions.GetSessionUser(w, r)
@@ -0,0 +1,218 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package nonces provides replay protection for the requests that are
+// authenticated by a client signature, such as new comments and cast votes.
+// The client signs a random nonce and an expiry along with the request. The
+// server rejects requests that have expired and requests that reuse a nonce.
+// A nonce only needs to be remembered until the request that used it expires.
+package nonces
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// pruneInterval is the interval at which the expired nonces are
+	// removed from the in-memory store.
+	pruneInterval = time.Minute
+)
+
+// ErrorCodeT represents a nonce verification error.
+type ErrorCodeT int
+
+const (
+	// ErrorCodeInvalid is returned when a nonce or an expiry is
+	// malformed, or when a required nonce is missing.
+	ErrorCodeInvalid ErrorCodeT = 1
+
+	// ErrorCodeExpired is returned when the expiry of a request has
+	// passed.
+	ErrorCodeExpired ErrorCodeT = 2
+
+	// ErrorCodeUsed is returned when a nonce has already been used.
+	ErrorCodeUsed ErrorCodeT = 3
+)
+
+// VerifyError is returned when the nonce of a request fails verification.
+type VerifyError struct {
+	ErrorCode    ErrorCodeT
+	ErrorContext string
+}
+
+// Error satisfies the error interface.
+func (e VerifyError) Error() string {
+	return fmt.Sprintf("nonce error code %v: %v", e.ErrorCode, e.ErrorContext)
+}
+
+// Store records the nonces that have been used by signed requests until the
+// requests expire.
+type Store interface {
+	// NonceAdd records a nonce as used until the provided unix expiry.
+	// False is returned if the nonce has already been used.
+	NonceAdd(nonce string, expiry int64) (bool, error)
+
+	// NonceDel deletes a nonce so that it can be used again.
+	NonceDel(nonce string) error
+}
+
+// memStore is the in-memory Store.
+//
+// This is fine for a single politeiawww instance since a nonce can only be
+// replayed until it expires.
+type memStore struct {
+	sync.Mutex
+	used map[string]int64 // [nonce]expiry
+}
+
+// NonceAdd satisfies the Store interface. A nonce whose expiry has passed is
+// treated as unused since the expired nonces are only removed periodically.
+func (m *memStore) NonceAdd(nonce string, expiry int64) (bool, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if e, ok := m.used[nonce]; ok && e > time.Now().Unix() {
+		return false, nil
+	}
+	m.used[nonce] = expiry
+
+	return true, nil
+}
+
+// NonceDel satisfies the Store interface.
+func (m *memStore) NonceDel(nonce string) error {
+	m.Lock()
+	defer m.Unlock()
+
+	delete(m.used, nonce)
+
+	return nil
+}
+
+// prune removes the expired nonces. They can no longer be replayed since the
+// expiry of the requests that used them has passed.
+func (m *memStore) prune(now time.Time) {
+	m.Lock()
+	defer m.Unlock()
+
+	for k, v := range m.used {
+		if v <= now.Unix() {
+			delete(m.used, k)
+		}
+	}
+}
+
+// pruneLoop periodically removes the expired nonces. It runs for the lifetime
+// of the process.
+func (m *memStore) pruneLoop() {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		m.prune(now)
+	}
+}
+
+// Nonces verifies the nonces of signed requests. The nonces are kept in
+// memory by default. A store that is shared by all politeiawww instances can
+// be set using SetStore.
+type Nonces struct {
+	store     Store
+	size      int   // Nonce size in bytes
+	expiryMax int64 // Max seconds that an expiry can be in the future
+}
+
+// New returns a new Nonces context that uses the in-memory store. The size is
+// the size in bytes of a nonce and expiryMax is the maximum number of seconds
+// in the future that the expiry of a request can be.
+func New(size int, expiryMax int64) *Nonces {
+	m := &memStore{
+		used: make(map[string]int64),
+	}
+	go m.pruneLoop()
+
+	return &Nonces{
+		store:     m,
+		size:      size,
+		expiryMax: expiryMax,
+	}
+}
+
+// SetStore sets the store that records the used nonces. This must be set
+// prior to the Nonces context being used.
+func (n *Nonces) SetStore(s Store) {
+	n.store = s
+}
+
+// Verify verifies the nonce and expiry of a signed request and marks the
+// nonce as used. The key scopes the nonce, e.g. to the public key that signed
+// the request. Requests without a nonce are allowed when nonces are not
+// required. A VerifyError is returned if the verification fails.
+func (n *Nonces) Verify(key, nonce string, expiry int64, required bool, now time.Time) error {
+	if nonce == "" {
+		switch {
+		case expiry != 0:
+			return VerifyError{
+				ErrorCode:    ErrorCodeInvalid,
+				ErrorContext: "expiry provided without a nonce",
+			}
+		case required:
+			return VerifyError{
+				ErrorCode:    ErrorCodeInvalid,
+				ErrorContext: "nonce required",
+			}
+		}
+		return nil
+	}
+
+	// Verify nonce
+	b, err := hex.DecodeString(nonce)
+	if err != nil || len(b) != n.size {
+		return VerifyError{
+			ErrorCode: ErrorCodeInvalid,
+			ErrorContext: fmt.Sprintf("nonce must be %v hex encoded bytes",
+				n.size),
+		}
+	}
+
+	// Verify expiry
+	switch {
+	case expiry <= now.Unix():
+		return VerifyError{
+			ErrorCode: ErrorCodeExpired,
+		}
+	case expiry > now.Unix()+n.expiryMax:
+		return VerifyError{
+			ErrorCode: ErrorCodeInvalid,
+			ErrorContext: fmt.Sprintf("expiry must be within %v seconds",
+				n.expiryMax),
+		}
+	}
+
+	// Verify the nonce has not been used
+	ok, err := n.store.NonceAdd(key+nonce, expiry)
+	if err != nil {
+		return fmt.Errorf("nonce add: %v", err)
+	}
+	if !ok {
+		return VerifyError{
+			ErrorCode: ErrorCodeUsed,
+		}
+	}
+
+	return nil
+}
+
+// Release releases a nonce that was marked as used by Verify so that the
+// request can be resubmitted. It is used when a request has been rejected
+// before it took effect.
+func (n *Nonces) Release(key, nonce string) error {
+	if nonce == "" {
+		return nil
+	}
+	return n.store.NonceDel(key + nonce)
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package nonces
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	var (
+		now    = time.Now()
+		expiry = now.Unix() + 60
+		nonce  = strings.Repeat("ab", 16)
+	)
+	tests := []struct {
+		name     string
+		key      string
+		nonce    string
+		expiry   int64
+		required bool
+		want     ErrorCodeT // Zero means no error
+	}{
+		{"no nonce", "a", "", 0, false, 0},
+		{"nonce required", "a", "", 0, true, ErrorCodeInvalid},
+		{"expiry without nonce", "a", "", expiry, false, ErrorCodeInvalid},
+		{"nonce not hex", "a", "zz", expiry, false, ErrorCodeInvalid},
+		{"nonce wrong size", "a", "abab", expiry, false, ErrorCodeInvalid},
+		{"expired", "a", nonce, now.Unix(), false, ErrorCodeExpired},
+		{"expiry too far", "a", nonce, now.Unix() + 61, false,
+			ErrorCodeInvalid},
+		{"valid", "a", nonce, expiry, true, 0},
+		{"replay", "a", nonce, expiry, true, ErrorCodeUsed},
+		{"other key", "b", nonce, expiry, true, 0},
+	}
+	n := New(16, 60)
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			err := n.Verify(v.key, v.nonce, v.expiry, v.required, now)
+			var got ErrorCodeT
+			var ve VerifyError
+			switch {
+			case errors.As(err, &ve):
+				got = ve.ErrorCode
+			case err != nil:
+				t.Fatal(err)
+			}
+			if got != v.want {
+				t.Fatalf("got error code %v, want %v", got, v.want)
+			}
+		})
+	}
+
+	// A released nonce can be used again
+	err := n.Release("a", nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = n.Verify("a", nonce, expiry, true, now)
+	if err != nil {
+		t.Fatalf("released nonce: %v", err)
+	}
+}
+
+func TestMemStorePrune(t *testing.T) {
+	m := &memStore{
+		used: make(map[string]int64),
+	}
+	now := time.Now()
+	for _, v := range []struct {
+		nonce  string
+		expiry int64
+	}{
+		{"expired", now.Unix() - 1},
+		{"valid", now.Unix() + 60},
+	} {
+		ok, err := m.NonceAdd(v.nonce, v.expiry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Fatalf("nonce %v was already used", v.nonce)
+		}
+	}
+
+	// A nonce that has not been pruned yet can't be reused until it
+	// has expired
+	ok, err := m.NonceAdd("valid", now.Unix()+60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatalf("valid nonce was reused")
+	}
+
+	// Only the expired nonces are pruned
+	m.prune(now)
+	if _, ok := m.used["expired"]; ok {
+		t.Fatalf("expired nonce was not pruned")
+	}
+	if _, ok := m.used["valid"]; !ok {
+		t.Fatalf("valid nonce was pruned")
+	}
+}
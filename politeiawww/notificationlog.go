@@ -0,0 +1,157 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/mail"
+	"github.com/decred/politeia/politeiawww/webhook"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// mailLogFilename is the filename of the mail send log. It is saved
+	// to the politeiawww data directory.
+	mailLogFilename = "maillog.json"
+)
+
+// handleNotificationLog returns the notification send attempts that match the
+// provided filters.
+func (p *politeiawww) handleNotificationLog(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleNotificationLog")
+
+	var ml www.NotificationLog
+	err := util.ParseGetParams(r, &ml)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleNotificationLog: ParseGetParams",
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	mlr, err := p.processNotificationLog(ml)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleNotificationLog: processNotificationLog: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, mlr)
+}
+
+// handleNotificationRetry retriggers the delivery of a notification that failed
+// permanently.
+func (p *politeiawww) handleNotificationRetry(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleNotificationRetry")
+
+	var mr www.NotificationRetry
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&mr); err != nil {
+		RespondWithError(w, r, 0, "handleNotificationRetry: unmarshal",
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	mrr, err := p.processNotificationRetry(mr)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleNotificationRetry: processNotificationRetry: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, mrr)
+}
+
+// processNotificationLog returns the most recent notification send attempts that
+// match the provided filters. The user ID filter is matched against the
+// current email address of the user.
+func (p *politeiawww) processNotificationLog(ml www.NotificationLog) (*www.NotificationLogReply, error) {
+	log.Tracef("processNotificationLog: %v %v %v %v %v %v", ml.Recipient, ml.UserID,
+		ml.MessageID, ml.Event, ml.Channel, ml.Failures)
+
+	switch ml.Channel {
+	case "", www.NotificationChannelEmail, www.NotificationChannelWebhook:
+	default:
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"invalid channel"},
+		}
+	}
+
+	recipient := ml.Recipient
+	if ml.UserID != "" {
+		u, err := p.userByIDStr(ml.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if recipient != "" && !strings.EqualFold(recipient, u.Email) {
+			// The filters can't both match
+			return &www.NotificationLogReply{
+				Entries: []www.NotificationLogEntry{},
+			}, nil
+		}
+		recipient = u.Email
+	}
+
+	return &www.NotificationLogReply{
+		Entries: p.mailLog.Query(mail.QueryFilter{
+			Recipient: recipient,
+			MessageID: ml.MessageID,
+			Event:     ml.Event,
+			Channel:   ml.Channel,
+			Failures:  ml.Failures,
+		}, www.NotificationLogPageSize),
+	}, nil
+}
+
+// processNotificationRetry retriggers the delivery of a notification that failed
+// permanently. The message ID is looked up in the failed notification emails
+// first and then in the failed webhook deliveries.
+func (p *politeiawww) processNotificationRetry(mr www.NotificationRetry) (*www.NotificationRetryReply, error) {
+	log.Tracef("processNotificationRetry: %v", mr.MessageID)
+
+	if mr.MessageID == "" {
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"message id not provided"},
+		}
+	}
+
+	err := p.mailQueue.Retry(mr.MessageID)
+	switch {
+	case err == nil:
+		return &www.NotificationRetryReply{
+			Channel: www.NotificationChannelEmail,
+		}, nil
+	case !errors.Is(err, mail.ErrNotFound):
+		return nil, err
+	}
+
+	for _, v := range p.webhooks {
+		err := v.Retry(mr.MessageID)
+		switch {
+		case err == nil:
+			log.Infof("Webhook deliveries %v retried", mr.MessageID)
+			return &www.NotificationRetryReply{
+				Channel: www.NotificationChannelWebhook,
+			}, nil
+		case !errors.Is(err, webhook.ErrNotFound):
+			return nil, err
+		}
+	}
+
+	return nil, www.UserError{
+		ErrorCode:    www.ErrorStatusInvalidInput,
+		ErrorContext: []string{"failed notification not found"},
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package oauth
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+const (
+	// clientsFilename is the name of the file in the data directory that
+	// the registered applications are persisted to.
+	clientsFilename = "oauthclients.json"
+)
+
+// registeredClient is an application that has been registered by an admin
+// using the API. This is a JSON structure so that the applications can be
+// persisted to disk. Only the hash of the client secret is stored.
+type registeredClient struct {
+	Name        string `json:"name"`
+	RedirectURI string `json:"redirecturi"`
+	SecretHash  string `json:"secrethash,omitempty"` // SHA256 hex
+	Timestamp   int64  `json:"timestamp"`            // UNIX time of registration
+	UserID      string `json:"userid"`               // Admin that registered it
+}
+
+// client returns the client of a registered application.
+func (rc registeredClient) client(clientID string) (*client, error) {
+	c := client{
+		id:          clientID,
+		redirectURI: rc.RedirectURI,
+		name:        rc.Name,
+	}
+	if rc.SecretHash != "" {
+		h, err := hex.DecodeString(rc.SecretHash)
+		if err != nil {
+			return nil, fmt.Errorf("client %v: invalid secret hash: %v",
+				clientID, err)
+		}
+		c.secretHash = h
+	}
+	return &c, nil
+}
+
+// clientStore contains the applications that have been registered using the
+// API. The applications are persisted to disk on every change.
+type clientStore struct {
+	sync.Mutex
+	path    string
+	clients map[string]registeredClient // [clientID]registeredClient
+}
+
+// newClientStore returns a new clientStore that is loaded from the provided
+// file. The file is created on the first registration if it does not exist.
+func newClientStore(path string) (*clientStore, error) {
+	cs := clientStore{
+		path:    path,
+		clients: make(map[string]registeredClient, 16),
+	}
+	b, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return &cs, nil
+	case err != nil:
+		return nil, err
+	}
+	err = json.Unmarshal(b, &cs.clients)
+	if err != nil {
+		return nil, fmt.Errorf("decode %v: %v", path, err)
+	}
+	for k, v := range cs.clients {
+		if _, err := v.client(k); err != nil {
+			return nil, err
+		}
+	}
+	return &cs, nil
+}
+
+// get returns a registered application.
+func (cs *clientStore) get(clientID string) (registeredClient, bool) {
+	cs.Lock()
+	defer cs.Unlock()
+
+	rc, ok := cs.clients[clientID]
+	return rc, ok
+}
+
+// all returns all registered applications.
+func (cs *clientStore) all() map[string]registeredClient {
+	cs.Lock()
+	defer cs.Unlock()
+
+	return cs.copy()
+}
+
+// copy returns a copy of the registered applications.
+//
+// This function must be called WITH the lock held.
+func (cs *clientStore) copy() map[string]registeredClient {
+	clients := make(map[string]registeredClient, len(cs.clients)+1)
+	for k, v := range cs.clients {
+		clients[k] = v
+	}
+	return clients
+}
+
+// save writes the provided applications to disk and then replaces the
+// applications in memory with them. The applications in memory are not
+// changed if the write fails so that they never diverge from the applications
+// on disk.
+//
+// This function must be called WITH the lock held.
+func (cs *clientStore) save(clients map[string]registeredClient) error {
+	err := writeJSON(cs.path, clients)
+	if err != nil {
+		return err
+	}
+	cs.clients = clients
+	return nil
+}
+
+// writeJSON writes the JSON encoding of v to the provided file. The file is
+// replaced atomically.
+func writeJSON(path string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	err = ioutil.WriteFile(tmp, b, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// add adds a registered application.
+func (cs *clientStore) add(clientID string, rc registeredClient) error {
+	cs.Lock()
+	defer cs.Unlock()
+
+	if _, ok := cs.clients[clientID]; ok {
+		return fmt.Errorf("client %v already exists", clientID)
+	}
+	clients := cs.copy()
+	clients[clientID] = rc
+
+	return cs.save(clients)
+}
+
+// del deletes a registered application. An error is returned if the
+// application does not exist.
+func (cs *clientStore) del(clientID string) error {
+	cs.Lock()
+	defer cs.Unlock()
+
+	if _, ok := cs.clients[clientID]; !ok {
+		return errClientNotFound
+	}
+	clients := cs.copy()
+	delete(clients, clientID)
+
+	return cs.save(clients)
+}
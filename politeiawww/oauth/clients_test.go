@@ -0,0 +1,69 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package oauth
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClientStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oauth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, clientsFilename)
+
+	rc := registeredClient{
+		Name:        "App",
+		RedirectURI: "https://app.example.com/callback",
+		Timestamp:   1,
+	}
+	cs, err := newClientStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cs.add("a", rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed write must not change the clients in memory
+	cs.path = filepath.Join(dir, "missing", clientsFilename)
+	err = cs.add("b", rc)
+	if err == nil {
+		t.Fatalf("add succeeded with a failed write")
+	}
+	if _, ok := cs.get("b"); ok {
+		t.Fatalf("client was added in memory")
+	}
+	err = cs.del("a")
+	if err == nil {
+		t.Fatalf("del succeeded with a failed write")
+	}
+	if _, ok := cs.get("a"); !ok {
+		t.Fatalf("client was deleted in memory")
+	}
+
+	// Reload the clients from disk
+	cs, err = newClientStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clients := cs.all()
+	if len(clients) != 1 || clients["a"] != rc {
+		t.Fatalf("got clients %+v", clients)
+	}
+	err = cs.del("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = cs.del("a"); err != errClientNotFound {
+		t.Fatalf("got %v, want %v", err, errClientNotFound)
+	}
+}
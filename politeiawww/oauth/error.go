@@ -0,0 +1,93 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	v1 "github.com/decred/politeia/politeiawww/api/oauth/v1"
+	"github.com/decred/politeia/util"
+)
+
+func respondWithError(w http.ResponseWriter, r *http.Request, format string, err error) {
+	// Check if the client dropped the connection
+	if err := r.Context().Err(); err == context.Canceled {
+		log.Infof("%v %v %v %v client aborted connection",
+			util.RemoteAddr(r), r.Method, r.URL, r.Proto)
+
+		// Client dropped the connection. There is no need to
+		// respond further.
+		return
+	}
+
+	// Check for expected error types
+	var ue v1.UserErrorReply
+	switch {
+	case errors.As(err, &ue):
+		// OAuth user error
+		m := fmt.Sprintf("%v OAuth user error: %v %v",
+			util.RemoteAddr(r), ue.ErrorCode, v1.ErrorCodes[ue.ErrorCode])
+		if ue.ErrorContext != "" {
+			m += fmt.Sprintf(": %v", ue.ErrorContext)
+		}
+		log.Infof(m)
+		util.RespondWithJSON(w, http.StatusBadRequest,
+			v1.UserErrorReply{
+				ErrorCode:    ue.ErrorCode,
+				ErrorContext: ue.ErrorContext,
+			})
+		return
+
+	default:
+		// Internal server error. Log it and return a 500.
+		t := time.Now().Unix()
+		e := fmt.Sprintf(format, err)
+		log.Errorf("%v %v %v %v Internal error %v: %v",
+			util.RemoteAddr(r), r.Method, r.URL, r.Proto, t, e)
+
+		// If this is a pkg/errors error then we can pull the
+		// stack trace out of the error, otherwise, we use the
+		// stack trace for this function.
+		stack, ok := util.StackTrace(err)
+		if !ok {
+			stack = string(debug.Stack())
+		}
+
+		log.Errorf("Stacktrace (NOT A REAL CRASH): %v", stack)
+
+		util.RespondWithJSON(w, http.StatusInternalServerError,
+			v1.ServerErrorReply{
+				ErrorCode: t,
+			})
+		return
+	}
+}
+
+// respondWithOAuthError responds with an OAuth2 error. It is used by the
+// token endpoint and by the routes that require an access token so that
+// standard OAuth2 client libraries can handle the errors. The routes that
+// require an access token also set the WWW-Authenticate header as described
+// in RFC 6750.
+func respondWithOAuthError(w http.ResponseWriter, r *http.Request, statusCode int, code, description string) {
+	log.Infof("%v OAuth error: %v %v: %v",
+		util.RemoteAddr(r), r.URL, code, description)
+
+	if code == v1.ErrorInvalidToken || code == v1.ErrorInsufficientScope {
+		w.Header().Set("WWW-Authenticate",
+			fmt.Sprintf(`Bearer error="%v", error_description="%v"`,
+				code, description))
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	util.RespondWithJSON(w, statusCode,
+		v1.ErrorReply{
+			Error:            code,
+			ErrorDescription: description,
+		})
+}
@@ -0,0 +1,299 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package oauth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/google/uuid"
+)
+
+const (
+	// grantsFilename is the name of the file in the data directory that
+	// the grants were persisted to before they were moved to the user
+	// database. The file is imported into the user database on startup.
+	grantsFilename = "oauthgrants.json"
+
+	// tokenPruneInterval is the interval at which the expired access
+	// tokens are deleted from the user database.
+	tokenPruneInterval = accessTokenExpiry
+)
+
+// grantStore contains the grants of all users along with the access tokens
+// and refresh tokens that have been issued for the grants. The grants and
+// tokens are persisted to the user database so that they are shared by all
+// politeiawww instances and survive a restart. Only hashes of the tokens are
+// stored.
+type grantStore struct {
+	sync.Mutex // Serializes the grant updates of this instance
+	userdb     user.Database
+	pruned     time.Time // Last time the expired tokens were deleted
+}
+
+// newGrantStore returns a new grantStore. The grants of the legacy grants
+// file are imported into the user database if the file exists. The file is
+// renamed once the import has completed.
+func newGrantStore(userdb user.Database, path string) (*grantStore, error) {
+	gs := grantStore{
+		userdb: userdb,
+	}
+	err := gs.importFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("import %v: %v", path, err)
+	}
+	return &gs, nil
+}
+
+// legacyGrant is a grant of the legacy grants file.
+type legacyGrant struct {
+	Scopes      []string `json:"scopes"`
+	Timestamp   int64    `json:"timestamp"`
+	RefreshHash string   `json:"refreshhash,omitempty"`
+}
+
+// importFile imports the grants of the legacy grants file into the user
+// database. The refresh tokens of the grants remain valid.
+func (gs *grantStore) importFile(path string) error {
+	b, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return nil
+	case err != nil:
+		return err
+	}
+	var grants map[string]map[string]legacyGrant // [userID][clientID]
+	err = json.Unmarshal(b, &grants)
+	if err != nil {
+		return err
+	}
+	var count int
+	for userID, ug := range grants {
+		uid, err := uuid.Parse(userID)
+		if err != nil {
+			return fmt.Errorf("user %v: %v", userID, err)
+		}
+		for clientID, g := range ug {
+			if g.RefreshHash != "" {
+				err = gs.userdb.OAuthTokenSave(user.OAuthToken{
+					Hash:     g.RefreshHash,
+					UserID:   uid,
+					ClientID: clientID,
+					Issued:   g.Timestamp,
+				})
+				if err != nil {
+					return err
+				}
+			}
+			err = gs.userdb.OAuthGrantSave(user.OAuthGrant{
+				UserID:      uid,
+				ClientID:    clientID,
+				Scopes:      g.Scopes,
+				Timestamp:   g.Timestamp,
+				RefreshHash: g.RefreshHash,
+			})
+			if err != nil {
+				return err
+			}
+			count++
+		}
+	}
+
+	log.Infof("Imported %v OAuth grants from %v", count, path)
+
+	return os.Rename(path, path+".imported")
+}
+
+// get returns the grant that a user has given to an application. An
+// errGrantNotFound is returned if the grant does not exist.
+func (gs *grantStore) get(userID uuid.UUID, clientID string) (*user.OAuthGrant, error) {
+	grants, err := gs.userdb.OAuthGrantsGetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range grants {
+		if v.ClientID == clientID {
+			return &v, nil
+		}
+	}
+	return nil, errGrantNotFound
+}
+
+// user returns all grants of a user.
+func (gs *grantStore) user(userID uuid.UUID) ([]user.OAuthGrant, error) {
+	return gs.userdb.OAuthGrantsGetByUserID(userID)
+}
+
+// consent records the consent of a user to give an application access to the
+// provided scopes. The scopes replace the scopes of an existing grant. The
+// refresh token of an existing grant remains valid.
+func (gs *grantStore) consent(userID uuid.UUID, clientID string, scopes []string) error {
+	gs.Lock()
+	defer gs.Unlock()
+
+	g, err := gs.get(userID, clientID)
+	switch {
+	case errors.Is(err, errGrantNotFound):
+		g = &user.OAuthGrant{
+			UserID:   userID,
+			ClientID: clientID,
+		}
+	case err != nil:
+		return err
+	}
+	g.Scopes = scopes
+	g.Timestamp = time.Now().Unix()
+
+	return gs.userdb.OAuthGrantSave(*g)
+}
+
+// replaceRefresh replaces the refresh token of a grant with the refresh token
+// that has the provided hash.
+//
+// This function must be called WITH the lock held.
+func (gs *grantStore) replaceRefresh(g *user.OAuthGrant, refreshHash string) error {
+	err := gs.userdb.OAuthTokenSave(user.OAuthToken{
+		Hash:     refreshHash,
+		UserID:   g.UserID,
+		ClientID: g.ClientID,
+		Issued:   time.Now().Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	prevHash := g.RefreshHash
+	g.RefreshHash = refreshHash
+	err = gs.userdb.OAuthGrantSave(*g)
+	if err != nil {
+		return err
+	}
+	if prevHash == "" {
+		return nil
+	}
+	return gs.userdb.OAuthTokenDeleteByHash(prevHash)
+}
+
+// setRefresh replaces the refresh token hash of a grant. An errGrantNotFound
+// is returned if the grant does not exist, i.e. it has been revoked.
+func (gs *grantStore) setRefresh(userID uuid.UUID, clientID, refreshHash string) error {
+	gs.Lock()
+	defer gs.Unlock()
+
+	g, err := gs.get(userID, clientID)
+	if err != nil {
+		return err
+	}
+	return gs.replaceRefresh(g, refreshHash)
+}
+
+// refreshGrant returns the grant that the refresh token with the provided
+// hash belongs to. An errGrantNotFound is returned if the refresh token does
+// not belong to the application or if it has been replaced.
+func (gs *grantStore) refreshGrant(clientID, refreshHash string) (*user.OAuthGrant, error) {
+	t, err := gs.userdb.OAuthTokenGetByHash(refreshHash)
+	switch {
+	case errors.Is(err, user.ErrOAuthTokenNotFound):
+		return nil, errGrantNotFound
+	case err != nil:
+		return nil, err
+	case t.Expires != 0 || t.ClientID != clientID:
+		// Not a refresh token of the application
+		return nil, errGrantNotFound
+	}
+	g, err := gs.get(t.UserID, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if g.RefreshHash != refreshHash {
+		return nil, errGrantNotFound
+	}
+	return g, nil
+}
+
+// rotateRefresh replaces the refresh token that has the provided hash with a
+// new refresh token hash. It returns the grant that the refresh token belongs
+// to. An errGrantNotFound is returned if the refresh token does not belong to
+// the application.
+func (gs *grantStore) rotateRefresh(clientID, refreshHash, newHash string) (*user.OAuthGrant, error) {
+	gs.Lock()
+	defer gs.Unlock()
+
+	g, err := gs.refreshGrant(clientID, refreshHash)
+	if err != nil {
+		return nil, err
+	}
+	err = gs.replaceRefresh(g, newHash)
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// revoke deletes the grant that a user has given to an application along
+// with the tokens that have been issued for the grant.
+func (gs *grantStore) revoke(userID uuid.UUID, clientID string) error {
+	gs.Lock()
+	defer gs.Unlock()
+
+	_, err := gs.get(userID, clientID)
+	if err != nil {
+		return err
+	}
+	return gs.userdb.OAuthGrantDelete(userID, clientID)
+}
+
+// revokeClient deletes all grants that users have given to an application
+// along with the tokens that have been issued for the grants.
+func (gs *grantStore) revokeClient(clientID string) error {
+	gs.Lock()
+	defer gs.Unlock()
+
+	return gs.userdb.OAuthGrantsDeleteByClientID(clientID)
+}
+
+// addAccessToken adds an access token. The expired access tokens are deleted
+// once every tokenPruneInterval.
+func (gs *grantStore) addAccessToken(t user.OAuthToken) error {
+	gs.Lock()
+	prune := time.Since(gs.pruned) > tokenPruneInterval
+	if prune {
+		gs.pruned = time.Now()
+	}
+	gs.Unlock()
+
+	if prune {
+		err := gs.userdb.OAuthTokensDeleteExpired(time.Now().Unix())
+		if err != nil {
+			log.Errorf("OAuthTokensDeleteExpired: %v", err)
+		}
+	}
+
+	return gs.userdb.OAuthTokenSave(t)
+}
+
+// accessToken returns the access token with the provided hash. Only unexpired
+// access tokens are returned. An errTokenNotFound is returned if the access
+// token does not exist.
+func (gs *grantStore) accessToken(hash string) (*user.OAuthToken, error) {
+	t, err := gs.userdb.OAuthTokenGetByHash(hash)
+	switch {
+	case errors.Is(err, user.ErrOAuthTokenNotFound):
+		return nil, errTokenNotFound
+	case err != nil:
+		return nil, err
+	case t.Expires == 0:
+		// Refresh token
+		return nil, errTokenNotFound
+	case time.Now().Unix() >= t.Expires:
+		return nil, errTokenNotFound
+	}
+	return t, nil
+}
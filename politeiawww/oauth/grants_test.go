@@ -0,0 +1,138 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package oauth
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/user"
+)
+
+func TestGrantStoreImport(t *testing.T) {
+	o, u, cleanup := newTestOAuth(t, "")
+	defer cleanup()
+
+	// Write a legacy grants file
+	refreshHash := hashHex("refresh")
+	path := filepath.Join(o.cfg.DataDir, grantsFilename)
+	b := []byte(fmt.Sprintf(`{"%v":{"%v":{"scopes":["profile"],`+
+		`"timestamp":1,"refreshhash":"%v"}}}`, u.ID, testClientID,
+		refreshHash))
+	err := ioutil.WriteFile(path, b, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Import the file. The file must be renamed and the refresh token
+	// must remain valid.
+	gs, err := newGrantStore(o.userdb, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("grants file was not renamed: %v", err)
+	}
+	if _, err := os.Stat(path + ".imported"); err != nil {
+		t.Fatal(err)
+	}
+	g, err := gs.refreshGrant(testClientID, refreshHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.UserID != u.ID || len(g.Scopes) != 1 || g.Scopes[0] != "profile" {
+		t.Fatalf("got grant %+v", g)
+	}
+
+	// The refresh token is not valid for other applications
+	_, err = gs.refreshGrant("other", refreshHash)
+	if !errors.Is(err, errGrantNotFound) {
+		t.Fatalf("got %v, want %v", err, errGrantNotFound)
+	}
+}
+
+func TestGrantStoreAccessToken(t *testing.T) {
+	o, u, cleanup := newTestOAuth(t, "")
+	defer cleanup()
+	gs := o.grants
+
+	// Add an expired and an unexpired access token. The expired token
+	// must not be returned.
+	now := time.Now()
+	expired := user.OAuthToken{
+		Hash:     hashHex("expired"),
+		UserID:   u.ID,
+		ClientID: testClientID,
+		Issued:   now.Add(-2 * accessTokenExpiry).Unix(),
+		Expires:  now.Add(-accessTokenExpiry).Unix(),
+	}
+	valid := user.OAuthToken{
+		Hash:     hashHex("valid"),
+		UserID:   u.ID,
+		ClientID: testClientID,
+		Scopes:   []string{"profile"},
+		Issued:   now.Unix(),
+		Expires:  now.Add(accessTokenExpiry).Unix(),
+	}
+	for _, v := range []user.OAuthToken{expired, valid} {
+		err := gs.addAccessToken(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := gs.accessToken(expired.Hash)
+	if !errors.Is(err, errTokenNotFound) {
+		t.Fatalf("expired: got %v, want %v", err, errTokenNotFound)
+	}
+	at, err := gs.accessToken(valid.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if at.UserID != u.ID || at.ClientID != testClientID {
+		t.Fatalf("got token %+v", at)
+	}
+
+	// A refresh token is not an access token
+	err = gs.consent(u.ID, testClientID, []string{"profile"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = gs.setRefresh(u.ID, testClientID, hashHex("refresh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = gs.accessToken(hashHex("refresh"))
+	if !errors.Is(err, errTokenNotFound) {
+		t.Fatalf("refresh: got %v, want %v", err, errTokenNotFound)
+	}
+
+	// Prune the expired tokens on the next add
+	gs.pruned = time.Time{}
+	err = gs.addAccessToken(user.OAuthToken{
+		Hash:     hashHex("another"),
+		UserID:   u.ID,
+		ClientID: testClientID,
+		Issued:   now.Unix(),
+		Expires:  now.Add(accessTokenExpiry).Unix(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = o.userdb.OAuthTokenGetByHash(expired.Hash)
+	if !errors.Is(err, user.ErrOAuthTokenNotFound) {
+		t.Fatalf("got %v, want %v", err, user.ErrOAuthTokenNotFound)
+	}
+	for _, v := range []string{valid.Hash, hashHex("refresh")} {
+		_, err = o.userdb.OAuthTokenGetByHash(v)
+		if err != nil {
+			t.Fatalf("token pruned: %v", err)
+		}
+	}
+}
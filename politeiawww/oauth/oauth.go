@@ -0,0 +1,546 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package oauth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pdclient "github.com/decred/politeia/politeiad/client"
+	v1 "github.com/decred/politeia/politeiawww/api/oauth/v1"
+	"github.com/decred/politeia/politeiawww/config"
+	"github.com/decred/politeia/politeiawww/sessions"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
+	"github.com/google/uuid"
+)
+
+const (
+	// codeExpiry is the duration that an authorization code is valid
+	// for.
+	codeExpiry = time.Minute
+
+	// accessTokenExpiry is the duration that an access token is valid
+	// for.
+	accessTokenExpiry = time.Hour
+
+	// stateLengthMax is the maximum length of the state that an
+	// application can provide.
+	stateLengthMax = 512
+)
+
+// client is a third-party application that users can authorize.
+type client struct {
+	id          string
+	redirectURI string
+	name        string
+
+	// secretHash is the SHA256 digest of the client secret. It is nil
+	// for public applications, which must use PKCE instead.
+	secretHash []byte
+}
+
+// authCode is an authorization code that has not been exchanged yet.
+type authCode struct {
+	clientID      string
+	userID        uuid.UUID
+	redirectURI   string
+	scopes        []string
+	nonce         string
+	codeChallenge string
+	expires       time.Time
+}
+
+// OAuth is the context for the OAuth API. It allows third-party applications
+// to access a limited set of user data once the user has given its consent.
+//
+// Authorization codes are short lived and are only kept in memory. The grants
+// along with the access tokens and refresh tokens are persisted to the user
+// database. The applications that have been registered using the API are
+// persisted to the data directory. Only hashes of the codes, tokens, and
+// secrets are stored.
+//
+// When an issuer is configured, the server also acts as an OpenID Connect
+// identity provider. Applications can then use the openid scope to sign
+// users in using their politeia account.
+type OAuth struct {
+	sync.Mutex
+	cfg        *config.Config
+	politeiad  *pdclient.Client
+	userdb     user.Database
+	sessions   *sessions.Sessions
+	clients    map[string]client // [clientID]client; from the config
+	registered *clientStore
+	grants     *grantStore
+	scopes     map[string]string // [scope]description; available scopes
+
+	// The following fields are only set when the server is configured
+	// as an OpenID Connect identity provider.
+	issuer string
+	key    *signingKey
+
+	// The following fields are protected by the mutex.
+	codes map[string]authCode // [codeHash]authCode
+}
+
+// HandleClient is the request handler for the oauth v1 Client route.
+func (o *OAuth) HandleClient(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleClient")
+
+	var c v1.Client
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&c); err != nil {
+		respondWithError(w, r, "HandleClient: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	// Lookup session user. This is a public route so a session may not
+	// exist. Ignore any session not found errors.
+	u, err := o.sessions.GetSessionUser(w, r)
+	if err != nil && err != sessions.ErrSessionNotFound {
+		respondWithError(w, r,
+			"HandleClient: GetSessionUser: %v", err)
+		return
+	}
+
+	cr, err := o.processClient(c, u)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleClient: processClient: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, cr)
+}
+
+// HandleAuthorize is the request handler for the oauth v1 Authorize route.
+func (o *OAuth) HandleAuthorize(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleAuthorize")
+
+	var a v1.Authorize
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&a); err != nil {
+		respondWithError(w, r, "HandleAuthorize: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	u, err := o.sessions.GetSessionUser(w, r)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleAuthorize: GetSessionUser: %v", err)
+		return
+	}
+
+	ar, err := o.processAuthorize(a, *u)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleAuthorize: processAuthorize: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, ar)
+}
+
+// HandleToken is the request handler for the oauth v1 Token route. The
+// request is form encoded and the client credentials can be provided using
+// either HTTP basic authentication or the form parameters, as described in
+// RFC 6749.
+func (o *OAuth) HandleToken(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleToken")
+
+	if err := r.ParseForm(); err != nil {
+		respondWithOAuthError(w, r, http.StatusBadRequest,
+			v1.ErrorInvalidRequest, "invalid form")
+		return
+	}
+	clientID, secret, ok := r.BasicAuth()
+	if !ok {
+		clientID = r.PostForm.Get("client_id")
+		secret = r.PostForm.Get("client_secret")
+	}
+
+	tr, err := o.processToken(clientID, secret, r.PostForm)
+	if err != nil {
+		var te tokenError
+		if errors.As(err, &te) {
+			respondWithOAuthError(w, r, te.statusCode,
+				te.code, te.description)
+			return
+		}
+		respondWithError(w, r,
+			"HandleToken: processToken: %v", err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	util.RespondWithJSON(w, http.StatusOK, tr)
+}
+
+// HandleMe is the request handler for the oauth v1 Me route.
+func (o *OAuth) HandleMe(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleMe")
+
+	u, _, ok := o.bearerUser(w, r, v1.ScopeProfile)
+	if !ok {
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK,
+		v1.MeReply{
+			UserID:   u.ID.String(),
+			Username: u.Username,
+		})
+}
+
+// HandleProposals is the request handler for the oauth v1 Proposals route.
+func (o *OAuth) HandleProposals(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleProposals")
+
+	u, _, ok := o.bearerUser(w, r, v1.ScopeProposals)
+	if !ok {
+		return
+	}
+
+	ur, err := o.politeiad.UserRecords(r.Context(), u.ID.String())
+	if err != nil {
+		respondWithError(w, r,
+			"HandleProposals: UserRecords: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK,
+		v1.ProposalsReply{
+			Unvetted: ur.Unvetted,
+			Vetted:   ur.Vetted,
+		})
+}
+
+// HandleGrants is the request handler for the oauth v1 Grants route.
+func (o *OAuth) HandleGrants(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleGrants")
+
+	u, err := o.sessions.GetSessionUser(w, r)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleGrants: GetSessionUser: %v", err)
+		return
+	}
+
+	gr, err := o.processGrants(*u)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleGrants: processGrants: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, gr)
+}
+
+// HandleRevoke is the request handler for the oauth v1 Revoke route.
+func (o *OAuth) HandleRevoke(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleRevoke")
+
+	var rv v1.Revoke
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&rv); err != nil {
+		respondWithError(w, r, "HandleRevoke: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	u, err := o.sessions.GetSessionUser(w, r)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleRevoke: GetSessionUser: %v", err)
+		return
+	}
+
+	rr, err := o.processRevoke(rv, *u)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleRevoke: processRevoke: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, rr)
+}
+
+// HandleIntrospect is the request handler for the oauth v1 Introspect route.
+// The request is form encoded and the application must authenticate using
+// the same client credentials that it uses for the token endpoint.
+func (o *OAuth) HandleIntrospect(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleIntrospect")
+
+	if err := r.ParseForm(); err != nil {
+		respondWithOAuthError(w, r, http.StatusBadRequest,
+			v1.ErrorInvalidRequest, "invalid form")
+		return
+	}
+	clientID, secret, ok := r.BasicAuth()
+	if !ok {
+		clientID = r.PostForm.Get("client_id")
+		secret = r.PostForm.Get("client_secret")
+	}
+
+	ir, err := o.processIntrospect(clientID, secret, r.PostForm)
+	if err != nil {
+		var te tokenError
+		if errors.As(err, &te) {
+			respondWithOAuthError(w, r, te.statusCode,
+				te.code, te.description)
+			return
+		}
+		respondWithError(w, r,
+			"HandleIntrospect: processIntrospect: %v", err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	util.RespondWithJSON(w, http.StatusOK, ir)
+}
+
+// HandleUserInfo is the request handler for the oauth v1 UserInfo route.
+func (o *OAuth) HandleUserInfo(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleUserInfo")
+
+	u, at, ok := o.bearerUser(w, r, v1.ScopeOpenID)
+	if !ok {
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, userInfo(*u, at.Scopes))
+}
+
+// HandleJWKS is the request handler for the oauth v1 JWKS route.
+func (o *OAuth) HandleJWKS(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleJWKS")
+
+	util.RespondWithJSON(w, http.StatusOK,
+		v1.JWKSReply{
+			Keys: []v1.JWK{o.key.jwk()},
+		})
+}
+
+// HandleDiscovery is the request handler for the OpenID Connect discovery
+// document.
+func (o *OAuth) HandleDiscovery(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleDiscovery")
+
+	util.RespondWithJSON(w, http.StatusOK, o.discovery())
+}
+
+// HandleClients is the request handler for the oauth v1 Clients route.
+func (o *OAuth) HandleClients(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleClients")
+
+	util.RespondWithJSON(w, http.StatusOK, o.processClients())
+}
+
+// HandleRegisterClient is the request handler for the oauth v1
+// RegisterClient route.
+func (o *OAuth) HandleRegisterClient(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleRegisterClient")
+
+	var rc v1.RegisterClient
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&rc); err != nil {
+		respondWithError(w, r, "HandleRegisterClient: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	u, err := o.sessions.GetSessionUser(w, r)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleRegisterClient: GetSessionUser: %v", err)
+		return
+	}
+
+	rcr, err := o.processRegisterClient(rc, *u)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleRegisterClient: processRegisterClient: %v", err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	util.RespondWithJSON(w, http.StatusOK, rcr)
+}
+
+// HandleDeleteClient is the request handler for the oauth v1 DeleteClient
+// route.
+func (o *OAuth) HandleDeleteClient(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleDeleteClient")
+
+	var dc v1.DeleteClient
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&dc); err != nil {
+		respondWithError(w, r, "HandleDeleteClient: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	u, err := o.sessions.GetSessionUser(w, r)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleDeleteClient: GetSessionUser: %v", err)
+		return
+	}
+
+	dcr, err := o.processDeleteClient(dc, *u)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleDeleteClient: processDeleteClient: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, dcr)
+}
+
+// bearerUser returns the user that authorized the access token of the
+// request along with the access token. The access token must have been
+// granted the provided scope. An OAuth2 error is sent and false is returned
+// if the request is not authorized.
+func (o *OAuth) bearerUser(w http.ResponseWriter, r *http.Request, scope string) (*user.User, *user.OAuthToken, bool) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		respondWithOAuthError(w, r, http.StatusUnauthorized,
+			v1.ErrorInvalidToken, "missing access token")
+		return nil, nil, false
+	}
+	at, err := o.grants.accessToken(hashHex(strings.TrimPrefix(auth, "Bearer ")))
+	switch {
+	case errors.Is(err, errTokenNotFound):
+		respondWithOAuthError(w, r, http.StatusUnauthorized,
+			v1.ErrorInvalidToken, "access token invalid or expired")
+		return nil, nil, false
+	case err != nil:
+		respondWithError(w, r, "bearerUser: accessToken: %v", err)
+		return nil, nil, false
+	}
+	_, err = o.grants.get(at.UserID, at.ClientID)
+	switch {
+	case errors.Is(err, errGrantNotFound):
+		// The grant has been revoked while the access token was
+		// being issued.
+		respondWithOAuthError(w, r, http.StatusUnauthorized,
+			v1.ErrorInvalidToken, "access token revoked")
+		return nil, nil, false
+	case err != nil:
+		respondWithError(w, r, "bearerUser: grant: %v", err)
+		return nil, nil, false
+	}
+	if !hasScope(at.Scopes, scope) {
+		respondWithOAuthError(w, r, http.StatusForbidden,
+			v1.ErrorInsufficientScope,
+			fmt.Sprintf("scope %v is required", scope))
+		return nil, nil, false
+	}
+	u, err := o.userdb.UserGetById(at.UserID)
+	if err != nil {
+		respondWithError(w, r, "bearerUser: user: %v", err)
+		return nil, nil, false
+	}
+	if u.Deactivated {
+		respondWithOAuthError(w, r, http.StatusUnauthorized,
+			v1.ErrorInvalidToken, "user deactivated")
+		return nil, nil, false
+	}
+	return u, at, true
+}
+
+// New returns a new OAuth context. An error is returned if the configured
+// applications or the configured issuer are invalid.
+func New(cfg *config.Config, pdc *pdclient.Client, udb user.Database, s *sessions.Sessions) (*OAuth, error) {
+	clients := make(map[string]client, len(cfg.OAuthClients))
+	for _, v := range cfg.OAuthClients {
+		c, err := parseClient(v)
+		if err != nil {
+			return nil, fmt.Errorf("oauthclient %v: %v", v, err)
+		}
+		if _, ok := clients[c.id]; ok {
+			return nil, fmt.Errorf("duplicate oauthclient %v", c.id)
+		}
+		clients[c.id] = *c
+	}
+	ids := make([]string, 0, len(clients))
+	for k := range clients {
+		ids = append(ids, k)
+	}
+	sort.Strings(ids)
+	log.Debugf("OAuth clients: %v", ids)
+
+	cs, err := newClientStore(filepath.Join(cfg.DataDir, clientsFilename))
+	if err != nil {
+		return nil, err
+	}
+	for k := range cs.all() {
+		if _, ok := clients[k]; ok {
+			return nil, fmt.Errorf("oauthclient %v is also a registered "+
+				"client", k)
+		}
+	}
+	gs, err := newGrantStore(udb, filepath.Join(cfg.DataDir, grantsFilename))
+	if err != nil {
+		return nil, err
+	}
+
+	// Setup the OpenID Connect identity provider
+	var (
+		issuer string
+		key    *signingKey
+		scopes = make(map[string]string, len(v1.Scopes))
+	)
+	if cfg.OAuthIssuer != "" {
+		issuer, err = parseIssuer(cfg.OAuthIssuer)
+		if err != nil {
+			return nil, fmt.Errorf("oauthissuer: %v", err)
+		}
+		key, err = loadSigningKey(filepath.Join(cfg.DataDir, keyFilename))
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("OpenID Connect issuer: %v", issuer)
+	}
+	for k, v := range v1.Scopes {
+		if _, ok := oidcScopes[k]; ok && key == nil {
+			continue
+		}
+		scopes[k] = v
+	}
+
+	return &OAuth{
+		cfg:        cfg,
+		politeiad:  pdc,
+		userdb:     udb,
+		sessions:   s,
+		clients:    clients,
+		registered: cs,
+		grants:     gs,
+		scopes:     scopes,
+		issuer:     issuer,
+		key:        key,
+		codes:      make(map[string]authCode),
+	}, nil
+}
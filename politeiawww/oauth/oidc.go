@@ -0,0 +1,243 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package oauth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "github.com/decred/politeia/politeiawww/api/oauth/v1"
+	"github.com/decred/politeia/politeiawww/user"
+)
+
+const (
+	// keyFilename is the name of the file in the data directory that
+	// the ID token signing key is stored in.
+	keyFilename = "oauthkey.pem"
+
+	// keyBits is the size of the ID token signing key.
+	keyBits = 2048
+
+	// idTokenExpiry is the duration that an ID token is valid for.
+	idTokenExpiry = time.Hour
+
+	// nonceLengthMax is the maximum length of the nonce that an
+	// application can provide.
+	nonceLengthMax = 512
+)
+
+// oidcScopes are the scopes that are only available when the server is
+// configured as an OpenID Connect identity provider.
+var oidcScopes = map[string]struct{}{
+	v1.ScopeOpenID: {},
+	v1.ScopeEmail:  {},
+}
+
+// signingKey is the RSA key that is used to sign the ID tokens.
+type signingKey struct {
+	key *rsa.PrivateKey
+	id  string
+}
+
+// loadSigningKey loads the ID token signing key from the provided PEM file.
+// A new key is created if the file does not exist.
+func loadSigningKey(path string) (*signingKey, error) {
+	var key *rsa.PrivateKey
+	b, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		log.Infof("Creating OAuth signing key %v", path)
+		key, err = rsa.GenerateKey(rand.Reader, keyBits)
+		if err != nil {
+			return nil, err
+		}
+		b = pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(key),
+		})
+		err = ioutil.WriteFile(path, b, 0600)
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		block, _ := pem.Decode(b)
+		if block == nil || block.Type != "RSA PRIVATE KEY" {
+			return nil, fmt.Errorf("%v: invalid pem block", path)
+		}
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", path, err)
+		}
+	}
+
+	// The key ID is derived from the public key so that it changes
+	// when the key is replaced.
+	h := sha256.Sum256(x509.MarshalPKCS1PublicKey(&key.PublicKey))
+	return &signingKey{
+		key: key,
+		id:  base64.RawURLEncoding.EncodeToString(h[:8]),
+	}, nil
+}
+
+// jwk returns the public key in the JSON Web Key format.
+func (k *signingKey) jwk() v1.JWK {
+	e := big.NewInt(int64(k.key.PublicKey.E)).Bytes()
+	return v1.JWK{
+		KeyType:   "RSA",
+		Use:       "sig",
+		KeyID:     k.id,
+		Algorithm: v1.SigningAlgRS256,
+		N:         base64.RawURLEncoding.EncodeToString(k.key.PublicKey.N.Bytes()),
+		E:         base64.RawURLEncoding.EncodeToString(e),
+	}
+}
+
+// sign returns a JWT that contains the provided claims and that is signed
+// using RS256. See RFC 7515 and RFC 7519.
+func (k *signingKey) sign(claims interface{}) (string, error) {
+	header, err := json.Marshal(struct {
+		Alg string `json:"alg"`
+		Typ string `json:"typ"`
+		Kid string `json:"kid"`
+	}{
+		Alg: v1.SigningAlgRS256,
+		Typ: "JWT",
+		Kid: k.id,
+	})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." +
+		base64.RawURLEncoding.EncodeToString(payload)
+
+	h := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, k.key, crypto.SHA256, h[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// parseIssuer parses the oauthissuer config setting. The issuer must be an
+// https URL, or an http URL on localhost, without a query or a fragment. The
+// trailing slash is removed.
+func parseIssuer(issuer string) (string, error) {
+	if !redirectURIIsValid(issuer) {
+		return "", fmt.Errorf("issuer must be an https url or an http " +
+			"url on localhost")
+	}
+	u, err := url.Parse(issuer)
+	if err != nil {
+		return "", err
+	}
+	if u.RawQuery != "" {
+		return "", fmt.Errorf("issuer must not contain a query")
+	}
+	return strings.TrimSuffix(issuer, "/"), nil
+}
+
+// oidcEnabled returns whether the server is configured as an OpenID Connect
+// identity provider.
+func (o *OAuth) oidcEnabled() bool {
+	return o.key != nil
+}
+
+// idToken returns a new ID token for a user. An empty string is returned if
+// the scopes do not include the ScopeOpenID scope.
+func (o *OAuth) idToken(clientID string, u user.User, scopes []string, nonce string) (string, error) {
+	if !o.oidcEnabled() || !hasScope(scopes, v1.ScopeOpenID) {
+		return "", nil
+	}
+	now := time.Now()
+	c := v1.IDTokenClaims{
+		Issuer:            o.issuer,
+		Subject:           u.ID.String(),
+		Audience:          clientID,
+		Expires:           now.Add(idTokenExpiry).Unix(),
+		IssuedAt:          now.Unix(),
+		Nonce:             nonce,
+		PreferredUsername: u.Username,
+	}
+	if hasScope(scopes, v1.ScopeEmail) {
+		c.Email = u.Email
+		c.EmailVerified = u.NewUserVerificationToken == nil
+	}
+	return o.key.sign(c)
+}
+
+// userInfo returns the OpenID Connect claims of a user.
+func userInfo(u user.User, scopes []string) v1.UserInfoReply {
+	ui := v1.UserInfoReply{
+		Subject:           u.ID.String(),
+		PreferredUsername: u.Username,
+	}
+	if hasScope(scopes, v1.ScopeEmail) {
+		ui.Email = u.Email
+		ui.EmailVerified = u.NewUserVerificationToken == nil
+	}
+	return ui
+}
+
+// discovery returns the OpenID Connect discovery document.
+func (o *OAuth) discovery() v1.DiscoveryReply {
+	scopes := make([]string, 0, len(o.scopes))
+	for k := range o.scopes {
+		scopes = append(scopes, k)
+	}
+	sort.Strings(scopes)
+
+	api := o.issuer + v1.APIRoute
+	return v1.DiscoveryReply{
+		Issuer:                 o.issuer,
+		AuthorizationEndpoint:  o.issuer + v1.ConsentPath,
+		TokenEndpoint:          api + v1.RouteToken,
+		UserInfoEndpoint:       api + v1.RouteUserInfo,
+		JWKSURI:                api + v1.RouteJWKS,
+		IntrospectionEndpoint:  api + v1.RouteIntrospect,
+		ScopesSupported:        scopes,
+		ResponseTypesSupported: []string{"code"},
+		GrantTypesSupported: []string{
+			v1.GrantTypeAuthorizationCode,
+			v1.GrantTypeRefreshToken,
+		},
+		SubjectTypesSupported: []string{"public"},
+		IDTokenSigningAlgValuesSupported: []string{
+			v1.SigningAlgRS256,
+		},
+		TokenEndpointAuthMethodsSupported: []string{
+			"client_secret_basic",
+			"client_secret_post",
+			"none",
+		},
+		CodeChallengeMethodsSupported: []string{
+			v1.CodeChallengeMethodS256,
+		},
+		ClaimsSupported: []string{
+			"iss", "sub", "aud", "exp", "iat", "nonce",
+			"preferred_username", "email", "email_verified",
+		},
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package oauth
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "github.com/decred/politeia/politeiawww/api/oauth/v1"
+)
+
+// verifyIDToken verifies the signature of an ID token using the provided
+// JSON Web Key and returns the claims of the token.
+func verifyIDToken(t *testing.T, jwk v1.JWK, token string) v1.IDTokenClaims {
+	t.Helper()
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("got %v token parts, want 3", len(parts))
+	}
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk := rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}
+	h := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	err = rsa.VerifyPKCS1v15(&pk, crypto.SHA256, h[:], sig)
+	if err != nil {
+		t.Fatalf("invalid signature: %v", err)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c v1.IDTokenClaims
+	err = json.Unmarshal(payload, &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestOpenIDConnect(t *testing.T) {
+	o, u, cleanup := newTestOAuth(t, "https://politeia.example.com/")
+	defer cleanup()
+
+	if o.issuer != "https://politeia.example.com" {
+		t.Fatalf("got issuer %v", o.issuer)
+	}
+	d := o.discovery()
+	if d.Issuer != o.issuer ||
+		d.TokenEndpoint != o.issuer+v1.APIRoute+v1.RouteToken {
+		t.Fatalf("got discovery %+v", d)
+	}
+
+	// Sign the user in
+	code := authorize(t, o, u, v1.Authorize{
+		ClientID:    testClientID,
+		RedirectURI: testRedirectURI,
+		Scope:       v1.ScopeOpenID + " " + v1.ScopeEmail,
+		Nonce:       "nonce",
+	})
+	tr, err := o.processToken(testClientID, testClientSecret, url.Values{
+		"grant_type":   []string{v1.GrantTypeAuthorizationCode},
+		"code":         []string{code},
+		"redirect_uri": []string{testRedirectURI},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := verifyIDToken(t, o.key.jwk(), tr.IDToken)
+	if c.Issuer != o.issuer || c.Subject != u.ID.String() ||
+		c.Audience != testClientID || c.Nonce != "nonce" ||
+		c.Email != u.Email || c.PreferredUsername != u.Username {
+		t.Fatalf("got claims %+v", c)
+	}
+
+	// The ID token of a refresh does not contain the nonce
+	tr, err = o.processToken(testClientID, testClientSecret, url.Values{
+		"grant_type":    []string{v1.GrantTypeRefreshToken},
+		"refresh_token": []string{tr.RefreshToken},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = verifyIDToken(t, o.key.jwk(), tr.IDToken)
+	if c.Nonce != "" || c.Subject != u.ID.String() {
+		t.Fatalf("got claims %+v", c)
+	}
+
+	// The email claims require the email scope
+	ui := userInfo(u, []string{v1.ScopeOpenID})
+	if ui.Subject != u.ID.String() || ui.Email != "" {
+		t.Fatalf("got user info %+v", ui)
+	}
+
+	// The signing key is reused on a restart
+	key, err := loadSigningKey(filepath.Join(o.cfg.DataDir, keyFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.id != o.key.id {
+		t.Fatalf("got key id %v, want %v", key.id, o.key.id)
+	}
+}
@@ -0,0 +1,815 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package oauth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "github.com/decred/politeia/politeiawww/api/oauth/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
+	"github.com/google/uuid"
+)
+
+const (
+	// clientIDSize is the size in bytes of the client ID of a
+	// registered application.
+	clientIDSize = 16
+
+	// clientNameLengthMax is the maximum length of the name of a
+	// registered application.
+	clientNameLengthMax = 64
+)
+
+var (
+	// errGrantNotFound is returned when a user has not authorized an
+	// application or when a refresh token does not match any grant.
+	errGrantNotFound = errors.New("grant not found")
+
+	// errClientNotFound is returned when a registered application does
+	// not exist.
+	errClientNotFound = errors.New("client not found")
+
+	// errTokenNotFound is returned when an access token does not exist
+	// or has expired.
+	errTokenNotFound = errors.New("token not found")
+)
+
+// tokenError is an error that is returned to the application in the OAuth2
+// error format. It is used by the token endpoint.
+type tokenError struct {
+	statusCode  int
+	code        string
+	description string
+}
+
+// Error satisfies the error interface.
+func (e tokenError) Error() string {
+	return fmt.Sprintf("%v: %v", e.code, e.description)
+}
+
+// parseClient parses an oauthclient config setting. The format is
+// clientid,redirecturi,name[,secret].
+func parseClient(s string) (*client, error) {
+	fields := strings.Split(s, ",")
+	if len(fields) != 3 && len(fields) != 4 {
+		return nil, fmt.Errorf("format must be " +
+			"clientid,redirecturi,name[,secret]")
+	}
+	c := client{
+		id:          strings.TrimSpace(fields[0]),
+		redirectURI: strings.TrimSpace(fields[1]),
+		name:        strings.TrimSpace(fields[2]),
+	}
+	if c.id == "" || c.name == "" {
+		return nil, fmt.Errorf("client id and name must be set")
+	}
+	if !redirectURIIsValid(c.redirectURI) {
+		return nil, fmt.Errorf("redirect uri must be an https url or " +
+			"an http url on localhost")
+	}
+	if len(fields) == 4 {
+		secret := strings.TrimSpace(fields[3])
+		if secret == "" {
+			return nil, fmt.Errorf("secret is empty")
+		}
+		c.secretHash = hash(secret)
+	}
+	return &c, nil
+}
+
+// redirectURIIsValid returns whether a redirect URI can be registered. Plain
+// http is only allowed for native applications that listen on the loopback
+// interface. Fragments are not allowed. See RFC 6749 section 3.1.2 and RFC
+// 8252 section 7.3.
+func redirectURIIsValid(redirectURI string) bool {
+	u, err := url.Parse(redirectURI)
+	if err != nil || u.Host == "" || u.Fragment != "" {
+		return false
+	}
+	switch u.Scheme {
+	case "https":
+		return true
+	case "http":
+		host := u.Hostname()
+		if host == "localhost" {
+			return true
+		}
+		ip := net.ParseIP(host)
+		return ip != nil && ip.IsLoopback()
+	}
+	return false
+}
+
+// parseScopes parses a space separated list of scopes. Only the provided
+// available scopes are allowed. The returned scopes are sorted and do not
+// contain duplicates.
+func parseScopes(s string, available map[string]string) ([]string, error) {
+	seen := make(map[string]struct{}, len(available))
+	scopes := make([]string, 0, len(available))
+	for _, v := range strings.Fields(s) {
+		if _, ok := available[v]; !ok {
+			return nil, fmt.Errorf("unknown scope %v", v)
+		}
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		scopes = append(scopes, v)
+	}
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("no scopes")
+	}
+	sort.Strings(scopes)
+	return scopes, nil
+}
+
+// hasScope returns whether the scopes contain the provided scope.
+func hasScope(scopes []string, scope string) bool {
+	for _, v := range scopes {
+		if v == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// hash returns the SHA256 digest of a secret, code, or token.
+func hash(s string) []byte {
+	h := sha256.Sum256([]byte(s))
+	return h[:]
+}
+
+// hashHex returns the hex encoded SHA256 digest of a code or token. It is
+// used as the map key of the codes and tokens so that the plain text values
+// are never stored.
+func hashHex(s string) string {
+	return hex.EncodeToString(hash(s))
+}
+
+// newToken returns a new random authorization code, access token, or refresh
+// token.
+func newToken() (string, error) {
+	b, err := util.Random(32)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// verifyCodeChallenge verifies a PKCE code verifier against the code
+// challenge of an authorization code. See RFC 7636 section 4.6.
+func verifyCodeChallenge(challenge, verifier string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	h := sha256.Sum256([]byte(verifier))
+	c := base64.RawURLEncoding.EncodeToString(h[:])
+	return subtle.ConstantTimeCompare([]byte(c), []byte(challenge)) == 1
+}
+
+// client returns the application with the provided client ID. The
+// applications that are configured in the config file take precedence over
+// the registered applications.
+func (o *OAuth) client(clientID string) (*client, bool) {
+	if c, ok := o.clients[clientID]; ok {
+		return &c, true
+	}
+	rc, ok := o.registered.get(clientID)
+	if !ok {
+		return nil, false
+	}
+	c, err := rc.client(clientID)
+	if err != nil {
+		// The registered clients are verified when they are loaded
+		// so this should not happen.
+		log.Errorf("client %v: %v", clientID, err)
+		return nil, false
+	}
+	return c, true
+}
+
+// authenticate verifies the credentials of an application. Public
+// applications do not have a secret and authenticate using PKCE instead.
+func (o *OAuth) authenticate(clientID, secret string) (*client, error) {
+	c, ok := o.client(clientID)
+	if !ok {
+		return nil, tokenError{
+			statusCode:  http.StatusUnauthorized,
+			code:        v1.ErrorInvalidClient,
+			description: "unknown client",
+		}
+	}
+	if c.secretHash != nil &&
+		subtle.ConstantTimeCompare(hash(secret), c.secretHash) != 1 {
+		return nil, tokenError{
+			statusCode:  http.StatusUnauthorized,
+			code:        v1.ErrorInvalidClient,
+			description: "client authentication failed",
+		}
+	}
+	return c, nil
+}
+
+// prune removes the expired authorization codes.
+//
+// This function must be called WITH the lock held.
+func (o *OAuth) prune() {
+	now := time.Now()
+	for k, v := range o.codes {
+		if now.After(v.expires) {
+			delete(o.codes, k)
+		}
+	}
+}
+
+// issueIDToken returns a new ID token for the user if the scopes include the
+// ScopeOpenID scope. An invalid grant error is returned if the user has been
+// deactivated.
+func (o *OAuth) issueIDToken(clientID string, userID uuid.UUID, scopes []string, nonce string) (string, error) {
+	if !hasScope(scopes, v1.ScopeOpenID) {
+		return "", nil
+	}
+	u, err := o.userdb.UserGetById(userID)
+	if err != nil {
+		return "", err
+	}
+	if u.Deactivated {
+		return "", tokenError{
+			statusCode:  http.StatusBadRequest,
+			code:        v1.ErrorInvalidGrant,
+			description: "user deactivated",
+		}
+	}
+	return o.idToken(clientID, *u, scopes, nonce)
+}
+
+// addAccessToken adds a new access token and returns it.
+func (o *OAuth) addAccessToken(clientID string, userID uuid.UUID, scopes []string) (string, error) {
+	access, err := newToken()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	err = o.grants.addAccessToken(user.OAuthToken{
+		Hash:     hashHex(access),
+		UserID:   userID,
+		ClientID: clientID,
+		Scopes:   scopes,
+		Issued:   now.Unix(),
+		Expires:  now.Add(accessTokenExpiry).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return access, nil
+}
+
+// issueTokens issues a new access token and refresh token to an application.
+// The refresh token replaces the existing refresh token of the grant. An ID
+// token is issued as well if the scopes include the ScopeOpenID scope.
+func (o *OAuth) issueTokens(clientID string, userID uuid.UUID, scopes []string, nonce string) (*v1.TokenReply, error) {
+	idToken, err := o.issueIDToken(clientID, userID, scopes, nonce)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	err = o.grants.setRefresh(userID, clientID, hashHex(refresh))
+	if err != nil {
+		return nil, err
+	}
+	access, err := o.addAccessToken(clientID, userID, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.TokenReply{
+		AccessToken:  access,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(accessTokenExpiry.Seconds()),
+		RefreshToken: refresh,
+		Scope:        strings.Join(scopes, " "),
+		IDToken:      idToken,
+	}, nil
+}
+
+func (o *OAuth) processClient(c v1.Client, u *user.User) (*v1.ClientReply, error) {
+	log.Tracef("processClient: %v", c.ClientID)
+
+	cl, ok := o.client(c.ClientID)
+	if !ok {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeClientInvalid,
+		}
+	}
+	if c.RedirectURI != cl.redirectURI {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeRedirectURIInvalid,
+		}
+	}
+
+	scopes := make([]v1.ScopeDetails, 0, len(o.scopes))
+	for k, v := range o.scopes {
+		scopes = append(scopes, v1.ScopeDetails{
+			Scope:       k,
+			Description: v,
+		})
+	}
+	sort.Slice(scopes, func(i, j int) bool {
+		return scopes[i].Scope < scopes[j].Scope
+	})
+
+	var granted []string
+	if u != nil {
+		g, err := o.grants.get(u.ID, cl.id)
+		switch {
+		case err == nil:
+			granted = g.Scopes
+		case !errors.Is(err, errGrantNotFound):
+			return nil, err
+		}
+	}
+
+	return &v1.ClientReply{
+		ClientID: cl.id,
+		Name:     cl.name,
+		Scopes:   scopes,
+		Granted:  granted,
+	}, nil
+}
+
+func (o *OAuth) processAuthorize(a v1.Authorize, u user.User) (*v1.AuthorizeReply, error) {
+	log.Tracef("processAuthorize: %v %v %v", a.ClientID, u.Username, a.Scope)
+
+	// Verify the application
+	c, ok := o.client(a.ClientID)
+	if !ok {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeClientInvalid,
+		}
+	}
+	if a.RedirectURI != c.redirectURI {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeRedirectURIInvalid,
+		}
+	}
+
+	// Verify the request
+	scopes, err := parseScopes(a.Scope, o.scopes)
+	if err != nil {
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeScopeInvalid,
+			ErrorContext: err.Error(),
+		}
+	}
+	if len(a.State) > stateLengthMax {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeInputInvalid,
+			ErrorContext: fmt.Sprintf("state exceeds max length of %v",
+				stateLengthMax),
+		}
+	}
+	if len(a.Nonce) > nonceLengthMax {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeInputInvalid,
+			ErrorContext: fmt.Sprintf("nonce exceeds max length of %v",
+				nonceLengthMax),
+		}
+	}
+	switch {
+	case a.CodeChallenge == "" && c.secretHash == nil:
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeInputInvalid,
+			ErrorContext: "code challenge is required",
+		}
+	case a.CodeChallenge != "" &&
+		a.CodeChallengeMethod != v1.CodeChallengeMethodS256:
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeInputInvalid,
+			ErrorContext: fmt.Sprintf("code challenge method must be %v",
+				v1.CodeChallengeMethodS256),
+		}
+	}
+
+	// Record the consent and create the authorization code
+	err = o.grants.consent(u.ID, c.id, scopes)
+	if err != nil {
+		return nil, err
+	}
+	code, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	o.Lock()
+	o.prune()
+	o.codes[hashHex(code)] = authCode{
+		clientID:      c.id,
+		userID:        u.ID,
+		redirectURI:   c.redirectURI,
+		scopes:        scopes,
+		nonce:         a.Nonce,
+		codeChallenge: a.CodeChallenge,
+		expires:       time.Now().Add(codeExpiry),
+	}
+	o.Unlock()
+
+	log.Infof("OAuth consent: %v %v %v", u.Username, c.id, scopes)
+
+	// Build the redirect URI
+	ru, err := url.Parse(c.redirectURI)
+	if err != nil {
+		return nil, err
+	}
+	q := ru.Query()
+	q.Set("code", code)
+	if a.State != "" {
+		q.Set("state", a.State)
+	}
+	ru.RawQuery = q.Encode()
+
+	return &v1.AuthorizeReply{
+		Code:        code,
+		RedirectURI: ru.String(),
+	}, nil
+}
+
+func (o *OAuth) processToken(clientID, secret string, form url.Values) (*v1.TokenReply, error) {
+	log.Tracef("processToken: %v %v", clientID, form.Get("grant_type"))
+
+	c, err := o.authenticate(clientID, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch form.Get("grant_type") {
+	case v1.GrantTypeAuthorizationCode:
+		return o.exchangeCode(c, form)
+	case v1.GrantTypeRefreshToken:
+		return o.refresh(c, form)
+	}
+
+	return nil, tokenError{
+		statusCode:  http.StatusBadRequest,
+		code:        v1.ErrorUnsupportedGrantType,
+		description: "grant type must be authorization_code or refresh_token",
+	}
+}
+
+// exchangeCode exchanges an authorization code for an access token and a
+// refresh token. The code is deleted on the first use.
+func (o *OAuth) exchangeCode(c *client, form url.Values) (*v1.TokenReply, error) {
+	invalidGrant := func(description string) tokenError {
+		return tokenError{
+			statusCode:  http.StatusBadRequest,
+			code:        v1.ErrorInvalidGrant,
+			description: description,
+		}
+	}
+
+	key := hashHex(form.Get("code"))
+	o.Lock()
+	ac, ok := o.codes[key]
+	delete(o.codes, key)
+	o.Unlock()
+
+	switch {
+	case !ok || time.Now().After(ac.expires):
+		return nil, invalidGrant("code invalid or expired")
+	case ac.clientID != c.id:
+		return nil, invalidGrant("code was issued to another client")
+	case form.Get("redirect_uri") != ac.redirectURI:
+		return nil, invalidGrant("redirect uri mismatch")
+	case ac.codeChallenge != "" &&
+		!verifyCodeChallenge(ac.codeChallenge, form.Get("code_verifier")):
+		return nil, invalidGrant("code verifier invalid")
+	}
+
+	tr, err := o.issueTokens(c.id, ac.userID, ac.scopes, ac.nonce)
+	if errors.Is(err, errGrantNotFound) {
+		return nil, invalidGrant("grant has been revoked")
+	}
+	return tr, err
+}
+
+// refresh exchanges a refresh token for a new access token and a new refresh
+// token. The access token is issued with the scopes of the grant. A new ID
+// token, without a nonce, is issued if the grant includes the ScopeOpenID
+// scope.
+func (o *OAuth) refresh(c *client, form url.Values) (*v1.TokenReply, error) {
+	refresh, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	g, err := o.grants.rotateRefresh(c.id,
+		hashHex(form.Get("refresh_token")), hashHex(refresh))
+	switch {
+	case errors.Is(err, errGrantNotFound):
+		return nil, tokenError{
+			statusCode:  http.StatusBadRequest,
+			code:        v1.ErrorInvalidGrant,
+			description: "refresh token invalid",
+		}
+	case err != nil:
+		return nil, err
+	}
+
+	idToken, err := o.issueIDToken(c.id, g.UserID, g.Scopes, "")
+	if err != nil {
+		return nil, err
+	}
+	access, err := o.addAccessToken(c.id, g.UserID, g.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.TokenReply{
+		AccessToken:  access,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(accessTokenExpiry.Seconds()),
+		RefreshToken: refresh,
+		Scope:        strings.Join(g.Scopes, " "),
+		IDToken:      idToken,
+	}, nil
+}
+
+func (o *OAuth) processIntrospect(clientID, secret string, form url.Values) (*v1.IntrospectReply, error) {
+	log.Tracef("processIntrospect: %v %v", clientID,
+		form.Get("token_type_hint"))
+
+	c, err := o.authenticate(clientID, secret)
+	if err != nil {
+		return nil, err
+	}
+	token := form.Get("token")
+	if token == "" {
+		return nil, tokenError{
+			statusCode:  http.StatusBadRequest,
+			code:        v1.ErrorInvalidRequest,
+			description: "token is required",
+		}
+	}
+
+	// Lookup the token. The token type hint is not needed since the
+	// access tokens and the refresh tokens can be told apart by their
+	// expiry. A token that was issued to another application is
+	// reported as not active so that applications can not probe the
+	// tokens of other applications.
+	var (
+		inactive = &v1.IntrospectReply{}
+		ir       = v1.IntrospectReply{
+			Active:   true,
+			ClientID: c.id,
+		}
+		userID uuid.UUID
+		scopes []string
+	)
+	at, err := o.grants.accessToken(hashHex(token))
+	switch {
+	case err == nil:
+		if at.ClientID != c.id {
+			return inactive, nil
+		}
+		_, err := o.grants.get(at.UserID, at.ClientID)
+		if errors.Is(err, errGrantNotFound) {
+			return inactive, nil
+		} else if err != nil {
+			return nil, err
+		}
+		userID = at.UserID
+		scopes = at.Scopes
+		ir.TokenType = v1.TokenTypeHintAccessToken
+		ir.Expires = at.Expires
+		ir.IssuedAt = at.Issued
+	case errors.Is(err, errTokenNotFound):
+		g, err := o.grants.refreshGrant(c.id, hashHex(token))
+		if errors.Is(err, errGrantNotFound) {
+			return inactive, nil
+		} else if err != nil {
+			return nil, err
+		}
+		userID = g.UserID
+		scopes = g.Scopes
+		ir.TokenType = v1.TokenTypeHintRefreshToken
+	default:
+		return nil, err
+	}
+
+	u, err := o.userdb.UserGetById(userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.Deactivated {
+		return inactive, nil
+	}
+	ir.Scope = strings.Join(scopes, " ")
+	ir.Username = u.Username
+	ir.Subject = userID.String()
+
+	return &ir, nil
+}
+
+func (o *OAuth) processGrants(u user.User) (*v1.GrantsReply, error) {
+	log.Tracef("processGrants: %v", u.Username)
+
+	ug, err := o.grants.user(u.ID)
+	if err != nil {
+		return nil, err
+	}
+	grants := make([]v1.Grant, 0, len(ug))
+	for _, g := range ug {
+		// The name of an application that has been removed from the
+		// config is not known anymore. The grant is still returned so
+		// that the user can revoke it.
+		var name string
+		if c, ok := o.client(g.ClientID); ok {
+			name = c.name
+		}
+		grants = append(grants, v1.Grant{
+			ClientID:  g.ClientID,
+			Name:      name,
+			Scopes:    g.Scopes,
+			Timestamp: g.Timestamp,
+		})
+	}
+	sort.Slice(grants, func(i, j int) bool {
+		return grants[i].ClientID < grants[j].ClientID
+	})
+
+	return &v1.GrantsReply{
+		Grants: grants,
+	}, nil
+}
+
+func (o *OAuth) processRevoke(rv v1.Revoke, u user.User) (*v1.RevokeReply, error) {
+	log.Tracef("processRevoke: %v %v", u.Username, rv.ClientID)
+
+	err := o.grants.revoke(u.ID, rv.ClientID)
+	if errors.Is(err, errGrantNotFound) {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeGrantNotFound,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Invalidate the pending authorization codes of the application.
+	// The tokens have been deleted along with the grant.
+	o.Lock()
+	for k, v := range o.codes {
+		if v.userID == u.ID && v.clientID == rv.ClientID {
+			delete(o.codes, k)
+		}
+	}
+	o.Unlock()
+
+	log.Infof("OAuth revoke: %v %v", u.Username, rv.ClientID)
+
+	return &v1.RevokeReply{}, nil
+}
+
+func (o *OAuth) processClients() *v1.ClientsReply {
+	log.Tracef("processClients")
+
+	rcs := o.registered.all()
+	clients := make([]v1.ClientDetails, 0, len(o.clients)+len(rcs))
+	for _, c := range o.clients {
+		clients = append(clients, v1.ClientDetails{
+			ClientID:     c.id,
+			Name:         c.name,
+			RedirectURI:  c.redirectURI,
+			Confidential: c.secretHash != nil,
+			Configured:   true,
+		})
+	}
+	for clientID, rc := range rcs {
+		clients = append(clients, v1.ClientDetails{
+			ClientID:     clientID,
+			Name:         rc.Name,
+			RedirectURI:  rc.RedirectURI,
+			Confidential: rc.SecretHash != "",
+			Timestamp:    rc.Timestamp,
+		})
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].ClientID < clients[j].ClientID
+	})
+
+	return &v1.ClientsReply{
+		Clients: clients,
+	}
+}
+
+func (o *OAuth) processRegisterClient(rc v1.RegisterClient, u user.User) (*v1.RegisterClientReply, error) {
+	log.Tracef("processRegisterClient: %v %v", rc.Name, rc.RedirectURI)
+
+	// Verify the application
+	name := strings.TrimSpace(rc.Name)
+	switch {
+	case name == "":
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeInputInvalid,
+			ErrorContext: "name is required",
+		}
+	case len(name) > clientNameLengthMax:
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeInputInvalid,
+			ErrorContext: fmt.Sprintf("name exceeds max length of %v",
+				clientNameLengthMax),
+		}
+	}
+	if !redirectURIIsValid(rc.RedirectURI) {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeRedirectURIInvalid,
+			ErrorContext: "redirect uri must be an https url or an " +
+				"http url on localhost",
+		}
+	}
+
+	// Create the client credentials
+	b, err := util.Random(clientIDSize)
+	if err != nil {
+		return nil, err
+	}
+	clientID := hex.EncodeToString(b)
+	reg := registeredClient{
+		Name:        name,
+		RedirectURI: rc.RedirectURI,
+		Timestamp:   time.Now().Unix(),
+		UserID:      u.ID.String(),
+	}
+	var secret string
+	if rc.Confidential {
+		secret, err = newToken()
+		if err != nil {
+			return nil, err
+		}
+		reg.SecretHash = hashHex(secret)
+	}
+	err = o.registered.add(clientID, reg)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("OAuth client registered: %v %v %v %v", u.Username,
+		clientID, name, rc.RedirectURI)
+
+	return &v1.RegisterClientReply{
+		ClientID:     clientID,
+		ClientSecret: secret,
+	}, nil
+}
+
+func (o *OAuth) processDeleteClient(dc v1.DeleteClient, u user.User) (*v1.DeleteClientReply, error) {
+	log.Tracef("processDeleteClient: %v %v", u.Username, dc.ClientID)
+
+	if _, ok := o.clients[dc.ClientID]; ok {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeClientInvalid,
+			ErrorContext: "clients that are configured in the config " +
+				"file can not be deleted",
+		}
+	}
+	err := o.registered.del(dc.ClientID)
+	if errors.Is(err, errClientNotFound) {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeClientInvalid,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Revoke the grants along with their tokens and invalidate the
+	// pending authorization codes of the application.
+	err = o.grants.revokeClient(dc.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	o.Lock()
+	for k, v := range o.codes {
+		if v.clientID == dc.ClientID {
+			delete(o.codes, k)
+		}
+	}
+	o.Unlock()
+
+	log.Infof("OAuth client deleted: %v %v", u.Username, dc.ClientID)
+
+	return &v1.DeleteClientReply{}, nil
+}
@@ -0,0 +1,307 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package oauth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "github.com/decred/politeia/politeiawww/api/oauth/v1"
+	"github.com/decred/politeia/politeiawww/config"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/politeiawww/user/localdb"
+)
+
+const (
+	testClientID     = "app"
+	testClientSecret = "secret"
+	testRedirectURI  = "https://app.example.com/callback"
+)
+
+// newTestOAuth returns a new OAuth context that uses a localdb user database
+// and that has a single confidential application configured. The returned
+// cleanup function must be called once the test has completed.
+func newTestOAuth(t *testing.T, issuer string) (*OAuth, user.User, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "oauth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := localdb.New(filepath.Join(dir, "localdb"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	cleanup := func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+
+	err = db.UserNew(user.User{
+		Email:    "alice@example.com",
+		Username: "alice",
+	})
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+	u, err := db.UserGetByUsername("alice")
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		DataDir: dir,
+		OAuthClients: []string{
+			strings.Join([]string{testClientID, testRedirectURI,
+				"App", testClientSecret}, ","),
+		},
+		OAuthIssuer: issuer,
+	}
+	o, err := New(cfg, nil, db, nil)
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+
+	return o, *u, cleanup
+}
+
+// authorize returns a new authorization code for the provided application.
+func authorize(t *testing.T, o *OAuth, u user.User, a v1.Authorize) string {
+	t.Helper()
+
+	ar, err := o.processAuthorize(a, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ar.Code
+}
+
+// introspect returns the introspection reply of a token.
+func introspect(t *testing.T, o *OAuth, clientID, secret, token string) v1.IntrospectReply {
+	t.Helper()
+
+	ir, err := o.processIntrospect(clientID, secret, url.Values{
+		"token": []string{token},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *ir
+}
+
+// tokenErrorCode returns the OAuth2 error code of a token endpoint error.
+func tokenErrorCode(err error) string {
+	var te tokenError
+	if errors.As(err, &te) {
+		return te.code
+	}
+	return ""
+}
+
+// userErrorCode returns the error code of a user error.
+func userErrorCode(err error) v1.ErrorCodeT {
+	var ue v1.UserErrorReply
+	if errors.As(err, &ue) {
+		return ue.ErrorCode
+	}
+	return v1.ErrorCodeInvalid
+}
+
+func TestCodeFlow(t *testing.T) {
+	o, u, cleanup := newTestOAuth(t, "")
+	defer cleanup()
+
+	// Authorize the application
+	code := authorize(t, o, u, v1.Authorize{
+		ClientID:    testClientID,
+		RedirectURI: testRedirectURI,
+		Scope:       v1.ScopeProfile + " " + v1.ScopeProposals,
+		State:       "state",
+	})
+
+	// Exchange the code. The code can only be used once.
+	form := url.Values{
+		"grant_type":   []string{v1.GrantTypeAuthorizationCode},
+		"code":         []string{code},
+		"redirect_uri": []string{testRedirectURI},
+	}
+	_, err := o.processToken(testClientID, "wrong", form)
+	if tokenErrorCode(err) != v1.ErrorInvalidClient {
+		t.Fatalf("got %v, want %v", err, v1.ErrorInvalidClient)
+	}
+	tr, err := o.processToken(testClientID, testClientSecret, form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.Scope != "profile proposals" {
+		t.Fatalf("got scope %q", tr.Scope)
+	}
+	_, err = o.processToken(testClientID, testClientSecret, form)
+	if tokenErrorCode(err) != v1.ErrorInvalidGrant {
+		t.Fatalf("got %v, want %v", err, v1.ErrorInvalidGrant)
+	}
+
+	// Introspect the tokens
+	ir := introspect(t, o, testClientID, testClientSecret, tr.AccessToken)
+	if !ir.Active || ir.Subject != u.ID.String() ||
+		ir.Username != u.Username ||
+		ir.TokenType != v1.TokenTypeHintAccessToken {
+		t.Fatalf("access token: got %+v", ir)
+	}
+	ir = introspect(t, o, testClientID, testClientSecret, tr.RefreshToken)
+	if !ir.Active || ir.TokenType != v1.TokenTypeHintRefreshToken {
+		t.Fatalf("refresh token: got %+v", ir)
+	}
+
+	// Refresh the tokens. The previous refresh token must be replaced.
+	refresh := url.Values{
+		"grant_type":    []string{v1.GrantTypeRefreshToken},
+		"refresh_token": []string{tr.RefreshToken},
+	}
+	tr2, err := o.processToken(testClientID, testClientSecret, refresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = o.processToken(testClientID, testClientSecret, refresh)
+	if tokenErrorCode(err) != v1.ErrorInvalidGrant {
+		t.Fatalf("got %v, want %v", err, v1.ErrorInvalidGrant)
+	}
+	ir = introspect(t, o, testClientID, testClientSecret, tr.RefreshToken)
+	if ir.Active {
+		t.Fatalf("replaced refresh token is active")
+	}
+	ir = introspect(t, o, testClientID, testClientSecret, tr2.AccessToken)
+	if !ir.Active {
+		t.Fatalf("refreshed access token is not active")
+	}
+
+	// Verify the grant
+	gr, err := o.processGrants(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gr.Grants) != 1 || gr.Grants[0].ClientID != testClientID ||
+		gr.Grants[0].Name != "App" {
+		t.Fatalf("got grants %+v", gr.Grants)
+	}
+
+	// Revoke the grant. The tokens must not be active anymore.
+	_, err = o.processRevoke(v1.Revoke{ClientID: testClientID}, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{tr.AccessToken, tr2.AccessToken,
+		tr2.RefreshToken} {
+		ir = introspect(t, o, testClientID, testClientSecret, v)
+		if ir.Active {
+			t.Fatalf("token is active after revoke")
+		}
+	}
+	_, err = o.processRevoke(v1.Revoke{ClientID: testClientID}, u)
+	if userErrorCode(err) != v1.ErrorCodeGrantNotFound {
+		t.Fatalf("got %v, want %v", err, v1.ErrorCodeGrantNotFound)
+	}
+}
+
+func TestPublicClient(t *testing.T) {
+	o, u, cleanup := newTestOAuth(t, "")
+	defer cleanup()
+
+	// Register a public application
+	rr, err := o.processRegisterClient(v1.RegisterClient{
+		Name:        "Native",
+		RedirectURI: "http://127.0.0.1:8080/callback",
+	}, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rr.ClientSecret != "" {
+		t.Fatalf("public client has a secret")
+	}
+
+	// Public applications must use PKCE
+	a := v1.Authorize{
+		ClientID:    rr.ClientID,
+		RedirectURI: "http://127.0.0.1:8080/callback",
+		Scope:       v1.ScopeProfile,
+	}
+	_, err = o.processAuthorize(a, u)
+	if userErrorCode(err) != v1.ErrorCodeInputInvalid {
+		t.Fatalf("got %v, want %v", err, v1.ErrorCodeInputInvalid)
+	}
+	verifier := strings.Repeat("v", 43)
+	h := sha256.Sum256([]byte(verifier))
+	a.CodeChallenge = base64.RawURLEncoding.EncodeToString(h[:])
+	a.CodeChallengeMethod = v1.CodeChallengeMethodS256
+
+	// Exchange the code using an invalid verifier
+	form := url.Values{
+		"grant_type":    []string{v1.GrantTypeAuthorizationCode},
+		"code":          []string{authorize(t, o, u, a)},
+		"redirect_uri":  []string{a.RedirectURI},
+		"code_verifier": []string{strings.Repeat("x", 43)},
+	}
+	_, err = o.processToken(rr.ClientID, "", form)
+	if tokenErrorCode(err) != v1.ErrorInvalidGrant {
+		t.Fatalf("got %v, want %v", err, v1.ErrorInvalidGrant)
+	}
+
+	// Exchange the code using the valid verifier
+	form.Set("code", authorize(t, o, u, a))
+	form.Set("code_verifier", verifier)
+	tr, err := o.processToken(rr.ClientID, "", form)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A token can not be introspected by another application
+	ir := introspect(t, o, testClientID, testClientSecret, tr.AccessToken)
+	if ir.Active {
+		t.Fatalf("token of another client is active")
+	}
+
+	// Delete the application. The grant and the tokens must be
+	// deleted along with it.
+	_, err = o.processDeleteClient(v1.DeleteClient{
+		ClientID: testClientID,
+	}, u)
+	if userErrorCode(err) != v1.ErrorCodeClientInvalid {
+		t.Fatalf("configured client: got %v, want %v", err,
+			v1.ErrorCodeClientInvalid)
+	}
+	_, err = o.processDeleteClient(v1.DeleteClient{
+		ClientID: rr.ClientID,
+	}, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grants, err := o.userdb.OAuthGrantsGetByUserID(u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(grants) != 0 {
+		t.Fatalf("got %v grants after delete", len(grants))
+	}
+	for _, v := range []string{tr.AccessToken, tr.RefreshToken} {
+		_, err = o.userdb.OAuthTokenGetByHash(hashHex(v))
+		if !errors.Is(err, user.ErrOAuthTokenNotFound) {
+			t.Fatalf("got %v, want %v", err, user.ErrOAuthTokenNotFound)
+		}
+	}
+	if _, ok := o.client(rr.ClientID); ok {
+		t.Fatalf("deleted client still exists")
+	}
+}
@@ -0,0 +1,364 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/openapi"
+	"github.com/decred/politeia/util"
+	"github.com/decred/politeia/util/version"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// The OpenAPI tags of the APIs.
+	openapiTagWWW        = "www"
+	openapiTagRecords    = "records"
+	openapiTagComments   = "comments"
+	openapiTagTicketVote = "ticketvote"
+)
+
+// newOpenAPI returns a new OpenAPI document for the politeiawww APIs. The
+// routes are added to it during the application specific setup.
+func newOpenAPI() *openapi.Document {
+	return openapi.New("politeiawww", "Politeia web server API", version.String())
+}
+
+// handleOpenAPI returns the OpenAPI document of the politeiawww APIs.
+func (p *politeiawww) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleOpenAPI")
+
+	util.RespondWithJSON(w, http.StatusOK, p.openapi)
+}
+
+// respondWithInputError replies with a 400 and the user error of the API
+// that the request was sent to.
+func respondWithInputError(w http.ResponseWriter, r *http.Request, errContext string) {
+	log.Debugf("%v invalid input: %v %v %v",
+		util.RemoteAddr(r), r.Method, r.URL, errContext)
+
+	var reply interface{}
+	switch path := r.URL.Path; {
+	case strings.HasPrefix(path, rcv1.APIRoute):
+		reply = rcv1.UserErrorReply{
+			ErrorCode:    rcv1.ErrorCodeInputInvalid,
+			ErrorContext: errContext,
+		}
+	case strings.HasPrefix(path, cmv1.APIRoute):
+		reply = cmv1.UserErrorReply{
+			ErrorCode:    cmv1.ErrorCodeInputInvalid,
+			ErrorContext: errContext,
+		}
+	case strings.HasPrefix(path, tkv1.APIRoute):
+		reply = tkv1.UserErrorReply{
+			ErrorCode:    tkv1.ErrorCodeInputInvalid,
+			ErrorContext: errContext,
+		}
+	default:
+		reply = www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{errContext},
+		}
+	}
+	util.RespondWithJSON(w, http.StatusBadRequest, reply)
+}
+
+// requestValidationMiddleware validates the request bodies of the routes that
+// have been added to the OpenAPI document against their schemas. Requests
+// with a malformed body are rejected before the handler is called. It must
+// be registered after the body size limit middleware so that the body is
+// read with the route limit in place.
+func requestValidationMiddleware(d *openapi.Document) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost && r.Method != http.MethodPut {
+				next.ServeHTTP(w, r)
+				return
+			}
+			route := mux.CurrentRoute(r)
+			if route == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			tmpl, err := route.GetPathTemplate()
+			if err != nil || !d.HasRequestBody(r.Method, tmpl) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// A body that exceeds the size limit is replaced with a 413
+			// by the body size limit writer.
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				respondWithInputError(w, r,
+					fmt.Sprintf("unable to read body: %v", err))
+				return
+			}
+			err = d.Validate(r.Method, tmpl, body)
+			if err != nil {
+				respondWithInputError(w, r, err.Error())
+				return
+			}
+
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// setupOpenAPI adds the www, records, comments, and ticketvote routes to the
+// OpenAPI document and serves the document. This must be called after the
+// pi routes have been setup.
+func (p *politeiawww) setupOpenAPI() {
+	d := p.openapi
+	d.AddTag(openapiTagWWW, "Users, policies, and the legacy proposal routes")
+	d.AddTag(openapiTagRecords, "Records API")
+	d.AddTag(openapiTagComments, "Comments API")
+	d.AddTag(openapiTagTicketVote, "Ticket vote API")
+
+	// www routes
+	type wwwRoute struct {
+		method     string
+		route      string
+		request    interface{}
+		reply      interface{}
+		deprecated bool
+	}
+	wwwRoutes := []wwwRoute{
+		{http.MethodGet, www.RouteVersion, nil, www.VersionReply{}, false},
+		{http.MethodGet, www.RoutePolicies, www.Policies{}, www.PoliciesReply{}, false},
+		{http.MethodGet, www.RoutePolicy, www.Policy{}, www.PolicyReply{}, false},
+		{http.MethodGet, www.RouteOpenAPI, nil, nil, false},
+
+		// Legacy proposal routes
+		{http.MethodGet, www.RouteTokenInventory, nil,
+			www.TokenInventoryReply{}, true},
+		{http.MethodGet, www.RouteAllVetted, www.GetAllVetted{},
+			www.GetAllVettedReply{}, true},
+		{http.MethodGet, www.RouteProposalDetails, www.ProposalsDetails{},
+			www.ProposalDetailsReply{}, true},
+		{http.MethodPost, www.RouteBatchProposals, www.BatchProposals{},
+			www.BatchProposalsReply{}, true},
+		{http.MethodGet, www.RouteVoteStatus, nil,
+			www.VoteStatusReply{}, true},
+		{http.MethodGet, www.RouteAllVoteStatus, nil,
+			www.GetAllVoteStatusReply{}, true},
+		{http.MethodGet, www.RouteActiveVote, nil,
+			www.ActiveVoteReply{}, true},
+		{http.MethodPost, www.RouteCastVotes, www.Ballot{},
+			www.BallotReply{}, true},
+		{http.MethodGet, www.RouteVoteResults, nil,
+			www.VoteResultsReply{}, true},
+		{http.MethodPost, www.RouteBatchVoteSummary, www.BatchVoteSummary{},
+			www.BatchVoteSummaryReply{}, true},
+
+		// User routes
+		{http.MethodPost, www.RouteNewUser, www.NewUser{},
+			www.NewUserReply{}, false},
+		{http.MethodGet, www.RouteVerifyNewUser, www.VerifyNewUser{},
+			www.VerifyNewUserReply{}, false},
+		{http.MethodPost, www.RouteResendVerification,
+			www.ResendVerification{}, www.ResendVerificationReply{}, false},
+		{http.MethodPost, www.RouteLogin, www.Login{},
+			www.LoginReply{}, false},
+		{http.MethodPost, www.RouteLogout, www.Logout{},
+			www.LogoutReply{}, false},
+		{http.MethodPost, www.RouteResetPassword, www.ResetPassword{},
+			www.ResetPasswordReply{}, false},
+		{http.MethodPost, www.RouteVerifyResetPassword,
+			www.VerifyResetPassword{}, www.VerifyResetPasswordReply{}, false},
+		{http.MethodGet, www.RouteUserDetails, www.UserDetails{},
+			www.UserDetailsReply{}, false},
+		{http.MethodGet, www.RouteUsers, www.Users{},
+			www.UsersReply{}, false},
+		{http.MethodGet, www.RouteCSRFToken, nil,
+			www.CSRFTokenReply{}, false},
+		{http.MethodGet, www.RouteUserMe, www.Me{},
+			www.LoginReply{}, false},
+		{http.MethodPost, www.RouteUpdateUserKey, www.UpdateUserKey{},
+			www.UpdateUserKeyReply{}, false},
+		{http.MethodPost, www.RouteVerifyUpdateUserKey,
+			www.VerifyUpdateUserKey{}, www.VerifyUpdateUserKeyReply{}, false},
+		{http.MethodPost, www.RouteChangeUsername, www.ChangeUsername{},
+			www.ChangeUsernameReply{}, false},
+		{http.MethodPost, www.RouteChangePassword, www.ChangePassword{},
+			www.ChangePasswordReply{}, false},
+		{http.MethodPost, www.RouteEditUser, www.EditUser{},
+			www.EditUserReply{}, false},
+		{http.MethodGet, www.RouteUserRegistrationPayment,
+			www.UserRegistrationPayment{},
+			www.UserRegistrationPaymentReply{}, false},
+		{http.MethodGet, www.RouteUserProposalPaywall,
+			www.UserProposalPaywall{}, www.UserProposalPaywallReply{}, false},
+		{http.MethodGet, www.RouteUserProposalPaywallTx,
+			www.UserProposalPaywallTx{}, www.UserProposalPaywallTxReply{},
+			false},
+		{http.MethodGet, www.RouteUserProposalCredits,
+			www.UserProposalCredits{}, www.UserProposalCreditsReply{}, false},
+		{http.MethodPost, www.RouteSetTOTP, www.SetTOTP{},
+			www.SetTOTPReply{}, false},
+		{http.MethodPost, www.RouteVerifyTOTP, www.VerifyTOTP{},
+			www.VerifyTOTPReply{}, false},
+		{http.MethodPost, www.RouteVerifyStake, www.VerifyStake{},
+			www.VerifyStakeReply{}, false},
+		{http.MethodPut, www.RouteUserPaymentsRescan,
+			www.UserPaymentsRescan{}, www.UserPaymentsRescanReply{}, false},
+		{http.MethodPost, www.RouteManageUser, www.ManageUser{},
+			www.ManageUserReply{}, false},
+		{http.MethodPost, www.RouteNewBot, www.NewBot{},
+			www.NewBotReply{}, false},
+		{http.MethodPost, www.RouteBotAPIKey, www.BotAPIKey{},
+			www.BotAPIKeyReply{}, false},
+		{http.MethodGet, www.RouteVerifications, www.Verifications{},
+			www.VerificationsReply{}, false},
+		{http.MethodGet, www.RouteMaintenance, www.Maintenance{},
+			www.MaintenanceReply{}, false},
+		{http.MethodPost, www.RouteSetMaintenance, www.SetMaintenance{},
+			www.SetMaintenanceReply{}, false},
+	}
+	for _, v := range wwwRoutes {
+		d.AddRoute(openapi.Route{
+			Method:     v.method,
+			Path:       www.PoliteiaWWWAPIRoute + v.route,
+			Tag:        openapiTagWWW,
+			Deprecated: v.deprecated,
+			Request:    v.request,
+			Reply:      v.reply,
+			ErrorReply: www.UserError{},
+		})
+	}
+
+	// Records routes
+	recordsRoutes := []struct {
+		route   string
+		request interface{}
+		reply   interface{}
+	}{
+		{rcv1.RouteNew, rcv1.New{}, rcv1.NewReply{}},
+		{rcv1.RouteEdit, rcv1.Edit{}, rcv1.EditReply{}},
+		{rcv1.RouteSetStatus, rcv1.SetStatus{}, rcv1.SetStatusReply{}},
+		{rcv1.RouteDetails, rcv1.Details{}, rcv1.DetailsReply{}},
+		{rcv1.RouteBatchDetails, rcv1.BatchDetails{}, rcv1.BatchDetailsReply{}},
+		{rcv1.RouteTimestamps, rcv1.Timestamps{}, rcv1.TimestampsReply{}},
+		{rcv1.RouteRecords, rcv1.Records{}, rcv1.RecordsReply{}},
+		{rcv1.RouteInventory, rcv1.Inventory{}, rcv1.InventoryReply{}},
+		{rcv1.RouteInventoryOrdered, rcv1.InventoryOrdered{},
+			rcv1.InventoryOrderedReply{}},
+		{rcv1.RouteUserRecords, rcv1.UserRecords{}, rcv1.UserRecordsReply{}},
+		{rcv1.RouteLegacyTokens, rcv1.LegacyTokens{},
+			rcv1.LegacyTokensReply{}},
+	}
+	for _, v := range recordsRoutes {
+		d.AddRoute(openapi.Route{
+			Method:     http.MethodPost,
+			Path:       rcv1.APIRoute + v.route,
+			Tag:        openapiTagRecords,
+			Request:    v.request,
+			Reply:      v.reply,
+			ErrorReply: rcv1.UserErrorReply{},
+		})
+	}
+
+	// Comments routes
+	commentsRoutes := []struct {
+		route   string
+		request interface{}
+		reply   interface{}
+	}{
+		{cmv1.RoutePolicy, cmv1.Policy{}, cmv1.PolicyReply{}},
+		{cmv1.RouteNew, cmv1.New{}, cmv1.NewReply{}},
+		{cmv1.RouteVote, cmv1.Vote{}, cmv1.VoteReply{}},
+		{cmv1.RouteDel, cmv1.Del{}, cmv1.DelReply{}},
+		{cmv1.RouteCount, cmv1.Count{}, cmv1.CountReply{}},
+		{cmv1.RouteComments, cmv1.Comments{}, cmv1.CommentsReply{}},
+		{cmv1.RouteVotes, cmv1.Votes{}, cmv1.VotesReply{}},
+		{cmv1.RouteTimestamps, cmv1.Timestamps{}, cmv1.TimestampsReply{}},
+		{cmv1.RouteExport, cmv1.Export{}, cmv1.ExportReply{}},
+	}
+	for _, v := range commentsRoutes {
+		d.AddRoute(openapi.Route{
+			Method:     http.MethodPost,
+			Path:       cmv1.APIRoute + v.route,
+			Tag:        openapiTagComments,
+			Request:    v.request,
+			Reply:      v.reply,
+			ErrorReply: cmv1.UserErrorReply{},
+		})
+	}
+
+	// Ticketvote routes
+	ticketvoteRoutes := []struct {
+		route   string
+		request interface{}
+		reply   interface{}
+	}{
+		{tkv1.RoutePolicy, tkv1.Policy{}, tkv1.PolicyReply{}},
+		{tkv1.RouteAuthorize, tkv1.Authorize{}, tkv1.AuthorizeReply{}},
+		{tkv1.RouteStart, tkv1.Start{}, tkv1.StartReply{}},
+		{tkv1.RouteCastBallot, tkv1.CastBallot{}, tkv1.CastBallotReply{}},
+		{tkv1.RouteDetails, tkv1.Details{}, tkv1.DetailsReply{}},
+		{tkv1.RouteResults, tkv1.Results{}, tkv1.ResultsReply{}},
+		{tkv1.RouteSummaries, tkv1.Summaries{}, tkv1.SummariesReply{}},
+		{tkv1.RouteSubmissions, tkv1.Submissions{}, tkv1.SubmissionsReply{}},
+		{tkv1.RouteInventory, tkv1.Inventory{}, tkv1.InventoryReply{}},
+		{tkv1.RouteTimestamps, tkv1.Timestamps{}, tkv1.TimestampsReply{}},
+		{tkv1.RouteCertificate, tkv1.Certificate{}, tkv1.CertificateReply{}},
+		{tkv1.RouteTallies, tkv1.Tallies{}, tkv1.TalliesReply{}},
+	}
+	for _, v := range ticketvoteRoutes {
+		d.AddRoute(openapi.Route{
+			Method:     http.MethodPost,
+			Path:       tkv1.APIRoute + v.route,
+			Tag:        openapiTagTicketVote,
+			Request:    v.request,
+			Reply:      v.reply,
+			ErrorReply: tkv1.UserErrorReply{},
+		})
+	}
+
+	// Routes that are also served to GET requests so that the replies
+	// can be cached and revalidated using their ETag.
+	getRoutes := []struct {
+		path       string
+		tag        string
+		request    interface{}
+		reply      interface{}
+		errorReply interface{}
+	}{
+		{rcv1.APIRoute + rcv1.RouteDetails, openapiTagRecords,
+			rcv1.Details{}, rcv1.DetailsReply{}, rcv1.UserErrorReply{}},
+		{rcv1.APIRoute + rcv1.RouteTimestamps, openapiTagRecords,
+			rcv1.Timestamps{}, rcv1.TimestampsReply{}, rcv1.UserErrorReply{}},
+		{cmv1.APIRoute + cmv1.RouteTimestamps, openapiTagComments,
+			cmv1.Timestamps{}, cmv1.TimestampsReply{}, cmv1.UserErrorReply{}},
+		{tkv1.APIRoute + tkv1.RouteResults, openapiTagTicketVote,
+			tkv1.Results{}, tkv1.ResultsReply{}, tkv1.UserErrorReply{}},
+		{tkv1.APIRoute + tkv1.RouteTimestamps, openapiTagTicketVote,
+			tkv1.Timestamps{}, tkv1.TimestampsReply{}, tkv1.UserErrorReply{}},
+	}
+	for _, v := range getRoutes {
+		d.AddRoute(openapi.Route{
+			Method:     http.MethodGet,
+			Path:       v.path,
+			Tag:        v.tag,
+			Request:    v.request,
+			Reply:      v.reply,
+			ErrorReply: v.errorReply,
+		})
+	}
+
+	p.addRoute(http.MethodGet, www.PoliteiaWWWAPIRoute,
+		www.RouteOpenAPI, p.handleOpenAPI,
+		permissionPublic)
+}
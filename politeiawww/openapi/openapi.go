@@ -0,0 +1,504 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package openapi generates OpenAPI 3 documents for the politeiawww APIs and
+// validates request bodies against the schemas of the document.
+//
+// The schemas are derived from the API types using reflection. The JSON
+// struct tags determine the property names. Properties are never required
+// since a property that is missing from a request body is decoded into the
+// zero value of the field, the same as it is by the request handlers.
+package openapi
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+const (
+	// Version is the OpenAPI specification version of the generated
+	// documents.
+	Version = "3.0.3"
+
+	// contentTypeJSON is the content type of the request and reply
+	// bodies.
+	contentTypeJSON = "application/json"
+
+	// schemaRefPrefix is the prefix of a reference to a component
+	// schema.
+	schemaRefPrefix = "#/components/schemas/"
+)
+
+// Document is an OpenAPI 3 document.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+
+	// bodies contains the request body schemas of the routes. It is
+	// keyed by the method and the route path that the route was added
+	// with.
+	bodies map[string]*Schema
+}
+
+// Info contains the API metadata.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Tag groups the operations of an API.
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem contains the operations of a path. It is keyed by the lowercase
+// HTTP method.
+type PathItem map[string]*Operation
+
+// Operation describes a single API route.
+type Operation struct {
+	Tags        []string             `json:"tags,omitempty"`
+	OperationID string               `json:"operationId"`
+	Deprecated  bool                 `json:"deprecated,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter describes a path or a query parameter.
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"` // "path" or "query"
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody describes a request body.
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes a reply.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType contains the schema of a request or reply body.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components contains the schemas that are referenced by the operations.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Schema is a JSON schema. Only the subset of the OpenAPI schema object that
+// is required to describe the API types is supported. A schema without a type
+// accepts any value.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Route describes an API route that is added to a document.
+type Route struct {
+	Method string
+
+	// Path is the full route path, including the API prefix. Gorilla
+	// mux path variables are converted into path parameters.
+	Path string
+
+	Tag        string
+	Deprecated bool
+
+	// Request is the request type. It is the request body of POST and
+	// PUT routes and the query parameters of GET routes. The schema
+	// struct tags are used for the query parameter names when present.
+	// Nil indicates that the route does not have any input.
+	Request interface{}
+
+	// Reply is the reply type.
+	Reply interface{}
+
+	// ErrorReply is the type of the reply that is returned with a 400
+	// on user errors.
+	ErrorReply interface{}
+}
+
+// New returns a new Document.
+func New(title, description, version string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       title,
+			Description: description,
+			Version:     version,
+		},
+		Paths: make(map[string]PathItem, 128),
+		Components: Components{
+			Schemas: make(map[string]*Schema, 256),
+		},
+		bodies: make(map[string]*Schema, 128),
+	}
+}
+
+// AddTag adds a tag to the document.
+func (d *Document) AddTag(name, description string) {
+	d.Tags = append(d.Tags, Tag{
+		Name:        name,
+		Description: description,
+	})
+}
+
+// AddRoute adds a route to the document.
+func (d *Document) AddRoute(r Route) {
+	path, params := pathParams(r.Path)
+
+	op := Operation{
+		OperationID: operationID(r.Method, path),
+		Deprecated:  r.Deprecated,
+		Parameters:  params,
+		Responses:   make(map[string]*Response, 2),
+	}
+	if r.Tag != "" {
+		op.Tags = []string{r.Tag}
+	}
+
+	// Add the request
+	if r.Request != nil {
+		switch r.Method {
+		case http.MethodPost, http.MethodPut:
+			s := d.schema(reflect.TypeOf(r.Request))
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content: map[string]MediaType{
+					contentTypeJSON: {Schema: s},
+				},
+			}
+			d.bodies[bodyKey(r.Method, r.Path)] = s
+		default:
+			op.Parameters = append(op.Parameters,
+				d.queryParams(reflect.TypeOf(r.Request), params)...)
+		}
+	}
+
+	// Add the replies
+	success := &Response{
+		Description: "Success",
+	}
+	if r.Reply != nil {
+		success.Content = map[string]MediaType{
+			contentTypeJSON: {Schema: d.schema(reflect.TypeOf(r.Reply))},
+		}
+	}
+	op.Responses["200"] = success
+	if r.ErrorReply != nil {
+		op.Responses["400"] = &Response{
+			Description: "User error",
+			Content: map[string]MediaType{
+				contentTypeJSON: {
+					Schema: d.schema(reflect.TypeOf(r.ErrorReply)),
+				},
+			},
+		}
+	}
+
+	item, ok := d.Paths[path]
+	if !ok {
+		item = make(PathItem, 1)
+		d.Paths[path] = item
+	}
+	item[strings.ToLower(r.Method)] = &op
+}
+
+// HasRequestBody returns whether the route has a request body schema. The
+// path must be the path that the route was added with.
+func (d *Document) HasRequestBody(method, path string) bool {
+	_, ok := d.bodies[bodyKey(method, path)]
+	return ok
+}
+
+// bodyKey returns the key of a request body schema.
+func bodyKey(method, path string) string {
+	return method + " " + path
+}
+
+// pathParams converts the gorilla mux path variables of a route path into
+// OpenAPI path parameters. The converted path and the parameters are
+// returned. Ex: "/proposals/{token:[A-z0-9]{64}}" is converted into
+// "/proposals/{token}".
+func pathParams(route string) (string, []Parameter) {
+	var (
+		path   strings.Builder
+		params []Parameter
+	)
+	for i := 0; i < len(route); i++ {
+		if route[i] != '{' {
+			path.WriteByte(route[i])
+			continue
+		}
+
+		// Parse the variable name and skip the pattern. The pattern
+		// may contain braces of its own.
+		var (
+			name   strings.Builder
+			inName = true
+			depth  = 1
+		)
+		for i++; i < len(route) && depth > 0; i++ {
+			switch c := route[i]; {
+			case c == '{':
+				depth++
+			case c == '}':
+				depth--
+			case c == ':' && depth == 1:
+				inName = false
+			case inName:
+				name.WriteByte(c)
+			}
+		}
+		i--
+
+		path.WriteString("{" + name.String() + "}")
+		params = append(params, Parameter{
+			Name:     name.String(),
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+	return path.String(), params
+}
+
+// operationID returns the operation ID of a route. Ex: "post /records/v1/new"
+// returns "post_records_v1_new".
+func operationID(method, path string) string {
+	r := strings.NewReplacer("/", "_", "{", "", "}", "")
+	return strings.ToLower(method) + r.Replace(path)
+}
+
+// queryParams returns the query parameters of a GET request type. Fields
+// whose names are the same as a path parameter are skipped.
+func (d *Document) queryParams(t reflect.Type, pathParams []Parameter) []Parameter {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	skip := make(map[string]struct{}, len(pathParams))
+	for _, v := range pathParams {
+		skip[v.Name] = struct{}{}
+	}
+	params := make([]Parameter, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			// Unexported
+			continue
+		}
+		name := tagName(f.Tag.Get("schema"))
+		if name == "" {
+			name = tagName(f.Tag.Get("json"))
+		}
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if _, ok := skip[name]; ok {
+			continue
+		}
+		params = append(params, Parameter{
+			Name:   name,
+			In:     "query",
+			Schema: d.schema(f.Type),
+		})
+	}
+	return params
+}
+
+// tagName returns the name portion of a struct tag value.
+func tagName(tag string) string {
+	return strings.Split(tag, ",")[0]
+}
+
+var (
+	// unmarshalerType is the type of the json.Unmarshaler interface.
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// schemaName returns the component schema name of a named type. The API
+// packages are all named v1, so the name includes the API name. Ex: the New
+// type of the records v1 API is named "records.v1.New".
+func schemaName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/api/"); i != -1 {
+		pkg = pkg[i+len("/api/"):]
+	} else if i := strings.LastIndex(pkg, "/"); i != -1 {
+		pkg = pkg[i+1:]
+	}
+	return strings.ReplaceAll(pkg, "/", ".") + "." + t.Name()
+}
+
+// intRange returns the minimum and maximum values of an integer kind.
+func intRange(k reflect.Kind) (float64, float64, string) {
+	switch k {
+	case reflect.Int8:
+		return math.MinInt8, math.MaxInt8, "int32"
+	case reflect.Int16:
+		return math.MinInt16, math.MaxInt16, "int32"
+	case reflect.Int32:
+		return math.MinInt32, math.MaxInt32, "int32"
+	case reflect.Uint8:
+		return 0, math.MaxUint8, "int32"
+	case reflect.Uint16:
+		return 0, math.MaxUint16, "int32"
+	case reflect.Uint32:
+		return 0, math.MaxUint32, "int64"
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		// The maximum can't be represented by a float64. It is
+		// enforced by the validation instead.
+		return 0, 0, "int64"
+	}
+	return 0, 0, "int64"
+}
+
+// schema returns the schema of a type. The schemas of named struct types are
+// added to the document components and a reference is returned.
+func (d *Document) schema(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Interface {
+		return &Schema{}
+	}
+	if t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(unmarshalerType) {
+		// The type decodes itself, e.g. json.RawMessage. Its
+		// encoding can't be derived.
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return d.schema(t.Elem())
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		s := &Schema{Type: "integer"}
+		min, max, format := intRange(t.Kind())
+		s.Format = format
+		if min != 0 || max != 0 {
+			s.Minimum, s.Maximum = &min, &max
+		}
+		return s
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		s := &Schema{Type: "integer"}
+		min, max, format := intRange(t.Kind())
+		s.Format = format
+		s.Minimum = &min
+		if max != 0 {
+			s.Maximum = &max
+		}
+		return s
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// Byte slices are base64 encoded
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: d.schema(t.Elem())}
+	case reflect.Array:
+		return &Schema{Type: "array", Items: d.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{
+			Type:                 "object",
+			AdditionalProperties: d.schema(t.Elem()),
+		}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.structSchema(t)
+		}
+		name := schemaName(t)
+		if _, ok := d.Components.Schemas[name]; !ok {
+			// Add a placeholder first so that recursive types
+			// terminate.
+			d.Components.Schemas[name] = &Schema{}
+			*d.Components.Schemas[name] = *d.structSchema(t)
+		}
+		return &Schema{Ref: schemaRefPrefix + name}
+	}
+
+	// Channels, functions, etc. are not encoded
+	return &Schema{}
+}
+
+// structSchema returns the object schema of a struct type. The fields of
+// embedded structs are promoted, the same as they are by the JSON encoding.
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema, t.NumField()),
+	}
+	d.structFields(t, s.Properties)
+	return s
+}
+
+// structFields adds the properties of the fields of a struct type to the
+// provided properties.
+func (d *Document) structFields(t reflect.Type, props map[string]*Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := tagName(tag)
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			d.structFields(ft, props)
+			continue
+		}
+		if f.PkgPath != "" {
+			// Unexported
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		fs := d.schema(f.Type)
+		if strings.Contains(tag, ",string") {
+			// The value is encoded as a JSON string
+			fs = &Schema{Type: "string"}
+		}
+		props[name] = fs
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+type testVote struct {
+	Token   string `json:"token"`
+	VoteBit uint32 `json:"votebit"`
+}
+
+type testBallot struct {
+	Votes     []testVote        `json:"votes"`
+	Signature []byte            `json:"signature"`
+	Confirm   bool              `json:"confirm"`
+	Metadata  map[string]int8   `json:"metadata"`
+	Counts    map[string]uint64 `json:"counts"`
+}
+
+func TestPathParams(t *testing.T) {
+	path, params := pathParams("/proposals/{token:[A-z0-9]{7,64}}/votes")
+	if path != "/proposals/{token}/votes" {
+		t.Fatalf("got path %v", path)
+	}
+	if len(params) != 1 || params[0].Name != "token" {
+		t.Fatalf("got params %v", params)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	d := New("test", "", "1")
+	d.AddRoute(Route{
+		Method:  http.MethodPost,
+		Path:    "/ballot",
+		Request: testBallot{},
+	})
+
+	var tests = []struct {
+		name  string
+		body  string
+		field string // Empty when the body is valid
+	}{
+		{"empty", "", ""},
+		{"valid", `{"votes":[{"token":"abc","votebit":1}],"confirm":true}`, ""},
+		{"null", `{"votes":null,"signature":null}`, ""},
+		{"unknown property", `{"foo":1}`, ""},
+		{"case insensitive", `{"CONFIRM":"yes"}`, "CONFIRM"},
+		{"malformed", `{"votes":`, "-"},
+		{"bad type", `{"confirm":1}`, "confirm"},
+		{"nested", `{"votes":[{},{"votebit":"1"}]}`, "votes[1].votebit"},
+		{"negative", `{"votes":[{"votebit":-1}]}`, "votes[0].votebit"},
+		{"overflow", `{"votes":[{"votebit":4294967296}]}`, "votes[0].votebit"},
+		{"fraction", `{"votes":[{"votebit":1.5}]}`, "votes[0].votebit"},
+		{"base64", `{"signature":"!!"}`, "signature"},
+		{"map", `{"metadata":{"a":1,"b":128}}`, "metadata.b"},
+		{"uint64", `{"counts":{"a":18446744073709551615}}`, ""},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			err := d.Validate(http.MethodPost, "/ballot", []byte(v.body))
+			switch {
+			case v.field == "" && err != nil:
+				t.Fatalf("got error %v, want nil", err)
+			case v.field == "":
+				return
+			}
+			var ve ValidationError
+			if !errors.As(err, &ve) {
+				t.Fatalf("got error %v, want ValidationError", err)
+			}
+			if v.field != "-" && ve.Field != v.field {
+				t.Fatalf("got field %v, want %v", ve.Field, v.field)
+			}
+		})
+	}
+
+	// Routes without a request body are not validated
+	err := d.Validate(http.MethodPost, "/other", []byte("{"))
+	if err != nil {
+		t.Fatalf("got error %v, want nil", err)
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ValidationError is returned when a request body does not match the request
+// body schema of the route.
+type ValidationError struct {
+	Field  string // JSON path of the invalid value; empty for the body
+	Reason string
+}
+
+// Error satisfies the error interface.
+func (e ValidationError) Error() string {
+	if e.Field == "" {
+		return e.Reason
+	}
+	return e.Field + ": " + e.Reason
+}
+
+// Validate validates a request body against the request body schema of the
+// route. The path must be the path that the route was added with. Nil is
+// returned for routes that do not have a request body schema and for empty
+// bodies, which are left to the request handlers.
+//
+// Unknown properties are allowed and property names are matched case
+// insensitively, the same as they are by the JSON decoding of the request
+// handlers.
+func (d *Document) Validate(method, path string, body []byte) error {
+	s, ok := d.bodies[bodyKey(method, path)]
+	if !ok {
+		return nil
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v interface{}
+	err := dec.Decode(&v)
+	if err != nil {
+		return ValidationError{
+			Reason: fmt.Sprintf("malformed json: %v", err),
+		}
+	}
+
+	return d.validate(s, v, "")
+}
+
+// resolve returns the component schema that a schema references.
+func (d *Document) resolve(s *Schema) (*Schema, error) {
+	for s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, schemaRefPrefix)
+		r, ok := d.Components.Schemas[name]
+		if !ok {
+			return nil, fmt.Errorf("schema not found: %v", s.Ref)
+		}
+		s = r
+	}
+	return s, nil
+}
+
+// validate validates a decoded JSON value against a schema. A null value is
+// always valid since it leaves the decoded field unchanged.
+func (d *Document) validate(s *Schema, v interface{}, field string) error {
+	if v == nil {
+		return nil
+	}
+	s, err := d.resolve(s)
+	if err != nil {
+		return err
+	}
+
+	invalid := func(want string) error {
+		return ValidationError{
+			Field:  field,
+			Reason: fmt.Sprintf("got %v, want %v", jsonType(v), want),
+		}
+	}
+
+	switch s.Type {
+	case "":
+		// Any value is valid
+		return nil
+
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return invalid("boolean")
+		}
+
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return invalid("string")
+		}
+		if s.Format == "byte" {
+			_, err := base64.StdEncoding.DecodeString(str)
+			if err != nil {
+				return ValidationError{
+					Field:  field,
+					Reason: "invalid base64",
+				}
+			}
+		}
+
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			return invalid("integer")
+		}
+		return validateInteger(s, n, field)
+
+	case "number":
+		n, ok := v.(json.Number)
+		if !ok {
+			return invalid("number")
+		}
+		if _, err := n.Float64(); err != nil {
+			return ValidationError{
+				Field:  field,
+				Reason: "number out of range",
+			}
+		}
+
+	case "array":
+		a, ok := v.([]interface{})
+		if !ok {
+			return invalid("array")
+		}
+		if s.Items == nil {
+			return nil
+		}
+		for i, e := range a {
+			err := d.validate(s.Items, e, field+"["+strconv.Itoa(i)+"]")
+			if err != nil {
+				return err
+			}
+		}
+
+	case "object":
+		o, ok := v.(map[string]interface{})
+		if !ok {
+			return invalid("object")
+		}
+		for k, e := range o {
+			ps := property(s, k)
+			if ps == nil {
+				// Unknown properties are ignored
+				continue
+			}
+			f := k
+			if field != "" {
+				f = field + "." + k
+			}
+			err := d.validate(ps, e, f)
+			if err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("unsupported schema type %v", s.Type)
+	}
+
+	return nil
+}
+
+// property returns the schema of an object property. Property names are
+// matched case insensitively when there is no exact match. The additional
+// properties schema is returned for objects that are maps.
+func property(s *Schema, name string) *Schema {
+	if s.AdditionalProperties != nil {
+		return s.AdditionalProperties
+	}
+	if ps, ok := s.Properties[name]; ok {
+		return ps
+	}
+	for k, ps := range s.Properties {
+		if strings.EqualFold(k, name) {
+			return ps
+		}
+	}
+	return nil
+}
+
+// validateInteger validates that a JSON number is an integer that is within
+// the range of the schema.
+func validateInteger(s *Schema, n json.Number, field string) error {
+	outOfRange := ValidationError{
+		Field:  field,
+		Reason: fmt.Sprintf("integer %v out of range", n),
+	}
+	str := n.String()
+	if strings.ContainsAny(str, ".eE") {
+		return ValidationError{
+			Field:  field,
+			Reason: fmt.Sprintf("got number %v, want integer", n),
+		}
+	}
+
+	// Unsigned integers
+	if s.Minimum != nil && *s.Minimum >= 0 {
+		u, err := strconv.ParseUint(str, 10, 64)
+		if err != nil {
+			return outOfRange
+		}
+		if s.Maximum != nil && float64(u) > *s.Maximum {
+			return outOfRange
+		}
+		return nil
+	}
+
+	// Signed integers
+	i, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return outOfRange
+	}
+	if s.Minimum != nil && float64(i) < *s.Minimum {
+		return outOfRange
+	}
+	if s.Maximum != nil && float64(i) > *s.Maximum {
+		return outOfRange
+	}
+	return nil
+}
+
+// jsonType returns the JSON type name of a decoded JSON value.
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "null"
+}
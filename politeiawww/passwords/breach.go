@@ -0,0 +1,70 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package passwords
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// breachPrefixLength is the number of hex characters of the SHA-1
+	// hash of a password that are sent to the breach service.
+	breachPrefixLength = 5
+
+	// breachRangePath is the path of the range API of the breach
+	// service. The hash prefix is appended to it.
+	breachRangePath = "/range/"
+)
+
+// breachClient looks up passwords using the k-anonymity range API of a Pwned
+// Passwords compatible service.
+type breachClient struct {
+	url  string
+	http *http.Client
+}
+
+// breached returns whether the password has appeared in a data breach. The
+// service returns the suffixes of all breached hashes that share the prefix,
+// along with the number of times they have been seen. Padded entries that
+// have a count of zero are ignored.
+func (c *breachClient) breached(password string) (bool, error) {
+	h := sha1.Sum([]byte(password))
+	digest := strings.ToUpper(hex.EncodeToString(h[:]))
+	prefix, suffix := digest[:breachPrefixLength], digest[breachPrefixLength:]
+
+	url := strings.TrimSuffix(c.url, "/") + breachRangePath + prefix
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Add-Padding", "true")
+	r, err := c.http.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("%v", r.Status)
+	}
+
+	s := bufio.NewScanner(r.Body)
+	for s.Scan() {
+		// Each line uses the format SUFFIX:COUNT
+		line := strings.TrimSpace(s.Text())
+		i := strings.IndexByte(line, ':')
+		if i == -1 {
+			continue
+		}
+		if strings.EqualFold(line[:i], suffix) {
+			return strings.TrimLeft(line[i+1:], "0") != "", nil
+		}
+	}
+	return false, s.Err()
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package passwords implements the password policy of politeiawww. A password
+// must satisfy a minimum length and a minimum strength score, and can
+// optionally be checked against a list of breached passwords.
+//
+// The strength score is an estimate of the number of guesses that are needed
+// to crack the password, in the style of zxcvbn. Scores range from 0 (too
+// guessable) to 4 (very unguessable).
+//
+// The breach check uses the k-anonymity range API of the Pwned Passwords
+// service. Only the first five characters of the SHA-1 hash of the password
+// are sent to the service.
+package passwords
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	// ScoreMax is the maximum password strength score.
+	ScoreMax = 4
+
+	// DefaultBreachURL is the URL of the public Pwned Passwords range API.
+	DefaultBreachURL = "https://api.pwnedpasswords.com"
+
+	// breachTimeout is the timeout of a breach lookup.
+	breachTimeout = 5 * time.Second
+)
+
+var (
+	// ErrTooShort is returned when a password is shorter than the
+	// minimum length.
+	ErrTooShort = errors.New("password is too short")
+
+	// ErrTooWeak is returned when the strength score of a password is
+	// below the minimum score.
+	ErrTooWeak = errors.New("password is too easy to guess")
+
+	// ErrBreached is returned when a password has appeared in a data
+	// breach.
+	ErrBreached = errors.New("password has appeared in a data breach")
+)
+
+// Policy contains the password policy settings.
+type Policy struct {
+	MinLength   uint // Minimum number of characters
+	MinScore    uint // Minimum strength score, 0 to ScoreMax
+	BreachCheck bool // Passwords are checked against breaches
+}
+
+// Checker verifies that passwords satisfy the password policy.
+type Checker struct {
+	policy Policy
+	breach *breachClient // Nil when the breach check is disabled
+}
+
+// New returns a new Checker. The breach check is disabled when the breach URL
+// is empty.
+func New(minLength, minScore uint, breachURL string) *Checker {
+	c := Checker{
+		policy: Policy{
+			MinLength:   minLength,
+			MinScore:    minScore,
+			BreachCheck: breachURL != "",
+		},
+	}
+	if breachURL != "" {
+		c.breach = &breachClient{
+			url: breachURL,
+			http: &http.Client{
+				Timeout: breachTimeout,
+			},
+		}
+	}
+	return &c
+}
+
+// Policy returns the password policy.
+func (c *Checker) Policy() Policy {
+	return c.policy
+}
+
+// Check verifies that the password satisfies the password policy. The user
+// inputs, e.g. the username and the email address, are treated as guessable
+// when the strength score of the password is estimated.
+//
+// The password must be checked before it is hashed. The politeiawww clients
+// send a digest of the password, which always passes the policy, so the
+// clients are expected to run this check on the plain text password using
+// the policy that is returned by the policy route.
+//
+// A breach lookup that fails is logged and does not fail the check so that
+// users can still register when the breach service is unavailable.
+func (c *Checker) Check(password string, userInputs ...string) error {
+	if uint(len([]rune(password))) < c.policy.MinLength {
+		return ErrTooShort
+	}
+	if c.policy.MinScore > 0 &&
+		Score(password, userInputs...) < c.policy.MinScore {
+		return ErrTooWeak
+	}
+	if c.breach != nil {
+		breached, err := c.breach.breached(password)
+		if err != nil {
+			log.Warnf("Password breach lookup failed: %v", err)
+			return nil
+		}
+		if breached {
+			return ErrBreached
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package passwords
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+	var tests = []struct {
+		name       string
+		password   string
+		userInputs []string
+		score      uint
+	}{
+		{"common password", "password", nil, 0},
+		{"leet common password", "P@ssw0rd", nil, 0},
+		{"keyboard pattern", "qwertyuiop", nil, 0},
+		{"sequence", "abcdefgh", nil, 0},
+		{"repeat", "aaaaaaaaaaaa", nil, 0},
+		{"random", "0e7b9a5cf2d14c83", nil, 4},
+		{"passphrase", "correcthorsebatterystaple", nil, 4},
+		{"user input", "alicealice", []string{"alice@example.com"}, 0},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			score := Score(v.password, v.userInputs...)
+			if score != v.score {
+				t.Fatalf("got score %v, want %v", score, v.score)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	// The SHA-1 hash of "password" is
+	// 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8.
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/range/5BAA6" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, "0018A45C4D1DEF81644B54AB7F969B88D65:0\r\n")
+		fmt.Fprint(w, "1E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493\r\n")
+	}))
+	defer s.Close()
+
+	var tests = []struct {
+		name     string
+		checker  *Checker
+		password string
+		want     error
+	}{
+		{"too short", New(8, 0, ""), "abc", ErrTooShort},
+		{"too weak", New(8, 2, ""), "password", ErrTooWeak},
+		{"breached", New(8, 0, s.URL), "password", ErrBreached},
+		{"not breached", New(8, 0, s.URL), "0e7b9a5cf2d14c83", nil},
+		{"lookup failure", New(8, 0, "http://127.0.0.1:0"), "password", nil},
+		{"valid", New(8, 2, ""), "correcthorsebatterystaple", nil},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			err := v.checker.Check(v.password)
+			if !errors.Is(err, v.want) {
+				t.Fatalf("got error %v, want %v", err, v.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package passwords
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+const (
+	// scoreLengthMax is the number of characters of a password that
+	// are scored. Passwords that are longer than this are given the
+	// maximum score.
+	scoreLengthMax = 256
+
+	// patternLengthMin is the minimum length of a repeat, sequence, or
+	// keyboard pattern.
+	patternLengthMin = 3
+
+	// wordLengthMin is the minimum length of a dictionary word or a
+	// user input that is matched.
+	wordLengthMin = 3
+)
+
+// scoreThresholds contains the log10 of the number of guesses that are
+// required for each score above zero. These match the zxcvbn thresholds.
+var scoreThresholds = []float64{3, 6, 8, 10}
+
+// commonPasswords contains the most common passwords, most common first. The
+// position of a password in the list is its rank.
+var commonPasswords = []string{
+	"password", "123456", "qwerty", "letmein", "welcome", "monkey",
+	"dragon", "football", "baseball", "iloveyou", "trustno1", "sunshine",
+	"master", "shadow", "superman", "princess", "admin", "login",
+	"abc123", "starwars", "whatever", "freedom", "passw0rd", "hello",
+	"charlie", "donald", "michael", "jordan", "jennifer", "hunter",
+	"buster", "soccer", "harley", "batman", "andrew", "tigger", "ranger",
+	"thomas", "robert", "daniel", "hockey", "killer", "george", "secret",
+	"summer", "winter", "spring", "autumn", "ashley", "bailey", "access",
+	"mustang", "michelle", "maggie", "pepper", "ginger", "cookie",
+	"cheese", "computer", "internet", "matrix", "orange", "purple",
+	"silver", "golden", "flower", "lovely", "loveme", "family", "friends",
+	"pokemon", "banana", "chocolate", "liverpool", "arsenal", "chelsea",
+	"qazwsx", "zaq1", "asdf", "test", "guest", "root", "user", "pass",
+	"love", "god", "sex", "money", "bitcoin", "decred", "politeia",
+	"proposal", "crypto", "wallet",
+}
+
+// commonRanks contains the rank of each common password.
+var commonRanks = func() map[string]int {
+	m := make(map[string]int, len(commonPasswords))
+	for i, v := range commonPasswords {
+		m[v] = i + 1
+	}
+	return m
+}()
+
+// keyboardRows contains the rows of a QWERTY keyboard.
+var keyboardRows = []string{
+	"`1234567890-=",
+	"qwertyuiop[]\\",
+	"asdfghjkl;'",
+	"zxcvbnm,./",
+	"qazwsxedcrfvtgbyhnujmik,ol.p;/",
+}
+
+// leetSubstitutions contains the common character substitutions.
+var leetSubstitutions = map[rune]rune{
+	'0': 'o',
+	'1': 'l',
+	'!': 'i',
+	'3': 'e',
+	'4': 'a',
+	'@': 'a',
+	'5': 's',
+	'$': 's',
+	'7': 't',
+	'+': 't',
+	'9': 'g',
+}
+
+// Score returns the strength score of the password. The score is derived from
+// an estimate of the number of guesses that are needed to crack the password.
+// The estimate breaks the password into common passwords, user inputs,
+// repeated characters, character sequences, and keyboard patterns. The
+// remaining characters are treated as random characters from the character
+// classes that the password uses.
+func Score(password string, userInputs ...string) uint {
+	runes := []rune(password)
+	if len(runes) > scoreLengthMax {
+		return ScoreMax
+	}
+	guesses := log10Guesses(runes, userInputs)
+	var score uint
+	for _, v := range scoreThresholds {
+		if guesses < v {
+			break
+		}
+		score++
+	}
+	return score
+}
+
+// log10Guesses returns the log10 of the estimated number of guesses that are
+// needed to crack the password.
+func log10Guesses(password []rune, userInputs []string) float64 {
+	var (
+		lower    = make([]rune, len(password))
+		unleeted = make([]rune, len(password))
+		words    = dictionary(userInputs)
+		pool     = math.Log10(cardinality(password))
+		guesses  float64
+	)
+	for i, r := range password {
+		r = unicode.ToLower(r)
+		lower[i] = r
+		if s, ok := leetSubstitutions[r]; ok {
+			r = s
+		}
+		unleeted[i] = r
+	}
+
+	for i := 0; i < len(password); {
+		n, g := matchWord(password, lower, unleeted, i, words)
+		if rn, rg := matchRepeat(lower, i); rn > n {
+			n, g = rn, rg
+		}
+		if sn, sg := matchSequence(lower, i); sn > n {
+			n, g = sn, sg
+		}
+		if kn, kg := matchKeyboard(lower, i); kn > n {
+			n, g = kn, kg
+		}
+		if n == 0 {
+			// Random character
+			guesses += pool
+			i++
+			continue
+		}
+		guesses += math.Log10(math.Max(g, 1))
+		i += n
+	}
+
+	return guesses
+}
+
+// dictionary returns the ranked words that are matched against a password.
+// The user inputs are given the lowest rank since they are the first guesses
+// of an attacker that targets the user. Email addresses are split so that the
+// local part is matched as well.
+func dictionary(userInputs []string) map[string]int {
+	words := make(map[string]int, len(commonRanks)+len(userInputs)*2)
+	for k, v := range commonRanks {
+		words[k] = v
+	}
+	for _, v := range userInputs {
+		v = strings.ToLower(strings.TrimSpace(v))
+		words[v] = 1
+		if i := strings.IndexByte(v, '@'); i > 0 {
+			words[v[:i]] = 1
+		}
+	}
+	for k := range words {
+		if len([]rune(k)) < wordLengthMin {
+			delete(words, k)
+		}
+	}
+	return words
+}
+
+// matchWord returns the length and the number of guesses of the longest
+// dictionary word that starts at the index. The most common word is used
+// when multiple words have the same length. Words that use upper case or
+// character substitutions require additional guesses.
+func matchWord(password, lower, unleeted []rune, i int, words map[string]int) (int, float64) {
+	var (
+		n    int
+		rank int
+		g    float64
+	)
+	for word, r := range words {
+		w := []rune(word)
+		switch {
+		case i+len(w) > len(password):
+			continue
+		case len(w) < n, len(w) == n && r >= rank:
+			continue
+		case string(unleeted[i:i+len(w)]) != word:
+			continue
+		}
+		n = len(w)
+		rank = r
+		g = float64(r)
+		if string(lower[i:i+n]) != string(password[i:i+n]) {
+			// Upper case variation
+			g *= 2
+		}
+		if string(lower[i:i+n]) != word {
+			// Substitution variation
+			g *= 2
+		}
+	}
+	return n, g
+}
+
+// matchRepeat returns the length and the number of guesses of a run of a
+// repeated character that starts at the index.
+func matchRepeat(lower []rune, i int) (int, float64) {
+	n := 1
+	for i+n < len(lower) && lower[i+n] == lower[i] {
+		n++
+	}
+	if n < patternLengthMin {
+		return 0, 0
+	}
+	return n, cardinality(lower[i:i+1]) * float64(n)
+}
+
+// matchSequence returns the length and the number of guesses of an ascending
+// or descending character sequence, e.g. abcd or 4321, that starts at the
+// index.
+func matchSequence(lower []rune, i int) (int, float64) {
+	if i+1 >= len(lower) {
+		return 0, 0
+	}
+	step := lower[i+1] - lower[i]
+	if step != 1 && step != -1 {
+		return 0, 0
+	}
+	n := 2
+	for i+n < len(lower) && lower[i+n]-lower[i+n-1] == step {
+		n++
+	}
+	if n < patternLengthMin {
+		return 0, 0
+	}
+
+	// Sequences that start with an obvious character are guessed
+	// first.
+	var base float64
+	switch start := lower[i]; {
+	case strings.ContainsRune("az019", start):
+		base = 4
+	case unicode.IsDigit(start):
+		base = 10
+	default:
+		base = 26
+	}
+	if step == -1 {
+		base *= 2
+	}
+	return n, base * float64(n)
+}
+
+// matchKeyboard returns the length and the number of guesses of the longest
+// run of adjacent keys, e.g. qwerty, that starts at the index.
+func matchKeyboard(lower []rune, i int) (int, float64) {
+	var n int
+	for _, row := range keyboardRows {
+		for _, r := range []string{row, reverse(row)} {
+			m := 0
+			for i+m < len(lower) &&
+				strings.Contains(r, string(lower[i:i+m+1])) {
+				m++
+			}
+			if m > n {
+				n = m
+			}
+		}
+	}
+	if n <= patternLengthMin {
+		// Short keyboard runs are common in random strings
+		return 0, 0
+	}
+	return n, float64(len(keyboardRows)*2) * float64(n)
+}
+
+// cardinality returns the size of the character set of the character classes
+// that are used by the password.
+func cardinality(password []rune) float64 {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range password {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII:
+			symbol = true
+		default:
+			other = true
+		}
+	}
+	var c float64
+	if lower {
+		c += 26
+	}
+	if upper {
+		c += 26
+	}
+	if digit {
+		c += 10
+	}
+	if symbol {
+		c += 33
+	}
+	if other {
+		c += 100
+	}
+	return math.Max(c, 1)
+}
+
+// reverse returns the reversed string.
+func reverse(s string) string {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r)
+}
@@ -13,15 +13,33 @@ import (
 	tkplugin "github.com/decred/politeia/politeiad/plugins/ticketvote"
 	umplugin "github.com/decred/politeia/politeiad/plugins/usermd"
 	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	elv1 "github.com/decred/politeia/politeiawww/api/eventlog/v1"
+	oav1 "github.com/decred/politeia/politeiawww/api/oauth/v1"
 	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
 	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	tmv1 "github.com/decred/politeia/politeiawww/api/telemetry/v1"
 	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
 	www "github.com/decred/politeia/politeiawww/api/www/v1"
 	"github.com/decred/politeia/politeiawww/comments"
+	"github.com/decred/politeia/politeiawww/eventlog"
+	"github.com/decred/politeia/politeiawww/oauth"
 	"github.com/decred/politeia/politeiawww/pi"
 	"github.com/decred/politeia/politeiawww/records"
+	"github.com/decred/politeia/politeiawww/telemetry"
 	"github.com/decred/politeia/politeiawww/ticketvote"
+	"github.com/decred/politeia/politeiawww/webhook"
+	"github.com/decred/politeia/util"
 	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// legacyProposalRoute and legacyCommentRoute are the proposal and
+	// comment permalinks of the legacy politeiagui. They are redirected to
+	// the legacy site.
+	legacyProposalRoute = "/proposals/{token:[A-Fa-f0-9]{7,64}}"
+	legacyCommentRoute  = legacyProposalRoute +
+		"/comments/{commentid:[0-9]+}"
 )
 
 // setupPiRoutes sets up the API routes for piwww mode.
@@ -30,12 +48,20 @@ func (p *politeiawww) setupPiRoutes(r *records.Records, c *comments.Comments, t
 	p.router.NotFoundHandler = http.HandlerFunc(p.handleNotFound)
 
 	// The version routes set the CSRF token and thus need to be part
-	// of the CSRF protected auth router.
-	p.auth.HandleFunc("/", p.handleVersion).Methods(http.MethodGet)
+	// of the CSRF protected auth router. The root route serves the web
+	// frontend instead when it has been enabled.
+	if p.cfg.WebRoot == "" {
+		p.auth.HandleFunc("/", p.handleVersion).Methods(http.MethodGet)
+	}
 	p.auth.StrictSlash(true).
 		HandleFunc(www.PoliteiaWWWAPIRoute+www.RouteVersion, p.handleVersion).
 		Methods(http.MethodGet)
 
+	// The policies route aggregates the www and plugin API policies
+	p.addRoute(http.MethodGet, www.PoliteiaWWWAPIRoute,
+		www.RoutePolicies, p.handlePolicies(c, t, pic),
+		permissionPublic)
+
 	// Legacy www routes. These routes have been DEPRECATED. Support
 	// will be removed in a future release.
 	p.addRoute(http.MethodGet, www.PoliteiaWWWAPIRoute,
@@ -48,13 +74,15 @@ func (p *politeiawww) setupPiRoutes(r *records.Records, c *comments.Comments, t
 		www.RouteAllVetted, p.handleAllVetted,
 		permissionPublic)
 	p.addRoute(http.MethodGet, www.PoliteiaWWWAPIRoute,
-		www.RouteProposalDetails, p.handleProposalDetails,
+		www.RouteProposalDetails,
+		legacyTokenHandler(r, p.handleProposalDetails),
 		permissionPublic)
 	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
 		www.RouteBatchProposals, p.handleBatchProposals,
 		permissionPublic)
 	p.addRoute(http.MethodGet, www.PoliteiaWWWAPIRoute,
-		www.RouteVoteStatus, p.handleVoteStatus,
+		www.RouteVoteStatus,
+		legacyTokenHandler(r, p.handleVoteStatus),
 		permissionPublic)
 	p.addRoute(http.MethodGet, www.PoliteiaWWWAPIRoute,
 		www.RouteAllVoteStatus, p.handleAllVoteStatus,
@@ -66,7 +94,8 @@ func (p *politeiawww) setupPiRoutes(r *records.Records, c *comments.Comments, t
 		www.RouteCastVotes, p.handleCastVotes,
 		permissionPublic)
 	p.addRoute(http.MethodGet, www.PoliteiaWWWAPIRoute,
-		www.RouteVoteResults, p.handleVoteResults,
+		www.RouteVoteResults,
+		legacyTokenHandler(r, p.handleVoteResults),
 		permissionPublic)
 	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
 		www.RouteBatchVoteSummary, p.handleBatchVoteSummary,
@@ -85,6 +114,9 @@ func (p *politeiawww) setupPiRoutes(r *records.Records, c *comments.Comments, t
 	p.addRoute(http.MethodPost, rcv1.APIRoute,
 		rcv1.RouteDetails, r.HandleDetails,
 		permissionPublic)
+	p.addRoute(http.MethodPost, rcv1.APIRoute,
+		rcv1.RouteBatchDetails, r.HandleBatchDetails,
+		permissionPublic)
 	p.addRoute(http.MethodPost, rcv1.APIRoute,
 		rcv1.RouteTimestamps, r.HandleTimestamps,
 		permissionPublic)
@@ -100,6 +132,28 @@ func (p *politeiawww) setupPiRoutes(r *records.Records, c *comments.Comments, t
 	p.addRoute(http.MethodPost, rcv1.APIRoute,
 		rcv1.RouteUserRecords, r.HandleUserRecords,
 		permissionPublic)
+	p.addRoute(http.MethodPost, rcv1.APIRoute,
+		rcv1.RouteLegacyTokens, r.HandleLegacyTokens,
+		permissionPublic)
+
+	// The replies of these routes are also served to GET requests so
+	// that they can be cached and revalidated using their ETag.
+	p.addRoute(http.MethodGet, rcv1.APIRoute,
+		rcv1.RouteDetails, r.HandleDetails,
+		permissionPublic)
+	p.addRoute(http.MethodGet, rcv1.APIRoute,
+		rcv1.RouteTimestamps, r.HandleTimestamps,
+		permissionPublic)
+
+	// Legacy proposal permalink redirects
+	if p.cfg.LegacyRedirectURL != "" {
+		p.router.StrictSlash(true).
+			HandleFunc(legacyProposalRoute, r.HandleLegacyRedirect).
+			Methods(http.MethodGet)
+		p.router.StrictSlash(true).
+			HandleFunc(legacyCommentRoute, r.HandleLegacyRedirect).
+			Methods(http.MethodGet)
+	}
 
 	// Comment routes
 	p.addRoute(http.MethodPost, cmv1.APIRoute,
@@ -126,6 +180,12 @@ func (p *politeiawww) setupPiRoutes(r *records.Records, c *comments.Comments, t
 	p.addRoute(http.MethodPost, cmv1.APIRoute,
 		cmv1.RouteTimestamps, c.HandleTimestamps,
 		permissionPublic)
+	p.addRoute(http.MethodPost, cmv1.APIRoute,
+		cmv1.RouteExport, c.HandleExport,
+		permissionPublic)
+	p.addRoute(http.MethodGet, cmv1.APIRoute,
+		cmv1.RouteTimestamps, c.HandleTimestamps,
+		permissionPublic)
 
 	// Ticket vote routes
 	p.addRoute(http.MethodPost, tkv1.APIRoute,
@@ -158,11 +218,208 @@ func (p *politeiawww) setupPiRoutes(r *records.Records, c *comments.Comments, t
 	p.addRoute(http.MethodPost, tkv1.APIRoute,
 		tkv1.RouteTimestamps, t.HandleTimestamps,
 		permissionPublic)
+	p.addRoute(http.MethodPost, tkv1.APIRoute,
+		tkv1.RouteCertificate, t.HandleCertificate,
+		permissionPublic)
+	p.addRoute(http.MethodPost, tkv1.APIRoute,
+		tkv1.RouteTallies, t.HandleTallies,
+		permissionPublic)
+	p.addRoute(http.MethodGet, tkv1.APIRoute,
+		tkv1.RouteResults, t.HandleResults,
+		permissionPublic)
+	p.addRoute(http.MethodGet, tkv1.APIRoute,
+		tkv1.RouteTimestamps, t.HandleTimestamps,
+		permissionPublic)
 
 	// Pi routes
 	p.addRoute(http.MethodPost, piv1.APIRoute,
 		piv1.RoutePolicy, pic.HandlePolicy,
 		permissionPublic)
+	p.addRoute(http.MethodPost, piv1.APIRoute,
+		piv1.RoutePreflight, pic.HandlePreflight,
+		permissionLogin)
+	p.addRoute(http.MethodPost, piv1.APIRoute,
+		piv1.RouteSimilar, pic.HandleSimilar,
+		permissionAdmin)
+	p.addRoute(http.MethodPost, piv1.APIRoute,
+		piv1.RouteSearch, pic.HandleSearch,
+		permissionPublic)
+	p.addRoute(http.MethodPost, piv1.APIRoute,
+		piv1.RouteSetAuthorUpdate, pic.HandleSetAuthorUpdate,
+		permissionLogin)
+	p.addRoute(http.MethodPost, piv1.APIRoute,
+		piv1.RouteAuthorUpdates, pic.HandleAuthorUpdates,
+		permissionPublic)
+	p.addRoute(http.MethodPost, piv1.APIRoute,
+		piv1.RouteWalletSummaries, pic.HandleWalletSummaries,
+		permissionPublic)
+	p.addRoute(http.MethodPost, piv1.APIRoute,
+		piv1.RouteVettingQueue, pic.HandleVettingQueue,
+		permissionAdmin)
+	p.addRoute(http.MethodPost, piv1.APIRoute,
+		piv1.RouteVettingAssign, pic.HandleVettingAssign,
+		permissionAdmin)
+	p.addRoute(http.MethodPost, piv1.APIRoute,
+		piv1.RouteReportNew, pic.HandleReportNew,
+		permissionPublic)
+	p.addRoute(http.MethodPost, piv1.APIRoute,
+		piv1.RouteReports, pic.HandleReports,
+		permissionAdmin)
+	p.addRoute(http.MethodPost, piv1.APIRoute,
+		piv1.RouteReportResolve, pic.HandleReportResolve,
+		permissionAdmin)
+	p.addRoute(http.MethodPost, piv1.APIRoute,
+		piv1.RouteRFP, pic.HandleRFP,
+		permissionPublic)
+	p.addRoute(http.MethodPost, piv1.APIRoute,
+		piv1.RouteRFPParent, pic.HandleRFPParent,
+		permissionPublic)
+	p.addRoute(http.MethodPost, piv1.APIRoute,
+		piv1.RouteRFPRepair, pic.HandleRFPRepair,
+		permissionAdmin)
+	p.addRoute(http.MethodPost, piv1.APIRoute,
+		piv1.RouteLineage, pic.HandleLineage,
+		permissionPublic)
+	p.addRoute(http.MethodGet, piv1.APIRoute,
+		piv1.RouteFeedProposals, pic.HandleFeedProposals,
+		permissionPublic)
+	p.addRoute(http.MethodGet, piv1.APIRoute,
+		piv1.RouteFeedVotes, pic.HandleFeedVotes,
+		permissionPublic)
+	p.addRoute(http.MethodGet, piv1.APIRoute,
+		piv1.RouteFeedComments, pic.HandleFeedComments,
+		permissionPublic)
+
+	// Websocket routes. The pi events are pushed to the authenticated
+	// websockets that have subscribed to them.
+	p.addRoute("", www.PoliteiaWWWAPIRoute,
+		www.RouteUnauthenticatedWebSocket, p.handleUnauthenticatedWebsocket,
+		permissionPublic)
+	p.addRoute("", www.PoliteiaWWWAPIRoute,
+		www.RouteAuthenticatedWebSocket, p.handleAuthenticatedWebsocket,
+		permissionLogin)
+}
+
+// handlePolicies returns the handler for the www Policies route. The reply
+// contains the policies of the www API and of the plugin APIs so that clients
+// are able to retrieve all policies with a single request at startup.
+func (p *politeiawww) handlePolicies(c *comments.Comments, t *ticketvote.TicketVote, pic *pi.Pi) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Tracef("handlePolicies")
+
+		var (
+			cp  = c.Policy()
+			tp  = t.Policy()
+			pip = pic.Policy()
+		)
+		util.RespondWithJSON(w, http.StatusOK, www.PoliciesReply{
+			WWW:        p.policy(),
+			Comments:   &cp,
+			TicketVote: &tp,
+			Pi:         &pip,
+			APIs:       pluginAPIVersions,
+		})
+	}
+}
+
+// legacyTokenHandler returns a handler that replaces a legacy git backend
+// token in the route variables with the tstore token that the proposal was
+// migrated to before invoking the provided handler. This allows the
+// deprecated www routes to be used with the tokens that are referenced by
+// existing links.
+func legacyTokenHandler(rc *records.Records, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		if token, ok := rc.LegacyToken(vars["token"]); ok {
+			vars["token"] = token
+			r = mux.SetURLVars(r, vars)
+		}
+		h(w, r)
+	}
+}
+
+// setupTelemetryRoutes sets up the API routes for the opt-in client telemetry
+// API. Reports are not tied to a user session so that they can't be used to
+// track users.
+func (p *politeiawww) setupTelemetryRoutes(t *telemetry.Telemetry) {
+	p.addRoute(http.MethodPost, tmv1.APIRoute,
+		tmv1.RoutePolicy, t.HandlePolicy,
+		permissionPublic)
+	p.addRoute(http.MethodPost, tmv1.APIRoute,
+		tmv1.RouteReport, t.HandleReport,
+		permissionPublic)
+	p.addRoute(http.MethodPost, tmv1.APIRoute,
+		tmv1.RouteStats, t.HandleStats,
+		permissionAdmin)
+}
+
+// setupOAuthRoutes sets up the API routes that allow third-party applications
+// to access user data once the user has given its consent. The consent routes
+// require a login and are CSRF protected. The token endpoint and the routes
+// that are used by the applications authenticate using client credentials
+// and access tokens instead of a session. The OpenID Connect routes are only
+// set up when an issuer is configured.
+func (p *politeiawww) setupOAuthRoutes(o *oauth.OAuth) {
+	p.addRoute(http.MethodPost, oav1.APIRoute,
+		oav1.RouteClient, o.HandleClient,
+		permissionPublic)
+	p.addRoute(http.MethodPost, oav1.APIRoute,
+		oav1.RouteAuthorize, o.HandleAuthorize,
+		permissionLogin)
+	p.addRoute(http.MethodPost, oav1.APIRoute,
+		oav1.RouteToken, o.HandleToken,
+		permissionPublic)
+	p.addRoute(http.MethodGet, oav1.APIRoute,
+		oav1.RouteMe, o.HandleMe,
+		permissionPublic)
+	p.addRoute(http.MethodGet, oav1.APIRoute,
+		oav1.RouteProposals, o.HandleProposals,
+		permissionPublic)
+	p.addRoute(http.MethodPost, oav1.APIRoute,
+		oav1.RouteGrants, o.HandleGrants,
+		permissionLogin)
+	p.addRoute(http.MethodPost, oav1.APIRoute,
+		oav1.RouteRevoke, o.HandleRevoke,
+		permissionLogin)
+	p.addRoute(http.MethodPost, oav1.APIRoute,
+		oav1.RouteIntrospect, o.HandleIntrospect,
+		permissionPublic)
+
+	// Admin routes
+	p.addRoute(http.MethodPost, oav1.APIRoute,
+		oav1.RouteClients, o.HandleClients,
+		permissionAdmin)
+	p.addRoute(http.MethodPost, oav1.APIRoute,
+		oav1.RouteRegisterClient, o.HandleRegisterClient,
+		permissionAdmin)
+	p.addRoute(http.MethodPost, oav1.APIRoute,
+		oav1.RouteDeleteClient, o.HandleDeleteClient,
+		permissionAdmin)
+
+	// OpenID Connect routes
+	if p.cfg.OAuthIssuer == "" {
+		return
+	}
+	p.addRoute(http.MethodGet, "",
+		oav1.RouteDiscovery, o.HandleDiscovery,
+		permissionPublic)
+	p.addRoute(http.MethodGet, oav1.APIRoute,
+		oav1.RouteJWKS, o.HandleJWKS,
+		permissionPublic)
+	p.addRoute(http.MethodGet, oav1.APIRoute,
+		oav1.RouteUserInfo, o.HandleUserInfo,
+		permissionPublic)
+}
+
+// setupEventLogRoutes sets up the admin API routes that are used to query the
+// event log and to replay recorded events.
+func (p *politeiawww) setupEventLogRoutes(l *eventlog.EventLog) {
+	p.addRoute(http.MethodPost, elv1.APIRoute,
+		elv1.RouteEvents, l.HandleEvents,
+		permissionAdmin)
+	p.addRoute(http.MethodPost, elv1.APIRoute,
+		elv1.RouteReplay, l.HandleReplay,
+		permissionAdmin)
 }
 
 func (p *politeiawww) setupPi() error {
@@ -198,27 +455,123 @@ func (p *politeiawww) setupPi() error {
 	}
 
 	// Setup api contexts
-	recordsCtx := records.New(p.cfg, p.politeiad, p.db, p.sessions, p.events)
+	recordsCtx, err := records.New(p.cfg, p.politeiad, p.db,
+		p.sessions, p.events)
+	if err != nil {
+		return fmt.Errorf("new records api: %v", err)
+	}
 	commentsCtx, err := comments.New(p.cfg, p.politeiad, p.db,
 		p.sessions, p.events, plugins)
 	if err != nil {
 		return fmt.Errorf("new comments api: %v", err)
 	}
+	recordsCtx.SetCommentCounter(commentsCtx)
 	voteCtx, err := ticketvote.New(p.cfg, p.politeiad,
 		p.sessions, p.events, plugins)
 	if err != nil {
 		return fmt.Errorf("new ticketvote api: %v", err)
 	}
+	if p.redis != nil {
+		// The nonces of the signed requests are shared with the
+		// standby instances.
+		commentsCtx.SetNonceStore(p.redis)
+		voteCtx.SetNonceStore(p.redis)
+	}
 	piCtx, err := pi.New(p.cfg, p.politeiad, p.db,
-		p.sessions, p.events, p.mail, plugins)
+		p.sessions, p.events, plugins)
 	if err != nil {
 		return fmt.Errorf("new pi api: %v", err)
 	}
+	recordsCtx.SetLineageProvider(piCtx)
+	wc, err := webhook.New(p.cfg, p.events)
+	if err != nil {
+		return fmt.Errorf("new webhook client: %v", err)
+	}
+	tc, err := webhook.NewTreasury(p.cfg, p.events)
+	if err != nil {
+		return fmt.Errorf("new treasury client: %v", err)
+	}
+	for _, v := range []*webhook.Client{wc, tc} {
+		if v == nil {
+			continue
+		}
+		if p.mailLog != nil {
+			v.SetSendLog(p.mailLog)
+		}
+		p.webhooks = append(p.webhooks, v)
+	}
+
+	// Push the pi events to the subscribed websockets
+	p.setupEventListenersWS()
+
+	// Drop the cached politeiad replies of the records that change
+	if p.cfg.SummaryCacheTTL > 0 {
+		p.setupEventListenersCache()
+	}
+
+	// Setup the configured notification routes. This must be done
+	// after all notifiers have been registered and all default routes
+	// have been setup.
+	for _, v := range p.cfg.Notifiers {
+		event, notifiers, err := parseNotifier(v)
+		if err != nil {
+			return err
+		}
+		err = p.events.SetRoute(event, notifiers...)
+		if err != nil {
+			return fmt.Errorf("notifier %v: %v", v, err)
+		}
+		log.Infof("Notification event %v routed to %v", event, notifiers)
+	}
 
 	// Setup routes
 	p.setUserWWWRoutes()
 	p.setupPiRoutes(recordsCtx, commentsCtx, voteCtx, piCtx)
 
+	// Setup the request body size limits of the routes that accept
+	// files and of the cast ballot route. The record limit allows a
+	// proposal that contains the maximum number of images along with
+	// its text files.
+	var (
+		pp        = piCtx.Policy()
+		textSize  = int64(pp.TextFileSizeMax) * recordTextFilesMax
+		imageSize = int64(pp.ImageFileCountMax) * int64(pp.ImageFileSizeMax)
+		recordMax = filesSizeMax(textSize + imageSize)
+	)
+	p.bodyLimits.set(rcv1.APIRoute+rcv1.RouteNew, recordMax)
+	p.bodyLimits.set(rcv1.APIRoute+rcv1.RouteEdit, recordMax)
+	p.bodyLimits.set(tkv1.APIRoute+tkv1.RouteCastBallot, ballotSizeMax)
+
+	// Setup the rate limit buckets of the comment and ballot routes
+	p.rateLimits.set(cmv1.APIRoute+cmv1.RouteNew, rateLimitComment)
+	p.rateLimits.set(cmv1.APIRoute+cmv1.RouteVote, rateLimitComment)
+	p.rateLimits.set(tkv1.APIRoute+tkv1.RouteCastBallot, rateLimitBallot)
+	p.rateLimits.set(piv1.APIRoute+piv1.RouteReportNew, rateLimitReport)
+
+	// Setup the OpenAPI document
+	p.setupOpenAPI()
+	if p.cfg.Telemetry {
+		log.Infof("Telemetry: enabled for %v", p.cfg.TelemetryClients)
+		p.setupTelemetryRoutes(telemetry.New(p.cfg))
+	}
+	if p.cfg.EventLog {
+		eventLogCtx, err := eventlog.New(p.cfg, p.events)
+		if err != nil {
+			return fmt.Errorf("new event log: %v", err)
+		}
+		log.Infof("Event log: enabled")
+		p.setupEventLogRoutes(eventLogCtx)
+	}
+	if len(p.cfg.OAuthClients) > 0 || p.cfg.OAuthIssuer != "" {
+		oauthCtx, err := oauth.New(p.cfg, p.politeiad, p.db, p.sessions)
+		if err != nil {
+			return fmt.Errorf("new oauth api: %v", err)
+		}
+		log.Infof("OAuth: enabled for %v configured clients",
+			len(p.cfg.OAuthClients))
+		p.setupOAuthRoutes(oauthCtx)
+	}
+
 	// Verify paywall settings
 	switch {
 	case p.cfg.PaywallAmount != 0 && p.cfg.PaywallXpub != "":
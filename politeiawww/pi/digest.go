@@ -0,0 +1,267 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"text/template"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/events"
+)
+
+const (
+	// digestsFilename is the name of the file in the data directory
+	// that the pending email digests are persisted to.
+	digestsFilename = "emaildigests.json"
+
+	// digestEntriesMax is the maximum number of notifications that are
+	// included in a single digest. The oldest notifications are
+	// dropped once the limit is reached.
+	digestEntriesMax = 200
+
+	// digestCheckInterval is the interval at which the pending digests
+	// are checked for digests that are due.
+	digestCheckInterval = 15 * time.Minute
+)
+
+// digestPeriod returns the period of an email digest type.
+func digestPeriod(d www.EmailDigestT) time.Duration {
+	switch d {
+	case www.EmailDigestDaily:
+		return 24 * time.Hour
+	case www.EmailDigestWeekly:
+		return 7 * 24 * time.Hour
+	}
+	return 0
+}
+
+// digestEntry is a notification that has been added to a digest. The body has
+// already been rendered using the date preferences of the recipient.
+type digestEntry struct {
+	Subject   string `json:"subject"`
+	Body      string `json:"body"`
+	NtfnBit   uint64 `json:"ntfnbit"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// digest contains the pending notifications of a user. The digest is sent
+// once its period has elapsed since the first pending notification was added.
+// This is a JSON structure so that the digests can be persisted to disk.
+type digest struct {
+	Email    string           `json:"email"`
+	TimeZone string           `json:"timezone,omitempty"`
+	Locale   string           `json:"locale,omitempty"`
+	Type     www.EmailDigestT `json:"type"`
+	Since    int64            `json:"since"`   // UNIX time of first entry
+	Dropped  uint32           `json:"dropped"` // Entries over the max
+	Entries  []digestEntry    `json:"entries"`
+}
+
+// digestQueue contains the pending email digests. The digests are persisted to
+// disk on every change so that the pending notifications survive a restart.
+type digestQueue struct {
+	sync.Mutex
+	path    string
+	digests map[string]*digest // [userID]digest
+}
+
+// newDigestQueue returns a new digestQueue that is loaded from the provided
+// file. The file is created on the first digest if it does not exist.
+func newDigestQueue(path string) (*digestQueue, error) {
+	dq := digestQueue{
+		path:    path,
+		digests: make(map[string]*digest, 64),
+	}
+	b, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return &dq, nil
+	case err != nil:
+		return nil, err
+	}
+	err = json.Unmarshal(b, &dq.digests)
+	if err != nil {
+		return nil, fmt.Errorf("decode %v: %v", path, err)
+	}
+	return &dq, nil
+}
+
+// save writes the digests to disk. The file is replaced atomically.
+//
+// This function must be called WITH the lock held.
+func (dq *digestQueue) save() error {
+	b, err := json.Marshal(dq.digests)
+	if err != nil {
+		return err
+	}
+	tmp := dq.path + ".tmp"
+	err = ioutil.WriteFile(tmp, b, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, dq.path)
+}
+
+// add adds a notification to the digest of a user. The recipient details and
+// the digest type of the user are updated so that the digest is sent using the
+// latest preferences of the user.
+func (dq *digestQueue) add(userID string, r events.Recipient, t www.EmailDigestT, e digestEntry) error {
+	dq.Lock()
+	defer dq.Unlock()
+
+	d, ok := dq.digests[userID]
+	if !ok {
+		d = &digest{
+			Since: time.Now().Unix(),
+		}
+		dq.digests[userID] = d
+	}
+	d.Email = r.Email
+	d.TimeZone = r.TimeZone
+	d.Locale = r.Locale
+	d.Type = t
+	d.Entries = append(d.Entries, e)
+	if len(d.Entries) > digestEntriesMax {
+		d.Dropped += uint32(len(d.Entries) - digestEntriesMax)
+		d.Entries = d.Entries[len(d.Entries)-digestEntriesMax:]
+	}
+
+	return dq.save()
+}
+
+// due returns copies of the digests whose period has elapsed.
+func (dq *digestQueue) due(now time.Time) map[string]digest {
+	dq.Lock()
+	defer dq.Unlock()
+
+	due := make(map[string]digest)
+	for userID, d := range dq.digests {
+		sendAt := time.Unix(d.Since, 0).Add(digestPeriod(d.Type))
+		if now.Before(sendAt) {
+			continue
+		}
+		c := *d
+		c.Entries = append([]digestEntry(nil), d.Entries...)
+		due[userID] = c
+	}
+	return due
+}
+
+// remove removes the first count entries from the digest of a user once they
+// have been sent. Entries that were added in the meantime remain pending and
+// start a new digest period.
+func (dq *digestQueue) remove(userID string, count int) error {
+	dq.Lock()
+	defer dq.Unlock()
+
+	d, ok := dq.digests[userID]
+	if !ok {
+		return nil
+	}
+	if count >= len(d.Entries) {
+		delete(dq.digests, userID)
+	} else {
+		d.Entries = d.Entries[count:]
+		d.Since = time.Now().Unix()
+		d.Dropped = 0
+	}
+
+	return dq.save()
+}
+
+type digestEmail struct {
+	Type    string        // daily or weekly
+	Entries []digestEntry // Pending notifications
+	Dropped uint32        // Number of dropped notifications
+}
+
+const digestEmailText = `
+This is your {{.Type}} digest of the Politeia notifications. It contains
+{{len .Entries}} notification(s).
+{{range .Entries}}
+--------------------------------------------------------------------------------
+{{.Subject}}
+{{.Body}}{{end}}
+{{- if .Dropped}}
+--------------------------------------------------------------------------------
+{{.Dropped}} older notification(s) were not included in this digest.
+{{end}}
+`
+
+var digestEmailTmpl = template.Must(
+	template.New("digestEmail").Parse(digestEmailText))
+
+// sendDigest sends a digest email that contains the pending notifications of
+// a user. The category unsubscribe link of the email disables all of the
+// notification categories that are included in the digest.
+func (p *Pi) sendDigest(d digest) error {
+	var ntfnBits uint64
+	for _, v := range d.Entries {
+		ntfnBits |= v.NtfnBit
+	}
+
+	typ := www.EmailDigests[d.Type]
+	body, err := populateTemplate(digestEmailTmpl, digestEmail{
+		Type:    typ,
+		Entries: d.Entries,
+		Dropped: d.Dropped,
+	})
+	if err != nil {
+		return err
+	}
+
+	r := events.Recipient{
+		Email:    d.Email,
+		TimeZone: d.TimeZone,
+		Locale:   d.Locale,
+	}
+	subject := fmt.Sprintf("Your %v Politeia digest", typ)
+	return p.events.Notify(ntfnDigest, []events.Recipient{r},
+		events.Notification{
+			Subject:   subject,
+			Timestamp: time.Now().Unix(),
+			NtfnBit:   ntfnBits,
+			Body: func(date string) (string, error) {
+				return body, nil
+			},
+		})
+}
+
+// sendDigests sends the digests that are due.
+func (p *Pi) sendDigests() {
+	for userID, d := range p.digests.due(time.Now()) {
+		err := p.sendDigest(d)
+		if err != nil {
+			// The digest remains pending and is retried on the
+			// next check.
+			log.Errorf("sendDigest %v: %v", userID, err)
+			continue
+		}
+		err = p.digests.remove(userID, len(d.Entries))
+		if err != nil {
+			log.Errorf("digests remove %v: %v", userID, err)
+			continue
+		}
+
+		log.Debugf("Email digest sent %v %v", userID, len(d.Entries))
+	}
+}
+
+// digestLoop periodically sends the email digests that are due. It runs for
+// the lifetime of the process.
+func (p *Pi) digestLoop() {
+	ticker := time.NewTicker(digestCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		p.sendDigests()
+	}
+}
@@ -7,12 +7,14 @@ package pi
 import (
 	"context"
 	"fmt"
+	"time"
 
 	pdv2 "github.com/decred/politeia/politeiad/api/v2"
 	cmplugin "github.com/decred/politeia/politeiad/plugins/comments"
 	piplugin "github.com/decred/politeia/politeiad/plugins/pi"
 	tkplugin "github.com/decred/politeia/politeiad/plugins/ticketvote"
 	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
 	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
 	v1 "github.com/decred/politeia/politeiawww/api/records/v1"
 	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
@@ -25,6 +27,44 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	// reportNtfnInterval is the minimum amount of time between two
+	// new report notifications. The reports that are submitted in
+	// between are batched into a single notification.
+	reportNtfnInterval = 15 * time.Minute
+
+	// EventTypeReportNew is emitted when a new legal or abuse report is
+	// submitted.
+	EventTypeReportNew = "pi-reportnew"
+
+	// EventTypeAuthorUpdate is emitted when a proposal author sets an
+	// author update.
+	EventTypeAuthorUpdate = "pi-authorupdate"
+
+	// EventTypeProposalApproved is emitted when the vote of a proposal
+	// has finished and the proposal has been approved.
+	EventTypeProposalApproved = "pi-proposalapproved"
+)
+
+// EventReportNew is the event data for the EventTypeReportNew.
+type EventReportNew struct {
+	Report piv1.Report
+}
+
+// EventAuthorUpdate is the event data for the EventTypeAuthorUpdate.
+type EventAuthorUpdate struct {
+	AuthorUpdate piv1.AuthorUpdate
+}
+
+// EventProposalApproved is the event data for the EventTypeProposalApproved.
+type EventProposalApproved struct {
+	Name           string // Proposal name
+	AuthorID       string
+	AuthorUsername string
+	AuthorPubKey   string // Public key that signed the proposal
+	Certificate    tkv1.CertificateReply
+}
+
 func (p *Pi) setupEventListeners() {
 	// Setup process for each event:
 	// 1. Create a channel for the event.
@@ -54,6 +94,11 @@ func (p *Pi) setupEventListeners() {
 	p.events.Register(comments.EventTypeNew, ch)
 	go p.handleEventCommentNew(ch)
 
+	// Comment del
+	ch = make(chan interface{})
+	p.events.Register(comments.EventTypeDel, ch)
+	go p.handleEventCommentDel(ch)
+
 	// Ticket vote authorized
 	ch = make(chan interface{})
 	p.events.Register(ticketvote.EventTypeAuthorize, ch)
@@ -63,6 +108,31 @@ func (p *Pi) setupEventListeners() {
 	ch = make(chan interface{})
 	p.events.Register(ticketvote.EventTypeStart, ch)
 	go p.handleEventVoteStarted(ch)
+
+	// Ticket vote finished
+	ch = make(chan interface{})
+	p.events.Register(ticketvote.EventTypeFinished, ch)
+	go p.handleEventVoteFinished(ch)
+
+	// Ticket vote finished, proposal approved
+	ch = make(chan interface{})
+	p.events.Register(ticketvote.EventTypeFinished, ch)
+	go p.handleEventProposalApproved(ch)
+
+	// Report new
+	ch = make(chan interface{})
+	p.events.Register(EventTypeReportNew, ch)
+	go p.handleEventReportNew(ch)
+
+	// Author update
+	ch = make(chan interface{})
+	p.events.Register(EventTypeAuthorUpdate, ch)
+	go p.handleEventAuthorUpdate(ch)
+
+	// File quarantined
+	ch = make(chan interface{})
+	p.events.Register(records.EventTypeFileQuarantined, ch)
+	go p.handleEventFileQuarantined(ch)
 }
 
 func (p *Pi) handleEventRecordNew(ch chan interface{}) {
@@ -73,9 +143,13 @@ func (p *Pi) handleEventRecordNew(ch chan interface{}) {
 			continue
 		}
 
+		// Add the proposal to the similarity index. The likely
+		// duplicates are returned to admins in the vetting queue.
+		p.similarityUpdate(e.Record)
+
 		// Compile notification email list
 		var (
-			emails  = make([]string, 0, 1024)
+			rs      recipients
 			ntfnBit = uint64(www.NotificationEmailAdminProposalNew)
 		)
 		err := p.userdb.AllUsers(func(u *user.User) {
@@ -89,7 +163,7 @@ func (p *Pi) handleEventRecordNew(ch chan interface{}) {
 			default:
 				// User is an admin and has the notification bit set. Add
 				// them to the email list.
-				emails = append(emails, u.Email)
+				rs.add(u)
 			}
 		})
 		if err != nil {
@@ -102,7 +176,8 @@ func (p *Pi) handleEventRecordNew(ch chan interface{}) {
 			token = e.Record.CensorshipRecord.Token
 			name  = proposalNameFromFiles(e.Record.Files)
 		)
-		err = p.mailNtfnProposalNew(token, name, e.User.Username, emails)
+		err = p.mailNtfnProposalNew(token, name, e.User.Username,
+			e.Record.Timestamp, rs)
 		if err != nil {
 			log.Errorf("mailNtfnProposalNew: %v", err)
 		}
@@ -119,6 +194,12 @@ func (p *Pi) handleEventRecordEdit(ch chan interface{}) {
 			continue
 		}
 
+		// Update the proposal in the similarity index
+		p.similarityUpdate(e.Record)
+
+		// Update the proposal in the search index
+		p.searchUpdateRecord(e.Record)
+
 		// Only send edit notifications for public proposals
 		if e.Record.State == rcv1.RecordStateUnvetted {
 			log.Debugf("Proposal is unvetted no edit ntfn %v",
@@ -128,7 +209,7 @@ func (p *Pi) handleEventRecordEdit(ch chan interface{}) {
 
 		// Compile notification email list
 		var (
-			emails   = make([]string, 0, 1024)
+			rs       recipients
 			authorID = e.User.ID.String()
 			ntfnBit  = uint64(www.NotificationEmailRegularProposalEdited)
 		)
@@ -144,7 +225,7 @@ func (p *Pi) handleEventRecordEdit(ch chan interface{}) {
 			default:
 				// User has the notification bit set. Add them to the email
 				// list.
-				emails = append(emails, u.Email)
+				rs.add(u)
 			}
 		})
 		if err != nil {
@@ -159,7 +240,8 @@ func (p *Pi) handleEventRecordEdit(ch chan interface{}) {
 			name     = proposalNameFromFiles(e.Record.Files)
 			username = e.User.Username
 		)
-		err = p.mailNtfnProposalEdit(token, version, name, username, emails)
+		err = p.mailNtfnProposalEdit(token, version, name, username,
+			e.Record.Timestamp, rs)
 		if err != nil {
 			log.Errorf("mailNtfnProposaledit: %v", err)
 			continue
@@ -208,7 +290,7 @@ func (p *Pi) ntfnRecordSetStatusToAuthor(r rcv1.Record) error {
 
 	// Author has notification enabled
 	err = p.mailNtfnProposalSetStatusToAuthor(token, name,
-		status, reason, author.Email)
+		status, reason, r.Timestamp, author)
 	if err != nil {
 		return fmt.Errorf("mailNtfnProposalSetStatusToAuthor: %v", err)
 	}
@@ -229,7 +311,7 @@ func (p *Pi) ntfnRecordSetStatus(r rcv1.Record) error {
 
 	// Compile user notification email list
 	var (
-		emails  = make([]string, 0, 1024)
+		rs      recipients
 		ntfnBit = uint64(www.NotificationEmailRegularProposalVetted)
 	)
 	err := p.userdb.AllUsers(func(u *user.User) {
@@ -243,7 +325,7 @@ func (p *Pi) ntfnRecordSetStatus(r rcv1.Record) error {
 			return
 		default:
 			// Add user to notification list
-			emails = append(emails, u.Email)
+			rs.add(u)
 		}
 	})
 	if err != nil {
@@ -251,7 +333,7 @@ func (p *Pi) ntfnRecordSetStatus(r rcv1.Record) error {
 	}
 
 	// Send user notifications
-	err = p.mailNtfnProposalSetStatus(token, name, status, emails)
+	err = p.mailNtfnProposalSetStatus(token, name, status, r.Timestamp, rs)
 	if err != nil {
 		return fmt.Errorf("mailNtfnProposalSetStatus: %v", err)
 	}
@@ -269,6 +351,14 @@ func (p *Pi) handleEventRecordSetStatus(ch chan interface{}) {
 			continue
 		}
 
+		// Add or remove the proposal from the search index
+		p.searchUpdateRecord(e.Record)
+
+		// Update the visibility of the proposal in the similarity
+		// index
+		p.similarity.setPublic(e.Record.CensorshipRecord.Token,
+			isPublic(e.Record))
+
 		// Unpack args
 		var (
 			token  = e.Record.CensorshipRecord.Token
@@ -340,7 +430,7 @@ func (p *Pi) ntfnCommentNewProposalAuthor(c cmv1.Comment, proposalAuthorID, prop
 
 	// Send notification email
 	err = p.mailNtfnCommentNewToProposalAuthor(c.Token, c.CommentID,
-		c.Username, proposalName, pauthor.Email)
+		c.Username, proposalName, c.Timestamp, pauthor)
 	if err != nil {
 		return err
 	}
@@ -394,7 +484,7 @@ func (p *Pi) ntfnCommentReply(c cmv1.Comment, proposalName string) error {
 
 	// Send notification email
 	err = p.mailNtfnCommentReply(c.Token, c.CommentID,
-		c.Username, proposalName, pauthor.Email)
+		c.Username, proposalName, c.Timestamp, pauthor)
 	if err != nil {
 		return err
 	}
@@ -404,6 +494,19 @@ func (p *Pi) ntfnCommentReply(c cmv1.Comment, proposalName string) error {
 	return nil
 }
 
+func (p *Pi) handleEventCommentDel(ch chan interface{}) {
+	for msg := range ch {
+		e, ok := msg.(comments.EventDel)
+		if !ok {
+			log.Errorf("handleEventCommentDel invalid msg: %v", msg)
+			continue
+		}
+
+		// Remove the comment from the search index
+		p.searchDelComment(e.Comment)
+	}
+}
+
 func (p *Pi) handleEventCommentNew(ch chan interface{}) {
 	for msg := range ch {
 		e, ok := msg.(comments.EventNew)
@@ -412,6 +515,9 @@ func (p *Pi) handleEventCommentNew(ch chan interface{}) {
 			continue
 		}
 
+		// Add the comment to the search index
+		p.searchAddComment(e.Comment)
+
 		// Get the record author and record name
 		var (
 			pdr              *pdv2.Record
@@ -473,7 +579,7 @@ func (p *Pi) handleEventVoteAuthorized(ch chan interface{}) {
 			token        = e.Auth.Token
 			proposalName string
 			r            rcv1.Record
-			emails       = make([]string, 0, 1024)
+			rs           recipients
 			ntfnBit      = uint64(www.NotificationEmailAdminProposalVoteAuthorized)
 			err          error
 		)
@@ -497,7 +603,7 @@ func (p *Pi) handleEventVoteAuthorized(ch chan interface{}) {
 				return
 			default:
 				// Admin has notification enabled
-				emails = append(emails, u.Email)
+				rs.add(u)
 			}
 		})
 		if err != nil {
@@ -506,7 +612,8 @@ func (p *Pi) handleEventVoteAuthorized(ch chan interface{}) {
 		}
 
 		// Send notification email
-		err = p.mailNtfnVoteAuthorized(token, proposalName, emails)
+		err = p.mailNtfnVoteAuthorized(token, proposalName,
+			time.Now().Unix(), rs)
 		if err != nil {
 			err = fmt.Errorf("mailNtfnVoteAuthorized: %v", err)
 			goto failed
@@ -544,7 +651,8 @@ func (p *Pi) ntfnVoteStartedToAuthor(sd tkv1.StartDetails, authorID, proposalNam
 	}
 
 	// Send notification to author
-	err = p.mailNtfnVoteStartedToAuthor(token, proposalName, author.Email)
+	err = p.mailNtfnVoteStartedToAuthor(token, proposalName,
+		time.Now().Unix(), author)
 	if err != nil {
 		return err
 	}
@@ -561,7 +669,7 @@ func (p *Pi) ntfnVoteStarted(sd tkv1.StartDetails, eventUser user.User, authorID
 	)
 
 	// Compile user notification list
-	emails := make([]string, 0, 1024)
+	var rs recipients
 	err := p.userdb.AllUsers(func(u *user.User) {
 		switch {
 		case u.ID.String() == eventUser.ID.String():
@@ -577,7 +685,7 @@ func (p *Pi) ntfnVoteStarted(sd tkv1.StartDetails, eventUser user.User, authorID
 			return
 		default:
 			// User has notification bit set
-			emails = append(emails, u.Email)
+			rs.add(u)
 		}
 	})
 	if err != nil {
@@ -585,7 +693,7 @@ func (p *Pi) ntfnVoteStarted(sd tkv1.StartDetails, eventUser user.User, authorID
 	}
 
 	// Email users
-	err = p.mailNtfnVoteStarted(token, proposalName, emails)
+	err = p.mailNtfnVoteStarted(token, proposalName, time.Now().Unix(), rs)
 	if err != nil {
 		return fmt.Errorf("mailNtfnVoteStarted: %v", err)
 	}
@@ -648,6 +756,250 @@ func (p *Pi) handleEventVoteStarted(ch chan interface{}) {
 	}
 }
 
+func (p *Pi) handleEventVoteFinished(ch chan interface{}) {
+	for msg := range ch {
+		e, ok := msg.(ticketvote.EventFinished)
+		if !ok {
+			log.Errorf("handleEventVoteFinished invalid msg: %v", msg)
+			continue
+		}
+
+		// Setup args to prevent goto errors
+		var (
+			token   = e.Certificate.Certificate.Token
+			ntfnBit = uint64(www.NotificationEmailMyProposalStatusChange)
+
+			pdr *pdv2.Record
+			r   rcv1.Record
+			err error
+
+			uid          uuid.UUID
+			author       *user.User
+			proposalName string
+		)
+		pdr, err = p.recordAbridged(token)
+		if err != nil {
+			goto failed
+		}
+		r = convertRecordToV1(*pdr)
+		proposalName = proposalNameFromFiles(r.Files)
+
+		// Get record author
+		uid, err = uuid.Parse(userIDFromMetadata(r.Metadata))
+		if err != nil {
+			goto failed
+		}
+		author, err = p.userdb.UserGetById(uid)
+		if err != nil {
+			err = fmt.Errorf("UserGetByID %v: %v", uid, err)
+			goto failed
+		}
+
+		// Verify author notification settings
+		if !author.NotificationIsEnabled(ntfnBit) {
+			log.Debugf("Vote finished ntfn to author not enabled %v", token)
+			continue
+		}
+
+		// Send notification to author
+		err = p.mailNtfnVoteFinishedToAuthor(token, proposalName,
+			e.Certificate.Text, author)
+		if err != nil {
+			err = fmt.Errorf("mailNtfnVoteFinishedToAuthor: %v", err)
+			goto failed
+		}
+
+		log.Debugf("Vote finished ntfn to author sent %v", token)
+		continue
+
+	failed:
+		log.Errorf("handleEventVoteFinished %v: %v", token, err)
+		continue
+	}
+}
+
+func (p *Pi) handleEventProposalApproved(ch chan interface{}) {
+	for msg := range ch {
+		e, ok := msg.(ticketvote.EventFinished)
+		if !ok {
+			log.Errorf("handleEventProposalApproved invalid msg: %v", msg)
+			continue
+		}
+
+		// Only approved proposals are handed off
+		vc := e.Certificate.Certificate
+		if vc.Status != tkv1.VoteStatusApproved {
+			continue
+		}
+
+		// Setup args to prevent goto errors
+		var (
+			token = vc.Token
+
+			pdr *pdv2.Record
+			r   rcv1.Record
+			err error
+
+			uid    uuid.UUID
+			author *user.User
+		)
+		pdr, err = p.recordAbridged(token)
+		if err != nil {
+			goto failed
+		}
+		r = convertRecordToV1(*pdr)
+
+		// Get record author
+		uid, err = uuid.Parse(userIDFromMetadata(r.Metadata))
+		if err != nil {
+			goto failed
+		}
+		author, err = p.userdb.UserGetById(uid)
+		if err != nil {
+			err = fmt.Errorf("UserGetByID %v: %v", uid, err)
+			goto failed
+		}
+
+		// Emit event. The event is emitted from a separate goroutine
+		// since the event manager is blocked until this handler has
+		// received all of the vote finished events that are being
+		// emitted, which would deadlock if this handler was waiting on
+		// the event manager.
+		go p.events.Emit(EventTypeProposalApproved,
+			EventProposalApproved{
+				Name:           proposalNameFromFiles(r.Files),
+				AuthorID:       author.ID.String(),
+				AuthorUsername: author.Username,
+				AuthorPubKey:   authorPubKeyFromMetadata(r.Metadata),
+				Certificate:    e.Certificate,
+			})
+
+		log.Debugf("Proposal approved event emitted %v", token)
+		continue
+
+	failed:
+		log.Errorf("handleEventProposalApproved %v: %v", token, err)
+		continue
+	}
+}
+
+// handleEventReportNew notifies the admins of new reports. The admins are
+// notified of the first report right away. The reports that are submitted
+// within the report notification interval of the last notification are sent
+// in a single notification once the interval has passed, so the public report
+// route can't be used to flood the admins with emails.
+func (p *Pi) handleEventReportNew(ch chan interface{}) {
+	var (
+		pending []piv1.Report
+		last    time.Time
+		timer   <-chan time.Time
+	)
+	for {
+		select {
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			e, ok := msg.(EventReportNew)
+			if !ok {
+				log.Errorf("handleEventReportNew invalid msg: %v", msg)
+				continue
+			}
+			pending = append(pending, e.Report)
+			if timer == nil {
+				timer = time.After(time.Until(last.Add(reportNtfnInterval)))
+			}
+			continue
+
+		case <-timer:
+			timer = nil
+			last = time.Now()
+		}
+
+		p.ntfnReportNew(pending)
+		pending = nil
+	}
+}
+
+// ntfnReportNew sends the new report notification to the admins.
+func (p *Pi) ntfnReportNew(reports []piv1.Report) {
+	// Compile notification email list
+	var (
+		rs      recipients
+		ntfnBit = uint64(www.NotificationEmailAdminReportNew)
+	)
+	err := p.userdb.AllUsers(func(u *user.User) {
+		switch {
+		case !u.Admin:
+			// Only admins get this notification
+			return
+		case !u.NotificationIsEnabled(ntfnBit):
+			// Admin doesn't have notification bit set
+			return
+		default:
+			// User is an admin and has the notification bit set. Add
+			// them to the email list.
+			rs.add(u)
+		}
+	})
+	if err != nil {
+		log.Errorf("handleEventReportNew: AllUsers: %v", err)
+		return
+	}
+
+	// Send notification email
+	err = p.mailNtfnReportNew(reports, rs)
+	if err != nil {
+		log.Errorf("mailNtfnReportNew: %v", err)
+		return
+	}
+
+	log.Debugf("Report new ntfn sent for %v reports", len(reports))
+}
+
+func (p *Pi) handleEventFileQuarantined(ch chan interface{}) {
+	for msg := range ch {
+		e, ok := msg.(records.EventFileQuarantined)
+		if !ok {
+			log.Errorf("handleEventFileQuarantined invalid msg: %v", msg)
+			continue
+		}
+
+		// Compile notification email list
+		var (
+			rs      recipients
+			ntfnBit = uint64(www.NotificationEmailAdminFileQuarantined)
+		)
+		err := p.userdb.AllUsers(func(u *user.User) {
+			switch {
+			case !u.Admin:
+				// Only admins get this notification
+				return
+			case !u.NotificationIsEnabled(ntfnBit):
+				// Admin doesn't have notification bit set
+				return
+			default:
+				// User is an admin and has the notification bit set. Add
+				// them to the email list.
+				rs.add(u)
+			}
+		})
+		if err != nil {
+			log.Errorf("handleEventFileQuarantined: AllUsers: %v", err)
+			continue
+		}
+
+		// Send notification email
+		err = p.mailNtfnFileQuarantined(e, rs)
+		if err != nil {
+			log.Errorf("mailNtfnFileQuarantined: %v", err)
+			continue
+		}
+
+		log.Debugf("File quarantined ntfn sent %v", e.Name)
+	}
+}
+
 // recordAbridged returns a proposal record without its index file or any
 // attachment files. This allows the request to be light weight.
 func (p *Pi) recordAbridged(token string) (*pdv2.Record, error) {
@@ -694,6 +1046,20 @@ func userIDFromMetadata(ms []v1.MetadataStream) string {
 	return um.UserID
 }
 
+// authorPubKeyFromMetadata searches for a UserMetadata and returns the public
+// key that the author used to sign the record. An empty string is returned if
+// no UserMetadata is found.
+func authorPubKeyFromMetadata(ms []v1.MetadataStream) string {
+	um, err := client.UserMetadataDecode(ms)
+	if err != nil {
+		return ""
+	}
+	if um == nil {
+		return ""
+	}
+	return um.PublicKey
+}
+
 func convertStateToV1(s pdv2.RecordStateT) rcv1.RecordStateT {
 	switch s {
 	case pdv2.RecordStateUnvetted:
@@ -762,3 +1128,65 @@ func convertRecordToV1(r pdv2.Record) rcv1.Record {
 		},
 	}
 }
+
+func (p *Pi) handleEventAuthorUpdate(ch chan interface{}) {
+	for msg := range ch {
+		e, ok := msg.(EventAuthorUpdate)
+		if !ok {
+			log.Errorf("handleEventAuthorUpdate invalid msg: %v", msg)
+			continue
+		}
+
+		// Setup args to prevent goto errors
+		var (
+			au      = e.AuthorUpdate
+			ntfnBit = uint64(www.NotificationEmailRegularProposalAuthorUpdate)
+
+			pdr *pdv2.Record
+			r   rcv1.Record
+			err error
+
+			rs           recipients
+			proposalName string
+		)
+		pdr, err = p.recordAbridged(au.Token)
+		if err != nil {
+			goto failed
+		}
+		r = convertRecordToV1(*pdr)
+		proposalName = proposalNameFromFiles(r.Files)
+
+		// Compile notification email list
+		err = p.userdb.AllUsers(func(u *user.User) {
+			switch {
+			case u.ID.String() == au.UserID:
+				// Don't send a notification to the author
+				return
+			case !u.NotificationIsEnabled(ntfnBit):
+				// User does not have notification bit set
+				return
+			default:
+				// User has notification bit set
+				rs.add(u)
+			}
+		})
+		if err != nil {
+			err = fmt.Errorf("AllUsers: %v", err)
+			goto failed
+		}
+
+		// Send notification email
+		err = p.mailNtfnAuthorUpdate(au, proposalName, rs)
+		if err != nil {
+			err = fmt.Errorf("mailNtfnAuthorUpdate: %v", err)
+			goto failed
+		}
+
+		log.Debugf("Author update ntfn sent %v", au.Token)
+		continue
+
+	failed:
+		log.Errorf("handleEventAuthorUpdate %v: %v", au.Token, err)
+		continue
+	}
+}
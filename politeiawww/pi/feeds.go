@@ -0,0 +1,569 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pi
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	piplugin "github.com/decred/politeia/politeiad/plugins/pi"
+	tkplugin "github.com/decred/politeia/politeiad/plugins/ticketvote"
+	v1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	"github.com/decred/politeia/politeiawww/consistency"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// feedEntry is a format agnostic feed entry.
+type feedEntry struct {
+	id      string
+	title   string
+	link    string
+	author  string
+	summary string
+	updated time.Time
+}
+
+// feed is a format agnostic feed. It is encoded to the requested format when
+// it is served.
+type feed struct {
+	id      string
+	title   string
+	link    string
+	updated time.Time
+	entries []feedEntry
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title   string     `xml:"title"`
+	ID      string     `xml:"id"`
+	Link    atomLink   `xml:"link"`
+	Updated string     `xml:"updated"`
+	Author  atomAuthor `xml:"author"`
+	Summary string     `xml:"summary,omitempty"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Link    atomLink    `xml:"link"`
+	Updated string      `xml:"updated"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description,omitempty"`
+	Creator     string  `xml:"http://purl.org/dc/elements/1.1/ creator,omitempty"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	TTL           int       `xml:"ttl"` // Minutes
+	Items         []rssItem `xml:"item"`
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+// atom returns the Atom encoding of the feed.
+func (f *feed) atom() ([]byte, error) {
+	af := atomFeed{
+		Title:   f.title,
+		ID:      f.id,
+		Link:    atomLink{Href: f.link},
+		Updated: f.updated.UTC().Format(time.RFC3339),
+		Entries: make([]atomEntry, 0, len(f.entries)),
+	}
+	for _, v := range f.entries {
+		// Atom requires an author for every entry
+		author := v.author
+		if author == "" {
+			author = f.title
+		}
+		af.Entries = append(af.Entries, atomEntry{
+			Title:   v.title,
+			ID:      v.id,
+			Link:    atomLink{Href: v.link},
+			Updated: v.updated.UTC().Format(time.RFC3339),
+			Author:  atomAuthor{Name: author},
+			Summary: v.summary,
+		})
+	}
+	b, err := xml.MarshalIndent(af, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), b...), nil
+}
+
+// rss returns the RSS 2.0 encoding of the feed.
+func (f *feed) rss() ([]byte, error) {
+	rf := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         f.title,
+			Link:          f.link,
+			Description:   f.title,
+			LastBuildDate: f.updated.UTC().Format(time.RFC1123Z),
+			TTL:           v1.FeedCacheTTL / 60,
+			Items:         make([]rssItem, 0, len(f.entries)),
+		},
+	}
+	for _, v := range f.entries {
+		rf.Channel.Items = append(rf.Channel.Items, rssItem{
+			Title:       v.title,
+			Link:        v.link,
+			Description: v.summary,
+			Creator:     v.author,
+			GUID: rssGUID{
+				Value:       v.id,
+				IsPermaLink: v.id == v.link,
+			},
+			PubDate: v.updated.UTC().Format(time.RFC1123Z),
+		})
+	}
+	b, err := xml.MarshalIndent(rf, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), b...), nil
+}
+
+// feedCacheEntry is a cached feed.
+type feedCacheEntry struct {
+	feed   *feed
+	expiry time.Time
+	seq    uint64 // Consistency sequence when the entry was populated
+}
+
+// feedCache caches the generated feeds. Feed readers poll the feeds, so
+// caching them prevents every poll from resulting in multiple politeiad
+// requests.
+type feedCache struct {
+	sync.Mutex
+	entries map[string]feedCacheEntry // [route]entry
+}
+
+// newFeedCache returns a new feedCache.
+func newFeedCache() *feedCache {
+	return &feedCache{
+		entries: make(map[string]feedCacheEntry, 64),
+	}
+}
+
+// get returns the cached feed of the provided route. A feed that was
+// populated before the write of the request consistency token is treated as
+// not found.
+func (c *feedCache) get(ctx context.Context, route string) (*feed, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	e, ok := c.entries[route]
+	if !ok || time.Now().After(e.expiry) {
+		return nil, false
+	}
+	if !consistency.Satisfied(ctx, e.seq) {
+		return nil, false
+	}
+	return e.feed, true
+}
+
+// put adds a feed to the cache. The seq is the consistency sequence prior to
+// the feed being created. The expired feeds are removed so that the cache does
+// not grow with the comment feeds of proposals that are no longer polled.
+func (c *feedCache) put(seq uint64, route string, f *feed) {
+	c.Lock()
+	defer c.Unlock()
+
+	now := time.Now()
+	for k, v := range c.entries {
+		if now.After(v.expiry) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[route] = feedCacheEntry{
+		feed:   f,
+		expiry: now.Add(v1.FeedCacheTTL * time.Second),
+		seq:    seq,
+	}
+}
+
+// HandleFeedProposals is the request handler for the pi v1 FeedProposals
+// route.
+func (p *Pi) HandleFeedProposals(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleFeedProposals")
+
+	p.serveFeed(w, r, "HandleFeedProposals", p.feedProposals)
+}
+
+// HandleFeedVotes is the request handler for the pi v1 FeedVotes route.
+func (p *Pi) HandleFeedVotes(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleFeedVotes")
+
+	p.serveFeed(w, r, "HandleFeedVotes", p.feedVotes)
+}
+
+// HandleFeedComments is the request handler for the pi v1 FeedComments route.
+func (p *Pi) HandleFeedComments(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleFeedComments")
+
+	token := mux.Vars(r)["token"]
+	p.serveFeed(w, r, "HandleFeedComments",
+		func(ctx context.Context) (*feed, error) {
+			return p.feedComments(ctx, token)
+		})
+}
+
+// serveFeed serves a feed in the requested format. The feed is retrieved from
+// the cache or, when it is not cached, created using the provided function.
+func (p *Pi) serveFeed(w http.ResponseWriter, r *http.Request, handler string, create func(context.Context) (*feed, error)) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = v1.FeedFormatAtom
+	}
+	if format != v1.FeedFormatAtom && format != v1.FeedFormatRSS {
+		respondWithError(w, r, handler+": format",
+			v1.UserErrorReply{
+				ErrorCode:    v1.ErrorCodeInputInvalid,
+				ErrorContext: fmt.Sprintf("invalid feed format %v", format),
+			})
+		return
+	}
+
+	var (
+		ctx = r.Context()
+		seq = consistency.Seq(ctx)
+	)
+	f, ok := p.feeds.get(ctx, r.URL.Path)
+	if !ok {
+		var err error
+		f, err = create(ctx)
+		if err != nil {
+			respondWithError(w, r, handler+": %v", err)
+			return
+		}
+		f.id = p.cfg.WebServerAddress + r.URL.Path
+		p.feeds.put(seq, r.URL.Path, f)
+	}
+
+	var (
+		b   []byte
+		err error
+	)
+	switch format {
+	case v1.FeedFormatRSS:
+		w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+		b, err = f.rss()
+	default:
+		w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+		b, err = f.atom()
+	}
+	if err != nil {
+		respondWithError(w, r, handler+": encode: %v", err)
+		return
+	}
+	w.Header().Set("Cache-Control",
+		fmt.Sprintf("public, max-age=%v", v1.FeedCacheTTL))
+
+	// ServeContent handles the conditional requests of the feed readers
+	// using the time that the feed was last updated.
+	http.ServeContent(w, r, "", f.updated, bytes.NewReader(b))
+}
+
+// feedProposals returns the feed of the most recent proposals that have been
+// made public.
+func (p *Pi) feedProposals(ctx context.Context) (*feed, error) {
+	tokens, err := p.politeiad.InventoryOrdered(ctx,
+		pdv2.RecordStateVetted, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) > v1.FeedEntriesMax {
+		tokens = tokens[:v1.FeedEntriesMax]
+	}
+	records, err := p.feedRecords(ctx, tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	f := feed{
+		title:   p.cfg.SiteName + " proposals",
+		link:    p.cfg.WebServerAddress,
+		entries: make([]feedEntry, 0, len(tokens)),
+	}
+	usernames := make(map[string]string, len(tokens))
+	for _, v := range tokens {
+		r, ok := records[v]
+		if !ok || r.Status != pdv2.RecordStatusPublic {
+			continue
+		}
+		link := p.guiLink(guiRouteRecordDetails, v, 0)
+		f.entries = append(f.entries, feedEntry{
+			id:      link,
+			title:   p.feedProposalName(r),
+			link:    link,
+			author:  p.feedUsername(r, usernames),
+			summary: walletDescription(r.Files),
+			updated: time.Unix(r.Timestamp, 0),
+		})
+	}
+	f.setUpdated()
+
+	return &f, nil
+}
+
+// feedVotes returns the feed of the proposals whose vote has started. The
+// entries are sorted by the time that the vote started.
+func (p *Pi) feedVotes(ctx context.Context) (*feed, error) {
+	ir, err := p.politeiad.TicketVoteInventory(ctx, tkplugin.Inventory{
+		Status: tkplugin.VoteStatusStarted,
+		Page:   1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	tokens := ir.Tokens[tkplugin.VoteStatuses[tkplugin.VoteStatusStarted]]
+	if len(tokens) > v1.FeedEntriesMax {
+		tokens = tokens[:v1.FeedEntriesMax]
+	}
+	records, err := p.feedRecords(ctx, tokens)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := p.politeiad.TicketVoteSummaries(ctx, tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	f := feed{
+		title:   p.cfg.SiteName + " votes",
+		link:    p.cfg.WebServerAddress,
+		entries: make([]feedEntry, 0, len(tokens)),
+	}
+	var (
+		usernames = make(map[string]string, len(tokens))
+		blocks    = make(map[uint32]time.Time, len(tokens))
+	)
+	for _, v := range tokens {
+		r, ok := records[v]
+		if !ok {
+			continue
+		}
+		s := summaries[v]
+
+		// The vote starts at the start block
+		started, ok := blocks[s.StartBlockHeight]
+		if !ok {
+			bdr, err := p.politeiad.DcrdataBlockDetails(ctx,
+				s.StartBlockHeight)
+			if err != nil {
+				return nil, err
+			}
+			started = time.Unix(bdr.Block.Time, 0)
+			blocks[s.StartBlockHeight] = started
+		}
+
+		link := p.guiLink(guiRouteRecordDetails, v, 0)
+		f.entries = append(f.entries, feedEntry{
+			id:     link,
+			title:  "Voting started: " + p.feedProposalName(r),
+			link:   link,
+			author: p.feedUsername(r, usernames),
+			summary: fmt.Sprintf("Voting ends at block %v. %v",
+				s.EndBlockHeight, walletDescription(r.Files)),
+			updated: started,
+		})
+	}
+	sort.SliceStable(f.entries, func(i, j int) bool {
+		return f.entries[i].updated.After(f.entries[j].updated)
+	})
+	f.setUpdated()
+
+	return &f, nil
+}
+
+// feedComments returns the feed of the most recent comments of a public
+// proposal.
+func (p *Pi) feedComments(ctx context.Context, token string) (*feed, error) {
+	records, err := p.feedRecords(ctx, []string{token})
+	if err != nil {
+		return nil, err
+	}
+	r, ok := records[token]
+	if !ok || r.State != pdv2.RecordStateVetted {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeRecordNotFound,
+		}
+	}
+	token = r.CensorshipRecord.Token
+	comments, err := p.politeiad.CommentsGetAll(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sort the comments from newest to oldest
+	sort.SliceStable(comments, func(i, j int) bool {
+		return comments[i].Timestamp > comments[j].Timestamp
+	})
+
+	name := p.feedProposalName(r)
+	f := feed{
+		title:   fmt.Sprintf("%v comments: %v", p.cfg.SiteName, name),
+		link:    p.guiLink(guiRouteRecordDetails, token, 0),
+		entries: make([]feedEntry, 0, v1.FeedEntriesMax),
+	}
+	usernames := make(map[string]string, v1.FeedEntriesMax)
+	for _, v := range comments {
+		if len(f.entries) == v1.FeedEntriesMax {
+			break
+		}
+		if v.Deleted {
+			continue
+		}
+		username, ok := usernames[v.UserID]
+		if !ok {
+			username = p.username(v.UserID)
+			usernames[v.UserID] = username
+		}
+		link := p.guiLink(guiRouteRecordComment, token, v.CommentID)
+		f.entries = append(f.entries, feedEntry{
+			id:      link,
+			title:   fmt.Sprintf("%v commented on %v", username, name),
+			link:    link,
+			author:  username,
+			summary: v.Comment,
+			updated: time.Unix(v.Timestamp, 0),
+		})
+	}
+	f.setUpdated()
+
+	return &f, nil
+}
+
+// setUpdated sets the updated time of the feed to the most recent entry
+// update. The current time is used when the feed does not have any entries.
+func (f *feed) setUpdated() {
+	if len(f.entries) == 0 {
+		f.updated = time.Now()
+		return
+	}
+	for _, v := range f.entries {
+		if v.updated.After(f.updated) {
+			f.updated = v.updated
+		}
+	}
+}
+
+// feedRecords returns the records of the provided tokens with the files that
+// are used to create the feed entries. The records are requested in pages
+// since the politeiad records page size is smaller than the number of feed
+// entries.
+func (p *Pi) feedRecords(ctx context.Context, tokens []string) (map[string]pdv2.Record, error) {
+	records := make(map[string]pdv2.Record, len(tokens))
+	for i := 0; i < len(tokens); i += int(pdv2.RecordsPageSize) {
+		end := i + int(pdv2.RecordsPageSize)
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+		reqs := make([]pdv2.RecordRequest, 0, end-i)
+		for _, v := range tokens[i:end] {
+			reqs = append(reqs, pdv2.RecordRequest{
+				Token: v,
+				Filenames: []string{
+					piplugin.FileNameIndexFile,
+					piplugin.FileNameProposalMetadata,
+				},
+			})
+		}
+		rs, err := p.politeiad.Records(ctx, reqs)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range rs {
+			records[k] = v
+		}
+	}
+	return records, nil
+}
+
+// feedProposalName returns the name of a proposal record. The token is
+// returned if the name can't be decoded.
+func (p *Pi) feedProposalName(r pdv2.Record) string {
+	name := proposalNameFromFiles(convertFilesToV1(r.Files))
+	if name == "" {
+		return r.CensorshipRecord.Token
+	}
+	return name
+}
+
+// feedUsername returns the username of the author of a proposal record. The
+// usernames are cached in the provided map so that a user is only looked up
+// once per feed.
+func (p *Pi) feedUsername(r pdv2.Record, usernames map[string]string) string {
+	uid := userIDFromMetadata(convertMetadataStreamsToV1(r.Metadata))
+	username, ok := usernames[uid]
+	if !ok {
+		username = p.username(uid)
+		usernames[uid] = username
+	}
+	return username
+}
+
+// username returns the username of the provided user ID. An empty string is
+// returned if the user can't be found.
+func (p *Pi) username(userID string) string {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return ""
+	}
+	u, err := p.userdb.UserGetById(id)
+	if err != nil {
+		log.Errorf("UserGetById %v: %v", userID, err)
+		return ""
+	}
+	return u.Username
+}
+
+// guiLink returns the GUI link of a proposal or, when a comment ID is
+// provided, of a proposal comment.
+func (p *Pi) guiLink(route, token string, commentID uint32) string {
+	route = strings.Replace(route, "{token}", token, 1)
+	route = strings.Replace(route, "{id}",
+		strconv.FormatUint(uint64(commentID), 10), 1)
+	return p.cfg.WebServerAddress + route
+}
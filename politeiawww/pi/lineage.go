@@ -0,0 +1,220 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	tkplugin "github.com/decred/politeia/politeiad/plugins/ticketvote"
+	v1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	"github.com/decred/politeia/politeiawww/client"
+	"github.com/decred/politeia/util"
+)
+
+// lineageDepthMax is the maximum number of resubmissions that are followed
+// when the lineage of a proposal is requested.
+const lineageDepthMax = 16
+
+// HandleLineage is the request handler for the pi v1 Lineage route.
+func (p *Pi) HandleLineage(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleLineage")
+
+	var l v1.Lineage
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&l); err != nil {
+		respondWithError(w, r, "HandleLineage: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	lr, err := p.processLineage(r.Context(), l)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleLineage: processLineage: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, lr)
+}
+
+func (p *Pi) processLineage(ctx context.Context, l v1.Lineage) (*v1.LineageReply, error) {
+	log.Tracef("processLineage: %v", l.Token)
+
+	r, err := p.rfpRecord(ctx, l.Token)
+	if err != nil {
+		return nil, err
+	}
+	records, missing, err := p.lineageRecords(ctx,
+		r.CensorshipRecord.Token, resubmitsFromRecord(*r))
+	if err != nil {
+		return nil, err
+	}
+	proposals, err := p.lineageProposals(ctx,
+		append([]pdv2.Record{*r}, records...), missing)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.LineageReply{
+		Proposals: proposals,
+	}, nil
+}
+
+// Lineage returns the records that a proposal is a resubmission of along with
+// the results of their votes. Nil is returned for proposals that are not
+// public or archived resubmissions.
+//
+// This function satisfies the records LineageProvider interface.
+func (p *Pi) Lineage(ctx context.Context, r rcv1.Record) ([]rcv1.LineageRecord, error) {
+	if r.State != rcv1.RecordStateVetted ||
+		(r.Status != rcv1.RecordStatusPublic &&
+			r.Status != rcv1.RecordStatusArchived) {
+		return nil, nil
+	}
+	pm, err := client.ProposalMetadataDecode(r.Files)
+	if err != nil || pm == nil || pm.Resubmits == "" {
+		return nil, nil
+	}
+
+	records, missing, err := p.lineageRecords(ctx,
+		r.CensorshipRecord.Token, pm.Resubmits)
+	if err != nil {
+		return nil, err
+	}
+	proposals, err := p.lineageProposals(ctx, records, missing)
+	if err != nil {
+		return nil, err
+	}
+
+	lineage := make([]rcv1.LineageRecord, 0, len(proposals))
+	for _, v := range proposals {
+		lineage = append(lineage, convertLineageRecordToV1(v))
+	}
+	return lineage, nil
+}
+
+// lineageRecords follows the resubmissions that start at the proposal that
+// the provided proposal is a resubmission of. The resubmitted proposals were
+// validated by politeiad when they were linked. The records are returned
+// ordered from newest to oldest along with the token of the proposal that
+// ended the lineage because it could not be retrieved. The returned token is
+// empty if all proposals were retrieved.
+func (p *Pi) lineageRecords(ctx context.Context, token, resubmits string) ([]pdv2.Record, string, error) {
+	var (
+		records = make([]pdv2.Record, 0, lineageDepthMax)
+		visited = map[string]struct{}{
+			token: {},
+		}
+	)
+	for resubmits != "" && len(records) < lineageDepthMax-1 {
+		if _, ok := visited[resubmits]; ok {
+			break
+		}
+		visited[resubmits] = struct{}{}
+
+		rs, err := p.rfpRecords(ctx, []string{resubmits})
+		if err != nil {
+			return nil, "", err
+		}
+		prev, ok := rs[resubmits]
+		if !ok {
+			return records, resubmits, nil
+		}
+		records = append(records, prev)
+
+		// The contents of the proposal are only used if it is still
+		// public or archived.
+		resubmits = resubmitsFromRecord(prev)
+	}
+	return records, "", nil
+}
+
+// lineageProposals returns the LineageProposals of the provided lineage
+// records along with their vote results. A proposal that only contains the
+// token is appended for the missing token if one is provided.
+func (p *Pi) lineageProposals(ctx context.Context, records []pdv2.Record, missing string) ([]v1.LineageProposal, error) {
+	proposals := make([]v1.LineageProposal, 0, len(records)+1)
+	if len(records) > 0 {
+		tokens := make([]string, 0, len(records))
+		for _, v := range records {
+			tokens = append(tokens, v.CensorshipRecord.Token)
+		}
+		vs, err := p.politeiad.TicketVoteSummaries(ctx, tokens)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range records {
+			proposals = append(proposals,
+				convertLineageProposalToV1(v, vs[v.CensorshipRecord.Token]))
+		}
+	}
+	if missing != "" {
+		proposals = append(proposals, v1.LineageProposal{
+			Token: missing,
+		})
+	}
+	return proposals, nil
+}
+
+// resubmitsFromRecord returns the token of the proposal that a proposal
+// record is a resubmission of. An empty string is returned if the record is
+// not a resubmission or if its contents can not be returned.
+func resubmitsFromRecord(r pdv2.Record) string {
+	if !rfpVisible(r) {
+		return ""
+	}
+	pm, err := client.ProposalMetadataDecode(convertFilesToV1(r.Files))
+	if err != nil || pm == nil {
+		return ""
+	}
+	return pm.Resubmits
+}
+
+// convertLineageProposalToV1 converts a proposal record and its vote summary
+// to a LineageProposal. The record contents are only included when the record
+// is public or archived.
+func convertLineageProposalToV1(r pdv2.Record, s tkplugin.SummaryReply) v1.LineageProposal {
+	lp := v1.LineageProposal{
+		Token:     r.CensorshipRecord.Token,
+		Status:    uint32(convertStatusToV1(r.Status)),
+		Resubmits: resubmitsFromRecord(r),
+		Vote:      convertWalletVoteToV1(s),
+	}
+	if rfpVisible(r) {
+		lp.Name = proposalNameFromFiles(convertFilesToV1(r.Files))
+	}
+	return lp
+}
+
+// convertLineageRecordToV1 converts a LineageProposal to a records v1
+// LineageRecord.
+func convertLineageRecordToV1(lp v1.LineageProposal) rcv1.LineageRecord {
+	results := make([]rcv1.LineageVoteResult, 0, len(lp.Vote.Results))
+	for _, v := range lp.Vote.Results {
+		results = append(results, rcv1.LineageVoteResult{
+			ID:          v.ID,
+			Description: v.Description,
+			VoteBit:     v.VoteBit,
+			Votes:       v.Votes,
+		})
+	}
+	return rcv1.LineageRecord{
+		Token:            lp.Token,
+		Name:             lp.Name,
+		Status:           rcv1.RecordStatusT(lp.Status),
+		Resubmits:        lp.Resubmits,
+		VoteStatus:       lp.Vote.Status,
+		EligibleTickets:  lp.Vote.EligibleTickets,
+		QuorumPercentage: lp.Vote.QuorumPercentage,
+		PassPercentage:   lp.Vote.PassPercentage,
+		VoteResults:      results,
+	}
+}
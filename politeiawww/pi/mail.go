@@ -12,7 +12,13 @@ import (
 	"strings"
 	"text/template"
 
+	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
 	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/events"
+	"github.com/decred/politeia/politeiawww/mail"
+	"github.com/decred/politeia/politeiawww/records"
+	"github.com/decred/politeia/politeiawww/user"
 )
 
 const (
@@ -22,10 +28,132 @@ const (
 	guiRouteRecordComment = "/record/{token}/comments/{id}"
 )
 
+const (
+	// The following are the notification events of the pi notifications.
+	// They are routed to the smtp notifier by default.
+	ntfnProposalNew            = "proposal-new"
+	ntfnProposalEdit           = "proposal-edit"
+	ntfnProposalPublished      = "proposal-published"
+	ntfnProposalStatusToAuthor = "proposal-status-author"
+	ntfnCommentNewToAuthor     = "comment-new-author"
+	ntfnCommentReply           = "comment-reply"
+	ntfnVoteAuthorized         = "vote-authorized"
+	ntfnVoteStarted            = "vote-started"
+	ntfnVoteStartedToAuthor    = "vote-started-author"
+	ntfnVoteFinishedToAuthor   = "vote-finished-author"
+	ntfnReportNew              = "report-new"
+	ntfnAuthorUpdate           = "author-update"
+	ntfnFileQuarantined        = "file-quarantined"
+
+	// ntfnDigest is the notification event of the digest emails. The
+	// notifications of the users that have an email digest set are
+	// batched into a digest email instead of being sent immediately.
+	ntfnDigest = "digest"
+)
+
+var (
+	// ntfnEvents contains all pi notification events.
+	ntfnEvents = []string{
+		ntfnProposalNew,
+		ntfnProposalEdit,
+		ntfnProposalPublished,
+		ntfnProposalStatusToAuthor,
+		ntfnCommentNewToAuthor,
+		ntfnCommentReply,
+		ntfnVoteAuthorized,
+		ntfnVoteStarted,
+		ntfnVoteStartedToAuthor,
+		ntfnVoteFinishedToAuthor,
+		ntfnReportNew,
+		ntfnAuthorUpdate,
+		ntfnFileQuarantined,
+		ntfnDigest,
+	}
+)
+
+// recipient is a notification recipient.
+type recipient struct {
+	events.Recipient
+	userID string
+	digest www.EmailDigestT
+}
+
+// recipients contains the notification recipients.
+type recipients []recipient
+
+// newRecipients returns a recipients that contains the provided users.
+func newRecipients(users ...*user.User) recipients {
+	r := make(recipients, 0, len(users))
+	for _, u := range users {
+		r.add(u)
+	}
+	return r
+}
+
+// add adds a user to the recipients.
+func (r *recipients) add(u *user.User) {
+	*r = append(*r, recipient{
+		Recipient: events.Recipient{
+			Email:    u.Email,
+			TimeZone: u.TimeZone,
+			Locale:   u.Locale,
+		},
+		userID: u.ID.String(),
+		digest: www.EmailDigestT(u.EmailDigest),
+	})
+}
+
+// notify sends a notification to the notifiers that the notification event
+// is routed to. The tmplData function returns the template data of the email
+// body given the timestamp formatted using the date preferences of the
+// recipient.
+//
+// The notifications of the recipients that have an email digest set are added
+// to their digest instead of being emailed immediately. This only applies to
+// the events that are routed to the smtp notifier.
+func (p *Pi) notify(event, subject string, tmpl *template.Template, timestamp int64, ntfnBit uint64, rs recipients, tmplData func(date string) interface{}) error {
+	body := func(date string) (string, error) {
+		return populateTemplate(tmpl, tmplData(date))
+	}
+
+	var (
+		now    = make([]events.Recipient, 0, len(rs))
+		digest = p.events.IsRouted(event, mail.NotifierSMTP)
+	)
+	for _, v := range rs {
+		if !digest || v.digest == www.EmailDigestNone {
+			now = append(now, v.Recipient)
+			continue
+		}
+		b, err := body(mail.FormatTime(timestamp, v.TimeZone, v.Locale))
+		if err != nil {
+			return err
+		}
+		err = p.digests.add(v.userID, v.Recipient, v.digest,
+			digestEntry{
+				Subject:   subject,
+				Body:      b,
+				NtfnBit:   ntfnBit,
+				Timestamp: timestamp,
+			})
+		if err != nil {
+			return err
+		}
+	}
+
+	return p.events.Notify(event, now, events.Notification{
+		Subject:   subject,
+		Timestamp: timestamp,
+		NtfnBit:   ntfnBit,
+		Body:      body,
+	})
+}
+
 type proposalNew struct {
 	Username string // Author username
 	Name     string // Proposal name
 	Link     string // GUI proposal details URL
+	Date     string // Submission date
 }
 
 var proposalNewText = `
@@ -33,31 +161,31 @@ A new proposal has been submitted on Politeia by {{.Username}}:
 
 {{.Name}}
 {{.Link}}
+
+Submitted: {{.Date}}
 `
 
 var proposalNewTmpl = template.Must(
 	template.New("proposalNew").Parse(proposalNewText))
 
-func (p *Pi) mailNtfnProposalNew(token, name, username string, emails []string) error {
+func (p *Pi) mailNtfnProposalNew(token, name, username string, timestamp int64, rs recipients) error {
 	route := strings.Replace(guiRouteRecordDetails, "{token}", token, 1)
 	u, err := url.Parse(p.cfg.WebServerAddress + route)
 	if err != nil {
 		return err
 	}
 
-	tmplData := proposalNew{
-		Username: username,
-		Name:     name,
-		Link:     u.String(),
-	}
-
 	subject := fmt.Sprintf(`New Proposal Submitted "%v"`, name)
-	body, err := populateTemplate(proposalNewTmpl, tmplData)
-	if err != nil {
-		return err
-	}
-
-	return p.mail.SendTo(subject, body, emails)
+	return p.notify(ntfnProposalNew, subject, proposalNewTmpl, timestamp,
+		uint64(www.NotificationEmailAdminProposalNew), rs,
+		func(date string) interface{} {
+			return proposalNew{
+				Username: username,
+				Name:     name,
+				Link:     u.String(),
+				Date:     date,
+			}
+		})
 }
 
 type proposalEdit struct {
@@ -65,6 +193,7 @@ type proposalEdit struct {
 	Version  uint32 // Proposal version
 	Username string // Author username
 	Link     string // GUI proposal details URL
+	Date     string // Edit date
 }
 
 var proposalEditText = `
@@ -72,37 +201,38 @@ A proposal by {{.Username}} has just been edited:
 
 {{.Name}} (Version {{.Version}})
 {{.Link}}
+
+Edited: {{.Date}}
 `
 
 var proposalEditTmpl = template.Must(
 	template.New("proposalEdit").Parse(proposalEditText))
 
-func (p *Pi) mailNtfnProposalEdit(token string, version uint32, name, username string, emails []string) error {
+func (p *Pi) mailNtfnProposalEdit(token string, version uint32, name, username string, timestamp int64, rs recipients) error {
 	route := strings.Replace(guiRouteRecordDetails, "{token}", token, 1)
 	u, err := url.Parse(p.cfg.WebServerAddress + route)
 	if err != nil {
 		return err
 	}
 
-	tmplData := proposalEdit{
-		Name:     name,
-		Version:  version,
-		Username: username,
-		Link:     u.String(),
-	}
-
 	subject := fmt.Sprintf(`Proposal Edited "%v"`, name)
-	body, err := populateTemplate(proposalEditTmpl, tmplData)
-	if err != nil {
-		return err
-	}
-
-	return p.mail.SendTo(subject, body, emails)
+	return p.notify(ntfnProposalEdit, subject, proposalEditTmpl, timestamp,
+		uint64(www.NotificationEmailRegularProposalEdited), rs,
+		func(date string) interface{} {
+			return proposalEdit{
+				Name:     name,
+				Version:  version,
+				Username: username,
+				Link:     u.String(),
+				Date:     date,
+			}
+		})
 }
 
 type proposalPublished struct {
 	Name string // Proposal name
 	Link string // GUI proposal details URL
+	Date string // Publish date
 }
 
 var proposalPublishedTmpl = template.Must(
@@ -113,9 +243,11 @@ A new proposal has just been published on Politeia.
 
 {{.Name}}
 {{.Link}}
+
+Published: {{.Date}}
 `
 
-func (p *Pi) mailNtfnProposalSetStatus(token, name string, status rcv1.RecordStatusT, emails []string) error {
+func (p *Pi) mailNtfnProposalSetStatus(token, name string, status rcv1.RecordStatusT, timestamp int64, rs recipients) error {
 	route := strings.Replace(guiRouteRecordDetails, "{token}", token, 1)
 	u, err := url.Parse(p.cfg.WebServerAddress + route)
 	if err != nil {
@@ -123,31 +255,34 @@ func (p *Pi) mailNtfnProposalSetStatus(token, name string, status rcv1.RecordSta
 	}
 
 	var (
-		subject string
-		body    string
+		subject  string
+		tmpl     *template.Template
+		tmplData func(date string) interface{}
 	)
 	switch status {
 	case rcv1.RecordStatusPublic:
 		subject = fmt.Sprintf(`New Proposal Published "%v"`, name)
-		tmplData := proposalPublished{
-			Name: name,
-			Link: u.String(),
-		}
-		body, err = populateTemplate(proposalPublishedTmpl, tmplData)
-		if err != nil {
-			return err
+		tmpl = proposalPublishedTmpl
+		tmplData = func(date string) interface{} {
+			return proposalPublished{
+				Name: name,
+				Link: u.String(),
+				Date: date,
+			}
 		}
 
 	default:
 		return fmt.Errorf("no mail ntfn for status %v", status)
 	}
 
-	return p.mail.SendTo(subject, body, emails)
+	return p.notify(ntfnProposalPublished, subject, tmpl, timestamp,
+		uint64(www.NotificationEmailRegularProposalVetted), rs, tmplData)
 }
 
 type proposalPublishedToAuthor struct {
 	Name string // Proposal name
 	Link string // GUI proposal details URL
+	Date string // Publish date
 }
 
 var proposalPublishedToAuthorText = `
@@ -159,6 +294,7 @@ Once you feel that enough time has been given for discussion you may authorize t
 
 {{.Name}}
 {{.Link}}
+Published: {{.Date}}
 
 If you have any questions, drop by the proposals channel on matrix.
 https://chat.decred.org/#/room/#proposals:decred.org
@@ -170,6 +306,7 @@ var proposalPublishedToAuthorTmpl = template.Must(
 type proposalCensoredToAuthor struct {
 	Name   string // Proposal name
 	Reason string // Reason for censoring
+	Date   string // Censor date
 }
 
 var proposalCensoredToAuthorText = `
@@ -177,13 +314,14 @@ Your proposal on Politeia has been censored.
 
 {{.Name}}
 Reason: {{.Reason}}
+Censored: {{.Date}}
 `
 
 var proposalCensoredToAuthorTmpl = template.Must(
 	template.New("proposalCensoredToAuthor").
 		Parse(proposalCensoredToAuthorText))
 
-func (p *Pi) mailNtfnProposalSetStatusToAuthor(token, name string, status rcv1.RecordStatusT, reason, authorEmail string) error {
+func (p *Pi) mailNtfnProposalSetStatusToAuthor(token, name string, status rcv1.RecordStatusT, reason string, timestamp int64, author *user.User) error {
 	route := strings.Replace(guiRouteRecordDetails, "{token}", token, 1)
 	u, err := url.Parse(p.cfg.WebServerAddress + route)
 	if err != nil {
@@ -191,47 +329,51 @@ func (p *Pi) mailNtfnProposalSetStatusToAuthor(token, name string, status rcv1.R
 	}
 
 	var (
-		subject string
-		body    string
+		subject  string
+		tmpl     *template.Template
+		tmplData func(date string) interface{}
 	)
 	switch status {
 	case rcv1.RecordStatusPublic:
 		subject = "Your Proposal Has Been Published " + token
-		tmplData := proposalPublishedToAuthor{
-			Name: name,
-			Link: u.String(),
-		}
-		body, err = populateTemplate(proposalPublishedToAuthorTmpl, tmplData)
-		if err != nil {
-			return err
+		tmpl = proposalPublishedToAuthorTmpl
+		tmplData = func(date string) interface{} {
+			return proposalPublishedToAuthor{
+				Name: name,
+				Link: u.String(),
+				Date: date,
+			}
 		}
 
 	case rcv1.RecordStatusCensored:
 		subject = fmt.Sprintf(`Your Proposal Has Been Censored "%v"`, name)
-		tmplData := proposalCensoredToAuthor{
-			Name:   name,
-			Reason: reason,
-		}
-		body, err = populateTemplate(proposalCensoredToAuthorTmpl, tmplData)
-		if err != nil {
-			return err
+		tmpl = proposalCensoredToAuthorTmpl
+		tmplData = func(date string) interface{} {
+			return proposalCensoredToAuthor{
+				Name:   name,
+				Reason: reason,
+				Date:   date,
+			}
 		}
 
 	default:
 		return fmt.Errorf("no author notification for prop status %v", status)
 	}
 
-	return p.mail.SendTo(subject, body, []string{authorEmail})
+	return p.notify(ntfnProposalStatusToAuthor, subject, tmpl, timestamp,
+		uint64(www.NotificationEmailMyProposalStatusChange),
+		newRecipients(author), tmplData)
 }
 
 type commentNewToProposalAuthor struct {
 	Username string // Comment author username
 	Name     string // Proposal name
 	Link     string // Comment link
+	Date     string // Comment date
 }
 
 var commentNewToProposalAuthorText = `
-{{.Username}} has commented on your proposal "{{.Name}}".
+{{.Username}} has commented on your proposal "{{.Name}}" on {{.Date}}.
 
 {{.Link}}
 `
@@ -240,7 +382,7 @@ var commentNewToProposalAuthorTmpl = template.Must(
 	template.New("commentNewToProposalAuthor").
 		Parse(commentNewToProposalAuthorText))
 
-func (p *Pi) mailNtfnCommentNewToProposalAuthor(token string, commentID uint32, commentUsername, proposalName, proposalAuthorEmail string) error {
+func (p *Pi) mailNtfnCommentNewToProposalAuthor(token string, commentID uint32, commentUsername, proposalName string, timestamp int64, proposalAuthor *user.User) error {
 	cid := strconv.FormatUint(uint64(commentID), 10)
 	route := strings.Replace(guiRouteRecordComment, "{token}", token, 1)
 	route = strings.Replace(route, "{id}", cid, 1)
@@ -251,27 +393,29 @@ func (p *Pi) mailNtfnCommentNewToProposalAuthor(token string, commentID uint32,
 	}
 
 	subject := fmt.Sprintf(`New Comment on Your Proposal "%v"`, proposalName)
-	tmplData := commentNewToProposalAuthor{
-		Username: commentUsername,
-		Name:     proposalName,
-		Link:     u.String(),
-	}
-	body, err := populateTemplate(commentNewToProposalAuthorTmpl, tmplData)
-	if err != nil {
-		return err
-	}
-
-	return p.mail.SendTo(subject, body, []string{proposalAuthorEmail})
+	return p.notify(ntfnCommentNewToAuthor, subject,
+		commentNewToProposalAuthorTmpl, timestamp,
+		uint64(www.NotificationEmailCommentOnMyProposal),
+		newRecipients(proposalAuthor),
+		func(date string) interface{} {
+			return commentNewToProposalAuthor{
+				Username: commentUsername,
+				Name:     proposalName,
+				Link:     u.String(),
+				Date:     date,
+			}
+		})
 }
 
 type commentReply struct {
 	Username string // Comment author username
 	Name     string // Proposal name
 	Link     string // Comment link
+	Date     string // Comment date
 }
 
 var commentReplyText = `
-{{.Username}} has replied to your comment on "{{.Name}}".
+{{.Username}} has replied to your comment on "{{.Name}}" on {{.Date}}.
 
 {{.Link}}
 `
@@ -279,7 +423,7 @@ var commentReplyText = `
 var commentReplyTmpl = template.Must(
 	template.New("commentReply").Parse(commentReplyText))
 
-func (p *Pi) mailNtfnCommentReply(token string, commentID uint32, commentUsername, proposalName, parentAuthorEmail string) error {
+func (p *Pi) mailNtfnCommentReply(token string, commentID uint32, commentUsername, proposalName string, timestamp int64, parentAuthor *user.User) error {
 	cid := strconv.FormatUint(uint64(commentID), 10)
 	route := strings.Replace(guiRouteRecordComment, "{token}", token, 1)
 	route = strings.Replace(route, "{id}", cid, 1)
@@ -290,22 +434,23 @@ func (p *Pi) mailNtfnCommentReply(token string, commentID uint32, commentUsernam
 	}
 
 	subject := fmt.Sprintf(`New Reply to Your Comment on "%v"`, proposalName)
-	tmplData := commentReply{
-		Username: commentUsername,
-		Name:     proposalName,
-		Link:     u.String(),
-	}
-	body, err := populateTemplate(commentReplyTmpl, tmplData)
-	if err != nil {
-		return err
-	}
-
-	return p.mail.SendTo(subject, body, []string{parentAuthorEmail})
+	return p.notify(ntfnCommentReply, subject, commentReplyTmpl, timestamp,
+		uint64(www.NotificationEmailCommentOnMyComment),
+		newRecipients(parentAuthor),
+		func(date string) interface{} {
+			return commentReply{
+				Username: commentUsername,
+				Name:     proposalName,
+				Link:     u.String(),
+				Date:     date,
+			}
+		})
 }
 
 type voteAuthorized struct {
 	Name string // Proposal name
 	Link string // GUI proposal details url
+	Date string // Authorization date
 }
 
 var voteAuthorizedText = `
@@ -313,12 +458,14 @@ A proposal vote has been authorized.
 
 {{.Name}}
 {{.Link}}
+
+Authorized: {{.Date}}
 `
 
 var voteAuthorizedTmpl = template.Must(
 	template.New("voteAuthorized").Parse(voteAuthorizedText))
 
-func (p *Pi) mailNtfnVoteAuthorized(token, name string, emails []string) error {
+func (p *Pi) mailNtfnVoteAuthorized(token, name string, timestamp int64, rs recipients) error {
 	route := strings.Replace(guiRouteRecordDetails, "{token}", token, 1)
 	u, err := url.Parse(p.cfg.WebServerAddress + route)
 	if err != nil {
@@ -326,21 +473,21 @@ func (p *Pi) mailNtfnVoteAuthorized(token, name string, emails []string) error {
 	}
 
 	subject := fmt.Sprintf(`Voting Authorized for "%v"`, name)
-	tmplData := voteAuthorized{
-		Name: name,
-		Link: u.String(),
-	}
-	body, err := populateTemplate(voteAuthorizedTmpl, tmplData)
-	if err != nil {
-		return err
-	}
-
-	return p.mail.SendTo(subject, body, emails)
+	return p.notify(ntfnVoteAuthorized, subject, voteAuthorizedTmpl, timestamp,
+		uint64(www.NotificationEmailAdminProposalVoteAuthorized), rs,
+		func(date string) interface{} {
+			return voteAuthorized{
+				Name: name,
+				Link: u.String(),
+				Date: date,
+			}
+		})
 }
 
 type voteStarted struct {
 	Name string // Proposal name
 	Link string // GUI proposal details url
+	Date string // Vote start date
 }
 
 const voteStartedText = `
@@ -348,12 +495,14 @@ Voting has started on a Politeia proposal.
 
 {{.Name}}
 {{.Link}}
+
+Voting started: {{.Date}}
 `
 
 var voteStartedTmpl = template.Must(
 	template.New("voteStarted").Parse(voteStartedText))
 
-func (p *Pi) mailNtfnVoteStarted(token, name string, emails []string) error {
+func (p *Pi) mailNtfnVoteStarted(token, name string, timestamp int64, rs recipients) error {
 	route := strings.Replace(guiRouteRecordDetails, "{token}", token, 1)
 	u, err := url.Parse(p.cfg.WebServerAddress + route)
 	if err != nil {
@@ -361,21 +510,21 @@ func (p *Pi) mailNtfnVoteStarted(token, name string, emails []string) error {
 	}
 
 	subject := fmt.Sprintf(`Voting Started for "%v"`, name)
-	tmplData := voteStarted{
-		Name: name,
-		Link: u.String(),
-	}
-	body, err := populateTemplate(voteStartedTmpl, tmplData)
-	if err != nil {
-		return err
-	}
-
-	return p.mail.SendTo(subject, body, emails)
+	return p.notify(ntfnVoteStarted, subject, voteStartedTmpl, timestamp,
+		uint64(www.NotificationEmailRegularProposalVoteStarted), rs,
+		func(date string) interface{} {
+			return voteStarted{
+				Name: name,
+				Link: u.String(),
+				Date: date,
+			}
+		})
 }
 
 type voteStartedToAuthor struct {
 	Name string // Proposal name
 	Link string // GUI proposal details url
+	Date string // Vote start date
 }
 
 const voteStartedToAuthorText = `
@@ -383,12 +532,14 @@ Voting has just started on your Politeia proposal.
 
 {{.Name}}
 {{.Link}}
+
+Voting started: {{.Date}}
 `
 
 var voteStartedToAuthorTmpl = template.Must(
 	template.New("voteStartedToAuthor").Parse(voteStartedToAuthorText))
 
-func (p *Pi) mailNtfnVoteStartedToAuthor(token, name, email string) error {
+func (p *Pi) mailNtfnVoteStartedToAuthor(token, name string, timestamp int64, author *user.User) error {
 	route := strings.Replace(guiRouteRecordDetails, "{token}", token, 1)
 	u, err := url.Parse(p.cfg.WebServerAddress + route)
 	if err != nil {
@@ -396,16 +547,228 @@ func (p *Pi) mailNtfnVoteStartedToAuthor(token, name, email string) error {
 	}
 
 	subject := fmt.Sprintf(`Voting Started on Your Proposal "%v"`, name)
-	tmplData := voteStartedToAuthor{
-		Name: name,
-		Link: u.String(),
+	return p.notify(ntfnVoteStartedToAuthor, subject,
+		voteStartedToAuthorTmpl, timestamp,
+		uint64(www.NotificationEmailMyProposalVoteStarted),
+		newRecipients(author),
+		func(date string) interface{} {
+			return voteStartedToAuthor{
+				Name: name,
+				Link: u.String(),
+				Date: date,
+			}
+		})
+}
+
+type voteFinishedToAuthor struct {
+	Name        string // Proposal name
+	Link        string // GUI proposal details url
+	Certificate string // Human readable vote certificate
+}
+
+const voteFinishedToAuthorText = `
+Voting has finished on your Politeia proposal.
+
+{{.Name}}
+{{.Link}}
+
+The vote certificate is included below. The signed certificate can be
+retrieved using the ticketvote certificate route.
+
+{{.Certificate}}
+`
+
+var voteFinishedToAuthorTmpl = template.Must(
+	template.New("voteFinishedToAuthor").Parse(voteFinishedToAuthorText))
+
+func (p *Pi) mailNtfnVoteFinishedToAuthor(token, name, certificate string, author *user.User) error {
+	route := strings.Replace(guiRouteRecordDetails, "{token}", token, 1)
+	u, err := url.Parse(p.cfg.WebServerAddress + route)
+	if err != nil {
+		return err
+	}
+
+	// The email does not include a date so the timestamp is not set
+	subject := fmt.Sprintf(`Voting Finished on Your Proposal "%v"`, name)
+	return p.notify(ntfnVoteFinishedToAuthor, subject,
+		voteFinishedToAuthorTmpl, 0,
+		uint64(www.NotificationEmailMyProposalStatusChange),
+		newRecipients(author),
+		func(date string) interface{} {
+			return voteFinishedToAuthor{
+				Name:        name,
+				Link:        u.String(),
+				Certificate: certificate,
+			}
+		})
+}
+
+type reportNewEntry struct {
+	Category string // Report category
+	Reason   string // Report reason
+	Link     string // GUI link of the reported content
+}
+
+type reportNew struct {
+	Reports []reportNewEntry
+	More    int    // Number of reports that are not listed
+	Date    string // Submission date of the most recent report
+}
+
+const reportNewText = `
+{{if eq (len .Reports) 1}}A new report has been{{else}}New reports have been{{end}} submitted on Politeia.
+{{range .Reports}}
+{{.Category}} report: {{.Link}}
+
+Reason:
+{{.Reason}}
+{{end}}{{if .More}}
+{{.More}} more reports have been submitted.
+{{end}}
+Submitted: {{.Date}}
+
+The reports can be resolved using the pi reports routes.
+`
+
+var reportNewTmpl = template.Must(
+	template.New("reportNew").Parse(reportNewText))
+
+// reportNewListMax is the maximum number of reports that are listed in a
+// new report notification.
+const reportNewListMax = 20
+
+func (p *Pi) mailNtfnReportNew(reports []piv1.Report, rs recipients) error {
+	if len(reports) == 0 {
+		return nil
+	}
+	list := reports
+	if len(list) > reportNewListMax {
+		list = list[len(list)-reportNewListMax:]
 	}
-	body, err := populateTemplate(voteStartedToAuthorTmpl, tmplData)
+	entries := make([]reportNewEntry, 0, len(list))
+	for _, r := range list {
+		route := strings.Replace(guiRouteRecordDetails, "{token}", r.Token, 1)
+		if r.CommentID != 0 {
+			cid := strconv.FormatUint(uint64(r.CommentID), 10)
+			route = strings.Replace(guiRouteRecordComment, "{token}",
+				r.Token, 1)
+			route = strings.Replace(route, "{id}", cid, 1)
+		}
+		u, err := url.Parse(p.cfg.WebServerAddress + route)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, reportNewEntry{
+			Category: strings.Title(piv1.ReportCategories[r.Category]),
+			Reason:   r.Reason,
+			Link:     u.String(),
+		})
+	}
+
+	latest := reports[len(reports)-1]
+	subject := fmt.Sprintf("New %v Report %v",
+		strings.Title(piv1.ReportCategories[latest.Category]), latest.ID)
+	if len(reports) > 1 {
+		subject = fmt.Sprintf("%v New Reports", len(reports))
+	}
+	return p.notify(ntfnReportNew, subject, reportNewTmpl, latest.Timestamp,
+		uint64(www.NotificationEmailAdminReportNew), rs,
+		func(date string) interface{} {
+			return reportNew{
+				Reports: entries,
+				More:    len(reports) - len(entries),
+				Date:    date,
+			}
+		})
+}
+
+type authorUpdate struct {
+	Username string // Author username
+	Name     string // Proposal name
+	Update   string // Author update text
+	Link     string // GUI proposal details URL
+	Date     string // Update date
+}
+
+const authorUpdateText = `
+{{.Username}} has posted an update on their Politeia proposal.
+
+{{.Name}}
+{{.Link}}
+
+{{.Update}}
+
+Posted: {{.Date}}
+`
+
+var authorUpdateTmpl = template.Must(
+	template.New("authorUpdate").Parse(authorUpdateText))
+
+func (p *Pi) mailNtfnAuthorUpdate(au piv1.AuthorUpdate, name string, rs recipients) error {
+	route := strings.Replace(guiRouteRecordDetails, "{token}", au.Token, 1)
+	u, err := url.Parse(p.cfg.WebServerAddress + route)
 	if err != nil {
 		return err
 	}
 
-	return p.mail.SendTo(subject, body, []string{email})
+	subject := fmt.Sprintf(`Author Update on Proposal "%v"`, name)
+	return p.notify(ntfnAuthorUpdate, subject, authorUpdateTmpl,
+		au.Timestamp,
+		uint64(www.NotificationEmailRegularProposalAuthorUpdate), rs,
+		func(date string) interface{} {
+			return authorUpdate{
+				Username: au.Username,
+				Name:     name,
+				Update:   au.Update,
+				Link:     u.String(),
+				Date:     date,
+			}
+		})
+}
+
+type fileQuarantined struct {
+	Username  string // Uploader username
+	Name      string // File name
+	Signature string // Detected threat
+	Upload    string // New record or record edit
+	Date      string // Upload date
+}
+
+const fileQuarantinedText = `
+A file that was uploaded to Politeia by {{.Username}} was found to be
+infected and has been quarantined. The upload was rejected.
+
+File: {{.Name}}
+Threat: {{.Signature}}
+Upload: {{.Upload}}
+
+Uploaded: {{.Date}}
+
+The file can be reviewed in the quarantine directory of the politeiawww
+data directory.
+`
+
+var fileQuarantinedTmpl = template.Must(
+	template.New("fileQuarantined").Parse(fileQuarantinedText))
+
+func (p *Pi) mailNtfnFileQuarantined(e records.EventFileQuarantined, rs recipients) error {
+	upload := "new proposal"
+	if e.Token != "" {
+		upload = "edit of proposal " + e.Token
+	}
+
+	subject := fmt.Sprintf("Infected File Quarantined %v", e.Name)
+	return p.notify(ntfnFileQuarantined, subject, fileQuarantinedTmpl,
+		e.Timestamp, uint64(www.NotificationEmailAdminFileQuarantined), rs,
+		func(date string) interface{} {
+			return fileQuarantined{
+				Username:  e.User.Username,
+				Name:      e.Name,
+				Signature: e.Signature,
+				Upload:    upload,
+				Date:      date,
+			}
+		})
 }
 
 func populateTemplate(tmpl *template.Template, tmplData interface{}) (string, error) {
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"path/filepath"
 	"strconv"
 
 	pdv2 "github.com/decred/politeia/politeiad/api/v2"
@@ -29,8 +30,38 @@ type Pi struct {
 	userdb    user.Database
 	sessions  *sessions.Sessions
 	events    *events.Manager
-	mail      *mail.Client
 	policy    *v1.PolicyReply
+
+	// similarity is an in-memory index that is used to detect
+	// proposals that are likely duplicates of each other.
+	similarity *similarityIndex
+
+	// search is an in-memory index of the public proposals and their
+	// comments that is used to search them.
+	search *searchIndex
+
+	// wallet caches the proposal summaries that are returned to
+	// wallet integrations.
+	wallet *walletCache
+
+	// feeds caches the RSS and Atom feeds.
+	feeds *feedCache
+
+	// vetting contains the reviewer assignments of the proposals that
+	// are awaiting vetting.
+	vetting *vettingAssignments
+
+	// reports contains the legal and abuse reports.
+	reports *reportStore
+
+	// digests contains the pending email digests of the users that
+	// have an email digest set.
+	digests *digestQueue
+}
+
+// Policy returns the pi v1 policy.
+func (p *Pi) Policy() v1.PolicyReply {
+	return *p.policy
 }
 
 // HandlePolicy is the request handler for the pi v1 Policy route.
@@ -40,8 +71,144 @@ func (p *Pi) HandlePolicy(w http.ResponseWriter, r *http.Request) {
 	util.RespondWithJSON(w, http.StatusOK, p.policy)
 }
 
+// HandleSimilar is the request handler for the pi v1 Similar route.
+func (p *Pi) HandleSimilar(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleSimilar")
+
+	var s v1.Similar
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&s); err != nil {
+		respondWithError(w, r, "HandleSimilar: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	sr, err := p.processSimilar(r.Context(), s)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleSimilar: processSimilar: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, sr)
+}
+
+// HandleSearch is the request handler for the pi v1 Search route.
+func (p *Pi) HandleSearch(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleSearch")
+
+	var s v1.Search
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&s); err != nil {
+		respondWithError(w, r, "HandleSearch: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	sr, err := p.processSearch(s)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleSearch: processSearch: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, sr)
+}
+
+// HandleSetAuthorUpdate is the request handler for the pi v1 SetAuthorUpdate
+// route.
+func (p *Pi) HandleSetAuthorUpdate(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleSetAuthorUpdate")
+
+	var sau v1.SetAuthorUpdate
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&sau); err != nil {
+		respondWithError(w, r, "HandleSetAuthorUpdate: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	u, err := p.sessions.GetSessionUser(w, r)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleSetAuthorUpdate: GetSessionUser: %v", err)
+		return
+	}
+
+	saur, err := p.processSetAuthorUpdate(r.Context(), sau, *u)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleSetAuthorUpdate: processSetAuthorUpdate: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, saur)
+}
+
+// HandleAuthorUpdates is the request handler for the pi v1 AuthorUpdates
+// route.
+func (p *Pi) HandleAuthorUpdates(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleAuthorUpdates")
+
+	var au v1.AuthorUpdates
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&au); err != nil {
+		respondWithError(w, r, "HandleAuthorUpdates: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	aur, err := p.processAuthorUpdates(r.Context(), au)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleAuthorUpdates: processAuthorUpdates: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, aur)
+}
+
+// HandlePreflight is the request handler for the pi v1 Preflight route.
+func (p *Pi) HandlePreflight(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandlePreflight")
+
+	var pf v1.Preflight
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&pf); err != nil {
+		respondWithError(w, r, "HandlePreflight: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	u, err := p.sessions.GetSessionUser(w, r)
+	if err != nil {
+		respondWithError(w, r,
+			"HandlePreflight: GetSessionUser: %v", err)
+		return
+	}
+
+	pr, err := p.processPreflight(r.Context(), pf, u.Admin)
+	if err != nil {
+		respondWithError(w, r,
+			"HandlePreflight: processPreflight: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, pr)
+}
+
 // New returns a new Pi context.
-func New(cfg *config.Config, pdc *pdclient.Client, udb user.Database, s *sessions.Sessions, e *events.Manager, m *mail.Client, plugins []pdv2.Plugin) (*Pi, error) {
+func New(cfg *config.Config, pdc *pdclient.Client, udb user.Database, s *sessions.Sessions, e *events.Manager, plugins []pdv2.Plugin) (*Pi, error) {
 	// Parse plugin settings
 	var (
 		textFileSizeMax    uint32
@@ -50,6 +217,7 @@ func New(cfg *config.Config, pdc *pdclient.Client, udb user.Database, s *session
 		nameLengthMin      uint32
 		nameLengthMax      uint32
 		nameSupportedChars []string
+		updateLengthMax    uint32
 	)
 	for _, p := range plugins {
 		if p.ID != pi.PluginID {
@@ -95,6 +263,12 @@ func New(cfg *config.Config, pdc *pdclient.Client, udb user.Database, s *session
 					return nil, err
 				}
 				nameSupportedChars = sc
+			case pi.SettingKeyAuthorUpdateLengthMax:
+				u, err := strconv.ParseUint(v.Value, 10, 64)
+				if err != nil {
+					return nil, err
+				}
+				updateLengthMax = uint32(u)
 			default:
 				// Skip unknown settings
 				log.Warnf("Unknown plugin setting %v; Skipping...", v.Key)
@@ -119,6 +293,30 @@ func New(cfg *config.Config, pdc *pdclient.Client, udb user.Database, s *session
 	case nameLengthMax == 0:
 		return nil, fmt.Errorf("plugin setting not found: %v",
 			pi.SettingKeyProposalNameLengthMax)
+	case updateLengthMax == 0:
+		return nil, fmt.Errorf("plugin setting not found: %v",
+			pi.SettingKeyAuthorUpdateLengthMax)
+	}
+
+	// Load the vetting assignments
+	vetting, err := newVettingAssignments(filepath.Join(cfg.DataDir,
+		vettingFilename))
+	if err != nil {
+		return nil, err
+	}
+
+	// Load the reports
+	reports, err := newReportStore(filepath.Join(cfg.DataDir,
+		reportsFilename))
+	if err != nil {
+		return nil, err
+	}
+
+	// Load the pending email digests
+	digests, err := newDigestQueue(filepath.Join(cfg.DataDir,
+		digestsFilename))
+	if err != nil {
+		return nil, err
 	}
 
 	// Setup pi context
@@ -128,19 +326,44 @@ func New(cfg *config.Config, pdc *pdclient.Client, udb user.Database, s *session
 		userdb:    udb,
 		sessions:  s,
 		events:    e,
-		mail:      m,
 		policy: &v1.PolicyReply{
-			TextFileSizeMax:    textFileSizeMax,
-			ImageFileCountMax:  imageFileCountMax,
-			ImageFileSizeMax:   imageFileSizeMax,
-			NameLengthMin:      nameLengthMin,
-			NameLengthMax:      nameLengthMax,
-			NameSupportedChars: nameSupportedChars,
+			TextFileSizeMax:       textFileSizeMax,
+			ImageFileCountMax:     imageFileCountMax,
+			ImageFileSizeMax:      imageFileSizeMax,
+			NameLengthMin:         nameLengthMin,
+			NameLengthMax:         nameLengthMax,
+			NameSupportedChars:    nameSupportedChars,
+			SimilarityThreshold:   cfg.SimilarityThreshold,
+			AuthorUpdateLengthMax: updateLengthMax,
+			VettingSLA:            int64(cfg.VettingSLA) * 3600,
+			ReportReasonLengthMax: reportReasonLengthMax,
 		},
+		similarity: newSimilarityIndex(),
+		search:     newSearchIndex(),
+		wallet:     newWalletCache(),
+		feeds:      newFeedCache(),
+		vetting:    vetting,
+		reports:    reports,
+		digests:    digests,
 	}
 
 	// Setup event listeners
 	p.setupEventListeners()
 
+	// Route the notification events to the smtp notifier by default.
+	// The routes can be changed using the notifier config option.
+	for _, v := range ntfnEvents {
+		e.Route(v, mail.NotifierSMTP)
+	}
+
+	// Build the similarity index in the background
+	go p.similarityIndexBuild()
+
+	// Build the search index in the background
+	go p.searchIndexBuild()
+
+	// Send the email digests once they are due
+	go p.digestLoop()
+
 	return &p, nil
 }
@@ -0,0 +1,105 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pi
+
+import (
+	"context"
+
+	"github.com/decred/politeia/politeiad/plugins/pi"
+	v1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/google/uuid"
+)
+
+func (p *Pi) processSetAuthorUpdate(ctx context.Context, sau v1.SetAuthorUpdate, u user.User) (*v1.SetAuthorUpdateReply, error) {
+	log.Tracef("processSetAuthorUpdate: %v %v", sau.Token, u.Username)
+
+	// Verify user signed using active identity
+	if u.PublicKey() != sau.PublicKey {
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodePublicKeyInvalid,
+			ErrorContext: "not active identity",
+		}
+	}
+
+	// Send plugin command. The pi plugin verifies that the user is
+	// the record author.
+	psau := pi.SetAuthorUpdate{
+		UserID:    u.ID.String(),
+		Token:     sau.Token,
+		Update:    sau.Update,
+		PublicKey: sau.PublicKey,
+		Signature: sau.Signature,
+	}
+	au, err := p.politeiad.PiSetAuthorUpdate(ctx, psau)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare reply
+	a := convertAuthorUpdateToV1(*au)
+	a.Username = u.Username
+
+	// Emit event
+	p.events.Emit(EventTypeAuthorUpdate,
+		EventAuthorUpdate{
+			AuthorUpdate: a,
+		})
+
+	return &v1.SetAuthorUpdateReply{
+		AuthorUpdate: a,
+	}, nil
+}
+
+func (p *Pi) processAuthorUpdates(ctx context.Context, au v1.AuthorUpdates) (*v1.AuthorUpdatesReply, error) {
+	log.Tracef("processAuthorUpdates: %v", au.Token)
+
+	// Get author updates
+	aus, err := p.politeiad.PiAuthorUpdates(ctx, au.Token)
+	if err != nil {
+		return nil, err
+	}
+	if len(aus) == 0 {
+		return &v1.AuthorUpdatesReply{
+			Updates: []v1.AuthorUpdate{},
+		}, nil
+	}
+
+	// Only the record author can set the author update so all
+	// versions have the same user ID. Lookup the username once.
+	uid, err := uuid.Parse(aus[0].UserID)
+	if err != nil {
+		return nil, err
+	}
+	u, err := p.userdb.UserGetById(uid)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make([]v1.AuthorUpdate, 0, len(aus))
+	for _, v := range aus {
+		a := convertAuthorUpdateToV1(v)
+		a.Username = u.Username
+		updates = append(updates, a)
+	}
+
+	return &v1.AuthorUpdatesReply{
+		Updates: updates,
+	}, nil
+}
+
+func convertAuthorUpdateToV1(au pi.AuthorUpdate) v1.AuthorUpdate {
+	return v1.AuthorUpdate{
+		UserID:    au.UserID,
+		Username:  "", // Intentionally omitted
+		Token:     au.Token,
+		Update:    au.Update,
+		PublicKey: au.PublicKey,
+		Signature: au.Signature,
+		Version:   au.Version,
+		Timestamp: au.Timestamp,
+		Receipt:   au.Receipt,
+	}
+}
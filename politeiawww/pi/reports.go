@@ -0,0 +1,426 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	cmplugin "github.com/decred/politeia/politeiad/plugins/comments"
+	v1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	"github.com/decred/politeia/politeiawww/sessions"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
+	"github.com/google/uuid"
+)
+
+const (
+	// reportsFilename is the name of the file in the data directory
+	// that the reports are persisted to.
+	reportsFilename = "reports.json"
+
+	// reportReasonLengthMax is the maximum number of characters that
+	// the reason and the resolution of a report can be.
+	reportReasonLengthMax = 2000
+
+	// reportContactLengthMax is the maximum number of characters that
+	// the contact of a report can be.
+	reportContactLengthMax = 256
+
+	// reportsOpenMax is the maximum number of open reports. New reports
+	// are rejected once the moderation queue is full so that the
+	// public route can't be used to fill the disk.
+	reportsOpenMax = 1000
+)
+
+// reportStore contains the legal and abuse reports. The reports are
+// persisted to disk on every change so that they survive a restart.
+type reportStore struct {
+	sync.Mutex
+	path    string
+	reports map[string]v1.Report // [id]report
+}
+
+// newReportStore returns a new reportStore that is loaded from the provided
+// file. The file is created on the first report if it does not exist.
+func newReportStore(path string) (*reportStore, error) {
+	rs := reportStore{
+		path:    path,
+		reports: make(map[string]v1.Report, 64),
+	}
+	b, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return &rs, nil
+	case err != nil:
+		return nil, err
+	}
+	err = json.Unmarshal(b, &rs.reports)
+	if err != nil {
+		return nil, fmt.Errorf("decode %v: %v", path, err)
+	}
+	return &rs, nil
+}
+
+// save writes the reports to disk. The file is replaced atomically.
+//
+// This function must be called WITH the lock held.
+func (rs *reportStore) save() error {
+	b, err := json.Marshal(rs.reports)
+	if err != nil {
+		return err
+	}
+	tmp := rs.path + ".tmp"
+	err = ioutil.WriteFile(tmp, b, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, rs.path)
+}
+
+// add adds a new report. An error is returned if the maximum number of open
+// reports has been reached.
+func (rs *reportStore) add(r v1.Report) error {
+	rs.Lock()
+	defer rs.Unlock()
+
+	var open int
+	for _, v := range rs.reports {
+		if v.Status == v1.ReportStatusOpen {
+			open++
+		}
+	}
+	if open >= reportsOpenMax {
+		return v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeInputInvalid,
+			ErrorContext: "the moderation queue is full; try again " +
+				"later",
+		}
+	}
+
+	rs.reports[r.ID] = r
+	return rs.save()
+}
+
+// resolve sets the status of an open report to resolved or dismissed and
+// returns the updated report.
+func (rs *reportStore) resolve(id string, status v1.ReportStatusT, resolution, adminID string) (*v1.Report, error) {
+	rs.Lock()
+	defer rs.Unlock()
+
+	r, ok := rs.reports[id]
+	if !ok {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeReportNotFound,
+		}
+	}
+	if r.Status != v1.ReportStatusOpen {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeReportStatusInvalid,
+			ErrorContext: fmt.Sprintf("report is already %v",
+				v1.ReportStatuses[r.Status]),
+		}
+	}
+
+	r.Status = status
+	r.Resolution = resolution
+	r.ResolvedBy = adminID
+	r.ResolvedAt = time.Now().Unix()
+	rs.reports[id] = r
+
+	err := rs.save()
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// filter returns the reports that match the provided status and token. A
+// zero status or an empty token matches all reports. The reports are sorted
+// by timestamp from oldest to newest.
+func (rs *reportStore) filter(status v1.ReportStatusT, token string) []v1.Report {
+	rs.Lock()
+	defer rs.Unlock()
+
+	reports := make([]v1.Report, 0, len(rs.reports))
+	for _, v := range rs.reports {
+		if status != v1.ReportStatusInvalid && v.Status != status {
+			continue
+		}
+		if token != "" && v.Token != token {
+			continue
+		}
+		reports = append(reports, v)
+	}
+	sort.SliceStable(reports, func(i, j int) bool {
+		if reports[i].Timestamp == reports[j].Timestamp {
+			return reports[i].ID < reports[j].ID
+		}
+		return reports[i].Timestamp < reports[j].Timestamp
+	})
+	return reports
+}
+
+// HandleReportNew is the request handler for the pi v1 ReportNew route.
+func (p *Pi) HandleReportNew(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleReportNew")
+
+	var rn v1.ReportNew
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&rn); err != nil {
+		respondWithError(w, r, "HandleReportNew: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	// Lookup session user. This is a public route so a session may not
+	// exist. Ignore any session not found errors.
+	u, err := p.sessions.GetSessionUser(w, r)
+	if err != nil && err != sessions.ErrSessionNotFound {
+		respondWithError(w, r,
+			"HandleReportNew: GetSessionUser: %v", err)
+		return
+	}
+
+	rnr, err := p.processReportNew(r.Context(), rn, u)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleReportNew: processReportNew: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, rnr)
+}
+
+// HandleReports is the request handler for the pi v1 Reports route.
+func (p *Pi) HandleReports(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleReports")
+
+	var rs v1.Reports
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&rs); err != nil {
+		respondWithError(w, r, "HandleReports: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	rsr, err := p.processReports(rs)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleReports: processReports: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, rsr)
+}
+
+// HandleReportResolve is the request handler for the pi v1 ReportResolve
+// route.
+func (p *Pi) HandleReportResolve(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleReportResolve")
+
+	var rr v1.ReportResolve
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&rr); err != nil {
+		respondWithError(w, r, "HandleReportResolve: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	u, err := p.sessions.GetSessionUser(w, r)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleReportResolve: GetSessionUser: %v", err)
+		return
+	}
+
+	rrr, err := p.processReportResolve(rr, *u)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleReportResolve: processReportResolve: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, rrr)
+}
+
+func (p *Pi) processReportNew(ctx context.Context, rn v1.ReportNew, u *user.User) (*v1.ReportNewReply, error) {
+	log.Tracef("processReportNew: %v %v", rn.Token, rn.CommentID)
+
+	// Verify the report contents
+	if _, ok := v1.ReportCategories[rn.Category]; !ok ||
+		rn.Category == v1.ReportCategoryInvalid {
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeInputInvalid,
+			ErrorContext: "invalid category",
+		}
+	}
+	reason := strings.TrimSpace(rn.Reason)
+	switch {
+	case reason == "":
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeInputInvalid,
+			ErrorContext: "reason is empty",
+		}
+	case utf8.RuneCountInString(reason) > reportReasonLengthMax:
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeInputInvalid,
+			ErrorContext: fmt.Sprintf("reason exceeds %v characters",
+				reportReasonLengthMax),
+		}
+	}
+	contact := strings.TrimSpace(rn.Contact)
+	if utf8.RuneCountInString(contact) > reportContactLengthMax {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeInputInvalid,
+			ErrorContext: fmt.Sprintf("contact exceeds %v characters",
+				reportContactLengthMax),
+		}
+	}
+
+	// Verify the reported proposal is public. The full token is used
+	// for the report in case a token prefix was provided.
+	if rn.Token == "" {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeTokenInvalid,
+		}
+	}
+	reqs := []pdv2.RecordRequest{
+		{
+			Token:        rn.Token,
+			OmitAllFiles: true,
+		},
+	}
+	records, err := p.politeiad.Records(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+	pr, ok := records[rn.Token]
+	if !ok || pr.State != pdv2.RecordStateVetted ||
+		pr.Status != pdv2.RecordStatusPublic {
+		// Unvetted proposals are reported as not found so that the
+		// route can't be used to check whether they exist.
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeRecordNotFound,
+		}
+	}
+	token := pr.CensorshipRecord.Token
+
+	// Verify the reported comment exists
+	if rn.CommentID != 0 {
+		g := cmplugin.Get{
+			CommentIDs: []uint32{rn.CommentID},
+		}
+		cs, err := p.politeiad.CommentsGet(ctx, token, g)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := cs[rn.CommentID]; !ok {
+			return nil, v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeCommentNotFound,
+			}
+		}
+	}
+
+	// Save the report
+	report := v1.Report{
+		ID:        uuid.New().String(),
+		Token:     token,
+		CommentID: rn.CommentID,
+		Category:  rn.Category,
+		Reason:    reason,
+		Contact:   contact,
+		Status:    v1.ReportStatusOpen,
+		Timestamp: time.Now().Unix(),
+	}
+	if u != nil {
+		report.UserID = u.ID.String()
+	}
+	err = p.reports.add(report)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Report submitted: %v %v %v %v", report.ID,
+		v1.ReportCategories[report.Category], token, report.CommentID)
+
+	// Emit event
+	p.events.Emit(EventTypeReportNew,
+		EventReportNew{
+			Report: report,
+		})
+
+	return &v1.ReportNewReply{
+		ID:        report.ID,
+		Timestamp: report.Timestamp,
+	}, nil
+}
+
+func (p *Pi) processReports(rs v1.Reports) (*v1.ReportsReply, error) {
+	log.Tracef("processReports: %v %v", rs.Status, rs.Token)
+
+	if _, ok := v1.ReportStatuses[rs.Status]; !ok {
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeInputInvalid,
+			ErrorContext: "invalid status",
+		}
+	}
+
+	return &v1.ReportsReply{
+		Reports: p.reports.filter(rs.Status, rs.Token),
+	}, nil
+}
+
+func (p *Pi) processReportResolve(rr v1.ReportResolve, u user.User) (*v1.ReportResolveReply, error) {
+	log.Tracef("processReportResolve: %v %v", rr.ID, rr.Status)
+
+	// Verify the resolution
+	switch rr.Status {
+	case v1.ReportStatusResolved, v1.ReportStatusDismissed:
+		// Valid resolution status
+	default:
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeReportStatusInvalid,
+			ErrorContext: "status must be resolved or dismissed",
+		}
+	}
+	resolution := strings.TrimSpace(rr.Resolution)
+	if utf8.RuneCountInString(resolution) > reportReasonLengthMax {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeInputInvalid,
+			ErrorContext: fmt.Sprintf("resolution exceeds %v characters",
+				reportReasonLengthMax),
+		}
+	}
+
+	r, err := p.reports.resolve(rr.ID, rr.Status, resolution,
+		u.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Report %v by %v: %v", v1.ReportStatuses[r.Status],
+		u.Username, r.ID)
+
+	return &v1.ReportResolveReply{
+		Report: *r,
+	}, nil
+}
@@ -0,0 +1,432 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	piplugin "github.com/decred/politeia/politeiad/plugins/pi"
+	tkplugin "github.com/decred/politeia/politeiad/plugins/ticketvote"
+	v1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	"github.com/decred/politeia/politeiawww/client"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
+)
+
+// The following are the reasons that the link between an RFP submission and
+// its RFP is broken.
+const (
+	linkErrSubmissionNotFound  = "submission not found"
+	linkErrSubmissionNotPublic = "submission is not public"
+	linkErrSubmissionNotLinked = "submission does not link to the rfp"
+	linkErrSubmissionNotListed = "submission is missing from the rfp " +
+		"submissions list"
+	linkErrRFPNotFound  = "rfp not found"
+	linkErrRFPNotPublic = "rfp is not public"
+	linkErrNotRFP       = "linked proposal is not an rfp"
+)
+
+// HandleRFP is the request handler for the pi v1 RFP route.
+func (p *Pi) HandleRFP(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleRFP")
+
+	var rfp v1.RFP
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&rfp); err != nil {
+		respondWithError(w, r, "HandleRFP: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	rr, err := p.processRFP(r.Context(), rfp)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleRFP: processRFP: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, rr)
+}
+
+// HandleRFPParent is the request handler for the pi v1 RFPParent route.
+func (p *Pi) HandleRFPParent(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleRFPParent")
+
+	var rp v1.RFPParent
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&rp); err != nil {
+		respondWithError(w, r, "HandleRFPParent: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	rpr, err := p.processRFPParent(r.Context(), rp)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleRFPParent: processRFPParent: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, rpr)
+}
+
+// HandleRFPRepair is the request handler for the pi v1 RFPRepair route.
+func (p *Pi) HandleRFPRepair(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleRFPRepair")
+
+	var rr v1.RFPRepair
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&rr); err != nil {
+		respondWithError(w, r, "HandleRFPRepair: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	u, err := p.sessions.GetSessionUser(w, r)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleRFPRepair: GetSessionUser: %v", err)
+		return
+	}
+
+	rrr, err := p.processRFPRepair(r.Context(), rr, *u)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleRFPRepair: processRFPRepair: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, rrr)
+}
+
+func (p *Pi) processRFP(ctx context.Context, rfp v1.RFP) (*v1.RFPReply, error) {
+	log.Tracef("processRFP: %v", rfp.Token)
+
+	r, err := p.rfpRecord(ctx, rfp.Token)
+	if err != nil {
+		return nil, err
+	}
+	vm := voteMetadataFromRecord(*r)
+	if vm == nil || vm.LinkBy == 0 {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeNotRFP,
+		}
+	}
+	token := r.CensorshipRecord.Token
+
+	// Get the submissions
+	subs, err := p.politeiad.TicketVoteSubmissions(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(subs)
+	records, err := p.rfpRecords(ctx, subs)
+	if err != nil {
+		return nil, err
+	}
+	vs, err := p.politeiad.TicketVoteSummaries(ctx, append([]string{token},
+		subs...))
+	if err != nil {
+		return nil, err
+	}
+
+	submissions := make([]v1.RFPProposal, 0, len(subs))
+	for _, v := range subs {
+		sr, ok := records[v]
+		if !ok {
+			submissions = append(submissions, v1.RFPProposal{
+				Token:      v,
+				LinkErrors: []string{linkErrSubmissionNotFound},
+			})
+			continue
+		}
+		s := convertRFPProposalToV1(sr, vs[v])
+		s.LinkErrors = submissionLinkErrors(token, s, true)
+		submissions = append(submissions, s)
+	}
+
+	return &v1.RFPReply{
+		RFP:         convertRFPProposalToV1(*r, vs[token]),
+		Submissions: submissions,
+	}, nil
+}
+
+func (p *Pi) processRFPParent(ctx context.Context, rp v1.RFPParent) (*v1.RFPParentReply, error) {
+	log.Tracef("processRFPParent: %v", rp.Token)
+
+	r, err := p.rfpRecord(ctx, rp.Token)
+	if err != nil {
+		return nil, err
+	}
+	vm := voteMetadataFromRecord(*r)
+	if vm == nil || vm.LinkTo == "" {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeNotRFPSubmission,
+		}
+	}
+	token := r.CensorshipRecord.Token
+
+	// Get the RFP. The vote summary of the RFP is only requested
+	// once it is known to be public or archived. The existence of an
+	// unvetted RFP must not be revealed.
+	tokens := []string{token}
+	records, err := p.rfpRecords(ctx, []string{vm.LinkTo})
+	if err != nil {
+		return nil, err
+	}
+	parent, ok := records[vm.LinkTo]
+	if ok && parent.State != pdv2.RecordStateVetted {
+		ok = false
+	}
+	if ok && rfpVisible(parent) {
+		tokens = append(tokens, vm.LinkTo)
+	}
+	vs, err := p.politeiad.TicketVoteSummaries(ctx, tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate the link
+	var (
+		rfp = v1.RFPProposal{
+			Token: vm.LinkTo,
+		}
+		sub = convertRFPProposalToV1(*r, vs[token])
+	)
+	switch {
+	case !ok:
+		sub.LinkErrors = []string{linkErrRFPNotFound}
+	case !rfpVisible(parent):
+		sub.LinkErrors = []string{linkErrRFPNotPublic}
+	default:
+		rfp = convertRFPProposalToV1(parent, vs[vm.LinkTo])
+		if rfp.LinkBy == 0 {
+			sub.LinkErrors = []string{linkErrNotRFP}
+			break
+		}
+		subs, err := p.politeiad.TicketVoteSubmissions(ctx, vm.LinkTo)
+		if err != nil {
+			return nil, err
+		}
+		var listed bool
+		for _, v := range subs {
+			if v == token {
+				listed = true
+				break
+			}
+		}
+		sub.LinkErrors = submissionLinkErrors(vm.LinkTo, sub, listed)
+	}
+
+	return &v1.RFPParentReply{
+		RFP:        rfp,
+		Submission: sub,
+	}, nil
+}
+
+func (p *Pi) processRFPRepair(ctx context.Context, rr v1.RFPRepair, u user.User) (*v1.RFPRepairReply, error) {
+	log.Tracef("processRFPRepair: %v %v", rr.Token, rr.Submissions)
+
+	r, err := p.rfpRecord(ctx, rr.Token)
+	if err != nil {
+		return nil, err
+	}
+	vm := voteMetadataFromRecord(*r)
+	if vm == nil || vm.LinkBy == 0 {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeNotRFP,
+		}
+	}
+	token := r.CensorshipRecord.Token
+
+	// The ticketvote plugin requires the full length tokens of the
+	// submissions. All public proposals are searched for submissions
+	// when none were provided.
+	var candidates []string
+	if len(rr.Submissions) > 0 {
+		records, err := p.rfpRecords(ctx, rr.Submissions)
+		if err != nil {
+			return nil, err
+		}
+		candidates = make([]string, 0, len(rr.Submissions))
+		for _, v := range rr.Submissions {
+			r, ok := records[v]
+			if !ok {
+				return nil, v1.UserErrorReply{
+					ErrorCode:    v1.ErrorCodeRecordNotFound,
+					ErrorContext: v,
+				}
+			}
+			candidates = append(candidates, r.CensorshipRecord.Token)
+		}
+	} else {
+		candidates, err = p.rfpSubmissionsSearch(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	srr, err := p.politeiad.TicketVoteSubmissionsRepair(ctx, token,
+		tkplugin.SubmissionsRepair{
+			Tokens: candidates,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("RFP submissions repaired by %v: %v added %v removed %v",
+		u.Username, token, srr.Added, srr.Removed)
+
+	return &v1.RFPRepairReply{
+		Added:   srr.Added,
+		Removed: srr.Removed,
+	}, nil
+}
+
+// rfpSubmissionsSearch searches the vetted proposals for the public proposals
+// that link to the provided RFP.
+func (p *Pi) rfpSubmissionsSearch(ctx context.Context, rfpToken string) ([]string, error) {
+	subs := make([]string, 0, 16)
+	for page := uint32(1); ; page++ {
+		tokens, err := p.politeiad.InventoryOrdered(ctx,
+			pdv2.RecordStateVetted, page)
+		if err != nil {
+			return nil, err
+		}
+		if len(tokens) == 0 {
+			break
+		}
+		records, err := p.rfpRecords(ctx, tokens)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range tokens {
+			r, ok := records[v]
+			if !ok || !rfpVisible(r) {
+				continue
+			}
+			vm := voteMetadataFromRecord(r)
+			if vm == nil || vm.LinkTo != rfpToken {
+				continue
+			}
+			subs = append(subs, r.CensorshipRecord.Token)
+		}
+	}
+	return subs, nil
+}
+
+// rfpRecord returns the public or archived proposal record of the provided
+// token with only its metadata files.
+func (p *Pi) rfpRecord(ctx context.Context, token string) (*pdv2.Record, error) {
+	records, err := p.rfpRecords(ctx, []string{token})
+	if err != nil {
+		return nil, err
+	}
+	r, ok := records[token]
+	if !ok || !rfpVisible(r) {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeRecordNotFound,
+		}
+	}
+	return &r, nil
+}
+
+// rfpRecords returns the records of the provided tokens with only the proposal
+// metadata and vote metadata files. The records are requested in pages since
+// the politeiad records page size is smaller than the number of submissions
+// that an RFP can have. Tokens that do not correspond to a record are not
+// included.
+func (p *Pi) rfpRecords(ctx context.Context, tokens []string) (map[string]pdv2.Record, error) {
+	records := make(map[string]pdv2.Record, len(tokens))
+	for i := 0; i < len(tokens); i += int(pdv2.RecordsPageSize) {
+		end := i + int(pdv2.RecordsPageSize)
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+		reqs := make([]pdv2.RecordRequest, 0, end-i)
+		for _, v := range tokens[i:end] {
+			reqs = append(reqs, pdv2.RecordRequest{
+				Token: v,
+				Filenames: []string{
+					piplugin.FileNameProposalMetadata,
+					tkplugin.FileNameVoteMetadata,
+				},
+			})
+		}
+		rs, err := p.politeiad.Records(ctx, reqs)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range rs {
+			records[k] = v
+		}
+	}
+	return records, nil
+}
+
+// rfpVisible returns whether the contents of a proposal record can be
+// returned by the RFP routes.
+func rfpVisible(r pdv2.Record) bool {
+	return r.State == pdv2.RecordStateVetted &&
+		(r.Status == pdv2.RecordStatusPublic ||
+			r.Status == pdv2.RecordStatusArchived)
+}
+
+// voteMetadataFromRecord returns the decoded vote metadata of a record. Nil is
+// returned if the record does not have vote metadata.
+func voteMetadataFromRecord(r pdv2.Record) *v1.VoteMetadata {
+	vm, err := client.VoteMetadataDecode(convertFilesToV1(r.Files))
+	if err != nil {
+		return nil
+	}
+	return vm
+}
+
+// submissionLinkErrors returns the reasons that the link between a submission
+// and an RFP is broken. The listed argument is whether the submission is in
+// the submissions list of the RFP.
+func submissionLinkErrors(rfpToken string, s v1.RFPProposal, listed bool) []string {
+	switch {
+	case s.Status != uint32(rcv1.RecordStatusPublic) &&
+		s.Status != uint32(rcv1.RecordStatusArchived):
+		return []string{linkErrSubmissionNotPublic}
+	case s.LinkTo != rfpToken:
+		return []string{linkErrSubmissionNotLinked}
+	case !listed:
+		return []string{linkErrSubmissionNotListed}
+	}
+	return nil
+}
+
+// convertRFPProposalToV1 converts a proposal record and its vote summary to a
+// RFPProposal. The record contents are only included when the record is
+// public or archived.
+func convertRFPProposalToV1(r pdv2.Record, s tkplugin.SummaryReply) v1.RFPProposal {
+	rp := v1.RFPProposal{
+		Token:  r.CensorshipRecord.Token,
+		Status: uint32(convertStatusToV1(r.Status)),
+		Vote:   convertWalletVoteToV1(s),
+	}
+	if !rfpVisible(r) {
+		return rp
+	}
+	rp.Name = proposalNameFromFiles(convertFilesToV1(r.Files))
+	if vm := voteMetadataFromRecord(r); vm != nil {
+		rp.LinkBy = vm.LinkBy
+		rp.LinkTo = vm.LinkTo
+	}
+	return rp
+}
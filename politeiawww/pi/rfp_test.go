@@ -0,0 +1,391 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	pdclient "github.com/decred/politeia/politeiad/client"
+	tkplugin "github.com/decred/politeia/politeiad/plugins/ticketvote"
+	v1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+)
+
+const (
+	testRFP      = "0000000000000001"
+	testNotRFP   = "0000000000000002"
+	testUnvetted = "0000000000000003"
+	testCensored = "0000000000000004"
+	testListed   = "0000000000000005"
+	testUnlisted = "0000000000000006"
+	testMissing  = "0000000000000007"
+
+	// Submissions that link to the proposals above
+	testLinkNotRFP   = "0000000000000008"
+	testLinkMissing  = "0000000000000009"
+	testLinkUnvetted = "000000000000000a"
+	testLinkCensored = "000000000000000b"
+)
+
+// testPoliteiad is a fake politeiad that serves the records, vote summaries
+// and submissions lists that are used by the RFP routes.
+type testPoliteiad struct {
+	id          *identity.FullIdentity
+	records     map[string]pdv2.Record // [token]Record
+	submissions map[string][]string    // [token]submissions
+
+	sync.Mutex
+	summaries map[string]struct{} // Tokens of the requested summaries
+}
+
+// respond signs the challenge of a request and writes the reply.
+func (p *testPoliteiad) respond(w http.ResponseWriter, challenge string, reply func(response string) interface{}) {
+	c, err := hex.DecodeString(challenge)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	sig := p.id.SignMessage(c)
+	json.NewEncoder(w).Encode(reply(hex.EncodeToString(sig[:])))
+}
+
+func (p *testPoliteiad) handleRecords(w http.ResponseWriter, r *http.Request) {
+	var rs pdv2.Records
+	err := json.NewDecoder(r.Body).Decode(&rs)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	records := make(map[string]pdv2.Record, len(rs.Requests))
+	for _, v := range rs.Requests {
+		if r, ok := p.records[v.Token]; ok {
+			records[v.Token] = r
+		}
+	}
+	p.respond(w, rs.Challenge, func(response string) interface{} {
+		return pdv2.RecordsReply{
+			Response: response,
+			Records:  records,
+		}
+	})
+}
+
+func (p *testPoliteiad) handlePluginReads(w http.ResponseWriter, r *http.Request) {
+	var pr pdv2.PluginReads
+	err := json.NewDecoder(r.Body).Decode(&pr)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	replies := make([]pdv2.PluginCmdReply, 0, len(pr.Cmds))
+	for _, v := range pr.Cmds {
+		var reply interface{}
+		switch v.Command {
+		case tkplugin.CmdSummary:
+			p.Lock()
+			p.summaries[v.Token] = struct{}{}
+			p.Unlock()
+			reply = tkplugin.SummaryReply{
+				Status: tkplugin.VoteStatusUnauthorized,
+			}
+		case tkplugin.CmdSubmissions:
+			reply = tkplugin.SubmissionsReply{
+				Submissions: p.submissions[v.Token],
+			}
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		b, err := json.Marshal(reply)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		replies = append(replies, pdv2.PluginCmdReply{
+			Token:   v.Token,
+			ID:      v.ID,
+			Command: v.Command,
+			Payload: string(b),
+		})
+	}
+	p.respond(w, pr.Challenge, func(response string) interface{} {
+		return pdv2.PluginReadsReply{
+			Response: response,
+			Replies:  replies,
+		}
+	})
+}
+
+// testProposal returns a proposal record with the provided state, status and
+// vote metadata.
+func testProposal(t *testing.T, token string, state pdv2.RecordStateT, status pdv2.RecordStatusT, vm v1.VoteMetadata) pdv2.Record {
+	t.Helper()
+
+	pm, err := json.Marshal(v1.ProposalMetadata{
+		Name: "Proposal " + token,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(vm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pdv2.Record{
+		State:  state,
+		Status: status,
+		Files: []pdv2.File{
+			{
+				Name:    v1.FileNameProposalMetadata,
+				Payload: base64.StdEncoding.EncodeToString(pm),
+			},
+			{
+				Name:    v1.FileNameVoteMetadata,
+				Payload: base64.StdEncoding.EncodeToString(b),
+			},
+		},
+		CensorshipRecord: pdv2.CensorshipRecord{
+			Token: token,
+		},
+	}
+}
+
+// newTestRFP returns a Pi context that is backed by a fake politeiad that
+// contains an RFP and its submissions.
+func newTestRFP(t *testing.T) (*Pi, *testPoliteiad, func()) {
+	t.Helper()
+
+	id, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		vetted   = pdv2.RecordStateVetted
+		public   = pdv2.RecordStatusPublic
+		linkToNo = v1.VoteMetadata{}
+	)
+	tp := &testPoliteiad{
+		id: id,
+		records: map[string]pdv2.Record{
+			testRFP: testProposal(t, testRFP, vetted, public,
+				v1.VoteMetadata{LinkBy: 1}),
+			testNotRFP: testProposal(t, testNotRFP, vetted, public,
+				linkToNo),
+			testUnvetted: testProposal(t, testUnvetted,
+				pdv2.RecordStateUnvetted, pdv2.RecordStatusUnreviewed,
+				v1.VoteMetadata{LinkBy: 1}),
+			testCensored: testProposal(t, testCensored, vetted,
+				pdv2.RecordStatusCensored, v1.VoteMetadata{LinkBy: 1}),
+			testListed: testProposal(t, testListed, vetted, public,
+				v1.VoteMetadata{LinkTo: testRFP}),
+			testUnlisted: testProposal(t, testUnlisted, vetted, public,
+				v1.VoteMetadata{LinkTo: testRFP}),
+			testLinkNotRFP: testProposal(t, testLinkNotRFP, vetted,
+				public, v1.VoteMetadata{LinkTo: testNotRFP}),
+			testLinkMissing: testProposal(t, testLinkMissing, vetted,
+				public, v1.VoteMetadata{LinkTo: testMissing}),
+			testLinkUnvetted: testProposal(t, testLinkUnvetted, vetted,
+				public, v1.VoteMetadata{LinkTo: testUnvetted}),
+			testLinkCensored: testProposal(t, testLinkCensored, vetted,
+				public, v1.VoteMetadata{LinkTo: testCensored}),
+		},
+		submissions: map[string][]string{
+			testRFP: {testListed, testMissing},
+		},
+		summaries: make(map[string]struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(pdv2.APIRoute+pdv2.RouteRecords, tp.handleRecords)
+	mux.HandleFunc(pdv2.APIRoute+pdv2.RoutePluginReads,
+		tp.handlePluginReads)
+	srv := httptest.NewServer(mux)
+
+	pdc, err := pdclient.New(srv.URL, "", "", "", &id.Public)
+	if err != nil {
+		srv.Close()
+		t.Fatal(err)
+	}
+
+	return &Pi{politeiad: pdc}, tp, srv.Close
+}
+
+func TestProcessRFP(t *testing.T) {
+	p, _, cleanup := newTestRFP(t)
+	defer cleanup()
+
+	// A proposal that is not an RFP
+	_, err := p.processRFP(context.Background(), v1.RFP{Token: testNotRFP})
+	var ue v1.UserErrorReply
+	if !errors.As(err, &ue) || ue.ErrorCode != v1.ErrorCodeNotRFP {
+		t.Fatalf("got error %v, want %v", err, v1.ErrorCodeNotRFP)
+	}
+
+	// An RFP whose submissions list contains a missing record
+	rr, err := p.processRFP(context.Background(), v1.RFP{Token: testRFP})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rr.RFP.Token != testRFP || rr.RFP.LinkBy != 1 {
+		t.Fatalf("got rfp %+v", rr.RFP)
+	}
+	if len(rr.Submissions) != 2 {
+		t.Fatalf("got %v submissions, want 2", len(rr.Submissions))
+	}
+	for _, v := range rr.Submissions {
+		switch v.Token {
+		case testListed:
+			if len(v.LinkErrors) != 0 || v.Name == "" {
+				t.Fatalf("got submission %+v", v)
+			}
+		case testMissing:
+			if len(v.LinkErrors) != 1 ||
+				v.LinkErrors[0] != linkErrSubmissionNotFound {
+				t.Fatalf("got link errors %v", v.LinkErrors)
+			}
+		default:
+			t.Fatalf("unexpected submission %v", v.Token)
+		}
+	}
+}
+
+func TestProcessRFPParent(t *testing.T) {
+	p, tp, cleanup := newTestRFP(t)
+	defer cleanup()
+
+	var tests = []struct {
+		name      string
+		linkTo    string
+		token     string // Submission token
+		linkErr   string // Empty when the link is valid
+		rfpDetail bool   // RFP contents and vote summary are returned
+	}{
+		{"valid", testRFP, testListed, "", true},
+		{"not listed", testRFP, testUnlisted, linkErrSubmissionNotListed,
+			true},
+		{"not an rfp", testNotRFP, testLinkNotRFP, linkErrNotRFP, true},
+		{"rfp not found", testMissing, testLinkMissing, linkErrRFPNotFound,
+			false},
+		{"rfp unvetted", testUnvetted, testLinkUnvetted,
+			linkErrRFPNotFound, false},
+		{"rfp censored", testCensored, testLinkCensored,
+			linkErrRFPNotPublic, false},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			tp.Lock()
+			tp.summaries = make(map[string]struct{})
+			tp.Unlock()
+
+			rpr, err := p.processRFPParent(context.Background(),
+				v1.RFPParent{Token: v.token})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Verify the link errors
+			switch {
+			case v.linkErr == "" && len(rpr.Submission.LinkErrors) != 0:
+				t.Fatalf("got link errors %v, want none",
+					rpr.Submission.LinkErrors)
+			case v.linkErr != "" && (len(rpr.Submission.LinkErrors) != 1 ||
+				rpr.Submission.LinkErrors[0] != v.linkErr):
+				t.Fatalf("got link errors %v, want %v",
+					rpr.Submission.LinkErrors, v.linkErr)
+			}
+
+			// The contents and the vote summary of an RFP that is
+			// not public must not be returned.
+			tp.Lock()
+			_, summary := tp.summaries[v.linkTo]
+			tp.Unlock()
+			if summary != v.rfpDetail {
+				t.Fatalf("got rfp summary requested %v, want %v",
+					summary, v.rfpDetail)
+			}
+			rfp := rpr.RFP
+			if rfp.Token != v.linkTo {
+				t.Fatalf("got rfp token %v, want %v", rfp.Token, v.linkTo)
+			}
+			if !v.rfpDetail && (rfp.Name != "" || rfp.Status != 0 ||
+				rfp.Vote.Status != 0) {
+				t.Fatalf("got rfp %+v, want only the token", rfp)
+			}
+		})
+	}
+
+	// The submission must be public
+	_, err := p.processRFPParent(context.Background(),
+		v1.RFPParent{Token: testUnvetted})
+	var ue v1.UserErrorReply
+	if !errors.As(err, &ue) || ue.ErrorCode != v1.ErrorCodeRecordNotFound {
+		t.Fatalf("got error %v, want %v", err, v1.ErrorCodeRecordNotFound)
+	}
+}
+
+func TestSubmissionLinkErrors(t *testing.T) {
+	var (
+		public   = uint32(rcv1.RecordStatusPublic)
+		censored = uint32(rcv1.RecordStatusCensored)
+		archived = uint32(rcv1.RecordStatusArchived)
+	)
+	var tests = []struct {
+		name   string
+		s      v1.RFPProposal
+		listed bool
+		want   string // Empty when the link is valid
+	}{
+		{
+			"valid",
+			v1.RFPProposal{Status: public, LinkTo: testRFP},
+			true,
+			"",
+		},
+		{
+			"archived",
+			v1.RFPProposal{Status: archived, LinkTo: testRFP},
+			true,
+			"",
+		},
+		{
+			"not public",
+			v1.RFPProposal{Status: censored, LinkTo: testRFP},
+			true,
+			linkErrSubmissionNotPublic,
+		},
+		{
+			"not linked",
+			v1.RFPProposal{Status: public, LinkTo: testNotRFP},
+			true,
+			linkErrSubmissionNotLinked,
+		},
+		{
+			"not listed",
+			v1.RFPProposal{Status: public, LinkTo: testRFP},
+			false,
+			linkErrSubmissionNotListed,
+		},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			errs := submissionLinkErrors(testRFP, v.s, v.listed)
+			switch {
+			case v.want == "" && len(errs) != 0:
+				t.Fatalf("got %v, want no errors", errs)
+			case v.want != "" && (len(errs) != 1 || errs[0] != v.want):
+				t.Fatalf("got %v, want %v", errs, v.want)
+			}
+		})
+	}
+}
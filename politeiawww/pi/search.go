@@ -0,0 +1,553 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pi
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	v1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+)
+
+const (
+	// searchNameWeight is the weight of a keyword that is found in a
+	// proposal name relative to a keyword that is found in the proposal
+	// index file or in a comment.
+	searchNameWeight = 3
+)
+
+// searchDoc is a single proposal or comment in the search index.
+type searchDoc struct {
+	typ       v1.SearchTypeT
+	token     string
+	commentID uint32
+	name      string // Proposal name
+	userID    string
+	username  string
+	status    rcv1.RecordStatusT // Proposal status
+	timestamp int64
+	text      string             // Used to create snippets
+	terms     map[string]float64 // [term]weighted term frequency
+}
+
+// searchIndex is an in-memory inverted index of the public proposals and
+// their comments. The index is built on startup and is kept current using the
+// record and comment events.
+type searchIndex struct {
+	sync.RWMutex
+	docs     map[string]*searchDoc          // [docID]doc
+	postings map[string]map[string]struct{} // [term][docID]
+	tokens   map[string]map[string]struct{} // [token][docID]
+}
+
+// newSearchIndex returns a new searchIndex.
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		docs:     make(map[string]*searchDoc, 1024),
+		postings: make(map[string]map[string]struct{}, 1024),
+		tokens:   make(map[string]map[string]struct{}, 1024),
+	}
+}
+
+// searchDocID returns the index ID of a proposal or, when a comment ID is
+// provided, of a proposal comment.
+func searchDocID(token string, commentID uint32) string {
+	if commentID == 0 {
+		return token
+	}
+	return token + ":" + strconv.FormatUint(uint64(commentID), 10)
+}
+
+// searchTerms adds the words of the provided text to the provided term
+// frequencies using the provided weight.
+func searchTerms(terms map[string]float64, text string, weight float64) {
+	for _, w := range words(text) {
+		terms[w] += weight
+	}
+}
+
+// has returns whether the index contains the provided document.
+func (s *searchIndex) has(docID string) bool {
+	s.RLock()
+	defer s.RUnlock()
+
+	_, ok := s.docs[docID]
+	return ok
+}
+
+// put adds a document to the index. An existing document with the same ID is
+// replaced.
+func (s *searchIndex) put(d searchDoc) {
+	s.Lock()
+	defer s.Unlock()
+
+	id := searchDocID(d.token, d.commentID)
+	s.delLocked(id)
+
+	s.docs[id] = &d
+	for t := range d.terms {
+		ids, ok := s.postings[t]
+		if !ok {
+			ids = make(map[string]struct{}, 16)
+			s.postings[t] = ids
+		}
+		ids[id] = struct{}{}
+	}
+	ids, ok := s.tokens[d.token]
+	if !ok {
+		ids = make(map[string]struct{}, 16)
+		s.tokens[d.token] = ids
+	}
+	ids[id] = struct{}{}
+}
+
+// delLocked removes a document from the index.
+//
+// This function must be called WITH the lock held.
+func (s *searchIndex) delLocked(docID string) {
+	d, ok := s.docs[docID]
+	if !ok {
+		return
+	}
+	for t := range d.terms {
+		delete(s.postings[t], docID)
+		if len(s.postings[t]) == 0 {
+			delete(s.postings, t)
+		}
+	}
+	delete(s.tokens[d.token], docID)
+	if len(s.tokens[d.token]) == 0 {
+		delete(s.tokens, d.token)
+	}
+	delete(s.docs, docID)
+}
+
+// del removes a document from the index.
+func (s *searchIndex) del(docID string) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.delLocked(docID)
+}
+
+// delProposal removes a proposal and all of its comments from the index.
+func (s *searchIndex) delProposal(token string) {
+	s.Lock()
+	defer s.Unlock()
+
+	for id := range s.tokens[token] {
+		s.delLocked(id)
+	}
+}
+
+// setProposal updates the name and status of a proposal on all of its
+// comments.
+func (s *searchIndex) setProposal(token, name string, status rcv1.RecordStatusT) {
+	s.Lock()
+	defer s.Unlock()
+
+	for id := range s.tokens[token] {
+		d := s.docs[id]
+		d.name = name
+		d.status = status
+	}
+}
+
+// proposal returns the name and status of an indexed proposal.
+func (s *searchIndex) proposal(token string) (string, rcv1.RecordStatusT, bool) {
+	s.RLock()
+	defer s.RUnlock()
+
+	d, ok := s.docs[token]
+	if !ok {
+		return "", 0, false
+	}
+	return d.name, d.status, true
+}
+
+// searchMatch returns whether a document matches the search filters.
+func searchMatch(d *searchDoc, sr v1.Search) bool {
+	switch {
+	case sr.Type != v1.SearchTypeAny && sr.Type != d.typ:
+		return false
+	case sr.Author != "" && !strings.EqualFold(sr.Author, d.username):
+		return false
+	case sr.Status != 0 && rcv1.RecordStatusT(sr.Status) != d.status:
+		return false
+	case sr.After != 0 && d.timestamp < sr.After:
+		return false
+	case sr.Before != 0 && d.timestamp > sr.Before:
+		return false
+	}
+	return true
+}
+
+// search returns the page of results that match the provided search and the
+// total number of results. Only documents that contain all of the query
+// keywords are returned. The results are ranked using the keyword frequencies
+// weighted by the inverse document frequency of each keyword.
+func (s *searchIndex) search(sr v1.Search) ([]v1.SearchResult, uint32) {
+	s.RLock()
+	defer s.RUnlock()
+
+	// Dedup the query keywords
+	var (
+		query = words(sr.Query)
+		terms = make([]string, 0, len(query))
+		seen  = make(map[string]struct{}, len(query))
+	)
+	for _, v := range query {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		terms = append(terms, v)
+	}
+
+	// Score the documents
+	type match struct {
+		doc   *searchDoc
+		score float64
+	}
+	var (
+		n       = float64(len(s.docs))
+		matches = make([]match, 0, v1.SearchPageSize)
+	)
+	if len(terms) == 0 {
+		for _, d := range s.docs {
+			if searchMatch(d, sr) {
+				matches = append(matches, match{doc: d})
+			}
+		}
+	} else {
+		// Start from the keyword with the fewest documents
+		sort.Slice(terms, func(i, j int) bool {
+			return len(s.postings[terms[i]]) < len(s.postings[terms[j]])
+		})
+		for id := range s.postings[terms[0]] {
+			d := s.docs[id]
+			if !searchMatch(d, sr) {
+				continue
+			}
+			var score float64
+			for _, t := range terms {
+				tf, ok := d.terms[t]
+				if !ok {
+					score = 0
+					break
+				}
+				idf := math.Log(1 + n/float64(len(s.postings[t])))
+				score += idf * tf / (tf + 1)
+			}
+			if score == 0 {
+				continue
+			}
+			matches = append(matches, match{doc: d, score: score})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		switch {
+		case a.score != b.score:
+			return a.score > b.score
+		case a.doc.timestamp != b.doc.timestamp:
+			return a.doc.timestamp > b.doc.timestamp
+		case a.doc.token != b.doc.token:
+			return a.doc.token < b.doc.token
+		}
+		return a.doc.commentID < b.doc.commentID
+	})
+
+	// Return the requested page
+	var (
+		total = uint32(len(matches))
+		page  = sr.Page
+	)
+	if page == 0 {
+		page = 1
+	}
+	start := uint64(page-1) * uint64(v1.SearchPageSize)
+	end := start + uint64(v1.SearchPageSize)
+	switch {
+	case start > uint64(total):
+		start, end = uint64(total), uint64(total)
+	case end > uint64(total):
+		end = uint64(total)
+	}
+	results := make([]v1.SearchResult, 0, end-start)
+	for _, m := range matches[start:end] {
+		results = append(results, searchResult(m.doc, m.score, terms))
+	}
+
+	return results, total
+}
+
+// searchResult returns the search result of a document.
+func searchResult(d *searchDoc, score float64, terms []string) v1.SearchResult {
+	return v1.SearchResult{
+		Type:      d.typ,
+		Token:     d.token,
+		CommentID: d.commentID,
+		Name:      d.name,
+		UserID:    d.userID,
+		Username:  d.username,
+		Status:    uint32(d.status),
+		Timestamp: d.timestamp,
+		Score:     score,
+		Snippet:   snippet(d.text, terms),
+	}
+}
+
+// snippet returns an excerpt of the provided text that starts shortly before
+// the first occurrence of any of the provided terms. The start of the text is
+// returned if none of the terms are found.
+func snippet(text string, terms []string) string {
+	var (
+		r     = []rune(text)
+		lower = strings.ToLower(text)
+		start = -1
+	)
+	if utf8.RuneCountInString(lower) != len(r) {
+		// Lower casing changed the number of runes. Fall back to the
+		// start of the text.
+		lower = ""
+	}
+	for _, t := range terms {
+		i := strings.Index(lower, t)
+		if i < 0 {
+			continue
+		}
+		i = utf8.RuneCountInString(lower[:i])
+		if start == -1 || i < start {
+			start = i
+		}
+	}
+
+	// Include some of the preceding text for context
+	switch {
+	case start == -1:
+		start = 0
+	case start < v1.SearchSnippetLengthMax/4:
+		start = 0
+	default:
+		start -= v1.SearchSnippetLengthMax / 4
+	}
+	end := start + v1.SearchSnippetLengthMax
+	if end > len(r) {
+		end = len(r)
+	}
+
+	return strings.Join(strings.Fields(string(r[start:end])), " ")
+}
+
+// searchProposalDoc returns the search document of a proposal record.
+func searchProposalDoc(r rcv1.Record, username string) searchDoc {
+	var (
+		name  = proposalNameFromFiles(r.Files)
+		index = proposalIndexFromFiles(r.Files)
+		terms = make(map[string]float64, 256)
+	)
+	searchTerms(terms, name, searchNameWeight)
+	searchTerms(terms, index, 1)
+
+	return searchDoc{
+		typ:       v1.SearchTypeProposal,
+		token:     r.CensorshipRecord.Token,
+		name:      name,
+		userID:    userIDFromMetadata(r.Metadata),
+		username:  username,
+		status:    r.Status,
+		timestamp: r.Timestamp,
+		text:      index,
+		terms:     terms,
+	}
+}
+
+// searchCommentDoc returns the search document of a proposal comment.
+func searchCommentDoc(c cmv1.Comment, name string, status rcv1.RecordStatusT) searchDoc {
+	terms := make(map[string]float64, 64)
+	searchTerms(terms, c.Comment, 1)
+
+	return searchDoc{
+		typ:       v1.SearchTypeComment,
+		token:     c.Token,
+		commentID: c.CommentID,
+		name:      name,
+		userID:    c.UserID,
+		username:  c.Username,
+		status:    status,
+		timestamp: c.Timestamp,
+		text:      c.Comment,
+		terms:     terms,
+	}
+}
+
+// searchable returns whether a record is included in the search index. Only
+// public and archived proposals are searchable.
+func searchable(r rcv1.Record) bool {
+	if r.State != rcv1.RecordStateVetted {
+		return false
+	}
+	switch r.Status {
+	case rcv1.RecordStatusPublic, rcv1.RecordStatusArchived:
+		return true
+	}
+	return false
+}
+
+// searchUpdateRecord updates a proposal in the search index. Proposals that
+// are no longer searchable are removed from the index along with their
+// comments.
+func (p *Pi) searchUpdateRecord(r rcv1.Record) {
+	token := r.CensorshipRecord.Token
+	if !searchable(r) {
+		p.search.delProposal(token)
+		return
+	}
+	uid := userIDFromMetadata(r.Metadata)
+	d := searchProposalDoc(r, p.username(uid))
+	p.search.put(d)
+	p.search.setProposal(token, d.name, d.status)
+}
+
+// searchAddComment adds a new comment to the search index. Comments are only
+// added when the proposal that they were made on is searchable.
+func (p *Pi) searchAddComment(c cmv1.Comment) {
+	name, status, ok := p.search.proposal(c.Token)
+	if !ok || c.Deleted {
+		return
+	}
+	p.search.put(searchCommentDoc(c, name, status))
+}
+
+// searchDelComment removes a deleted comment from the search index.
+func (p *Pi) searchDelComment(c cmv1.Comment) {
+	p.search.del(searchDocID(c.Token, c.CommentID))
+}
+
+// searchIndexBuild adds all public proposals and their comments to the search
+// index. This is done in the background on startup. Proposals and comments
+// that are submitted while the index is being built are added using the
+// record and comment events.
+func (p *Pi) searchIndexBuild() {
+	log.Infof("Building search index")
+
+	var (
+		ctx       = context.Background()
+		usernames = make(map[string]string, 1024)
+		count     int
+	)
+	for page := uint32(1); ; page++ {
+		tokens, err := p.politeiad.InventoryOrdered(ctx,
+			pdv2.RecordStateVetted, page)
+		if err != nil {
+			log.Errorf("searchIndexBuild: InventoryOrdered: %v", err)
+			return
+		}
+		if len(tokens) == 0 {
+			break
+		}
+		records, err := p.feedRecords(ctx, tokens)
+		if err != nil {
+			log.Errorf("searchIndexBuild: feedRecords: %v", err)
+			return
+		}
+		for _, t := range tokens {
+			r, ok := records[t]
+			if !ok {
+				continue
+			}
+			rv1 := convertRecordToV1(r)
+			token := rv1.CensorshipRecord.Token
+
+			// Don't overwrite entries that were added by a record
+			// event while the index was being built.
+			if !searchable(rv1) || p.search.has(token) {
+				continue
+			}
+			uid := userIDFromMetadata(rv1.Metadata)
+			username, ok := usernames[uid]
+			if !ok {
+				username = p.username(uid)
+				usernames[uid] = username
+			}
+			d := searchProposalDoc(rv1, username)
+			p.search.put(d)
+			count++
+
+			// Add the proposal comments
+			comments, err := p.politeiad.CommentsGetAll(ctx, token)
+			if err != nil {
+				log.Errorf("searchIndexBuild: CommentsGetAll %v: %v",
+					token, err)
+				continue
+			}
+			for _, c := range comments {
+				if c.Deleted || p.search.has(searchDocID(token, c.CommentID)) {
+					continue
+				}
+				username, ok := usernames[c.UserID]
+				if !ok {
+					username = p.username(c.UserID)
+					usernames[c.UserID] = username
+				}
+				p.search.put(searchCommentDoc(cmv1.Comment{
+					UserID:    c.UserID,
+					Username:  username,
+					Token:     token,
+					CommentID: c.CommentID,
+					Comment:   c.Comment,
+					Timestamp: c.Timestamp,
+				}, d.name, d.status))
+				count++
+			}
+		}
+	}
+
+	log.Infof("Search index built: %v proposals and comments", count)
+}
+
+func (p *Pi) processSearch(s v1.Search) (*v1.SearchReply, error) {
+	log.Tracef("processSearch: %v %v", s.Query, s.Author)
+
+	// Verify the search
+	switch {
+	case strings.TrimSpace(s.Query) == "" && s.Author == "":
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeInputInvalid,
+			ErrorContext: "query or author required",
+		}
+	case len(s.Query) > v1.SearchQueryLengthMax:
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeInputInvalid,
+			ErrorContext: "query exceeds max length",
+		}
+	case s.Type >= v1.SearchTypeLast:
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeInputInvalid,
+			ErrorContext: "invalid type",
+		}
+	case s.After != 0 && s.Before != 0 && s.After > s.Before:
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeInputInvalid,
+			ErrorContext: "after is later than before",
+		}
+	}
+
+	results, total := p.search.search(s)
+	return &v1.SearchReply{
+		Results: results,
+		Total:   total,
+	}, nil
+}
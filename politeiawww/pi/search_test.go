@@ -0,0 +1,143 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pi
+
+import (
+	"testing"
+
+	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	v1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+)
+
+func TestSearch(t *testing.T) {
+	s := newSearchIndex()
+
+	// Setup the index
+	terms := make(map[string]float64)
+	searchTerms(terms, "Marketing budget", searchNameWeight)
+	searchTerms(terms, "A marketing campaign for the next year.", 1)
+	s.put(searchDoc{
+		typ:       v1.SearchTypeProposal,
+		token:     "aaaa",
+		name:      "Marketing budget",
+		username:  "alice",
+		status:    rcv1.RecordStatusPublic,
+		timestamp: 100,
+		text:      "A marketing campaign for the next year.",
+		terms:     terms,
+	})
+	terms = make(map[string]float64)
+	searchTerms(terms, "Development", searchNameWeight)
+	searchTerms(terms, "Development work. Some marketing is included.", 1)
+	s.put(searchDoc{
+		typ:       v1.SearchTypeProposal,
+		token:     "bbbb",
+		name:      "Development",
+		username:  "bob",
+		status:    rcv1.RecordStatusArchived,
+		timestamp: 200,
+		text:      "Development work. Some marketing is included.",
+		terms:     terms,
+	})
+	s.put(searchCommentDoc(cmv1.Comment{
+		Username:  "bob",
+		Token:     "aaaa",
+		CommentID: 1,
+		Comment:   "The marketing budget is too high.",
+		Timestamp: 300,
+	}, "Marketing budget", rcv1.RecordStatusPublic))
+
+	var tests = []struct {
+		name   string
+		search v1.Search
+		want   []string // Snippets
+	}{
+		{
+			"ranked",
+			v1.Search{Query: "marketing"},
+			[]string{
+				"A marketing campaign for the next year.",
+				"The marketing budget is too high.",
+				"Development work. Some marketing is included.",
+			},
+		},
+		{
+			"all keywords",
+			v1.Search{Query: "Marketing BUDGET"},
+			[]string{
+				"A marketing campaign for the next year.",
+				"The marketing budget is too high.",
+			},
+		},
+		{
+			"author",
+			v1.Search{Author: "Bob"},
+			[]string{
+				"The marketing budget is too high.",
+				"Development work. Some marketing is included.",
+			},
+		},
+		{
+			"type",
+			v1.Search{Query: "marketing", Type: v1.SearchTypeComment},
+			[]string{"The marketing budget is too high."},
+		},
+		{
+			"status",
+			v1.Search{
+				Query:  "marketing",
+				Status: uint32(rcv1.RecordStatusArchived),
+			},
+			[]string{"Development work. Some marketing is included."},
+		},
+		{
+			"date range",
+			v1.Search{Query: "marketing", After: 150, Before: 250},
+			[]string{"Development work. Some marketing is included."},
+		},
+		{
+			"page out of range",
+			v1.Search{Query: "marketing", Page: 2},
+			[]string{},
+		},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			results, _ := s.search(v.search)
+			if len(results) != len(v.want) {
+				t.Fatalf("got %v results, want %v", len(results), len(v.want))
+			}
+			for i, r := range results {
+				if r.Snippet != v.want[i] {
+					t.Fatalf("result %v: got %v, want %v",
+						i, r.Snippet, v.want[i])
+				}
+			}
+		})
+	}
+
+	// Verify that a deleted comment is no longer returned
+	s.del(searchDocID("aaaa", 1))
+	results, _ := s.search(v1.Search{Query: "budget"})
+	if len(results) != 1 || results[0].CommentID != 0 {
+		t.Fatalf("got %v results after comment delete, want the "+
+			"proposal only", len(results))
+	}
+
+	// Verify that removing a proposal removes its comments
+	s.put(searchCommentDoc(cmv1.Comment{
+		Username:  "bob",
+		Token:     "aaaa",
+		CommentID: 2,
+		Comment:   "The budget is fine.",
+		Timestamp: 400,
+	}, "Marketing budget", rcv1.RecordStatusPublic))
+	s.delProposal("aaaa")
+	results, total := s.search(v1.Search{Query: "budget"})
+	if len(results) != 0 || total != 0 {
+		t.Fatalf("got %v results after delete, want 0", total)
+	}
+}
@@ -0,0 +1,378 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pi
+
+import (
+	"context"
+	"encoding/base64"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	piplugin "github.com/decred/politeia/politeiad/plugins/pi"
+	v1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+)
+
+const (
+	// shingleSize is the number of consecutive words that make up a
+	// single index file shingle.
+	shingleSize = 3
+
+	// similarProposalsMax is the maximum number of similar proposals
+	// that are returned for any single request.
+	similarProposalsMax = 10
+)
+
+// shingleSet contains the hashed shingles of a proposal.
+type shingleSet map[uint64]struct{}
+
+// similarityEntry contains the similarity data of a single proposal. Public
+// is set when the proposal is a vetted public record. Only public proposals
+// are returned to users that are not admins.
+type similarityEntry struct {
+	name     string
+	shingles shingleSet
+	public   bool
+}
+
+// similarityIndex is an in-memory index of the shingles of all proposals. It
+// is used to detect proposals that are likely duplicates of each other.
+type similarityIndex struct {
+	sync.RWMutex
+	entries map[string]similarityEntry // [token]entry
+}
+
+// newSimilarityIndex returns a new similarityIndex.
+func newSimilarityIndex() *similarityIndex {
+	return &similarityIndex{
+		entries: make(map[string]similarityEntry, 1024),
+	}
+}
+
+// put adds a proposal to the index. An existing entry for the proposal is
+// overwritten.
+func (s *similarityIndex) put(token, name string, shingles shingleSet, public bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.entries[token] = similarityEntry{
+		name:     name,
+		shingles: shingles,
+		public:   public,
+	}
+}
+
+// setPublic updates whether an indexed proposal is a vetted public record.
+func (s *similarityIndex) setPublic(token string, public bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	e, ok := s.entries[token]
+	if !ok {
+		return
+	}
+	e.public = public
+	s.entries[token] = e
+}
+
+// get returns the index entry for a proposal.
+func (s *similarityIndex) get(token string) (*similarityEntry, bool) {
+	s.RLock()
+	defer s.RUnlock()
+
+	e, ok := s.entries[token]
+	if !ok {
+		return nil, false
+	}
+	return &e, true
+}
+
+// similar returns the proposals that have a similarity score that meets the
+// provided threshold, sorted from the highest score to the lowest. The
+// proposal with the provided token is not included in the results. Only
+// vetted public proposals are returned when publicOnly is set.
+func (s *similarityIndex) similar(token string, shingles shingleSet, threshold float64, publicOnly bool) []v1.SimilarProposal {
+	s.RLock()
+	defer s.RUnlock()
+
+	sp := make([]v1.SimilarProposal, 0, similarProposalsMax)
+	for t, e := range s.entries {
+		if t == token || (publicOnly && !e.public) {
+			continue
+		}
+		score := jaccard(shingles, e.shingles)
+		if score < threshold {
+			continue
+		}
+		sp = append(sp, v1.SimilarProposal{
+			Token: t,
+			Name:  e.name,
+			Score: score,
+		})
+	}
+
+	sort.Slice(sp, func(i, j int) bool {
+		if sp[i].Score == sp[j].Score {
+			return sp[i].Token < sp[j].Token
+		}
+		return sp[i].Score > sp[j].Score
+	})
+	if len(sp) > similarProposalsMax {
+		sp = sp[:similarProposalsMax]
+	}
+
+	return sp
+}
+
+// words returns the lower case words of the provided text. Punctuation and
+// markdown syntax are ignored.
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// hashShingle returns the 64 bit FNV-1a hash of the provided shingle.
+func hashShingle(prefix string, w []string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(prefix))
+	h.Write([]byte(strings.Join(w, " ")))
+	return h.Sum64()
+}
+
+// proposalShingles returns the shingles of a proposal. The name is shingled
+// one word at a time. The index file is shingled using shingleSize
+// consecutive words. The name and index shingles are prefixed differently so
+// that they never collide.
+func proposalShingles(name, index string) shingleSet {
+	var (
+		nw = words(name)
+		iw = words(index)
+		s  = make(shingleSet, len(nw)+len(iw))
+	)
+	for i := range nw {
+		s[hashShingle("name:", nw[i:i+1])] = struct{}{}
+	}
+	if len(iw) > 0 && len(iw) < shingleSize {
+		s[hashShingle("index:", iw)] = struct{}{}
+	}
+	for i := 0; i+shingleSize <= len(iw); i++ {
+		s[hashShingle("index:", iw[i:i+shingleSize])] = struct{}{}
+	}
+	return s
+}
+
+// jaccard returns the jaccard similarity of the provided shingle sets.
+func jaccard(a, b shingleSet) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	var intersection int
+	for k := range a {
+		if _, ok := b[k]; ok {
+			intersection++
+		}
+	}
+	union := len(a) + len(b) - intersection
+	return float64(intersection) / float64(union)
+}
+
+// proposalIndexFromFiles decodes and returns the contents of the proposal
+// index file. An empty string is returned if the index file is not found.
+func proposalIndexFromFiles(files []rcv1.File) string {
+	for _, v := range files {
+		if v.Name != v1.FileNameIndexFile {
+			continue
+		}
+		b, err := base64.StdEncoding.DecodeString(v.Payload)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+	return ""
+}
+
+// isPublic returns whether the record is a vetted public record.
+func isPublic(r rcv1.Record) bool {
+	return r.State == rcv1.RecordStateVetted &&
+		r.Status == rcv1.RecordStatusPublic
+}
+
+// similarityUpdate adds the provided proposal to the similarity index. The
+// existing proposals that are similar to it are returned to admins in the
+// vetting queue.
+func (p *Pi) similarityUpdate(r rcv1.Record) {
+	var (
+		token    = r.CensorshipRecord.Token
+		name     = proposalNameFromFiles(r.Files)
+		shingles = proposalShingles(name, proposalIndexFromFiles(r.Files))
+	)
+	p.similarity.put(token, name, shingles, isPublic(r))
+}
+
+// similarProposals returns the indexed proposals that are similar to the
+// proposal with the provided token. All proposals are considered since the
+// result is only returned to admins.
+func (p *Pi) similarProposals(token string) []v1.SimilarProposal {
+	e, ok := p.similarity.get(token)
+	if !ok {
+		return nil
+	}
+	return p.similarity.similar(token, e.shingles,
+		p.cfg.SimilarityThreshold, false)
+}
+
+// similarityIndexBuild adds all existing proposals to the similarity index.
+// This is done in the background on startup. Proposals that are submitted
+// while the index is being built are added using the record events.
+func (p *Pi) similarityIndexBuild() {
+	log.Infof("Building proposal similarity index")
+
+	var (
+		ctx   = context.Background()
+		count int
+	)
+	for _, state := range []pdv2.RecordStateT{
+		pdv2.RecordStateUnvetted,
+		pdv2.RecordStateVetted,
+	} {
+		for page := uint32(1); ; page++ {
+			tokens, err := p.politeiad.InventoryOrdered(ctx, state, page)
+			if err != nil {
+				log.Errorf("similarityIndexBuild: InventoryOrdered: %v", err)
+				return
+			}
+			if len(tokens) == 0 {
+				break
+			}
+
+			// Fetch the records in batches
+			for len(tokens) > 0 {
+				n := int(pdv2.RecordsPageSize)
+				if len(tokens) < n {
+					n = len(tokens)
+				}
+				reqs := make([]pdv2.RecordRequest, 0, n)
+				for _, t := range tokens[:n] {
+					reqs = append(reqs, pdv2.RecordRequest{
+						Token: t,
+						Filenames: []string{
+							piplugin.FileNameIndexFile,
+							piplugin.FileNameProposalMetadata,
+						},
+					})
+				}
+				tokens = tokens[n:]
+
+				records, err := p.politeiad.Records(ctx, reqs)
+				if err != nil {
+					log.Errorf("similarityIndexBuild: Records: %v", err)
+					return
+				}
+				for _, r := range records {
+					rv1 := convertRecordToV1(r)
+					name := proposalNameFromFiles(rv1.Files)
+					index := proposalIndexFromFiles(rv1.Files)
+					token := r.CensorshipRecord.Token
+					public := isPublic(rv1)
+
+					// Don't overwrite entries that were added by a
+					// record event while the index was being built.
+					if _, ok := p.similarity.get(token); ok {
+						continue
+					}
+					p.similarity.put(token, name,
+						proposalShingles(name, index), public)
+					count++
+				}
+			}
+		}
+	}
+
+	log.Infof("Proposal similarity index built: %v proposals", count)
+}
+
+func (p *Pi) processSimilar(ctx context.Context, s v1.Similar) (*v1.SimilarReply, error) {
+	log.Tracef("processSimilar: %v", s.Token)
+
+	if s.Token == "" {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeTokenInvalid,
+		}
+	}
+
+	// Get the proposal shingles. The proposal may not have been added
+	// to the index yet if the index is still being built.
+	var (
+		token    = s.Token
+		shingles shingleSet
+	)
+	e, ok := p.similarity.get(token)
+	if ok {
+		shingles = e.shingles
+	} else {
+		reqs := []pdv2.RecordRequest{
+			{
+				Token: token,
+				Filenames: []string{
+					piplugin.FileNameIndexFile,
+					piplugin.FileNameProposalMetadata,
+				},
+			},
+		}
+		records, err := p.politeiad.Records(ctx, reqs)
+		if err != nil {
+			return nil, err
+		}
+		r, ok := records[token]
+		if !ok {
+			return nil, v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeRecordNotFound,
+			}
+		}
+		rv1 := convertRecordToV1(r)
+		token = r.CensorshipRecord.Token
+		shingles = proposalShingles(proposalNameFromFiles(rv1.Files),
+			proposalIndexFromFiles(rv1.Files))
+	}
+
+	return &v1.SimilarReply{
+		Proposals: p.similarity.similar(token, shingles,
+			p.cfg.SimilarityThreshold, false),
+	}, nil
+}
+
+func (p *Pi) processPreflight(ctx context.Context, pf v1.Preflight, isAdmin bool) (*v1.PreflightReply, error) {
+	log.Tracef("processPreflight")
+
+	switch {
+	case uint32(len(pf.Name)) > p.policy.NameLengthMax:
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeInputInvalid,
+			ErrorContext: "name exceeds max length",
+		}
+	case uint32(len(pf.Index)) > p.policy.TextFileSizeMax:
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeInputInvalid,
+			ErrorContext: "index exceeds max size",
+		}
+	}
+
+	// Users that are not admins are only matched against vetted public
+	// proposals so that unvetted proposals are not disclosed.
+	shingles := proposalShingles(pf.Name, pf.Index)
+	return &v1.PreflightReply{
+		Proposals: p.similarity.similar("", shingles,
+			p.cfg.SimilarityThreshold, !isAdmin),
+	}, nil
+}
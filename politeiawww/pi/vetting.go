@@ -0,0 +1,380 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	piplugin "github.com/decred/politeia/politeiad/plugins/pi"
+	v1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
+	"github.com/google/uuid"
+)
+
+const (
+	// vettingFilename is the name of the file in the data directory
+	// that the vetting assignments are persisted to.
+	vettingFilename = "vettingassignments.json"
+)
+
+// vettingAssignment is the reviewer that has been assigned to a proposal that
+// is awaiting vetting. This is a JSON structure so that the assignments can be
+// persisted to disk.
+type vettingAssignment struct {
+	Reviewer   string `json:"reviewer"`   // User ID
+	AssignedAt int64  `json:"assignedat"` // UNIX timestamp
+}
+
+// vettingAssignments contains the reviewer assignments of the proposals that
+// are awaiting vetting. The assignments are persisted to disk on every change
+// so that they survive a restart.
+type vettingAssignments struct {
+	sync.Mutex
+	path        string
+	assignments map[string]vettingAssignment // [token]assignment
+}
+
+// newVettingAssignments returns a new vettingAssignments that is loaded from
+// the provided file. The file is created on the first assignment if it does
+// not exist.
+func newVettingAssignments(path string) (*vettingAssignments, error) {
+	va := vettingAssignments{
+		path:        path,
+		assignments: make(map[string]vettingAssignment, 64),
+	}
+	b, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return &va, nil
+	case err != nil:
+		return nil, err
+	}
+	err = json.Unmarshal(b, &va.assignments)
+	if err != nil {
+		return nil, fmt.Errorf("decode %v: %v", path, err)
+	}
+	return &va, nil
+}
+
+// save writes the assignments to disk. The file is replaced atomically.
+//
+// This function must be called WITH the lock held.
+func (va *vettingAssignments) save() error {
+	b, err := json.Marshal(va.assignments)
+	if err != nil {
+		return err
+	}
+	tmp := va.path + ".tmp"
+	err = ioutil.WriteFile(tmp, b, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, va.path)
+}
+
+// get returns the assignment of a proposal.
+func (va *vettingAssignments) get(token string) (vettingAssignment, bool) {
+	va.Lock()
+	defer va.Unlock()
+
+	a, ok := va.assignments[token]
+	return a, ok
+}
+
+// set assigns a reviewer to a proposal. An empty reviewer removes the
+// assignment.
+func (va *vettingAssignments) set(token, reviewer string) error {
+	va.Lock()
+	defer va.Unlock()
+
+	if reviewer == "" {
+		delete(va.assignments, token)
+	} else {
+		va.assignments[token] = vettingAssignment{
+			Reviewer:   reviewer,
+			AssignedAt: time.Now().Unix(),
+		}
+	}
+	return va.save()
+}
+
+// prune removes the assignments of the proposals that are no longer awaiting
+// vetting.
+func (va *vettingAssignments) prune(queue map[string]struct{}) error {
+	va.Lock()
+	defer va.Unlock()
+
+	var pruned bool
+	for token := range va.assignments {
+		if _, ok := queue[token]; !ok {
+			delete(va.assignments, token)
+			pruned = true
+		}
+	}
+	if !pruned {
+		return nil
+	}
+	return va.save()
+}
+
+// HandleVettingQueue is the request handler for the pi v1 VettingQueue route.
+func (p *Pi) HandleVettingQueue(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleVettingQueue")
+
+	var vq v1.VettingQueue
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&vq); err != nil {
+		respondWithError(w, r, "HandleVettingQueue: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	vqr, err := p.processVettingQueue(r.Context(), vq)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleVettingQueue: processVettingQueue: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, vqr)
+}
+
+// HandleVettingAssign is the request handler for the pi v1 VettingAssign
+// route.
+func (p *Pi) HandleVettingAssign(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleVettingAssign")
+
+	var va v1.VettingAssign
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&va); err != nil {
+		respondWithError(w, r, "HandleVettingAssign: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	u, err := p.sessions.GetSessionUser(w, r)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleVettingAssign: GetSessionUser: %v", err)
+		return
+	}
+
+	vr, err := p.processVettingAssign(r.Context(), va, *u)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleVettingAssign: processVettingAssign: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, vr)
+}
+
+func (p *Pi) processVettingQueue(ctx context.Context, vq v1.VettingQueue) (*v1.VettingQueueReply, error) {
+	log.Tracef("processVettingQueue")
+
+	// Get the tokens of all proposals that are awaiting vetting
+	var (
+		status = pdv2.RecordStatuses[pdv2.RecordStatusUnreviewed]
+		tokens = make([]string, 0, pdv2.InventoryPageSize)
+	)
+	for page := uint32(1); ; page++ {
+		ir, err := p.politeiad.Inventory(ctx, pdv2.RecordStateUnvetted,
+			pdv2.RecordStatusUnreviewed, page)
+		if err != nil {
+			return nil, err
+		}
+		t := ir.Unvetted[status]
+		tokens = append(tokens, t...)
+		if uint32(len(t)) < pdv2.InventoryPageSize {
+			break
+		}
+	}
+
+	entries, err := p.vettingEntries(ctx, tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remove the assignments of the proposals that have been vetted
+	queue := make(map[string]struct{}, len(entries))
+	for _, v := range entries {
+		queue[v.Token] = struct{}{}
+	}
+	err = p.vetting.prune(queue)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp < entries[j].Timestamp
+	})
+
+	return &v1.VettingQueueReply{
+		Entries: entries,
+	}, nil
+}
+
+func (p *Pi) processVettingAssign(ctx context.Context, va v1.VettingAssign, u user.User) (*v1.VettingAssignReply, error) {
+	log.Tracef("processVettingAssign: %v %v", va.Token, va.Reviewer)
+
+	// Verify the reviewer is an admin
+	if va.Reviewer != "" {
+		id, err := uuid.Parse(va.Reviewer)
+		if err != nil {
+			return nil, v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeUserNotFound,
+			}
+		}
+		reviewer, err := p.userdb.UserGetById(id)
+		if err != nil {
+			if err == user.ErrUserNotFound {
+				return nil, v1.UserErrorReply{
+					ErrorCode: v1.ErrorCodeUserNotFound,
+				}
+			}
+			return nil, err
+		}
+		if !reviewer.Admin {
+			return nil, v1.UserErrorReply{
+				ErrorCode:    v1.ErrorCodeInputInvalid,
+				ErrorContext: "reviewer is not an admin",
+			}
+		}
+	}
+
+	// Verify the proposal is awaiting vetting. The full token is used
+	// for the assignment in case a token prefix was provided.
+	entries, err := p.vettingEntries(ctx, []string{va.Token})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeRecordStatusInvalid,
+			ErrorContext: "proposal does not exist or is not awaiting " +
+				"vetting",
+		}
+	}
+	token := entries[0].Token
+
+	err = p.vetting.set(token, va.Reviewer)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Vetting reviewer assigned by %v: %v %v",
+		u.Username, token, va.Reviewer)
+
+	entries, err = p.vettingEntries(ctx, []string{token})
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		// The proposal was vetted in the meantime
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeRecordStatusInvalid,
+		}
+	}
+
+	return &v1.VettingAssignReply{
+		Entry: entries[0],
+	}, nil
+}
+
+// vettingEntries returns the vetting queue entries of the provided tokens.
+// Tokens that do not correspond to a proposal that is awaiting vetting are
+// not included.
+func (p *Pi) vettingEntries(ctx context.Context, tokens []string) ([]v1.VettingEntry, error) {
+	var (
+		now       = time.Now().Unix()
+		sla       = p.policy.VettingSLA
+		entries   = make([]v1.VettingEntry, 0, len(tokens))
+		usernames = make(map[string]string, len(tokens)) // [userID]username
+	)
+	username := func(userID string) (string, error) {
+		if u, ok := usernames[userID]; ok {
+			return u, nil
+		}
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			return "", nil
+		}
+		u, err := p.userdb.UserGetById(id)
+		if err != nil {
+			return "", fmt.Errorf("UserGetById %v: %v", userID, err)
+		}
+		usernames[userID] = u.Username
+		return u.Username, nil
+	}
+
+	for i := 0; i < len(tokens); i += int(pdv2.RecordsPageSize) {
+		end := i + int(pdv2.RecordsPageSize)
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+		reqs := make([]pdv2.RecordRequest, 0, end-i)
+		for _, v := range tokens[i:end] {
+			reqs = append(reqs, pdv2.RecordRequest{
+				Token: v,
+				Filenames: []string{
+					piplugin.FileNameProposalMetadata,
+				},
+			})
+		}
+		records, err := p.politeiad.Records(ctx, reqs)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range records {
+			if r.State != pdv2.RecordStateUnvetted ||
+				r.Status != pdv2.RecordStatusUnreviewed {
+				continue
+			}
+			var (
+				rv1   = convertRecordToV1(r)
+				token = r.CensorshipRecord.Token
+				e     = v1.VettingEntry{
+					Token:     token,
+					Name:      proposalNameFromFiles(rv1.Files),
+					UserID:    userIDFromMetadata(rv1.Metadata),
+					Timestamp: r.Timestamp,
+					Age:       now - r.Timestamp,
+				}
+			)
+			e.SLABreached = sla > 0 && e.Age > sla
+			e.Similar = p.similarProposals(token)
+			e.Username, err = username(e.UserID)
+			if err != nil {
+				return nil, err
+			}
+			if a, ok := p.vetting.get(token); ok {
+				e.Reviewer = a.Reviewer
+				e.AssignedAt = a.AssignedAt
+				e.ReviewerUsername, err = username(a.Reviewer)
+				if err != nil {
+					return nil, err
+				}
+			}
+			entries = append(entries, e)
+		}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,274 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	piplugin "github.com/decred/politeia/politeiad/plugins/pi"
+	"github.com/decred/politeia/politeiad/plugins/ticketvote"
+	v1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	"github.com/decred/politeia/politeiawww/consistency"
+	"github.com/decred/politeia/util"
+	"github.com/google/uuid"
+)
+
+// walletCacheEntry is a cached wallet summary.
+type walletCacheEntry struct {
+	summary v1.WalletSummary
+	expiry  time.Time
+	seq     uint64 // Consistency sequence when the entry was populated
+}
+
+// walletCache caches the wallet summaries of proposals. Wallets poll the
+// summaries of all active proposals, so caching them prevents every poll from
+// resulting in multiple politeiad requests per proposal.
+type walletCache struct {
+	sync.Mutex
+	entries map[string]walletCacheEntry // [token]entry
+}
+
+// newWalletCache returns a new walletCache.
+func newWalletCache() *walletCache {
+	return &walletCache{
+		entries: make(map[string]walletCacheEntry, 64),
+	}
+}
+
+// get returns the cached summaries of the provided tokens and the tokens that
+// were not found in the cache. Entries that were populated before the write
+// of the request consistency token are treated as not found.
+func (c *walletCache) get(ctx context.Context, tokens []string) (map[string]v1.WalletSummary, []string) {
+	c.Lock()
+	defer c.Unlock()
+
+	var (
+		now     = time.Now()
+		found   = make(map[string]v1.WalletSummary, len(tokens))
+		missing = make([]string, 0, len(tokens))
+	)
+	for _, v := range tokens {
+		e, ok := c.entries[v]
+		if !ok || now.After(e.expiry) {
+			delete(c.entries, v)
+			missing = append(missing, v)
+			continue
+		}
+		if !consistency.Satisfied(ctx, e.seq) {
+			missing = append(missing, v)
+			continue
+		}
+		found[v] = e.summary
+	}
+	return found, missing
+}
+
+// put adds the provided summaries to the cache. The seq is the consistency
+// sequence prior to the summaries being retrieved.
+func (c *walletCache) put(seq uint64, summaries map[string]v1.WalletSummary) {
+	c.Lock()
+	defer c.Unlock()
+
+	expiry := time.Now().Add(v1.WalletSummariesCacheTTL * time.Second)
+	for k, v := range summaries {
+		c.entries[k] = walletCacheEntry{
+			summary: v,
+			expiry:  expiry,
+			seq:     seq,
+		}
+	}
+}
+
+// HandleWalletSummaries is the request handler for the pi v1 WalletSummaries
+// route.
+func (p *Pi) HandleWalletSummaries(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleWalletSummaries")
+
+	var ws v1.WalletSummaries
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&ws); err != nil {
+		respondWithError(w, r, "HandleWalletSummaries: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	wsr, err := p.processWalletSummaries(r.Context(), ws)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleWalletSummaries: processWalletSummaries: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, wsr)
+}
+
+func (p *Pi) processWalletSummaries(ctx context.Context, ws v1.WalletSummaries) (*v1.WalletSummariesReply, error) {
+	log.Tracef("processWalletSummaries: %v", ws.Tokens)
+
+	// Verify page size
+	if len(ws.Tokens) > int(v1.WalletSummariesPageSize) {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodePageSizeExceeded,
+			ErrorContext: fmt.Sprintf("max page size is %v",
+				v1.WalletSummariesPageSize),
+		}
+	}
+
+	seq := consistency.Seq(ctx)
+	summaries, missing := p.wallet.get(ctx, ws.Tokens)
+	if len(missing) == 0 {
+		return &v1.WalletSummariesReply{
+			Summaries: summaries,
+		}, nil
+	}
+
+	// Get the summaries that were not cached
+	fetched, err := p.walletSummaries(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	p.wallet.put(seq, fetched)
+	for k, v := range fetched {
+		summaries[k] = v
+	}
+
+	return &v1.WalletSummariesReply{
+		Summaries: summaries,
+	}, nil
+}
+
+// walletSummaries retrieves the proposal records, vote summaries, and comment
+// counts of the provided tokens from politeiad and returns the wallet
+// summaries of the public proposals.
+func (p *Pi) walletSummaries(ctx context.Context, tokens []string) (map[string]v1.WalletSummary, error) {
+	// The records are requested in pages since the politeiad records
+	// page size is smaller than the wallet summaries page size.
+	records := make(map[string]pdv2.Record, len(tokens))
+	for i := 0; i < len(tokens); i += int(pdv2.RecordsPageSize) {
+		end := i + int(pdv2.RecordsPageSize)
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+		reqs := make([]pdv2.RecordRequest, 0, end-i)
+		for _, v := range tokens[i:end] {
+			reqs = append(reqs, pdv2.RecordRequest{
+				Token: v,
+				Filenames: []string{
+					piplugin.FileNameIndexFile,
+					piplugin.FileNameProposalMetadata,
+				},
+			})
+		}
+		rs, err := p.politeiad.Records(ctx, reqs)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range rs {
+			records[k] = v
+		}
+	}
+	vs, err := p.politeiad.TicketVoteSummaries(ctx, tokens)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := p.politeiad.CommentCount(ctx, tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make(map[string]v1.WalletSummary, len(records))
+	for token, r := range records {
+		if r.State != pdv2.RecordStateVetted ||
+			(r.Status != pdv2.RecordStatusPublic &&
+				r.Status != pdv2.RecordStatusArchived) {
+			continue
+		}
+		files := convertFilesToV1(r.Files)
+		ws := v1.WalletSummary{
+			Token:        r.CensorshipRecord.Token,
+			Name:         proposalNameFromFiles(files),
+			Timestamp:    r.Timestamp,
+			Description:  walletDescription(r.Files),
+			CommentCount: counts[token],
+			Vote:         convertWalletVoteToV1(vs[token]),
+		}
+		uid := userIDFromMetadata(convertMetadataStreamsToV1(r.Metadata))
+		if id, err := uuid.Parse(uid); err == nil {
+			u, err := p.userdb.UserGetById(id)
+			if err != nil {
+				return nil, fmt.Errorf("UserGetById %v: %v", uid, err)
+			}
+			ws.Username = u.Username
+		}
+		summaries[token] = ws
+	}
+
+	return summaries, nil
+}
+
+// walletDescription returns the start of the proposal index file. Whitespace
+// is collapsed and the description is truncated on a word boundary.
+func walletDescription(files []pdv2.File) string {
+	var index []byte
+	for _, v := range files {
+		if v.Name != piplugin.FileNameIndexFile {
+			continue
+		}
+		b, err := base64.StdEncoding.DecodeString(v.Payload)
+		if err != nil {
+			return ""
+		}
+		index = b
+	}
+	if !utf8.Valid(index) {
+		return ""
+	}
+
+	d := strings.Join(strings.Fields(string(index)), " ")
+	if utf8.RuneCountInString(d) <= v1.WalletDescriptionLengthMax {
+		return d
+	}
+	d = string([]rune(d)[:v1.WalletDescriptionLengthMax])
+	if i := strings.LastIndex(d, " "); i > 0 {
+		d = d[:i]
+	}
+	return d + "..."
+}
+
+func convertWalletVoteToV1(s ticketvote.SummaryReply) v1.WalletVote {
+	results := make([]v1.WalletVoteResult, 0, len(s.Results))
+	for _, v := range s.Results {
+		results = append(results, v1.WalletVoteResult{
+			ID:          v.ID,
+			Description: v.Description,
+			VoteBit:     v.VoteBit,
+			Votes:       v.Votes,
+		})
+	}
+	return v1.WalletVote{
+		Status:           uint32(s.Status),
+		Type:             uint32(s.Type),
+		Duration:         s.Duration,
+		StartBlockHeight: s.StartBlockHeight,
+		StartBlockHash:   s.StartBlockHash,
+		EndBlockHeight:   s.EndBlockHeight,
+		EligibleTickets:  s.EligibleTickets,
+		QuorumPercentage: s.QuorumPercentage,
+		PassPercentage:   s.PassPercentage,
+		Results:          results,
+		BestBlock:        s.BestBlock,
+	}
+}
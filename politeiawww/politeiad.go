@@ -10,10 +10,53 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
+	pdv1 "github.com/decred/politeia/politeiad/api/v1"
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	pdclient "github.com/decred/politeia/politeiad/client"
+	"github.com/decred/politeia/politeiawww/consistency"
+	"github.com/decred/politeia/politeiawww/metrics"
 	"github.com/decred/politeia/util"
 )
 
+var (
+	// pdWriteRoutes contains the politeiad routes that write data. The
+	// politeiad write version of a successful request to any of these
+	// routes is returned as the read-after-write consistency token. The
+	// v1 plugin command route is used for both reads and writes so it
+	// is treated as a write.
+	pdWriteRoutes = map[string]struct{}{
+		pdv1.NewRecordRoute:                          {},
+		pdv1.UpdateUnvettedRoute:                     {},
+		pdv1.UpdateVettedRoute:                       {},
+		pdv1.UpdateVettedMetadataRoute:               {},
+		pdv1.SetUnvettedStatusRoute:                  {},
+		pdv1.SetVettedStatusRoute:                    {},
+		pdv1.PluginCommandRoute:                      {},
+		pdv1.UpdateReadmeRoute:                       {},
+		pdv2.APIRoute + pdv2.RouteRecordNew:          {},
+		pdv2.APIRoute + pdv2.RouteRecordEdit:         {},
+		pdv2.APIRoute + pdv2.RouteRecordEditMetadata: {},
+		pdv2.APIRoute + pdv2.RouteRecordSetStatus:    {},
+		pdv2.APIRoute + pdv2.RoutePluginWrite:        {},
+	}
+)
+
+// newPoliteiadObserver returns the function that is called after every
+// politeiad request. It records the request metrics, when the metrics are
+// enabled, and records the politeiad write version with the consistency
+// tracker.
+func newPoliteiadObserver(m *metrics.Metrics, ct *consistency.Tracker) pdclient.ObserverFunc {
+	return func(ctx context.Context, route string, version uint64, d time.Duration, err error) {
+		if m != nil {
+			m.ObservePoliteiad(route, d, err)
+		}
+		_, write := pdWriteRoutes[route]
+		ct.Observe(ctx, version, write && err == nil)
+	}
+}
+
 // pdErrorReply represents the request body that is returned from politeaid
 // when an error occurs. PluginID will be populated if this is a plugin error.
 type pdErrorReply struct {
@@ -42,6 +85,17 @@ func (e pdError) Error() string {
 // context should be used instead. This method can be removed once all of the
 // cms invocations have been switched over to use the politeaid client.
 func (p *politeiawww) makeRequest(ctx context.Context, method string, route string, v interface{}) ([]byte, error) {
+	start := time.Now()
+	b, version, err := p.doRequest(ctx, method, route, v)
+	if p.observePoliteiad != nil {
+		p.observePoliteiad(ctx, route, version, time.Since(start), err)
+	}
+	return b, err
+}
+
+// doRequest makes a politeiad http request and returns the reply body and the
+// politeiad write version of the reply. See makeRequest for more details.
+func (p *politeiawww) doRequest(ctx context.Context, method string, route string, v interface{}) ([]byte, uint64, error) {
 	var (
 		reqBody []byte
 		err     error
@@ -49,7 +103,7 @@ func (p *politeiawww) makeRequest(ctx context.Context, method string, route stri
 	if v != nil {
 		reqBody, err = json.Marshal(v)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 	}
 
@@ -60,28 +114,30 @@ func (p *politeiawww) makeRequest(ctx context.Context, method string, route stri
 	req, err := http.NewRequestWithContext(ctx, method,
 		fullRoute, bytes.NewReader(reqBody))
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	req.SetBasicAuth(p.cfg.RPCUser, p.cfg.RPCPass)
 	r, err := p.http.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer r.Body.Close()
+	version := pdclient.VersionFromHeader(r.Header)
 
 	if r.StatusCode != http.StatusOK {
 		var e pdErrorReply
 		decoder := json.NewDecoder(r.Body)
 		if err := decoder.Decode(&e); err != nil {
-			return nil, fmt.Errorf("status code %v: %v", r.StatusCode, err)
+			return nil, version, fmt.Errorf("status code %v: %v",
+				r.StatusCode, err)
 		}
 
-		return nil, pdError{
+		return nil, version, pdError{
 			HTTPCode:   r.StatusCode,
 			ErrorReply: e,
 		}
 	}
 
 	responseBody := util.ConvertBodyToByteArray(r.Body, false)
-	return responseBody, nil
+	return responseBody, version, nil
 }
@@ -16,6 +16,7 @@ import (
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/politeia/politeiad/api/v1/identity"
 	"github.com/decred/politeia/politeiad/api/v1/mime"
 	pdclient "github.com/decred/politeia/politeiad/client"
 	www "github.com/decred/politeia/politeiawww/api/www/v1"
@@ -24,10 +25,15 @@ import (
 	"github.com/decred/politeia/politeiawww/config"
 	"github.com/decred/politeia/politeiawww/events"
 	"github.com/decred/politeia/politeiawww/mail"
+	"github.com/decred/politeia/politeiawww/metrics"
+	"github.com/decred/politeia/politeiawww/openapi"
+	"github.com/decred/politeia/politeiawww/passwords"
 	"github.com/decred/politeia/politeiawww/sessions"
 	"github.com/decred/politeia/politeiawww/user"
 	utilwww "github.com/decred/politeia/politeiawww/util"
+	"github.com/decred/politeia/politeiawww/webhook"
 	"github.com/decred/politeia/util"
+	"github.com/decred/politeia/util/tracing"
 	"github.com/decred/politeia/util/version"
 	"github.com/decred/politeia/wsdcrdata"
 	"github.com/google/uuid"
@@ -37,6 +43,10 @@ import (
 	"github.com/robfig/cron"
 )
 
+// wsEventQueueSize is the number of events that are queued for a websocket
+// before new events are dropped.
+const wsEventQueueSize = 64
+
 // wsContext is the websocket context. If uuid == "" then it is an
 // unauthenticated websocket.
 type wsContext struct {
@@ -45,8 +55,10 @@ type wsContext struct {
 	conn          *websocket.Conn
 	wg            sync.WaitGroup
 	subscriptions map[string]struct{}
+	tokens        map[string]struct{} // Event record filter
 	errorC        chan www.WSError
 	pingC         chan struct{}
+	eventC        chan www.WSEvent
 	done          chan struct{} // SHUT...DOWN...EVERYTHING...
 }
 
@@ -73,10 +85,25 @@ type politeiawww struct {
 	politeiad *pdclient.Client
 	http      *http.Client // Deprecated; use politeiad client
 	mail      *mail.Client
+	mailLog   *mail.SendLog // Nil when the send log is disabled
+	mailQueue *mail.Queue
+	webhooks  []*webhook.Client // Used to retry failed deliveries
 	db        user.Database
 	sessions  *sessions.Sessions
 	events    *events.Manager
 
+	// redis is the Redis session store. It is nil when the sessions are
+	// stored in the user database.
+	redis *sessions.Redis
+
+	// csrfSessionKey is the HMAC key used to create and verify CSRF
+	// session tokens.
+	csrfSessionKey []byte
+
+	// unsubscribeKey is the HMAC key used to create and verify the
+	// notification email unsubscribe tokens.
+	unsubscribeKey []byte
+
 	// Client websocket connections
 	ws    map[string]map[string]*wsContext // [uuid][]*context
 	wsMtx sync.RWMutex
@@ -89,6 +116,53 @@ type politeiawww struct {
 	// removed once all user by email lookups have been taken out.
 	userEmails map[string]uuid.UUID // [email]userID
 
+	// resendThrottle limits the rate at which new user verification
+	// emails are sent to an email address.
+	resendThrottle *resendThrottle
+
+	// passwords enforces the password policy.
+	passwords *passwords.Checker
+
+	// stakeMtx serializes the stake verifications so that a ticket
+	// can't be used to verify multiple users concurrently.
+	stakeMtx sync.Mutex
+
+	// acl contains the network access control lists.
+	acl *acl
+
+	// maintenance contains the maintenance mode status.
+	maintenance *maintenance
+
+	// bodyLimits contains the maximum request body sizes of the routes.
+	bodyLimits *bodyLimits
+
+	// rateLimits contains the rate limits of the routes.
+	rateLimits *rateLimits
+
+	// idempotency caches the replies to the write requests that contain
+	// an idempotency key.
+	idempotency *idempotencyCache
+
+	// openapi is the OpenAPI document of the APIs. The request bodies
+	// of the routes that are in the document are validated against it.
+	openapi *openapi.Document
+
+	// metrics collects the Prometheus metrics. It is nil when the
+	// metrics are disabled.
+	metrics *metrics.Metrics
+
+	// tracer exports the request trace spans. It is nil when tracing
+	// is disabled.
+	tracer *tracing.Tracer
+
+	// observePoliteiad is called after every politeiad request. See
+	// newPoliteiadObserver.
+	observePoliteiad pdclient.ObserverFunc
+
+	// shuttingDown is set to 1 once a graceful shutdown has started. It
+	// must be accessed atomically.
+	shuttingDown uint32
+
 	// These fields are only used during piwww mode
 	userPaywallPool map[uuid.UUID]paywallPoolMember // [userid][paywallPoolMember]
 
@@ -102,6 +176,16 @@ type politeiawww struct {
 	test bool
 }
 
+// politeiadIdentity returns the identity that politeiad replies are verified
+// against. The identity of the politeiad client is used when available since
+// it is replaced when politeiad rotates its identity.
+func (p *politeiawww) politeiadIdentity() *identity.PublicIdentity {
+	if p.politeiad == nil {
+		return p.cfg.Identity
+	}
+	return p.politeiad.PublicIdentity()
+}
+
 // handleNotFound is a generic handler for an invalid route.
 func (p *politeiawww) handleNotFound(w http.ResponseWriter, r *http.Request) {
 	// Log incoming connection
@@ -121,8 +205,22 @@ func (p *politeiawww) handleNotFound(w http.ResponseWriter, r *http.Request) {
 	util.RespondWithJSON(w, http.StatusNotFound, www.ErrorReply{})
 }
 
+// pluginAPIVersions contains the versions of the plugin APIs that are
+// supported by the pi mode of politeiawww. It is returned in the version
+// reply so that clients are able to negotiate the API version that they
+// use.
+var pluginAPIVersions = map[string][]uint32{
+	"records":    {1},
+	"comments":   {1},
+	"ticketvote": {1},
+	"pi":         {1},
+}
+
 // version is an HTTP GET to determine the lowest API route version that this
 // backend supports.  Additionally it is used to obtain a CSRF token.
+//
+// The cookie based CSRF token has been DEPRECATED. API clients should use the
+// CSRF session token route instead. See www.CSRFToken for more details.
 func (p *politeiawww) handleVersion(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("handleVersion")
 
@@ -130,10 +228,20 @@ func (p *politeiawww) handleVersion(w http.ResponseWriter, r *http.Request) {
 		Version:      www.PoliteiaWWWAPIVersion,
 		Route:        www.PoliteiaWWWAPIRoute,
 		BuildVersion: version.BuildMainVersion(),
-		PubKey:       hex.EncodeToString(p.cfg.Identity.Key[:]),
+		PubKey:       hex.EncodeToString(p.politeiadIdentity().Key[:]),
 		TestNet:      p.cfg.TestNet,
 		Mode:         p.cfg.Mode,
 	}
+	if p.cfg.ServerIdentity != nil {
+		versionReply.SigningPubKey = p.cfg.ServerIdentity.Public.String()
+	}
+	if prevID := p.cfg.RotationIdentity(); prevID != nil {
+		versionReply.PrevSigningPubKey = prevID.Public.String()
+		versionReply.RotationEnd = p.cfg.RotationEndTime
+	}
+	if p.cfg.Mode == config.PoliteiaWWWMode {
+		versionReply.APIs = pluginAPIVersions
+	}
 
 	_, err := p.sessions.GetSessionUser(w, r)
 	if err == nil {
@@ -163,8 +271,53 @@ func (p *politeiawww) handlePolicy(w http.ResponseWriter, r *http.Request) {
 	// Get the policy command.
 	log.Tracef("handlePolicy")
 
-	reply := &www.PolicyReply{
-		MinPasswordLength:          www.PolicyMinPasswordLength,
+	util.RespondWithJSON(w, http.StatusOK, p.policy())
+}
+
+// handleSiteInfo returns the branding and the enabled features of the
+// deployment.
+func (p *politeiawww) handleSiteInfo(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleSiteInfo")
+
+	util.RespondWithJSON(w, http.StatusOK, p.siteInfo())
+}
+
+// siteInfo returns the www site info.
+func (p *politeiawww) siteInfo() www.SiteInfoReply {
+	features := make([]string, 0, 5)
+	if p.paywallIsEnabled() {
+		features = append(features, www.FeaturePaywall)
+	}
+	if p.mail.IsEnabled() {
+		features = append(features, www.FeatureEmail)
+	}
+	if p.cfg.Mode == config.PoliteiaWWWMode {
+		if p.cfg.Telemetry {
+			features = append(features, www.FeatureTelemetry)
+		}
+		if len(p.cfg.OAuthClients) > 0 || p.cfg.OAuthIssuer != "" {
+			features = append(features, www.FeatureOAuth)
+		}
+		features = append(features, www.FeatureReports)
+	}
+
+	return www.SiteInfoReply{
+		Name:     p.cfg.SiteName,
+		LogoURL:  p.cfg.SiteLogoURL,
+		Contact:  p.cfg.SiteContact,
+		Network:  p.params.Name,
+		Mode:     p.cfg.Mode,
+		Features: features,
+	}
+}
+
+// policy returns the www policy.
+func (p *politeiawww) policy() www.PolicyReply {
+	pp := p.passwords.Policy()
+	return www.PolicyReply{
+		MinPasswordLength:          pp.MinLength,
+		MinPasswordScore:           pp.MinScore,
+		PasswordBreachCheck:        pp.BreachCheck,
 		MinUsernameLength:          www.PolicyMinUsernameLength,
 		MaxUsernameLength:          www.PolicyMaxUsernameLength,
 		UsernameSupportedChars:     www.PolicyUsernameSupportedChars,
@@ -180,7 +333,7 @@ func (p *politeiawww) handlePolicy(w http.ResponseWriter, r *http.Request) {
 		MaxProposalNameLength:      www.PolicyMaxProposalNameLength,
 		ProposalNameSupportedChars: www.PolicyProposalNameSupportedChars,
 		MaxCommentLength:           www.PolicyMaxCommentLength,
-		TokenPrefixLength:          www.TokenPrefixLength,
+		TokenPrefixLength:          p.cfg.TokenPrefixLength,
 		BuildInformation:           version.BuildInformation(),
 		IndexFilename:              www.PolicyIndexFilename,
 		MinLinkByPeriod:            0,
@@ -188,9 +341,8 @@ func (p *politeiawww) handlePolicy(w http.ResponseWriter, r *http.Request) {
 		MinVoteDuration:            0,
 		MaxVoteDuration:            0,
 		PaywallConfirmations:       p.cfg.MinConfirmationsRequired,
+		EmailLocales:               mail.Locales(),
 	}
-
-	util.RespondWithJSON(w, http.StatusOK, reply)
 }
 
 // websocketPing is used to verify that websockets are operational.
@@ -213,6 +365,36 @@ func (p *politeiawww) websocketPing(id string) {
 	}
 }
 
+// websocketEvent sends an event to all websockets that have subscribed to it.
+// The event is dropped for the websockets whose event queue is full so that a
+// slow client can't block the event listeners.
+func (p *politeiawww) websocketEvent(e www.WSEvent) {
+	log.Tracef("websocketEvent %v %v", e.Event, e.Token)
+
+	p.wsMtx.RLock()
+	defer p.wsMtx.RUnlock()
+
+	for _, v := range p.ws {
+		for _, wc := range v {
+			if _, ok := wc.subscriptions[e.Event]; !ok {
+				continue
+			}
+			if len(wc.tokens) > 0 {
+				if _, ok := wc.tokens[e.Token]; !ok {
+					continue
+				}
+			}
+
+			select {
+			case wc.eventC <- e:
+			default:
+				log.Debugf("websocketEvent queue full %v %v",
+					wc, e.Event)
+			}
+		}
+	}
+}
+
 // handleWebsocketRead reads a websocket command off the socket and tries to
 // handle it. Currently it only supports subscribing to websocket events.
 func (p *politeiawww) handleWebsocketRead(wc *wsContext) {
@@ -265,11 +447,22 @@ func (p *politeiawww) handleWebsocketRead(wc *wsContext) {
 				}
 				subscriptions[v] = struct{}{}
 			}
+			if len(subscribe.Tokens) > www.WSSubscribeTokensMax {
+				errors = append(errors,
+					fmt.Sprintf("too many tokens; max is %v",
+						www.WSSubscribeTokensMax))
+			}
+			tokens := make(map[string]struct{},
+				len(subscribe.Tokens))
+			for _, v := range subscribe.Tokens {
+				tokens[v] = struct{}{}
+			}
 
 			if len(errors) == 0 {
 				// Replace old subscriptions
 				p.wsMtx.Lock()
 				wc.subscriptions = subscriptions
+				wc.tokens = tokens
 				p.wsMtx.Unlock()
 			} else {
 				wc.errorC <- www.WSError{
@@ -282,8 +475,8 @@ func (p *politeiawww) handleWebsocketRead(wc *wsContext) {
 	}
 }
 
-// handleWebsocketWrite attempts to notify a subscribed websocket. Currently
-// only ping is supported.
+// handleWebsocketWrite attempts to notify a subscribed websocket of a ping or
+// of an event.
 func (p *politeiawww) handleWebsocketWrite(wc *wsContext) {
 	defer wc.wg.Done()
 	log.Tracef("handleWebsocketWrite %v", wc)
@@ -315,6 +508,15 @@ func (p *politeiawww) handleWebsocketWrite(wc *wsContext) {
 			cmd = www.WSCPing
 			id = ""
 			payload = www.WSPing{Timestamp: time.Now().Unix()}
+		case e, ok := <-wc.eventC:
+			if !ok {
+				log.Tracef("handleWebsocketWrite event not ok"+
+					" %v", wc)
+				return
+			}
+			cmd = www.WSCEvent
+			id = ""
+			payload = e
 		}
 
 		err := utilwww.WSWrite(wc.conn, cmd, id, payload)
@@ -334,8 +536,10 @@ func (p *politeiawww) handleWebsocket(w http.ResponseWriter, r *http.Request, id
 	wc := wsContext{
 		uuid:          id,
 		subscriptions: make(map[string]struct{}),
+		tokens:        make(map[string]struct{}),
 		pingC:         make(chan struct{}),
 		errorC:        make(chan www.WSError),
+		eventC:        make(chan www.WSEvent, wsEventQueueSize),
 		done:          make(chan struct{}),
 	}
 
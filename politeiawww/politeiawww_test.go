@@ -77,3 +77,73 @@ func TestHandleVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestWebsocketEvent(t *testing.T) {
+	newContext := func(subscriptions []string, tokens []string) *wsContext {
+		wc := wsContext{
+			subscriptions: make(map[string]struct{}),
+			tokens:        make(map[string]struct{}),
+			eventC:        make(chan www.WSEvent, 1),
+		}
+		for _, v := range subscriptions {
+			wc.subscriptions[v] = struct{}{}
+		}
+		for _, v := range tokens {
+			wc.tokens[v] = struct{}{}
+		}
+		return &wc
+	}
+	var (
+		token = "4e6b1f0c9f2e8a7d"
+
+		all       = newContext([]string{www.WSEventCommentNew}, nil)
+		watching  = newContext([]string{www.WSEventCommentNew}, []string{token})
+		other     = newContext([]string{www.WSEventCommentNew}, []string{"abcdef"})
+		ping      = newContext([]string{www.WSCPing}, nil)
+		queueFull = newContext([]string{www.WSEventCommentNew}, nil)
+	)
+	queueFull.eventC <- www.WSEvent{}
+
+	p := &politeiawww{
+		ws: map[string]map[string]*wsContext{
+			"user1": {
+				"1": all,
+				"2": watching,
+			},
+			"user2": {
+				"1": other,
+				"2": ping,
+				"3": queueFull,
+			},
+		},
+	}
+	p.websocketEvent(www.WSEvent{
+		Event:     www.WSEventCommentNew,
+		Token:     token,
+		CommentID: 1,
+	})
+
+	var tests = []struct {
+		name string
+		wc   *wsContext
+		want bool
+	}{
+		{"all records", all, true},
+		{"watched record", watching, true},
+		{"unwatched record", other, false},
+		{"not subscribed", ping, false},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			var got bool
+			select {
+			case e := <-v.wc.eventC:
+				got = e.CommentID == 1
+			default:
+			}
+			if got != v.want {
+				t.Errorf("got event %v, want %v", got, v.want)
+			}
+		})
+	}
+}
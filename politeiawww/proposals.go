@@ -15,7 +15,6 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/decred/politeia/decredplugin"
 	pdv2 "github.com/decred/politeia/politeiad/api/v2"
 	piplugin "github.com/decred/politeia/politeiad/plugins/pi"
 	"github.com/decred/politeia/politeiad/plugins/ticketvote"
@@ -1051,29 +1050,29 @@ func convertVoteTypeToWWW(t tkplugin.VoteT) www.VoteT {
 	}
 }
 
-func convertVoteErrorCodeToWWW(e tkplugin.VoteErrorT) decredplugin.ErrorStatusT {
+func convertVoteErrorCodeToWWW(e tkplugin.VoteErrorT) www.CastVoteErrorT {
 	switch e {
 	case tkplugin.VoteErrorInvalid:
-		return decredplugin.ErrorStatusInvalid
+		return www.CastVoteErrorInvalid
 	case tkplugin.VoteErrorInternalError:
-		return decredplugin.ErrorStatusInternalError
+		return www.CastVoteErrorInternalError
 	case tkplugin.VoteErrorRecordNotFound:
-		return decredplugin.ErrorStatusProposalNotFound
+		return www.CastVoteErrorProposalNotFound
 	case tkplugin.VoteErrorMultipleRecordVotes:
-		// There is not decredplugin error code for this
+		// There is not a www error code for this
 	case tkplugin.VoteErrorVoteStatusInvalid:
-		return decredplugin.ErrorStatusVoteHasEnded
+		return www.CastVoteErrorVoteHasEnded
 	case tkplugin.VoteErrorVoteBitInvalid:
-		return decredplugin.ErrorStatusInvalidVoteBit
+		return www.CastVoteErrorInvalidVoteBit
 	case tkplugin.VoteErrorSignatureInvalid:
-		// There is not decredplugin error code for this
+		// There is not a www error code for this
 	case tkplugin.VoteErrorTicketNotEligible:
-		return decredplugin.ErrorStatusIneligibleTicket
+		return www.CastVoteErrorIneligibleTicket
 	case tkplugin.VoteErrorTicketAlreadyVoted:
-		return decredplugin.ErrorStatusDuplicateVote
+		return www.CastVoteErrorDuplicateVote
 	default:
 	}
-	return decredplugin.ErrorStatusInternalError
+	return www.CastVoteErrorInternalError
 }
 
 func convertVoteStatusReply(token string, s tkplugin.SummaryReply) www.VoteStatusReply {
@@ -0,0 +1,224 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/util"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// The following buckets are supported by the ratelimit config
+	// setting. The default bucket applies to all routes that have not
+	// been assigned a bucket.
+	rateLimitDefault = "default"
+	rateLimitLogin   = "login"
+	rateLimitSignup  = "signup"
+	rateLimitComment = "comment"
+	rateLimitBallot  = "ballot"
+	rateLimitReport  = "report"
+
+	// rateLimitWindow is the window in which the requests of a client
+	// are counted.
+	rateLimitWindow = time.Minute
+)
+
+var (
+	// rateLimitBuckets contains the supported rate limit buckets.
+	rateLimitBuckets = map[string]struct{}{
+		rateLimitDefault: {},
+		rateLimitLogin:   {},
+		rateLimitSignup:  {},
+		rateLimitComment: {},
+		rateLimitBallot:  {},
+		rateLimitReport:  {},
+	}
+)
+
+// rateLimit contains the maximum number of requests per minute of a rate
+// limit bucket. A zero limit disables the limit.
+type rateLimit struct {
+	perIP   uint32
+	perUser uint32
+}
+
+// parseRateLimit parses a ratelimit config setting. The setting uses the
+// format bucket:perip:peruser.
+func parseRateLimit(s string) (string, *rateLimit, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return "", nil, fmt.Errorf("invalid format %v; want "+
+			"bucket:perip:peruser", s)
+	}
+	bucket := parts[0]
+	if _, ok := rateLimitBuckets[bucket]; !ok {
+		return "", nil, fmt.Errorf("invalid bucket %v", bucket)
+	}
+	perIP, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid per ip limit %v", parts[1])
+	}
+	perUser, err := strconv.ParseUint(parts[2], 10, 32)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid per user limit %v", parts[2])
+	}
+	return bucket, &rateLimit{
+		perIP:   uint32(perIP),
+		perUser: uint32(perUser),
+	}, nil
+}
+
+// rateLimits limits the number of requests per minute that a client address
+// and a user can send to the routes of a rate limit bucket. The requests are
+// counted in fixed one minute windows. The routes and the user ID lookup must
+// be set before the server starts accepting requests.
+type rateLimits struct {
+	sync.Mutex
+	limits   map[string]rateLimit // [bucket]rateLimit
+	routes   map[string]string    // [route]bucket
+	clientIP func(*http.Request) net.IP
+
+	// userID returns the user ID of the session of the request or an
+	// empty string if the request does not have a valid session. The
+	// per user limits are not enforced when it is not set.
+	userID func(http.ResponseWriter, *http.Request) string
+
+	window time.Time         // Start of the current window
+	counts map[string]uint32 // [bucket/key]count
+}
+
+// newRateLimits returns a new rateLimits that is populated using the
+// provided ratelimit config settings. The settings are applied in order so a
+// later setting of a bucket overrides an earlier one.
+func newRateLimits(settings []string, clientIP func(*http.Request) net.IP) (*rateLimits, error) {
+	l := rateLimits{
+		limits:   make(map[string]rateLimit, len(rateLimitBuckets)),
+		routes:   make(map[string]string, 16),
+		clientIP: clientIP,
+		counts:   make(map[string]uint32, 1024),
+	}
+	for _, v := range settings {
+		bucket, rl, err := parseRateLimit(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ratelimit: %v", err)
+		}
+		l.limits[bucket] = *rl
+	}
+	for k, v := range l.limits {
+		log.Debugf("Rate limit %v: %v per ip, %v per user", k, v.perIP,
+			v.perUser)
+	}
+	return &l, nil
+}
+
+// set assigns a route to a rate limit bucket. The route must include the API
+// version prefix.
+func (l *rateLimits) set(route, bucket string) {
+	l.routes[route] = bucket
+}
+
+// bucket returns the rate limit bucket of the route that matched the
+// request.
+func (l *rateLimits) bucket(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return rateLimitDefault
+	}
+	tmpl, err := route.GetPathTemplate()
+	if err != nil {
+		return rateLimitDefault
+	}
+	bucket, ok := l.routes[tmpl]
+	if !ok {
+		return rateLimitDefault
+	}
+	return bucket
+}
+
+// allow counts a request of the provided key and returns whether the key is
+// still within the limit. The time until the key may send requests again is
+// returned when the limit has been exceeded.
+func (l *rateLimits) allow(bucket, key string, max uint32, now time.Time) (bool, time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+
+	// Start a new window once the current one has passed. The counts
+	// of the previous window are no longer needed.
+	window := now.Truncate(rateLimitWindow)
+	if !window.Equal(l.window) {
+		l.window = window
+		l.counts = make(map[string]uint32, len(l.counts))
+	}
+
+	k := bucket + "/" + key
+	if l.counts[k] >= max {
+		return false, window.Add(rateLimitWindow).Sub(now)
+	}
+	l.counts[k]++
+
+	return true, 0
+}
+
+// respondRateLimited replies with a 429 and a Retry-After header that
+// contains the number of seconds until the client may send requests again.
+func respondRateLimited(w http.ResponseWriter, r *http.Request, bucket string, retry time.Duration) {
+	log.Debugf("%v rate limited: %v %v bucket %v",
+		util.RemoteAddr(r), r.Method, r.URL, bucket)
+
+	seconds := int64(math.Ceil(retry.Seconds()))
+	w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+	util.RespondWithJSON(w, http.StatusTooManyRequests, www.UserError{
+		ErrorCode:    www.ErrorStatusRateLimited,
+		ErrorContext: []string{bucket},
+	})
+}
+
+// middleware enforces the rate limits of the bucket of the route. The per
+// client address limit is checked first so that the session of a client that
+// exceeds it is not looked up.
+func (l *rateLimits) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bucket := l.bucket(r)
+		limit, ok := l.limits[bucket]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		now := time.Now()
+		if limit.perIP > 0 {
+			ip := l.clientIP(r)
+			if ip != nil {
+				ok, retry := l.allow(bucket, ip.String(), limit.perIP, now)
+				if !ok {
+					respondRateLimited(w, r, bucket, retry)
+					return
+				}
+			}
+		}
+		if limit.perUser > 0 && l.userID != nil {
+			userID := l.userID(w, r)
+			if userID != "" {
+				ok, retry := l.allow(bucket, userID, limit.perUser, now)
+				if !ok {
+					respondRateLimited(w, r, bucket, retry)
+					return
+				}
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func TestRateLimits(t *testing.T) {
+	// Invalid settings are rejected
+	for _, v := range []string{"login:10", "unknown:1:1", "login:x:0"} {
+		_, err := newRateLimits([]string{v}, nil)
+		if err == nil {
+			t.Fatalf("%v: got nil error", v)
+		}
+	}
+
+	clientIP := func(r *http.Request) net.IP {
+		host, _, _ := net.SplitHostPort(r.RemoteAddr)
+		return net.ParseIP(host)
+	}
+	l, err := newRateLimits([]string{"login:5:0", "login:2:0"}, clientIP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.set("/v1/login", rateLimitLogin)
+
+	// Later settings override earlier ones
+	if got := l.limits[rateLimitLogin].perIP; got != 2 {
+		t.Fatalf("got per ip limit %v, want 2", got)
+	}
+
+	// The counts are reset in a new window
+	now := time.Unix(1600000000, 0).Truncate(rateLimitWindow)
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.allow(rateLimitLogin, "k", 2, now); !ok {
+			t.Fatalf("request %v was rate limited", i)
+		}
+	}
+	ok, retry := l.allow(rateLimitLogin, "k", 2, now.Add(15*time.Second))
+	if ok || retry != 45*time.Second {
+		t.Fatalf("got %v %v, want false 45s", ok, retry)
+	}
+	if ok, _ := l.allow(rateLimitLogin, "k", 2, now.Add(time.Minute)); !ok {
+		t.Fatalf("request in new window was rate limited")
+	}
+
+	// Only the routes of a bucket that has limits are rate limited
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	router := mux.NewRouter()
+	router.Use(l.middleware)
+	router.HandleFunc("/v1/login", handler).Methods(http.MethodPost)
+	router.HandleFunc("/v1/other", handler).Methods(http.MethodPost)
+	send := func(route string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, route, nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+	for i := 0; i < 3; i++ {
+		if w := send("/v1/other"); w.Code != http.StatusOK {
+			t.Fatalf("got code %v, want 200", w.Code)
+		}
+	}
+	var w *httptest.ResponseRecorder
+	for i := 0; i < 3; i++ {
+		w = send("/v1/login")
+	}
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("got code %v, want 429", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Fatalf("Retry-After header not set")
+	}
+}
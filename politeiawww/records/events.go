@@ -18,6 +18,10 @@ const (
 
 	// EventTypeSetStatus is emitted when a a record status is updated.
 	EventTypeSetStatus = "records-setstatus"
+
+	// EventTypeFileQuarantined is emitted when an uploaded file is
+	// found to be infected and is quarantined.
+	EventTypeFileQuarantined = "records-filequarantined"
 )
 
 // EventNew is the event data for the EventTypeNew.
@@ -36,3 +40,13 @@ type EventEdit struct {
 type EventSetStatus struct {
 	Record v1.Record
 }
+
+// EventFileQuarantined is the event data for the EventTypeFileQuarantined.
+type EventFileQuarantined struct {
+	User      user.User
+	Token     string // Empty for new records
+	Name      string // File name
+	Digest    string // SHA256 digest of the file
+	Signature string // Name of the detected threat
+	Timestamp int64
+}
@@ -0,0 +1,214 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package records
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	pdv1 "github.com/decred/politeia/politeiad/api/v1"
+	v1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	"github.com/decred/politeia/util"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// legacyTokenLength is the length of a hex encoded legacy git
+	// backend censorship token.
+	legacyTokenLength = 64
+)
+
+// legacyTokens maps the censorship tokens of proposals that were submitted
+// to the legacy git backend to the tstore tokens that the proposals were
+// migrated to.
+type legacyTokens struct {
+	tokens   map[string]string // [legacyToken]token
+	prefixes map[string]string // [legacyTokenPrefix]token
+}
+
+// loadLegacyTokens reads a legacy tokens file and returns the token mappings.
+//
+// The legacy tokens file contains one proposal per line. Each line contains
+// the legacy token followed by the tstore token, separated by whitespace.
+// Blank lines and lines starting with a '#' are ignored.
+func loadLegacyTokens(path string) (*legacyTokens, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		l = legacyTokens{
+			tokens:   make(map[string]string, 512),
+			prefixes: make(map[string]string, 512),
+		}
+		ambiguous = make(map[string]struct{})
+		scanner   = bufio.NewScanner(f)
+		line      int
+	)
+	for scanner.Scan() {
+		line++
+		s := strings.TrimSpace(scanner.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		fields := strings.Fields(s)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %v: expected '<legacy token> "+
+				"<token>', got '%v'", line, s)
+		}
+		legacy, token := strings.ToLower(fields[0]), strings.ToLower(fields[1])
+		_, err := hex.DecodeString(legacy)
+		if err != nil || len(legacy) != legacyTokenLength {
+			return nil, fmt.Errorf("line %v: invalid legacy token '%v'",
+				line, legacy)
+		}
+		_, err = util.TokenDecode(util.TokenTypeTstore, token)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: invalid token '%v': %v",
+				line, token, err)
+		}
+		if _, ok := l.tokens[legacy]; ok {
+			return nil, fmt.Errorf("line %v: duplicate legacy token %v",
+				line, legacy)
+		}
+		l.tokens[legacy] = token
+
+		// Legacy permalinks often use the token prefix. A prefix that
+		// is shared by multiple legacy tokens can't be resolved.
+		prefix := legacy[:pdv1.TokenPrefixLength]
+		if _, ok := l.prefixes[prefix]; ok {
+			ambiguous[prefix] = struct{}{}
+		}
+		l.prefixes[prefix] = token
+	}
+	err = scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+	for prefix := range ambiguous {
+		delete(l.prefixes, prefix)
+	}
+
+	return &l, nil
+}
+
+// lookup returns the tstore token for a full length legacy token or a legacy
+// token prefix.
+func (l *legacyTokens) lookup(legacy string) (string, bool) {
+	legacy = strings.ToLower(legacy)
+	switch len(legacy) {
+	case legacyTokenLength:
+		token, ok := l.tokens[legacy]
+		return token, ok
+	case pdv1.TokenPrefixLength:
+		token, ok := l.prefixes[legacy]
+		return token, ok
+	}
+	return "", false
+}
+
+// LegacyToken returns the tstore token of a proposal that was submitted to
+// the legacy git backend. Only full length legacy tokens are resolved since a
+// legacy token prefix can collide with a tstore short token.
+func (c *Records) LegacyToken(legacy string) (string, bool) {
+	if c.legacy == nil || len(legacy) != legacyTokenLength {
+		return "", false
+	}
+	return c.legacy.lookup(legacy)
+}
+
+func (r *Records) processLegacyTokens(ctx context.Context, lt v1.LegacyTokens) (*v1.LegacyTokensReply, error) {
+	log.Tracef("processLegacyTokens: %v", lt.Tokens)
+
+	// Verify page size
+	if len(lt.Tokens) > v1.LegacyTokensPageSize {
+		e := fmt.Sprintf("max page size is %v", v1.LegacyTokensPageSize)
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodePageSizeExceeded,
+			ErrorContext: e,
+		}
+	}
+
+	tokens := make(map[string]string, len(lt.Tokens))
+	if r.legacy == nil {
+		return &v1.LegacyTokensReply{
+			Tokens: tokens,
+		}, nil
+	}
+	for _, v := range lt.Tokens {
+		token, ok := r.legacy.lookup(v)
+		if !ok {
+			continue
+		}
+		tokens[v] = token
+	}
+
+	return &v1.LegacyTokensReply{
+		Tokens: tokens,
+	}, nil
+}
+
+// HandleLegacyTokens is the request handler for the records v1 LegacyTokens
+// route.
+func (c *Records) HandleLegacyTokens(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleLegacyTokens")
+
+	var lt v1.LegacyTokens
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&lt); err != nil {
+		respondWithError(w, r, "HandleLegacyTokens: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	ltr, err := c.processLegacyTokens(r.Context(), lt)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleLegacyTokens: processLegacyTokens: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, ltr)
+}
+
+// HandleLegacyRedirect redirects a legacy proposal permalink to the permalink
+// of the migrated proposal. Both the proposal and the proposal comment
+// permalinks are supported. A 404 is returned for legacy tokens that do not
+// correspond to a migrated proposal.
+func (c *Records) HandleLegacyRedirect(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleLegacyRedirect")
+
+	var (
+		vars      = mux.Vars(r)
+		legacy    = vars["token"]
+		commentID = vars["commentid"]
+	)
+	var token string
+	if c.legacy != nil {
+		token, _ = c.legacy.lookup(legacy)
+	}
+	if token == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	url := c.cfg.LegacyRedirectURL + "/record/" +
+		token[:util.ShortTokenLength()]
+	if commentID != "" {
+		url += "/comments/" + commentID
+	}
+
+	http.Redirect(w, r, url, http.StatusMovedPermanently)
+}
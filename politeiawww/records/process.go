@@ -31,6 +31,13 @@ func (r *Records) processNew(ctx context.Context, n v1.New, u user.User) (*v1.Ne
 		}
 	}
 
+	// Bot accounts are not allowed to submit records
+	if u.Bot {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeBotNotAllowed,
+		}
+	}
+
 	// Execute pre plugin hooks. Checking the mode is a temporary
 	// measure until user plugins have been properly implemented.
 	switch r.cfg.Mode {
@@ -41,6 +48,12 @@ func (r *Records) processNew(ctx context.Context, n v1.New, u user.User) (*v1.Ne
 		}
 	}
 
+	// Scan the uploaded files
+	err := r.scanFiles(ctx, u, "", n.Files)
+	if err != nil {
+		return nil, err
+	}
+
 	// Setup metadata stream
 	um := usermd.UserMetadata{
 		UserID:    u.ID.String(),
@@ -139,6 +152,12 @@ func (r *Records) processEdit(ctx context.Context, e v1.Edit, u user.User) (*v1.
 		return nil, err
 	}
 
+	// Scan the uploaded files
+	err = r.scanFiles(ctx, u, e.Token, e.Files)
+	if err != nil {
+		return nil, err
+	}
+
 	// Setup files
 	filesAdd := convertFilesToPD(e.Files)
 	filesDel := filesToDel(curr.Files, e.Files)
@@ -254,27 +273,110 @@ func (r *Records) processDetails(ctx context.Context, d v1.Details, u *user.User
 				ErrorCode: v1.ErrorCodeRecordNotFound,
 			}
 		}
+		return nil, err
 	}
 
 	// Only admins and the record author are allowed to retrieve
-	// unvetted record files. Remove files if the user is not an admin
-	// or the author. This is a public route so a user may not exist.
-	if rc.State != v1.RecordStateVetted {
-		var (
-			authorID = userIDFromMetadataStreams(rc.Metadata)
-			isAuthor = u != nil && u.ID.String() == authorID
-			isAdmin  = u != nil && u.Admin
-		)
-		if !isAuthor && !isAdmin {
-			rc.Files = []v1.File{}
+	// unvetted record files. This is a public route so a user may not
+	// exist.
+	recordStripFiles(rc, u)
+
+	return &v1.DetailsReply{
+		Record:  *rc,
+		Lineage: r.recordLineage(ctx, *rc),
+	}, nil
+}
+
+func (r *Records) processBatchDetails(ctx context.Context, bd v1.BatchDetails, u *user.User) (*v1.BatchDetailsReply, error) {
+	log.Tracef("processBatchDetails: %v reqs", len(bd.Requests))
+
+	// Verify page size
+	if len(bd.Requests) > v1.BatchDetailsPageSize {
+		e := fmt.Sprintf("max page size is %v", v1.BatchDetailsPageSize)
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodePageSizeExceeded,
+			ErrorContext: e,
 		}
 	}
 
-	return &v1.DetailsReply{
-		Record: *rc,
+	// Get the full records. A single politeiad request is used for all
+	// of the records.
+	reqs := make([]pdv2.RecordRequest, 0, len(bd.Requests))
+	for _, v := range bd.Requests {
+		reqs = append(reqs, pdv2.RecordRequest{
+			Token:   v.Token,
+			Version: v.Version,
+		})
+	}
+	records, err := r.records(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Records that were not returned by politeiad are reported as not
+	// found. Only admins and the record author are allowed to retrieve
+	// unvetted record files.
+	errs := make(map[string]v1.UserErrorReply)
+	for _, v := range bd.Requests {
+		rc, ok := records[v.Token]
+		if !ok {
+			errs[v.Token] = v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeRecordNotFound,
+			}
+			continue
+		}
+		recordStripFiles(&rc, u)
+		records[v.Token] = rc
+	}
+
+	return &v1.BatchDetailsReply{
+		Records:       records,
+		Errors:        errs,
+		CommentCounts: r.commentCounts(ctx, records),
 	}, nil
 }
 
+// commentCounts returns the comment counts of the provided records keyed by
+// the same tokens as the records. The comment counts are supplementary, so a
+// failure to retrieve them is logged and nil is returned instead of failing
+// the request.
+func (r *Records) commentCounts(ctx context.Context, records map[string]v1.Record) map[string]uint32 {
+	if r.comments == nil || len(records) == 0 {
+		return nil
+	}
+	tokens := make([]string, 0, len(records))
+	for _, v := range records {
+		tokens = append(tokens, v.CensorshipRecord.Token)
+	}
+	counts, err := r.comments.CommentCounts(ctx, tokens)
+	if err != nil {
+		log.Errorf("commentCounts: %v", err)
+		return nil
+	}
+	reply := make(map[string]uint32, len(records))
+	for k, v := range records {
+		if count, ok := counts[v.CensorshipRecord.Token]; ok {
+			reply[k] = count
+		}
+	}
+	return reply
+}
+
+// recordLineage returns the resubmission lineage of a record. The lineage is
+// supplementary, so a failure to retrieve it is logged and nil is returned
+// instead of failing the request.
+func (r *Records) recordLineage(ctx context.Context, rc v1.Record) []v1.LineageRecord {
+	if r.lineage == nil {
+		return nil
+	}
+	lineage, err := r.lineage.Lineage(ctx, rc)
+	if err != nil {
+		log.Errorf("recordLineage %v: %v", rc.CensorshipRecord.Token, err)
+		return nil
+	}
+	return lineage
+}
+
 func (r *Records) processTimestamps(ctx context.Context, t v1.Timestamps, isAdmin bool) (*v1.TimestampsReply, error) {
 	log.Tracef("processTimestamps: %v %v", t.Token, t.Version)
 
@@ -352,20 +454,11 @@ func (r *Records) processRecords(ctx context.Context, rs v1.Records, u *user.Use
 	}
 
 	// Only admins and the record author are allowed to retrieve
-	// unvetted record files. Remove files if the user is not an admin
-	// or the author. This is a public route so a user may not exist.
+	// unvetted record files. This is a public route so a user may not
+	// exist.
 	for k, v := range records {
-		if v.State != v1.RecordStateVetted {
-			var (
-				authorID = userIDFromMetadataStreams(v.Metadata)
-				isAuthor = u != nil && u.ID.String() == authorID
-				isAdmin  = u != nil && u.Admin
-			)
-			if !isAuthor && !isAdmin {
-				v.Files = []v1.File{}
-				records[k] = v
-			}
-		}
+		recordStripFiles(&v, u)
+		records[k] = v
 	}
 
 	return &v1.RecordsReply{
@@ -539,6 +632,22 @@ func (r *Records) convertRecordToV1(pdr pdv2.Record) (*v1.Record, error) {
 	return &rc, nil
 }
 
+// recordStripFiles removes the files from an unvetted record if the user is
+// not an admin or the record author. The user may be nil.
+func recordStripFiles(r *v1.Record, u *user.User) {
+	if r.State == v1.RecordStateVetted {
+		return
+	}
+	var (
+		authorID = userIDFromMetadataStreams(r.Metadata)
+		isAuthor = u != nil && u.ID.String() == authorID
+		isAdmin  = u != nil && u.Admin
+	)
+	if !isAuthor && !isAdmin {
+		r.Files = []v1.File{}
+	}
+}
+
 // recordPopulateUserData populates the record with user data that is not
 // stored in politeiad.
 func recordPopulateUserData(r *v1.Record, u user.User) {
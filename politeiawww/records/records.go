@@ -5,13 +5,17 @@
 package records
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"time"
 
 	pdclient "github.com/decred/politeia/politeiad/client"
 	v1 "github.com/decred/politeia/politeiawww/api/records/v1"
 	"github.com/decred/politeia/politeiawww/config"
 	"github.com/decred/politeia/politeiawww/events"
+	"github.com/decred/politeia/politeiawww/scanner"
 	"github.com/decred/politeia/politeiawww/sessions"
 	"github.com/decred/politeia/politeiawww/user"
 	"github.com/decred/politeia/util"
@@ -24,6 +28,52 @@ type Records struct {
 	userdb    user.Database
 	sessions  *sessions.Sessions
 	events    *events.Manager
+
+	// legacy contains the legacy git backend token mappings. This
+	// field will be nil if no legacy tokens file was provided.
+	legacy *legacyTokens
+
+	// scanner scans the uploaded files for malware. This field will be
+	// nil if no scanner was configured.
+	scanner scanner.Scanner
+
+	// comments returns the comment counts that are included in the
+	// batch details replies. This field will be nil if no comment
+	// counter was set.
+	comments CommentCounter
+
+	// lineage returns the resubmission lineage that is included in the
+	// details replies. This field will be nil if no lineage provider
+	// was set.
+	lineage LineageProvider
+}
+
+// CommentCounter returns the comment counts of records.
+type CommentCounter interface {
+	// CommentCounts returns the comment counts of the provided full
+	// length tokens.
+	CommentCounts(ctx context.Context, tokens []string) (map[string]uint32, error)
+}
+
+// SetCommentCounter sets the comment counter that is used to include the
+// comment counts in the batch details replies. This must be set prior to the
+// records API being used.
+func (c *Records) SetCommentCounter(cc CommentCounter) {
+	c.comments = cc
+}
+
+// LineageProvider returns the resubmission lineage of records.
+type LineageProvider interface {
+	// Lineage returns the records that the provided record is a
+	// resubmission of, ordered from newest to oldest.
+	Lineage(ctx context.Context, r v1.Record) ([]v1.LineageRecord, error)
+}
+
+// SetLineageProvider sets the lineage provider that is used to include the
+// resubmission lineage in the details replies. This must be set prior to the
+// records API being used.
+func (c *Records) SetLineageProvider(lp LineageProvider) {
+	c.lineage = lp
 }
 
 // HandleNew is the request handler for the records v1 New route.
@@ -124,8 +174,7 @@ func (c *Records) HandleDetails(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("HandleDetails")
 
 	var d v1.Details
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&d); err != nil {
+	if err := util.ParseRequest(r, &d); err != nil {
 		respondWithError(w, r, "HandleDetails: unmarshal",
 			v1.UserErrorReply{
 				ErrorCode: v1.ErrorCodeInputInvalid,
@@ -149,7 +198,41 @@ func (c *Records) HandleDetails(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	util.RespondWithJSON(w, http.StatusOK, dr)
+	util.RespondWithETag(w, r, dr)
+}
+
+// HandleBatchDetails is the request handler for the records v1 BatchDetails
+// route.
+func (c *Records) HandleBatchDetails(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleBatchDetails")
+
+	var bd v1.BatchDetails
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&bd); err != nil {
+		respondWithError(w, r, "HandleBatchDetails: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	// Lookup session user. This is a public route so a session may not
+	// exist. Ignore any session not found errors.
+	u, err := c.sessions.GetSessionUser(w, r)
+	if err != nil && err != sessions.ErrSessionNotFound {
+		respondWithError(w, r,
+			"HandleBatchDetails: GetSessionUser: %v", err)
+		return
+	}
+
+	bdr, err := c.processBatchDetails(r.Context(), bd, u)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleBatchDetails: processBatchDetails: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, bdr)
 }
 
 // HandleTimestamps is the request handler for the records v1 Timestamps route.
@@ -157,8 +240,7 @@ func (c *Records) HandleTimestamps(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("HandleTimestamps")
 
 	var t v1.Timestamps
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&t); err != nil {
+	if err := util.ParseRequest(r, &t); err != nil {
 		respondWithError(w, r, "HandleTimestamps: unmarshal",
 			v1.UserErrorReply{
 				ErrorCode: v1.ErrorCodeInputInvalid,
@@ -183,7 +265,7 @@ func (c *Records) HandleTimestamps(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	util.RespondWithJSON(w, http.StatusOK, tr)
+	util.RespondWithETag(w, r, tr)
 }
 
 // HandleRecords is the request handler for the records v1 Records route.
@@ -321,12 +403,35 @@ func (c *Records) HandleUserRecords(w http.ResponseWriter, r *http.Request) {
 }
 
 // New returns a new Records context.
-func New(cfg *config.Config, pdc *pdclient.Client, udb user.Database, s *sessions.Sessions, e *events.Manager) *Records {
-	return &Records{
+func New(cfg *config.Config, pdc *pdclient.Client, udb user.Database, s *sessions.Sessions, e *events.Manager) (*Records, error) {
+	r := Records{
 		cfg:       cfg,
 		politeiad: pdc,
 		userdb:    udb,
 		sessions:  s,
 		events:    e,
 	}
+
+	// Load the legacy token mappings
+	if cfg.LegacyTokens != "" {
+		l, err := loadLegacyTokens(cfg.LegacyTokens)
+		if err != nil {
+			return nil, fmt.Errorf("load legacy tokens: %v", err)
+		}
+		log.Infof("Legacy tokens: %v", len(l.tokens))
+		r.legacy = l
+	}
+
+	// Setup the content scanner
+	if cfg.Scanner != "" {
+		sc, err := scanner.New(cfg.Scanner,
+			time.Duration(cfg.ScannerTimeout)*time.Second)
+		if err != nil {
+			return nil, fmt.Errorf("scanner: %v", err)
+		}
+		log.Infof("Content scanner: %v", cfg.Scanner)
+		r.scanner = sc
+	}
+
+	return &r, nil
 }
@@ -0,0 +1,119 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package records
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	v1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	"github.com/decred/politeia/politeiawww/user"
+)
+
+// quarantineDirname is the name of the directory in the data directory that
+// infected uploads are moved to.
+const quarantineDirname = "quarantine"
+
+// quarantined describes a quarantined upload. It is saved next to the file
+// contents so that admins can review the upload.
+type quarantined struct {
+	Name      string `json:"name"`
+	MIME      string `json:"mime"`
+	Digest    string `json:"digest"`
+	Signature string `json:"signature"`
+	UserID    string `json:"userid"`
+	Username  string `json:"username"`
+	Token     string `json:"token,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// scanFiles scans the uploaded files using the configured content scanner.
+// An infected file is quarantined, the admins are alerted, and a user error
+// is returned. The files are not scanned when no scanner has been configured.
+// The token is empty for new records.
+func (r *Records) scanFiles(ctx context.Context, u user.User, token string, files []v1.File) error {
+	if r.scanner == nil {
+		return nil
+	}
+	for _, f := range files {
+		b, err := base64.StdEncoding.DecodeString(f.Payload)
+		if err != nil {
+			return v1.UserErrorReply{
+				ErrorCode:    v1.ErrorCodeFilePayloadInvalid,
+				ErrorContext: f.Name,
+			}
+		}
+		res, err := r.scanner.Scan(ctx, f.Name, b)
+		if err != nil {
+			return fmt.Errorf("scan %v: %v", f.Name, err)
+		}
+		if !res.Infected {
+			continue
+		}
+
+		q := quarantined{
+			Name:      f.Name,
+			MIME:      f.MIME,
+			Digest:    f.Digest,
+			Signature: res.Signature,
+			UserID:    u.ID.String(),
+			Username:  u.Username,
+			Token:     token,
+			Timestamp: time.Now().Unix(),
+		}
+		err = r.quarantine(q, b)
+		if err != nil {
+			return fmt.Errorf("quarantine %v: %v", f.Name, err)
+		}
+		log.Warnf("File quarantined: %v %v uploaded by %v: %v",
+			f.Name, f.Digest, u.Username, res.Signature)
+
+		r.events.Emit(EventTypeFileQuarantined,
+			EventFileQuarantined{
+				User:      u,
+				Token:     token,
+				Name:      f.Name,
+				Digest:    f.Digest,
+				Signature: res.Signature,
+				Timestamp: q.Timestamp,
+			})
+
+		return v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeFileRejected,
+			ErrorContext: f.Name,
+		}
+	}
+	return nil
+}
+
+// quarantine saves an infected file and its description to the quarantine
+// directory. The files are named after the upload timestamp and the SHA256
+// digest of the contents, since the digest that was provided by the user has
+// not been verified yet. The files are only readable by the server user.
+func (r *Records) quarantine(q quarantined, b []byte) error {
+	dir := filepath.Join(r.cfg.DataDir, quarantineDirname)
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return err
+	}
+	h := sha256.Sum256(b)
+	name := fmt.Sprintf("%v-%x", q.Timestamp, h)
+	err = ioutil.WriteFile(filepath.Join(dir, name), b, 0600)
+	if err != nil {
+		return err
+	}
+	jb, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, name+".json"), jb, 0600)
+}
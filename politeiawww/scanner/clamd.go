@@ -0,0 +1,91 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	// clamdChunkSize is the size of the chunks that a file is streamed
+	// to clamd in. clamd rejects chunks that are larger than its
+	// StreamMaxLength setting.
+	clamdChunkSize = 64 * 1024
+
+	// clamdFound is the suffix of a clamd reply that reports a threat.
+	clamdFound = " FOUND"
+)
+
+// clamd scans files using the INSTREAM command of a clamd daemon.
+type clamd struct {
+	network string // tcp or unix
+	address string
+	timeout time.Duration
+}
+
+// Scan satisfies the Scanner interface.
+func (c *clamd) Scan(ctx context.Context, name string, b []byte) (*Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, c.network, c.address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	err = conn.SetDeadline(deadline)
+	if err != nil {
+		return nil, err
+	}
+
+	// The file is sent as a sequence of chunks that are prefixed with
+	// their length. A zero length chunk ends the stream.
+	w := bufio.NewWriter(conn)
+	w.WriteString("zINSTREAM\x00")
+	size := make([]byte, 4)
+	for len(b) > 0 {
+		n := len(b)
+		if n > clamdChunkSize {
+			n = clamdChunkSize
+		}
+		binary.BigEndian.PutUint32(size, uint32(n))
+		w.Write(size)
+		w.Write(b[:n])
+		b = b[n:]
+	}
+	binary.BigEndian.PutUint32(size, 0)
+	w.Write(size)
+	err = w.Flush()
+	if err != nil {
+		return nil, err
+	}
+
+	// The reply uses the format 'stream: OK', 'stream: <signature>
+	// FOUND', or '<message> ERROR' and is terminated by a null byte.
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil {
+		return nil, err
+	}
+	reply = strings.TrimSuffix(reply, "\x00")
+	reply = strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case reply == "OK":
+		return &Result{}, nil
+	case strings.HasSuffix(reply, clamdFound):
+		return &Result{
+			Infected:  true,
+			Signature: strings.TrimSuffix(reply, clamdFound),
+		}, nil
+	}
+	return nil, fmt.Errorf("clamd: %v", reply)
+}
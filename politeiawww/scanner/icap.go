@@ -0,0 +1,135 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// icapDefaultPort is the ICAP port that is used when the scanner URL
+	// does not contain one.
+	icapDefaultPort = "1344"
+
+	// icapSignatureUnknown is the signature that is reported when the
+	// ICAP server blocks a file without naming the threat.
+	icapSignatureUnknown = "unknown"
+)
+
+// icapThreatHeaders are the ICAP response headers that are used by the
+// common ICAP servers to report a threat, in order of preference.
+var icapThreatHeaders = []string{
+	"X-Infection-Found",
+	"X-Virus-Id",
+	"X-Violations-Found",
+}
+
+// icap scans files using the RESPMOD method of an ICAP server. See RFC 3507.
+// The file is sent as the body of an encapsulated HTTP response. The server
+// replies with a 204 when the file is clean and with a 200 that contains a
+// modified response when the file has been blocked.
+type icap struct {
+	url     *url.URL
+	timeout time.Duration
+}
+
+// Scan satisfies the Scanner interface.
+func (c *icap) Scan(ctx context.Context, name string, b []byte) (*Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", c.url.Host)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	err = conn.SetDeadline(deadline)
+	if err != nil {
+		return nil, err
+	}
+
+	// Setup the encapsulated HTTP response
+	var hdr bytes.Buffer
+	fmt.Fprintf(&hdr, "GET /%v HTTP/1.1\r\n\r\n", url.PathEscape(name))
+	reqHdrLen := hdr.Len()
+	fmt.Fprintf(&hdr, "HTTP/1.1 200 OK\r\n"+
+		"Content-Type: application/octet-stream\r\n"+
+		"Content-Length: %v\r\n\r\n", len(b))
+
+	w := bufio.NewWriter(conn)
+	fmt.Fprintf(w, "RESPMOD %v ICAP/1.0\r\n", c.url.String())
+	fmt.Fprintf(w, "Host: %v\r\n", c.url.Host)
+	fmt.Fprintf(w, "Allow: 204\r\n")
+	fmt.Fprintf(w, "Encapsulated: req-hdr=0, res-hdr=%v, res-body=%v\r\n",
+		reqHdrLen, hdr.Len())
+	fmt.Fprintf(w, "\r\n")
+	w.Write(hdr.Bytes())
+	if len(b) > 0 {
+		fmt.Fprintf(w, "%x\r\n", len(b))
+		w.Write(b)
+		fmt.Fprintf(w, "\r\n")
+	}
+	fmt.Fprintf(w, "0\r\n\r\n")
+	err = w.Flush()
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the ICAP response status and headers. The encapsulated
+	// response is not needed.
+	tp := textproto.NewReader(bufio.NewReader(conn))
+	line, err := tp.ReadLine()
+	if err != nil {
+		return nil, err
+	}
+	s := strings.SplitN(line, " ", 3)
+	if len(s) < 2 || !strings.HasPrefix(s[0], "ICAP/") {
+		return nil, fmt.Errorf("icap: invalid status line '%v'", line)
+	}
+	h, err := tp.ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	switch s[1] {
+	case "204":
+		return &Result{}, nil
+	case "200":
+		r := Result{
+			Infected:  true,
+			Signature: icapSignatureUnknown,
+		}
+		for _, v := range icapThreatHeaders {
+			if t := h.Get(v); t != "" {
+				r.Signature = icapThreat(t)
+				break
+			}
+		}
+		return &r, nil
+	}
+	return nil, fmt.Errorf("icap: %v", line)
+}
+
+// icapThreat returns the threat name of an ICAP threat header. The
+// X-Infection-Found header uses the format 'Type=0; Resolution=2;
+// Threat=<name>;'. The other headers contain the name itself.
+func icapThreat(header string) string {
+	for _, v := range strings.Split(header, ";") {
+		v = strings.TrimSpace(v)
+		if strings.HasPrefix(v, "Threat=") {
+			return strings.TrimPrefix(v, "Threat=")
+		}
+	}
+	return strings.TrimSpace(header)
+}
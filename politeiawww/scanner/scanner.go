@@ -0,0 +1,74 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package scanner provides content scanners that check uploaded files for
+// malware before they are accepted.
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const (
+	// SchemeClamd is the URL scheme of a clamd scanner, e.g.
+	// clamd://127.0.0.1:3310 or clamd:///var/run/clamav/clamd.ctl.
+	SchemeClamd = "clamd"
+
+	// SchemeICAP is the URL scheme of an ICAP scanner, e.g.
+	// icap://127.0.0.1:1344/avscan.
+	SchemeICAP = "icap"
+)
+
+// Result is the result of a file scan.
+type Result struct {
+	Infected  bool
+	Signature string // Name of the detected threat
+}
+
+// Scanner scans file contents for malware.
+type Scanner interface {
+	// Scan scans the contents of a file. An error is returned if the
+	// file could not be scanned.
+	Scan(ctx context.Context, name string, b []byte) (*Result, error)
+}
+
+// New returns a new Scanner for the provided scanner URL. The timeout is
+// the maximum duration of a single scan.
+func New(rawURL string, timeout time.Duration) (Scanner, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case SchemeClamd:
+		c := clamd{
+			network: "tcp",
+			address: u.Host,
+			timeout: timeout,
+		}
+		if u.Host == "" {
+			c.network = "unix"
+			c.address = u.Path
+		}
+		if c.address == "" {
+			return nil, fmt.Errorf("clamd address not found")
+		}
+		return &c, nil
+	case SchemeICAP:
+		if u.Host == "" {
+			return nil, fmt.Errorf("icap host not found")
+		}
+		if u.Port() == "" {
+			u.Host += ":" + icapDefaultPort
+		}
+		return &icap{
+			url:     u,
+			timeout: timeout,
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported scanner scheme '%v'", u.Scheme)
+}
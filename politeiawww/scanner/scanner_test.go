@@ -0,0 +1,134 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// eicar is a stand-in for the test signature that the fake servers detect.
+var eicar = []byte("EICAR-STANDARD-ANTIVIRUS-TEST-FILE")
+
+// serve runs a fake scanner server that handles each connection using the
+// provided function. It returns the server address.
+func serve(t *testing.T, handle func(net.Conn)) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			handle(c)
+			c.Close()
+		}
+	}()
+	return l.Addr().String()
+}
+
+func clamdHandle(c net.Conn) {
+	r := bufio.NewReader(c)
+	cmd, err := r.ReadString(0)
+	if err != nil || cmd != "zINSTREAM\x00" {
+		c.Write([]byte("UNKNOWN COMMAND\x00"))
+		return
+	}
+	var b []byte
+	size := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, size); err != nil {
+			return
+		}
+		n := binary.BigEndian.Uint32(size)
+		if n == 0 {
+			break
+		}
+		chunk := make([]byte, n)
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return
+		}
+		b = append(b, chunk...)
+	}
+	if bytes.Contains(b, eicar) {
+		c.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+		return
+	}
+	c.Write([]byte("stream: OK\x00"))
+}
+
+func icapHandle(c net.Conn) {
+	// Read the request up to the last chunk of the encapsulated body
+	r := bufio.NewReader(c)
+	var b []byte
+	for !bytes.HasSuffix(b, []byte("\r\n0\r\n\r\n")) {
+		ch, err := r.ReadByte()
+		if err != nil {
+			return
+		}
+		b = append(b, ch)
+	}
+	if bytes.Contains(b, eicar) {
+		c.Write([]byte("ICAP/1.0 200 OK\r\n" +
+			"X-Infection-Found: Type=0; Resolution=2; Threat=EICAR-Test;\r\n" +
+			"Encapsulated: null-body=0\r\n\r\n"))
+		return
+	}
+	c.Write([]byte("ICAP/1.0 204 No Content\r\n\r\n"))
+}
+
+func TestScan(t *testing.T) {
+	clamdURL := "clamd://" + serve(t, clamdHandle)
+	icapURL := "icap://" + serve(t, icapHandle) + "/avscan"
+
+	var tests = []struct {
+		name      string
+		url       string
+		file      []byte
+		signature string // Empty when the file is clean
+	}{
+		{"clamd clean", clamdURL, []byte("clean"), ""},
+		{"clamd infected", clamdURL, eicar, "Eicar-Test-Signature"},
+		{"clamd large", clamdURL, make([]byte, 3*clamdChunkSize), ""},
+		{"icap clean", icapURL, []byte("clean"), ""},
+		{"icap infected", icapURL, eicar, "EICAR-Test"},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			s, err := New(v.url, 5*time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := s.Scan(context.Background(), "index.md", v.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Infected != (v.signature != "") {
+				t.Fatalf("got infected %v, want %v", r.Infected,
+					v.signature != "")
+			}
+			if r.Signature != v.signature {
+				t.Fatalf("got signature %v, want %v", r.Signature, v.signature)
+			}
+		})
+	}
+
+	_, err := New("http://127.0.0.1:3310", time.Second)
+	if err == nil {
+		t.Fatalf("New: want error for unsupported scheme")
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package sessions
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
+	"github.com/google/uuid"
+)
+
+const (
+	// apiKeySecretSize is the size of the random secret of an API key.
+	apiKeySecretSize = 32
+
+	// apiKeySeparator separates the user ID from the secret in an API
+	// key. The user ID allows the key to be verified without a lookup
+	// by key.
+	apiKeySeparator = "."
+)
+
+// NewAPIKey returns a new API key for a bot account along with the hash that
+// is stored in the user database. An API key uses the format
+// <userID>.<hex encoded secret>.
+func NewAPIKey(userID uuid.UUID) (string, []byte, error) {
+	secret, err := util.Random(apiKeySecretSize)
+	if err != nil {
+		return "", nil, err
+	}
+	key := userID.String() + apiKeySeparator + hex.EncodeToString(secret)
+	return key, apiKeyHash(secret), nil
+}
+
+// apiKeyHash returns the SHA256 hash of an API key secret.
+func apiKeyHash(secret []byte) []byte {
+	h := sha256.Sum256(secret)
+	return h[:]
+}
+
+// isAPIKeyRequest returns whether the request authenticates using an API key.
+// The session cookie of a request that contains an API key is ignored.
+func isAPIKeyRequest(r *http.Request) bool {
+	return r.Header.Get(www.APIKey) != ""
+}
+
+// apiKeyUser returns the bot user that the API key of the request belongs to.
+// A ErrSessionNotFound error is returned if the API key is invalid or has
+// been revoked, or if the bot account has been deactivated.
+func (s *Sessions) apiKeyUser(r *http.Request) (*user.User, error) {
+	key := r.Header.Get(www.APIKey)
+	parts := strings.SplitN(key, apiKeySeparator, 2)
+	if len(parts) != 2 {
+		log.Debugf("API key malformed")
+		return nil, ErrSessionNotFound
+	}
+	userID, err := uuid.Parse(parts[0])
+	if err != nil {
+		log.Debugf("API key user ID invalid")
+		return nil, ErrSessionNotFound
+	}
+	secret, err := hex.DecodeString(parts[1])
+	if err != nil || len(secret) != apiKeySecretSize {
+		log.Debugf("API key secret invalid")
+		return nil, ErrSessionNotFound
+	}
+
+	u, err := s.userdb.UserGetById(userID)
+	if err != nil {
+		if err == user.ErrUserNotFound {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	switch {
+	case !u.Bot || u.APIKeyHash == nil:
+		log.Debugf("API key user %v is not a bot", u.ID)
+		return nil, ErrSessionNotFound
+	case subtle.ConstantTimeCompare(u.APIKeyHash, apiKeyHash(secret)) != 1:
+		log.Debugf("API key for bot %v does not match", u.ID)
+		return nil, ErrSessionNotFound
+	case u.Deactivated:
+		log.Debugf("Bot %v has been deactivated", u.ID)
+		return nil, ErrSessionNotFound
+	}
+
+	log.Debugf("API key found for bot %v", u.ID)
+
+	return u, nil
+}
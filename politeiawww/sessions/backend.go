@@ -0,0 +1,40 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package sessions
+
+import (
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/google/uuid"
+)
+
+const (
+	// StoreUserDB is the session store setting that stores the user
+	// sessions in the user database.
+	StoreUserDB = "userdb"
+
+	// StoreRedis is the session store setting that stores the user
+	// sessions in a Redis server.
+	StoreRedis = "redis"
+)
+
+// Backend is the storage backend of the session store. The user database
+// satisfies this interface and is used by default.
+type Backend interface {
+	// SessionSave saves the given session to the backend. New sessions
+	// are inserted and existing sessions are updated.
+	SessionSave(user.Session) error
+
+	// SessionGetByID returns a session given its id. A
+	// user.ErrSessionNotFound error is returned if the session does not
+	// exist.
+	SessionGetByID(sessionID string) (*user.Session, error)
+
+	// SessionDeleteByID deletes the session with the given id.
+	SessionDeleteByID(sessionID string) error
+
+	// SessionsDeleteByUserID deletes all sessions for the given user ID,
+	// except the session IDs in exemptSessionIDs.
+	SessionsDeleteByUserID(id uuid.UUID, exemptSessionIDs []string) error
+}
@@ -0,0 +1,544 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package sessions
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
+	"github.com/google/uuid"
+	"github.com/marcopeereboom/sbox"
+)
+
+var (
+	_ Backend = (*Redis)(nil)
+)
+
+const (
+	// redisKeyPrefix is the prefix of all keys that politeiawww stores
+	// in Redis.
+	redisKeyPrefix = "politeiawww:"
+
+	// redisTimeout is the maximum duration of a single Redis command,
+	// including the time it takes to connect.
+	redisTimeout = 5 * time.Second
+
+	// redisConnsMax is the maximum number of idle connections that are
+	// kept open to the Redis server.
+	redisConnsMax = 16
+
+	// redisLockTTL is the duration after which the instance lock expires
+	// when it is not refreshed by the instance that holds it.
+	redisLockTTL = 30 * time.Second
+
+	// redisLockRefresh refreshes the TTL of the instance lock if it is
+	// held by the instance ID that is provided as the argument.
+	redisLockRefresh = `if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`
+
+	// redisLockRelease deletes the instance lock if it is held by the
+	// instance ID that is provided as the argument.
+	redisLockRelease = `if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`
+)
+
+// redisError is an error reply from the Redis server.
+type redisError string
+
+// Error satisfies the error interface.
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// redisConn is a connection to the Redis server.
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+// Redis is a session store backend that stores the user sessions in a Redis
+// server. It allows multiple politeiawww instances to share the user sessions
+// so that a standby instance can take over from the active instance without
+// logging out the users.
+//
+// politeiawww keeps state that is not shared between instances, e.g. the
+// rate limits, the ACL bans, the idempotency keys and the JSON data files.
+// Only one instance may therefore serve requests at a time. The active
+// instance holds the instance lock in Redis. See LockInstance. The nonces of
+// signed requests are stored in Redis so that a request can not be replayed
+// after a standby instance has taken over.
+//
+// The sessions are encrypted before they are sent to Redis using a key that
+// is derived from the cookie key. All instances must use the same cookie key.
+// The sessions expire in Redis once they have reached their max age. The IDs
+// of the sessions of a user are kept in a set so that all sessions of a user
+// can be deleted, e.g. on a password change. Set members of sessions that
+// have expired are removed when the sessions of the user are deleted.
+type Redis struct {
+	dial     func() (net.Conn, error)
+	tls      *tls.Config
+	password string
+	db       int
+	key      *[32]byte
+	conns    chan *redisConn
+
+	// The following fields are used by the instance lock.
+	lockTTL    time.Duration
+	instanceID string
+	unlock     chan struct{}
+}
+
+// RedisOpts contains the optional settings of the Redis session store.
+type RedisOpts struct {
+	Password string
+	DB       int
+
+	// TLS is the TLS configuration of the connections to the Redis
+	// server. The connections are not encrypted when it is nil.
+	TLS *tls.Config
+}
+
+// redisEncryptionKey derives the session encryption key from the cookie key.
+func redisEncryptionKey(cookieKey []byte) *[32]byte {
+	h := hmac.New(sha256.New, cookieKey)
+	h.Write([]byte("politeiawww redis session store"))
+	var key [32]byte
+	copy(key[:], h.Sum(nil))
+	return &key
+}
+
+// sessionKey returns the Redis key of a session.
+func sessionKey(sessionID string) string {
+	return redisKeyPrefix + "session:" + sessionID
+}
+
+// userSessionsKey returns the Redis key of the set that contains the session
+// IDs of a user.
+func userSessionsKey(userID uuid.UUID) string {
+	return redisKeyPrefix + "usersessions:" + userID.String()
+}
+
+// nonceKey returns the Redis key of a used nonce.
+func nonceKey(nonce string) string {
+	return redisKeyPrefix + "nonce:" + nonce
+}
+
+// lockKey returns the Redis key of the instance lock.
+func lockKey() string {
+	return redisKeyPrefix + "instancelock"
+}
+
+// connect opens a new connection to the Redis server and authenticates it.
+// The TLS handshake is performed before any command is sent so that the
+// password is never sent in clear text when TLS is enabled.
+func (r *Redis) connect() (*redisConn, error) {
+	conn, err := r.dial()
+	if err != nil {
+		return nil, err
+	}
+	if r.tls != nil {
+		tc := tls.Client(conn, r.tls)
+		err = tc.SetDeadline(time.Now().Add(redisTimeout))
+		if err == nil {
+			err = tc.Handshake()
+		}
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tc
+	}
+	c := &redisConn{
+		Conn: conn,
+		r:    bufio.NewReader(conn),
+		w:    bufio.NewWriter(conn),
+	}
+	if r.password != "" {
+		_, err = c.do("AUTH", r.password)
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	if r.db != 0 {
+		_, err = c.do("SELECT", strconv.Itoa(r.db))
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// do executes a command using a pooled connection. Connections that return a
+// network or protocol error are closed instead of being returned to the pool.
+func (r *Redis) do(args ...string) (interface{}, error) {
+	var (
+		c   *redisConn
+		err error
+	)
+	select {
+	case c = <-r.conns:
+	default:
+		c, err = r.connect()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	reply, err := c.do(args...)
+	var re redisError
+	if err != nil && !errors.As(err, &re) {
+		c.Close()
+		return nil, err
+	}
+
+	select {
+	case r.conns <- c:
+	default:
+		c.Close()
+	}
+	return reply, err
+}
+
+// do sends a command to the Redis server and returns the reply. Commands are
+// sent as an array of bulk strings. See the Redis serialization protocol.
+func (c *redisConn) do(args ...string) (interface{}, error) {
+	err := c.SetDeadline(time.Now().Add(redisTimeout))
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(c.w, "*%d\r\n", len(args))
+	for _, v := range args {
+		fmt.Fprintf(c.w, "$%d\r\n%s\r\n", len(v), v)
+	}
+	err = c.w.Flush()
+	if err != nil {
+		return nil, err
+	}
+	return c.readReply()
+}
+
+// readReply reads a single reply from the Redis server. Bulk strings are
+// returned as byte slices, integers as int64s, and arrays as slices of
+// replies. A nil bulk string or array is returned as nil.
+func (c *redisConn) readReply() (interface{}, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis: invalid reply %q", line)
+	}
+	t, v := line[0], line[1:len(line)-2]
+	switch t {
+	case '+':
+		return v, nil
+	case '-':
+		return nil, redisError(v)
+	case ':':
+		return strconv.ParseInt(v, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		b := make([]byte, n+2)
+		_, err = io.ReadFull(c.r, b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	case '*':
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		replies := make([]interface{}, 0, n)
+		for i := 0; i < n; i++ {
+			reply, err := c.readReply()
+			if err != nil {
+				return nil, err
+			}
+			replies = append(replies, reply)
+		}
+		return replies, nil
+	}
+	return nil, fmt.Errorf("redis: invalid reply type %q", t)
+}
+
+// SessionSave saves the given session to Redis. The session expires once it
+// has reached its max age.
+//
+// This function satisfies the Backend interface.
+func (r *Redis) SessionSave(s user.Session) error {
+	ttl := s.CreatedAt + SessionMaxAge - time.Now().Unix()
+	if ttl <= 0 {
+		return r.SessionDeleteByID(s.ID)
+	}
+
+	b, err := user.EncodeSession(s)
+	if err != nil {
+		return err
+	}
+	eb, err := sbox.Encrypt(user.VersionSession, r.key, b)
+	if err != nil {
+		return err
+	}
+	_, err = r.do("SET", sessionKey(s.ID), string(eb),
+		"EX", strconv.FormatInt(ttl, 10))
+	if err != nil {
+		return err
+	}
+
+	// Add the session to the sessions of the user. The set expires
+	// once the most recent session of the user has expired.
+	k := userSessionsKey(s.UserID)
+	_, err = r.do("SADD", k, s.ID)
+	if err != nil {
+		return err
+	}
+	_, err = r.do("EXPIRE", k, strconv.Itoa(SessionMaxAge))
+	return err
+}
+
+// SessionGetByID returns the session with the given ID. A
+// user.ErrSessionNotFound error is returned if the session does not exist or
+// has expired.
+//
+// This function satisfies the Backend interface.
+func (r *Redis) SessionGetByID(sessionID string) (*user.Session, error) {
+	reply, err := r.do("GET", sessionKey(sessionID))
+	if err != nil {
+		return nil, err
+	}
+	eb, ok := reply.([]byte)
+	if !ok {
+		return nil, user.ErrSessionNotFound
+	}
+	b, _, err := sbox.Decrypt(r.key, eb)
+	if err != nil {
+		return nil, err
+	}
+	return user.DecodeSession(b)
+}
+
+// SessionDeleteByID deletes the session with the given ID. The session ID is
+// not removed from the sessions of the user. It is removed when the sessions
+// of the user are deleted or when the set expires.
+//
+// This function satisfies the Backend interface.
+func (r *Redis) SessionDeleteByID(sessionID string) error {
+	_, err := r.do("DEL", sessionKey(sessionID))
+	return err
+}
+
+// SessionsDeleteByUserID deletes all sessions of the given user except the
+// provided session IDs.
+//
+// This function satisfies the Backend interface.
+func (r *Redis) SessionsDeleteByUserID(userID uuid.UUID, exemptSessionIDs []string) error {
+	exempt := make(map[string]struct{}, len(exemptSessionIDs))
+	for _, v := range exemptSessionIDs {
+		exempt[v] = struct{}{}
+	}
+
+	k := userSessionsKey(userID)
+	reply, err := r.do("SMEMBERS", k)
+	if err != nil {
+		return err
+	}
+	members, _ := reply.([]interface{})
+	for _, v := range members {
+		b, ok := v.([]byte)
+		if !ok {
+			continue
+		}
+		sessionID := string(b)
+		if _, ok := exempt[sessionID]; ok {
+			continue
+		}
+		err = r.SessionDeleteByID(sessionID)
+		if err != nil {
+			return err
+		}
+		_, err = r.do("SREM", k, sessionID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NonceAdd records a nonce of a signed request as used until the provided
+// unix expiry. False is returned if the nonce has already been used.
+//
+// This function satisfies the nonces Store interface.
+func (r *Redis) NonceAdd(nonce string, expiry int64) (bool, error) {
+	ttl := expiry - time.Now().Unix()
+	if ttl <= 0 {
+		// The request has already expired. The nonce will be rejected
+		// by the expiry check, so it does not need to be recorded.
+		ttl = 1
+	}
+	reply, err := r.do("SET", nonceKey(nonce), "1", "NX",
+		"EX", strconv.FormatInt(ttl, 10))
+	if err != nil {
+		return false, err
+	}
+	// A nil reply is returned when the key already exists
+	return reply != nil, nil
+}
+
+// NonceDel deletes a nonce so that it can be used again.
+//
+// This function satisfies the nonces Store interface.
+func (r *Redis) NonceDel(nonce string) error {
+	_, err := r.do("DEL", nonceKey(nonce))
+	return err
+}
+
+// LockInstance acquires the instance lock, which must be held by the
+// politeiawww instance that serves requests. It blocks until the lock has
+// been acquired, i.e. a standby instance waits until the active instance has
+// released the lock or until the lock has expired.
+//
+// The lock is refreshed until UnlockInstance is called. The returned channel
+// is closed when the lock has been lost, e.g. because the Redis server could
+// not be reached for longer than the lock TTL. The instance must stop serving
+// requests when this happens since a standby instance may take over.
+func (r *Redis) LockInstance() (<-chan struct{}, error) {
+	b, err := util.Random(8)
+	if err != nil {
+		return nil, err
+	}
+	hostname, _ := os.Hostname()
+	r.instanceID = hostname + " " + hex.EncodeToString(b)
+
+	ttl := strconv.FormatInt(r.lockTTL.Milliseconds(), 10)
+	var waiting bool
+	for {
+		reply, err := r.do("SET", lockKey(), r.instanceID, "NX", "PX", ttl)
+		if err != nil {
+			return nil, err
+		}
+		if reply != nil {
+			break
+		}
+		if !waiting {
+			waiting = true
+			reply, _ := r.do("GET", lockKey())
+			holder, _ := reply.([]byte)
+			log.Infof("Waiting for the instance lock held by: %s", holder)
+		}
+		time.Sleep(r.lockTTL / 3)
+	}
+	log.Infof("Instance lock acquired: %v", r.instanceID)
+
+	lost := make(chan struct{})
+	r.unlock = make(chan struct{})
+	go r.refreshLock(lost)
+
+	return lost, nil
+}
+
+// refreshLock refreshes the instance lock until it is released. The lost
+// channel is closed when the lock could not be refreshed before it expired
+// or when it is held by another instance.
+func (r *Redis) refreshLock(lost chan struct{}) {
+	var (
+		ticker    = time.NewTicker(r.lockTTL / 3)
+		refreshed = time.Now()
+		ttl       = strconv.FormatInt(r.lockTTL.Milliseconds(), 10)
+	)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.unlock:
+			return
+		case <-ticker.C:
+		}
+		reply, err := r.do("EVAL", redisLockRefresh, "1", lockKey(),
+			r.instanceID, ttl)
+		switch {
+		case err != nil && time.Since(refreshed) < r.lockTTL:
+			log.Errorf("Refresh instance lock: %v", err)
+			continue
+		case err != nil:
+			log.Errorf("Instance lock lost: %v", err)
+		case reply != int64(1):
+			log.Errorf("Instance lock lost: lock is not held")
+		default:
+			refreshed = time.Now()
+			continue
+		}
+		close(lost)
+		return
+	}
+}
+
+// UnlockInstance releases the instance lock so that a standby instance can
+// take over.
+func (r *Redis) UnlockInstance() error {
+	if r.unlock == nil {
+		return nil
+	}
+	close(r.unlock)
+	_, err := r.do("EVAL", redisLockRelease, "1", lockKey(), r.instanceID)
+	return err
+}
+
+// NewRedis returns a new Redis session store backend. The session encryption
+// key is derived from the cookie key. An error is returned if the Redis server
+// can not be reached.
+func NewRedis(addr string, opts RedisOpts, cookieKey []byte) (*Redis, error) {
+	dial := func() (net.Conn, error) {
+		return net.DialTimeout("tcp", addr, redisTimeout)
+	}
+	return newRedis(dial, opts, cookieKey)
+}
+
+// newRedis returns a new Redis session store backend that uses the provided
+// function to open the connections to the Redis server.
+func newRedis(dial func() (net.Conn, error), opts RedisOpts, cookieKey []byte) (*Redis, error) {
+	r := Redis{
+		dial:     dial,
+		tls:      opts.TLS,
+		password: opts.Password,
+		db:       opts.DB,
+		key:      redisEncryptionKey(cookieKey),
+		conns:    make(chan *redisConn, redisConnsMax),
+		lockTTL:  redisLockTTL,
+	}
+	_, err := r.do("PING")
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
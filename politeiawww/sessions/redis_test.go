@@ -0,0 +1,455 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package sessions
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/google/uuid"
+)
+
+// fakeRedis is an in-memory Redis server that implements the commands that
+// are used by the Redis session store. The connections to the server are
+// made using net.Pipe.
+type fakeRedis struct {
+	sync.Mutex
+	password string
+	tls      *tls.Config // Server TLS config; TLS is disabled when nil
+	keys     map[string]string
+	sets     map[string]map[string]struct{}
+	cmds     []string // Names of the commands that were received
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{
+		keys: make(map[string]string),
+		sets: make(map[string]map[string]struct{}),
+	}
+}
+
+// dial opens a connection to the fake server.
+func (f *fakeRedis) dial() (net.Conn, error) {
+	client, server := net.Pipe()
+	go f.serve(server)
+	return client, nil
+}
+
+// serve reads the commands of a connection and writes the replies.
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	if f.tls != nil {
+		conn = tls.Server(conn, f.tls)
+	}
+	var (
+		r    = bufio.NewReader(conn)
+		auth = f.password == ""
+	)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(args[0])
+		var reply string
+		switch {
+		case cmd == "AUTH":
+			if args[1] != f.password {
+				reply = "-WRONGPASS invalid password\r\n"
+				break
+			}
+			auth = true
+			reply = "+OK\r\n"
+		case !auth:
+			reply = "-NOAUTH Authentication required\r\n"
+		default:
+			reply = f.exec(cmd, args[1:])
+		}
+		_, err = io.WriteString(conn, reply)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// readCommand reads a command that was sent as an array of bulk strings.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil || line[0] != '*' {
+		return nil, fmt.Errorf("invalid command %q", line)
+	}
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		line, err = r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		b := make([]byte, size+2)
+		_, err = io.ReadFull(r, b)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, string(b[:size]))
+	}
+	return args, nil
+}
+
+func bulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+// exec executes a command and returns the encoded reply. Key expiries are
+// ignored.
+func (f *fakeRedis) exec(cmd string, args []string) string {
+	f.Lock()
+	defer f.Unlock()
+
+	f.cmds = append(f.cmds, cmd)
+	switch cmd {
+	case "PING":
+		return "+PONG\r\n"
+	case "SELECT", "EXPIRE":
+		return "+OK\r\n"
+	case "SET":
+		for _, v := range args[2:] {
+			if v == "NX" {
+				if _, ok := f.keys[args[0]]; ok {
+					return "$-1\r\n"
+				}
+			}
+		}
+		f.keys[args[0]] = args[1]
+		return "+OK\r\n"
+	case "GET":
+		v, ok := f.keys[args[0]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return bulk(v)
+	case "DEL":
+		_, ok := f.keys[args[0]]
+		delete(f.keys, args[0])
+		if ok {
+			return ":1\r\n"
+		}
+		return ":0\r\n"
+	case "SADD":
+		if f.sets[args[0]] == nil {
+			f.sets[args[0]] = make(map[string]struct{})
+		}
+		f.sets[args[0]][args[1]] = struct{}{}
+		return ":1\r\n"
+	case "SREM":
+		delete(f.sets[args[0]], args[1])
+		return ":1\r\n"
+	case "SMEMBERS":
+		reply := fmt.Sprintf("*%d\r\n", len(f.sets[args[0]]))
+		for k := range f.sets[args[0]] {
+			reply += bulk(k)
+		}
+		return reply
+	case "EVAL":
+		// The lock scripts only modify the key when it holds the
+		// provided value.
+		key, value := args[2], args[3]
+		if f.keys[key] != value {
+			return ":0\r\n"
+		}
+		if args[0] == redisLockRelease {
+			delete(f.keys, key)
+		}
+		return ":1\r\n"
+	}
+	return fmt.Sprintf("-ERR unknown command '%v'\r\n", cmd)
+}
+
+// commands returns the names of the commands that have been received.
+func (f *fakeRedis) commands() []string {
+	f.Lock()
+	defer f.Unlock()
+	return append([]string{}, f.cmds...)
+}
+
+// delete deletes a key.
+func (f *fakeRedis) delete(key string) {
+	f.Lock()
+	defer f.Unlock()
+	delete(f.keys, key)
+}
+
+func newTestRedis(t *testing.T, f *fakeRedis, opts RedisOpts) *Redis {
+	t.Helper()
+
+	r, err := newRedis(f.dial, opts, []byte("cookiekey"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestRedisSessions(t *testing.T) {
+	f := newFakeRedis()
+	r := newTestRedis(t, f, RedisOpts{})
+
+	var (
+		userID = uuid.New()
+		now    = time.Now().Unix()
+	)
+	for _, id := range []string{"s1", "s2", "s3"} {
+		err := r.SessionSave(user.Session{
+			ID:        id,
+			UserID:    userID,
+			CreatedAt: now,
+			Values:    "values-" + id,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The sessions are encrypted
+	f.Lock()
+	stored := f.keys[sessionKey("s1")]
+	f.Unlock()
+	if stored == "" || strings.Contains(stored, "values-s1") {
+		t.Fatalf("session not encrypted: %q", stored)
+	}
+
+	s, err := r.SessionGetByID("s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ID != "s1" || s.UserID != userID || s.Values != "values-s1" {
+		t.Fatalf("got session %+v", s)
+	}
+
+	// A session that was encrypted with a different cookie key can not
+	// be decrypted.
+	other, err := newRedis(f.dial, RedisOpts{}, []byte("otherkey"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = other.SessionGetByID("s1")
+	if err == nil {
+		t.Fatalf("session decrypted using the wrong key")
+	}
+
+	// Delete all sessions of the user except s2
+	err = r.SessionsDeleteByUserID(userID, []string{"s2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []struct {
+		id  string
+		err error
+	}{
+		{"s1", user.ErrSessionNotFound},
+		{"s2", nil},
+		{"s3", user.ErrSessionNotFound},
+	} {
+		_, err := r.SessionGetByID(v.id)
+		if !errors.Is(err, v.err) {
+			t.Fatalf("session %v: got err %v, want %v", v.id, err, v.err)
+		}
+	}
+
+	// Saving an expired session deletes it
+	err = r.SessionSave(user.Session{
+		ID:        "s2",
+		UserID:    userID,
+		CreatedAt: now - SessionMaxAge,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.SessionGetByID("s2")
+	if !errors.Is(err, user.ErrSessionNotFound) {
+		t.Fatalf("got err %v, want %v", err, user.ErrSessionNotFound)
+	}
+}
+
+func TestRedisConnect(t *testing.T) {
+	f := newFakeRedis()
+	f.password = "password"
+
+	// The wrong password is rejected
+	_, err := newRedis(f.dial, RedisOpts{Password: "wrong"},
+		[]byte("cookiekey"))
+	var re redisError
+	if !errors.As(err, &re) {
+		t.Fatalf("got err %v, want a redis error", err)
+	}
+
+	// The connections are authenticated and the database is selected
+	// before any other command is sent.
+	f = newFakeRedis()
+	f.password = "password"
+	r := newTestRedis(t, f, RedisOpts{Password: "password", DB: 2})
+	cmds := f.commands()
+	if len(cmds) != 2 || cmds[0] != "SELECT" || cmds[1] != "PING" {
+		t.Fatalf("got commands %v", cmds)
+	}
+
+	// Error replies do not close the connection
+	_, err = r.do("UNKNOWN")
+	if !errors.As(err, &re) {
+		t.Fatalf("got err %v, want a redis error", err)
+	}
+	_, err = r.do("PING")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(f.commands()); got != 4 {
+		t.Fatalf("got %v commands, want 4; the connection was reopened",
+			got)
+	}
+}
+
+func TestRedisTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	f := newFakeRedis()
+	f.password = "password"
+	f.tls = srv.TLS
+	clientTLS := srv.Client().Transport.(*http.Transport).TLSClientConfig
+	clientTLS.ServerName = "example.com"
+
+	r := newTestRedis(t, f, RedisOpts{
+		Password: "password",
+		TLS:      clientTLS,
+	})
+	_, err := r.do("PING")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A client that does not trust the server certificate does not
+	// send the password.
+	f = newFakeRedis()
+	f.password = "password"
+	f.tls = srv.TLS
+	_, err = newRedis(f.dial, RedisOpts{
+		Password: "password",
+		TLS:      &tls.Config{ServerName: "example.com"},
+	}, []byte("cookiekey"))
+	if err == nil {
+		t.Fatalf("untrusted server certificate was accepted")
+	}
+	if cmds := f.commands(); len(cmds) != 0 {
+		t.Fatalf("got commands %v", cmds)
+	}
+}
+
+func TestRedisNonces(t *testing.T) {
+	f := newFakeRedis()
+	r := newTestRedis(t, f, RedisOpts{})
+
+	expiry := time.Now().Unix() + 60
+	ok, err := r.NonceAdd("nonce", expiry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatalf("new nonce was rejected")
+	}
+
+	// The nonce is rejected by all instances that share the store
+	other := newTestRedis(t, f, RedisOpts{})
+	ok, err = other.NonceAdd("nonce", expiry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatalf("used nonce was accepted")
+	}
+
+	// A deleted nonce can be used again
+	err = r.NonceDel("nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err = other.NonceAdd("nonce", expiry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatalf("deleted nonce was rejected")
+	}
+}
+
+func TestRedisInstanceLock(t *testing.T) {
+	f := newFakeRedis()
+	active := newTestRedis(t, f, RedisOpts{})
+	standby := newTestRedis(t, f, RedisOpts{})
+	active.lockTTL = 30 * time.Millisecond
+	standby.lockTTL = 30 * time.Millisecond
+
+	_, err := active.LockInstance()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The standby instance waits until the active instance has
+	// released the lock.
+	acquired := make(chan (<-chan struct{}))
+	go func() {
+		lost, err := standby.LockInstance()
+		if err != nil {
+			t.Error(err)
+		}
+		acquired <- lost
+	}()
+	select {
+	case <-acquired:
+		t.Fatalf("lock acquired while held by the active instance")
+	case <-time.After(100 * time.Millisecond):
+	}
+	err = active.UnlockInstance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lost <-chan struct{}
+	select {
+	case lost = <-acquired:
+	case <-time.After(time.Second):
+		t.Fatalf("lock not acquired after it was released")
+	}
+
+	// The lock is refreshed while it is held
+	select {
+	case <-lost:
+		t.Fatalf("lock lost while held")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// The lost channel is closed when the lock is no longer held
+	f.delete(lockKey())
+	select {
+	case <-lost:
+	case <-time.After(time.Second):
+		t.Fatalf("lost lock was not detected")
+	}
+}
@@ -7,6 +7,7 @@ package sessions
 import (
 	"errors"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	www "github.com/decred/politeia/politeiawww/api/www/v1"
@@ -24,6 +25,11 @@ const (
 	// keys for the politeiawww specific values.
 	sessionValueUserID    = "user_id"
 	sessionValueCreatedAt = "created_at"
+
+	// pingSessionID is the session ID that is looked up to verify that
+	// the session store backend is reachable. It can never match a real
+	// session ID since those are base32 encoded.
+	pingSessionID = "ping"
 )
 
 var (
@@ -34,8 +40,43 @@ var (
 
 // Sessions manages politeiawww sessions.
 type Sessions struct {
-	store  sessions.Store
-	userdb user.Database
+	store   sessions.Store
+	backend Backend
+	userdb  user.Database
+
+	// The following fields are session store stats and must be
+	// accessed atomically.
+	created uint64
+	deleted uint64
+	expired uint64
+	errors  uint64
+}
+
+// Stats contains the session store stats since politeiawww was started.
+type Stats struct {
+	Created uint64 // Sessions created
+	Deleted uint64 // Sessions deleted, e.g. on logout
+	Expired uint64 // Expired sessions that were deleted
+	Errors  uint64 // Session store errors
+}
+
+// Stats returns the session store stats.
+func (s *Sessions) Stats() Stats {
+	return Stats{
+		Created: atomic.LoadUint64(&s.created),
+		Deleted: atomic.LoadUint64(&s.deleted),
+		Expired: atomic.LoadUint64(&s.expired),
+		Errors:  atomic.LoadUint64(&s.errors),
+	}
+}
+
+// countErr counts the provided error as a session store error if it is not
+// nil and returns it.
+func (s *Sessions) countErr(err error) error {
+	if err != nil {
+		atomic.AddUint64(&s.errors, 1)
+	}
+	return err
 }
 
 func sessionIsExpired(session *sessions.Session) bool {
@@ -52,15 +93,25 @@ func sessionIsExpired(session *sessions.Session) bool {
 func (s *Sessions) GetSession(r *http.Request) (*sessions.Session, error) {
 	log.Tracef("GetSession")
 
-	return s.store.Get(r, www.CookieSession)
+	session, err := s.store.Get(r, www.CookieSession)
+	return session, s.countErr(err)
 }
 
 // GetSessionUserID returns the user ID of the user for the given session. A
 // ErrSessionNotFound error is returned if a user session does not exist or
-// has expired.
+// has expired. Requests that contain an API key are authenticated using the
+// API key instead of the session.
 func (s *Sessions) GetSessionUserID(w http.ResponseWriter, r *http.Request) (string, error) {
 	log.Tracef("GetSessionUserID")
 
+	if isAPIKeyRequest(r) {
+		u, err := s.apiKeyUser(r)
+		if err != nil {
+			return "", err
+		}
+		return u.ID.String(), nil
+	}
+
 	session, err := s.GetSession(r)
 	if err != nil {
 		return "", err
@@ -80,7 +131,10 @@ func (s *Sessions) GetSessionUserID(w http.ResponseWriter, r *http.Request) (str
 	if sessionIsExpired(session) {
 		log.Debug("Session is expired")
 		session.Options.MaxAge = -1
-		s.store.Save(r, w, session)
+		err = s.countErr(s.store.Save(r, w, session))
+		if err == nil {
+			atomic.AddUint64(&s.expired, 1)
+		}
 		return "", ErrSessionNotFound
 	}
 
@@ -92,6 +146,10 @@ func (s *Sessions) GetSessionUserID(w http.ResponseWriter, r *http.Request) (str
 func (s *Sessions) GetSessionUser(w http.ResponseWriter, r *http.Request) (*user.User, error) {
 	log.Tracef("GetSessionUser")
 
+	if isAPIKeyRequest(r) {
+		return s.apiKeyUser(r)
+	}
+
 	uid, err := s.GetSessionUserID(w, r)
 	if err != nil {
 		return nil, err
@@ -138,7 +196,13 @@ func (s *Sessions) DelSession(w http.ResponseWriter, r *http.Request) error {
 	// Saving the session with a negative MaxAge will cause it to be
 	// deleted.
 	session.Options.MaxAge = -1
-	return s.store.Save(r, w, session)
+	err = s.countErr(s.store.Save(r, w, session))
+	if err != nil {
+		return err
+	}
+	atomic.AddUint64(&s.deleted, 1)
+
+	return nil
 }
 
 // NewSession creates a new session, adds it to the given http response
@@ -161,13 +225,40 @@ func (s *Sessions) NewSession(w http.ResponseWriter, r *http.Request, userID str
 	log.Debugf("Session created for user %v", userID)
 
 	// Update session in the store and update the response cookie
-	return s.store.Save(r, w, session)
+	err = s.countErr(s.store.Save(r, w, session))
+	if err != nil {
+		return err
+	}
+	atomic.AddUint64(&s.created, 1)
+
+	return nil
+}
+
+// DeleteUserSessions deletes all sessions of the given user except the
+// provided session IDs, e.g. on a password change.
+func (s *Sessions) DeleteUserSessions(userID uuid.UUID, exemptSessionIDs []string) error {
+	return s.countErr(s.backend.SessionsDeleteByUserID(userID,
+		exemptSessionIDs))
 }
 
-// New returns a new Sessions context.
-func New(userdb user.Database, keyPairs ...[]byte) *Sessions {
+// Ping verifies that the session store backend is reachable.
+func (s *Sessions) Ping() error {
+	_, err := s.backend.SessionGetByID(pingSessionID)
+	if errors.Is(err, user.ErrSessionNotFound) {
+		return nil
+	}
+	return err
+}
+
+// New returns a new Sessions context. The sessions are stored in the user
+// database if the backend is nil.
+func New(userdb user.Database, backend Backend, keyPairs ...[]byte) *Sessions {
+	if backend == nil {
+		backend = userdb
+	}
 	return &Sessions{
-		store:  newSessionStore(userdb, keyPairs...),
-		userdb: userdb,
+		store:   newSessionStore(backend, keyPairs...),
+		backend: backend,
+		userdb:  userdb,
 	}
 }
@@ -20,13 +20,14 @@ var (
 	_ sessions.Store = (*sessionStore)(nil)
 )
 
-// sessionStore is a session store backed by the user database.
+// sessionStore is a session store backed by the user database or by a
+// shared Redis server.
 //
 // sessionStore impelements the sessions.Store interface.
 type sessionStore struct {
 	Codecs  []securecookie.Codec
 	Options *sessions.Options
-	db      user.Database
+	db      Backend
 }
 
 // newSessionID returns a new session ID. A session ID is defined as a 32 byte
@@ -199,7 +200,7 @@ func (s *sessionStore) Save(r *http.Request, w http.ResponseWriter, session *ses
 // It is recommended to use an authentication key with 32 or 64 bytes.
 // The encryption key, if set, must be either 16, 24, or 32 bytes to select
 // AES-128, AES-192, or AES-256 modes.
-func newSessionStore(db user.Database, keyPairs ...[]byte) *sessionStore {
+func newSessionStore(db Backend, keyPairs ...[]byte) *sessionStore {
 	// Set the maxAge for each securecookie instance
 	codecs := securecookie.CodecsFromPairs(keyPairs...)
 	for _, codec := range codecs {
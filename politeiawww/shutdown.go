@@ -0,0 +1,107 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/mail"
+	"github.com/gorilla/websocket"
+)
+
+// wsCloseTimeout is the maximum amount of time that is spent sending the
+// close message to a websocket.
+const wsCloseTimeout = time.Second
+
+// closeWebsockets sends a going away close message to all websockets and
+// closes their connections. The websocket handlers exit once their
+// connection has been closed.
+func (p *politeiawww) closeWebsockets() {
+	p.wsMtx.RLock()
+	defer p.wsMtx.RUnlock()
+
+	msg := websocket.FormatCloseMessage(websocket.CloseGoingAway,
+		"server shutting down")
+	for _, v := range p.ws {
+		for _, wc := range v {
+			err := wc.conn.WriteControl(websocket.CloseMessage, msg,
+				time.Now().Add(wsCloseTimeout))
+			if err != nil {
+				log.Debugf("closeWebsockets %v: %v", wc, err)
+			}
+			wc.conn.Close()
+		}
+	}
+}
+
+// shutdown gracefully shuts down politeiawww. The readiness check starts
+// failing, the listeners stop accepting new connections, and the in-flight
+// requests, e.g. ballot submissions, are allowed to finish. The queued events
+// are then handled and the queued notification emails are sent. The steps
+// are aborted once the shutdown timeout has elapsed or once another signal
+// has been received. The connections to the user database and to politeiad
+// are closed by the caller once this function returns.
+func (p *politeiawww) shutdown(servers []*http.Server, mailQueue *mail.Queue, sigs chan os.Signal) {
+	atomic.StoreUint32(&p.shuttingDown, 1)
+
+	timeout := time.Duration(p.cfg.ShutdownTimeout) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	go func() {
+		select {
+		case sig := <-sigs:
+			log.Warnf("Received %v, aborting graceful shutdown", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	log.Infof("Shutting down; waiting up to %v", timeout)
+	start := time.Now()
+
+	// Stop accepting new connections and wait for the in-flight
+	// requests to finish. Websocket connections are hijacked so they
+	// are not tracked by the server and must be closed separately.
+	var wg sync.WaitGroup
+	for _, v := range servers {
+		wg.Add(1)
+		go func(srv *http.Server) {
+			defer wg.Done()
+			err := srv.Shutdown(ctx)
+			if err != nil {
+				log.Errorf("Shutdown listener %v: %v", srv.Addr, err)
+			}
+		}(v)
+	}
+	p.closeWebsockets()
+	wg.Wait()
+	log.Infof("In-flight requests finished")
+
+	// Handle the queued events. Events that are emitted from here on,
+	// e.g. by the background routines, are dropped.
+	err := p.events.Close(ctx)
+	if err != nil {
+		log.Errorf("Close events: %v", err)
+	}
+
+	// Send the queued notification emails. The emails that are not sent
+	// remain queued in the user database and are sent on the next
+	// start.
+	err = mailQueue.Stop(ctx)
+	if err != nil {
+		log.Errorf("Stop mail queue: %v", err)
+	}
+
+	// Close the idle politeiad connections
+	p.politeiad.Close()
+
+	log.Infof("Shutdown finished in %v",
+		time.Since(start).Round(time.Millisecond))
+}
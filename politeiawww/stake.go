@@ -0,0 +1,152 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
+)
+
+// ticketCommitmentAddr returns the largest commitment address of a ticket. An
+// error is returned if the ticket is not found or if it is not a ticket.
+func (p *politeiawww) ticketCommitmentAddr(ctx context.Context, ticket string) (string, error) {
+	ttr, err := p.politeiad.DcrdataTxsTrimmed(ctx, []string{ticket})
+	if err != nil {
+		return "", err
+	}
+	if len(ttr.Txs) == 0 {
+		return "", fmt.Errorf("ticket not found")
+	}
+
+	var (
+		bestAddr string  // Addr with largest commitment amount
+		bestAmt  float64 // Largest commitment amount
+	)
+	for _, vout := range ttr.Txs[0].Vout {
+		scriptPubKey := vout.ScriptPubKeyDecoded
+		switch {
+		case scriptPubKey.CommitAmt == nil:
+			// No commitment amount; continue
+		case len(scriptPubKey.Addresses) == 0:
+			// No commitment address; continue
+		case *scriptPubKey.CommitAmt > bestAmt:
+			// New largest commitment address found
+			bestAddr = scriptPubKey.Addresses[0]
+			bestAmt = *scriptPubKey.CommitAmt
+		}
+	}
+	if bestAddr == "" || bestAmt == 0.0 {
+		return "", fmt.Errorf("no largest commitment address found")
+	}
+
+	return bestAddr, nil
+}
+
+// ticketIsLive returns whether a ticket is in the ticket pool of the best
+// block.
+func (p *politeiawww) ticketIsLive(ctx context.Context, ticket string) (bool, error) {
+	bb, err := p.politeiad.DcrdataBestBlock(ctx)
+	if err != nil {
+		return false, err
+	}
+	bd, err := p.politeiad.DcrdataBlockDetails(ctx, bb.Height)
+	if err != nil {
+		return false, err
+	}
+	tp, err := p.politeiad.DcrdataTicketPool(ctx, bd.Block.Hash)
+	if err != nil {
+		return false, err
+	}
+	for _, v := range tp.Tickets {
+		if v == ticket {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// processVerifyStake verifies that the user controls a live ticket. The user
+// must sign the stake verification message using the largest commitment
+// address of the ticket. A ticket can only be used to verify a single user.
+func (p *politeiawww) processVerifyStake(ctx context.Context, vs www.VerifyStake, u *user.User) (*www.VerifyStakeReply, error) {
+	log.Tracef("processVerifyStake: %v %v", u.ID, vs.Ticket)
+
+	// Verify signature
+	addr, err := p.ticketCommitmentAddr(ctx, vs.Ticket)
+	if err != nil {
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusStakeInvalid,
+			ErrorContext: []string{err.Error()},
+		}
+	}
+	b, err := hex.DecodeString(vs.Signature)
+	if err != nil {
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusStakeInvalid,
+			ErrorContext: []string{"signature is not hex"},
+		}
+	}
+	msg := fmt.Sprintf(www.StakeVerificationMsg, u.ID.String())
+	valid, err := util.VerifyMessage(addr, msg,
+		base64.StdEncoding.EncodeToString(b), p.params)
+	if err != nil || !valid {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusInvalidSignature,
+		}
+	}
+
+	// Verify the ticket is live
+	live, err := p.ticketIsLive(ctx, vs.Ticket)
+	if err != nil {
+		return nil, err
+	}
+	if !live {
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusStakeInvalid,
+			ErrorContext: []string{"ticket is not live"},
+		}
+	}
+
+	p.stakeMtx.Lock()
+	defer p.stakeMtx.Unlock()
+
+	// Verify the ticket has not been used by a different user
+	var usedBy string
+	err = p.db.AllUsers(func(v *user.User) {
+		if v.StakeTicket == vs.Ticket && v.ID != u.ID {
+			usedBy = v.ID.String()
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if usedBy != "" {
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusStakeInvalid,
+			ErrorContext: []string{"ticket already used"},
+		}
+	}
+
+	// Update user
+	u.StakeTicket = vs.Ticket
+	u.StakeVerified = time.Now().Unix()
+	err = p.db.UserUpdate(*u)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Stake verified: %v %v", u.Username, vs.Ticket)
+
+	return &www.VerifyStakeReply{
+		StakeVerified: u.StakeVerified,
+	}, nil
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package telemetry
+
+import (
+	"fmt"
+	"regexp"
+
+	v1 "github.com/decred/politeia/politeiawww/api/telemetry/v1"
+)
+
+var (
+	// regexpName matches valid event names and client versions.
+	regexpName = regexp.MustCompile(`^[a-z0-9._-]+$`)
+)
+
+// nameIsValid returns whether the provided event name or client version is
+// valid.
+func nameIsValid(name string) bool {
+	return len(name) > 0 && len(name) <= nameLengthMax &&
+		regexpName.MatchString(name)
+}
+
+// incr increments the counter for the provided key. The key is replaced with
+// nameOther if the map already contains the maximum number of keys.
+func incr(m map[string]uint64, key string, count uint64) {
+	if _, ok := m[key]; !ok && len(m) >= namesMax {
+		key = nameOther
+	}
+	m[key] += count
+}
+
+func (t *Telemetry) processReport(rp v1.Report) (*v1.ReportReply, error) {
+	log.Tracef("processReport: %v %v", rp.Client, rp.Version)
+
+	// Verify client
+	if _, ok := t.clients[rp.Client]; !ok {
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeClientInvalid,
+			ErrorContext: rp.Client,
+		}
+	}
+	if !nameIsValid(rp.Version) {
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeInputInvalid,
+			ErrorContext: "invalid version",
+		}
+	}
+
+	// Verify events
+	if len(rp.Events) > eventsMax {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeEventsExceeded,
+			ErrorContext: fmt.Sprintf("max number of events is %v",
+				eventsMax),
+		}
+	}
+	for _, v := range rp.Events {
+		switch {
+		case v.Type != v1.EventTypeUsage && v.Type != v1.EventTypeError:
+			return nil, v1.UserErrorReply{
+				ErrorCode:    v1.ErrorCodeEventInvalid,
+				ErrorContext: fmt.Sprintf("invalid type %v", v.Type),
+			}
+		case !nameIsValid(v.Name):
+			return nil, v1.UserErrorReply{
+				ErrorCode:    v1.ErrorCodeEventInvalid,
+				ErrorContext: fmt.Sprintf("invalid name '%v'", v.Name),
+			}
+		case v.Count == 0:
+			return nil, v1.UserErrorReply{
+				ErrorCode:    v1.ErrorCodeEventInvalid,
+				ErrorContext: fmt.Sprintf("%v count is 0", v.Name),
+			}
+		}
+	}
+
+	// Aggregate the report
+	t.Lock()
+	defer t.Unlock()
+
+	cs, ok := t.stats[rp.Client]
+	if !ok {
+		cs = &v1.ClientStats{
+			Versions: make(map[string]uint64),
+			Usage:    make(map[string]uint64),
+			Errors:   make(map[string]uint64),
+		}
+		t.stats[rp.Client] = cs
+	}
+	cs.Reports++
+	incr(cs.Versions, rp.Version, 1)
+	for _, v := range rp.Events {
+		switch v.Type {
+		case v1.EventTypeUsage:
+			incr(cs.Usage, v.Name, uint64(v.Count))
+		case v1.EventTypeError:
+			incr(cs.Errors, v.Name, uint64(v.Count))
+		}
+	}
+
+	return &v1.ReportReply{}, nil
+}
+
+func (t *Telemetry) processStats() *v1.StatsReply {
+	log.Tracef("processStats")
+
+	t.Lock()
+	defer t.Unlock()
+
+	// Return a copy of the stats so that the caller can't race with
+	// new reports.
+	clients := make(map[string]v1.ClientStats, len(t.stats))
+	for k, v := range t.stats {
+		cs := v1.ClientStats{
+			Reports:  v.Reports,
+			Versions: make(map[string]uint64, len(v.Versions)),
+			Usage:    make(map[string]uint64, len(v.Usage)),
+			Errors:   make(map[string]uint64, len(v.Errors)),
+		}
+		for name, count := range v.Versions {
+			cs.Versions[name] = count
+		}
+		for name, count := range v.Usage {
+			cs.Usage[name] = count
+		}
+		for name, count := range v.Errors {
+			cs.Errors[name] = count
+		}
+		clients[k] = cs
+	}
+
+	return &v1.StatsReply{
+		Since:   t.since,
+		Clients: clients,
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package telemetry
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	v1 "github.com/decred/politeia/politeiawww/api/telemetry/v1"
+	"github.com/decred/politeia/politeiawww/config"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// eventsMax is the maximum number of events that can be included
+	// in a single report.
+	eventsMax = 100
+
+	// nameLengthMax is the maximum length of an event name and of a
+	// client version.
+	nameLengthMax = 64
+
+	// namesMax is the maximum number of distinct event names or client
+	// versions that are tracked per client. Anything beyond this limit
+	// is aggregated under nameOther so that a misbehaving client cannot
+	// grow the stats without bound.
+	namesMax = 512
+
+	// nameOther is the name that is used once namesMax is reached.
+	nameOther = "other"
+)
+
+// Telemetry is the context for the telemetry API.
+type Telemetry struct {
+	sync.Mutex
+	cfg     *config.Config
+	policy  *v1.PolicyReply
+	clients map[string]struct{} // Allowed clients
+
+	// The following fields contain the aggregated stats and are
+	// protected by the mutex.
+	since int64
+	stats map[string]*v1.ClientStats // [client]ClientStats
+}
+
+// HandlePolicy is the request handler for the telemetry v1 Policy route.
+func (t *Telemetry) HandlePolicy(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandlePolicy")
+
+	util.RespondWithJSON(w, http.StatusOK, t.policy)
+}
+
+// HandleReport is the request handler for the telemetry v1 Report route.
+func (t *Telemetry) HandleReport(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleReport")
+
+	var rp v1.Report
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&rp); err != nil {
+		respondWithError(w, r, "HandleReport: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	rr, err := t.processReport(rp)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleReport: processReport: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, rr)
+}
+
+// HandleStats is the request handler for the telemetry v1 Stats route.
+func (t *Telemetry) HandleStats(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleStats")
+
+	util.RespondWithJSON(w, http.StatusOK, t.processStats())
+}
+
+// New returns a new Telemetry context.
+func New(cfg *config.Config) *Telemetry {
+	clients := make(map[string]struct{}, len(cfg.TelemetryClients))
+	for _, v := range cfg.TelemetryClients {
+		clients[v] = struct{}{}
+	}
+	names := make([]string, 0, len(clients))
+	for k := range clients {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	return &Telemetry{
+		cfg: cfg,
+		policy: &v1.PolicyReply{
+			Clients:       names,
+			EventsMax:     eventsMax,
+			NameLengthMax: nameLengthMax,
+		},
+		clients: clients,
+		since:   time.Now().Unix(),
+		stats:   make(map[string]*v1.ClientStats),
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/decred/dcrd/chaincfg/v3"
 	"github.com/decred/politeia/politeiad/api/v1/identity"
@@ -26,6 +27,7 @@ import (
 	www "github.com/decred/politeia/politeiawww/api/www/v1"
 	"github.com/decred/politeia/politeiawww/config"
 	"github.com/decred/politeia/politeiawww/mail"
+	"github.com/decred/politeia/politeiawww/passwords"
 	"github.com/decred/politeia/politeiawww/sessions"
 	"github.com/decred/politeia/politeiawww/user"
 	"github.com/decred/politeia/politeiawww/user/localdb"
@@ -299,7 +301,7 @@ func newTestPoliteiawww(t *testing.T) (*politeiawww, func()) {
 	}
 
 	// Setup mail client
-	mailClient, err := mail.New("", "", "", "", "", false)
+	mailClient, err := mail.New("")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -310,18 +312,27 @@ func newTestPoliteiawww(t *testing.T) (*politeiawww, func()) {
 		t.Fatalf("create cookie key: %v", err)
 	}
 
+	// Setup network access control lists
+	acl, err := newACL(cfg)
+	if err != nil {
+		t.Fatalf("setup acl: %v", err)
+	}
+
 	// Setup politeiawww context
 	p := politeiawww{
 		cfg:             cfg,
 		params:          chaincfg.TestNet3Params(),
 		router:          mux.NewRouter(),
 		auth:            mux.NewRouter(),
-		sessions:        sessions.New(db, cookieKey),
+		sessions:        sessions.New(db, nil, cookieKey),
 		mail:            mailClient,
 		db:              db,
 		test:            true,
 		userEmails:      make(map[string]uuid.UUID),
 		userPaywallPool: make(map[uuid.UUID]paywallPoolMember),
+		acl:             acl,
+		resendThrottle:  newResendThrottle(time.Minute),
+		passwords:       passwords.New(www.PolicyMinPasswordLength, 0, ""),
 	}
 
 	// Setup routes
@@ -397,7 +408,7 @@ func newTestCMSwww(t *testing.T) (*politeiawww, func()) {
 	}
 
 	// Setup smtp
-	mailClient, err := mail.New("", "", "", "", "", false)
+	mailClient, err := mail.New("")
 	if err != nil {
 		t.Fatalf("setup SMTP: %v", err)
 	}
@@ -408,6 +419,12 @@ func newTestCMSwww(t *testing.T) (*politeiawww, func()) {
 		t.Fatalf("create cookie key: %v", err)
 	}
 
+	// Setup network access control lists
+	acl, err := newACL(cfg)
+	if err != nil {
+		t.Fatalf("setup acl: %v", err)
+	}
+
 	initLogRotator(filepath.Join(dataDir, "cmswww.test.log"))
 	setLogLevels("off")
 
@@ -418,11 +435,14 @@ func newTestCMSwww(t *testing.T) (*politeiawww, func()) {
 		params:          chaincfg.TestNet3Params(),
 		router:          mux.NewRouter(),
 		auth:            mux.NewRouter(),
-		sessions:        sessions.New(db, cookieKey),
+		sessions:        sessions.New(db, nil, cookieKey),
 		mail:            mailClient,
 		test:            true,
 		userEmails:      make(map[string]uuid.UUID),
 		userPaywallPool: make(map[uuid.UUID]paywallPoolMember),
+		acl:             acl,
+		resendThrottle:  newResendThrottle(time.Minute),
+		passwords:       passwords.New(www.PolicyMinPasswordLength, 0, ""),
 	}
 
 	// Setup routes
@@ -0,0 +1,322 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ticketvote
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"text/template"
+	"time"
+
+	"github.com/decred/politeia/politeiad/plugins/ticketvote"
+	v1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// finishedPollInterval is the interval at which the started votes
+	// are checked to determine if they have finished.
+	finishedPollInterval = 5 * time.Minute
+)
+
+const certificateText = `Politeia Vote Certificate
+
+Record:            {{.Token}} (version {{.Version}})
+Vote type:         {{index .VoteTypes .Type}}{{if .Parent}}
+Runoff parent:     {{.Parent}}{{end}}
+Outcome:           {{index .VoteStatuses .Status}}
+Voting period:     blocks {{.StartBlockHeight}} to {{.EndBlockHeight}}
+Start block hash:  {{.StartBlockHash}}
+
+Eligible tickets:  {{.EligibleTickets}}
+Tickets digest:    {{.EligibleTicketsDigest}}
+Votes cast:        {{.TotalVotes}}
+{{range .Results}}  {{printf "%-16s" .ID}} {{.Votes}}
+{{end}}
+Quorum:            {{.Quorum}} votes ({{.QuorumPercentage}}% of eligible tickets), {{if .QuorumMet}}met{{else}}not met{{end}}
+Pass:              {{.Pass}} approve votes ({{.PassPercentage}}% of votes cast), {{if .PassMet}}met{{else}}not met{{end}}
+{{if .AnchorTxID}}
+Anchor digest:     {{.AnchorDigest}}
+Anchor tx:         {{.AnchorTxID}}
+Anchor merkle:     {{.AnchorMerkleRoot}}
+{{else}}
+The vote details have not been anchored yet.
+{{end}}
+Generated:         {{.Generated}}
+Politeiad key:     {{.PoliteiadPubKey}}
+Server key:        {{.ServerPubKey}}
+Signature:         {{.Signature}}{{if .PrevServerPubKey}}
+Previous key:      {{.PrevServerPubKey}}
+Previous sig:      {{.PrevSignature}}{{end}}
+`
+
+var certificateTmpl = template.Must(
+	template.New("certificate").Parse(certificateText))
+
+// certificateTmplData contains the template data for the human readable
+// vote certificate.
+type certificateTmplData struct {
+	v1.VoteCertificate
+	VoteTypes     map[v1.VoteT]string
+	VoteStatuses  map[v1.VoteStatusT]string
+	Generated     string
+	Signature     string
+	PrevSignature string
+}
+
+func (t *TicketVote) processCertificate(ctx context.Context, c v1.Certificate) (*v1.CertificateReply, error) {
+	log.Tracef("processCertificate: %v", c.Token)
+
+	// Check the cache
+	t.Lock()
+	cr, ok := t.certificates[c.Token]
+	t.Unlock()
+	if ok {
+		return &cr, nil
+	}
+
+	return t.certificate(ctx, c.Token)
+}
+
+// certificate generates the vote certificate for a finished record vote.
+// Certificates are cached once the vote details have been anchored since
+// they will not change after that point.
+func (t *TicketVote) certificate(ctx context.Context, token string) (*v1.CertificateReply, error) {
+	// Verify the vote has finished
+	s, err := t.politeiad.TicketVoteSummary(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	switch s.Status {
+	case ticketvote.VoteStatusFinished, ticketvote.VoteStatusApproved,
+		ticketvote.VoteStatusRejected:
+		// Vote has finished; continue
+	default:
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeVoteStatusInvalid,
+			ErrorContext: "vote has not finished",
+		}
+	}
+
+	// Get the vote details and the vote details timestamp
+	dr, err := t.politeiad.TicketVoteDetails(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if dr.Vote == nil {
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeVoteStatusInvalid,
+			ErrorContext: "vote details not found",
+		}
+	}
+	tr, err := t.politeiad.TicketVoteTimestamps(ctx, token,
+		ticketvote.Timestamps{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit to the eligible ticket snapshot. The digest can be
+	// verified using the eligible tickets of the Details route.
+	et, err := json.Marshal(dr.Vote.EligibleTickets)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tally the votes and check them against the thresholds. This
+	// mirrors the politeiad approval calculation.
+	vs := convertSummaryToV1(*s)
+	var total, approve uint64
+	for _, v := range vs.Results {
+		total += v.Votes
+		if v.ID == v1.VoteOptionIDApprove {
+			approve = v.Votes
+		}
+	}
+	var (
+		quorumPerc = float64(vs.QuorumPercentage)
+		passPerc   = float64(vs.PassPercentage)
+		quorum     = uint64(quorumPerc / 100 * float64(vs.EligibleTickets))
+		pass       = uint64(passPerc / 100 * float64(total))
+	)
+
+	vc := v1.VoteCertificate{
+		Token:                 token,
+		Version:               dr.Vote.Params.Version,
+		Type:                  vs.Type,
+		Status:                vs.Status,
+		Parent:                dr.Vote.Params.Parent,
+		StartBlockHeight:      vs.StartBlockHeight,
+		StartBlockHash:        vs.StartBlockHash,
+		EndBlockHeight:        vs.EndBlockHeight,
+		EligibleTickets:       vs.EligibleTickets,
+		EligibleTicketsDigest: hex.EncodeToString(util.Digest(et)),
+		QuorumPercentage:      vs.QuorumPercentage,
+		PassPercentage:        vs.PassPercentage,
+		Results:               vs.Results,
+		TotalVotes:            total,
+		ApproveVotes:          approve,
+		Quorum:                quorum,
+		QuorumMet:             total >= quorum,
+		Pass:                  pass,
+		PassMet:               approve >= pass,
+		Timestamp:             time.Now().Unix(),
+		PoliteiadPubKey:       t.politeiad.PublicIdentity().String(),
+		ServerPubKey:          t.cfg.ServerIdentity.Public.String(),
+	}
+	if tr.Details != nil {
+		vc.AnchorDigest = tr.Details.Digest
+		vc.AnchorTxID = tr.Details.TxID
+		vc.AnchorMerkleRoot = tr.Details.MerkleRoot
+	}
+	prevID := t.cfg.RotationIdentity()
+	if prevID != nil {
+		vc.PrevServerPubKey = prevID.Public.String()
+	}
+
+	// Sign the certificate. The certificate is also signed using the
+	// previous signing identity during an identity rotation.
+	b, err := json.Marshal(vc)
+	if err != nil {
+		return nil, err
+	}
+	msg := hex.EncodeToString(util.Digest(b))
+	sig := t.cfg.ServerIdentity.SignMessage([]byte(msg))
+	signature := hex.EncodeToString(sig[:])
+	var prevSignature string
+	if prevID != nil {
+		s := prevID.SignMessage([]byte(msg))
+		prevSignature = hex.EncodeToString(s[:])
+	}
+
+	// Prepare the human readable certificate
+	var buf bytes.Buffer
+	err = certificateTmpl.Execute(&buf, certificateTmplData{
+		VoteCertificate: vc,
+		VoteTypes:       v1.VoteTypes,
+		VoteStatuses:    v1.VoteStatuses,
+		Generated:       time.Unix(vc.Timestamp, 0).UTC().String(),
+		Signature:       signature,
+		PrevSignature:   prevSignature,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cr := v1.CertificateReply{
+		Certificate:   vc,
+		Signature:     signature,
+		PrevSignature: prevSignature,
+		Text:          buf.String(),
+	}
+
+	// Only cache the certificate once it has been anchored. The
+	// certificate is not cached during an identity rotation so that
+	// the previous signature is no longer returned once the rotation
+	// has ended.
+	if vc.AnchorTxID != "" && prevID == nil {
+		t.Lock()
+		t.certificates[token] = cr
+		t.Unlock()
+	}
+
+	return &cr, nil
+}
+
+// startedVotes returns the tokens of all records with a started vote.
+func (t *TicketVote) startedVotes(ctx context.Context) (map[string]struct{}, error) {
+	var (
+		started = make(map[string]struct{}, 64)
+		status  = ticketvote.VoteStatuses[ticketvote.VoteStatusStarted]
+		page    uint32
+	)
+	for {
+		page++
+		ir, err := t.politeiad.TicketVoteInventory(ctx,
+			ticketvote.Inventory{
+				Status: ticketvote.VoteStatusStarted,
+				Page:   page,
+			})
+		if err != nil {
+			return nil, err
+		}
+		tokens := ir.Tokens[status]
+		for _, v := range tokens {
+			started[v] = struct{}{}
+		}
+		if len(tokens) < int(ticketvote.InventoryPageSize) {
+			break
+		}
+	}
+	return started, nil
+}
+
+// monitorFinished periodically checks the started votes. A vote certificate
+// is generated for every vote that has finished since the previous check and
+// an EventTypeFinished event is emitted. This function must be run as a go
+// routine.
+func (t *TicketVote) monitorFinished() {
+	ctx := context.Background()
+
+	// The initial set of started votes. Votes that finished prior to
+	// startup are not certified until they are requested.
+	started, err := t.startedVotes(ctx)
+	if err != nil {
+		log.Errorf("monitorFinished: startedVotes: %v", err)
+		started = make(map[string]struct{})
+	}
+
+	ticker := time.NewTicker(finishedPollInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		current, err := t.startedVotes(ctx)
+		if err != nil {
+			log.Errorf("monitorFinished: startedVotes: %v", err)
+			continue
+		}
+
+		// Any vote that is no longer in the started inventory has
+		// finished. Votes that fail to be certified because of an
+		// internal error are retried on the next check.
+		for token := range started {
+			if _, ok := current[token]; ok {
+				continue
+			}
+			cr, err := t.certificate(ctx, token)
+			if err != nil {
+				log.Errorf("monitorFinished: certificate %v: %v", token, err)
+				var ue v1.UserErrorReply
+				if !errors.As(err, &ue) {
+					current[token] = struct{}{}
+				}
+				continue
+			}
+
+			log.Infof("Vote finished %v: %v", token,
+				v1.VoteStatuses[cr.Certificate.Status])
+
+			// Add the final tally so that the tallies end with
+			// the final vote results.
+			vc := cr.Certificate
+			_, err = t.tallies.add(token, v1.Tally{
+				BlockHeight: vc.EndBlockHeight,
+				Timestamp:   vc.Timestamp,
+				Results:     vc.Results,
+			}, 0)
+			if err != nil {
+				log.Errorf("monitorFinished: add tally %v: %v", token, err)
+			}
+
+			t.events.Emit(EventTypeFinished,
+				EventFinished{
+					Certificate: *cr,
+				})
+		}
+
+		started = current
+	}
+}
@@ -15,6 +15,12 @@ const (
 
 	// EventTypeStart is emitted when a vote is started.
 	EventTypeStart = "ticketvote-start"
+
+	// EventTypeFinished is emitted when a vote has finished.
+	EventTypeFinished = "ticketvote-finished"
+
+	// EventTypeCastBallot is emitted when a ballot of votes is cast.
+	EventTypeCastBallot = "ticketvote-castballot"
 )
 
 // EventAuthorize is the event data for EventTypeAuthorize.
@@ -28,3 +34,13 @@ type EventStart struct {
 	Starts []v1.StartDetails
 	User   user.User
 }
+
+// EventFinished is the event data for EventTypeFinished.
+type EventFinished struct {
+	Certificate v1.CertificateReply
+}
+
+// EventCastBallot is the event data for EventTypeCastBallot.
+type EventCastBallot struct {
+	Token string
+}
@@ -6,10 +6,13 @@ package ticketvote
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/decred/politeia/politeiad/plugins/ticketvote"
 	v1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	"github.com/decred/politeia/politeiawww/nonces"
 	"github.com/decred/politeia/politeiawww/user"
 )
 
@@ -131,20 +134,96 @@ func (t *TicketVote) processCastBallot(ctx context.Context, cb v1.CastBallot) (*
 		break
 	}
 
+	// Verify the votes are not replays. The votes that fail the
+	// verification are not sent to politeiad.
+	var (
+		now     = time.Now()
+		votes   = make([]v1.CastVote, 0, len(cb.Votes))
+		invalid = make(map[int]v1.CastVoteReply) // [ballot index]receipt
+	)
+	for k, v := range cb.Votes {
+		err := t.nonces.Verify(v.Ticket, v.Nonce, v.Expiry,
+			t.policy.NonceRequired, now)
+		var ve nonces.VerifyError
+		switch {
+		case errors.As(err, &ve):
+			invalid[k] = v1.CastVoteReply{
+				Ticket:       v.Ticket,
+				ErrorCode:    convertNonceErrorToV1(ve.ErrorCode),
+				ErrorContext: ve.ErrorContext,
+			}
+			continue
+		case err != nil:
+			for _, v := range votes {
+				t.releaseNonce(v)
+			}
+			return nil, err
+		}
+		votes = append(votes, v)
+	}
+
 	// Send plugin command
-	tcb := ticketvote.CastBallot{
-		Ballot: convertCastVotesToPlugin(cb.Votes),
+	var receipts []v1.CastVoteReply
+	if len(votes) > 0 {
+		tcb := ticketvote.CastBallot{
+			Ballot: convertCastVotesToPlugin(votes),
+		}
+		tcbr, err := t.politeiad.TicketVoteCastBallot(ctx, token, tcb)
+		if err != nil {
+			// The votes have not been cast. Their nonces are
+			// released so that the votes can be resubmitted.
+			for _, v := range votes {
+				t.releaseNonce(v)
+			}
+			return nil, err
+		}
+		receipts = convertCastVoteRepliesToV1(tcbr.Receipts)
+
+		// Release the nonces of the votes that were rejected. A
+		// receipt without a vote error means the vote was cast.
+		for k, v := range receipts {
+			if v.ErrorCode != v1.VoteErrorInvalid && k < len(votes) {
+				t.releaseNonce(votes[k])
+			}
+		}
+
+		// Emit event
+		t.events.Emit(EventTypeCastBallot,
+			EventCastBallot{
+				Token: token,
+			})
 	}
-	tcbr, err := t.politeiad.TicketVoteCastBallot(ctx, token, tcb)
-	if err != nil {
-		return nil, err
+
+	// Return the receipts in the order of the ballot. The plugin
+	// returns the receipts in the order of the votes it was sent.
+	r := make([]v1.CastVoteReply, 0, len(cb.Votes))
+	for k := range cb.Votes {
+		if cvr, ok := invalid[k]; ok {
+			r = append(r, cvr)
+			continue
+		}
+		if len(receipts) == 0 {
+			break
+		}
+		r = append(r, receipts[0])
+		receipts = receipts[1:]
 	}
 
 	return &v1.CastBallotReply{
-		Receipts: convertCastVoteRepliesToV1(tcbr.Receipts),
+		Receipts: r,
 	}, nil
 }
 
+// releaseNonce releases the nonce of a vote that has not been cast so that
+// the vote can be resubmitted. Errors are logged since the vote has been
+// rejected regardless.
+func (t *TicketVote) releaseNonce(v v1.CastVote) {
+	err := t.nonces.Release(v.Ticket, v.Nonce)
+	if err != nil {
+		log.Errorf("Release nonce %v: %v", v.Ticket, err)
+	}
+}
+
 func (t *TicketVote) processDetails(ctx context.Context, d v1.Details) (*v1.DetailsReply, error) {
 	log.Tracef("processsDetails: %v", d.Token)
 
@@ -350,6 +429,10 @@ func convertCastVotesToPlugin(votes []v1.CastVote) []ticketvote.CastVote {
 			Ticket:    v.Ticket,
 			VoteBit:   v.VoteBit,
 			Signature: v.Signature,
+			ReplayProtection: ticketvote.ReplayProtection{
+				Nonce:  v.Nonce,
+				Expiry: v.Expiry,
+			},
 		})
 	}
 	return cv
@@ -410,6 +493,16 @@ func convertVoteErrorToV1(e ticketvote.VoteErrorT) v1.VoteErrorT {
 	}
 }
 
+func convertNonceErrorToV1(e nonces.ErrorCodeT) v1.VoteErrorT {
+	switch e {
+	case nonces.ErrorCodeExpired:
+		return v1.VoteErrorNonceExpired
+	case nonces.ErrorCodeUsed:
+		return v1.VoteErrorNonceUsed
+	}
+	return v1.VoteErrorNonceInvalid
+}
+
 func convertCastVoteRepliesToV1(replies []ticketvote.CastVoteReply) []v1.CastVoteReply {
 	r := make([]v1.CastVoteReply, 0, len(replies))
 	for _, v := range replies {
@@ -463,6 +556,10 @@ func convertCastVoteDetailsToV1(votes []ticketvote.CastVoteDetails) []v1.CastVot
 			Signature: v.Signature,
 			Receipt:   v.Receipt,
 			Timestamp: v.Timestamp,
+			ReplayProtection: v1.ReplayProtection{
+				Nonce:  v.Nonce,
+				Expiry: v.Expiry,
+			},
 		})
 	}
 	return vs
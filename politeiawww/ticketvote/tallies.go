@@ -0,0 +1,181 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ticketvote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/decred/politeia/politeiad/plugins/ticketvote"
+	v1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+)
+
+const (
+	// talliesFilename is the name of the file in the data directory
+	// that the vote tallies are persisted to.
+	talliesFilename = "votetallies.json"
+
+	// tallyPollInterval is the interval at which the vote summaries of
+	// the started votes are checked for a new tally snapshot.
+	tallyPollInterval = time.Minute
+)
+
+// voteTallies contains the vote tally snapshots of the record votes. The
+// tallies are persisted to disk on every change so that they survive a
+// restart.
+type voteTallies struct {
+	sync.Mutex
+	path    string
+	tallies map[string][]v1.Tally // [token]tallies
+}
+
+// newVoteTallies returns a new voteTallies that is loaded from the provided
+// file. The file is created on the first snapshot if it does not exist.
+func newVoteTallies(path string) (*voteTallies, error) {
+	vt := voteTallies{
+		path:    path,
+		tallies: make(map[string][]v1.Tally, 64),
+	}
+	b, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return &vt, nil
+	case err != nil:
+		return nil, err
+	}
+	err = json.Unmarshal(b, &vt.tallies)
+	if err != nil {
+		return nil, fmt.Errorf("decode %v: %v", path, err)
+	}
+	return &vt, nil
+}
+
+// save writes the tallies to disk. The file is replaced atomically.
+//
+// This function must be called WITH the lock held.
+func (vt *voteTallies) save() error {
+	b, err := json.Marshal(vt.tallies)
+	if err != nil {
+		return err
+	}
+	tmp := vt.path + ".tmp"
+	err = ioutil.WriteFile(tmp, b, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, vt.path)
+}
+
+// get returns the tallies of a record vote.
+func (vt *voteTallies) get(token string) []v1.Tally {
+	vt.Lock()
+	defer vt.Unlock()
+
+	tallies := vt.tallies[token]
+	c := make([]v1.Tally, len(tallies))
+	copy(c, tallies)
+	return c
+}
+
+// add adds a tally snapshot to a record vote. The snapshot is only added if at
+// least interval blocks have passed since the previous snapshot. An interval
+// of 0 adds the snapshot as long as it is for a later block. Returns whether
+// the snapshot was added.
+func (vt *voteTallies) add(token string, t v1.Tally, interval uint32) (bool, error) {
+	vt.Lock()
+	defer vt.Unlock()
+
+	tallies := vt.tallies[token]
+	if len(tallies) > 0 {
+		last := tallies[len(tallies)-1]
+		if t.BlockHeight <= last.BlockHeight ||
+			t.BlockHeight-last.BlockHeight < interval {
+			return false, nil
+		}
+	}
+	vt.tallies[token] = append(tallies, t)
+
+	return true, vt.save()
+}
+
+func (t *TicketVote) processTallies(ctx context.Context, tl v1.Tallies) (*v1.TalliesReply, error) {
+	log.Tracef("processTallies: %v", tl.Token)
+
+	return &v1.TalliesReply{
+		Interval: t.cfg.VoteTallyInterval,
+		Tallies:  t.tallies.get(tl.Token),
+	}, nil
+}
+
+// snapshotTallies adds a tally snapshot for every started vote that has
+// progressed at least the tally interval since its previous snapshot.
+func (t *TicketVote) snapshotTallies(ctx context.Context) error {
+	started, err := t.startedVotes(ctx)
+	if err != nil {
+		return fmt.Errorf("startedVotes: %v", err)
+	}
+	tokens := make([]string, 0, len(started))
+	for k := range started {
+		tokens = append(tokens, k)
+	}
+
+	// Get the vote summaries in batches
+	now := time.Now().Unix()
+	for len(tokens) > 0 {
+		n := int(v1.SummariesPageSize)
+		if n > len(tokens) {
+			n = len(tokens)
+		}
+		batch := tokens[:n]
+		tokens = tokens[n:]
+
+		sr, err := t.politeiad.TicketVoteSummaries(ctx, batch)
+		if err != nil {
+			return fmt.Errorf("TicketVoteSummaries: %v", err)
+		}
+		for token, s := range sr {
+			if s.Status != ticketvote.VoteStatusStarted {
+				continue
+			}
+			vs := convertSummaryToV1(s)
+			added, err := t.tallies.add(token, v1.Tally{
+				BlockHeight: vs.BestBlock,
+				Timestamp:   now,
+				Results:     vs.Results,
+			}, t.cfg.VoteTallyInterval)
+			if err != nil {
+				return fmt.Errorf("add tally %v: %v", token, err)
+			}
+			if added {
+				log.Debugf("Vote tally snapshot %v at block %v",
+					token, vs.BestBlock)
+			}
+		}
+	}
+
+	return nil
+}
+
+// monitorTallies periodically takes a snapshot of the tallies of the started
+// votes so that clients can chart the progress of a vote over its voting
+// period. This function must be run as a go routine.
+func (t *TicketVote) monitorTallies() {
+	ctx := context.Background()
+
+	ticker := time.NewTicker(tallyPollInterval)
+	defer ticker.Stop()
+	for {
+		err := t.snapshotTallies(ctx)
+		if err != nil {
+			log.Errorf("monitorTallies: %v", err)
+		}
+		<-ticker.C
+	}
+}
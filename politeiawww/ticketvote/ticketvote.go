@@ -8,7 +8,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"path/filepath"
 	"strconv"
+	"sync"
 
 	pdv2 "github.com/decred/politeia/politeiad/api/v2"
 	pdclient "github.com/decred/politeia/politeiad/client"
@@ -16,17 +18,39 @@ import (
 	v1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
 	"github.com/decred/politeia/politeiawww/config"
 	"github.com/decred/politeia/politeiawww/events"
+	"github.com/decred/politeia/politeiawww/nonces"
 	"github.com/decred/politeia/politeiawww/sessions"
 	"github.com/decred/politeia/util"
 )
 
 // TicketVote is the context for the ticketvote API.
 type TicketVote struct {
+	sync.Mutex
 	cfg       *config.Config
 	politeiad *pdclient.Client
 	sessions  *sessions.Sessions
 	events    *events.Manager
+	nonces    *nonces.Nonces
 	policy    *v1.PolicyReply
+
+	// certificates caches the vote certificates of finished votes.
+	certificates map[string]v1.CertificateReply // [token]CertificateReply
+
+	// tallies contains the vote tally snapshots of the record votes.
+	tallies *voteTallies
+}
+
+// Policy returns the ticketvote v1 policy.
+func (t *TicketVote) Policy() v1.PolicyReply {
+	return *t.policy
+}
+
+// SetNonceStore sets the store that records the nonces that have been used by
+// the cast votes. A store that is shared by all politeiawww instances must be
+// used when multiple instances serve requests. This must be set prior to the
+// TicketVote context being used.
+func (t *TicketVote) SetNonceStore(s nonces.Store) {
+	t.nonces.SetStore(s)
 }
 
 // HandlePolicy is the request handler for the ticketvote v1 Policy route.
@@ -153,8 +177,7 @@ func (t *TicketVote) HandleResults(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("HandleResults")
 
 	var rs v1.Results
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&rs); err != nil {
+	if err := util.ParseRequest(r, &rs); err != nil {
 		respondWithError(w, r, "HandleResults: unmarshal",
 			v1.UserErrorReply{
 				ErrorCode: v1.ErrorCodeInputInvalid,
@@ -169,7 +192,7 @@ func (t *TicketVote) HandleResults(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	util.RespondWithJSON(w, http.StatusOK, rsr)
+	util.RespondWithETag(w, r, rsr)
 }
 
 // HandleSummaries is the request handler for the ticketvote v1 Summaries
@@ -253,8 +276,7 @@ func (t *TicketVote) HandleTimestamps(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("HandleTimestamps")
 
 	var ts v1.Timestamps
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&ts); err != nil {
+	if err := util.ParseRequest(r, &ts); err != nil {
 		respondWithError(w, r, "HandleTimestamps: unmarshal",
 			v1.UserErrorReply{
 				ErrorCode: v1.ErrorCodeInputInvalid,
@@ -269,7 +291,56 @@ func (t *TicketVote) HandleTimestamps(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	util.RespondWithJSON(w, http.StatusOK, tsr)
+	util.RespondWithETag(w, r, tsr)
+}
+
+// HandleCertificate is the request handler for the ticketvote v1 Certificate
+// route.
+func (t *TicketVote) HandleCertificate(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleCertificate")
+
+	var c v1.Certificate
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&c); err != nil {
+		respondWithError(w, r, "HandleCertificate: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	cr, err := t.processCertificate(r.Context(), c)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleCertificate: processCertificate: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, cr)
+}
+
+// HandleTallies is the request handler for the ticketvote v1 Tallies route.
+func (t *TicketVote) HandleTallies(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleTallies")
+
+	var tl v1.Tallies
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&tl); err != nil {
+		respondWithError(w, r, "HandleTallies: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	tr, err := t.processTallies(r.Context(), tl)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleTallies: processTallies: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, tr)
 }
 
 // New returns a new TicketVote context.
@@ -334,16 +405,35 @@ func New(cfg *config.Config, pdc *pdclient.Client, s *sessions.Sessions, e *even
 			ticketvote.SettingKeyVoteDurationMax)
 	}
 
-	return &TicketVote{
+	tallies, err := newVoteTallies(filepath.Join(cfg.DataDir,
+		talliesFilename))
+	if err != nil {
+		return nil, err
+	}
+
+	t := TicketVote{
 		cfg:       cfg,
 		politeiad: pdc,
 		sessions:  s,
 		events:    e,
+		nonces:    nonces.New(v1.NonceSize, v1.NonceExpiryMax),
 		policy: &v1.PolicyReply{
 			LinkByPeriodMin: linkByPeriodMin,
 			LinkByPeriodMax: linkByPeriodMax,
 			VoteDurationMin: voteDurationMin,
 			VoteDurationMax: voteDurationMax,
+			NonceRequired:   cfg.VoteNonces,
+			NonceExpiryMax:  v1.NonceExpiryMax,
 		},
-	}, nil
+		certificates: make(map[string]v1.CertificateReply),
+		tallies:      tallies,
+	}
+
+	// Generate vote certificates as votes finish
+	go t.monitorFinished()
+
+	// Take vote tally snapshots of the started votes
+	go t.monitorTallies()
+
+	return &t, nil
 }
@@ -0,0 +1,250 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/mail"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// ntfnAll is the notification bit field that is used by the
+	// unsubscribe from all notifications link.
+	ntfnAll = ^uint64(0)
+)
+
+// unsubscribeKey derives the HMAC key that is used to sign unsubscribe
+// tokens from the CSRF key.
+func unsubscribeKey(csrfKey []byte) []byte {
+	h := hmac.New(sha256.New, csrfKey)
+	h.Write([]byte("politeiawww unsubscribe token"))
+	return h.Sum(nil)
+}
+
+// unsubscribeToken returns the HMAC-SHA256 of the email address and the
+// notification bit field. The token allows the owner of the email address to
+// disable the notifications without logging in. The token does not expire.
+func unsubscribeToken(key []byte, email string, ntfn uint64) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(strings.ToLower(email)))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.FormatUint(ntfn, 10)))
+	return h.Sum(nil)
+}
+
+// unsubscribeLink returns a signed unsubscribe link for the provided email
+// address and notification bit field. The link points to the politeiawww
+// Unsubscribe route. A GET request returns a confirmation page and a POST
+// request performs the unsubscribe.
+func (p *politeiawww) unsubscribeLink(email string, ntfn uint64) (string, error) {
+	l, err := url.Parse(p.cfg.WebServerAddress + "/api" +
+		www.PoliteiaWWWAPIRoute + www.RouteUnsubscribe)
+	if err != nil {
+		return "", err
+	}
+
+	q := l.Query()
+	q.Set("email", email)
+	q.Set("ntfn", strconv.FormatUint(ntfn, 10))
+	q.Set("token", hex.EncodeToString(unsubscribeToken(p.unsubscribeKey,
+		email, ntfn)))
+	l.RawQuery = q.Encode()
+
+	return l.String(), nil
+}
+
+// unsubscribeLinks returns the unsubscribe links that are included in the
+// notification emails. It satisfies the mail UnsubscribeFunc type.
+func (p *politeiawww) unsubscribeLinks(email string, ntfn uint64) (*mail.Unsubscribe, error) {
+	category, err := p.unsubscribeLink(email, ntfn)
+	if err != nil {
+		return nil, err
+	}
+	all, err := p.unsubscribeLink(email, ntfnAll)
+	if err != nil {
+		return nil, err
+	}
+	return &mail.Unsubscribe{
+		Category: category,
+		All:      all,
+	}, nil
+}
+
+// unsubscribeFromQuery returns the Unsubscribe request that is encoded in the
+// URL query of an unsubscribe link.
+func unsubscribeFromQuery(r *http.Request) (*www.Unsubscribe, error) {
+	q := r.URL.Query()
+	ntfn, err := strconv.ParseUint(q.Get("ntfn"), 10, 64)
+	if err != nil {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusInvalidInput,
+		}
+	}
+	return &www.Unsubscribe{
+		Email:         q.Get("email"),
+		Notifications: ntfn,
+		Token:         q.Get("token"),
+	}, nil
+}
+
+// unsubscribeConfirmTmpl is the page that is shown when an unsubscribe link
+// is opened. The unsubscribe is only performed once the user submits the form
+// so that link scanners and prefetchers do not unsubscribe the user.
+var unsubscribeConfirmTmpl = template.Must(template.New("confirm").Parse(
+	`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Unsubscribe</title></head>
+<body>
+{{- if .Done}}
+<p>{{.Email}} has been unsubscribed from {{.Category}}.</p>
+{{- else}}
+<p>Unsubscribe {{.Email}} from {{.Category}}?</p>
+<form method="post" action="?{{.Query}}">
+<input type="hidden" name="List-Unsubscribe" value="One-Click">
+<button type="submit">Unsubscribe</button>
+</form>
+{{- end}}
+</body>
+</html>
+`))
+
+// respondWithUnsubscribePage writes the unsubscribe confirmation page. The
+// page is written after the unsubscribe has been performed when done is true.
+func respondWithUnsubscribePage(w http.ResponseWriter, r *http.Request, u www.Unsubscribe, done bool) {
+	category := "these email notifications"
+	if u.Notifications == ntfnAll {
+		category = "all email notifications"
+	}
+	h := w.Header()
+	h.Set("Content-Type", "text/html; charset=utf-8")
+	h.Set("Content-Security-Policy", "default-src 'none'; form-action 'self'")
+	h.Set("Cache-Control", "no-store")
+	h.Set("X-Frame-Options", "DENY")
+	w.WriteHeader(http.StatusOK)
+	err := unsubscribeConfirmTmpl.Execute(w, struct {
+		Email    string
+		Category string
+		Query    template.URL
+		Done     bool
+	}{
+		Email:    u.Email,
+		Category: category,
+		Query:    template.URL(r.URL.RawQuery),
+		Done:     done,
+	})
+	if err != nil {
+		log.Errorf("respondWithUnsubscribePage: %v", err)
+	}
+}
+
+// handleUnsubscribeConfirm handles the GET requests of the unsubscribe links
+// that are included in notification emails. The unsubscribe token is verified
+// and a page that asks the user to confirm the unsubscribe is returned. The
+// notification settings of the user are not changed.
+func (p *politeiawww) handleUnsubscribeConfirm(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleUnsubscribeConfirm")
+
+	u, err := unsubscribeFromQuery(r)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleUnsubscribeConfirm: %v", err)
+		return
+	}
+	err = p.unsubscribeVerify(*u)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleUnsubscribeConfirm: %v", err)
+		return
+	}
+
+	respondWithUnsubscribePage(w, r, *u, false)
+}
+
+// handleUnsubscribe handles the POST requests of the unsubscribe links that
+// are included in notification emails. These are sent by mail clients that
+// support one-click unsubscribe (RFC 8058) and by the confirmation page. The
+// parameters are always read from the URL query since the POST request body
+// only contains the List-Unsubscribe field. A page is returned to browsers
+// instead of the JSON reply.
+func (p *politeiawww) handleUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleUnsubscribe")
+
+	u, err := unsubscribeFromQuery(r)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleUnsubscribe: %v", err)
+		return
+	}
+
+	reply, err := p.processUnsubscribe(*u)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleUnsubscribe: processUnsubscribe %v", err)
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "text/html") {
+		respondWithUnsubscribePage(w, r, *u, true)
+		return
+	}
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
+// unsubscribeVerify verifies the unsubscribe token of an Unsubscribe request.
+func (p *politeiawww) unsubscribeVerify(u www.Unsubscribe) error {
+	token, err := hex.DecodeString(u.Token)
+	if err != nil || !hmac.Equal(token, unsubscribeToken(p.unsubscribeKey,
+		u.Email, u.Notifications)) {
+		return www.UserError{
+			ErrorCode: www.ErrorStatusVerificationTokenInvalid,
+		}
+	}
+	return nil
+}
+
+// processUnsubscribe verifies the unsubscribe token and disables the provided
+// notifications for the user with the provided email address.
+func (p *politeiawww) processUnsubscribe(u www.Unsubscribe) (*www.UnsubscribeReply, error) {
+	log.Tracef("processUnsubscribe: %v %v", u.Email, u.Notifications)
+
+	err := p.unsubscribeVerify(u)
+	if err != nil {
+		return nil, err
+	}
+
+	usr, err := p.userByEmail(strings.ToLower(u.Email))
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return nil, www.UserError{
+				ErrorCode: www.ErrorStatusUserNotFound,
+			}
+		}
+		return nil, err
+	}
+
+	ntfns := usr.EmailNotifications &^ u.Notifications
+	if ntfns != usr.EmailNotifications {
+		usr.EmailNotifications = ntfns
+		err = p.db.UserUpdate(*usr)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("User %v unsubscribed from email notifications %v",
+			usr.ID, u.Notifications)
+	}
+
+	return &www.UnsubscribeReply{
+		EmailNotifications: usr.EmailNotifications,
+	}, nil
+}
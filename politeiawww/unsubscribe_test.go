@@ -0,0 +1,173 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+)
+
+func TestProcessUnsubscribe(t *testing.T) {
+	p, cleanup := newTestPoliteiawww(t)
+	defer cleanup()
+
+	p.unsubscribeKey = unsubscribeKey([]byte("csrf key"))
+	usr, _ := newUser(t, p, true, false)
+	usr.EmailNotifications = uint64(www.NotificationEmailCommentOnMyProposal |
+		www.NotificationEmailCommentOnMyComment)
+	err := p.db.UserUpdate(*usr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ntfn := uint64(www.NotificationEmailCommentOnMyComment)
+	token := hex.EncodeToString(unsubscribeToken(p.unsubscribeKey,
+		usr.Email, ntfn))
+	allToken := hex.EncodeToString(unsubscribeToken(p.unsubscribeKey,
+		usr.Email, ntfnAll))
+
+	var tests = []struct {
+		name  string
+		input www.Unsubscribe
+		ntfns uint64
+		want  error
+	}{
+		{
+			"wrong notifications",
+			www.Unsubscribe{
+				Email:         usr.Email,
+				Notifications: ntfnAll,
+				Token:         token,
+			},
+			0,
+			www.UserError{
+				ErrorCode: www.ErrorStatusVerificationTokenInvalid,
+			},
+		},
+		{
+			"wrong email",
+			www.Unsubscribe{
+				Email:         "x" + usr.Email,
+				Notifications: ntfn,
+				Token:         token,
+			},
+			0,
+			www.UserError{
+				ErrorCode: www.ErrorStatusVerificationTokenInvalid,
+			},
+		},
+		{
+			"category",
+			www.Unsubscribe{
+				Email:         usr.Email,
+				Notifications: ntfn,
+				Token:         token,
+			},
+			uint64(www.NotificationEmailCommentOnMyProposal),
+			nil,
+		},
+		{
+			"all",
+			www.Unsubscribe{
+				Email:         usr.Email,
+				Notifications: ntfnAll,
+				Token:         allToken,
+			},
+			0,
+			nil,
+		},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			reply, err := p.processUnsubscribe(v.input)
+			got := errToStr(err)
+			want := errToStr(v.want)
+			if got != want {
+				t.Fatalf("got error %v, want %v", got, want)
+			}
+			if err != nil {
+				return
+			}
+			if reply.EmailNotifications != v.ntfns {
+				t.Errorf("got notifications %v, want %v",
+					reply.EmailNotifications, v.ntfns)
+			}
+		})
+	}
+}
+
+func TestHandleUnsubscribe(t *testing.T) {
+	p, cleanup := newTestPoliteiawww(t)
+	defer cleanup()
+
+	p.unsubscribeKey = unsubscribeKey([]byte("csrf key"))
+	usr, _ := newUser(t, p, true, false)
+	usr.EmailNotifications = uint64(www.NotificationEmailCommentOnMyComment)
+	err := p.db.UserUpdate(*usr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := url.Values{}
+	q.Set("email", usr.Email)
+	q.Set("ntfn", strconv.FormatUint(ntfnAll, 10))
+	q.Set("token", hex.EncodeToString(unsubscribeToken(p.unsubscribeKey,
+		usr.Email, ntfnAll)))
+	link := www.PoliteiaWWWAPIRoute + www.RouteUnsubscribe + "?" + q.Encode()
+
+	// notifications returns the current notification setting of the user
+	notifications := func() uint64 {
+		t.Helper()
+		u, err := p.db.UserGetById(usr.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u.EmailNotifications
+	}
+
+	// A GET request only returns the confirmation page
+	r := httptest.NewRequest(http.MethodGet, link, nil)
+	w := httptest.NewRecorder()
+	p.handleUnsubscribeConfirm(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET: got status %v, want %v", w.Code, http.StatusOK)
+	}
+	if !strings.Contains(w.Body.String(), `<form method="post"`) {
+		t.Fatalf("GET: confirmation form not found: %v", w.Body.String())
+	}
+	if got := notifications(); got == 0 {
+		t.Fatalf("GET: user was unsubscribed")
+	}
+
+	// A GET request with an invalid token is rejected
+	r = httptest.NewRequest(http.MethodGet,
+		strings.Replace(link, "token=", "token=00", 1), nil)
+	w = httptest.NewRecorder()
+	p.handleUnsubscribeConfirm(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("GET: got status %v, want %v", w.Code,
+			http.StatusBadRequest)
+	}
+
+	// The one-click POST request unsubscribes the user
+	r = httptest.NewRequest(http.MethodPost, link,
+		strings.NewReader("List-Unsubscribe=One-Click"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	p.handleUnsubscribe(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST: got status %v, want %v", w.Code, http.StatusOK)
+	}
+	if got := notifications(); got != 0 {
+		t.Fatalf("POST: got notifications %v, want 0", got)
+	}
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/decred/politeia/politeiad/api/v1/identity"
 	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/mail"
 	"github.com/decred/politeia/politeiawww/user"
 	"github.com/decred/politeia/util"
 	"github.com/google/uuid"
@@ -98,6 +99,7 @@ func convertWWWUserFromDatabaseUser(user *user.User) www.User {
 	return www.User{
 		ID:                              user.ID.String(),
 		Admin:                           user.Admin,
+		Bot:                             user.Bot,
 		Email:                           user.Email,
 		Username:                        user.Username,
 		NewUserPaywallAddress:           user.NewUserPaywallAddress,
@@ -118,6 +120,12 @@ func convertWWWUserFromDatabaseUser(user *user.User) www.User {
 		Identities:                      convertWWWIdentitiesFromDatabaseIdentities(user.Identities),
 		ProposalCredits:                 uint64(len(user.UnspentProposalCredits)),
 		EmailNotifications:              user.EmailNotifications,
+		EmailSuppressed:                 user.EmailSuppressed,
+		EmailSuppressedReason:           user.EmailSuppressedReason,
+		TimeZone:                        user.TimeZone,
+		Locale:                          user.Locale,
+		EmailDigest:                     www.EmailDigestT(user.EmailDigest),
+		StakeVerified:                   user.StakeVerified,
 	}
 }
 
@@ -147,6 +155,7 @@ func filterUserPublicFields(user www.User) www.User {
 	return www.User{
 		ID:         user.ID,
 		Admin:      user.Admin,
+		Bot:        user.Bot,
 		Username:   user.Username,
 		Identities: user.Identities,
 	}
@@ -185,11 +194,19 @@ func validateUsername(username string) error {
 	return nil
 }
 
-// validatePassword verifies that a password adheres to required policy.
-func validatePassword(password string) error {
-	if len(password) < www.PolicyMinPasswordLength {
+// validatePassword verifies that a password adheres to the password policy.
+// The user inputs, e.g. the username and email address, are treated as
+// guessable when the strength of the password is estimated.
+//
+// Clients that hash the password before sending it must enforce the password
+// policy on the plain text password. This check only protects against
+// clients that send the password without hashing it.
+func (p *politeiawww) validatePassword(password string, userInputs ...string) error {
+	err := p.passwords.Check(password, userInputs...)
+	if err != nil {
 		return www.UserError{
-			ErrorCode: www.ErrorStatusMalformedPassword,
+			ErrorCode:    www.ErrorStatusMalformedPassword,
+			ErrorContext: []string{err.Error()},
 		}
 	}
 
@@ -389,7 +406,7 @@ func (p *politeiawww) processNewUser(nu www.NewUser) (*www.NewUserReply, error)
 	// sending it to politeiawww. This validation is only
 	// relevant if the client failed to hash the password
 	// or does not include a password in the request.
-	err = validatePassword(nu.Password)
+	err = p.validatePassword(nu.Password, nu.Username, nu.Email)
 	if err != nil {
 		return nil, err
 	}
@@ -472,6 +489,7 @@ func (p *politeiawww) processNewUser(nu www.NewUser) (*www.NewUserReply, error)
 		// the new identity if one was set.
 		u.NewUserVerificationToken = tokenb
 		u.NewUserVerificationExpiry = expiry
+		reactivateLapsedUser(u)
 		err = p.db.UserUpdate(*u)
 		if err != nil {
 			return nil, err
@@ -625,6 +643,33 @@ func (p *politeiawww) processEditUser(eu *www.EditUser, user *user.User) (*www.E
 	if eu.EmailNotifications != nil {
 		user.EmailNotifications = *eu.EmailNotifications
 	}
+	if eu.TimeZone != nil {
+		if !mail.IsValidTimeZone(*eu.TimeZone) {
+			return nil, www.UserError{
+				ErrorCode:    www.ErrorStatusInvalidInput,
+				ErrorContext: []string{"invalid time zone"},
+			}
+		}
+		user.TimeZone = *eu.TimeZone
+	}
+	if eu.Locale != nil {
+		if !mail.IsValidLocale(*eu.Locale) {
+			return nil, www.UserError{
+				ErrorCode:    www.ErrorStatusInvalidInput,
+				ErrorContext: []string{"unsupported locale"},
+			}
+		}
+		user.Locale = *eu.Locale
+	}
+	if eu.EmailDigest != nil {
+		if _, ok := www.EmailDigests[*eu.EmailDigest]; !ok {
+			return nil, www.UserError{
+				ErrorCode:    www.ErrorStatusInvalidInput,
+				ErrorContext: []string{"invalid email digest"},
+			}
+		}
+		user.EmailDigest = int(*eu.EmailDigest)
+	}
 
 	// Update the user in the database.
 	err := p.db.UserUpdate(*user)
@@ -658,6 +703,10 @@ func (p *politeiawww) createLoginReply(u *user.User, lastLoginTime int64) (*www.
 		ProposalCredits:    uint64(len(u.UnspentProposalCredits)),
 		LastLoginTime:      lastLoginTime,
 		TOTPVerified:       u.TOTPVerified,
+		TimeZone:           u.TimeZone,
+		Locale:             u.Locale,
+		EmailDigest:        www.EmailDigestT(u.EmailDigest),
+		StakeVerified:      u.StakeVerified,
 	}
 
 	if !p.userHasPaid(*u) {
@@ -769,16 +818,30 @@ func (p *politeiawww) processVerifyNewUser(usr www.VerifyNewUser) (*user.User, e
 }
 
 // processResendVerification resends a new user verification email if the
-// user exists and his verification token is expired.
+// user exists and is not verified. A verification email is sent at most once
+// per resend interval to an email address.
 func (p *politeiawww) processResendVerification(rv *www.ResendVerification) (*www.ResendVerificationReply, error) {
 	rvr := www.ResendVerificationReply{}
 
+	// Check the throttle before the user lookup so that the reply does
+	// not depend on whether the user exists. The requests that do not
+	// send an email because the user does not exist or is already
+	// verified are throttled the same as the requests that do.
+	now := time.Now()
+	next, ok := p.resendThrottle.throttled(rv.Email, now)
+	if ok {
+		log.Debugf("ResendVerification failure for %v: throttled until %v",
+			rv.Email, next)
+		return nil, throttledResendError(next)
+	}
+
 	// Get user from db.
 	u, err := p.userByEmail(rv.Email)
 	if err != nil {
 		if errors.Is(err, user.ErrUserNotFound) {
 			log.Debugf("ResendVerification failure for %v: user not found",
 				rv.Email)
+			p.resendThrottle.sent(rv.Email, now)
 			return nil, www.UserError{
 				ErrorCode: www.ErrorStatusUserNotFound,
 			}
@@ -791,14 +854,15 @@ func (p *politeiawww) processResendVerification(rv *www.ResendVerification) (*ww
 	if u.NewUserVerificationToken == nil {
 		log.Debugf("ResendVerification failure for %v: user already verified",
 			rv.Email)
+		p.resendThrottle.sent(rv.Email, now)
 		return nil, www.UserError{
 			ErrorCode: www.ErrorStatusEmailAlreadyVerified,
 		}
 	}
 
-	if u.ResendNewUserVerificationExpiry > time.Now().Unix() {
-		log.Debugf("ResendVerification failure for %v: verification token "+
-			"not expired yet", rv.Email)
+	if u.ResendNewUserVerificationExpiry > now.Unix() {
+		log.Debugf("ResendVerification failure for %v: verification email "+
+			"sent recently", rv.Email)
 		return nil, www.UserError{
 			ErrorCode: www.ErrorStatusVerificationTokenUnexpired,
 		}
@@ -837,7 +901,8 @@ func (p *politeiawww) processResendVerification(rv *www.ResendVerification) (*ww
 	}
 	u.NewUserVerificationToken = token
 	u.NewUserVerificationExpiry = expiry
-	u.ResendNewUserVerificationExpiry = expiry
+	u.ResendNewUserVerificationExpiry = now.Add(p.resendThrottle.interval).Unix()
+	reactivateLapsedUser(u)
 	id, err := user.NewIdentity(rv.PublicKey)
 	if err != nil {
 		return nil, err
@@ -864,6 +929,7 @@ func (p *politeiawww) processResendVerification(rv *www.ResendVerification) (*ww
 	if err != nil {
 		return nil, err
 	}
+	p.resendThrottle.sent(rv.Email, now)
 
 	// Only set the token if email verification is disabled.
 	if !p.mail.IsEnabled() {
@@ -1042,6 +1108,16 @@ func (p *politeiawww) login(l www.Login) loginResult {
 		}
 	}
 
+	// Bot accounts can only authenticate using an API key
+	if u.Bot {
+		return loginResult{
+			reply: nil,
+			err: www.UserError{
+				ErrorCode: www.ErrorStatusBotNotAllowed,
+			},
+		}
+	}
+
 	// First check if TOTP is enabled and verified.
 	if u.TOTPVerified {
 		err := p.totpCheck(l.Code, u)
@@ -1320,7 +1396,7 @@ func (p *politeiawww) processChangePassword(email string, cp www.ChangePassword)
 	}
 
 	// Validate the new password.
-	err = validatePassword(cp.NewPassword)
+	err = p.validatePassword(cp.NewPassword, u.Username, u.Email)
 	if err != nil {
 		return nil, err
 	}
@@ -1505,7 +1581,7 @@ func (p *politeiawww) processVerifyResetPassword(vrp www.VerifyResetPassword) (*
 	// it to politeiawww. This validation is only relevant if the
 	// client failed to hash the password or does not include a
 	// password in the request.
-	err = validatePassword(vrp.NewPassword)
+	err = p.validatePassword(vrp.NewPassword, u.Username, u.Email)
 	if err != nil {
 		return nil, err
 	}
@@ -1629,6 +1705,11 @@ func (p *politeiawww) processManageUser(mu *www.ManageUser, adminUser *user.User
 		user.Deactivated = true
 	case www.UserManageReactivate:
 		user.Deactivated = false
+	case www.UserManageClearEmailSuppression:
+		user.EmailSuppressed = false
+		user.EmailSuppressedReason = ""
+		user.EmailSoftBounces = 0
+		p.mail.Unsuppress(user.Email)
 	default:
 		return nil, www.UserError{
 			ErrorCode: www.ErrorStatusInvalidUserManageAction,
@@ -26,10 +26,13 @@ const (
 	databaseVersion uint32 = 1
 
 	// Database table names
-	tableKeyValue   = "key_value"
-	tableUsers      = "users"
-	tableIdentities = "identities"
-	tableSessions   = "sessions"
+	tableKeyValue     = "key_value"
+	tableUsers        = "users"
+	tableIdentities   = "identities"
+	tableSessions     = "sessions"
+	tableQueuedEmails = "queued_emails"
+	tableOAuthGrants  = "oauth_grants"
+	tableOAuthTokens  = "oauth_tokens"
 
 	// Database user (read/write access)
 	userPoliteiawww = "politeiawww"
@@ -553,6 +556,366 @@ func (c *cockroachdb) SessionsDeleteByUserID(uid uuid.UUID, exemptSessionIDs []s
 		Error
 }
 
+func (c *cockroachdb) convertQueuedEmailFromUser(e user.QueuedEmail) (*QueuedEmail, error) {
+	b, err := user.EncodeQueuedEmail(e)
+	if err != nil {
+		return nil, err
+	}
+	eb, err := c.encrypt(user.VersionQueuedEmail, b)
+	if err != nil {
+		return nil, err
+	}
+	return &QueuedEmail{
+		ID:        e.ID,
+		Failed:    e.Failed,
+		CreatedAt: e.CreatedAt,
+		Blob:      eb,
+	}, nil
+}
+
+func (c *cockroachdb) convertQueuedEmailToUser(e QueuedEmail) (*user.QueuedEmail, error) {
+	b, _, err := c.decrypt(e.Blob)
+	if err != nil {
+		return nil, err
+	}
+	return user.DecodeQueuedEmail(b)
+}
+
+// QueuedEmailSave saves the given queued email to the database. New emails
+// are inserted into the database. Existing emails are updated in the
+// database.
+//
+// QueuedEmailSave satisfies the Database interface.
+func (c *cockroachdb) QueuedEmailSave(ue user.QueuedEmail) error {
+	log.Tracef("QueuedEmailSave: %v", ue.ID)
+
+	if c.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	email, err := c.convertQueuedEmailFromUser(ue)
+	if err != nil {
+		return err
+	}
+
+	// Check if email already exists
+	var update bool
+	var e QueuedEmail
+	err = c.userDB.
+		Where("id = ?", email.ID).
+		Find(&e).
+		Error
+	switch err {
+	case nil:
+		// Email already exists; update existing email
+		update = true
+	case gorm.ErrRecordNotFound:
+		// Email doesn't exist; continue
+	default:
+		// All other errors
+		return fmt.Errorf("lookup: %v", err)
+	}
+
+	// Save email record
+	if update {
+		err := c.userDB.Save(email).Error
+		if err != nil {
+			return fmt.Errorf("save: %v", err)
+		}
+	} else {
+		err := c.userDB.Create(email).Error
+		if err != nil {
+			return fmt.Errorf("create: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// QueuedEmailsGet returns the queued emails that are pending or, when failed
+// is true, the queued emails that failed permanently. The emails are returned
+// in the order that they were queued in.
+//
+// QueuedEmailsGet satisfies the Database interface.
+func (c *cockroachdb) QueuedEmailsGet(failed bool) ([]user.QueuedEmail, error) {
+	log.Tracef("QueuedEmailsGet: %v", failed)
+
+	if c.isShutdown() {
+		return nil, user.ErrShutdown
+	}
+
+	var emails []QueuedEmail
+	err := c.userDB.
+		Where("failed = ?", failed).
+		Order("created_at").
+		Find(&emails).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	ue := make([]user.QueuedEmail, 0, len(emails))
+	for _, v := range emails {
+		e, err := c.convertQueuedEmailToUser(v)
+		if err != nil {
+			return nil, err
+		}
+		ue = append(ue, *e)
+	}
+
+	return ue, nil
+}
+
+// QueuedEmailDeleteByID deletes the queued email with the given id.
+//
+// QueuedEmailDeleteByID satisfies the Database interface.
+func (c *cockroachdb) QueuedEmailDeleteByID(id string) error {
+	log.Tracef("QueuedEmailDeleteByID: %v", id)
+
+	if c.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	e := QueuedEmail{
+		ID: id,
+	}
+	return c.userDB.Delete(&e).Error
+}
+
+func (c *cockroachdb) convertOAuthGrantToUser(g OAuthGrant) (*user.OAuthGrant, error) {
+	b, _, err := c.decrypt(g.Blob)
+	if err != nil {
+		return nil, err
+	}
+	return user.DecodeOAuthGrant(b)
+}
+
+// OAuthGrantSave saves the given OAuth grant to the database. New grants are
+// inserted into the database. Existing grants are updated in the database.
+//
+// OAuthGrantSave satisfies the Database interface.
+func (c *cockroachdb) OAuthGrantSave(ug user.OAuthGrant) error {
+	log.Tracef("OAuthGrantSave: %v %v", ug.UserID, ug.ClientID)
+
+	if c.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	b, err := user.EncodeOAuthGrant(ug)
+	if err != nil {
+		return err
+	}
+	eb, err := c.encrypt(user.VersionOAuthGrant, b)
+	if err != nil {
+		return err
+	}
+
+	// Save inserts the grant if it does not exist yet
+	err = c.userDB.Save(&OAuthGrant{
+		UserID:   ug.UserID,
+		ClientID: ug.ClientID,
+		Blob:     eb,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("save: %v", err)
+	}
+
+	return nil
+}
+
+// OAuthGrantsGetByUserID returns the OAuth grants of the given user.
+//
+// OAuthGrantsGetByUserID satisfies the Database interface.
+func (c *cockroachdb) OAuthGrantsGetByUserID(uid uuid.UUID) ([]user.OAuthGrant, error) {
+	log.Tracef("OAuthGrantsGetByUserID: %v", uid)
+
+	if c.isShutdown() {
+		return nil, user.ErrShutdown
+	}
+
+	var grants []OAuthGrant
+	err := c.userDB.
+		Where("user_id = ?", uid.String()).
+		Find(&grants).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	ug := make([]user.OAuthGrant, 0, len(grants))
+	for _, v := range grants {
+		g, err := c.convertOAuthGrantToUser(v)
+		if err != nil {
+			return nil, err
+		}
+		ug = append(ug, *g)
+	}
+
+	return ug, nil
+}
+
+// OAuthGrantDelete deletes the OAuth grant that the given user has given to
+// the given application along with the tokens of the grant.
+//
+// OAuthGrantDelete satisfies the Database interface.
+func (c *cockroachdb) OAuthGrantDelete(uid uuid.UUID, clientID string) error {
+	log.Tracef("OAuthGrantDelete: %v %v", uid, clientID)
+
+	if c.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	tx := c.userDB.Begin()
+	err := tx.
+		Where("user_id = ? AND client_id = ?", uid.String(), clientID).
+		Delete(OAuthGrant{}).
+		Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = tx.
+		Where("user_id = ? AND client_id = ?", uid.String(), clientID).
+		Delete(OAuthToken{}).
+		Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// OAuthGrantsDeleteByClientID deletes the OAuth grants and tokens of the given
+// application.
+//
+// OAuthGrantsDeleteByClientID satisfies the Database interface.
+func (c *cockroachdb) OAuthGrantsDeleteByClientID(clientID string) error {
+	log.Tracef("OAuthGrantsDeleteByClientID: %v", clientID)
+
+	if c.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	tx := c.userDB.Begin()
+	err := tx.
+		Where("client_id = ?", clientID).
+		Delete(OAuthGrant{}).
+		Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	err = tx.
+		Where("client_id = ?", clientID).
+		Delete(OAuthToken{}).
+		Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// OAuthTokenSave saves the given OAuth token to the database. New tokens are
+// inserted into the database. Existing tokens are updated in the database.
+//
+// OAuthTokenSave satisfies the Database interface.
+func (c *cockroachdb) OAuthTokenSave(ut user.OAuthToken) error {
+	log.Tracef("OAuthTokenSave: %v %v", ut.UserID, ut.ClientID)
+
+	if c.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	b, err := user.EncodeOAuthToken(ut)
+	if err != nil {
+		return err
+	}
+	eb, err := c.encrypt(user.VersionOAuthToken, b)
+	if err != nil {
+		return err
+	}
+
+	// Save inserts the token if it does not exist yet
+	err = c.userDB.Save(&OAuthToken{
+		Hash:     ut.Hash,
+		UserID:   ut.UserID,
+		ClientID: ut.ClientID,
+		Expires:  ut.Expires,
+		Blob:     eb,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("save: %v", err)
+	}
+
+	return nil
+}
+
+// OAuthTokenGetByHash returns the OAuth token with the given hash. A
+// user.ErrOAuthTokenNotFound is returned if the token does not exist.
+//
+// OAuthTokenGetByHash satisfies the Database interface.
+func (c *cockroachdb) OAuthTokenGetByHash(hash string) (*user.OAuthToken, error) {
+	log.Tracef("OAuthTokenGetByHash")
+
+	if c.isShutdown() {
+		return nil, user.ErrShutdown
+	}
+
+	var t OAuthToken
+	err := c.userDB.
+		Where("hash = ?", hash).
+		Find(&t).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = user.ErrOAuthTokenNotFound
+		}
+		return nil, err
+	}
+
+	b, _, err := c.decrypt(t.Blob)
+	if err != nil {
+		return nil, err
+	}
+	return user.DecodeOAuthToken(b)
+}
+
+// OAuthTokenDeleteByHash deletes the OAuth token with the given hash.
+//
+// OAuthTokenDeleteByHash satisfies the Database interface.
+func (c *cockroachdb) OAuthTokenDeleteByHash(hash string) error {
+	log.Tracef("OAuthTokenDeleteByHash")
+
+	if c.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	return c.userDB.
+		Where("hash = ?", hash).
+		Delete(OAuthToken{}).
+		Error
+}
+
+// OAuthTokensDeleteExpired deletes the OAuth tokens that expired before the
+// given UNIX time. Tokens that do not expire are not deleted.
+//
+// OAuthTokensDeleteExpired satisfies the Database interface.
+func (c *cockroachdb) OAuthTokensDeleteExpired(before int64) error {
+	log.Tracef("OAuthTokensDeleteExpired: %v", before)
+
+	if c.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	return c.userDB.
+		Where("expires != 0 AND expires < ?", before).
+		Delete(OAuthToken{}).
+		Error
+}
+
 // rotateKeys rotates the existing database encryption key with the given new
 // key.
 //
@@ -614,6 +977,90 @@ func rotateKeys(tx *gorm.DB, oldKey *[32]byte, newKey *[32]byte) error {
 		}
 	}
 
+	// Rotate keys for queued emails table
+	var emails []QueuedEmail
+	err = tx.Find(&emails).Error
+	if err != nil {
+		return err
+	}
+
+	for _, v := range emails {
+		b, _, err := sbox.Decrypt(oldKey, v.Blob)
+		if err != nil {
+			return fmt.Errorf("decrypt queued email '%v': %v",
+				v.ID, err)
+		}
+
+		eb, err := sbox.Encrypt(user.VersionQueuedEmail, newKey, b)
+		if err != nil {
+			return fmt.Errorf("encrypt queued email '%v': %v",
+				v.ID, err)
+		}
+
+		v.Blob = eb
+		err = tx.Save(&v).Error
+		if err != nil {
+			return fmt.Errorf("save queued email '%v': %v",
+				v.ID, err)
+		}
+	}
+
+	// Rotate keys for OAuth grants table
+	var grants []OAuthGrant
+	err = tx.Find(&grants).Error
+	if err != nil {
+		return err
+	}
+
+	for _, v := range grants {
+		b, _, err := sbox.Decrypt(oldKey, v.Blob)
+		if err != nil {
+			return fmt.Errorf("decrypt oauth grant '%v %v': %v",
+				v.UserID, v.ClientID, err)
+		}
+
+		eb, err := sbox.Encrypt(user.VersionOAuthGrant, newKey, b)
+		if err != nil {
+			return fmt.Errorf("encrypt oauth grant '%v %v': %v",
+				v.UserID, v.ClientID, err)
+		}
+
+		v.Blob = eb
+		err = tx.Save(&v).Error
+		if err != nil {
+			return fmt.Errorf("save oauth grant '%v %v': %v",
+				v.UserID, v.ClientID, err)
+		}
+	}
+
+	// Rotate keys for OAuth tokens table
+	var tokens []OAuthToken
+	err = tx.Find(&tokens).Error
+	if err != nil {
+		return err
+	}
+
+	for _, v := range tokens {
+		b, _, err := sbox.Decrypt(oldKey, v.Blob)
+		if err != nil {
+			return fmt.Errorf("decrypt oauth token '%v': %v",
+				v.Hash, err)
+		}
+
+		eb, err := sbox.Encrypt(user.VersionOAuthToken, newKey, b)
+		if err != nil {
+			return fmt.Errorf("encrypt oauth token '%v': %v",
+				v.Hash, err)
+		}
+
+		v.Blob = eb
+		err = tx.Save(&v).Error
+		if err != nil {
+			return fmt.Errorf("save oauth token '%v': %v",
+				v.Hash, err)
+		}
+	}
+
 	return nil
 }
 
@@ -782,6 +1229,24 @@ func (c *cockroachdb) createTables(tx *gorm.DB) error {
 			return err
 		}
 	}
+	if !tx.HasTable(tableQueuedEmails) {
+		err := tx.CreateTable(&QueuedEmail{}).Error
+		if err != nil {
+			return err
+		}
+	}
+	if !tx.HasTable(tableOAuthGrants) {
+		err := tx.CreateTable(&OAuthGrant{}).Error
+		if err != nil {
+			return err
+		}
+	}
+	if !tx.HasTable(tableOAuthTokens) {
+		err := tx.CreateTable(&OAuthToken{}).Error
+		if err != nil {
+			return err
+		}
+	}
 
 	// Insert version record
 	kv := KeyValue{
@@ -67,6 +67,55 @@ func (Session) TableName() string {
 	return tableSessions
 }
 
+// QueuedEmail represents an outbound email that is waiting to be sent.
+//
+// Blob represents an encrypted user.QueuedEmail. The fields that have been
+// broken out of the encrypted blob are the fields that need to be queryable.
+type QueuedEmail struct {
+	ID        string `gorm:"primary_key"` // Unique email ID
+	Failed    bool   `gorm:"not null"`    // Delivery failed permanently
+	CreatedAt int64  `gorm:"not null"`    // Queued at UNIX timestamp
+	Blob      []byte `gorm:"not null"`    // Encrypted queued email
+}
+
+// TableName returns the table name of the QueuedEmail table.
+func (QueuedEmail) TableName() string {
+	return tableQueuedEmails
+}
+
+// OAuthGrant represents the consent that a user has given to a third-party
+// application.
+//
+// Blob represents an encrypted user.OAuthGrant. The fields that have been
+// broken out of the encrypted blob are the fields that need to be queryable.
+type OAuthGrant struct {
+	UserID   uuid.UUID `gorm:"primary_key"` // User UUID
+	ClientID string    `gorm:"primary_key"` // Application ID
+	Blob     []byte    `gorm:"not null"`    // Encrypted OAuth grant
+}
+
+// TableName returns the table name of the OAuthGrant table.
+func (OAuthGrant) TableName() string {
+	return tableOAuthGrants
+}
+
+// OAuthToken represents an OAuth access token or refresh token.
+//
+// Blob represents an encrypted user.OAuthToken. The fields that have been
+// broken out of the encrypted blob are the fields that need to be queryable.
+type OAuthToken struct {
+	Hash     string    `gorm:"primary_key"` // SHA256 hash of the token
+	UserID   uuid.UUID `gorm:"not null"`    // User UUID
+	ClientID string    `gorm:"not null"`    // Application ID
+	Expires  int64     `gorm:"not null"`    // Expires at UNIX timestamp
+	Blob     []byte    `gorm:"not null"`    // Encrypted OAuth token
+}
+
+// TableName returns the table name of the OAuthToken table.
+func (OAuthToken) TableName() string {
+	return tableOAuthTokens
+}
+
 // CMSUser represents a CMS user. A CMS user includes the politeiawww User
 // object as well as CMS specific user fields. A CMS user must correspond to
 // a politeiawww User.
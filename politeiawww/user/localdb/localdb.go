@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -23,6 +24,15 @@ const (
 
 	// The key for a user session is sessionPrefix+sessionID
 	sessionPrefix = "session:"
+
+	// The key for a queued email is queuedEmailPrefix+emailID
+	queuedEmailPrefix = "queuedemail:"
+
+	// The key for an OAuth grant is oauthGrantPrefix+userID+":"+clientID
+	oauthGrantPrefix = "oauthgrant:"
+
+	// The key for an OAuth token is oauthTokenPrefix+tokenHash
+	oauthTokenPrefix = "oauthtoken:"
 )
 
 var (
@@ -52,6 +62,9 @@ func isUserRecord(key string) bool {
 	return key != UserVersionKey &&
 		key != LastPaywallAddressIndex &&
 		!strings.HasPrefix(key, sessionPrefix) &&
+		!strings.HasPrefix(key, queuedEmailPrefix) &&
+		!strings.HasPrefix(key, oauthGrantPrefix) &&
+		!strings.HasPrefix(key, oauthTokenPrefix) &&
 		!strings.HasPrefix(key, cmsUserPrefix) &&
 		!strings.HasPrefix(key, cmsCodeStatsPrefix)
 }
@@ -592,6 +605,318 @@ func (l *localdb) SessionsDeleteByUserID(uid uuid.UUID, exemptSessionIDs []strin
 	return l.userdb.Write(batch, nil)
 }
 
+// QueuedEmailSave saves the given queued email to the database. New emails
+// are inserted into the database. Existing emails are updated in the
+// database.
+//
+// QueuedEmailSave satisfies the user.Database interface.
+func (l *localdb) QueuedEmailSave(e user.QueuedEmail) error {
+	log.Tracef("QueuedEmailSave: %v", e.ID)
+
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return user.ErrShutdown
+	}
+
+	payload, err := user.EncodeQueuedEmail(e)
+	if err != nil {
+		return err
+	}
+
+	key := []byte(queuedEmailPrefix + e.ID)
+	return l.userdb.Put(key, payload, nil)
+}
+
+// QueuedEmailsGet returns the queued emails that are pending or, when failed
+// is true, the queued emails that failed permanently. The emails are returned
+// in the order that they were queued in.
+//
+// QueuedEmailsGet satisfies the user.Database interface.
+func (l *localdb) QueuedEmailsGet(failed bool) ([]user.QueuedEmail, error) {
+	log.Tracef("QueuedEmailsGet: %v", failed)
+
+	l.RLock()
+	defer l.RUnlock()
+
+	if l.shutdown {
+		return nil, user.ErrShutdown
+	}
+
+	emails := make([]user.QueuedEmail, 0, 64)
+	iter := l.userdb.NewIterator(util.BytesPrefix([]byte(queuedEmailPrefix)), nil)
+	for iter.Next() {
+		e, err := user.DecodeQueuedEmail(iter.Value())
+		if err != nil {
+			iter.Release()
+			return nil, err
+		}
+		if e.Failed != failed {
+			continue
+		}
+		emails = append(emails, *e)
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(emails, func(i, j int) bool {
+		return emails[i].CreatedAt < emails[j].CreatedAt
+	})
+
+	return emails, nil
+}
+
+// QueuedEmailDeleteByID deletes the queued email with the given id.
+//
+// QueuedEmailDeleteByID satisfies the user.Database interface.
+func (l *localdb) QueuedEmailDeleteByID(id string) error {
+	log.Tracef("QueuedEmailDeleteByID: %v", id)
+
+	l.RLock()
+	defer l.RUnlock()
+
+	if l.shutdown {
+		return user.ErrShutdown
+	}
+
+	return l.userdb.Delete([]byte(queuedEmailPrefix+id), nil)
+}
+
+// oauthGrantKey returns the key of the OAuth grant that a user has given to
+// an application.
+func oauthGrantKey(userID uuid.UUID, clientID string) []byte {
+	return []byte(oauthGrantPrefix + userID.String() + ":" + clientID)
+}
+
+// OAuthGrantSave saves the given OAuth grant to the database. New grants are
+// inserted into the database. Existing grants are updated in the database.
+//
+// OAuthGrantSave satisfies the user.Database interface.
+func (l *localdb) OAuthGrantSave(g user.OAuthGrant) error {
+	log.Tracef("OAuthGrantSave: %v %v", g.UserID, g.ClientID)
+
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return user.ErrShutdown
+	}
+
+	payload, err := user.EncodeOAuthGrant(g)
+	if err != nil {
+		return err
+	}
+
+	return l.userdb.Put(oauthGrantKey(g.UserID, g.ClientID), payload, nil)
+}
+
+// OAuthGrantsGetByUserID returns the OAuth grants of the given user.
+//
+// OAuthGrantsGetByUserID satisfies the user.Database interface.
+func (l *localdb) OAuthGrantsGetByUserID(uid uuid.UUID) ([]user.OAuthGrant, error) {
+	log.Tracef("OAuthGrantsGetByUserID: %v", uid)
+
+	l.RLock()
+	defer l.RUnlock()
+
+	if l.shutdown {
+		return nil, user.ErrShutdown
+	}
+
+	prefix := []byte(oauthGrantPrefix + uid.String() + ":")
+	grants := make([]user.OAuthGrant, 0, 8)
+	iter := l.userdb.NewIterator(util.BytesPrefix(prefix), nil)
+	for iter.Next() {
+		g, err := user.DecodeOAuthGrant(iter.Value())
+		if err != nil {
+			iter.Release()
+			return nil, err
+		}
+		grants = append(grants, *g)
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+
+	return grants, nil
+}
+
+// oauthDelete deletes the OAuth grants and tokens that the provided function
+// returns true for.
+//
+// This function must be called WITH the lock held.
+func (l *localdb) oauthDelete(grantFn func(user.OAuthGrant) bool, tokenFn func(user.OAuthToken) bool) error {
+	batch := new(leveldb.Batch)
+	iter := l.userdb.NewIterator(util.BytesPrefix([]byte(oauthGrantPrefix)), nil)
+	for iter.Next() {
+		g, err := user.DecodeOAuthGrant(iter.Value())
+		if err != nil {
+			iter.Release()
+			return err
+		}
+		if grantFn(*g) {
+			batch.Delete(append([]byte{}, iter.Key()...))
+		}
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	iter = l.userdb.NewIterator(util.BytesPrefix([]byte(oauthTokenPrefix)), nil)
+	for iter.Next() {
+		t, err := user.DecodeOAuthToken(iter.Value())
+		if err != nil {
+			iter.Release()
+			return err
+		}
+		if tokenFn(*t) {
+			batch.Delete(append([]byte{}, iter.Key()...))
+		}
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	return l.userdb.Write(batch, nil)
+}
+
+// OAuthGrantDelete deletes the OAuth grant that the given user has given to
+// the given application along with the tokens of the grant.
+//
+// OAuthGrantDelete satisfies the user.Database interface.
+func (l *localdb) OAuthGrantDelete(uid uuid.UUID, clientID string) error {
+	log.Tracef("OAuthGrantDelete: %v %v", uid, clientID)
+
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return user.ErrShutdown
+	}
+
+	return l.oauthDelete(
+		func(g user.OAuthGrant) bool {
+			return g.UserID == uid && g.ClientID == clientID
+		},
+		func(t user.OAuthToken) bool {
+			return t.UserID == uid && t.ClientID == clientID
+		})
+}
+
+// OAuthGrantsDeleteByClientID deletes the OAuth grants and tokens of the given
+// application.
+//
+// OAuthGrantsDeleteByClientID satisfies the user.Database interface.
+func (l *localdb) OAuthGrantsDeleteByClientID(clientID string) error {
+	log.Tracef("OAuthGrantsDeleteByClientID: %v", clientID)
+
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return user.ErrShutdown
+	}
+
+	return l.oauthDelete(
+		func(g user.OAuthGrant) bool {
+			return g.ClientID == clientID
+		},
+		func(t user.OAuthToken) bool {
+			return t.ClientID == clientID
+		})
+}
+
+// OAuthTokenSave saves the given OAuth token to the database. New tokens are
+// inserted into the database. Existing tokens are updated in the database.
+//
+// OAuthTokenSave satisfies the user.Database interface.
+func (l *localdb) OAuthTokenSave(t user.OAuthToken) error {
+	log.Tracef("OAuthTokenSave: %v %v", t.UserID, t.ClientID)
+
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return user.ErrShutdown
+	}
+
+	payload, err := user.EncodeOAuthToken(t)
+	if err != nil {
+		return err
+	}
+
+	return l.userdb.Put([]byte(oauthTokenPrefix+t.Hash), payload, nil)
+}
+
+// OAuthTokenGetByHash returns the OAuth token with the given hash. A
+// user.ErrOAuthTokenNotFound is returned if the token does not exist.
+//
+// OAuthTokenGetByHash satisfies the user.Database interface.
+func (l *localdb) OAuthTokenGetByHash(hash string) (*user.OAuthToken, error) {
+	log.Tracef("OAuthTokenGetByHash")
+
+	l.RLock()
+	defer l.RUnlock()
+
+	if l.shutdown {
+		return nil, user.ErrShutdown
+	}
+
+	payload, err := l.userdb.Get([]byte(oauthTokenPrefix+hash), nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return nil, user.ErrOAuthTokenNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return user.DecodeOAuthToken(payload)
+}
+
+// OAuthTokenDeleteByHash deletes the OAuth token with the given hash.
+//
+// OAuthTokenDeleteByHash satisfies the user.Database interface.
+func (l *localdb) OAuthTokenDeleteByHash(hash string) error {
+	log.Tracef("OAuthTokenDeleteByHash")
+
+	l.RLock()
+	defer l.RUnlock()
+
+	if l.shutdown {
+		return user.ErrShutdown
+	}
+
+	return l.userdb.Delete([]byte(oauthTokenPrefix+hash), nil)
+}
+
+// OAuthTokensDeleteExpired deletes the OAuth tokens that expired before the
+// given UNIX time. Tokens that do not expire are not deleted.
+//
+// OAuthTokensDeleteExpired satisfies the user.Database interface.
+func (l *localdb) OAuthTokensDeleteExpired(before int64) error {
+	log.Tracef("OAuthTokensDeleteExpired: %v", before)
+
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return user.ErrShutdown
+	}
+
+	return l.oauthDelete(
+		func(g user.OAuthGrant) bool {
+			return false
+		},
+		func(t user.OAuthToken) bool {
+			return t.Expires != 0 && t.Expires < before
+		})
+}
+
 // New creates a new localdb instance.
 func New(root string) (*localdb, error) {
 	log.Tracef("localdb New: %v", root)
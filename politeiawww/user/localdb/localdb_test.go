@@ -181,6 +181,67 @@ func TestSessionDeleteByID(t *testing.T) {
 	}
 }
 
+func TestQueuedEmails(t *testing.T) {
+	db, dataDir := setupTestData(t)
+	defer teardownTestData(t, db, dataDir)
+
+	// Save queued emails
+	e1 := user.QueuedEmail{
+		ID:         uuid.New().String(),
+		Subject:    "subject",
+		Body:       "body",
+		Recipients: []string{"user@example.com"},
+		CreatedAt:  2,
+	}
+	e2 := e1
+	e2.ID = uuid.New().String()
+	e2.CreatedAt = 1
+	for _, v := range []user.QueuedEmail{e1, e2} {
+		err := db.QueuedEmailSave(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Mark an email as failed
+	e1.Failed = true
+	e1.Attempts = 3
+	err := db.QueuedEmailSave(e1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify pending and failed emails
+	pending, err := db.QueuedEmailsGet(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].ID != e2.ID {
+		t.Errorf("got pending emails %v, want %v", pending, e2.ID)
+	}
+	failed, err := db.QueuedEmailsGet(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 1 || failed[0].ID != e1.ID ||
+		failed[0].Attempts != e1.Attempts {
+		t.Errorf("got failed emails %v, want %v", failed, e1.ID)
+	}
+
+	// Delete email
+	err = db.QueuedEmailDeleteByID(e2.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, err = db.QueuedEmailsGet(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("got pending emails %v, want none", pending)
+	}
+}
+
 func TestIsUserRecord(t *testing.T) {
 	tests := []struct {
 		input string
@@ -198,6 +259,10 @@ func TestIsUserRecord(t *testing.T) {
 			input: sessionPrefix + uuid.New().String(),
 			want:  false,
 		},
+		{
+			input: queuedEmailPrefix + uuid.New().String(),
+			want:  false,
+		},
 	}
 
 	for _, test := range tests {
@@ -37,10 +37,13 @@ const (
 	databaseID = "users"
 
 	// Database table names.
-	tableNameKeyValue   = "key_value"
-	tableNameUsers      = "users"
-	tableNameIdentities = "identities"
-	tableNameSessions   = "sessions"
+	tableNameKeyValue     = "key_value"
+	tableNameUsers        = "users"
+	tableNameIdentities   = "identities"
+	tableNameSessions     = "sessions"
+	tableNameQueuedEmails = "queued_emails"
+	tableNameOAuthGrants  = "oauth_grants"
+	tableNameOAuthTokens  = "oauth_tokens"
 
 	// Key-value store keys.
 	keyVersion             = "version"
@@ -80,6 +83,31 @@ const tableSessions = `
   s_blob BLOB NOT NULL
 `
 
+// tableQueuedEmails defines the queued emails table.
+const tableQueuedEmails = `
+  id VARCHAR(36) NOT NULL PRIMARY KEY,
+  failed BOOLEAN NOT NULL,
+  created_at INT(11) NOT NULL,
+  e_blob LONGBLOB NOT NULL
+`
+
+// tableOAuthGrants defines the OAuth grants table.
+const tableOAuthGrants = `
+  user_id VARCHAR(36) NOT NULL,
+  client_id VARCHAR(255) NOT NULL,
+  g_blob BLOB NOT NULL,
+  PRIMARY KEY (user_id, client_id)
+`
+
+// tableOAuthTokens defines the OAuth tokens table.
+const tableOAuthTokens = `
+  k CHAR(64) NOT NULL PRIMARY KEY,
+  user_id VARCHAR(36) NOT NULL,
+  client_id VARCHAR(255) NOT NULL,
+  expires INT(11) NOT NULL,
+  t_blob BLOB NOT NULL
+`
+
 var (
 	_ user.Database = (*mysql)(nil)
 )
@@ -323,6 +351,146 @@ func rotateKeys(ctx context.Context, tx *sql.Tx, oldKey *[32]byte, newKey *[32]b
 		}
 	}
 
+	// Rotate keys for queued emails table.
+	type QueuedEmail struct {
+		ID   string
+		Blob []byte // Encrypted blob of queued email data.
+	}
+	var emails []QueuedEmail
+	rows, err = tx.QueryContext(ctx, "SELECT id, e_blob FROM queued_emails")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e QueuedEmail
+		if err := rows.Scan(&e.ID, &e.Blob); err != nil {
+			return err
+		}
+		emails = append(emails, e)
+	}
+	// Rows.Err will report the last error encountered by Rows.Scan.
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, v := range emails {
+		b, _, err := sbox.Decrypt(oldKey, v.Blob)
+		if err != nil {
+			return fmt.Errorf("decrypt queued email '%v': %v",
+				v.ID, err)
+		}
+
+		eb, err := sbox.Encrypt(user.VersionQueuedEmail, newKey, b)
+		if err != nil {
+			return fmt.Errorf("encrypt queued email '%v': %v",
+				v.ID, err)
+		}
+
+		v.Blob = eb
+		// Store new queued email blob.
+		_, err = tx.ExecContext(ctx,
+			"UPDATE queued_emails SET e_blob = ? WHERE id = ?", v.Blob, v.ID)
+		if err != nil {
+			return fmt.Errorf("save queued email '%v': %v", v.ID, err)
+		}
+	}
+
+	// Rotate keys for OAuth grants table.
+	type OAuthGrant struct {
+		UserID   string
+		ClientID string
+		Blob     []byte // Encrypted blob of OAuth grant data.
+	}
+	var grants []OAuthGrant
+	rows, err = tx.QueryContext(ctx,
+		"SELECT user_id, client_id, g_blob FROM oauth_grants")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var g OAuthGrant
+		if err := rows.Scan(&g.UserID, &g.ClientID, &g.Blob); err != nil {
+			return err
+		}
+		grants = append(grants, g)
+	}
+	// Rows.Err will report the last error encountered by Rows.Scan.
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, v := range grants {
+		b, _, err := sbox.Decrypt(oldKey, v.Blob)
+		if err != nil {
+			return fmt.Errorf("decrypt oauth grant '%v %v': %v",
+				v.UserID, v.ClientID, err)
+		}
+
+		eb, err := sbox.Encrypt(user.VersionOAuthGrant, newKey, b)
+		if err != nil {
+			return fmt.Errorf("encrypt oauth grant '%v %v': %v",
+				v.UserID, v.ClientID, err)
+		}
+
+		v.Blob = eb
+		// Store new OAuth grant blob.
+		_, err = tx.ExecContext(ctx,
+			"UPDATE oauth_grants SET g_blob = ? WHERE user_id = ? AND client_id = ?",
+			v.Blob, v.UserID, v.ClientID)
+		if err != nil {
+			return fmt.Errorf("save oauth grant '%v %v': %v",
+				v.UserID, v.ClientID, err)
+		}
+	}
+
+	// Rotate keys for OAuth tokens table.
+	type OAuthToken struct {
+		Key  string
+		Blob []byte // Encrypted blob of OAuth token data.
+	}
+	var tokens []OAuthToken
+	rows, err = tx.QueryContext(ctx, "SELECT k, t_blob FROM oauth_tokens")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t OAuthToken
+		if err := rows.Scan(&t.Key, &t.Blob); err != nil {
+			return err
+		}
+		tokens = append(tokens, t)
+	}
+	// Rows.Err will report the last error encountered by Rows.Scan.
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, v := range tokens {
+		b, _, err := sbox.Decrypt(oldKey, v.Blob)
+		if err != nil {
+			return fmt.Errorf("decrypt oauth token '%v': %v", v.Key, err)
+		}
+
+		eb, err := sbox.Encrypt(user.VersionOAuthToken, newKey, b)
+		if err != nil {
+			return fmt.Errorf("encrypt oauth token '%v': %v", v.Key, err)
+		}
+
+		v.Blob = eb
+		// Store new OAuth token blob.
+		_, err = tx.ExecContext(ctx,
+			"UPDATE oauth_tokens SET t_blob = ? WHERE k = ?", v.Blob, v.Key)
+		if err != nil {
+			return fmt.Errorf("save oauth token '%v': %v", v.Key, err)
+		}
+	}
+
 	return nil
 }
 
@@ -919,6 +1087,363 @@ func (m *mysql) SessionsDeleteByUserID(uid uuid.UUID, exemptSessionIDs []string)
 	return err
 }
 
+// QueuedEmailSave saves the given queued email to the database. New emails
+// are inserted into the database. Existing emails are updated in the
+// database.
+//
+// QueuedEmailSave satisfies the Database interface.
+func (m *mysql) QueuedEmailSave(e user.QueuedEmail) error {
+	log.Tracef("QueuedEmailSave: %v", e.ID)
+
+	if m.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	ctx, cancel := ctxWithTimeout()
+	defer cancel()
+
+	b, err := user.EncodeQueuedEmail(e)
+	if err != nil {
+		return err
+	}
+	eb, err := m.encrypt(user.VersionQueuedEmail, b)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.userDB.ExecContext(ctx,
+		`INSERT INTO queued_emails (id, failed, created_at, e_blob)
+    VALUES (?, ?, ?, ?)
+    ON DUPLICATE KEY UPDATE
+    failed = VALUES(failed), e_blob = VALUES(e_blob)`,
+		e.ID, e.Failed, e.CreatedAt, eb)
+	if err != nil {
+		return fmt.Errorf("save: %v", err)
+	}
+
+	return nil
+}
+
+// QueuedEmailsGet returns the queued emails that are pending or, when failed
+// is true, the queued emails that failed permanently. The emails are returned
+// in the order that they were queued in.
+//
+// QueuedEmailsGet satisfies the Database interface.
+func (m *mysql) QueuedEmailsGet(failed bool) ([]user.QueuedEmail, error) {
+	log.Tracef("QueuedEmailsGet: %v", failed)
+
+	if m.isShutdown() {
+		return nil, user.ErrShutdown
+	}
+
+	ctx, cancel := ctxWithTimeout()
+	defer cancel()
+
+	rows, err := m.userDB.QueryContext(ctx,
+		"SELECT e_blob FROM queued_emails WHERE failed = ? ORDER BY created_at",
+		failed)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	emails := make([]user.QueuedEmail, 0, 64)
+	for rows.Next() {
+		var blob []byte
+		if err := rows.Scan(&blob); err != nil {
+			return nil, err
+		}
+		b, _, err := m.decrypt(blob)
+		if err != nil {
+			return nil, err
+		}
+		e, err := user.DecodeQueuedEmail(b)
+		if err != nil {
+			return nil, err
+		}
+		emails = append(emails, *e)
+	}
+	// Rows.Err will report the last error encountered by Rows.Scan.
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return emails, nil
+}
+
+// QueuedEmailDeleteByID deletes the queued email with the given id.
+//
+// QueuedEmailDeleteByID satisfies the Database interface.
+func (m *mysql) QueuedEmailDeleteByID(id string) error {
+	log.Tracef("QueuedEmailDeleteByID: %v", id)
+
+	if m.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	ctx, cancel := ctxWithTimeout()
+	defer cancel()
+
+	_, err := m.userDB.ExecContext(ctx,
+		"DELETE FROM queued_emails WHERE id = ?", id)
+	return err
+}
+
+// OAuthGrantSave saves the given OAuth grant to the database. New grants are
+// inserted into the database. Existing grants are updated in the database.
+//
+// OAuthGrantSave satisfies the Database interface.
+func (m *mysql) OAuthGrantSave(g user.OAuthGrant) error {
+	log.Tracef("OAuthGrantSave: %v %v", g.UserID, g.ClientID)
+
+	if m.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	ctx, cancel := ctxWithTimeout()
+	defer cancel()
+
+	b, err := user.EncodeOAuthGrant(g)
+	if err != nil {
+		return err
+	}
+	eb, err := m.encrypt(user.VersionOAuthGrant, b)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.userDB.ExecContext(ctx,
+		`INSERT INTO oauth_grants (user_id, client_id, g_blob)
+    VALUES (?, ?, ?)
+    ON DUPLICATE KEY UPDATE
+    g_blob = VALUES(g_blob)`,
+		g.UserID.String(), g.ClientID, eb)
+	if err != nil {
+		return fmt.Errorf("save: %v", err)
+	}
+
+	return nil
+}
+
+// OAuthGrantsGetByUserID returns the OAuth grants of the given user.
+//
+// OAuthGrantsGetByUserID satisfies the Database interface.
+func (m *mysql) OAuthGrantsGetByUserID(uid uuid.UUID) ([]user.OAuthGrant, error) {
+	log.Tracef("OAuthGrantsGetByUserID: %v", uid)
+
+	if m.isShutdown() {
+		return nil, user.ErrShutdown
+	}
+
+	ctx, cancel := ctxWithTimeout()
+	defer cancel()
+
+	rows, err := m.userDB.QueryContext(ctx,
+		"SELECT g_blob FROM oauth_grants WHERE user_id = ?", uid.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grants := make([]user.OAuthGrant, 0, 8)
+	for rows.Next() {
+		var blob []byte
+		if err := rows.Scan(&blob); err != nil {
+			return nil, err
+		}
+		b, _, err := m.decrypt(blob)
+		if err != nil {
+			return nil, err
+		}
+		g, err := user.DecodeOAuthGrant(b)
+		if err != nil {
+			return nil, err
+		}
+		grants = append(grants, *g)
+	}
+	// Rows.Err will report the last error encountered by Rows.Scan.
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return grants, nil
+}
+
+// OAuthGrantDelete deletes the OAuth grant that the given user has given to
+// the given application along with the tokens of the grant.
+//
+// OAuthGrantDelete satisfies the Database interface.
+func (m *mysql) OAuthGrantDelete(uid uuid.UUID, clientID string) error {
+	log.Tracef("OAuthGrantDelete: %v %v", uid, clientID)
+
+	if m.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	ctx, cancel := ctxWithTimeout()
+	defer cancel()
+
+	tx, err := m.userDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %v", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		"DELETE FROM oauth_grants WHERE user_id = ? AND client_id = ?",
+		uid.String(), clientID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		"DELETE FROM oauth_tokens WHERE user_id = ? AND client_id = ?",
+		uid.String(), clientID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// OAuthGrantsDeleteByClientID deletes the OAuth grants and tokens of the given
+// application.
+//
+// OAuthGrantsDeleteByClientID satisfies the Database interface.
+func (m *mysql) OAuthGrantsDeleteByClientID(clientID string) error {
+	log.Tracef("OAuthGrantsDeleteByClientID: %v", clientID)
+
+	if m.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	ctx, cancel := ctxWithTimeout()
+	defer cancel()
+
+	tx, err := m.userDB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %v", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		"DELETE FROM oauth_grants WHERE client_id = ?", clientID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		"DELETE FROM oauth_tokens WHERE client_id = ?", clientID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// OAuthTokenSave saves the given OAuth token to the database. New tokens are
+// inserted into the database. Existing tokens are updated in the database.
+//
+// OAuthTokenSave satisfies the Database interface.
+func (m *mysql) OAuthTokenSave(t user.OAuthToken) error {
+	log.Tracef("OAuthTokenSave: %v %v", t.UserID, t.ClientID)
+
+	if m.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	ctx, cancel := ctxWithTimeout()
+	defer cancel()
+
+	b, err := user.EncodeOAuthToken(t)
+	if err != nil {
+		return err
+	}
+	eb, err := m.encrypt(user.VersionOAuthToken, b)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.userDB.ExecContext(ctx,
+		`INSERT INTO oauth_tokens (k, user_id, client_id, expires, t_blob)
+    VALUES (?, ?, ?, ?, ?)
+    ON DUPLICATE KEY UPDATE
+    expires = VALUES(expires), t_blob = VALUES(t_blob)`,
+		t.Hash, t.UserID.String(), t.ClientID, t.Expires, eb)
+	if err != nil {
+		return fmt.Errorf("save: %v", err)
+	}
+
+	return nil
+}
+
+// OAuthTokenGetByHash returns the OAuth token with the given hash. A
+// user.ErrOAuthTokenNotFound is returned if the token does not exist.
+//
+// OAuthTokenGetByHash satisfies the Database interface.
+func (m *mysql) OAuthTokenGetByHash(hash string) (*user.OAuthToken, error) {
+	log.Tracef("OAuthTokenGetByHash")
+
+	if m.isShutdown() {
+		return nil, user.ErrShutdown
+	}
+
+	ctx, cancel := ctxWithTimeout()
+	defer cancel()
+
+	var blob []byte
+	err := m.userDB.QueryRowContext(ctx,
+		"SELECT t_blob FROM oauth_tokens WHERE k = ?", hash).
+		Scan(&blob)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, user.ErrOAuthTokenNotFound
+	case err != nil:
+		return nil, err
+	}
+
+	b, _, err := m.decrypt(blob)
+	if err != nil {
+		return nil, err
+	}
+	return user.DecodeOAuthToken(b)
+}
+
+// OAuthTokenDeleteByHash deletes the OAuth token with the given hash.
+//
+// OAuthTokenDeleteByHash satisfies the Database interface.
+func (m *mysql) OAuthTokenDeleteByHash(hash string) error {
+	log.Tracef("OAuthTokenDeleteByHash")
+
+	if m.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	ctx, cancel := ctxWithTimeout()
+	defer cancel()
+
+	_, err := m.userDB.ExecContext(ctx,
+		"DELETE FROM oauth_tokens WHERE k = ?", hash)
+	return err
+}
+
+// OAuthTokensDeleteExpired deletes the OAuth tokens that expired before the
+// given UNIX time. Tokens that do not expire are not deleted.
+//
+// OAuthTokensDeleteExpired satisfies the Database interface.
+func (m *mysql) OAuthTokensDeleteExpired(before int64) error {
+	log.Tracef("OAuthTokensDeleteExpired: %v", before)
+
+	if m.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	ctx, cancel := ctxWithTimeout()
+	defer cancel()
+
+	_, err := m.userDB.ExecContext(ctx,
+		"DELETE FROM oauth_tokens WHERE expires != 0 AND expires < ?", before)
+	return err
+}
+
 // RegisterPlugin registers a plugin.
 func (m *mysql) RegisterPlugin(p user.Plugin) error {
 	log.Tracef("RegisterPlugin: %v %v", p.ID, p.Version)
@@ -1149,6 +1674,33 @@ func New(host, password, network, encryptionKey string) (*mysql, error) {
 		return nil, fmt.Errorf("create %v table: %v", tableNameSessions, err)
 	}
 
+	// Setup queued emails table.
+	q = fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v (%v)`,
+		tableNameQueuedEmails, tableQueuedEmails)
+	_, err = db.Exec(q)
+	if err != nil {
+		return nil, fmt.Errorf("create %v table: %v",
+			tableNameQueuedEmails, err)
+	}
+
+	// Setup OAuth grants table.
+	q = fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v (%v)`,
+		tableNameOAuthGrants, tableOAuthGrants)
+	_, err = db.Exec(q)
+	if err != nil {
+		return nil, fmt.Errorf("create %v table: %v",
+			tableNameOAuthGrants, err)
+	}
+
+	// Setup OAuth tokens table.
+	q = fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v (%v)`,
+		tableNameOAuthTokens, tableOAuthTokens)
+	_, err = db.Exec(q)
+	if err != nil {
+		return nil, fmt.Errorf("create %v table: %v",
+			tableNameOAuthTokens, err)
+	}
+
 	// Load encryption key.
 	key, err := util.LoadEncryptionKey(log, encryptionKey)
 	if err != nil {
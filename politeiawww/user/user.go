@@ -29,6 +29,10 @@ var (
 	// database.
 	ErrUserExists = errors.New("user already exists")
 
+	// ErrOAuthTokenNotFound indicates that an OAuth token was not found
+	// in the database.
+	ErrOAuthTokenNotFound = errors.New("oauth token not found")
+
 	// ErrShutdown is emitted when the database is shutting down.
 	ErrShutdown = errors.New("database is shutting down")
 
@@ -152,6 +156,37 @@ type User struct {
 	FailedLoginAttempts uint64    `json:"failedloginattempts"` // Sequential failed login attempts
 	Deactivated         bool      `json:"deactivated"`         // Is account deactivated
 
+	// Bot accounts do not have a password and authenticate using an
+	// API key. Only the SHA256 hash of the API key secret is stored.
+	Bot           bool   `json:"bot,omitempty"`
+	APIKeyHash    []byte `json:"apikeyhash,omitempty"`
+	APIKeyCreated int64  `json:"apikeycreated,omitempty"`
+
+	// Email suppression. Emails are not sent to a suppressed address.
+	// An address is suppressed when it hard bounces, when the user
+	// files a complaint, or when it has reached the soft bounce limit.
+	EmailSuppressed       bool   `json:"emailsuppressed,omitempty"`
+	EmailSuppressedReason string `json:"emailsuppressedreason,omitempty"`
+	EmailSoftBounces      uint64 `json:"emailsoftbounces,omitempty"`
+
+	// Date preferences. The dates in notification emails are formatted
+	// using the time zone and the locale of the user. UTC and the
+	// default locale are used when they are not set.
+	TimeZone string `json:"timezone,omitempty"` // IANA time zone name
+	Locale   string `json:"locale,omitempty"`   // e.g. en-US
+
+	// EmailDigest is the www.EmailDigestT of the user. The notification
+	// emails of a user that has a digest set are batched into a single
+	// daily or weekly email instead of being sent immediately.
+	EmailDigest int `json:"emaildigest,omitempty"`
+
+	// Stake verification. A user verifies stake by signing a message
+	// using the commitment address of a live ticket. StakeVerified is
+	// the UNIX timestamp of the verification. Deployments can restrict
+	// actions to users that have verified stake.
+	StakeTicket   string `json:"staketicket,omitempty"`
+	StakeVerified int64  `json:"stakeverified,omitempty"`
+
 	// Verification tokens and their expirations
 	NewUserVerificationToken        []byte `json:"newuserverificationtoken"`
 	NewUserVerificationExpiry       int64  `json:"newuserverificationtokenexiry"`
@@ -161,6 +196,13 @@ type User struct {
 	ResetPasswordVerificationToken  []byte `json:"resetpasswordverificationtoken"`
 	ResetPasswordVerificationExpiry int64  `json:"resetpasswordverificationexpiry"`
 
+	// VerificationLapsed is the UNIX timestamp of when the account was
+	// deactivated because the new user verification token had been
+	// expired for longer than the unverified account retention period.
+	// The account is reactivated when a new verification token is
+	// issued.
+	VerificationLapsed int64 `json:"verificationlapsed,omitempty"`
+
 	// PaywallAddressIndex is the index that is used to generate the
 	// paywall address for the user. The same paywall address is used
 	// for the user registration paywall and for proposal credit
@@ -455,6 +497,128 @@ func DecodeSession(payload []byte) (*Session, error) {
 	return &s, nil
 }
 
+// QueuedEmail represents an outbound email that is waiting to be sent. Emails
+// are queued in the database so that they are not lost when the mail server
+// is unavailable. Emails that could not be delivered after the maximum number
+// of attempts are marked as failed and remain in the database.
+type QueuedEmail struct {
+	ID          string   `json:"id"`              // Unique email ID
+	Event       string   `json:"event,omitempty"` // Notification event type
+	Subject     string   `json:"subject"`         // Email subject
+	Body        string   `json:"body"`            // Email body
+	NtfnBit     uint64   `json:"ntfnbit"`         // Notification category
+	Recipients  []string `json:"recipients"`      // Email addresses
+	CreatedAt   int64    `json:"createdat"`       // Queued at UNIX timestamp
+	Attempts    uint32   `json:"attempts"`        // Failed delivery attempts
+	NextAttempt int64    `json:"nextattempt"`     // Next attempt UNIX timestamp
+	Failed      bool     `json:"failed"`          // Delivery failed permanently
+	Error       string   `json:"error,omitempty"` // Last delivery error
+}
+
+// VersionQueuedEmail is the version of the QueuedEmail struct.
+const VersionQueuedEmail uint32 = 1
+
+// EncodeQueuedEmail encodes QueuedEmail into a JSON byte slice.
+func EncodeQueuedEmail(e QueuedEmail) ([]byte, error) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// DecodeQueuedEmail decodes a JSON byte slice into a QueuedEmail.
+func DecodeQueuedEmail(payload []byte) (*QueuedEmail, error) {
+	var e QueuedEmail
+
+	err := json.Unmarshal(payload, &e)
+	if err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// OAuthGrant is the consent that a user has given to a third-party
+// application to access the user data that is covered by the scopes.
+//
+// RefreshHash is the SHA256 hex digest of the refresh token of the grant.
+// There is a single refresh token per grant. It is replaced every time that
+// it is used or that a new authorization code is exchanged.
+type OAuthGrant struct {
+	UserID      uuid.UUID `json:"userid"`                // User UUID
+	ClientID    string    `json:"clientid"`              // Application ID
+	Scopes      []string  `json:"scopes"`                // Granted scopes
+	Timestamp   int64     `json:"timestamp"`             // UNIX time of consent
+	RefreshHash string    `json:"refreshhash,omitempty"` // SHA256 hex
+}
+
+// VersionOAuthGrant is the version of the OAuthGrant struct.
+const VersionOAuthGrant uint32 = 1
+
+// EncodeOAuthGrant encodes OAuthGrant into a JSON byte slice.
+func EncodeOAuthGrant(g OAuthGrant) ([]byte, error) {
+	b, err := json.Marshal(g)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// DecodeOAuthGrant decodes a JSON byte slice into an OAuthGrant.
+func DecodeOAuthGrant(payload []byte) (*OAuthGrant, error) {
+	var g OAuthGrant
+
+	err := json.Unmarshal(payload, &g)
+	if err != nil {
+		return nil, err
+	}
+
+	return &g, nil
+}
+
+// OAuthToken is an access token or a refresh token that has been issued to a
+// third-party application. Only the SHA256 hex digest of the token is stored.
+//
+// The scopes of a refresh token are the scopes of its grant and are not set.
+// Refresh tokens do not expire and have an Expires of 0. The tokens of a grant
+// are deleted when the grant is deleted.
+type OAuthToken struct {
+	Hash     string    `json:"hash"`             // SHA256 hex of the token
+	UserID   uuid.UUID `json:"userid"`           // User UUID
+	ClientID string    `json:"clientid"`         // Application ID
+	Scopes   []string  `json:"scopes,omitempty"` // Access token scopes
+	Issued   int64     `json:"issued"`           // Issued at UNIX timestamp
+	Expires  int64     `json:"expires"`          // Expires at UNIX timestamp
+}
+
+// VersionOAuthToken is the version of the OAuthToken struct.
+const VersionOAuthToken uint32 = 1
+
+// EncodeOAuthToken encodes OAuthToken into a JSON byte slice.
+func EncodeOAuthToken(t OAuthToken) ([]byte, error) {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// DecodeOAuthToken decodes a JSON byte slice into an OAuthToken.
+func DecodeOAuthToken(payload []byte) (*OAuthToken, error) {
+	var t OAuthToken
+
+	err := json.Unmarshal(payload, &t)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
 // Database describes the interface used for interacting with the user
 // database.
 type Database interface {
@@ -495,6 +659,40 @@ type Database interface {
 	// Delete all sessions for a user except for the given session IDs
 	SessionsDeleteByUserID(id uuid.UUID, exemptSessionIDs []string) error
 
+	// Create or update a queued email
+	QueuedEmailSave(QueuedEmail) error
+
+	// Return the queued emails that are pending or, when failed is
+	// true, the queued emails that failed permanently
+	QueuedEmailsGet(failed bool) ([]QueuedEmail, error)
+
+	// Delete a queued email given its id
+	QueuedEmailDeleteByID(id string) error
+
+	// Create or update an OAuth grant
+	OAuthGrantSave(OAuthGrant) error
+
+	// Return the OAuth grants of a user
+	OAuthGrantsGetByUserID(uuid.UUID) ([]OAuthGrant, error)
+
+	// Delete an OAuth grant along with its tokens
+	OAuthGrantDelete(userID uuid.UUID, clientID string) error
+
+	// Delete the OAuth grants and tokens of an application
+	OAuthGrantsDeleteByClientID(clientID string) error
+
+	// Create or update an OAuth token
+	OAuthTokenSave(OAuthToken) error
+
+	// Return an OAuth token given its hash
+	OAuthTokenGetByHash(hash string) (*OAuthToken, error)
+
+	// Delete an OAuth token given its hash
+	OAuthTokenDeleteByHash(hash string) error
+
+	// Delete the OAuth tokens that expired before the given UNIX time
+	OAuthTokensDeleteExpired(before int64) error
+
 	// SetPaywallAddressIndex updates the paywall address index.
 	SetPaywallAddressIndex(index uint64) error
 
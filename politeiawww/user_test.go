@@ -185,6 +185,9 @@ func TestValidateUsername(t *testing.T) {
 }
 
 func TestValidatePassword(t *testing.T) {
+	p, cleanup := newTestPoliteiawww(t)
+	defer cleanup()
+
 	// Password under the min length requirement
 	var minPass string
 	for i := 0; i < www.PolicyMinPasswordLength-1; i++ {
@@ -206,7 +209,7 @@ func TestValidatePassword(t *testing.T) {
 	// Run tests
 	for _, v := range tests {
 		t.Run(v.name, func(t *testing.T) {
-			err := validatePassword(v.password)
+			err := p.validatePassword(v.password)
 			got := errToStr(err)
 			want := errToStr(v.want)
 			if got != want {
@@ -619,9 +622,8 @@ func TestProcessResendVerification(t *testing.T) {
 				ErrorCode: www.ErrorStatusDuplicatePublicKey,
 			},
 		},
-		// If the user has an unexpired token, they are allowed
-		// to resend the verification email one time. The second
-		// attempt should fail.
+		// The verification email can only be resent once per
+		// resend interval. The second attempt should fail.
 		{
 			"success",
 			www.ResendVerification{
@@ -631,13 +633,13 @@ func TestProcessResendVerification(t *testing.T) {
 			nil,
 		},
 		{
-			"unexpired token",
+			"resend throttled",
 			www.ResendVerification{
 				Email:     usr1.Email,
 				PublicKey: usr1Pubkey,
 			},
 			www.UserError{
-				ErrorCode: www.ErrorStatusVerificationTokenUnexpired,
+				ErrorCode: www.ErrorStatusVerificationResendThrottled,
 			},
 		},
 		// The user does not have to pass in the same pubkey that
@@ -74,7 +74,8 @@ func (p *politeiawww) handleVerifyNewUser(w http.ResponseWriter, r *http.Request
 }
 
 // handleResendVerification sends another verification email for new user
-// signup, if there is an existing verification token and it is expired.
+// signup, if the user has not been verified yet. Requests for the same email
+// address are throttled.
 func (p *politeiawww) handleResendVerification(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("handleResendVerification")
 
@@ -132,6 +133,9 @@ func (p *politeiawww) handleLogin(w http.ResponseWriter, r *http.Request) {
 
 	reply, err := p.processLogin(l)
 	if err != nil {
+		if loginCredentialsInvalid(err) {
+			p.acl.authFailure(p.acl.clientIP(r))
+		}
 		RespondWithError(w, r, http.StatusUnauthorized,
 			"handleLogin: processLogin: %v", err)
 		return
@@ -233,9 +237,9 @@ func (p *politeiawww) handleVerifyResetPassword(w http.ResponseWriter, r *http.R
 		util.RespondWithJSON(w, http.StatusOK, reply)
 		return
 	}
-	err = p.db.SessionsDeleteByUserID(user.ID, []string{})
+	err = p.sessions.DeleteUserSessions(user.ID, []string{})
 	if err != nil {
-		log.Errorf("handleVerifyResetPassword: SessionsDeleteByUserID(%v, %v): %v",
+		log.Errorf("handleVerifyResetPassword: DeleteUserSessions(%v, %v): %v",
 			user.ID, []string{}, err)
 	}
 
@@ -451,9 +455,9 @@ func (p *politeiawww) handleChangePassword(w http.ResponseWriter, r *http.Reques
 	// Delete all existing sessions for the user except the current.
 	// Return a 200 if this call fails since the password was changed
 	// correctly.
-	err = p.db.SessionsDeleteByUserID(user.ID, []string{session.ID})
+	err = p.sessions.DeleteUserSessions(user.ID, []string{session.ID})
 	if err != nil {
-		log.Errorf("handleChangePassword: SessionsDeleteByUserID(%v, %v): %v",
+		log.Errorf("handleChangePassword: DeleteUserSessions(%v, %v): %v",
 			user.ID, []string{session.ID}, err)
 	}
 
@@ -785,6 +789,37 @@ func (p *politeiawww) handleVerifyTOTP(w http.ResponseWriter, r *http.Request) {
 	util.RespondWithJSON(w, http.StatusOK, vtr)
 }
 
+// handleVerifyStake handles the verification of the stake of a user.
+func (p *politeiawww) handleVerifyStake(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleVerifyStake")
+
+	var vs www.VerifyStake
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&vs); err != nil {
+		RespondWithError(w, r, 0, "handleVerifyStake: unmarshal",
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	u, err := p.sessions.GetSessionUser(w, r)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleVerifyStake: getSessionUser %v", err)
+		return
+	}
+
+	vsr, err := p.processVerifyStake(r.Context(), vs, u)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleVerifyStake: processVerifyStake %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, vsr)
+}
+
 // setUserWWWRoutes setsup the user routes.
 func (p *politeiawww) setUserWWWRoutes() {
 	// Public routes
@@ -817,6 +852,9 @@ func (p *politeiawww) setUserWWWRoutes() {
 		permissionPublic)
 
 	// Routes that require being logged in.
+	p.addRoute(http.MethodGet, www.PoliteiaWWWAPIRoute,
+		www.RouteCSRFToken, p.handleCSRFToken,
+		permissionLogin)
 	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
 		www.RouteSecret, p.handleSecret,
 		permissionLogin)
@@ -856,6 +894,9 @@ func (p *politeiawww) setUserWWWRoutes() {
 	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
 		www.RouteVerifyTOTP, p.handleVerifyTOTP,
 		permissionLogin)
+	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
+		www.RouteVerifyStake, p.handleVerifyStake,
+		permissionLogin)
 
 	// Routes that require being logged in as an admin user.
 	p.addRoute(http.MethodPut, www.PoliteiaWWWAPIRoute,
@@ -864,6 +905,12 @@ func (p *politeiawww) setUserWWWRoutes() {
 	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
 		www.RouteManageUser, p.handleManageUser,
 		permissionAdmin)
+	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
+		www.RouteNewBot, p.handleNewBot,
+		permissionAdmin)
+	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
+		www.RouteBotAPIKey, p.handleBotAPIKey,
+		permissionAdmin)
 }
 
 // setCMSUserWWWRoutes setsup the user routes for cms mode
@@ -886,6 +933,9 @@ func (p *politeiawww) setCMSUserWWWRoutes() {
 		permissionPublic)
 
 	// Routes that require being logged in.
+	p.addRoute(http.MethodGet, www.PoliteiaWWWAPIRoute,
+		www.RouteCSRFToken, p.handleCSRFToken,
+		permissionLogin)
 	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
 		www.RouteSecret, p.handleSecret,
 		permissionLogin)
@@ -21,6 +21,7 @@ func validCommand(cmd string) bool {
 	case v1.WSCError:
 	case v1.WSCPing:
 	case v1.WSCSubscribe:
+	case v1.WSCEvent:
 	default:
 		return false
 	}
@@ -30,6 +31,11 @@ func validCommand(cmd string) bool {
 func ValidSubscription(cmd string) bool {
 	switch cmd {
 	case v1.WSCPing:
+	case v1.WSEventProposalNew:
+	case v1.WSEventProposalStatus:
+	case v1.WSEventVoteStarted:
+	case v1.WSEventVoteFinished:
+	case v1.WSEventCommentNew:
 	default:
 		return false
 	}
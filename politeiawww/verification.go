@@ -0,0 +1,281 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// verificationCheckInterval is the interval at which the expired
+	// verification tokens are cleared.
+	verificationCheckInterval = time.Hour
+)
+
+// resendThrottle limits the rate at which new user verification emails are
+// sent to an email address. The throttle is applied to every email address,
+// including the addresses that do not belong to a user, so that it can't be
+// used to determine whether an email address has an account.
+type resendThrottle struct {
+	sync.Mutex
+	interval time.Duration
+	next     map[string]time.Time // [email]Next allowed send
+}
+
+// newResendThrottle returns a new resendThrottle. The throttle is disabled
+// when the interval is zero.
+func newResendThrottle(interval time.Duration) *resendThrottle {
+	return &resendThrottle{
+		interval: interval,
+		next:     make(map[string]time.Time),
+	}
+}
+
+// throttled returns whether a verification email can't be sent to the email
+// address yet. The time at which the next email can be sent is returned when
+// true is returned.
+func (t *resendThrottle) throttled(email string, now time.Time) (time.Time, bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	next, ok := t.next[normalizeEmail(email)]
+	if ok && now.Before(next) {
+		return next, true
+	}
+	return time.Time{}, false
+}
+
+// sent records that a verification email was sent to the email address.
+func (t *resendThrottle) sent(email string, now time.Time) {
+	if t.interval == 0 {
+		return
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	t.next[normalizeEmail(email)] = now.Add(t.interval)
+}
+
+// prune removes the email addresses that are no longer throttled.
+func (t *resendThrottle) prune(now time.Time) {
+	t.Lock()
+	defer t.Unlock()
+
+	for email, next := range t.next {
+		if !now.Before(next) {
+			delete(t.next, email)
+		}
+	}
+}
+
+// normalizeEmail returns the email address in the form that is used as the
+// throttle key.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// clearExpiredVerifications clears the expired update key and reset password
+// verification tokens of the user and deactivates the user if the new user
+// verification token has been expired for longer than the retention period,
+// in seconds. Unverified users are not deactivated when the retention period
+// is zero. Returns whether the user was modified.
+func clearExpiredVerifications(u *user.User, now, retention int64) bool {
+	var modified bool
+	if u.UpdateKeyVerificationToken != nil &&
+		u.UpdateKeyVerificationExpiry < now {
+		u.UpdateKeyVerificationToken = nil
+		u.UpdateKeyVerificationExpiry = 0
+		modified = true
+	}
+	if u.ResetPasswordVerificationToken != nil &&
+		u.ResetPasswordVerificationExpiry < now {
+		u.ResetPasswordVerificationToken = nil
+		u.ResetPasswordVerificationExpiry = 0
+		modified = true
+	}
+
+	// The new user verification token can't be cleared since a nil
+	// token indicates that the user has been verified.
+	if retention > 0 && u.NewUserVerificationToken != nil &&
+		!u.Deactivated && u.NewUserVerificationExpiry+retention < now {
+		u.Deactivated = true
+		u.VerificationLapsed = now
+		modified = true
+	}
+
+	return modified
+}
+
+// reactivateLapsedUser reactivates a user that was deactivated because it was
+// not verified within the retention period. This is done when the user is
+// issued a new verification token.
+func reactivateLapsedUser(u *user.User) {
+	if u.VerificationLapsed == 0 {
+		return
+	}
+	log.Infof("Reactivating unverified user %v", u.ID)
+
+	u.Deactivated = false
+	u.VerificationLapsed = 0
+}
+
+// expireVerifications clears the expired verification tokens of all users and
+// deactivates the users that have not been verified within the retention
+// period.
+func (p *politeiawww) expireVerifications() {
+	now := time.Now()
+	p.resendThrottle.prune(now)
+
+	// The users are updated once the iteration has finished since the
+	// database can't be written to during the iteration.
+	var (
+		retention = int64(p.cfg.UnverifiedRetention) * 24 * 60 * 60
+		modified  = make([]user.User, 0, 64)
+	)
+	err := p.db.AllUsers(func(u *user.User) {
+		if clearExpiredVerifications(u, now.Unix(), retention) {
+			modified = append(modified, *u)
+		}
+	})
+	if err != nil {
+		log.Errorf("expireVerifications: AllUsers: %v", err)
+		return
+	}
+
+	for _, u := range modified {
+		err := p.db.UserUpdate(u)
+		if err != nil {
+			log.Errorf("expireVerifications: UserUpdate %v: %v", u.ID, err)
+			continue
+		}
+		if u.VerificationLapsed == now.Unix() {
+			log.Infof("Deactivated unverified user %v", u.ID)
+		}
+	}
+
+	log.Debugf("Expired verifications of %v users", len(modified))
+}
+
+// verificationLoop periodically clears the expired verification tokens. It
+// runs for the lifetime of the process.
+func (p *politeiawww) verificationLoop() {
+	p.expireVerifications()
+
+	ticker := time.NewTicker(verificationCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		p.expireVerifications()
+	}
+}
+
+// handleVerifications returns the outstanding verification tokens of all
+// users.
+func (p *politeiawww) handleVerifications(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleVerifications")
+
+	var v www.Verifications
+	err := util.ParseGetParams(r, &v)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleVerifications: ParseGetParams",
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	vr, err := p.processVerifications(v)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleVerifications: processVerifications: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, vr)
+}
+
+// processVerifications returns the outstanding verification tokens that match
+// the provided filters. The tokens of deactivated users are not returned.
+func (p *politeiawww) processVerifications(v www.Verifications) (*www.VerificationsReply, error) {
+	log.Tracef("processVerifications: %v %v", v.Type, v.ExcludeExpired)
+
+	switch v.Type {
+	case "", www.VerificationTypeNewUser, www.VerificationTypeUpdateKey,
+		www.VerificationTypeResetPassword:
+		// Valid type; continue
+	default:
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"invalid verification type"},
+		}
+	}
+
+	var (
+		now = time.Now().Unix()
+		vs  = make([]www.Verification, 0, 64)
+	)
+	add := func(u *user.User, vtype string, token []byte, expiry int64) {
+		switch {
+		case token == nil:
+			return
+		case v.Type != "" && v.Type != vtype:
+			return
+		case v.ExcludeExpired && expiry < now:
+			return
+		}
+		vs = append(vs, www.Verification{
+			UserID:   u.ID.String(),
+			Username: u.Username,
+			Email:    u.Email,
+			Type:     vtype,
+			Expiry:   expiry,
+			Expired:  expiry < now,
+		})
+	}
+	err := p.db.AllUsers(func(u *user.User) {
+		if u.Deactivated {
+			return
+		}
+		add(u, www.VerificationTypeNewUser,
+			u.NewUserVerificationToken, u.NewUserVerificationExpiry)
+		add(u, www.VerificationTypeUpdateKey,
+			u.UpdateKeyVerificationToken, u.UpdateKeyVerificationExpiry)
+		add(u, www.VerificationTypeResetPassword,
+			u.ResetPasswordVerificationToken,
+			u.ResetPasswordVerificationExpiry)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(vs, func(i, j int) bool {
+		return vs[i].Expiry < vs[j].Expiry
+	})
+
+	return &www.VerificationsReply{
+		Verifications: vs,
+	}, nil
+}
+
+// throttledResendError returns the user error that is returned when a new
+// user verification email is requested before the resend interval has
+// elapsed. The error context contains the UNIX timestamp at which the next
+// email can be requested.
+func throttledResendError(next time.Time) error {
+	return www.UserError{
+		ErrorCode:    www.ErrorStatusVerificationResendThrottled,
+		ErrorContext: []string{strconv.FormatInt(next.Unix(), 10)},
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/user"
+)
+
+func TestClearExpiredVerifications(t *testing.T) {
+	var (
+		now       int64 = 1000000
+		retention int64 = 1000
+		token           = []byte("token")
+	)
+	var tests = []struct {
+		name        string
+		user        user.User
+		modified    bool
+		deactivated bool
+	}{
+		{
+			"unexpired tokens",
+			user.User{
+				NewUserVerificationToken:        token,
+				NewUserVerificationExpiry:       now + 1,
+				UpdateKeyVerificationToken:      token,
+				UpdateKeyVerificationExpiry:     now + 1,
+				ResetPasswordVerificationToken:  token,
+				ResetPasswordVerificationExpiry: now + 1,
+			},
+			false,
+			false,
+		},
+		{
+			"expired update key token",
+			user.User{
+				UpdateKeyVerificationToken:  token,
+				UpdateKeyVerificationExpiry: now - 1,
+			},
+			true,
+			false,
+		},
+		{
+			"expired reset password token",
+			user.User{
+				ResetPasswordVerificationToken:  token,
+				ResetPasswordVerificationExpiry: now - 1,
+			},
+			true,
+			false,
+		},
+		{
+			"unverified within retention",
+			user.User{
+				NewUserVerificationToken:  token,
+				NewUserVerificationExpiry: now - retention,
+			},
+			false,
+			false,
+		},
+		{
+			"unverified past retention",
+			user.User{
+				NewUserVerificationToken:  token,
+				NewUserVerificationExpiry: now - retention - 1,
+			},
+			true,
+			true,
+		},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			u := v.user
+			modified := clearExpiredVerifications(&u, now, retention)
+			if modified != v.modified {
+				t.Fatalf("got modified %v, want %v", modified, v.modified)
+			}
+			if u.Deactivated != v.deactivated {
+				t.Fatalf("got deactivated %v, want %v",
+					u.Deactivated, v.deactivated)
+			}
+			if u.UpdateKeyVerificationExpiry < now &&
+				u.UpdateKeyVerificationToken != nil {
+				t.Fatalf("expired update key token was not cleared")
+			}
+			if u.ResetPasswordVerificationExpiry < now &&
+				u.ResetPasswordVerificationToken != nil {
+				t.Fatalf("expired reset password token was not cleared")
+			}
+			if u.NewUserVerificationToken == nil &&
+				v.user.NewUserVerificationToken != nil {
+				t.Fatalf("new user verification token was cleared")
+			}
+
+			// Issuing a new token reactivates a lapsed user
+			reactivateLapsedUser(&u)
+			if u.Deactivated || u.VerificationLapsed != 0 {
+				t.Fatalf("lapsed user was not reactivated")
+			}
+		})
+	}
+
+	// Unverified users are not deactivated when the retention is zero
+	u := user.User{
+		NewUserVerificationToken:  token,
+		NewUserVerificationExpiry: 1,
+	}
+	if clearExpiredVerifications(&u, now, 0) {
+		t.Fatalf("unverified user deactivated with zero retention")
+	}
+}
+
+func TestResendThrottle(t *testing.T) {
+	var (
+		now   = time.Unix(1000000, 0)
+		email = "user@example.com"
+	)
+	rt := newResendThrottle(time.Minute)
+	if _, ok := rt.throttled(email, now); ok {
+		t.Fatalf("unsent email is throttled")
+	}
+	rt.sent(email, now)
+
+	// The email address is case insensitive
+	next, ok := rt.throttled("User@Example.com", now.Add(time.Second))
+	if !ok {
+		t.Fatalf("email is not throttled")
+	}
+	if !next.Equal(now.Add(time.Minute)) {
+		t.Fatalf("got next %v, want %v", next, now.Add(time.Minute))
+	}
+	if _, ok := rt.throttled(email, now.Add(time.Minute)); ok {
+		t.Fatalf("email is throttled after the interval")
+	}
+
+	rt.prune(now.Add(time.Minute))
+	if len(rt.next) != 0 {
+		t.Fatalf("throttle was not pruned")
+	}
+
+	// A zero interval disables the throttle
+	rt = newResendThrottle(0)
+	rt.sent(email, now)
+	if _, ok := rt.throttled(email, now); ok {
+		t.Fatalf("email is throttled with a zero interval")
+	}
+}
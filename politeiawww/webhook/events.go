@@ -0,0 +1,185 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	"github.com/decred/politeia/politeiawww/comments"
+	"github.com/decred/politeia/politeiawww/events"
+	"github.com/decred/politeia/politeiawww/records"
+	"github.com/decred/politeia/politeiawww/ticketvote"
+)
+
+const (
+	// The following routes are used to create the GUI links that are
+	// included in the payloads.
+	guiRouteRecordDetails = "/record/{token}"
+	guiRouteRecordComment = "/record/{token}/comments/{id}"
+)
+
+// recordLink returns the GUI link of a record.
+func (c *Client) recordLink(token string) string {
+	return c.webServerAddress +
+		strings.Replace(guiRouteRecordDetails, "{token}", token, 1)
+}
+
+// commentLink returns the GUI link of a comment.
+func (c *Client) commentLink(token string, commentID uint32) string {
+	route := strings.Replace(guiRouteRecordComment, "{token}", token, 1)
+	route = strings.Replace(route, "{id}",
+		strconv.FormatUint(uint64(commentID), 10), 1)
+	return c.webServerAddress + route
+}
+
+// setupEventListeners registers the webhook event listeners with the event
+// manager. The handlers only queue the deliveries so that the event manager
+// is never blocked by a webhook endpoint.
+func (c *Client) setupEventListeners(e *events.Manager) {
+	log.Debugf("Setting up webhook event listeners")
+
+	// Record new
+	ch := make(chan interface{})
+	e.Register(records.EventTypeNew, ch)
+	go c.handleEventRecordNew(ch)
+
+	// Record set status
+	ch = make(chan interface{})
+	e.Register(records.EventTypeSetStatus, ch)
+	go c.handleEventRecordSetStatus(ch)
+
+	// Comment new
+	ch = make(chan interface{})
+	e.Register(comments.EventTypeNew, ch)
+	go c.handleEventCommentNew(ch)
+
+	// Ticket vote started
+	ch = make(chan interface{})
+	e.Register(ticketvote.EventTypeStart, ch)
+	go c.handleEventVoteStarted(ch)
+
+	// Ticket vote finished
+	ch = make(chan interface{})
+	e.Register(ticketvote.EventTypeFinished, ch)
+	go c.handleEventVoteFinished(ch)
+}
+
+func (c *Client) handleEventRecordNew(ch chan interface{}) {
+	for msg := range ch {
+		e, ok := msg.(records.EventNew)
+		if !ok {
+			log.Errorf("handleEventRecordNew invalid msg: %v", msg)
+			continue
+		}
+
+		token := e.Record.CensorshipRecord.Token
+		c.dispatch(EventProposalNew, e.Record.Timestamp, Proposal{
+			Token:    token,
+			Username: e.User.Username,
+			Link:     c.recordLink(token),
+		})
+	}
+}
+
+func (c *Client) handleEventRecordSetStatus(ch chan interface{}) {
+	for msg := range ch {
+		e, ok := msg.(records.EventSetStatus)
+		if !ok {
+			log.Errorf("handleEventRecordSetStatus invalid msg: %v", msg)
+			continue
+		}
+		if e.Record.Status != rcv1.RecordStatusPublic {
+			continue
+		}
+
+		token := e.Record.CensorshipRecord.Token
+		c.dispatch(EventProposalPublished, e.Record.Timestamp, Proposal{
+			Token:    token,
+			Username: e.Record.Username,
+			Link:     c.recordLink(token),
+		})
+	}
+}
+
+func (c *Client) handleEventCommentNew(ch chan interface{}) {
+	for msg := range ch {
+		e, ok := msg.(comments.EventNew)
+		if !ok {
+			log.Errorf("handleEventCommentNew invalid msg: %v", msg)
+			continue
+		}
+
+		// Comments on unvetted records are not public
+		if e.State != cmv1.RecordStateVetted {
+			continue
+		}
+
+		event := EventCommentNew
+		if e.Comment.ParentID != 0 {
+			event = EventCommentReply
+		}
+		cm := e.Comment
+		c.dispatch(event, cm.Timestamp, Comment{
+			Token:     cm.Token,
+			CommentID: cm.CommentID,
+			ParentID:  cm.ParentID,
+			Username:  cm.Username,
+			Link:      c.commentLink(cm.Token, cm.CommentID),
+		})
+	}
+}
+
+func (c *Client) handleEventVoteStarted(ch chan interface{}) {
+	for msg := range ch {
+		e, ok := msg.(ticketvote.EventStart)
+		if !ok {
+			log.Errorf("handleEventVoteStarted invalid msg: %v", msg)
+			continue
+		}
+
+		for _, v := range e.Starts {
+			vp := v.Params
+			c.dispatch(EventVoteStarted, time.Now().Unix(), Vote{
+				Token:            vp.Token,
+				Type:             tkv1.VoteTypes[vp.Type],
+				Duration:         vp.Duration,
+				QuorumPercentage: vp.QuorumPercentage,
+				PassPercentage:   vp.PassPercentage,
+				Link:             c.recordLink(vp.Token),
+			})
+		}
+	}
+}
+
+func (c *Client) handleEventVoteFinished(ch chan interface{}) {
+	for msg := range ch {
+		e, ok := msg.(ticketvote.EventFinished)
+		if !ok {
+			log.Errorf("handleEventVoteFinished invalid msg: %v", msg)
+			continue
+		}
+
+		vc := e.Certificate.Certificate
+		c.dispatch(EventVoteFinished, time.Now().Unix(), Vote{
+			Token:            vc.Token,
+			Type:             tkv1.VoteTypes[vc.Type],
+			Duration:         vc.EndBlockHeight - vc.StartBlockHeight,
+			QuorumPercentage: vc.QuorumPercentage,
+			PassPercentage:   vc.PassPercentage,
+			Link:             c.recordLink(vc.Token),
+			Status:           tkv1.VoteStatuses[vc.Status],
+			StartBlockHeight: vc.StartBlockHeight,
+			EndBlockHeight:   vc.EndBlockHeight,
+			EligibleTickets:  vc.EligibleTickets,
+			TotalVotes:       vc.TotalVotes,
+			ApproveVotes:     vc.ApproveVotes,
+		})
+	}
+}
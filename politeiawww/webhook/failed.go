@@ -0,0 +1,155 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+const (
+	// failedFilenameFmt is the name format of the file in the data
+	// directory that the failed deliveries of a client are persisted
+	// to. The name of the client is inserted so that the webhook and
+	// the treasury clients use separate files.
+	failedFilenameFmt = "%vfailed.json"
+
+	// failedMax is the number of failed notifications whose deliveries
+	// are kept so that they can be retried.
+	failedMax = 256
+)
+
+// failedDelivery is a webhook delivery that failed permanently. This is a
+// JSON structure so that the failed deliveries can be persisted to disk.
+type failedDelivery struct {
+	URL   string `json:"url"`
+	Event string `json:"event"`
+	Body  []byte `json:"body"`
+}
+
+// failedNotification contains the failed deliveries of a notification.
+type failedNotification struct {
+	ID         string           `json:"id"`
+	Deliveries []failedDelivery `json:"deliveries"`
+}
+
+// failedStore contains the failed deliveries of the most recent failedMax
+// notifications so that they can be retried. The failed deliveries are
+// persisted to disk on every change so that they survive a restart.
+type failedStore struct {
+	sync.Mutex
+	path  string               // Not persisted when empty
+	ntfns []failedNotification // Oldest first
+}
+
+// newFailedStore returns a new failedStore that is loaded from the provided
+// file. The file is created on the first failed delivery if it does not exist.
+// The failed deliveries are only kept in memory if the path is empty.
+func newFailedStore(path string) (*failedStore, error) {
+	fs := failedStore{
+		path:  path,
+		ntfns: make([]failedNotification, 0, failedMax),
+	}
+	if path == "" {
+		return &fs, nil
+	}
+	b, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return &fs, nil
+	case err != nil:
+		return nil, err
+	}
+	err = json.Unmarshal(b, &fs.ntfns)
+	if err != nil {
+		return nil, fmt.Errorf("decode %v: %v", path, err)
+	}
+	return &fs, nil
+}
+
+// save writes the failed deliveries to disk. The file is replaced atomically.
+//
+// This function must be called WITH the lock held.
+func (fs *failedStore) save() error {
+	if fs.path == "" {
+		return nil
+	}
+	b, err := json.Marshal(fs.ntfns)
+	if err != nil {
+		return err
+	}
+	tmp := fs.path + ".tmp"
+	err = ioutil.WriteFile(tmp, b, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, fs.path)
+}
+
+// add adds a delivery that failed permanently. The deliveries of the oldest
+// notification are dropped once failedMax notifications are kept.
+func (fs *failedStore) add(d delivery) error {
+	fs.Lock()
+	defer fs.Unlock()
+
+	fd := failedDelivery{
+		URL:   d.url,
+		Event: d.event,
+		Body:  d.body,
+	}
+	for i, v := range fs.ntfns {
+		if v.ID == d.id {
+			fs.ntfns[i].Deliveries = append(v.Deliveries, fd)
+			return fs.save()
+		}
+	}
+	if len(fs.ntfns) >= failedMax {
+		fs.ntfns = append(fs.ntfns[:0:0], fs.ntfns[1:]...)
+	}
+	fs.ntfns = append(fs.ntfns, failedNotification{
+		ID:         d.id,
+		Deliveries: []failedDelivery{fd},
+	})
+
+	return fs.save()
+}
+
+// del removes the failed deliveries of a notification and returns them. The
+// returned deliveries have their attempts reset. ErrNotFound is returned if
+// there are no failed deliveries for the notification.
+func (fs *failedStore) del(id string) ([]delivery, error) {
+	fs.Lock()
+	defer fs.Unlock()
+
+	for i, v := range fs.ntfns {
+		if v.ID != id {
+			continue
+		}
+		ntfns := append(fs.ntfns[:i:i], fs.ntfns[i+1:]...)
+		prev := fs.ntfns
+		fs.ntfns = ntfns
+		err := fs.save()
+		if err != nil {
+			fs.ntfns = prev
+			return nil, err
+		}
+
+		ds := make([]delivery, 0, len(v.Deliveries))
+		for _, fd := range v.Deliveries {
+			ds = append(ds, delivery{
+				url:   fd.URL,
+				id:    id,
+				event: fd.Event,
+				body:  fd.Body,
+			})
+		}
+		return ds, nil
+	}
+
+	return nil, ErrNotFound
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+)
+
+const (
+	// EventProposalNew is sent when a new proposal is submitted. The
+	// proposal is unvetted at this point so only its token and the
+	// author are included. The data is a Proposal.
+	EventProposalNew = "proposal-new"
+
+	// EventProposalPublished is sent when a proposal is made public.
+	// The data is a Proposal.
+	EventProposalPublished = "proposal-published"
+
+	// EventVoteStarted is sent when the voting period of a proposal
+	// starts. The data is a Vote.
+	EventVoteStarted = "vote-started"
+
+	// EventVoteFinished is sent when the voting period of a proposal
+	// has ended. The data is a Vote.
+	EventVoteFinished = "vote-finished"
+
+	// EventCommentNew is sent when a new top level comment is made on
+	// a public proposal. The data is a Comment.
+	EventCommentNew = "comment-new"
+
+	// EventCommentReply is sent when a reply to a comment is made on a
+	// public proposal. The data is a Comment.
+	EventCommentReply = "comment-reply"
+
+	// HeaderEvent is the header that contains the event of the
+	// payload.
+	HeaderEvent = "X-Politeia-Event"
+
+	// HeaderDelivery is the header that contains the unique delivery
+	// ID of the payload. The ID is the same for all delivery attempts
+	// of a payload and can be used to detect duplicate deliveries.
+	HeaderDelivery = "X-Politeia-Delivery"
+
+	// HeaderSignature is the header that contains the hex encoded
+	// HMAC-SHA256 of the request body using the shared webhook secret.
+	HeaderSignature = "X-Politeia-Signature"
+)
+
+var (
+	// Events contains all webhook events.
+	Events = []string{
+		EventProposalNew,
+		EventProposalPublished,
+		EventVoteStarted,
+		EventVoteFinished,
+		EventCommentNew,
+		EventCommentReply,
+	}
+)
+
+// Payload is the JSON encoded request body of a webhook notification.
+type Payload struct {
+	ID        string      `json:"id"`        // Unique delivery ID
+	Event     string      `json:"event"`     // Event type
+	Timestamp int64       `json:"timestamp"` // UNIX time of the event
+	Data      interface{} `json:"data"`      // Event data
+}
+
+// Proposal is the event data of the proposal events.
+type Proposal struct {
+	Token    string `json:"token"`
+	Username string `json:"username"` // Author username
+	Link     string `json:"link"`     // GUI proposal details URL
+}
+
+// Vote is the event data of the vote events. The vote results are only
+// included in the EventVoteFinished data.
+type Vote struct {
+	Token            string `json:"token"`
+	Type             string `json:"type"`
+	Duration         uint32 `json:"duration"` // In blocks
+	QuorumPercentage uint32 `json:"quorumpercentage"`
+	PassPercentage   uint32 `json:"passpercentage"`
+	Link             string `json:"link"` // GUI proposal details URL
+
+	// Vote results
+	Status           string `json:"status,omitempty"`
+	StartBlockHeight uint32 `json:"startblockheight,omitempty"`
+	EndBlockHeight   uint32 `json:"endblockheight,omitempty"`
+	EligibleTickets  uint32 `json:"eligibletickets,omitempty"`
+	TotalVotes       uint64 `json:"totalvotes,omitempty"`
+	ApproveVotes     uint64 `json:"approvevotes,omitempty"`
+}
+
+// Comment is the event data of the comment events.
+type Comment struct {
+	Token     string `json:"token"`
+	CommentID uint32 `json:"commentid"`
+	ParentID  uint32 `json:"parentid"` // 0 if top level comment
+	Username  string `json:"username"` // Comment author username
+	Link      string `json:"link"`     // GUI comment URL
+}
+
+const (
+	// EventProposalApproved is sent to the treasury endpoint when the
+	// vote of a proposal has finished and the proposal was approved.
+	// The data is a TreasuryProposal.
+	EventProposalApproved = "proposal-approved"
+)
+
+// TreasuryAuthor contains the proposal author details that the treasury
+// operations need to handle the payout of an approved proposal.
+type TreasuryAuthor struct {
+	UserID    string `json:"userid"`
+	Username  string `json:"username"`
+	PublicKey string `json:"publickey"` // Key that signed the proposal
+}
+
+// TreasuryProposal is the event data of the EventProposalApproved event that
+// is sent to the treasury endpoint. Certificate and Signature are the vote
+// certificate of the proposal and its politeiawww signature, which allow the
+// treasury tooling to verify the vote outcome independently of the webhook
+// signature.
+type TreasuryProposal struct {
+	Token       string               `json:"token"`
+	Version     uint32               `json:"version"` // Approved version
+	Name        string               `json:"name"`
+	Link        string               `json:"link"` // GUI proposal details URL
+	Author      TreasuryAuthor       `json:"author"`
+	Certificate tkv1.VoteCertificate `json:"certificate"`
+	Signature   string               `json:"signature"`
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"time"
+
+	"github.com/decred/politeia/politeiawww/config"
+	"github.com/decred/politeia/politeiawww/events"
+	"github.com/decred/politeia/politeiawww/pi"
+)
+
+// NewTreasury returns a new webhook Client that sends the approved proposals
+// to the treasury operations endpoint. The deliveries are signed using the
+// treasury secret. nil is returned if a treasury URL has not been configured.
+//
+// The treasury deliveries are not routed through the notification routes
+// since they hand off a proposal to the treasury tooling and must not be
+// disabled by a notifier setting.
+func NewTreasury(cfg *config.Config, e *events.Manager) (*Client, error) {
+	if cfg.TreasuryURL == "" {
+		return nil, nil
+	}
+
+	enabled := map[string]struct{}{
+		EventProposalApproved: {},
+	}
+	c, err := newClient(cfg, e, "treasury", []string{cfg.TreasuryURL},
+		cfg.TreasurySecret, enabled)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan interface{})
+	e.Register(pi.EventTypeProposalApproved, ch)
+	go c.handleEventProposalApproved(ch)
+
+	log.Infof("Treasury handoff enabled to %v", cfg.TreasuryURL)
+
+	return c, nil
+}
+
+func (c *Client) handleEventProposalApproved(ch chan interface{}) {
+	for msg := range ch {
+		e, ok := msg.(pi.EventProposalApproved)
+		if !ok {
+			log.Errorf("handleEventProposalApproved invalid msg: %v", msg)
+			continue
+		}
+
+		vc := e.Certificate.Certificate
+		c.notify(EventProposalApproved, time.Now().Unix(), TreasuryProposal{
+			Token:   vc.Token,
+			Version: vc.Version,
+			Name:    e.Name,
+			Link:    c.recordLink(vc.Token),
+			Author: TreasuryAuthor{
+				UserID:    e.AuthorID,
+				Username:  e.AuthorUsername,
+				PublicKey: e.AuthorPubKey,
+			},
+			Certificate: vc,
+			Signature:   e.Certificate.Signature,
+		})
+
+		log.Debugf("Treasury handoff queued %v", vc.Token)
+	}
+}
@@ -0,0 +1,393 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/config"
+	"github.com/decred/politeia/politeiawww/events"
+	"github.com/decred/politeia/util"
+	"github.com/google/uuid"
+)
+
+const (
+	// queueSize is the number of deliveries that can be waiting to be
+	// sent. Deliveries are not queued when the queue is full so that
+	// slow webhook endpoints are not able to block the event manager.
+	// They are saved as failed deliveries instead so that they can be
+	// retried.
+	queueSize = 1024
+
+	// workers is the number of deliveries that are sent concurrently.
+	workers = 4
+
+	// retryDelay is the delay before the first retry of a failed
+	// delivery. The delay is doubled on every retry.
+	retryDelay = 5 * time.Second
+
+	// timeout is the timeout of a single delivery attempt.
+	timeout = 10 * time.Second
+
+	// NotifierWebhook is the name of the webhook notifier.
+	NotifierWebhook = "webhook"
+)
+
+var (
+	// ErrNotFound is returned by Retry when there are no failed
+	// deliveries for the notification.
+	ErrNotFound = errors.New("failed delivery not found")
+
+	// errQueueFull is recorded for the deliveries that could not be
+	// queued because the queue was full.
+	errQueueFull = errors.New("queue full")
+
+	// errNoRetry is returned by post when the webhook endpoint rejected
+	// the payload. The delivery is not retried since it will fail
+	// again.
+	errNoRetry = errors.New("payload rejected")
+)
+
+// delivery is a webhook notification that is sent to a single URL.
+type delivery struct {
+	url     string
+	id      string
+	event   string
+	body    []byte
+	attempt uint32
+}
+
+// Recorder records the send attempts of the webhook deliveries.
+type Recorder interface {
+	Record([]www.NotificationLogEntry) error
+}
+
+// Client sends webhook notifications for events that are emitted by the
+// politeiawww event manager.
+type Client struct {
+	urls             []string
+	secret           []byte
+	events           map[string]struct{}
+	retries          uint32
+	retryDelay       time.Duration
+	webServerAddress string
+	http             *http.Client
+	queue            chan delivery
+	manager          *events.Manager
+	sendLog          Recorder // Optional
+
+	// failed contains the deliveries that failed permanently so that
+	// they can be retried.
+	failed *failedStore
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the payload using the provided
+// secret. Webhook receivers use it to verify the HeaderSignature of a
+// request.
+func Sign(secret, payload []byte) string {
+	h := hmac.New(sha256.New, secret)
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Send satisfies the events.Notifier interface. Notifications that do not
+// contain event data, e.g. email notifications that share an event name with
+// a webhook event, are ignored.
+func (c *Client) Send(event string, recipients []events.Recipient, n events.Notification) error {
+	if n.Data == nil {
+		return nil
+	}
+	c.notify(event, n.Timestamp, n.Data)
+	return nil
+}
+
+// dispatch sends the webhook event to the notifiers that the event is routed
+// to. The event is routed to the webhook notifier by default.
+func (c *Client) dispatch(event string, timestamp int64, data interface{}) {
+	err := c.manager.Notify(event, nil, events.Notification{
+		Timestamp: timestamp,
+		Data:      data,
+	})
+	if err != nil {
+		log.Errorf("webhook dispatch %v: %v", event, err)
+	}
+}
+
+// notify sends a webhook notification for the event to all URLs. The
+// notification is dropped if the event is not enabled.
+func (c *Client) notify(event string, timestamp int64, data interface{}) {
+	if _, ok := c.events[event]; !ok {
+		return
+	}
+
+	id := uuid.New().String()
+	body, err := json.Marshal(Payload{
+		ID:        id,
+		Event:     event,
+		Timestamp: timestamp,
+		Data:      data,
+	})
+	if err != nil {
+		log.Errorf("webhook notify %v: %v", event, err)
+		return
+	}
+	for _, v := range c.urls {
+		c.enqueue(delivery{
+			url:   v,
+			id:    id,
+			event: event,
+			body:  body,
+		})
+	}
+}
+
+// enqueue adds a delivery to the queue. A delivery that can't be queued
+// because the queue is full is saved as a failed delivery so that it can be
+// retried. It returns whether the delivery was queued.
+func (c *Client) enqueue(d delivery) bool {
+	select {
+	case c.queue <- d:
+		return true
+	default:
+	}
+
+	log.Errorf("Webhook queue full; %v %v to %v failed", d.event, d.id, d.url)
+	c.record(d, www.NotificationStatusFailed, errQueueFull)
+	c.saveFailed(d)
+
+	return false
+}
+
+// SetSendLog sets the recorder that the delivery attempts are recorded to.
+// This must be set prior to the events being emitted.
+func (c *Client) SetSendLog(r Recorder) {
+	c.sendLog = r
+}
+
+// Retry resends the failed deliveries of a notification with a fresh set of
+// attempts. ErrNotFound is returned if there are no failed deliveries for the
+// notification. An error is returned if any of the deliveries could not be
+// queued. Those deliveries remain failed deliveries that can be retried.
+func (c *Client) Retry(id string) error {
+	ds, err := c.failed.del(id)
+	if err != nil {
+		return err
+	}
+	var notQueued int
+	for _, v := range ds {
+		if !c.enqueue(v) {
+			notQueued++
+		}
+	}
+	if notQueued > 0 {
+		return fmt.Errorf("%v of %v deliveries not queued: %v",
+			notQueued, len(ds), errQueueFull)
+	}
+	return nil
+}
+
+// saveFailed saves a delivery that failed permanently so that it can be
+// retried.
+func (c *Client) saveFailed(d delivery) {
+	err := c.failed.add(d)
+	if err != nil {
+		log.Errorf("Webhook %v %v save failed delivery: %v",
+			d.event, d.id, err)
+	}
+}
+
+// record records a delivery attempt in the send log. The query string of the
+// URL is not recorded since it may contain credentials.
+func (c *Client) record(d delivery, status string, err error) {
+	if c.sendLog == nil {
+		return
+	}
+	recipient := d.url
+	if u, err := url.Parse(d.url); err == nil {
+		u.RawQuery = ""
+		u.User = nil
+		recipient = u.String()
+	}
+	e := www.NotificationLogEntry{
+		MessageID: d.id,
+		Event:     d.event,
+		Channel:   www.NotificationChannelWebhook,
+		Recipient: recipient,
+		Status:    status,
+		Attempt:   d.attempt + 1,
+		Timestamp: time.Now().Unix(),
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	err = c.sendLog.Record([]www.NotificationLogEntry{e})
+	if err != nil {
+		log.Errorf("Send log %v: %v", d.id, err)
+	}
+}
+
+// send sends a single delivery attempt. A delivery that failed because of a
+// network error, a server error, or rate limiting is retried using an
+// exponential backoff until the maximum number of retries is reached.
+func (c *Client) send(d delivery) {
+	err := c.post(d)
+	if err == nil {
+		c.record(d, www.NotificationStatusSent, nil)
+		log.Debugf("Webhook %v %v delivered to %v", d.event, d.id, d.url)
+		return
+	}
+	if err == errNoRetry || d.attempt >= c.retries {
+		c.record(d, www.NotificationStatusFailed, err)
+		c.saveFailed(d)
+		log.Errorf("Webhook %v %v to %v failed: %v", d.event, d.id,
+			d.url, err)
+		return
+	}
+	c.record(d, www.NotificationStatusRetrying, err)
+
+	delay := c.retryDelay << d.attempt
+	log.Debugf("Webhook %v %v to %v failed, retrying in %v: %v",
+		d.event, d.id, d.url, delay, err)
+
+	d.attempt++
+	time.AfterFunc(delay, func() {
+		c.enqueue(d)
+	})
+}
+
+// post POSTs the delivery payload to the webhook URL.
+func (c *Client) post(d delivery) error {
+	req, err := http.NewRequest(http.MethodPost, d.url,
+		bytes.NewReader(d.body))
+	if err != nil {
+		return errNoRetry
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, d.event)
+	req.Header.Set(HeaderDelivery, d.id)
+	req.Header.Set(HeaderSignature, Sign(c.secret, d.body))
+
+	r, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	// The reply body is not used, but it must be read in order for the
+	// connection to be reused.
+	io.Copy(ioutil.Discard, io.LimitReader(r.Body, 1<<16))
+
+	switch {
+	case r.StatusCode >= 200 && r.StatusCode < 300:
+		return nil
+	case r.StatusCode == http.StatusTooManyRequests,
+		r.StatusCode >= 500:
+		return fmt.Errorf("%v", r.Status)
+	default:
+		log.Debugf("Webhook %v %v to %v: %v", d.event, d.id, d.url,
+			r.Status)
+		return errNoRetry
+	}
+}
+
+// worker sends the queued deliveries.
+func (c *Client) worker() {
+	for d := range c.queue {
+		c.send(d)
+	}
+}
+
+// newClient returns a new Client that sends the enabled events to the provided
+// URLs and starts its delivery workers. The name of the client is used to name
+// the file that its failed deliveries are persisted to.
+func newClient(cfg *config.Config, e *events.Manager, name string, urls []string, secret string, enabled map[string]struct{}) (*Client, error) {
+	httpClient, err := util.NewHTTPClient(false, "")
+	if err != nil {
+		return nil, err
+	}
+	httpClient.Timeout = timeout
+	failed, err := newFailedStore(filepath.Join(cfg.DataDir,
+		fmt.Sprintf(failedFilenameFmt, name)))
+	if err != nil {
+		return nil, err
+	}
+
+	c := Client{
+		urls:             urls,
+		secret:           []byte(secret),
+		events:           enabled,
+		retries:          cfg.WebhookRetries,
+		retryDelay:       retryDelay,
+		webServerAddress: cfg.WebServerAddress,
+		http:             httpClient,
+		queue:            make(chan delivery, queueSize),
+		manager:          e,
+		failed:           failed,
+	}
+	for i := 0; i < workers; i++ {
+		go c.worker()
+	}
+
+	return &c, nil
+}
+
+// New returns a new webhook Client and registers its event listeners. nil is
+// returned if no webhook URLs have been configured.
+func New(cfg *config.Config, e *events.Manager) (*Client, error) {
+	if len(cfg.WebhookURLs) == 0 {
+		return nil, nil
+	}
+
+	// Setup the enabled events
+	enabled := make(map[string]struct{}, len(Events))
+	if len(cfg.WebhookEvents) == 0 {
+		for _, v := range Events {
+			enabled[v] = struct{}{}
+		}
+	}
+	for _, v := range cfg.WebhookEvents {
+		var found bool
+		for _, event := range Events {
+			if v == event {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("invalid webhook event %v", v)
+		}
+		enabled[v] = struct{}{}
+	}
+
+	c, err := newClient(cfg, e, NotifierWebhook, cfg.WebhookURLs,
+		cfg.WebhookSecret, enabled)
+	if err != nil {
+		return nil, err
+	}
+	c.setupEventListeners(e)
+
+	// Register the webhook notifier and route the webhook events to it
+	e.RegisterNotifier(NotifierWebhook, c)
+	for _, v := range Events {
+		e.Route(v, NotifierWebhook)
+	}
+
+	log.Infof("Webhook notifications enabled for %v URLs", len(c.urls))
+
+	return c, nil
+}
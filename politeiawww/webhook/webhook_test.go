@@ -0,0 +1,255 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDelivery(t *testing.T) {
+	var (
+		secret = []byte("secret")
+
+		mtx      sync.Mutex
+		attempts int
+		done     = make(chan Payload, 1)
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if r.Header.Get(HeaderSignature) != Sign(secret, body) {
+			t.Errorf("invalid signature")
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		// Fail the first attempt in order to test the retry
+		mtx.Lock()
+		attempts++
+		n := attempts
+		mtx.Unlock()
+		if n == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var p Payload
+		err = json.Unmarshal(body, &p)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if r.Header.Get(HeaderDelivery) != p.ID {
+			t.Errorf("delivery header mismatch")
+		}
+		done <- p
+	}))
+	defer s.Close()
+
+	c := Client{
+		urls:       []string{s.URL},
+		secret:     secret,
+		events:     map[string]struct{}{EventCommentReply: {}},
+		retries:    1,
+		retryDelay: time.Millisecond,
+		http:       s.Client(),
+		queue:      make(chan delivery, queueSize),
+	}
+	go c.worker()
+
+	// Events that are not enabled are not sent
+	c.notify(EventCommentNew, 1, Comment{})
+	c.notify(EventCommentReply, 2, Comment{ParentID: 1})
+
+	select {
+	case p := <-done:
+		if p.Event != EventCommentReply || p.Timestamp != 2 {
+			t.Fatalf("got event %v %v, want %v 2", p.Event, p.Timestamp,
+				EventCommentReply)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("webhook not delivered")
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	if attempts != 2 {
+		t.Fatalf("got %v attempts, want 2", attempts)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "webhook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		mtx    sync.Mutex
+		reject = true
+		done   = make(chan Payload, 1)
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Reject the payload until the delivery is retried
+		mtx.Lock()
+		rj := reject
+		mtx.Unlock()
+		if rj {
+			w.WriteHeader(http.StatusBadRequest)
+			done <- Payload{}
+			return
+		}
+
+		var p Payload
+		err := json.NewDecoder(r.Body).Decode(&p)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		done <- p
+	}))
+	defer s.Close()
+
+	path := filepath.Join(dir, "webhookfailed.json")
+	failed, err := newFailedStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := Client{
+		urls:       []string{s.URL},
+		events:     map[string]struct{}{EventCommentNew: {}},
+		retryDelay: time.Millisecond,
+		http:       s.Client(),
+		queue:      make(chan delivery, queueSize),
+		failed:     failed,
+	}
+	go c.worker()
+
+	// A rejected delivery fails permanently
+	c.notify(EventCommentNew, 1, Comment{})
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("webhook not sent")
+	}
+
+	// The failed delivery is persisted. The delivery attempt is
+	// saved after the reply has been received so wait for it.
+	var id string
+	for i := 0; i < 100 && id == ""; i++ {
+		c.failed.Lock()
+		if len(c.failed.ntfns) == 1 {
+			id = c.failed.ntfns[0].ID
+		}
+		c.failed.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	}
+	if id == "" {
+		t.Fatalf("failed delivery not saved")
+	}
+	reloaded, err := newFailedStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.ntfns) != 1 || reloaded.ntfns[0].ID != id ||
+		len(reloaded.ntfns[0].Deliveries) != 1 {
+		t.Fatalf("got failed deliveries %+v, want %v", reloaded.ntfns, id)
+	}
+
+	// A notification without failed deliveries can't be retried
+	err = c.Retry("unknown")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("got err %v, want %v", err, ErrNotFound)
+	}
+
+	// A retry of the reloaded store resends the failed delivery and
+	// removes it from disk
+	c.failed = reloaded
+	mtx.Lock()
+	reject = false
+	mtx.Unlock()
+	err = c.Retry(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case p := <-done:
+		if p.ID != id || p.Event != EventCommentNew {
+			t.Fatalf("got payload %v %v, want %v %v", p.ID, p.Event,
+				id, EventCommentNew)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("webhook not retried")
+	}
+	reloaded, err = newFailedStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.ntfns) != 0 {
+		t.Fatalf("got failed deliveries %+v, want none", reloaded.ntfns)
+	}
+	err = c.Retry(id)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("got err %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestRetryQueueFull(t *testing.T) {
+	failed, err := newFailedStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := Client{
+		urls:   []string{"http://127.0.0.1/a", "http://127.0.0.1/b"},
+		events: map[string]struct{}{EventCommentNew: {}},
+		queue:  make(chan delivery, 1),
+		failed: failed,
+	}
+
+	// The delivery that does not fit in the queue is saved as a failed
+	// delivery
+	c.notify(EventCommentNew, 1, Comment{})
+	if len(c.queue) != 1 {
+		t.Fatalf("got %v queued deliveries, want 1", len(c.queue))
+	}
+	if len(failed.ntfns) != 1 || len(failed.ntfns[0].Deliveries) != 1 {
+		t.Fatalf("got failed deliveries %+v, want 1", failed.ntfns)
+	}
+	id := failed.ntfns[0].ID
+
+	// A retry that can't queue the delivery returns an error and keeps
+	// the failed delivery
+	err = c.Retry(id)
+	if err == nil {
+		t.Fatalf("retry with a full queue did not return an error")
+	}
+	if len(failed.ntfns) != 1 || failed.ntfns[0].ID != id {
+		t.Fatalf("got failed deliveries %+v, want %v", failed.ntfns, id)
+	}
+
+	// The delivery is queued once there is room in the queue
+	<-c.queue
+	err = c.Retry(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.queue) != 1 || len(failed.ntfns) != 0 {
+		t.Fatalf("got %v queued and %v failed, want 1 and 0",
+			len(c.queue), len(failed.ntfns))
+	}
+}
@@ -9,12 +9,14 @@ import (
 	"context"
 	"crypto/elliptic"
 	"crypto/tls"
+	"crypto/x509"
 	_ "encoding/gob"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -26,22 +28,35 @@ import (
 
 	"github.com/decred/politeia/mdstream"
 	pd "github.com/decred/politeia/politeiad/api/v1"
+	"github.com/decred/politeia/politeiad/api/v1/identity"
 	pdv2 "github.com/decred/politeia/politeiad/api/v2"
 	pdclient "github.com/decred/politeia/politeiad/client"
 	cms "github.com/decred/politeia/politeiawww/api/cms/v1"
+	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	elv1 "github.com/decred/politeia/politeiawww/api/eventlog/v1"
+	oav1 "github.com/decred/politeia/politeiawww/api/oauth/v1"
+	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	tmv1 "github.com/decred/politeia/politeiawww/api/telemetry/v1"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
 	www "github.com/decred/politeia/politeiawww/api/www/v1"
 	database "github.com/decred/politeia/politeiawww/cmsdatabase"
 	cmsdb "github.com/decred/politeia/politeiawww/cmsdatabase/cockroachdb"
 	ghtracker "github.com/decred/politeia/politeiawww/codetracker/github"
 	"github.com/decred/politeia/politeiawww/config"
+	"github.com/decred/politeia/politeiawww/consistency"
 	"github.com/decred/politeia/politeiawww/events"
+	"github.com/decred/politeia/politeiawww/frontend"
 	"github.com/decred/politeia/politeiawww/mail"
+	"github.com/decred/politeia/politeiawww/metrics"
+	"github.com/decred/politeia/politeiawww/passwords"
 	"github.com/decred/politeia/politeiawww/sessions"
 	"github.com/decred/politeia/politeiawww/user"
 	"github.com/decred/politeia/politeiawww/user/cockroachdb"
 	"github.com/decred/politeia/politeiawww/user/localdb"
 	"github.com/decred/politeia/politeiawww/user/mysql"
 	"github.com/decred/politeia/util"
+	"github.com/decred/politeia/util/tracing"
 	"github.com/decred/politeia/util/version"
 	"github.com/decred/politeia/wsdcrdata"
 	"github.com/google/uuid"
@@ -270,9 +285,12 @@ func (p *politeiawww) addRoute(method string, routeVersion string, route string,
 
 	switch perm {
 	case permissionAdmin:
-		handler = p.isLoggedInAsAdmin(handler)
+		handler = p.isAdminNetwork(p.isLoggedInAsAdmin(
+			p.idempotency.middleware(handler)))
 	case permissionLogin:
-		handler = p.isLoggedIn(handler)
+		handler = p.isLoggedIn(p.idempotency.middleware(handler))
+	case permissionPublic:
+		handler = p.idempotency.middleware(handler)
 	}
 
 	if method == "" {
@@ -292,6 +310,30 @@ func (p *politeiawww) addRoute(method string, routeVersion string, route string,
 	}
 }
 
+// setupFrontend sets up the static file server of the web frontend. Requests
+// that are not matched by an API route are served by the frontend. Unknown
+// API routes still receive the not found error reply.
+func (p *politeiawww) setupFrontend() error {
+	apiRoutes := []string{
+		www.PoliteiaWWWAPIRoute,
+		cms.APIRoute,
+		rcv1.APIRoute,
+		cmv1.APIRoute,
+		tkv1.APIRoute,
+		piv1.APIRoute,
+		tmv1.APIRoute,
+		oav1.APIRoute,
+		elv1.APIRoute,
+	}
+	f, err := frontend.New(p.cfg, apiRoutes,
+		http.HandlerFunc(p.handleNotFound))
+	if err != nil {
+		return err
+	}
+	p.router.PathPrefix("/").HandlerFunc(f.HandleFrontend)
+	return nil
+}
+
 // getPluginInventory returns the politeiad plugin inventory. If a politeiad
 // connection cannot be made, the call will be retried every 5 seconds for up
 // to 1000 tries.
@@ -333,6 +375,13 @@ func (p *politeiawww) setupCMS() error {
 	p.setCMSWWWRoutes()
 	p.setCMSUserWWWRoutes()
 
+	// Setup the request body size limits of the invoice routes, which
+	// accept files.
+	invoiceMax := filesSizeMax(www.PolicyMaxMDs*www.PolicyMaxMDSize +
+		www.PolicyMaxImages*www.PolicyMaxImageSize)
+	p.bodyLimits.set(cms.APIRoute+cms.RouteNewInvoice, invoiceMax)
+	p.bodyLimits.set(cms.APIRoute+cms.RouteEditInvoice, invoiceMax)
+
 	// Setup event manager
 	p.setupEventListenersCMS()
 
@@ -362,6 +411,10 @@ func (p *politeiawww) setupCMS() error {
 	if err != nil {
 		return fmt.Errorf("cmsdb setup: %v", err)
 	}
+	err = p.setupCMSDomains()
+	if err != nil {
+		return fmt.Errorf("setup cms domains: %v", err)
+	}
 
 	// Build the cms database
 	if p.cfg.BuildCMSDB {
@@ -401,7 +454,7 @@ func (p *politeiawww) setupCMS() error {
 			}
 
 			// Verify the UpdateVettedMetadata challenge.
-			err = util.VerifyChallenge(p.cfg.Identity, challenge, pdReply.Response)
+			err = util.VerifyChallenge(p.politeiadIdentity(), challenge, pdReply.Response)
 			if err != nil {
 				return err
 			}
@@ -487,6 +540,54 @@ func (p *politeiawww) setupCMS() error {
 	return nil
 }
 
+// redisTLSConfig returns the TLS configuration of the connections to the
+// Redis server. The server certificate is verified using the system CAs and
+// the provided CA certificate, if one is provided.
+func redisTLSConfig(host, certFile string) (*tls.Config, error) {
+	serverName, _, err := net.SplitHostPort(host)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if certFile == "" {
+		return tlsConfig, nil
+	}
+	cert, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read redis cert %v: %v",
+			certFile, err)
+	}
+	certPool, err := x509.SystemCertPool()
+	if err != nil {
+		log.Warnf("Unable to get system cert pool: %v", err)
+		certPool = x509.NewCertPool()
+	}
+	if !certPool.AppendCertsFromPEM(cert) {
+		return nil, fmt.Errorf("unable to load redis cert %v", certFile)
+	}
+	tlsConfig.RootCAs = certPool
+	return tlsConfig, nil
+}
+
+// newMailProvider returns the mail provider of the provided name using the
+// provider settings of the config.
+func newMailProvider(cfg *config.Config, name string) (mail.Provider, error) {
+	switch name {
+	case mail.ProviderSMTP:
+		return mail.NewSMTP(cfg.MailHost, cfg.MailUser, cfg.MailPass,
+			cfg.MailCert, cfg.MailSkipVerify)
+	case mail.ProviderSendGrid:
+		return mail.NewSendGrid(cfg.SendGridKey), nil
+	case mail.ProviderSES:
+		return mail.NewSES(cfg.SESRegion, cfg.SESAccessKey,
+			cfg.SESSecretKey), nil
+	}
+	return nil, fmt.Errorf("invalid mail provider")
+}
+
 func _main() error {
 	// Load configuration and parse command line.  This function also
 	// initializes logging and configures it accordingly.
@@ -578,18 +679,89 @@ func _main() error {
 		csrf.MaxAge(sessions.SessionMaxAge),
 	)
 
+	// Setup the network access control lists
+	acl, err := newACL(loadedCfg)
+	if err != nil {
+		return err
+	}
+	go acl.pruneLoop()
+
+	// Setup the rate limits. The login and signup routes are shared by
+	// all applications. The per user limits are enforced once the
+	// sessions have been setup. The rate limit middleware is registered
+	// before the body size limit middleware so that the rejected
+	// requests are not read.
+	rl, err := newRateLimits(loadedCfg.RateLimits, acl.clientIP)
+	if err != nil {
+		return err
+	}
+	rl.set(www.PoliteiaWWWAPIRoute+www.RouteLogin, rateLimitLogin)
+	rl.set(www.PoliteiaWWWAPIRoute+www.RouteNewUser, rateLimitSignup)
+
+	// Setup the maintenance mode. The maintenance middleware is
+	// registered before the body size limit middleware so that the
+	// rejected write requests are not read.
+	maintenanceRetry := time.Duration(loadedCfg.MaintenanceRetryAfter) *
+		time.Second
+	mt := newMaintenance(loadedCfg.Maintenance, maintenanceRetry)
+	if loadedCfg.Maintenance {
+		log.Infof("Maintenance mode: enabled")
+	}
+
+	// Setup the request body size limits. The route specific limits
+	// are set during the application specific setup.
+	bodyLimits := newBodyLimits(bodySizeMaxDefault)
+
+	// Setup the OpenAPI document. The routes are added to it during
+	// the application specific setup. The request validation
+	// middleware is registered after the body size limit middleware
+	// so that the body is read with the route limit in place.
+	oa := newOpenAPI()
+
+	// Setup the metrics. The metrics middleware is registered first
+	// so that the request latencies include all other middleware.
+	var m *metrics.Metrics
+	if loadedCfg.Metrics {
+		m = metrics.New()
+	}
+
+	// Setup the request tracing. The tracing middleware is registered
+	// after the metrics middleware so that the request spans include
+	// all other middleware.
+	var tracer *tracing.Tracer
+	if loadedCfg.TracingEndpoint != "" {
+		log.Infof("Tracing: %v", loadedCfg.TracingEndpoint)
+		tracer = tracing.New("politeiawww", loadedCfg.TracingEndpoint)
+	}
+
+	// Setup the read-after-write consistency tracker. The consistency
+	// middleware runs before the idempotency middleware of the routes
+	// so that replayed replies contain the consistency token.
+	ct := consistency.New()
+
 	// Setup router
 	router := mux.NewRouter()
+	if m != nil {
+		router.Use(m.Middleware)
+	}
+	if tracer != nil {
+		router.Use(tracer.Middleware)
+	}
 	router.Use(closeBodyMiddleware)
 	router.Use(loggingMiddleware)
 	router.Use(recoverMiddleware)
+	router.Use(acl.middleware)
+	router.Use(rl.middleware)
+	router.Use(mt.middleware)
+	router.Use(bodyLimits.middleware)
+	router.Use(requestValidationMiddleware(oa))
+	router.Use(ct.Middleware)
 
 	// Setup a subrouter that is CSRF protected. Authenticated routes
 	// are required to use the auth router. The subrouter takes on the
 	// configuration of the router that it was spawned from, including
 	// all of the middleware that has already been registered.
 	auth := router.NewRoute().Subrouter()
-	auth.Use(csrfMiddleware)
 
 	// Setup the politeiad client
 	pdc, err := pdclient.New(loadedCfg.RPCHost, loadedCfg.RPCCert,
@@ -597,6 +769,21 @@ func _main() error {
 	if err != nil {
 		return err
 	}
+	pdc.SetRotationHandler(func(pid *identity.PublicIdentity) {
+		log.Infof("Politeiad identity rotated: %v", pid)
+		err := pid.SavePublicIdentity(loadedCfg.RPCIdentityFile)
+		if err != nil {
+			log.Errorf("Save politeiad identity %v: %v",
+				loadedCfg.RPCIdentityFile, err)
+		}
+	})
+	observer := newPoliteiadObserver(m, ct)
+	pdc.SetObserver(observer)
+	pdc.SetMinVersion(consistency.MinSeq)
+	if loadedCfg.SummaryCacheTTL > 0 {
+		pdc.EnableCache(time.Duration(loadedCfg.SummaryCacheTTL) *
+			time.Second)
+	}
 
 	// Setup user database
 	log.Infof("User database: %v", loadedCfg.UserDB)
@@ -663,11 +850,67 @@ func _main() error {
 		}
 		log.Infof("Cookie key generated")
 	}
+	var (
+		sessionBackend sessions.Backend
+		redisStore     *sessions.Redis
+
+		// lockLost is closed when the redis instance lock has been
+		// lost. It is nil when the redis session store is not used.
+		lockLost <-chan struct{}
+	)
+	switch loadedCfg.SessionStore {
+	case sessions.StoreUserDB:
+		// The user database is used by default
+	case sessions.StoreRedis:
+		opts := sessions.RedisOpts{
+			Password: loadedCfg.RedisPassword,
+			DB:       loadedCfg.RedisDB,
+		}
+		if loadedCfg.RedisTLS {
+			opts.TLS, err = redisTLSConfig(loadedCfg.RedisHost,
+				loadedCfg.RedisCert)
+			if err != nil {
+				return err
+			}
+		}
+		redisStore, err = sessions.NewRedis(loadedCfg.RedisHost, opts,
+			cookieKey)
+		if err != nil {
+			return fmt.Errorf("new redis session store: %v", err)
+		}
+		log.Infof("Session store: redis %v", loadedCfg.RedisHost)
+		sessionBackend = redisStore
+
+		// Only one instance may serve requests at a time. A standby
+		// instance blocks here until the active instance has released
+		// the instance lock.
+		lockLost, err = redisStore.LockInstance()
+		if err != nil {
+			return fmt.Errorf("redis instance lock: %v", err)
+		}
+	default:
+		return fmt.Errorf("invalid session store '%v'",
+			loadedCfg.SessionStore)
+	}
 
-	// Setup smtp client
-	mailClient, err := mail.New(loadedCfg.MailHost, loadedCfg.MailUser,
-		loadedCfg.MailPass, loadedCfg.MailAddress, loadedCfg.MailCert,
-		loadedCfg.MailSkipVerify)
+	// Setup mail client. Email is disabled when the WebServerAddress has
+	// not been set. The emails that the primary mail provider fails to
+	// send are sent using the fallback provider.
+	var mailProviders []mail.Provider
+	if loadedCfg.WebServerAddress != "" {
+		for _, v := range []string{loadedCfg.MailProvider,
+			loadedCfg.MailFallback} {
+			if v == "" {
+				continue
+			}
+			mp, err := newMailProvider(loadedCfg, v)
+			if err != nil {
+				return fmt.Errorf("new mail provider %v: %v", v, err)
+			}
+			mailProviders = append(mailProviders, mp)
+		}
+	}
+	mailClient, err := mail.New(loadedCfg.MailAddress, mailProviders...)
 	if err != nil {
 		return fmt.Errorf("new mail client: %v", err)
 	}
@@ -678,21 +921,76 @@ func _main() error {
 		return err
 	}
 
+	// Setup the new user verification email throttle
+	resendInterval := time.Duration(loadedCfg.VerificationResendInterval) *
+		time.Minute
+
+	// Setup the password policy
+	pwc := passwords.New(loadedCfg.PasswordMinLength,
+		loadedCfg.PasswordMinScore, loadedCfg.PasswordBreachURL)
+
 	// Setup application context
 	p := &politeiawww{
-		cfg:        loadedCfg,
-		params:     activeNetParams.Params,
-		router:     router,
-		auth:       auth,
-		politeiad:  pdc,
-		http:       httpClient,
-		mail:       mailClient,
-		db:         userDB,
-		sessions:   sessions.New(userDB, cookieKey),
-		events:     events.NewManager(),
-		ws:         make(map[string]map[string]*wsContext),
-		userEmails: make(map[string]uuid.UUID),
+		cfg:            loadedCfg,
+		params:         activeNetParams.Params,
+		router:         router,
+		auth:           auth,
+		csrfSessionKey: csrfSessionKey(csrfKey),
+		unsubscribeKey: unsubscribeKey(csrfKey),
+		politeiad:      pdc,
+		http:           httpClient,
+		mail:           mailClient,
+		db:             userDB,
+		sessions:       sessions.New(userDB, sessionBackend, cookieKey),
+		events:         events.NewManager(),
+		redis:          redisStore,
+		ws:             make(map[string]map[string]*wsContext),
+		userEmails:     make(map[string]uuid.UUID),
+		resendThrottle: newResendThrottle(resendInterval),
+		passwords:      pwc,
+		acl:            acl,
+		maintenance:    mt,
+		bodyLimits:     bodyLimits,
+		rateLimits:     rl,
+		openapi:        oa,
+		metrics:        m,
+		tracer:         tracer,
+
+		observePoliteiad: observer,
+	}
+
+	// Setup the CSRF middleware. The CSRF session token middleware
+	// must be registered first since it allows requests that contain a
+	// valid CSRF session token to skip the cookie based CSRF check.
+	auth.Use(p.csrfSessionMiddleware)
+	auth.Use(csrfMiddleware)
+
+	// Setup the user ID lookup of the per user rate limits
+	rl.userID = p.sessionUserID
+
+	// Setup the idempotency cache. The idempotency middleware is added
+	// to all routes by addRoute. It runs after the session of the routes
+	// that require a login has been authenticated.
+	p.idempotency = newIdempotencyCache(p.sessionUserID)
+
+	// Register the smtp notifier. The notification events are routed
+	// to it by the APIs that send email notifications. The emails are
+	// persisted to the user database and sent by the mail queue so
+	// that mail server outages do not drop notifications.
+	//
+	// Every send attempt of the notification emails is recorded in the
+	// send log when it is enabled.
+	if p.cfg.MailLogRetention > 0 {
+		retention := time.Duration(p.cfg.MailLogRetention) * 24 * time.Hour
+		p.mailLog, err = mail.NewSendLog(filepath.Join(p.cfg.DataDir,
+			mailLogFilename), retention)
+		if err != nil {
+			return fmt.Errorf("new mail send log: %v", err)
+		}
 	}
+	mailQueue := mail.NewQueue(mailClient, userDB, p.mailLog)
+	p.mailQueue = mailQueue
+	p.events.RegisterNotifier(mail.NotifierSMTP, mail.NewNotifier(mailQueue))
 
 	// Setup email-userID cache
 	err = p.initUserEmailsCache()
@@ -700,6 +998,12 @@ func _main() error {
 		return err
 	}
 
+	// Setup email suppression list
+	err = p.initMailSuppression()
+	if err != nil {
+		return err
+	}
+
 	// Perform application specific setup
 	switch p.cfg.Mode {
 	case config.PoliteiaWWWMode:
@@ -716,33 +1020,129 @@ func _main() error {
 		return fmt.Errorf("unknown mode: %v", p.cfg.Mode)
 	}
 
-	// Bind to a port and pass our router in
-	listenC := make(chan error)
+	// Setup the mail provider bounce and complaint webhook
+	if p.cfg.MailFeedbackToken != "" {
+		log.Infof("Mail feedback: enabled")
+		p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
+			www.RouteMailFeedback, p.handleMailFeedback,
+			permissionPublic)
+	}
+
+	// Setup the mail send log route
+	if p.mailLog != nil {
+		p.addRoute(http.MethodGet, www.PoliteiaWWWAPIRoute,
+			www.RouteNotificationLog, p.handleNotificationLog,
+			permissionAdmin)
+	}
+	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
+		www.RouteNotificationRetry, p.handleNotificationRetry,
+		permissionAdmin)
+
+	// Setup the notification email unsubscribe links. The one-click
+	// unsubscribe request is a POST request that is sent by the mail
+	// client, so it can't include a CSRF token. GET requests only
+	// return a confirmation page so that link scanners do not
+	// unsubscribe users.
+	p.mail.SetUnsubscribe(p.unsubscribeLinks)
+	p.addRoute(http.MethodGet, www.PoliteiaWWWAPIRoute,
+		www.RouteUnsubscribe, p.handleUnsubscribeConfirm,
+		permissionPublic)
+	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
+		www.RouteUnsubscribe, p.handleUnsubscribe,
+		permissionPublic)
+
+	// Start sending the queued emails. This is done once the
+	// unsubscribe links have been setup so that the emails that were
+	// queued before a restart include them.
+	go mailQueue.Run()
+
+	// Setup the verification token expiration and the pending
+	// verifications admin route
+	go p.verificationLoop()
+	p.addRoute(http.MethodGet, www.PoliteiaWWWAPIRoute,
+		www.RouteVerifications, p.handleVerifications,
+		permissionAdmin)
+
+	// Setup the network access control list admin routes
+	p.addRoute(http.MethodGet, www.PoliteiaWWWAPIRoute,
+		www.RouteACL, p.handleACL,
+		permissionAdmin)
+	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
+		www.RouteSetACL, p.handleSetACL,
+		permissionAdmin)
+
+	// Setup the maintenance mode routes
+	p.addRoute(http.MethodGet, www.PoliteiaWWWAPIRoute,
+		www.RouteMaintenance, p.handleMaintenance,
+		permissionPublic)
+	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
+		www.RouteSetMaintenance, p.handleSetMaintenance,
+		permissionAdmin)
+
+	// Setup the site info route. It is served in all modes so that
+	// generic clients can discover the deployment.
+	p.addRoute(http.MethodGet, www.PoliteiaWWWAPIRoute,
+		www.RouteSiteInfo, p.handleSiteInfo,
+		permissionPublic)
+
+	// Setup the health and readiness routes. They are served from the
+	// root of the server so that they can be probed without knowledge
+	// of the API.
+	p.addRoute(http.MethodGet, "", www.RouteHealth, p.handleHealth,
+		permissionPublic)
+	p.addRoute(http.MethodGet, "", www.RouteReady, p.handleReady,
+		permissionPublic)
+
+	// Setup the metrics route
+	if p.metrics != nil {
+		log.Infof("Metrics: enabled")
+		p.setupMetrics()
+	}
+
+	// Setup the web frontend. This must be done after all other routes
+	// have been added since the frontend matches every path.
+	if p.cfg.WebRoot != "" {
+		err = p.setupFrontend()
+		if err != nil {
+			return fmt.Errorf("setupFrontend: %v", err)
+		}
+	}
+
+	// Bind to a port and pass our router in. The listen channel is
+	// buffered so that the listeners are able to return once they have
+	// been shut down.
+	listenC := make(chan error, len(loadedCfg.Listeners)+1)
+	servers := make([]*http.Server, 0, len(loadedCfg.Listeners)+1)
+	if loadedCfg.MetricsListen != "" {
+		srv := p.newMetricsServer()
+		servers = append(servers, srv)
+		go p.listenMetrics(srv, listenC)
+	}
 	for _, listener := range loadedCfg.Listeners {
 		listen := listener
+		cfg := &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			CurvePreferences: []tls.CurveID{
+				tls.CurveP256, // BLAME CHROME, NOT ME!
+				tls.CurveP521,
+				tls.X25519},
+			PreferServerCipherSuites: true,
+			CipherSuites: []uint16{
+				tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256,
+				tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+				tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+				tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+			},
+		}
+		srv := &http.Server{
+			Handler:   p.router,
+			Addr:      listen,
+			TLSConfig: cfg,
+			TLSNextProto: make(map[string]func(*http.Server,
+				*tls.Conn, http.Handler)),
+		}
+		servers = append(servers, srv)
 		go func() {
-			cfg := &tls.Config{
-				MinVersion: tls.VersionTLS12,
-				CurvePreferences: []tls.CurveID{
-					tls.CurveP256, // BLAME CHROME, NOT ME!
-					tls.CurveP521,
-					tls.X25519},
-				PreferServerCipherSuites: true,
-				CipherSuites: []uint16{
-					tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256,
-					tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
-					tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
-					tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
-				},
-			}
-			srv := &http.Server{
-				Handler:   p.router,
-				Addr:      listen,
-				TLSConfig: cfg,
-				TLSNextProto: make(map[string]func(*http.Server,
-					*tls.Conn, http.Handler)),
-			}
-
 			log.Infof("Listen: %v", listen)
 			listenC <- srv.ListenAndServeTLS(loadedCfg.HTTPSCert,
 				loadedCfg.HTTPSKey)
@@ -764,15 +1164,35 @@ func _main() error {
 		case err := <-listenC:
 			log.Errorf("%v", err)
 			goto done
+		case <-lockLost:
+			log.Errorf("Redis instance lock lost")
+			goto done
 		}
 	}
 done:
 
+	// Gracefully shutdown
+	p.shutdown(servers, mailQueue, sigs)
+
+	// Release the redis instance lock so that a standby instance can
+	// take over.
+	if redisStore != nil {
+		err := redisStore.UnlockInstance()
+		if err != nil {
+			log.Errorf("Redis instance unlock: %v", err)
+		}
+	}
+
 	log.Infof("Exiting")
 
 	// Close user db connection
 	p.db.Close()
 
+	// Export the remaining trace spans
+	if p.tracer != nil {
+		p.tracer.Close()
+	}
+
 	// Perform application specific shutdown
 	switch p.cfg.Mode {
 	case config.PoliteiaWWWMode:
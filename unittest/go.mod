@@ -0,0 +1,3 @@
+module github.com/decred/politeia/unittest
+
+go 1.15
@@ -5,10 +5,12 @@
 package util
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 )
 
 func RespondWithError(w http.ResponseWriter, code int, message string) {
@@ -43,6 +45,50 @@ func RespondRaw(w http.ResponseWriter, code int, payload []byte) {
 	w.Write(payload)
 }
 
+// RespondWithETag replies with the JSON encoded payload. The reply to a GET or
+// HEAD request includes an ETag header that contains the SHA256 digest of the
+// reply and a Cache-Control header that requires clients to revalidate a
+// cached reply. A 304 without a body is returned when the If-None-Match header
+// of the request matches the ETag, which allows clients to revalidate a cached
+// reply without downloading it again.
+//
+// The conditional headers are ignored for all other methods. Their replies are
+// not cacheable and a matching If-None-Match header would require a 412 to be
+// returned instead of the reply.
+func RespondWithETag(w http.ResponseWriter, r *http.Request, payload interface{}) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		RespondWithJSON(w, http.StatusOK, payload)
+		return
+	}
+
+	response, _ := json.Marshal(payload)
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256(response))
+
+	h := w.Header()
+	h.Set("ETag", etag)
+	h.Set("Cache-Control", "no-cache")
+
+	if etagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	RespondRaw(w, http.StatusOK, response)
+}
+
+// etagMatch returns whether an If-None-Match header value matches the ETag.
+// The header can contain a list of ETags. The weak comparison is used, so a
+// weak ETag matches the strong ETag with the same value.
+func etagMatch(header, etag string) bool {
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
 // GetErrorFromJSON returns the error that is embedded in a JSON reply.
 func GetErrorFromJSON(r io.Reader) (interface{}, error) {
 	var e interface{}
@@ -0,0 +1,68 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRespondWithETag(t *testing.T) {
+	payload := map[string]string{"token": "45154fb45664714b"}
+
+	// Get the ETag
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	RespondWithETag(w, r, payload)
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" || w.Body.Len() == 0 {
+		t.Fatalf("got code %v etag %v body %v", w.Code, etag, w.Body.Len())
+	}
+	if w.Header().Get("Cache-Control") != "no-cache" {
+		t.Fatalf("got cache control %v", w.Header().Get("Cache-Control"))
+	}
+
+	// The conditional headers of a POST request are ignored
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPost, "/", nil)
+	r.Header.Set("If-None-Match", etag)
+	RespondWithETag(w, r, payload)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != "" {
+		t.Fatalf("POST: got code %v etag %v", w.Code, w.Header().Get("ETag"))
+	}
+
+	var tests = []struct {
+		name        string
+		ifNoneMatch string
+		wantCode    int
+	}{
+		{"no header", "", http.StatusOK},
+		{"match", etag, http.StatusNotModified},
+		{"weak match", "W/" + etag, http.StatusNotModified},
+		{"list", `"abc", ` + etag, http.StatusNotModified},
+		{"wildcard", "*", http.StatusNotModified},
+		{"no match", `"abc"`, http.StatusOK},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if v.ifNoneMatch != "" {
+				r.Header.Set("If-None-Match", v.ifNoneMatch)
+			}
+			w := httptest.NewRecorder()
+			RespondWithETag(w, r, payload)
+			if w.Code != v.wantCode {
+				t.Fatalf("got code %v, want %v", w.Code, v.wantCode)
+			}
+			if v.wantCode == http.StatusNotModified && w.Body.Len() != 0 {
+				t.Fatalf("304 reply contains a body")
+			}
+			if w.Header().Get("ETag") != etag {
+				t.Fatalf("got etag %v, want %v", w.Header().Get("ETag"), etag)
+			}
+		})
+	}
+}
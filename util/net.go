@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -88,6 +89,17 @@ func ParseGetParams(r *http.Request, dst interface{}) error {
 	return schema.NewDecoder().Decode(dst, r.Form)
 }
 
+// ParseRequest decodes the params of a request into a struct. The params of a
+// GET request are parsed from the query params, which requires the struct type
+// to be defined with `schema` tags. The JSON encoded request body is decoded
+// for all other methods.
+func ParseRequest(r *http.Request, dst interface{}) error {
+	if r.Method == http.MethodGet {
+		return ParseGetParams(r, dst)
+	}
+	return json.NewDecoder(r.Body).Decode(dst)
+}
+
 // RespBody returns the response body as a byte slice.
 func RespBody(r *http.Response) []byte {
 	var mw io.Writer
@@ -8,6 +8,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"regexp"
+	"strings"
 
 	pdv1 "github.com/decred/politeia/politeiad/api/v1"
 	pdv2 "github.com/decred/politeia/politeiad/api/v2"
@@ -22,26 +23,69 @@ var (
 	TokenTypeTstore = "tstore"
 
 	// tokenRegexp is a regexp that matches short tokens and full
-	// length tokens.
+	// length tokens. Short tokens of the default length are always
+	// matched so that short tokens that were handed out prior to a
+	// short token length change remain valid.
 	tokenRegexp = regexp.MustCompile(fmt.Sprintf("^[0-9a-f]{%v,%v}$",
 		pdv2.ShortTokenLength, pdv2.TokenSize*2))
+
+	// shortTokenLength is the length, in characters, of a hex encoded
+	// short token. It defaults to the politeiad API short token length
+	// and can be changed by the deployment on startup.
+	shortTokenLength = pdv2.ShortTokenLength
 )
 
-// ShortTokenSize returns the size, in bytes, of a politeiad short token.
-func ShortTokenSize() int {
+// SetShortTokenLength sets the length, in characters, of the hex encoded short
+// tokens. The length must be at least the politeiad API short token length and
+// less than the length of a full token. This function is not concurrency safe
+// and must be called on startup, prior to any tokens being used.
+func SetShortTokenLength(length int) error {
+	if length < pdv2.ShortTokenLength || length >= pdv2.TokenSize*2 {
+		return fmt.Errorf("short token length must be between %v and %v",
+			pdv2.ShortTokenLength, pdv2.TokenSize*2-1)
+	}
+	shortTokenLength = length
+	return nil
+}
+
+// ShortTokenLength returns the length, in characters, of a hex encoded short
+// token.
+func ShortTokenLength() int {
+	return shortTokenLength
+}
+
+// shortTokenSize returns the size, in bytes, of a short token of the provided
+// length.
+func shortTokenSize(length int) int {
 	// If the short token length is an odd number of characters then
 	// padding would have needed to be added to it prior to encoding it
 	// to hex to prevent a hex.ErrLenth (odd length hex string) error.
 	// This function accounts for this padding in the returned size.
-	var size int
-	if pdv1.TokenPrefixLength%2 == 1 {
-		// Add 1 to the length to account for padding
-		size = (pdv2.ShortTokenLength + 1) / 2
-	} else {
-		// No padding was required
-		size = pdv2.ShortTokenLength / 2
-	}
-	return size
+	return (length + 1) / 2
+}
+
+// ShortTokenSize returns the size, in bytes, of a politeiad short token.
+func ShortTokenSize() int {
+	return shortTokenSize(shortTokenLength)
+}
+
+// LegacyShortToken returns the hex encoded short token of the default short
+// token length for a decoded token of that size. This allows the short tokens
+// that were handed out prior to a short token length change to be looked up.
+// False is returned if the token is not a default length short token or if it
+// is a short token of the configured length.
+func LegacyShortToken(token []byte) (string, bool) {
+	if shortTokenLength == pdv2.ShortTokenLength ||
+		len(token) != shortTokenSize(pdv2.ShortTokenLength) {
+		return "", false
+	}
+	t := hex.EncodeToString(token)
+	if strings.TrimRight(t[pdv2.ShortTokenLength:], "0") != "" {
+		// The token contains more characters than a default length
+		// short token. These are not padding.
+		return "", false
+	}
+	return t[:pdv2.ShortTokenLength], true
 }
 
 // ShortToken returns the short version of a token.
@@ -59,7 +103,10 @@ func ShortTokenString(token string) (string, error) {
 	if tokenRegexp.FindString(token) == "" {
 		return "", fmt.Errorf("invalid token %v", tokenRegexp.String())
 	}
-	return token[:pdv2.ShortTokenLength], nil
+	if len(token) < shortTokenLength {
+		return "", fmt.Errorf("token is not large enough")
+	}
+	return token[:shortTokenLength], nil
 }
 
 // ShortTokenEncode returns the hex encoded shortened token.
@@ -129,6 +176,9 @@ func TokenDecodeAnyLength(tokenType, token string) ([]byte, error) {
 	case len(t) == ShortTokenSize():
 		// This is a short token. Short tokens are the same size
 		// regardless of token type.
+	case len(t) == shortTokenSize(pdv2.ShortTokenLength):
+		// This is a short token of the default length. These remain
+		// valid after a short token length change.
 	case tokenType == TokenTypeGit && TokenIsFullLength(TokenTypeGit, t):
 		// Token is a valid git backend token
 	case tokenType == TokenTypeTstore && TokenIsFullLength(TokenTypeTstore, t):
@@ -146,10 +196,10 @@ func TokenDecodeAnyLength(tokenType, token string) ([]byte, error) {
 // the token.
 func TokenEncode(token []byte) string {
 	t := hex.EncodeToString(token)
-	if len(t) == pdv2.ShortTokenLength+1 {
+	if shortTokenLength%2 == 1 && len(t) == shortTokenLength+1 {
 		// This is a short token that has had padding added to it. Remove
 		// the padding.
-		t = t[:pdv2.ShortTokenLength]
+		t = t[:shortTokenLength]
 	}
 	return t
 }
@@ -0,0 +1,72 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"encoding/hex"
+	"testing"
+
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+)
+
+func TestShortTokenLength(t *testing.T) {
+	defer SetShortTokenLength(pdv2.ShortTokenLength)
+
+	token, err := hex.DecodeString("45154fb45664714b")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Invalid lengths
+	for _, v := range []int{pdv2.ShortTokenLength - 1, pdv2.TokenSize * 2} {
+		if SetShortTokenLength(v) == nil {
+			t.Fatalf("short token length %v accepted", v)
+		}
+	}
+
+	var tests = []struct {
+		length int
+		short  string
+		legacy bool // Default length short tokens are looked up separately
+	}{
+		{7, "45154fb", false},
+		{8, "45154fb4", true},
+		{9, "45154fb45", true},
+		{12, "45154fb45664", true},
+	}
+	for _, v := range tests {
+		err := SetShortTokenLength(v.length)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Verify the short token round trips
+		s, err := ShortTokenEncode(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != v.short {
+			t.Fatalf("length %v: got short token %v, want %v",
+				v.length, s, v.short)
+		}
+		b, err := TokenDecodeAnyLength(TokenTypeTstore, s)
+		if err != nil {
+			t.Fatalf("length %v: %v", v.length, err)
+		}
+		if TokenEncode(b) != s {
+			t.Fatalf("length %v: got %v, want %v", v.length, TokenEncode(b), s)
+		}
+
+		// Verify that default length short tokens are still accepted
+		b, err = TokenDecodeAnyLength(TokenTypeTstore, "45154fb")
+		if err != nil {
+			t.Fatalf("length %v: default length: %v", v.length, err)
+		}
+		legacy, ok := LegacyShortToken(b)
+		if ok != v.legacy || (ok && legacy != "45154fb") {
+			t.Fatalf("length %v: got legacy %v %v", v.length, legacy, ok)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// exportPath is the OTLP/HTTP path that spans are exported to.
+	exportPath = "/v1/traces"
+
+	// exportInterval is the interval at which the queued spans are
+	// exported.
+	exportInterval = 5 * time.Second
+
+	// exportTimeout is the timeout of a single export request.
+	exportTimeout = 10 * time.Second
+
+	// exportBatchSize is the number of queued spans that triggers an
+	// export prior to the export interval.
+	exportBatchSize = 512
+
+	// queueSize is the maximum number of spans that are queued for
+	// export. Spans are dropped when the queue is full, which happens
+	// when the collector is unavailable.
+	queueSize = 4096
+
+	// scopeName is the instrumentation scope that spans are exported
+	// with.
+	scopeName = "github.com/decred/politeia/util/tracing"
+
+	// OTLP status codes
+	statusCodeOK    = 1
+	statusCodeError = 2
+)
+
+// queue adds an ended span to the export queue.
+func (t *Tracer) queue(s *Span) {
+	t.Lock()
+	defer t.Unlock()
+
+	if len(t.spans) >= queueSize {
+		t.dropped++
+		return
+	}
+	t.spans = append(t.spans, s)
+	if len(t.spans) == exportBatchSize {
+		select {
+		case t.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run exports the queued spans until the tracer is closed. This function
+// must be run as a go routine.
+func (t *Tracer) run() {
+	defer close(t.closed)
+
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-t.flush:
+		case <-t.done:
+			t.exportQueued()
+			return
+		}
+		t.exportQueued()
+	}
+}
+
+// exportQueued exports all queued spans. The spans are dropped if the export
+// fails.
+func (t *Tracer) exportQueued() {
+	t.Lock()
+	spans := t.spans
+	dropped := t.dropped
+	t.spans = make([]*Span, 0, exportBatchSize)
+	t.dropped = 0
+	t.Unlock()
+
+	if dropped > 0 {
+		log.Warnf("%v spans dropped; export queue full", dropped)
+	}
+	if len(spans) == 0 {
+		return
+	}
+	err := t.export(spans)
+	if err != nil {
+		log.Errorf("Export %v spans: %v", len(spans), err)
+	}
+}
+
+// export sends the spans to the collector.
+func (t *Tracer) export(spans []*Span) error {
+	b, err := json.Marshal(t.convertSpans(spans))
+	if err != nil {
+		return err
+	}
+	r, err := t.http.Post(t.endpoint, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(r.Body)
+		return fmt.Errorf("%v: %s", r.Status, body)
+	}
+	return nil
+}
+
+// The types below are the OTLP/HTTP JSON encoding of an export trace service
+// request. Trace and span IDs are hex encoded and timestamps are encoded as
+// decimal strings of UNIX nanoseconds.
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              SpanKind        `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// convertSpans converts the spans to an OTLP export request.
+func (t *Tracer) convertSpans(spans []*Span) otlpRequest {
+	ss := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		ss = append(ss, convertSpan(s))
+	}
+	return otlpRequest{
+		ResourceSpans: []otlpResourceSpans{
+			{
+				Resource: otlpResource{
+					Attributes: []otlpAttribute{
+						convertAttribute("service.name", t.service),
+					},
+				},
+				ScopeSpans: []otlpScopeSpans{
+					{
+						Scope: otlpScope{
+							Name: scopeName,
+						},
+						Spans: ss,
+					},
+				},
+			},
+		},
+	}
+}
+
+func convertSpan(s *Span) otlpSpan {
+	s.Lock()
+	defer s.Unlock()
+
+	// Sort the attributes so that the encoding is deterministic
+	keys := make([]string, 0, len(s.attrs))
+	for k := range s.attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	attrs := make([]otlpAttribute, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, convertAttribute(k, s.attrs[k]))
+	}
+
+	span := otlpSpan{
+		TraceID:           hex.EncodeToString(s.traceID[:]),
+		SpanID:            hex.EncodeToString(s.spanID[:]),
+		Name:              s.name,
+		Kind:              s.kind,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		Attributes:        attrs,
+		Status: otlpStatus{
+			Code: statusCodeOK,
+		},
+	}
+	if s.parentID != [8]byte{} {
+		span.ParentSpanID = hex.EncodeToString(s.parentID[:])
+	}
+	if s.err != "" {
+		span.Status = otlpStatus{
+			Code:    statusCodeError,
+			Message: s.err,
+		}
+	}
+	return span
+}
+
+func convertAttribute(key, value string) otlpAttribute {
+	return otlpAttribute{
+		Key: key,
+		Value: otlpValue{
+			StringValue: value,
+		},
+	}
+}
@@ -0,0 +1,314 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package tracing implements distributed request tracing. Spans are propagated
+// between politeiawww and politeiad using the W3C Trace Context traceparent
+// header and are exported to an OpenTelemetry collector using the OTLP/HTTP
+// JSON encoding.
+//
+// Spans are started from a context. A nil span is returned when the context
+// does not contain a span, which happens when tracing has not been enabled.
+// All span methods can be called on a nil span.
+package tracing
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// HeaderTraceParent is the W3C Trace Context header that is used to
+	// propagate spans across http requests.
+	HeaderTraceParent = "traceparent"
+
+	// traceParentVersion is the supported traceparent version.
+	traceParentVersion = "00"
+
+	// traceFlagSampled is the traceparent flag that indicates that the
+	// trace is sampled. All traces are sampled.
+	traceFlagSampled = "01"
+)
+
+// SpanKind describes the relationship between a span and its parent.
+type SpanKind int
+
+const (
+	// SpanKindInternal is an operation that is internal to a service.
+	SpanKindInternal SpanKind = 1
+
+	// SpanKindServer is the handling of a remote request.
+	SpanKindServer SpanKind = 2
+
+	// SpanKindClient is a request made to a remote service.
+	SpanKindClient SpanKind = 3
+)
+
+// Span represents a single traced operation.
+type Span struct {
+	sync.Mutex
+	tracer   *Tracer
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte // Zero for root spans
+	name     string
+	kind     SpanKind
+	start    time.Time
+	end      time.Time
+	attrs    map[string]string
+	err      string
+	ended    bool
+}
+
+// SetAttribute sets an attribute on the span. The value is stored as a
+// string.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+
+	s.attrs[key] = fmt.Sprint(value)
+}
+
+// SetError marks the span as failed. A nil error is ignored.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+
+	s.err = err.Error()
+}
+
+// End ends the span and queues it for export. Calling End more than once has
+// no effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.Lock()
+	if s.ended {
+		s.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.Unlock()
+
+	s.tracer.queue(s)
+}
+
+// TraceParent returns the traceparent header value of the span.
+func (s *Span) TraceParent() string {
+	if s == nil {
+		return ""
+	}
+	return traceParentVersion + "-" + hex.EncodeToString(s.traceID[:]) +
+		"-" + hex.EncodeToString(s.spanID[:]) + "-" + traceFlagSampled
+}
+
+type contextKey int
+
+const spanKey contextKey = 0
+
+// SpanFromContext returns the span that the context contains. Nil is
+// returned if the context does not contain a span.
+func SpanFromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey).(*Span)
+	return s
+}
+
+// ContextWithSpan returns a copy of the context that contains the span.
+func ContextWithSpan(ctx context.Context, s *Span) context.Context {
+	return context.WithValue(ctx, spanKey, s)
+}
+
+// Start starts a child span of the span that the context contains. The
+// returned context contains the new span. A nil span is returned and the
+// context is returned unchanged if the context does not contain a span.
+func Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	parent := SpanFromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	s := parent.tracer.newSpan(name, kind)
+	s.traceID = parent.traceID
+	s.parentID = parent.spanID
+	return ContextWithSpan(ctx, s), s
+}
+
+// Inject sets the traceparent header of an outgoing http request using the
+// span that the context contains.
+func Inject(ctx context.Context, h http.Header) {
+	s := SpanFromContext(ctx)
+	if s == nil {
+		return
+	}
+	h.Set(HeaderTraceParent, s.TraceParent())
+}
+
+// parseTraceParent parses a traceparent header value and returns the trace ID
+// and the parent span ID. The header is only accepted if it is valid and uses
+// a supported version.
+func parseTraceParent(v string) ([16]byte, [8]byte, bool) {
+	var (
+		traceID [16]byte
+		spanID  [8]byte
+	)
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) != 4 || parts[0] != traceParentVersion ||
+		len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return traceID, spanID, false
+	}
+	_, err := hex.Decode(traceID[:], []byte(parts[1]))
+	if err != nil {
+		return traceID, spanID, false
+	}
+	_, err = hex.Decode(spanID[:], []byte(parts[2]))
+	if err != nil {
+		return traceID, spanID, false
+	}
+	if traceID == [16]byte{} || spanID == [8]byte{} {
+		// All zero IDs are invalid
+		return traceID, spanID, false
+	}
+	return traceID, spanID, true
+}
+
+// Tracer creates spans and exports them to an OpenTelemetry collector.
+type Tracer struct {
+	sync.Mutex
+	service  string
+	endpoint string
+	http     *http.Client
+	spans    []*Span // Ended spans that have not been exported
+	dropped  uint64  // Spans dropped because the queue was full
+	flush    chan struct{}
+	done     chan struct{}
+	closed   chan struct{}
+}
+
+// New returns a new Tracer that exports spans to the OTLP/HTTP endpoint of an
+// OpenTelemetry collector (e.g. http://localhost:4318). The service is the
+// service name that the spans are exported with. Spans are exported in the
+// background until Close is called.
+func New(service, endpoint string) *Tracer {
+	t := &Tracer{
+		service:  service,
+		endpoint: strings.TrimSuffix(endpoint, "/") + exportPath,
+		http: &http.Client{
+			Timeout: exportTimeout,
+		},
+		spans:  make([]*Span, 0, exportBatchSize),
+		flush:  make(chan struct{}, 1),
+		done:   make(chan struct{}),
+		closed: make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+// Close exports the remaining spans and stops the tracer.
+func (t *Tracer) Close() {
+	close(t.done)
+	<-t.closed
+}
+
+// newSpan returns a new span with a random span ID.
+func (t *Tracer) newSpan(name string, kind SpanKind) *Span {
+	s := &Span{
+		tracer: t,
+		name:   name,
+		kind:   kind,
+		start:  time.Now(),
+		attrs:  make(map[string]string),
+	}
+	rand.Read(s.spanID[:])
+	return s
+}
+
+// StartRequest starts a server span for an incoming http request. The span
+// continues the trace of the traceparent header of the request when one is
+// present. Otherwise, a new trace is started.
+func (t *Tracer) StartRequest(r *http.Request, name string) (context.Context, *Span) {
+	s := t.newSpan(name, SpanKindServer)
+	traceID, parentID, ok := parseTraceParent(r.Header.Get(HeaderTraceParent))
+	if ok {
+		s.traceID = traceID
+		s.parentID = parentID
+	} else {
+		rand.Read(s.traceID[:])
+	}
+	s.attrs["http.method"] = r.Method
+	s.attrs["http.target"] = r.URL.Path
+	return ContextWithSpan(r.Context(), s), s
+}
+
+// Middleware is a http middleware that starts a server span for every
+// request. The span is named using the path template of the matched route so
+// that requests for the same route are grouped together.
+func (t *Tracer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Path
+		if route := mux.CurrentRoute(r); route != nil {
+			if tmpl, err := route.GetPathTemplate(); err == nil {
+				name = tmpl
+			}
+		}
+		ctx, s := t.StartRequest(r, r.Method+" "+name)
+		defer s.End()
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r.WithContext(ctx))
+
+		s.SetAttribute("http.status_code", sw.status)
+		if sw.status >= http.StatusInternalServerError {
+			s.SetError(fmt.Errorf("%v %v", sw.status,
+				http.StatusText(sw.status)))
+		}
+	})
+}
+
+// statusWriter records the status code of a http response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status code and writes it to the underlying
+// ResponseWriter.
+func (w *statusWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Flush satisfies the http Flusher interface for streaming responses.
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack satisfies the http Hijacker interface so that websocket connections
+// can be upgraded.
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer is not a hijacker")
+	}
+	w.status = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
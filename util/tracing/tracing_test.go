@@ -0,0 +1,125 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTraceParent(t *testing.T) {
+	var tests = []struct {
+		name  string
+		value string
+		valid bool
+	}{
+		{
+			"valid",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			true,
+		},
+		{
+			"unsupported version",
+			"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			false,
+		},
+		{
+			"zero trace id",
+			"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			false,
+		},
+		{
+			"invalid hex",
+			"00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01",
+			false,
+		},
+		{
+			"missing flags",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+			false,
+		},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			_, _, ok := parseTraceParent(v.value)
+			if ok != v.valid {
+				t.Fatalf("got %v, want %v", ok, v.valid)
+			}
+		})
+	}
+}
+
+func TestTracer(t *testing.T) {
+	// Setup a collector
+	var req otlpRequest
+	collector := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != exportPath {
+				t.Errorf("got path %v, want %v", r.URL.Path, exportPath)
+			}
+			b, _ := ioutil.ReadAll(r.Body)
+			err := json.Unmarshal(b, &req)
+			if err != nil {
+				t.Error(err)
+			}
+		}))
+	defer collector.Close()
+
+	// Start a child span for a request that contains a traceparent and
+	// propagate it to an outgoing request.
+	var outgoing http.Header
+	tracer := New("test", collector.URL)
+	h := tracer.Middleware(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			ctx, s := Start(r.Context(), "child", SpanKindClient)
+			s.SetError(errors.New("child error"))
+			outgoing = make(http.Header)
+			Inject(ctx, outgoing)
+			s.End()
+		}))
+	parent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	r := httptest.NewRequest(http.MethodPost, "/v1/test", nil)
+	r.Header.Set(HeaderTraceParent, parent)
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	tracer.Close()
+
+	// Verify the exported spans
+	if len(req.ResourceSpans) != 1 ||
+		len(req.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("unexpected export request: %+v", req)
+	}
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("got %v spans, want 2", len(spans))
+	}
+	child, server := spans[0], spans[1]
+	switch {
+	case server.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736":
+		t.Fatalf("server span did not continue the trace: %v", server.TraceID)
+	case server.ParentSpanID != "00f067aa0ba902b7":
+		t.Fatalf("server span parent: got %v", server.ParentSpanID)
+	case server.Kind != SpanKindServer || server.Status.Code != statusCodeOK:
+		t.Fatalf("unexpected server span: %+v", server)
+	case child.TraceID != server.TraceID || child.ParentSpanID != server.SpanID:
+		t.Fatalf("child span is not a child of the server span")
+	case child.Status.Code != statusCodeError:
+		t.Fatalf("child span error was not exported")
+	}
+	want := "00-" + child.TraceID + "-" + child.SpanID + "-01"
+	if got := outgoing.Get(HeaderTraceParent); got != want {
+		t.Fatalf("got traceparent %v, want %v", got, want)
+	}
+
+	// Spans cannot be started without a parent span
+	_, s := Start(r.Context(), "orphan", SpanKindInternal)
+	if s != nil {
+		t.Fatalf("got span without a parent")
+	}
+	s.End()
+}